@@ -0,0 +1,14 @@
+-- migrate:up
+CREATE TABLE categories (
+    name VARCHAR(128) PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO categories (name) SELECT DISTINCT category FROM series ON CONFLICT DO NOTHING;
+
+ALTER TABLE series
+    ADD CONSTRAINT series_category_fkey FOREIGN KEY (category) REFERENCES categories (name);
+
+-- migrate:down
+ALTER TABLE series DROP CONSTRAINT IF EXISTS series_category_fkey;
+DROP TABLE IF EXISTS categories;
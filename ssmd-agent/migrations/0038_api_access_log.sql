@@ -0,0 +1,30 @@
+-- migrate:up
+
+-- Structured access audit: one row per authenticated API request
+CREATE TABLE api_access_log (
+    id              BIGSERIAL PRIMARY KEY,
+    key_prefix      VARCHAR(30) NOT NULL,
+    user_email      VARCHAR(255),
+    method          VARCHAR(10) NOT NULL,
+    route           VARCHAR(255) NOT NULL,
+    dataset         VARCHAR(64),
+    status_code     SMALLINT NOT NULL,
+    response_bytes  BIGINT,
+    latency_ms      INTEGER NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Per-key audit queries
+CREATE INDEX idx_api_access_log_key
+    ON api_access_log (key_prefix, created_at);
+
+-- Per-dataset audit queries
+CREATE INDEX idx_api_access_log_dataset
+    ON api_access_log (dataset, created_at)
+    WHERE dataset IS NOT NULL;
+
+CREATE INDEX idx_api_access_log_created
+    ON api_access_log (created_at);
+
+-- migrate:down
+DROP TABLE IF EXISTS api_access_log;
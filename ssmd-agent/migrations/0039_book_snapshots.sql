@@ -0,0 +1,25 @@
+-- migrate:up
+
+-- Top-N order book levels per instrument, written on an interval by
+-- book-snapshot-consumer from NATS orderbook messages
+CREATE TABLE book_snapshots (
+    id              BIGSERIAL PRIMARY KEY,
+    feed            VARCHAR(32) NOT NULL,
+    ticker          VARCHAR(128) NOT NULL,
+    depth           SMALLINT NOT NULL,
+    bids            JSONB NOT NULL,
+    asks            JSONB NOT NULL,
+    exchange_ts     TIMESTAMPTZ,
+    snapshot_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Book as of a time: latest snapshot at or before ts per (feed, ticker)
+CREATE INDEX idx_book_snapshots_ticker_time
+    ON book_snapshots (feed, ticker, snapshot_at DESC);
+
+-- Retention cleanup
+CREATE INDEX idx_book_snapshots_time
+    ON book_snapshots (snapshot_at);
+
+-- migrate:down
+DROP TABLE IF EXISTS book_snapshots;
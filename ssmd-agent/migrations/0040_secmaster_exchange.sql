@@ -0,0 +1,38 @@
+-- migrate:up
+
+-- Exchange discriminator for events and markets. Tickers are only unique
+-- within an exchange, so keys become (exchange, ticker). The DEFAULT backfills
+-- every existing row as 'kalshi', the only exchange synced so far.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS exchange VARCHAR(32) NOT NULL DEFAULT 'kalshi';
+ALTER TABLE markets ADD COLUMN IF NOT EXISTS exchange VARCHAR(32) NOT NULL DEFAULT 'kalshi';
+
+ALTER TABLE markets DROP CONSTRAINT IF EXISTS markets_event_ticker_fkey;
+ALTER TABLE markets DROP CONSTRAINT IF EXISTS markets_pkey;
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_pkey;
+
+ALTER TABLE events ADD CONSTRAINT events_pkey PRIMARY KEY (exchange, event_ticker);
+ALTER TABLE markets ADD CONSTRAINT markets_pkey PRIMARY KEY (exchange, ticker);
+ALTER TABLE markets ADD CONSTRAINT markets_event_fkey
+    FOREIGN KEY (exchange, event_ticker) REFERENCES events (exchange, event_ticker);
+
+-- Ticker-only lookups (lifecycle updates, joins from other tables) stay indexed
+CREATE INDEX IF NOT EXISTS idx_events_event_ticker ON events (event_ticker);
+CREATE INDEX IF NOT EXISTS idx_markets_ticker ON markets (ticker);
+
+-- migrate:down
+
+-- Fails if the same ticker now exists on more than one exchange
+DROP INDEX IF EXISTS idx_markets_ticker;
+DROP INDEX IF EXISTS idx_events_event_ticker;
+
+ALTER TABLE markets DROP CONSTRAINT IF EXISTS markets_event_fkey;
+ALTER TABLE markets DROP CONSTRAINT IF EXISTS markets_pkey;
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_pkey;
+
+ALTER TABLE events ADD CONSTRAINT events_pkey PRIMARY KEY (event_ticker);
+ALTER TABLE markets ADD CONSTRAINT markets_pkey PRIMARY KEY (ticker);
+ALTER TABLE markets ADD CONSTRAINT markets_event_ticker_fkey
+    FOREIGN KEY (event_ticker) REFERENCES events (event_ticker);
+
+ALTER TABLE markets DROP COLUMN IF EXISTS exchange;
+ALTER TABLE events DROP COLUMN IF EXISTS exchange;
@@ -4,6 +4,7 @@
 import { kubectl, kubectlStream, getCurrentEnvDisplay, type KubectlOptions } from "../utils/kubectl.ts";
 import { getEnvContext } from "../utils/env-context.ts";
 import { getRawSql, closeDb } from "../../lib/db/mod.ts";
+import { dedupKeys } from "../../server/schema-versions.ts";
 
 interface ArchiverDeployFlags {
   _: (string | number)[];
@@ -62,7 +63,18 @@ export async function handleArchiverDeploy(
   }
 }
 
-const DEFAULT_IMAGE = "ghcr.io/aaronwald/ssmd-archiver:0.4.8";
+export const DEFAULT_IMAGE = "ghcr.io/aaronwald/ssmd-archiver:0.4.8";
+
+/**
+ * spec.dedupKeys lines for a feed's Archiver CR, from the dedup_key of each
+ * message type in the schema registry; empty when no type declares one.
+ */
+export function dedupKeysYaml(feed: string): string {
+  const keys = Object.entries(dedupKeys(feed));
+  if (keys.length === 0) return "";
+  const lines = keys.map(([type, fields]) => `    ${type}: [${fields.map((f) => JSON.stringify(f)).join(", ")}]\n`);
+  return `  dedupKeys:\n${lines.join("")}`;
+}
 
 async function newArchiver(flags: ArchiverDeployFlags, opts: KubectlOptions): Promise<void> {
   const name = flags._[2] as string;
@@ -130,7 +142,7 @@ spec:
       secretRef: gcs-credentials
   rotation:
     maxFileAge: "15m"
-  sync:
+${dedupKeysYaml("kalshi")}  sync:
     enabled: true
   resources:
     requests:
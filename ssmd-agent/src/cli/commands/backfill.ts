@@ -0,0 +1,351 @@
+// backfill.ts - Fetch historical Kalshi trades (and optionally candlesticks)
+// from the REST API and write them into the raw GCS archive layout, so replay
+// and parquet-gen treat them like captured data
+
+import { Storage } from "@google-cloud/storage";
+import { createKalshiClient, type KalshiCandlestick, type KalshiClient, type KalshiTrade } from "../../lib/api/kalshi.ts";
+import {
+  archiveDir,
+  archiveStream,
+  dateRange,
+  groupArchiveFiles,
+  loadArchiveManifest,
+  saveArchiveManifest,
+  toLines,
+  type ArchiveManifest,
+  type ManifestFile,
+  type StoredManifest,
+} from "../../lib/archive/mod.ts";
+
+interface BackfillFlags {
+  _: (string | number)[];
+  ticker?: string;
+  from?: string;
+  to?: string;
+  bucket?: string;
+  candles?: boolean;
+  "dry-run"?: boolean;
+}
+
+/** Longest range one run may request */
+const MAX_DAYS = 92;
+/** Archiver rotation; backfilled files use the same 15-minute slots */
+const ROTATION_MINUTES = 15;
+
+/** One archive line plus the keys needed to place and dedupe it */
+export interface ArchiveLine {
+  date: string;
+  slot: string;
+  /** Microseconds since epoch, as the archiver stamps _received_at */
+  receivedAt: number;
+  tradeId: string;
+  line: string;
+}
+
+/** Provenance for one backfill run, kept in the date's manifest */
+export interface BackfillRun {
+  source: "kalshi-rest";
+  endpoints: string[];
+  tickers: string[];
+  from: string;
+  to: string;
+  fetched_at: string;
+}
+
+/**
+ * ssmd-archiver manifest.json, plus source/provenance so backfilled dates
+ * can be told apart from live capture.
+ */
+export interface BackfillManifest extends ArchiveManifest {
+  source: "backfill";
+  provenance: BackfillRun[];
+}
+
+/** The dates a run covers (see dateRange); throws on bad input or too long a range */
+export function backfillDates(from: string, to: string): string[] {
+  const dates = dateRange(from, to);
+  if (dates.length > MAX_DAYS) throw new Error(`range is ${dates.length} days; at most ${MAX_DAYS} per run`);
+  return dates;
+}
+
+/** UTC date and 15-minute HHMM slot for a millisecond timestamp */
+export function archiveSlot(ms: number): { date: string; slot: string } {
+  const iso = new Date(ms).toISOString();
+  const minute = Math.floor(Number(iso.slice(14, 16)) / ROTATION_MINUTES) * ROTATION_MINUTES;
+  return { date: iso.slice(0, 10), slot: iso.slice(11, 13) + String(minute).padStart(2, "0") };
+}
+
+/**
+ * Encode a REST trade as the Kalshi WebSocket trade message the connector
+ * publishes, stamped with _received_at = trade time so replay keeps the
+ * original pacing. Returns null for trades with an unparseable timestamp.
+ */
+export function tradeToArchiveLine(trade: KalshiTrade): ArchiveLine | null {
+  const ms = Date.parse(trade.created_time);
+  if (Number.isNaN(ms)) return null;
+  const receivedAt = ms * 1000;
+  const message = {
+    type: "trade",
+    sid: 0,
+    msg: {
+      trade_id: trade.trade_id,
+      market_ticker: trade.ticker,
+      yes_price: trade.yes_price,
+      no_price: trade.no_price,
+      count: trade.count,
+      taker_side: trade.taker_side,
+      ts: Math.floor(ms / 1000),
+    },
+    _received_at: receivedAt,
+  };
+  return { ...archiveSlot(ms), receivedAt, tradeId: trade.trade_id, line: JSON.stringify(message) };
+}
+
+/** Parse a previously written backfill line back into an ArchiveLine */
+export function parseArchiveLine(line: string): ArchiveLine | null {
+  try {
+    const json = JSON.parse(line);
+    const tradeId = json?.msg?.trade_id;
+    if (typeof json._received_at !== "number" || typeof tradeId !== "string") return null;
+    return { ...archiveSlot(json._received_at / 1000), receivedAt: json._received_at, tradeId, line };
+  } catch {
+    return null;
+  }
+}
+
+/** Merge lines, dropping repeated trade ids, sorted by receive time */
+export function mergeArchiveLines(existing: ArchiveLine[], incoming: ArchiveLine[]): ArchiveLine[] {
+  const byId = new Map<string, ArchiveLine>();
+  for (const l of [...existing, ...incoming]) byId.set(l.tradeId, l);
+  return [...byId.values()].sort((a, b) => a.receivedAt - b.receivedAt);
+}
+
+/** Build the date's manifest from its slot files and all runs that touched it */
+export function buildManifest(
+  date: string,
+  slots: Map<string, ArchiveLine[]>,
+  /** Decompressed size per slot, as the archiver records it */
+  bytes: Map<string, number>,
+  runs: BackfillRun[],
+): BackfillManifest {
+  const tickers = new Set<string>();
+  const files: ManifestFile[] = [];
+  for (const [slot, lines] of [...slots.entries()].sort(([a], [b]) => a.localeCompare(b))) {
+    const slotTickers = new Set<string>();
+    for (const l of lines) slotTickers.add(JSON.parse(l.line).msg.market_ticker);
+    for (const t of slotTickers) tickers.add(t);
+    const start = new Date(lines[0].receivedAt / 1000).toISOString();
+    const end = new Date(lines[lines.length - 1].receivedAt / 1000).toISOString();
+    files.push({
+      name: `${slot}.jsonl.gz`,
+      start,
+      end,
+      records: lines.length,
+      bytes: bytes.get(slot) ?? 0,
+      nats_start_seq: 0,
+      nats_end_seq: 0,
+      records_by_type: { trade: lines.length },
+      min_ts: start,
+      max_ts: end,
+      ticker_count: slotTickers.size,
+    });
+  }
+  return {
+    feed: "kalshi",
+    date,
+    format: "jsonl",
+    rotation_interval: `${ROTATION_MINUTES}m`,
+    files,
+    gaps: [],
+    tickers: [...tickers].sort(),
+    message_types: files.length > 0 ? ["trade"] : [],
+    has_gaps: false,
+    source: "backfill",
+    provenance: runs,
+  };
+}
+
+async function gzip(text: string): Promise<Uint8Array> {
+  const stream = new Blob([text]).stream().pipeThrough(new CompressionStream("gzip"));
+  return new Uint8Array(await new Response(stream).arrayBuffer());
+}
+
+/** Series ticker for a market, via its event */
+async function seriesFor(client: KalshiClient, ticker: string): Promise<string> {
+  const market = await client.getMarket(ticker);
+  if (!market) throw new Error(`market ${ticker} not found`);
+  const event = await client.getEvent(market.event_ticker);
+  if (!event?.series_ticker) throw new Error(`no series for event ${market.event_ticker}`);
+  return event.series_ticker;
+}
+
+export async function handleBackfill(subcommand: string, flags: BackfillFlags): Promise<void> {
+  switch (subcommand) {
+    case "kalshi":
+      await backfillKalshi(flags);
+      break;
+    default:
+      if (subcommand) console.error(`Unknown backfill source: ${subcommand}`);
+      printBackfillHelp();
+      Deno.exit(1);
+  }
+}
+
+async function backfillKalshi(flags: BackfillFlags): Promise<void> {
+  const tickers = (flags.ticker ?? "").split(",").map((t) => t.trim()).filter(Boolean);
+  if (tickers.length === 0 || !flags.from || !flags.to) {
+    printBackfillHelp();
+    Deno.exit(1);
+  }
+  let dates: string[];
+  try {
+    dates = backfillDates(flags.from, flags.to);
+  } catch (err) {
+    console.error(`Error: ${(err as Error).message}`);
+    Deno.exit(1);
+  }
+  const bucket = flags.bucket ?? Deno.env.get("GCS_BUCKET");
+  if (!bucket && !flags["dry-run"]) {
+    console.error("Error: --bucket or GCS_BUCKET is required (or use --dry-run)");
+    Deno.exit(1);
+  }
+
+  const client = createKalshiClient();
+  const minTs = Date.parse(`${dates[0]}T00:00:00Z`) / 1000;
+  const maxTs = Date.parse(`${dates[dates.length - 1]}T00:00:00Z`) / 1000 + 86_400;
+  const run: BackfillRun = {
+    source: "kalshi-rest",
+    endpoints: flags.candles ? ["/markets/trades", "/series/{series}/markets/{ticker}/candlesticks"] : ["/markets/trades"],
+    tickers,
+    from: dates[0],
+    to: dates[dates.length - 1],
+    fetched_at: new Date().toISOString(),
+  };
+
+  // Fetch everything first so a failed fetch writes nothing
+  const byDate = new Map<string, ArchiveLine[]>();
+  const candles = new Map<string, Map<string, KalshiCandlestick[]>>();
+  for (const ticker of tickers) {
+    let count = 0;
+    for await (const batch of client.fetchTrades(ticker, minTs, maxTs - 1)) {
+      for (const trade of batch) {
+        const line = tradeToArchiveLine(trade);
+        if (!line) continue;
+        const lines = byDate.get(line.date) ?? [];
+        lines.push(line);
+        byDate.set(line.date, lines);
+        count++;
+      }
+    }
+    console.log(`${ticker}: ${count} trades`);
+
+    if (flags.candles) {
+      const series = await seriesFor(client, ticker);
+      const all = await client.fetchCandlesticks(series, ticker, minTs, maxTs, 1);
+      for (const c of all) {
+        // end_period_ts is exclusive; a candle belongs to the day it starts in
+        const { date } = archiveSlot((c.end_period_ts - 60) * 1000);
+        const perTicker = candles.get(date) ?? new Map<string, KalshiCandlestick[]>();
+        perTicker.set(ticker, [...(perTicker.get(ticker) ?? []), c]);
+        candles.set(date, perTicker);
+      }
+      console.log(`${ticker}: ${all.length} 1m candlesticks (series ${series})`);
+    }
+  }
+
+  const storage = bucket ? new Storage() : null;
+  let written = 0;
+  for (const date of dates) {
+    const incoming = byDate.get(date) ?? [];
+    const dayCandles = candles.get(date);
+    if (incoming.length === 0 && !dayCandles) continue;
+
+    const prefix = `${archiveDir("kalshi", date)}/`;
+    const dest = bucket ? `gs://${bucket}/${prefix}` : prefix;
+    let stored: StoredManifest | null = null;
+    const existing: ArchiveLine[] = [];
+
+    if (storage && bucket) {
+      stored = await loadArchiveManifest(storage, bucket, "kalshi", date);
+      const [objects] = await storage.bucket(bucket).getFiles({ prefix });
+      const slotFiles = groupArchiveFiles(objects.map((o: { name: string }) => o.name));
+      if (slotFiles.length > 0 && stored?.manifest.source !== "backfill") {
+        // Never mix REST data into a day the archiver captured live
+        console.log(`${date}: skipped, ${dest} holds live-captured data`);
+        continue;
+      }
+      for (const [, names] of slotFiles) {
+        for (const name of names) {
+          for await (const line of toLines(archiveStream(storage, bucket, name))) {
+            const parsed = parseArchiveLine(line);
+            if (parsed) existing.push(parsed);
+          }
+        }
+      }
+    }
+
+    const merged = mergeArchiveLines(existing, incoming);
+    const slots = new Map<string, ArchiveLine[]>();
+    for (const l of merged) {
+      const lines = slots.get(l.slot) ?? [];
+      lines.push(l);
+      slots.set(l.slot, lines);
+    }
+
+    const bytes = new Map<string, number>();
+    const uploads: [string, Uint8Array, string][] = [];
+    for (const [slot, lines] of slots) {
+      const text = lines.map((l) => l.line).join("\n") + "\n";
+      const body = await gzip(text);
+      bytes.set(slot, new TextEncoder().encode(text).length);
+      uploads.push([`${prefix}${slot}.jsonl.gz`, body, "application/gzip"]);
+    }
+    for (const [ticker, list] of dayCandles ?? []) {
+      const body = await gzip(list.map((c) => JSON.stringify({ ticker, ...c })).join("\n") + "\n");
+      uploads.push([`${prefix}candlesticks_${ticker}.jsonl.gz`, body, "application/gzip"]);
+    }
+    const next = buildManifest(date, slots, bytes, [...((stored?.manifest.provenance as BackfillRun[] | undefined) ?? []), run]);
+
+    const added = merged.length - existing.length;
+    const candleNote = dayCandles ? `, candlesticks for ${dayCandles.size} ticker(s)` : "";
+    if (!storage || !bucket || flags["dry-run"]) {
+      console.log(`${date}: would write ${slots.size} files (${added} new trades${candleNote}) to ${dest}`);
+      continue;
+    }
+    for (const [name, body, contentType] of uploads) {
+      await storage.bucket(bucket).file(name).save(body, { contentType });
+    }
+    // Fails if the archiver or another backfill wrote the manifest meanwhile
+    await saveArchiveManifest(storage, bucket, "kalshi", date, next, stored?.generation ?? null);
+    written += added;
+    console.log(`${date}: wrote ${slots.size} files (${added} new trades${candleNote}) to ${dest}`);
+  }
+
+  if (bucket && !flags["dry-run"]) {
+    console.log(`Backfill complete: ${written} new trades`);
+  }
+}
+
+function printBackfillHelp(): void {
+  console.log("Usage: ssmd backfill kalshi --ticker <ticker[,ticker...]> --from <date> --to <date> [options]");
+  console.log("");
+  console.log("Fetch historical trades from the Kalshi REST API into the raw archive");
+  console.log(`(${archiveDir("kalshi", "<date>")}/HHMM.jsonl.gz) as WebSocket-format trade`);
+  console.log("messages, with a manifest.json recording source and provenance.");
+  console.log("Dates already captured live are skipped; earlier backfills are merged.");
+  console.log("");
+  console.log("Options:");
+  console.log("  --ticker <tickers>   Market ticker(s), comma-separated");
+  console.log("  --from <date>        First UTC date (YYYY-MM-DD)");
+  console.log(`  --to <date>          Last UTC date, inclusive (at most ${MAX_DAYS} days per run)`);
+  console.log("  --candles            Also write 1-minute candlesticks (candlesticks_<ticker>.jsonl.gz)");
+  console.log("  --bucket <name>      GCS bucket (default: GCS_BUCKET)");
+  console.log("  --dry-run            Fetch and report, write nothing");
+  console.log("");
+  console.log("Environment:");
+  console.log("  KALSHI_RATE_BUDGET     Request budget as <requests>/<seconds> (default: 10/1)");
+  console.log("  RATE_BUDGET_REDIS_URL  Share the budget with concurrent secmaster syncs");
+  console.log("");
+  console.log("Example:");
+  console.log("  ssmd backfill kalshi --ticker KXBTCD-25JAN1017-T94999.99 --from 2025-01-01 --to 2025-01-31");
+}
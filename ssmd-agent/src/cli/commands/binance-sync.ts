@@ -4,6 +4,7 @@
 import { getDb, closeDb } from "../../lib/db/client.ts";
 import { upsertSpotPairs, softDeleteMissingPairs, updateBinanceUsTradeable, markKrakenUsTradeable } from "../../lib/db/pairs.ts";
 import type { NewPair } from "../../lib/db/schema.ts";
+import { HttpClient } from "../../lib/httpx/mod.ts";
 
 // Use data-api.binance.vision — api.binance.com returns 451 from US IPs in GKE
 const BINANCE_EXCHANGE_INFO_URL = "https://data-api.binance.vision/api/v3/exchangeInfo";
@@ -11,6 +12,9 @@ const BINANCE_EXCHANGE_INFO_URL = "https://data-api.binance.vision/api/v3/exchan
 const BINANCE_US_EXCHANGE_INFO_URL = "https://api.binance.us/api/v3/exchangeInfo";
 const API_TIMEOUT_MS = 30000;
 
+const binanceHttp = new HttpClient({ name: "Binance", timeoutMs: API_TIMEOUT_MS });
+const binanceUsHttp = new HttpClient({ name: "Binance.US", timeoutMs: API_TIMEOUT_MS });
+
 // --- Binance API response types ---
 
 interface BinanceExchangeInfo {
@@ -61,13 +65,7 @@ export async function runBinanceSync(
   const result: BinanceSyncResult = { fetched: 0, usdtPairs: 0, upserted: 0, deleted: 0 };
 
   console.log("\n[Binance Spot] Fetching exchange info...");
-  const res = await fetch(BINANCE_EXCHANGE_INFO_URL, {
-    signal: AbortSignal.timeout(API_TIMEOUT_MS),
-  });
-  if (!res.ok) {
-    throw new Error(`Binance API error: ${res.status} ${await res.text()}`);
-  }
-  const data: BinanceExchangeInfo = await res.json();
+  const data = await binanceHttp.getJson<BinanceExchangeInfo>(BINANCE_EXCHANGE_INFO_URL);
 
   result.fetched = data.symbols.length;
 
@@ -137,13 +135,7 @@ export async function runBinanceSync(
  */
 async function runSyncUs(dryRun: boolean): Promise<void> {
   console.log("\n[Binance.US] Fetching exchangeInfo...");
-  const res = await fetch(BINANCE_US_EXCHANGE_INFO_URL, {
-    signal: AbortSignal.timeout(API_TIMEOUT_MS),
-  });
-  if (!res.ok) {
-    throw new Error(`Binance.US API error: ${res.status} ${await res.text()}`);
-  }
-  const data: BinanceExchangeInfo = await res.json();
+  const data = await binanceUsHttp.getJson<BinanceExchangeInfo>(BINANCE_US_EXCHANGE_INFO_URL);
 
   const usSymbols = new Set<string>();
   for (const s of data.symbols) {
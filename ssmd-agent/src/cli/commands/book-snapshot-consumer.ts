@@ -0,0 +1,346 @@
+// ssmd-agent/src/cli/commands/book-snapshot-consumer.ts
+// Daemon that consumes orderbook messages from NATS, keeps each instrument's
+// book in memory and writes its top-N levels to book_snapshots on an interval
+
+import { parseArgs } from "https://deno.land/std@0.224.0/cli/parse_args.ts";
+import {
+  AckPolicy,
+  connect,
+  DeliverPolicy,
+  Events,
+  type NatsConnection,
+  StringCodec,
+} from "npm:nats";
+import {
+  cleanupOldBookSnapshots,
+  closeDb,
+  getDb,
+  insertBookSnapshots,
+  type NewBookSnapshot,
+} from "../../lib/db/mod.ts";
+import { applyBookMessage, BOOK_FEEDS, type BookStore, topLevels } from "../../lib/types/orderbook.ts";
+import { MetricsRegistry } from "../../server/metrics.ts";
+
+const sc = StringCodec();
+
+function log(message: string): void {
+  console.log(`${new Date().toISOString()} ${message}`);
+}
+
+function logWarn(message: string): void {
+  console.warn(`${new Date().toISOString()} WARN ${message}`);
+}
+
+function logError(message: string): void {
+  console.error(`${new Date().toISOString()} ERROR ${message}`);
+}
+
+interface ConsumerConfig {
+  natsUrl: string;
+  stream: string;
+  filter: string;
+  feed: string;
+  consumerName: string;
+  snapshotIntervalMs: number;
+  depth: number;
+  cleanupIntervalMs: number;
+  retentionDays: number;
+  metricsPort: number;
+}
+
+function loadConfig(): ConsumerConfig {
+  return {
+    natsUrl: Deno.env.get("NATS_URL") ?? "nats://localhost:4222",
+    stream: Deno.env.get("NATS_STREAM") ?? "PROD_KALSHI",
+    filter: Deno.env.get("NATS_FILTER") ?? "prod.kalshi.json.orderbook.>",
+    feed: Deno.env.get("FEED") ?? "kalshi",
+    consumerName: Deno.env.get("CONSUMER_NAME") ?? "book-snapshot-consumer",
+    snapshotIntervalMs: parseInt(Deno.env.get("SNAPSHOT_INTERVAL_MS") ?? "60000", 10), // 1 min
+    depth: parseInt(Deno.env.get("BOOK_DEPTH") ?? "10", 10),
+    cleanupIntervalMs: parseInt(Deno.env.get("CLEANUP_INTERVAL_MS") ?? "3600000", 10), // 1 hr
+    retentionDays: parseInt(Deno.env.get("RETENTION_DAYS") ?? "7", 10),
+    metricsPort: parseInt(Deno.env.get("METRICS_PORT") ?? "9090", 10),
+  };
+}
+
+const metrics = new MetricsRegistry();
+const messagesProcessedMetric = metrics.counter(
+  "ssmd_book_snapshot_messages_total",
+  "Total orderbook messages applied",
+);
+const snapshotsWrittenMetric = metrics.counter(
+  "ssmd_book_snapshot_rows_total",
+  "Total book snapshot rows written to DB",
+);
+const booksTrackedMetric = metrics.gauge(
+  "ssmd_book_snapshot_books_tracked",
+  "Number of books held in memory",
+);
+const lastFlushTimestampMetric = metrics.gauge(
+  "ssmd_book_snapshot_last_flush_timestamp",
+  "Unix timestamp of last successful flush",
+);
+const consumerConnectedMetric = metrics.gauge(
+  "ssmd_book_snapshot_connected",
+  "NATS consumer connected (1=yes, 0=no)",
+);
+const flushErrorsMetric = metrics.counter(
+  "ssmd_book_snapshot_flush_errors_total",
+  "Total flush errors",
+);
+
+/**
+ * Snapshot rows for the books updated after `since` (epoch millis). Books
+ * that did not change are not rewritten: getBookAt returns the latest
+ * snapshot at or before a time, which still describes them.
+ */
+export function snapshotRows(store: BookStore, depth: number, since: number): NewBookSnapshot[] {
+  const rows: NewBookSnapshot[] = [];
+  for (const book of store.values()) {
+    if (book.updatedAt <= since) continue;
+    const { bids, asks } = topLevels(book, depth);
+    rows.push({
+      feed: book.feed,
+      ticker: book.ticker,
+      depth,
+      bids,
+      asks,
+      exchangeTs: book.exchangeTs !== null ? new Date(book.exchangeTs) : null,
+    });
+  }
+  return rows;
+}
+
+/**
+ * Main book snapshot consumer daemon
+ */
+export async function runBookSnapshotConsumer(args: string[] = Deno.args): Promise<void> {
+  const flags = parseArgs(args, {
+    boolean: ["help"],
+    alias: { h: "help" },
+  });
+
+  if (flags.help) {
+    console.log(`
+SSMD Book Snapshot Consumer - Consume orderbook messages from NATS and write
+top-N book levels to the book_snapshots table on an interval.
+
+Supported feeds: kalshi (orderbook_snapshot/orderbook_delta), polymarket (book),
+kraken-futures (book_snapshot/book).
+
+Environment variables:
+  DATABASE_URL          PostgreSQL connection string (required)
+  NATS_URL              NATS server URL (default: nats://localhost:4222)
+  NATS_STREAM           JetStream stream name (default: PROD_KALSHI)
+  NATS_FILTER           Subject filter (default: prod.kalshi.json.orderbook.>)
+  FEED                  Feed of the messages (default: kalshi)
+  CONSUMER_NAME         Durable consumer name (default: book-snapshot-consumer)
+  SNAPSHOT_INTERVAL_MS  Snapshot interval in ms (default: 60000 = 1 min)
+  BOOK_DEPTH            Levels per side to store (default: 10)
+  CLEANUP_INTERVAL_MS   Old snapshot cleanup interval in ms (default: 3600000 = 1 hr)
+  RETENTION_DAYS        Days of snapshot retention (default: 7)
+  METRICS_PORT          HTTP metrics/health port (default: 9090)
+`);
+    return;
+  }
+
+  log("=== SSMD Book Snapshot Consumer ===");
+
+  const config = loadConfig();
+  log(`NATS: ${config.natsUrl}`);
+  log(`Stream: ${config.stream}`);
+  log(`Filter: ${config.filter}`);
+  log(`Feed: ${config.feed}`);
+  log(`Snapshot interval: ${config.snapshotIntervalMs}ms, depth ${config.depth}`);
+  log(`Retention: ${config.retentionDays} days`);
+
+  if (!BOOK_FEEDS.includes(config.feed)) {
+    logError(`FEED must be one of ${BOOK_FEEDS.join(", ")}, got ${config.feed}`);
+    Deno.exit(1);
+  }
+  if (!Number.isInteger(config.depth) || config.depth < 1) {
+    logError(`BOOK_DEPTH must be a positive integer, got ${Deno.env.get("BOOK_DEPTH")}`);
+    Deno.exit(1);
+  }
+  if (!Deno.env.get("DATABASE_URL")) {
+    logError("DATABASE_URL environment variable not set");
+    Deno.exit(1);
+  }
+
+  const db = getDb();
+  log("Database connected");
+
+  let nc: NatsConnection;
+  try {
+    nc = await connect({
+      servers: config.natsUrl,
+      reconnect: true,
+      maxReconnectAttempts: -1,
+      reconnectTimeWait: 2000,
+      pingInterval: 30000,
+      maxPingOut: 3,
+    });
+    log("NATS connected");
+    consumerConnectedMetric.set({}, 1);
+  } catch (e) {
+    logError(`Failed to connect to NATS: ${e}`);
+    await closeDb();
+    Deno.exit(1);
+  }
+
+  let natsConnected = true;
+  (async () => {
+    for await (const status of nc.status()) {
+      switch (status.type) {
+        case Events.Disconnect:
+          natsConnected = false;
+          consumerConnectedMetric.set({}, 0);
+          logWarn("NATS disconnected");
+          break;
+        case Events.Reconnect:
+          natsConnected = true;
+          consumerConnectedMetric.set({}, 1);
+          log(`NATS reconnected to ${status.data}`);
+          break;
+        case Events.Error:
+          logError(`NATS error: ${status.data}`);
+          break;
+      }
+    }
+  })().catch(() => {});
+
+  const js = nc.jetstream();
+  const jsm = await nc.jetstreamManager();
+
+  // Books are rebuilt from each instrument's next snapshot, so start from new messages
+  try {
+    await jsm.consumers.add(config.stream, {
+      durable_name: config.consumerName,
+      filter_subject: config.filter,
+      ack_policy: AckPolicy.Explicit,
+      deliver_policy: DeliverPolicy.New,
+    });
+    log(`Created durable consumer: ${config.consumerName}`);
+  } catch (e) {
+    if (!String(e).includes("already exists")) {
+      logError(`Failed to create consumer: ${e}`);
+    }
+  }
+
+  const consumer = await js.consumers.get(config.stream, config.consumerName);
+  log("Consumer ready, starting message consumption...");
+
+  const books: BookStore = new Map();
+  let messagesProcessed = 0;
+  let rowsWritten = 0;
+  let flushCount = 0;
+  let lastFlushAt = Date.now();
+  const startTime = Date.now();
+
+  async function flushSnapshots(): Promise<void> {
+    const flushStarted = Date.now();
+    const rows = snapshotRows(books, config.depth, lastFlushAt);
+    try {
+      const count = await insertBookSnapshots(db, rows);
+      rowsWritten += count;
+      flushCount++;
+      lastFlushAt = flushStarted;
+      log(`[flush #${flushCount}] Wrote ${count} book snapshots (total: ${rowsWritten})`);
+      snapshotsWrittenMetric.inc({}, count);
+      lastFlushTimestampMetric.set({}, flushStarted / 1000);
+      booksTrackedMetric.set({}, books.size);
+    } catch (e) {
+      logError(`Failed to flush book snapshots: ${e}`);
+      flushErrorsMetric.inc();
+    }
+  }
+
+  const flushTimer = setInterval(async () => {
+    await flushSnapshots();
+  }, config.snapshotIntervalMs);
+
+  const cleanupTimer = setInterval(async () => {
+    try {
+      const cleaned = await cleanupOldBookSnapshots(config.retentionDays);
+      if (cleaned > 0) log(`[cleanup] Removed ${cleaned} old book snapshots`);
+    } catch (e) {
+      logError(`Cleanup failed: ${e}`);
+    }
+  }, config.cleanupIntervalMs);
+
+  let shuttingDown = false;
+  const shutdown = async () => {
+    if (shuttingDown) return;
+    shuttingDown = true;
+
+    log("Shutting down...");
+    clearInterval(flushTimer);
+    clearInterval(cleanupTimer);
+    consumerConnectedMetric.set({}, 0);
+    await metricsServer.shutdown();
+
+    await flushSnapshots();
+
+    log(`Messages processed: ${messagesProcessed}`);
+    log(`Book snapshots written: ${rowsWritten}`);
+    log(`Books tracked: ${books.size}`);
+    log(`Runtime: ${Math.round((Date.now() - startTime) / 1000)}s`);
+
+    await nc.drain();
+    await nc.close();
+    await closeDb();
+    Deno.exit(0);
+  };
+
+  Deno.addSignalListener("SIGINT", shutdown);
+  Deno.addSignalListener("SIGTERM", shutdown);
+
+  const metricsServer = Deno.serve(
+    { port: config.metricsPort, hostname: "0.0.0.0" },
+    (req) => {
+      const url = new URL(req.url);
+      if (url.pathname === "/health") {
+        const flushAge = (Date.now() - lastFlushAt) / 1000;
+        const healthy = natsConnected && flushAge < config.snapshotIntervalMs / 1000 * 2;
+        return new Response(
+          JSON.stringify({ status: healthy ? "ok" : "unhealthy", natsConnected, flushAgeSec: Math.round(flushAge) }),
+          { status: healthy ? 200 : 503, headers: { "content-type": "application/json" } },
+        );
+      }
+      if (url.pathname === "/ready") {
+        const ready = !shuttingDown;
+        return new Response(
+          JSON.stringify({ status: ready ? "ok" : "not_ready" }),
+          { status: ready ? 200 : 503, headers: { "content-type": "application/json" } },
+        );
+      }
+      if (url.pathname === "/metrics") {
+        return new Response(metrics.format(), {
+          headers: { "content-type": "text/plain; charset=utf-8" },
+        });
+      }
+      return new Response("Not Found", { status: 404 });
+    },
+  );
+  log(`Metrics server listening on :${config.metricsPort}`);
+
+  const messages = await consumer.consume();
+  for await (const msg of messages) {
+    try {
+      const raw = JSON.parse(sc.decode(msg.data));
+      if (applyBookMessage(books, config.feed, raw)) {
+        messagesProcessed++;
+        messagesProcessedMetric.inc();
+      }
+      msg.ack();
+    } catch (e) {
+      // Unparseable messages would fail again on redelivery
+      logError(`Error processing message on ${msg.subject}: ${e}`);
+      msg.term();
+    }
+  }
+}
+
+if (import.meta.main) {
+  await runBookSnapshotConsumer();
+}
@@ -0,0 +1,84 @@
+// Commit command: validate exchanges/, refresh ssmd.lock and commit it with a
+// summary of changed entities
+// ssmd commit -m <message> [--sign] [--allow-invalid]
+import { findExchangesRoot } from "../utils/paths.ts";
+import { git } from "../utils/git.ts";
+import { CONFIG_KINDS, type ConfigDiff, diffSnapshots, isEmptyDiff, loadGitRef, loadWorkingTree } from "./diff.ts";
+import { LOCK_FILE, writeLock } from "./lock.ts";
+import { printIssues, validateWorkspace } from "./validate.ts";
+
+/** Trailer holding the JSON change summary; `git log --format=%(trailers:key=Ssmd-Changes)` reads it back */
+export const CHANGES_TRAILER = "Ssmd-Changes";
+
+interface CommitFlags {
+  message?: string;
+  sign?: boolean;
+  "allow-invalid"?: boolean;
+}
+
+/** Entity names per kind, without field-level detail, for the commit trailer */
+export function changeSummary(diff: ConfigDiff): Record<string, { added: string[]; removed: string[]; changed: string[] }> {
+  return Object.fromEntries(CONFIG_KINDS.map((kind) => [kind, {
+    added: diff[kind].added,
+    removed: diff[kind].removed,
+    changed: diff[kind].changed.map((c) => c.name),
+  }]));
+}
+
+/**
+ * Commit message: the subject, one human-readable line per kind that changed,
+ * and a JSON trailer with the same summary.
+ */
+export function buildCommitMessage(subject: string, diff: ConfigDiff): string {
+  const lines = [subject.trim(), ""];
+  for (const kind of CONFIG_KINDS) {
+    const d = diff[kind];
+    const parts = [
+      ...d.added.map((n) => `+${n}`),
+      ...d.removed.map((n) => `-${n}`),
+      ...d.changed.map((c) => `~${c.name}`),
+    ];
+    if (parts.length > 0) {
+      lines.push(`${kind}: ${parts.join(" ")}`);
+    }
+  }
+  lines.push("", `${CHANGES_TRAILER}: ${JSON.stringify(changeSummary(diff))}`);
+  return lines.join("\n") + "\n";
+}
+
+export async function handleCommit(flags: CommitFlags): Promise<void> {
+  if (!flags.message) {
+    console.error("Usage: ssmd commit -m <message> [--sign] [--allow-invalid]");
+    Deno.exit(1);
+  }
+
+  const root = await findExchangesRoot();
+
+  // Validation includes schema hash checks against HEAD, so an edited schema
+  // version is caught before it is recorded.
+  const issues = await validateWorkspace(root, "HEAD");
+  if (issues.length > 0) {
+    printIssues(issues);
+  }
+  if (issues.some((i) => i.severity === "error") && !flags["allow-invalid"]) {
+    console.error("\nValidation failed; fix the errors above or pass --allow-invalid");
+    Deno.exit(1);
+  }
+
+  const diff = diffSnapshots(await loadGitRef(root, "HEAD"), await loadWorkingTree(root));
+  if (isEmptyDiff(diff)) {
+    console.log("No changes to feeds, schemas or environments");
+    return;
+  }
+
+  // The reviewed config is what gets committed, so the lock records it
+  await writeLock(root);
+  console.log(`Updated ${LOCK_FILE}`);
+  await git(["add", "-A", "--", "."], root);
+  const args = ["commit", "-F", "-"];
+  if (flags.sign) {
+    args.push("-S");
+  }
+  args.push("--", ".");
+  console.log(await git(args, root, buildCommitMessage(flags.message, diff)));
+}
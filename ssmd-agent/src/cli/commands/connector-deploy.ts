@@ -53,7 +53,7 @@ export async function handleConnectorDeploy(
   }
 }
 
-const DEFAULT_IMAGE = "ghcr.io/aaronwald/ssmd-connector:0.5.5";
+export const DEFAULT_IMAGE = "ghcr.io/aaronwald/ssmd-connector:0.5.5";
 
 async function newConnector(flags: ConnectorDeployFlags, opts: KubectlOptions): Promise<void> {
   const name = flags._[2] as string;
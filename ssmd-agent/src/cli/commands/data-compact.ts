@@ -0,0 +1,51 @@
+/**
+ * ssmd data compact - merge a day's archive into ticker-partitioned parquet
+ * (see lib/archive/compact.ts)
+ */
+import { Storage } from "@google-cloud/storage";
+import { DuckDBInstance } from "@duckdb/node-api";
+import { archiveDir, compactArchive, COMPACTED_DIR, TICKER_INDEX_FILE } from "../../lib/archive/mod.ts";
+import { configureDuckDBLimits } from "./hols-duckdb.ts";
+import { dedupKeys } from "../../server/schema-versions.ts";
+import type { ArchiveTarget } from "./data.ts";
+
+export interface CompactFlags {
+  "delete-originals"?: boolean;
+  dedup?: boolean;
+  "dry-run"?: boolean;
+  json?: boolean;
+}
+
+export async function runCompact({ feed, date, bucket }: ArchiveTarget, flags: CompactFlags): Promise<void> {
+  const instance = await DuckDBInstance.create();
+  const conn = await instance.connect();
+  await configureDuckDBLimits(conn);
+  const report = await compactArchive(new Storage(), conn, bucket, feed, date, {
+    deleteOriginals: flags["delete-originals"],
+    dedup: flags.dedup,
+    keys: dedupKeys(feed),
+    dryRun: flags["dry-run"],
+  });
+  if (!report) {
+    console.error(`No manifest.json at gs://${bucket}/${archiveDir(feed, date)}/`);
+    Deno.exit(2);
+  }
+
+  if (flags.json) {
+    console.log(JSON.stringify(report, null, 2));
+    return;
+  }
+  const bytes = report.files.reduce((n, f) => n + f.bytes, 0);
+  console.log(
+    `${report.sources.length} files, ${report.records} records -> ${report.files.length} parquet partitions (${bytes} bytes)`,
+  );
+  if (flags.dedup) console.log(`Left out ${report.duplicates} duplicate lines`);
+  const path = `gs://${bucket}/${archiveDir(feed, date)}/${COMPACTED_DIR}/`;
+  if (!report.uploaded) {
+    console.log(`Dry run: nothing written to ${path}`);
+    return;
+  }
+  console.log(`Wrote ${path} and updated manifest.json`);
+  if (report.indexed) console.log(`Wrote ${archiveDir(feed, date)}/${TICKER_INDEX_FILE}`);
+  if (report.deleted > 0) console.log(`Deleted ${report.deleted} original JSONL files`);
+}
@@ -0,0 +1,41 @@
+/**
+ * ssmd data dedup - count duplicate messages per archive file, and
+ * optionally rewrite files without them (see lib/archive/dedup.ts)
+ */
+import { Storage } from "@google-cloud/storage";
+import { archiveDir, dedupArchive } from "../../lib/archive/mod.ts";
+import { dedupKeys } from "../../server/schema-versions.ts";
+import type { ArchiveTarget } from "./data.ts";
+
+export interface DedupFlags {
+  rewrite?: boolean;
+  "dry-run"?: boolean;
+  json?: boolean;
+}
+
+export async function runDedup({ feed, date, bucket }: ArchiveTarget, flags: DedupFlags): Promise<void> {
+  const report = await dedupArchive(new Storage(), bucket, feed, date, {
+    rewrite: flags.rewrite,
+    keys: dedupKeys(feed),
+    dryRun: flags["dry-run"],
+    onFile: flags.json ? undefined : (f) => {
+      const note = f.rewritten ? "  rewritten" : "";
+      console.log(
+        `  ${f.name.padEnd(20)} ${String(f.records).padStart(9)} records  ${String(f.duplicates).padStart(7)} duplicates${note}`,
+      );
+    },
+  });
+
+  if (flags.json) {
+    console.log(JSON.stringify(report, null, 2));
+    return;
+  }
+  if (report.files.length === 0) {
+    console.log(`No archived JSONL files at gs://${bucket}/${archiveDir(feed, date)}/`);
+    return;
+  }
+  const withDuplicates = report.files.filter((f) => f.duplicates > 0).length;
+  console.log(`\n${report.duplicates} duplicates in ${withDuplicates} of ${report.files.length} files.`);
+  if (report.written) console.log("Rewrote files and updated manifest.json");
+  else if (flags.rewrite && report.duplicates > 0) console.log("Dry run: nothing rewritten.");
+}
@@ -0,0 +1,172 @@
+/**
+ * ssmd data diff - compare two archived days of a feed: manifest totals and
+ * per-ticker trade counts/volume (see lib/archive/diff.ts)
+ */
+import { Storage } from "@google-cloud/storage";
+import {
+  archiveDir,
+  diffManifests,
+  diffTickers,
+  fractionalChange,
+  loadArchiveManifest,
+  summarizeManifest,
+  type TickerDiff,
+  type TickerStats,
+} from "../../lib/archive/mod.ts";
+import { closeDuckDB, initDuckDB, query } from "../../lib/duckdb/mod.ts";
+import { buildTradeSQL } from "../../lib/duckdb/queries.ts";
+import type { ArchiveTarget } from "./data.ts";
+
+export interface DiffFlags {
+  _: (string | number)[];
+  threshold?: string;
+  "min-trades"?: string;
+  limit?: string;
+  json?: boolean;
+}
+
+export const DIFF_USAGE =
+  "ssmd data diff <feed> <date1> <date2> [--threshold 0.5] [--min-trades N] [--limit N] [--json]";
+
+/** Default fraction a count must fall by to be flagged */
+const DEFAULT_THRESHOLD = 0.5;
+/** Tickers with fewer trades on the first day are too noisy to flag */
+const DEFAULT_MIN_TRADES = 10;
+/** Rows printed per section */
+const DEFAULT_LIMIT = 20;
+/** Upper bound on tickers read per day */
+const MAX_TICKERS = 1_000_000;
+
+/** Per-ticker trade counts and volume for a day, from its trade parquet */
+async function tickerStats(bucket: string, feed: string, date: string): Promise<TickerStats[]> {
+  const result = await query(buildTradeSQL(bucket, feed, date, MAX_TICKERS));
+  return result.rows.map((row) => ({
+    ticker: String(row.ticker),
+    trades: Number(row.trade_count ?? 0),
+    volume: Number(row.total_volume ?? 0),
+  }));
+}
+
+function formatChange(change: number | null): string {
+  if (change === null) return "new";
+  const pct = (change * 100).toFixed(1);
+  return change > 0 ? `+${pct}%` : `${pct}%`;
+}
+
+function parseFraction(name: string, value: string | undefined, fallback: number): number {
+  if (value === undefined) return fallback;
+  const n = Number(value);
+  if (!(n > 0 && n <= 1)) {
+    console.error(`--${name} must be a fraction in (0, 1], got '${value}'`);
+    Deno.exit(2);
+  }
+  return n;
+}
+
+function parseCount(name: string, value: string | undefined, fallback: number): number {
+  if (value === undefined) return fallback;
+  const n = Number(value);
+  if (!Number.isInteger(n) || n < 0) {
+    console.error(`--${name} must be a non-negative integer, got '${value}'`);
+    Deno.exit(2);
+  }
+  return n;
+}
+
+export async function runDiff({ feed, date: before, bucket }: ArchiveTarget, flags: DiffFlags): Promise<void> {
+  const after = flags._[4] as string | undefined;
+  if (!after) {
+    console.error(`Usage: ${DIFF_USAGE}`);
+    Deno.exit(2);
+  }
+  if (!/^\d{4}-\d{2}-\d{2}$/.test(after)) {
+    console.error(`Invalid date: ${after} (expected YYYY-MM-DD)`);
+    Deno.exit(2);
+  }
+  const threshold = parseFraction("threshold", flags.threshold, DEFAULT_THRESHOLD);
+  const minTrades = parseCount("min-trades", flags["min-trades"], DEFAULT_MIN_TRADES);
+  const limit = parseCount("limit", flags.limit, DEFAULT_LIMIT);
+
+  const storage = new Storage();
+  const [manifestBefore, manifestAfter] = await Promise.all([
+    loadArchiveManifest(storage, bucket, feed, before),
+    loadArchiveManifest(storage, bucket, feed, after),
+  ]);
+  for (const [date, stored] of [[before, manifestBefore], [after, manifestAfter]] as const) {
+    if (!stored) console.warn(`No manifest.json at gs://${bucket}/${archiveDir(feed, date)}/`);
+  }
+  const manifestDiff = diffManifests(
+    summarizeManifest(manifestBefore?.manifest ?? null),
+    summarizeManifest(manifestAfter?.manifest ?? null),
+    threshold,
+  );
+
+  await initDuckDB({ quiet: true });
+  let tickers: TickerDiff;
+  let tickerCounts: { before: number; after: number };
+  try {
+    const [statsBefore, statsAfter] = await Promise.all([
+      tickerStats(bucket, feed, before),
+      tickerStats(bucket, feed, after),
+    ]);
+    tickers = diffTickers(statsBefore, statsAfter, { threshold, minTrades });
+    tickerCounts = { before: statsBefore.length, after: statsAfter.length };
+  } catch (err) {
+    console.error(`Trade query failed: ${(err as Error).message}`);
+    Deno.exit(1);
+  } finally {
+    await closeDuckDB();
+  }
+
+  const anomalous = manifestDiff.some((d) => d.anomalous) || tickers.missing.length > 0 || tickers.drops.length > 0;
+
+  if (flags.json) {
+    console.log(JSON.stringify({ feed, before, after, threshold, min_trades: minTrades, manifest: manifestDiff, tickers }, null, 2));
+    if (anomalous) Deno.exit(1);
+    return;
+  }
+
+  console.log(`${feed}: ${before} -> ${after}\n`);
+  const metricWidth = Math.max(6, ...manifestDiff.map((d) => d.metric.length));
+  console.log("METRIC".padEnd(metricWidth) + "  " + before.padStart(14) + "  " + after.padStart(14) + "  " + "CHANGE".padStart(8));
+  for (const d of manifestDiff) {
+    console.log(
+      d.metric.padEnd(metricWidth) + "  " +
+        d.before.toLocaleString().padStart(14) + "  " +
+        d.after.toLocaleString().padStart(14) + "  " +
+        formatChange(d.change).padStart(8) +
+        (d.anomalous ? "  !" : ""),
+    );
+  }
+  console.log(
+    "tickers".padEnd(metricWidth) + "  " +
+      tickerCounts.before.toLocaleString().padStart(14) + "  " +
+      tickerCounts.after.toLocaleString().padStart(14) + "  " +
+      formatChange(fractionalChange(tickerCounts.before, tickerCounts.after)).padStart(8),
+  );
+
+  if (tickers.missing.length > 0) {
+    console.log(`\nMissing on ${after} (${tickers.missing.length}, traded >= ${minTrades} times on ${before}):`);
+    for (const t of tickers.missing.slice(0, limit)) {
+      console.log(`  ${t.ticker.padEnd(40)} ${t.trades.toLocaleString().padStart(10)} trades  ${t.volume.toLocaleString()} volume`);
+    }
+  }
+  if (tickers.drops.length > 0) {
+    console.log(`\nTrades down ${Math.round(threshold * 100)}% or more (${tickers.drops.length}):`);
+    for (const d of tickers.drops.slice(0, limit)) {
+      console.log(
+        `  ${d.ticker.padEnd(40)} ${d.before.trades.toLocaleString().padStart(10)} -> ${d.after.trades.toLocaleString().padEnd(10)} ` +
+          `${formatChange(d.change).padStart(8)}  volume ${d.before.volume.toLocaleString()} -> ${d.after.volume.toLocaleString()}`,
+      );
+    }
+  }
+  if (tickers.added.length > 0) {
+    console.log(`\nNew on ${after}: ${tickers.added.length} tickers`);
+  }
+
+  if (anomalous) {
+    console.log("\nAnomalies found");
+    Deno.exit(1);
+  }
+  console.log("\nNo anomalies");
+}
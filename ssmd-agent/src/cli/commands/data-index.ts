@@ -0,0 +1,45 @@
+/**
+ * ssmd data index - build a day's per-ticker byte-range index for sampling
+ * (see lib/archive/ticker-index.ts)
+ */
+import { Storage } from "@google-cloud/storage";
+import {
+  archiveDir,
+  buildTickerIndex,
+  DEFAULT_MERGE_GAP_BYTES,
+  saveTickerIndex,
+  tickerIndexPath,
+} from "../../lib/archive/mod.ts";
+import type { ArchiveTarget } from "./data.ts";
+
+export interface IndexFlags {
+  "merge-gap"?: string;
+  "dry-run"?: boolean;
+}
+
+export async function runIndex({ feed, date, bucket }: ArchiveTarget, flags: IndexFlags): Promise<void> {
+  const mergeGap = flags["merge-gap"] !== undefined ? Number(flags["merge-gap"]) : DEFAULT_MERGE_GAP_BYTES;
+  if (!Number.isInteger(mergeGap) || mergeGap < 0) {
+    console.error(`--merge-gap must be a non-negative integer, got '${flags["merge-gap"]}'`);
+    Deno.exit(2);
+  }
+
+  const storage = new Storage();
+  console.log(`Indexing ${feed} ${date}`);
+  const index = await buildTickerIndex(storage, bucket, feed, date, {
+    mergeGap,
+    onFile: (name, file) => console.log(`  ${name}: ${file.lines} lines, ${Object.keys(file.tickers).length} tickers`),
+  });
+  if (!index) {
+    console.error(`No archived JSONL files at gs://${bucket}/${archiveDir(feed, date)}/`);
+    Deno.exit(2);
+  }
+
+  const path = `gs://${bucket}/${tickerIndexPath(feed, date)}`;
+  if (flags["dry-run"]) {
+    console.log(`\nDry run: would write ${path}`);
+    return;
+  }
+  await saveTickerIndex(storage, bucket, index);
+  console.log(`\nWrote ${path}`);
+}
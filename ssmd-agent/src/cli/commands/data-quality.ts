@@ -0,0 +1,208 @@
+/**
+ * ssmd data quality - capture quality of a day's archive or a live NATS
+ * window against the previous days' reports, with Notifier alerts
+ * (see lib/quality)
+ */
+import { Storage } from "@google-cloud/storage";
+import { connect, type NatsConnection, StringCodec } from "npm:nats";
+import { archiveDir, newDedupWindow } from "../../lib/archive/mod.ts";
+import { LIVE_TAIL_PREFIXES, parseTailSubject } from "../../lib/nats/mod.ts";
+import {
+  archiveQualityReport,
+  buildBaseline,
+  DEFAULT_ANOMALY_OPTIONS,
+  detectAnomalies,
+  loadQualityReport,
+  observeMessage,
+  previousDates,
+  QUALITY_REPORT_FILE,
+  QualityAccumulator,
+  qualityAlert,
+  qualityAlertSubject,
+  type QualityAnomaly,
+  type QualityReport,
+  qualityReportPath,
+  saveQualityReport,
+} from "../../lib/quality/mod.ts";
+import { dedupKeys } from "../../server/schema-versions.ts";
+import type { ArchiveTarget } from "./data.ts";
+import { parseDuration } from "./markets.ts";
+
+export interface QualityFlags {
+  _: (string | number)[];
+  live?: boolean;
+  window?: string;
+  "baseline-days"?: string;
+  threshold?: string;
+  limit?: string;
+  save?: boolean;
+  publish?: boolean;
+  "nats-url"?: string;
+  json?: boolean;
+}
+
+export const QUALITY_USAGE = "ssmd data quality <feed> <date> [--baseline-days 7] [--save] [--publish] [--json]\n" +
+  "       ssmd data quality <feed> --live [--window 5m] [--publish] [--json]";
+
+const DEFAULT_BASELINE_DAYS = 7;
+const DEFAULT_LIVE_WINDOW = "5m";
+/** Anomalies printed */
+const DEFAULT_LIMIT = 20;
+
+async function liveReport(nc: NatsConnection, feed: string, windowMs: number): Promise<QualityReport> {
+  const acc = new QualityAccumulator(feed);
+  const window = newDedupWindow(feed, dedupKeys(feed));
+  const sc = StringCodec();
+  const start = Date.now();
+  const sub = nc.subscribe(`${LIVE_TAIL_PREFIXES[feed]}.>`);
+  const timer = setTimeout(() => sub.unsubscribe(), windowMs);
+  try {
+    for await (const msg of sub) {
+      const parsed = parseTailSubject(msg.subject);
+      if (!parsed) continue;
+      let json: Record<string, unknown>;
+      try {
+        json = JSON.parse(sc.decode(msg.data));
+      } catch {
+        continue;
+      }
+      observeMessage(acc, json, Date.now(), parsed.ticker, window);
+    }
+  } finally {
+    clearTimeout(timer);
+  }
+  return acc.report("live", start, Date.now());
+}
+
+function parsePositive(name: string, value: string | undefined, fallback: number, integer: boolean): number {
+  if (value === undefined) return fallback;
+  const n = Number(value);
+  if (!(n > 0) || (integer && !Number.isInteger(n))) {
+    console.error(`--${name} must be a positive ${integer ? "integer" : "number"}, got '${value}'`);
+    Deno.exit(2);
+  }
+  return n;
+}
+
+function formatValue(v: number | null): string {
+  if (v === null) return "-";
+  return Math.abs(v) >= 100 ? v.toFixed(0) : v.toFixed(2);
+}
+
+function printAnomalies(anomalies: QualityAnomaly[], limit: number): void {
+  const width = Math.max(6, ...anomalies.slice(0, limit).map((a) => a.ticker.length));
+  console.log(
+    "TICKER".padEnd(width) + "  " + "METRIC".padEnd(18) + "  " + "VALUE".padStart(10) + "  " +
+      "BASELINE".padStart(10) + "  " + "Z".padStart(7) + "  SEVERITY",
+  );
+  for (const a of anomalies.slice(0, limit)) {
+    console.log(
+      a.ticker.padEnd(width) + "  " + a.metric.padEnd(18) + "  " + formatValue(a.value).padStart(10) + "  " +
+        formatValue(a.baseline_mean).padStart(10) + "  " + formatValue(a.z).padStart(7) + "  " + a.severity,
+    );
+  }
+  if (anomalies.length > limit) console.log(`... ${anomalies.length - limit} more`);
+}
+
+/**
+ * A --live run has no <date> argument; its window is compared with the days
+ * before today (UTC).
+ */
+export function qualityArgs<T extends { _: (string | number)[]; live?: boolean }>(flags: T, now = new Date()): T {
+  if (!flags.live || !flags._[2] || flags._[3]) return flags;
+  return { ...flags, _: [...flags._, now.toISOString().slice(0, 10)] };
+}
+
+export async function runQuality({ feed, date, bucket }: ArchiveTarget, flags: QualityFlags): Promise<void> {
+  const live = flags.live === true;
+
+  if (live && !LIVE_TAIL_PREFIXES[feed]) {
+    console.error(`--live is not supported for ${feed} (live feeds: ${Object.keys(LIVE_TAIL_PREFIXES).join(", ")})`);
+    Deno.exit(2);
+  }
+  if (live && flags.save) {
+    // Saved reports are daily baselines; a short window would skew them
+    console.error("--save only applies to archived days, not --live windows");
+    Deno.exit(2);
+  }
+  const windowMs = parseDuration(flags.window ?? DEFAULT_LIVE_WINDOW);
+  if (windowMs === null) {
+    console.error(`Invalid --window: ${flags.window} (e.g. 5m, 1h)`);
+    Deno.exit(2);
+  }
+  const baselineDays = parsePositive("baseline-days", flags["baseline-days"], DEFAULT_BASELINE_DAYS, true);
+  const zThreshold = parsePositive("threshold", flags.threshold, DEFAULT_ANOMALY_OPTIONS.zThreshold, false);
+  const limit = parsePositive("limit", flags.limit, DEFAULT_LIMIT, true);
+
+  const storage = new Storage();
+  const history = await Promise.all(
+    previousDates(date, baselineDays).map((d) => loadQualityReport(storage, bucket, feed, d)),
+  );
+  const baseline = buildBaseline(history.filter((r): r is QualityReport => r !== null));
+
+  let nc: NatsConnection | null = null;
+  if (live || flags.publish) {
+    nc = await connect({ servers: flags["nats-url"] ?? Deno.env.get("NATS_URL") ?? "nats://nats.nats.svc:4222" });
+  }
+
+  let anomalous = false;
+  try {
+    let report: QualityReport;
+    if (live) {
+      if (!flags.json) console.log(`Sampling ${LIVE_TAIL_PREFIXES[feed]}.> for ${flags.window ?? DEFAULT_LIVE_WINDOW}...`);
+      report = await liveReport(nc!, feed, windowMs);
+    } else {
+      const archived = await archiveQualityReport(storage, bucket, feed, date, dedupKeys(feed));
+      if (!archived) {
+        console.error(`No archived JSONL files at gs://${bucket}/${archiveDir(feed, date)}/`);
+        Deno.exit(1);
+      }
+      report = archived;
+    }
+
+    const anomalies = detectAnomalies(report, baseline, { ...DEFAULT_ANOMALY_OPTIONS, zThreshold });
+
+    if (flags.save) {
+      await saveQualityReport(storage, bucket, date, report);
+      if (!flags.json) console.log(`Wrote gs://${bucket}/${qualityReportPath(feed, date)}`);
+    }
+
+    if (nc && flags.publish) {
+      const sc = StringCodec();
+      for (const anomaly of anomalies) {
+        nc.publish(qualityAlertSubject(feed, anomaly), sc.encode(JSON.stringify(qualityAlert(report, anomaly))));
+      }
+    }
+
+    if (flags.json) {
+      console.log(JSON.stringify({ report, baseline_days: baseline.days, anomalies }, null, 2));
+    } else {
+      const tickers = Object.values(report.tickers);
+      const messages = tickers.reduce((n, t) => n + t.messages, 0);
+      const quoted = tickers.filter((t) => t.quotes > 0).length;
+      console.log(`${feed} ${report.source} ${report.window_start} -> ${report.window_end}`);
+      console.log(
+        `  ${tickers.length.toLocaleString()} tickers (${quoted.toLocaleString()} quoted), ` +
+          `${messages.toLocaleString()} messages, ${report.duplicates.toLocaleString()} duplicates`,
+      );
+      if (baseline.days === 0) {
+        console.log(`  No baseline: no ${QUALITY_REPORT_FILE} in the previous ${baselineDays} days (build one with --save)`);
+      } else {
+        console.log(`  Baseline: ${baseline.days} of the previous ${baselineDays} days`);
+      }
+      console.log("");
+      if (anomalies.length === 0) {
+        console.log("No anomalies");
+      } else {
+        printAnomalies(anomalies, limit);
+        if (flags.publish) console.log(`\nPublished ${anomalies.length} alerts to alerts.${feed}.quality.>`);
+      }
+    }
+
+    anomalous = anomalies.length > 0;
+  } finally {
+    // Drain before exiting so published alerts are flushed
+    if (nc) await nc.drain();
+  }
+  if (anomalous) Deno.exit(1);
+}
@@ -0,0 +1,89 @@
+/**
+ * ssmd data replay - republish an archived day to NATS with the original
+ * pacing (see lib/archive/replay.ts)
+ */
+import { Storage } from "@google-cloud/storage";
+import { connect, type NatsConnection } from "npm:nats";
+import { parseSpeed, replayArchive } from "../../lib/archive/mod.ts";
+import type { CheckpointStore } from "../../lib/nats/mod.ts";
+import { configMapCheckpointStore } from "../utils/checkpoints.ts";
+import type { ArchiveTarget } from "./data.ts";
+
+export interface ReplayFlags {
+  speed?: string;
+  "subject-prefix"?: string;
+  "nats-url"?: string;
+  "dry-run"?: boolean;
+  checkpoint?: string;
+  stream?: string;
+  env?: string;
+  namespace?: string;
+}
+
+export async function runReplay({ feed, date, bucket }: ArchiveTarget, flags: ReplayFlags): Promise<void> {
+  let speed: number;
+  try {
+    speed = parseSpeed(flags.speed);
+  } catch (err) {
+    console.error(`Error: ${(err as Error).message}`);
+    Deno.exit(2);
+  }
+  const subjectPrefix = flags["subject-prefix"] ?? `replay.${feed}`;
+  if (subjectPrefix.startsWith("prod.")) {
+    // Replayed messages on live subjects would be archived and traded on
+    console.error(`Refusing to replay onto live subject prefix '${subjectPrefix}'`);
+    Deno.exit(2);
+  }
+
+  // The archive's _nats_seq values are sequences of the stream it captured
+  const { checkpoint, stream } = flags;
+  if (checkpoint && !stream) {
+    console.error("--stream (the archived stream, e.g. PROD_KALSHI) is required with --checkpoint");
+    Deno.exit(2);
+  }
+  const store: CheckpointStore | null = checkpoint
+    ? configMapCheckpointStore({ env: flags.env, namespace: flags.namespace })
+    : null;
+  let afterSeq: number | undefined;
+  if (store) {
+    try {
+      afterSeq = (await store.get(stream!, checkpoint!))?.sequence;
+    } catch (err) {
+      console.error(`Error: ${(err as Error).message}`);
+      Deno.exit(2);
+    }
+  }
+
+  const nc: NatsConnection | null = flags["dry-run"]
+    ? null
+    : await connect({ servers: flags["nats-url"] ?? Deno.env.get("NATS_URL") ?? "nats://nats.nats.svc:4222" });
+  const encoder = new TextEncoder();
+  const pace = Number.isFinite(speed) ? `${speed}x` : "max speed";
+  console.log(`Replaying ${feed} ${date} onto ${subjectPrefix}.> at ${pace}`);
+  if (afterSeq !== undefined) console.log(`Resuming after ${stream} sequence ${afterSeq}`);
+
+  try {
+    const report = await replayArchive(new Storage(), bucket, feed, date, {
+      speed,
+      subjectPrefix,
+      publish: nc ? (subject, payload) => nc.publish(subject, encoder.encode(payload)) : undefined,
+      onSlot: (slot, messages) => console.log(`  ${slot}: ${messages} messages`),
+      afterSeq,
+      // Flushed first so the checkpoint never runs ahead of what the server has
+      onCheckpoint: nc && store
+        ? async (seq) => {
+          await nc.flush();
+          await store.set(stream!, checkpoint!, seq);
+        }
+        : undefined,
+    });
+    const verb = nc ? "Published" : "Would publish";
+    console.log(`\n${verb} ${report.published} messages from ${report.slots} slots on ${report.subjects} subjects`);
+    if (report.skipped > 0) console.log(`Skipped ${report.skipped} messages at or before the checkpoint`);
+  } catch (err) {
+    console.error(`Error: ${(err as Error).message}`);
+    Deno.exit(1);
+  } finally {
+    if (nc) await nc.drain();
+  }
+}
@@ -0,0 +1,91 @@
+/**
+ * ssmd data replicate - copy feeds' archive dates to a DR bucket or prefix
+ * (see lib/archive/replicate.ts)
+ */
+import { Storage } from "@google-cloud/storage";
+import {
+  dateRange,
+  locationUrl,
+  replicaDir,
+  replicateDate,
+  type ReplicaLocation,
+  type ReplicationResult,
+} from "../../lib/archive/mod.ts";
+import { FEED_PATHS, VALID_DATA_FEEDS } from "../../lib/duckdb/feed-config.ts";
+
+export interface ReplicateFlags {
+  _: (string | number)[];
+  bucket?: string;
+  "source-prefix"?: string;
+  "dest-bucket"?: string;
+  "dest-prefix"?: string;
+  from?: string;
+  to?: string;
+  "dry-run"?: boolean;
+  json?: boolean;
+}
+
+export async function runReplicate(flags: ReplicateFlags): Promise<void> {
+  const usage =
+    "ssmd data replicate <feed[,feed]> [<date>] [--from <date> --to <date>] --dest-bucket <bucket> [--dest-prefix <p>] [--source-prefix <p>] [--dry-run] [--json]";
+  const feedArg = flags._[2] as string | undefined;
+  const date = flags._[3] as string | undefined;
+  if (!feedArg || (!date && !(flags.from && flags.to))) {
+    console.error(`Usage: ${usage}`);
+    Deno.exit(2);
+  }
+  const feeds = feedArg.split(",").map((f) => f.trim()).filter(Boolean);
+  const unknown = feeds.filter((f) => !FEED_PATHS[f]);
+  if (unknown.length > 0) {
+    console.error(`Unknown feed: ${unknown.join(", ")} (valid: ${VALID_DATA_FEEDS.join(", ")})`);
+    Deno.exit(2);
+  }
+  const bucket = flags.bucket ?? Deno.env.get("GCS_BUCKET");
+  if (!bucket) {
+    console.error("Error: --bucket or GCS_BUCKET is required");
+    Deno.exit(2);
+  }
+  const destBucket = flags["dest-bucket"];
+  if (!destBucket) {
+    console.error("Error: --dest-bucket is required");
+    Deno.exit(2);
+  }
+  const source: ReplicaLocation = { bucket, prefix: flags["source-prefix"] ?? "" };
+  const destination: ReplicaLocation = { bucket: destBucket, prefix: flags["dest-prefix"] ?? "" };
+  if (source.bucket === destination.bucket && source.prefix === destination.prefix) {
+    console.error("Error: the destination must differ from the source");
+    Deno.exit(2);
+  }
+
+  let dates: string[];
+  try {
+    dates = date ? dateRange(date, date) : dateRange(flags.from!, flags.to!);
+  } catch (err) {
+    console.error(`Error: ${(err as Error).message}`);
+    Deno.exit(2);
+  }
+
+  const storage = new Storage();
+  const results: ReplicationResult[] = [];
+  for (const feed of feeds) {
+    for (const day of dates) {
+      const result = await replicateDate(storage, source, destination, feed, day, {
+        dryRun: flags["dry-run"],
+        onCopy: flags.json ? undefined : (name, error) => {
+          if (error) console.error(`  ${feed} ${day} ${name}: ${error}`);
+        },
+      });
+      results.push(result);
+      if (flags.json) continue;
+      if (!result.found) {
+        console.log(`${feed} ${day}: nothing at ${locationUrl(source, replicaDir(source, feed, day))}`);
+      } else {
+        const verb = flags["dry-run"] ? "to copy" : "copied";
+        console.log(`${feed} ${day}: ${result.copied} ${verb}, ${result.current} up to date, ${result.failed} failed`);
+      }
+    }
+  }
+
+  if (flags.json) console.log(JSON.stringify(results, null, 2));
+  if (results.some((r) => r.failed > 0)) Deno.exit(1);
+}
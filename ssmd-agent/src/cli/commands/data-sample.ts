@@ -0,0 +1,87 @@
+/**
+ * ssmd data sample - archived lines for tickers over a date or a span of
+ * dates, in received-time order (see lib/archive/sample.ts)
+ */
+import { Storage } from "@google-cloud/storage";
+import { sampleLines, sampleRange, type SampleRange } from "../../lib/archive/mod.ts";
+import { FEED_PATHS, VALID_DATA_FEEDS } from "../../lib/duckdb/feed-config.ts";
+import { compileFilter, FilterError, type RecordFilter } from "../../lib/filter/mod.ts";
+
+export interface SampleFlags {
+  _: (string | number)[];
+  bucket?: string;
+  ticker?: string;
+  filter?: string;
+  from?: string;
+  to?: string;
+  limit?: string;
+  "full-scan"?: boolean;
+}
+
+export async function runSample(flags: SampleFlags): Promise<void> {
+  const usage = "ssmd data sample <feed> [<date>] [--ticker <t1,t2>] [--filter <expr>] " +
+    "[--from <time|date>] [--to <time|date>] [--limit N] [--full-scan]";
+  const feed = flags._[2] as string | undefined;
+  const date = flags._[3] as string | undefined;
+  if (!feed) {
+    console.error(`Usage: ${usage}`);
+    Deno.exit(2);
+  }
+  if (!FEED_PATHS[feed]) {
+    console.error(`Unknown feed: ${feed} (valid: ${VALID_DATA_FEEDS.join(", ")})`);
+    Deno.exit(2);
+  }
+  if (date !== undefined && !/^\d{4}-\d{2}-\d{2}$/.test(date)) {
+    console.error(`Invalid date: ${date} (expected YYYY-MM-DD)`);
+    Deno.exit(2);
+  }
+  const bucket = flags.bucket ?? Deno.env.get("GCS_BUCKET");
+  if (!bucket) {
+    console.error("Error: --bucket or GCS_BUCKET is required");
+    Deno.exit(2);
+  }
+  const tickers = (flags.ticker ?? "").split(",").map((t) => t.trim()).filter(Boolean);
+  if (tickers.length === 0 && !flags.filter) {
+    console.error("Error: --ticker or --filter is required");
+    Deno.exit(2);
+  }
+  let where: RecordFilter | undefined;
+  if (flags.filter) {
+    try {
+      where = compileFilter(flags.filter);
+    } catch (err) {
+      if (!(err instanceof FilterError)) throw err;
+      console.error(`Invalid --filter: ${err.message}`);
+      Deno.exit(2);
+    }
+  }
+  const limit = parseInt(flags.limit ?? "100", 10);
+  if (isNaN(limit) || limit < 1) {
+    console.error(`--limit must be a positive integer, got '${flags.limit}'`);
+    Deno.exit(2);
+  }
+
+  let range: SampleRange;
+  try {
+    range = sampleRange(date, flags.from, flags.to);
+  } catch (err) {
+    console.error(`Error: ${(err as Error).message}`);
+    Deno.exit(2);
+  }
+
+  let emitted = 0;
+  const lines = sampleLines(new Storage(), bucket, feed, range.dates, {
+    tickers,
+    where,
+    fromMs: range.fromMs,
+    toMs: range.toMs,
+    // A range only reads the dates the archiver has manifests for
+    requireManifest: date === undefined,
+    fullScan: flags["full-scan"],
+    warn: (message) => console.error(message),
+  });
+  for await (const line of lines) {
+    console.log(line);
+    if (++emitted >= limit) break;
+  }
+}
@@ -0,0 +1,89 @@
+/**
+ * ssmd data sql - ad-hoc DuckDB SQL over one day's archived parquet and JSONL
+ * (see lib/duckdb/queries.ts)
+ */
+import { closeDuckDB, initDuckDB, query, type QueryResult } from "../../lib/duckdb/mod.ts";
+import { buildDatasetSQL, datasetTables } from "../../lib/duckdb/queries.ts";
+import type { ArchiveTarget } from "./data.ts";
+
+export interface SqlFlags {
+  _: (string | number)[];
+  limit?: string;
+  json?: boolean;
+  csv?: boolean;
+}
+
+const MAX_CELL_WIDTH = 40;
+
+function cell(value: unknown): string {
+  if (value === null || value === undefined) return "";
+  return typeof value === "object" ? JSON.stringify(value) : String(value);
+}
+
+function csvCell(value: unknown): string {
+  const s = cell(value);
+  return /[",\n]/.test(s) ? `"${s.replaceAll('"', '""')}"` : s;
+}
+
+export function formatCsv(result: QueryResult): string {
+  const lines = [result.columns.map(csvCell).join(",")];
+  for (const row of result.rows) lines.push(result.columns.map((c) => csvCell(row[c])).join(","));
+  return lines.join("\n");
+}
+
+/** Fixed-width table, long cells truncated */
+export function formatTable(result: QueryResult): string {
+  const text = result.rows.map((row) =>
+    result.columns.map((c) => {
+      const s = cell(row[c]);
+      return s.length > MAX_CELL_WIDTH ? `${s.slice(0, MAX_CELL_WIDTH - 1)}…` : s;
+    })
+  );
+  const widths = result.columns.map((c, i) => Math.max(c.length, ...text.map((r) => r[i].length)));
+  const line = (cells: string[]) => cells.map((s, i) => s.padEnd(widths[i])).join("  ").trimEnd();
+  return [line(result.columns), line(widths.map((w) => "-".repeat(w))), ...text.map(line)].join("\n");
+}
+
+export const SQL_USAGE = 'ssmd data sql <feed> <date> "<query>" [--limit N] [--json | --csv]';
+
+export async function runSql({ feed, date, bucket }: ArchiveTarget, flags: SqlFlags): Promise<void> {
+  const sql = flags._[4] as string | undefined;
+  if (!sql) {
+    console.error(`Usage: ${SQL_USAGE}`);
+    console.error(`Tables: ${Object.keys(datasetTables(bucket, feed, date)).join(", ")}`);
+    Deno.exit(2);
+  }
+  const limit = parseInt(flags.limit ?? "1000", 10);
+  if (isNaN(limit) || limit < 1) {
+    console.error(`--limit must be a positive integer, got '${flags.limit}'`);
+    Deno.exit(2);
+  }
+
+  let statement: string;
+  try {
+    statement = buildDatasetSQL(bucket, feed, date, String(sql), limit);
+  } catch (err) {
+    console.error(`Error: ${(err as Error).message}`);
+    Deno.exit(2);
+  }
+
+  await initDuckDB({ quiet: true });
+  let failed = false;
+  try {
+    const result = await query(statement);
+    if (flags.json) {
+      console.log(JSON.stringify(result.rows, null, 2));
+    } else if (flags.csv) {
+      console.log(formatCsv(result));
+    } else {
+      console.log(formatTable(result));
+      console.log(`\n${result.rows.length} row${result.rows.length === 1 ? "" : "s"}`);
+    }
+  } catch (err) {
+    console.error(`Query failed: ${(err as Error).message}`);
+    failed = true;
+  } finally {
+    await closeDuckDB();
+  }
+  if (failed) Deno.exit(1);
+}
@@ -0,0 +1,36 @@
+/**
+ * ssmd data stats - backfill per-file statistics into a day's archiver
+ * manifest (see lib/archive/stats.ts)
+ */
+import { Storage } from "@google-cloud/storage";
+import { backfillStats, manifestPath } from "../../lib/archive/mod.ts";
+import type { ArchiveTarget } from "./data.ts";
+
+export interface StatsFlags {
+  force?: boolean;
+  "dry-run"?: boolean;
+  json?: boolean;
+}
+
+export async function runStats({ feed, date, bucket }: ArchiveTarget, flags: StatsFlags): Promise<void> {
+  const report = await backfillStats(new Storage(), bucket, feed, date, {
+    force: flags.force,
+    dryRun: flags["dry-run"],
+    onFile: flags.json ? undefined : (name, stats) =>
+      console.log(`  ${name.padEnd(20)} ${String(stats.records).padStart(9)} records  ${String(stats.tickers.size).padStart(6)} tickers`),
+    onMissing: (name) => console.error(`  ${name}: not found in the archive, skipped`),
+  });
+  const path = `gs://${bucket}/${manifestPath(feed, date)}`;
+  if (!report) {
+    console.error(`No manifest.json at ${path}`);
+    Deno.exit(2);
+  }
+
+  if (flags.json) {
+    console.log(JSON.stringify(report, null, 2));
+    return;
+  }
+  console.log(`\n${feed} ${date}: ${report.pending} of ${report.files} files needed statistics, ${report.updated} computed.`);
+  if (report.written) console.log(`Updated ${path}`);
+  else if (flags["dry-run"] && report.updated > 0) console.log("Dry run: manifest not written.");
+}
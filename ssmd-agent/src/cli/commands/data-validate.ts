@@ -0,0 +1,103 @@
+/**
+ * ssmd data validate - check a day's archived records against the raw field
+ * maps the schema parsers expect (see lib/archive/validate.ts)
+ */
+import { Storage } from "@google-cloud/storage";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { parse as parseYaml } from "yaml";
+import {
+  archiveDir,
+  buildSchemaRegistry,
+  type FileValidation,
+  selectSchemas,
+  validateArchive,
+} from "../../lib/archive/mod.ts";
+import { type RawSchema, RawSchemaSchema } from "../../lib/types/raw-schema.ts";
+import { getSchemasDir } from "../utils/paths.ts";
+import type { ArchiveTarget } from "./data.ts";
+
+export interface ValidateFlags {
+  schema?: string;
+  json?: boolean;
+}
+
+/**
+ * Raw schemas from YAML files in a schemas directory. Files without a
+ * `fields` map (capnp, parquet docs, ...) are not raw schemas and are
+ * ignored; malformed ones are reported and skipped, like listFeeds.
+ */
+export async function loadRawSchemaFiles(schemasDir: string): Promise<RawSchema[]> {
+  const schemas: RawSchema[] = [];
+  try {
+    for await (const entry of Deno.readDir(schemasDir)) {
+      if (!entry.isFile || !/\.ya?ml$/.test(entry.name)) continue;
+      try {
+        const data = parseYaml(await Deno.readTextFile(join(schemasDir, entry.name)));
+        if (!data || typeof data !== "object" || !("fields" in data)) continue;
+        schemas.push(RawSchemaSchema.parse(data));
+      } catch (e) {
+        console.error(`Warning: Failed to parse ${entry.name}: ${(e as Error).message}`);
+      }
+    }
+  } catch (e) {
+    if (!(e instanceof Deno.errors.NotFound)) throw e;
+  }
+  return schemas.sort((a, b) => `${a.feed}/${a.type}`.localeCompare(`${b.feed}/${b.type}`));
+}
+
+/** Registry from exchanges/schemas/, or the built-ins when there is no exchanges tree */
+export async function loadSchemaRegistry(): Promise<RawSchema[]> {
+  let schemasDir: string;
+  try {
+    schemasDir = await getSchemasDir();
+  } catch {
+    return buildSchemaRegistry([]);
+  }
+  return buildSchemaRegistry(await loadRawSchemaFiles(schemasDir));
+}
+
+function printFile(r: FileValidation): void {
+  console.log(
+    r.file.padEnd(16) + "  " +
+      String(r.records).padStart(9) + "  " +
+      String(r.validated).padStart(9) + "  " +
+      String(r.invalid).padStart(8) + "  " +
+      String(r.unparseable).padStart(7),
+  );
+  for (const [issue, count] of Object.entries(r.issues).sort()) {
+    console.log(`    ${issue}: ${count}`);
+  }
+}
+
+export async function runValidate({ feed, date, bucket }: ArchiveTarget, flags: ValidateFlags): Promise<void> {
+  let schemas: RawSchema[];
+  try {
+    schemas = selectSchemas(await loadSchemaRegistry(), feed, flags.schema);
+  } catch (err) {
+    console.error(`Error: ${(err as Error).message}`);
+    Deno.exit(2);
+  }
+  const schemaIds = schemas.map((s) => `${s.type}:${s.version}`);
+
+  if (!flags.json) {
+    console.log(`Validating ${feed} ${date} against ${schemaIds.join(", ")}\n`);
+    console.log("FILE".padEnd(16) + "  " + "RECORDS".padStart(9) + "  " + "CHECKED".padStart(9) + "  " +
+      "INVALID".padStart(8) + "  " + "BADJSON".padStart(7));
+  }
+  const reports = await validateArchive(new Storage(), bucket, feed, date, schemas, {
+    onFile: flags.json ? undefined : printFile,
+  });
+  if (!reports) {
+    console.error(`No archived JSONL files at gs://${bucket}/${archiveDir(feed, date)}/`);
+    Deno.exit(2);
+  }
+  const invalid = reports.reduce((n, r) => n + r.invalid + r.unparseable, 0);
+
+  if (flags.json) {
+    console.log(JSON.stringify({ feed, date, schemas: schemaIds, valid: invalid === 0, files: reports }, null, 2));
+  } else {
+    console.log(invalid === 0 ? "\nAll checked records valid." : `\n${invalid} invalid records.`);
+  }
+
+  if (invalid > 0) Deno.exit(1);
+}
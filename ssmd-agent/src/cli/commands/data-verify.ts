@@ -0,0 +1,64 @@
+/**
+ * ssmd data verify - check a day's archive against its manifest, and
+ * optionally repair the manifest (see lib/archive/verify.ts)
+ */
+import { Storage } from "@google-cloud/storage";
+import { archiveDir, manifestPath, verifyArchive, type VerifyIssue } from "../../lib/archive/mod.ts";
+import { dedupKeys } from "../../server/schema-versions.ts";
+import type { ArchiveTarget } from "./data.ts";
+
+export interface VerifyFlags {
+  repair?: boolean;
+  "dry-run"?: boolean;
+  json?: boolean;
+}
+
+export function describeIssue(issue: VerifyIssue): string {
+  switch (issue.kind) {
+    case "missing":
+      return "in the manifest but not in the archive";
+    case "unlisted":
+      return "in the archive but not in the manifest";
+    case "truncated":
+      return `truncated (${issue.actual})`;
+    default:
+      return `${issue.kind}: manifest ${issue.expected}, file ${issue.actual}`;
+  }
+}
+
+export async function runVerify({ feed, date, bucket }: ArchiveTarget, flags: VerifyFlags): Promise<void> {
+  const report = await verifyArchive(new Storage(), bucket, feed, date, {
+    repair: flags.repair,
+    keys: dedupKeys(feed),
+    dryRun: flags["dry-run"],
+    onFile: flags.json ? undefined : (f) => {
+      const dups = f.duplicates ? `, ${f.duplicates} duplicates` : "";
+      const summary = f.records !== undefined ? `${f.records} records, ${f.bytes} bytes${dups}` : "-";
+      console.log(`  ${f.name.padEnd(20)} ${f.issues.length === 0 ? "ok" : "FAIL"}  ${summary}`);
+      for (const issue of f.issues) console.log(`      ${describeIssue(issue)}`);
+    },
+  });
+  if (!report) {
+    console.error(`No manifest.json at gs://${bucket}/${archiveDir(feed, date)}/`);
+    Deno.exit(2);
+  }
+
+  if (flags.json) {
+    console.log(JSON.stringify(report, null, 2));
+  } else {
+    const failed = report.files.filter((f) => f.issues.length > 0).length;
+    console.log(failed === 0
+      ? `\n${report.files.length} files match the manifest.`
+      : `\n${failed} of ${report.files.length} files have issues.`);
+    const duplicates = report.files.reduce((n, f) => n + (f.duplicates ?? 0), 0);
+    if (duplicates > 0) console.log(`${duplicates} duplicate messages; ssmd data dedup ${feed} ${date} --rewrite removes them.`);
+    if (flags.repair) {
+      const path = `gs://${bucket}/${manifestPath(feed, date)}`;
+      if (report.repaired === 0) console.log("Manifest needs no repair.");
+      else if (report.written) console.log(`Repaired ${report.repaired} entries in ${path}`);
+      else console.log(`Would repair ${report.repaired} entries in ${path}`);
+    }
+  }
+
+  if (!report.ok) Deno.exit(1);
+}
@@ -0,0 +1,235 @@
+/**
+ * ssmd data - tools for the raw JSONL.gz archive in GCS
+ */
+import { FEED_PATHS, VALID_DATA_FEEDS } from "../../lib/duckdb/feed-config.ts";
+import { previousDay } from "../../lib/archive/mod.ts";
+import { runCompact } from "./data-compact.ts";
+import { runDedup } from "./data-dedup.ts";
+import { DIFF_USAGE, runDiff } from "./data-diff.ts";
+import { runIndex } from "./data-index.ts";
+import { QUALITY_USAGE, qualityArgs, runQuality } from "./data-quality.ts";
+import { runReplay } from "./data-replay.ts";
+import { runReplicate } from "./data-replicate.ts";
+import { runSample } from "./data-sample.ts";
+import { runSql, SQL_USAGE } from "./data-sql.ts";
+import { runStats } from "./data-stats.ts";
+import { runValidate } from "./data-validate.ts";
+import { runVerify } from "./data-verify.ts";
+
+export interface DataFlags {
+  _: (string | number)[];
+  "baseline-days"?: string;
+  bucket?: string;
+  checkpoint?: string;
+  csv?: boolean;
+  dedup?: boolean;
+  "delete-originals"?: boolean;
+  "dest-bucket"?: string;
+  "dest-prefix"?: string;
+  "dry-run"?: boolean;
+  env?: string;
+  filter?: string;
+  force?: boolean;
+  from?: string;
+  "full-scan"?: boolean;
+  json?: boolean;
+  limit?: string;
+  live?: boolean;
+  "merge-gap"?: string;
+  "min-trades"?: string;
+  namespace?: string;
+  "nats-url"?: string;
+  publish?: boolean;
+  repair?: boolean;
+  schema?: string;
+  rewrite?: boolean;
+  save?: boolean;
+  "source-prefix"?: string;
+  speed?: string;
+  stream?: string;
+  "subject-prefix"?: string;
+  threshold?: string;
+  ticker?: string;
+  to?: string;
+  window?: string;
+}
+
+/** The <feed> <date> a data command works on, and the bucket holding it */
+export interface ArchiveTarget {
+  feed: string;
+  date: string;
+  bucket: string;
+}
+
+/**
+ * Validate the <feed> <date> arguments and bucket, exiting 2 with usage on
+ * error. "yesterday" stands for the previous local date, for scheduled jobs.
+ */
+export function parseArchiveTarget(flags: DataFlags, usage: string): ArchiveTarget {
+  const feed = flags._[2] as string | undefined;
+  const arg = flags._[3] as string | undefined;
+  const date = arg === "yesterday" ? previousDay() : arg;
+  if (!feed || !date) {
+    console.error(`Usage: ${usage}`);
+    Deno.exit(2);
+  }
+  if (!FEED_PATHS[feed]) {
+    console.error(`Unknown feed: ${feed} (valid: ${VALID_DATA_FEEDS.join(", ")})`);
+    Deno.exit(2);
+  }
+  if (!/^\d{4}-\d{2}-\d{2}$/.test(date)) {
+    console.error(`Invalid date: ${date} (expected YYYY-MM-DD)`);
+    Deno.exit(2);
+  }
+  const bucket = flags.bucket ?? Deno.env.get("GCS_BUCKET");
+  if (!bucket) {
+    console.error("Error: --bucket or GCS_BUCKET is required");
+    Deno.exit(2);
+  }
+  return { feed, date, bucket };
+}
+
+export async function handleData(subcommand: string, flags: DataFlags): Promise<void> {
+  switch (subcommand) {
+    case "compact":
+      await runCompact(
+        parseArchiveTarget(flags, "ssmd data compact <feed> <date|yesterday> [--dedup] [--delete-originals] [--dry-run] [--json]"),
+        flags,
+      );
+      break;
+    case "dedup":
+      await runDedup(parseArchiveTarget(flags, "ssmd data dedup <feed> <date> [--rewrite] [--dry-run] [--json]"), flags);
+      break;
+    case "diff":
+      await runDiff(parseArchiveTarget(flags, DIFF_USAGE), flags);
+      break;
+    case "index":
+      await runIndex(parseArchiveTarget(flags, "ssmd data index <feed> <date> [--merge-gap <bytes>] [--dry-run]"), flags);
+      break;
+    case "quality":
+      await runQuality(parseArchiveTarget(qualityArgs(flags), QUALITY_USAGE), flags);
+      break;
+    case "replay":
+      await runReplay(
+        parseArchiveTarget(
+          flags,
+          "ssmd data replay <feed> <date> [--speed 10x|max] [--subject-prefix <p>] " +
+            "[--checkpoint <name> --stream <STREAM>] [--dry-run]",
+        ),
+        flags,
+      );
+      break;
+    case "replicate":
+      await runReplicate(flags);
+      break;
+    case "sample":
+      await runSample(flags);
+      break;
+    case "sql":
+      await runSql(parseArchiveTarget(flags, SQL_USAGE), flags);
+      break;
+    case "stats":
+      await runStats(parseArchiveTarget(flags, "ssmd data stats <feed> <date> [--force] [--dry-run] [--json]"), flags);
+      break;
+    case "validate":
+      await runValidate(parseArchiveTarget(flags, "ssmd data validate <feed> <date> [--schema <type>[:<version>]] [--json]"), flags);
+      break;
+    case "verify":
+      await runVerify(parseArchiveTarget(flags, "ssmd data verify <feed> <date> [--repair] [--dry-run] [--json]"), flags);
+      break;
+    default:
+      if (subcommand) console.error(`Unknown data command: ${subcommand}`);
+      printDataHelp();
+      Deno.exit(1);
+  }
+}
+
+function printDataHelp(): void {
+  console.log("Usage: ssmd data <command> <feed> <date> [options]");
+  console.log("");
+  console.log("COMMANDS:");
+  console.log("  verify     Recompute each archive file's record count, decompressed size and");
+  console.log("             SHA-256 and compare them with the day's manifest.json. Truncated gzip");
+  console.log("             files, files missing from the archive and files missing from the");
+  console.log("             manifest are reported; exits 1 if any file has an issue.");
+  console.log("  validate   Check every record against the raw field map the schema parsers expect:");
+  console.log("             missing required fields and type mismatches are errors, unknown fields");
+  console.log("             warnings, counted per file; exits 1 if any record is invalid.");
+  console.log("             Schemas are built in for kalshi, kraken-spot and binance; YAML files in");
+  console.log("             exchanges/schemas/ (feed, type, version, type_field, fields) add or");
+  console.log("             override them.");
+  console.log("  dedup      Count duplicate messages per file, e.g. NATS redeliveries archived twice:");
+  console.log("             same ticker, exchange timestamp, type and payload (ignoring _received_at");
+  console.log("             and _nats_seq) as a line earlier in the same or previous file.");
+  console.log("  compact    Merge the day's JSONL.gz files into parquet under <date>/compacted/,");
+  console.log("             partitioned by ticker and sorted by ticker and received time, and");
+  console.log("             record it in manifest.json. <date> may be \"yesterday\" (local date, $TZ).");
+  console.log("             Also rebuilds ticker-index.json unless --delete-originals.");
+  console.log("  stats      Backfill per-file statistics (min_ts/max_ts, records_by_type,");
+  console.log("             ticker_count) into manifest.json for files archived without them;");
+  console.log("             sample skips files outside --from/--to by them.");
+  console.log("  index      Write ticker-index.json next to manifest.json: for each file, the");
+  console.log("             decompressed byte ranges holding each ticker's lines.");
+  console.log("  sample     Archived lines for --ticker and/or --filter over <date> (--from/--to");
+  console.log("             as HH:MM[:SS] UTC times) or without a date a --from..--to span of days");
+  console.log("             that have manifests, merged in received-time order across files");
+  console.log("             (--limit N).");
+  console.log("             With a ticker index, files without the tickers are skipped and only");
+  console.log("             their indexed ranges are read.");
+  console.log("  sql        Run a DuckDB SELECT over the day's files, named as tables: one per");
+  console.log("             parquet type (trade, ticker) and raw for the archived JSONL, e.g.");
+  console.log("             ssmd data sql kalshi 2026-01-15 \"SELECT ticker, count(*) FROM raw GROUP BY 1\"");
+  console.log("  quality    Per-ticker message rate, quote staleness (longest gap between quotes)");
+  console.log("             and spread percentiles for <date> or, with --live and no date, a live");
+  console.log("             NATS window, compared with the previous days' quality.json reports.");
+  console.log("             Tickers that went quiet, stale or wide by --threshold standard");
+  console.log("             deviations are listed; exits 1 when any are.");
+  console.log("  replay     Republish the day's messages to NATS in received-time order on");
+  console.log("             <subject-prefix>.json.<type>.<ticker>, keeping the original spacing");
+  console.log("             scaled by --speed. With --checkpoint, the archived stream sequence reached");
+  console.log("             is recorded after each slot and a rerun resumes after it.");
+  console.log("  diff       Compare <date1> with <date2>: manifest totals and per-ticker trade counts");
+  console.log("             and volume from the trade parquet. Tickers missing on <date2> and");
+  console.log("             counts down by --threshold or more are anomalies; exits 1 if any.");
+  console.log("  replicate  Copy feeds' dates (<feed[,feed]> <date> or --from/--to) to --dest-bucket");
+  console.log("             and --dest-prefix for DR, checking each copy's CRC32C and size and");
+  console.log("             writing replication.json per date so reruns resume.");
+  console.log("");
+  console.log("OPTIONS:");
+  console.log("  --repair                 verify: rewrite the manifest from the recomputed values");
+  console.log("  --schema <type>[:<v>]    validate: only check one message type, e.g. trade:v1");
+  console.log("  --rewrite                dedup: replace files that have duplicates with cleaned copies");
+  console.log("                           and update their manifest.json entries");
+  console.log("  --force                  stats: recompute every file, not only those missing statistics");
+  console.log("  --dedup                  compact: leave duplicate messages out of the parquet");
+  console.log("  --delete-originals       compact: delete the JSONL files once the parquet is written");
+  console.log("  --dry-run                Report what would change without writing");
+  console.log("  --json                   Machine-readable report");
+  console.log("  --csv                    sql: print rows as CSV instead of a table");
+  console.log("  --ticker <t1,t2>         sample: tickers to return");
+  console.log("  --filter <expr>          sample: keep lines matching e.g.");
+  console.log("                           'ticker == \"INXD\" && price > 0.4 && type in (\"trade\")'");
+  console.log("  --from/--to <bound>      sample: time bounds; replicate: date range");
+  console.log("  --limit <n>              sample: lines to print (default: 100); sql: rows (default: 1000);");
+  console.log("                           diff: tickers listed per section; quality: anomalies (default: 20)");
+  console.log("  --full-scan              sample: ignore the ticker index and read whole files");
+  console.log("  --merge-gap <bytes>      index: merge a ticker's ranges closer than this (default: 65536)");
+  console.log("  --speed <n>x             replay: speed multiplier, or 'max' (default: 1x)");
+  console.log("  --subject-prefix <p>     replay: subject prefix (default: replay.<feed>; prod.* refused)");
+  console.log("  --nats-url <url>         replay, quality: NATS server (default: $NATS_URL or nats://nats.nats.svc:4222)");
+  console.log("  --checkpoint <name>      replay: checkpoint to resume from and record (ConfigMap nats-checkpoints,");
+  console.log("                           --env/--namespace); one name per replayed date");
+  console.log("  --stream <STREAM>        replay: stream the archive captured, e.g. PROD_KALSHI (with --checkpoint)");
+  console.log("  --threshold <f>          diff: fractional drop flagged as an anomaly (default: 0.5);");
+  console.log("                           quality: standard deviations flagged (default: 3)");
+  console.log("  --min-trades <n>         diff: ignore tickers with fewer trades on <date1> (default: 10)");
+  console.log("  --live                   quality: sample live NATS messages instead of the archive");
+  console.log("  --window <dur>           quality: live sampling window, e.g. 5m or 1h (default: 5m)");
+  console.log("  --baseline-days <n>      quality: previous days of quality.json to compare with (default: 7)");
+  console.log("  --save                   quality: write the day's report as quality.json (archive only)");
+  console.log("  --publish                quality: publish anomalies to alerts.<feed>.quality.<metric>.<ticker>");
+  console.log("  --dest-bucket <name>     replicate: destination bucket");
+  console.log("  --dest-prefix <p>        replicate: prefix before the feed path at the destination");
+  console.log("  --source-prefix <p>      replicate: prefix before the feed path at the source");
+  console.log("  --bucket <name>          GCS archive bucket (default: $GCS_BUCKET)");
+}
@@ -0,0 +1,191 @@
+// Diff command: compare exchanges/ configs in the working tree against a git ref
+// ssmd diff --against <ref> [--output json]
+import { parse as parseYaml } from "yaml";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { findExchangesRoot } from "../utils/paths.ts";
+import { readTreeAt } from "../utils/git.ts";
+
+export const CONFIG_KINDS = ["feeds", "schemas", "environments"] as const;
+export type ConfigKind = typeof CONFIG_KINDS[number];
+
+/** Parsed configs per kind, keyed by entity name (file name without .yaml) */
+export type ConfigSnapshot = Record<ConfigKind, Map<string, unknown>>;
+
+export interface FieldChange {
+  path: string;
+  from: unknown;
+  to: unknown;
+}
+
+export interface EntityChange {
+  name: string;
+  changes: FieldChange[];
+}
+
+export interface KindDiff {
+  added: string[];
+  removed: string[];
+  changed: EntityChange[];
+}
+
+export type ConfigDiff = Record<ConfigKind, KindDiff>;
+
+interface DiffFlags {
+  against?: string;
+  output?: string;
+}
+
+function emptySnapshot(): ConfigSnapshot {
+  return { feeds: new Map(), schemas: new Map(), environments: new Map() };
+}
+
+/**
+ * Build a snapshot from files keyed by path relative to exchanges/
+ * (e.g. "feeds/kalshi.yaml"). Files outside the known kinds are ignored.
+ */
+export function snapshotFromFiles(files: Map<string, string>): ConfigSnapshot {
+  const snapshot = emptySnapshot();
+  for (const [path, content] of files) {
+    const [kind, file, ...rest] = path.split("/");
+    if (rest.length > 0 || !file.endsWith(".yaml") || !(CONFIG_KINDS as readonly string[]).includes(kind)) {
+      continue;
+    }
+    snapshot[kind as ConfigKind].set(file.slice(0, -".yaml".length), parseYaml(content));
+  }
+  return snapshot;
+}
+
+/** Read feeds/, schemas/ and environments/ YAML files from the working tree */
+export async function loadWorkingTree(root: string): Promise<ConfigSnapshot> {
+  const files = new Map<string, string>();
+  for (const kind of CONFIG_KINDS) {
+    try {
+      for await (const entry of Deno.readDir(join(root, kind))) {
+        if (entry.isFile && entry.name.endsWith(".yaml")) {
+          files.set(`${kind}/${entry.name}`, await Deno.readTextFile(join(root, kind, entry.name)));
+        }
+      }
+    } catch (e) {
+      if (!(e instanceof Deno.errors.NotFound)) throw e;
+    }
+  }
+  return snapshotFromFiles(files);
+}
+
+/** Read the same files from a git ref */
+export async function loadGitRef(root: string, ref: string): Promise<ConfigSnapshot> {
+  return snapshotFromFiles(await readTreeAt(root, ref));
+}
+
+function isObject(v: unknown): v is Record<string, unknown> {
+  return typeof v === "object" && v !== null && !Array.isArray(v);
+}
+
+// Versioned lists (feed versions) are keyed by their version field, so adding
+// a version reports one entry instead of a change to the whole array.
+function keyedByVersion(v: unknown): Map<string, unknown> | null {
+  if (!Array.isArray(v) || !v.every((e) => isObject(e) && typeof e.version === "string")) {
+    return null;
+  }
+  return new Map(v.map((e) => [(e as { version: string }).version, e]));
+}
+
+/** Field-level changes between two parsed YAML values, by dotted path */
+export function diffValues(from: unknown, to: unknown, path = ""): FieldChange[] {
+  if (JSON.stringify(from) === JSON.stringify(to)) {
+    return [];
+  }
+
+  const fromVersions = keyedByVersion(from);
+  const toVersions = keyedByVersion(to);
+  if (fromVersions && toVersions) {
+    const changes: FieldChange[] = [];
+    for (const key of new Set([...fromVersions.keys(), ...toVersions.keys()])) {
+      changes.push(...diffValues(fromVersions.get(key), toVersions.get(key), `${path}[${key}]`));
+    }
+    return changes;
+  }
+
+  if (isObject(from) && isObject(to)) {
+    const changes: FieldChange[] = [];
+    for (const key of [...new Set([...Object.keys(from), ...Object.keys(to)])].sort()) {
+      changes.push(...diffValues(from[key], to[key], path ? `${path}.${key}` : key));
+    }
+    return changes;
+  }
+
+  return [{ path, from: from ?? null, to: to ?? null }];
+}
+
+/** Added, removed and changed entities of each kind, going from `before` to `after` */
+export function diffSnapshots(before: ConfigSnapshot, after: ConfigSnapshot): ConfigDiff {
+  const diff = {} as ConfigDiff;
+  for (const kind of CONFIG_KINDS) {
+    const b = before[kind];
+    const a = after[kind];
+    const result: KindDiff = { added: [], removed: [], changed: [] };
+    for (const name of [...new Set([...b.keys(), ...a.keys()])].sort()) {
+      if (!b.has(name)) {
+        result.added.push(name);
+      } else if (!a.has(name)) {
+        result.removed.push(name);
+      } else {
+        const changes = diffValues(b.get(name), a.get(name));
+        if (changes.length > 0) {
+          result.changed.push({ name, changes });
+        }
+      }
+    }
+    diff[kind] = result;
+  }
+  return diff;
+}
+
+export function isEmptyDiff(diff: ConfigDiff): boolean {
+  return CONFIG_KINDS.every((k) =>
+    diff[k].added.length === 0 && diff[k].removed.length === 0 && diff[k].changed.length === 0
+  );
+}
+
+function formatValue(v: unknown): string {
+  return v === null ? "(none)" : typeof v === "string" ? v : JSON.stringify(v);
+}
+
+export function printConfigDiff(diff: ConfigDiff, against: string): void {
+  console.log(`Changes in exchanges/ since ${against}:`);
+  if (isEmptyDiff(diff)) {
+    console.log("  (none)");
+    return;
+  }
+  for (const kind of CONFIG_KINDS) {
+    const d = diff[kind];
+    if (d.added.length === 0 && d.removed.length === 0 && d.changed.length === 0) continue;
+    console.log(`\n${kind}:`);
+    for (const name of d.added) console.log(`  + ${name}`);
+    for (const name of d.removed) console.log(`  - ${name}`);
+    for (const entity of d.changed) {
+      console.log(`  ~ ${entity.name}`);
+      for (const c of entity.changes) {
+        console.log(`      ${c.path}: ${formatValue(c.from)} -> ${formatValue(c.to)}`);
+      }
+    }
+  }
+}
+
+export async function handleDiff(flags: DiffFlags): Promise<void> {
+  const against = flags.against ?? "HEAD";
+  const output = flags.output ?? "text";
+  if (output !== "text" && output !== "json") {
+    console.error(`Unknown output format: ${output} (expected text or json)`);
+    Deno.exit(1);
+  }
+
+  const root = await findExchangesRoot();
+  const diff = diffSnapshots(await loadGitRef(root, against), await loadWorkingTree(root));
+
+  if (output === "json") {
+    console.log(JSON.stringify({ against, ...diff }, null, 2));
+  } else {
+    printConfigDiff(diff, against);
+  }
+}
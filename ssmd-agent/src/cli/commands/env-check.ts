@@ -0,0 +1,188 @@
+// env-check.ts - Readiness report for an environment before deploying:
+// NATS reachable with the environment's streams, storage writable, and
+// feed endpoints dialable
+
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { Storage } from "@google-cloud/storage";
+import { connect } from "npm:nats";
+import { getEnv } from "../utils/env-context.ts";
+import { getFeedsDir } from "../utils/paths.ts";
+import { listFeeds } from "./feed.ts";
+import { getLatestVersion } from "../../lib/types/feed.ts";
+import type { Environment } from "../../lib/types/env-config.ts";
+
+interface EnvCheckFlags {
+  _: (string | number)[];
+  feed?: string;
+  output?: string;
+  json?: boolean;
+}
+
+export type CheckStatus = "ok" | "fail" | "skip";
+
+export interface CheckResult {
+  check: "transport" | "storage" | "endpoint";
+  target: string;
+  status: CheckStatus;
+  detail: string;
+  ms: number | null;
+}
+
+/** Per-check timeout so an unreachable host cannot hang the report */
+const CHECK_TIMEOUT_MS = 5_000;
+
+/** Host, port and TLS for dialing a feed endpoint URL */
+export function dialTarget(endpoint: string): { hostname: string; port: number; tls: boolean } {
+  const url = new URL(endpoint);
+  const tls = url.protocol === "wss:" || url.protocol === "https:";
+  const port = url.port ? Number(url.port) : tls ? 443 : 80;
+  return { hostname: url.hostname, port, tls };
+}
+
+/**
+ * Streams belonging to the environment (PREFIX_*), optionally narrowed to
+ * one feed: kraken-futures matches PREFIX_KRAKEN_FUTURES and its children.
+ */
+export function matchingStreams(streams: string[], streamPrefix: string, feed?: string): string[] {
+  const base = feed ? `${streamPrefix}_${feed.toUpperCase().replaceAll("-", "_")}` : streamPrefix;
+  return streams.filter((s) => s === base || s.startsWith(`${base}_`)).sort();
+}
+
+function withTimeout<T>(promise: Promise<T>, what: string): Promise<T> {
+  let timer: number | undefined;
+  const timeout = new Promise<never>((_, reject) => {
+    timer = setTimeout(() => reject(new Error(`${what} timed out after ${CHECK_TIMEOUT_MS}ms`)), CHECK_TIMEOUT_MS);
+  });
+  return Promise.race([promise, timeout]).finally(() => clearTimeout(timer));
+}
+
+async function timed(
+  check: CheckResult["check"],
+  target: string,
+  fn: () => Promise<string>,
+): Promise<CheckResult> {
+  const started = performance.now();
+  try {
+    const detail = await withTimeout(fn(), check);
+    return { check, target, status: "ok", detail, ms: Math.round(performance.now() - started) };
+  } catch (e) {
+    return { check, target, status: "fail", detail: (e as Error).message, ms: Math.round(performance.now() - started) };
+  }
+}
+
+async function checkTransport(env: Environment, feed?: string): Promise<CheckResult> {
+  return timed("transport", env.nats.url, async () => {
+    const nc = await connect({ servers: env.nats.url, timeout: CHECK_TIMEOUT_MS });
+    try {
+      const jsm = await nc.jetstreamManager();
+      const names: string[] = [];
+      for await (const name of jsm.streams.names()) names.push(name);
+      const found = matchingStreams(names, env.nats.stream_prefix, feed);
+      if (found.length === 0) {
+        const wanted = feed ? `${env.nats.stream_prefix}_${feed.toUpperCase().replaceAll("-", "_")}*` : `${env.nats.stream_prefix}_*`;
+        throw new Error(`connected, but no stream matches ${wanted}`);
+      }
+      return `streams: ${found.join(", ")}`;
+    } finally {
+      await nc.close();
+    }
+  });
+}
+
+async function checkStorage(env: Environment): Promise<CheckResult> {
+  const storage = env.storage;
+  if (!storage) {
+    return { check: "storage", target: "-", status: "skip", detail: "no storage configured", ms: null };
+  }
+  const probe = `.ssmd-env-check-${Date.now()}`;
+  switch (storage.type) {
+    case "local":
+      return timed("storage", storage.path, async () => {
+        const path = join(storage.path, probe);
+        await Deno.writeTextFile(path, "probe\n");
+        await Deno.remove(path);
+        return "wrote and deleted probe file";
+      });
+    case "gcs":
+      return timed("storage", `gs://${storage.bucket}`, async () => {
+        const file = new Storage().bucket(storage.bucket).file(probe);
+        await file.save("probe\n", { contentType: "text/plain" });
+        await file.delete();
+        return "wrote and deleted probe object";
+      });
+    case "s3":
+      return {
+        check: "storage",
+        target: `s3://${storage.bucket}`,
+        status: "skip",
+        detail: "S3 probe not supported (no S3 client in ssmd CLI)",
+        ms: null,
+      };
+  }
+}
+
+async function checkEndpoint(name: string, endpoint: string): Promise<CheckResult> {
+  return timed("endpoint", `${name} ${endpoint}`, async () => {
+    const { hostname, port, tls } = dialTarget(endpoint);
+    const conn = tls ? await Deno.connectTls({ hostname, port }) : await Deno.connect({ hostname, port });
+    if (tls) await (conn as Deno.TlsConn).handshake();
+    conn.close();
+    return `${tls ? "TLS" : "TCP"} connect to ${hostname}:${port}`;
+  });
+}
+
+export async function runEnvCheck(flags: EnvCheckFlags): Promise<void> {
+  const name = flags._[2] as string | undefined;
+  if (!name) {
+    console.error("Usage: ssmd env check <name> [--feed <feed>] [--output json]");
+    Deno.exit(2);
+  }
+  const asJson = flags.json || flags.output === "json";
+
+  let env: Environment;
+  try {
+    env = await getEnv(name);
+  } catch (e) {
+    console.error(e instanceof Error ? e.message : String(e));
+    Deno.exit(2);
+  }
+
+  const feeds = (await listFeeds(await getFeedsDir()))
+    .filter((f) => (flags.feed ? f.name === flags.feed : f.status === "active"));
+  if (flags.feed && feeds.length === 0) {
+    console.error(`Unknown feed: ${flags.feed}`);
+    Deno.exit(2);
+  }
+
+  const results = await Promise.all([
+    checkTransport(env, flags.feed),
+    checkStorage(env),
+    ...feeds.map((f) => {
+      const endpoint = getLatestVersion(f)?.endpoint;
+      return endpoint
+        ? checkEndpoint(f.name, endpoint)
+        : Promise.resolve<CheckResult>({ check: "endpoint", target: f.name, status: "skip", detail: "no versions", ms: null });
+    }),
+  ]);
+  const ready = results.every((r) => r.status !== "fail");
+
+  if (asJson) {
+    console.log(JSON.stringify({ environment: name, ready, checks: results }, null, 2));
+  } else {
+    console.log(`Environment: ${name} (cluster ${env.cluster}, namespace ${env.namespace})\n`);
+    const width = Math.max(6, ...results.map((r) => r.target.length));
+    console.log("CHECK".padEnd(10) + "  " + "TARGET".padEnd(width) + "  " + "STATUS".padEnd(6) + "  " + "TIME".padStart(7) + "  DETAIL");
+    for (const r of results) {
+      console.log(
+        r.check.padEnd(10) + "  " +
+          r.target.padEnd(width) + "  " +
+          r.status.toUpperCase().padEnd(6) + "  " +
+          (r.ms !== null ? `${r.ms}ms` : "-").padStart(7) + "  " +
+          r.detail,
+      );
+    }
+    console.log(`\n${ready ? "READY" : "NOT READY"}`);
+  }
+
+  if (!ready) Deno.exit(1);
+}
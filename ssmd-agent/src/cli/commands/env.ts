@@ -1,17 +1,29 @@
 // env.ts - Environment management commands
-// ssmd env list|use|current|show
+// ssmd env list|use|current|show|check|render|promote|delete
 
+import { stringify as stringifyYaml } from "https://deno.land/std@0.224.0/yaml/mod.ts";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
 import {
   loadConfig,
+  loadRawConfig,
+  saveConfig,
   getCurrentEnvName,
   setCurrentEnv,
   getEnv,
   listEnvNames,
   getConfigPath,
 } from "../utils/env-context.ts";
+import { diffEnvironments, type EnvChange, PROMOTE_PRESERVED_KEYS, promoteEnvironment } from "../../lib/types/env-config.ts";
+import { findExchangesRoot } from "../utils/paths.ts";
+import { moveToTrash, type Tombstone } from "./trash.ts";
+import { runEnvCheck } from "./env-check.ts";
 
 interface EnvFlags {
   _: (string | number)[];
+  json?: boolean;
+  "dry-run"?: boolean;
+  feed?: string;
+  output?: string;
 }
 
 export async function handleEnv(
@@ -32,6 +44,18 @@ export async function handleEnv(
     case "show":
       await envShow(flags._[2] as string);
       break;
+    case "check":
+      await runEnvCheck(flags);
+      break;
+    case "render":
+      await envRender(flags._[2] as string, Boolean(flags.json));
+      break;
+    case "promote":
+      await envPromote(flags._[2] as string, flags._[3] as string, Boolean(flags["dry-run"]));
+      break;
+    case "delete":
+      await envDelete(flags._[2] as string);
+      break;
     default:
       console.error(`Unknown env command: ${subcommand}`);
       printEnvHelp();
@@ -148,6 +172,130 @@ async function envShow(name?: string): Promise<void> {
   }
 }
 
+/**
+ * Print an environment with its extends chain merged in, as the CLI uses it
+ */
+async function envRender(name: string | undefined, json: boolean): Promise<void> {
+  if (!name) {
+    name = await getCurrentEnvName();
+  }
+
+  try {
+    const env = await getEnv(name);
+    if (json) {
+      console.log(JSON.stringify(env, null, 2));
+      return;
+    }
+
+    const raw = await loadRawConfig();
+    const chain: string[] = [];
+    for (let base = raw.environments[name]?.extends; base; base = raw.environments[base]?.extends) {
+      chain.push(base);
+    }
+    if (chain.length > 0) {
+      console.log(`# ${name} extends ${chain.join(" -> ")}`);
+    }
+    console.log(stringifyYaml(env as Record<string, unknown>).trimEnd());
+  } catch (e) {
+    console.error(e instanceof Error ? e.message : String(e));
+    Deno.exit(1);
+  }
+}
+
+/** One line per change: + added, - removed, ~ changed */
+export function formatEnvChanges(changes: EnvChange[]): string[] {
+  const fmt = (v: unknown) => JSON.stringify(v);
+  return changes.map((c) => {
+    if (!("from" in c)) return `+ ${c.path}: ${fmt(c.to)}`;
+    if (!("to" in c)) return `- ${c.path}: ${fmt(c.from)}`;
+    return `~ ${c.path}: ${fmt(c.from)} -> ${fmt(c.to)}`;
+  });
+}
+
+/**
+ * Copy source's resolved config over target, keeping the target's own
+ * cluster, namespace, URLs, buckets and secret sources. Shows the diff first;
+ * with dryRun nothing is written.
+ */
+async function envPromote(source: string | undefined, target: string | undefined, dryRun: boolean): Promise<void> {
+  if (!source || !target) {
+    console.error("Usage: ssmd env promote <source> <target> [--dry-run]");
+    Deno.exit(1);
+  }
+  if (source === target) {
+    console.error("Source and target must differ");
+    Deno.exit(1);
+  }
+
+  try {
+    const config = await loadConfig();
+    const from = config.environments[source];
+    const to = config.environments[target];
+    if (!from || !to) {
+      throw new Error(`Environment '${!from ? source : target}' not found in config`);
+    }
+
+    const promoted = promoteEnvironment(from, to);
+    const changes = diffEnvironments(to, promoted);
+    console.log(`Promote ${source} -> ${target} (keeping ${target}'s ${PROMOTE_PRESERVED_KEYS.join(", ")})`);
+    if (changes.length === 0) {
+      console.log(`\n${target} already matches ${source}.`);
+      return;
+    }
+    console.log();
+    for (const line of formatEnvChanges(changes)) console.log(`  ${line}`);
+
+    if (dryRun) {
+      console.log("\nDry run - nothing written.");
+      return;
+    }
+
+    const raw = await loadRawConfig();
+    if (raw.environments[target]?.extends) {
+      console.log(`\nNote: ${target} no longer extends ${raw.environments[target].extends}; its resolved config is written in full.`);
+    }
+    raw.environments[target] = promoted;
+    await saveConfig(raw);
+    console.log(`\nUpdated ${target} in ${getConfigPath()}`);
+  } catch (e) {
+    console.error(e instanceof Error ? e.message : String(e));
+    Deno.exit(1);
+  }
+}
+
+/**
+ * Move exchanges/environments/<name>.yaml (or .yml) to exchanges/.trash
+ */
+export async function deleteEnvironment(exchangesRoot: string, name: string): Promise<Tombstone> {
+  for (const ext of [".yaml", ".yml"]) {
+    const path = join("environments", `${name}${ext}`);
+    try {
+      await Deno.stat(join(exchangesRoot, path));
+    } catch (e) {
+      if (e instanceof Deno.errors.NotFound) continue;
+      throw e;
+    }
+    return moveToTrash(exchangesRoot, "environment", name, path);
+  }
+  throw new Error(`Environment '${name}' not found in ${join(exchangesRoot, "environments")}`);
+}
+
+async function envDelete(name?: string): Promise<void> {
+  if (!name) {
+    console.error("Usage: ssmd env delete <name>");
+    Deno.exit(1);
+  }
+
+  try {
+    const t = await deleteEnvironment(await findExchangesRoot(), name);
+    console.log(`Moved ${t.path} to trash (${t.id})`);
+    console.log(`Undo with: ssmd restore ${t.id}`);
+  } catch (e) {
+    console.error(e instanceof Error ? e.message : String(e));
+    Deno.exit(1);
+  }
+}
+
 function printEnvHelp(): void {
   console.log("Usage: ssmd env <command> [options]");
   console.log();
@@ -156,12 +304,22 @@ function printEnvHelp(): void {
   console.log("  use <name>        Switch to a different environment");
   console.log("  current           Show current environment");
   console.log("  show [name]       Show environment details (defaults to current)");
+  console.log("  check <name>      Readiness report: NATS streams, storage write, feed endpoint dial");
+  console.log("                    (--feed <feed> to check one feed, --output json; exit 1 if not ready)");
+  console.log("  render [name]     Print the environment with extends merged in (--json for JSON)");
+  console.log("  promote <src> <dst> [--dry-run]  Copy src's config to dst, keeping dst's cluster, URLs,");
+  console.log("                    buckets and secrets; prints the diff before writing");
+  console.log("  delete <name>     Move exchanges/environments/<name>.yaml to exchanges/.trash (undo: ssmd restore)");
   console.log();
   console.log("Examples:");
   console.log("  ssmd env list");
   console.log("  ssmd env use dev");
   console.log("  ssmd env current");
   console.log("  ssmd env show prod");
+  console.log("  ssmd env check dev --feed kalshi");
+  console.log("  ssmd env render dev");
+  console.log("  ssmd env promote dev prod --dry-run");
   console.log();
   console.log(`Config file: ${getConfigPath()}`);
+  console.log("An environment with 'extends: <base>' inherits the base and overrides only the keys it sets.");
 }
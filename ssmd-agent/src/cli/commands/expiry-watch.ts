@@ -0,0 +1,228 @@
+// ssmd-agent/src/cli/commands/expiry-watch.ts
+// Daemon that polls secmaster for markets approaching close_time and publishes
+// "closing soon" and "closed" notices to NATS
+
+import { parseArgs } from "https://deno.land/std@0.224.0/cli/parse_args.ts";
+import { connect, type NatsConnection, StringCodec, Events } from "npm:nats";
+import { getDb, closeDb, listMarketsClosingBetween, type MarketCloseTime } from "../../lib/db/mod.ts";
+
+const sc = StringCodec();
+
+function log(message: string): void {
+  console.log(`${new Date().toISOString()} ${message}`);
+}
+
+function logWarn(message: string): void {
+  console.warn(`${new Date().toISOString()} WARN ${message}`);
+}
+
+function logError(message: string): void {
+  console.error(`${new Date().toISOString()} ERROR ${message}`);
+}
+
+interface WatcherConfig {
+  natsUrl: string;
+  subjectPrefix: string;
+  windowMs: number;
+  pollIntervalMs: number;
+}
+
+function loadConfig(): WatcherConfig {
+  return {
+    natsUrl: Deno.env.get("NATS_URL") ?? "nats://localhost:4222",
+    subjectPrefix: Deno.env.get("EXPIRY_SUBJECT_PREFIX") ?? "prod.kalshi.expiry",
+    windowMs: Number(Deno.env.get("EXPIRY_WINDOW_MINUTES") ?? "60") * 60_000,
+    pollIntervalMs: Number(Deno.env.get("POLL_INTERVAL_SECONDS") ?? "60") * 1000,
+  };
+}
+
+export type ExpiryEventType = "closing_soon" | "closed";
+
+/** Notice published on <prefix>.closing.<ticker> or <prefix>.closed.<ticker> */
+export interface ExpiryNotice {
+  type: ExpiryEventType;
+  ticker: string;
+  event_ticker: string;
+  close_time: string;
+  seconds_to_close: number;
+}
+
+/** Tickers already announced, per event type, so each notice is sent once */
+export interface Announced {
+  closingSoon: Set<string>;
+  closed: Set<string>;
+}
+
+/**
+ * Decide which notices to publish for markets closing in the window. A market
+ * is announced "closing_soon" once while close_time is ahead, and "closed" once
+ * after it passes. Marks returned tickers as announced.
+ */
+export function collectExpiryNotices(
+  markets: MarketCloseTime[],
+  now: Date,
+  announced: Announced,
+): ExpiryNotice[] {
+  const notices: ExpiryNotice[] = [];
+  for (const m of markets) {
+    const secondsToClose = Math.round((m.closeTime.getTime() - now.getTime()) / 1000);
+    const type: ExpiryEventType = secondsToClose > 0 ? "closing_soon" : "closed";
+    const seen = type === "closing_soon" ? announced.closingSoon : announced.closed;
+    if (seen.has(m.ticker)) continue;
+    seen.add(m.ticker);
+    notices.push({
+      type,
+      ticker: m.ticker,
+      event_ticker: m.eventTicker,
+      close_time: m.closeTime.toISOString(),
+      seconds_to_close: secondsToClose,
+    });
+  }
+  return notices;
+}
+
+/** Subject for a notice; the ticker is sanitized like the Rust SubjectBuilder does */
+export function expirySubject(prefix: string, notice: ExpiryNotice): string {
+  const token = notice.ticker.replaceAll("/", "-").replace(/[^A-Za-z0-9_-]/g, "");
+  const kind = notice.type === "closing_soon" ? "closing" : "closed";
+  return `${prefix}.${kind}.${token}`;
+}
+
+/**
+ * Forget tickers no longer in the polled range, so the sets don't grow
+ * without bound.
+ */
+export function pruneAnnounced(announced: Announced, markets: MarketCloseTime[]): void {
+  const inRange = new Set(markets.map((m) => m.ticker));
+  for (const set of [announced.closingSoon, announced.closed]) {
+    for (const ticker of set) {
+      if (!inRange.has(ticker)) set.delete(ticker);
+    }
+  }
+}
+
+export async function runExpiryWatch(args: string[] = Deno.args): Promise<void> {
+  const flags = parseArgs(args, {
+    boolean: ["help"],
+    alias: { h: "help" },
+  });
+
+  if (flags.help) {
+    console.log(`
+SSMD Expiry Watch - Publish "closing soon" and "closed" notices for markets
+approaching close_time.
+
+Each market gets one notice on <prefix>.closing.<ticker> once it is within the
+window, and one on <prefix>.closed.<ticker> once close_time passes. Notices are
+remembered in memory, so a restart may repeat notices for markets in range.
+
+Environment variables:
+  DATABASE_URL            PostgreSQL connection string (required)
+  NATS_URL                NATS server URL (default: nats://localhost:4222)
+  EXPIRY_SUBJECT_PREFIX   Subject prefix (default: prod.kalshi.expiry)
+  EXPIRY_WINDOW_MINUTES   How far ahead to announce closes (default: 60)
+  POLL_INTERVAL_SECONDS   How often to poll secmaster (default: 60)
+`);
+    return;
+  }
+
+  log("=== SSMD Expiry Watch ===");
+
+  const config = loadConfig();
+  log(`NATS: ${config.natsUrl}`);
+  log(`Subjects: ${config.subjectPrefix}.{closing,closed}.<ticker>`);
+  log(`Window: ${config.windowMs / 60_000}m, poll every ${config.pollIntervalMs / 1000}s`);
+
+  if (!Deno.env.get("DATABASE_URL")) {
+    logError("DATABASE_URL environment variable not set");
+    Deno.exit(1);
+  }
+
+  const db = getDb();
+  log("Database connected");
+
+  let nc: NatsConnection;
+  try {
+    nc = await connect({
+      servers: config.natsUrl,
+      reconnect: true,
+      maxReconnectAttempts: -1,
+      reconnectTimeWait: 2000,
+      pingInterval: 30000,
+      maxPingOut: 3,
+    });
+    log("NATS connected");
+  } catch (e) {
+    logError(`Failed to connect to NATS: ${e}`);
+    await closeDb();
+    Deno.exit(1);
+  }
+
+  // Monitor NATS connection status
+  (async () => {
+    for await (const status of nc.status()) {
+      switch (status.type) {
+        case Events.Disconnect:
+          logWarn("NATS disconnected");
+          break;
+        case Events.Reconnect:
+          log(`NATS reconnected to ${status.data}`);
+          break;
+        case Events.Error:
+          logError(`NATS error: ${status.data}`);
+          break;
+      }
+    }
+  })().catch(() => {});
+
+  const announced: Announced = { closingSoon: new Set(), closed: new Set() };
+  let published = 0;
+  let consecutiveErrors = 0;
+  const MAX_CONSECUTIVE_ERRORS = 5;
+
+  // Graceful shutdown
+  let shuttingDown = false;
+  const shutdown = async () => {
+    if (shuttingDown) return;
+    shuttingDown = true;
+    log(`Shutting down... published: ${published}`);
+    await nc.drain();
+    await closeDb();
+    Deno.exit(0);
+  };
+
+  Deno.addSignalListener("SIGINT", shutdown);
+  Deno.addSignalListener("SIGTERM", shutdown);
+
+  while (!shuttingDown) {
+    try {
+      const now = new Date();
+      // Look back one window so markets that closed while down still get "closed"
+      const from = new Date(now.getTime() - config.windowMs);
+      const to = new Date(now.getTime() + config.windowMs);
+      const markets = await listMarketsClosingBetween(db, from, to);
+
+      pruneAnnounced(announced, markets);
+      for (const notice of collectExpiryNotices(markets, now, announced)) {
+        nc.publish(expirySubject(config.subjectPrefix, notice), sc.encode(JSON.stringify(notice)));
+        published++;
+        log(`[${notice.type}] ${notice.ticker} closes ${notice.close_time}`);
+      }
+      consecutiveErrors = 0;
+    } catch (e) {
+      consecutiveErrors++;
+      logError(`Poll failed: ${e}`);
+      if (consecutiveErrors >= MAX_CONSECUTIVE_ERRORS) {
+        logError(`Fatal: ${MAX_CONSECUTIVE_ERRORS} consecutive errors — crashing for restart`);
+        await nc.drain().catch(() => {});
+        await closeDb().catch(() => {});
+        Deno.exit(1);
+      }
+    }
+    await new Promise((resolve) => setTimeout(resolve, config.pollIntervalMs));
+  }
+}
+
+if (import.meta.main) {
+  await runExpiryWatch();
+}
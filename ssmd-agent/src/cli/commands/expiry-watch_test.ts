@@ -0,0 +1,46 @@
+import { assertEquals } from "jsr:@std/assert";
+import {
+  type Announced,
+  collectExpiryNotices,
+  expirySubject,
+  pruneAnnounced,
+} from "./expiry-watch.ts";
+
+const now = new Date("2026-01-05T15:00:00Z");
+const soon = { ticker: "KXBTCD-26JAN0516-T97499.99", eventTicker: "KXBTCD-26JAN0516", closeTime: new Date("2026-01-05T15:30:00Z") };
+const past = { ticker: "KXETHD-26JAN0515-T3499.99", eventTicker: "KXETHD-26JAN0515", closeTime: new Date("2026-01-05T14:59:00Z") };
+
+function emptyAnnounced(): Announced {
+  return { closingSoon: new Set(), closed: new Set() };
+}
+
+Deno.test("collectExpiryNotices: closing_soon ahead of close, closed after", () => {
+  const notices = collectExpiryNotices([soon, past], now, emptyAnnounced());
+  assertEquals(notices.map((n) => [n.type, n.ticker, n.seconds_to_close]), [
+    ["closing_soon", soon.ticker, 1800],
+    ["closed", past.ticker, -60],
+  ]);
+});
+
+Deno.test("collectExpiryNotices: each notice is sent once", () => {
+  const announced = emptyAnnounced();
+  collectExpiryNotices([soon], now, announced);
+  assertEquals(collectExpiryNotices([soon], now, announced), []);
+
+  // The same market still gets "closed" once its close time passes
+  const later = new Date("2026-01-05T15:31:00Z");
+  assertEquals(collectExpiryNotices([soon], later, announced).map((n) => n.type), ["closed"]);
+});
+
+Deno.test("expirySubject: sanitizes tickers into one subject token", () => {
+  const [notice] = collectExpiryNotices([soon], now, emptyAnnounced());
+  assertEquals(expirySubject("prod.kalshi.expiry", notice), "prod.kalshi.expiry.closing.KXBTCD-26JAN0516-T9749999");
+});
+
+Deno.test("pruneAnnounced: forgets markets out of range", () => {
+  const announced = emptyAnnounced();
+  collectExpiryNotices([soon, past], now, announced);
+  pruneAnnounced(announced, [soon]);
+  assertEquals([...announced.closingSoon], [soon.ticker]);
+  assertEquals([...announced.closed], []);
+});
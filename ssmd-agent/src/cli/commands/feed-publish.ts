@@ -0,0 +1,179 @@
+// feed-publish.ts - Render exchanges/ configs into the ConfigMaps the operators
+// consume and apply them with server-side apply, so git stays the source of truth
+
+import { parse as parseYaml } from "yaml";
+import { basename, join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { FeedSchema } from "../../lib/types/feed.ts";
+import { kubectlWithInput, getCurrentEnvDisplay, type KubectlOptions } from "../utils/kubectl.ts";
+
+/** Field manager for server-side apply, so ownership shows who wrote each key */
+export const FIELD_MANAGER = "ssmd-cli";
+
+/** ConfigMap holding every file in exchanges/schemas/ */
+export const SCHEMAS_CONFIGMAP = "ssmd-schemas";
+
+export interface ConfigMapManifest {
+  apiVersion: "v1";
+  kind: "ConfigMap";
+  metadata: {
+    name: string;
+    labels: Record<string, string>;
+    annotations: Record<string, string>;
+  };
+  data: Record<string, string>;
+}
+
+export type PublishMode = "apply" | "dry-run" | "diff";
+
+export interface PublishOptions extends KubectlOptions {
+  /** Only publish feed-<feed> */
+  feed?: string;
+  mode?: PublishMode;
+}
+
+function configMap(name: string, source: string, data: Record<string, string>): ConfigMapManifest {
+  return {
+    apiVersion: "v1",
+    kind: "ConfigMap",
+    metadata: {
+      name,
+      labels: {
+        "app.kubernetes.io/part-of": "ssmd",
+        "app.kubernetes.io/managed-by": FIELD_MANAGER,
+      },
+      annotations: { "ssmd.io/source": source },
+    },
+    data,
+  };
+}
+
+/** Files in dir (sorted), or [] if the directory does not exist */
+async function readDirFiles(dir: string, filter: (name: string) => boolean): Promise<string[]> {
+  const names: string[] = [];
+  try {
+    for await (const entry of Deno.readDir(dir)) {
+      if (entry.isFile && filter(entry.name)) {
+        names.push(entry.name);
+      }
+    }
+  } catch (e) {
+    if (!(e instanceof Deno.errors.NotFound)) throw e;
+  }
+  return names.sort();
+}
+
+const isYaml = (name: string) => name.endsWith(".yaml") || name.endsWith(".yml");
+
+/**
+ * Render the ConfigMaps for an exchanges/ directory:
+ *   feeds/<name>.yaml        -> feed-<name> (key feed.yaml)
+ *   schemas/*                -> ssmd-schemas (one key per file)
+ *   environments/<env>.yaml  -> env-<env> (key env.yaml)
+ *
+ * Files are published verbatim, so comments and keys the CLI schema does not
+ * model (e.g. defaults) reach the operators. Feeds are validated first and an
+ * invalid one fails the whole render. With feed set, only feed-<feed> is rendered.
+ */
+export async function renderConfigMaps(exchangesRoot: string, feed?: string): Promise<ConfigMapManifest[]> {
+  const manifests: ConfigMapManifest[] = [];
+
+  const feedsDir = join(exchangesRoot, "feeds");
+  for (const file of await readDirFiles(feedsDir, isYaml)) {
+    const content = await Deno.readTextFile(join(feedsDir, file));
+    const parsed = FeedSchema.safeParse(parseYaml(content));
+    if (!parsed.success) {
+      const issues = parsed.error.issues.map((i) => `${i.path.join(".")}: ${i.message}`).join("; ");
+      throw new Error(`feeds/${file}: ${issues}`);
+    }
+    if (feed && parsed.data.name !== feed) continue;
+    manifests.push(configMap(`feed-${parsed.data.name}`, `exchanges/feeds/${file}`, { "feed.yaml": content }));
+  }
+  if (feed) {
+    if (manifests.length === 0) {
+      throw new Error(`Feed '${feed}' not found in ${feedsDir}`);
+    }
+    return manifests;
+  }
+
+  const schemasDir = join(exchangesRoot, "schemas");
+  const schemaFiles = await readDirFiles(schemasDir, (name) => !name.startsWith("."));
+  if (schemaFiles.length > 0) {
+    const data: Record<string, string> = {};
+    for (const file of schemaFiles) {
+      data[file] = await Deno.readTextFile(join(schemasDir, file));
+    }
+    manifests.push(configMap(SCHEMAS_CONFIGMAP, "exchanges/schemas", data));
+  }
+
+  const envsDir = join(exchangesRoot, "environments");
+  for (const file of await readDirFiles(envsDir, isYaml)) {
+    const content = await Deno.readTextFile(join(envsDir, file));
+    parseYaml(content); // fail on malformed YAML before anything is applied
+    const env = basename(file).replace(/\.ya?ml$/, "");
+    manifests.push(configMap(`env-${env}`, `exchanges/environments/${file}`, { "env.yaml": content }));
+  }
+
+  return manifests;
+}
+
+/** kubectl arguments for a publish mode */
+export function publishArgs(mode: PublishMode): string[] {
+  const ssa = ["--server-side", `--field-manager=${FIELD_MANAGER}`, "-f", "-"];
+  switch (mode) {
+    case "diff":
+      return ["diff", ...ssa];
+    case "dry-run":
+      return ["apply", "--dry-run=server", ...ssa];
+    case "apply":
+      return ["apply", ...ssa];
+  }
+}
+
+/**
+ * Apply (or diff / dry-run) the rendered ConfigMaps. Returns true when the
+ * cluster differs from git in diff mode, so callers can exit non-zero.
+ */
+export async function publishConfigMaps(
+  manifests: ConfigMapManifest[],
+  options: PublishOptions = {},
+): Promise<boolean> {
+  const mode = options.mode ?? "apply";
+  const input = JSON.stringify({ apiVersion: "v1", kind: "List", items: manifests });
+  const { code, stdout, stderr } = await kubectlWithInput(publishArgs(mode), input, options);
+
+  // kubectl diff exits 1 when there are differences, >1 on error
+  if (mode === "diff" && code === 1) {
+    console.log(stdout);
+    return true;
+  }
+  if (code !== 0) {
+    throw new Error(`kubectl failed: ${stderr.trim()}`);
+  }
+  if (stdout.trim()) {
+    console.log(stdout.trimEnd());
+  }
+  return false;
+}
+
+export async function runFeedPublish(exchangesRoot: string, options: PublishOptions): Promise<void> {
+  const mode = options.mode ?? "apply";
+  const manifests = await renderConfigMaps(exchangesRoot, options.feed);
+  if (manifests.length === 0) {
+    console.log(`Nothing to publish in ${exchangesRoot}`);
+    return;
+  }
+
+  const env = await getCurrentEnvDisplay(options.env);
+  const action = mode === "apply" ? "Publishing" : mode === "diff" ? "Diffing" : "Dry-run publishing";
+  console.log(`${action} ${manifests.length} ConfigMap(s) to ${env}:`);
+  for (const m of manifests) {
+    console.log(`  ${m.metadata.name.padEnd(24)} <- ${m.metadata.annotations["ssmd.io/source"]}`);
+  }
+  console.log();
+
+  const changed = await publishConfigMaps(manifests, options);
+  if (mode === "diff") {
+    console.log(changed ? "Cluster differs from git." : "Cluster matches git.");
+    if (changed) Deno.exit(1);
+  }
+}
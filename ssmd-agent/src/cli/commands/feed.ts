@@ -1,13 +1,21 @@
-// Feed commands: list, show, create
+// Feed commands: list, show, create, delete, calendar
 import { parse as parseYaml, stringify as stringifyYaml } from "yaml";
 import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
 import {
+  type Calendar,
+  CalendarSchema,
   FeedSchema,
   type Feed,
   type FeedType,
   getLatestVersion,
+  nextSession,
+  type Session,
+  WEEKDAYS,
+  type Weekday,
+  type WeekdaySession,
 } from "../../lib/types/feed.ts";
 import { TablePrinter } from "../utils/table.ts";
+import { moveToTrash, type Tombstone } from "./trash.ts";
 
 export interface CreateFeedOptions {
   type: FeedType;
@@ -107,6 +115,154 @@ export async function createFeed(
   await Deno.writeTextFile(path, yaml);
 }
 
+/**
+ * Environments (exchanges/environments/*.yaml) whose feed is name
+ */
+export async function environmentsReferencingFeed(exchangesRoot: string, name: string): Promise<string[]> {
+  const envsDir = join(exchangesRoot, "environments");
+  const envs: string[] = [];
+  try {
+    for await (const entry of Deno.readDir(envsDir)) {
+      if (!entry.isFile || !/\.ya?ml$/.test(entry.name)) continue;
+      const env = parseYaml(await Deno.readTextFile(join(envsDir, entry.name))) as { feed?: unknown } | null;
+      if (env?.feed === name) {
+        envs.push(entry.name.replace(/\.ya?ml$/, ""));
+      }
+    }
+  } catch (e) {
+    if (!(e instanceof Deno.errors.NotFound)) throw e;
+  }
+  return envs.sort();
+}
+
+/**
+ * Move a feed to exchanges/.trash. Refuses while environments still use the
+ * feed, unless force is set; the tombstone then records them.
+ */
+export async function deleteFeed(exchangesRoot: string, name: string, force = false): Promise<Tombstone> {
+  const path = join("feeds", `${name}.yaml`);
+  try {
+    await Deno.stat(join(exchangesRoot, path));
+  } catch (e) {
+    if (e instanceof Deno.errors.NotFound) throw new Error(`Feed '${name}' not found`);
+    throw e;
+  }
+
+  const referencedBy = await environmentsReferencingFeed(exchangesRoot, name);
+  if (referencedBy.length > 0 && !force) {
+    throw new Error(
+      `Feed '${name}' is used by environment(s): ${referencedBy.join(", ")}. Delete those first or pass --force.`
+    );
+  }
+  return moveToTrash(exchangesRoot, "feed", name, path, referencedBy);
+}
+
+export interface SetCalendarOptions {
+  timezone?: string;
+  holidayCalendar?: string;
+  openTime?: string;
+  closeTime?: string;
+  /** Apply openTime/closeTime (or closed) to this weekday instead of the default hours */
+  weekday?: Weekday;
+  closed?: boolean;
+}
+
+export interface AddHolidayOptions {
+  name?: string;
+  /** Early close (HH:MM) - records a half day instead of a full holiday */
+  closeTime?: string;
+}
+
+/**
+ * Apply update to a feed's calendar and write the feed back. The file is
+ * edited as parsed YAML so keys outside the feed schema (message_types,
+ * defaults) are kept.
+ */
+async function updateCalendar(
+  feedsDir: string,
+  name: string,
+  update: (calendar: Calendar) => Calendar
+): Promise<Calendar> {
+  const path = join(feedsDir, `${name}.yaml`);
+  let raw: Record<string, unknown>;
+  try {
+    raw = parseYaml(await Deno.readTextFile(path)) as Record<string, unknown>;
+  } catch (e) {
+    if (e instanceof Deno.errors.NotFound) throw new Error(`Feed '${name}' not found`);
+    throw e;
+  }
+
+  const current = CalendarSchema.parse(raw.calendar ?? {});
+  const calendar = CalendarSchema.parse(update(structuredClone(current)));
+  raw.calendar = calendar;
+  FeedSchema.parse(raw);
+
+  await Deno.writeTextFile(path, stringifyYaml(raw, { indent: 2 }));
+  return calendar;
+}
+
+/**
+ * Set the timezone, default hours, or one weekday's hours of a feed calendar
+ */
+export function setCalendar(feedsDir: string, name: string, options: SetCalendarOptions): Promise<Calendar> {
+  return updateCalendar(feedsDir, name, (calendar) => {
+    if (options.timezone !== undefined) calendar.timezone = options.timezone;
+    if (options.holidayCalendar !== undefined) calendar.holiday_calendar = options.holidayCalendar;
+
+    if (options.weekday) {
+      if (!options.closed && (!options.openTime || !options.closeTime)) {
+        throw new Error("--weekday needs --open and --close, or --closed");
+      }
+      const session: WeekdaySession = options.closed
+        ? { closed: true }
+        : { open_time: options.openTime, close_time: options.closeTime };
+      const weekdays = { ...calendar.weekdays };
+      weekdays[options.weekday] = session;
+      calendar.weekdays = weekdays;
+    } else {
+      if (options.closed) throw new Error("--closed applies to a --weekday");
+      if (options.openTime !== undefined) calendar.open_time = options.openTime;
+      if (options.closeTime !== undefined) calendar.close_time = options.closeTime;
+    }
+    return calendar;
+  });
+}
+
+/**
+ * Add a holiday (no session) or, with closeTime, a half day to a feed calendar.
+ * Lists are kept sorted by date.
+ */
+export function addHoliday(
+  feedsDir: string,
+  name: string,
+  date: string,
+  options: AddHolidayOptions = {}
+): Promise<Calendar> {
+  return updateCalendar(feedsDir, name, (calendar) => {
+    if (options.closeTime) {
+      const halfDays = [...(calendar.half_days ?? []), { date, close_time: options.closeTime, name: options.name }];
+      calendar.half_days = halfDays.sort((a, b) => a.date.localeCompare(b.date));
+    } else {
+      const holidays = [...(calendar.holidays ?? []), { date, name: options.name }];
+      calendar.holidays = holidays.sort((a, b) => a.date.localeCompare(b.date));
+    }
+    return calendar;
+  });
+}
+
+/**
+ * Next collection window of a feed at from, or null if none within a year
+ */
+export async function feedNextSession(feedsDir: string, name: string, from = new Date()): Promise<Session | null> {
+  const feed = await showFeed(feedsDir, name);
+  if (!feed) throw new Error(`Feed '${name}' not found`);
+  return nextSession(feed.calendar, from);
+}
+
+export function isWeekday(value: string): value is Weekday {
+  return (WEEKDAYS as readonly string[]).includes(value);
+}
+
 /**
  * Print feed list as table
  */
@@ -166,5 +322,34 @@ export function printFeed(feed: Feed): void {
     if (feed.calendar.open_time && feed.calendar.close_time) {
       console.log(`  Hours:    ${feed.calendar.open_time} - ${feed.calendar.close_time}`);
     }
+    for (const day of WEEKDAYS) {
+      const session = feed.calendar.weekdays?.[day];
+      if (!session) continue;
+      console.log(`  ${day}:      ${session.closed ? "closed" : `${session.open_time} - ${session.close_time}`}`);
+    }
+    if (feed.calendar.holiday_calendar) {
+      console.log(`  Holiday calendar: ${feed.calendar.holiday_calendar}`);
+    }
+    for (const h of feed.calendar.holidays ?? []) {
+      console.log(`  Holiday:  ${h.date}${h.name ? ` (${h.name})` : ""}`);
+    }
+    for (const h of feed.calendar.half_days ?? []) {
+      console.log(`  Half day: ${h.date} closes ${h.close_time}${h.name ? ` (${h.name})` : ""}`);
+    }
+  }
+}
+
+/**
+ * Print a collection window in UTC and the feed timezone
+ */
+export function printSession(session: Session | null, calendar?: Calendar): void {
+  if (!session) {
+    console.log("No session within the next year.");
+    return;
   }
+  const timezone = calendar?.timezone ?? "UTC";
+  const local = (t: Date) => t.toLocaleString("en-US", { timeZone: timezone, hourCycle: "h23" });
+  console.log(`Date:  ${session.date}${session.halfDay ? " (half day)" : ""}`);
+  console.log(`Open:  ${session.open.toISOString()}  (${local(session.open)} ${timezone})`);
+  console.log(`Close: ${session.close.toISOString()}  (${local(session.close)} ${timezone})`);
 }
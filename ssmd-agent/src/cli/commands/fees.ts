@@ -3,7 +3,7 @@
  */
 import { getDb, closeDb, upsertFeeChanges, seedMissingFees } from "../../lib/db/mod.ts";
 import { createKalshiClient } from "../../lib/api/kalshi.ts";
-import { FeeTypeSchema } from "../../lib/types/fee.ts";
+import { FeeTypeSchema, FeeUpdateSchema, type FeeUpdate } from "../../lib/types/fee.ts";
 
 const API_TIMEOUT_MS = 10000;
 
@@ -15,9 +15,10 @@ function getApiKey(): string {
   return Deno.env.get("SSMD_DATA_API_KEY") ?? "";
 }
 
-async function apiRequest<T>(path: string): Promise<T> {
+async function apiRequest<T>(path: string, init: RequestInit = {}): Promise<T> {
   const res = await fetch(`${getApiUrl()}${path}`, {
-    headers: { "X-API-Key": getApiKey() },
+    ...init,
+    headers: { "X-API-Key": getApiKey(), "Content-Type": "application/json" },
     signal: AbortSignal.timeout(API_TIMEOUT_MS),
   });
   if (!res.ok) {
@@ -166,6 +167,66 @@ export async function showFeeList(limit = 50): Promise<void> {
   }
 }
 
+// series_fees row as serialized by the fee history and PUT endpoints
+interface ApiFeePeriod {
+  seriesTicker: string;
+  feeType: string;
+  feeMultiplier: string;
+  effectiveFrom: string;
+  effectiveTo: string | null;
+  sourceId: string | null;
+}
+
+/**
+ * Show every fee period for a series, newest first (via API)
+ */
+export async function showFeeHistory(series: string): Promise<void> {
+  const result = await apiRequest<{ fees: ApiFeePeriod[] }>(`/v1/fees/${encodeURIComponent(series)}/history`);
+  if (result.fees.length === 0) {
+    console.log(`\nNo fee history for ${series}.`);
+    return;
+  }
+
+  console.log(`\n=== Fee History: ${series} (${result.fees.length}) ===`);
+  console.log("");
+  console.log("Fee Type                    Multiplier  From        To          Source");
+  console.log("-".repeat(80));
+  for (const fee of result.fees) {
+    const type = fee.feeType.padEnd(26);
+    const mult = Number(fee.feeMultiplier).toFixed(4).padStart(10);
+    const from = fee.effectiveFrom.slice(0, 10);
+    const to = (fee.effectiveTo?.slice(0, 10) ?? "-").padEnd(10);
+    console.log(`${type}  ${mult}  ${from}  ${to}  ${fee.sourceId ?? ""}`);
+  }
+}
+
+/**
+ * Build a fee update from `ssmd fees set` flags; throws on invalid input
+ */
+export function feeUpdateFromFlags(flags: Record<string, unknown>): FeeUpdate {
+  const parsed = FeeUpdateSchema.safeParse({
+    fee_type: flags["fee-type"],
+    fee_multiplier: flags.multiplier !== undefined ? Number(flags.multiplier) : undefined,
+    effective_from: flags["effective-from"],
+  });
+  if (!parsed.success) {
+    throw new Error(parsed.error.issues.map((i) => `${i.path.join(".")}: ${i.message}`).join("; "));
+  }
+  return parsed.data;
+}
+
+/**
+ * Set a series fee schedule (via API, needs admin:write)
+ */
+export async function setSeriesFee(series: string, update: FeeUpdate): Promise<void> {
+  const fee = await apiRequest<ApiFeePeriod>(`/v1/fees/${encodeURIComponent(series)}`, {
+    method: "PUT",
+    body: JSON.stringify(update),
+  });
+  const to = fee.effectiveTo ? ` until ${fee.effectiveTo}` : "";
+  console.log(`Set ${series}: ${fee.feeType} x${Number(fee.feeMultiplier).toFixed(4)} from ${fee.effectiveFrom}${to}`);
+}
+
 /**
  * Handle fees subcommands
  */
@@ -202,7 +263,11 @@ export async function handleFees(
     case "list": {
       const limit = flags.limit ? Number(flags.limit) : 50;
       try {
-        await showFeeList(limit);
+        if (flags.series) {
+          await showFeeHistory(String(flags.series));
+        } else {
+          await showFeeList(limit);
+        }
       } catch (e) {
         console.error(`Failed to list fees: ${(e as Error).message}`);
         Deno.exit(1);
@@ -210,6 +275,21 @@ export async function handleFees(
       break;
     }
 
+    case "set": {
+      const series = flags._ && (flags._ as unknown[])[2];
+      if (!series) {
+        console.error("Usage: ssmd fees set <series> --fee-type TYPE [--multiplier N] [--effective-from TS]");
+        Deno.exit(1);
+      }
+      try {
+        await setSeriesFee(String(series), feeUpdateFromFlags(flags));
+      } catch (e) {
+        console.error(`Failed to set fee: ${(e as Error).message}`);
+        Deno.exit(1);
+      }
+      break;
+    }
+
     default:
       console.log("Usage: ssmd fees <command>");
       console.log();
@@ -217,12 +297,19 @@ export async function handleFees(
       console.log("  sync         Sync fee schedules from Kalshi API");
       console.log("  stats        Show fee schedule statistics");
       console.log("  list         List current fee schedules");
+      console.log("  set <series> Set a series fee schedule (admin)");
       console.log();
       console.log("Options for sync:");
       console.log("  --dry-run    Fetch but don't write to database");
       console.log();
       console.log("Options for list:");
       console.log("  --limit N    Maximum records to show (default: 50)");
+      console.log("  --series S   Full fee history for one series");
+      console.log();
+      console.log("Options for set:");
+      console.log("  --fee-type T          quadratic, quadratic_with_maker_fees or flat");
+      console.log("  --multiplier N        Fee multiplier (default: 1.0)");
+      console.log("  --effective-from TS   RFC 3339 start (default: now)");
       Deno.exit(1);
   }
 }
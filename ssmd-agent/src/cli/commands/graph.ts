@@ -0,0 +1,176 @@
+// Graph command: dependency graph of feeds, schemas, environments and deployed CRs
+// ssmd graph [--output dot|json] [--k8s] [--impact <node>]
+import { findExchangesRoot } from "../utils/paths.ts";
+import { kubectl } from "../utils/kubectl.ts";
+import { type ConfigSnapshot, loadWorkingTree } from "./diff.ts";
+
+export type NodeKind = "feed" | "schema" | "environment" | "connector" | "archiver";
+
+export interface GraphNode {
+  /** Unique id, e.g. "schema:kalshi/trade:v1" */
+  id: string;
+  kind: NodeKind;
+  label: string;
+}
+
+/** `from` depends on `to` */
+export interface GraphEdge {
+  from: string;
+  to: string;
+  relation: "of" | "uses" | "compatible_with" | "deploys";
+}
+
+export interface ConfigGraph {
+  nodes: GraphNode[];
+  edges: GraphEdge[];
+}
+
+/** A Connector or Archiver CR as returned by kubectl get -o json */
+export interface FeedResource {
+  kind: "connector" | "archiver";
+  name: string;
+  feed: string;
+}
+
+interface GraphFlags {
+  output?: string;
+  k8s?: boolean;
+  impact?: string;
+  env?: string;
+}
+
+function isObject(v: unknown): v is Record<string, unknown> {
+  return typeof v === "object" && v !== null && !Array.isArray(v);
+}
+
+/**
+ * Build the graph from a workspace snapshot. Schemas point at their feed and
+ * the versions they declare compatible_with; environments point at their feed
+ * and schema. References to missing entities still get a node, so dangling
+ * references show up in the output.
+ */
+export function buildGraph(snapshot: ConfigSnapshot, resources: FeedResource[] = []): ConfigGraph {
+  const nodes = new Map<string, GraphNode>();
+  const edges: GraphEdge[] = [];
+  const node = (kind: NodeKind, label: string): string => {
+    const id = `${kind}:${label}`;
+    if (!nodes.has(id)) nodes.set(id, { id, kind, label });
+    return id;
+  };
+
+  for (const name of snapshot.feeds.keys()) {
+    node("feed", name);
+  }
+
+  for (const doc of snapshot.schemas.values()) {
+    const s = isObject(doc) ? doc : {};
+    if (typeof s.feed !== "string" || typeof s.type !== "string" || typeof s.version !== "string") continue;
+    const id = node("schema", `${s.feed}/${s.type}:${s.version}`);
+    edges.push({ from: id, to: node("feed", s.feed), relation: "of" });
+    if (Array.isArray(s.compatible_with)) {
+      for (const v of s.compatible_with) {
+        edges.push({ from: id, to: node("schema", `${s.feed}/${s.type}:${v}`), relation: "compatible_with" });
+      }
+    }
+  }
+
+  for (const [name, doc] of snapshot.environments) {
+    const env = isObject(doc) ? doc : {};
+    const id = node("environment", name);
+    if (typeof env.feed !== "string") continue;
+    edges.push({ from: id, to: node("feed", env.feed), relation: "uses" });
+    if (typeof env.schema === "string") {
+      edges.push({ from: id, to: node("schema", `${env.feed}/${env.schema}`), relation: "uses" });
+    }
+  }
+
+  for (const r of resources) {
+    edges.push({ from: node(r.kind, r.name), to: node("feed", r.feed), relation: "deploys" });
+  }
+
+  return { nodes: [...nodes.values()], edges };
+}
+
+/**
+ * Nodes that depend on `id` directly through "uses" or "deploys" edges, e.g.
+ * the environments that break if a schema version is deprecated. Schemas
+ * declaring compatible_with the target are not dependents: they replace it.
+ */
+export function dependents(graph: ConfigGraph, id: string): GraphNode[] {
+  const byId = new Map(graph.nodes.map((n) => [n.id, n]));
+  const ids = new Set(
+    graph.edges.filter((e) => e.to === id && (e.relation === "uses" || e.relation === "deploys")).map((e) => e.from),
+  );
+  return [...ids].sort().map((i) => byId.get(i)!);
+}
+
+function quote(s: string): string {
+  return `"${s.replace(/\\/g, "\\\\").replace(/"/g, '\\"')}"`;
+}
+
+const SHAPES: Record<NodeKind, string> = {
+  feed: "box",
+  schema: "note",
+  environment: "ellipse",
+  connector: "component",
+  archiver: "cylinder",
+};
+
+/** Graphviz digraph, one shape per node kind */
+export function toDot(graph: ConfigGraph): string {
+  const lines = ["digraph ssmd {", "  rankdir=LR;"];
+  for (const n of graph.nodes) {
+    lines.push(`  ${quote(n.id)} [label=${quote(n.label)}, shape=${SHAPES[n.kind]}];`);
+  }
+  for (const e of graph.edges) {
+    const style = e.relation === "compatible_with" ? ", style=dashed" : "";
+    lines.push(`  ${quote(e.from)} -> ${quote(e.to)} [label=${quote(e.relation)}${style}];`);
+  }
+  lines.push("}");
+  return lines.join("\n") + "\n";
+}
+
+/** Connector and Archiver CRs in the current environment's namespace */
+async function loadFeedResources(env?: string): Promise<FeedResource[]> {
+  const resources: FeedResource[] = [];
+  for (const kind of ["connector", "archiver"] as const) {
+    const list = JSON.parse(await kubectl(["get", `${kind}s`, "-o", "json"], { env }));
+    for (const item of list.items ?? []) {
+      if (typeof item.spec?.feed === "string") {
+        resources.push({ kind, name: item.metadata.name, feed: item.spec.feed });
+      }
+    }
+  }
+  return resources;
+}
+
+export async function handleGraph(flags: GraphFlags): Promise<void> {
+  const output = flags.output ?? "dot";
+  if (output !== "dot" && output !== "json") {
+    console.error(`Unknown output format: ${output} (expected dot or json)`);
+    Deno.exit(1);
+  }
+
+  const snapshot = await loadWorkingTree(await findExchangesRoot());
+  const graph = buildGraph(snapshot, flags.k8s ? await loadFeedResources(flags.env) : []);
+
+  if (flags.impact) {
+    if (!graph.nodes.some((n) => n.id === flags.impact)) {
+      console.error(`Unknown node: ${flags.impact} (e.g. schema:kalshi/trade:v1, feed:kalshi)`);
+      Deno.exit(1);
+    }
+    const affected = dependents(graph, flags.impact);
+    if (output === "json") {
+      console.log(JSON.stringify({ node: flags.impact, dependents: affected }, null, 2));
+    } else {
+      for (const n of affected) console.log(n.id);
+    }
+    return;
+  }
+
+  if (output === "json") {
+    console.log(JSON.stringify(graph, null, 2));
+  } else {
+    console.log(toDot(graph).trimEnd());
+  }
+}
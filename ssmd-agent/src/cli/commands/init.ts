@@ -1,14 +1,201 @@
 // Init command: create exchanges directory structure
 import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { stringify as stringifyYaml } from "yaml";
+import { type Feed, FeedSchema } from "../../lib/types/feed.ts";
+import { BUILTIN_RAW_SCHEMAS, type RawSchema, RawSchemaSchema } from "../../lib/types/raw-schema.ts";
 
 const SUBDIRS = ["feeds", "schemas", "environments"];
 
 const GITKEEP_DIRS = ["feeds", "schemas", "environments"];
 
+export const INIT_TEMPLATES = ["kalshi", "kraken", "polymarket"] as const;
+export type InitTemplate = typeof INIT_TEMPLATES[number];
+
+export interface InitOptions {
+  template?: InitTemplate;
+}
+
+/** Per-exchange starting point: feed, message types, raw schemas and env keys */
+interface ExchangeTemplate {
+  feed: Omit<Feed, "versions"> & { versions: Omit<Feed["versions"][0], "effective_from">[] };
+  messageTypes: Record<string, Record<string, unknown>>;
+  schemas: RawSchema[];
+  // Default schema of the environment, as type:version
+  envSchema: string;
+  keys?: Record<string, Record<string, unknown>>;
+}
+
+const PIPELINE_TS = { timestamp_field: "_received_at", timestamp_format: "pipeline", sequenced: false };
+
+/** Raw schemas under another feed name (kraken spot is "kraken-spot" in the built-ins) */
+function builtinSchemas(feed: string, as = feed): RawSchema[] {
+  return BUILTIN_RAW_SCHEMAS.filter((s) => s.feed === feed).map((s) => ({ ...s, feed: as }));
+}
+
+const POLYMARKET_ENVELOPE: RawSchema["fields"] = {
+  _received_at: { type: "integer", required: true },
+  _nats_seq: { type: "integer" },
+  event_type: { type: "string", required: true },
+  asset_id: { type: "string", required: true },
+  market: { type: "string" },
+  timestamp: { type: "string" },
+};
+
+const TEMPLATES: Record<InitTemplate, ExchangeTemplate> = {
+  kalshi: {
+    feed: {
+      name: "kalshi",
+      display_name: "Kalshi Exchange",
+      type: "websocket",
+      status: "active",
+      versions: [{
+        version: "v1",
+        protocol: { transport: "wss", message: "json" },
+        endpoint: "wss://api.elections.kalshi.com/trade-api/ws/v2",
+        auth_method: "api_key",
+        rate_limit_per_second: 10,
+      }],
+    },
+    messageTypes: {
+      ticker: { identifier_field: "market_ticker", timestamp_field: "ts", timestamp_format: "unix_seconds", sequenced: false },
+      trade: {
+        identifier_field: "market_ticker",
+        timestamp_field: "ts",
+        timestamp_format: "unix_seconds",
+        sequenced: true,
+        sequence_field: "exchange_seq",
+        sequence_scope: "per_subscription",
+        sequence_group_by: "sid",
+      },
+      market_lifecycle_v2: { identifier_field: "market_ticker", ...PIPELINE_TS },
+    },
+    schemas: builtinSchemas("kalshi"),
+    envSchema: "trade:v1",
+    keys: {
+      kalshi: {
+        type: "api_key",
+        description: "Kalshi API key ID and RSA private key (PEM)",
+        required: true,
+        fields: ["api_key", "private_key"],
+        source: "env:KALSHI_API_KEY,KALSHI_PRIVATE_KEY",
+      },
+    },
+  },
+  kraken: {
+    feed: {
+      name: "kraken",
+      display_name: "Kraken Exchange",
+      type: "websocket",
+      status: "active",
+      versions: [{
+        version: "v2",
+        protocol: { transport: "wss", message: "json" },
+        endpoint: "wss://ws.kraken.com/v2",
+        auth_method: "none",
+      }],
+    },
+    messageTypes: {
+      ticker: { identifier_field: "symbol", ...PIPELINE_TS, fanout: true },
+      trade: { identifier_field: "symbol", timestamp_field: "timestamp", timestamp_format: "iso8601", sequenced: false, fanout: true },
+    },
+    schemas: builtinSchemas("kraken-spot", "kraken"),
+    envSchema: "trade:v1",
+  },
+  polymarket: {
+    feed: {
+      name: "polymarket",
+      display_name: "Polymarket CLOB",
+      type: "websocket",
+      status: "active",
+      versions: [{
+        version: "v1",
+        protocol: { transport: "wss", message: "json" },
+        endpoint: "wss://ws-subscriptions-clob.polymarket.com/ws/market",
+        auth_method: "none",
+      }],
+    },
+    messageTypes: {
+      last_trade_price: { identifier_field: "asset_id", ...PIPELINE_TS },
+      book: { identifier_field: "asset_id", ...PIPELINE_TS },
+      price_change: { identifier_field: "asset_id", ...PIPELINE_TS, fanout: true },
+      best_bid_ask: { identifier_field: "asset_id", ...PIPELINE_TS },
+    },
+    schemas: [
+      {
+        feed: "polymarket",
+        type: "last_trade_price",
+        version: "v1",
+        fields: {
+          ...POLYMARKET_ENVELOPE,
+          price: { type: "string", required: true },
+          size: { type: "string", required: true },
+          side: { type: "string" },
+        },
+      },
+      {
+        feed: "polymarket",
+        type: "book",
+        version: "v1",
+        fields: { ...POLYMARKET_ENVELOPE, bids: { type: "array", required: true }, asks: { type: "array", required: true } },
+      },
+    ],
+    envSchema: "last_trade_price:v1",
+  },
+};
+
+export function isInitTemplate(name: string): name is InitTemplate {
+  return (INIT_TEMPLATES as readonly string[]).includes(name);
+}
+
 /**
- * Initialize the exchanges directory structure
+ * Files for a template, keyed by path relative to the exchanges directory:
+ * the feed (with message types), one raw schema per message type the CLI can
+ * validate, and a dev environment publishing to NATS. Everything is checked
+ * against the CLI schemas before it is returned.
  */
-export async function initExchanges(path?: string): Promise<string> {
+export function renderTemplate(template: InitTemplate, today = new Date()): Record<string, string> {
+  const t = TEMPLATES[template];
+  const name = t.feed.name;
+  const effectiveFrom = today.toISOString().split("T")[0];
+
+  const feed = {
+    ...t.feed,
+    versions: t.feed.versions.map((v) => ({ version: v.version, effective_from: effectiveFrom, ...v })),
+  };
+  FeedSchema.parse(feed);
+
+  const files: Record<string, string> = {
+    [`feeds/${name}.yaml`]: stringifyYaml({ ...feed, message_types: t.messageTypes }, { indent: 2 }),
+  };
+
+  for (const schema of t.schemas) {
+    RawSchemaSchema.parse(schema);
+    files[`schemas/${name}-${schema.type}.yaml`] = stringifyYaml(schema, { indent: 2 });
+  }
+
+  const env = {
+    name: `${name}-dev`,
+    feed: name,
+    schema: t.envSchema,
+    ...(t.keys ? { keys: t.keys } : {}),
+    transport: {
+      type: "nats",
+      url: "nats://nats.nats.svc.cluster.local:4222",
+      stream: `DEV_${name.toUpperCase()}`,
+      subject_prefix: `dev.${name}`,
+    },
+    storage: { type: "local", path: "/var/lib/ssmd/data" },
+  };
+  files[`environments/${name}-dev.yaml`] = stringifyYaml(env, { indent: 2 });
+
+  return files;
+}
+
+/**
+ * Initialize the exchanges directory structure, optionally with a template's
+ * feed, schemas and environment
+ */
+export async function initExchanges(path?: string, options: InitOptions = {}): Promise<string> {
   const targetDir = path ?? join(Deno.cwd(), "exchanges");
 
   // Check if already exists
@@ -21,6 +208,9 @@ export async function initExchanges(path?: string): Promise<string> {
     if (!(e instanceof Deno.errors.NotFound)) throw e;
   }
 
+  const files = options.template ? renderTemplate(options.template) : {};
+  const populated = new Set(Object.keys(files).map((f) => f.split("/")[0]));
+
   // Create root directory
   await Deno.mkdir(targetDir, { recursive: true });
   console.log(`Created: ${targetDir}`);
@@ -31,11 +221,17 @@ export async function initExchanges(path?: string): Promise<string> {
     await Deno.mkdir(subdirPath, { recursive: true });
     console.log(`Created: ${subdirPath}`);
 
-    if (GITKEEP_DIRS.includes(subdir)) {
+    if (GITKEEP_DIRS.includes(subdir) && !populated.has(subdir)) {
       const gitkeepPath = join(subdirPath, ".gitkeep");
       await Deno.writeTextFile(gitkeepPath, "");
     }
   }
 
+  for (const [file, content] of Object.entries(files)) {
+    const filePath = join(targetDir, file);
+    await Deno.writeTextFile(filePath, content);
+    console.log(`Created: ${filePath}`);
+  }
+
   return targetDir;
 }
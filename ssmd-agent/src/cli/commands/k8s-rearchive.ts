@@ -0,0 +1,408 @@
+// ssmd k8s rearchive: rebuild part of an archived day from JetStream. A one-shot
+// Job runs the archiver in replay mode from a stream sequence into an empty
+// directory and syncs it under <prefix>/reprocess/<job>/, next to (not over)
+// the day's original files, for recovering from corrupt files without
+// re-running the whole day
+import { getCurrentEnvDisplay, kubectl, type KubectlOptions, kubectlWithInput } from "../utils/kubectl.ts";
+import { DEFAULT_IMAGE as DEFAULT_ARCHIVER_IMAGE } from "./archiver-deploy.ts";
+import { FIELD_MANAGER } from "./feed-publish.ts";
+
+export interface RearchiveFlags {
+  _: (string | number)[];
+  env?: string;
+  namespace?: string;
+  "from-sequence"?: string;
+  "to-sequence"?: string;
+  source?: string;
+  stream?: string;
+  filter?: string;
+  image?: string;
+  "sync-image"?: string;
+  bucket?: string;
+  prefix?: string;
+  "dry-run"?: boolean;
+}
+
+const DEFAULT_SYNC_IMAGE = "ghcr.io/aaronwald/ssmd-sync:latest";
+const DEFAULT_NATS_URL = "nats://nats.nats.svc.cluster.local:4222";
+const DEFAULT_ROTATION = "15m";
+
+/** Finished Jobs (and their pods' logs) are kept a day for inspection */
+const TTL_SECONDS_AFTER_FINISHED = 86400;
+/** A replay that has not finished in 6h is stuck, not slow */
+const ACTIVE_DEADLINE_SECONDS = 6 * 3600;
+
+/** The ArchiverSchedule template fields a rearchive reads */
+interface ArchiverTemplate {
+  image?: string;
+  feed?: string;
+  sources?: { name: string; stream: string; filter?: string; feed?: string }[];
+  source?: { url?: string; stream?: string; filter?: string };
+  storage?: {
+    remote?: { type?: string; bucket?: string; prefix?: string; secretRef?: string; endpoint?: string; region?: string };
+  };
+  rotation?: { maxFileAge?: string };
+  sync?: { image?: string };
+  serviceAccountName?: string;
+  podTemplate?: { imagePullSecrets?: { name: string }[] };
+}
+
+export interface ArchiverSchedule {
+  metadata: { name: string };
+  spec: { feed: string; template: ArchiverTemplate };
+}
+
+export interface RearchiveSource {
+  name: string;
+  stream: string;
+  filter: string;
+  feed: string;
+}
+
+export interface RemoteTarget {
+  type: string;
+  bucket: string;
+  prefix: string;
+  secretRef?: string;
+  endpoint?: string;
+  region?: string;
+}
+
+/** Everything needed to render a rearchive Job */
+export interface RearchiveTarget {
+  feed: string;
+  /** YYYY-MM-DD */
+  date: string;
+  fromSequence: number;
+  toSequence?: number;
+  source: RearchiveSource;
+  natsUrl: string;
+  rotation: string;
+  image: string;
+  syncImage: string;
+  remote: RemoteTarget;
+  serviceAccountName?: string;
+  imagePullSecrets?: { name: string }[];
+}
+
+/** Job name, DNS-1123 and at most 63 characters so it is also a valid label value */
+export function rearchiveJobName(feed: string, date: string, fromSequence: number): string {
+  const name = `rearchive-${feed}-${date}-${fromSequence}`.toLowerCase().replace(/[^a-z0-9-]+/g, "-");
+  return name.slice(0, 63).replace(/-+$/, "");
+}
+
+/** Remote prefix the replayed files are synced under */
+export function reprocessPrefix(prefix: string, jobName: string): string {
+  const base = prefix.replace(/\/+$/, "");
+  return base ? `${base}/reprocess/${jobName}` : `reprocess/${jobName}`;
+}
+
+/**
+ * Resolve a rearchive target from the feed's ArchiverSchedule template, with
+ * flags overriding it. "{date}" in template strings is expanded as the
+ * schedule controller does. Throws when the stream source or bucket cannot
+ * be determined.
+ */
+export function resolveTarget(
+  feed: string,
+  date: string,
+  fromSequence: number,
+  toSequence: number | undefined,
+  schedule: ArchiverSchedule | null,
+  flags: Pick<RearchiveFlags, "source" | "stream" | "filter" | "image" | "sync-image" | "bucket" | "prefix">,
+): RearchiveTarget {
+  const t: ArchiverTemplate = JSON.parse(
+    JSON.stringify(schedule?.spec.template ?? {}).replaceAll("{date}", date),
+  );
+
+  const sources: RearchiveSource[] = (t.sources ?? []).map((s) => ({
+    name: s.name,
+    stream: s.stream,
+    filter: s.filter ?? "",
+    feed: s.feed || t.feed || feed,
+  }));
+  if (sources.length === 0 && t.source?.stream) {
+    sources.push({ name: "main", stream: t.source.stream, filter: t.source.filter ?? "", feed: t.feed || feed });
+  }
+
+  let source: RearchiveSource | undefined;
+  if (flags.source) {
+    source = sources.find((s) => s.name === flags.source);
+    if (!source && !flags.stream) {
+      throw new Error(`No source '${flags.source}' in the ${feed} template (sources: ${sources.map((s) => s.name).join(", ") || "none"})`);
+    }
+  } else if (sources.length === 1) {
+    source = sources[0];
+  } else if (sources.length > 1 && !flags.stream) {
+    throw new Error(`The ${feed} template has ${sources.length} sources; pick one with --source (${sources.map((s) => s.name).join(", ")})`);
+  }
+  if (flags.stream) {
+    source = { name: flags.source ?? source?.name ?? "main", stream: flags.stream, filter: source?.filter ?? "", feed: source?.feed ?? feed };
+  }
+  if (!source) {
+    throw new Error(`No ArchiverSchedule for ${feed}; pass --stream (and --filter, --bucket)`);
+  }
+  if (flags.filter !== undefined) source = { ...source, filter: flags.filter };
+  if (!source.filter) {
+    throw new Error(`No filter subject for stream ${source.stream}; pass --filter`);
+  }
+
+  const remote = t.storage?.remote ?? {};
+  const bucket = flags.bucket ?? remote.bucket;
+  if (!bucket) {
+    throw new Error(`No remote bucket for ${feed}; pass --bucket`);
+  }
+
+  return {
+    feed,
+    date,
+    fromSequence,
+    toSequence,
+    source,
+    natsUrl: t.source?.url || DEFAULT_NATS_URL,
+    rotation: t.rotation?.maxFileAge || DEFAULT_ROTATION,
+    image: flags.image ?? (t.image || DEFAULT_ARCHIVER_IMAGE),
+    syncImage: flags["sync-image"] ?? (t.sync?.image || DEFAULT_SYNC_IMAGE),
+    remote: {
+      type: remote.type || "gcs",
+      bucket,
+      prefix: flags.prefix ?? remote.prefix ?? "",
+      secretRef: remote.secretRef || undefined,
+      endpoint: remote.endpoint || undefined,
+      region: remote.region || undefined,
+    },
+    serviceAccountName: t.serviceAccountName || undefined,
+    imagePullSecrets: t.podTemplate?.imagePullSecrets,
+  };
+}
+
+/**
+ * archiver.yaml for the replay, in the layout the operator writes. The
+ * consumer name is only a label: replays read through an ephemeral consumer
+ * and never touch the live archiver's durable one.
+ */
+export function rearchiveConfig(target: RearchiveTarget, jobName: string): string {
+  const lines = [
+    "nats:",
+    `  url: ${target.natsUrl}`,
+    "  streams:",
+    `    - name: ${target.source.name}`,
+    `      stream: ${target.source.stream}`,
+    `      consumer: ${jobName}`,
+    `      filter: ${target.source.filter}`,
+    `      feed: ${target.source.feed}`,
+    "",
+    "storage:",
+    "  path: /data/ssmd",
+    `  feed: ${target.feed}`,
+    "  format: jsonl",
+    "",
+    "rotation:",
+    `  interval: ${target.rotation}`,
+    "",
+    "replay:",
+    `  date: ${target.date}`,
+    `  from_sequence: ${target.fromSequence}`,
+  ];
+  if (target.toSequence !== undefined) lines.push(`  to_sequence: ${target.toSequence}`);
+  return lines.join("\n") + "\n";
+}
+
+/** Args for the ssmd-sync container, matching the operator's sync Jobs */
+export function rearchiveSyncArgs(target: RearchiveTarget, jobName: string): string[] {
+  const { remote } = target;
+  const args = [
+    "--source", "/data/ssmd/",
+    "--bucket", remote.bucket,
+    "--prefix", reprocessPrefix(remote.prefix, jobName),
+    "--name", jobName,
+    "--max-attempts", "5",
+  ];
+  if (remote.type === "s3") {
+    args.push("--type", "s3");
+    if (remote.region) args.push("--region", remote.region);
+    if (remote.endpoint) args.push("--endpoint", remote.endpoint);
+  } else if (remote.secretRef) {
+    args.push("--credentials", "/etc/gcs/key.json");
+  }
+  return args;
+}
+
+/**
+ * The ConfigMap and Job for a rearchive. The archiver runs as an init
+ * container into an emptyDir and exits once the range is archived; ssmd-sync
+ * then uploads the directory. The Job never retries: a failed replay leaves
+ * a partial directory that should be looked at, not overwritten.
+ */
+export function renderRearchive(target: RearchiveTarget): Record<string, unknown>[] {
+  const name = rearchiveJobName(target.feed, target.date, target.fromSequence);
+  const labels = {
+    "app.kubernetes.io/name": "ssmd-rearchive",
+    "app.kubernetes.io/instance": name,
+    "app.kubernetes.io/managed-by": FIELD_MANAGER,
+    "ssmd.io/feed": target.feed,
+  };
+  const annotations: Record<string, string> = {
+    "ssmd.io/date": target.date,
+    "ssmd.io/stream": target.source.stream,
+    "ssmd.io/from-sequence": String(target.fromSequence),
+    "ssmd.io/remote-prefix": reprocessPrefix(target.remote.prefix, name),
+  };
+  if (target.toSequence !== undefined) annotations["ssmd.io/to-sequence"] = String(target.toSequence);
+
+  const syncEnv: Record<string, unknown>[] = [
+    { name: "POD_NAMESPACE", valueFrom: { fieldRef: { fieldPath: "metadata.namespace" } } },
+  ];
+  const syncMounts: Record<string, unknown>[] = [{ name: "data", mountPath: "/data" }];
+  const volumes: Record<string, unknown>[] = [
+    { name: "data", emptyDir: {} },
+    { name: "config", configMap: { name } },
+  ];
+  const { remote } = target;
+  if (remote.type === "s3" && remote.secretRef) {
+    for (const key of ["AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"]) {
+      syncEnv.push({
+        name: key,
+        valueFrom: { secretKeyRef: { name: remote.secretRef, key, optional: key === "AWS_SESSION_TOKEN" } },
+      });
+    }
+  } else if (remote.type !== "s3" && remote.secretRef) {
+    syncMounts.push({ name: "gcs-credentials", mountPath: "/etc/gcs", readOnly: true });
+    volumes.push({ name: "gcs-credentials", secret: { secretName: remote.secretRef } });
+  }
+
+  const podSpec: Record<string, unknown> = {
+    restartPolicy: "Never",
+    initContainers: [{
+      name: "archive",
+      image: target.image,
+      args: ["--config", "/etc/archiver/archiver.yaml"],
+      env: [{ name: "RUST_LOG", value: "info" }],
+      volumeMounts: [
+        { name: "data", mountPath: "/data" },
+        { name: "config", mountPath: "/etc/archiver", readOnly: true },
+      ],
+    }],
+    containers: [{
+      name: "sync",
+      image: target.syncImage,
+      args: rearchiveSyncArgs(target, name),
+      env: syncEnv,
+      volumeMounts: syncMounts,
+    }],
+    volumes,
+  };
+  if (target.serviceAccountName) podSpec.serviceAccountName = target.serviceAccountName;
+  if (target.imagePullSecrets?.length) podSpec.imagePullSecrets = target.imagePullSecrets;
+
+  return [
+    {
+      apiVersion: "v1",
+      kind: "ConfigMap",
+      metadata: { name, labels },
+      data: { "archiver.yaml": rearchiveConfig(target, name) },
+    },
+    {
+      apiVersion: "batch/v1",
+      kind: "Job",
+      metadata: { name, labels, annotations },
+      spec: {
+        backoffLimit: 0,
+        ttlSecondsAfterFinished: TTL_SECONDS_AFTER_FINISHED,
+        activeDeadlineSeconds: ACTIVE_DEADLINE_SECONDS,
+        template: { metadata: { labels }, spec: podSpec },
+      },
+    },
+  ];
+}
+
+function parseSequence(name: string, value: string | undefined): number | undefined {
+  if (value === undefined) return undefined;
+  const n = Number(value);
+  if (!Number.isSafeInteger(n) || n < 1) {
+    console.error(`--${name} must be a positive integer, got '${value}'`);
+    Deno.exit(2);
+  }
+  return n;
+}
+
+async function findSchedule(feed: string, options: KubectlOptions): Promise<ArchiverSchedule | null> {
+  const output = await kubectl(["get", "archiverschedules", "-o", "json"], options);
+  const items: ArchiverSchedule[] = JSON.parse(output).items ?? [];
+  return items.find((s) => s.spec?.feed === feed) ?? null;
+}
+
+const REARCHIVE_USAGE =
+  "ssmd k8s rearchive <feed> <date> --from-sequence N [--to-sequence M] [--source NAME] [--dry-run]";
+
+export async function runRearchive(flags: RearchiveFlags): Promise<void> {
+  const feed = flags._[2] as string | undefined;
+  const date = flags._[3] as string | undefined;
+  if (!feed || !date) {
+    console.error(`Usage: ${REARCHIVE_USAGE}`);
+    Deno.exit(2);
+  }
+  if (!/^\d{4}-\d{2}-\d{2}$/.test(date)) {
+    console.error(`Invalid date: ${date} (expected YYYY-MM-DD)`);
+    Deno.exit(2);
+  }
+  const fromSequence = parseSequence("from-sequence", flags["from-sequence"]);
+  if (fromSequence === undefined) {
+    console.error(`--from-sequence is required\nUsage: ${REARCHIVE_USAGE}`);
+    Deno.exit(2);
+  }
+  const toSequence = parseSequence("to-sequence", flags["to-sequence"]);
+  if (toSequence !== undefined && toSequence < fromSequence) {
+    console.error(`--to-sequence (${toSequence}) is before --from-sequence (${fromSequence})`);
+    Deno.exit(2);
+  }
+
+  const options: KubectlOptions = { env: flags.env, namespace: flags.namespace };
+  let target: RearchiveTarget;
+  try {
+    const schedule = await findSchedule(feed, options);
+    target = resolveTarget(feed, date, fromSequence, toSequence, schedule, flags);
+  } catch (e) {
+    console.error(`Error: ${(e as Error).message}`);
+    Deno.exit(1);
+  }
+
+  const list = { apiVersion: "v1", kind: "List", items: renderRearchive(target) };
+  if (flags["dry-run"]) {
+    console.log(JSON.stringify(list, null, 2));
+    return;
+  }
+
+  const name = rearchiveJobName(feed, date, fromSequence);
+  const env = await getCurrentEnvDisplay(flags.env);
+  const range = toSequence !== undefined ? `${fromSequence}-${toSequence}` : `${fromSequence}-end of day`;
+  console.log(`Rearchiving ${target.source.stream} ${range} for ${feed} ${date} in ${env}`);
+
+  const { code, stdout, stderr } = await kubectlWithInput(
+    ["apply", "--server-side", `--field-manager=${FIELD_MANAGER}`, "-f", "-"],
+    JSON.stringify(list),
+    options,
+  );
+  if (code !== 0) {
+    console.error(`kubectl failed: ${stderr.trim()}`);
+    Deno.exit(1);
+  }
+  if (stdout.trim()) console.log(stdout.trimEnd());
+
+  // Hand the ConfigMap to the Job so the TTL controller cleans up both
+  try {
+    const uid = (await kubectl(["get", "job", name, "-o", "jsonpath={.metadata.uid}"], options)).trim();
+    const ownerRef = { apiVersion: "batch/v1", kind: "Job", name, uid };
+    await kubectl(
+      ["patch", "configmap", name, "--type", "merge", "-p", JSON.stringify({ metadata: { ownerReferences: [ownerRef] } })],
+      options,
+    );
+  } catch (e) {
+    console.warn(`Warning: ConfigMap ${name} is not owned by the Job and must be deleted by hand: ${(e as Error).message}`);
+  }
+
+  const dest = `${target.remote.type === "s3" ? "s3" : "gs"}://${target.remote.bucket}/${reprocessPrefix(target.remote.prefix, name)}/`;
+  console.log(`\nOutput: ${dest}`);
+  console.log(`Follow:  kubectl logs -f job/${name} -c archive`);
+  console.log(`Status:  kubectl get job ${name}`);
+}
@@ -0,0 +1,212 @@
+// K8s command: generate Connector/Archiver CRs from exchanges/ environments
+// ssmd k8s generate <env> [--kind connector|archiver|all] [--apply] [--output FILE]
+// ssmd k8s rearchive: see k8s-rearchive.ts
+import { stringify as stringifyYaml } from "yaml";
+import { FeedSchema, getLatestVersion } from "../../lib/types/feed.ts";
+import { findExchangesRoot } from "../utils/paths.ts";
+import { getEnvContext } from "../utils/env-context.ts";
+import { kubectl } from "../utils/kubectl.ts";
+import { type ConfigSnapshot, loadWorkingTree } from "./diff.ts";
+import { DEFAULT_IMAGE as CONNECTOR_IMAGE } from "./connector-deploy.ts";
+import { DEFAULT_IMAGE as ARCHIVER_IMAGE } from "./archiver-deploy.ts";
+import { dedupKeys } from "../../server/schema-versions.ts";
+import { type RearchiveFlags, runRearchive } from "./k8s-rearchive.ts";
+
+export const MANIFEST_KINDS = ["connector", "archiver", "all"] as const;
+export type ManifestKind = typeof MANIFEST_KINDS[number];
+
+export interface GenerateOptions {
+  kind?: ManifestKind;
+  namespace: string;
+  connectorImage?: string;
+  archiverImage?: string;
+}
+
+/** A CR as a plain object, ready for YAML or kubectl apply */
+export type Manifest = Record<string, unknown>;
+
+interface K8sFlags extends RearchiveFlags {
+  _: (string | number)[];
+  kind?: string;
+  apply?: boolean;
+  output?: string;
+  namespace?: string;
+  env?: string;
+  "connector-image"?: string;
+  "archiver-image"?: string;
+}
+
+interface EnvironmentDoc {
+  feed?: unknown;
+  schema?: unknown;
+  transport?: { type?: string; url?: string; stream?: string; subject_prefix?: string };
+  storage?: { type?: string; path?: string; bucket?: string };
+}
+
+const API_VERSION = "ssmd.ssmd.io/v1alpha1";
+
+/**
+ * Connector and/or Archiver manifests for an environment of the exchanges/
+ * workspace. The environment's NATS transport becomes the connector's
+ * transport and the archiver's source; its storage becomes the archiver's
+ * local path or remote bucket. Feeds whose latest version authenticates get
+ * a secretRef to ssmd-<feed>-credentials.
+ */
+export function generateManifests(snapshot: ConfigSnapshot, envName: string, options: GenerateOptions): Manifest[] {
+  const file = `environments/${envName}.yaml`;
+  const doc = snapshot.environments.get(envName);
+  if (doc === undefined) {
+    throw new Error(`No environment ${envName} (${file})`);
+  }
+  const env = (doc ?? {}) as EnvironmentDoc;
+  if (typeof env.feed !== "string") {
+    throw new Error(`${file}: feed is required`);
+  }
+  const parsed = FeedSchema.safeParse(snapshot.feeds.get(env.feed));
+  if (!parsed.success) {
+    throw new Error(`${file}: feed ${env.feed} has no valid feeds/${env.feed}.yaml`);
+  }
+  const feed = parsed.data;
+  const transport = env.transport;
+  if (transport?.type !== "nats" || !transport.stream || !transport.subject_prefix) {
+    throw new Error(`${file}: transport must be nats with stream and subject_prefix`);
+  }
+  const url = transport.url ?? "nats://nats.nats.svc.cluster.local:4222";
+
+  const metadata = (name: string) => ({
+    name,
+    namespace: options.namespace,
+    labels: { "ssmd.io/environment": envName, "ssmd.io/feed": feed.name },
+    ...(typeof env.schema === "string" ? { annotations: { "ssmd.io/schema": env.schema } } : {}),
+  });
+  const kind = options.kind ?? "all";
+  const manifests: Manifest[] = [];
+
+  if (kind === "connector" || kind === "all") {
+    const auth = getLatestVersion(feed)?.auth_method;
+    manifests.push({
+      apiVersion: API_VERSION,
+      kind: "Connector",
+      metadata: metadata(envName),
+      spec: {
+        feed: feed.name,
+        image: options.connectorImage ?? CONNECTOR_IMAGE,
+        transport: { type: "nats", url, stream: transport.stream, subjectPrefix: transport.subject_prefix },
+        ...(auth && auth !== "none"
+          ? {
+            secretRef: {
+              name: `ssmd-${feed.name}-credentials`,
+              apiKeyField: "api-key",
+              privateKeyField: "private-key",
+            },
+          }
+          : {}),
+      },
+    });
+  }
+
+  if (kind === "archiver" || kind === "all") {
+    const storage = env.storage;
+    const remote = (storage?.type === "gcs" || storage?.type === "s3") && storage.bucket
+      ? { type: storage.type, bucket: storage.bucket, prefix: feed.name, secretRef: `${storage.type}-credentials` }
+      : undefined;
+    const dedup = dedupKeys(feed.name);
+    manifests.push({
+      apiVersion: API_VERSION,
+      kind: "Archiver",
+      metadata: metadata(`${envName}-archiver`),
+      spec: {
+        feed: feed.name,
+        image: options.archiverImage ?? ARCHIVER_IMAGE,
+        source: {
+          type: "nats",
+          url,
+          stream: transport.stream,
+          consumer: `${envName}-archiver`,
+          filter: `${transport.subject_prefix}.>`,
+        },
+        storage: {
+          local: {
+            path: storage?.type === "local" && storage.path ? storage.path : `/data/ssmd/${feed.name}`,
+            pvcName: "ssmd-archiver-data",
+          },
+          ...(remote ? { remote } : {}),
+        },
+        ...(Object.keys(dedup).length > 0 ? { dedupKeys: dedup } : {}),
+        sync: { enabled: remote !== undefined },
+      },
+    });
+  }
+
+  return manifests;
+}
+
+/** Multi-document YAML, one document per manifest */
+export function renderManifests(manifests: Manifest[]): string {
+  return manifests.map((m) => stringifyYaml(m, { indent: 2 })).join("---\n");
+}
+
+function isManifestKind(value: string): value is ManifestKind {
+  return (MANIFEST_KINDS as readonly string[]).includes(value);
+}
+
+async function generate(flags: K8sFlags): Promise<void> {
+  const envName = flags._[2] as string | undefined;
+  const kind = flags.kind ?? "all";
+  if (!envName || !isManifestKind(kind)) {
+    console.error("Usage: ssmd k8s generate <env> [--kind connector|archiver|all] [--apply] [--output FILE]");
+    Deno.exit(1);
+  }
+
+  const namespace = flags.namespace ?? (await getEnvContext(flags.env)).namespace;
+  const snapshot = await loadWorkingTree(await findExchangesRoot());
+  let yaml: string;
+  try {
+    yaml = renderManifests(generateManifests(snapshot, envName, {
+      kind,
+      namespace,
+      connectorImage: flags["connector-image"],
+      archiverImage: flags["archiver-image"],
+    }));
+  } catch (e) {
+    console.error((e as Error).message);
+    Deno.exit(1);
+  }
+
+  if (flags.apply) {
+    const file = await Deno.makeTempFile({ suffix: ".yaml" });
+    try {
+      await Deno.writeTextFile(file, yaml);
+      console.log((await kubectl(["apply", "-f", file], { env: flags.env, namespace })).trimEnd());
+    } finally {
+      await Deno.remove(file);
+    }
+  } else if (flags.output) {
+    await Deno.writeTextFile(flags.output, yaml);
+    console.log(`Wrote ${envName} manifests to ${flags.output}`);
+  } else {
+    console.log(yaml.trimEnd());
+  }
+}
+
+export async function handleK8s(subcommand: string, flags: K8sFlags): Promise<void> {
+  switch (subcommand) {
+    case "generate":
+      await generate(flags);
+      break;
+
+    case "rearchive":
+      await runRearchive(flags);
+      break;
+
+    default:
+      console.error(`Unknown k8s command: ${subcommand}`);
+      console.log("Usage: ssmd k8s [generate|rearchive]");
+      console.log("  generate <env> [--kind connector|archiver|all]  Connector/Archiver CRs from exchanges/environments/<env>.yaml");
+      console.log("           [--apply] [--output FILE] [--connector-image IMG] [--archiver-image IMG]");
+      console.log("  rearchive <feed> <date> --from-sequence N  One-shot Job replaying a JetStream range through the");
+      console.log("           archiver into <prefix>/reprocess/<job>/ (--to-sequence M, --source NAME, --stream,");
+      console.log("           --filter, --bucket, --prefix, --image, --sync-image, --dry-run)");
+      Deno.exit(1);
+  }
+}
@@ -0,0 +1,251 @@
+/**
+ * ssmd keys audit - Report environment keys past their rotation window.
+ *
+ * Reads the `keys:` map of an environment file (ssmd-metadata KeySpec) and
+ * finds when each key with rotation_days was last rotated, from:
+ *   sealed-secret:<ns>/<name>   latest write to the unsealed Secret (kubectl)
+ *   vault:<mount>/<path>        KV v2 metadata updated_time (VAULT_ADDR/VAULT_TOKEN)
+ *   rotation ledger             ~/.ssmd/key-rotations.yaml, key name -> date
+ * The most recent of the available timestamps wins; env: sources have no
+ * metadata and rely on the ledger.
+ */
+
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { parse as parseYaml } from "https://deno.land/std@0.224.0/yaml/mod.ts";
+import { getConfigDir } from "../utils/env-context.ts";
+import { kubectl } from "../utils/kubectl.ts";
+
+interface AuditFlags {
+  _: (string | number)[];
+  ledger?: string;
+  output?: string;
+  json?: boolean;
+}
+
+/** Key entry of an environment file (mirrors ssmd-metadata KeySpec) */
+export interface KeySpec {
+  type: string;
+  description?: string;
+  required?: boolean;
+  fields: string[];
+  source?: string;
+  rotation_days?: number;
+}
+
+export type KeySource =
+  | { kind: "env"; vars: string[] }
+  | { kind: "sealed-secret"; namespace: string | null; name: string }
+  | { kind: "vault"; mount: string; path: string }
+  | { kind: "none" }
+  | { kind: "unknown"; raw: string };
+
+export type AuditStatus = "ok" | "due_soon" | "overdue" | "unknown" | "no_policy";
+
+export interface KeyAuditRow {
+  key: string;
+  source: string | null;
+  rotation_days: number | null;
+  last_rotated: string | null;
+  age_days: number | null;
+  status: AuditStatus;
+  /** Where last_rotated came from, or why it could not be determined */
+  detail: string;
+}
+
+/** Keys within this many days of their window are reported as due_soon */
+export const DUE_SOON_DAYS = 7;
+
+export function parseKeySource(source: string | undefined): KeySource {
+  if (!source) return { kind: "none" };
+  const colon = source.indexOf(":");
+  const scheme = colon === -1 ? source : source.slice(0, colon);
+  const rest = colon === -1 ? "" : source.slice(colon + 1);
+  switch (scheme) {
+    case "env":
+      return { kind: "env", vars: rest.split(",").map((v) => v.trim()).filter(Boolean) };
+    case "sealed-secret": {
+      const [a, b] = rest.split("/");
+      if (!a || rest.split("/").length > 2) return { kind: "unknown", raw: source };
+      return b ? { kind: "sealed-secret", namespace: a, name: b } : { kind: "sealed-secret", namespace: null, name: a };
+    }
+    case "vault": {
+      const slash = rest.indexOf("/");
+      if (slash <= 0 || slash === rest.length - 1) return { kind: "unknown", raw: source };
+      return { kind: "vault", mount: rest.slice(0, slash), path: rest.slice(slash + 1) };
+    }
+    default:
+      return { kind: "unknown", raw: source };
+  }
+}
+
+/** Latest write time of a Secret: creation or any managedFields update */
+export function secretLastWritten(secret: {
+  metadata?: { creationTimestamp?: string; managedFields?: { time?: string }[] };
+}): Date | null {
+  const times = [
+    secret.metadata?.creationTimestamp,
+    ...(secret.metadata?.managedFields ?? []).map((f) => f.time),
+  ].filter((t): t is string => typeof t === "string").map((t) => Date.parse(t)).filter((t) => !Number.isNaN(t));
+  return times.length > 0 ? new Date(Math.max(...times)) : null;
+}
+
+/** Ledger YAML (key name -> date or timestamp) as Dates; bad entries throw */
+export function parseLedger(content: string): Map<string, Date> {
+  const parsed = parseYaml(content) ?? {};
+  if (typeof parsed !== "object" || Array.isArray(parsed)) {
+    throw new Error("rotation ledger must be a map of key name to date");
+  }
+  const ledger = new Map<string, Date>();
+  for (const [key, value] of Object.entries(parsed as Record<string, unknown>)) {
+    const date = value instanceof Date ? value : new Date(String(value));
+    if (Number.isNaN(date.getTime())) {
+      throw new Error(`rotation ledger entry '${key}' is not a date: ${value}`);
+    }
+    ledger.set(key, date);
+  }
+  return ledger;
+}
+
+/** Classify one key given its last rotation (null when unknown) */
+export function assessKey(
+  key: string,
+  spec: KeySpec,
+  lastRotated: Date | null,
+  detail: string,
+  now: Date = new Date(),
+): KeyAuditRow {
+  const row: KeyAuditRow = {
+    key,
+    source: spec.source ?? null,
+    rotation_days: spec.rotation_days ?? null,
+    last_rotated: lastRotated?.toISOString() ?? null,
+    age_days: lastRotated ? Math.floor((now.getTime() - lastRotated.getTime()) / 86_400_000) : null,
+    status: "unknown",
+    detail,
+  };
+  if (!spec.rotation_days) {
+    row.status = "no_policy";
+  } else if (row.age_days !== null) {
+    const remaining = spec.rotation_days - row.age_days;
+    row.status = remaining < 0 ? "overdue" : remaining <= DUE_SOON_DAYS ? "due_soon" : "ok";
+  }
+  return row;
+}
+
+/**
+ * Last rotation from the key's source. Returns the time or a skip reason
+ * (missing credentials, lookup failure, or no metadata for the source).
+ */
+async function sourceRotation(source: KeySource): Promise<{ at: Date | null; detail: string }> {
+  switch (source.kind) {
+    case "sealed-secret": {
+      try {
+        const out = await kubectl(
+          ["get", "secret", source.name, "-o", "json"],
+          source.namespace ? { namespace: source.namespace } : {},
+        );
+        const at = secretLastWritten(JSON.parse(out));
+        return { at, detail: at ? "secret" : "secret has no timestamps" };
+      } catch (e) {
+        return { at: null, detail: `skipped secret: ${(e as Error).message.split("\n")[0].trim()}` };
+      }
+    }
+    case "vault": {
+      const addr = Deno.env.get("VAULT_ADDR");
+      const token = Deno.env.get("VAULT_TOKEN");
+      if (!addr || !token) return { at: null, detail: "skipped vault: VAULT_ADDR/VAULT_TOKEN not set" };
+      try {
+        const res = await fetch(`${addr.replace(/\/$/, "")}/v1/${source.mount}/metadata/${source.path}`, {
+          headers: { "X-Vault-Token": token },
+        });
+        if (!res.ok) {
+          await res.body?.cancel();
+          return { at: null, detail: `skipped vault: HTTP ${res.status}` };
+        }
+        const body = await res.json() as { data?: { updated_time?: string } };
+        const at = body.data?.updated_time ? new Date(body.data.updated_time) : null;
+        return { at, detail: at ? "vault" : "vault metadata has no updated_time" };
+      } catch (e) {
+        return { at: null, detail: `skipped vault: ${(e as Error).message}` };
+      }
+    }
+    case "env":
+      return { at: null, detail: "env source has no rotation metadata" };
+    case "none":
+      return { at: null, detail: "no source" };
+    case "unknown":
+      return { at: null, detail: `unsupported source '${source.raw}'` };
+  }
+}
+
+export async function runKeysAudit(flags: AuditFlags): Promise<void> {
+  const envFile = flags._[2] as string | undefined;
+  if (!envFile) {
+    console.error("Usage: ssmd keys audit <environment.yaml> [--ledger FILE] [--output json]");
+    Deno.exit(2);
+  }
+  const asJson = flags.json || flags.output === "json";
+
+  let keys: Record<string, KeySpec>;
+  let ledger = new Map<string, Date>();
+  try {
+    const env = parseYaml(await Deno.readTextFile(envFile)) as { keys?: Record<string, KeySpec> } | null;
+    keys = env?.keys ?? {};
+    const ledgerPath = flags.ledger ?? join(getConfigDir(), "key-rotations.yaml");
+    try {
+      ledger = parseLedger(await Deno.readTextFile(ledgerPath));
+    } catch (e) {
+      // The default ledger is optional; an explicit one must exist
+      if (!(e instanceof Deno.errors.NotFound) || flags.ledger) throw e;
+    }
+  } catch (e) {
+    console.error(`Error: ${(e as Error).message}`);
+    Deno.exit(2);
+  }
+
+  const now = new Date();
+  const rows: KeyAuditRow[] = [];
+  for (const [name, spec] of Object.entries(keys).sort(([a], [b]) => a.localeCompare(b))) {
+    if (!spec.rotation_days) {
+      rows.push(assessKey(name, spec, null, "no rotation_days", now));
+      continue;
+    }
+    const fromSource = await sourceRotation(parseKeySource(spec.source));
+    const fromLedger = ledger.get(name) ?? null;
+    let lastRotated = fromSource.at;
+    let detail = fromSource.detail;
+    if (fromLedger && (!lastRotated || fromLedger > lastRotated)) {
+      lastRotated = fromLedger;
+      detail = fromSource.at ? "ledger (newer than source)" : `ledger; ${fromSource.detail}`;
+    }
+    rows.push(assessKey(name, spec, lastRotated, detail, now));
+  }
+
+  const overdue = rows.filter((r) => r.status === "overdue");
+  if (asJson) {
+    console.log(JSON.stringify({ environment: envFile, checked_at: now.toISOString(), keys: rows }, null, 2));
+  } else if (rows.length === 0) {
+    console.log(`No keys defined in ${envFile}`);
+  } else {
+    const width = Math.max(3, ...rows.map((r) => r.key.length));
+    console.log(
+      "KEY".padEnd(width) + "  " + "STATUS".padEnd(9) + "  " + "ROTATED".padEnd(10) + "  " +
+        "AGE".padStart(5) + "  " + "WINDOW".padStart(6) + "  DETAIL",
+    );
+    for (const r of rows) {
+      console.log(
+        r.key.padEnd(width) + "  " +
+          r.status.padEnd(9) + "  " +
+          (r.last_rotated?.slice(0, 10) ?? "-").padEnd(10) + "  " +
+          (r.age_days !== null ? `${r.age_days}d` : "-").padStart(5) + "  " +
+          (r.rotation_days !== null ? `${r.rotation_days}d` : "-").padStart(6) + "  " +
+          r.detail,
+      );
+    }
+    if (overdue.length > 0) {
+      console.log(`\n${overdue.length} key(s) past rotation window: ${overdue.map((r) => r.key).join(", ")}`);
+    }
+  }
+
+  if (overdue.length > 0) Deno.exit(1);
+}
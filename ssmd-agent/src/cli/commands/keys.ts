@@ -5,8 +5,11 @@
  *   create  Create a new API key with optional expiration
  *   list    List all API keys
  *   revoke  Revoke an API key by prefix
+ *   audit   Report environment keys past their rotation window
  */
 
+import { runKeysAudit } from "./keys-audit.ts";
+
 interface KeysFlags {
   _: (string | number)[];
   email?: string;
@@ -19,6 +22,8 @@ interface KeysFlags {
   "date-to"?: string;
   "rate-limit-tier"?: string;
   "no-bill"?: boolean;
+  ledger?: string;
+  output?: string;
 }
 
 function getApiConfig(): { apiUrl: string; apiKey: string } {
@@ -52,6 +57,9 @@ export async function handleKeys(subcommand: string, flags: KeysFlags): Promise<
     case "update":
       await updateKey(flags);
       break;
+    case "audit":
+      await runKeysAudit(flags);
+      break;
     case "help":
     default:
       printKeysHelp();
@@ -295,6 +303,7 @@ function printKeysHelp(): void {
   console.log("  list      List all API keys");
   console.log("  update    Update scopes on an existing key");
   console.log("  revoke    Revoke an API key by prefix");
+  console.log("  audit     Report environment keys past rotation_days (exit 1 if any)");
   console.log();
   console.log("OPTIONS (create):");
   console.log("  --email EMAIL         User email (required)");
@@ -312,6 +321,11 @@ function printKeysHelp(): void {
   console.log("OPTIONS (list):");
   console.log("  --json              Output JSON format");
   console.log();
+  console.log("OPTIONS (audit <environment.yaml>):");
+  console.log("  --ledger FILE       Rotation ledger, key name -> date (default: ~/.ssmd/key-rotations.yaml)");
+  console.log("  --output json       Output JSON format");
+  console.log("  Sources: sealed-secret:<ns>/<name> (kubectl), vault:<mount>/<path> (VAULT_ADDR, VAULT_TOKEN)");
+  console.log();
   console.log("ENVIRONMENT:");
   console.log("  SSMD_API_URL        API base URL (default: http://localhost:8080)");
   console.log("  SSMD_DATA_API_KEY   Admin API key for key management");
@@ -321,4 +335,5 @@ function printKeysHelp(): void {
   console.log("  ssmd keys list");
   console.log("  ssmd keys update sk_live_abc --scopes datasets:read,llm:chat");
   console.log("  ssmd keys revoke sk_live_abc123");
+  console.log("  ssmd keys audit environments/kalshi-prod.yaml --output json");
 }
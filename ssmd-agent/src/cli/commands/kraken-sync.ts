@@ -4,82 +4,14 @@
 import { getDb, closeDb } from "../../lib/db/client.ts";
 import { upsertSpotPairs, upsertPerpPairs, softDeleteMissingPairs, insertPerpSnapshots, cleanupOldSnapshots } from "../../lib/db/pairs.ts";
 import type { NewPair } from "../../lib/db/schema.ts";
-
-const KRAKEN_SPOT_URL = "https://api.kraken.com/0/public/AssetPairs";
-const KRAKEN_FUTURES_INSTRUMENTS_URL = "https://futures.kraken.com/derivatives/api/v3/instruments";
-const KRAKEN_FUTURES_TICKERS_URL = "https://futures.kraken.com/derivatives/api/v3/tickers";
-const API_TIMEOUT_MS = 30000;
-
-// --- Kraken API response types ---
-
-interface KrakenSpotResponse {
-  error: string[];
-  result: Record<string, KrakenAssetPair>;
-}
-
-interface KrakenAssetPair {
-  altname: string;
-  wsname?: string;
-  aclass_base: string;
-  base: string;
-  aclass_quote: string;
-  quote: string;
-  lot: string;
-  pair_decimals: number;
-  lot_decimals: number;
-  lot_multiplier: number;
-  fees: number[][];
-  fees_maker?: number[][];
-  fee_volume_currency: string;
-  margin_call: number;
-  margin_stop: number;
-  ordermin?: string;
-  costmin?: string;
-  tick_size?: string;
-  status: string;
-}
-
-interface KrakenFuturesInstrumentsResponse {
-  result: string;
-  instruments: KrakenFuturesInstrument[];
-}
-
-interface KrakenFuturesInstrument {
-  symbol: string;
-  type: string;
-  underlying: string;
-  tickSize: number;
-  contractSize: number;
-  tradeable: boolean;
-  suspended?: boolean;
-  openingDate?: string;
-  feeScheduleUid?: string;
-  marginLevels?: Array<{ contracts: number; initialMargin: number; maintenanceMargin: number }>;
-  maxPositionSize?: number;
-  tags?: string[];
-  contractValueTradePrecision?: number;
-}
-
-interface KrakenFuturesTickersResponse {
-  result: string;
-  tickers: KrakenFuturesTicker[];
-}
-
-interface KrakenFuturesTicker {
-  symbol: string;
-  bid: number;
-  ask: number;
-  last: number;
-  vol24h: number;
-  markPrice: number;
-  indexPrice?: number;
-  fundingRate?: number;
-  fundingRatePrediction?: number;
-  openInterest?: number;
-  suspended?: boolean;
-  tag?: string;
-  pair?: string;
-}
+import {
+  createKrakenClient,
+  type KrakenAssetPair,
+  type KrakenClient,
+  type KrakenFuturesTicker,
+  type KrakenSpotTicker,
+  parseSpotTicker,
+} from "../../lib/api/kraken.ts";
 
 // --- Normalization ---
 
@@ -185,31 +117,15 @@ export interface KrakenSyncResult {
 }
 
 /**
- * Sync Kraken spot pairs from the AssetPairs API.
+ * Convert online spot asset pairs to pair rows, with bid/ask/last/24h volume
+ * from the matching ticker when there is one.
  */
-async function syncSpot(
-  db: ReturnType<typeof getDb>,
-  dryRun: boolean,
-  noDelete: boolean,
-): Promise<KrakenSyncResult["spot"]> {
-  console.log("\n[Kraken Spot] Fetching asset pairs...");
-  const res = await fetch(KRAKEN_SPOT_URL, {
-    signal: AbortSignal.timeout(API_TIMEOUT_MS),
-  });
-  if (!res.ok) {
-    throw new Error(`Kraken API error: ${res.status} ${await res.text()}`);
-  }
-  const data: KrakenSpotResponse = await res.json();
-  if (data.error && data.error.length > 0) {
-    throw new Error(`Kraken API errors: ${data.error.join(", ")}`);
-  }
-
-  const allPairs = Object.entries(data.result);
-  const result = { fetched: allPairs.length, online: 0, upserted: 0, deleted: 0 };
-
-  // Filter to online pairs and convert
+export function buildSpotPairRows(
+  assetPairs: Record<string, KrakenAssetPair>,
+  tickers: Record<string, KrakenSpotTicker> = {},
+): NewPair[] {
   const pairRows: NewPair[] = [];
-  for (const [pairId, pair] of allPairs) {
+  for (const [pairId, pair] of Object.entries(assetPairs)) {
     if (pair.status !== "online") continue;
 
     // Prefer wsname for base/quote (clean format: "XBT/USD"), fall back to raw fields
@@ -236,8 +152,35 @@ async function syncSpot(
       orderMin: pair.ordermin ?? null,
       costMin: pair.costmin ?? null,
       feeSchedule: buildFeeSchedule(pair.fees, pair.fees_maker),
+      ...parseSpotTicker(tickers[pairId]),
     });
   }
+  return pairRows;
+}
+
+/**
+ * Sync Kraken spot pairs from the AssetPairs API, with ticker metadata.
+ */
+async function syncSpot(
+  client: KrakenClient,
+  db: ReturnType<typeof getDb>,
+  dryRun: boolean,
+  noDelete: boolean,
+): Promise<KrakenSyncResult["spot"]> {
+  console.log("\n[Kraken Spot] Fetching asset pairs...");
+  const assetPairs = await client.fetchAssetPairs();
+  const online = Object.entries(assetPairs).filter(([, pair]) => pair.status === "online").map(([id]) => id);
+
+  // Ticker metadata is best effort: pairs still sync without prices
+  let tickers: Record<string, KrakenSpotTicker> = {};
+  try {
+    tickers = await client.fetchSpotTickers(online);
+  } catch (e) {
+    console.warn(`[Kraken Spot] Ticker fetch failed, syncing without prices: ${(e as Error).message}`);
+  }
+
+  const pairRows = buildSpotPairRows(assetPairs, tickers);
+  const result = { fetched: Object.keys(assetPairs).length, online: 0, upserted: 0, deleted: 0 };
 
   result.online = pairRows.length;
   console.log(`[Kraken Spot] Fetched ${result.fetched} pairs, ${result.online} online`);
@@ -266,6 +209,7 @@ async function syncSpot(
  * Merges instrument metadata with live ticker data.
  */
 async function syncPerps(
+  client: KrakenClient,
   db: ReturnType<typeof getDb>,
   dryRun: boolean,
   noDelete: boolean,
@@ -273,28 +217,17 @@ async function syncPerps(
   console.log("\n[Kraken Perps] Fetching instruments...");
 
   // Fetch instruments and tickers in parallel
-  const [instrumentsRes, tickersRes] = await Promise.all([
-    fetch(KRAKEN_FUTURES_INSTRUMENTS_URL, { signal: AbortSignal.timeout(API_TIMEOUT_MS) }),
-    fetch(KRAKEN_FUTURES_TICKERS_URL, { signal: AbortSignal.timeout(API_TIMEOUT_MS) }),
+  const [allInstruments, tickers] = await Promise.all([
+    client.fetchFuturesInstruments(),
+    client.fetchFuturesTickers(),
   ]);
 
-  if (!instrumentsRes.ok) {
-    throw new Error(`Kraken Futures instruments API error: ${instrumentsRes.status} ${await instrumentsRes.text()}`);
-  }
-  if (!tickersRes.ok) {
-    throw new Error(`Kraken Futures tickers API error: ${tickersRes.status} ${await tickersRes.text()}`);
-  }
-
-  const instrumentsData: KrakenFuturesInstrumentsResponse = await instrumentsRes.json();
-  const tickersData: KrakenFuturesTickersResponse = await tickersRes.json();
-
   // Index tickers by symbol for lookup
   const tickerMap = new Map<string, KrakenFuturesTicker>();
-  for (const t of tickersData.tickers) {
+  for (const t of tickers) {
     tickerMap.set(t.symbol, t);
   }
 
-  const allInstruments = instrumentsData.instruments;
   const result = { fetched: allInstruments.length, tradeable: 0, upserted: 0, deleted: 0 };
 
   // Filter to tradeable perpetuals and convert
@@ -380,13 +313,14 @@ export async function runKrakenSync(
     perps: { fetched: 0, tradeable: 0, upserted: 0, deleted: 0 },
   };
 
+  const client = createKrakenClient();
   const db = getDb();
   try {
     if (syncSpotFlag) {
-      result.spot = await syncSpot(db, dryRun, noDelete);
+      result.spot = await syncSpot(client, db, dryRun, noDelete);
     }
     if (syncPerpsFlag) {
-      result.perps = await syncPerps(db, dryRun, noDelete);
+      result.perps = await syncPerps(client, db, dryRun, noDelete);
     }
 
     // Cleanup old snapshots (7-day retention)
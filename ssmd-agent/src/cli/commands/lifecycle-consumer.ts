@@ -234,14 +234,14 @@ Environment variables:
       if (TERMINAL_EVENT_TYPES.has(m.event_type)) {
         const newStatus = eventTypeToStatus(m.event_type);
         const sql = getRawSql();
-        const result = await sql`UPDATE markets SET status = ${newStatus}, updated_at = NOW() WHERE ticker = ${m.market_ticker}`;
+        const result = await sql`UPDATE markets SET status = ${newStatus}, updated_at = NOW() WHERE exchange = 'kalshi' AND ticker = ${m.market_ticker}`;
         if (result.count > 0) {
           log(`[status→${newStatus}] ${m.market_ticker}`);
         }
       } else if (m.event_type === "close_date_updated" && m.close_ts) {
         const sql = getRawSql();
         const closeTime = new Date(m.close_ts * 1000).toISOString();
-        await sql`UPDATE markets SET close_time = ${closeTime}::timestamptz, updated_at = NOW() WHERE ticker = ${m.market_ticker}`;
+        await sql`UPDATE markets SET close_time = ${closeTime}::timestamptz, updated_at = NOW() WHERE exchange = 'kalshi' AND ticker = ${m.market_ticker}`;
       }
 
       eventsWritten++;
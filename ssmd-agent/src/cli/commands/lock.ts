@@ -0,0 +1,153 @@
+// lock.ts - exchanges/ssmd.lock: content hashes of every feed, schema and
+// environment file plus the feed versions in effect, so a rollout can assert
+// the config it applies is the config that was reviewed
+
+import { parse as parseYaml } from "yaml";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { encodeHex } from "https://deno.land/std@0.224.0/encoding/hex.ts";
+import { FeedSchema, getLatestVersion } from "../../lib/types/feed.ts";
+import { type ConfigMapManifest, renderConfigMaps, SCHEMAS_CONFIGMAP } from "./feed-publish.ts";
+
+export const LOCK_FILE = "ssmd.lock";
+const LOCK_VERSION = 1;
+
+interface LockFlags {
+  _: (string | number)[];
+}
+
+export interface FeedLock {
+  /** Latest version id and its effective_from date */
+  version: string | null;
+  effective_from: string | null;
+  sha256: string;
+}
+
+export interface Lockfile {
+  lock_version: number;
+  feeds: Record<string, FeedLock>;
+  schemas: Record<string, string>;
+  environments: Record<string, string>;
+}
+
+async function sha256(content: string): Promise<string> {
+  return encodeHex(await crypto.subtle.digest("SHA-256", new TextEncoder().encode(content)));
+}
+
+/** Lock for rendered ConfigMaps, so it covers exactly what feed publish applies */
+export async function buildLock(manifests: ConfigMapManifest[]): Promise<Lockfile> {
+  const lock: Lockfile = { lock_version: LOCK_VERSION, feeds: {}, schemas: {}, environments: {} };
+  for (const m of manifests) {
+    const name = m.metadata.name;
+    if (name === SCHEMAS_CONFIGMAP) {
+      for (const [file, content] of Object.entries(m.data)) {
+        lock.schemas[file] = await sha256(content);
+      }
+    } else if (name.startsWith("feed-")) {
+      const content = m.data["feed.yaml"];
+      const latest = getLatestVersion(FeedSchema.parse(parseYaml(content)));
+      lock.feeds[name.slice("feed-".length)] = {
+        version: latest?.version ?? null,
+        effective_from: latest?.effective_from ?? null,
+        sha256: await sha256(content),
+      };
+    } else if (name.startsWith("env-")) {
+      lock.environments[name.slice("env-".length)] = await sha256(m.data["env.yaml"]);
+    }
+  }
+  return lock;
+}
+
+/** Stable serialization (sorted keys) so unchanged config gives an identical file */
+export function formatLock(lock: Lockfile): string {
+  const sorted = <T>(record: Record<string, T>) =>
+    Object.fromEntries(Object.entries(record).sort(([a], [b]) => a.localeCompare(b)));
+  return JSON.stringify(
+    {
+      lock_version: lock.lock_version,
+      feeds: sorted(lock.feeds),
+      schemas: sorted(lock.schemas),
+      environments: sorted(lock.environments),
+    },
+    null,
+    2,
+  ) + "\n";
+}
+
+/** Human-readable differences between the lock and the current tree; [] if none */
+export function diffLock(locked: Lockfile, current: Lockfile): string[] {
+  const drift: string[] = [];
+  const compare = <T>(kind: string, a: Record<string, T>, b: Record<string, T>, same: (x: T, y: T) => boolean) => {
+    for (const key of [...new Set([...Object.keys(a), ...Object.keys(b)])].sort()) {
+      if (!(key in b)) drift.push(`${kind} ${key}: removed`);
+      else if (!(key in a)) drift.push(`${kind} ${key}: added`);
+      else if (!same(a[key], b[key])) drift.push(`${kind} ${key}: changed`);
+    }
+  };
+  compare("feed", locked.feeds, current.feeds, (x, y) => x.sha256 === y.sha256);
+  for (const [name, feed] of Object.entries(locked.feeds)) {
+    const now = current.feeds[name];
+    if (now && now.version !== feed.version) {
+      drift.push(`feed ${name}: version ${feed.version} -> ${now.version}`);
+    }
+  }
+  compare("schema", locked.schemas, current.schemas, (x, y) => x === y);
+  compare("environment", locked.environments, current.environments, (x, y) => x === y);
+  return drift;
+}
+
+/**
+ * Compare exchanges/ssmd.lock with the tree. Returns null when there is no
+ * lockfile, otherwise the drift ([] when the tree matches).
+ */
+export async function verifyLock(exchangesRoot: string): Promise<string[] | null> {
+  let locked: Lockfile;
+  try {
+    locked = JSON.parse(await Deno.readTextFile(join(exchangesRoot, LOCK_FILE)));
+  } catch (e) {
+    if (e instanceof Deno.errors.NotFound) return null;
+    throw new Error(`${LOCK_FILE}: ${(e as Error).message}`);
+  }
+  if (locked.lock_version !== LOCK_VERSION) {
+    throw new Error(`${LOCK_FILE}: unsupported lock_version ${locked.lock_version}; run 'ssmd lock'`);
+  }
+  return diffLock(locked, await buildLock(await renderConfigMaps(exchangesRoot)));
+}
+
+/** Write exchanges/ssmd.lock for the current tree */
+export async function writeLock(exchangesRoot: string): Promise<Lockfile> {
+  const lock = await buildLock(await renderConfigMaps(exchangesRoot));
+  await Deno.writeTextFile(join(exchangesRoot, LOCK_FILE), formatLock(lock));
+  return lock;
+}
+
+export async function handleLock(subcommand: string | undefined, _flags: LockFlags, exchangesRoot: string): Promise<void> {
+  switch (subcommand) {
+    case undefined:
+    case "write": {
+      const lock = await writeLock(exchangesRoot);
+      console.log(
+        `Wrote ${join(exchangesRoot, LOCK_FILE)}: ${Object.keys(lock.feeds).length} feeds, ` +
+          `${Object.keys(lock.schemas).length} schemas, ${Object.keys(lock.environments).length} environments`,
+      );
+      break;
+    }
+    case "verify": {
+      const drift = await verifyLock(exchangesRoot);
+      if (drift === null) {
+        console.error(`No ${LOCK_FILE} in ${exchangesRoot}; run 'ssmd lock' first`);
+        Deno.exit(1);
+      }
+      if (drift.length > 0) {
+        console.error(`Config drifted from ${LOCK_FILE}:`);
+        for (const d of drift) console.error(`  ${d}`);
+        Deno.exit(1);
+      }
+      console.log(`Config matches ${LOCK_FILE}`);
+      break;
+    }
+    default:
+      console.error(`Unknown lock command: ${subcommand}`);
+      console.log("Usage: ssmd lock [write|verify]");
+      Deno.exit(1);
+  }
+}
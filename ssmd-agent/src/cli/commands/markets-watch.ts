@@ -0,0 +1,296 @@
+/**
+ * ssmd markets watch - Live quote table for a few tickers, fed by the data
+ * API's SSE live tail (GET /v1/data/stream), so no NATS access is needed.
+ *
+ * Keys (when stdin is a terminal): t/b/a/l/v sort by ticker, bid, ask,
+ * last or volume; r reverses; q quits.
+ */
+
+interface WatchFlags {
+  _: (string | number)[];
+  feed?: string;
+  sort?: string;
+  "csv-file"?: string;
+  interval?: string;
+}
+
+/** Latest observed values per ticker; prices in dollars */
+export interface Quote {
+  ticker: string;
+  bid: number | null;
+  ask: number | null;
+  last: number | null;
+  volume: number | null;
+  updates: number;
+  updatedAt: string | null;
+}
+
+export type QuoteColumn = "ticker" | "bid" | "ask" | "last" | "volume";
+
+export const QUOTE_COLUMNS: readonly QuoteColumn[] = ["ticker", "bid", "ask", "last", "volume"];
+
+const SORT_KEYS: Record<string, QuoteColumn> = { t: "ticker", b: "bid", a: "ask", l: "last", v: "volume" };
+
+export const WATCH_FEEDS = ["kalshi", "kraken-spot", "kraken-futures"];
+
+type QuoteUpdate = Partial<Pick<Quote, "bid" | "ask" | "last" | "volume">>;
+
+function num(v: unknown): number | null {
+  if (typeof v === "number" && Number.isFinite(v)) return v;
+  if (typeof v === "string" && v !== "" && Number.isFinite(Number(v))) return Number(v);
+  return null;
+}
+
+/** Kalshi sends integer cents, or dollar strings in *_dollars fields */
+function kalshiPrice(msg: Record<string, unknown>, field: string): number | null {
+  const cents = msg[field];
+  if (typeof cents === "number") return cents / 100;
+  return num(msg[`${field}_dollars`]);
+}
+
+/**
+ * Quote fields carried by one live-tail message, or null for message types
+ * that carry none. Field names follow ssmd-schemas.
+ */
+export function quoteUpdate(feed: string, type: string, data: Record<string, unknown>): QuoteUpdate | null {
+  const defined = (u: QuoteUpdate): QuoteUpdate | null => {
+    const out: QuoteUpdate = {};
+    for (const [k, v] of Object.entries(u)) {
+      if (v !== null && v !== undefined) out[k as keyof QuoteUpdate] = v;
+    }
+    return Object.keys(out).length > 0 ? out : null;
+  };
+
+  switch (feed) {
+    case "kalshi": {
+      const msg = (data.msg ?? {}) as Record<string, unknown>;
+      if (type === "ticker") {
+        return defined({
+          bid: kalshiPrice(msg, "yes_bid"),
+          ask: kalshiPrice(msg, "yes_ask"),
+          last: kalshiPrice(msg, "price"),
+          volume: num(msg.volume) ?? num(msg.volume_fp),
+        });
+      }
+      if (type === "trade") {
+        return defined({ last: kalshiPrice(msg, "yes_price") ?? kalshiPrice(msg, "price") });
+      }
+      return null;
+    }
+    case "kraken-spot": {
+      const row = (Array.isArray(data.data) ? data.data[0] : null) as Record<string, unknown> | null;
+      if (!row) return null;
+      if (type === "ticker") {
+        return defined({ bid: num(row.bid), ask: num(row.ask), last: num(row.last), volume: num(row.volume) });
+      }
+      return type === "trade" ? defined({ last: num(row.price) }) : null;
+    }
+    case "kraken-futures": {
+      if (type === "ticker" || type === "ticker_lite") {
+        return defined({ bid: num(data.bid), ask: num(data.ask), last: num(data.last), volume: num(data.volume) });
+      }
+      return type === "trade" ? defined({ last: num(data.price) }) : null;
+    }
+    default:
+      return null;
+  }
+}
+
+/**
+ * Split buffered SSE text into complete events. Returns the events and the
+ * unterminated remainder to prepend to the next chunk. Comments are dropped.
+ */
+export function parseSseEvents(buffer: string): { events: { event: string; data: string }[]; rest: string } {
+  const frames = buffer.replaceAll("\r\n", "\n").split("\n\n");
+  const rest = frames.pop() ?? "";
+  const events: { event: string; data: string }[] = [];
+  for (const frame of frames) {
+    let event = "message";
+    const data: string[] = [];
+    for (const line of frame.split("\n")) {
+      if (line.startsWith("event:")) event = line.slice(6).trim();
+      else if (line.startsWith("data:")) data.push(line.slice(5).trimStart());
+    }
+    if (data.length > 0) events.push({ event, data: data.join("\n") });
+  }
+  return { events, rest };
+}
+
+/** Sort quotes by a column; nulls last, ties by ticker */
+export function sortQuotes(quotes: Quote[], column: QuoteColumn, descending = false): Quote[] {
+  return [...quotes].sort((a, b) => {
+    if (column !== "ticker") {
+      const av = a[column];
+      const bv = b[column];
+      if (av === null || bv === null) {
+        if (av !== bv) return av === null ? 1 : -1;
+      } else if (av !== bv) {
+        return descending ? bv - av : av - bv;
+      }
+    }
+    const byTicker = a.ticker.localeCompare(b.ticker);
+    return column === "ticker" && descending ? -byTicker : byTicker;
+  });
+}
+
+export const CSV_HEADER = "received_at,ticker,type,bid,ask,last,volume";
+
+/** CSV line for one observed update (fields the message did not carry are empty) */
+export function csvLine(receivedAt: string, ticker: string, type: string, update: QuoteUpdate): string {
+  const cell = (v: number | undefined) => (v === undefined ? "" : String(v));
+  return [receivedAt, ticker, type, cell(update.bid), cell(update.ask), cell(update.last), cell(update.volume)].join(",");
+}
+
+function formatPrice(v: number | null): string {
+  if (v === null) return "-";
+  return v < 10 ? v.toFixed(4) : v.toFixed(2);
+}
+
+export function renderQuoteTable(quotes: Quote[], column: QuoteColumn, descending: boolean): string {
+  const width = Math.max(6, ...quotes.map((q) => q.ticker.length));
+  const mark = (c: QuoteColumn, label: string) => (c === column ? `${label}${descending ? "v" : "^"}` : label);
+  const lines = [
+    mark("ticker", "TICKER").padEnd(width + 1) + "  " +
+    mark("bid", "BID").padStart(10) + "  " +
+    mark("ask", "ASK").padStart(10) + "  " +
+    mark("last", "LAST").padStart(10) + "  " +
+    mark("volume", "VOLUME").padStart(14) + "  " +
+    "UPDATES".padStart(7) + "  " +
+    "UPDATED",
+  ];
+  for (const q of sortQuotes(quotes, column, descending)) {
+    lines.push(
+      q.ticker.padEnd(width + 1) + "  " +
+        formatPrice(q.bid).padStart(10) + "  " +
+        formatPrice(q.ask).padStart(10) + "  " +
+        formatPrice(q.last).padStart(10) + "  " +
+        (q.volume?.toLocaleString() ?? "-").padStart(14) + "  " +
+        String(q.updates).padStart(7) + "  " +
+        (q.updatedAt?.slice(11, 23) ?? "-"),
+    );
+  }
+  return lines.join("\n");
+}
+
+function getApiConfig(): { apiUrl: string; apiKey: string } {
+  const apiUrl = Deno.env.get("SSMD_API_URL") || "http://localhost:8080";
+  const apiKey = Deno.env.get("SSMD_DATA_API_KEY") || Deno.env.get("SSMD_API_KEY") || "";
+  if (!apiKey) {
+    console.error("Error: SSMD_DATA_API_KEY or SSMD_API_KEY environment variable required");
+    Deno.exit(1);
+  }
+  return { apiUrl, apiKey };
+}
+
+export async function runMarketsWatch(flags: WatchFlags): Promise<void> {
+  const tickers = (flags._.slice(2) as string[]).map(String);
+  const feed = flags.feed ?? "kalshi";
+  if (tickers.length === 0) {
+    console.error("Usage: ssmd markets watch <ticker...> [--feed kalshi] [--sort volume] [--csv-file FILE]");
+    Deno.exit(1);
+  }
+  if (!WATCH_FEEDS.includes(feed)) {
+    console.error(`Error: --feed must be one of ${WATCH_FEEDS.join(", ")}`);
+    Deno.exit(1);
+  }
+  let column: QuoteColumn = (flags.sort ?? "ticker") as QuoteColumn;
+  if (!QUOTE_COLUMNS.includes(column)) {
+    console.error(`Error: --sort must be one of ${QUOTE_COLUMNS.join(", ")}`);
+    Deno.exit(1);
+  }
+  let descending = column !== "ticker";
+  const intervalMs = Number(flags.interval ?? "1") * 1000;
+  if (!Number.isFinite(intervalMs) || intervalMs < 100) {
+    console.error("Error: --interval must be at least 0.1 (seconds)");
+    Deno.exit(1);
+  }
+
+  const quotes = new Map<string, Quote>();
+  const encoder = new TextEncoder();
+  let csv: Deno.FsFile | null = null;
+  const csvPath = flags["csv-file"];
+  if (csvPath) {
+    const exists = await Deno.stat(csvPath).then(() => true, () => false);
+    csv = await Deno.open(csvPath, { create: true, append: true });
+    if (!exists) await csv.write(encoder.encode(CSV_HEADER + "\n"));
+  }
+
+  const controller = new AbortController();
+  let status = "connecting";
+  const draw = () => {
+    const header = `ssmd markets watch  feed=${feed}  ${status}` +
+      (csv ? `  csv=${csvPath}` : "") + "\n[t/b/a/l/v] sort  [r] reverse  [q] quit\n\n";
+    const body = quotes.size > 0 ? renderQuoteTable([...quotes.values()], column, descending) : "Waiting for updates...";
+    Deno.stdout.writeSync(encoder.encode("\x1b[2J\x1b[H" + header + body + "\n"));
+  };
+
+  // Interactive sort keys
+  if (Deno.stdin.isTerminal()) {
+    Deno.stdin.setRaw(true);
+    (async () => {
+      const buf = new Uint8Array(8);
+      while (!controller.signal.aborted) {
+        const n = await Deno.stdin.read(buf);
+        if (n === null) break;
+        const key = new TextDecoder().decode(buf.subarray(0, n));
+        if (key === "q" || key === "\x03") {
+          controller.abort();
+        } else if (key === "r") {
+          descending = !descending;
+        } else if (SORT_KEYS[key]) {
+          column = SORT_KEYS[key];
+          descending = column !== "ticker";
+        }
+        draw();
+      }
+    })();
+  }
+  Deno.addSignalListener("SIGINT", () => controller.abort());
+
+  const timer = setInterval(draw, intervalMs);
+  const { apiUrl, apiKey } = getApiConfig();
+  const params = new URLSearchParams({ feed, tickers: tickers.join(",") });
+
+  try {
+    const res = await fetch(`${apiUrl}/v1/data/stream?${params}`, {
+      headers: { "X-API-Key": apiKey, Accept: "text/event-stream" },
+      signal: controller.signal,
+    });
+    if (!res.ok || !res.body) {
+      const body = await res.json().catch(() => ({}));
+      throw new Error(`${body.error ?? res.statusText} (${res.status})`);
+    }
+    status = "live";
+
+    let buffer = "";
+    for await (const chunk of res.body.pipeThrough(new TextDecoderStream())) {
+      const parsed = parseSseEvents(buffer + chunk);
+      buffer = parsed.rest;
+      for (const { event, data } of parsed.events) {
+        if (event === "dropped") {
+          status = `live (${JSON.parse(data).dropped} dropped by rate limit)`;
+          continue;
+        }
+        if (event !== "message") continue;
+        const msg = JSON.parse(data) as { type: string; ticker: string; data: Record<string, unknown> };
+        const update = quoteUpdate(feed, msg.type, msg.data);
+        if (!update) continue;
+
+        const now = new Date().toISOString();
+        const quote = quotes.get(msg.ticker) ??
+          { ticker: msg.ticker, bid: null, ask: null, last: null, volume: null, updates: 0, updatedAt: null };
+        quotes.set(msg.ticker, { ...quote, ...update, updates: quote.updates + 1, updatedAt: now });
+        if (csv) await csv.write(encoder.encode(csvLine(now, msg.ticker, msg.type, update) + "\n"));
+      }
+    }
+    status = "stream closed";
+  } catch (e) {
+    if (!controller.signal.aborted) status = `error: ${(e as Error).message}`;
+  } finally {
+    clearInterval(timer);
+    draw();
+    csv?.close();
+    if (Deno.stdin.isTerminal()) Deno.stdin.setRaw(false);
+  }
+  Deno.exit(0);
+}
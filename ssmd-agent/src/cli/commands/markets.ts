@@ -0,0 +1,232 @@
+/**
+ * ssmd markets - Find markets from the terminal via the data API
+ *
+ * Subcommands:
+ *   list    List markets with category/status/close-time/volume filters
+ *   watch   Live bid/ask/last/volume table for tickers
+ */
+
+import { runMarketsWatch } from "./markets-watch.ts";
+
+interface MarketsFlags {
+  _: (string | number)[];
+  exchange?: string;
+  category?: string;
+  status?: string;
+  series?: string;
+  event?: string;
+  "closing-within"?: string;
+  "min-volume"?: string;
+  "min-volume-24h"?: string;
+  sort?: string;
+  limit?: string;
+  output?: string;
+  json?: boolean;
+  feed?: string;
+  interval?: string;
+  "csv-file"?: string;
+}
+
+/** Market fields the list view uses (the API returns the full row) */
+export interface MarketListRow {
+  ticker: string;
+  eventTicker: string;
+  title: string;
+  status: string;
+  closeTime: string | null;
+  lastPrice: number | null;
+  volume: number | null;
+  volume24h: number | null;
+}
+
+const SORTS = ["updated_at", "close_time", "volume"];
+const DEFAULT_LIMIT = 50;
+/** Page size requested from the API while following next_cursor */
+const PAGE_SIZE = 500;
+
+function getApiConfig(): { apiUrl: string; apiKey: string } {
+  const apiUrl = Deno.env.get("SSMD_API_URL") || "http://localhost:8080";
+  const apiKey = Deno.env.get("SSMD_DATA_API_KEY") || Deno.env.get("SSMD_API_KEY") || "";
+  if (!apiKey) {
+    console.error("Error: SSMD_DATA_API_KEY or SSMD_API_KEY environment variable required");
+    Deno.exit(1);
+  }
+  return { apiUrl, apiKey };
+}
+
+/** Parse a duration like 30m, 24h or 7d into milliseconds, or null if invalid */
+export function parseDuration(value: string): number | null {
+  const match = value.match(/^(\d+)(m|h|d)$/);
+  if (!match) return null;
+  const n = Number(match[1]);
+  if (n <= 0) return null;
+  const unitMs = { m: 60_000, h: 3_600_000, d: 86_400_000 }[match[2] as "m" | "h" | "d"];
+  return n * unitMs;
+}
+
+/**
+ * Build the /v1/markets query for list flags. Throws on invalid flags so the
+ * caller can print one error.
+ */
+export function buildMarketsQuery(flags: MarketsFlags, now: Date = new Date()): URLSearchParams {
+  const params = new URLSearchParams();
+  if (flags.exchange) params.set("exchange", String(flags.exchange));
+  if (flags.category) params.set("category", String(flags.category));
+  if (flags.status) params.set("status", String(flags.status));
+  if (flags.series) params.set("series", String(flags.series));
+  if (flags.event) params.set("event", String(flags.event));
+
+  if (flags["closing-within"]) {
+    const ms = parseDuration(flags["closing-within"]);
+    if (ms === null) {
+      throw new Error(`--closing-within must be a duration like 30m, 24h or 7d, got '${flags["closing-within"]}'`);
+    }
+    params.set("closing_before", new Date(now.getTime() + ms).toISOString());
+  }
+
+  for (const [flag, param] of [["min-volume", "min_volume"], ["min-volume-24h", "min_volume_24h"]] as const) {
+    const raw = flags[flag];
+    if (raw === undefined || raw === "") continue;
+    const n = Number(raw);
+    if (!Number.isInteger(n) || n < 0) {
+      throw new Error(`--${flag} must be a non-negative integer, got '${raw}'`);
+    }
+    params.set(param, String(n));
+  }
+
+  const sort = flags.sort ?? "updated_at";
+  if (!SORTS.includes(sort)) {
+    throw new Error(`--sort must be one of ${SORTS.join(", ")}, got '${sort}'`);
+  }
+  params.set("sort", sort);
+  return params;
+}
+
+export async function handleMarkets(subcommand: string, flags: MarketsFlags): Promise<void> {
+  switch (subcommand) {
+    case "list":
+      await listMarkets(flags);
+      break;
+    case "watch":
+      await runMarketsWatch(flags);
+      break;
+    case "help":
+    default:
+      printMarketsHelp();
+      break;
+  }
+}
+
+async function listMarkets(flags: MarketsFlags): Promise<void> {
+  const asJson = flags.json || flags.output === "json";
+  if (flags.output && flags.output !== "json" && flags.output !== "table") {
+    console.error(`Error: --output must be table or json, got '${flags.output}'`);
+    Deno.exit(1);
+  }
+  const limit = flags.limit ? Number(flags.limit) : DEFAULT_LIMIT;
+  if (!Number.isInteger(limit) || limit <= 0) {
+    console.error(`Error: --limit must be a positive integer, got '${flags.limit}'`);
+    Deno.exit(1);
+  }
+
+  let params: URLSearchParams;
+  try {
+    params = buildMarketsQuery(flags);
+  } catch (e) {
+    console.error(`Error: ${(e as Error).message}`);
+    Deno.exit(1);
+  }
+
+  const { apiUrl, apiKey } = getApiConfig();
+  const markets: MarketListRow[] = [];
+  let total: number | null = null;
+  let cursor: string | null = null;
+  do {
+    params.set("limit", String(Math.min(PAGE_SIZE, limit - markets.length)));
+    if (cursor) params.set("cursor", cursor);
+    const res = await fetch(`${apiUrl}/v1/markets?${params}`, { headers: { "X-API-Key": apiKey } });
+    if (!res.ok) {
+      const body = await res.json().catch(() => ({}));
+      console.error(`Error: ${body.error ?? res.statusText} (${res.status})`);
+      Deno.exit(1);
+    }
+    const page = await res.json() as { markets: MarketListRow[]; next_cursor: string | null };
+    total ??= Number(res.headers.get("X-Total-Count") ?? page.markets.length);
+    markets.push(...page.markets);
+    cursor = page.next_cursor;
+  } while (cursor && markets.length < limit);
+
+  if (asJson) {
+    console.log(JSON.stringify({ total, markets }, null, 2));
+    return;
+  }
+
+  if (markets.length === 0) {
+    console.log("No markets match.");
+    return;
+  }
+
+  const tickerWidth = Math.max(6, ...markets.map((m) => m.ticker.length));
+  const titleWidth = Math.min(40, Math.max(5, ...markets.map((m) => m.title.length)));
+  console.log(
+    "TICKER".padEnd(tickerWidth) + "  " +
+      "TITLE".padEnd(titleWidth) + "  " +
+      "STATUS".padEnd(8) + "  " +
+      "CLOSES".padEnd(17) + "  " +
+      "LAST".padStart(6) + "  " +
+      "VOLUME".padStart(12) + "  " +
+      "VOL24H".padStart(10),
+  );
+  console.log("-".repeat(tickerWidth + titleWidth + 67));
+  for (const m of markets) {
+    const title = m.title.length > titleWidth ? m.title.slice(0, titleWidth - 3) + "..." : m.title;
+    const closes = m.closeTime ? new Date(m.closeTime).toISOString().slice(0, 16).replace("T", " ") : "-";
+    const last = m.lastPrice !== null ? `$${Number(m.lastPrice).toFixed(2)}` : "-";
+    console.log(
+      m.ticker.padEnd(tickerWidth) + "  " +
+        title.padEnd(titleWidth) + "  " +
+        m.status.padEnd(8) + "  " +
+        closes.padEnd(17) + "  " +
+        last.padStart(6) + "  " +
+        (m.volume?.toLocaleString() ?? "-").padStart(12) + "  " +
+        (m.volume24h?.toLocaleString() ?? "-").padStart(10),
+    );
+  }
+  console.log(`\nShowing ${markets.length} of ${total} markets`);
+}
+
+function printMarketsHelp(): void {
+  console.log("Usage: ssmd markets <command> [options]");
+  console.log("");
+  console.log("Commands:");
+  console.log("  list                       List markets matching filters");
+  console.log("  watch <ticker...>          Live bid/ask/last/volume table (via /v1/data/stream)");
+  console.log("");
+  console.log("Options for list:");
+  console.log("  --exchange <name>          Exchange, e.g. kalshi (default: all)");
+  console.log("  --category <name>          Event category (e.g. Politics, Crypto)");
+  console.log("  --status <status>          Market status (e.g. open, active, closed)");
+  console.log("  --series <ticker>          Series ticker");
+  console.log("  --event <ticker>           Event ticker");
+  console.log("  --closing-within <dur>     Closing within a duration (30m, 24h, 7d)");
+  console.log("  --min-volume <n>           Minimum lifetime volume (contracts)");
+  console.log("  --min-volume-24h <n>       Minimum 24h volume (contracts)");
+  console.log("  --sort <order>             updated_at (default), close_time or volume");
+  console.log(`  --limit <n>                Maximum markets to show (default: ${DEFAULT_LIMIT})`);
+  console.log("  --output <table|json>      Output format (default: table)");
+  console.log("");
+  console.log("Options for watch:");
+  console.log("  --feed <feed>              kalshi (default), kraken-spot or kraken-futures");
+  console.log("  --sort <column>            ticker (default), bid, ask, last or volume");
+  console.log("  --interval <seconds>       Refresh interval (default: 1)");
+  console.log("  --csv-file <path>          Append every observed update to a CSV file");
+  console.log("");
+  console.log("Examples:");
+  console.log("  ssmd markets list --category Politics --status open --closing-within 24h --min-volume 1000 --sort volume");
+  console.log("  ssmd markets list --series KXBTCD --sort close_time --output json");
+  console.log("  ssmd markets watch KXBTCD-26JAN0517-T97499.99 KXBTCD-26JAN0517-T97999.99 --csv-file btc.csv");
+  console.log("");
+  console.log("Environment:");
+  console.log("  SSMD_API_URL        API base URL (default: http://localhost:8080)");
+  console.log("  SSMD_DATA_API_KEY   API key with secmaster:read (list) or datasets:read (watch) scope");
+}
@@ -1,15 +1,23 @@
 // CLI command router
 import { parse } from "https://deno.land/std@0.224.0/flags/mod.ts";
-import { getFeedsDir } from "../utils/paths.ts";
-import { initExchanges } from "./init.ts";
+import { findExchangesRoot, getFeedsDir } from "../utils/paths.ts";
+import { INIT_TEMPLATES, initExchanges, isInitTemplate } from "./init.ts";
 import {
   listFeeds,
   showFeed,
   createFeed,
   printFeedList,
   printFeed,
+  setCalendar,
+  addHoliday,
+  feedNextSession,
+  printSession,
+  isWeekday,
+  deleteFeed,
   type CreateFeedOptions,
 } from "./feed.ts";
+import type { Weekday } from "../../lib/types/feed.ts";
+import { runFeedPublish } from "./feed-publish.ts";
 import { handleSecmaster } from "./secmaster.ts";
 import { handleFees } from "./fees.ts";
 import { handleSeries } from "./series.ts";
@@ -32,11 +40,24 @@ import { handleVolume } from "./volume-analysis.ts";
 import { handleBilling } from "./billing.ts";
 import { handleSmokeTest } from "./smoke-test.ts";
 import { handleVerifyHourly } from "./verify-hourly.ts";
+import { handleNats } from "./nats.ts";
+import { handleDiff } from "./diff.ts";
+import { handleValidate } from "./validate.ts";
+import { handleCommit } from "./commit.ts";
+import { handleSchema } from "./schema.ts";
+import { handleRestore } from "./trash.ts";
+import { handleGraph } from "./graph.ts";
+import { handleK8s } from "./k8s.ts";
+import { handleSnap } from "./snap.ts";
+import { handleBackfill } from "./backfill.ts";
+import { handleLock, LOCK_FILE, verifyLock } from "./lock.ts";
+import { handleData } from "./data.ts";
+import { handleMarkets } from "./markets.ts";
 export async function run(args: string[]): Promise<void> {
   const flags = parse(args, {
-    string: ["_", "type", "endpoint", "display-name", "auth-method", "dates", "from", "to", "sha", "feed", "limit", "source", "data", "nats-url", "stream", "subject", "date", "connector-image", "archiver-image", "namespace", "message", "destination", "tail", "tag", "env", "config", "balance", "filter", "cache-dir", "results-dir", "run-id", "image", "bucket", "prefix", "trades-out", "spec", "name", "sort", "min-trades", "ticker", "window", "email", "scopes", "expires", "amount", "description", "days", "series-suffix", "interval", "mode", "trailing-minutes"],
-    boolean: ["help", "version", "allow-dirty", "no-wait", "events-only", "markets-only", "no-delete", "dry-run", "console", "wait", "follow", "games-only", "by-series", "json", "csv", "exclude-halted", "detailed", "spot", "perps", "notify-on-failure"],
-    alias: { h: "help", v: "version", t: "type", e: "endpoint", f: "follow" },
+    string: ["_", "events", "markets", "fee-type", "multiplier", "effective-from", "type", "endpoint", "display-name", "auth-method", "dates", "from", "to", "sha", "feed", "limit", "source", "data", "nats-url", "stream", "subject", "date", "connector-image", "archiver-image", "namespace", "message", "destination", "dest-bucket", "dest-prefix", "source-prefix", "tail", "tag", "env", "config", "balance", "filter", "cache-dir", "results-dir", "run-id", "image", "bucket", "prefix", "trades-out", "spec", "name", "sort", "min-trades", "threshold", "baseline-days", "from-sequence", "to-sequence", "sync-image", "ticker", "window", "email", "scopes", "expires", "amount", "description", "days", "series-suffix", "interval", "mode", "trailing-minutes", "template", "against", "output", "timezone", "holiday-calendar", "open", "close", "weekday", "file", "proto-message", "compatible-with", "capnp-struct", "impact", "kind", "dir", "max-pending", "max-ack-pending", "max-redelivered", "consumer", "sequence", "checkpoint", "speed", "subject-prefix", "closing-within", "min-volume", "min-volume-24h", "csv-file", "ledger", "schema", "merge-gap", "exchange"],
+    boolean: ["help", "version", "allow-dirty", "no-wait", "events-only", "markets-only", "no-delete", "dry-run", "console", "wait", "follow", "games-only", "by-series", "json", "csv", "exclude-halted", "detailed", "spot", "perps", "notify-on-failure", "sign", "allow-invalid", "closed", "breaking", "force", "canonical", "k8s", "apply", "publish", "diff", "repair", "delete-originals", "dedup", "rewrite", "candles", "check-keys", "full-scan", "live", "save"],
+    alias: { h: "help", v: "version", t: "type", e: "endpoint", f: "follow", m: "message" },
     default: { wait: true },
     collect: ["tag"], // Allow multiple --tag flags
   });
@@ -61,8 +82,13 @@ export async function run(args: string[]): Promise<void> {
 
     case "init": {
       const path = flags._[1] as string | undefined;
-      await initExchanges(path);
-      console.log("\nInitialized exchanges directory.");
+      const template = flags.template as string | undefined;
+      if (template !== undefined && !isInitTemplate(template)) {
+        console.error(`Unknown template: ${template} (expected ${INIT_TEMPLATES.join("|")})`);
+        Deno.exit(1);
+      }
+      await initExchanges(path, { template });
+      console.log(`\nInitialized exchanges directory${template ? ` from the ${template} template` : ""}.`);
       break;
     }
 
@@ -70,6 +96,30 @@ export async function run(args: string[]): Promise<void> {
       await handleFeedCommand(subcommand, flags);
       break;
 
+    case "diff":
+      await handleDiff(flags);
+      break;
+
+    case "validate":
+      await handleValidate(flags);
+      break;
+
+    case "commit":
+      await handleCommit(flags);
+      break;
+
+    case "schema":
+      await handleSchema(subcommand, flags);
+      break;
+
+    case "graph":
+      await handleGraph(flags);
+      break;
+
+    case "k8s":
+      await handleK8s(subcommand, flags);
+      break;
+
     case "secmaster":
       await handleSecmaster(subcommand, flags);
       break;
@@ -82,6 +132,14 @@ export async function run(args: string[]): Promise<void> {
       await handleSeries(subcommand, flags);
       break;
 
+    case "markets":
+      await handleMarkets(subcommand, flags);
+      break;
+
+    case "data":
+      await handleData(subcommand, flags);
+      break;
+
     case "signal":
       await handleSignalDeploy(subcommand, flags);
       break;
@@ -114,6 +172,26 @@ export async function run(args: string[]): Promise<void> {
       await handleHealth(subcommand, flags);
       break;
 
+    case "nats":
+      await handleNats(subcommand, flags);
+      break;
+
+    case "snap":
+      await handleSnap(subcommand, flags);
+      break;
+
+    case "backfill":
+      await handleBackfill(subcommand, flags);
+      break;
+
+    case "lock":
+      await handleLock(subcommand, flags, await findExchangesRoot());
+      break;
+
+    case "restore":
+      await handleRestore(flags, await findExchangesRoot());
+      break;
+
     case "dq":
       console.warn("WARN: 'dq' is deprecated, use 'health' instead");
       await handleHealth(subcommand, flags);
@@ -175,12 +253,24 @@ export async function run(args: string[]): Promise<void> {
       break;
     }
 
+    case "book-snapshot-consumer": {
+      const { runBookSnapshotConsumer } = await import("./book-snapshot-consumer.ts");
+      await runBookSnapshotConsumer(args.slice(1));
+      break;
+    }
+
     case "lifecycle-consumer": {
       const { runLifecycleConsumer } = await import("./lifecycle-consumer.ts");
       await runLifecycleConsumer(args.slice(1));
       break;
     }
 
+    case "expiry-watch": {
+      const { runExpiryWatch } = await import("./expiry-watch.ts");
+      await runExpiryWatch(args.slice(1));
+      break;
+    }
+
     default:
       console.error(`Unknown command: ${command}`);
       console.log("");
@@ -236,9 +326,120 @@ async function handleFeedCommand(
       break;
     }
 
+    case "delete": {
+      const name = flags._[2] as string;
+      if (!name) {
+        console.error("Usage: ssmd feed delete <name> [--force]");
+        Deno.exit(1);
+      }
+      try {
+        const t = await deleteFeed(await findExchangesRoot(), name, Boolean(flags.force));
+        if (t.referenced_by) {
+          console.warn(`Warning: still used by environment(s): ${t.referenced_by.join(", ")}`);
+        }
+        console.log(`Moved ${t.path} to trash (${t.id})`);
+        console.log(`Undo with: ssmd restore ${t.id}`);
+      } catch (e) {
+        console.error(e instanceof Error ? e.message : String(e));
+        Deno.exit(1);
+      }
+      break;
+    }
+
+    case "set-calendar": {
+      const name = flags._[2] as string;
+      const weekday = flags.weekday as string | undefined;
+      if (!name) {
+        console.error(
+          "Usage: ssmd feed set-calendar <name> [--timezone TZ] [--open HH:MM --close HH:MM] [--weekday sat (--closed | --open --close)]"
+        );
+        Deno.exit(1);
+      }
+      if (weekday !== undefined && !isWeekday(weekday)) {
+        console.error(`Unknown weekday '${weekday}' (use sun, mon, tue, wed, thu, fri, sat)`);
+        Deno.exit(1);
+      }
+      await setCalendar(feedsDir, name, {
+        timezone: flags.timezone as string | undefined,
+        holidayCalendar: flags["holiday-calendar"] as string | undefined,
+        openTime: flags.open as string | undefined,
+        closeTime: flags.close as string | undefined,
+        weekday: weekday as Weekday | undefined,
+        closed: Boolean(flags.closed),
+      });
+      console.log(`Updated calendar for feed: ${name}`);
+      const feed = await showFeed(feedsDir, name);
+      if (feed) printFeed(feed);
+      break;
+    }
+
+    case "add-holiday": {
+      const name = flags._[2] as string;
+      const date = flags._[3] as string;
+      if (!name || !date) {
+        console.error("Usage: ssmd feed add-holiday <name> <YYYY-MM-DD> [--name LABEL] [--close HH:MM for a half day]");
+        Deno.exit(1);
+      }
+      await addHoliday(feedsDir, name, String(date), {
+        name: flags.name as string | undefined,
+        closeTime: flags.close as string | undefined,
+      });
+      console.log(`Added ${flags.close ? `half day (close ${flags.close})` : "holiday"} ${date} to feed: ${name}`);
+      break;
+    }
+
+    case "next-session": {
+      const name = flags._[2] as string;
+      if (!name) {
+        console.error("Usage: ssmd feed next-session <name> [--from ISO-8601] [--json]");
+        Deno.exit(1);
+      }
+      const from = flags.from ? new Date(String(flags.from)) : new Date();
+      if (Number.isNaN(from.getTime())) {
+        console.error(`Invalid --from: ${flags.from}`);
+        Deno.exit(1);
+      }
+      const session = await feedNextSession(feedsDir, name, from);
+      if (flags.json) {
+        console.log(JSON.stringify(session));
+      } else {
+        printSession(session, (await showFeed(feedsDir, name))?.calendar);
+      }
+      break;
+    }
+
+    case "publish": {
+      const mode = flags.diff ? "diff" : flags["dry-run"] ? "dry-run" : "apply";
+      const exchangesRoot = await findExchangesRoot();
+      // With a lockfile, only the reviewed config may be applied
+      const drift = await verifyLock(exchangesRoot);
+      if (drift && drift.length > 0) {
+        console.error(`Config drifted from ${LOCK_FILE}:`);
+        for (const d of drift) console.error(`  ${d}`);
+        if (mode === "apply") {
+          console.error("Refusing to publish; review the change and run 'ssmd lock'.");
+          Deno.exit(1);
+        }
+        console.error();
+      }
+      await runFeedPublish(exchangesRoot, {
+        feed: flags._[2] as string | undefined,
+        mode,
+        env: flags.env as string | undefined,
+        namespace: flags.namespace as string | undefined,
+      });
+      break;
+    }
+
     default:
       console.error(`Unknown feed command: ${subcommand}`);
-      console.log("Usage: ssmd feed [list|show|add]");
+      console.log("Usage: ssmd feed [list|show|add|delete|publish|set-calendar|add-holiday|next-session]");
+      console.log("  delete <name> [--force]  Move the feed to exchanges/.trash; refused while environments use it");
+      console.log("  publish [name] [--diff|--dry-run]  Apply feed, schema and env ConfigMaps to the cluster");
+      console.log("                                     (refused when exchanges/ssmd.lock exists and config drifted)");
+      console.log("  set-calendar <name> [--timezone TZ] [--open HH:MM --close HH:MM] [--weekday DAY (--closed | --open --close)]");
+      console.log("  add-holiday <name> <YYYY-MM-DD> [--name LABEL] [--close HH:MM]  Holiday, or half day with --close");
+      console.log("  next-session <name> [--from ISO-8601] [--json]  Next collection window");
       Deno.exit(1);
   }
 }
@@ -250,13 +451,25 @@ function printHelp(): void {
   console.log("  ssmd [--env <name>] <command> [options]");
   console.log("");
   console.log("COMMANDS:");
-  console.log("  env               Manage environment contexts (list, use, current, show)");
+  console.log("  env               Manage environment contexts (list, use, current, show, delete)");
   console.log("  status            Show cluster status overview");
-  console.log("  init              Initialize exchanges directory");
-  console.log("  feed              Manage feed configurations");
+  console.log("  init              Initialize exchanges directory (--template kalshi|kraken|polymarket)");
+  console.log("  feed              Manage feed configurations (list, show, add, delete, publish, calendar)");
+  console.log("  lock              Record (write) or check (verify) exchanges/ssmd.lock config hashes");
+  console.log("  restore [id|name] Restore a feed or environment removed by delete (lists .trash without args)");
+  console.log("  diff              Compare exchanges/ configs against a git ref (--against REF, --output json)");
+  console.log("  validate          Check feeds, schemas and environment references (--against REF checks schema hashes, --check-keys resolves key sources, --output json|sarif; reports ssmd.lock drift)");
+  console.log("  commit            Validate, refresh ssmd.lock and commit exchanges/ with a change summary (-m MSG, --sign)");
+  console.log("  schema            Schema versions: list, check compatibility, add-version, rehash");
+  console.log("  graph             Feed/schema/environment dependency graph (--output dot|json, --k8s, --impact NODE)");
+  console.log("  k8s generate ENV  Connector/Archiver CRs from an exchanges/ environment (--kind, --apply)");
+  console.log("  k8s rearchive     One-shot Job rebuilding part of an archived day from a JetStream range");
   console.log("  series            Series metadata operations");
   console.log("  secmaster         Security master database operations");
+  console.log("  markets           Find markets (list) and watch live quotes (watch)");
   console.log("  fees              Fee schedule database operations");
+  console.log("  data              Raw archive tools (verify, validate, dedup, compact, stats, index, sample, diff, quality, replay, replicate)");
+  console.log("  backfill          Fetch historical Kalshi trades/candles into the raw archive");
   console.log("  signal            Manage Signal CRs in Kubernetes");
   console.log("  connector         Manage Connector CRs in Kubernetes");
   console.log("  archiver          Manage Archiver CRs in Kubernetes");
@@ -266,7 +479,9 @@ function printHelp(): void {
   console.log("  binance           Binance spot pairs sync");
   console.log("  polymarket        Polymarket conditions sync");
   console.log("  health            Pipeline health checks (connector status, stream flow, archive sync)");
-  console.log("  keys              Manage API keys (create, list, revoke)");
+  console.log("  nats              JetStream inspection (lag, checkpoint get/set)");
+  console.log("  snap              Latest cached state per ticker from ssmd-snap (get)");
+  console.log("  keys              Manage API keys (create, list, revoke) and audit key rotation");
   console.log("  share             Generate signed URLs for parquet data sharing");
   console.log("  audit-email       Send daily data access audit report email");
   console.log("  diagnosis         AI-powered analysis of health and DQ results");
@@ -276,7 +491,9 @@ function printHelp(): void {
   console.log("  verify-hourly     Verify current hourly KXBTCD contract is in secmaster");
   console.log("  hols              HOLS strategy — OHLCV from Kraken Spot REST or WS trade aggregation");
   console.log("  funding-rate-consumer  Consume Kraken Futures funding rates from NATS");
+  console.log("  book-snapshot-consumer  Write top-N order book levels from NATS to book_snapshots");
   console.log("  lifecycle-consumer  Consume Kalshi lifecycle events from NATS");
+  console.log("  expiry-watch        Publish closing-soon/closed market notices to NATS");
   console.log("");
   console.log("OPTIONS:");
   console.log("  --env <name>      Override current environment for this command");
@@ -0,0 +1,279 @@
+// nats.ts - JetStream inspection commands (consumer lag for alerting scripts)
+// and per-consumer sequence checkpoints (see lib/nats/checkpoint.ts)
+
+import { connect, type ConsumerInfo } from "npm:nats";
+import { type Checkpoint, CHECKPOINT_CONFIGMAP, type CheckpointStore } from "../../lib/nats/mod.ts";
+import { configMapCheckpointStore } from "../utils/checkpoints.ts";
+
+interface NatsFlags {
+  _: (string | number)[];
+  stream?: string;
+  "nats-url"?: string;
+  output?: string;
+  json?: boolean;
+  "max-pending"?: string;
+  "max-ack-pending"?: string;
+  "max-redelivered"?: string;
+  consumer?: string;
+  sequence?: string;
+  namespace?: string;
+  env?: string;
+}
+
+/** Per-consumer lag, flattened from JetStream ConsumerInfo */
+export interface ConsumerLag {
+  consumer: string;
+  /** Messages in the stream not yet delivered to the consumer */
+  pending: number;
+  /** Delivered but not yet acknowledged */
+  ack_pending: number;
+  /** Messages delivered more than once */
+  redelivered: number;
+  /** Stream sequence of the last delivered message */
+  delivered_seq: number;
+  /** Stream sequence below which everything is acknowledged */
+  ack_floor_seq: number;
+}
+
+/** Thresholds; a count strictly above a limit is a breach. Unset = unchecked */
+export interface LagThresholds {
+  maxPending?: number;
+  maxAckPending?: number;
+  maxRedelivered?: number;
+}
+
+export interface LagBreach {
+  consumer: string;
+  metric: "pending" | "ack_pending" | "redelivered";
+  value: number;
+  limit: number;
+}
+
+export function toConsumerLag(info: ConsumerInfo): ConsumerLag {
+  return {
+    consumer: info.name,
+    pending: info.num_pending,
+    ack_pending: info.num_ack_pending,
+    redelivered: info.num_redelivered,
+    delivered_seq: info.delivered?.stream_seq ?? 0,
+    ack_floor_seq: info.ack_floor?.stream_seq ?? 0,
+  };
+}
+
+/** Consumers over any threshold, in consumer order */
+export function findLagBreaches(lags: ConsumerLag[], thresholds: LagThresholds): LagBreach[] {
+  const checks: [LagBreach["metric"], number | undefined][] = [
+    ["pending", thresholds.maxPending],
+    ["ack_pending", thresholds.maxAckPending],
+    ["redelivered", thresholds.maxRedelivered],
+  ];
+  const breaches: LagBreach[] = [];
+  for (const lag of lags) {
+    for (const [metric, limit] of checks) {
+      if (limit !== undefined && lag[metric] > limit) {
+        breaches.push({ consumer: lag.consumer, metric, value: lag[metric], limit });
+      }
+    }
+  }
+  return breaches;
+}
+
+/** Parse a non-negative integer threshold flag; undefined when not given */
+export function parseThreshold(name: string, value: string | undefined): number | undefined {
+  if (value === undefined || value === "") return undefined;
+  const n = Number(value);
+  if (!Number.isInteger(n) || n < 0) {
+    throw new Error(`--${name} must be a non-negative integer, got '${value}'`);
+  }
+  return n;
+}
+
+export async function handleNats(subcommand: string, flags: NatsFlags): Promise<void> {
+  switch (subcommand) {
+    case "lag":
+      await runLag(flags);
+      break;
+    case "checkpoint":
+      await runCheckpoint(flags);
+      break;
+    default:
+      if (subcommand) console.error(`Unknown nats command: ${subcommand}`);
+      printNatsHelp();
+      Deno.exit(1);
+  }
+}
+
+async function runLag(flags: NatsFlags): Promise<void> {
+  const stream = flags.stream;
+  if (!stream) {
+    console.error("Error: --stream is required");
+    printNatsHelp();
+    Deno.exit(1);
+  }
+  const asJson = flags.json || flags.output === "json";
+  if (flags.output && flags.output !== "json" && flags.output !== "table") {
+    console.error(`Invalid --output: ${flags.output} (expected table or json)`);
+    Deno.exit(1);
+  }
+
+  let thresholds: LagThresholds;
+  try {
+    thresholds = {
+      maxPending: parseThreshold("max-pending", flags["max-pending"]),
+      maxAckPending: parseThreshold("max-ack-pending", flags["max-ack-pending"]),
+      maxRedelivered: parseThreshold("max-redelivered", flags["max-redelivered"]),
+    };
+  } catch (err) {
+    console.error(`Error: ${(err as Error).message}`);
+    Deno.exit(1);
+  }
+
+  const natsUrl = flags["nats-url"] ?? Deno.env.get("NATS_URL") ?? "nats://nats.nats.svc:4222";
+  const nc = await connect({ servers: natsUrl });
+  let lags: ConsumerLag[];
+  try {
+    const jsm = await nc.jetstreamManager();
+    const infos = await jsm.consumers.list(stream).next();
+    lags = infos.map(toConsumerLag).sort((a, b) => a.consumer.localeCompare(b.consumer));
+  } catch (err) {
+    console.error(`Failed to list consumers for ${stream}: ${(err as Error).message}`);
+    await nc.close();
+    Deno.exit(2);
+  }
+  await nc.close();
+
+  const breaches = findLagBreaches(lags, thresholds);
+
+  if (asJson) {
+    console.log(JSON.stringify({ stream, consumers: lags, breaches }, null, 2));
+  } else {
+    printLagTable(stream, lags, breaches);
+  }
+
+  // Exit 1 on breach so alerting scripts can key off the status alone
+  if (breaches.length > 0) {
+    Deno.exit(1);
+  }
+}
+
+function printLagTable(stream: string, lags: ConsumerLag[], breaches: LagBreach[]): void {
+  if (lags.length === 0) {
+    console.log(`No consumers on stream ${stream}`);
+    return;
+  }
+  const breached = new Set(breaches.map((b) => b.consumer));
+  const width = Math.max(8, ...lags.map((l) => l.consumer.length));
+
+  console.log(`Stream: ${stream}\n`);
+  console.log(
+    `${"CONSUMER".padEnd(width)}  ${"PENDING".padStart(10)}  ${"ACK PENDING".padStart(11)}  ${
+      "REDELIVERED".padStart(11)
+    }  ${"DELIVERED SEQ".padStart(13)}`,
+  );
+  for (const l of lags) {
+    const mark = breached.has(l.consumer) ? "  !" : "";
+    console.log(
+      `${l.consumer.padEnd(width)}  ${String(l.pending).padStart(10)}  ${String(l.ack_pending).padStart(11)}  ${
+        String(l.redelivered).padStart(11)
+      }  ${String(l.delivered_seq).padStart(13)}${mark}`,
+    );
+  }
+
+  if (breaches.length > 0) {
+    console.log("\nThreshold breaches:");
+    for (const b of breaches) {
+      console.log(`  ${b.consumer}: ${b.metric} ${b.value} > ${b.limit}`);
+    }
+  }
+}
+
+async function runCheckpoint(flags: NatsFlags): Promise<void> {
+  const action = flags._[2] as string;
+  const store = configMapCheckpointStore({ env: flags.env, namespace: flags.namespace });
+
+  try {
+    switch (action) {
+      case "get":
+        await runCheckpointGet(flags, store);
+        break;
+      case "set":
+        await runCheckpointSet(flags, store);
+        break;
+      default:
+        if (action) console.error(`Unknown checkpoint command: ${action}`);
+        printNatsHelp();
+        Deno.exit(1);
+    }
+  } catch (err) {
+    console.error(`Error: ${(err as Error).message}`);
+    Deno.exit(1);
+  }
+}
+
+async function runCheckpointGet(flags: NatsFlags, store: CheckpointStore): Promise<void> {
+  let checkpoints: Checkpoint[];
+  if (flags.consumer) {
+    if (!flags.stream) throw new Error("--stream is required with --consumer");
+    const checkpoint = await store.get(flags.stream, flags.consumer);
+    checkpoints = checkpoint ? [checkpoint] : [];
+  } else {
+    checkpoints = await store.list(flags.stream);
+  }
+
+  if (flags.json || flags.output === "json") {
+    console.log(JSON.stringify(flags.consumer ? checkpoints[0] ?? null : checkpoints, null, 2));
+  } else if (checkpoints.length === 0) {
+    console.log("No checkpoints recorded");
+  } else {
+    const width = Math.max(8, ...checkpoints.map((cp) => cp.consumer.length));
+    const streamWidth = Math.max(6, ...checkpoints.map((cp) => cp.stream.length));
+    console.log(`${"STREAM".padEnd(streamWidth)}  ${"CONSUMER".padEnd(width)}  ${"SEQUENCE".padStart(12)}  UPDATED`);
+    for (const cp of checkpoints) {
+      console.log(
+        `${cp.stream.padEnd(streamWidth)}  ${cp.consumer.padEnd(width)}  ${String(cp.sequence).padStart(12)}  ${cp.updated_at}`,
+      );
+    }
+  }
+
+  // Exit 1 when a named checkpoint is missing so jobs can fall back to a full replay
+  if (flags.consumer && checkpoints.length === 0) {
+    Deno.exit(1);
+  }
+}
+
+async function runCheckpointSet(flags: NatsFlags, store: CheckpointStore): Promise<void> {
+  if (!flags.stream || !flags.consumer) {
+    throw new Error("--stream and --consumer are required");
+  }
+  const sequence = parseThreshold("sequence", flags.sequence);
+  if (sequence === undefined) {
+    throw new Error("--sequence is required");
+  }
+  await store.set(flags.stream, flags.consumer, sequence);
+  console.log(`${flags.stream}/${flags.consumer} checkpoint set to ${sequence}`);
+}
+
+function printNatsHelp(): void {
+  console.log("Usage: ssmd nats lag --stream <STREAM> [options]");
+  console.log("       ssmd nats checkpoint get [--stream <STREAM>] [--consumer <NAME>] [--output json]");
+  console.log("       ssmd nats checkpoint set --stream <STREAM> --consumer <NAME> --sequence <N>");
+  console.log("");
+  console.log("lag reports per-consumer pending, ack-pending and redelivery counts.");
+  console.log("Exits 1 when any threshold is exceeded, 2 when the stream cannot be read.");
+  console.log("");
+  console.log("checkpoint reads or records the last processed stream sequence per consumer");
+  console.log(`(ConfigMap ${CHECKPOINT_CONFIGMAP}), so jobs resume after restarts; ssmd data replay`);
+  console.log("--checkpoint records and resumes from one.");
+  console.log("get exits 1 when a named consumer has no checkpoint.");
+  console.log("");
+  console.log("OPTIONS:");
+  console.log("  --stream <name>            JetStream stream (e.g. PROD_KALSHI)");
+  console.log("  --nats-url <url>           NATS server (default: $NATS_URL or nats://nats.nats.svc:4222)");
+  console.log("  --output <table|json>      Output format (default: table)");
+  console.log("  --max-pending <n>          Fail when a consumer has more than n undelivered messages");
+  console.log("  --max-ack-pending <n>      Fail when a consumer has more than n unacknowledged messages");
+  console.log("  --max-redelivered <n>      Fail when a consumer has more than n redelivered messages");
+  console.log("  --consumer <name>          Checkpoint consumer");
+  console.log("  --sequence <n>             Stream sequence to record (set)");
+  console.log("  --env <name>, --namespace <ns>  Cluster environment and namespace for checkpoints");
+}
@@ -8,11 +8,14 @@ import {
   softDeleteMissingConditions,
 } from "../../lib/db/polymarket.ts";
 import type { NewPolymarketCondition, NewPolymarketToken } from "../../lib/db/schema.ts";
+import { HttpClient } from "../../lib/httpx/mod.ts";
 
 const GAMMA_API_URL = "https://gamma-api.polymarket.com";
 const PAGE_SIZE = 100;
 const API_TIMEOUT_MS = 30000;
 
+const gammaHttp = new HttpClient({ name: "Gamma", timeoutMs: API_TIMEOUT_MS });
+
 // --- Gamma API response types ---
 
 interface GammaTag {
@@ -121,13 +124,7 @@ async function* fetchGammaEventBatches(): AsyncGenerator<GammaEvent[]> {
 
   while (true) {
     const url = `${GAMMA_API_URL}/events?active=true&closed=false&limit=${PAGE_SIZE}&offset=${offset}`;
-    const res = await fetch(url, { signal: AbortSignal.timeout(API_TIMEOUT_MS) });
-
-    if (!res.ok) {
-      throw new Error(`Gamma API error: ${res.status} ${await res.text()}`);
-    }
-
-    const events: GammaEvent[] = await res.json();
+    const events = await gammaHttp.getJson<GammaEvent[]>(url);
     if (events.length === 0) break;
 
     yield events;
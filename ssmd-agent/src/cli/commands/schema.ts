@@ -0,0 +1,464 @@
+// Schema commands: list versions, check compatibility, add a version, rehash sources
+// ssmd schema list|check|add-version|rehash
+import { parse as parseYaml, stringify as stringifyYaml } from "yaml";
+import { extname, join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import {
+  canonicalSourceHash,
+  compareFields,
+  type FieldMap,
+  fieldsFromCapnp,
+  fieldsFromJsonSchema,
+  fieldsFromProto,
+  formatFromPath,
+  isBreaking,
+  parseFieldMap,
+  type SchemaChange,
+  type SchemaFormat,
+  type SourceHashes,
+  sourceMatches,
+} from "../../lib/schema/mod.ts";
+import { findExchangesRoot } from "../utils/paths.ts";
+import { TablePrinter } from "../utils/table.ts";
+import { type ConfigSnapshot, loadWorkingTree } from "./diff.ts";
+import type { ValidationIssue } from "./validate.ts";
+
+interface SchemaFlags {
+  _: (string | number)[];
+  feed?: string;
+  from?: string;
+  to?: string;
+  file?: string;
+  "proto-message"?: string;
+  "capnp-struct"?: string;
+  canonical?: boolean;
+  "dry-run"?: boolean;
+  "compatible-with"?: string;
+  breaking?: boolean;
+  json?: boolean;
+}
+
+/**
+ * The file a version's fields were generated from, kept under schemas/sources/.
+ * New records carry canonical_sha256; sha256 (raw bytes) is from before
+ * canonical hashing and is replaced by `ssmd schema rehash --canonical`.
+ */
+export interface SchemaSource extends SourceHashes {
+  format: SchemaFormat;
+  /** Path relative to exchanges/schemas */
+  file: string;
+}
+
+/** One version of a message type, from exchanges/schemas/<file>.yaml */
+export interface SchemaVersion {
+  file: string;
+  feed: string;
+  type: string;
+  version: string;
+  compatible_with?: string[];
+  source?: SchemaSource;
+  fields: FieldMap;
+}
+
+export interface SchemaCheck {
+  feed: string;
+  type: string;
+  from: string;
+  to: string;
+  changes: SchemaChange[];
+}
+
+export interface AddVersionOptions {
+  file: string;
+  protoMessage?: string;
+  capnpStruct?: string;
+  /** Versions the new one declares itself compatible with; defaults to the previous version */
+  compatibleWith?: string[];
+  /** Acknowledge breaking changes against the previous version */
+  breaking?: boolean;
+}
+
+export interface AddVersionResult {
+  path: string;
+  previous: string | null;
+  changes: SchemaChange[];
+  compatibleWith: string[];
+}
+
+/** Version ids in release order: v2 before v10 */
+export function compareVersionIds(a: string, b: string): number {
+  return a.localeCompare(b, undefined, { numeric: true });
+}
+
+/** "<feed>/<type>" as used on the command line */
+export function parseSchemaName(name: string): { feed: string; type: string } {
+  const m = name.match(/^([^/]+)\/([^/]+)$/);
+  if (!m) {
+    throw new Error(`Schema name must be <feed>/<type>, got '${name}'`);
+  }
+  return { feed: m[1], type: m[2] };
+}
+
+/**
+ * Every well-formed schema version in exchanges/schemas. Files that fail to
+ * parse are skipped here; `ssmd validate` reports them.
+ */
+export async function loadSchemaVersions(root: string): Promise<SchemaVersion[]> {
+  const versions: SchemaVersion[] = [];
+  for (const [file, doc] of (await loadWorkingTree(root)).schemas) {
+    const s = doc as Record<string, unknown> | null;
+    if (!s || typeof s.feed !== "string" || typeof s.type !== "string" || typeof s.version !== "string") {
+      continue;
+    }
+    let fields: FieldMap;
+    try {
+      fields = parseFieldMap(s.fields);
+    } catch {
+      continue;
+    }
+    versions.push({
+      file,
+      feed: s.feed,
+      type: s.type,
+      version: s.version,
+      compatible_with: Array.isArray(s.compatible_with) ? s.compatible_with.map(String) : undefined,
+      source: s.source as SchemaSource | undefined,
+      fields,
+    });
+  }
+  return versions.sort((a, b) =>
+    a.feed.localeCompare(b.feed) || a.type.localeCompare(b.type) || compareVersionIds(a.version, b.version)
+  );
+}
+
+function versionsOf(all: SchemaVersion[], feed: string, type: string): SchemaVersion[] {
+  return all.filter((v) => v.feed === feed && v.type === type);
+}
+
+/**
+ * Compare two versions of a message type. `to` defaults to the latest
+ * version and `from` to the one before it.
+ */
+export function checkSchema(all: SchemaVersion[], name: string, from?: string, to?: string): SchemaCheck {
+  const { feed, type } = parseSchemaName(name);
+  const versions = versionsOf(all, feed, type);
+  if (versions.length === 0) {
+    throw new Error(`No schema versions for ${name} in exchanges/schemas`);
+  }
+  const find = (id: string) => {
+    const v = versions.find((v) => v.version === id);
+    if (!v) throw new Error(`${name} has no version ${id} (have ${versions.map((v) => v.version).join(", ")})`);
+    return v;
+  };
+
+  const target = to ? find(to) : versions[versions.length - 1];
+  const base = from ? find(from) : versions[versions.indexOf(target) - 1];
+  if (!base) {
+    throw new Error(`${name} ${target.version} has no earlier version to compare with; pass --from`);
+  }
+  return { feed, type, from: base.version, to: target.version, changes: compareFields(base.fields, target.fields) };
+}
+
+/**
+ * Fields from a source file: a JSON Schema (.json), a protobuf message
+ * (.proto), a Cap'n Proto struct (.capnp) or a YAML file holding a field map
+ * (or a JSON Schema).
+ */
+export async function readSourceFields(
+  path: string,
+  options: { protoMessage?: string; capnpStruct?: string } = {},
+): Promise<{ format: SchemaFormat; fields: FieldMap }> {
+  const text = await Deno.readTextFile(path);
+  const format = formatFromPath(path);
+  if (format === "protobuf") {
+    return { format, fields: fieldsFromProto(text, options.protoMessage) };
+  }
+  if (format === "capnp") {
+    return { format, fields: fieldsFromCapnp(text, options.capnpStruct) };
+  }
+  if (format === "json-schema") {
+    return { format, fields: fieldsFromJsonSchema(JSON.parse(text)) };
+  }
+
+  const doc = parseYaml(text) as Record<string, unknown> | null;
+  if (doc && typeof doc === "object" && "properties" in doc) {
+    return { format: "json-schema", fields: fieldsFromJsonSchema(doc) };
+  }
+  return { format, fields: parseFieldMap(doc && "fields" in doc ? doc.fields : doc) };
+}
+
+function formatChange(c: SchemaChange): string {
+  const type = (spec?: { type: unknown; required?: true }) =>
+    spec ? `${Array.isArray(spec.type) ? spec.type.join("|") : spec.type}${spec.required ? " (required)" : ""}` : "";
+  switch (c.kind) {
+    case "added":
+      return `+ ${c.path}: ${type(c.to)}`;
+    case "removed":
+      return `- ${c.path}: ${type(c.from)}`;
+    default:
+      return `~ ${c.path}: ${type(c.from)} -> ${type(c.to)}`;
+  }
+}
+
+export function printChanges(changes: SchemaChange[]): void {
+  for (const c of changes) {
+    console.log(`  ${c.breaking ? "BREAKING" : "ok      "} ${formatChange(c)}`);
+  }
+}
+
+/**
+ * Write schemas/<feed>-<type>-<version>.yaml from a source file, checking it
+ * against the previous version and every version it declares compatibility
+ * with. Fails on a breaking change that is not acknowledged with `breaking`,
+ * or on any breaking change against a declared compatible version.
+ */
+export async function addSchemaVersion(
+  root: string,
+  name: string,
+  version: string,
+  options: AddVersionOptions,
+): Promise<AddVersionResult> {
+  const { feed, type } = parseSchemaName(name);
+  const all = await loadSchemaVersions(root);
+  const versions = versionsOf(all, feed, type);
+  if (versions.some((v) => v.version === version)) {
+    throw new Error(`${name} ${version} already exists`);
+  }
+  const previous = versions.length > 0 ? versions[versions.length - 1] : null;
+  if (previous && compareVersionIds(version, previous.version) < 0) {
+    throw new Error(`${version} sorts before the latest version ${previous.version}`);
+  }
+
+  const source = await readSourceFields(options.file, options);
+  const changes = previous ? compareFields(previous.fields, source.fields) : [];
+
+  const compatibleWith = options.compatibleWith ??
+    (previous && !isBreaking(changes) ? [previous.version] : []);
+  const undeclared: string[] = [];
+  for (const id of compatibleWith) {
+    const declared = versions.find((v) => v.version === id);
+    if (!declared) {
+      throw new Error(`compatible_with ${id}: ${name} has no such version`);
+    }
+    const breaking = compareFields(declared.fields, source.fields).filter((c) => c.breaking);
+    undeclared.push(...breaking.map((c) => `${id}: ${formatChange(c)}`));
+  }
+  if (undeclared.length > 0) {
+    throw new Error(`${name} ${version} declares compatibility but has breaking changes:\n  ${undeclared.join("\n  ")}`);
+  }
+  if (previous && isBreaking(changes) && !options.breaking) {
+    const lines = changes.filter((c) => c.breaking).map(formatChange);
+    throw new Error(
+      `${name} ${version} breaks ${previous.version}:\n  ${lines.join("\n  ")}\nPass --breaking to add it as an incompatible version`,
+    );
+  }
+
+  const doc: Record<string, unknown> = { feed, type, version };
+  if (compatibleWith.length > 0) doc.compatible_with = compatibleWith;
+
+  // JSON Schema and proto sources are kept next to the generated fields, so
+  // validate can tell when a source was edited without regenerating them
+  await Deno.mkdir(join(root, "schemas", "sources"), { recursive: true });
+  if (source.format !== "fields") {
+    const content = await Deno.readFile(options.file);
+    const file = `sources/${feed}-${type}-${version}${extname(options.file)}`;
+    await Deno.writeFile(join(root, "schemas", file), content);
+    doc.source = {
+      format: source.format,
+      file,
+      canonical_sha256: await canonicalSourceHash(source.format, content),
+    } satisfies SchemaSource;
+  }
+  doc.fields = source.fields;
+
+  const path = join(root, "schemas", `${feed}-${type}-${version}.yaml`);
+  await Deno.writeTextFile(path, stringifyYaml(doc, { indent: 2 }));
+  return { path, previous: previous?.version ?? null, changes, compatibleWith };
+}
+
+/** Schema versions whose recorded source file is missing or no longer matches its hash */
+export async function checkSchemaSources(root: string, snapshot: ConfigSnapshot): Promise<ValidationIssue[]> {
+  const issues: ValidationIssue[] = [];
+  for (const [name, doc] of snapshot.schemas) {
+    const source = (doc as { source?: Partial<SchemaSource> } | null)?.source;
+    if (!source) continue;
+    const file = `schemas/${name}.yaml`;
+    if (typeof source.file !== "string" || typeof source.format !== "string" || (!source.sha256 && !source.canonical_sha256)) {
+      issues.push({ code: "SSMD-S009", severity: "error", file, message: "source needs format, file and sha256 or canonical_sha256" });
+      continue;
+    }
+    let content: Uint8Array;
+    try {
+      content = await Deno.readFile(join(root, "schemas", source.file));
+    } catch (e) {
+      if (!(e instanceof Deno.errors.NotFound)) throw e;
+      issues.push({ code: "SSMD-S010", severity: "error", file, message: `source ${source.file} is missing` });
+      continue;
+    }
+    if (!await sourceMatches(source.format, content, source)) {
+      issues.push({
+        code: "SSMD-S011",
+        severity: "error",
+        file,
+        message: `source ${source.file} changed since its fields were generated; add a new version instead`,
+      });
+    }
+  }
+  return issues;
+}
+
+export type RehashStatus = "rehashed" | "current" | "changed" | "missing";
+
+/**
+ * Move raw source hashes to canonical ones. A source is only rehashed when it
+ * still matches its raw hash, so the migration cannot bless an edited file.
+ */
+export async function rehashSchemaSources(
+  root: string,
+  dryRun = false,
+): Promise<{ file: string; source: string; status: RehashStatus }[]> {
+  const results: { file: string; source: string; status: RehashStatus }[] = [];
+  for (const [name, doc] of (await loadWorkingTree(root)).schemas) {
+    const schema = doc as { source?: SchemaSource } | null;
+    const source = schema?.source;
+    if (!source?.file) continue;
+    const file = `${name}.yaml`;
+    if (source.canonical_sha256 && !source.sha256) {
+      results.push({ file, source: source.file, status: "current" });
+      continue;
+    }
+
+    let content: Uint8Array;
+    try {
+      content = await Deno.readFile(join(root, "schemas", source.file));
+    } catch (e) {
+      if (!(e instanceof Deno.errors.NotFound)) throw e;
+      results.push({ file, source: source.file, status: "missing" });
+      continue;
+    }
+    if (!await sourceMatches(source.format, content, source)) {
+      results.push({ file, source: source.file, status: "changed" });
+      continue;
+    }
+
+    const { sha256: _raw, ...rest } = source;
+    schema!.source = { ...rest, canonical_sha256: await canonicalSourceHash(source.format, content) };
+    if (!dryRun) {
+      await Deno.writeTextFile(join(root, "schemas", file), stringifyYaml(schema as Record<string, unknown>, { indent: 2 }));
+    }
+    results.push({ file, source: source.file, status: "rehashed" });
+  }
+  return results;
+}
+
+function fail(e: unknown): never {
+  console.error(e instanceof Error ? e.message : String(e));
+  Deno.exit(1);
+}
+
+export async function handleSchema(subcommand: string, flags: SchemaFlags): Promise<void> {
+  switch (subcommand) {
+    case "list":
+    case undefined: {
+      const all = (await loadSchemaVersions(await findExchangesRoot()))
+        .filter((v) => !flags.feed || v.feed === flags.feed);
+      if (flags.json) {
+        console.log(JSON.stringify(all.map(({ fields, ...v }) => ({ ...v, fields: Object.keys(fields).length })), null, 2));
+        break;
+      }
+      if (all.length === 0) {
+        console.log("No schemas found in exchanges/schemas");
+        break;
+      }
+      const t = new TablePrinter();
+      t.header("FEED", "TYPE", "VERSION", "COMPATIBLE WITH", "FIELDS", "FILE");
+      for (const v of all) {
+        t.row(v.feed, v.type, v.version, v.compatible_with?.join(",") ?? "-", String(Object.keys(v.fields).length), `${v.file}.yaml`);
+      }
+      t.flush();
+      break;
+    }
+
+    case "check": {
+      const name = flags._[2] as string | undefined;
+      if (!name) {
+        console.error("Usage: ssmd schema check <feed>/<type> [--from VERSION] [--to VERSION] [--json]");
+        Deno.exit(1);
+      }
+      let result: SchemaCheck;
+      try {
+        result = checkSchema(await loadSchemaVersions(await findExchangesRoot()), name, flags.from, flags.to);
+      } catch (e) {
+        fail(e);
+      }
+      const breaking = isBreaking(result.changes);
+      if (flags.json) {
+        console.log(JSON.stringify({ ...result, breaking }, null, 2));
+      } else {
+        console.log(`${name} ${result.from} -> ${result.to}: ${result.changes.length} change(s)`);
+        printChanges(result.changes);
+        console.log(breaking ? "\nBreaking: readers of the new version cannot read all old data" : "\nCompatible");
+      }
+      if (breaking) Deno.exit(1);
+      break;
+    }
+
+    case "add-version": {
+      const name = flags._[2] as string | undefined;
+      const version = flags._[3] as string | undefined;
+      if (!name || !version || !flags.file) {
+        console.error(
+          "Usage: ssmd schema add-version <feed>/<type> <version> --file <schema.json|.proto|.capnp|.yaml> [--proto-message NAME] [--capnp-struct NAME] [--compatible-with v1,v2] [--breaking]",
+        );
+        Deno.exit(1);
+      }
+      try {
+        const result = await addSchemaVersion(await findExchangesRoot(), name, String(version), {
+          file: flags.file,
+          protoMessage: flags["proto-message"],
+          capnpStruct: flags["capnp-struct"],
+          compatibleWith: flags["compatible-with"]?.split(",").map((s) => s.trim()).filter(Boolean),
+          breaking: flags.breaking,
+        });
+        if (result.previous) {
+          console.log(`${name} ${result.previous} -> ${version}: ${result.changes.length} change(s)`);
+          printChanges(result.changes);
+        }
+        console.log(`\nWrote ${result.path}`);
+        if (result.compatibleWith.length > 0) {
+          console.log(`compatible_with: ${result.compatibleWith.join(", ")}`);
+        }
+      } catch (e) {
+        fail(e);
+      }
+      break;
+    }
+
+    case "rehash": {
+      if (!flags.canonical) {
+        console.error("Usage: ssmd schema rehash --canonical [--dry-run]");
+        Deno.exit(1);
+      }
+      const results = await rehashSchemaSources(await findExchangesRoot(), flags["dry-run"]);
+      for (const r of results) {
+        console.log(`${r.status.padEnd(9)} ${r.file} (${r.source})`);
+      }
+      const rehashed = results.filter((r) => r.status === "rehashed").length;
+      console.log(`\n${rehashed} source(s) ${flags["dry-run"] ? "would be " : ""}rehashed`);
+      if (results.some((r) => r.status === "changed" || r.status === "missing")) {
+        console.error("Sources that changed or are missing keep their raw hash; add a new version for them");
+        Deno.exit(1);
+      }
+      break;
+    }
+
+    default:
+      console.error(`Unknown schema command: ${subcommand}`);
+      console.log("Usage: ssmd schema [list|check|add-version|rehash]");
+      console.log("  list [--feed FEED] [--json]                       Schema versions in exchanges/schemas");
+      console.log("  check <feed>/<type> [--from V] [--to V] [--json]  Classify changes; exit 1 if breaking");
+      console.log("  add-version <feed>/<type> <version> --file F      Add a version from JSON Schema, .proto, .capnp or a field map");
+      console.log("              [--proto-message NAME] [--capnp-struct NAME] [--compatible-with V,...] [--breaking]");
+      console.log("  rehash --canonical [--dry-run]                    Replace raw source hashes with canonical ones");
+      Deno.exit(1);
+  }
+}
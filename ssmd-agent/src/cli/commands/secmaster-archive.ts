@@ -0,0 +1,223 @@
+/**
+ * Secmaster archive command - snapshot the events and markets tables as
+ * parquet under secmaster/YYYY-MM-DD/ in the archive bucket, with a
+ * manifest.json, so reference data is versioned alongside tick data
+ */
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { encodeHex } from "https://deno.land/std@0.224.0/encoding/hex.ts";
+import { Storage } from "@google-cloud/storage";
+import { DuckDBInstance } from "@duckdb/node-api";
+import { closeDb, getRawSql } from "../../lib/db/client.ts";
+
+/** Rows fetched from Postgres per cursor batch */
+const CURSOR_BATCH_SIZE = 5000;
+
+export const SECMASTER_PREFIX = "secmaster";
+
+/** A snapshotted table: its ordering key and parquet column types (DuckDB) */
+export interface SnapshotTable {
+  table: string;
+  key: string;
+  columns: Record<string, string>;
+}
+
+export const SNAPSHOT_TABLES: SnapshotTable[] = [
+  {
+    table: "events",
+    key: "event_ticker",
+    columns: {
+      exchange: "VARCHAR",
+      event_ticker: "VARCHAR",
+      title: "VARCHAR",
+      category: "VARCHAR",
+      series_ticker: "VARCHAR",
+      strike_date: "TIMESTAMPTZ",
+      mutually_exclusive: "BOOLEAN",
+      status: "VARCHAR",
+      created_at: "TIMESTAMPTZ",
+      updated_at: "TIMESTAMPTZ",
+      deleted_at: "TIMESTAMPTZ",
+    },
+  },
+  {
+    table: "markets",
+    key: "ticker",
+    columns: {
+      exchange: "VARCHAR",
+      ticker: "VARCHAR",
+      event_ticker: "VARCHAR",
+      title: "VARCHAR",
+      status: "VARCHAR",
+      close_time: "TIMESTAMPTZ",
+      yes_bid: "DECIMAL(8,4)",
+      yes_ask: "DECIMAL(8,4)",
+      no_bid: "DECIMAL(8,4)",
+      no_ask: "DECIMAL(8,4)",
+      last_price: "DECIMAL(8,4)",
+      volume: "BIGINT",
+      volume_24h: "BIGINT",
+      open_interest: "BIGINT",
+      floor_strike: "DOUBLE",
+      cap_strike: "DOUBLE",
+      strike_type: "VARCHAR",
+      result: "VARCHAR",
+      expiration_value: "VARCHAR",
+      yes_sub_title: "VARCHAR",
+      no_sub_title: "VARCHAR",
+      can_close_early: "BOOLEAN",
+      market_type: "VARCHAR",
+      open_time: "TIMESTAMPTZ",
+      expected_expiration_time: "TIMESTAMPTZ",
+      created_at: "TIMESTAMPTZ",
+      updated_at: "TIMESTAMPTZ",
+      deleted_at: "TIMESTAMPTZ",
+    },
+  },
+];
+
+export interface ArchiveOptions {
+  /** Snapshot date label (YYYY-MM-DD), default today in UTC */
+  date?: string;
+  bucket?: string;
+  /** Build the parquet files but don't upload */
+  dryRun?: boolean;
+}
+
+export interface SnapshotFile {
+  name: string;
+  table: string;
+  records: number;
+  bytes: number;
+  sha256: string;
+}
+
+export interface SnapshotManifest {
+  date: string;
+  generated_at: string;
+  source: "secmaster";
+  format: "parquet";
+  files: SnapshotFile[];
+}
+
+export interface ArchiveResult {
+  /** gs:// URL of the snapshot directory, or null for a dry run */
+  location: string | null;
+  manifest: SnapshotManifest;
+}
+
+/** Object prefix of a day's snapshot */
+export function snapshotPrefix(date: string): string {
+  return `${SECMASTER_PREFIX}/${date}/`;
+}
+
+/**
+ * One Postgres row as a JSON line for DuckDB: only the snapshot columns,
+ * timestamps as ISO strings and numeric/bigint text (postgres.js returns
+ * both as strings) as numbers.
+ */
+export function toSnapshotRow(row: Record<string, unknown>, columns: Record<string, string>): Record<string, unknown> {
+  const out: Record<string, unknown> = {};
+  for (const [name, type] of Object.entries(columns)) {
+    const value = row[name];
+    if (value === null || value === undefined) {
+      out[name] = null;
+    } else if (value instanceof Date) {
+      out[name] = value.toISOString();
+    } else if ((type === "BIGINT" || type === "DOUBLE" || type.startsWith("DECIMAL")) && typeof value === "string") {
+      out[name] = Number(value);
+    } else {
+      out[name] = value;
+    }
+  }
+  return out;
+}
+
+/** DuckDB struct literal of column types for read_json(columns=...) */
+export function duckColumns(columns: Record<string, string>): string {
+  return `{${Object.entries(columns).map(([name, type]) => `'${name}': '${type}'`).join(", ")}}`;
+}
+
+/** Dump a table to newline-delimited JSON, returning the row count */
+async function dumpTable(spec: SnapshotTable, path: string): Promise<number> {
+  const sql = getRawSql();
+  const encoder = new TextEncoder();
+  const file = await Deno.open(path, { write: true, create: true, truncate: true });
+  let records = 0;
+  try {
+    const cursor = sql`SELECT * FROM ${sql(spec.table)} ORDER BY ${sql(spec.key)}`.cursor(CURSOR_BATCH_SIZE);
+    for await (const rows of cursor) {
+      const lines = rows.map((row) => JSON.stringify(toSnapshotRow(row, spec.columns))).join("\n") + "\n";
+      await file.write(encoder.encode(lines));
+      records += rows.length;
+    }
+  } finally {
+    file.close();
+  }
+  return records;
+}
+
+async function sha256File(path: string): Promise<string> {
+  return encodeHex(await crypto.subtle.digest("SHA-256", await Deno.readFile(path)));
+}
+
+/** Snapshot every table to parquet in a temp directory, then upload with the manifest */
+export async function runSecmasterArchive(options: ArchiveOptions = {}): Promise<ArchiveResult> {
+  const date = options.date ?? new Date().toISOString().slice(0, 10);
+  if (!/^\d{4}-\d{2}-\d{2}$/.test(date)) {
+    throw new Error(`invalid date: ${date} (expected YYYY-MM-DD)`);
+  }
+  const bucket = options.bucket ?? Deno.env.get("GCS_BUCKET");
+  if (!bucket && !options.dryRun) {
+    throw new Error("--bucket or GCS_BUCKET is required");
+  }
+
+  const dir = await Deno.makeTempDir({ prefix: "ssmd-secmaster-" });
+  const instance = await DuckDBInstance.create();
+  const connection = await instance.connect();
+  try {
+    const files: SnapshotFile[] = [];
+    for (const spec of SNAPSHOT_TABLES) {
+      const jsonl = join(dir, `${spec.table}.jsonl`);
+      const name = `${spec.table}.parquet`;
+      const records = await dumpTable(spec, jsonl);
+      await connection.run(
+        `COPY (SELECT * FROM read_json('${jsonl}', format = 'newline_delimited', columns = ${duckColumns(spec.columns)})) ` +
+          `TO '${join(dir, name)}' (FORMAT PARQUET, COMPRESSION ZSTD)`,
+      );
+      const { size } = await Deno.stat(join(dir, name));
+      files.push({ name, table: spec.table, records, bytes: size, sha256: await sha256File(join(dir, name)) });
+    }
+
+    const manifest: SnapshotManifest = {
+      date,
+      generated_at: new Date().toISOString(),
+      source: "secmaster",
+      format: "parquet",
+      files,
+    };
+    if (options.dryRun || !bucket) {
+      return { location: null, manifest };
+    }
+
+    // Parquet first, manifest last: a manifest means the snapshot is complete
+    const storage = new Storage().bucket(bucket);
+    const prefix = snapshotPrefix(date);
+    for (const file of files) {
+      await storage.upload(join(dir, file.name), { destination: `${prefix}${file.name}` });
+    }
+    await storage.file(`${prefix}manifest.json`).save(JSON.stringify(manifest, null, 2), {
+      contentType: "application/json",
+    });
+    return { location: `gs://${bucket}/${prefix}`, manifest };
+  } finally {
+    await closeDb();
+    await Deno.remove(dir, { recursive: true });
+  }
+}
+
+export function printArchiveSummary(result: ArchiveResult): void {
+  console.log(`Secmaster snapshot ${result.manifest.date}${result.location ? ` -> ${result.location}` : " (dry run, not uploaded)"}`);
+  for (const file of result.manifest.files) {
+    console.log(`  ${file.name.padEnd(16)} ${String(file.records).padStart(9)} rows  ${String(file.bytes).padStart(11)} bytes`);
+  }
+}
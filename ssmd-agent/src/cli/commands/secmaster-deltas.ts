@@ -0,0 +1,126 @@
+/**
+ * Secmaster change notifications - compact deltas for markets a sync inserts
+ * or whose status or close_time moves, published to NATS in batches so
+ * connectors can pick up new markets without polling the API.
+ * Enabled with `ssmd secmaster sync --publish`.
+ */
+import { connect } from "npm:nats";
+import type { MarketRow } from "../../lib/db/markets.ts";
+import type { Market as ApiMarket } from "../../lib/types/market.ts";
+import { normalizeDiffValue } from "./secmaster-diff.ts";
+
+export const DEFAULT_DELTA_SUBJECT = "secmaster.updates.markets";
+
+// Deltas per NATS message
+const DELTA_BATCH_SIZE = 100;
+
+export interface MarketDelta {
+  ticker: string;
+  event_ticker: string;
+  /** "new" for markets not in the table (or soft-deleted) */
+  change: "new" | "updated";
+  status: string;
+  close_time: string | null;
+  /** Previous values, set only when they changed */
+  prev_status?: string;
+  prev_close_time?: string | null;
+}
+
+/** One published message */
+export interface MarketDeltaBatch {
+  ts: string;
+  markets: MarketDelta[];
+}
+
+/** The part of a NATS connection the publisher uses */
+export interface DeltaSink {
+  publish(subject: string, data: Uint8Array): void;
+  flush(): Promise<void>;
+}
+
+/**
+ * Deltas for a fetched market batch against the current rows by ticker.
+ * Price, volume and metadata changes are not deltas.
+ */
+export function marketDeltas(batch: ApiMarket[], existing: Map<string, MarketRow>): MarketDelta[] {
+  const deltas: MarketDelta[] = [];
+  for (const m of batch) {
+    const closeTime = normalizeDiffValue(m.close_time) as string | null;
+    const row = existing.get(m.ticker);
+    const base = { ticker: m.ticker, event_ticker: m.event_ticker, status: m.status, close_time: closeTime };
+    if (!row) {
+      deltas.push({ ...base, change: "new" });
+      continue;
+    }
+    const prevCloseTime = normalizeDiffValue(row.closeTime) as string | null;
+    const statusChanged = row.status !== m.status;
+    const closeChanged = prevCloseTime !== closeTime;
+    if (statusChanged || closeChanged) {
+      deltas.push({
+        ...base,
+        change: "updated",
+        ...(statusChanged ? { prev_status: row.status } : {}),
+        ...(closeChanged ? { prev_close_time: prevCloseTime } : {}),
+      });
+    }
+  }
+  return deltas;
+}
+
+/**
+ * Buffers deltas and publishes them DELTA_BATCH_SIZE at a time. A ticker
+ * fetched by more than one sync pass is published once.
+ */
+export class DeltaPublisher {
+  private pending: MarketDelta[] = [];
+  private seen = new Set<string>();
+  published = 0;
+
+  constructor(
+    private sink: DeltaSink,
+    private subject = DEFAULT_DELTA_SUBJECT,
+    private batchSize = DELTA_BATCH_SIZE,
+  ) {}
+
+  add(deltas: MarketDelta[]): void {
+    for (const d of deltas) {
+      if (this.seen.has(d.ticker)) continue;
+      this.seen.add(d.ticker);
+      this.pending.push(d);
+      if (this.pending.length >= this.batchSize) this.publishPending();
+    }
+  }
+
+  /** Publish what is buffered and wait for the server to receive it */
+  async flush(): Promise<void> {
+    this.publishPending();
+    await this.sink.flush();
+  }
+
+  private publishPending(): void {
+    if (this.pending.length === 0) return;
+    const msg: MarketDeltaBatch = { ts: new Date().toISOString(), markets: this.pending };
+    this.sink.publish(this.subject, new TextEncoder().encode(JSON.stringify(msg)));
+    this.published += this.pending.length;
+    this.pending = [];
+  }
+}
+
+/** Connect to NATS and return a publisher plus a close that flushes first */
+export async function connectDeltaPublisher(
+  natsUrl: string,
+  subject = DEFAULT_DELTA_SUBJECT,
+): Promise<{ publisher: DeltaPublisher; close(): Promise<void> }> {
+  const nc = await connect({ servers: natsUrl });
+  const publisher = new DeltaPublisher(nc, subject);
+  return {
+    publisher,
+    async close() {
+      try {
+        await publisher.flush();
+      } finally {
+        await nc.close();
+      }
+    },
+  };
+}
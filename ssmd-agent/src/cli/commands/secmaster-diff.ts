@@ -0,0 +1,212 @@
+/**
+ * Secmaster sync diff - what a sync would change in Postgres, computed by
+ * comparing fetched Kalshi batches against the current events/markets rows.
+ * Built by `ssmd secmaster sync --dry-run`.
+ */
+import type { Database } from "../../lib/db/client.ts";
+import { getEventsByTickers, type EventRow } from "../../lib/db/events.ts";
+import { getMarketsByTickers, type MarketRow } from "../../lib/db/markets.ts";
+import { DEFAULT_EXCHANGE } from "../../lib/db/schema.ts";
+import type { Event as ApiEvent } from "../../lib/types/event.ts";
+import type { Market as ApiMarket } from "../../lib/types/market.ts";
+
+export type DiffValue = string | number | boolean | null;
+
+export interface FieldChange {
+  from: DiffValue;
+  to: DiffValue;
+}
+
+export interface RecordUpdate {
+  ticker: string;
+  changes: Record<string, FieldChange>;
+}
+
+export interface StatusTransition {
+  ticker: string;
+  from: string;
+  to: string;
+}
+
+export interface RecordDiff {
+  /** Tickers not in the table (or soft-deleted), which the sync would insert */
+  new: string[];
+  /** Existing rows whose metadata would change, status excluded */
+  updated: RecordUpdate[];
+  /** Existing rows whose status would change */
+  statusTransitions: StatusTransition[];
+  /** Existing rows the sync would leave as they are */
+  unchanged: number;
+}
+
+export interface SyncDiff {
+  events: RecordDiff;
+  markets: RecordDiff;
+}
+
+// API field -> table column compared for each record. Prices, volume and open
+// interest move on every sync and are left out so the diff shows real changes.
+const EVENT_FIELDS: Array<[keyof ApiEvent, keyof EventRow]> = [
+  ["title", "title"],
+  ["category", "category"],
+  ["series_ticker", "seriesTicker"],
+  ["strike_date", "strikeDate"],
+  ["mutually_exclusive", "mutuallyExclusive"],
+];
+
+const MARKET_FIELDS: Array<[keyof ApiMarket, keyof MarketRow]> = [
+  ["event_ticker", "eventTicker"],
+  ["title", "title"],
+  ["close_time", "closeTime"],
+  ["open_time", "openTime"],
+  ["expected_expiration_time", "expectedExpirationTime"],
+  ["floor_strike", "floorStrike"],
+  ["cap_strike", "capStrike"],
+  ["strike_type", "strikeType"],
+  ["result", "result"],
+  ["expiration_value", "expirationValue"],
+  ["yes_sub_title", "yesSubTitle"],
+  ["no_sub_title", "noSubTitle"],
+  ["can_close_early", "canCloseEarly"],
+  ["market_type", "marketType"],
+];
+
+const ISO_TIMESTAMP = /^\d{4}-\d{2}-\d{2}T/;
+
+// Tickers already diffed, so records fetched by more than one pass count once
+const seenTickers = new WeakMap<RecordDiff, Set<string>>();
+
+export function emptySyncDiff(): SyncDiff {
+  const empty = (): RecordDiff => ({ new: [], updated: [], statusTransitions: [], unchanged: 0 });
+  return { events: empty(), markets: empty() };
+}
+
+/**
+ * Normalize an API or column value so the two sides compare equal when the
+ * upsert would store the same thing: timestamps as ISO strings, numeric
+ * columns (strings in Drizzle) as numbers, and empty strings as null.
+ */
+export function normalizeDiffValue(v: unknown): DiffValue {
+  if (v === null || v === undefined || v === "") return null;
+  if (v instanceof Date) return v.toISOString();
+  if (typeof v === "string") {
+    if (ISO_TIMESTAMP.test(v)) {
+      const t = Date.parse(v);
+      return Number.isNaN(t) ? v : new Date(t).toISOString();
+    }
+    const n = Number(v);
+    return v.trim() !== "" && Number.isFinite(n) ? n : v;
+  }
+  if (typeof v === "number" || typeof v === "boolean") return v;
+  return String(v);
+}
+
+function diffRecord<A, R>(
+  diff: RecordDiff,
+  ticker: string,
+  fetched: A,
+  existing: R | undefined,
+  fields: Array<[keyof A, keyof R]>,
+  status: [keyof A, keyof R],
+): void {
+  let seen = seenTickers.get(diff);
+  if (!seen) {
+    seen = new Set();
+    seenTickers.set(diff, seen);
+  }
+  if (seen.has(ticker)) return;
+  seen.add(ticker);
+
+  if (!existing) {
+    diff.new.push(ticker);
+    return;
+  }
+
+  let changed = false;
+  const from = normalizeDiffValue(existing[status[1]]);
+  const to = normalizeDiffValue(fetched[status[0]]);
+  if (to !== null && from !== to) {
+    diff.statusTransitions.push({ ticker, from: String(from), to: String(to) });
+    changed = true;
+  }
+
+  const changes: Record<string, FieldChange> = {};
+  for (const [apiField, column] of fields) {
+    const before = normalizeDiffValue(existing[column]);
+    const after = normalizeDiffValue(fetched[apiField]);
+    if (before !== after) {
+      changes[String(apiField)] = { from: before, to: after };
+    }
+  }
+  if (Object.keys(changes).length > 0) {
+    diff.updated.push({ ticker, changes });
+    changed = true;
+  }
+
+  if (!changed) diff.unchanged++;
+}
+
+/** Add a fetched event batch to the diff, given the matching rows by ticker */
+export function diffEvents(diff: SyncDiff, batch: ApiEvent[], existing: Map<string, EventRow>): void {
+  for (const e of batch) {
+    diffRecord(diff.events, e.event_ticker, e, existing.get(e.event_ticker), EVENT_FIELDS, ["status", "status"]);
+  }
+}
+
+/** Add a fetched market batch to the diff, given the matching rows by ticker */
+export function diffMarkets(diff: SyncDiff, batch: ApiMarket[], existing: Map<string, MarketRow>): void {
+  for (const m of batch) {
+    diffRecord(diff.markets, m.ticker, m, existing.get(m.ticker), MARKET_FIELDS, ["status", "status"]);
+  }
+}
+
+/** A batch split by exchange; tickers are only unique within one */
+function byExchange<T extends { exchange?: string }>(batch: T[]): Map<string, T[]> {
+  const groups = new Map<string, T[]>();
+  for (const r of batch) {
+    const exchange = r.exchange ?? DEFAULT_EXCHANGE;
+    if (!groups.has(exchange)) groups.set(exchange, []);
+    groups.get(exchange)!.push(r);
+  }
+  return groups;
+}
+
+/** Look up the rows for an event batch and add it to the diff (read-only) */
+export async function collectEventDiff(db: Database, diff: SyncDiff, batch: ApiEvent[]): Promise<void> {
+  for (const [exchange, group] of byExchange(batch)) {
+    const existing = await getEventsByTickers(db, group.map((e) => e.event_ticker), exchange);
+    diffEvents(diff, group, existing);
+  }
+}
+
+/** Look up the rows for a market batch and add it to the diff (read-only) */
+export async function collectMarketDiff(db: Database, diff: SyncDiff, batch: ApiMarket[]): Promise<void> {
+  for (const [exchange, group] of byExchange(batch)) {
+    const existing = await getMarketsByTickers(db, group.map((m) => m.ticker), exchange);
+    diffMarkets(diff, group, existing);
+  }
+}
+
+/**
+ * Print a short human summary of the diff; the full diff is written with
+ * --json or --output.
+ */
+export function printSyncDiff(diff: SyncDiff): void {
+  console.log("\n=== Dry-run Diff ===");
+  for (const [label, d] of [["Events", diff.events], ["Markets", diff.markets]] as const) {
+    console.log(`\n${label}:`);
+    console.log(`  New:                ${d.new.length}`);
+    console.log(`  Updated:            ${d.updated.length}`);
+    console.log(`  Status transitions: ${d.statusTransitions.length}`);
+    console.log(`  Unchanged:          ${d.unchanged}`);
+
+    const transitions = new Map<string, number>();
+    for (const t of d.statusTransitions) {
+      const key = `${t.from} -> ${t.to}`;
+      transitions.set(key, (transitions.get(key) ?? 0) + 1);
+    }
+    for (const [key, n] of transitions) {
+      console.log(`    ${key}: ${n}`);
+    }
+  }
+}
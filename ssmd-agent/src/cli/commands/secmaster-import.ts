@@ -0,0 +1,274 @@
+/**
+ * Secmaster import command - load events and markets from local JSONL/CSV files
+ * through the same batch upserts as secmaster sync
+ */
+import { z } from "zod";
+import { TextLineStream } from "https://deno.land/std@0.224.0/streams/text_line_stream.ts";
+import { CsvParseStream } from "https://deno.land/std@0.224.0/csv/csv_parse_stream.ts";
+import { getDb, closeDb, type Database } from "../../lib/db/client.ts";
+import { upsertEvents, getExistingEventTickers } from "../../lib/db/events.ts";
+import { upsertMarkets } from "../../lib/db/markets.ts";
+import { DEFAULT_EXCHANGE } from "../../lib/db/schema.ts";
+import { EventSchema, type Event } from "../../lib/types/event.ts";
+import { MarketSchema, type Market } from "../../lib/types/market.ts";
+
+const IMPORT_BATCH_SIZE = 1000;
+
+/** Per-row errors printed before the rest are only counted */
+const MAX_PRINTED_ERRORS = 50;
+
+export interface ImportOptions {
+  eventsFile?: string;
+  marketsFile?: string;
+  /** Exchange for rows without an exchange column (default: kalshi) */
+  exchange?: string;
+  /** Validate only - don't write to database */
+  dryRun?: boolean;
+}
+
+export interface ImportRowError {
+  file: string;
+  line: number;
+  message: string;
+}
+
+export interface ImportFileResult {
+  read: number;
+  imported: number;
+  errors: ImportRowError[];
+}
+
+export interface ImportResult {
+  events: ImportFileResult;
+  markets: ImportFileResult;
+  durationMs: number;
+}
+
+/** A parsed row and the file line it came from */
+interface ImportRecord {
+  line: number;
+  value: Record<string, unknown>;
+}
+
+/**
+ * Convert CSV string cells to the types the schema expects: empty cells are
+ * dropped (so defaults and optionals apply), and number/boolean fields are parsed.
+ */
+export function coerceCsvRow(
+  row: Record<string, string>,
+  schema: z.AnyZodObject,
+): Record<string, unknown> {
+  const out: Record<string, unknown> = {};
+  for (const [key, raw] of Object.entries(row)) {
+    if (raw === "") continue;
+    let field = schema.shape[key] as z.ZodTypeAny | undefined;
+    while (field instanceof z.ZodOptional || field instanceof z.ZodNullable || field instanceof z.ZodDefault) {
+      field = field instanceof z.ZodDefault ? field._def.innerType : field.unwrap();
+    }
+    if (field instanceof z.ZodNumber) {
+      out[key] = Number(raw);
+    } else if (field instanceof z.ZodBoolean) {
+      out[key] = raw === "true" ? true : raw === "false" ? false : raw;
+    } else {
+      out[key] = raw;
+    }
+  }
+  return out;
+}
+
+/**
+ * Validate a record against the schema, returning the parsed value or a
+ * one-line error message listing each failing field.
+ */
+export function validateRecord<T>(
+  schema: z.ZodType<T, z.ZodTypeDef, unknown>,
+  value: unknown,
+): { ok: true; value: T } | { ok: false; message: string } {
+  const parsed = schema.safeParse(value);
+  if (parsed.success) {
+    return { ok: true, value: parsed.data };
+  }
+  const message = parsed.error.issues
+    .map((issue) => `${issue.path.join(".") || "(row)"}: ${issue.message}`)
+    .join("; ");
+  return { ok: false, message };
+}
+
+/**
+ * Stream records from a .jsonl or .csv file (CSV needs a header row). JSONL
+ * lines that are not valid JSON are yielded as errors.
+ */
+async function* readRecords(
+  path: string,
+  schema: z.AnyZodObject,
+): AsyncGenerator<ImportRecord | ImportRowError> {
+  const file = await Deno.open(path);
+  const lines = file.readable
+    .pipeThrough(new TextDecoderStream())
+    .pipeThrough(new TextLineStream());
+
+  if (path.endsWith(".csv")) {
+    // Header is line 1, so data starts on line 2
+    let line = 1;
+    for await (const row of lines.pipeThrough(new CsvParseStream({ skipFirstRow: true }))) {
+      line++;
+      yield { line, value: coerceCsvRow(row as Record<string, string>, schema) };
+    }
+    return;
+  }
+
+  let line = 0;
+  for await (const text of lines) {
+    line++;
+    if (text.trim() === "") continue;
+    try {
+      yield { line, value: JSON.parse(text) };
+    } catch (e) {
+      yield { file: path, line, message: `invalid JSON: ${(e as Error).message}` };
+    }
+  }
+}
+
+/**
+ * Validate and upsert one file in batches. flush receives each batch of valid
+ * rows and returns the rows it rejected, with their messages.
+ */
+async function importFile<T>(
+  path: string,
+  schema: z.AnyZodObject & z.ZodType<T, z.ZodTypeDef, unknown>,
+  flush: (batch: { line: number; value: T }[]) => Promise<ImportRowError[]>,
+): Promise<ImportFileResult> {
+  const result: ImportFileResult = { read: 0, imported: 0, errors: [] };
+  let batch: { line: number; value: T }[] = [];
+
+  const flushBatch = async () => {
+    if (batch.length === 0) return;
+    const rejected = await flush(batch);
+    result.errors.push(...rejected);
+    result.imported += batch.length - rejected.length;
+    batch = [];
+  };
+
+  for await (const record of readRecords(path, schema)) {
+    result.read++;
+    if ("message" in record) {
+      result.errors.push(record);
+      continue;
+    }
+    const validated = validateRecord(schema, record.value);
+    if (!validated.ok) {
+      result.errors.push({ file: path, line: record.line, message: validated.message });
+      continue;
+    }
+    batch.push({ line: record.line, value: validated.value });
+    if (batch.length >= IMPORT_BATCH_SIZE) {
+      await flushBatch();
+    }
+  }
+  await flushBatch();
+
+  return result;
+}
+
+function emptyResult(): ImportFileResult {
+  return { read: 0, imported: 0, errors: [] };
+}
+
+/**
+ * Import events, then markets, so markets can reference events from the same run.
+ */
+export async function runSecmasterImport(options: ImportOptions): Promise<ImportResult> {
+  const startTime = Date.now();
+  const db = getDb();
+  const result: ImportResult = { events: emptyResult(), markets: emptyResult(), durationMs: 0 };
+  const exchange = options.exchange ?? DEFAULT_EXCHANGE;
+  // Events from this run count as existing parents, even in a dry run
+  const importedEvents = new Set<string>();
+
+  try {
+    if (options.eventsFile) {
+      const path = options.eventsFile;
+      console.log(`[Events] Importing ${path}${options.dryRun ? " (dry run)" : ""}...`);
+      result.events = await importFile<Event>(path, EventSchema, async (batch) => {
+        for (const r of batch) {
+          r.value.exchange ??= exchange;
+          importedEvents.add(eventKey(r.value.exchange, r.value.event_ticker));
+        }
+        if (!options.dryRun) {
+          await upsertEvents(db, batch.map((r) => r.value));
+        }
+        return [];
+      });
+      console.log(`[Events] Imported ${result.events.imported}/${result.events.read} rows`);
+    }
+
+    if (options.marketsFile) {
+      const path = options.marketsFile;
+      console.log(`[Markets] Importing ${path}${options.dryRun ? " (dry run)" : ""}...`);
+      result.markets = await importFile<Market>(path, MarketSchema, async (batch) => {
+        batch.forEach((r) => r.value.exchange ??= exchange);
+        // Reject rows whose event is missing rather than failing the whole batch
+        const missing = await missingParentEvents(
+          db,
+          batch
+            .map((r) => ({ exchange: r.value.exchange!, eventTicker: r.value.event_ticker }))
+            .filter((e) => !importedEvents.has(eventKey(e.exchange, e.eventTicker))),
+        );
+        const isMissing = (m: Market) => missing.has(eventKey(m.exchange!, m.event_ticker));
+        const rejected = batch
+          .filter((r) => isMissing(r.value))
+          .map((r) => ({ file: path, line: r.line, message: `event_ticker: event ${r.value.event_ticker} does not exist on ${r.value.exchange}` }));
+        const valid = batch.filter((r) => !isMissing(r.value));
+        if (!options.dryRun && valid.length > 0) {
+          await upsertMarkets(db, valid.map((r) => r.value));
+        }
+        return rejected;
+      });
+      console.log(`[Markets] Imported ${result.markets.imported}/${result.markets.read} rows`);
+    }
+  } finally {
+    await closeDb();
+  }
+
+  result.durationMs = Date.now() - startTime;
+  return result;
+}
+
+function eventKey(exchange: string, eventTicker: string): string {
+  return `${exchange}:${eventTicker}`;
+}
+
+/** eventKeys of the given events that are not in the database */
+async function missingParentEvents(
+  db: Database,
+  parents: { exchange: string; eventTicker: string }[],
+): Promise<Set<string>> {
+  const byExchange = new Map<string, Set<string>>();
+  for (const p of parents) {
+    if (!byExchange.has(p.exchange)) byExchange.set(p.exchange, new Set());
+    byExchange.get(p.exchange)!.add(p.eventTicker);
+  }
+  const missing = new Set<string>();
+  for (const [exchange, tickers] of byExchange) {
+    const existing = await getExistingEventTickers(db, [...tickers], exchange);
+    for (const t of tickers) {
+      if (!existing.has(t)) missing.add(eventKey(exchange, t));
+    }
+  }
+  return missing;
+}
+
+export function printImportSummary(result: ImportResult): void {
+  const errors = [...result.events.errors, ...result.markets.errors];
+  for (const e of errors.slice(0, MAX_PRINTED_ERRORS)) {
+    console.error(`  ${e.file}:${e.line}: ${e.message}`);
+  }
+  if (errors.length > MAX_PRINTED_ERRORS) {
+    console.error(`  ... and ${errors.length - MAX_PRINTED_ERRORS} more errors`);
+  }
+
+  console.log("\n=== Import Summary ===");
+  console.log(`Events:  ${result.events.imported} imported, ${result.events.errors.length} errors (${result.events.read} rows)`);
+  console.log(`Markets: ${result.markets.imported} imported, ${result.markets.errors.length} errors (${result.markets.read} rows)`);
+  console.log(`Duration: ${(result.durationMs / 1000).toFixed(1)}s`);
+}
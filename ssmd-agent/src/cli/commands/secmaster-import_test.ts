@@ -0,0 +1,38 @@
+import { assertEquals } from "jsr:@std/assert";
+import { coerceCsvRow, validateRecord } from "./secmaster-import.ts";
+import { MarketSchema } from "../../lib/types/market.ts";
+import { EventSchema } from "../../lib/types/event.ts";
+
+Deno.test("coerceCsvRow: parses numbers and booleans, drops empty cells", () => {
+  const row = coerceCsvRow({
+    ticker: "KXBTCD-26JAN0516-T97499.99",
+    event_ticker: "KXBTCD-26JAN0516",
+    title: "Bitcoin above 97,500?",
+    yes_bid: "0.42",
+    volume: "1200",
+    can_close_early: "true",
+    close_time: "",
+  }, MarketSchema);
+  assertEquals(row.yes_bid, 0.42);
+  assertEquals(row.volume, 1200);
+  assertEquals(row.can_close_early, true);
+  assertEquals("close_time" in row, false);
+
+  const validated = validateRecord(MarketSchema, row);
+  assertEquals(validated.ok, true);
+});
+
+Deno.test("validateRecord: reports each failing field", () => {
+  const validated = validateRecord(EventSchema, { event_ticker: "", category: "Crypto", status: "open" });
+  assertEquals(validated.ok, false);
+  if (!validated.ok) {
+    assertEquals(validated.message.includes("event_ticker:"), true);
+    assertEquals(validated.message.includes("title:"), true);
+    assertEquals(validated.message.includes("status:"), true);
+  }
+});
+
+Deno.test("validateRecord: applies schema defaults", () => {
+  const validated = validateRecord(EventSchema, { event_ticker: "KXBTCD-26JAN0516", title: "BTC", category: "Crypto" });
+  assertEquals(validated.ok && validated.value.status, "active");
+});
@@ -0,0 +1,83 @@
+/**
+ * Read path for ssmd secmaster stats/events/markets: the data API by default
+ * (SSMD_API_URL + SSMD_DATA_API_KEY, so laptops need no DB credentials),
+ * falling back to a direct DATABASE_URL connection when the API cannot be
+ * reached. The fallback runs the same lib/db queries as the API routes and
+ * round-trips the result through JSON, so output is identical either way.
+ */
+import { closeDb, getDb, type Database } from "../../lib/db/client.ts";
+
+const API_TIMEOUT_MS = 10000;
+
+/** api: data API only; db: DATABASE_URL only; auto: API, then DB if unreachable */
+export type SecmasterSource = "auto" | "api" | "db";
+
+export const SECMASTER_SOURCES: SecmasterSource[] = ["auto", "api", "db"];
+
+/** A read answered by a data API path or, equivalently, a DB query */
+export interface SecmasterQuery {
+  path: string;
+  /** Same result the route returns for path; null means not found */
+  db: (db: Database) => Promise<unknown>;
+}
+
+function getApiUrl(): string {
+  return Deno.env.get("SSMD_API_URL") ?? "http://localhost:8080";
+}
+
+function getApiKey(): string {
+  return Deno.env.get("SSMD_DATA_API_KEY") ?? "";
+}
+
+/** Thrown when the API answered with an error status, which is never retried against the DB */
+export class ApiError extends Error {}
+
+async function apiRequest<T>(path: string): Promise<T> {
+  const res = await fetch(`${getApiUrl()}${path}`, {
+    headers: { "X-API-Key": getApiKey() },
+    signal: AbortSignal.timeout(API_TIMEOUT_MS),
+  });
+  if (!res.ok) {
+    throw new ApiError(`API error: ${res.status} ${await res.text()}`);
+  }
+  return res.json();
+}
+
+async function dbRequest<T>(query: SecmasterQuery): Promise<T> {
+  try {
+    const result = await query.db(getDb());
+    if (result === null) throw new Error(`Not found: ${query.path}`);
+    // Dates serialize as the API sends them
+    return JSON.parse(JSON.stringify(result));
+  } finally {
+    await closeDb();
+  }
+}
+
+/**
+ * Resolve the source from --source or SSMD_SECMASTER_SOURCE (default auto).
+ * Throws on an unknown value.
+ */
+export function secmasterSource(flag: unknown): SecmasterSource {
+  const value = String(flag ?? Deno.env.get("SSMD_SECMASTER_SOURCE") ?? "auto");
+  if (!SECMASTER_SOURCES.includes(value as SecmasterSource)) {
+    throw new Error(`--source must be one of ${SECMASTER_SOURCES.join(", ")}, got '${value}'`);
+  }
+  return value as SecmasterSource;
+}
+
+/**
+ * Run a read against the configured source. With auto, a network failure or
+ * timeout reaching the API falls back to the DB when DATABASE_URL is set;
+ * API error responses (401, 404, ...) are reported as they are.
+ */
+export async function secmasterRead<T>(source: SecmasterSource, query: SecmasterQuery): Promise<T> {
+  if (source === "db") return dbRequest<T>(query);
+  try {
+    return await apiRequest<T>(query.path);
+  } catch (err) {
+    if (source === "api" || err instanceof ApiError || !Deno.env.get("DATABASE_URL")) throw err;
+    console.error(`Data API unreachable at ${getApiUrl()} (${(err as Error).message}); reading DATABASE_URL`);
+    return dbRequest<T>(query);
+  }
+}
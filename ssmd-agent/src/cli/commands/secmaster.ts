@@ -2,33 +2,22 @@
  * Secmaster sync command - sync Kalshi events and markets to PostgreSQL
  */
 import { getDb, closeDb, getRawSql } from "../../lib/db/client.ts";
-import { bulkUpsertEvents, initEventTickerTable, appendEventTickers, softDeleteMissingEvents, upsertEvents } from "../../lib/db/events.ts";
-import { bulkUpsertMarkets, initMarketTickerTable, appendMarketTickers, softDeleteMissingMarkets } from "../../lib/db/markets.ts";
+import { DEFAULT_MIGRATIONS_DIR, planMigrations, runMigrations } from "../../lib/db/migrate.ts";
+import { bulkUpsertEvents, initEventTickerTable, appendEventTickers, softDeleteMissingEvents, upsertEvents, getEvent, getEventStats, listEvents as queryEvents } from "../../lib/db/events.ts";
+import { bulkUpsertMarkets, getMarketsByTickers, initMarketTickerTable, appendMarketTickers, softDeleteMissingMarkets, getActiveMarketsByCategoryTimeseries, getMarket, getMarketStats, listMarkets as queryMarkets } from "../../lib/db/markets.ts";
 import { getAllActiveSeries, getSeriesByTags, getSeriesByCategory } from "../../lib/db/series.ts";
 import { getSettingValue, upsertSetting } from "../../lib/db/settings.ts";
 import { createKalshiClient } from "../../lib/api/kalshi.ts";
+import { type SyncDiff, emptySyncDiff, collectEventDiff, collectMarketDiff, printSyncDiff } from "./secmaster-diff.ts";
+import { DEFAULT_DELTA_SUBJECT, type DeltaPublisher, connectDeltaPublisher, marketDeltas } from "./secmaster-deltas.ts";
+import { runSecmasterImport, printImportSummary } from "./secmaster-import.ts";
+import { type SecmasterSource, secmasterRead, secmasterSource } from "./secmaster-reader.ts";
+import type { Database } from "../../lib/db/client.ts";
+import type { Market as ApiMarket } from "../../lib/types/market.ts";
+import { runSecmasterArchive, printArchiveSummary } from "./secmaster-archive.ts";
+import type { MarketPricesJson } from "../../lib/types/market.ts";
+import type { PriceJson } from "../../lib/types/price.ts";
 
-const API_TIMEOUT_MS = 10000;
-
-
-function getApiUrl(): string {
-  return Deno.env.get("SSMD_API_URL") ?? "http://localhost:8080";
-}
-
-function getApiKey(): string {
-  return Deno.env.get("SSMD_DATA_API_KEY") ?? "";
-}
-
-async function apiRequest<T>(path: string): Promise<T> {
-  const res = await fetch(`${getApiUrl()}${path}`, {
-    headers: { "X-API-Key": getApiKey() },
-    signal: AbortSignal.timeout(API_TIMEOUT_MS),
-  });
-  if (!res.ok) {
-    throw new Error(`API error: ${res.status} ${await res.text()}`);
-  }
-  return res.json();
-}
 
 /**
  * Secmaster sync options
@@ -40,7 +29,7 @@ export interface SyncOptions {
   marketsOnly?: boolean;
   /** Skip soft-deleting missing records */
   noDelete?: boolean;
-  /** Dry run - don't write to database */
+  /** Dry run - don't write to database, diff fetched records against it instead */
   dryRun?: boolean;
   /** Only sync active/open records (faster incremental sync) */
   activeOnly?: boolean;
@@ -58,6 +47,14 @@ export interface SyncOptions {
   minCloseTs?: number;
   /** Relative filter: only sync markets closing within N days ago (converted to minCloseTs at runtime) */
   minCloseDaysAgo?: number;
+  /** Publish new markets and status/close_time changes to NATS */
+  publish?: boolean;
+  /** Subject for published deltas (default secmaster.updates.markets) */
+  publishSubject?: string;
+  /** NATS server for --publish (default NATS_URL or nats://localhost:4222) */
+  natsUrl?: string;
+  /** Write progress to stderr, leaving stdout to --json output */
+  progressToStderr?: boolean;
 }
 
 /**
@@ -75,15 +72,40 @@ export interface SyncResult {
     upserted: number;
     skipped: number;
     deleted: number;
+    /** Deltas published to NATS (--publish only) */
+    published?: number;
     durationMs: number;
   };
   totalDurationMs: number;
+  /** What the sync would have changed (dry run only) */
+  diff?: SyncDiff;
+}
+
+/**
+ * Upsert a market batch, queueing deltas against the rows it replaces when
+ * publishing. Deltas are queued only after the upsert succeeds.
+ */
+async function upsertMarketBatch(db: Database, batch: ApiMarket[], publisher?: DeltaPublisher) {
+  const existing = publisher ? await getMarketsByTickers(db, batch.map((m) => m.ticker)) : undefined;
+  const upserted = await bulkUpsertMarkets(db, batch);
+  if (publisher && existing) publisher.add(marketDeltas(batch, existing));
+  return upserted;
+}
+
+/** Open the delta publisher for a sync with --publish (never for dry runs) */
+function openPublisher(options: SyncOptions) {
+  if (!options.publish || options.dryRun) return Promise.resolve(undefined);
+  const natsUrl = options.natsUrl ?? Deno.env.get("NATS_URL") ?? "nats://localhost:4222";
+  const subject = options.publishSubject ?? DEFAULT_DELTA_SUBJECT;
+  console.log(`[Publish] Market deltas to ${subject} on ${natsUrl}`);
+  return connectDeltaPublisher(natsUrl, subject);
 }
 
 /**
  * Run the secmaster sync
  */
 export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncResult> {
+  const log = options.progressToStderr ? console.error : console.log;
   const startTime = Date.now();
   const client = createKalshiClient();
   const db = getDb();
@@ -93,6 +115,9 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
     markets: { fetched: 0, upserted: 0, skipped: 0, deleted: 0, durationMs: 0 },
     totalDurationMs: 0,
   };
+  const diff = options.dryRun ? emptySyncDiff() : undefined;
+  if (diff) result.diff = diff;
+  const delta = await openPublisher(options);
 
   // Status filter for incremental sync
   const statusFilter = options.activeOnly ? "open" : undefined;
@@ -101,7 +126,7 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
   try {
     // Sync events - upsert each batch as it arrives, stream tickers to temp table
     if (!options.marketsOnly) {
-      console.log(`\n[Events] Starting ${syncMode} sync...`);
+      log(`\n[Events] Starting ${syncMode} sync...`);
       const eventStart = Date.now();
 
       // Reserve a connection for temp table operations (temp tables are session-scoped)
@@ -118,7 +143,9 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
       for await (const batch of client.fetchAllEvents(statusFilter)) {
         result.events.fetched += batch.length;
 
-        if (!options.dryRun) {
+        if (diff) {
+          await collectEventDiff(db, diff, batch);
+        } else {
           await bulkUpsertEvents(db, batch);
           batchCount++;
         }
@@ -130,13 +157,13 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
       }
 
       result.events.upserted = result.events.fetched;
-      console.log(`[Events] Synced ${result.events.fetched} events in ${batchCount} batches`);
+      log(`[Events] Synced ${result.events.fetched} events in ${batchCount} batches`);
 
       if (conn) {
         const deleted = await softDeleteMissingEvents(conn);
         result.events.deleted = deleted;
         if (deleted > 0) {
-          console.log(`[Events] Soft-deleted ${deleted} missing events`);
+          log(`[Events] Soft-deleted ${deleted} missing events`);
         }
       }
       } finally {
@@ -148,7 +175,7 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
 
     // Sync markets - upsert each batch as it arrives, stream tickers to temp table
     if (!options.eventsOnly) {
-      console.log(`\n[Markets] Starting ${syncMode} sync...`);
+      log(`\n[Markets] Starting ${syncMode} sync...`);
       const marketStart = Date.now();
 
       // Reserve a connection for temp table operations (temp tables are session-scoped)
@@ -165,8 +192,10 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
       // Helper: process a market batch (upsert + stream tickers to temp table)
       const processBatch = async (batch: import("../../lib/types/market.ts").Market[]) => {
         result.markets.fetched += batch.length;
-        if (!options.dryRun) {
-          const batchResult = await bulkUpsertMarkets(db, batch);
+        if (diff) {
+          await collectMarketDiff(db, diff, batch);
+        } else {
+          const batchResult = await upsertMarketBatch(db, batch, delta?.publisher);
           result.markets.upserted += batchResult.total;
           result.markets.skipped += batchResult.skipped;
           batchCount++;
@@ -190,7 +219,7 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
         const closedAnchor = await getSettingValue<number>(db, CLOSED_ANCHOR_KEY, sevenDaysAgo);
 
         // Pass 1: Markets closing in next 48 hours (any status)
-        console.log(`  Fetching markets closing in next 48 hours (any status)...`);
+        log(`  Fetching markets closing in next 48 hours (any status)...`);
         for await (const batch of client.fetchAllMarkets({
           minCloseTs: now,
           maxCloseTs: fortyEightHoursFromNow,
@@ -200,7 +229,7 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
         }
 
         // Pass 2: Recently created open markets
-        console.log(`  Fetching open markets created in last 7 days...`);
+        log(`  Fetching open markets created in last 7 days...`);
         for await (const batch of client.fetchAllMarkets({
           status: "open",
           minCreatedTs: sevenDaysAgo,
@@ -210,7 +239,7 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
         }
 
         // Pass 3: Settled markets since last sync (anchor-based)
-        console.log(`  Fetching settled markets since ${new Date(settledAnchor * 1000).toISOString()}...`);
+        log(`  Fetching settled markets since ${new Date(settledAnchor * 1000).toISOString()}...`);
         for await (const batch of client.fetchAllMarkets({
           status: "settled",
           minSettledTs: settledAnchor,
@@ -220,7 +249,7 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
         }
 
         // Pass 4: Closed markets since last sync (anchor-based)
-        console.log(`  Fetching closed markets since ${new Date(closedAnchor * 1000).toISOString()}...`);
+        log(`  Fetching closed markets since ${new Date(closedAnchor * 1000).toISOString()}...`);
         for await (const batch of client.fetchAllMarkets({
           status: "closed",
           minCloseTs: closedAnchor,
@@ -242,7 +271,7 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
         }
       }
 
-      console.log(
+      log(
         `[Markets] Synced ${result.markets.upserted} markets in ${batchCount} batches` +
           (result.markets.skipped > 0 ? ` (${result.markets.skipped} skipped)` : "")
       );
@@ -251,7 +280,7 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
         const deleted = await softDeleteMissingMarkets(mktConn);
         result.markets.deleted = deleted;
         if (deleted > 0) {
-          console.log(`[Markets] Soft-deleted ${deleted} missing markets`);
+          log(`[Markets] Soft-deleted ${deleted} missing markets`);
         }
       }
       } finally {
@@ -261,9 +290,15 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
       result.markets.durationMs = Date.now() - marketStart;
     }
 
+    if (delta) {
+      await delta.publisher.flush();
+      result.markets.published = delta.publisher.published;
+    }
+
     result.totalDurationMs = Date.now() - startTime;
     return result;
   } finally {
+    await delta?.close();
     await closeDb();
   }
 }
@@ -278,6 +313,7 @@ export async function runSecmasterSync(options: SyncOptions = {}): Promise<SyncR
  * - Progress markers: PROGRESS:series:N/total:ticker for Temporal heartbeats
  */
 export async function runSeriesBasedSync(options: SyncOptions = {}): Promise<SyncResult> {
+  const log = options.progressToStderr ? console.error : console.log;
   const startTime = Date.now();
   const client = createKalshiClient();
   const db = getDb();
@@ -287,6 +323,9 @@ export async function runSeriesBasedSync(options: SyncOptions = {}): Promise<Syn
     markets: { fetched: 0, upserted: 0, skipped: 0, deleted: 0, durationMs: 0 },
     totalDurationMs: 0,
   };
+  const diff = options.dryRun ? emptySyncDiff() : undefined;
+  if (diff) result.diff = diff;
+  const delta = await openPublisher(options);
 
   // Track errors for fail-fast behavior
   let consecutiveErrors = 0;
@@ -297,7 +336,7 @@ export async function runSeriesBasedSync(options: SyncOptions = {}): Promise<Syn
   if (options.minCloseDaysAgo && !options.minCloseTs) {
     const now = Math.floor(Date.now() / 1000);
     options.minCloseTs = now - options.minCloseDaysAgo * 24 * 60 * 60;
-    console.log(`[Filter] minCloseDaysAgo=${options.minCloseDaysAgo} → minCloseTs=${options.minCloseTs} (${new Date(options.minCloseTs * 1000).toISOString()})`);
+    log(`[Filter] minCloseDaysAgo=${options.minCloseDaysAgo} → minCloseTs=${options.minCloseTs} (${new Date(options.minCloseTs * 1000).toISOString()})`);
   }
 
   try {
@@ -307,13 +346,13 @@ export async function runSeriesBasedSync(options: SyncOptions = {}): Promise<Syn
 
     if (options.category) {
       const isGamesOnly = options.category === "Sports";
-      console.log(`\n[Series] Fetching series for category: ${options.category}${isGamesOnly ? " (games only)" : ""}${volFilter}`);
+      log(`\n[Series] Fetching series for category: ${options.category}${isGamesOnly ? " (games only)" : ""}${volFilter}`);
       seriesList = await getSeriesByCategory(options.category, isGamesOnly, options.minVolume);
     } else if (options.tags && options.tags.length > 0) {
-      console.log(`\n[Series] Fetching series for tags: ${options.tags.join(", ")}${volFilter}`);
+      log(`\n[Series] Fetching series for tags: ${options.tags.join(", ")}${volFilter}`);
       seriesList = await getSeriesByTags(options.tags, false);
     } else {
-      console.log(`\n[Series] Fetching all active series from database...${volFilter}`);
+      log(`\n[Series] Fetching all active series from database...${volFilter}`);
       seriesList = await getAllActiveSeries(options.minVolume);
     }
 
@@ -321,14 +360,14 @@ export async function runSeriesBasedSync(options: SyncOptions = {}): Promise<Syn
       const before = seriesList.length;
       const suffix = options.seriesSuffix;
       seriesList = seriesList.filter((s) => s.ticker.endsWith(suffix));
-      console.log(`[Series] Filtered to ${seriesList.length}/${before} series ending in "${suffix}"`);
+      log(`[Series] Filtered to ${seriesList.length}/${before} series ending in "${suffix}"`);
     }
 
-    console.log(`[Series] Found ${seriesList.length} series to sync`);
+    log(`[Series] Found ${seriesList.length} series to sync`);
 
     if (seriesList.length === 0) {
-      console.log("[Series] No series found. Run 'ssmd series sync' first.");
-      console.log(`PROGRESS:complete:events=0,markets=0,errors=0`);
+      log("[Series] No series found. Run 'ssmd series sync' first.");
+      log(`PROGRESS:complete:events=0,markets=0,errors=0`);
       result.totalDurationMs = Date.now() - startTime;
       return result;
     }
@@ -342,7 +381,7 @@ export async function runSeriesBasedSync(options: SyncOptions = {}): Promise<Syn
       const s = seriesList[i];
 
       // Emit progress marker for Temporal heartbeat
-      console.log(`PROGRESS:series:${i + 1}/${total}:${s.ticker}`);
+      log(`PROGRESS:series:${i + 1}/${total}:${s.ticker}`);
 
       try {
         for (const status of statuses) {
@@ -355,14 +394,17 @@ export async function runSeriesBasedSync(options: SyncOptions = {}): Promise<Syn
             result.events.fetched += batch.events.length;
             result.markets.fetched += batch.markets.length;
 
-            if (!options.dryRun) {
+            if (diff) {
+              await collectEventDiff(db, diff, batch.events);
+              await collectMarketDiff(db, diff, batch.markets);
+            } else {
               // Upsert events first (FK constraint)
               if (batch.events.length > 0) {
                 result.events.upserted += await upsertEvents(db, batch.events);
               }
               // Then upsert markets
               if (batch.markets.length > 0) {
-                const marketResult = await bulkUpsertMarkets(db, batch.markets);
+                const marketResult = await upsertMarketBatch(db, batch.markets, delta?.publisher);
                 result.markets.upserted += marketResult.total;
               }
             }
@@ -376,12 +418,15 @@ export async function runSeriesBasedSync(options: SyncOptions = {}): Promise<Syn
         for await (const batch of client.fetchEventsBySeries(s.ticker)) {
           result.events.fetched += batch.events.length;
           result.markets.fetched += batch.markets.length;
-          if (!options.dryRun) {
+          if (diff) {
+            await collectEventDiff(db, diff, batch.events);
+            await collectMarketDiff(db, diff, batch.markets);
+          } else {
             if (batch.events.length > 0) {
               result.events.upserted += await upsertEvents(db, batch.events);
             }
             if (batch.markets.length > 0) {
-              const marketResult = await bulkUpsertMarkets(db, batch.markets);
+              const marketResult = await upsertMarketBatch(db, batch.markets, delta?.publisher);
               result.markets.upserted += marketResult.total;
             }
           }
@@ -393,11 +438,11 @@ export async function runSeriesBasedSync(options: SyncOptions = {}): Promise<Syn
         consecutiveErrors++;
         const errorMsg = String(err).slice(0, 200);
         errors.push({ ticker: s.ticker, error: errorMsg });
-        console.log(`PROGRESS:error:${s.ticker}:${errorMsg}`);
+        log(`PROGRESS:error:${s.ticker}:${errorMsg}`);
 
         // Fail-fast: abort after N consecutive failures
         if (consecutiveErrors >= FAIL_FAST_THRESHOLD) {
-          console.log(`PROGRESS:fatal:${FAIL_FAST_THRESHOLD} consecutive failures - aborting`);
+          log(`PROGRESS:fatal:${FAIL_FAST_THRESHOLD} consecutive failures - aborting`);
           throw new Error(
             `Aborting: ${FAIL_FAST_THRESHOLD} consecutive failures. Last error on ${s.ticker}: ${errorMsg}`
           );
@@ -405,31 +450,50 @@ export async function runSeriesBasedSync(options: SyncOptions = {}): Promise<Syn
       }
     }
 
+    if (delta) {
+      await delta.publisher.flush();
+      result.markets.published = delta.publisher.published;
+    }
+
     result.totalDurationMs = Date.now() - startTime;
 
     // Emit completion marker
-    console.log(`PROGRESS:complete:events=${result.events.upserted},markets=${result.markets.upserted},errors=${errors.length}`);
+    log(`PROGRESS:complete:events=${result.events.upserted},markets=${result.markets.upserted},errors=${errors.length}`);
 
     if (errors.length > 0) {
-      console.log(`\n[Warning] ${errors.length} series failed (non-consecutive):`);
+      log(`\n[Warning] ${errors.length} series failed (non-consecutive):`);
       for (const e of errors.slice(0, 10)) {
-        console.log(`  - ${e.ticker}: ${e.error.slice(0, 80)}`);
+        log(`  - ${e.ticker}: ${e.error.slice(0, 80)}`);
       }
       if (errors.length > 10) {
-        console.log(`  ... and ${errors.length - 10} more`);
+        log(`  ... and ${errors.length - 10} more`);
       }
     }
 
-    console.log(
+    log(
       `\n[Done] Synced ${result.events.upserted} events, ${result.markets.upserted} markets`
     );
 
     return result;
   } finally {
+    await delta?.close();
     await closeDb();
   }
 }
 
+/**
+ * Write a dry-run diff as JSON to --output, or to stdout with --json
+ */
+async function writeSyncDiff(diff: SyncDiff, flags: Record<string, unknown>): Promise<void> {
+  const json = JSON.stringify(diff, null, 2);
+  if (flags.output) {
+    await Deno.writeTextFile(String(flags.output), json + "\n");
+    console.log(`\nDiff written to ${flags.output}`);
+  } else if (flags.json) {
+    console.log(json);
+  }
+}
+
 /**
  * Print sync summary
  */
@@ -450,6 +514,9 @@ export function printSyncSummary(result: SyncResult): void {
     console.log(`  Upserted: ${result.markets.upserted}`);
     console.log(`  Skipped:  ${result.markets.skipped}`);
     console.log(`  Deleted:  ${result.markets.deleted}`);
+    if (result.markets.published !== undefined) {
+      console.log(`  Published: ${result.markets.published} deltas`);
+    }
     console.log(`  Duration: ${(result.markets.durationMs / 1000).toFixed(2)}s`);
   }
 
@@ -485,8 +552,11 @@ interface ActiveByCategoryResponse {
 /**
  * Show secmaster statistics
  */
-async function showStats(days?: number): Promise<void> {
-  const stats = await apiRequest<SecmasterStats>("/v1/secmaster/stats");
+async function showStats(source: SecmasterSource, days?: number): Promise<void> {
+  const stats = await secmasterRead<SecmasterStats>(source, {
+    path: "/v1/secmaster/stats",
+    db: async (db) => ({ events: await getEventStats(db), markets: await getMarketStats(db) }),
+  });
 
   console.log("\n=== Secmaster Statistics ===\n");
 
@@ -516,17 +586,18 @@ async function showStats(days?: number): Promise<void> {
 
   // Show active markets by category over time
   if (days && days > 0) {
-    await showActiveByCategory(days);
+    await showActiveByCategory(source, days);
   }
 }
 
 /**
  * Show active markets by category over time as a table
  */
-async function showActiveByCategory(days: number): Promise<void> {
-  const data = await apiRequest<ActiveByCategoryResponse>(
-    `/v1/secmaster/markets/active-by-category?days=${days}`
-  );
+async function showActiveByCategory(source: SecmasterSource, days: number): Promise<void> {
+  const data = await secmasterRead<ActiveByCategoryResponse>(source, {
+    path: `/v1/secmaster/markets/active-by-category?days=${days}`,
+    db: async (db) => ({ timeseries: await getActiveMarketsByCategoryTimeseries(db, days) }),
+  });
 
   if (data.timeseries.length === 0) {
     console.log("\nNo active market history data available.");
@@ -594,6 +665,7 @@ async function showActiveByCategory(days: number): Promise<void> {
  * Event row from API (camelCase to match API response)
  */
 interface EventRow {
+  exchange: string;
   eventTicker: string;
   title: string;
   category: string;
@@ -607,28 +679,67 @@ interface EventRow {
  * Market row from API (camelCase to match API response)
  */
 interface MarketRow {
+  exchange: string;
   ticker: string;
   eventTicker: string;
   title: string;
   status: string;
   closeTime: string | null;
-  lastPrice: number;
+  /** Raw NUMERIC column, dollars as a string */
+  lastPrice: string | null;
   volume24h: number;
   updatedAt: string;
+  /** Typed prices (major_e4 units + currency + decimal) */
+  prices: MarketPricesJson;
+}
+
+/** Dollar display of a typed price, "-" when absent */
+function formatPrice(price: PriceJson | null): string {
+  return price ? `$${Number(price.decimal).toFixed(2)}` : "-";
 }
 
 /**
- * List events
+ * API path for listing events or markets: /v1/secmaster/<exchange>/<kind> with
+ * --exchange, otherwise /v1/<kind> across exchanges.
  */
-async function listEvents(flags: Record<string, unknown>): Promise<void> {
+export function secmasterListPath(kind: "events" | "markets", flags: Record<string, unknown>): string {
   const params = new URLSearchParams();
   if (flags.category) params.set("category", String(flags.category));
   if (flags.status) params.set("status", String(flags.status));
   if (flags.series) params.set("series", String(flags.series));
+  if (kind === "markets" && flags.event) params.set("event", String(flags.event));
   if (flags.limit) params.set("limit", String(flags.limit));
 
-  const url = `/v1/events${params.toString() ? "?" + params : ""}`;
-  const { events } = await apiRequest<{ events: EventRow[] }>(url);
+  const base = flags.exchange
+    ? `/v1/secmaster/${encodeURIComponent(String(flags.exchange))}/${kind}`
+    : `/v1/${kind}`;
+  return `${base}${params.toString() ? "?" + params : ""}`;
+}
+
+/** Database filters matching secmasterListPath, for the DATABASE_URL fallback */
+function secmasterListFilters(flags: Record<string, unknown>) {
+  return {
+    exchange: flags.exchange ? String(flags.exchange) : undefined,
+    category: flags.category ? String(flags.category) : undefined,
+    status: flags.status ? String(flags.status) : undefined,
+    series: flags.series ? String(flags.series) : undefined,
+    limit: flags.limit ? parseInt(String(flags.limit)) : undefined,
+  };
+}
+
+/** Query string selecting the exchange for a single event or market */
+function exchangeQuery(flags: Record<string, unknown>): string {
+  return flags.exchange ? `?exchange=${encodeURIComponent(String(flags.exchange))}` : "";
+}
+
+/**
+ * List events
+ */
+async function listEvents(source: SecmasterSource, flags: Record<string, unknown>): Promise<void> {
+  const { events } = await secmasterRead<{ events: EventRow[] }>(source, {
+    path: secmasterListPath("events", flags),
+    db: async (db) => ({ events: await queryEvents(db, secmasterListFilters(flags)) }),
+  });
 
   console.log(`\nFound ${events.length} events\n`);
 
@@ -666,16 +777,16 @@ async function listEvents(flags: Record<string, unknown>): Promise<void> {
 /**
  * List markets
  */
-async function listMarkets(flags: Record<string, unknown>): Promise<void> {
-  const params = new URLSearchParams();
-  if (flags.category) params.set("category", String(flags.category));
-  if (flags.status) params.set("status", String(flags.status));
-  if (flags.series) params.set("series", String(flags.series));
-  if (flags.event) params.set("event", String(flags.event));
-  if (flags.limit) params.set("limit", String(flags.limit));
-
-  const url = `/v1/markets${params.toString() ? "?" + params : ""}`;
-  const { markets } = await apiRequest<{ markets: MarketRow[] }>(url);
+async function listMarkets(source: SecmasterSource, flags: Record<string, unknown>): Promise<void> {
+  const { markets } = await secmasterRead<{ markets: MarketRow[] }>(source, {
+    path: secmasterListPath("markets", flags),
+    db: async (db) => ({
+      markets: await queryMarkets(db, {
+        ...secmasterListFilters(flags),
+        eventTicker: flags.event ? String(flags.event) : undefined,
+      }),
+    }),
+  });
 
   console.log(`\nFound ${markets.length} markets\n`);
 
@@ -698,7 +809,7 @@ async function listMarkets(flags: Record<string, unknown>): Promise<void> {
   // Rows
   for (const m of markets) {
     const title = m.title.length > titleWidth ? m.title.slice(0, titleWidth - 3) + "..." : m.title;
-    const lastPrice = m.lastPrice !== null ? `$${Number(m.lastPrice).toFixed(2)}` : "-";
+    const lastPrice = formatPrice(m.prices.last_price);
     const vol = m.volume24h !== null ? m.volume24h.toLocaleString() : "-";
     console.log(
       m.ticker.padEnd(tickerWidth) + "  " +
@@ -713,10 +824,14 @@ async function listMarkets(flags: Record<string, unknown>): Promise<void> {
 /**
  * Show a single event
  */
-async function showEvent(ticker: string): Promise<void> {
-  const event = await apiRequest<EventRow & { marketCount: number }>(`/v1/events/${encodeURIComponent(ticker)}`);
+async function showEvent(source: SecmasterSource, ticker: string, flags: Record<string, unknown>): Promise<void> {
+  const event = await secmasterRead<EventRow & { marketCount: number }>(source, {
+    path: `/v1/events/${encodeURIComponent(ticker)}${exchangeQuery(flags)}`,
+    db: (db) => getEvent(db, ticker, flags.exchange ? String(flags.exchange) : undefined),
+  });
 
   console.log("\n=== Event Details ===\n");
+  console.log(`Exchange: ${event.exchange}`);
   console.log(`Ticker:   ${event.eventTicker}`);
   console.log(`Title:    ${event.title}`);
   console.log(`Category: ${event.category}`);
@@ -729,15 +844,19 @@ async function showEvent(ticker: string): Promise<void> {
 /**
  * Show a single market
  */
-async function showMarket(ticker: string): Promise<void> {
-  const m = await apiRequest<MarketRow>(`/v1/markets/${encodeURIComponent(ticker)}`);
+async function showMarket(source: SecmasterSource, ticker: string, flags: Record<string, unknown>): Promise<void> {
+  const m = await secmasterRead<MarketRow>(source, {
+    path: `/v1/markets/${encodeURIComponent(ticker)}${exchangeQuery(flags)}`,
+    db: (db) => getMarket(db, ticker, flags.exchange ? String(flags.exchange) : undefined),
+  });
 
   console.log("\n=== Market Details ===\n");
+  console.log(`Exchange:   ${m.exchange}`);
   console.log(`Ticker:     ${m.ticker}`);
   console.log(`Title:      ${m.title}`);
   console.log(`Event:      ${m.eventTicker}`);
   console.log(`Status:     ${m.status}`);
-  console.log(`Last Price: $${Number(m.lastPrice).toFixed(2)}`);
+  console.log(`Last Price: ${formatPrice(m.prices.last_price)}`);
   console.log(`Volume 24h: ${m.volume24h?.toLocaleString() ?? "-"}`);
   if (m.closeTime) console.log(`Closes:     ${m.closeTime}`);
   console.log(`Updated:    ${m.updatedAt}`);
@@ -761,6 +880,8 @@ export async function handleSecmaster(
         tags.push(...tagFlags.map(String));
       }
 
+      // A dry-run diff printed as JSON is the only thing on stdout
+      const jsonOutput = Boolean(flags["dry-run"]) && Boolean(flags.json) && !flags.output;
       const options: SyncOptions = {
         eventsOnly: Boolean(flags["events-only"]),
         marketsOnly: Boolean(flags["markets-only"]),
@@ -773,21 +894,28 @@ export async function handleSecmaster(
         tags: tags.length > 0 ? tags : undefined,
         minVolume: flags["min-volume"] ? Number(flags["min-volume"]) : undefined,
         minCloseDaysAgo: flags["min-close-days-ago"] ? Number(flags["min-close-days-ago"]) : undefined,
+        publish: Boolean(flags.publish),
+        publishSubject: flags.subject ? String(flags.subject) : undefined,
+        natsUrl: flags["nats-url"] ? String(flags["nats-url"]) : undefined,
+        progressToStderr: jsonOutput,
       };
 
       if (options.eventsOnly && options.marketsOnly) {
         console.error("Cannot specify both --events-only and --markets-only");
         Deno.exit(1);
       }
+      if (options.publish && options.dryRun) {
+        console.error("Cannot specify both --publish and --dry-run");
+        Deno.exit(1);
+      }
 
       try {
         // Use series-based sync if --by-series flag is set
-        if (options.bySeries) {
-          const result = await runSeriesBasedSync(options);
-          printSyncSummary(result);
-        } else {
-          const result = await runSecmasterSync(options);
-          printSyncSummary(result);
+        const result = options.bySeries ? await runSeriesBasedSync(options) : await runSecmasterSync(options);
+        if (!jsonOutput) printSyncSummary(result);
+        if (result.diff) {
+          if (!jsonOutput) printSyncDiff(result.diff);
+          await writeSyncDiff(result.diff, flags);
         }
       } catch (e) {
         console.error(`Sync failed: ${(e as Error).message}`);
@@ -796,10 +924,73 @@ export async function handleSecmaster(
       break;
     }
 
+    case "migrate": {
+      const dir = flags.dir ? String(flags.dir) : DEFAULT_MIGRATIONS_DIR;
+      try {
+        if (flags["dry-run"]) {
+          const plan = await planMigrations(getRawSql(), dir);
+          console.log(`${plan.applied.length} applied, ${plan.pending.length} pending`);
+          for (const m of plan.pending) {
+            console.log(`  pending ${m.version}_${m.name}`);
+          }
+        } else {
+          const ran = await runMigrations(getRawSql(), dir);
+          console.log(ran.length > 0 ? `Applied ${ran.length} migration(s)` : "Schema is up to date");
+        }
+      } catch (e) {
+        console.error(`Migration failed: ${(e as Error).message}`);
+        Deno.exit(1);
+      } finally {
+        await closeDb();
+      }
+      break;
+    }
+
+    case "import": {
+      const eventsFile = flags.events ? String(flags.events) : undefined;
+      const marketsFile = flags.markets ? String(flags.markets) : undefined;
+      if (!eventsFile && !marketsFile) {
+        console.error("Usage: ssmd secmaster import [--events FILE] [--markets FILE] [--exchange NAME] [--dry-run]");
+        Deno.exit(1);
+      }
+
+      try {
+        const result = await runSecmasterImport({
+          eventsFile,
+          marketsFile,
+          exchange: flags.exchange ? String(flags.exchange) : undefined,
+          dryRun: Boolean(flags["dry-run"]),
+        });
+        printImportSummary(result);
+        if (result.events.errors.length > 0 || result.markets.errors.length > 0) {
+          Deno.exit(1);
+        }
+      } catch (e) {
+        console.error(`Import failed: ${(e as Error).message}`);
+        Deno.exit(1);
+      }
+      break;
+    }
+
+    case "archive": {
+      try {
+        const result = await runSecmasterArchive({
+          date: flags.date ? String(flags.date) : undefined,
+          bucket: flags.bucket ? String(flags.bucket) : undefined,
+          dryRun: Boolean(flags["dry-run"]),
+        });
+        printArchiveSummary(result);
+      } catch (e) {
+        console.error(`Archive failed: ${(e as Error).message}`);
+        Deno.exit(1);
+      }
+      break;
+    }
+
     case "stats": {
       const days = flags.days ? Number(flags.days) : undefined;
       try {
-        await showStats(days);
+        await showStats(secmasterSource(flags.source), days);
       } catch (e) {
         console.error(`Failed to get stats: ${(e as Error).message}`);
         Deno.exit(1);
@@ -811,10 +1002,11 @@ export async function handleSecmaster(
       const args = flags._ as string[];
       const ticker = args[2]; // flags._[0]=secmaster, _[1]=events, _[2]=ticker
       try {
+        const source = secmasterSource(flags.source);
         if (ticker) {
-          await showEvent(ticker);
+          await showEvent(source, ticker, flags);
         } else {
-          await listEvents(flags);
+          await listEvents(source, flags);
         }
       } catch (e) {
         console.error(`Failed: ${(e as Error).message}`);
@@ -827,10 +1019,11 @@ export async function handleSecmaster(
       const args = flags._ as string[];
       const ticker = args[2]; // flags._[0]=secmaster, _[1]=markets, _[2]=ticker
       try {
+        const source = secmasterSource(flags.source);
         if (ticker) {
-          await showMarket(ticker);
+          await showMarket(source, ticker, flags);
         } else {
-          await listMarkets(flags);
+          await listMarkets(source, flags);
         }
       } catch (e) {
         console.error(`Failed: ${(e as Error).message}`);
@@ -844,6 +1037,9 @@ export async function handleSecmaster(
       console.log();
       console.log("Commands:");
       console.log("  sync         Sync events and markets from Kalshi API");
+      console.log("  migrate      Apply pending migrations (--dry-run lists them, --dir=PATH)");
+      console.log("  import       Import events and markets from JSONL or CSV files");
+      console.log("  archive      Snapshot events and markets as parquet to the archive bucket");
       console.log("  stats        Show event and market statistics");
       console.log("  events       List events (or show one: events <ticker>)");
       console.log("  markets      List markets (or show one: markets <ticker>)");
@@ -859,7 +1055,24 @@ export async function handleSecmaster(
       console.log("  --events-only    Only sync events");
       console.log("  --markets-only   Only sync markets");
       console.log("  --no-delete      Skip soft-deleting missing records");
-      console.log("  --dry-run        Fetch but don't write to database");
+      console.log("  --dry-run        Fetch but don't write; report new, updated and status-changed records");
+      console.log("  --json           With --dry-run, print only the full diff as JSON (progress goes to stderr)");
+      console.log("  --output=FILE    With --dry-run, write the full diff as JSON to FILE");
+      console.log("  --publish        Publish new markets and status/close_time changes to NATS");
+      console.log("  --subject=S      Subject for --publish (default secmaster.updates.markets)");
+      console.log("  --nats-url=URL   NATS server for --publish (default NATS_URL)");
+      console.log("  KALSHI_RATE_BUDGET=N/S and RATE_BUDGET_REDIS_URL set a request budget shared with backfill");
+      console.log();
+      console.log("Options for import:");
+      console.log("  --events=FILE    Events file (.jsonl, or .csv with a header row)");
+      console.log("  --markets=FILE   Markets file, imported after events");
+      console.log("  --exchange=X     Exchange for rows without an exchange column (default: kalshi)");
+      console.log("  --dry-run        Validate rows without writing to database");
+      console.log();
+      console.log("Options for archive:");
+      console.log("  --date=YYYY-MM-DD  Snapshot date label (default: today, UTC)");
+      console.log("  --bucket=NAME    Archive bucket (default: $GCS_BUCKET); writes secmaster/<date>/");
+      console.log("  --dry-run        Build the parquet files but don't upload");
       console.log();
       console.log("Options for stats:");
       console.log("  --days=N         Show active markets by category over N days");
@@ -870,11 +1083,16 @@ export async function handleSecmaster(
       console.log("  ssmd secmaster stats --days=7                        # Show 7-day history");
       console.log();
       console.log("Options for events/markets:");
+      console.log("  --exchange       Filter by exchange, e.g. kalshi (default: all)");
       console.log("  --category       Filter by category");
       console.log("  --status         Filter by status");
       console.log("  --series         Filter by series ticker");
       console.log("  --event          Filter markets by event ticker");
       console.log("  --limit          Limit results (default: 100)");
+      console.log();
+      console.log("stats, events and markets read the data API (SSMD_API_URL, SSMD_DATA_API_KEY)");
+      console.log("and fall back to DATABASE_URL when it cannot be reached. --source=api|db|auto");
+      console.log("(or SSMD_SECMASTER_SOURCE) picks one; the output is the same either way.");
       Deno.exit(1);
   }
 }
@@ -92,12 +92,12 @@ async function listSignals(opts: KubectlOptions): Promise<void> {
     for (const line of signals.split("\n").filter(Boolean)) {
       const [name, signalsJson, stream, phase, createdAt] = line.split("|");
 
-      // Parse signals array - kubectl returns it as JSON array format like ["sig1","sig2"]
+      // Parse signals array - kubectl returns it as JSON array format like [{"name":"sig1"}]
       let signalsStr = "-";
       if (signalsJson) {
         try {
           const signalsArray = JSON.parse(signalsJson);
-          signalsStr = Array.isArray(signalsArray) ? signalsArray.join(",") : signalsJson;
+          signalsStr = Array.isArray(signalsArray) ? signalNames(signalsArray).join(",") : signalsJson;
         } catch {
           signalsStr = signalsJson.replace(/[\[\]"]/g, "");
         }
@@ -142,7 +142,7 @@ async function statusSignal(flags: SignalDeployFlags, opts: KubectlOptions): Pro
 
     // Spec
     console.log("Spec:");
-    console.log(`  Signals: ${signal.spec.signals?.join(", ") || "-"}`);
+    console.log(`  Signals: ${signalNames(signal.spec.signals ?? []).join(", ") || "-"}`);
     console.log(`  Image: ${signal.spec.image || "-"}`);
     console.log(`  Output Prefix: ${signal.spec.outputPrefix || "signals"}`);
     console.log();
@@ -279,6 +279,12 @@ export function formatAge(timestamp: string): string {
   }
 }
 
+// signalNames returns the IDs from spec.signals, which holds objects ({name, params, ...})
+// on current operators and plain strings on older ones
+export function signalNames(signals: Array<string | { name: string }>): string[] {
+  return signals.map((s) => typeof s === "string" ? s : s.name);
+}
+
 export function printSignalDeployHelp(): void {
   console.log("Usage: ssmd [--env <env>] signal <deploy-command> [options]");
   console.log();
@@ -0,0 +1,124 @@
+/**
+ * ssmd snap - Read ssmd-snap's latest-state cache via the data API
+ * (GET /v1/data/snap/:ticker), so no NATS subscription is needed.
+ *
+ * Subcommands:
+ *   get <ticker>   Current bid/ask/last/volume and the raw cached message
+ */
+
+import { quoteUpdate, WATCH_FEEDS } from "./markets-watch.ts";
+
+interface SnapFlags {
+  _: (string | number)[];
+  feed?: string;
+  output?: string;
+  json?: boolean;
+}
+
+/** Response of GET /v1/data/snap/:ticker */
+export interface SnapResponse {
+  feed: string;
+  ticker: string;
+  snap_at: string | null;
+  age_ms: number | null;
+  snapshot: Record<string, unknown>;
+}
+
+function getApiConfig(): { apiUrl: string; apiKey: string } {
+  const apiUrl = Deno.env.get("SSMD_API_URL") || "http://localhost:8080";
+  const apiKey = Deno.env.get("SSMD_DATA_API_KEY") || Deno.env.get("SSMD_API_KEY") || "";
+  if (!apiKey) {
+    console.error("Error: SSMD_DATA_API_KEY or SSMD_API_KEY environment variable required");
+    Deno.exit(1);
+  }
+  return { apiUrl, apiKey };
+}
+
+/** Human-readable age like 850ms, 12.3s or 4m05s */
+export function formatAge(ms: number): string {
+  if (ms < 1000) return `${ms}ms`;
+  if (ms < 60_000) return `${(ms / 1000).toFixed(1)}s`;
+  const s = Math.floor(ms / 1000);
+  return `${Math.floor(s / 60)}m${String(s % 60).padStart(2, "0")}s`;
+}
+
+/**
+ * Summary lines for a snapshot. Quote fields are read the same way as
+ * `markets watch`; snap merges trades into the ticker message, so it is
+ * read as a ticker.
+ */
+export function formatSnap(res: SnapResponse): string[] {
+  const lines = [`${res.ticker} (${res.feed})`];
+  if (res.snap_at) {
+    lines.push(`  snap_at   ${res.snap_at}${res.age_ms !== null ? ` (${formatAge(res.age_ms)} ago)` : ""}`);
+  }
+  const quote = WATCH_FEEDS.includes(res.feed) ? quoteUpdate(res.feed, "ticker", res.snapshot) : null;
+  for (const field of ["bid", "ask", "last", "volume"] as const) {
+    const v = quote?.[field];
+    if (v !== undefined) lines.push(`  ${field.padEnd(8)}  ${v}`);
+  }
+  return lines;
+}
+
+export async function handleSnap(subcommand: string, flags: SnapFlags): Promise<void> {
+  switch (subcommand) {
+    case "get":
+      await getSnap(flags);
+      break;
+    case "help":
+    default:
+      printSnapHelp();
+      break;
+  }
+}
+
+async function getSnap(flags: SnapFlags): Promise<void> {
+  const ticker = flags._[2] !== undefined ? String(flags._[2]) : "";
+  if (!ticker) {
+    console.error("Usage: ssmd snap get <ticker> [--feed kalshi] [--output json]");
+    Deno.exit(1);
+  }
+  const feed = flags.feed ?? "kalshi";
+  const asJson = flags.json || flags.output === "json";
+
+  const { apiUrl, apiKey } = getApiConfig();
+  const params = new URLSearchParams({ feed });
+  const res = await fetch(`${apiUrl}/v1/data/snap/${encodeURIComponent(ticker)}?${params}`, {
+    headers: { "X-API-Key": apiKey },
+  });
+  if (!res.ok) {
+    const body = await res.json().catch(() => ({}));
+    console.error(`Error: ${body.error ?? res.statusText} (${res.status})`);
+    Deno.exit(1);
+  }
+  const snap = await res.json() as SnapResponse;
+
+  if (asJson) {
+    console.log(JSON.stringify(snap, null, 2));
+    return;
+  }
+  for (const line of formatSnap(snap)) console.log(line);
+  console.log("");
+  console.log(JSON.stringify(snap.snapshot, null, 2));
+}
+
+function printSnapHelp(): void {
+  console.log("Usage: ssmd snap <command> [options]");
+  console.log("");
+  console.log("Read the latest cached message per ticker from ssmd-snap (via the data API).");
+  console.log("");
+  console.log("Commands:");
+  console.log("  get <ticker>               Current bid/ask/last/volume plus the cached message");
+  console.log("");
+  console.log("Options:");
+  console.log("  --feed <feed>              Feed (default: kalshi)");
+  console.log("  --output <table|json>      Output format (default: table)");
+  console.log("");
+  console.log("Examples:");
+  console.log("  ssmd snap get KXBTCD-26JAN0517-T97999.99");
+  console.log("  ssmd snap get PF_XBTUSD --feed kraken-futures --output json");
+  console.log("");
+  console.log("Environment:");
+  console.log("  SSMD_API_URL        API base URL (default: http://localhost:8080)");
+  console.log("  SSMD_DATA_API_KEY   API key with datasets:read scope");
+}
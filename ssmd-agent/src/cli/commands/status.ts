@@ -1,72 +1,84 @@
 // status.ts - Top-level cluster status overview
-// Aggregates status from connectors, archivers, signals, and NATS streams
+// Aggregates status from the operator's CRs and NATS streams, joined with the
+// feeds in the local exchanges/ config
 
 import { kubectl, getCurrentEnvDisplay, type KubectlOptions } from "../utils/kubectl.ts";
 import { getEnvContext } from "../utils/env-context.ts";
+import { getFeedsDir } from "../utils/paths.ts";
+import { listFeeds } from "./feed.ts";
 
 interface StatusFlags {
   _: (string | number)[];
   namespace?: string;
   env?: string;
+  json?: boolean;
+  output?: string;
 }
 
+// CR kinds managed by ssmd-operators, in display order
+const RESOURCE_KINDS = [
+  { kind: "connector", title: "Connectors" },
+  { kind: "archiver", title: "Archivers" },
+  { kind: "snap", title: "Snaps" },
+  { kind: "notifier", title: "Notifiers" },
+  { kind: "signal", title: "Signals" },
+  { kind: "harman", title: "Harmans" },
+] as const;
+
+type ResourceKind = typeof RESOURCE_KINDS[number]["kind"];
+
+// Phases that count as healthy (backtest Signals finish as Succeeded)
+const HEALTHY_PHASES = new Set(["Running", "Succeeded"]);
+
+// Conditions where True is the problem; for all others False is
+const PROBLEM_WHEN_TRUE = new Set(["Degraded", "DeliveryDegraded", "StorageFilling", "TradingHalted"]);
+
+// Conditions that describe progress rather than health
+const INFORMATIONAL = new Set(["FinalSyncing"]);
+
 export async function handleStatus(flags: StatusFlags): Promise<void> {
   const opts: KubectlOptions = {
     env: flags.env,
     namespace: flags.namespace,
   };
+  const jsonOutput = flags.json === true || flags.output === "json";
 
   const envDisplay = await getCurrentEnvDisplay(opts.env);
   const context = await getEnvContext(opts.env);
 
-  console.log(`SSMD Cluster Status (${envDisplay})`);
-  console.log("=".repeat(40 + envDisplay.length) + "\n");
-
   // Run all status checks in parallel
-  const [connectors, archivers, signals, streams] = await Promise.all([
-    getConnectors(opts),
-    getArchivers(opts),
-    getSignals(opts),
+  const [resourceLists, streams, knownFeeds] = await Promise.all([
+    Promise.all(RESOURCE_KINDS.map(({ kind }) => getResources(kind, opts))),
     getNatsStreams(context.cluster),
+    getKnownFeeds(),
   ]);
+  const resources = resourceLists.flat().map((r) => joinFeedConfig(r, knownFeeds));
 
-  // Connectors
-  console.log("Connectors:");
-  if (connectors.length === 0) {
-    console.log("  (none)");
-  } else {
-    for (const c of connectors) {
-      const status = c.phase === "Running" ? "✓" : "✗";
-      const msgs = c.messages > 0 ? ` [${formatNumber(c.messages)} msgs]` : "";
-      console.log(`  ${status} ${c.name.padEnd(25)} ${c.phase.padEnd(10)}${msgs}`);
-    }
+  if (jsonOutput) {
+    console.log(JSON.stringify({ env: envDisplay, resources, streams }, null, 2));
+    return;
   }
-  console.log();
 
-  // Archivers
-  console.log("Archivers:");
-  if (archivers.length === 0) {
-    console.log("  (none)");
-  } else {
-    for (const a of archivers) {
-      const status = a.phase === "Running" ? "✓" : "✗";
-      const msgs = a.messages > 0 ? ` [${formatNumber(a.messages)} msgs]` : "";
-      console.log(`  ${status} ${a.name.padEnd(25)} ${a.phase.padEnd(10)}${msgs}`);
-    }
-  }
-  console.log();
+  console.log(`SSMD Cluster Status (${envDisplay})`);
+  console.log("=".repeat(40 + envDisplay.length) + "\n");
 
-  // Signals
-  console.log("Signals:");
-  if (signals.length === 0) {
-    console.log("  (none)");
-  } else {
-    for (const s of signals) {
-      const status = s.phase === "Running" ? "✓" : "✗";
-      console.log(`  ${status} ${s.name.padEnd(25)} ${s.phase}`);
+  for (const { kind, title } of RESOURCE_KINDS) {
+    console.log(`${title}:`);
+    const items = resources.filter((r) => r.kind === kind);
+    if (items.length === 0) {
+      console.log("  (none)");
     }
+    for (const r of items) {
+      const status = r.healthy ? "✓" : "✗";
+      const msgs = r.messages > 0 ? ` [${formatNumber(r.messages)} msgs]` : "";
+      const feed = r.feed ? ` ${r.feed}${r.feedInConfig === false ? " (not in config)" : ""}` : "";
+      console.log(`  ${status} ${r.name.padEnd(25)} ${r.phase.padEnd(10)}${feed}${msgs}`);
+      for (const issue of r.issues) {
+        console.log(`      - ${issue}`);
+      }
+    }
+    console.log();
   }
-  console.log();
 
   // NATS Streams
   console.log("NATS Streams:");
@@ -82,84 +94,99 @@ export async function handleStatus(flags: StatusFlags): Promise<void> {
   console.log();
 
   // Summary
-  const runningConnectors = connectors.filter(c => c.phase === "Running").length;
-  const runningArchivers = archivers.filter(a => a.phase === "Running").length;
-  const runningSignals = signals.filter(s => s.phase === "Running").length;
-
   console.log("Summary:");
-  console.log(`  Connectors: ${runningConnectors}/${connectors.length} running`);
-  console.log(`  Archivers:  ${runningArchivers}/${archivers.length} running`);
-  console.log(`  Signals:    ${runningSignals}/${signals.length} running`);
-  console.log(`  Streams:    ${streams.length} active`);
+  for (const { kind, title } of RESOURCE_KINDS) {
+    const items = resources.filter((r) => r.kind === kind);
+    const healthy = items.filter((r) => r.healthy).length;
+    console.log(`  ${(title + ":").padEnd(12)}${healthy}/${items.length} healthy`);
+  }
+  console.log(`  ${"Streams:".padEnd(12)}${streams.length} active`);
 }
 
-interface ConnectorStatus {
+export interface ResourceStatus {
+  kind: ResourceKind;
   name: string;
   phase: string;
+  feed?: string;
+  feedInConfig?: boolean;
   messages: number;
+  healthy: boolean;
+  issues: string[];
 }
 
-interface ArchiverStatus {
+interface StreamStatus {
   name: string;
-  phase: string;
   messages: number;
+  bytes: number;
 }
 
-interface SignalStatus {
-  name: string;
-  phase: string;
-}
+/**
+ * Summarize a CR's status: healthy when its phase is healthy and no condition
+ * reports a problem. Each problem condition becomes an issue ("Type: Reason").
+ */
+export function summarizeResource(kind: ResourceKind, item: Record<string, unknown>): ResourceStatus {
+  const metadata = (item.metadata ?? {}) as Record<string, unknown>;
+  const spec = (item.spec ?? {}) as Record<string, unknown>;
+  const status = (item.status ?? {}) as Record<string, unknown>;
+  const phase = (status.phase as string) || "Unknown";
 
-interface StreamStatus {
-  name: string;
-  messages: number;
-  bytes: number;
+  const issues: string[] = [];
+  for (const c of (status.conditions ?? []) as Record<string, string>[]) {
+    if (INFORMATIONAL.has(c.type)) continue;
+    const problem = PROBLEM_WHEN_TRUE.has(c.type) ? c.status === "True" : c.status === "False";
+    if (problem) {
+      issues.push(c.message ? `${c.type}: ${c.reason} (${c.message})` : `${c.type}: ${c.reason}`);
+    }
+  }
+
+  // Snaps name feeds per subscription and Harman names its exchange
+  let feed = spec.feed as string | undefined;
+  if (kind === "snap") {
+    feed = ((spec.subscriptions ?? []) as Record<string, string>[])[0]?.feed;
+  } else if (kind === "harman") {
+    feed = (spec.exchange as Record<string, string> | undefined)?.type;
+  }
+
+  return {
+    kind,
+    name: (metadata.name as string) || "-",
+    phase,
+    feed: feed || undefined,
+    messages: ((status.messagesPublished ?? status.messagesArchived) as number) || 0,
+    healthy: HEALTHY_PHASES.has(phase) && issues.length === 0,
+    issues,
+  };
 }
 
-async function getConnectors(opts: KubectlOptions): Promise<ConnectorStatus[]> {
-  try {
-    const output = await kubectl([
-      "get", "connector", "-o", "json"
-    ], opts);
-    const data = JSON.parse(output);
-    return (data.items || []).map((c: Record<string, unknown>) => ({
-      name: (c.metadata as Record<string, string>)?.name || "-",
-      phase: (c.status as Record<string, unknown>)?.phase as string || "Unknown",
-      messages: (c.status as Record<string, unknown>)?.messagesPublished as number || 0,
-    }));
-  } catch {
-    return [];
+/**
+ * Mark whether the resource's feed exists in exchanges/feeds. Left unset when
+ * the resource has no feed or no local config was found.
+ */
+export function joinFeedConfig(resource: ResourceStatus, knownFeeds: Set<string> | null): ResourceStatus {
+  if (!resource.feed || knownFeeds === null) {
+    return resource;
   }
+  return { ...resource, feedInConfig: knownFeeds.has(resource.feed) };
 }
 
-async function getArchivers(opts: KubectlOptions): Promise<ArchiverStatus[]> {
+async function getResources(kind: ResourceKind, opts: KubectlOptions): Promise<ResourceStatus[]> {
   try {
-    const output = await kubectl([
-      "get", "archiver", "-o", "json"
-    ], opts);
+    const output = await kubectl(["get", kind, "-o", "json"], opts);
     const data = JSON.parse(output);
-    return (data.items || []).map((a: Record<string, unknown>) => ({
-      name: (a.metadata as Record<string, string>)?.name || "-",
-      phase: (a.status as Record<string, unknown>)?.phase as string || "Unknown",
-      messages: (a.status as Record<string, unknown>)?.messagesArchived as number || 0,
-    }));
+    return (data.items || []).map((item: Record<string, unknown>) => summarizeResource(kind, item));
   } catch {
     return [];
   }
 }
 
-async function getSignals(opts: KubectlOptions): Promise<SignalStatus[]> {
+// getKnownFeeds returns the feed names in the local exchanges/ config, or null
+// when run outside a config checkout
+async function getKnownFeeds(): Promise<Set<string> | null> {
   try {
-    const output = await kubectl([
-      "get", "signal", "-o", "json"
-    ], opts);
-    const data = JSON.parse(output);
-    return (data.items || []).map((s: Record<string, unknown>) => ({
-      name: (s.metadata as Record<string, string>)?.name || "-",
-      phase: (s.status as Record<string, unknown>)?.phase as string || "Unknown",
-    }));
+    const feeds = await listFeeds(await getFeedsDir());
+    return new Set(feeds.map((f) => f.name));
   } catch {
-    return [];
+    return null;
   }
 }
 
@@ -253,10 +280,18 @@ export function printStatusHelp(): void {
   console.log("Shows overview of SSMD cluster components:");
   console.log("  - Connectors (market data feeds)");
   console.log("  - Archivers (data persistence)");
+  console.log("  - Snaps (market snapshots)");
+  console.log("  - Notifiers (alert routing)");
   console.log("  - Signals (event detection)");
+  console.log("  - Harmans (order gateways)");
   console.log("  - NATS Streams (message queues)");
   console.log();
+  console.log("A resource is healthy when its phase is Running (or Succeeded for a");
+  console.log("backtest) and no condition reports a problem. Feeds missing from the");
+  console.log("local exchanges/feeds config are flagged.");
+  console.log();
   console.log("Options:");
   console.log("  --env <env>       Target environment (default: current from 'ssmd env')");
   console.log("  --namespace NS    Override namespace (default: from environment)");
+  console.log("  --json            Output as JSON (also --output json)");
 }
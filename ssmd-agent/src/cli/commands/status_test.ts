@@ -0,0 +1,64 @@
+import { assertEquals } from "jsr:@std/assert";
+import { joinFeedConfig, summarizeResource } from "./status.ts";
+
+Deno.test("summarizeResource: running with healthy conditions → healthy", () => {
+  const r = summarizeResource("connector", {
+    metadata: { name: "kalshi-prod" },
+    spec: { feed: "kalshi" },
+    status: {
+      phase: "Running",
+      messagesPublished: 1200,
+      conditions: [
+        { type: "Ready", status: "True", reason: "DeploymentReady" },
+        { type: "Degraded", status: "False", reason: "MessagesFlowing" },
+      ],
+    },
+  });
+  assertEquals(r.healthy, true);
+  assertEquals(r.issues, []);
+  assertEquals(r.feed, "kalshi");
+  assertEquals(r.messages, 1200);
+});
+
+Deno.test("summarizeResource: problem conditions become issues", () => {
+  const r = summarizeResource("archiver", {
+    metadata: { name: "kalshi-archiver" },
+    spec: { feed: "kalshi" },
+    status: {
+      phase: "Running",
+      conditions: [
+        { type: "StorageHealthy", status: "False", reason: "PVCNotBound", message: "PVC is not bound" },
+        { type: "StorageFilling", status: "True", reason: "UsageHigh" },
+        { type: "FinalSyncing", status: "False", reason: "FinalSyncComplete" },
+      ],
+    },
+  });
+  assertEquals(r.healthy, false);
+  assertEquals(r.issues, ["StorageHealthy: PVCNotBound (PVC is not bound)", "StorageFilling: UsageHigh"]);
+});
+
+Deno.test("summarizeResource: harman uses its exchange type as feed", () => {
+  const r = summarizeResource("harman", {
+    metadata: { name: "harman-demo" },
+    spec: { exchange: { type: "kalshi", environment: "demo" } },
+    status: { phase: "Halted" },
+  });
+  assertEquals(r.feed, "kalshi");
+  assertEquals(r.healthy, false);
+});
+
+Deno.test("summarizeResource: missing status → Unknown", () => {
+  const r = summarizeResource("signal", { metadata: { name: "momentum" } });
+  assertEquals(r.phase, "Unknown");
+  assertEquals(r.healthy, false);
+});
+
+Deno.test("joinFeedConfig: flags feeds missing from config", () => {
+  const r = summarizeResource("snap", {
+    metadata: { name: "snap-kraken" },
+    spec: { subscriptions: [{ stream: "PROD_KRAKEN", feed: "kraken" }] },
+  });
+  assertEquals(joinFeedConfig(r, new Set(["kalshi"])).feedInConfig, false);
+  assertEquals(joinFeedConfig(r, new Set(["kraken"])).feedInConfig, true);
+  assertEquals(joinFeedConfig(r, null).feedInConfig, undefined);
+});
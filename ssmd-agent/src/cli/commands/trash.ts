@@ -0,0 +1,131 @@
+// trash.ts - exchanges/.trash: files removed by feed/env delete, each with a
+// tombstone recording where it came from, so `ssmd restore` can undo a delete
+
+import { basename, dirname, join } from "https://deno.land/std@0.224.0/path/mod.ts";
+
+export const TRASH_DIR = ".trash";
+const TOMBSTONE_FILE = "tombstone.json";
+
+export type TrashKind = "feed" | "environment";
+
+export interface Tombstone {
+  /** Directory name under .trash/ */
+  id: string;
+  kind: TrashKind;
+  name: string;
+  /** Original path, relative to the exchanges root */
+  path: string;
+  deleted_at: string;
+  /** Environments that still referenced the feed (deleted with --force) */
+  referenced_by?: string[];
+}
+
+interface TrashFlags {
+  _: (string | number)[];
+}
+
+/**
+ * Move exchangesRoot/path into a new .trash/ entry with its tombstone
+ */
+export async function moveToTrash(
+  exchangesRoot: string,
+  kind: TrashKind,
+  name: string,
+  path: string,
+  referencedBy: string[] = [],
+  now = new Date(),
+): Promise<Tombstone> {
+  const stamp = now.toISOString().replace(/[-:.]/g, "");
+  const tombstone: Tombstone = {
+    id: `${stamp}-${kind}-${name}`,
+    kind,
+    name,
+    path,
+    deleted_at: now.toISOString(),
+    ...(referencedBy.length > 0 ? { referenced_by: referencedBy } : {}),
+  };
+
+  const entryDir = join(exchangesRoot, TRASH_DIR, tombstone.id);
+  await Deno.mkdir(entryDir, { recursive: true });
+  await Deno.writeTextFile(join(entryDir, TOMBSTONE_FILE), JSON.stringify(tombstone, null, 2) + "\n");
+  await Deno.rename(join(exchangesRoot, path), join(entryDir, basename(path)));
+  return tombstone;
+}
+
+/** Tombstones in .trash/, newest first */
+export async function listTrash(exchangesRoot: string): Promise<Tombstone[]> {
+  const tombstones: Tombstone[] = [];
+  try {
+    for await (const entry of Deno.readDir(join(exchangesRoot, TRASH_DIR))) {
+      if (!entry.isDirectory) continue;
+      try {
+        const content = await Deno.readTextFile(join(exchangesRoot, TRASH_DIR, entry.name, TOMBSTONE_FILE));
+        tombstones.push(JSON.parse(content) as Tombstone);
+      } catch (e) {
+        if (!(e instanceof Deno.errors.NotFound)) throw e;
+      }
+    }
+  } catch (e) {
+    if (!(e instanceof Deno.errors.NotFound)) throw e;
+  }
+  return tombstones.sort((a, b) => b.deleted_at.localeCompare(a.deleted_at));
+}
+
+/**
+ * Restore a trashed file to its original path. target is a tombstone id, or
+ * a feed/environment name for its most recent delete. Refuses to overwrite a
+ * file that has been recreated since.
+ */
+export async function restoreFromTrash(exchangesRoot: string, target: string): Promise<Tombstone> {
+  const tombstones = await listTrash(exchangesRoot);
+  const tombstone = tombstones.find((t) => t.id === target) ?? tombstones.find((t) => t.name === target);
+  if (!tombstone) {
+    throw new Error(`Nothing named '${target}' in ${join(exchangesRoot, TRASH_DIR)}`);
+  }
+
+  const dest = join(exchangesRoot, tombstone.path);
+  try {
+    await Deno.stat(dest);
+    throw new Error(`${tombstone.path} already exists; move it away before restoring ${tombstone.id}`);
+  } catch (e) {
+    if (!(e instanceof Deno.errors.NotFound)) throw e;
+  }
+
+  const entryDir = join(exchangesRoot, TRASH_DIR, tombstone.id);
+  await Deno.mkdir(dirname(dest), { recursive: true });
+  await Deno.rename(join(entryDir, basename(tombstone.path)), dest);
+  await Deno.remove(entryDir, { recursive: true });
+  return tombstone;
+}
+
+/**
+ * ssmd restore [id|name] - with no argument, list what can be restored
+ */
+export async function handleRestore(flags: TrashFlags, exchangesRoot: string): Promise<void> {
+  const target = flags._[1] as string | undefined;
+
+  if (!target) {
+    const tombstones = await listTrash(exchangesRoot);
+    if (tombstones.length === 0) {
+      console.log("Trash is empty.");
+      return;
+    }
+    console.log("ID".padEnd(48) + "KIND".padEnd(13) + "PATH");
+    for (const t of tombstones) {
+      console.log(t.id.padEnd(48) + t.kind.padEnd(13) + t.path);
+    }
+    console.log("\nUsage: ssmd restore <id|name>");
+    return;
+  }
+
+  try {
+    const t = await restoreFromTrash(exchangesRoot, String(target));
+    console.log(`Restored ${t.kind} '${t.name}' to ${t.path}`);
+    if (t.referenced_by) {
+      console.log(`  Referenced by: ${t.referenced_by.join(", ")}`);
+    }
+  } catch (e) {
+    console.error(e instanceof Error ? e.message : String(e));
+    Deno.exit(1);
+  }
+}
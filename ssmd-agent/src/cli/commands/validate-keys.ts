@@ -0,0 +1,165 @@
+// Key source checks for ssmd validate --check-keys: every environment's
+// keys.<name>.source is resolved against where it points (env vars, a
+// Kubernetes secret, a Vault path) without printing any secret values
+import type { ConfigSnapshot } from "./diff.ts";
+import { kubectl } from "../utils/kubectl.ts";
+import type { ValidationIssue } from "../../lib/types/validation.ts";
+
+/** A parsed keys.<name>.source string */
+export type KeySource =
+  | { kind: "env"; vars: string[] }
+  | { kind: "sealed-secret"; namespace?: string; name: string }
+  | { kind: "vault"; path: string };
+
+export type KeyCheckStatus = "ok" | "failed" | "skipped";
+
+/** Outcome of checking one key source */
+export interface KeyCheck {
+  /** Path relative to exchanges/, e.g. "environments/kalshi-dev.yaml" */
+  file: string;
+  key: string;
+  source: string;
+  status: KeyCheckStatus;
+  /** Why the check failed or was skipped */
+  reason?: string;
+}
+
+/** Thrown by a resolver when the check cannot run here, e.g. no credentials */
+export class SkipCheck extends Error {}
+
+/** How sources are resolved; replaced in tests */
+export interface KeyResolvers {
+  env: (name: string) => string | undefined;
+  /** Key names of a secret, or null when it does not exist */
+  secretKeys: (namespace: string | undefined, name: string) => Promise<string[] | null>;
+  /** Whether a Vault path is readable */
+  vaultReadable: (path: string) => Promise<boolean>;
+}
+
+/**
+ * Parse a key source: env:VAR[,VAR...], sealed-secret:[namespace/]name or
+ * vault:path. Throws on anything else.
+ */
+export function parseKeySource(source: string): KeySource {
+  const colon = source.indexOf(":");
+  const scheme = colon < 0 ? source : source.slice(0, colon);
+  const rest = colon < 0 ? "" : source.slice(colon + 1).trim();
+  if (rest === "") throw new Error(`source ${JSON.stringify(source)} has no target`);
+  switch (scheme) {
+    case "env":
+      return { kind: "env", vars: rest.split(",").map((v) => v.trim()).filter(Boolean) };
+    case "sealed-secret": {
+      const parts = rest.split("/");
+      if (parts.length > 2 || parts.some((p) => p === "")) {
+        throw new Error(`sealed-secret source must be [namespace/]name, got ${JSON.stringify(rest)}`);
+      }
+      return parts.length === 2 ? { kind: "sealed-secret", namespace: parts[0], name: parts[1] } : { kind: "sealed-secret", name: parts[0] };
+    }
+    case "vault":
+      return { kind: "vault", path: rest.replace(/^\/+/, "") };
+    default:
+      throw new Error(`unknown key source ${JSON.stringify(scheme)} (expected env:, sealed-secret: or vault:)`);
+  }
+}
+
+interface KeyDoc {
+  source?: unknown;
+  fields?: unknown;
+}
+
+async function checkSource(source: KeySource, fields: string[], resolvers: KeyResolvers): Promise<string | null> {
+  switch (source.kind) {
+    case "env": {
+      const missing = source.vars.filter((v) => !resolvers.env(v));
+      return missing.length > 0 ? `env ${missing.join(", ")} not set` : null;
+    }
+    case "sealed-secret": {
+      const where = source.namespace ? `${source.namespace}/${source.name}` : source.name;
+      const keys = await resolvers.secretKeys(source.namespace, source.name);
+      if (keys === null) return `secret ${where} not found`;
+      const missing = fields.filter((f) => !keys.includes(f));
+      return missing.length > 0 ? `secret ${where} has no ${missing.join(", ")}` : null;
+    }
+    case "vault":
+      return await resolvers.vaultReadable(source.path) ? null : `vault path ${source.path} is not readable`;
+  }
+}
+
+/**
+ * Check every environment's key sources. A sealed-secret source must name a
+ * secret that holds each of the key's fields; a vault source must be
+ * readable with the current token.
+ */
+export async function checkKeySources(snapshot: ConfigSnapshot, resolvers: KeyResolvers): Promise<KeyCheck[]> {
+  const checks: KeyCheck[] = [];
+  for (const [name, doc] of snapshot.environments) {
+    const keys = (doc as { keys?: unknown })?.keys;
+    if (typeof keys !== "object" || keys === null) continue;
+    const file = `environments/${name}.yaml`;
+    for (const [key, value] of Object.entries(keys as Record<string, KeyDoc>)) {
+      if (typeof value?.source !== "string") continue;
+      const result: KeyCheck = { file, key, source: value.source, status: "ok" };
+      try {
+        const fields = Array.isArray(value.fields) ? value.fields.map(String) : [];
+        const reason = await checkSource(parseKeySource(value.source), fields, resolvers);
+        if (reason) Object.assign(result, { status: "failed", reason });
+      } catch (e) {
+        Object.assign(result, { status: e instanceof SkipCheck ? "skipped" : "failed", reason: (e as Error).message });
+      }
+      checks.push(result);
+    }
+  }
+  return checks;
+}
+
+/** Failed checks as validation errors */
+export function keyCheckIssues(checks: KeyCheck[]): ValidationIssue[] {
+  return checks
+    .filter((c) => c.status === "failed")
+    .map((c): ValidationIssue => ({ code: "SSMD-E005", severity: "error", file: c.file, message: `keys.${c.key}: ${c.reason}` }));
+}
+
+export function printKeyChecks(checks: KeyCheck[]): void {
+  for (const c of checks) {
+    const detail = c.reason ? ` (${c.reason})` : "";
+    console.log(`${c.status.toUpperCase().padEnd(7)} ${c.file} keys.${c.key} ${c.source}${detail}`);
+  }
+}
+
+/**
+ * Resolvers against the real world: env vars of this process, secrets via
+ * kubectl in the current ssmd environment, and Vault via VAULT_ADDR and
+ * VAULT_TOKEN. Checks that cannot run here are skipped with the reason.
+ */
+export function defaultKeyResolvers(env?: string): KeyResolvers {
+  return {
+    env: (name) => Deno.env.get(name),
+    secretKeys: async (namespace, name) => {
+      let out: string;
+      try {
+        out = await kubectl(["get", "secret", name, "-o", "json"], { env, namespace });
+      } catch (e) {
+        const message = (e as Error).message;
+        if (/NotFound/.test(message)) return null;
+        throw new SkipCheck(`kubectl unavailable: ${message.split("\n")[0].replace(/^kubectl failed: /, "")}`);
+      }
+      return Object.keys(JSON.parse(out).data ?? {});
+    },
+    vaultReadable: async (path) => {
+      const addr = Deno.env.get("VAULT_ADDR");
+      const token = Deno.env.get("VAULT_TOKEN");
+      if (!addr || !token) throw new SkipCheck("VAULT_ADDR and VAULT_TOKEN are required");
+      let res: Response;
+      try {
+        res = await fetch(`${addr.replace(/\/+$/, "")}/v1/${path}`, {
+          headers: { "X-Vault-Token": token },
+          signal: AbortSignal.timeout(10_000),
+        });
+      } catch (e) {
+        throw new SkipCheck(`vault unreachable: ${(e as Error).message}`);
+      }
+      await res.body?.cancel();
+      return res.ok;
+    },
+  };
+}
@@ -0,0 +1,97 @@
+// Machine-readable output for ssmd validate: a per-file JSON report
+// (--output json) and SARIF 2.1.0 for GitHub code scanning (--output sarif)
+import { CONFIG_KINDS, type ConfigSnapshot } from "./diff.ts";
+import { VALIDATION_CODES, type ValidationCode, type ValidationIssue } from "../../lib/types/validation.ts";
+import type { KeyCheck } from "./validate-keys.ts";
+
+export const VALIDATE_OUTPUTS = ["text", "json", "sarif"] as const;
+export type ValidateOutput = typeof VALIDATE_OUTPUTS[number];
+
+const SARIF_SCHEMA = "https://json.schemastore.org/sarif-2.1.0.json";
+
+/** Result for one checked file */
+export interface FileResult {
+  /** Path relative to exchanges/ */
+  file: string;
+  result: "pass" | "fail";
+  issues: Omit<ValidationIssue, "file">[];
+}
+
+export interface ValidationReport {
+  root: string;
+  ok: boolean;
+  summary: { files: number; failed: number; errors: number; warnings: number };
+  files: FileResult[];
+  /** Key source checks, with --check-keys */
+  keys?: KeyCheck[];
+}
+
+/**
+ * One entry per feed, schema and environment file, passing or not, plus any
+ * other file an issue names (schema sources, ssmd.lock). A file fails when it
+ * has an error; warnings alone pass.
+ */
+export function buildReport(
+  root: string,
+  snapshot: ConfigSnapshot,
+  issues: ValidationIssue[],
+  keys?: KeyCheck[],
+): ValidationReport {
+  const byFile = new Map<string, Omit<ValidationIssue, "file">[]>();
+  for (const kind of CONFIG_KINDS) {
+    for (const name of snapshot[kind].keys()) byFile.set(`${kind}/${name}.yaml`, []);
+  }
+  for (const { file, ...issue } of issues) {
+    byFile.set(file, [...(byFile.get(file) ?? []), issue]);
+  }
+
+  const files = [...byFile.entries()]
+    .sort(([a], [b]) => a.localeCompare(b))
+    .map(([file, list]): FileResult => ({
+      file,
+      result: list.some((i) => i.severity === "error") ? "fail" : "pass",
+      issues: list,
+    }));
+  const errors = issues.filter((i) => i.severity === "error").length;
+  return {
+    root,
+    ok: errors === 0,
+    summary: {
+      files: files.length,
+      failed: files.filter((f) => f.result === "fail").length,
+      errors,
+      warnings: issues.length - errors,
+    },
+    files,
+    ...(keys && { keys }),
+  };
+}
+
+/**
+ * SARIF log with one rule per validation code and one result per issue.
+ * uriPrefix is exchanges/ relative to the repository root (e.g. "exchanges/"),
+ * so code scanning can place results on the files.
+ */
+export function toSarif(issues: ValidationIssue[], uriPrefix: string): Record<string, unknown> {
+  const codes = Object.keys(VALIDATION_CODES) as ValidationCode[];
+  return {
+    $schema: SARIF_SCHEMA,
+    version: "2.1.0",
+    runs: [{
+      tool: {
+        driver: {
+          name: "ssmd validate",
+          informationUri: "https://github.com/aaronwald/ssmd",
+          rules: codes.map((id) => ({ id, shortDescription: { text: VALIDATION_CODES[id] } })),
+        },
+      },
+      results: issues.map((issue) => ({
+        ruleId: issue.code,
+        ruleIndex: codes.indexOf(issue.code),
+        level: issue.severity,
+        message: { text: issue.message },
+        locations: [{ physicalLocation: { artifactLocation: { uri: `${uriPrefix}${issue.file}` } } }],
+      })),
+    }],
+  };
+}
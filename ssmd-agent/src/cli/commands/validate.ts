@@ -0,0 +1,268 @@
+// Validate command: check exchanges/ feeds, schemas and environments
+// ssmd validate [--against <ref>] [--check-keys] [--output text|json|sarif]
+import { encodeHex } from "https://deno.land/std@0.224.0/encoding/hex.ts";
+import { FeedSchema } from "../../lib/types/feed.ts";
+import type { ValidationCode, ValidationIssue } from "../../lib/types/validation.ts";
+import { compareFields, type FieldMap, parseFieldMap } from "../../lib/schema/mod.ts";
+import { findExchangesRoot } from "../utils/paths.ts";
+import { git } from "../utils/git.ts";
+import { type ConfigSnapshot, loadGitRef, loadWorkingTree } from "./diff.ts";
+import { LOCK_FILE, verifyLock } from "./lock.ts";
+import { checkSchemaSources } from "./schema.ts";
+import { buildReport, toSarif, VALIDATE_OUTPUTS, type ValidateOutput } from "./validate-report.ts";
+import { checkKeySources, defaultKeyResolvers, keyCheckIssues, type KeyCheck, printKeyChecks } from "./validate-keys.ts";
+
+export type { Severity, ValidationIssue } from "../../lib/types/validation.ts";
+
+interface ValidateFlags {
+  against?: string;
+  "check-keys"?: boolean;
+  env?: string;
+  output?: string;
+  /** Same as --output json */
+  json?: boolean;
+}
+
+interface SchemaDoc {
+  feed?: unknown;
+  type?: unknown;
+  version?: unknown;
+  compatible_with?: unknown;
+  fields?: unknown;
+}
+
+interface EnvironmentDoc {
+  feed?: unknown;
+  schema?: unknown;
+}
+
+function isObject(v: unknown): v is Record<string, unknown> {
+  return typeof v === "object" && v !== null && !Array.isArray(v);
+}
+
+/** Stable JSON (sorted object keys) so hashes do not depend on YAML key order */
+function canonicalJson(v: unknown): string {
+  if (Array.isArray(v)) return `[${v.map(canonicalJson).join(",")}]`;
+  if (isObject(v)) {
+    return `{${Object.keys(v).sort().map((k) => `${JSON.stringify(k)}:${canonicalJson(v[k])}`).join(",")}}`;
+  }
+  return JSON.stringify(v);
+}
+
+/** sha256 of a schema's canonical content, e.g. "sha256:9f86d0..." */
+export async function schemaHash(schema: unknown): Promise<string> {
+  const digest = await crypto.subtle.digest("SHA-256", new TextEncoder().encode(canonicalJson(schema)));
+  return `sha256:${encodeHex(new Uint8Array(digest))}`;
+}
+
+/**
+ * Check a workspace snapshot: feeds parse and match their file names, schemas
+ * carry feed/type/version/fields and keep their compatible_with promises, and
+ * environments reference an existing feed and a schema (type:version) of that
+ * feed.
+ */
+export function validateSnapshot(snapshot: ConfigSnapshot): ValidationIssue[] {
+  const issues: ValidationIssue[] = [];
+  const error = (code: ValidationCode, file: string, message: string) => issues.push({ code, severity: "error", file, message });
+
+  for (const [name, doc] of snapshot.feeds) {
+    const file = `feeds/${name}.yaml`;
+    const parsed = FeedSchema.safeParse(doc);
+    if (!parsed.success) {
+      for (const issue of parsed.error.issues) {
+        error("SSMD-F001", file, `${issue.path.join(".") || "(root)"}: ${issue.message}`);
+      }
+    } else if (parsed.data.name !== name) {
+      error("SSMD-F002", file, `feed name ${parsed.data.name} does not match file name ${name}`);
+    }
+  }
+
+  // feed -> "type:version" keys declared by schema files
+  const schemaKeys = new Map<string, Set<string>>();
+  // "feed/type:version" -> fields, for compatible_with checks
+  const versionFields = new Map<string, FieldMap>();
+  for (const [name, doc] of snapshot.schemas) {
+    const file = `schemas/${name}.yaml`;
+    const s = (isObject(doc) ? doc : {}) as SchemaDoc;
+    const missing = (["feed", "type", "version"] as const).filter((k) => typeof s[k] !== "string");
+    if (missing.length > 0) {
+      error("SSMD-S001", file, `missing ${missing.join(", ")}`);
+      continue;
+    }
+    let fields: FieldMap | undefined;
+    try {
+      fields = parseFieldMap(s.fields);
+    } catch (e) {
+      error("SSMD-S002", file, (e as Error).message);
+    }
+    const feed = s.feed as string;
+    if (!snapshot.feeds.has(feed)) {
+      error("SSMD-S003", file, `feed ${feed} has no feeds/${feed}.yaml`);
+    }
+    const keys = schemaKeys.get(feed) ?? new Set<string>();
+    const key = `${s.type}:${s.version}`;
+    if (keys.has(key)) {
+      error("SSMD-S004", file, `duplicate schema ${feed} ${key}`);
+    }
+    keys.add(key);
+    schemaKeys.set(feed, keys);
+    if (fields) {
+      versionFields.set(`${feed}/${s.type}:${s.version}`, fields);
+    }
+  }
+
+  // compatible_with must name versions of the same message type that this
+  // version does not break
+  for (const [name, doc] of snapshot.schemas) {
+    const s = (isObject(doc) ? doc : {}) as SchemaDoc;
+    if (s.compatible_with === undefined) continue;
+    const file = `schemas/${name}.yaml`;
+    if (!Array.isArray(s.compatible_with)) {
+      error("SSMD-S005", file, "compatible_with must be a list of versions");
+      continue;
+    }
+    const fields = versionFields.get(`${s.feed}/${s.type}:${s.version}`);
+    for (const id of s.compatible_with) {
+      const declared = versionFields.get(`${s.feed}/${s.type}:${id}`);
+      if (!declared) {
+        error("SSMD-S006", file, `compatible_with ${id}: no schema ${s.feed} ${s.type}:${id}`);
+      } else if (fields) {
+        for (const c of compareFields(declared, fields).filter((c) => c.breaking)) {
+          error("SSMD-S007", file, `compatible_with ${id}: breaking change (${c.kind}) at ${c.path}`);
+        }
+      }
+    }
+  }
+
+  for (const [name, doc] of snapshot.environments) {
+    const file = `environments/${name}.yaml`;
+    const env = (isObject(doc) ? doc : {}) as EnvironmentDoc;
+    if (typeof env.feed !== "string") {
+      error("SSMD-E001", file, "feed is required");
+      continue;
+    }
+    if (!snapshot.feeds.has(env.feed)) {
+      error("SSMD-E002", file, `feed ${env.feed} has no feeds/${env.feed}.yaml`);
+    }
+    if (env.schema !== undefined) {
+      if (typeof env.schema !== "string" || !/^[^:]+:[^:]+$/.test(env.schema)) {
+        error("SSMD-E003", file, `schema must be type:version, got ${JSON.stringify(env.schema)}`);
+      } else if (!schemaKeys.get(env.feed)?.has(env.schema)) {
+        error("SSMD-E004", file, `schema ${env.schema} of feed ${env.feed} has no schemas/ file`);
+      }
+    }
+  }
+
+  return issues;
+}
+
+/**
+ * Schemas whose content changed since `before` without a version bump. Readers
+ * key schemas by type:version, so an in-place edit silently changes what an
+ * existing version means.
+ */
+export async function checkSchemaHashes(before: ConfigSnapshot, after: ConfigSnapshot): Promise<ValidationIssue[]> {
+  const previous = new Map<string, { name: string; hash: string }>();
+  for (const [name, doc] of before.schemas) {
+    const s = (isObject(doc) ? doc : {}) as SchemaDoc;
+    previous.set(`${s.feed}/${s.type}:${s.version}`, { name, hash: await schemaHash(doc) });
+  }
+
+  const issues: ValidationIssue[] = [];
+  for (const [name, doc] of after.schemas) {
+    const s = (isObject(doc) ? doc : {}) as SchemaDoc;
+    const key = `${s.feed}/${s.type}:${s.version}`;
+    const old = previous.get(key);
+    if (old && old.hash !== await schemaHash(doc)) {
+      issues.push({
+        code: "SSMD-S008",
+        severity: "error",
+        file: `schemas/${name}.yaml`,
+        message: `schema ${s.feed} ${s.type}:${s.version} changed without a version bump`,
+      });
+    }
+  }
+  return issues;
+}
+
+/**
+ * Drift from exchanges/ssmd.lock, one error per entry; [] when there is no
+ * lockfile. Not part of validateWorkspace, since commit rewrites the lock.
+ */
+export async function checkLock(root: string): Promise<ValidationIssue[]> {
+  const drift = await verifyLock(root);
+  return (drift ?? []).map((d): ValidationIssue => ({
+    code: "SSMD-L001",
+    severity: "error",
+    file: LOCK_FILE,
+    message: `drifted: ${d}`,
+  }));
+}
+
+/**
+ * Full validation of the workspace, including schema source files, plus
+ * schema hash checks against a git ref when given
+ */
+export async function validateWorkspace(root: string, against?: string): Promise<ValidationIssue[]> {
+  const snapshot = await loadWorkingTree(root);
+  const issues = validateSnapshot(snapshot);
+  issues.push(...await checkSchemaSources(root, snapshot));
+  if (against) {
+    issues.push(...await checkSchemaHashes(await loadGitRef(root, against), snapshot));
+  }
+  return issues;
+}
+
+export function printIssues(issues: ValidationIssue[]): void {
+  if (issues.length === 0) {
+    console.log("exchanges/ is valid");
+    return;
+  }
+  for (const issue of issues) {
+    console.log(`${issue.severity.toUpperCase().padEnd(7)} ${issue.code} ${issue.file}: ${issue.message}`);
+  }
+  const errors = issues.filter((i) => i.severity === "error").length;
+  console.log(`\n${errors} error(s), ${issues.length - errors} warning(s)`);
+}
+
+/** exchanges/ relative to the repository root, e.g. "exchanges/"; "" outside git */
+async function repoPrefix(root: string): Promise<string> {
+  try {
+    return (await git(["rev-parse", "--show-prefix"], root)).trim();
+  } catch {
+    return "";
+  }
+}
+
+export async function handleValidate(flags: ValidateFlags): Promise<void> {
+  const output = (flags.output ?? (flags.json ? "json" : "text")) as ValidateOutput;
+  if (!VALIDATE_OUTPUTS.includes(output)) {
+    console.error(`--output must be one of ${VALIDATE_OUTPUTS.join(", ")}`);
+    Deno.exit(1);
+  }
+
+  const root = await findExchangesRoot();
+  const snapshot = await loadWorkingTree(root);
+  const issues = await validateWorkspace(root, flags.against);
+  issues.push(...await checkLock(root));
+  let keys: KeyCheck[] | undefined;
+  if (flags["check-keys"]) {
+    keys = await checkKeySources(snapshot, defaultKeyResolvers(flags.env));
+    issues.push(...keyCheckIssues(keys));
+  }
+
+  if (output === "json") {
+    console.log(JSON.stringify(buildReport(root, snapshot, issues, keys), null, 2));
+  } else if (output === "sarif") {
+    console.log(JSON.stringify(toSarif(issues, await repoPrefix(root)), null, 2));
+  } else {
+    if (keys) {
+      printKeyChecks(keys);
+      console.log("");
+    }
+    printIssues(issues);
+  }
+  if (issues.some((i) => i.severity === "error")) {
+    Deno.exit(1);
+  }
+}
@@ -0,0 +1,44 @@
+// Checkpoint store over the nats-checkpoints ConfigMap, via kubectl
+
+import {
+  CHECKPOINT_CONFIGMAP,
+  checkpointKey,
+  type CheckpointStore,
+  checkpointValue,
+  parseCheckpoints,
+} from "../../lib/nats/mod.ts";
+import { kubectl, type KubectlOptions } from "./kubectl.ts";
+
+async function readCheckpointData(options: KubectlOptions): Promise<Record<string, string> | undefined> {
+  try {
+    const output = await kubectl(["get", "configmap", CHECKPOINT_CONFIGMAP, "-o", "json"], options);
+    return (JSON.parse(output) as { data?: Record<string, string> }).data ?? {};
+  } catch (err) {
+    if ((err as Error).message.includes("NotFound")) return undefined;
+    throw err;
+  }
+}
+
+// Checkpoints in the environment's namespace (--env, --namespace)
+export function configMapCheckpointStore(options: KubectlOptions): CheckpointStore {
+  return {
+    async list(stream) {
+      return parseCheckpoints(await readCheckpointData(options), stream);
+    },
+    async get(stream, consumer) {
+      checkpointKey(stream, consumer);
+      const checkpoints = parseCheckpoints(await readCheckpointData(options), stream);
+      return checkpoints.find((cp) => cp.consumer === consumer) ?? null;
+    },
+    async set(stream, consumer, sequence) {
+      const key = checkpointKey(stream, consumer);
+      const value = checkpointValue(sequence, new Date());
+      if (await readCheckpointData(options) === undefined) {
+        await kubectl(["create", "configmap", CHECKPOINT_CONFIGMAP, `--from-literal=${key}=${value}`], options);
+      } else {
+        const patch = JSON.stringify({ data: { [key]: value } });
+        await kubectl(["patch", "configmap", CHECKPOINT_CONFIGMAP, "--type", "merge", "-p", patch], options);
+      }
+    },
+  };
+}
@@ -4,10 +4,12 @@
 import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
 import { parse as parseYaml, stringify as stringifyYaml } from "https://deno.land/std@0.224.0/yaml/mod.ts";
 import {
-  EnvConfigSchema,
   type EnvConfig,
   type Environment,
   getDefaultConfig,
+  RawEnvConfigSchema,
+  type RawEnvConfig,
+  resolveEnvConfig,
 } from "../../lib/types/env-config.ts";
 
 // Get the config directory path
@@ -33,14 +35,14 @@ async function ensureConfigDir(): Promise<void> {
   }
 }
 
-// Load config from file, creating default if not exists
-export async function loadConfig(): Promise<EnvConfig> {
+// Load config as written (extends unresolved), creating default if not exists
+export async function loadRawConfig(): Promise<RawEnvConfig> {
   const configPath = getConfigPath();
 
   try {
     const content = await Deno.readTextFile(configPath);
     const parsed = parseYaml(content);
-    return EnvConfigSchema.parse(parsed);
+    return RawEnvConfigSchema.parse(parsed);
   } catch (e) {
     if (e instanceof Deno.errors.NotFound) {
       // Create default config
@@ -52,8 +54,13 @@ export async function loadConfig(): Promise<EnvConfig> {
   }
 }
 
-// Save config to file
-export async function saveConfig(config: EnvConfig): Promise<void> {
+// Load config with every environment's extends chain merged in and validated
+export async function loadConfig(): Promise<EnvConfig> {
+  return resolveEnvConfig(await loadRawConfig());
+}
+
+// Save config to file. Pass the raw config so extends is kept.
+export async function saveConfig(config: EnvConfig | RawEnvConfig): Promise<void> {
   await ensureConfigDir();
   const configPath = getConfigPath();
   const content = stringifyYaml(config as Record<string, unknown>);
@@ -93,7 +100,9 @@ export async function getEnv(name: string): Promise<Environment> {
 
 // Set current environment
 export async function setCurrentEnv(name: string): Promise<void> {
-  const config = await loadConfig();
+  // Validate the resolved config, but write back the raw one so inheritance survives
+  await loadConfig();
+  const config = await loadRawConfig();
 
   if (!config.environments[name]) {
     throw new Error(`Environment '${name}' not found. Available: ${Object.keys(config.environments).join(", ")}`);
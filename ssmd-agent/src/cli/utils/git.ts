@@ -0,0 +1,46 @@
+// Git helpers for commands that read exchanges/ configs at a ref or commit them
+
+/**
+ * Run git in a directory and return stdout. Throws with git's stderr on a
+ * non-zero exit.
+ */
+export async function git(args: string[], cwd: string, stdin?: string): Promise<string> {
+  const cmd = new Deno.Command("git", {
+    args,
+    cwd,
+    stdin: stdin === undefined ? "null" : "piped",
+    stdout: "piped",
+    stderr: "piped",
+  });
+  const child = cmd.spawn();
+  if (stdin !== undefined) {
+    const writer = child.stdin.getWriter();
+    await writer.write(new TextEncoder().encode(stdin));
+    await writer.close();
+  }
+  const { stdout, stderr, code } = await child.output();
+
+  if (code !== 0) {
+    const err = new TextDecoder().decode(stderr).trim();
+    throw new Error(`git ${args[0]} failed: ${err}`);
+  }
+
+  return new TextDecoder().decode(stdout);
+}
+
+/**
+ * Files of a directory at a git ref, as path (relative to dir) -> content.
+ * Only files ending in `suffix` are read.
+ */
+export async function readTreeAt(dir: string, ref: string, suffix = ".yaml"): Promise<Map<string, string>> {
+  await git(["rev-parse", "--verify", "--quiet", `${ref}^{commit}`], dir).catch(() => {
+    throw new Error(`Unknown git ref: ${ref}`);
+  });
+
+  const files = new Map<string, string>();
+  const listing = await git(["ls-tree", "-r", "--name-only", ref, "--", "."], dir);
+  for (const path of listing.split("\n").filter((p) => p.endsWith(suffix))) {
+    files.set(path, await git(["show", `${ref}:./${path}`], dir));
+  }
+  return files;
+}
@@ -43,6 +43,44 @@ export async function kubectl(
   return new TextDecoder().decode(stdout);
 }
 
+// Execute kubectl with input on stdin (e.g. apply -f -). Returns the exit code
+// instead of throwing, for commands like diff that exit non-zero on success.
+export async function kubectlWithInput(
+  args: string[],
+  input: string,
+  options: KubectlOptions = {}
+): Promise<{ code: number; stdout: string; stderr: string }> {
+  const context = await getEnvContext(options.env);
+
+  // Build args with context
+  const fullArgs = ["--context", context.cluster];
+
+  // Add namespace if not already in args
+  if (!args.includes("-n") && !args.includes("--namespace")) {
+    const ns = options.namespace ?? context.namespace;
+    fullArgs.push("-n", ns);
+  }
+
+  fullArgs.push(...args);
+
+  const child = new Deno.Command("kubectl", {
+    args: fullArgs,
+    stdin: "piped",
+    stdout: "piped",
+    stderr: "piped",
+  }).spawn();
+  const writer = child.stdin.getWriter();
+  await writer.write(new TextEncoder().encode(input));
+  await writer.close();
+
+  const { code, stdout, stderr } = await child.output();
+  return {
+    code,
+    stdout: new TextDecoder().decode(stdout),
+    stderr: new TextDecoder().decode(stderr),
+  };
+}
+
 // Stream kubectl output (for logs, etc.)
 export async function kubectlStream(
   args: string[],
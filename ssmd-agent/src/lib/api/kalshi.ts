@@ -1,7 +1,9 @@
 /**
  * Kalshi API client with rate limiting and pagination
  */
-import { RateLimiter, retry } from "../utils/rate-limit.ts";
+import { HttpClient, HttpError } from "../httpx/mod.ts";
+import { RateLimiter } from "../utils/rate-limit.ts";
+import { budgetCounterFromEnv, parseRateBudget, RateBudget } from "../utils/rate-budget.ts";
 import { fromKalshiEvent, type Event, type KalshiEvent } from "../types/event.ts";
 import { fromKalshiMarket, type Market, type KalshiMarket } from "../types/market.ts";
 import {
@@ -66,6 +68,8 @@ export interface KalshiClientOptions {
   minDelayMs?: number;
   /** Max retries for rate limiting (default: 10) */
   maxRetries?: number;
+  /** Request budget consulted before every request (default: none) */
+  budget?: RateBudget;
 }
 
 /**
@@ -104,18 +108,14 @@ interface PaginatedResponse<T> {
  */
 export class KalshiClient {
   private readonly baseUrl: string;
-  private readonly headers: Headers;
   private readonly limiter: RateLimiter;
+  private readonly http: HttpClient;
 
   constructor(options: KalshiClientOptions = {}) {
     this.baseUrl = options.demo
       ? "https://demo-api.kalshi.co/trade-api/v2"
       : "https://api.elections.kalshi.com/trade-api/v2";
 
-    this.headers = new Headers({
-      "Content-Type": "application/json",
-    });
-
     // Rate limit: 300ms between requests (~3 req/sec)
     // Plus 1 second delay between series in secmaster sync
     this.limiter = new RateLimiter(
@@ -123,6 +123,15 @@ export class KalshiClient {
       options.maxRetries ?? 10,
       5000 // Min retry wait (5s) with exponential backoff
     );
+
+    const { maxRetries, minRetryWaitMs } = this.limiter.retryConfig;
+    this.http = new HttpClient({
+      name: "Kalshi",
+      headers: { "Content-Type": "application/json" },
+      retry: { maxRetries, initialDelayMs: minRetryWaitMs },
+      minRateLimitWaitMs: minRetryWaitMs,
+      budget: options.budget,
+    });
   }
 
   /**
@@ -130,38 +139,11 @@ export class KalshiClient {
    */
   private async fetch<T>(path: string): Promise<T> {
     await this.limiter.wait();
-
-    const { maxRetries, minRetryWaitMs } = this.limiter.retryConfig;
-
-    return retry(
-      async () => {
-        const url = `${this.baseUrl}${path}`;
-
-        const res = await fetch(url, {
-          headers: this.headers,
-        });
-        this.limiter.markRequest();
-
-        if (res.status === 429) {
-          const retryAfter = res.headers.get("Retry-After");
-          const wait = Math.max(minRetryWaitMs, parseInt(retryAfter || "5") * 1000);
-          console.log(`  [API] 429 rate limited, waiting ${wait}ms`);
-          throw new Error(`Rate limited, retry after ${wait}ms`);
-        }
-
-        if (!res.ok) {
-          const text = await res.text();
-          throw new Error(`API error ${res.status}: ${text}`);
-        }
-
-        return res.json();
-      },
-      {
-        maxRetries,
-        initialDelayMs: minRetryWaitMs,
-        shouldRetry: (e) => e.message.includes("Rate limited"),
-      }
-    );
+    try {
+      return await this.http.getJson<T>(`${this.baseUrl}${path}`);
+    } finally {
+      this.limiter.markRequest();
+    }
   }
 
   /**
@@ -290,7 +272,7 @@ export class KalshiClient {
       );
       return fromKalshiEvent(data.event);
     } catch (e) {
-      if ((e as Error).message.includes("404")) {
+      if (e instanceof HttpError && e.status === 404) {
         return null;
       }
       throw e;
@@ -307,7 +289,7 @@ export class KalshiClient {
       );
       return fromKalshiMarket(data.market);
     } catch (e) {
-      if ((e as Error).message.includes("404")) {
+      if (e instanceof HttpError && e.status === 404) {
         return null;
       }
       throw e;
@@ -489,6 +471,35 @@ export class KalshiClient {
     }
     return allTrades;
   }
+
+  /**
+   * Fetch candlesticks for a market over a time range.
+   * Kalshi returns at most 5000 periods per request, so the range is split.
+   * @param seriesTicker - Series ticker (e.g., 'KXBTCD')
+   * @param ticker - Market ticker
+   * @param startTs - Range start (Unix seconds)
+   * @param endTs - Range end (Unix seconds)
+   * @param periodInterval - Candle length in minutes: 1, 60 or 1440
+   */
+  async fetchCandlesticks(
+    seriesTicker: string,
+    ticker: string,
+    startTs: number,
+    endTs: number,
+    periodInterval: 1 | 60 | 1440 = 1
+  ): Promise<KalshiCandlestick[]> {
+    const maxSpan = 5000 * periodInterval * 60;
+    const candles: KalshiCandlestick[] = [];
+    for (let from = startTs; from < endTs; from += maxSpan) {
+      const to = Math.min(endTs, from + maxSpan);
+      const data = await this.fetch<{ candlesticks: KalshiCandlestick[] }>(
+        `/series/${encodeURIComponent(seriesTicker)}/markets/${encodeURIComponent(ticker)}/candlesticks` +
+          `?start_ts=${from}&end_ts=${to}&period_interval=${periodInterval}`
+      );
+      candles.push(...(data.candlesticks || []));
+    }
+    return candles;
+  }
 }
 
 /**
@@ -505,9 +516,45 @@ export interface KalshiTrade {
 }
 
 /**
- * Create a Kalshi client (no auth needed for public read-only endpoints)
+ * OHLC for one side of the book (cents)
+ */
+export interface KalshiOhlc {
+  open: number | null;
+  high: number | null;
+  low: number | null;
+  close: number | null;
+}
+
+/**
+ * Candlestick from Kalshi API
+ */
+export interface KalshiCandlestick {
+  /** Period end (Unix seconds) */
+  end_period_ts: number;
+  yes_bid: KalshiOhlc;
+  yes_ask: KalshiOhlc;
+  price: KalshiOhlc & { mean?: number | null; previous?: number | null };
+  volume: number;
+  open_interest: number;
+}
+
+/** Default Kalshi read budget: half the basic tier's 20 reads/sec */
+export const DEFAULT_KALSHI_RATE_BUDGET = "10/1";
+
+/**
+ * Create a Kalshi client (no auth needed for public read-only endpoints),
+ * configured from the environment. KALSHI_RATE_BUDGET ("<requests>/<seconds>")
+ * sets the request budget; with RATE_BUDGET_REDIS_URL set, every client on the
+ * same budget key shares it across processes.
  */
 export function createKalshiClient(): KalshiClient {
   const demo = Deno.env.get("KALSHI_DEMO") === "true";
-  return new KalshiClient({ demo });
+  const { limit, windowMs } = parseRateBudget(Deno.env.get("KALSHI_RATE_BUDGET") || DEFAULT_KALSHI_RATE_BUDGET);
+  const budget = new RateBudget({
+    key: demo ? "kalshi-demo" : "kalshi",
+    limit,
+    windowMs,
+    counter: budgetCounterFromEnv(),
+  });
+  return new KalshiClient({ demo, budget });
 }
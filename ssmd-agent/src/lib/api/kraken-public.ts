@@ -1,6 +1,9 @@
 /**
  * Minimal Kraken public REST client for trade fetching (DQ reconciliation)
  */
+import { HttpClient } from "../httpx/mod.ts";
+
+const http = new HttpClient({ name: "Kraken trades" });
 
 const KRAKEN_TRADES_URL = "https://api.kraken.com/0/public/Trades";
 
@@ -25,13 +28,8 @@ export async function fetchKrakenTrades(
   const params = new URLSearchParams({ pair });
   if (since) params.set("since", since);
 
-  const res = await fetch(`${KRAKEN_TRADES_URL}?${params}`, {
-    signal: AbortSignal.timeout(30000),
-  });
-  if (!res.ok) {
-    throw new Error(`Kraken trades API error: ${res.status}`);
-  }
-  const data = await res.json();
+  // deno-lint-ignore no-explicit-any
+  const data = await http.getJson<any>(`${KRAKEN_TRADES_URL}?${params}`);
   if (data.error?.length > 0) {
     throw new Error(`Kraken API errors: ${data.error.join(", ")}`);
   }
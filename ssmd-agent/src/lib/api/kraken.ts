@@ -0,0 +1,185 @@
+/**
+ * Kraken public REST client for secmaster reference data: spot asset pairs
+ * with ticker metadata, and Futures perpetual instruments with tickers.
+ */
+import { HttpClient, type HttpClientOptions } from "../httpx/mod.ts";
+import { budgetCounterFromEnv, parseRateBudget, RateBudget } from "../utils/rate-budget.ts";
+
+const KRAKEN_SPOT_BASE_URL = "https://api.kraken.com/0/public";
+const KRAKEN_FUTURES_BASE_URL = "https://futures.kraken.com/derivatives/api/v3";
+
+/** Pairs per /Ticker request; Kraken accepts a comma-separated list */
+const TICKER_BATCH_SIZE = 50;
+
+interface KrakenSpotResponse {
+  error: string[];
+  result: Record<string, KrakenAssetPair>;
+}
+
+export interface KrakenAssetPair {
+  altname: string;
+  wsname?: string;
+  aclass_base: string;
+  base: string;
+  aclass_quote: string;
+  quote: string;
+  lot: string;
+  pair_decimals: number;
+  lot_decimals: number;
+  lot_multiplier: number;
+  fees: number[][];
+  fees_maker?: number[][];
+  fee_volume_currency: string;
+  margin_call: number;
+  margin_stop: number;
+  ordermin?: string;
+  costmin?: string;
+  tick_size?: string;
+  status: string;
+}
+
+interface KrakenFuturesInstrumentsResponse {
+  result: string;
+  instruments: KrakenFuturesInstrument[];
+}
+
+export interface KrakenFuturesInstrument {
+  symbol: string;
+  type: string;
+  underlying: string;
+  tickSize: number;
+  contractSize: number;
+  tradeable: boolean;
+  suspended?: boolean;
+  openingDate?: string;
+  feeScheduleUid?: string;
+  marginLevels?: Array<{ contracts: number; initialMargin: number; maintenanceMargin: number }>;
+  maxPositionSize?: number;
+  tags?: string[];
+  contractValueTradePrecision?: number;
+}
+
+interface KrakenFuturesTickersResponse {
+  result: string;
+  tickers: KrakenFuturesTicker[];
+}
+
+export interface KrakenFuturesTicker {
+  symbol: string;
+  bid: number;
+  ask: number;
+  last: number;
+  vol24h: number;
+  markPrice: number;
+  indexPrice?: number;
+  fundingRate?: number;
+  fundingRatePrediction?: number;
+  openInterest?: number;
+  suspended?: boolean;
+  tag?: string;
+  pair?: string;
+}
+
+/** Spot ticker from /0/public/Ticker (values are decimal strings) */
+export interface KrakenSpotTicker {
+  /** Ask [price, whole lot volume, lot volume] */
+  a: string[];
+  /** Bid [price, whole lot volume, lot volume] */
+  b: string[];
+  /** Last trade closed [price, lot volume] */
+  c: string[];
+  /** Volume [today, last 24 hours] */
+  v: string[];
+}
+
+interface KrakenSpotTickerResponse {
+  error: string[];
+  result: Record<string, KrakenSpotTicker>;
+}
+
+/** Spot ticker fields stored on a pair */
+export interface SpotTickerFields {
+  bid: string | null;
+  ask: string | null;
+  lastPrice: string | null;
+  volume24h: string | null;
+}
+
+export function parseSpotTicker(ticker: KrakenSpotTicker | undefined): SpotTickerFields {
+  return {
+    bid: ticker?.b?.[0] ?? null,
+    ask: ticker?.a?.[0] ?? null,
+    lastPrice: ticker?.c?.[0] ?? null,
+    volume24h: ticker?.v?.[1] ?? null,
+  };
+}
+
+function checkErrors(api: string, errors: string[] | undefined): void {
+  if (errors && errors.length > 0) {
+    throw new Error(`${api} errors: ${errors.join(", ")}`);
+  }
+}
+
+export interface KrakenClientOptions {
+  /** Per-request timeout (default: 30s) */
+  timeoutMs?: number;
+  /** Request budget shared by the spot and futures endpoints (default: none) */
+  budget?: RateBudget;
+  /** Injectable for tests */
+  fetch?: HttpClientOptions["fetch"];
+}
+
+export class KrakenClient {
+  private readonly spot: HttpClient;
+  private readonly futures: HttpClient;
+
+  constructor(options: KrakenClientOptions = {}) {
+    const shared = { timeoutMs: options.timeoutMs ?? 30_000, budget: options.budget, fetch: options.fetch };
+    this.spot = new HttpClient({ name: "Kraken", ...shared });
+    this.futures = new HttpClient({ name: "Kraken Futures", ...shared });
+  }
+
+  /** All spot asset pairs keyed by Kraken pair id (e.g. XXBTZUSD) */
+  async fetchAssetPairs(): Promise<Record<string, KrakenAssetPair>> {
+    const data = await this.spot.getJson<KrakenSpotResponse>(`${KRAKEN_SPOT_BASE_URL}/AssetPairs`);
+    checkErrors("Kraken API", data.error);
+    return data.result;
+  }
+
+  /** Spot tickers for the given pair ids, fetched in batches */
+  async fetchSpotTickers(pairIds: string[]): Promise<Record<string, KrakenSpotTicker>> {
+    const tickers: Record<string, KrakenSpotTicker> = {};
+    for (let i = 0; i < pairIds.length; i += TICKER_BATCH_SIZE) {
+      const batch = pairIds.slice(i, i + TICKER_BATCH_SIZE);
+      const params = new URLSearchParams({ pair: batch.join(",") });
+      const data = await this.spot.getJson<KrakenSpotTickerResponse>(`${KRAKEN_SPOT_BASE_URL}/Ticker?${params}`);
+      checkErrors("Kraken API", data.error);
+      Object.assign(tickers, data.result);
+    }
+    return tickers;
+  }
+
+  async fetchFuturesInstruments(): Promise<KrakenFuturesInstrument[]> {
+    const data = await this.futures.getJson<KrakenFuturesInstrumentsResponse>(`${KRAKEN_FUTURES_BASE_URL}/instruments`);
+    return data.instruments;
+  }
+
+  async fetchFuturesTickers(): Promise<KrakenFuturesTicker[]> {
+    const data = await this.futures.getJson<KrakenFuturesTickersResponse>(`${KRAKEN_FUTURES_BASE_URL}/tickers`);
+    return data.tickers;
+  }
+}
+
+/** Default Kraken public budget: the spot REST API allows about one call per second */
+export const DEFAULT_KRAKEN_RATE_BUDGET = "1/1";
+
+/**
+ * Client configured from the environment. KRAKEN_RATE_BUDGET ("<requests>/<seconds>")
+ * sets the request budget, shared across processes with RATE_BUDGET_REDIS_URL.
+ */
+export function createKrakenClient(): KrakenClient {
+  const { limit, windowMs } = parseRateBudget(Deno.env.get("KRAKEN_RATE_BUDGET") || DEFAULT_KRAKEN_RATE_BUDGET);
+  return new KrakenClient({
+    budget: new RateBudget({ key: "kraken", limit, windowMs, counter: budgetCounterFromEnv() }),
+  });
+}
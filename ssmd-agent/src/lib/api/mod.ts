@@ -2,3 +2,11 @@
  * API client module exports
  */
 export { KalshiClient, createKalshiClient, type KalshiClientOptions } from "./kalshi.ts";
+export {
+  createKrakenClient,
+  KrakenClient,
+  parseSpotTicker,
+  type KrakenAssetPair,
+  type KrakenClientOptions,
+  type KrakenSpotTicker,
+} from "./kraken.ts";
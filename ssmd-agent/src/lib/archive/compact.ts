@@ -0,0 +1,296 @@
+/**
+ * Compaction: merge a day's rotated JSONL.gz archive files into parquet
+ * partitioned by ticker and sorted by received time, record it in
+ * manifest.json, and optionally delete the originals once the parquet has
+ * been checked against them. The day's ticker index is rebuilt on the way,
+ * since every line is read anyway. Used by `ssmd data compact`, which the
+ * ArchiverSchedule compaction CronJob runs daily.
+ */
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { Storage } from "@google-cloud/storage";
+import type { DuckDBConnection } from "@duckdb/node-api";
+import { type DedupKeys, isDuplicate, newDedupWindow, nextDedupFile } from "./dedup.ts";
+import { archiveDir, archiveFiles, archiveStream, toLines } from "./files.ts";
+import { type CompactedFile, loadArchiveManifest, type ManifestCompaction, saveArchiveManifest } from "./manifest.ts";
+import { detectMessageType, extractTicker, sanitizeToken } from "./messages.ts";
+import { indexLine, newFileIndex, newTickerIndex, saveTickerIndex, type TickerIndex } from "./ticker-index.ts";
+
+/** Parquet goes under <date>/compacted/ticker_key=<key>/, next to the JSONL files */
+export const COMPACTED_DIR = "compacted";
+
+/** Partition for lines without a ticker (control frames, unparseable lines) */
+export const NO_TICKER_KEY = "_none";
+
+export const COMPACTION_PARTITION = "ticker_key";
+export const COMPACTION_SORT = ["ticker", "received_at", "nats_seq"];
+
+/** Rows buffered before each write to the staging file */
+const WRITE_BATCH_LINES = 5000;
+
+/** One archive line as a compacted row; the raw JSON is kept unchanged in line */
+export interface CompactionRow {
+  ticker: string | null;
+  /** Ticker made safe for a directory name, as for NATS subjects */
+  ticker_key: string;
+  type: string | null;
+  /** _received_at, microseconds since epoch */
+  received_at: number | null;
+  nats_seq: number | null;
+  line: string;
+}
+
+/** Parquet column types (DuckDB) of a CompactionRow */
+const ROW_COLUMNS =
+  "{'ticker': 'VARCHAR', 'ticker_key': 'VARCHAR', 'type': 'VARCHAR', 'received_at': 'BIGINT', 'nats_seq': 'BIGINT', 'line': 'VARCHAR'}";
+
+export interface CompactOptions {
+  deleteOriginals?: boolean;
+  /** Leave duplicate messages out of the parquet (see dedup.ts) */
+  dedup?: boolean;
+  /** dedup_key fields by message type, for dedup */
+  keys?: DedupKeys;
+  dryRun?: boolean;
+}
+
+export interface CompactReport {
+  feed: string;
+  date: string;
+  /** Archive files compacted, by name */
+  sources: string[];
+  records: number;
+  /** Duplicate lines left out with dedup */
+  duplicates: number;
+  files: CompactedFile[];
+  /** Whether the parquet was uploaded and recorded in the manifest */
+  uploaded: boolean;
+  /** Whether ticker-index.json was written (not when the originals are deleted) */
+  indexed: boolean;
+  /** Original JSONL files deleted */
+  deleted: number;
+}
+
+/**
+ * The local date before `now`, as YYYY-MM-DD. The compaction CronJob sets TZ
+ * to the trading day timezone, so this is the trading day that just ended.
+ */
+export function previousDay(now = new Date()): string {
+  const d = new Date(now.getFullYear(), now.getMonth(), now.getDate() - 1);
+  return `${d.getFullYear()}-${String(d.getMonth() + 1).padStart(2, "0")}-${String(d.getDate()).padStart(2, "0")}`;
+}
+
+/**
+ * The compacted row for an archive line, or null for a blank line. Lines that
+ * do not parse are kept under NO_TICKER_KEY so compaction never drops data.
+ */
+export function compactionRow(feed: string, line: string): CompactionRow | null {
+  if (line.trim() === "") return null;
+  let json: unknown = null;
+  try {
+    json = JSON.parse(line);
+  } catch {
+    // kept as an untyped row below
+  }
+  if (json === null || typeof json !== "object" || Array.isArray(json)) {
+    return { ticker: null, ticker_key: NO_TICKER_KEY, type: null, received_at: null, nats_seq: null, line };
+  }
+  const record = json as Record<string, unknown>;
+  const ticker = extractTicker(feed, record);
+  return {
+    ticker,
+    ticker_key: (ticker && sanitizeToken(ticker)) || NO_TICKER_KEY,
+    type: detectMessageType(feed, record),
+    received_at: typeof record._received_at === "number" ? record._received_at : null,
+    nats_seq: typeof record._nats_seq === "number" ? record._nats_seq : null,
+    line,
+  };
+}
+
+/**
+ * Stage every line of the archive files as a CompactionRow, checking each
+ * file's line count against its manifest entry, and index them into index.
+ * With dedup, lines repeating one already staged are left out. Returns the
+ * source names, total rows and duplicates left out.
+ */
+async function stageRows(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  paths: string[],
+  records: Map<string, number>,
+  rowsPath: string,
+  index: TickerIndex,
+  dedup = false,
+  keys: DedupKeys = {},
+): Promise<{ sources: string[]; rows: number; duplicates: number }> {
+  const encoder = new TextEncoder();
+  const file = await Deno.open(rowsPath, { write: true, create: true, truncate: true });
+  const window = newDedupWindow(feed, keys);
+  const sources: string[] = [];
+  let rows = 0;
+  let duplicates = 0;
+  try {
+    for (const path of paths) {
+      nextDedupFile(window);
+      const name = path.split("/").pop() ?? path;
+      const expected = records.get(name);
+      if (expected === undefined) {
+        throw new Error(`${name} is not in manifest.json; run ssmd data verify --repair first`);
+      }
+      let lines = 0;
+      let skipped = 0;
+      let batch: string[] = [];
+      const fileIndex = index.files[name] = newFileIndex();
+      for await (const line of toLines(archiveStream(storage, bucket, path))) {
+        indexLine(fileIndex, feed, line, index.merge_gap_bytes);
+        const row = compactionRow(feed, line);
+        if (!row) continue;
+        lines++;
+        if (dedup && isDuplicate(window, line)) {
+          skipped++;
+          continue;
+        }
+        batch.push(JSON.stringify(row));
+        if (batch.length >= WRITE_BATCH_LINES) {
+          await file.write(encoder.encode(batch.join("\n") + "\n"));
+          batch = [];
+        }
+      }
+      if (batch.length > 0) await file.write(encoder.encode(batch.join("\n") + "\n"));
+      if (lines !== expected) {
+        throw new Error(`${name} has ${lines} records but manifest.json lists ${expected}; run ssmd data verify`);
+      }
+      sources.push(name);
+      rows += lines - skipped;
+      duplicates += skipped;
+    }
+  } finally {
+    file.close();
+  }
+  return { sources, rows, duplicates };
+}
+
+/**
+ * Compact a date's JSONL archive into ticker-partitioned parquet with the
+ * given DuckDB connection. The parquet is built and checked locally (row
+ * counts against the manifest), uploaded under <date>/compacted/, and
+ * recorded in manifest.json; with deleteOriginals the JSONL files are
+ * deleted after the manifest is written. With dedup, duplicate lines are left
+ * out of the parquet and counted. A dry run builds and checks the
+ * parquet without uploading. Returns null when the date has no manifest.
+ */
+export async function compactArchive(
+  storage: Storage,
+  connection: DuckDBConnection,
+  bucket: string,
+  feed: string,
+  date: string,
+  opts: CompactOptions = {},
+): Promise<CompactReport | null> {
+  const stored = await loadArchiveManifest(storage, bucket, feed, date);
+  if (!stored) return null;
+  const { manifest, generation } = stored;
+  const dateDir = archiveDir(feed, date);
+
+  const paths = await archiveFiles(storage, bucket, feed, date);
+  if (paths.length === 0) {
+    const previous = manifest.compaction ? ` (compacted at ${manifest.compaction.compacted_at})` : "";
+    throw new Error(`No archived JSONL files at gs://${bucket}/${dateDir}/${previous}`);
+  }
+
+  const dir = await Deno.makeTempDir({ prefix: "ssmd-compact-" });
+  try {
+    const rowsPath = join(dir, "rows.jsonl");
+    const records = new Map(manifest.files.map((f) => [f.name, f.records]));
+    const index = newTickerIndex(feed, date);
+    const { sources, rows, duplicates } = await stageRows(
+      storage,
+      bucket,
+      feed,
+      paths,
+      records,
+      rowsPath,
+      index,
+      opts.dedup,
+      opts.keys,
+    );
+
+    const outDir = join(dir, COMPACTED_DIR);
+    await connection.run(
+      `COPY (SELECT * FROM read_json('${rowsPath}', format = 'newline_delimited', columns = ${ROW_COLUMNS}) ` +
+        `ORDER BY ${COMPACTION_SORT.join(", ")}) ` +
+        `TO '${outDir}' (FORMAT PARQUET, COMPRESSION ZSTD, PARTITION_BY (${COMPACTION_PARTITION}))`,
+    );
+    const result = await connection.run(
+      `SELECT filename, COUNT(*) FROM read_parquet('${outDir}/**/*.parquet', filename = true, hive_partitioning = false) ` +
+        "GROUP BY filename ORDER BY filename",
+    );
+    const files: CompactedFile[] = [];
+    for (const [filename, count] of await result.getRows()) {
+      const local = String(filename);
+      files.push({ name: local.slice(outDir.length + 1), records: Number(count), bytes: (await Deno.stat(local)).size });
+    }
+    const written = files.reduce((n, f) => n + f.records, 0);
+    if (written !== rows) {
+      throw new Error(`Compacted parquet holds ${written} rows, but the archive files have ${rows}`);
+    }
+
+    const report: CompactReport = {
+      feed,
+      date,
+      sources,
+      records: rows,
+      duplicates,
+      files,
+      uploaded: false,
+      indexed: false,
+      deleted: 0,
+    };
+    if (opts.dryRun) return report;
+
+    const prefix = `${dateDir}/${COMPACTED_DIR}/`;
+    const b = storage.bucket(bucket);
+    const [previous] = await b.getFiles({ prefix });
+    for (const file of files) {
+      const [uploaded] = await b.upload(join(outDir, file.name), { destination: `${prefix}${file.name}` });
+      const [metadata] = await uploaded.getMetadata();
+      if (Number(metadata.size) !== file.bytes) {
+        throw new Error(`Uploaded ${prefix}${file.name} is ${metadata.size} bytes, expected ${file.bytes}`);
+      }
+    }
+    // Partitions from an earlier compaction whose tickers are no longer present
+    const current = new Set(files.map((f) => `${prefix}${f.name}`));
+    for (const stale of previous.filter((o) => !current.has(o.name))) {
+      await stale.delete({ ignoreNotFound: true });
+    }
+
+    const compaction: ManifestCompaction = {
+      compacted_at: new Date().toISOString(),
+      path: COMPACTED_DIR,
+      partition_by: COMPACTION_PARTITION,
+      sort_by: COMPACTION_SORT,
+      sources,
+      records: rows,
+      ...(opts.dedup && { duplicates_removed: duplicates }),
+      files,
+      originals_deleted: opts.deleteOriginals ?? false,
+    };
+    manifest.compaction = compaction;
+    // Written before the deletes: verify treats the listed sources as expected missing
+    await saveArchiveManifest(storage, bucket, feed, date, manifest, generation);
+    report.uploaded = true;
+
+    if (opts.deleteOriginals) {
+      for (const path of paths) {
+        await b.file(path).delete({ ignoreNotFound: true });
+        report.deleted++;
+      }
+    } else {
+      // The index points into the JSONL files, so it is only kept alongside them
+      await saveTickerIndex(storage, bucket, index);
+      report.indexed = true;
+    }
+    return report;
+  } finally {
+    await Deno.remove(dir, { recursive: true });
+  }
+}
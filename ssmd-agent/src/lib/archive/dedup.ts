@@ -0,0 +1,255 @@
+/**
+ * Deduplication after the fact: NATS redelivery can archive the same message
+ * twice, with different _received_at and _nats_seq. Lines are keyed on their
+ * type's dedup_key fields (schema-versions.json, passed in as DedupKeys), or
+ * on (ticker, exchange timestamp, type, hash of the payload without the
+ * archiver's fields) for types without one; counts are reported per file,
+ * and files can be rewritten without their duplicates. The key and window
+ * are shared with verify (duplicate counts) and compact (dedup option). Used
+ * by `ssmd data dedup`.
+ */
+import { createHash } from "node:crypto";
+import { Storage } from "@google-cloud/storage";
+import { archiveFiles, archiveStream, toLines } from "./files.ts";
+import { loadArchiveManifest, recomputeTotals, saveArchiveManifest } from "./manifest.ts";
+import { detectMessageType, extractTicker } from "./messages.ts";
+import { checkArchive } from "./verify.ts";
+
+/** dedup_key field paths by message type; `[]` steps into every element of an array */
+export type DedupKeys = Record<string, string[]>;
+
+/** Fields the archiver adds to each message, which differ between redeliveries */
+const ARCHIVER_FIELDS = ["_received_at", "_nats_seq"];
+
+/** Exchange timestamp of a message, as written by the feed, or null if it has none */
+export function messageTimestamp(feed: string, json: Record<string, unknown>): string | null {
+  const value = (v: unknown) => (typeof v === "string" || typeof v === "number" ? String(v) : null);
+  switch (feed) {
+    case "binance":
+      return value((json.data as Record<string, unknown> | undefined)?.E);
+    case "kalshi":
+      return value((json.msg as Record<string, unknown> | undefined)?.ts);
+    case "kraken":
+    case "kraken-spot": {
+      const data = json.data;
+      return Array.isArray(data) ? value(data[0]?.timestamp) : null;
+    }
+    case "kraken-futures":
+      return value(json.time);
+    case "polymarket":
+      return value(json.timestamp);
+    default:
+      return null;
+  }
+}
+
+/** Values at a field path, or null when a step is missing or an array is empty */
+function fieldValues(record: Record<string, unknown>, path: string): unknown[] | null {
+  let values: unknown[] = [record];
+  for (const part of path.split(".")) {
+    const each = part.endsWith("[]");
+    const key = each ? part.slice(0, -2) : part;
+    const next: unknown[] = [];
+    for (const value of values) {
+      const child = value !== null && typeof value === "object" ? (value as Record<string, unknown>)[key] : undefined;
+      if (child === undefined || (each && !Array.isArray(child))) return null;
+      if (each) next.push(...(child as unknown[]));
+      else next.push(child);
+    }
+    values = next;
+  }
+  return values.length > 0 ? values : null;
+}
+
+/**
+ * Dedup key of a parsed archive record. With dedup_key fields for its type,
+ * the key is the type and those fields' values, so a resend with a corrected
+ * price is still the same message; otherwise, or when a field is missing,
+ * (ticker, exchange timestamp, type, SHA-256 of the payload without the
+ * archiver's fields). Null for records without a ticker (control frames),
+ * which are never duplicates.
+ */
+export function recordDedupKey(feed: string, record: Record<string, unknown>, keys: DedupKeys = {}): string | null {
+  const ticker = extractTicker(feed, record);
+  if (!ticker) return null;
+  const type = detectMessageType(feed, record);
+  const fields = type ? keys[type] : undefined;
+  if (type && fields?.length) {
+    const values = fields.map((path) => fieldValues(record, path));
+    if (values.every((v) => v !== null)) return [type, JSON.stringify(values)].join("\u0000");
+  }
+  const payload = { ...record };
+  for (const field of ARCHIVER_FIELDS) delete payload[field];
+  const hash = createHash("sha256").update(JSON.stringify(payload)).digest("hex");
+  return [ticker, messageTimestamp(feed, record) ?? "", type ?? "", hash].join("\u0000");
+}
+
+/**
+ * Dedup key of an archive line (see recordDedupKey). Null for lines that are
+ * never treated as duplicates: blank, unparseable, or without a ticker.
+ */
+export function dedupKey(feed: string, line: string, keys: DedupKeys = {}): string | null {
+  if (line.trim() === "") return null;
+  let json: unknown;
+  try {
+    json = JSON.parse(line);
+  } catch {
+    return null;
+  }
+  if (json === null || typeof json !== "object" || Array.isArray(json)) return null;
+  return recordDedupKey(feed, json as Record<string, unknown>, keys);
+}
+
+/**
+ * Keys seen in the current file and the one before it. Redelivery repeats a
+ * message within seconds, so at most across one rotation; keeping two files
+ * bounds memory for a whole day.
+ */
+export interface DedupWindow {
+  feed: string;
+  keys: DedupKeys;
+  previous: Set<string>;
+  current: Set<string>;
+}
+
+export function newDedupWindow(feed: string, keys: DedupKeys = {}): DedupWindow {
+  return { feed, keys, previous: new Set(), current: new Set() };
+}
+
+/** Start the next file: its lines are still compared with the last file's */
+export function nextDedupFile(window: DedupWindow): void {
+  window.previous = window.current;
+  window.current = new Set();
+}
+
+function seen(window: DedupWindow, key: string | null): boolean {
+  if (key === null) return false;
+  if (window.current.has(key) || window.previous.has(key)) return true;
+  window.current.add(key);
+  return false;
+}
+
+/** Whether a line repeats one already seen in the window; records it if not */
+export function isDuplicate(window: DedupWindow, line: string): boolean {
+  return seen(window, dedupKey(window.feed, line, window.keys));
+}
+
+/** isDuplicate for a record that is already parsed */
+export function isDuplicateRecord(window: DedupWindow, record: Record<string, unknown>): boolean {
+  return seen(window, recordDedupKey(window.feed, record, window.keys));
+}
+
+export interface FileDuplicates {
+  name: string;
+  records: number;
+  duplicates: number;
+  /** Whether the file was rewritten without its duplicates */
+  rewritten: boolean;
+}
+
+export interface DedupReport {
+  feed: string;
+  date: string;
+  files: FileDuplicates[];
+  duplicates: number;
+  /** Whether manifest.json was updated for rewritten files */
+  written: boolean;
+}
+
+export interface DedupOptions {
+  rewrite?: boolean;
+  dryRun?: boolean;
+  /** dedup_key fields by message type; without them every type uses the payload hash */
+  keys?: DedupKeys;
+  /** Called as each file is read, for progress output */
+  onFile?: (file: FileDuplicates) => void;
+}
+
+/** Gzip lines into a local file */
+async function writeGzip(lines: AsyncIterable<string>, path: string): Promise<void> {
+  const file = await Deno.open(path, { write: true, create: true, truncate: true });
+  await ReadableStream.from(lines)
+    .pipeThrough(new TextEncoderStream())
+    .pipeThrough(new CompressionStream("gzip"))
+    .pipeTo(file.writable);
+}
+
+/**
+ * Count duplicates in a date's archive files, and with rewrite replace each
+ * file that has any with a copy without them (unless dryRun), updating its
+ * manifest entry. An upload only replaces the generation that was read, and
+ * the manifest write is conditional on the manifest generation read.
+ */
+export async function dedupArchive(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+  opts: DedupOptions = {},
+): Promise<DedupReport> {
+  const paths = await archiveFiles(storage, bucket, feed, date);
+  const rewrite = opts.rewrite && !opts.dryRun;
+  const stored = rewrite ? await loadArchiveManifest(storage, bucket, feed, date) : null;
+  if (rewrite && !stored) throw new Error("rewrite needs the date's manifest.json to update");
+
+  const window = newDedupWindow(feed, opts.keys);
+  const files: FileDuplicates[] = [];
+  const tmp = await Deno.makeTempFile({ prefix: "ssmd-dedup-", suffix: ".jsonl.gz" });
+  try {
+    for (const path of paths) {
+      nextDedupFile(window);
+      const name = path.split("/").pop() ?? path;
+      const counts = { records: 0, duplicates: 0 };
+      const [metadata] = await storage.bucket(bucket).file(path).getMetadata();
+
+      async function* kept(): AsyncGenerator<string> {
+        for await (const line of toLines(archiveStream(storage, bucket, path))) {
+          if (line.trim() !== "") counts.records++;
+          if (isDuplicate(window, line)) {
+            counts.duplicates++;
+            continue;
+          }
+          yield `${line}\n`;
+        }
+      }
+
+      if (rewrite) {
+        await writeGzip(kept(), tmp);
+      } else {
+        for await (const _ of kept()) { /* counting only */ }
+      }
+
+      const file: FileDuplicates = { name, records: counts.records, duplicates: counts.duplicates, rewritten: false };
+      if (stored && counts.duplicates > 0) {
+        await storage.bucket(bucket).upload(tmp, {
+          destination: path,
+          contentType: metadata.contentType,
+          preconditionOpts: { ifGenerationMatch: String(metadata.generation) },
+        });
+        const check = await checkArchive((await Deno.open(tmp)).readable);
+        stored.manifest.files = stored.manifest.files.map((entry) =>
+          entry.name !== name ? entry : {
+            ...entry,
+            records: check.records,
+            bytes: check.bytes,
+            sha256: check.sha256,
+            duplicates_removed: (entry.duplicates_removed ?? 0) + counts.duplicates,
+          }
+        );
+        file.rewritten = true;
+      }
+      files.push(file);
+      opts.onFile?.(file);
+    }
+  } finally {
+    await Deno.remove(tmp);
+  }
+
+  let written = false;
+  if (stored && files.some((f) => f.rewritten)) {
+    recomputeTotals(stored.manifest);
+    await saveArchiveManifest(storage, bucket, feed, date, stored.manifest, stored.generation);
+    written = true;
+  }
+  return { feed, date, files, duplicates: files.reduce((n, f) => n + f.duplicates, 0), written };
+}
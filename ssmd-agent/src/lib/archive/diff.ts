@@ -0,0 +1,117 @@
+/**
+ * Day-over-day comparison of an archived feed: manifest totals and per-ticker
+ * trade counts, flagging missing tickers and sharp drops. A silent capture
+ * regression after a connector upgrade shows up here first. Used by
+ * `ssmd data diff`.
+ */
+import type { ArchiveManifest } from "./manifest.ts";
+
+/** Totals of one day's archiver manifest */
+export interface ManifestSummary {
+  files: number;
+  records: number;
+  bytes: number;
+  recordsByType: Record<string, number>;
+}
+
+export function summarizeManifest(manifest: ArchiveManifest | null): ManifestSummary {
+  const summary: ManifestSummary = { files: 0, records: 0, bytes: 0, recordsByType: {} };
+  for (const file of manifest?.files ?? []) {
+    summary.files++;
+    summary.records += file.records ?? 0;
+    summary.bytes += file.bytes ?? 0;
+    for (const [type, n] of Object.entries(file.records_by_type ?? {})) {
+      summary.recordsByType[type] = (summary.recordsByType[type] ?? 0) + n;
+    }
+  }
+  return summary;
+}
+
+/** One compared quantity; change is the fractional change, null when before is 0 */
+export interface MetricDiff {
+  metric: string;
+  before: number;
+  after: number;
+  change: number | null;
+  anomalous: boolean;
+}
+
+export function fractionalChange(before: number, after: number): number | null {
+  return before === 0 ? null : (after - before) / before;
+}
+
+function metricDiff(metric: string, before: number, after: number, threshold: number): MetricDiff {
+  const change = fractionalChange(before, after);
+  return { metric, before, after, change, anomalous: change !== null && change <= -threshold };
+}
+
+/** Compare manifest totals; a metric is anomalous when it fell by at least threshold */
+export function diffManifests(before: ManifestSummary, after: ManifestSummary, threshold: number): MetricDiff[] {
+  const diffs = [
+    metricDiff("files", before.files, after.files, threshold),
+    metricDiff("records", before.records, after.records, threshold),
+    metricDiff("bytes", before.bytes, after.bytes, threshold),
+  ];
+  const types = [...new Set([...Object.keys(before.recordsByType), ...Object.keys(after.recordsByType)])].sort();
+  for (const type of types) {
+    diffs.push(metricDiff(`records.${type}`, before.recordsByType[type] ?? 0, after.recordsByType[type] ?? 0, threshold));
+  }
+  return diffs;
+}
+
+/** A ticker's trades on one day */
+export interface TickerStats {
+  ticker: string;
+  trades: number;
+  volume: number;
+}
+
+export interface TickerDrop {
+  ticker: string;
+  before: TickerStats;
+  after: TickerStats;
+  /** Fractional change in trades (negative) */
+  change: number;
+}
+
+export interface TickerDiff {
+  /** Traded at least minTrades times on the first day, absent on the second */
+  missing: TickerStats[];
+  /** Only on the second day */
+  added: TickerStats[];
+  /** Trades fell by at least threshold, largest drop first */
+  drops: TickerDrop[];
+}
+
+/**
+ * Compare per-ticker trades of two days. Tickers that traded fewer than
+ * minTrades times on the first day are left out of missing and drops.
+ */
+export function diffTickers(
+  before: TickerStats[],
+  after: TickerStats[],
+  options: { threshold: number; minTrades: number },
+): TickerDiff {
+  const afterByTicker = new Map(after.map((t) => [t.ticker, t]));
+  const beforeTickers = new Set(before.map((t) => t.ticker));
+  const missing: TickerStats[] = [];
+  const drops: TickerDrop[] = [];
+
+  for (const b of before) {
+    if (b.trades < options.minTrades) continue;
+    const a = afterByTicker.get(b.ticker);
+    if (!a) {
+      missing.push(b);
+      continue;
+    }
+    const change = fractionalChange(b.trades, a.trades)!;
+    if (change <= -options.threshold) {
+      drops.push({ ticker: b.ticker, before: b, after: a, change });
+    }
+  }
+
+  missing.sort((x, y) => y.trades - x.trades);
+  drops.sort((x, y) => x.change - y.change || y.before.trades - x.before.trades);
+  const added = after.filter((t) => !beforeTickers.has(t.ticker)).sort((x, y) => y.trades - x.trades);
+  return { missing, added, drops };
+}
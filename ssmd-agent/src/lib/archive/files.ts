@@ -0,0 +1,111 @@
+/**
+ * Raw archive layout in GCS: ssmd-archiver writes one JSONL.gz file per
+ * 15-minute slot under <feed path>/<date>/, next to the day's manifest.json.
+ */
+import { Storage } from "@google-cloud/storage";
+import { TextLineStream } from "https://deno.land/std@0.224.0/streams/text_line_stream.ts";
+import { FEED_PATHS } from "../duckdb/feed-config.ts";
+import { GcsObjects } from "../gcs/objects.ts";
+
+/** GCS compose accepts at most this many source objects per call */
+export const MAX_COMPOSE_SOURCES = 32;
+
+/** Directory of a feed's archive for one date, without a trailing slash */
+export function archiveDir(feed: string, date: string): string {
+  return `${FEED_PATHS[feed]}/${date}`;
+}
+
+/** The dates from `from` to `to` inclusive (YYYY-MM-DD), for multi-day commands */
+export function dateRange(from: string, to: string): string[] {
+  for (const [flag, value] of [["--from", from], ["--to", to]]) {
+    if (!/^\d{4}-\d{2}-\d{2}$/.test(value) || Number.isNaN(Date.parse(`${value}T00:00:00Z`))) {
+      throw new Error(`${flag} must be a date like 2025-01-01, got '${value}'`);
+    }
+  }
+  const start = Date.parse(`${from}T00:00:00Z`);
+  const end = Date.parse(`${to}T00:00:00Z`);
+  if (end < start) throw new Error("--to must not be before --from");
+  const days = (end - start) / 86_400_000 + 1;
+  return Array.from({ length: days }, (_, i) => new Date(start + i * 86_400_000).toISOString().slice(0, 10));
+}
+
+/**
+ * Group archive object names by 15-minute slot (HHMM), sorted. Restarted
+ * archivers write HHMM-NN.jsonl.gz alongside HHMM.jsonl.gz; those overlap in
+ * time and are kept together in their slot.
+ */
+export function groupArchiveFiles(names: string[]): [string, string[]][] {
+  const slots = new Map<string, string[]>();
+  for (const name of names) {
+    const match = name.split("/").pop()?.match(/^(\d{4})(-\d+)?\.jsonl\.gz$/);
+    if (!match) continue;
+    const files = slots.get(match[1]) ?? [];
+    files.push(name);
+    slots.set(match[1], files);
+  }
+  return [...slots.entries()].sort(([a], [b]) => a.localeCompare(b));
+}
+
+/** A date's archive object names grouped by slot (see groupArchiveFiles) */
+export async function archiveSlots(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+): Promise<[string, string[]][]> {
+  const [objects] = await storage.bucket(bucket).getFiles({ prefix: `${archiveDir(feed, date)}/` });
+  return groupArchiveFiles(objects.map((o: { name: string }) => o.name));
+}
+
+/** A date's archive object names, in slot order */
+export async function archiveFiles(storage: Storage, bucket: string, feed: string, date: string): Promise<string[]> {
+  return (await archiveSlots(storage, bucket, feed, date)).flatMap(([, names]) => names);
+}
+
+/** Stored (compressed) bytes of an archive file, streamed from GCS */
+export function objectStream(storage: Storage, bucket: string, name: string): ReadableStream<Uint8Array> {
+  return new GcsObjects(storage).read(bucket, name);
+}
+
+/** Decompressed bytes of an archive file, streamed from GCS */
+export function archiveStream(storage: Storage, bucket: string, name: string): ReadableStream<Uint8Array> {
+  return objectStream(storage, bucket, name).pipeThrough(new DecompressionStream("gzip"));
+}
+
+/** Decode bytes into lines without their newlines */
+export function toLines(bytes: ReadableStream<Uint8Array>): ReadableStream<string> {
+  return bytes.pipeThrough(new TextDecoderStream()).pipeThrough(new TextLineStream());
+}
+
+/**
+ * Concatenate objects into destination with GCS compose, in rounds of
+ * MAX_COMPOSE_SOURCES through temporary objects when there are more sources.
+ * Composed .jsonl.gz files are multi-member gzip, which gzip readers treat as
+ * one stream. Sources are left in place.
+ */
+export async function composeObjects(
+  storage: Storage,
+  bucket: string,
+  sources: string[],
+  destination: string,
+): Promise<void> {
+  if (sources.length === 0) throw new Error(`No sources to compose into ${destination}`);
+  const b = storage.bucket(bucket);
+  const temps: string[] = [];
+  try {
+    let round = sources;
+    while (round.length > MAX_COMPOSE_SOURCES) {
+      const next: string[] = [];
+      for (let i = 0; i < round.length; i += MAX_COMPOSE_SOURCES) {
+        const temp = `${destination}.compose-${temps.length}`;
+        await b.combine(round.slice(i, i + MAX_COMPOSE_SOURCES), temp);
+        temps.push(temp);
+        next.push(temp);
+      }
+      round = next;
+    }
+    await b.combine(round, destination);
+  } finally {
+    await Promise.all(temps.map((t) => b.file(t).delete({ ignoreNotFound: true })));
+  }
+}
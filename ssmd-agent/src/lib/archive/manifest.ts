@@ -0,0 +1,230 @@
+/**
+ * ssmd-archiver manifest.json: one per feed and date, listing the day's
+ * JSONL.gz files. Types mirror the Rust Manifest/FileEntry structs; fields
+ * this side does not know are kept as read. Besides reading, tools that
+ * rewrite a day's files (verify, compaction, import) create and update
+ * manifests here: add or merge file entries, recompute day-level fields,
+ * mark NATS sequence gaps, and write without clobbering a concurrent writer.
+ */
+import { dirname } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { Storage } from "@google-cloud/storage";
+import { archiveDir } from "./files.ts";
+
+/** Archiver manifest entry (ssmd-archiver FileEntry) */
+export interface ManifestFile {
+  name: string;
+  start: string;
+  end: string;
+  records: number;
+  /** Decompressed size, including the archiver's injected fields */
+  bytes: number;
+  raw_bytes?: number;
+  compression_ratio?: number;
+  nats_start_seq: number;
+  nats_end_seq: number;
+  records_by_type?: Record<string, number>;
+  /** Earliest and latest _received_at in the file (ISO 8601), for pruning time ranges */
+  min_ts?: string;
+  max_ts?: string;
+  /** Distinct tickers in the file */
+  ticker_count?: number;
+  /** Hex SHA-256 of the compressed file; absent for files archived before it was recorded */
+  sha256?: string;
+  /** Duplicate lines removed by ssmd data dedup --rewrite */
+  duplicates_removed?: number;
+  [key: string]: unknown;
+}
+
+/** NATS sequence gap recorded by the archiver (ssmd-archiver Gap) */
+export interface ManifestGap {
+  after_seq: number;
+  missing_count: number;
+  detected_at: string;
+}
+
+/** One parquet file written by compaction, relative to the compaction path */
+export interface CompactedFile {
+  name: string;
+  records: number;
+  bytes: number;
+}
+
+/** Parquet compaction of the day's JSONL files (ssmd data compact) */
+export interface ManifestCompaction {
+  compacted_at: string;
+  /** Directory of the parquet files, relative to the date directory */
+  path: string;
+  partition_by: string;
+  sort_by: string[];
+  /** Archive files compacted, by name */
+  sources: string[];
+  records: number;
+  /** Duplicate lines left out (ssmd data compact --dedup) */
+  duplicates_removed?: number;
+  files: CompactedFile[];
+  /** Whether the sources were deleted; verify then expects them missing */
+  originals_deleted: boolean;
+}
+
+/** ssmd-archiver manifest.json */
+export interface ArchiveManifest {
+  files: ManifestFile[];
+  feed?: string;
+  date?: string;
+  format?: string;
+  rotation_interval?: string;
+  gaps?: ManifestGap[];
+  tickers?: string[];
+  message_types?: string[];
+  /** Field paths identifying one message, by message type (see schema-versions.json) */
+  dedup_keys?: Record<string, string[]>;
+  has_gaps?: boolean;
+  compaction?: ManifestCompaction;
+  [key: string]: unknown;
+}
+
+/** A manifest read from GCS with the generation it was read at */
+export interface StoredManifest {
+  manifest: ArchiveManifest;
+  generation: string;
+}
+
+/** Empty manifest in the shape ssmd-archiver writes */
+export function newArchiveManifest(feed: string, date: string, rotationInterval = "15m"): ArchiveManifest {
+  return {
+    feed,
+    date,
+    format: "jsonl",
+    rotation_interval: rotationInterval,
+    files: [],
+    gaps: [],
+    tickers: [],
+    message_types: [],
+    has_gaps: false,
+  };
+}
+
+/** Add a file entry, replacing any entry of the same name; files stay sorted by name */
+export function addFile(manifest: ArchiveManifest, entry: ManifestFile): void {
+  manifest.files = [...manifest.files.filter((f) => f.name !== entry.name), entry]
+    .sort((a, b) => a.name.localeCompare(b.name));
+}
+
+/**
+ * One entry for files merged into `name` (e.g. composed into one object):
+ * counts and sizes summed, time and sequence bounds widened. The checksum is
+ * dropped since it no longer matches; `ssmd data verify --repair` fills it.
+ */
+export function mergeEntries(name: string, entries: ManifestFile[]): ManifestFile {
+  if (entries.length === 0) throw new Error(`No entries to merge into ${name}`);
+  const starts = entries.map((e) => e.start).sort();
+  const ends = entries.map((e) => e.end).sort();
+  const seqs = entries.filter((e) => e.nats_start_seq > 0);
+  const recordsByType: Record<string, number> = {};
+  for (const e of entries) {
+    for (const [type, n] of Object.entries(e.records_by_type ?? {})) {
+      recordsByType[type] = (recordsByType[type] ?? 0) + n;
+    }
+  }
+  return {
+    name,
+    start: starts[0],
+    end: ends[ends.length - 1],
+    records: entries.reduce((n, e) => n + e.records, 0),
+    bytes: entries.reduce((n, e) => n + e.bytes, 0),
+    nats_start_seq: seqs.length > 0 ? Math.min(...seqs.map((e) => e.nats_start_seq)) : 0,
+    nats_end_seq: seqs.length > 0 ? Math.max(...seqs.map((e) => e.nats_end_seq)) : 0,
+    records_by_type: recordsByType,
+  };
+}
+
+/**
+ * Recompute the day-level fields from the file entries: message_types from
+ * records_by_type, has_gaps from gaps. Ticker names are not in the entries,
+ * so tickers only grows by the ones passed in.
+ */
+export function recomputeTotals(manifest: ArchiveManifest, tickers: Iterable<string> = []): void {
+  const types = new Set(manifest.message_types ?? []);
+  for (const f of manifest.files) {
+    for (const [type, n] of Object.entries(f.records_by_type ?? {})) {
+      if (n > 0) types.add(type);
+    }
+  }
+  manifest.message_types = [...types].sort();
+  manifest.tickers = [...new Set([...(manifest.tickers ?? []), ...tickers])].sort();
+  manifest.has_gaps = (manifest.gaps ?? []).length > 0;
+}
+
+/**
+ * Add a gap for every break in NATS sequence between consecutive files, as
+ * the archiver does for gaps it sees live. Entries without sequences are
+ * skipped, and gaps already recorded are kept once. Returns the gaps added.
+ */
+export function markGaps(manifest: ArchiveManifest, now = new Date()): ManifestGap[] {
+  const gaps = manifest.gaps ?? [];
+  const known = new Set(gaps.map((g) => g.after_seq));
+  const files = manifest.files.filter((f) => f.nats_start_seq > 0).sort((a, b) => a.nats_start_seq - b.nats_start_seq);
+  const added: ManifestGap[] = [];
+  for (let i = 1; i < files.length; i++) {
+    const afterSeq = files[i - 1].nats_end_seq;
+    const missing = files[i].nats_start_seq - afterSeq - 1;
+    if (missing <= 0 || known.has(afterSeq)) continue;
+    added.push({ after_seq: afterSeq, missing_count: missing, detected_at: now.toISOString() });
+    known.add(afterSeq);
+  }
+  manifest.gaps = [...gaps, ...added].sort((a, b) => a.after_seq - b.after_seq);
+  manifest.has_gaps = manifest.gaps.length > 0;
+  return added;
+}
+
+/** Write a local manifest atomically: a .tmp file next to it, then a rename */
+export async function writeManifestFile(path: string, manifest: ArchiveManifest): Promise<void> {
+  await Deno.mkdir(dirname(path), { recursive: true });
+  const tmp = `${path}.tmp`;
+  await Deno.writeTextFile(tmp, JSON.stringify(manifest));
+  await Deno.rename(tmp, path);
+}
+
+export function manifestPath(feed: string, date: string): string {
+  return `${archiveDir(feed, date)}/manifest.json`;
+}
+
+/** Read a date's manifest and its object generation, or null if it has none */
+export async function loadArchiveManifest(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+): Promise<StoredManifest | null> {
+  const path = manifestPath(feed, date);
+  try {
+    const [metadata] = await storage.bucket(bucket).file(path).getMetadata();
+    const generation = String(metadata.generation);
+    const [contents] = await storage.bucket(bucket).file(path, { generation }).download();
+    return { manifest: JSON.parse(contents.toString("utf-8")), generation };
+  } catch (err: unknown) {
+    if ((err as { code?: number }).code === 404) return null;
+    throw err;
+  }
+}
+
+/**
+ * Upload a date's manifest. An object upload replaces the old one atomically,
+ * so GCS needs no temp object; passing the generation it was loaded at makes
+ * the write fail (412) if the archiver or another tool rewrote it since. With
+ * null, the write only succeeds if there is no manifest yet.
+ */
+export async function saveArchiveManifest(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+  manifest: ArchiveManifest,
+  generation?: string | null,
+): Promise<void> {
+  await storage.bucket(bucket).file(manifestPath(feed, date)).save(JSON.stringify(manifest), {
+    contentType: "application/json",
+    resumable: false,
+    ...(generation !== undefined && { preconditionOpts: { ifGenerationMatch: generation ?? 0 } }),
+  });
+}
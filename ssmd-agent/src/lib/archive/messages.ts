@@ -0,0 +1,57 @@
+/**
+ * Message type and ticker of an archived frame, per feed. These follow the
+ * connectors' subject layout (<prefix>.json.<type>.<ticker>) so archive tools
+ * group and route lines the way live consumers see them.
+ */
+
+/** Sanitize a ticker like the Rust sanitize_subject_token */
+export function sanitizeToken(input: string): string {
+  return input.replaceAll("/", "-").replace(/[^A-Za-z0-9_-]/g, "").slice(0, 128);
+}
+
+function str(v: unknown): string | null {
+  return typeof v === "string" ? v : null;
+}
+
+/**
+ * Message type for an archived frame, following ssmd-schemas
+ * detect_message_type. Control frames return null.
+ */
+export function detectMessageType(feed: string, json: Record<string, unknown>): string | null {
+  switch (feed) {
+    case "binance":
+      return str((json.data as Record<string, unknown> | undefined)?.e);
+    case "kalshi":
+      return str(json.type);
+    case "kraken":
+    case "kraken-spot":
+      return json.data === undefined ? null : str(json.channel);
+    case "kraken-futures":
+      return json.event !== undefined ? null : str(json.feed);
+    case "polymarket":
+      return str(json.event_type);
+    default:
+      return null;
+  }
+}
+
+/** Instrument identifier for the subject's ticker token, or null if absent */
+export function extractTicker(feed: string, json: Record<string, unknown>): string | null {
+  switch (feed) {
+    case "binance":
+      return str((json.data as Record<string, unknown> | undefined)?.s);
+    case "kalshi":
+      return str((json.msg as Record<string, unknown> | undefined)?.market_ticker);
+    case "kraken":
+    case "kraken-spot": {
+      const data = json.data;
+      return Array.isArray(data) ? str(data[0]?.symbol) : null;
+    }
+    case "kraken-futures":
+      return str(json.product_id);
+    case "polymarket":
+      return str(json.asset_id) ?? str(json.market);
+    default:
+      return null;
+  }
+}
@@ -0,0 +1,170 @@
+/**
+ * Raw archive module exports
+ */
+export {
+  archiveDir,
+  archiveFiles,
+  archiveSlots,
+  archiveStream,
+  composeObjects,
+  dateRange,
+  groupArchiveFiles,
+  MAX_COMPOSE_SOURCES,
+  objectStream,
+  toLines,
+} from "./files.ts";
+
+export {
+  COMPACTED_DIR,
+  compactArchive,
+  COMPACTION_PARTITION,
+  COMPACTION_SORT,
+  compactionRow,
+  NO_TICKER_KEY,
+  previousDay,
+  type CompactionRow,
+  type CompactOptions,
+  type CompactReport,
+} from "./compact.ts";
+
+export {
+  diffManifests,
+  diffTickers,
+  fractionalChange,
+  summarizeManifest,
+  type ManifestSummary,
+  type MetricDiff,
+  type TickerDiff,
+  type TickerDrop,
+  type TickerStats,
+} from "./diff.ts";
+
+export {
+  addFile,
+  loadArchiveManifest,
+  manifestPath,
+  markGaps,
+  mergeEntries,
+  newArchiveManifest,
+  recomputeTotals,
+  saveArchiveManifest,
+  writeManifestFile,
+  type ArchiveManifest,
+  type CompactedFile,
+  type ManifestCompaction,
+  type ManifestFile,
+  type ManifestGap,
+  type StoredManifest,
+} from "./manifest.ts";
+
+export { detectMessageType, extractTicker, sanitizeToken } from "./messages.ts";
+
+export {
+  parseSpeed,
+  replayArchive,
+  replayDelayMs,
+  toReplayRecord,
+  type ReplayOptions,
+  type ReplayRecord,
+  type ReplayReport,
+} from "./replay.ts";
+
+export {
+  locationUrl,
+  planReplication,
+  replicaDir,
+  replicateDate,
+  REPLICATION_STATUS_FILE,
+  type ObjectInfo,
+  type ReplicaLocation,
+  type ReplicateOptions,
+  type ReplicatedObject,
+  type ReplicationResult,
+  type ReplicationStatus,
+} from "./replicate.ts";
+
+export {
+  fileOverlaps,
+  mergeByTime,
+  parseTimeBound,
+  sampleLines,
+  sampleRange,
+  sampleResolver,
+  type MergeInput,
+  type SampleOptions,
+  type SampleRange,
+} from "./sample.ts";
+
+export {
+  applyFileStats,
+  backfillStats,
+  needsStats,
+  newFileStats,
+  statsLine,
+  type FileStats,
+  type StatsOptions,
+  type StatsReport,
+} from "./stats.ts";
+
+export {
+  buildTickerIndex,
+  DEFAULT_MERGE_GAP_BYTES,
+  indexLine,
+  indexMatches,
+  loadTickerIndex,
+  newFileIndex,
+  newTickerIndex,
+  rangesFor,
+  saveTickerIndex,
+  sliceRanges,
+  TICKER_INDEX_FILE,
+  TICKER_INDEX_VERSION,
+  tickerIndexPath,
+  type FileIndex,
+  type IndexOptions,
+  type TickerIndex,
+  type TickerRanges,
+} from "./ticker-index.ts";
+
+export {
+  dedupArchive,
+  dedupKey,
+  isDuplicate,
+  isDuplicateRecord,
+  messageTimestamp,
+  newDedupWindow,
+  nextDedupFile,
+  recordDedupKey,
+  type DedupKeys,
+  type DedupOptions,
+  type DedupReport,
+  type DedupWindow,
+  type FileDuplicates,
+} from "./dedup.ts";
+
+export {
+  buildSchemaRegistry,
+  newFileValidation,
+  recordType,
+  selectSchemas,
+  validateArchive,
+  validateLine,
+  validateRecord,
+  type FileValidation,
+  type RecordIssue,
+  type RecordIssueKind,
+  type ValidateArchiveOptions,
+} from "./validate.ts";
+
+export {
+  checkArchive,
+  compareEntry,
+  repairManifest,
+  verifyArchive,
+  type ArchiveCheck,
+  type FileVerification,
+  type VerifyIssue,
+  type VerifyIssueKind,
+  type VerifyOptions,
+  type VerifyReport,
+} from "./verify.ts";
@@ -0,0 +1,163 @@
+/**
+ * Replay: republish an archived day's JSONL messages in _received_at order
+ * with the original spacing between them scaled by a speed multiplier, on
+ * connector-style subjects <prefix>.json.<type>.<ticker>. Used by
+ * `ssmd data replay`, which owns the NATS connection and can checkpoint the
+ * archived stream sequence (_nats_seq) reached so an interrupted replay
+ * resumes after it.
+ */
+import { Storage } from "@google-cloud/storage";
+import { archiveDir, archiveSlots, archiveStream, toLines } from "./files.ts";
+import { detectMessageType, extractTicker, sanitizeToken } from "./messages.ts";
+
+/** A decoded archive line ready to publish */
+export interface ReplayRecord {
+  /** Archiver receive time, microseconds since epoch */
+  receivedAt: number;
+  /** Archived stream sequence (_nats_seq), when present */
+  seq: number | null;
+  subject: string;
+  payload: string;
+}
+
+export interface ReplayOptions {
+  /** Multiplier on real time; Infinity publishes without pacing */
+  speed: number;
+  subjectPrefix: string;
+  /** Publishes a record; without it the replay only reads and counts (dry run) */
+  publish?: (subject: string, payload: string) => void;
+  /** Called after each 15-minute slot with its message count */
+  onSlot?: (slot: string, messages: number) => void;
+  /** Skip records at or below this stream sequence, e.g. a checkpoint of an interrupted replay */
+  afterSeq?: number;
+  /** Awaited after each slot that published records, with the highest stream sequence published so far */
+  onCheckpoint?: (seq: number) => Promise<void>;
+}
+
+export interface ReplayReport {
+  slots: number;
+  published: number;
+  subjects: number;
+  /** Records skipped as at or below afterSeq */
+  skipped: number;
+}
+
+/**
+ * Parse a speed: "10x" or "10" replays ten times faster than real time,
+ * "max" publishes as fast as possible (returns Infinity).
+ */
+export function parseSpeed(value: string | undefined): number {
+  if (value === undefined || value === "") return 1;
+  if (value === "max") return Infinity;
+  const n = Number(value.endsWith("x") ? value.slice(0, -1) : value);
+  if (!Number.isFinite(n) || n <= 0) {
+    throw new Error(`--speed must be a positive multiplier like 10x or 'max', got '${value}'`);
+  }
+  return n;
+}
+
+/**
+ * Decode one archive line. The archiver's injected _received_at/_nats_seq
+ * fields are stripped so consumers see the payload as the connector sent it.
+ * Returns null for blank lines, control frames and lines without _received_at.
+ */
+export function toReplayRecord(feed: string, subjectPrefix: string, line: string): ReplayRecord | null {
+  if (line.trim() === "") return null;
+  let json: Record<string, unknown>;
+  try {
+    json = JSON.parse(line);
+  } catch {
+    return null;
+  }
+  const receivedAt = json._received_at;
+  if (typeof receivedAt !== "number") return null;
+  const seq = typeof json._nats_seq === "number" ? json._nats_seq : null;
+  const type = detectMessageType(feed, json);
+  if (!type) return null;
+
+  delete json._received_at;
+  delete json._nats_seq;
+  const ticker = sanitizeToken(extractTicker(feed, json) ?? "");
+  const subject = ticker
+    ? `${subjectPrefix}.json.${sanitizeToken(type)}.${ticker}`
+    : `${subjectPrefix}.json.${sanitizeToken(type)}`;
+  return { receivedAt, seq, subject, payload: JSON.stringify(json) };
+}
+
+/**
+ * Milliseconds to wait before publishing a record so the replay keeps the
+ * original spacing scaled by speed. Measured from the start of the replay
+ * rather than the previous record, so sleep overshoot does not accumulate.
+ */
+export function replayDelayMs(
+  firstReceivedAt: number,
+  receivedAt: number,
+  startedAtMs: number,
+  nowMs: number,
+  speed: number,
+): number {
+  if (!Number.isFinite(speed)) return 0;
+  const offsetMs = (receivedAt - firstReceivedAt) / 1000 / speed;
+  return Math.max(0, startedAtMs + offsetMs - nowMs);
+}
+
+/**
+ * Replay a date's archive. Each slot's files (restart files included) are
+ * read and sorted together before publishing, so memory holds one slot at a
+ * time. With afterSeq, records the archive holds without a sequence are
+ * published again on resume. Throws when the date has no archive files.
+ */
+export async function replayArchive(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+  opts: ReplayOptions,
+): Promise<ReplayReport> {
+  const slots = await archiveSlots(storage, bucket, feed, date);
+  if (slots.length === 0) {
+    throw new Error(`No archived JSONL files at gs://${bucket}/${archiveDir(feed, date)}/`);
+  }
+
+  const subjects = new Set<string>();
+  let published = 0;
+  let skipped = 0;
+  let lastSeq = opts.afterSeq ?? 0;
+  let firstReceivedAt: number | null = null;
+  let startedAtMs = 0;
+
+  for (const [slot, files] of slots) {
+    const records: ReplayRecord[] = [];
+    for (const name of files) {
+      for await (const line of toLines(archiveStream(storage, bucket, name))) {
+        const record = toReplayRecord(feed, opts.subjectPrefix, line);
+        if (record) records.push(record);
+      }
+    }
+    records.sort((a, b) => a.receivedAt - b.receivedAt);
+
+    let slotPublished = 0;
+    for (const record of records) {
+      if (opts.afterSeq !== undefined && record.seq !== null && record.seq <= opts.afterSeq) {
+        skipped++;
+        continue;
+      }
+      if (firstReceivedAt === null) {
+        firstReceivedAt = record.receivedAt;
+        startedAtMs = Date.now();
+      }
+      if (opts.publish) {
+        const delay = replayDelayMs(firstReceivedAt, record.receivedAt, startedAtMs, Date.now(), opts.speed);
+        if (delay > 0) await new Promise((resolve) => setTimeout(resolve, delay));
+        opts.publish(record.subject, record.payload);
+      }
+      subjects.add(record.subject);
+      published++;
+      slotPublished++;
+      if (record.seq !== null && record.seq > lastSeq) lastSeq = record.seq;
+    }
+    opts.onSlot?.(slot, slotPublished);
+    if (slotPublished > 0 && lastSeq > 0) await opts.onCheckpoint?.(lastSeq);
+  }
+  return { slots: slots.length, published, subjects: subjects.size, skipped };
+}
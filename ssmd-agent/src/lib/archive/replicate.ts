@@ -0,0 +1,203 @@
+/**
+ * Replication: copy a feed's archive dates to a second bucket or prefix for
+ * disaster recovery. Per date, objects the destination lacks or holds a
+ * different version of are copied and each copy's CRC32C and size checked;
+ * a replication.json status manifest next to the copies lets reruns resume.
+ * Used by `ssmd data replicate`.
+ */
+import { Storage } from "@google-cloud/storage";
+import { FEED_PATHS } from "../duckdb/feed-config.ts";
+
+/** Status manifest written in each replicated date directory of the destination */
+export const REPLICATION_STATUS_FILE = "replication.json";
+
+/** Status is saved after this many copies, so an interrupted run loses little */
+const SAVE_EVERY = 50;
+
+/** One side of a replication: a bucket and an optional prefix before the feed path */
+export interface ReplicaLocation {
+  bucket: string;
+  prefix: string;
+}
+
+/** An object in a date directory, named relative to it */
+export interface ObjectInfo {
+  name: string;
+  size: number;
+  crc32c: string;
+  generation: string;
+}
+
+export interface ReplicatedObject {
+  size: number;
+  crc32c: string;
+  source_generation: string;
+  replicated_at: string;
+}
+
+export interface ReplicationStatus {
+  feed: string;
+  date: string;
+  source: string;
+  destination: string;
+  updated_at: string;
+  /** Every source object was replicated and checked by the last run */
+  complete: boolean;
+  objects: Record<string, ReplicatedObject>;
+  /** Objects whose copy failed or did not match, with the reason */
+  failed: Record<string, string>;
+}
+
+export interface ReplicationResult {
+  feed: string;
+  date: string;
+  copied: number;
+  current: number;
+  failed: number;
+  /** Whether the source date directory has any objects */
+  found: boolean;
+}
+
+export interface ReplicateOptions {
+  dryRun?: boolean;
+  /** Called after each copy with its error, or null, for progress output */
+  onCopy?: (name: string, error: string | null) => void;
+}
+
+/** Date directory of a feed under a location, with a trailing slash */
+export function replicaDir(location: ReplicaLocation, feed: string, date: string): string {
+  const prefix = location.prefix.replace(/^\/+|\/+$/g, "");
+  return `${prefix ? `${prefix}/` : ""}${FEED_PATHS[feed]}/${date}/`;
+}
+
+/** gs:// URL of a directory under a location */
+export function locationUrl(location: ReplicaLocation, dir: string): string {
+  return `gs://${location.bucket}/${dir}`;
+}
+
+/**
+ * Split source objects into those to copy and those the destination already
+ * holds (same size and CRC32C). The manifest is copied last, so a manifest at
+ * the destination only lists files that are already there.
+ */
+export function planReplication(
+  source: ObjectInfo[],
+  destination: Map<string, ObjectInfo>,
+): { copy: ObjectInfo[]; current: ObjectInfo[] } {
+  const copy: ObjectInfo[] = [];
+  const current: ObjectInfo[] = [];
+  for (const object of source) {
+    if (object.name === REPLICATION_STATUS_FILE) continue;
+    const existing = destination.get(object.name);
+    if (existing && existing.size === object.size && existing.crc32c === object.crc32c) current.push(object);
+    else copy.push(object);
+  }
+  const manifestLast = (a: ObjectInfo, b: ObjectInfo) =>
+    Number(a.name === "manifest.json") - Number(b.name === "manifest.json") || a.name.localeCompare(b.name);
+  return { copy: copy.sort(manifestLast), current };
+}
+
+async function listObjects(storage: Storage, bucket: string, dir: string): Promise<ObjectInfo[]> {
+  const [files] = await storage.bucket(bucket).getFiles({ prefix: dir });
+  return files.map((f) => ({
+    name: f.name.slice(dir.length),
+    size: Number(f.metadata.size),
+    crc32c: String(f.metadata.crc32c),
+    generation: String(f.metadata.generation),
+  }));
+}
+
+async function readStatus(storage: Storage, bucket: string, dir: string): Promise<ReplicationStatus | null> {
+  try {
+    const [contents] = await storage.bucket(bucket).file(`${dir}${REPLICATION_STATUS_FILE}`).download();
+    return JSON.parse(contents.toString("utf-8"));
+  } catch (err: unknown) {
+    if ((err as { code?: number }).code === 404) return null;
+    throw err;
+  }
+}
+
+async function saveStatus(storage: Storage, bucket: string, dir: string, status: ReplicationStatus): Promise<void> {
+  status.updated_at = new Date().toISOString();
+  await storage.bucket(bucket).file(`${dir}${REPLICATION_STATUS_FILE}`).save(JSON.stringify(status, null, 2), {
+    contentType: "application/json",
+    resumable: false,
+  });
+}
+
+/**
+ * Replicate one feed and date. Objects the destination already holds are
+ * skipped, so a rerun after an interruption only copies what is left; the
+ * status manifest is saved every SAVE_EVERY copies and at the end.
+ */
+export async function replicateDate(
+  storage: Storage,
+  source: ReplicaLocation,
+  destination: ReplicaLocation,
+  feed: string,
+  date: string,
+  opts: ReplicateOptions = {},
+): Promise<ReplicationResult> {
+  const sourceDir = replicaDir(source, feed, date);
+  const destDir = replicaDir(destination, feed, date);
+  const objects = await listObjects(storage, source.bucket, sourceDir);
+  if (objects.length === 0) return { feed, date, copied: 0, current: 0, failed: 0, found: false };
+
+  const existing = new Map((await listObjects(storage, destination.bucket, destDir)).map((o) => [o.name, o]));
+  const { copy, current } = planReplication(objects, existing);
+  if (opts.dryRun) return { feed, date, copied: copy.length, current: current.length, failed: 0, found: true };
+
+  const status: ReplicationStatus = {
+    feed,
+    date,
+    source: locationUrl(source, sourceDir),
+    destination: locationUrl(destination, destDir),
+    updated_at: "",
+    complete: false,
+    objects: (await readStatus(storage, destination.bucket, destDir))?.objects ?? {},
+    failed: {},
+  };
+  const record = (object: ObjectInfo) => {
+    status.objects[object.name] ??= {
+      size: object.size,
+      crc32c: object.crc32c,
+      source_generation: object.generation,
+      replicated_at: new Date().toISOString(),
+    };
+  };
+  for (const object of current) record(object);
+
+  let copied = 0;
+  for (const object of copy) {
+    let error: string | null = null;
+    try {
+      const target = storage.bucket(destination.bucket).file(`${destDir}${object.name}`);
+      // Pin the generation listed, so a concurrent rewrite is not copied under the old checksum
+      await storage.bucket(source.bucket).file(`${sourceDir}${object.name}`, { generation: object.generation }).copy(target);
+      const [metadata] = await target.getMetadata();
+      if (String(metadata.crc32c) !== object.crc32c || Number(metadata.size) !== object.size) {
+        error = `copy has crc32c ${metadata.crc32c} and ${metadata.size} bytes, source ${object.crc32c} and ${object.size}`;
+      }
+    } catch (err) {
+      error = (err as Error).message;
+    }
+    opts.onCopy?.(object.name, error);
+    if (error) {
+      status.failed[object.name] = error;
+      continue;
+    }
+    delete status.objects[object.name];
+    record(object);
+    if (++copied % SAVE_EVERY === 0) await saveStatus(storage, destination.bucket, destDir, status);
+  }
+
+  // Objects no longer in the source are dropped from the status
+  const names = new Set(objects.map((o) => o.name));
+  for (const name of Object.keys(status.objects)) {
+    if (!names.has(name)) delete status.objects[name];
+  }
+  const failed = Object.keys(status.failed).length;
+  status.complete = failed === 0;
+  await saveStatus(storage, destination.bucket, destDir, status);
+  return { feed, date, copied, current: current.length, failed, found: true };
+}
@@ -0,0 +1,281 @@
+/**
+ * Sampling: archived JSONL lines for a set of tickers and/or a filter
+ * expression (lib/filter) over one date or a span of dates, merged into received-time order across every file with a
+ * k-way merge. Files are opened only once the merge reaches their manifest
+ * start time, and files outside the time bounds (the manifest's min_ts/max_ts
+ * statistics, or the file window) are skipped unread. With a ticker index
+ * (ticker-index.ts), files without the tickers are skipped too and only the
+ * indexed byte ranges of the others are parsed. Used by
+ * `ssmd data sample` and GET /v1/data/sample.
+ */
+import { Storage } from "@google-cloud/storage";
+import { archiveFiles, archiveStream, dateRange, toLines } from "./files.ts";
+import { loadArchiveManifest, type ManifestFile } from "./manifest.ts";
+import { type FieldResolver, recordResolver, type RecordFilter } from "../filter/mod.ts";
+import { detectMessageType, extractTicker } from "./messages.ts";
+import { indexMatches, loadTickerIndex, rangesFor, sliceRanges, TICKER_INDEX_FILE } from "./ticker-index.ts";
+
+/** Dates a sample reads and its received-time bounds (epoch millis) */
+export interface SampleRange {
+  dates: string[];
+  fromMs: number;
+  toMs: number;
+}
+
+const DAY_MS = 86_400_000;
+
+/**
+ * A from/to bound on a date: HH:MM[:SS] (UTC) or a full ISO timestamp.
+ * Returns epoch millis, or null when the value is not a time.
+ */
+export function parseTimeBound(date: string, value: string): number | null {
+  const ms = /^\d{2}:\d{2}(:\d{2})?$/.test(value) ? Date.parse(`${date}T${value}Z`) : Date.parse(value);
+  return Number.isNaN(ms) ? null : ms;
+}
+
+/** A range bound: YYYY-MM-DD (plus offsetMs into the day) or an ISO timestamp */
+function rangeBound(value: string, offsetMs: number): number | null {
+  const ms = /^\d{4}-\d{2}-\d{2}$/.test(value) ? Date.parse(`${value}T00:00:00Z`) + offsetMs : Date.parse(value);
+  return Number.isNaN(ms) ? null : ms;
+}
+
+/**
+ * Resolve what a sample reads. With a date, from/to are HH:MM[:SS] (UTC) or
+ * ISO bounds within that day. Without one both are required and may span
+ * days: a bare YYYY-MM-DD covers the whole day. Throws on invalid input.
+ */
+export function sampleRange(date: string | undefined, from?: string, to?: string): SampleRange {
+  if (date !== undefined) {
+    const fromMs = from !== undefined ? parseTimeBound(date, from) : -Infinity;
+    const toMs = to !== undefined ? parseTimeBound(date, to) : Infinity;
+    if (fromMs === null || toMs === null) {
+      throw new Error("from/to must be HH:MM[:SS] (UTC) or an ISO timestamp");
+    }
+    return { dates: [date], fromMs, toMs };
+  }
+  if (from === undefined || to === undefined) {
+    throw new Error("from and to are both required without a date");
+  }
+  const fromMs = rangeBound(from, 0);
+  const toMs = rangeBound(to, DAY_MS - 1);
+  if (fromMs === null || toMs === null) {
+    throw new Error("from/to must be YYYY-MM-DD or an ISO timestamp");
+  }
+  if (toMs < fromMs) throw new Error("to must not be before from");
+  const day = (ms: number) => new Date(ms).toISOString().slice(0, 10);
+  return { dates: dateRange(day(fromMs), day(toMs)), fromMs, toMs };
+}
+
+/**
+ * Whether a manifest entry may hold records received in [fromMs, toMs]. Uses
+ * min_ts/max_ts, falling back to the file's start/end window for entries
+ * without statistics; entries with unreadable bounds are kept. Entries with
+ * no tickers (ticker_count 0) hold only control frames and never match.
+ */
+export function fileOverlaps(file: ManifestFile, fromMs: number, toMs: number): boolean {
+  if (file.ticker_count === 0) return false;
+  const min = Date.parse(file.min_ts ?? file.start);
+  const max = Date.parse(file.max_ts ?? file.end);
+  if (Number.isNaN(min) || Number.isNaN(max)) return true;
+  return max >= fromMs && min <= toMs;
+}
+
+/** One input to mergeByTime, opened once the merge reaches startMs */
+export interface MergeInput<T> {
+  /** No item of the input is earlier than this; -Infinity opens it straight away */
+  startMs: number;
+  open: () => AsyncIterable<T>;
+}
+
+interface MergeHead<T> {
+  time: number;
+  order: number;
+  item: T;
+  it: AsyncIterator<T>;
+}
+
+function heapLess<T>(a: MergeHead<T>, b: MergeHead<T>): boolean {
+  return a.time < b.time || (a.time === b.time && a.order < b.order);
+}
+
+function heapPush<T>(heap: MergeHead<T>[], head: MergeHead<T>): void {
+  heap.push(head);
+  let i = heap.length - 1;
+  while (i > 0) {
+    const parent = (i - 1) >> 1;
+    if (!heapLess(heap[i], heap[parent])) break;
+    [heap[i], heap[parent]] = [heap[parent], heap[i]];
+    i = parent;
+  }
+}
+
+function heapPop<T>(heap: MergeHead<T>[]): MergeHead<T> {
+  const top = heap[0];
+  const last = heap.pop()!;
+  if (heap.length > 0) {
+    heap[0] = last;
+    let i = 0;
+    for (;;) {
+      const l = 2 * i + 1;
+      const r = l + 1;
+      let min = i;
+      if (l < heap.length && heapLess(heap[l], heap[min])) min = l;
+      if (r < heap.length && heapLess(heap[r], heap[min])) min = r;
+      if (min === i) break;
+      [heap[i], heap[min]] = [heap[min], heap[i]];
+      i = min;
+    }
+  }
+  return top;
+}
+
+/**
+ * k-way merge of inputs that are each in time order. Inputs are opened in
+ * startMs order only once the merge could need them, so a day of rotated
+ * files is read a few at a time rather than all at once. Items without a
+ * time keep the time of the item before them in their input; ties go to
+ * the input listed first. Inputs still open are closed when the consumer stops.
+ */
+export async function* mergeByTime<T>(inputs: MergeInput<T>[], timeOf: (item: T) => number | null): AsyncGenerator<T> {
+  const pending = inputs
+    .map((input, order) => ({ ...input, order }))
+    .sort((a, b) => a.startMs - b.startMs || a.order - b.order);
+  const heap: MergeHead<T>[] = [];
+  const opened: AsyncIterator<T>[] = [];
+
+  const advance = async (it: AsyncIterator<T>, order: number, prevTime: number) => {
+    const r = await it.next();
+    if (!r.done) heapPush(heap, { time: timeOf(r.value) ?? prevTime, order, item: r.value, it });
+  };
+
+  let next = 0;
+  try {
+    for (;;) {
+      while (next < pending.length && (heap.length === 0 || pending[next].startMs <= heap[0].time)) {
+        const input = pending[next++];
+        const it = input.open()[Symbol.asyncIterator]();
+        opened.push(it);
+        await advance(it, input.order, input.startMs);
+      }
+      if (heap.length === 0) return;
+      const head = heapPop(heap);
+      yield head.item;
+      await advance(head.it, head.order, head.time);
+    }
+  } finally {
+    await Promise.all(opened.map((it) => it.return?.()));
+  }
+}
+
+export interface SampleOptions {
+  /** Tickers to return; empty returns every ticker the filter matches */
+  tickers: string[];
+  /** Expression each line must match, tested after the ticker and time checks */
+  where?: RecordFilter;
+  fromMs: number;
+  toMs: number;
+  /** Skip dates without an archiver manifest instead of scanning their files */
+  requireManifest?: boolean;
+  /** Ignore the ticker index and parse every line of every file */
+  fullScan?: boolean;
+  /** Notes about skipped dates and dates read without an index */
+  warn?: (message: string) => void;
+}
+
+/** Filter fields for an archive line: normalized ticker and type, the rest from the message */
+export function sampleResolver(
+  feed: string,
+  json: Record<string, unknown>,
+  ticker = extractTicker(feed, json),
+): FieldResolver {
+  return recordResolver(json, { ticker, type: detectMessageType(feed, json) });
+}
+
+interface SampledLine {
+  line: string;
+  /** _received_at in epoch millis, when present */
+  ms: number | null;
+}
+
+/** Merge inputs for one date's archive files that can hold matching lines */
+async function sampleInputs(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+  opts: SampleOptions,
+): Promise<MergeInput<SampledLine>[]> {
+  const stored = await loadArchiveManifest(storage, bucket, feed, date);
+  if (!stored && opts.requireManifest) {
+    opts.warn?.(`No manifest.json for ${feed} ${date}; skipping`);
+    return [];
+  }
+  // Manifest time windows let files outside the range be skipped unread
+  const entries = new Map((stored?.manifest.files ?? []).map((f) => [f.name, f]));
+  const base = (name: string) => name.split("/").pop() ?? name;
+  const files = (await archiveFiles(storage, bucket, feed, date)).filter((name) => {
+    const entry = entries.get(base(name));
+    return !entry || fileOverlaps(entry, opts.fromMs, opts.toMs);
+  });
+
+  // The index only helps when tickers narrow the files; a filter alone reads everything
+  const useIndex = opts.tickers.length > 0 && !opts.fullScan;
+  const index = useIndex ? await loadTickerIndex(storage, bucket, feed, date) : null;
+  if (!index && useIndex) {
+    opts.warn?.(`No ${TICKER_INDEX_FILE} for ${feed} ${date}; reading whole files (ssmd data index builds it)`);
+  }
+
+  const wanted = new Set(opts.tickers);
+  const inputs: MergeInput<SampledLine>[] = [];
+  for (const name of files) {
+    const entry = entries.get(base(name));
+    const fileIndex = index?.files[base(name)];
+    // Files archived or rewritten after the index was built are read in full
+    const ranges = fileIndex && indexMatches(fileIndex, entry) ? rangesFor(fileIndex, opts.tickers) : null;
+    if (ranges?.length === 0) continue;
+    const startMs = entry ? Date.parse(entry.min_ts ?? entry.start) : NaN;
+    inputs.push({
+      startMs: Number.isNaN(startMs) ? -Infinity : startMs,
+      open: async function* () {
+        const bytes = archiveStream(storage, bucket, name);
+        const lines = toLines(ranges ? ReadableStream.from(sliceRanges(bytes, ranges)) : bytes);
+        for await (const line of lines) {
+          let json: Record<string, unknown>;
+          try {
+            json = JSON.parse(line);
+          } catch {
+            continue;
+          }
+          const ticker = extractTicker(feed, json);
+          if (wanted.size > 0 && (!ticker || !wanted.has(ticker))) continue;
+          const ms = typeof json._received_at === "number" ? json._received_at / 1000 : null;
+          if (ms !== null && (ms < opts.fromMs || ms > opts.toMs)) continue;
+          if (opts.where && !opts.where.matches(sampleResolver(feed, json, ticker))) continue;
+          yield { line, ms };
+        }
+      },
+    });
+  }
+  return inputs;
+}
+
+/**
+ * Archived JSONL lines for the tickers across the dates, merged into
+ * received-time order over every file of every date. Stop iterating to stop
+ * reading; open GCS streams are closed.
+ */
+export async function* sampleLines(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  dates: string[],
+  opts: SampleOptions,
+): AsyncGenerator<string> {
+  const inputs: MergeInput<SampledLine>[] = [];
+  for (const date of dates) {
+    inputs.push(...await sampleInputs(storage, bucket, feed, date, opts));
+  }
+  for await (const { line } of mergeByTime(inputs, (l) => l.ms)) {
+    yield line;
+  }
+}
@@ -0,0 +1,130 @@
+/**
+ * Per-file statistics in archiver manifests: received-time bounds (min_ts,
+ * max_ts), message type counts and ticker cardinality. The archiver records
+ * them as it writes; files archived before that get them backfilled here.
+ * Sampling uses them to skip files outside a time range. Used by
+ * `ssmd data stats`.
+ */
+import { Storage } from "@google-cloud/storage";
+import { archiveFiles, archiveStream, toLines } from "./files.ts";
+import { loadArchiveManifest, type ManifestFile, saveArchiveManifest } from "./manifest.ts";
+import { detectMessageType, extractTicker } from "./messages.ts";
+
+/** Statistics gathered from one archive file's lines */
+export interface FileStats {
+  records: number;
+  /** _received_at bounds, microseconds since epoch */
+  minReceivedAt: number | null;
+  maxReceivedAt: number | null;
+  recordsByType: Record<string, number>;
+  tickers: Set<string>;
+}
+
+export interface StatsOptions {
+  /** Recompute every file, not only those missing statistics */
+  force?: boolean;
+  dryRun?: boolean;
+  /** Called as each file's statistics are computed */
+  onFile?: (name: string, stats: FileStats) => void;
+  /** Manifest entries without an archive file */
+  onMissing?: (name: string) => void;
+}
+
+export interface StatsReport {
+  feed: string;
+  date: string;
+  files: number;
+  /** Entries that were missing statistics (all with force) */
+  pending: number;
+  updated: number;
+  /** Whether the manifest was written */
+  written: boolean;
+}
+
+export function newFileStats(): FileStats {
+  return { records: 0, minReceivedAt: null, maxReceivedAt: null, recordsByType: {}, tickers: new Set() };
+}
+
+/** Add one archive line to a file's statistics; blank and unparseable lines are skipped */
+export function statsLine(stats: FileStats, feed: string, line: string): void {
+  if (line.trim() === "") return;
+  let json: Record<string, unknown>;
+  try {
+    json = JSON.parse(line);
+  } catch {
+    return;
+  }
+  stats.records++;
+  const receivedAt = json._received_at;
+  if (typeof receivedAt === "number") {
+    if (stats.minReceivedAt === null || receivedAt < stats.minReceivedAt) stats.minReceivedAt = receivedAt;
+    if (stats.maxReceivedAt === null || receivedAt > stats.maxReceivedAt) stats.maxReceivedAt = receivedAt;
+  }
+  const type = detectMessageType(feed, json);
+  if (type) stats.recordsByType[type] = (stats.recordsByType[type] ?? 0) + 1;
+  const ticker = extractTicker(feed, json);
+  if (ticker) stats.tickers.add(ticker);
+}
+
+/**
+ * Copy statistics onto a manifest entry. Bounds are widened to whole
+ * milliseconds (floor/ceil) so they still contain every record.
+ */
+export function applyFileStats(file: ManifestFile, stats: FileStats): ManifestFile {
+  return {
+    ...file,
+    records_by_type: stats.recordsByType,
+    ticker_count: stats.tickers.size,
+    ...(stats.minReceivedAt !== null && { min_ts: new Date(Math.floor(stats.minReceivedAt / 1000)).toISOString() }),
+    ...(stats.maxReceivedAt !== null && { max_ts: new Date(Math.ceil(stats.maxReceivedAt / 1000)).toISOString() }),
+  };
+}
+
+/** True when an entry lacks any of the statistics the archiver now records */
+export function needsStats(file: ManifestFile): boolean {
+  return file.min_ts === undefined || file.max_ts === undefined ||
+    file.ticker_count === undefined || file.records_by_type === undefined;
+}
+
+/**
+ * Backfill statistics into a date's manifest for entries missing them, reading
+ * each of those files once. The manifest is written only if it has not changed
+ * since it was read. Returns null when the date has no manifest.
+ */
+export async function backfillStats(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+  opts: StatsOptions = {},
+): Promise<StatsReport | null> {
+  const stored = await loadArchiveManifest(storage, bucket, feed, date);
+  if (!stored) return null;
+  const { manifest, generation } = stored;
+  const report: StatsReport = { feed, date, files: manifest.files.length, pending: 0, updated: 0, written: false };
+
+  const paths = new Map((await archiveFiles(storage, bucket, feed, date)).map((p) => [p.split("/").pop() ?? p, p]));
+  for (let i = 0; i < manifest.files.length; i++) {
+    const file = manifest.files[i];
+    if (!opts.force && !needsStats(file)) continue;
+    report.pending++;
+    const path = paths.get(file.name);
+    if (!path) {
+      opts.onMissing?.(file.name);
+      continue;
+    }
+    const stats = newFileStats();
+    for await (const line of toLines(archiveStream(storage, bucket, path))) {
+      statsLine(stats, feed, line);
+    }
+    manifest.files[i] = applyFileStats(file, stats);
+    report.updated++;
+    opts.onFile?.(file.name, stats);
+  }
+
+  if (report.updated > 0 && !opts.dryRun) {
+    await saveArchiveManifest(storage, bucket, feed, date, manifest, generation);
+    report.written = true;
+  }
+  return report;
+}
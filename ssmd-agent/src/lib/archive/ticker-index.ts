@@ -0,0 +1,200 @@
+/**
+ * Per-ticker index of a day's archive: for each JSONL.gz file, the byte
+ * ranges of the decompressed lines holding each ticker. Stored as
+ * ticker-index.json next to manifest.json, built by `ssmd data index` and by
+ * compaction, and used by sampling to skip files without the requested
+ * tickers and to parse only their ranges of the others.
+ */
+import { Storage } from "@google-cloud/storage";
+import { archiveDir, archiveFiles, archiveStream, toLines } from "./files.ts";
+import type { ManifestFile } from "./manifest.ts";
+import { extractTicker } from "./messages.ts";
+
+export const TICKER_INDEX_FILE = "ticker-index.json";
+
+export const TICKER_INDEX_VERSION = "1.0.0";
+
+/**
+ * Ranges closer than this are merged, so tickers that interleave with others
+ * do not produce one range per line. Sampling re-checks the ticker of every
+ * line it reads, so merged gaps only cost some extra decompressed bytes.
+ */
+export const DEFAULT_MERGE_GAP_BYTES = 64 * 1024;
+
+/** One ticker's lines in a file: [start, end) offsets into the decompressed JSONL */
+export interface TickerRanges {
+  count: number;
+  ranges: [number, number][];
+}
+
+export interface FileIndex {
+  /** Decompressed size in bytes, as the manifest entry's bytes */
+  bytes: number;
+  lines: number;
+  tickers: Record<string, TickerRanges>;
+}
+
+export interface TickerIndex {
+  feed: string;
+  date: string;
+  version: string;
+  generated_at: string;
+  merge_gap_bytes: number;
+  /** Keyed by archive file name, e.g. "0015.jsonl.gz" */
+  files: Record<string, FileIndex>;
+}
+
+export interface IndexOptions {
+  mergeGap?: number;
+  /** Called as each file is indexed */
+  onFile?: (name: string, file: FileIndex) => void;
+}
+
+export function newFileIndex(): FileIndex {
+  return { bytes: 0, lines: 0, tickers: {} };
+}
+
+export function newTickerIndex(feed: string, date: string, mergeGap = DEFAULT_MERGE_GAP_BYTES): TickerIndex {
+  return {
+    feed,
+    date,
+    version: TICKER_INDEX_VERSION,
+    generated_at: new Date().toISOString(),
+    merge_gap_bytes: mergeGap,
+    files: {},
+  };
+}
+
+const encoder = new TextEncoder();
+
+/**
+ * Add one archive line (without its newline) to a file index. Lines without a
+ * ticker (control frames, blank or unparseable lines) only advance the offset.
+ */
+export function indexLine(index: FileIndex, feed: string, line: string, mergeGap = DEFAULT_MERGE_GAP_BYTES): void {
+  const start = index.bytes;
+  const end = start + encoder.encode(line).byteLength + 1;
+  index.bytes = end;
+  index.lines++;
+
+  let ticker: string | null = null;
+  try {
+    ticker = extractTicker(feed, JSON.parse(line));
+  } catch {
+    return;
+  }
+  if (!ticker) return;
+
+  const entry = index.tickers[ticker] ??= { count: 0, ranges: [] };
+  entry.count++;
+  const last = entry.ranges[entry.ranges.length - 1];
+  if (last && start - last[1] <= mergeGap) {
+    last[1] = end;
+  } else {
+    entry.ranges.push([start, end]);
+  }
+}
+
+/**
+ * Byte ranges of a file holding any of the tickers, sorted and merged.
+ * Empty when none of them appear in the file.
+ */
+export function rangesFor(file: FileIndex, tickers: string[]): [number, number][] {
+  const ranges = tickers.flatMap((t) => file.tickers[t]?.ranges ?? []).sort((a, b) => a[0] - b[0]);
+  const merged: [number, number][] = [];
+  for (const [start, end] of ranges) {
+    const last = merged[merged.length - 1];
+    if (last && start <= last[1]) last[1] = Math.max(last[1], end);
+    else merged.push([start, end]);
+  }
+  return merged;
+}
+
+/**
+ * Whether a file's index still describes the file. A file rewritten since
+ * the index was built (dedup --rewrite) no longer matches its manifest
+ * entry's records and size. Files without an entry are trusted.
+ */
+export function indexMatches(file: FileIndex, entry: ManifestFile | undefined): boolean {
+  return !entry || (entry.bytes === file.bytes && entry.records === file.lines);
+}
+
+/**
+ * Yield only the bytes of a stream that fall inside the sorted ranges, and stop
+ * reading once the last range has been passed.
+ */
+export async function* sliceRanges(
+  chunks: AsyncIterable<Uint8Array>,
+  ranges: [number, number][],
+): AsyncGenerator<Uint8Array> {
+  if (ranges.length === 0) return;
+  let offset = 0;
+  let i = 0;
+  for await (const chunk of chunks) {
+    const chunkEnd = offset + chunk.byteLength;
+    while (i < ranges.length && ranges[i][0] < chunkEnd) {
+      const [start, end] = ranges[i];
+      const from = Math.max(start, offset) - offset;
+      const to = Math.min(end, chunkEnd) - offset;
+      if (to > from) yield chunk.subarray(from, to);
+      if (end > chunkEnd) break;
+      i++;
+    }
+    offset = chunkEnd;
+    if (i >= ranges.length) return;
+  }
+}
+
+export function tickerIndexPath(feed: string, date: string): string {
+  return `${archiveDir(feed, date)}/${TICKER_INDEX_FILE}`;
+}
+
+/** Read a date's ticker index, or null if it has not been built */
+export async function loadTickerIndex(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+): Promise<TickerIndex | null> {
+  try {
+    const [contents] = await storage.bucket(bucket).file(tickerIndexPath(feed, date)).download();
+    return JSON.parse(contents.toString("utf-8"));
+  } catch (err: unknown) {
+    if ((err as { code?: number }).code === 404) return null;
+    throw err;
+  }
+}
+
+export async function saveTickerIndex(storage: Storage, bucket: string, index: TickerIndex): Promise<void> {
+  await storage.bucket(bucket).file(tickerIndexPath(index.feed, index.date)).save(JSON.stringify(index), {
+    contentType: "application/json",
+    resumable: false,
+  });
+}
+
+/**
+ * Index every archive file of a date, reading each once. Returns null when
+ * the date has no archive files. The index is not written; see saveTickerIndex.
+ */
+export async function buildTickerIndex(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+  opts: IndexOptions = {},
+): Promise<TickerIndex | null> {
+  const paths = await archiveFiles(storage, bucket, feed, date);
+  if (paths.length === 0) return null;
+  const mergeGap = opts.mergeGap ?? DEFAULT_MERGE_GAP_BYTES;
+  const index = newTickerIndex(feed, date, mergeGap);
+  for (const path of paths) {
+    const file = newFileIndex();
+    for await (const line of toLines(archiveStream(storage, bucket, path))) {
+      indexLine(file, feed, line, mergeGap);
+    }
+    const name = path.split("/").pop() ?? path;
+    index.files[name] = file;
+    opts.onFile?.(name, file);
+  }
+  return index;
+}
@@ -0,0 +1,203 @@
+/**
+ * Record validation: archived raw lines checked against the raw schemas the
+ * ssmd-schemas parsers expect (lib/types/raw-schema.ts, plus any from
+ * exchanges/schemas), to catch connector or exchange regressions in the
+ * archive before parquet-gen silently skips rows. Used by `ssmd data validate`.
+ */
+import { Storage } from "@google-cloud/storage";
+import { BUILTIN_RAW_SCHEMAS, type JsonType, type RawSchema } from "../types/raw-schema.ts";
+import { archiveFiles, archiveStream, toLines } from "./files.ts";
+import { detectMessageType } from "./messages.ts";
+
+export type RecordIssueKind = "missing" | "type" | "unknown";
+
+export interface RecordIssue {
+  kind: RecordIssueKind;
+  path: string;
+  /** Observed JSON type, for type mismatches */
+  got?: string;
+}
+
+/** Built-ins overlaid with file schemas; a file replaces the same feed/type/version */
+export function buildSchemaRegistry(files: RawSchema[], builtins: RawSchema[] = BUILTIN_RAW_SCHEMAS): RawSchema[] {
+  const key = (s: RawSchema) => `${s.feed}\0${s.type}\0${s.version}`;
+  const registry = new Map(builtins.map((s) => [key(s), s]));
+  for (const s of files) registry.set(key(s), s);
+  return [...registry.values()];
+}
+
+/** Schemas for a feed, narrowed by --schema type[:version]; throws if none match */
+export function selectSchemas(registry: RawSchema[], feed: string, schema?: string): RawSchema[] {
+  const forFeed = registry.filter((s) => s.feed === feed);
+  if (!schema) return forFeed;
+  const [type, version] = schema.split(":");
+  const matched = forFeed.filter((s) => s.type === type && (!version || s.version === version));
+  if (matched.length === 0) {
+    const known = forFeed.map((s) => `${s.type}:${s.version}`).join(", ") || "none";
+    throw new Error(`No schema ${schema} for ${feed} (known: ${known})`);
+  }
+  return matched;
+}
+
+/** Message type from the feed's detector, else from a schema's type_field path */
+export function recordType(feed: string, schemas: RawSchema[], record: Record<string, unknown>): string | null {
+  const detected = detectMessageType(feed, record);
+  if (detected) return detected;
+  for (const schema of schemas) {
+    if (!schema.type_field) continue;
+    let value: unknown = record;
+    for (const part of schema.type_field.split(".")) {
+      value = value && typeof value === "object" ? (value as Record<string, unknown>)[part] : undefined;
+    }
+    if (typeof value === "string") return value;
+  }
+  return null;
+}
+
+function jsonType(v: unknown): string {
+  if (v === null) return "null";
+  if (Array.isArray(v)) return "array";
+  if (typeof v === "number") return Number.isInteger(v) ? "integer" : "number";
+  return typeof v;
+}
+
+function typeMatches(expected: JsonType | JsonType[], v: unknown): boolean {
+  const got = jsonType(v);
+  // Whole-valued floats serialize as integers, so integer satisfies number
+  return [expected].flat().some((t) => got === t || (t === "number" && got === "integer"));
+}
+
+/** Check one parsed record; issues are deduplicated by kind and path */
+export function validateRecord(schema: RawSchema, record: Record<string, unknown>): RecordIssue[] {
+  const issues = new Map<string, RecordIssue>();
+  const add = (issue: RecordIssue) => issues.set(`${issue.kind}\0${issue.path}`, issue);
+  const paths = Object.keys(schema.fields);
+  const hasChildren = (prefix: string) => paths.some((p) => p.startsWith(`${prefix}.`));
+  const parentOf = (path: string) => (path.includes(".") ? path.slice(0, path.lastIndexOf(".")) : "");
+
+  const checkObject = (obj: Record<string, unknown>, prefix: string) => {
+    for (const [key, value] of Object.entries(obj)) {
+      const path = prefix ? `${prefix}.${key}` : key;
+      const spec = schema.fields[path];
+      if (Array.isArray(value) && hasChildren(`${path}[]`)) {
+        for (const item of value) {
+          if (item !== null && typeof item === "object" && !Array.isArray(item)) {
+            checkObject(item as Record<string, unknown>, `${path}[]`);
+          } else {
+            add({ kind: "type", path: `${path}[]`, got: jsonType(item) });
+          }
+        }
+      } else if (!spec && hasChildren(path)) {
+        if (value !== null && typeof value === "object" && !Array.isArray(value)) {
+          checkObject(value as Record<string, unknown>, path);
+        } else {
+          add({ kind: "type", path, got: jsonType(value) });
+        }
+      } else if (!spec) {
+        add({ kind: "unknown", path });
+      } else if (!typeMatches(spec.type, value)) {
+        add({ kind: "type", path, got: jsonType(value) });
+      }
+    }
+
+    // Required fields (and groups) whose parent object is this one
+    const groups = new Map<string, string[]>();
+    for (const [path, spec] of Object.entries(schema.fields)) {
+      if (!spec.required || parentOf(path) !== prefix) continue;
+      const key = path.slice(prefix ? prefix.length + 1 : 0);
+      if (spec.required === true) {
+        if (!(key in obj)) add({ kind: "missing", path });
+      } else {
+        groups.set(spec.required, [...(groups.get(spec.required) ?? []), path]);
+      }
+    }
+    for (const members of groups.values()) {
+      const present = members.some((p) => p.slice(prefix ? prefix.length + 1 : 0) in obj);
+      if (!present) add({ kind: "missing", path: members.join("|") });
+    }
+  };
+
+  checkObject(record, "");
+  // Required nested objects that are absent entirely (e.g. no msg at all)
+  for (const [path, spec] of Object.entries(schema.fields)) {
+    if (spec.required !== true) continue;
+    const top = path.split(".")[0].replace("[]", "");
+    if (top !== path && !(top in record)) add({ kind: "missing", path });
+  }
+  return [...issues.values()];
+}
+
+/** Per-file counts */
+export interface FileValidation {
+  file: string;
+  records: number;
+  validated: number;
+  /** Records whose type has no registered schema (or is filtered out) */
+  skipped: number;
+  unparseable: number;
+  /** Records with at least one missing/type issue */
+  invalid: number;
+  /** "kind path" -> number of records with that issue */
+  issues: Record<string, number>;
+}
+
+export function newFileValidation(file: string): FileValidation {
+  return { file, records: 0, validated: 0, skipped: 0, unparseable: 0, invalid: 0, issues: {} };
+}
+
+/** Validate one archive line into the file report */
+export function validateLine(feed: string, schemas: RawSchema[], line: string, report: FileValidation): void {
+  if (line.trim() === "") return;
+  report.records++;
+  let record: Record<string, unknown>;
+  try {
+    record = JSON.parse(line);
+  } catch {
+    report.unparseable++;
+    return;
+  }
+  const type = recordType(feed, schemas, record);
+  const schema = schemas.find((s) => s.type === type);
+  if (!schema) {
+    report.skipped++;
+    return;
+  }
+  report.validated++;
+  const issues = validateRecord(schema, record);
+  if (issues.some((i) => i.kind !== "unknown")) report.invalid++;
+  for (const issue of issues) {
+    const key = `${issue.kind} ${type}.${issue.path}${issue.got ? ` (got ${issue.got})` : ""}`;
+    report.issues[key] = (report.issues[key] ?? 0) + 1;
+  }
+}
+
+export interface ValidateArchiveOptions {
+  /** Called as each file finishes */
+  onFile?: (file: FileValidation) => void;
+}
+
+/**
+ * Validate every archive file of a date, streaming each from GCS. Returns
+ * null when the date has no archive files.
+ */
+export async function validateArchive(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+  schemas: RawSchema[],
+  opts: ValidateArchiveOptions = {},
+): Promise<FileValidation[] | null> {
+  const files = await archiveFiles(storage, bucket, feed, date);
+  if (files.length === 0) return null;
+  const reports: FileValidation[] = [];
+  for (const name of files) {
+    const report = newFileValidation(name.split("/").pop() ?? name);
+    for await (const line of toLines(archiveStream(storage, bucket, name))) {
+      validateLine(feed, schemas, line, report);
+    }
+    opts.onFile?.(report);
+    reports.push(report);
+  }
+  return reports;
+}
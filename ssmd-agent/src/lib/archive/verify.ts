@@ -0,0 +1,297 @@
+/**
+ * Archive integrity: recompute each of a day's JSONL.gz files' record count,
+ * decompressed size and SHA-256, compare them with manifest.json, flag
+ * truncated gzip data, count duplicate messages, and optionally write the
+ * recomputed values back.
+ * Used by `ssmd data verify` and POST /datasets/:feed/:date/verify.
+ */
+import { createHash } from "node:crypto";
+import { Storage } from "@google-cloud/storage";
+import { TextLineStream } from "https://deno.land/std@0.224.0/streams/text_line_stream.ts";
+import { type DedupKeys, type DedupWindow, isDuplicate, newDedupWindow, nextDedupFile } from "./dedup.ts";
+import { archiveFiles, objectStream } from "./files.ts";
+import {
+  type ArchiveManifest,
+  loadArchiveManifest,
+  type ManifestFile,
+  recomputeTotals,
+  saveArchiveManifest,
+} from "./manifest.ts";
+
+/** What one archive file actually holds */
+export interface ArchiveCheck {
+  /** Newline-terminated lines, one per archived message */
+  records: number;
+  /** Decompressed size */
+  bytes: number;
+  compressedBytes: number;
+  /** Hex SHA-256 of the stored (compressed) bytes */
+  sha256: string;
+  /** Why the file is incomplete: a broken gzip stream or a final line without its newline */
+  truncated: string | null;
+  /** _received_at bounds, microseconds since epoch */
+  minReceivedAt: number | null;
+  maxReceivedAt: number | null;
+  /** Lines repeating an earlier message (see dedup.ts); 0 without a window */
+  duplicates: number;
+}
+
+export type VerifyIssueKind = "missing" | "unlisted" | "truncated" | "records" | "bytes" | "sha256";
+
+export interface VerifyIssue {
+  kind: VerifyIssueKind;
+  /** Manifest value, for mismatches */
+  expected?: number | string;
+  /** Recomputed value, or the truncation reason */
+  actual?: number | string;
+}
+
+export interface FileVerification {
+  name: string;
+  records?: number;
+  bytes?: number;
+  compressed_bytes?: number;
+  sha256?: string;
+  /** Duplicate messages, reported but not an issue: ssmd data dedup removes them */
+  duplicates?: number;
+  issues: VerifyIssue[];
+}
+
+export interface VerifyReport {
+  feed: string;
+  date: string;
+  ok: boolean;
+  files: FileVerification[];
+  /** Manifest entries added, changed or dropped by a repair */
+  repaired: number;
+  /** Whether the repaired manifest was written */
+  written: boolean;
+}
+
+export interface VerifyOptions {
+  repair?: boolean;
+  dryRun?: boolean;
+  /** dedup_key fields by message type, for the duplicate counts */
+  keys?: DedupKeys;
+  /** Called as each file is checked, for progress output */
+  onFile?: (file: FileVerification) => void;
+}
+
+const NEWLINE = 0x0a;
+
+function receivedAt(line: string): number | null {
+  try {
+    const value = JSON.parse(line)._received_at;
+    return typeof value === "number" ? value : null;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Read an archive file once: hash and size the stored bytes, and decompress
+ * them to count records and bytes. With a dedup window, duplicate lines are
+ * counted too. A gzip stream that fails to decompress is reported as
+ * truncated rather than thrown; the hash still covers the whole object.
+ */
+export async function checkArchive(stored: ReadableStream<Uint8Array>, window?: DedupWindow): Promise<ArchiveCheck> {
+  const [raw, compressed] = stored.tee();
+  const hash = createHash("sha256");
+  let compressedBytes = 0;
+  const hashing = (async () => {
+    for await (const chunk of raw) {
+      hash.update(chunk);
+      compressedBytes += chunk.byteLength;
+    }
+  })();
+
+  // An empty file ends "after a newline", so it is not truncated
+  const counts = { records: 0, bytes: 0, lastByte: NEWLINE };
+  let truncated: string | null = null;
+  let minReceivedAt: number | null = null;
+  let maxReceivedAt: number | null = null;
+  let duplicates = 0;
+  const counted = compressed.pipeThrough(new DecompressionStream("gzip")).pipeThrough(
+    new TransformStream<Uint8Array, Uint8Array>({
+      transform(chunk, controller) {
+        counts.bytes += chunk.byteLength;
+        for (const b of chunk) if (b === NEWLINE) counts.records++;
+        if (chunk.byteLength > 0) counts.lastByte = chunk[chunk.byteLength - 1];
+        controller.enqueue(chunk);
+      },
+    }),
+  );
+  try {
+    for await (const line of counted.pipeThrough(new TextDecoderStream()).pipeThrough(new TextLineStream())) {
+      if (window && isDuplicate(window, line)) duplicates++;
+      const ts = receivedAt(line);
+      if (ts === null) continue;
+      if (minReceivedAt === null || ts < minReceivedAt) minReceivedAt = ts;
+      if (maxReceivedAt === null || ts > maxReceivedAt) maxReceivedAt = ts;
+    }
+    if (counts.lastByte !== NEWLINE) truncated = "last line has no newline";
+  } catch (err) {
+    truncated = `gzip: ${(err as Error).message}`;
+  }
+  await hashing;
+
+  return {
+    records: counts.records,
+    bytes: counts.bytes,
+    compressedBytes,
+    sha256: hash.digest("hex"),
+    truncated,
+    minReceivedAt,
+    maxReceivedAt,
+    duplicates,
+  };
+}
+
+/**
+ * Compare a manifest entry with what its file holds. Entries without a
+ * sha256 (archived before it was recorded) are not flagged for it, and a
+ * file deleted after compaction (deletedByCompaction) is expected missing.
+ */
+export function compareEntry(
+  entry: ManifestFile | undefined,
+  check: ArchiveCheck | undefined,
+  deletedByCompaction = false,
+): VerifyIssue[] {
+  if (!check) return deletedByCompaction ? [] : [{ kind: "missing" }];
+  const issues: VerifyIssue[] = [];
+  if (check.truncated) issues.push({ kind: "truncated", actual: check.truncated });
+  if (!entry) return [...issues, { kind: "unlisted" }];
+
+  if (entry.records !== check.records) {
+    issues.push({ kind: "records", expected: entry.records, actual: check.records });
+  }
+  if (entry.bytes !== check.bytes) {
+    issues.push({ kind: "bytes", expected: entry.bytes, actual: check.bytes });
+  }
+  if (entry.sha256 !== undefined && entry.sha256 !== check.sha256) {
+    issues.push({ kind: "sha256", expected: entry.sha256, actual: check.sha256 });
+  }
+  return issues;
+}
+
+/** Files whose originals compaction deleted; their entries stay in the manifest */
+function compactionDeleted(manifest: ArchiveManifest): Set<string> {
+  const compaction = manifest.compaction;
+  return new Set(compaction?.originals_deleted ? compaction.sources : []);
+}
+
+/** A manifest entry for an intact file that the manifest does not list */
+function unlistedEntry(name: string, check: ArchiveCheck): ManifestFile {
+  const min = check.minReceivedAt;
+  const max = check.maxReceivedAt;
+  return {
+    name,
+    start: new Date(min !== null ? Math.floor(min / 1000) : 0).toISOString(),
+    end: new Date(max !== null ? Math.ceil(max / 1000) : 0).toISOString(),
+    records: check.records,
+    bytes: check.bytes,
+    nats_start_seq: 0,
+    nats_end_seq: 0,
+    sha256: check.sha256,
+  };
+}
+
+/**
+ * Rewrite manifest entries from the recomputed files: intact files get their
+ * records, size and checksum; entries whose object is gone are dropped,
+ * unless compaction deleted it; intact files missing from the manifest are
+ * added. Truncated files are left as they are, since the manifest cannot fix
+ * their data. Returns the number of entries added, changed or dropped.
+ */
+export function repairManifest(manifest: ArchiveManifest, checks: Map<string, ArchiveCheck>): number {
+  const compacted = compactionDeleted(manifest);
+  let changed = 0;
+  const files: ManifestFile[] = [];
+  for (const entry of manifest.files) {
+    const check = checks.get(entry.name);
+    if (!check) {
+      if (compacted.has(entry.name)) {
+        files.push(entry);
+        continue;
+      }
+      changed++;
+      continue;
+    }
+    if (check.truncated) {
+      files.push(entry);
+      continue;
+    }
+    if (entry.records !== check.records || entry.bytes !== check.bytes || entry.sha256 !== check.sha256) {
+      changed++;
+    }
+    files.push({ ...entry, records: check.records, bytes: check.bytes, sha256: check.sha256 });
+  }
+
+  const listed = new Set(manifest.files.map((f) => f.name));
+  for (const [name, check] of checks) {
+    if (listed.has(name) || check.truncated) continue;
+    files.push(unlistedEntry(name, check));
+    changed++;
+  }
+  manifest.files = files.sort((a, b) => a.name.localeCompare(b.name));
+  return changed;
+}
+
+/**
+ * Verify a date's archive files against manifest.json, and with repair write
+ * the corrected manifest (unless dryRun). The write is conditional on the
+ * manifest generation read, so a concurrent archiver update is not lost.
+ * Returns null when the date has no manifest.
+ */
+export async function verifyArchive(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+  opts: VerifyOptions = {},
+): Promise<VerifyReport | null> {
+  const stored = await loadArchiveManifest(storage, bucket, feed, date);
+  if (!stored) return null;
+  const { manifest, generation } = stored;
+
+  const checks = new Map<string, ArchiveCheck>();
+  const window = newDedupWindow(feed, opts.keys);
+  for (const path of await archiveFiles(storage, bucket, feed, date)) {
+    nextDedupFile(window);
+    checks.set(path.split("/").pop() ?? path, await checkArchive(objectStream(storage, bucket, path), window));
+  }
+
+  const entries = new Map(manifest.files.map((f) => [f.name, f]));
+  const compacted = compactionDeleted(manifest);
+  const names = [...new Set([...entries.keys(), ...checks.keys()])].sort();
+  const files: FileVerification[] = [];
+  for (const name of names) {
+    const check = checks.get(name);
+    const file: FileVerification = {
+      name,
+      ...(check && {
+        records: check.records,
+        bytes: check.bytes,
+        compressed_bytes: check.compressedBytes,
+        sha256: check.sha256,
+        duplicates: check.duplicates,
+      }),
+      issues: compareEntry(entries.get(name), check, compacted.has(name)),
+    };
+    files.push(file);
+    opts.onFile?.(file);
+  }
+
+  let repaired = 0;
+  let written = false;
+  if (opts.repair) {
+    repaired = repairManifest(manifest, checks);
+    if (repaired > 0 && !opts.dryRun) {
+      recomputeTotals(manifest);
+      await saveArchiveManifest(storage, bucket, feed, date, manifest, generation);
+      written = true;
+    }
+  }
+
+  return { feed, date, ok: files.every((f) => f.issues.length === 0), files, repaired, written };
+}
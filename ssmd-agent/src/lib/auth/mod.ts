@@ -19,3 +19,11 @@ export {
   incrementRateLimitHits,
   RATE_LIMITS,
 } from "./ratelimit.ts";
+
+export {
+  findRouteLimit,
+  loadRouteLimits,
+  parseRouteLimits,
+  RouteRateLimiter,
+  type RouteLimit,
+} from "./route-ratelimit.ts";
@@ -0,0 +1,116 @@
+/**
+ * Per-key, per-route token buckets, layered on the per-key tier limit in
+ * ratelimit.ts so one client hammering an expensive route (e.g. downloads)
+ * cannot starve others reading secmaster.
+ *
+ * Rules come from ROUTE_RATE_LIMITS (comma-separated) and/or the file named by
+ * ROUTE_RATE_LIMITS_FILE (one rule per line, # comments):
+ *
+ *   /v1/data/download=10          10 requests/minute, burst 10
+ *   /v1/data/trades=60:20         60 requests/minute, burst 20
+ *   /v1/data/*=300                prefix match; first matching rule wins
+ *
+ * Buckets live in process memory, so limits apply per replica.
+ */
+
+export interface RouteLimit {
+  /** Route pattern (as registered, e.g. /v1/keys/:prefix); trailing * = prefix */
+  route: string;
+  perMinute: number;
+  burst: number;
+}
+
+interface Bucket {
+  limit: RouteLimit;
+  tokens: number;
+  updatedMs: number;
+}
+
+/** Buckets kept before idle (full) ones are pruned */
+const MAX_BUCKETS = 10_000;
+
+/**
+ * Parse rules from a comma- or newline-separated spec.
+ * Throws on a malformed rule so a bad config fails at startup.
+ */
+export function parseRouteLimits(spec: string): RouteLimit[] {
+  const limits: RouteLimit[] = [];
+  for (const raw of spec.split(/[,\n]/)) {
+    const rule = raw.replace(/#.*$/, "").trim();
+    if (!rule) continue;
+    const match = rule.match(/^(\/\S*)=(\d+)(?::(\d+))?$/);
+    if (!match) {
+      throw new Error(`invalid route rate limit '${rule}' (expected /path=perMinute[:burst])`);
+    }
+    const perMinute = Number(match[2]);
+    const burst = match[3] !== undefined ? Number(match[3]) : perMinute;
+    if (perMinute <= 0 || burst <= 0) {
+      throw new Error(`invalid route rate limit '${rule}': rate and burst must be positive`);
+    }
+    limits.push({ route: match[1], perMinute, burst });
+  }
+  return limits;
+}
+
+/** Rules from ROUTE_RATE_LIMITS and ROUTE_RATE_LIMITS_FILE (env rules first) */
+export function loadRouteLimits(): RouteLimit[] {
+  const limits = parseRouteLimits(Deno.env.get("ROUTE_RATE_LIMITS") ?? "");
+  const file = Deno.env.get("ROUTE_RATE_LIMITS_FILE");
+  if (file) {
+    limits.push(...parseRouteLimits(Deno.readTextFileSync(file)));
+  }
+  return limits;
+}
+
+/** First rule matching a route pattern, or null */
+export function findRouteLimit(limits: RouteLimit[], route: string): RouteLimit | null {
+  for (const limit of limits) {
+    if (limit.route.endsWith("*") ? route.startsWith(limit.route.slice(0, -1)) : route === limit.route) {
+      return limit;
+    }
+  }
+  return null;
+}
+
+export class RouteRateLimiter {
+  private buckets = new Map<string, Bucket>();
+
+  constructor(private readonly limits: RouteLimit[]) {}
+
+  get enabled(): boolean {
+    return this.limits.length > 0;
+  }
+
+  /**
+   * Take a token for keyPrefix on route. Returns null when the route is
+   * unlimited or a token was available; otherwise the seconds until one is.
+   */
+  take(keyPrefix: string, route: string, nowMs: number = Date.now()): { retryAfterSeconds: number } | null {
+    const limit = findRouteLimit(this.limits, route);
+    if (!limit) return null;
+
+    const refillPerMs = limit.perMinute / 60_000;
+    const id = `${keyPrefix}\0${limit.route}`;
+    const bucket = this.buckets.get(id) ?? { limit, tokens: limit.burst, updatedMs: nowMs };
+    bucket.tokens = Math.min(limit.burst, bucket.tokens + (nowMs - bucket.updatedMs) * refillPerMs);
+    bucket.updatedMs = nowMs;
+
+    if (bucket.tokens < 1) {
+      this.buckets.set(id, bucket);
+      return { retryAfterSeconds: Math.max(1, Math.ceil((1 - bucket.tokens) / refillPerMs / 1000)) };
+    }
+    bucket.tokens -= 1;
+    this.buckets.set(id, bucket);
+    if (this.buckets.size > MAX_BUCKETS) this.prune(nowMs);
+    return null;
+  }
+
+  /** Drop buckets that have refilled completely; they carry no state */
+  private prune(nowMs: number): void {
+    for (const [id, { limit, tokens, updatedMs }] of this.buckets) {
+      if (tokens + (nowMs - updatedMs) * (limit.perMinute / 60_000) >= limit.burst) {
+        this.buckets.delete(id);
+      }
+    }
+  }
+}
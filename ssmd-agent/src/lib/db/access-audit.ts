@@ -0,0 +1,137 @@
+/**
+ * Structured access audit for market data access.
+ * Every authenticated request produces one record, written as a JSON line
+ * to stdout and, when enabled, batch-inserted into api_access_log.
+ */
+import { and, desc, eq, gte, lt, type SQL } from "drizzle-orm";
+import { apiAccessLog, type ApiAccessLogEntry, type NewApiAccessLogEntry } from "./schema.ts";
+import type { Database } from "./client.ts";
+
+const FLUSH_INTERVAL_MS = 5_000;
+const FLUSH_THRESHOLD = 100;
+const MAX_BUFFER_SIZE = 5_000;
+
+export interface AccessAuditEntry {
+  keyPrefix: string;
+  userEmail: string | null;
+  method: string;
+  /** Normalized route (see normalizePath), not the raw URL */
+  route: string;
+  /** Feed the request read, from the feed query or path parameter */
+  dataset: string | null;
+  statusCode: number;
+  /** Body bytes sent; null when the client disconnected mid-stream */
+  responseBytes: number | null;
+  /** Time until the handler returned a response */
+  latencyMs: number;
+}
+
+export interface AccessAuditFilter {
+  keyPrefix?: string;
+  dataset?: string;
+  route?: string;
+  since?: Date;
+  until?: Date;
+  limit: number;
+}
+
+/** One structured stdout line; "log":"access" lets log queries select it */
+export function accessAuditLine(entry: AccessAuditEntry, at: Date = new Date()): string {
+  return JSON.stringify({
+    log: "access",
+    ts: at.toISOString(),
+    key_prefix: entry.keyPrefix,
+    user_email: entry.userEmail,
+    method: entry.method,
+    route: entry.route,
+    dataset: entry.dataset,
+    status: entry.statusCode,
+    bytes: entry.responseBytes,
+    latency_ms: entry.latencyMs,
+  });
+}
+
+/**
+ * Buffers audit records and bulk-inserts them on a timer (5s) or when the
+ * buffer reaches 100 rows, like RequestLogBuffer. Failed flushes are retried;
+ * past 5000 pending rows the oldest are dropped (stdout still has them).
+ */
+export class AccessAuditBuffer {
+  private buffer: NewApiAccessLogEntry[] = [];
+  private timer: ReturnType<typeof setInterval> | null = null;
+
+  constructor(private readonly db: Database) {}
+
+  start(): void {
+    if (this.timer) return;
+    this.timer = setInterval(() => {
+      this.flush().catch((err) => console.error("Access audit flush failed:", err));
+    }, FLUSH_INTERVAL_MS);
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  push(entry: AccessAuditEntry): void {
+    this.buffer.push({ ...entry });
+    this.trim();
+    if (this.buffer.length >= FLUSH_THRESHOLD) {
+      this.flush().catch((err) => console.error("Access audit flush failed:", err));
+    }
+  }
+
+  async flush(): Promise<void> {
+    if (this.buffer.length === 0) return;
+    const rows = this.buffer.splice(0);
+    try {
+      await this.db.insert(apiAccessLog).values(rows);
+    } catch (err) {
+      this.buffer.unshift(...rows);
+      this.trim();
+      throw err;
+    }
+  }
+
+  async shutdown(): Promise<void> {
+    this.stop();
+    await this.flush();
+  }
+
+  get size(): number {
+    return this.buffer.length;
+  }
+
+  private trim(): void {
+    if (this.buffer.length > MAX_BUFFER_SIZE) {
+      const dropped = this.buffer.length - MAX_BUFFER_SIZE;
+      this.buffer = this.buffer.slice(dropped);
+      console.warn(`Access audit buffer overflow: dropped ${dropped} oldest entries`);
+    }
+  }
+}
+
+/**
+ * Query the audit table, newest first.
+ */
+export async function listAccessAudit(
+  db: Database,
+  filter: AccessAuditFilter,
+): Promise<ApiAccessLogEntry[]> {
+  const conditions: SQL[] = [];
+  if (filter.keyPrefix) conditions.push(eq(apiAccessLog.keyPrefix, filter.keyPrefix));
+  if (filter.dataset) conditions.push(eq(apiAccessLog.dataset, filter.dataset));
+  if (filter.route) conditions.push(eq(apiAccessLog.route, filter.route));
+  if (filter.since) conditions.push(gte(apiAccessLog.createdAt, filter.since));
+  if (filter.until) conditions.push(lt(apiAccessLog.createdAt, filter.until));
+
+  return db
+    .select()
+    .from(apiAccessLog)
+    .where(conditions.length > 0 ? and(...conditions) : undefined)
+    .orderBy(desc(apiAccessLog.createdAt))
+    .limit(filter.limit);
+}
@@ -0,0 +1,63 @@
+/**
+ * Order book snapshot operations (Drizzle ORM)
+ */
+import { and, desc, eq, lte } from "drizzle-orm";
+import { type Database, getRawSql } from "./client.ts";
+import { bookSnapshots, type BookSnapshot, type NewBookSnapshot } from "./schema.ts";
+
+// Each row carries two JSONB arrays; keep batches small
+const BOOK_SNAPSHOTS_BATCH_SIZE = 500;
+
+/**
+ * Batch insert book snapshot rows. Books with no levels on either side are skipped.
+ */
+export async function insertBookSnapshots(
+  db: Database,
+  snapshots: NewBookSnapshot[],
+): Promise<number> {
+  const rows = snapshots.filter((s) => s.bids.length > 0 || s.asks.length > 0);
+  if (rows.length === 0) return 0;
+
+  for (let i = 0; i < rows.length; i += BOOK_SNAPSHOTS_BATCH_SIZE) {
+    await db.insert(bookSnapshots).values(rows.slice(i, i + BOOK_SNAPSHOTS_BATCH_SIZE));
+  }
+  return rows.length;
+}
+
+/**
+ * Book for a ticker as of a time: the latest snapshot at or before `at`
+ * (default now), or null if none was written by then.
+ */
+export async function getBookAt(
+  db: Database,
+  feed: string,
+  ticker: string,
+  at: Date = new Date(),
+): Promise<BookSnapshot | null> {
+  const [row] = await db
+    .select()
+    .from(bookSnapshots)
+    .where(and(
+      eq(bookSnapshots.feed, feed),
+      eq(bookSnapshots.ticker, ticker),
+      lte(bookSnapshots.snapshotAt, at),
+    ))
+    .orderBy(desc(bookSnapshots.snapshotAt))
+    .limit(1);
+  return row ?? null;
+}
+
+/**
+ * Delete book snapshots older than the retention period. Returns the row
+ * count rather than RETURNING ids, since a day of books is many rows.
+ */
+export async function cleanupOldBookSnapshots(
+  retentionDays = 7,
+): Promise<number> {
+  const rawSql = getRawSql();
+  const result = await rawSql`
+    DELETE FROM book_snapshots
+    WHERE snapshot_at < NOW() - make_interval(days => ${retentionDays})
+  `;
+  return result.count;
+}
@@ -10,6 +10,74 @@ export type Database = ReturnType<typeof drizzle<typeof schema>>;
 let db: Database | null = null;
 let sql: ReturnType<typeof postgres> | null = null;
 
+/**
+ * Connection pool settings. Statements are prepared (postgres.js caches them
+ * per connection), and statement_timeout bounds every query server-side.
+ */
+export interface PoolOptions {
+  max: number;
+  idleTimeoutSec: number;
+  connectTimeoutSec: number;
+  statementTimeoutMs: number;
+}
+
+/**
+ * Pool settings from DB_POOL_MAX, DB_IDLE_TIMEOUT_SEC, DB_CONNECT_TIMEOUT_SEC
+ * and DB_STATEMENT_TIMEOUT_MS (0 disables the timeout).
+ */
+export function poolOptionsFromEnv(): PoolOptions {
+  const num = (name: string, fallback: number): number => {
+    const v = Number(Deno.env.get(name));
+    return Deno.env.get(name) && Number.isFinite(v) && v >= 0 ? v : fallback;
+  };
+  return {
+    max: num("DB_POOL_MAX", 10),
+    idleTimeoutSec: num("DB_IDLE_TIMEOUT_SEC", 30),
+    connectTimeoutSec: num("DB_CONNECT_TIMEOUT_SEC", 10),
+    statementTimeoutMs: num("DB_STATEMENT_TIMEOUT_MS", 30000),
+  };
+}
+
+/**
+ * Create a postgres.js pool with the given settings.
+ */
+export function createPool(url: string, options: PoolOptions = poolOptionsFromEnv()): ReturnType<typeof postgres> {
+  return postgres(url, {
+    max: options.max,
+    idle_timeout: options.idleTimeoutSec,
+    connect_timeout: options.connectTimeoutSec,
+    prepare: true,
+    connection: options.statementTimeoutMs > 0 ? { statement_timeout: options.statementTimeoutMs } : {},
+  });
+}
+
+/**
+ * Receives the duration of each timedQuery. The server registers one to
+ * export statement metrics; lib code stays free of server imports.
+ */
+export type QueryObserver = (name: string, durationMs: number, ok: boolean) => void;
+
+let queryObserver: QueryObserver | null = null;
+
+export function setQueryObserver(observer: QueryObserver | null): void {
+  queryObserver = observer;
+}
+
+/**
+ * Run a named query and report its duration and outcome to the observer.
+ */
+export async function timedQuery<T>(name: string, fn: () => Promise<T>): Promise<T> {
+  const start = performance.now();
+  let ok = false;
+  try {
+    const result = await fn();
+    ok = true;
+    return result;
+  } finally {
+    queryObserver?.(name, performance.now() - start, ok);
+  }
+}
+
 /**
  * Get the Drizzle database instance.
  * Creates connection pool on first call.
@@ -20,11 +88,7 @@ export function getDb(): Database {
     if (!url) {
       throw new Error("DATABASE_URL environment variable not set");
     }
-    sql = postgres(url, {
-      max: 10,
-      idle_timeout: 30,
-      connect_timeout: 10,
-    });
+    sql = createPool(url);
     db = drizzle(sql, {
       schema,
       logger: Deno.env.get("DRIZZLE_LOG") === "true"
@@ -1,9 +1,9 @@
 /**
  * Event database operations with upsert support (Drizzle ORM)
  */
-import { eq, isNull, desc, sql, inArray, count } from "drizzle-orm";
+import { and, eq, isNull, desc, sql, inArray, count } from "drizzle-orm";
 import { type Database, getRawSql } from "./client.ts";
-import { events, markets, type Event, type NewEvent } from "./schema.ts";
+import { DEFAULT_EXCHANGE, events, markets, type Event, type NewEvent } from "./schema.ts";
 import type { Event as ApiEvent } from "../types/event.ts";
 
 /**
@@ -11,6 +11,7 @@ import type { Event as ApiEvent } from "../types/event.ts";
  */
 function toNewEvent(e: ApiEvent): NewEvent {
   return {
+    exchange: e.exchange ?? DEFAULT_EXCHANGE,
     eventTicker: e.event_ticker,
     title: e.title,
     category: e.category,
@@ -37,10 +38,10 @@ export async function upsertEvents(
     return 0;
   }
 
-  // Deduplicate by event_ticker (keep last occurrence)
+  // Deduplicate by (exchange, event_ticker) (keep last occurrence)
   const seen = new Map<string, ApiEvent>();
   for (const e of eventList) {
-    seen.set(e.event_ticker, e);
+    seen.set(`${e.exchange ?? DEFAULT_EXCHANGE}:${e.event_ticker}`, e);
   }
   const dedupedList = Array.from(seen.values());
 
@@ -53,7 +54,7 @@ export async function upsertEvents(
       .insert(events)
       .values(chunk)
       .onConflictDoUpdate({
-        target: events.eventTicker,
+        target: [events.exchange, events.eventTicker],
         set: {
           title: sql`excluded.title`,
           category: sql`excluded.category`,
@@ -91,12 +92,13 @@ export async function bulkUpsertEvents(
 }
 
 /**
- * Get set of existing event tickers for FK validation.
+ * Get set of existing event tickers on an exchange for FK validation.
  * Used to filter markets before insert to avoid FK violations.
  */
 export async function getExistingEventTickers(
   db: Database,
-  eventTickers: string[]
+  eventTickers: string[],
+  exchange: string = DEFAULT_EXCHANGE
 ): Promise<Set<string>> {
   if (eventTickers.length === 0) {
     return new Set();
@@ -106,12 +108,34 @@ export async function getExistingEventTickers(
     .select({ eventTicker: events.eventTicker })
     .from(events)
     .where(
-      sql`${inArray(events.eventTicker, eventTickers)} AND ${isNull(events.deletedAt)}`
+      sql`${eq(events.exchange, exchange)} AND ${inArray(events.eventTicker, eventTickers)} AND ${isNull(events.deletedAt)}`
     );
 
   return new Set(rows.map((r) => r.eventTicker));
 }
 
+/**
+ * Get an exchange's live (not soft-deleted) events by ticker, keyed by ticker.
+ * Used by secmaster sync --dry-run to diff fetched events against the table.
+ */
+export async function getEventsByTickers(
+  db: Database,
+  eventTickers: string[],
+  exchange: string = DEFAULT_EXCHANGE
+): Promise<Map<string, EventRow>> {
+  const found = new Map<string, EventRow>();
+  for (let i = 0; i < eventTickers.length; i += EVENTS_BATCH_SIZE) {
+    const rows = await db
+      .select()
+      .from(events)
+      .where(
+        sql`${eq(events.exchange, exchange)} AND ${inArray(events.eventTicker, eventTickers.slice(i, i + EVENTS_BATCH_SIZE))} AND ${isNull(events.deletedAt)}`
+      );
+    for (const row of rows) found.set(row.eventTicker, row);
+  }
+  return found;
+}
+
 /**
  * Initialize a temp table for streaming ticker collection.
  * Call once before the sync loop, then appendEventTickers() per batch.
@@ -132,14 +156,18 @@ export async function appendEventTickers(conn: ReturnType<typeof getRawSql>, tic
 }
 
 /**
- * Soft delete events not in the temp table (populated by appendEventTickers).
+ * Soft delete an exchange's events not in the temp table (populated by appendEventTickers).
  * @param conn - Same reserved connection used for initEventTickerTable
  */
-export async function softDeleteMissingEvents(conn: ReturnType<typeof getRawSql>): Promise<number> {
+export async function softDeleteMissingEvents(
+  conn: ReturnType<typeof getRawSql>,
+  exchange: string = DEFAULT_EXCHANGE
+): Promise<number> {
   const result = await conn`
     UPDATE events
     SET deleted_at = NOW()
     WHERE deleted_at IS NULL
+      AND exchange = ${exchange}
       AND event_ticker NOT IN (SELECT event_ticker FROM temp_current_events)
     RETURNING event_ticker
   `;
@@ -160,6 +188,7 @@ export type EventRow = Event;
 export async function listEvents(
   db: Database,
   options: {
+    exchange?: string;
     category?: string;
     status?: string;
     series?: string;
@@ -179,6 +208,9 @@ export async function listEvents(
     sql`(${events.deletedAt} IS NULL OR ${events.deletedAt} > ${asOf})`,
   ];
 
+  if (options.exchange) {
+    conditions.push(eq(events.exchange, options.exchange));
+  }
   if (options.category) {
     conditions.push(eq(events.category, options.category));
   }
@@ -196,6 +228,7 @@ export async function listEvents(
 
   return await db
     .select({
+      exchange: events.exchange,
       eventTicker: events.eventTicker,
       title: events.title,
       category: events.category,
@@ -211,23 +244,25 @@ export async function listEvents(
     .from(events)
     .leftJoin(
       markets,
-      sql`${markets.eventTicker} = ${events.eventTicker} AND ${isNull(markets.deletedAt)}`
+      sql`${markets.exchange} = ${events.exchange} AND ${markets.eventTicker} = ${events.eventTicker} AND ${isNull(markets.deletedAt)}`
     )
     .where(sql.join(conditions, sql` AND `))
-    .groupBy(events.eventTicker)
+    .groupBy(events.exchange, events.eventTicker)
     .orderBy(desc(events.updatedAt))
     .limit(limit);
 }
 
 /**
- * Get a single event by ticker with its market count.
+ * Get a single event by exchange and ticker with its market count.
  */
 export async function getEvent(
   db: Database,
-  eventTicker: string
+  eventTicker: string,
+  exchange: string = DEFAULT_EXCHANGE
 ): Promise<(EventRow & { marketCount: number }) | null> {
   const rows = await db
     .select({
+      exchange: events.exchange,
       eventTicker: events.eventTicker,
       title: events.title,
       category: events.category,
@@ -243,12 +278,12 @@ export async function getEvent(
     .from(events)
     .leftJoin(
       markets,
-      sql`${markets.eventTicker} = ${events.eventTicker} AND ${isNull(markets.deletedAt)}`
+      sql`${markets.exchange} = ${events.exchange} AND ${markets.eventTicker} = ${events.eventTicker} AND ${isNull(markets.deletedAt)}`
     )
     .where(
-      sql`${eq(events.eventTicker, eventTicker)} AND ${isNull(events.deletedAt)}`
+      sql`${and(eq(events.exchange, exchange), eq(events.eventTicker, eventTicker))} AND ${isNull(events.deletedAt)}`
     )
-    .groupBy(events.eventTicker);
+    .groupBy(events.exchange, events.eventTicker);
 
   if (rows.length === 0) {
     return null;
@@ -258,7 +293,7 @@ export async function getEvent(
 }
 
 /**
- * Upsert a single event from lifecycle data (simpler than API format).
+ * Upsert a single Kalshi event from lifecycle data (simpler than API format).
  * Used by lifecycle-consumer for real-time market creation.
  */
 export async function upsertEventFromLifecycle(
@@ -271,6 +306,7 @@ export async function upsertEventFromLifecycle(
   await db
     .insert(events)
     .values({
+      exchange: DEFAULT_EXCHANGE,
       eventTicker,
       title,
       category,
@@ -278,7 +314,7 @@ export async function upsertEventFromLifecycle(
       status: "active",
     })
     .onConflictDoUpdate({
-      target: events.eventTicker,
+      target: [events.exchange, events.eventTicker],
       set: {
         title,
         category,
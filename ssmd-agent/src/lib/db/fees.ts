@@ -5,7 +5,7 @@
 import { eq, isNull, desc, and, lte, or, gt, lt, sql, asc } from "drizzle-orm";
 import type { Database } from "./client.ts";
 import { seriesFees, type SeriesFee } from "./schema.ts";
-import { type SeriesFeeChange, type FeeType, FeeTypeSchema } from "../types/fee.ts";
+import { type SeriesFeeChange, type FeeType, type FeeUpdate, FeeTypeSchema } from "../types/fee.ts";
 
 /**
  * Result of fee sync operation
@@ -185,6 +185,89 @@ export async function listCurrentFees(
   return rows;
 }
 
+/**
+ * Full fee history for a series, newest period first.
+ */
+export async function listFeeHistory(
+  db: Database,
+  seriesTicker: string
+): Promise<SeriesFee[]> {
+  return await db
+    .select()
+    .from(seriesFees)
+    .where(eq(seriesFees.seriesTicker, seriesTicker))
+    .orderBy(desc(seriesFees.effectiveFrom));
+}
+
+/**
+ * Set a series fee schedule by hand (admin override). The period in force at
+ * effective_from is closed there; if a later period already exists, the new
+ * one ends where it starts, so history stays non-overlapping. A period
+ * starting at exactly effective_from is replaced in place.
+ */
+export async function setFee(
+  db: Database,
+  seriesTicker: string,
+  update: FeeUpdate
+): Promise<SeriesFee> {
+  const effectiveFrom = update.effective_from ? new Date(update.effective_from) : new Date();
+  const values = {
+    feeType: update.fee_type,
+    feeMultiplier: update.fee_multiplier.toString(),
+    sourceId: `manual:${effectiveFrom.toISOString()}`,
+  };
+
+  const replaced = await db
+    .update(seriesFees)
+    .set(values)
+    .where(
+      and(
+        eq(seriesFees.seriesTicker, seriesTicker),
+        eq(seriesFees.effectiveFrom, effectiveFrom)
+      )
+    )
+    .returning();
+  if (replaced.length > 0) {
+    return replaced[0];
+  }
+
+  const next = await db
+    .select({ effectiveFrom: seriesFees.effectiveFrom })
+    .from(seriesFees)
+    .where(
+      and(
+        eq(seriesFees.seriesTicker, seriesTicker),
+        gt(seriesFees.effectiveFrom, effectiveFrom)
+      )
+    )
+    .orderBy(asc(seriesFees.effectiveFrom))
+    .limit(1);
+
+  // Close the period in force at effectiveFrom
+  await db
+    .update(seriesFees)
+    .set({ effectiveTo: effectiveFrom })
+    .where(
+      and(
+        eq(seriesFees.seriesTicker, seriesTicker),
+        lt(seriesFees.effectiveFrom, effectiveFrom),
+        or(isNull(seriesFees.effectiveTo), gt(seriesFees.effectiveTo, effectiveFrom))
+      )
+    );
+
+  const inserted = await db
+    .insert(seriesFees)
+    .values({
+      seriesTicker,
+      ...values,
+      effectiveFrom,
+      effectiveTo: next.length > 0 ? next[0].effectiveFrom : null,
+    })
+    .returning();
+
+  return inserted[0];
+}
+
 /**
  * Seed fee records for series that have no fee_changes but do have
  * fee_type/fee_multiplier on their series metadata from the Kalshi API.
@@ -2,9 +2,10 @@
  * Cross-feed market lookup by IDs.
  * Searches Kalshi markets, Kraken pairs, and Polymarket conditions/tokens.
  */
-import { eq, inArray, isNull, sql } from "drizzle-orm";
+import { and, eq, inArray, isNull, sql } from "drizzle-orm";
 import { type Database } from "./client.ts";
 import {
+  DEFAULT_EXCHANGE,
   markets,
   events,
   pairs,
@@ -61,10 +62,10 @@ export async function lookupMarketsByIds(
             openInterest: markets.openInterest,
           })
           .from(markets)
-          .innerJoin(events, eq(markets.eventTicker, events.eventTicker))
+          .innerJoin(events, and(eq(markets.exchange, events.exchange), eq(markets.eventTicker, events.eventTicker)))
           .where(
             sql.join(
-              [inArray(markets.ticker, ids), isNull(markets.deletedAt)],
+              [eq(markets.exchange, DEFAULT_EXCHANGE), inArray(markets.ticker, ids), isNull(markets.deletedAt)],
               sql` AND `,
             ),
           );
@@ -1,11 +1,17 @@
 /**
  * Market database operations with upsert support (Drizzle ORM)
  */
-import { eq, isNull, desc, sql, count } from "drizzle-orm";
+import { and, eq, isNull, asc, desc, sql, count, inArray } from "drizzle-orm";
 import { type Database, getRawSql } from "./client.ts";
-import { markets, events, series, type Market, type NewMarket } from "./schema.ts";
+import { DEFAULT_EXCHANGE, markets, events, series, type Market, type NewMarket } from "./schema.ts";
 import { getExistingEventTickers } from "./events.ts";
-import type { Market as ApiMarket } from "../types/market.ts";
+import {
+  type Market as ApiMarket,
+  type MarketPrices,
+  marketPrices,
+  type MarketPricesJson,
+  marketPricesToJson,
+} from "../types/market.ts";
 
 export interface UpsertResult {
   total: number;
@@ -23,6 +29,7 @@ function numToStr(n: number | null | undefined): string | null {
  */
 function toNewMarket(m: ApiMarket): NewMarket {
   return {
+    exchange: m.exchange ?? DEFAULT_EXCHANGE,
     ticker: m.ticker,
     eventTicker: m.event_ticker,
     title: m.title,
@@ -67,12 +74,24 @@ export async function upsertMarkets(
     return { total: 0, skipped: 0 };
   }
 
-  // Collect unique event tickers
-  const eventTickers = [...new Set(marketList.map((m) => m.event_ticker))];
+  // Collect unique event tickers per exchange
+  const eventTickersByExchange = new Map<string, Set<string>>();
+  for (const m of marketList) {
+    const exchange = m.exchange ?? DEFAULT_EXCHANGE;
+    if (!eventTickersByExchange.has(exchange)) eventTickersByExchange.set(exchange, new Set());
+    eventTickersByExchange.get(exchange)!.add(m.event_ticker);
+  }
 
   // Check for missing parent events (FK constraint)
-  const existingEvents = await getExistingEventTickers(db, eventTickers);
-  const missingEvents = eventTickers.filter((t) => !existingEvents.has(t));
+  const missingEvents: string[] = [];
+  for (const [exchange, tickers] of eventTickersByExchange) {
+    const existingEvents = await getExistingEventTickers(db, [...tickers], exchange);
+    for (const t of tickers) {
+      if (!existingEvents.has(t)) {
+        missingEvents.push(exchange === DEFAULT_EXCHANGE ? t : `${exchange}:${t}`);
+      }
+    }
+  }
 
   if (missingEvents.length > 0) {
     const sample = missingEvents.slice(0, 5).join(", ");
@@ -92,7 +111,7 @@ export async function upsertMarkets(
       .insert(markets)
       .values(chunk)
       .onConflictDoUpdate({
-        target: markets.ticker,
+        target: [markets.exchange, markets.ticker],
         set: {
           eventTicker: sql`excluded.event_ticker`,
           title: sql`excluded.title`,
@@ -152,6 +171,28 @@ export async function bulkUpsertMarkets(
   return { batches: 1, ...result };
 }
 
+/**
+ * Get an exchange's live (not soft-deleted) markets by ticker, keyed by ticker.
+ * Used by secmaster sync --dry-run to diff fetched markets against the table.
+ */
+export async function getMarketsByTickers(
+  db: Database,
+  tickers: string[],
+  exchange: string = DEFAULT_EXCHANGE
+): Promise<Map<string, MarketRow>> {
+  const found = new Map<string, MarketRow>();
+  for (let i = 0; i < tickers.length; i += MARKETS_BATCH_SIZE) {
+    const rows = await db
+      .select()
+      .from(markets)
+      .where(
+        sql`${eq(markets.exchange, exchange)} AND ${inArray(markets.ticker, tickers.slice(i, i + MARKETS_BATCH_SIZE))} AND ${isNull(markets.deletedAt)}`
+      );
+    for (const row of rows) found.set(row.ticker, row);
+  }
+  return found;
+}
+
 /**
  * Initialize a temp table for streaming ticker collection.
  * Call once before the sync loop, then appendMarketTickers() per batch.
@@ -172,14 +213,18 @@ export async function appendMarketTickers(conn: ReturnType<typeof getRawSql>, ti
 }
 
 /**
- * Soft delete markets not in the temp table (populated by appendMarketTickers).
+ * Soft delete an exchange's markets not in the temp table (populated by appendMarketTickers).
  * @param conn - Same reserved connection used for initMarketTickerTable
  */
-export async function softDeleteMissingMarkets(conn: ReturnType<typeof getRawSql>): Promise<number> {
+export async function softDeleteMissingMarkets(
+  conn: ReturnType<typeof getRawSql>,
+  exchange: string = DEFAULT_EXCHANGE
+): Promise<number> {
   const result = await conn`
     UPDATE markets
     SET deleted_at = NOW()
     WHERE deleted_at IS NULL
+      AND exchange = ${exchange}
       AND ticker NOT IN (SELECT ticker FROM temp_current_markets)
     RETURNING ticker
   `;
@@ -192,6 +237,32 @@ export async function softDeleteMissingMarkets(conn: ReturnType<typeof getRawSql
  */
 export type MarketRow = Market;
 
+/**
+ * Typed prices of a market row. The NUMERIC price columns scan as dollar
+ * strings (e.g. "0.5500"); the Prices carry major_e4 units and currency.
+ */
+export function scanMarketPrices(
+  row: Pick<MarketRow, "yesBid" | "yesAsk" | "noBid" | "noAsk" | "lastPrice">,
+): MarketPrices {
+  return marketPrices({
+    yes_bid: row.yesBid,
+    yes_ask: row.yesAsk,
+    no_bid: row.noBid,
+    no_ask: row.noAsk,
+    last_price: row.lastPrice,
+  });
+}
+
+/**
+ * Market as served by the API: the row with its raw dollar columns plus
+ * `prices`, each price in major_e4 units, currency and decimal form
+ */
+export type MarketResponse<T extends MarketRow = MarketRow> = T & { prices: MarketPricesJson };
+
+export function toMarketResponse<T extends MarketRow>(row: T): MarketResponse<T> {
+  return { ...row, prices: marketPricesToJson(scanMarketPrices(row)) };
+}
+
 /**
  * Result of listMarketsWithSnapshot - includes CDC sync metadata
  */
@@ -209,18 +280,7 @@ export interface MarketsWithSnapshot {
  */
 export async function listMarketsWithSnapshot(
   db: Database,
-  options: {
-    category?: string;
-    status?: string;
-    series?: string;
-    eventTicker?: string;
-    closingBefore?: string;
-    closingAfter?: string;
-    openBefore?: string;
-    asOf?: string;
-    gamesOnly?: boolean;
-    limit?: number;
-  } = {}
+  options: Parameters<typeof listMarkets>[1] = {}
 ): Promise<MarketsWithSnapshot> {
   const rawSql = getRawSql();
 
@@ -244,28 +304,80 @@ export async function listMarketsWithSnapshot(
   };
 }
 
+/** Sort orders for listMarkets; updated_at and volume sort descending, close_time ascending */
+export type MarketSort = "updated_at" | "close_time" | "volume";
+
+export const MARKET_SORTS: readonly MarketSort[] = ["updated_at", "close_time", "volume"];
+
+/** Filters shared by listMarkets and countMarkets */
+export interface MarketFilters {
+  exchange?: string;
+  category?: string;
+  status?: string;
+  series?: string;
+  eventTicker?: string;
+  closingBefore?: string;
+  closingAfter?: string;
+  openBefore?: string;
+  asOf?: string;
+  gamesOnly?: boolean;
+  /** Minimum lifetime volume (contracts); missing volume counts as 0 */
+  minVolume?: number;
+  /** Minimum 24h volume (contracts); missing volume counts as 0 */
+  minVolume24h?: number;
+}
+
 /**
- * List markets with optional filters.
- * @param options.asOf - Point-in-time filter (ISO timestamp). Returns markets that existed
- *                       and were tradeable at this time. Defaults to now.
- * @param options.gamesOnly - If true, only return markets from series where is_game = true
+ * Sort key per MarketSort. Nullable columns are coalesced so the keyset
+ * comparison never meets NULL: markets without a close time sort last, and
+ * missing volume counts as 0.
  */
-export async function listMarkets(
-  db: Database,
-  options: {
-    category?: string;
-    status?: string;
-    series?: string;
-    eventTicker?: string;
-    closingBefore?: string;
-    closingAfter?: string;
-    openBefore?: string;
-    asOf?: string;
-    gamesOnly?: boolean;
-    limit?: number;
-  } = {}
-): Promise<MarketRow[]> {
-  const limit = options.limit ?? 100;
+function marketSortKey(sort: MarketSort) {
+  switch (sort) {
+    case "close_time":
+      return { expr: sql`COALESCE(${markets.closeTime}, 'infinity'::timestamptz)`, type: "timestamptz", desc: false };
+    case "volume":
+      return { expr: sql`COALESCE(${markets.volume}, 0)`, type: "bigint", desc: true };
+    default:
+      return { expr: sql`${markets.updatedAt}`, type: "timestamptz", desc: true };
+  }
+}
+
+/** Position after a row in a keyset-paginated listing */
+export interface MarketCursor {
+  sortValue: string;
+  exchange: string;
+  ticker: string;
+}
+
+/**
+ * Encode a keyset cursor: the last row's sort key (as Postgres text, keeping
+ * microsecond precision), then exchange and ticker as the tiebreaker, since a
+ * ticker is only unique within its exchange.
+ */
+export function encodeMarketCursor(sortValue: string, exchange: string, ticker: string): string {
+  return btoa(JSON.stringify([sortValue, exchange, ticker])).replaceAll("+", "-").replaceAll("/", "_").replace(/=+$/, "");
+}
+
+/** Decode a cursor from encodeMarketCursor, or null if it is malformed */
+export function decodeMarketCursor(cursor: string): MarketCursor | null {
+  try {
+    const padded = cursor.replaceAll("-", "+").replaceAll("_", "/");
+    const decoded = JSON.parse(atob(padded + "=".repeat((4 - padded.length % 4) % 4)));
+    if (!Array.isArray(decoded) || decoded.length !== 3 || !decoded.every((v) => typeof v === "string")) {
+      return null;
+    }
+    return { sortValue: decoded[0], exchange: decoded[1], ticker: decoded[2] };
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Build the WHERE conditions for market filters. Category, series and
+ * gamesOnly need events (and series) joined.
+ */
+function marketFilterConditions(options: MarketFilters) {
   const asOf = options.asOf ?? new Date().toISOString();
 
   // Build conditions array with point-in-time filtering
@@ -278,6 +390,9 @@ export async function listMarkets(
     sql`(${markets.deletedAt} IS NULL OR ${markets.deletedAt} > ${asOf})`,
   ];
 
+  if (options.exchange) {
+    conditions.push(eq(markets.exchange, options.exchange));
+  }
   if (options.status) {
     conditions.push(eq(markets.status, options.status));
   }
@@ -293,97 +408,118 @@ export async function listMarkets(
   if (options.openBefore) {
     conditions.push(sql`(${markets.openTime} IS NULL OR ${markets.openTime} <= ${options.openBefore})`);
   }
+  if (options.minVolume !== undefined) {
+    conditions.push(sql`COALESCE(${markets.volume}, 0) >= ${options.minVolume}`);
+  }
+  if (options.minVolume24h !== undefined) {
+    conditions.push(sql`COALESCE(${markets.volume24h}, 0) >= ${options.minVolume24h}`);
+  }
 
-  // If filtering by category, series, or gamesOnly, need to join events (and possibly series)
-  if (options.category || options.series || options.gamesOnly) {
-    const eventConditions: ReturnType<typeof sql>[] = [];
-    if (options.category) {
-      eventConditions.push(eq(events.category, options.category));
-    }
-    if (options.series) {
-      // Case-insensitive match (Kalshi tickers are uppercase but allow lowercase input)
-      eventConditions.push(sql`LOWER(${events.seriesTicker}) = LOWER(${options.series})`);
-    }
+  const eventConditions: ReturnType<typeof sql>[] = [];
+  if (options.category) {
+    eventConditions.push(eq(events.category, options.category));
+  }
+  if (options.series) {
+    // Case-insensitive match (Kalshi tickers are uppercase but allow lowercase input)
+    eventConditions.push(sql`LOWER(${events.seriesTicker}) = LOWER(${options.series})`);
+  }
+  if (options.gamesOnly) {
+    eventConditions.push(eq(series.isGame, true));
+  }
 
-    // If gamesOnly, need to join series table and filter on is_game
-    if (options.gamesOnly) {
-      const rows = await db
-        .select({
-          ticker: markets.ticker,
-          eventTicker: markets.eventTicker,
-          title: markets.title,
-          status: markets.status,
-          closeTime: markets.closeTime,
-          yesBid: markets.yesBid,
-          yesAsk: markets.yesAsk,
-          noBid: markets.noBid,
-          noAsk: markets.noAsk,
-          lastPrice: markets.lastPrice,
-          volume: markets.volume,
-          volume24h: markets.volume24h,
-          openInterest: markets.openInterest,
-          floorStrike: markets.floorStrike,
-          capStrike: markets.capStrike,
-          strikeType: markets.strikeType,
-          result: markets.result,
-          expirationValue: markets.expirationValue,
-          yesSubTitle: markets.yesSubTitle,
-          noSubTitle: markets.noSubTitle,
-          canCloseEarly: markets.canCloseEarly,
-          marketType: markets.marketType,
-          openTime: markets.openTime,
-          expectedExpirationTime: markets.expectedExpirationTime,
-          createdAt: markets.createdAt,
-          updatedAt: markets.updatedAt,
-          deletedAt: markets.deletedAt,
-        })
-        .from(markets)
-        .innerJoin(events, eq(markets.eventTicker, events.eventTicker))
-        .innerJoin(series, eq(events.seriesTicker, series.ticker))
-        .where(sql.join([...conditions, ...eventConditions, eq(series.isGame, true)], sql` AND `))
-        .orderBy(desc(markets.updatedAt))
-        .limit(limit);
-
-      return rows;
-    }
+  return {
+    conditions,
+    eventConditions,
+    joinEvents: Boolean(options.category || options.series || options.gamesOnly),
+    joinSeries: Boolean(options.gamesOnly),
+  };
+}
 
-    const rows = await db
-      .select({
-        ticker: markets.ticker,
-        eventTicker: markets.eventTicker,
-        title: markets.title,
-        status: markets.status,
-        closeTime: markets.closeTime,
-        yesBid: markets.yesBid,
-        yesAsk: markets.yesAsk,
-        noBid: markets.noBid,
-        noAsk: markets.noAsk,
-        lastPrice: markets.lastPrice,
-        volume: markets.volume,
-        volume24h: markets.volume24h,
-        openInterest: markets.openInterest,
-        floorStrike: markets.floorStrike,
-        capStrike: markets.capStrike,
-        strikeType: markets.strikeType,
-        result: markets.result,
-        expirationValue: markets.expirationValue,
-        yesSubTitle: markets.yesSubTitle,
-        noSubTitle: markets.noSubTitle,
-        canCloseEarly: markets.canCloseEarly,
-        marketType: markets.marketType,
-        openTime: markets.openTime,
-        expectedExpirationTime: markets.expectedExpirationTime,
-        createdAt: markets.createdAt,
-        updatedAt: markets.updatedAt,
-        deletedAt: markets.deletedAt,
-      })
+/** Markets join their event on (exchange, event_ticker) */
+const marketEventJoin = and(eq(markets.exchange, events.exchange), eq(markets.eventTicker, events.eventTicker));
+
+/** Market columns, selected explicitly when events/series are joined */
+const marketColumns = {
+  exchange: markets.exchange,
+  ticker: markets.ticker,
+  eventTicker: markets.eventTicker,
+  title: markets.title,
+  status: markets.status,
+  closeTime: markets.closeTime,
+  yesBid: markets.yesBid,
+  yesAsk: markets.yesAsk,
+  noBid: markets.noBid,
+  noAsk: markets.noAsk,
+  lastPrice: markets.lastPrice,
+  volume: markets.volume,
+  volume24h: markets.volume24h,
+  openInterest: markets.openInterest,
+  floorStrike: markets.floorStrike,
+  capStrike: markets.capStrike,
+  strikeType: markets.strikeType,
+  result: markets.result,
+  expirationValue: markets.expirationValue,
+  yesSubTitle: markets.yesSubTitle,
+  noSubTitle: markets.noSubTitle,
+  canCloseEarly: markets.canCloseEarly,
+  marketType: markets.marketType,
+  openTime: markets.openTime,
+  expectedExpirationTime: markets.expectedExpirationTime,
+  createdAt: markets.createdAt,
+  updatedAt: markets.updatedAt,
+  deletedAt: markets.deletedAt,
+};
+
+/**
+ * List markets with optional filters.
+ * @param options.asOf - Point-in-time filter (ISO timestamp). Returns markets that existed
+ *                       and were tradeable at this time. Defaults to now.
+ * @param options.gamesOnly - If true, only return markets from series where is_game = true
+ * @param options.sort - Sort order (default updated_at)
+ * @param options.cursor - Keyset cursor from marketCursorAfter; returns the rows after it
+ */
+export async function listMarkets(
+  db: Database,
+  options: MarketFilters & {
+    sort?: MarketSort;
+    cursor?: MarketCursor;
+    limit?: number;
+  } = {}
+): Promise<MarketRow[]> {
+  const limit = options.limit ?? 100;
+  const { conditions, eventConditions, joinEvents, joinSeries } = marketFilterConditions(options);
+
+  const key = marketSortKey(options.sort ?? "updated_at");
+  const orderBy = key.desc
+    ? [desc(key.expr), desc(markets.exchange), desc(markets.ticker)]
+    : [asc(key.expr), asc(markets.exchange), asc(markets.ticker)];
+  if (options.cursor) {
+    const op = key.desc ? sql`<` : sql`>`;
+    const { sortValue, exchange, ticker } = options.cursor;
+    conditions.push(
+      sql`(${key.expr}, ${markets.exchange}, ${markets.ticker}) ${op} (CAST(${sortValue} AS ${sql.raw(key.type)}), ${exchange}, ${ticker})`
+    );
+  }
+
+  // If filtering by category, series, or gamesOnly, need to join events (and possibly series)
+  if (joinSeries) {
+    return await db
+      .select(marketColumns)
       .from(markets)
-      .innerJoin(events, eq(markets.eventTicker, events.eventTicker))
+      .innerJoin(events, marketEventJoin)
+      .innerJoin(series, eq(events.seriesTicker, series.ticker))
       .where(sql.join([...conditions, ...eventConditions], sql` AND `))
-      .orderBy(desc(markets.updatedAt))
+      .orderBy(...orderBy)
+      .limit(limit);
+  }
+  if (joinEvents) {
+    return await db
+      .select(marketColumns)
+      .from(markets)
+      .innerJoin(events, marketEventJoin)
+      .where(sql.join([...conditions, ...eventConditions], sql` AND `))
+      .orderBy(...orderBy)
       .limit(limit);
-
-    return rows;
   }
 
   // Simple query without join
@@ -391,24 +527,74 @@ export async function listMarkets(
     .select()
     .from(markets)
     .where(sql.join(conditions, sql` AND `))
-    .orderBy(desc(markets.updatedAt))
+    .orderBy(...orderBy)
     .limit(limit);
 
   return rows;
 }
 
 /**
- * Get a single market by ticker.
+ * Count markets matching the filters, ignoring sort, cursor and limit.
+ */
+export async function countMarkets(db: Database, options: MarketFilters = {}): Promise<number> {
+  const { conditions, eventConditions, joinEvents, joinSeries } = marketFilterConditions(options);
+
+  if (joinSeries) {
+    const [row] = await db
+      .select({ count: count() })
+      .from(markets)
+      .innerJoin(events, marketEventJoin)
+      .innerJoin(series, eq(events.seriesTicker, series.ticker))
+      .where(sql.join([...conditions, ...eventConditions], sql` AND `));
+    return row?.count ?? 0;
+  }
+  if (joinEvents) {
+    const [row] = await db
+      .select({ count: count() })
+      .from(markets)
+      .innerJoin(events, marketEventJoin)
+      .where(sql.join([...conditions, ...eventConditions], sql` AND `));
+    return row?.count ?? 0;
+  }
+
+  const [row] = await db
+    .select({ count: count() })
+    .from(markets)
+    .where(sql.join(conditions, sql` AND `));
+  return row?.count ?? 0;
+}
+
+/**
+ * Cursor for the page after the given market. Reads the sort key back from
+ * Postgres as text so timestamps keep their microseconds.
+ */
+export async function marketCursorAfter(
+  db: Database,
+  sort: MarketSort,
+  ticker: string,
+  exchange: string = DEFAULT_EXCHANGE
+): Promise<string | null> {
+  const key = marketSortKey(sort);
+  const [row] = await db
+    .select({ sortValue: sql<string>`(${key.expr})::text` })
+    .from(markets)
+    .where(and(eq(markets.exchange, exchange), eq(markets.ticker, ticker)));
+  return row ? encodeMarketCursor(row.sortValue, exchange, ticker) : null;
+}
+
+/**
+ * Get a single market by exchange and ticker.
  */
 export async function getMarket(
   db: Database,
-  ticker: string
+  ticker: string,
+  exchange: string = DEFAULT_EXCHANGE
 ): Promise<MarketRow | null> {
   const rows = await db
     .select()
     .from(markets)
     .where(
-      sql`${eq(markets.ticker, ticker)} AND ${isNull(markets.deletedAt)}`
+      sql`${and(eq(markets.exchange, exchange), eq(markets.ticker, ticker))} AND ${isNull(markets.deletedAt)}`
     );
 
   if (rows.length === 0) {
@@ -418,6 +604,259 @@ export async function getMarket(
   return rows[0];
 }
 
+/**
+ * An exchange's markets updated after `since`, oldest update first, including
+ * soft-deleted ones so watchers see removals. Used to poll for changes (gRPC
+ * WatchMarkets, which serves Kalshi markets).
+ *
+ * Resume from a row's `updatedAtText` rather than its `updatedAt`: the Date
+ * keeps only milliseconds of the microsecond column, so a Date cursor would
+ * return the same rows again.
+ *
+ * @param options.afterTicker - Resume within the `since` timestamp: rows updated
+ *                              exactly at `since` with a greater ticker are included
+ */
+export async function listMarketsUpdatedSince(
+  db: Database,
+  since: Date | string,
+  options: { exchange?: string; afterTicker?: string; status?: string; eventTicker?: string; limit?: number } = {}
+): Promise<(MarketRow & { updatedAtText: string })[]> {
+  const cursor = typeof since === "string" ? since : since.toISOString();
+  const conditions: ReturnType<typeof sql>[] = [
+    eq(markets.exchange, options.exchange ?? DEFAULT_EXCHANGE),
+    options.afterTicker === undefined
+      ? sql`${markets.updatedAt} > CAST(${cursor} AS timestamptz)`
+      : sql`(${markets.updatedAt}, ${markets.ticker}) > (CAST(${cursor} AS timestamptz), ${options.afterTicker})`,
+  ];
+  if (options.status) {
+    conditions.push(eq(markets.status, options.status));
+  }
+  if (options.eventTicker) {
+    conditions.push(eq(markets.eventTicker, options.eventTicker));
+  }
+
+  return await db
+    .select({ ...marketColumns, updatedAtText: sql<string>`${markets.updatedAt}::text` })
+    .from(markets)
+    .where(sql.join(conditions, sql` AND `))
+    .orderBy(markets.updatedAt, markets.ticker)
+    .limit(options.limit ?? 1000);
+}
+
+/** A cached open market with the event/series fields its filters need */
+export interface CachedMarket {
+  row: MarketRow;
+  category: string | null;
+  seriesTicker: string | null;
+  isGame: boolean;
+}
+
+export interface MarketCacheOptions {
+  /** Incremental refresh interval (default 5s) */
+  refreshIntervalMs?: number;
+  /** Full reload interval, picking up event/series changes (default 15m) */
+  fullReloadMs?: number;
+  /** Called per list/get with whether the cache answered it */
+  onLookup?: (hit: boolean) => void;
+}
+
+/**
+ * Whether the cache can answer a listMarkets call: current state only (no
+ * asOf) and the first page (no cursor). Later pages stay on Postgres so
+ * their cursors keep microsecond precision.
+ */
+export function cacheServes(options: MarketFilters & { cursor?: unknown }): boolean {
+  return options.asOf === undefined && options.cursor === undefined;
+}
+
+/**
+ * listMarkets semantics over cached markets at `now`. Timestamps compare at
+ * millisecond precision.
+ */
+export function filterCachedMarkets(
+  entries: Iterable<CachedMarket>,
+  options: MarketFilters,
+  now = new Date(),
+): CachedMarket[] {
+  const time = (v: string | undefined) => (v === undefined ? undefined : new Date(v).getTime());
+  const closingBefore = time(options.closingBefore);
+  const closingAfter = time(options.closingAfter);
+  const openBefore = time(options.openBefore);
+  const series = options.series?.toLowerCase();
+  const out: CachedMarket[] = [];
+  for (const e of entries) {
+    const m = e.row;
+    const close = m.closeTime?.getTime();
+    if (m.deletedAt || m.createdAt > now || (close !== undefined && close <= now.getTime())) continue;
+    if (options.exchange && m.exchange !== options.exchange) continue;
+    if (options.status && m.status !== options.status) continue;
+    if (options.eventTicker && m.eventTicker !== options.eventTicker) continue;
+    if (closingBefore !== undefined && !(close !== undefined && close < closingBefore)) continue;
+    if (closingAfter !== undefined && !(close !== undefined && close > closingAfter)) continue;
+    if (openBefore !== undefined && m.openTime && m.openTime.getTime() > openBefore) continue;
+    if (options.category && e.category !== options.category) continue;
+    if (series && e.seriesTicker?.toLowerCase() !== series) continue;
+    if (options.gamesOnly && !e.isGame) continue;
+    out.push(e);
+  }
+  return out;
+}
+
+/** Order cached markets as listMarkets does for a sort */
+export function sortCachedMarkets(entries: CachedMarket[], sort: MarketSort = "updated_at"): CachedMarket[] {
+  const key = (m: MarketRow): number => {
+    switch (sort) {
+      case "close_time":
+        return m.closeTime?.getTime() ?? Infinity;
+      case "volume":
+        return Number(m.volume ?? 0);
+      default:
+        return m.updatedAt.getTime();
+    }
+  };
+  const dir = sort === "close_time" ? 1 : -1;
+  return entries.sort((a, b) => {
+    const ka = key(a.row), kb = key(b.row);
+    if (ka !== kb) return ka < kb ? -dir : dir;
+    if (a.row.exchange !== b.row.exchange) return a.row.exchange < b.row.exchange ? -dir : dir;
+    return a.row.ticker < b.row.ticker ? -dir : a.row.ticker > b.row.ticker ? dir : 0;
+  });
+}
+
+/** Cache map key; tickers are unique per exchange */
+function cacheKey(exchange: string, ticker: string): string {
+  return `${exchange}:${ticker}`;
+}
+
+/**
+ * In-process copy of all open markets for read-heavy agent traffic. A
+ * refresh builds a new map and swaps it in, so reads never see a partial
+ * update. Refreshes are incremental (rows updated since the last one) on a
+ * timer, on refreshSoon() (e.g. from the secmaster delta feed), and fully
+ * reloaded every fullReloadMs.
+ */
+export class MarketCache {
+  private markets = new Map<string, CachedMarket>();
+  // Newest updated_at seen, as Postgres text to keep microseconds
+  private since: string | null = null;
+  private lastFullLoad = 0;
+  private timer: number | undefined;
+  private refreshing: Promise<void> | null = null;
+  private readonly refreshIntervalMs: number;
+  private readonly fullReloadMs: number;
+
+  constructor(private db: Database, private options: MarketCacheOptions = {}) {
+    this.refreshIntervalMs = options.refreshIntervalMs ?? 5000;
+    this.fullReloadMs = options.fullReloadMs ?? 15 * 60 * 1000;
+  }
+
+  get size(): number {
+    return this.markets.size;
+  }
+
+  get loaded(): boolean {
+    return this.since !== null;
+  }
+
+  /** Load, then refresh every refreshIntervalMs until stop() */
+  async start(): Promise<void> {
+    await this.refresh();
+    this.timer = setInterval(() => {
+      this.refreshSoon();
+    }, this.refreshIntervalMs);
+  }
+
+  stop(): void {
+    clearInterval(this.timer);
+  }
+
+  /** Start a refresh unless one is running; errors are logged */
+  refreshSoon(): void {
+    this.refresh().catch((err) => console.error("[market-cache] refresh failed:", (err as Error).message));
+  }
+
+  refresh(): Promise<void> {
+    this.refreshing ??= this.doRefresh().finally(() => {
+      this.refreshing = null;
+    });
+    return this.refreshing;
+  }
+
+  /** Cached page for listMarkets options, or null when the cache can't answer */
+  list(options: Parameters<typeof listMarkets>[1] = {}): MarketRow[] | null {
+    if (!this.loaded || !cacheServes(options)) {
+      this.options.onLookup?.(false);
+      return null;
+    }
+    this.options.onLookup?.(true);
+    const matched = sortCachedMarkets(filterCachedMarkets(this.markets.values(), options), options.sort);
+    return matched.slice(0, options.limit ?? 100).map((e) => e.row);
+  }
+
+  /** countMarkets from the cache, or null when it can't answer */
+  count(options: MarketFilters = {}): number | null {
+    if (!this.loaded || !cacheServes(options)) return null;
+    return filterCachedMarkets(this.markets.values(), options).length;
+  }
+
+  /** An open market by exchange and ticker; null on a miss (closed or unknown markets) */
+  get(ticker: string, exchange: string = DEFAULT_EXCHANGE): MarketRow | null {
+    const entry = this.loaded ? this.markets.get(cacheKey(exchange, ticker)) : undefined;
+    this.options.onLookup?.(entry !== undefined);
+    return entry?.row ?? null;
+  }
+
+  private async doRefresh(): Promise<void> {
+    const full = this.since === null || Date.now() - this.lastFullLoad >= this.fullReloadMs;
+    // A full load resumes from the newest update of any market, open or not
+    let since = this.since;
+    if (full) {
+      const [latest] = await this.db
+        .select({ max: sql<string | null>`max(${markets.updatedAt})::text` })
+        .from(markets);
+      since = latest?.max ?? new Date(0).toISOString();
+    }
+    const rows = await this.db
+      .select({
+        ...marketColumns,
+        category: events.category,
+        seriesTicker: events.seriesTicker,
+        isGame: series.isGame,
+        updatedAtText: sql<string>`${markets.updatedAt}::text`,
+      })
+      .from(markets)
+      .leftJoin(events, marketEventJoin)
+      .leftJoin(series, eq(events.seriesTicker, series.ticker))
+      .where(
+        full
+          ? sql`${markets.deletedAt} IS NULL AND (${markets.closeTime} > now() OR ${markets.closeTime} IS NULL)`
+          : sql`${markets.updatedAt} > CAST(${this.since} AS timestamptz)`
+      )
+      .orderBy(markets.updatedAt);
+
+    const next = full ? new Map<string, CachedMarket>() : new Map(this.markets);
+    const now = Date.now();
+    for (const { category, seriesTicker, isGame, updatedAtText, ...row } of rows) {
+      const closed = row.closeTime !== null && row.closeTime.getTime() <= now;
+      const key = cacheKey(row.exchange, row.ticker);
+      if (row.deletedAt || closed) {
+        next.delete(key);
+      } else {
+        next.set(key, { row, category, seriesTicker, isGame: isGame ?? false });
+      }
+      if (!full) since = updatedAtText;
+    }
+    // Drop markets that closed since they were loaded
+    for (const [key, e] of next) {
+      if (e.row.closeTime && e.row.closeTime.getTime() <= now) next.delete(key);
+    }
+
+    this.markets = next;
+    this.since = since;
+    if (full) this.lastFullLoad = now;
+  }
+}
+
 /**
  * Get market statistics by status.
  */
@@ -479,7 +918,7 @@ export async function getActiveMarketsByCategoryTimeseries(
       createdAt: markets.createdAt,
     })
     .from(markets)
-    .innerJoin(events, eq(markets.eventTicker, events.eventTicker))
+    .innerJoin(events, marketEventJoin)
     .where(
       sql`${markets.status} = 'active' AND ${markets.deletedAt} IS NULL AND ${events.deletedAt} IS NULL`
     );
@@ -514,7 +953,7 @@ export async function getActiveMarketsByCategoryTimeseries(
 }
 
 /**
- * Upsert a single market from lifecycle data (simpler than API format).
+ * Upsert a single Kalshi market from lifecycle data (simpler than API format).
  * Used by lifecycle-consumer for real-time market creation.
  * Note: Parent event must exist (FK constraint).
  */
@@ -529,6 +968,7 @@ export async function upsertMarketFromLifecycle(
   await db
     .insert(markets)
     .values({
+      exchange: DEFAULT_EXCHANGE,
       ticker,
       eventTicker,
       title,
@@ -536,7 +976,7 @@ export async function upsertMarketFromLifecycle(
       closeTime,
     })
     .onConflictDoUpdate({
-      target: markets.ticker,
+      target: [markets.exchange, markets.ticker],
       set: {
         title,
         status,
@@ -553,17 +993,50 @@ export async function upsertMarketFromLifecycle(
 export async function updateMarketStatus(
   db: Database,
   ticker: string,
-  status: string
+  status: string,
+  exchange: string = DEFAULT_EXCHANGE
 ): Promise<boolean> {
   const result = await db
     .update(markets)
     .set({ status })
-    .where(eq(markets.ticker, ticker))
+    .where(and(eq(markets.exchange, exchange), eq(markets.ticker, ticker)))
     .returning({ ticker: markets.ticker });
 
   return result.length > 0;
 }
 
+/** A market's close time, as read by the expiry watcher */
+export interface MarketCloseTime {
+  ticker: string;
+  eventTicker: string;
+  closeTime: Date;
+}
+
+/**
+ * List markets whose close_time falls in [from, to), soonest first.
+ */
+export async function listMarketsClosingBetween(
+  db: Database,
+  from: Date,
+  to: Date,
+  limit = 5000
+): Promise<MarketCloseTime[]> {
+  const rows = await db
+    .select({
+      ticker: markets.ticker,
+      eventTicker: markets.eventTicker,
+      closeTime: markets.closeTime,
+    })
+    .from(markets)
+    .where(
+      sql`${markets.closeTime} >= ${from.toISOString()} AND ${markets.closeTime} < ${to.toISOString()} AND ${isNull(markets.deletedAt)}`
+    )
+    .orderBy(markets.closeTime)
+    .limit(limit);
+
+  return rows.filter((r): r is MarketCloseTime => r.closeTime !== null);
+}
+
 /**
  * Get market activity over time (added, closed, and settled per day).
  * @param days Number of days to look back (default 30)
@@ -0,0 +1,99 @@
+/**
+ * Migration runner for ssmd-agent/migrations, compatible with dbmate: files
+ * are <version>_<name>.sql with "-- migrate:up" / "-- migrate:down" sections,
+ * and applied versions are recorded in schema_migrations. Lets ssmd-data
+ * migrate on start and `ssmd secmaster migrate` run without dbmate installed.
+ */
+import { fromFileUrl, join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import type postgres from "postgres";
+
+/** ssmd-agent/migrations, next to src/ */
+export const DEFAULT_MIGRATIONS_DIR = fromFileUrl(new URL("../../../migrations", import.meta.url));
+
+// Serializes runners across processes (e.g. several ssmd-data replicas starting)
+const MIGRATION_LOCK_ID = 0x55d4d;
+
+export interface Migration {
+  version: string;
+  name: string;
+  path: string;
+}
+
+export interface MigrationPlan {
+  applied: string[];
+  pending: Migration[];
+}
+
+/** The up and down sections of a migration file */
+export function parseMigration(text: string): { up: string; down: string } {
+  const up = text.indexOf("-- migrate:up");
+  if (up < 0) {
+    throw new Error("missing -- migrate:up");
+  }
+  const down = text.indexOf("-- migrate:down");
+  const upEnd = down > up ? down : text.length;
+  return {
+    up: text.slice(up + "-- migrate:up".length, upEnd).trim(),
+    down: down >= 0 ? text.slice(down + "-- migrate:down".length).trim() : "",
+  };
+}
+
+/** Migration files in dir, ordered by version */
+export async function listMigrations(dir = DEFAULT_MIGRATIONS_DIR): Promise<Migration[]> {
+  const migrations: Migration[] = [];
+  for await (const entry of Deno.readDir(dir)) {
+    const m = entry.isFile ? /^(\d+)_(.+)\.sql$/.exec(entry.name) : null;
+    if (m) {
+      migrations.push({ version: m[1], name: m[2], path: join(dir, entry.name) });
+    }
+  }
+  return migrations.sort((a, b) => a.version.localeCompare(b.version, undefined, { numeric: true }));
+}
+
+/** Migrations not yet in applied, in order */
+export function pendingMigrations(migrations: Migration[], applied: Iterable<string>): Migration[] {
+  const done = new Set(applied);
+  return migrations.filter((m) => !done.has(m.version));
+}
+
+async function appliedVersions(sql: postgres.Sql): Promise<string[]> {
+  await sql`CREATE TABLE IF NOT EXISTS schema_migrations (version VARCHAR(128) PRIMARY KEY)`;
+  const rows = await sql<{ version: string }[]>`SELECT version FROM schema_migrations ORDER BY version`;
+  return rows.map((r) => r.version);
+}
+
+/** Applied versions and pending migrations; creates schema_migrations if missing */
+export async function planMigrations(sql: postgres.Sql, dir = DEFAULT_MIGRATIONS_DIR): Promise<MigrationPlan> {
+  const applied = await appliedVersions(sql);
+  return { applied, pending: pendingMigrations(await listMigrations(dir), applied) };
+}
+
+/**
+ * Apply pending migrations in order, each in its own transaction under an
+ * advisory lock. A migration another runner applied meanwhile is skipped.
+ * Returns the versions applied here.
+ */
+export async function runMigrations(
+  sql: postgres.Sql,
+  dir = DEFAULT_MIGRATIONS_DIR,
+  log: (msg: string) => void = console.log,
+): Promise<string[]> {
+  const { pending } = await planMigrations(sql, dir);
+  const ran: string[] = [];
+  for (const m of pending) {
+    const { up } = parseMigration(await Deno.readTextFile(m.path));
+    const applied = await sql.begin(async (tx) => {
+      await tx`SELECT pg_advisory_xact_lock(${MIGRATION_LOCK_ID})`;
+      const [done] = await tx`SELECT 1 FROM schema_migrations WHERE version = ${m.version}`;
+      if (done) return false;
+      await tx.unsafe(up);
+      await tx`INSERT INTO schema_migrations (version) VALUES (${m.version})`;
+      return true;
+    });
+    if (applied) {
+      log(`Applied ${m.version}_${m.name}`);
+      ran.push(m.version);
+    }
+  }
+  return ran;
+}
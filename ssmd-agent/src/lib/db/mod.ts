@@ -1,7 +1,18 @@
 /**
  * Database module exports
  */
-export { getDb, getRawSql, closeDb, type Database } from "./client.ts";
+export {
+  getDb,
+  getRawSql,
+  closeDb,
+  createPool,
+  poolOptionsFromEnv,
+  setQueryObserver,
+  timedQuery,
+  type Database,
+  type PoolOptions,
+  type QueryObserver,
+} from "./client.ts";
 
 // Schema and types
 export {
@@ -10,21 +21,25 @@ export {
   seriesFees,
   apiKeys,
   settings,
+  categories,
   series,
   marketLifecycleEvents,
   pairs,
   pairSnapshots,
+  bookSnapshots,
   polymarketConditions,
   polymarketTokens,
   dqDailyScores,
   dqParquetStats,
   dataAccessLog,
   apiRequestLog,
+  apiAccessLog,
   apiKeyEvents,
   llmUsageDaily,
   billingRates,
   billingLedger,
   feeTypeEnum,
+  DEFAULT_EXCHANGE,
   type Event,
   type NewEvent,
   type Market,
@@ -35,6 +50,7 @@ export {
   type NewApiKey,
   type Setting,
   type NewSetting,
+  type Category,
   type Series,
   type NewSeries,
   type MarketLifecycleEvent,
@@ -43,6 +59,8 @@ export {
   type NewPair,
   type PairSnapshot,
   type NewPairSnapshot,
+  type BookSnapshot,
+  type NewBookSnapshot,
   type PolymarketCondition,
   type NewPolymarketCondition,
   type PolymarketToken,
@@ -55,6 +73,8 @@ export {
   type NewDataAccessLogEntry,
   type ApiRequestLogEntry,
   type NewApiRequestLogEntry,
+  type ApiAccessLogEntry,
+  type NewApiAccessLogEntry,
   type ApiKeyEvent,
   type NewApiKeyEvent,
   type LlmUsageDailyEntry,
@@ -86,14 +106,32 @@ export {
   softDeleteMissingMarkets,
   listMarkets,
   listMarketsWithSnapshot,
+  listMarketsUpdatedSince,
   getMarket,
   getMarketStats,
   getMarketTimeseries,
   getActiveMarketsByCategoryTimeseries,
+  listMarketsClosingBetween,
+  MarketCache,
+  filterCachedMarkets,
+  sortCachedMarkets,
+  cacheServes,
+  type CachedMarket,
+  type MarketCacheOptions,
+  countMarkets,
+  marketCursorAfter,
+  decodeMarketCursor,
+  type MarketCursor,
+  scanMarketPrices,
+  toMarketResponse,
+  MARKET_SORTS,
+  type MarketSort,
   type UpsertResult,
+  type MarketCloseTime,
   type MarketDayActivity,
   type ActiveByCategoryDay,
   type MarketsWithSnapshot,
+  type MarketResponse,
 } from "./markets.ts";
 
 // Fee operations
@@ -103,6 +141,8 @@ export {
   getCurrentFee,
   getFeeAsOf,
   listCurrentFees,
+  listFeeHistory,
+  setFee,
   getFeeStats,
 } from "./fees.ts";
 
@@ -125,6 +165,15 @@ export {
 // Request log operations
 export { RequestLogBuffer, type RequestLogEntry } from "./request-log.ts";
 
+// Access audit operations
+export {
+  AccessAuditBuffer,
+  accessAuditLine,
+  listAccessAudit,
+  type AccessAuditEntry,
+  type AccessAuditFilter,
+} from "./access-audit.ts";
+
 // Settings operations
 export {
   getSetting,
@@ -142,6 +191,8 @@ export {
   getSeriesStats,
   getSeries,
   listSeries,
+  listCategories,
+  categoryExists,
 } from "./series.ts";
 
 // Lifecycle operations
@@ -160,6 +211,9 @@ export {
   upsertPerpPairs,
   softDeleteMissingPairs,
   listPairs,
+  countPairs,
+  pairCursorAfter,
+  toPairMarketResponse,
   getPair,
   getPairStats,
   listActivePerpSymbols,
@@ -168,6 +222,13 @@ export {
   cleanupOldSnapshots,
 } from "./pairs.ts";
 
+// Order book snapshot operations
+export {
+  insertBookSnapshots,
+  getBookAt,
+  cleanupOldBookSnapshots,
+} from "./books.ts";
+
 // Polymarket operations
 export {
   upsertConditions,
@@ -193,6 +254,16 @@ export {
   listAccessByUser,
 } from "./accesslog.ts";
 
+// Migrations (dbmate-compatible runner)
+export {
+  DEFAULT_MIGRATIONS_DIR,
+  listMigrations,
+  planMigrations,
+  runMigrations,
+  type Migration,
+  type MigrationPlan,
+} from "./migrate.ts";
+
 // Cross-feed market lookup
 export {
   lookupMarketsByIds,
@@ -3,6 +3,7 @@
  */
 import { eq, and, isNull, desc, sql, count } from "drizzle-orm";
 import { type Database, getRawSql } from "./client.ts";
+import { encodeMarketCursor, type MarketCursor } from "./markets.ts";
 import { pairs, pairSnapshots, type NewPair, type NewPairSnapshot, type Pair, type PairSnapshot } from "./schema.ts";
 
 // PostgreSQL has a 65534 parameter limit. Pairs have many fields, so keep batches conservative.
@@ -39,6 +40,11 @@ export async function upsertSpotPairs(
           orderMin: sql`excluded.order_min`,
           costMin: sql`excluded.cost_min`,
           feeSchedule: sql`excluded.fee_schedule`,
+          // Keep the last known prices when a sync ran without ticker data
+          bid: sql`COALESCE(excluded.bid, ${pairs.bid})`,
+          ask: sql`COALESCE(excluded.ask, ${pairs.ask})`,
+          lastPrice: sql`COALESCE(excluded.last_price, ${pairs.lastPrice})`,
+          volume24h: sql`COALESCE(excluded.volume_24h, ${pairs.volume24h})`,
           deletedAt: sql`NULL`,
         },
       });
@@ -133,23 +139,17 @@ export async function softDeleteMissingPairs(
   return result.length;
 }
 
-/**
- * List pairs with optional filters.
- */
-export async function listPairs(
-  db: Database,
-  options: {
-    exchange?: string;
-    marketType?: string;
-    base?: string;
-    quote?: string;
-    status?: string;
-    query?: string;
-    limit?: number;
-  } = {},
-): Promise<Pair[]> {
-  const limit = options.limit ?? 100;
+/** Filters shared by listPairs and countPairs */
+export interface PairFilters {
+  exchange?: string;
+  marketType?: string;
+  base?: string;
+  quote?: string;
+  status?: string;
+  query?: string;
+}
 
+function pairFilterConditions(options: PairFilters) {
   const conditions: ReturnType<typeof sql>[] = [
     isNull(pairs.deletedAt),
   ];
@@ -173,15 +173,88 @@ export async function listPairs(
     const pattern = `%${options.query}%`;
     conditions.push(sql`(${pairs.pairId} ILIKE ${pattern} OR ${pairs.base} ILIKE ${pattern} OR ${pairs.wsName} ILIKE ${pattern})`);
   }
+  return conditions;
+}
+
+/**
+ * List pairs with optional filters, most recently updated first.
+ * @param options.cursor - Keyset cursor from pairCursorAfter; returns the rows after it
+ */
+export async function listPairs(
+  db: Database,
+  options: PairFilters & {
+    cursor?: MarketCursor;
+    limit?: number;
+  } = {},
+): Promise<Pair[]> {
+  const limit = options.limit ?? 100;
+  const conditions = pairFilterConditions(options);
+  if (options.cursor) {
+    conditions.push(
+      sql`(${pairs.updatedAt}, ${pairs.pairId}) < (CAST(${options.cursor.sortValue} AS timestamptz), ${options.cursor.ticker})`
+    );
+  }
 
   return await db
     .select()
     .from(pairs)
     .where(sql.join(conditions, sql` AND `))
-    .orderBy(desc(pairs.updatedAt))
+    .orderBy(desc(pairs.updatedAt), desc(pairs.pairId))
     .limit(limit);
 }
 
+/**
+ * Count pairs matching the filters, ignoring cursor and limit.
+ */
+export async function countPairs(db: Database, options: PairFilters = {}): Promise<number> {
+  const [row] = await db
+    .select({ count: count() })
+    .from(pairs)
+    .where(sql.join(pairFilterConditions(options), sql` AND `));
+  return row?.count ?? 0;
+}
+
+/**
+ * Cursor for the page after the given pair, in the /v1/markets cursor format.
+ * Reads updated_at back from Postgres as text so it keeps its microseconds.
+ * pair_id is unique on its own, so listPairs ignores the cursor's exchange.
+ */
+export async function pairCursorAfter(db: Database, pairId: string): Promise<string | null> {
+  const [row] = await db
+    .select({ sortValue: sql<string>`${pairs.updatedAt}::text`, exchange: pairs.exchange })
+    .from(pairs)
+    .where(eq(pairs.pairId, pairId));
+  return row ? encodeMarketCursor(row.sortValue, row.exchange, pairId) : null;
+}
+
+/**
+ * A pair in /v1/markets shape, so exchange-agnostic consumers (Harman ticker
+ * lookup) read Kraken reference data the way they read Kalshi markets.
+ */
+export function toPairMarketResponse(pair: Pair) {
+  return {
+    ticker: pair.pairId,
+    title: pair.wsName,
+    status: pair.status,
+    exchange: pair.exchange,
+    marketType: pair.marketType,
+    base: pair.base,
+    quote: pair.quote,
+    wsName: pair.wsName,
+    tickSize: pair.tickSize,
+    orderMin: pair.orderMin,
+    costMin: pair.costMin,
+    lotDecimals: pair.lotDecimals,
+    pairDecimals: pair.pairDecimals,
+    contractSize: pair.contractSize,
+    bid: pair.bid,
+    ask: pair.ask,
+    lastPrice: pair.lastPrice,
+    volume24h: pair.volume24h,
+    updatedAt: pair.updatedAt,
+  };
+}
+
 /**
  * Get a single pair by namespaced ID.
  */
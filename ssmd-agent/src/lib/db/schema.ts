@@ -18,6 +18,8 @@ import {
   jsonb,
   date,
   unique,
+  primaryKey,
+  foreignKey,
 } from "drizzle-orm/pg-core";
 
 // Fee type enum matching PostgreSQL
@@ -27,9 +29,13 @@ export const feeTypeEnum = pgEnum("fee_type", [
   "flat",
 ]);
 
-// Events table
+// Exchange of events and markets synced before the exchange column (migration 0040)
+export const DEFAULT_EXCHANGE = "kalshi";
+
+// Events table, keyed by (exchange, event_ticker)
 export const events = pgTable("events", {
-  eventTicker: varchar("event_ticker", { length: 128 }).primaryKey(),
+  exchange: varchar("exchange", { length: 32 }).notNull().default(DEFAULT_EXCHANGE),
+  eventTicker: varchar("event_ticker", { length: 128 }).notNull(),
   title: text("title").notNull(),
   category: varchar("category", { length: 128 }).notNull().default(""),
   seriesTicker: varchar("series_ticker", { length: 128 }).notNull().default(""),
@@ -39,13 +45,15 @@ export const events = pgTable("events", {
   createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
   updatedAt: timestamp("updated_at", { withTimezone: true }).notNull().defaultNow(),
   deletedAt: timestamp("deleted_at", { withTimezone: true }),
-});
+}, (table) => ({
+  pk: primaryKey({ columns: [table.exchange, table.eventTicker] }),
+}));
 
-// Markets table (prices in dollars, 0.00-1.00 range — migration 0026)
+// Markets table (prices in dollars, 0.00-1.00 range — migration 0026), keyed by (exchange, ticker)
 export const markets = pgTable("markets", {
-  ticker: varchar("ticker", { length: 128 }).primaryKey(),
-  eventTicker: varchar("event_ticker", { length: 128 }).notNull()
-    .references(() => events.eventTicker),
+  exchange: varchar("exchange", { length: 32 }).notNull().default(DEFAULT_EXCHANGE),
+  ticker: varchar("ticker", { length: 128 }).notNull(),
+  eventTicker: varchar("event_ticker", { length: 128 }).notNull(),
   title: text("title").notNull(),
   status: varchar("status", { length: 16 }).notNull().default("open"),
   closeTime: timestamp("close_time", { withTimezone: true }),
@@ -72,7 +80,14 @@ export const markets = pgTable("markets", {
   createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
   updatedAt: timestamp("updated_at", { withTimezone: true }).notNull().defaultNow(),
   deletedAt: timestamp("deleted_at", { withTimezone: true }),
-});
+}, (table) => ({
+  pk: primaryKey({ columns: [table.exchange, table.ticker] }),
+  event: foreignKey({
+    columns: [table.exchange, table.eventTicker],
+    foreignColumns: [events.exchange, events.eventTicker],
+    name: "markets_event_fkey",
+  }),
+}));
 
 // Series fees table (exclusion constraint lives in SQL migration)
 export const seriesFees = pgTable("series_fees", {
@@ -114,11 +129,17 @@ export const settings = pgTable("settings", {
   updatedAt: timestamp("updated_at", { withTimezone: true }).defaultNow(),
 });
 
+// Categories referenced by series.category (filled by series sync)
+export const categories = pgTable("categories", {
+  name: varchar("name", { length: 128 }).primaryKey(),
+  createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
+});
+
 // Series table for Kalshi series metadata
 export const series = pgTable("series", {
   ticker: varchar("ticker", { length: 128 }).primaryKey(),
   title: text("title").notNull(),
-  category: varchar("category", { length: 128 }).notNull(),
+  category: varchar("category", { length: 128 }).notNull().references(() => categories.name),
   tags: text("tags").array(), // Array of tags from Kalshi API
   isGame: boolean("is_game").notNull().default(false), // For Sports: GAME/MATCH in ticker
   active: boolean("active").notNull().default(true), // Soft disable for filtering
@@ -202,6 +223,20 @@ export const pairSnapshots = pgTable("pair_snapshots", {
   snapshotAt: timestamp("snapshot_at", { withTimezone: true }).notNull().defaultNow(),
 });
 
+// Order book snapshots: top-N levels per instrument, written on an interval by book-snapshot-consumer
+export const bookSnapshots = pgTable("book_snapshots", {
+  id: bigserial("id", { mode: "bigint" }).primaryKey(),
+  feed: varchar("feed", { length: 32 }).notNull(),
+  ticker: varchar("ticker", { length: 128 }).notNull(),
+  depth: smallint("depth").notNull(),
+  // [[price, size], ...] best first
+  bids: jsonb("bids").$type<[number, number][]>().notNull(),
+  asks: jsonb("asks").$type<[number, number][]>().notNull(),
+  // Exchange time of the last book update, if the feed provides one
+  exchangeTs: timestamp("exchange_ts", { withTimezone: true }),
+  snapshotAt: timestamp("snapshot_at", { withTimezone: true }).notNull().defaultNow(),
+});
+
 // Polymarket conditions (prediction markets)
 export const polymarketConditions = pgTable("polymarket_conditions", {
   conditionId: varchar("condition_id", { length: 128 }).primaryKey(),
@@ -300,6 +335,20 @@ export const apiRequestLog = pgTable("api_request_log", {
   createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
 });
 
+// Structured access audit (every authenticated request)
+export const apiAccessLog = pgTable("api_access_log", {
+  id: bigserial("id", { mode: "bigint" }).primaryKey(),
+  keyPrefix: varchar("key_prefix", { length: 30 }).notNull(),
+  userEmail: varchar("user_email", { length: 255 }),
+  method: varchar("method", { length: 10 }).notNull(),
+  route: varchar("route", { length: 255 }).notNull(),
+  dataset: varchar("dataset", { length: 64 }),
+  statusCode: smallint("status_code").notNull(),
+  responseBytes: bigint("response_bytes", { mode: "number" }),
+  latencyMs: integer("latency_ms").notNull(),
+  createdAt: timestamp("created_at", { withTimezone: true }).notNull().defaultNow(),
+});
+
 // API key audit trail
 export const apiKeyEvents = pgTable("api_key_events", {
   id: bigserial("id", { mode: "bigint" }).primaryKey(),
@@ -429,6 +478,7 @@ export type ApiKey = typeof apiKeys.$inferSelect;
 export type NewApiKey = typeof apiKeys.$inferInsert;
 export type Setting = typeof settings.$inferSelect;
 export type NewSetting = typeof settings.$inferInsert;
+export type Category = typeof categories.$inferSelect;
 export type Series = typeof series.$inferSelect;
 export type NewSeries = typeof series.$inferInsert;
 export type MarketLifecycleEvent = typeof marketLifecycleEvents.$inferSelect;
@@ -437,6 +487,8 @@ export type Pair = typeof pairs.$inferSelect;
 export type NewPair = typeof pairs.$inferInsert;
 export type PairSnapshot = typeof pairSnapshots.$inferSelect;
 export type NewPairSnapshot = typeof pairSnapshots.$inferInsert;
+export type BookSnapshot = typeof bookSnapshots.$inferSelect;
+export type NewBookSnapshot = typeof bookSnapshots.$inferInsert;
 export type PolymarketCondition = typeof polymarketConditions.$inferSelect;
 export type NewPolymarketCondition = typeof polymarketConditions.$inferInsert;
 export type PolymarketToken = typeof polymarketTokens.$inferSelect;
@@ -449,6 +501,8 @@ export type DataAccessLogEntry = typeof dataAccessLog.$inferSelect;
 export type NewDataAccessLogEntry = typeof dataAccessLog.$inferInsert;
 export type ApiRequestLogEntry = typeof apiRequestLog.$inferSelect;
 export type NewApiRequestLogEntry = typeof apiRequestLog.$inferInsert;
+export type ApiAccessLogEntry = typeof apiAccessLog.$inferSelect;
+export type NewApiAccessLogEntry = typeof apiAccessLog.$inferInsert;
 export type ApiKeyEvent = typeof apiKeyEvents.$inferSelect;
 export type NewApiKeyEvent = typeof apiKeyEvents.$inferInsert;
 export type LlmUsageDailyEntry = typeof llmUsageDaily.$inferSelect;
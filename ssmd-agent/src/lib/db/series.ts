@@ -3,7 +3,7 @@
  */
 import { eq, and, sql, arrayContains } from "drizzle-orm";
 import { getDb } from "./client.ts";
-import { series, type Series, type NewSeries } from "./schema.ts";
+import { categories, series, type Series, type NewSeries } from "./schema.ts";
 
 /**
  * Upsert series records (insert or update on conflict)
@@ -17,6 +17,10 @@ export async function upsertSeries(records: NewSeries[]): Promise<{ inserted: nu
   let inserted = 0;
   let updated = 0;
 
+  // series.category references categories
+  const names = [...new Set(records.map((r) => r.category))];
+  await db.insert(categories).values(names.map((name) => ({ name }))).onConflictDoNothing();
+
   for (const record of records) {
     const result = await db
       .insert(series)
@@ -208,3 +212,33 @@ export async function listSeries(options?: {
 
   return query;
 }
+
+/**
+ * List categories with their active series counts
+ */
+export async function listCategories(): Promise<Array<{ name: string; series: number }>> {
+  const db = getDb();
+  return await db
+    .select({
+      name: categories.name,
+      series: sql<number>`count(${series.ticker}) filter (where ${series.active})::int`,
+    })
+    .from(categories)
+    .leftJoin(series, eq(series.category, categories.name))
+    .groupBy(categories.name)
+    .orderBy(categories.name);
+}
+
+/**
+ * Whether a category is in the categories table. Used to reject category
+ * filters that would otherwise silently match nothing.
+ */
+export async function categoryExists(name: string): Promise<boolean> {
+  const db = getDb();
+  const result = await db
+    .select({ name: categories.name })
+    .from(categories)
+    .where(eq(categories.name, name))
+    .limit(1);
+  return result.length > 0;
+}
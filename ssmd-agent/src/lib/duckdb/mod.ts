@@ -12,12 +12,14 @@ import { DuckDBInstance } from "@duckdb/node-api";
 
 let instance: DuckDBInstance | null = null;
 let connection: Awaited<ReturnType<DuckDBInstance["connect"]>> | null = null;
+let log: (message: string) => void = console.log;
 
 /**
  * Initialize DuckDB: create instance, load httpfs, configure GCS.
- * Call once at server startup.
+ * Call once at server startup; CLI commands pass quiet to keep stdout for results.
  */
-export async function initDuckDB(): Promise<void> {
+export async function initDuckDB(opts: { quiet?: boolean } = {}): Promise<void> {
+  log = opts.quiet ? () => {} : console.log;
   instance = await DuckDBInstance.create();
   connection = await instance.connect();
 
@@ -47,11 +49,11 @@ export async function initDuckDB(): Promise<void> {
   if (hmacKeyId && hmacSecret) {
     await connection.run(`SET s3_access_key_id='${hmacKeyId}'`);
     await connection.run(`SET s3_secret_access_key='${hmacSecret}'`);
-    console.log("DuckDB initialized with GCS access (HMAC credentials)");
+    log("DuckDB initialized with GCS access (HMAC credentials)");
   } else {
     try {
       await connection.run("CREATE SECRET (TYPE GCS, PROVIDER CREDENTIAL_CHAIN)");
-      console.log("DuckDB initialized with GCS access (credential chain)");
+      log("DuckDB initialized with GCS access (credential chain)");
     } catch (e) {
       console.warn("DuckDB GCS auth failed, queries may not work:", (e as Error).message);
     }
@@ -124,5 +126,5 @@ export async function closeDuckDB(): Promise<void> {
     // DuckDBInstance doesn't have explicit close, just null the reference
     instance = null;
   }
-  console.log("DuckDB closed");
+  log("DuckDB closed");
 }
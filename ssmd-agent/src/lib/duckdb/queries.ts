@@ -2,7 +2,7 @@
  * SQL query builders for DuckDB parquet queries.
  * Ported from ssmd-mcp Python tools.py.
  */
-import { TRADE_CONFIG, PRICE_CONFIG, gcsParquetPath } from "./feed-config.ts";
+import { TRADE_CONFIG, PRICE_CONFIG, FEED_PATHS, FEED_TYPES, gcsParquetPath } from "./feed-config.ts";
 
 /**
  * Build trade aggregation SQL for a feed.
@@ -305,3 +305,82 @@ export function buildPriceSQL(
   `.trim();
 }
 
+
+/** Longest accepted ad-hoc dataset query */
+export const MAX_DATASET_SQL_LENGTH = 10_000;
+
+/**
+ * Table sources for one feed and date: a table per parquet type
+ * (e.g. `trade`, `ticker`) plus `raw` for the archived JSONL.
+ */
+export function datasetTables(bucket: string, feed: string, date: string): Record<string, string> {
+  const tables: Record<string, string> = {};
+  for (const type of FEED_TYPES[feed] ?? []) {
+    tables[type] = `read_parquet('${gcsParquetPath(bucket, feed, date, type)}', union_by_name = true)`;
+  }
+  const prefix = FEED_PATHS[feed] ?? feed;
+  tables.raw =
+    `read_json_auto('s3://${bucket}/${prefix}/${date}/*.jsonl.gz', format = 'newline_delimited', union_by_name = true, ignore_errors = true)`;
+  return tables;
+}
+
+/**
+ * Offset of the first ";" outside string literals, quoted identifiers and
+ * comments, or -1. A doubled quote ('' or "") escapes itself, which the scan
+ * handles as leaving and re-entering the quote.
+ */
+export function statementSeparator(sql: string): number {
+  let quote: string | null = null;
+  for (let i = 0; i < sql.length; i++) {
+    const c = sql[i];
+    if (quote) {
+      if (c === quote) quote = null;
+    } else if (c === "'" || c === '"') {
+      quote = c;
+    } else if (c === "-" && sql[i + 1] === "-") {
+      const end = sql.indexOf("\n", i);
+      if (end === -1) return -1;
+      i = end;
+    } else if (c === "/" && sql[i + 1] === "*") {
+      const end = sql.indexOf("*/", i + 2);
+      if (end === -1) return -1;
+      i = end + 1;
+    } else if (c === ";") {
+      return i;
+    }
+  }
+  return -1;
+}
+
+/**
+ * Wrap an ad-hoc SELECT so it can name a day's files as tables. Tables are
+ * CTEs rather than views so concurrent queries on the shared connection do
+ * not see each other's feed/date, and only tables the query mentions are
+ * defined (a glob with no files fails even when unused). Running the query
+ * as a subquery also rejects anything other than a SELECT.
+ */
+export function buildDatasetSQL(
+  bucket: string,
+  feed: string,
+  date: string,
+  sql: string,
+  limit: number,
+): string {
+  let body = sql.trim();
+  if (body.length > MAX_DATASET_SQL_LENGTH) {
+    throw new Error(`Query longer than ${MAX_DATASET_SQL_LENGTH} characters`);
+  }
+  // A trailing separator is dropped; one followed by anything else starts a second statement
+  const separator = statementSeparator(body);
+  if (separator !== -1) {
+    if (!/^[\s;]*$/.test(body.slice(separator + 1))) throw new Error("Only a single statement is allowed");
+    body = body.slice(0, separator).trim();
+  }
+  if (!body) throw new Error("Query is empty");
+
+  const ctes = Object.entries(datasetTables(bucket, feed, date))
+    .filter(([name]) => new RegExp(`\\b${name}\\b`, "i").test(body))
+    .map(([name, source]) => `${name} AS (SELECT * FROM ${source})`);
+  const withClause = ctes.length > 0 ? `WITH ${ctes.join(",\n  ")}\n` : "";
+  return `${withClause}SELECT * FROM (\n${body}\n) LIMIT ${limit}`;
+}
@@ -0,0 +1,296 @@
+/**
+ * Record filter expressions, evaluated per record while streaming:
+ *
+ *   ticker == "INXD" && price > 0.4 && type in ("trade", "ticker")
+ *
+ * Grammar (|| binds looser than &&, ! binds tightest):
+ *   expr       := and ("||" and)*
+ *   and        := unary ("&&" unary)*
+ *   unary      := "!" unary | "(" expr ")" | comparison
+ *   comparison := field ("==" | "!=" | ">" | ">=" | "<" | "<=") literal
+ *               | field ["not"] "in" "(" literal ("," literal)* ")"
+ *   field      := ident ("." ident)*
+ *   literal    := "string" | 'string' | number | true | false | null
+ *
+ * Numeric strings compare as numbers, so `price > 0.4` works for feeds that
+ * send prices as decimal strings. A comparison on a missing field is false.
+ */
+
+/** Longest accepted expression; filters come from query strings */
+export const MAX_FILTER_LENGTH = 1024;
+
+export class FilterError extends Error {
+  constructor(message: string, readonly position: number) {
+    super(`${message} at position ${position}`);
+    this.name = "FilterError";
+  }
+}
+
+type Literal = string | number | boolean | null;
+type CompareOp = "==" | "!=" | ">" | ">=" | "<" | "<=";
+
+export type FilterNode =
+  | { kind: "and" | "or"; left: FilterNode; right: FilterNode }
+  | { kind: "not"; operand: FilterNode }
+  | { kind: "compare"; field: string; op: CompareOp; value: Literal }
+  | { kind: "in"; field: string; values: Literal[]; negated: boolean };
+
+interface Token {
+  kind: "ident" | "string" | "number" | "op" | "punct" | "end";
+  text: string;
+  value?: Literal;
+  pos: number;
+}
+
+const OPERATORS = ["&&", "||", "==", "!=", ">=", "<=", ">", "<", "!"];
+
+function tokenize(src: string): Token[] {
+  const tokens: Token[] = [];
+  let i = 0;
+  while (i < src.length) {
+    const c = src[i];
+    if (/\s/.test(c)) {
+      i++;
+      continue;
+    }
+    const start = i;
+    if (c === '"' || c === "'") {
+      let text = "";
+      i++;
+      while (i < src.length && src[i] !== c) {
+        if (src[i] === "\\" && i + 1 < src.length) i++;
+        text += src[i++];
+      }
+      if (i >= src.length) throw new FilterError("Unterminated string", start);
+      i++;
+      tokens.push({ kind: "string", text, value: text, pos: start });
+      continue;
+    }
+    const number = /^-?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?/.exec(src.slice(i));
+    if (number && (c !== "-" || /[\d.]/.test(src[i + 1] ?? ""))) {
+      i += number[0].length;
+      tokens.push({ kind: "number", text: number[0], value: Number(number[0]), pos: start });
+      continue;
+    }
+    const ident = /^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*/.exec(src.slice(i));
+    if (ident) {
+      i += ident[0].length;
+      tokens.push({ kind: "ident", text: ident[0], pos: start });
+      continue;
+    }
+    const op = OPERATORS.find((o) => src.startsWith(o, i));
+    if (op) {
+      i += op.length;
+      tokens.push({ kind: "op", text: op, pos: start });
+      continue;
+    }
+    if (c === "(" || c === ")" || c === ",") {
+      i++;
+      tokens.push({ kind: "punct", text: c, pos: start });
+      continue;
+    }
+    throw new FilterError(`Unexpected character '${c}'`, start);
+  }
+  tokens.push({ kind: "end", text: "", pos: src.length });
+  return tokens;
+}
+
+class Parser {
+  private i = 0;
+
+  constructor(private readonly tokens: Token[]) {}
+
+  parse(): FilterNode {
+    const node = this.or();
+    const next = this.peek();
+    if (next.kind !== "end") throw new FilterError(`Unexpected '${next.text}'`, next.pos);
+    return node;
+  }
+
+  private peek(): Token {
+    return this.tokens[this.i];
+  }
+
+  private next(): Token {
+    return this.tokens[this.i++];
+  }
+
+  private accept(text: string): boolean {
+    const t = this.peek();
+    if ((t.kind === "op" || t.kind === "punct") && t.text === text) {
+      this.i++;
+      return true;
+    }
+    return false;
+  }
+
+  private expect(text: string): void {
+    const t = this.peek();
+    if (!this.accept(text)) throw new FilterError(`Expected '${text}' but found '${t.text || "end"}'`, t.pos);
+  }
+
+  private or(): FilterNode {
+    let left = this.and();
+    while (this.accept("||")) left = { kind: "or", left, right: this.and() };
+    return left;
+  }
+
+  private and(): FilterNode {
+    let left = this.unary();
+    while (this.accept("&&")) left = { kind: "and", left, right: this.unary() };
+    return left;
+  }
+
+  private unary(): FilterNode {
+    if (this.accept("!")) return { kind: "not", operand: this.unary() };
+    if (this.accept("(")) {
+      const node = this.or();
+      this.expect(")");
+      return node;
+    }
+    return this.comparison();
+  }
+
+  private comparison(): FilterNode {
+    const field = this.next();
+    if (field.kind !== "ident" || isKeyword(field.text)) {
+      throw new FilterError(`Expected a field name but found '${field.text || "end"}'`, field.pos);
+    }
+    const t = this.peek();
+    if (t.kind === "ident" && (t.text === "in" || t.text === "not")) {
+      this.i++;
+      const negated = t.text === "not";
+      if (negated) {
+        const inToken = this.next();
+        if (inToken.kind !== "ident" || inToken.text !== "in") {
+          throw new FilterError("Expected 'in' after 'not'", inToken.pos);
+        }
+      }
+      this.expect("(");
+      const values = [this.literal()];
+      while (this.accept(",")) values.push(this.literal());
+      this.expect(")");
+      return { kind: "in", field: field.text, values, negated };
+    }
+    if (t.kind !== "op" || !["==", "!=", ">", ">=", "<", "<="].includes(t.text)) {
+      throw new FilterError(`Expected a comparison after '${field.text}'`, t.pos);
+    }
+    this.i++;
+    return { kind: "compare", field: field.text, op: t.text as CompareOp, value: this.literal() };
+  }
+
+  private literal(): Literal {
+    const t = this.next();
+    if (t.kind === "string" || t.kind === "number") return t.value!;
+    if (t.kind === "ident" && t.text === "true") return true;
+    if (t.kind === "ident" && t.text === "false") return false;
+    if (t.kind === "ident" && t.text === "null") return null;
+    throw new FilterError(`Expected a value but found '${t.text || "end"}'`, t.pos);
+  }
+}
+
+function isKeyword(text: string): boolean {
+  return ["in", "not", "true", "false", "null"].includes(text);
+}
+
+/** Parse an expression; throws FilterError with the offending position */
+export function parseFilter(src: string): FilterNode {
+  if (src.length > MAX_FILTER_LENGTH) {
+    throw new FilterError(`Filter longer than ${MAX_FILTER_LENGTH} characters`, MAX_FILTER_LENGTH);
+  }
+  return new Parser(tokenize(src)).parse();
+}
+
+/** Numeric value of a number or numeric string, else null */
+function numeric(value: unknown): number | null {
+  if (typeof value === "number") return Number.isFinite(value) ? value : null;
+  if (typeof value === "string" && value.trim() !== "") {
+    const n = Number(value);
+    return Number.isFinite(n) ? n : null;
+  }
+  return null;
+}
+
+function equals(actual: unknown, expected: Literal): boolean {
+  if (expected === null) return actual === null;
+  if (typeof expected === "number") return numeric(actual) === expected;
+  if (typeof expected === "string" && typeof actual === "number") return numeric(expected) === actual;
+  return actual === expected;
+}
+
+function compare(actual: unknown, op: CompareOp, expected: Literal): boolean {
+  if (actual === undefined) return false;
+  if (op === "==") return equals(actual, expected);
+  if (op === "!=") return !equals(actual, expected);
+  const a = numeric(actual);
+  const b = numeric(expected);
+  if (a !== null && b !== null) {
+    return op === ">" ? a > b : op === ">=" ? a >= b : op === "<" ? a < b : a <= b;
+  }
+  if (typeof actual === "string" && typeof expected === "string") {
+    return op === ">" ? actual > expected : op === ">=" ? actual >= expected : op === "<" ? actual < expected : actual <= expected;
+  }
+  return false;
+}
+
+/** Looks up a field (dotted path) of the record being tested; undefined when absent */
+export type FieldResolver = (field: string) => unknown;
+
+export function evaluateFilter(node: FilterNode, resolve: FieldResolver): boolean {
+  switch (node.kind) {
+    case "and":
+      return evaluateFilter(node.left, resolve) && evaluateFilter(node.right, resolve);
+    case "or":
+      return evaluateFilter(node.left, resolve) || evaluateFilter(node.right, resolve);
+    case "not":
+      return !evaluateFilter(node.operand, resolve);
+    case "compare":
+      return compare(resolve(node.field), node.op, node.value);
+    case "in": {
+      const actual = resolve(node.field);
+      if (actual === undefined) return false;
+      const found = node.values.some((v) => equals(actual, v));
+      return node.negated ? !found : found;
+    }
+  }
+}
+
+/** A parsed filter, ready to test records */
+export interface RecordFilter {
+  source: string;
+  matches(resolve: FieldResolver): boolean;
+}
+
+export function compileFilter(src: string): RecordFilter {
+  const node = parseFilter(src);
+  return { source: src, matches: (resolve) => evaluateFilter(node, resolve) };
+}
+
+function lookup(obj: unknown, path: string[]): unknown {
+  let current = obj;
+  for (const key of path) {
+    if (current === null || typeof current !== "object") return undefined;
+    current = (current as Record<string, unknown>)[key];
+  }
+  return current;
+}
+
+/**
+ * Resolver over a raw exchange message. `derived` supplies normalized fields
+ * (ticker, type). Other names are dotted paths into the message; a plain name
+ * missing at the top level is also looked up in the Kalshi `msg` and Binance
+ * `data` envelopes, so `price` finds msg.price.
+ */
+export function recordResolver(record: Record<string, unknown>, derived: Record<string, unknown> = {}): FieldResolver {
+  return (field) => {
+    if (field in derived) return derived[field] ?? undefined;
+    const path = field.split(".");
+    const direct = lookup(record, path);
+    if (direct !== undefined || path.length > 1) return direct;
+    for (const envelope of ["msg", "data"]) {
+      const nested = lookup(record[envelope], path);
+      if (nested !== undefined) return nested;
+    }
+    return undefined;
+  };
+}
@@ -0,0 +1,11 @@
+export {
+  compileFilter,
+  evaluateFilter,
+  FilterError,
+  MAX_FILTER_LENGTH,
+  parseFilter,
+  recordResolver,
+  type FieldResolver,
+  type FilterNode,
+  type RecordFilter,
+} from "./expr.ts";
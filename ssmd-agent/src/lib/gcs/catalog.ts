@@ -1,9 +1,17 @@
 /**
  * GCS catalog reader — reads catalog.json and per-date parquet-manifest.json from GCS.
  * Types mirror the Rust catalog/manifest structs.
- * In-memory cache with 5-minute TTL (catalog changes once daily at 02:00 UTC).
+ * In-memory cache with a TTL of DATASET_CACHE_TTL_SECONDS (default 5 minutes;
+ * the catalog changes once daily at 02:00 UTC). Once the TTL passes the stale
+ * catalog is still served while it is re-read in the background, so only the
+ * first request after startup waits on GCS. Per-date manifests and file
+ * listings are cached by feed/date for the same TTL. invalidateDatasetCache
+ * (POST /v1/admin/cache/invalidate) drops entries after a backfill or re-run,
+ * and primeDatasetCache (POST /v1/admin/cache/prime, or DATASET_CACHE_PRIME_AT
+ * daily) loads them ahead of expected query load.
  */
 import { Storage } from "@google-cloud/storage";
+import { FEED_CONFIG, listParquetFiles, listRawFiles, type ParquetFile } from "./signed-urls.ts";
 
 // --- Types matching Rust structs ---
 
@@ -82,25 +90,139 @@ interface CacheEntry<T> {
   expiresAt: number;
 }
 
-const CACHE_TTL_MS = 5 * 60 * 1000; // 5 minutes
-let catalogCache: CacheEntry<Catalog> | null = null;
+const DEFAULT_CACHE_TTL_SECONDS = 5 * 60;
+const DEFAULT_CACHE_MAX_ENTRIES = 1000;
 
-// --- Public API ---
+/** Cache TTL from DATASET_CACHE_TTL_SECONDS; 0 disables the feed/date caches */
+export function datasetCacheTtlMs(): number {
+  const raw = Deno.env.get("DATASET_CACHE_TTL_SECONDS");
+  const seconds = raw === undefined || raw === "" ? DEFAULT_CACHE_TTL_SECONDS : Number(raw);
+  if (!Number.isFinite(seconds) || seconds < 0) {
+    console.error(`[catalog] invalid DATASET_CACHE_TTL_SECONDS=${raw}, using ${DEFAULT_CACHE_TTL_SECONDS}`);
+    return DEFAULT_CACHE_TTL_SECONDS * 1000;
+  }
+  return seconds * 1000;
+}
+
+interface PendingLoad<T> {
+  promise: Promise<T>;
+  feed: string;
+  date: string;
+}
 
 /**
- * Read the root catalog.json from GCS. Returns null if not found.
- * Cached for 5 minutes.
+ * TTL cache keyed by feed and date. Concurrent misses for a key share one
+ * load, and failed loads are not cached. A load only stores its result if it
+ * is still the current load for its key, so invalidate() during a load keeps
+ * the stale result out. Expired entries are dropped when read, and the oldest
+ * entries are evicted once maxEntries is reached.
  */
-export async function getCatalog(bucket: string): Promise<Catalog | null> {
-  if (catalogCache && Date.now() < catalogCache.expiresAt) {
-    return catalogCache.data;
+export class FeedDateCache<T> {
+  #entries = new Map<string, CacheEntry<T> & { feed: string; date: string }>();
+  #loading = new Map<string, PendingLoad<T>>();
+
+  constructor(
+    private ttlMs: () => number = datasetCacheTtlMs,
+    private now: () => number = Date.now,
+    private maxEntries = DEFAULT_CACHE_MAX_ENTRIES,
+  ) {}
+
+  get size(): number {
+    return this.#entries.size;
   }
 
+  async get(feed: string, date: string, load: () => Promise<T>): Promise<T> {
+    const key = `${feed}/${date}`;
+    const entry = this.#entries.get(key);
+    if (entry) {
+      if (this.now() < entry.expiresAt) {
+        return entry.data;
+      }
+      this.#entries.delete(key);
+    }
+
+    let pending = this.#loading.get(key);
+    if (!pending) {
+      const current: PendingLoad<T> = {
+        feed,
+        date,
+        promise: load().then((data) => {
+          if (this.#loading.get(key) === current) {
+            this.#store(key, feed, date, data);
+          }
+          return data;
+        }).finally(() => {
+          if (this.#loading.get(key) === current) {
+            this.#loading.delete(key);
+          }
+        }),
+      };
+      pending = current;
+      this.#loading.set(key, pending);
+    }
+    return await pending.promise;
+  }
+
+  /**
+   * Drop entries matching feed and/or date (all entries when neither is set)
+   * and detach matching in-flight loads so they do not store; returns the
+   * number of stored entries dropped.
+   */
+  invalidate(feed?: string, date?: string): number {
+    const matches = (e: { feed: string; date: string }) =>
+      (feed === undefined || e.feed === feed) && (date === undefined || e.date === date);
+    for (const [key, pending] of this.#loading) {
+      if (matches(pending)) {
+        this.#loading.delete(key);
+      }
+    }
+    let dropped = 0;
+    for (const [key, entry] of this.#entries) {
+      if (matches(entry)) {
+        this.#entries.delete(key);
+        dropped++;
+      }
+    }
+    return dropped;
+  }
+
+  #store(key: string, feed: string, date: string, data: T): void {
+    const ttl = this.ttlMs();
+    if (ttl <= 0) {
+      return;
+    }
+    this.#entries.delete(key);
+    if (this.#entries.size >= this.maxEntries) {
+      const now = this.now();
+      for (const [k, e] of this.#entries) {
+        if (e.expiresAt <= now) {
+          this.#entries.delete(k);
+        }
+      }
+      // Map iteration is insertion order, so the first keys are the oldest.
+      for (const k of this.#entries.keys()) {
+        if (this.#entries.size < this.maxEntries) {
+          break;
+        }
+        this.#entries.delete(k);
+      }
+    }
+    this.#entries.set(key, { data, expiresAt: this.now() + ttl, feed, date });
+  }
+}
+
+let catalogCache: CacheEntry<Catalog> | null = null;
+let catalogRefresh: Promise<Catalog | null> | null = null;
+const manifestCache = new FeedDateCache<ParquetManifest | null>();
+const listingCache = new FeedDateCache<ParquetFile[]>();
+const rawListingCache = new FeedDateCache<ParquetFile[]>();
+
+async function loadCatalog(bucket: string): Promise<Catalog | null> {
   const storage = new Storage();
   try {
     const [content] = await storage.bucket(bucket).file("catalog.json").download();
     const catalog: Catalog = JSON.parse(content.toString("utf-8"));
-    catalogCache = { data: catalog, expiresAt: Date.now() + CACHE_TTL_MS };
+    catalogCache = { data: catalog, expiresAt: Date.now() + datasetCacheTtlMs() };
     return catalog;
   } catch (err: unknown) {
     const error = err as { code?: number };
@@ -111,12 +233,244 @@ export async function getCatalog(bucket: string): Promise<Catalog | null> {
   }
 }
 
+/** Start a catalog read unless one is already running */
+function refreshCatalog(bucket: string): Promise<Catalog | null> {
+  if (!catalogRefresh) {
+    catalogRefresh = loadCatalog(bucket).finally(() => {
+      catalogRefresh = null;
+    });
+  }
+  return catalogRefresh;
+}
+
+// --- Public API ---
+
+/**
+ * Read the root catalog.json from GCS. Returns null if not found.
+ * Cached for the dataset cache TTL; an expired catalog is returned as-is while
+ * a background refresh runs (refresh failures are logged and retried next call).
+ */
+export async function getCatalog(bucket: string): Promise<Catalog | null> {
+  if (!catalogCache) {
+    return await refreshCatalog(bucket);
+  }
+  if (Date.now() >= catalogCache.expiresAt) {
+    refreshCatalog(bucket).catch((err) => {
+      console.error(`[catalog] background refresh failed: ${err}`);
+    });
+  }
+  return catalogCache.data;
+}
+
+/** Roll-up statistics for one feed, derived from its catalog summary */
+export interface FeedStats {
+  feed: string;
+  stream: string;
+  date_min: string;
+  date_max: string;
+  date_count: number;
+  /** Calendar days between date_min and date_max with no data */
+  gap_dates: string[];
+  total_files: number;
+  total_bytes: number;
+  total_rows: number;
+}
+
+/** Catalog-wide totals across feeds */
+export interface CatalogStats {
+  feeds: FeedStats[];
+  date_min: string | null;
+  date_max: string | null;
+  total_files: number;
+  total_bytes: number;
+  total_rows: number;
+  gap_days: number;
+}
+
 /**
- * Read a per-date parquet-manifest.json from GCS. Not cached (called infrequently).
+ * Calendar dates (YYYY-MM-DD) from min to max inclusive that are not in dates.
  */
-export async function getDateManifest(
+export function missingDates(dates: string[], min: string, max: string): string[] {
+  const present = new Set(dates);
+  const missing: string[] = [];
+  const end = Date.parse(`${max}T00:00:00Z`);
+  for (let t = Date.parse(`${min}T00:00:00Z`); t <= end; t += 86_400_000) {
+    const day = new Date(t).toISOString().slice(0, 10);
+    if (!present.has(day)) missing.push(day);
+  }
+  return missing;
+}
+
+export function feedStats(f: FeedSummary): FeedStats {
+  return {
+    feed: f.feed,
+    stream: f.stream,
+    date_min: f.date_min,
+    date_max: f.date_max,
+    date_count: f.dates.length,
+    gap_dates: f.dates.length > 0 ? missingDates(f.dates, f.date_min, f.date_max) : [],
+    total_files: f.total_files,
+    total_bytes: f.total_bytes,
+    total_rows: f.total_rows,
+  };
+}
+
+/**
+ * Aggregate per-feed and catalog-wide statistics for the given feed summaries
+ * (callers pass the feeds the requester may see).
+ */
+export function catalogStats(feeds: FeedSummary[]): CatalogStats {
+  const stats = feeds.map(feedStats);
+  const withDates = stats.filter((f) => f.date_count > 0);
+  return {
+    feeds: stats,
+    date_min: withDates.length > 0 ? withDates.map((f) => f.date_min).sort()[0] : null,
+    date_max: withDates.length > 0 ? withDates.map((f) => f.date_max).sort().at(-1)! : null,
+    total_files: stats.reduce((sum, f) => sum + f.total_files, 0),
+    total_bytes: stats.reduce((sum, f) => sum + f.total_bytes, 0),
+    total_rows: stats.reduce((sum, f) => sum + f.total_rows, 0),
+    gap_days: stats.reduce((sum, f) => sum + f.gap_dates.length, 0),
+  };
+}
+
+/**
+ * Read a per-date parquet-manifest.json from GCS, cached by feed/date.
+ */
+export function getDateManifest(
+  bucket: string,
+  feed: string,
+  stream: string,
+  prefix: string,
+  date: string,
+): Promise<ParquetManifest | null> {
+  return manifestCache.get(feed, date, () => loadDateManifest(bucket, stream, prefix, date));
+}
+
+/**
+ * Files of one feed and date (listParquetFiles), cached by feed/date. Callers
+ * must not mutate the returned array.
+ */
+export function listDateFiles(bucket: string, feed: string, date: string): Promise<ParquetFile[]> {
+  return listingCache.get(feed, date, () => listParquetFiles(bucket, feed, date, date));
+}
+
+/**
+ * Files of one feed from dateFrom to dateTo inclusive, optionally of one
+ * message type, read through the per-date listing cache.
+ */
+export async function listRangeFiles(
   bucket: string,
   feed: string,
+  dateFrom: string,
+  dateTo: string,
+  msgType?: string,
+): Promise<ParquetFile[]> {
+  const files: ParquetFile[] = [];
+  const to = new Date(dateTo);
+  for (let d = new Date(dateFrom); d <= to; d.setUTCDate(d.getUTCDate() + 1)) {
+    for (const file of await listDateFiles(bucket, feed, d.toISOString().slice(0, 10))) {
+      if (!msgType || file.type === msgType) files.push(file);
+    }
+  }
+  return files;
+}
+
+/**
+ * Raw archiver files of one feed and date (listRawFiles), cached by feed/date.
+ * Callers must not mutate the returned array.
+ */
+export function listDateRawFiles(bucket: string, feed: string, date: string): Promise<ParquetFile[]> {
+  return rawListingCache.get(feed, date, () => listRawFiles(bucket, feed, date));
+}
+
+export interface CacheInvalidation {
+  catalog: boolean;
+  manifests: number;
+  listings: number;
+}
+
+/**
+ * Drop cached manifests and listings for feed and/or date (everything when
+ * neither is set). The catalog spans all feeds and dates, so it is always
+ * re-read on the next request.
+ */
+export function invalidateDatasetCache(feed?: string, date?: string): CacheInvalidation {
+  const catalog = catalogCache !== null;
+  catalogCache = null;
+  return {
+    catalog,
+    manifests: manifestCache.invalidate(feed, date),
+    listings: listingCache.invalidate(feed, date) + rawListingCache.invalidate(feed, date),
+  };
+}
+
+export interface CachePrimeFailure {
+  feed: string;
+  date: string;
+  error: string;
+}
+
+export interface CachePrimeResult {
+  primed: number;
+  failed: CachePrimeFailure[];
+}
+
+/**
+ * Load manifests and file listings for every feed/date pair into the cache.
+ * Pairs are loaded a few at a time; failures are collected rather than thrown
+ * so one missing day does not stop the rest.
+ */
+export async function primeDatasetCache(
+  bucket: string,
+  feeds: string[],
+  dates: string[],
+  concurrency = 4,
+): Promise<CachePrimeResult> {
+  const pairs = feeds.flatMap((feed) => dates.map((date) => ({ feed, date })));
+  const result: CachePrimeResult = { primed: 0, failed: [] };
+  let next = 0;
+  const worker = async () => {
+    while (next < pairs.length) {
+      const { feed, date } = pairs[next++];
+      const config = FEED_CONFIG[feed];
+      try {
+        await Promise.all([
+          getDateManifest(bucket, feed, config.stream, config.prefix, date),
+          listDateFiles(bucket, feed, date),
+        ]);
+        result.primed++;
+      } catch (err) {
+        result.failed.push({ feed, date, error: err instanceof Error ? err.message : String(err) });
+      }
+    }
+  };
+  await Promise.all(Array.from({ length: Math.min(concurrency, pairs.length) }, worker));
+  return result;
+}
+
+/** The last `days` UTC dates ending yesterday, oldest first (today is still being written) */
+export function recentDates(days: number, now: Date = new Date()): string[] {
+  const dates: string[] = [];
+  for (let i = days; i >= 1; i--) {
+    const d = new Date(Date.UTC(now.getUTCFullYear(), now.getUTCMonth(), now.getUTCDate() - i));
+    dates.push(d.toISOString().slice(0, 10));
+  }
+  return dates;
+}
+
+/** Milliseconds from now until the next HH:MM UTC, or null if `at` is not HH:MM */
+export function msUntilUtc(at: string, now: Date = new Date()): number | null {
+  const m = /^([01]\d|2[0-3]):([0-5]\d)$/.exec(at);
+  if (!m) {
+    return null;
+  }
+  const target = Date.UTC(now.getUTCFullYear(), now.getUTCMonth(), now.getUTCDate(), Number(m[1]), Number(m[2]));
+  const ms = target - now.getTime();
+  return ms > 0 ? ms : ms + 24 * 60 * 60 * 1000;
+}
+
+async function loadDateManifest(
+  bucket: string,
   stream: string,
   prefix: string,
   date: string,
@@ -3,7 +3,13 @@
  */
 export {
   listParquetFiles,
+  listRawFiles,
+  rawFileSlot,
   generateSignedUrls,
+  generateSignedUrl,
+  findParquetFile,
+  readFileStream,
+  downloadName,
   FEED_CONFIG,
   feedDescription,
   type ParquetFile,
@@ -14,10 +20,35 @@ export {
 export {
   getCatalog,
   getDateManifest,
+  catalogStats,
+  feedStats,
+  missingDates,
+  listDateFiles,
+  listRangeFiles,
+  listDateRawFiles,
+  invalidateDatasetCache,
+  primeDatasetCache,
+  recentDates,
+  msUntilUtc,
+  datasetCacheTtlMs,
+  FeedDateCache,
+  type CacheInvalidation,
+  type CachePrimeResult,
+  type CachePrimeFailure,
   type Catalog,
+  type CatalogStats,
+  type FeedStats,
   type FeedSummary,
   type SchemaInfo,
   type ColumnDef,
   type ParquetManifest,
   type ParquetFileEntry,
 } from "./catalog.ts";
+
+export {
+  GcsObjects,
+  LocalObjects,
+  headLines,
+  type ByteRange,
+  type ObjectStorage,
+} from "./objects.ts";
@@ -0,0 +1,96 @@
+/**
+ * Streamed and ranged object reads. GcsObjects reads from GCS and LocalObjects
+ * from a directory laid out as <root>/<bucket>/<object name>, so callers that
+ * need the start of a large object (sampling) or one byte range never
+ * download the rest of it.
+ */
+import { Readable } from "node:stream";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { TextLineStream } from "https://deno.land/std@0.224.0/streams/text_line_stream.ts";
+import { Storage } from "@google-cloud/storage";
+
+/** Bytes from start (inclusive) to end (exclusive; the end of the object when omitted) */
+export interface ByteRange {
+  start: number;
+  end?: number;
+}
+
+export interface ObjectStorage {
+  /** An object's bytes, streamed as they are read; only the range when one is given */
+  read(bucket: string, name: string, range?: ByteRange): ReadableStream<Uint8Array>;
+}
+
+const LOCAL_READ_CHUNK = 64 * 1024;
+
+function emptyRange(range?: ByteRange): boolean {
+  return range?.end !== undefined && range.end <= range.start;
+}
+
+export class GcsObjects implements ObjectStorage {
+  constructor(private storage: Storage = new Storage()) {}
+
+  read(bucket: string, name: string, range?: ByteRange): ReadableStream<Uint8Array> {
+    if (emptyRange(range)) return ReadableStream.from<Uint8Array>([]);
+    // GCS read streams take an inclusive end offset
+    const opts = range ? { start: range.start, end: range.end === undefined ? undefined : range.end - 1 } : {};
+    const stream = this.storage.bucket(bucket).file(name).createReadStream(opts);
+    return Readable.toWeb(stream) as ReadableStream<Uint8Array>;
+  }
+}
+
+export class LocalObjects implements ObjectStorage {
+  constructor(private root: string) {}
+
+  read(bucket: string, name: string, range?: ByteRange): ReadableStream<Uint8Array> {
+    if (emptyRange(range)) return ReadableStream.from<Uint8Array>([]);
+    const path = join(this.root, bucket, name);
+    const start = range?.start ?? 0;
+    let remaining = range?.end === undefined ? Infinity : range.end - start;
+    let file: Deno.FsFile | undefined;
+    return new ReadableStream<Uint8Array>({
+      async pull(controller) {
+        if (!file) {
+          file = await Deno.open(path);
+          if (start > 0) await file.seek(start, Deno.SeekMode.Start);
+        }
+        const buf = new Uint8Array(Math.min(LOCAL_READ_CHUNK, Math.max(remaining, 0)));
+        const n = buf.byteLength === 0 ? null : await file.read(buf);
+        if (n === null) {
+          file.close();
+          controller.close();
+          return;
+        }
+        remaining -= n;
+        controller.enqueue(buf.subarray(0, n));
+      },
+      cancel() {
+        file?.close();
+      },
+    });
+  }
+}
+
+/**
+ * The first limit lines of a gzipped JSONL object. The object is decompressed
+ * as it streams and the read is cancelled once limit lines are in, so only
+ * the compressed bytes holding them (plus read-ahead) are fetched.
+ */
+export async function headLines(
+  objects: ObjectStorage,
+  bucket: string,
+  name: string,
+  limit: number,
+): Promise<string[]> {
+  const lines: string[] = [];
+  if (limit <= 0) return lines;
+  const stream = objects.read(bucket, name)
+    .pipeThrough(new DecompressionStream("gzip"))
+    .pipeThrough(new TextDecoderStream())
+    .pipeThrough(new TextLineStream());
+  for await (const line of stream) {
+    if (line === "") continue;
+    lines.push(line);
+    if (lines.length >= limit) break;
+  }
+  return lines;
+}
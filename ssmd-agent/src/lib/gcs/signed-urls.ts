@@ -2,7 +2,9 @@
  * GCS signed URL generation for parquet data sharing.
  * Uses Workload Identity (signBlob) for V4 signed URLs.
  */
+import { Readable } from "node:stream";
 import { Storage } from "@google-cloud/storage";
+import { COMPACTED_DIR } from "../archive/compact.ts";
 
 /** Feed → GCS path mapping (matches parquet-gen CronJob layout) */
 export const FEED_CONFIG: Record<string, FeedInfo> = {
@@ -189,6 +191,8 @@ export async function listParquetFiles(
 
       for (const gcsFile of gcsFiles) {
         if (!gcsFile.name.endsWith(".parquet") && !gcsFile.name.endsWith(".csv")) continue;
+        // Compacted raw archive (ssmd data compact) shares the date prefix but is not a per-type file
+        if (gcsFile.name.startsWith(`${gcsPrefix}${COMPACTED_DIR}/`)) continue;
 
         const fileName = gcsFile.name.split("/").pop() ?? "";
         const ext = fileName.endsWith(".csv") ? ".csv" : ".parquet";
@@ -218,6 +222,51 @@ export async function listParquetFiles(
   return files;
 }
 
+/** Raw archiver file name: HHMM rotation slot, "-N" after a restart within the slot */
+const RAW_FILE_NAME = /^(\d{4})(?:-\d+)?\.jsonl\.gz$/;
+
+/** The HHMM slot of a raw archiver file name, or null if it is not one */
+export function rawFileSlot(name: string): string | null {
+  return RAW_FILE_NAME.exec(name)?.[1] ?? null;
+}
+
+/**
+ * List the raw archiver files (rotated JSONL.gz) of a feed's date, from the
+ * nested archiver directory the parquet is generated from. Their type is
+ * "raw" and their hour the rotation slot. Feeds that only have the flat
+ * layout (hols) have none.
+ */
+export async function listRawFiles(bucket: string, feed: string, date: string): Promise<ParquetFile[]> {
+  const config = FEED_CONFIG[feed];
+  if (!config) {
+    throw new Error(`Unknown feed: ${feed}. Valid feeds: ${Object.keys(FEED_CONFIG).join(", ")}`);
+  }
+  if (!scanLayout(config, false)) return [];
+
+  const gcsPrefix = gcsDirPrefix(config, date, false);
+  const [gcsFiles] = await new Storage().bucket(bucket).getFiles({ prefix: gcsPrefix });
+  const files: ParquetFile[] = [];
+  for (const gcsFile of gcsFiles) {
+    const name = gcsFile.name.slice(gcsPrefix.length);
+    const hour = rawFileSlot(name);
+    if (!hour) continue;
+    files.push({ path: gcsFile.name, name, type: "raw", hour, bytes: Number(gcsFile.metadata.size ?? 0) });
+  }
+  return files;
+}
+
+/**
+ * Downloaded filename for a file: prefixed with the date folder segment so
+ * multi-day downloads don't collide (e.g. "2026-06-08-ohlcv-1m-binance.parquet").
+ * file.path is a GCS object key like ".../{date}/{name}"; falls back to the
+ * bare name if the expected date segment isn't present.
+ */
+export function downloadName(file: ParquetFile): string {
+  const segments = file.path.split("/");
+  const dateSegment = segments.length >= 2 ? segments[segments.length - 2] : "";
+  return /^\d{4}-\d{2}-\d{2}$/.test(dateSegment) ? `${dateSegment}-${file.name}` : file.name;
+}
+
 /**
  * Generate V4 signed URLs for parquet files.
  * Max 12h expiration with Workload Identity signBlob.
@@ -234,16 +283,6 @@ export async function generateSignedUrls(
   const results: SignedFile[] = [];
 
   for (const file of files) {
-    // Prefix the downloaded filename with the date folder segment so multi-day
-    // downloads don't collide (e.g. "2026-06-08-ohlcv-1m-binance.parquet").
-    // file.path is a GCS object key like ".../{date}/{name}"; fall back to the
-    // bare name if the expected date segment isn't present.
-    const segments = file.path.split("/");
-    const dateSegment = segments.length >= 2 ? segments[segments.length - 2] : "";
-    const downloadName = /^\d{4}-\d{2}-\d{2}$/.test(dateSegment)
-      ? `${dateSegment}-${file.name}`
-      : file.name;
-
     const [signedUrl] = await storage
       .bucket(bucket)
       .file(file.path)
@@ -251,7 +290,7 @@ export async function generateSignedUrls(
         version: "v4",
         action: "read",
         expires: expiresAt,
-        responseDisposition: `attachment; filename="${downloadName}"`,
+        responseDisposition: `attachment; filename="${downloadName(file)}"`,
       });
 
     results.push({
@@ -263,3 +302,47 @@ export async function generateSignedUrls(
 
   return results;
 }
+
+/**
+ * Find one file of a feed's date by filename: parquet/CSV, or a raw archiver
+ * JSONL.gz file. Names are matched against the listing, so a request can only
+ * reach files the feed layout exposes.
+ */
+export async function findParquetFile(
+  bucket: string,
+  feed: string,
+  date: string,
+  name: string,
+): Promise<ParquetFile | null> {
+  const files = rawFileSlot(name) ? await listRawFiles(bucket, feed, date) : await listParquetFiles(bucket, feed, date, date);
+  return files.find((f) => f.name === name) ?? null;
+}
+
+/**
+ * Generate one short-lived V4 signed URL, for redirecting a single download.
+ */
+export async function generateSignedUrl(
+  bucket: string,
+  file: ParquetFile,
+  expiresInSeconds: number,
+): Promise<SignedFile> {
+  const expiresAt = new Date(Date.now() + expiresInSeconds * 1000);
+  const [signedUrl] = await new Storage()
+    .bucket(bucket)
+    .file(file.path)
+    .getSignedUrl({
+      version: "v4",
+      action: "read",
+      expires: expiresAt,
+      responseDisposition: `attachment; filename="${downloadName(file)}"`,
+    });
+  return { ...file, signedUrl, expiresAt: expiresAt.toISOString() };
+}
+
+/**
+ * Stream a file's bytes from GCS, for keys that may not receive signed URLs.
+ */
+export function readFileStream(bucket: string, file: ParquetFile): ReadableStream<Uint8Array> {
+  const stream = new Storage().bucket(bucket).file(file.path).createReadStream();
+  return Readable.toWeb(stream) as ReadableStream<Uint8Array>;
+}
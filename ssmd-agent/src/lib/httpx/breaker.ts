@@ -0,0 +1,86 @@
+/**
+ * Per-host circuit breaker.
+ *
+ * After `failureThreshold` consecutive failures the breaker opens and requests
+ * fail fast for `resetAfterMs`. It then half-opens: one trial request is let
+ * through, closing the breaker on success or re-opening it on failure.
+ */
+
+export type BreakerState = "closed" | "open" | "half-open";
+
+export interface CircuitBreakerOptions {
+  /** Consecutive failures that open the breaker (default: 5) */
+  failureThreshold?: number;
+  /** How long the breaker stays open before a trial request (default: 30s) */
+  resetAfterMs?: number;
+  /** Clock, injectable for tests */
+  now?: () => number;
+}
+
+export class CircuitOpenError extends Error {
+  constructor(readonly host: string, readonly retryInMs: number) {
+    super(`circuit open for ${host}, retry in ${retryInMs}ms`);
+    this.name = "CircuitOpenError";
+  }
+}
+
+export class CircuitBreaker {
+  private failures = 0;
+  private openedAt: number | null = null;
+  private trialInFlight = false;
+  private readonly failureThreshold: number;
+  private readonly resetAfterMs: number;
+  private readonly now: () => number;
+
+  constructor(readonly host: string, options: CircuitBreakerOptions = {}) {
+    this.failureThreshold = options.failureThreshold ?? 5;
+    this.resetAfterMs = options.resetAfterMs ?? 30_000;
+    this.now = options.now ?? Date.now;
+  }
+
+  get state(): BreakerState {
+    if (this.openedAt === null) return "closed";
+    return this.now() - this.openedAt >= this.resetAfterMs ? "half-open" : "open";
+  }
+
+  /**
+   * Throw CircuitOpenError unless a request may go out now. In the half-open
+   * state only one trial request is allowed at a time.
+   */
+  check(): void {
+    const state = this.state;
+    if (state === "closed") return;
+    if (state === "half-open" && !this.trialInFlight) {
+      this.trialInFlight = true;
+      return;
+    }
+    const retryInMs = Math.max(0, this.openedAt! + this.resetAfterMs - this.now());
+    throw new CircuitOpenError(this.host, retryInMs);
+  }
+
+  onSuccess(): void {
+    this.failures = 0;
+    this.openedAt = null;
+    this.trialInFlight = false;
+  }
+
+  onFailure(): void {
+    this.failures++;
+    if (this.trialInFlight || this.failures >= this.failureThreshold) {
+      this.openedAt = this.now();
+    }
+    this.trialInFlight = false;
+  }
+}
+
+const breakers = new Map<string, CircuitBreaker>();
+
+/** Process-wide breaker for a host, shared by every client that calls it */
+export function breakerFor(host: string, options?: CircuitBreakerOptions): CircuitBreaker {
+  let breaker = breakers.get(host);
+  if (!breaker) {
+    breaker = new CircuitBreaker(host, options);
+    breakers.set(host, breaker);
+  }
+  return breaker;
+}
@@ -0,0 +1,239 @@
+/**
+ * Shared HTTP client for exchange REST APIs: timeouts, jittered exponential
+ * backoff, Retry-After handling, caller cancellation, an optional rate budget,
+ * a per-host circuit breaker and per-host Prometheus metrics.
+ *
+ * Retried: network errors and timeouts, 408, 429 and 5xx. Other 4xx responses
+ * are returned to the caller as HttpError straight away. A caller abort (via
+ * `signal`) is never retried and does not count against the host.
+ */
+import { globalRegistry } from "../utils/metrics.ts";
+import type { RateBudget } from "../utils/rate-budget.ts";
+import { breakerFor, type CircuitBreaker, type CircuitBreakerOptions } from "./breaker.ts";
+
+export const httpClientRequestsTotal = globalRegistry.counter(
+  "ssmd_http_client_requests_total",
+  "Outbound HTTP requests by host and status (\"error\" for network failures)",
+  ["host", "status"],
+);
+
+export const httpClientRequestDuration = globalRegistry.histogram(
+  "ssmd_http_client_request_duration_seconds",
+  "Outbound HTTP request duration by host",
+  ["host"],
+  [0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30],
+);
+
+export const httpClientRetriesTotal = globalRegistry.counter(
+  "ssmd_http_client_retries_total",
+  "Outbound HTTP retries by host and reason (status code or \"network\")",
+  ["host", "reason"],
+);
+
+export const httpClientCircuitOpen = globalRegistry.gauge(
+  "ssmd_http_client_circuit_open",
+  "1 while the host's circuit breaker is open or half-open",
+  ["host"],
+);
+
+export interface RetryPolicy {
+  /** Retries after the first attempt */
+  maxRetries: number;
+  /** Backoff before the first retry; doubles each retry */
+  initialDelayMs: number;
+  /** Backoff ceiling */
+  maxDelayMs: number;
+}
+
+export const DEFAULT_RETRY_POLICY: RetryPolicy = {
+  maxRetries: 3,
+  initialDelayMs: 1000,
+  maxDelayMs: 60_000,
+};
+
+export class HttpError extends Error {
+  constructor(readonly api: string, readonly status: number, readonly body: string) {
+    super(`${api} API error ${status}: ${body}`);
+    this.name = "HttpError";
+  }
+}
+
+/**
+ * Backoff before retry `attempt` (0-based): initialDelayMs doubling per
+ * attempt, capped at maxDelayMs, with ±25% jitter.
+ */
+export function backoffDelay(attempt: number, policy: RetryPolicy, random: () => number = Math.random): number {
+  const base = Math.min(policy.initialDelayMs * 2 ** attempt, policy.maxDelayMs);
+  return Math.round(base + base * 0.25 * (random() * 2 - 1));
+}
+
+/** Retry-After in ms (delta-seconds or an HTTP date), undefined when absent or invalid */
+export function parseRetryAfter(value: string | null, now: number = Date.now()): number | undefined {
+  if (!value) return undefined;
+  const seconds = Number(value);
+  if (Number.isFinite(seconds) && seconds >= 0) return seconds * 1000;
+  const at = Date.parse(value);
+  return Number.isNaN(at) ? undefined : Math.max(0, at - now);
+}
+
+/** Sleep that rejects with the signal's reason when the caller aborts */
+export function abortableSleep(ms: number, signal?: AbortSignal): Promise<void> {
+  return new Promise((resolve, reject) => {
+    if (signal?.aborted) {
+      reject(signal.reason);
+      return;
+    }
+    const timer = setTimeout(() => {
+      signal?.removeEventListener("abort", onAbort);
+      resolve();
+    }, ms);
+    const onAbort = () => {
+      clearTimeout(timer);
+      reject(signal!.reason);
+    };
+    signal?.addEventListener("abort", onAbort, { once: true });
+  });
+}
+
+export interface HttpClientOptions {
+  /** API name for errors and logs, e.g. "Kalshi" */
+  name: string;
+  retry?: Partial<RetryPolicy>;
+  /** Minimum wait after a 429 when Retry-After is shorter or absent (default: initialDelayMs) */
+  minRateLimitWaitMs?: number;
+  /** Per-attempt timeout (default: 30s) */
+  timeoutMs?: number;
+  /** Headers sent with every request */
+  headers?: HeadersInit;
+  /** Budget consulted before every attempt and exhausted on 429 */
+  budget?: RateBudget;
+  /** Breaker settings for hosts this client calls, or false to disable */
+  breaker?: CircuitBreakerOptions | false;
+  /** Injectable for tests */
+  fetch?: typeof fetch;
+  sleep?: (ms: number, signal?: AbortSignal) => Promise<void>;
+}
+
+export interface RequestOptions extends RequestInit {
+  /** Overrides the client's per-attempt timeout */
+  timeoutMs?: number;
+}
+
+export class HttpClient {
+  readonly name: string;
+  private readonly policy: RetryPolicy;
+  private readonly minRateLimitWaitMs: number;
+  private readonly timeoutMs: number;
+  private readonly headers: HeadersInit;
+  private readonly budget?: RateBudget;
+  private readonly breakerOptions: CircuitBreakerOptions | false;
+  private readonly fetchFn: typeof fetch;
+  private readonly sleep: (ms: number, signal?: AbortSignal) => Promise<void>;
+
+  constructor(options: HttpClientOptions) {
+    this.name = options.name;
+    this.policy = { ...DEFAULT_RETRY_POLICY, ...options.retry };
+    this.minRateLimitWaitMs = options.minRateLimitWaitMs ?? this.policy.initialDelayMs;
+    this.timeoutMs = options.timeoutMs ?? 30_000;
+    this.headers = options.headers ?? {};
+    this.budget = options.budget;
+    this.breakerOptions = options.breaker ?? {};
+    this.fetchFn = options.fetch ?? fetch;
+    this.sleep = options.sleep ?? abortableSleep;
+  }
+
+  /**
+   * Send a request, retrying per the client's policy. Resolves with a 2xx
+   * response; rejects with HttpError, CircuitOpenError, or the fetch error
+   * once retries run out.
+   */
+  async request(url: string, init: RequestOptions = {}): Promise<Response> {
+    const host = new URL(url).host;
+    const breaker = this.breakerOptions === false ? null : breakerFor(host, this.breakerOptions);
+    const { timeoutMs = this.timeoutMs, signal: callerSignal, ...rest } = init;
+    const headers = new Headers(this.headers);
+    new Headers(init.headers).forEach((value, key) => headers.set(key, value));
+
+    for (let attempt = 0;; attempt++) {
+      callerSignal?.throwIfAborted();
+      breaker?.check();
+      await this.budget?.acquire();
+
+      const timeout = AbortSignal.timeout(timeoutMs);
+      const signal = callerSignal ? AbortSignal.any([callerSignal, timeout]) : timeout;
+      const started = performance.now();
+      let res: Response;
+      try {
+        res = await this.fetchFn(url, { ...rest, headers, signal });
+      } catch (e) {
+        if (callerSignal?.aborted) throw e;
+        httpClientRequestsTotal.inc({ host, status: "error" });
+        this.recordFailure(breaker, host);
+        if (attempt >= this.policy.maxRetries) throw e;
+        await this.backoff(host, "network", backoffDelay(attempt, this.policy), attempt, (e as Error).message, callerSignal);
+        continue;
+      } finally {
+        httpClientRequestDuration.observe({ host }, (performance.now() - started) / 1000);
+      }
+      httpClientRequestsTotal.inc({ host, status: String(res.status) });
+
+      if (res.ok) {
+        this.recordSuccess(breaker, host);
+        return res;
+      }
+
+      const error = new HttpError(this.name, res.status, await res.text());
+      let wait: number;
+      if (res.status === 429) {
+        // The host is up, so a 429 does not count toward the breaker
+        this.recordSuccess(breaker, host);
+        // Spend the shared budget so cooperating jobs back off too
+        await this.budget?.exhaust();
+        wait = Math.max(
+          this.minRateLimitWaitMs,
+          parseRetryAfter(res.headers.get("Retry-After")) ?? backoffDelay(attempt, this.policy),
+        );
+      } else if (res.status === 408 || res.status >= 500) {
+        this.recordFailure(breaker, host);
+        wait = backoffDelay(attempt, this.policy);
+      } else {
+        this.recordSuccess(breaker, host);
+        throw error;
+      }
+
+      if (attempt >= this.policy.maxRetries) throw error;
+      await this.backoff(host, String(res.status), wait, attempt, `status ${res.status}`, callerSignal);
+    }
+  }
+
+  /** GET url and parse the JSON body */
+  async getJson<T>(url: string, init: RequestOptions = {}): Promise<T> {
+    const res = await this.request(url, init);
+    return await res.json() as T;
+  }
+
+  private async backoff(
+    host: string,
+    reason: string,
+    wait: number,
+    attempt: number,
+    detail: string,
+    signal?: AbortSignal,
+  ): Promise<void> {
+    httpClientRetriesTotal.inc({ host, reason });
+    console.log(`  [${this.name}] ${detail}, retry ${attempt + 1}/${this.policy.maxRetries} in ${wait}ms`);
+    await this.sleep(wait, signal);
+  }
+
+  private recordSuccess(breaker: CircuitBreaker | null, host: string): void {
+    if (!breaker) return;
+    breaker.onSuccess();
+    httpClientCircuitOpen.set({ host }, 0);
+  }
+
+  private recordFailure(breaker: CircuitBreaker | null, host: string): void {
+    if (!breaker) return;
+    breaker.onFailure();
+    httpClientCircuitOpen.set({ host }, breaker.state === "closed" ? 0 : 1);
+  }
+}
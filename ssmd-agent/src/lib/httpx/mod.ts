@@ -0,0 +1,18 @@
+export {
+  abortableSleep,
+  backoffDelay,
+  DEFAULT_RETRY_POLICY,
+  HttpClient,
+  HttpError,
+  parseRetryAfter,
+  type HttpClientOptions,
+  type RequestOptions,
+  type RetryPolicy,
+} from "./client.ts";
+export {
+  breakerFor,
+  CircuitBreaker,
+  CircuitOpenError,
+  type BreakerState,
+  type CircuitBreakerOptions,
+} from "./breaker.ts";
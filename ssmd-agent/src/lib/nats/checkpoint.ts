@@ -0,0 +1,56 @@
+/**
+ * Per-consumer JetStream sequence checkpoints: the last stream sequence a
+ * consumer finished processing, so a restarted job resumes after it instead
+ * of starting over. Checkpoints live in one ConfigMap per namespace, keyed
+ * "<stream>.<consumer>" with a JSON value; `ssmd nats checkpoint` reads and
+ * writes them and `ssmd data replay --checkpoint` resumes from them.
+ */
+
+export const CHECKPOINT_CONFIGMAP = "nats-checkpoints";
+
+/** Last stream sequence a consumer finished processing */
+export interface Checkpoint {
+  stream: string;
+  consumer: string;
+  sequence: number;
+  updated_at: string;
+}
+
+/** Where checkpoints are kept; the CLI implements it over kubectl */
+export interface CheckpointStore {
+  /** Checkpoints, optionally of one stream, sorted by stream then consumer */
+  list(stream?: string): Promise<Checkpoint[]>;
+  get(stream: string, consumer: string): Promise<Checkpoint | null>;
+  set(stream: string, consumer: string, sequence: number): Promise<void>;
+}
+
+/** ConfigMap key for a stream's consumer; JetStream names cannot contain '.' */
+export function checkpointKey(stream: string, consumer: string): string {
+  if (!stream || !consumer) {
+    throw new Error("stream and consumer are required");
+  }
+  const key = `${stream}.${consumer}`;
+  if (stream.includes(".") || consumer.includes(".") || !/^[-._a-zA-Z0-9]+$/.test(key)) {
+    throw new Error(`invalid checkpoint key '${key}'`);
+  }
+  return key;
+}
+
+/** ConfigMap value for a checkpoint */
+export function checkpointValue(sequence: number, now: Date): string {
+  return JSON.stringify({ sequence, updated_at: now.toISOString() });
+}
+
+/** Checkpoints in ConfigMap data, optionally limited to one stream, sorted by stream then consumer */
+export function parseCheckpoints(data: Record<string, string> | undefined, stream?: string): Checkpoint[] {
+  const checkpoints: Checkpoint[] = [];
+  for (const [key, value] of Object.entries(data ?? {})) {
+    const dot = key.indexOf(".");
+    if (dot < 0) continue;
+    const cp = { stream: key.slice(0, dot), consumer: key.slice(dot + 1) };
+    if (stream && cp.stream !== stream) continue;
+    const parsed = JSON.parse(value) as { sequence: number; updated_at: string };
+    checkpoints.push({ ...cp, sequence: parsed.sequence, updated_at: parsed.updated_at });
+  }
+  return checkpoints.sort((a, b) => a.stream.localeCompare(b.stream) || a.consumer.localeCompare(b.consumer));
+}
@@ -0,0 +1,40 @@
+import { connect, type NatsConnection } from "npm:nats";
+
+let natsClient: NatsConnection | null = null;
+let connecting: Promise<NatsConnection> | null = null;
+
+/**
+ * Get or create the shared NATS connection (NATS_URL, default nats://localhost:4222).
+ * The client reconnects on its own; a closed connection is replaced.
+ */
+export async function getNats(): Promise<NatsConnection> {
+  if (natsClient && !natsClient.isClosed()) {
+    return natsClient;
+  }
+  if (!connecting) {
+    const servers = Deno.env.get("NATS_URL") ?? "nats://localhost:4222";
+    connecting = connect({
+      servers,
+      reconnect: true,
+      maxReconnectAttempts: -1,
+      reconnectTimeWait: 2000,
+    }).then((nc) => {
+      console.log("[nats] Connected to", servers);
+      natsClient = nc;
+      return nc;
+    }).finally(() => {
+      connecting = null;
+    });
+  }
+  return await connecting;
+}
+
+/**
+ * Close the NATS connection.
+ */
+export async function closeNats(): Promise<void> {
+  if (natsClient) {
+    await natsClient.drain();
+    natsClient = null;
+  }
+}
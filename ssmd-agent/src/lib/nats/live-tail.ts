@@ -0,0 +1,175 @@
+/**
+ * Live tail — relay JSON market data from NATS to an HTTP client as
+ * Server-Sent Events, so dashboards and notebooks need no NATS access.
+ *
+ * Connector subjects are <prefix>[.<category>].json.<type>.<ticker>
+ * (see the Rust SubjectBuilder); Kalshi connectors add a category token.
+ */
+import { type NatsConnection, type Subscription } from "npm:nats";
+import { recordResolver, type RecordFilter } from "../filter/mod.ts";
+
+/** Subject prefix per feed, matching the connector transport defaults */
+export const LIVE_TAIL_PREFIXES: Record<string, string> = {
+  "kalshi": "prod.kalshi",
+  "kraken-spot": "prod.kraken",
+  "kraken-futures": "prod.kraken-futures",
+  "polymarket": "prod.polymarket",
+};
+
+/** Tickers per tail; each adds two subscriptions */
+export const MAX_TAIL_TICKERS = 50;
+
+/** Comment line sent when idle so proxies keep the connection open */
+const KEEPALIVE_MS = 15_000;
+
+export interface TailFilter {
+  /** Message types (subject token after "json"); empty = all */
+  types: Set<string>;
+  /** Tickers (last subject token, sanitized with sanitizeToken); empty = all */
+  tickers: Set<string>;
+  /** Expression tested against each message body (see lib/filter) */
+  where?: RecordFilter;
+}
+
+/**
+ * Extract the message type and ticker from a JSON subject, or null for
+ * non-JSON subjects (e.g. Cap'n Proto).
+ */
+export function parseTailSubject(subject: string): { type: string; ticker: string } | null {
+  const tokens = subject.split(".");
+  const json = tokens.indexOf("json");
+  if (json < 0 || json + 2 >= tokens.length) return null;
+  return { type: tokens[json + 1], ticker: tokens.slice(json + 2).join(".") };
+}
+
+/**
+ * Subjects to subscribe to. With tickers, subscribe per ticker (with and
+ * without a category token) so NATS does the filtering; otherwise the whole feed.
+ */
+export function tailSubjects(prefix: string, filter: TailFilter): string[] {
+  if (filter.tickers.size === 0) {
+    return [`${prefix}.>`];
+  }
+  return [...filter.tickers].flatMap((t) => [`${prefix}.json.*.${t}`, `${prefix}.*.json.*.${t}`]);
+}
+
+export function matchesTailFilter(parsed: { type: string; ticker: string }, filter: TailFilter): boolean {
+  if (filter.types.size > 0 && !filter.types.has(parsed.type)) return false;
+  if (filter.tickers.size > 0 && !filter.tickers.has(parsed.ticker)) return false;
+  return true;
+}
+
+/** Filter fields for a tailed message: type and ticker from the subject, the rest from the body */
+export function tailResolver(parsed: { type: string; ticker: string }, data: unknown) {
+  const record = data !== null && typeof data === "object" ? data as Record<string, unknown> : {};
+  return recordResolver(record, { type: parsed.type, ticker: parsed.ticker });
+}
+
+/**
+ * Fixed one-second window limiter. Messages over the limit are dropped and
+ * counted, so a slow client sees a sample rather than a growing backlog.
+ */
+export class RateLimiter {
+  private windowStart = 0;
+  private count = 0;
+  dropped = 0;
+
+  constructor(private readonly maxPerSecond: number) {}
+
+  allow(nowMs: number): boolean {
+    if (nowMs - this.windowStart >= 1000) {
+      this.windowStart = nowMs;
+      this.count = 0;
+    }
+    if (this.count >= this.maxPerSecond) {
+      this.dropped++;
+      return false;
+    }
+    this.count++;
+    return true;
+  }
+}
+
+/**
+ * Stream matching messages as SSE "message" events with data
+ * {subject, type, ticker, data}. Once a second with drops, a "dropped" event
+ * reports the running total. Subscriptions end when the client disconnects.
+ */
+export function liveTailStream(
+  nc: NatsConnection,
+  prefix: string,
+  filter: TailFilter,
+  maxPerSecond: number,
+  signal?: AbortSignal,
+): ReadableStream<Uint8Array> {
+  const encoder = new TextEncoder();
+  const decoder = new TextDecoder();
+  const limiter = new RateLimiter(maxPerSecond);
+  const subs: Subscription[] = [];
+  let keepalive: number | undefined;
+  let closed = false;
+
+  const cleanup = () => {
+    if (closed) return;
+    closed = true;
+    clearInterval(keepalive);
+    for (const sub of subs) sub.unsubscribe();
+  };
+
+  return new ReadableStream<Uint8Array>({
+    start(controller) {
+      let lastSent = Date.now();
+      const send = (frame: string) => {
+        if (closed) return;
+        try {
+          controller.enqueue(encoder.encode(frame));
+          lastSent = Date.now();
+        } catch {
+          cleanup();
+        }
+      };
+
+      let reportedDrops = 0;
+      keepalive = setInterval(() => {
+        if (limiter.dropped > reportedDrops) {
+          reportedDrops = limiter.dropped;
+          send(`event: dropped\ndata: ${JSON.stringify({ dropped: reportedDrops })}\n\n`);
+        } else if (Date.now() - lastSent >= KEEPALIVE_MS) {
+          send(": keepalive\n\n");
+        }
+      }, 1000);
+
+      for (const subject of tailSubjects(prefix, filter)) {
+        const sub = nc.subscribe(subject);
+        subs.push(sub);
+        (async () => {
+          for await (const msg of sub) {
+            const parsed = parseTailSubject(msg.subject);
+            if (!parsed || !matchesTailFilter(parsed, filter)) continue;
+            let data: unknown;
+            try {
+              data = JSON.parse(decoder.decode(msg.data));
+            } catch {
+              continue;
+            }
+            // Filtered-out messages do not count against the rate limit
+            if (filter.where && !filter.where.matches(tailResolver(parsed, data))) continue;
+            if (!limiter.allow(Date.now())) continue;
+            send(`event: message\ndata: ${JSON.stringify({ subject: msg.subject, ...parsed, data })}\n\n`);
+          }
+        })().catch(() => cleanup());
+      }
+
+      send(`event: subscribed\ndata: ${JSON.stringify({ subjects: tailSubjects(prefix, filter) })}\n\n`);
+      signal?.addEventListener("abort", () => {
+        cleanup();
+        try {
+          controller.close();
+        } catch { /* already closed */ }
+      });
+    },
+    cancel() {
+      cleanup();
+    },
+  });
+}
@@ -0,0 +1,20 @@
+export { getNats, closeNats } from "./client.ts";
+export {
+  LIVE_TAIL_PREFIXES,
+  MAX_TAIL_TICKERS,
+  parseTailSubject,
+  tailSubjects,
+  matchesTailFilter,
+  tailResolver,
+  RateLimiter,
+  liveTailStream,
+  type TailFilter,
+} from "./live-tail.ts";
+export {
+  CHECKPOINT_CONFIGMAP,
+  checkpointKey,
+  checkpointValue,
+  parseCheckpoints,
+  type Checkpoint,
+  type CheckpointStore,
+} from "./checkpoint.ts";
@@ -0,0 +1,184 @@
+/**
+ * Rolling baseline from previous days' quality reports, and the anomalies and
+ * Notifier alerts raised when a window strays from it.
+ *
+ * Baselines are daily. A live window is compared against daily figures, so
+ * its rates and spreads are like-for-like, while its quote gaps (bounded by
+ * the window) only alert on gaps longer than a typical whole day's worst.
+ */
+import { sanitizeToken } from "../archive/mod.ts";
+import type { QualityReport, TickerQuality } from "./metrics.ts";
+
+export type QualityMetric = "rate_per_min" | "max_quote_gap_sec" | "spread_p50_bps";
+
+/** Which way a metric moves when capture degrades */
+const WORSE: Record<QualityMetric, 1 | -1> = {
+  rate_per_min: -1,
+  max_quote_gap_sec: 1,
+  spread_p50_bps: 1,
+};
+
+const METRICS = Object.keys(WORSE) as QualityMetric[];
+
+export interface MetricBaseline {
+  mean: number;
+  stddev: number;
+  /** Days contributing a value */
+  days: number;
+}
+
+export type TickerBaseline = Partial<Record<QualityMetric, MetricBaseline>>;
+
+export interface QualityBaseline {
+  /** Reports the baseline was built from */
+  days: number;
+  tickers: Record<string, TickerBaseline>;
+}
+
+function metricValue(t: TickerQuality, metric: QualityMetric): number | null {
+  switch (metric) {
+    case "rate_per_min":
+      return t.rate_per_min;
+    case "max_quote_gap_sec":
+      return t.max_quote_gap_sec;
+    case "spread_p50_bps":
+      return t.spread_bps?.p50 ?? null;
+  }
+}
+
+/** Per-ticker mean and population stddev of each metric across the reports */
+export function buildBaseline(reports: QualityReport[]): QualityBaseline {
+  const values = new Map<string, Record<QualityMetric, number[]>>();
+  for (const report of reports) {
+    for (const [ticker, t] of Object.entries(report.tickers)) {
+      let v = values.get(ticker);
+      if (!v) {
+        v = { rate_per_min: [], max_quote_gap_sec: [], spread_p50_bps: [] };
+        values.set(ticker, v);
+      }
+      for (const metric of METRICS) {
+        const x = metricValue(t, metric);
+        if (x !== null) v[metric].push(x);
+      }
+    }
+  }
+
+  const tickers: Record<string, TickerBaseline> = {};
+  for (const [ticker, v] of values) {
+    const baseline: TickerBaseline = {};
+    for (const metric of METRICS) {
+      const xs = v[metric];
+      if (xs.length === 0) continue;
+      const mean = xs.reduce((a, b) => a + b, 0) / xs.length;
+      const variance = xs.reduce((a, x) => a + (x - mean) ** 2, 0) / xs.length;
+      baseline[metric] = { mean, stddev: Math.sqrt(variance), days: xs.length };
+    }
+    tickers[ticker] = baseline;
+  }
+  return { days: reports.length, tickers };
+}
+
+export interface AnomalyOptions {
+  /** Standard deviations in the degrading direction before flagging */
+  zThreshold: number;
+  /** Days of history a metric needs before it is checked */
+  minDays: number;
+  /** Tickers averaging fewer messages per minute are too sparse to check */
+  minRate: number;
+}
+
+export const DEFAULT_ANOMALY_OPTIONS: AnomalyOptions = { zThreshold: 3, minDays: 3, minRate: 1 };
+
+export interface QualityAnomaly {
+  ticker: string;
+  /** "missing" when a ticker with a baseline sent nothing in the window */
+  metric: QualityMetric | "missing";
+  value: number | null;
+  baseline_mean: number;
+  baseline_stddev: number;
+  z: number | null;
+  severity: "medium" | "high";
+}
+
+/**
+ * z-score against a baseline. The stddev is floored at 10% of the mean so a
+ * ticker that is unusually steady does not alert on small moves.
+ */
+export function zScore(value: number, baseline: MetricBaseline): number {
+  const sd = Math.max(baseline.stddev, Math.abs(baseline.mean) * 0.1, 1e-9);
+  return (value - baseline.mean) / sd;
+}
+
+/** Compare a window's report with the baseline; worst anomalies first */
+export function detectAnomalies(
+  report: QualityReport,
+  baseline: QualityBaseline,
+  options: AnomalyOptions = DEFAULT_ANOMALY_OPTIONS,
+): QualityAnomaly[] {
+  const anomalies: QualityAnomaly[] = [];
+  for (const [ticker, b] of Object.entries(baseline.tickers)) {
+    const rate = b.rate_per_min;
+    if (!rate || rate.days < options.minDays || rate.mean < options.minRate) continue;
+
+    const t = report.tickers[ticker];
+    if (!t) {
+      anomalies.push({
+        ticker,
+        metric: "missing",
+        value: null,
+        baseline_mean: rate.mean,
+        baseline_stddev: rate.stddev,
+        z: null,
+        severity: "high",
+      });
+      continue;
+    }
+
+    for (const metric of METRICS) {
+      const mb = b[metric];
+      const value = metricValue(t, metric);
+      if (!mb || mb.days < options.minDays || value === null) continue;
+      const z = zScore(value, mb);
+      if (z * WORSE[metric] < options.zThreshold) continue;
+      anomalies.push({
+        ticker,
+        metric,
+        value,
+        baseline_mean: mb.mean,
+        baseline_stddev: mb.stddev,
+        z,
+        severity: Math.abs(z) >= options.zThreshold * 2 ? "high" : "medium",
+      });
+    }
+  }
+
+  const rank = (a: QualityAnomaly) => a.z === null ? Infinity : Math.abs(a.z);
+  return anomalies.sort((a, b) => rank(b) - rank(a) || a.ticker.localeCompare(b.ticker));
+}
+
+/** Alert published for the Notifier; match rules can select on type, metric and severity */
+export interface QualityAlert extends QualityAnomaly {
+  type: "quality";
+  feed: string;
+  source: QualityReport["source"];
+  window_start: string;
+  window_end: string;
+  detected_at: string;
+}
+
+/** alerts.<feed>.quality.<metric>.<ticker>, under the Notifier's alerts.<feed>.> subscription */
+export function qualityAlertSubject(feed: string, anomaly: QualityAnomaly): string {
+  return `alerts.${sanitizeToken(feed)}.quality.${anomaly.metric}.${sanitizeToken(anomaly.ticker)}`;
+}
+
+export function qualityAlert(report: QualityReport, anomaly: QualityAnomaly, now: number = Date.now()): QualityAlert {
+  return {
+    type: "quality",
+    feed: report.feed,
+    source: report.source,
+    window_start: report.window_start,
+    window_end: report.window_end,
+    detected_at: new Date(now).toISOString(),
+    ...anomaly,
+  };
+}
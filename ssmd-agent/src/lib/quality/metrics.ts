@@ -0,0 +1,178 @@
+/**
+ * Per-ticker capture quality over a window: message rate, quote staleness and
+ * the distribution of quoted spreads. Fed from archived JSONL or live NATS;
+ * the same report shape is saved per day as the baseline for later windows.
+ */
+
+/**
+ * Spread histogram bucket upper bounds, in basis points of mid. Spreads wider
+ * than the last bound are counted in the last bucket.
+ */
+export const SPREAD_BUCKETS_BPS = [1, 2, 5, 10, 20, 50, 100, 200, 500, 1000, 2000, 5000, 10000];
+
+export interface Quote {
+  bid: number;
+  ask: number;
+}
+
+const num = (v: unknown): number | null => {
+  if (typeof v === "number") return Number.isFinite(v) ? v : null;
+  if (typeof v === "string" && v !== "") {
+    const n = Number(v);
+    return Number.isFinite(n) ? n : null;
+  }
+  return null;
+};
+
+/**
+ * Top-of-book quote carried by a message, or null when it carries none.
+ * Kalshi tickers quote yes_bid/yes_ask in integer cents (old format) or
+ * yes_bid_dollars/yes_ask_dollars strings (new format).
+ */
+export function extractQuote(feed: string, json: Record<string, unknown>): Quote | null {
+  let bid: number | null = null;
+  let ask: number | null = null;
+  switch (feed) {
+    case "kalshi": {
+      if (json.type !== "ticker") return null;
+      const msg = json.msg as Record<string, unknown> | undefined;
+      if (!msg) return null;
+      bid = typeof msg.yes_bid === "number" ? msg.yes_bid / 100 : num(msg.yes_bid_dollars);
+      ask = typeof msg.yes_ask === "number" ? msg.yes_ask / 100 : num(msg.yes_ask_dollars);
+      break;
+    }
+    case "kraken":
+    case "kraken-spot": {
+      if (json.channel !== "ticker" || !Array.isArray(json.data)) return null;
+      const data = json.data[0] as Record<string, unknown> | undefined;
+      bid = num(data?.bid);
+      ask = num(data?.ask);
+      break;
+    }
+    case "kraken-futures":
+      if (json.feed !== "ticker") return null;
+      bid = num(json.bid);
+      ask = num(json.ask);
+      break;
+    default:
+      return null;
+  }
+  return bid === null || ask === null ? null : { bid, ask };
+}
+
+/** Spread in basis points of mid, or null for one-sided, empty or crossed books */
+export function spreadBps(quote: Quote): number | null {
+  if (quote.bid <= 0 || quote.ask <= 0 || quote.ask < quote.bid) return null;
+  return ((quote.ask - quote.bid) / ((quote.ask + quote.bid) / 2)) * 10_000;
+}
+
+export interface TickerQuality {
+  messages: number;
+  rate_per_min: number;
+  quotes: number;
+  /** Longest gap between consecutive quotes, null with fewer than two */
+  max_quote_gap_sec: number | null;
+  /** Time from the last quote to the end of the window, null without quotes */
+  last_quote_age_sec: number | null;
+  /** Histogram percentiles (bucket upper bounds), null without two-sided quotes */
+  spread_bps: { p50: number; p90: number } | null;
+}
+
+export interface QualityReport {
+  feed: string;
+  source: "archive" | "live";
+  window_start: string;
+  window_end: string;
+  generated_at: string;
+  /** Messages repeating one already counted (lib/archive/dedup.ts), left out of the ticker metrics */
+  duplicates: number;
+  tickers: Record<string, TickerQuality>;
+}
+
+interface TickerState {
+  messages: number;
+  quotes: number;
+  lastQuoteMs: number | null;
+  maxGapMs: number | null;
+  spreadCounts: number[];
+}
+
+/** Bucket upper bound at percentile p (0-1) of a spread histogram */
+export function histogramPercentile(counts: number[], p: number): number | null {
+  const total = counts.reduce((a, b) => a + b, 0);
+  if (total === 0) return null;
+  const target = p * total;
+  let seen = 0;
+  for (let i = 0; i < counts.length; i++) {
+    seen += counts[i];
+    if (seen >= target) return SPREAD_BUCKETS_BPS[i];
+  }
+  return SPREAD_BUCKETS_BPS[SPREAD_BUCKETS_BPS.length - 1];
+}
+
+export class QualityAccumulator {
+  private readonly tickers = new Map<string, TickerState>();
+  private duplicates = 0;
+
+  constructor(readonly feed: string) {}
+
+  /**
+   * Count one message received at timeMs. Messages arriving before the
+   * ticker's last quote (overlapping archive files) count toward the rate and
+   * spreads but not toward gaps.
+   */
+  add(ticker: string, timeMs: number, quote: Quote | null): void {
+    let state = this.tickers.get(ticker);
+    if (!state) {
+      state = { messages: 0, quotes: 0, lastQuoteMs: null, maxGapMs: null, spreadCounts: SPREAD_BUCKETS_BPS.map(() => 0) };
+      this.tickers.set(ticker, state);
+    }
+    state.messages++;
+    if (!quote) return;
+
+    state.quotes++;
+    if (state.lastQuoteMs === null || timeMs >= state.lastQuoteMs) {
+      if (state.lastQuoteMs !== null) {
+        state.maxGapMs = Math.max(state.maxGapMs ?? 0, timeMs - state.lastQuoteMs);
+      }
+      state.lastQuoteMs = timeMs;
+    }
+    const spread = spreadBps(quote);
+    if (spread !== null) {
+      const i = SPREAD_BUCKETS_BPS.findIndex((bound) => spread <= bound);
+      state.spreadCounts[i < 0 ? SPREAD_BUCKETS_BPS.length - 1 : i]++;
+    }
+  }
+
+  /** Count a duplicate message, which add() is not called for */
+  duplicate(): void {
+    this.duplicates++;
+  }
+
+  /** Report for the window [startMs, endMs) */
+  report(source: QualityReport["source"], startMs: number, endMs: number, now: number = Date.now()): QualityReport {
+    const minutes = Math.max(endMs - startMs, 1) / 60_000;
+    const tickers: Record<string, TickerQuality> = {};
+    for (const ticker of [...this.tickers.keys()].sort()) {
+      const s = this.tickers.get(ticker)!;
+      const p50 = histogramPercentile(s.spreadCounts, 0.5);
+      tickers[ticker] = {
+        messages: s.messages,
+        rate_per_min: s.messages / minutes,
+        quotes: s.quotes,
+        max_quote_gap_sec: s.maxGapMs === null ? null : s.maxGapMs / 1000,
+        last_quote_age_sec: s.lastQuoteMs === null ? null : Math.max(0, endMs - s.lastQuoteMs) / 1000,
+        spread_bps: p50 === null ? null : { p50, p90: histogramPercentile(s.spreadCounts, 0.9)! },
+      };
+    }
+    return {
+      feed: this.feed,
+      source,
+      window_start: new Date(startMs).toISOString(),
+      window_end: new Date(endMs).toISOString(),
+      generated_at: new Date(now).toISOString(),
+      duplicates: this.duplicates,
+      tickers,
+    };
+  }
+}
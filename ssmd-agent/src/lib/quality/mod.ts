@@ -0,0 +1,34 @@
+export {
+  extractQuote,
+  histogramPercentile,
+  QualityAccumulator,
+  SPREAD_BUCKETS_BPS,
+  spreadBps,
+  type QualityReport,
+  type Quote,
+  type TickerQuality,
+} from "./metrics.ts";
+export {
+  buildBaseline,
+  DEFAULT_ANOMALY_OPTIONS,
+  detectAnomalies,
+  qualityAlert,
+  qualityAlertSubject,
+  zScore,
+  type AnomalyOptions,
+  type MetricBaseline,
+  type QualityAlert,
+  type QualityAnomaly,
+  type QualityBaseline,
+  type QualityMetric,
+  type TickerBaseline,
+} from "./baseline.ts";
+export {
+  archiveQualityReport,
+  loadQualityReport,
+  observeMessage,
+  previousDates,
+  QUALITY_REPORT_FILE,
+  qualityReportPath,
+  saveQualityReport,
+} from "./report.ts";
@@ -0,0 +1,117 @@
+/**
+ * Daily quality reports: built from a day's archive and stored as
+ * quality.json next to its manifest.json, where later runs read the previous
+ * days' reports as their baseline. Used by `ssmd data quality`.
+ */
+import { Storage } from "@google-cloud/storage";
+import {
+  archiveDir,
+  archiveFiles,
+  archiveStream,
+  type DedupKeys,
+  type DedupWindow,
+  detectMessageType,
+  extractTicker,
+  isDuplicateRecord,
+  newDedupWindow,
+  nextDedupFile,
+  toLines,
+} from "../archive/mod.ts";
+import { extractQuote, QualityAccumulator, type QualityReport } from "./metrics.ts";
+
+/** Report object name, next to manifest.json in the date directory */
+export const QUALITY_REPORT_FILE = "quality.json";
+
+export function qualityReportPath(feed: string, date: string): string {
+  return `${archiveDir(feed, date)}/${QUALITY_REPORT_FILE}`;
+}
+
+/** The n dates before date (YYYY-MM-DD), most recent first */
+export function previousDates(date: string, n: number): string[] {
+  const day = Date.parse(`${date}T00:00:00Z`);
+  return Array.from({ length: n }, (_, i) => new Date(day - (i + 1) * 86_400_000).toISOString().slice(0, 10));
+}
+
+/** Read a date's saved quality report, or null if it has none */
+export async function loadQualityReport(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+): Promise<QualityReport | null> {
+  try {
+    const [contents] = await storage.bucket(bucket).file(qualityReportPath(feed, date)).download();
+    return JSON.parse(contents.toString("utf-8"));
+  } catch (err: unknown) {
+    if ((err as { code?: number }).code === 404) return null;
+    throw err;
+  }
+}
+
+export async function saveQualityReport(
+  storage: Storage,
+  bucket: string,
+  date: string,
+  report: QualityReport,
+): Promise<void> {
+  await storage.bucket(bucket).file(qualityReportPath(report.feed, date)).save(JSON.stringify(report), {
+    contentType: "application/json",
+    resumable: false,
+  });
+}
+
+/**
+ * Add one archived or live message to the accumulator. Control frames and
+ * messages without a ticker are skipped; fallbackTicker is the subject's
+ * ticker token for live messages. With a dedup window, duplicates are only
+ * counted as such.
+ */
+export function observeMessage(
+  acc: QualityAccumulator,
+  json: Record<string, unknown>,
+  timeMs: number,
+  fallbackTicker?: string,
+  window?: DedupWindow,
+): void {
+  if (!detectMessageType(acc.feed, json)) return;
+  const ticker = extractTicker(acc.feed, json) ?? fallbackTicker;
+  if (!ticker) return;
+  if (window && isDuplicateRecord(window, json)) {
+    acc.duplicate();
+    return;
+  }
+  acc.add(ticker, timeMs, extractQuote(acc.feed, json));
+}
+
+/**
+ * Quality of a day's archive, timed by _received_at, with duplicates keyed
+ * by the types' dedup_key fields. Returns null when the date has no archive
+ * files.
+ */
+export async function archiveQualityReport(
+  storage: Storage,
+  bucket: string,
+  feed: string,
+  date: string,
+  keys: DedupKeys = {},
+): Promise<QualityReport | null> {
+  const paths = await archiveFiles(storage, bucket, feed, date);
+  if (paths.length === 0) return null;
+  const acc = new QualityAccumulator(feed);
+  const window = newDedupWindow(feed, keys);
+  for (const path of paths) {
+    nextDedupFile(window);
+    for await (const line of toLines(archiveStream(storage, bucket, path))) {
+      let json: Record<string, unknown>;
+      try {
+        json = JSON.parse(line);
+      } catch {
+        continue;
+      }
+      if (typeof json._received_at !== "number") continue;
+      observeMessage(acc, json, json._received_at / 1000, undefined, window);
+    }
+  }
+  const start = Date.parse(`${date}T00:00:00Z`);
+  return acc.report("archive", start, Math.min(start + 86_400_000, Date.now()));
+}
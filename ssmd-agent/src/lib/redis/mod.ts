@@ -1 +1,2 @@
 export { getRedis, closeRedis } from "./client.ts";
+export { getSnapRedis, parseSnapshot, snapKey, snapKeyPrefix } from "./snap.ts";
@@ -0,0 +1,77 @@
+/**
+ * Read access to ssmd-snap's Redis cache (latest message per ticker, keyed
+ * `<prefix>:<feed>:<ticker>`, with `_snap_at` epoch millis injected by snap).
+ *
+ * SNAP_REDIS_URL points at a separate snap Redis; unset, the shared REDIS_URL
+ * connection is used. SNAP_KEY_PREFIX overrides the default "snap" prefix.
+ */
+import { connect, type Redis } from "https://deno.land/x/redis@v0.32.4/mod.ts";
+import { getRedis } from "./client.ts";
+
+let snapClient: Redis | null = null;
+
+/** Kalshi top-level price fields stored in cents */
+const KALSHI_CENT_FIELDS = ["yes_bid", "yes_ask", "no_bid", "no_ask", "last_price"];
+
+export function snapKeyPrefix(): string {
+  return Deno.env.get("SNAP_KEY_PREFIX") || "snap";
+}
+
+export function snapKey(feed: string, ticker: string, prefix: string = snapKeyPrefix()): string {
+  return `${prefix}:${feed}:${ticker}`;
+}
+
+/**
+ * Redis holding snap keys. Unlike getRedis() there is no empty-database
+ * guard: a snap Redis is legitimately empty once every key's TTL lapses.
+ */
+export async function getSnapRedis(): Promise<Redis> {
+  const snapUrl = Deno.env.get("SNAP_REDIS_URL");
+  if (!snapUrl) return getRedis();
+
+  if (snapClient) {
+    try {
+      await snapClient.ping();
+      return snapClient;
+    } catch {
+      console.warn("[redis] Snap connection stale, reconnecting...");
+      try { snapClient.close(); } catch { /* ignore */ }
+      snapClient = null;
+    }
+  }
+
+  const url = new URL(snapUrl);
+  snapClient = await connect({
+    hostname: url.hostname,
+    port: parseInt(url.port || "6379"),
+    ...(url.password ? { password: decodeURIComponent(url.password) } : {}),
+  });
+  console.log("[redis] Connected to snap Redis", url.hostname);
+  return snapClient;
+}
+
+/**
+ * Parse a cached snap value. Kalshi top-level cent prices are converted to
+ * dollars and `_ticker` is set from the key. Returns null if unparseable.
+ */
+// deno-lint-ignore no-explicit-any
+export function parseSnapshot(feed: string, ticker: string, raw: string): Record<string, any> | null {
+  // deno-lint-ignore no-explicit-any
+  let parsed: Record<string, any>;
+  try {
+    parsed = JSON.parse(raw);
+  } catch {
+    return null;
+  }
+  if (parsed === null || typeof parsed !== "object" || Array.isArray(parsed)) return null;
+  if (feed === "kalshi") {
+    for (const field of KALSHI_CENT_FIELDS) {
+      if (typeof parsed[field] === "number") {
+        parsed[field] = parsed[field] / 100;
+      }
+    }
+  }
+  // Use the ticker from the key, not the payload, for consistency
+  parsed._ticker = ticker;
+  return parsed;
+}
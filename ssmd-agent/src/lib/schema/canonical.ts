@@ -0,0 +1,102 @@
+// Canonical forms of schema source files, so a comment, whitespace or import
+// order edit does not change a source's hash. The raw sha256 of the bytes is
+// still accepted for sources recorded before canonical hashing.
+import { encodeHex } from "https://deno.land/std@0.224.0/encoding/hex.ts";
+import { parse as parseYaml } from "yaml";
+import { type SchemaFormat, stripCapnpComments, stripProtoComments } from "./compat.ts";
+
+const STRING = /"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'/g;
+
+/** Collapse whitespace outside string literals and drop it around punctuation */
+function normalizeStatement(stmt: string): string {
+  let out = "";
+  let last = 0;
+  const squeeze = (s: string) => s.replace(/\s+/g, " ").replace(/ ?([=,:;()<>[\]@$]) ?/g, "$1");
+  for (const m of stmt.matchAll(STRING)) {
+    out += squeeze(stmt.slice(last, m.index)) + m[0];
+    last = m.index! + m[0].length;
+  }
+  return (out + squeeze(stmt.slice(last))).trim();
+}
+
+/**
+ * One normalized statement per line, with the file's import statements
+ * sorted and kept at the position of the first one.
+ */
+function canonicalStatements(src: string, isImport: (stmt: string) => boolean): string {
+  const statements: string[] = [];
+  for (const m of src.matchAll(/([^;{}]*)([;{}])/g)) {
+    const stmt = normalizeStatement(m[1]);
+    statements.push(m[2] === "}" ? (stmt ? `${stmt}\n}` : "}") : `${stmt}${m[2]}`);
+  }
+  const tail = normalizeStatement(src.replace(/[\s\S]*[;{}]/, ""));
+  if (tail) statements.push(tail);
+
+  const imports = statements.filter(isImport).sort();
+  const first = statements.findIndex(isImport);
+  const rest = statements.filter((s) => !isImport(s));
+  if (first >= 0) rest.splice(first, 0, ...imports);
+  return rest.join("\n") + "\n";
+}
+
+/** Stable JSON (sorted object keys) */
+function canonicalJson(v: unknown): string {
+  if (Array.isArray(v)) return `[${v.map(canonicalJson).join(",")}]`;
+  if (typeof v === "object" && v !== null) {
+    const o = v as Record<string, unknown>;
+    return `{${Object.keys(o).sort().map((k) => `${JSON.stringify(k)}:${canonicalJson(o[k])}`).join(",")}}`;
+  }
+  return JSON.stringify(v);
+}
+
+/**
+ * Canonical text of a source file: protobuf and Cap'n Proto lose comments,
+ * formatting and import order; JSON Schema and field maps become sorted-key
+ * JSON.
+ */
+export function canonicalizeSource(format: SchemaFormat, text: string): string {
+  switch (format) {
+    case "protobuf":
+      return canonicalStatements(stripProtoComments(text), (s) => /^import\b/.test(s) && s.endsWith(";"));
+    case "capnp":
+      return canonicalStatements(stripCapnpComments(text), (s) => /^using\b.*\bimport\b/.test(s) && s.endsWith(";"));
+    case "json-schema":
+    case "fields":
+      // YAML is a superset of JSON, so one parser covers both
+      return canonicalJson(parseYaml(text)) + "\n";
+  }
+}
+
+async function sha256(content: Uint8Array): Promise<string> {
+  return encodeHex(await crypto.subtle.digest("SHA-256", content));
+}
+
+/** sha256 of the file bytes, as recorded before canonical hashing */
+export function rawSourceHash(content: Uint8Array): Promise<string> {
+  return sha256(content);
+}
+
+/** sha256 of the canonical form */
+export function canonicalSourceHash(format: SchemaFormat, content: Uint8Array): Promise<string> {
+  return sha256(new TextEncoder().encode(canonicalizeSource(format, new TextDecoder().decode(content))));
+}
+
+/** Recorded source hashes: canonical_sha256 for new records, sha256 (raw) for older ones */
+export interface SourceHashes {
+  sha256?: string;
+  canonical_sha256?: string;
+}
+
+/**
+ * Whether content matches the recorded hash, preferring the canonical one.
+ * A source with only a raw hash must match byte for byte.
+ */
+export async function sourceMatches(format: SchemaFormat, content: Uint8Array, recorded: SourceHashes): Promise<boolean> {
+  if (recorded.canonical_sha256) {
+    return await canonicalSourceHash(format, content) === recorded.canonical_sha256;
+  }
+  if (recorded.sha256) {
+    return await rawSourceHash(content) === recorded.sha256;
+  }
+  return false;
+}
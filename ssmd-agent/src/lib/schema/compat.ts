@@ -0,0 +1,435 @@
+// Schema compatibility - field maps (the exchanges/schemas format) built from
+// JSON Schema, protobuf or Cap'n Proto sources, and a classifier for the
+// changes between two versions of a message type.
+
+/** JSON value types a field may hold */
+export type FieldType = "string" | "integer" | "number" | "boolean" | "object" | "array" | "null";
+
+export interface FieldSpec {
+  type: FieldType | FieldType[];
+  required?: true;
+}
+
+/** Dotted field path -> spec; `[]` marks per-element fields of an array */
+export type FieldMap = Record<string, FieldSpec>;
+
+export type SchemaFormat = "fields" | "json-schema" | "protobuf" | "capnp";
+
+export type ChangeKind = "added" | "removed" | "type_changed" | "required_changed";
+
+export interface SchemaChange {
+  path: string;
+  kind: ChangeKind;
+  from?: FieldSpec;
+  to?: FieldSpec;
+  /** True when data written under the old version can fail under the new one */
+  breaking: boolean;
+}
+
+function isObject(v: unknown): v is Record<string, unknown> {
+  return typeof v === "object" && v !== null && !Array.isArray(v);
+}
+
+function typesOf(spec: FieldSpec): FieldType[] {
+  return Array.isArray(spec.type) ? spec.type : [spec.type];
+}
+
+// Every old type must still be accepted; integer values are valid numbers.
+function isWidening(from: FieldSpec, to: FieldSpec): boolean {
+  const accepted = new Set(typesOf(to));
+  return typesOf(from).every((t) => accepted.has(t) || (t === "integer" && accepted.has("number")));
+}
+
+function sameTypes(a: FieldSpec, b: FieldSpec): boolean {
+  return [...typesOf(a)].sort().join("|") === [...typesOf(b)].sort().join("|");
+}
+
+/**
+ * Changes going from one version's fields to the next, classified for
+ * backward compatibility: a reader on `to` must accept data written under
+ * `from`. Adding a required field, narrowing a type and making a field
+ * required are breaking; adding an optional field, removing a field, widening
+ * a type and relaxing required are not.
+ */
+export function compareFields(from: FieldMap, to: FieldMap): SchemaChange[] {
+  const changes: SchemaChange[] = [];
+  for (const path of [...new Set([...Object.keys(from), ...Object.keys(to)])].sort()) {
+    const a = from[path];
+    const b = to[path];
+    if (!a) {
+      changes.push({ path, kind: "added", to: b, breaking: b.required === true });
+    } else if (!b) {
+      changes.push({ path, kind: "removed", from: a, breaking: false });
+    } else {
+      if (!sameTypes(a, b)) {
+        changes.push({ path, kind: "type_changed", from: a, to: b, breaking: !isWidening(a, b) });
+      }
+      if (Boolean(a.required) !== Boolean(b.required)) {
+        changes.push({ path, kind: "required_changed", from: a, to: b, breaking: b.required === true });
+      }
+    }
+  }
+  return changes;
+}
+
+export function isBreaking(changes: SchemaChange[]): boolean {
+  return changes.some((c) => c.breaking);
+}
+
+// --- JSON Schema ----------------------------------------------------------
+
+const JSON_TYPES: readonly string[] = ["string", "integer", "number", "boolean", "object", "array", "null"];
+
+function jsonSchemaType(node: Record<string, unknown>, path: string): FieldType | FieldType[] {
+  const t = node.type ?? (isObject(node.properties) ? "object" : node.items ? "array" : undefined);
+  const list = Array.isArray(t) ? t : [t];
+  if (list.length === 0 || !list.every((x) => typeof x === "string" && JSON_TYPES.includes(x))) {
+    throw new Error(`${path || "(root)"}: unsupported JSON Schema type ${JSON.stringify(t)}`);
+  }
+  return list.length === 1 ? list[0] as FieldType : list as FieldType[];
+}
+
+/**
+ * Flatten a JSON Schema object into a field map. Only leaves get an entry:
+ * nested objects become dotted paths and arrays of objects `name[].field`.
+ * A field is required when it and every enclosing object are required (array
+ * elements count from the element). Local $refs (#/$defs/X, #/definitions/X)
+ * are followed; other combinators are not supported.
+ */
+export function fieldsFromJsonSchema(doc: unknown): FieldMap {
+  if (!isObject(doc) || !isObject(doc.properties)) {
+    throw new Error("JSON Schema must be an object schema with properties");
+  }
+  const root = doc;
+  const fields: FieldMap = {};
+
+  const deref = (node: unknown, path: string, seen: string[]): Record<string, unknown> => {
+    if (!isObject(node)) throw new Error(`${path}: schema must be an object`);
+    const ref = node.$ref;
+    if (typeof ref !== "string") return node;
+    const m = ref.match(/^#\/(\$defs|definitions)\/(.+)$/);
+    if (!m) throw new Error(`${path}: only local $refs are supported, got ${ref}`);
+    if (seen.includes(ref)) throw new Error(`${path}: recursive $ref ${ref}`);
+    const defs = root[m[1]];
+    if (!isObject(defs) || !(m[2] in defs)) throw new Error(`${path}: unresolved $ref ${ref}`);
+    return deref(defs[m[2]], path, [...seen, ref]);
+  };
+
+  const walk = (node: Record<string, unknown>, prefix: string, parentRequired: boolean, seen: string[]) => {
+    const required = new Set(Array.isArray(node.required) ? node.required : []);
+    for (const [name, raw] of Object.entries(node.properties as Record<string, unknown>)) {
+      const path = prefix ? `${prefix}.${name}` : name;
+      const ref = isObject(raw) && typeof raw.$ref === "string" ? [raw.$ref] : [];
+      const child = deref(raw, path, seen);
+      const isRequired = parentRequired && required.has(name);
+
+      if (isObject(child.properties)) {
+        walk(child, path, isRequired, [...seen, ...ref]);
+        continue;
+      }
+      if (child.items !== undefined) {
+        const items = deref(child.items, `${path}[]`, [...seen, ...ref]);
+        if (isObject(items.properties)) {
+          walk(items, `${path}[]`, true, [...seen, ...ref]);
+          continue;
+        }
+      }
+      const spec: FieldSpec = { type: jsonSchemaType(child, path) };
+      if (isRequired) spec.required = true;
+      fields[path] = spec;
+    }
+  };
+
+  walk(root, "", true, []);
+  return fields;
+}
+
+// --- protobuf -------------------------------------------------------------
+
+const PROTO_SCALARS: Record<string, FieldType> = {
+  double: "number",
+  float: "number",
+  int32: "integer",
+  int64: "integer",
+  uint32: "integer",
+  uint64: "integer",
+  sint32: "integer",
+  sint64: "integer",
+  fixed32: "integer",
+  fixed64: "integer",
+  sfixed32: "integer",
+  sfixed64: "integer",
+  bool: "boolean",
+  string: "string",
+  bytes: "string",
+};
+
+interface ProtoField {
+  label: "optional" | "required" | "repeated" | "";
+  type: string;
+  name: string;
+}
+
+interface ProtoMessage {
+  fields: ProtoField[];
+}
+
+/** Drop // and block comments, leaving string literals intact */
+export function stripProtoComments(text: string): string {
+  return text.replace(/"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|\/\/[^\n]*|\/\*[\s\S]*?\*\//g, (m) =>
+    m.startsWith("/") ? (m.startsWith("/*") ? m.replace(/[^\n]/g, "") : "") : m
+  );
+}
+
+// Resolve a type name the way protoc and capnp do: innermost enclosing scope first
+function resolve(type: string, scope: string, names: Iterable<string>): string | undefined {
+  const known = new Set(names);
+  const parts = scope.split(".");
+  for (let i = parts.length; i >= 0; i--) {
+    const candidate = [...parts.slice(0, i), type].join(".");
+    if (known.has(candidate)) return candidate;
+  }
+  return undefined;
+}
+
+/** Messages and enums by (dotted, for nested) name */
+function parseProto(text: string): { messages: Map<string, ProtoMessage>; enums: Set<string> } {
+  const src = stripProtoComments(text);
+  const messages = new Map<string, ProtoMessage>();
+  const enums = new Set<string>();
+  const stack: { kind: "message" | "enum" | "other"; name: string }[] = [];
+  const scope = () => stack.filter((s) => s.kind === "message").map((s) => s.name);
+
+  // Statements end at ; { or }, which is enough for message/enum/field syntax
+  const re = /([^;{}]*)([;{}])/g;
+  let m: RegExpExecArray | null;
+  while ((m = re.exec(src))) {
+    const stmt = m[1].trim().replace(/\s+/g, " ");
+    const delim = m[2];
+    if (delim === "{") {
+      const decl = stmt.match(/^(message|enum)\s+(\w+)$/);
+      if (decl) {
+        const name = [...scope(), decl[2]].join(".");
+        if (decl[1] === "message") messages.set(name, { fields: [] });
+        else enums.add(name);
+        stack.push({ kind: decl[1] as "message" | "enum", name: decl[2] });
+      } else {
+        // oneof, service, option blocks and the like
+        stack.push({ kind: "other", name: stmt });
+      }
+      continue;
+    }
+    if (delim === "}") {
+      stack.pop();
+      continue;
+    }
+
+    const owner = scope();
+    const top = stack[stack.length - 1];
+    if (owner.length === 0 || !top || top.kind === "enum") continue;
+    const inOneof = top.kind === "other" && top.name.startsWith("oneof ");
+    if (top.kind === "other" && !inOneof) continue;
+
+    const map = stmt.match(/^map\s*<\s*[\w.]+\s*,\s*[\w.]+\s*>\s+(\w+)\s*=\s*\d+/);
+    if (map) {
+      messages.get(owner.join("."))!.fields.push({ label: "", type: "map", name: map[1] });
+      continue;
+    }
+    const field = stmt.match(/^(optional |required |repeated )?([\w.]+)\s+(\w+)\s*=\s*\d+/);
+    if (field && !["option", "reserved", "extensions"].includes(field[2])) {
+      const label = inOneof ? "optional" : (field[1]?.trim() ?? "") as ProtoField["label"];
+      messages.get(owner.join("."))!.fields.push({ label, type: field[2].replace(/^\./, ""), name: field[3] });
+    }
+  }
+  return { messages, enums };
+}
+
+/**
+ * Field map for a protobuf message: scalars map to their JSON types, enums to
+ * string, nested messages are flattened (repeated ones under `name[]`) and
+ * maps become objects. Only proto2 `required` fields, under required parents,
+ * are marked required.
+ * With no message name the file must declare exactly one top-level message.
+ */
+export function fieldsFromProto(text: string, message?: string): FieldMap {
+  const { messages, enums } = parseProto(text);
+  const topLevel = [...messages.keys()].filter((n) => !n.includes("."));
+  const name = message ?? (topLevel.length === 1 ? topLevel[0] : undefined);
+  if (!name) {
+    throw new Error(`proto declares ${topLevel.length} top-level messages (${topLevel.join(", ")}); pick one`);
+  }
+  if (!messages.has(name)) {
+    throw new Error(`message ${name} not found in proto`);
+  }
+
+  const fields: FieldMap = {};
+  const walk = (msgName: string, prefix: string, parentRequired: boolean, seen: string[]) => {
+    for (const f of messages.get(msgName)!.fields) {
+      const path = prefix ? `${prefix}.${f.name}` : f.name;
+      const isRequired = parentRequired && f.label === "required";
+      const nested = f.type === "map" ? undefined : resolve(f.type, msgName, messages.keys());
+      if (nested && messages.get(nested)!.fields.length > 0) {
+        if (seen.includes(nested)) throw new Error(`${path}: recursive message ${nested}`);
+        const repeated = f.label === "repeated";
+        walk(nested, repeated ? `${path}[]` : path, repeated || isRequired, [...seen, nested]);
+        continue;
+      }
+
+      let type: FieldType;
+      if (f.type === "map" || nested) type = "object";
+      else if (f.type in PROTO_SCALARS) type = PROTO_SCALARS[f.type];
+      else if (resolve(f.type, msgName, enums)) type = "string";
+      else throw new Error(`${path}: unknown type ${f.type}`);
+
+      const spec: FieldSpec = { type: f.label === "repeated" ? "array" : type };
+      if (isRequired) spec.required = true;
+      fields[path] = spec;
+    }
+  };
+  walk(name, "", true, [name]);
+  return fields;
+}
+
+// --- Cap'n Proto ----------------------------------------------------------
+
+const CAPNP_SCALARS: Record<string, FieldType> = {
+  Void: "null",
+  Bool: "boolean",
+  Int8: "integer",
+  Int16: "integer",
+  Int32: "integer",
+  Int64: "integer",
+  UInt8: "integer",
+  UInt16: "integer",
+  UInt32: "integer",
+  UInt64: "integer",
+  Float32: "number",
+  Float64: "number",
+  Text: "string",
+  Data: "string",
+  AnyPointer: "object",
+};
+
+/** Drop # comments, leaving string literals intact */
+export function stripCapnpComments(text: string): string {
+  return text.replace(/"(?:[^"\\]|\\.)*"|#[^\n]*/g, (m) => (m.startsWith("#") ? "" : m));
+}
+
+/** Structs (fields with group/union prefixes) and enums by dotted name */
+function parseCapnp(text: string): { structs: Map<string, { name: string; type: string }[]>; enums: Set<string> } {
+  const src = stripCapnpComments(text);
+  const structs = new Map<string, { name: string; type: string }[]>();
+  const enums = new Set<string>();
+  const stack: { kind: "struct" | "enum" | "group" | "union" | "other"; name: string }[] = [];
+  const scope = () => stack.filter((s) => s.kind === "struct").map((s) => s.name);
+  // Group and named-union names since the innermost struct
+  const prefix = () => {
+    const i = stack.map((s) => s.kind).lastIndexOf("struct");
+    return stack.slice(i + 1).filter((s) => s.name).map((s) => s.name);
+  };
+
+  const re = /([^;{}]*)([;{}])/g;
+  let m: RegExpExecArray | null;
+  while ((m = re.exec(src))) {
+    const stmt = m[1].trim().replace(/\s+/g, " ");
+    const delim = m[2];
+    if (delim === "{") {
+      const decl = stmt.match(/^(struct|enum) (\w+)/);
+      const group = stmt.match(/^(\w+) ?(?:@\d+ ?)?:(group|union)\b/);
+      const top = stack[stack.length - 1];
+      const inStruct = top && top.kind !== "enum" && top.kind !== "other";
+      if (decl) {
+        const name = [...scope(), decl[2]].join(".");
+        if (decl[1] === "struct") structs.set(name, []);
+        else enums.add(name);
+        stack.push({ kind: decl[1] as "struct" | "enum", name: decl[2] });
+      } else if (group && inStruct) {
+        stack.push({ kind: group[2] as "group" | "union", name: group[1] });
+      } else if (stmt === "union" && inStruct) {
+        stack.push({ kind: "union", name: "" });
+      } else {
+        stack.push({ kind: "other", name: stmt });
+      }
+      continue;
+    }
+    if (delim === "}") {
+      stack.pop();
+      continue;
+    }
+
+    const top = stack[stack.length - 1];
+    if (!top || top.kind === "enum" || top.kind === "other") continue;
+    const field = stmt.match(/^(\w+) ?@\d+ ?:([\w.]+(?:\([\w.]+\))?)/);
+    if (field) {
+      structs.get(scope().join("."))!.push({ name: [...prefix(), field[1]].join("."), type: field[2] });
+    }
+  }
+  return { structs, enums };
+}
+
+/**
+ * Field map for a Cap'n Proto struct: scalars map to their JSON types, enums
+ * to string, nested structs and groups are flattened (lists of structs under
+ * `name[]`). Cap'n Proto has no required fields, so none are marked required.
+ * With no struct name the file must declare exactly one top-level struct.
+ */
+export function fieldsFromCapnp(text: string, struct?: string): FieldMap {
+  const { structs, enums } = parseCapnp(text);
+  const topLevel = [...structs.keys()].filter((n) => !n.includes("."));
+  const name = struct ?? (topLevel.length === 1 ? topLevel[0] : undefined);
+  if (!name) {
+    throw new Error(`capnp declares ${topLevel.length} top-level structs (${topLevel.join(", ")}); pick one`);
+  }
+  if (!structs.has(name)) {
+    throw new Error(`struct ${name} not found in capnp`);
+  }
+
+  const fields: FieldMap = {};
+  const walk = (structName: string, prefix: string, seen: string[]) => {
+    for (const f of structs.get(structName)!) {
+      const path = prefix ? `${prefix}.${f.name}` : f.name;
+      const list = f.type.match(/^List\(([\w.]+)\)$/);
+      const elem = list ? list[1] : f.type;
+      const nested = resolve(elem, structName, structs.keys());
+      if (nested && structs.get(nested)!.length > 0) {
+        if (seen.includes(nested)) throw new Error(`${path}: recursive struct ${nested}`);
+        walk(nested, list ? `${path}[]` : path, [...seen, nested]);
+        continue;
+      }
+
+      let type: FieldType;
+      if (list) type = "array";
+      else if (nested) type = "object";
+      else if (f.type in CAPNP_SCALARS) type = CAPNP_SCALARS[f.type];
+      else if (resolve(f.type, structName, enums)) type = "string";
+      else throw new Error(`${path}: unknown type ${f.type}`);
+      fields[path] = { type };
+    }
+  };
+  walk(name, "", [name]);
+  return fields;
+}
+
+/** Infer a source format from a file name */
+export function formatFromPath(path: string): SchemaFormat {
+  if (path.endsWith(".capnp")) return "capnp";
+  if (path.endsWith(".proto")) return "protobuf";
+  if (path.endsWith(".json")) return "json-schema";
+  return "fields";
+}
+
+/** Validate a field map as written in a schemas/*.yaml file */
+export function parseFieldMap(doc: unknown): FieldMap {
+  if (!isObject(doc)) throw new Error("fields must be a map of field path to type");
+  const fields: FieldMap = {};
+  for (const [path, spec] of Object.entries(doc)) {
+    if (!isObject(spec)) throw new Error(`${path}: field spec must be a map with a type`);
+    const types = Array.isArray(spec.type) ? spec.type : [spec.type];
+    if (types.length === 0 || !types.every((t) => typeof t === "string" && JSON_TYPES.includes(t))) {
+      throw new Error(`${path}: unsupported type ${JSON.stringify(spec.type)}`);
+    }
+    fields[path] = { type: spec.type as FieldSpec["type"] };
+    if (spec.required === true) fields[path].required = true;
+  }
+  return fields;
+}
@@ -0,0 +1,3 @@
+// Schema exports
+export * from "./compat.ts";
+export * from "./canonical.ts";
@@ -45,12 +45,92 @@ export const EnvConfigSchema = z.object({
   environments: z.record(z.string(), EnvironmentSchema),
 });
 
+// An environment as written in config.yaml. With extends, it is merged over
+// the named base at load time, so it only needs the keys that differ.
+export const EnvironmentOverlaySchema = z.object({
+  extends: z.string().optional(),
+}).passthrough();
+
+// Config file before extends is resolved
+export const RawEnvConfigSchema = z.object({
+  "current-env": z.string(),
+  environments: z.record(z.string(), EnvironmentOverlaySchema),
+});
+
 // Type exports
 export type NatsConfig = z.infer<typeof NatsConfigSchema>;
 export type StorageConfig = z.infer<typeof StorageConfigSchema>;
 export type SecretsConfig = z.infer<typeof SecretsConfigSchema>;
 export type Environment = z.infer<typeof EnvironmentSchema>;
 export type EnvConfig = z.infer<typeof EnvConfigSchema>;
+export type EnvironmentOverlay = z.infer<typeof EnvironmentOverlaySchema>;
+export type RawEnvConfig = z.infer<typeof RawEnvConfigSchema>;
+
+function isPlainObject(v: unknown): v is Record<string, unknown> {
+  return typeof v === "object" && v !== null && !Array.isArray(v);
+}
+
+/**
+ * Merge overlay over base: nested objects merge key by key, anything else
+ * replaces, and null removes the inherited key. An object whose type differs
+ * from the base's (e.g. storage gcs over local) replaces it whole.
+ */
+export function mergeEnvironment(
+  base: Record<string, unknown>,
+  overlay: Record<string, unknown>
+): Record<string, unknown> {
+  const merged: Record<string, unknown> = { ...base };
+  for (const [key, value] of Object.entries(overlay)) {
+    const inherited = merged[key];
+    if (value === null) {
+      delete merged[key];
+    } else if (isPlainObject(value) && isPlainObject(inherited) && (value.type ?? inherited.type) === inherited.type) {
+      merged[key] = mergeEnvironment(inherited, value);
+    } else {
+      merged[key] = value;
+    }
+  }
+  return merged;
+}
+
+/**
+ * The environment name with its extends chain merged in, before validation.
+ * Throws on an unknown base or an inheritance cycle.
+ */
+export function flattenEnvironment(
+  name: string,
+  environments: Record<string, EnvironmentOverlay>,
+  chain: string[] = []
+): Record<string, unknown> {
+  if (chain.includes(name)) {
+    throw new Error(`Environment inheritance cycle: ${[...chain, name].join(" -> ")}`);
+  }
+  const env = environments[name];
+  if (!env) {
+    const from = chain[chain.length - 1];
+    throw new Error(from ? `Environment '${from}' extends unknown environment '${name}'` : `Environment '${name}' not found in config`);
+  }
+
+  const { extends: base, ...own } = env;
+  if (!base) return own;
+  return mergeEnvironment(flattenEnvironment(base, environments, [...chain, name]), own);
+}
+
+/**
+ * Resolve every environment's extends and validate the result
+ */
+export function resolveEnvConfig(raw: RawEnvConfig): EnvConfig {
+  const environments: Record<string, Environment> = {};
+  for (const name of Object.keys(raw.environments)) {
+    const parsed = EnvironmentSchema.safeParse(flattenEnvironment(name, raw.environments));
+    if (!parsed.success) {
+      const issues = parsed.error.issues.map((i) => `${i.path.join(".")}: ${i.message}`).join("; ");
+      throw new Error(`Environment '${name}': ${issues}`);
+    }
+    environments[name] = parsed.data;
+  }
+  return { "current-env": raw["current-env"], environments };
+}
 
 // Default config with prod and dev environments
 export function getDefaultConfig(): EnvConfig {
@@ -84,3 +164,102 @@ export function getDefaultConfig(): EnvConfig {
     },
   };
 }
+
+// Keys owned by the target of `ssmd env promote`: where it runs, its URLs,
+// buckets and secret sources are never copied from the source environment
+export const PROMOTE_PRESERVED_KEYS = [
+  "cluster",
+  "namespace",
+  "nats.url",
+  "nats.stream_prefix",
+  "storage.bucket",
+  "storage.path",
+  "secrets",
+];
+
+function getPath(obj: Record<string, unknown>, path: string[]): unknown {
+  let cur: unknown = obj;
+  for (const key of path) {
+    if (!isPlainObject(cur)) return undefined;
+    cur = cur[key];
+  }
+  return cur;
+}
+
+function setPath(obj: Record<string, unknown>, path: string[], value: unknown): void {
+  let cur = obj;
+  for (const key of path.slice(0, -1)) {
+    if (!isPlainObject(cur[key])) {
+      if (value === undefined) return;
+      cur[key] = {};
+    }
+    cur = cur[key] as Record<string, unknown>;
+  }
+  const last = path[path.length - 1];
+  if (value === undefined) {
+    delete cur[last];
+  } else {
+    cur[last] = value;
+  }
+}
+
+/**
+ * The source environment's config with the target's preserved keys kept.
+ * Throws if the result is not a valid environment (e.g. the source moves
+ * storage to a type whose bucket or path the target does not have).
+ */
+export function promoteEnvironment(
+  source: Environment,
+  target: Environment,
+  preserve: string[] = PROMOTE_PRESERVED_KEYS
+): Environment {
+  const promoted = structuredClone(source) as Record<string, unknown>;
+  for (const key of preserve) {
+    const path = key.split(".");
+    setPath(promoted, path, structuredClone(getPath(target, path)));
+  }
+
+  const parsed = EnvironmentSchema.safeParse(promoted);
+  if (!parsed.success) {
+    const issues = parsed.error.issues.map((i) => `${i.path.join(".")}: ${i.message}`).join("; ");
+    throw new Error(`Promoted environment is invalid: ${issues}`);
+  }
+  return parsed.data;
+}
+
+export interface EnvChange {
+  /** Dotted key path */
+  path: string;
+  from?: unknown;
+  to?: unknown;
+}
+
+function leaves(obj: Record<string, unknown>, prefix = "", out = new Map<string, unknown>()): Map<string, unknown> {
+  for (const [key, value] of Object.entries(obj)) {
+    const path = prefix ? `${prefix}.${key}` : key;
+    if (isPlainObject(value)) {
+      leaves(value, path, out);
+    } else if (value !== undefined) {
+      out.set(path, value);
+    }
+  }
+  return out;
+}
+
+/**
+ * Leaf-level differences from before to after, sorted by path. A missing
+ * from is an added key, a missing to a removed one.
+ */
+export function diffEnvironments(before: Environment, after: Environment): EnvChange[] {
+  const a = leaves(before);
+  const b = leaves(after);
+  const changes: EnvChange[] = [];
+  for (const path of [...new Set([...a.keys(), ...b.keys()])].sort()) {
+    if (JSON.stringify(a.get(path)) === JSON.stringify(b.get(path))) continue;
+    const change: EnvChange = { path };
+    if (a.has(path)) change.from = a.get(path);
+    if (b.has(path)) change.to = b.get(path);
+    changes.push(change);
+  }
+  return changes;
+}
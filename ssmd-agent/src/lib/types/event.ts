@@ -4,6 +4,8 @@ import { z } from "zod";
  * Kalshi Event schema - represents a prediction market event
  */
 export const EventSchema = z.object({
+  /** Exchange the ticker belongs to (default: kalshi) */
+  exchange: z.string().min(1).optional(),
   /** Unique event ticker (e.g., "KXBTC-24DEC31") */
   event_ticker: z.string().min(1),
   /** Event title */
@@ -27,6 +27,18 @@ export const SeriesFeeChangeSchema = z.object({
 });
 export type SeriesFeeChange = z.infer<typeof SeriesFeeChangeSchema>;
 
+/**
+ * Manual fee schedule update (PUT /v1/fees/:series, `ssmd fees set`)
+ */
+export const FeeUpdateSchema = z.object({
+  fee_type: FeeTypeSchema,
+  /** Series-specific multiplier (default 1.0) */
+  fee_multiplier: z.number().positive().max(99).default(1.0),
+  /** When the schedule takes effect (default now) */
+  effective_from: z.string().datetime({ offset: true }).optional(),
+});
+export type FeeUpdate = z.infer<typeof FeeUpdateSchema>;
+
 /**
  * Series fee schedule from database
  */
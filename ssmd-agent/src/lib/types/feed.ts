@@ -25,12 +25,90 @@ export const CaptureLocationSchema = z.object({
   clock: z.string().optional(),
 });
 
-// Calendar schema
+// Calendar schemas
+const CalendarDate = z.string().regex(/^\d{4}-\d{2}-\d{2}$/, "Date must be YYYY-MM-DD");
+const TimeOfDay = z.string().regex(/^([01]\d|2[0-3]):[0-5]\d$/, "Time must be HH:MM (24h)");
+
+// Indexed like Date.getUTCDay()
+export const WEEKDAYS = ["sun", "mon", "tue", "wed", "thu", "fri", "sat"] as const;
+export const WeekdayEnum = z.enum(WEEKDAYS);
+
+// Hours for one weekday, replacing the default open/close; closed skips the day
+export const WeekdaySessionSchema = z.object({
+  closed: z.boolean().optional(),
+  open_time: TimeOfDay.optional(),
+  close_time: TimeOfDay.optional(),
+});
+
+export const HolidaySchema = z.object({
+  date: CalendarDate,
+  name: z.string().optional(),
+});
+
+// Session that closes early on date
+export const HalfDaySchema = z.object({
+  date: CalendarDate,
+  close_time: TimeOfDay,
+  name: z.string().optional(),
+});
+
 export const CalendarSchema = z.object({
   timezone: z.string().optional(),
   holiday_calendar: z.string().optional(),
-  open_time: z.string().optional(),
-  close_time: z.string().optional(),
+  // Session hours in the calendar timezone; close before open runs past midnight.
+  // Without hours the feed collects around the clock.
+  open_time: TimeOfDay.optional(),
+  close_time: TimeOfDay.optional(),
+  holidays: z.array(HolidaySchema).optional(),
+  half_days: z.array(HalfDaySchema).optional(),
+  weekdays: z.object({
+    sun: WeekdaySessionSchema.optional(),
+    mon: WeekdaySessionSchema.optional(),
+    tue: WeekdaySessionSchema.optional(),
+    wed: WeekdaySessionSchema.optional(),
+    thu: WeekdaySessionSchema.optional(),
+    fri: WeekdaySessionSchema.optional(),
+    sat: WeekdaySessionSchema.optional(),
+  }).strict().optional(),
+}).superRefine((c, ctx) => {
+  if (c.timezone && !isValidTimezone(c.timezone)) {
+    ctx.addIssue({ code: z.ZodIssueCode.custom, path: ["timezone"], message: `Unknown timezone: ${c.timezone}` });
+  }
+
+  const checkHours = (h: { open_time?: string; close_time?: string }, path: (string | number)[]) => {
+    if ((h.open_time === undefined) !== (h.close_time === undefined)) {
+      ctx.addIssue({ code: z.ZodIssueCode.custom, path, message: "open_time and close_time must be set together" });
+    } else if (h.open_time !== undefined && h.open_time === h.close_time) {
+      ctx.addIssue({ code: z.ZodIssueCode.custom, path, message: "open_time and close_time must differ" });
+    }
+  };
+  checkHours(c, []);
+  for (const [day, session] of Object.entries(c.weekdays ?? {})) {
+    if (!session) continue;
+    if (session.closed && (session.open_time || session.close_time)) {
+      ctx.addIssue({ code: z.ZodIssueCode.custom, path: ["weekdays", day], message: "A closed weekday has no hours" });
+    }
+    checkHours(session, ["weekdays", day]);
+  }
+
+  const holidays = new Set<string>();
+  (c.holidays ?? []).forEach((h, i) => {
+    if (holidays.has(h.date)) {
+      ctx.addIssue({ code: z.ZodIssueCode.custom, path: ["holidays", i], message: `Duplicate holiday ${h.date}` });
+    }
+    holidays.add(h.date);
+  });
+  const halfDays = new Set<string>();
+  (c.half_days ?? []).forEach((h, i) => {
+    if (holidays.has(h.date) || halfDays.has(h.date)) {
+      ctx.addIssue({
+        code: z.ZodIssueCode.custom,
+        path: ["half_days", i],
+        message: `${h.date} is already a holiday or half day`,
+      });
+    }
+    halfDays.add(h.date);
+  });
 });
 
 // Feed version schema
@@ -69,6 +147,10 @@ export type MessageProtocol = z.infer<typeof MessageProtocolEnum>;
 export type SiteType = z.infer<typeof SiteTypeEnum>;
 export type Protocol = z.infer<typeof ProtocolSchema>;
 export type CaptureLocation = z.infer<typeof CaptureLocationSchema>;
+export type Weekday = z.infer<typeof WeekdayEnum>;
+export type WeekdaySession = z.infer<typeof WeekdaySessionSchema>;
+export type Holiday = z.infer<typeof HolidaySchema>;
+export type HalfDay = z.infer<typeof HalfDaySchema>;
 export type Calendar = z.infer<typeof CalendarSchema>;
 export type FeedVersion = z.infer<typeof FeedVersionSchema>;
 export type Feed = z.infer<typeof FeedSchema>;
@@ -107,3 +189,109 @@ export function getLatestVersion(feed: Feed): FeedVersion | null {
 
   return sorted[0];
 }
+
+/**
+ * Whether timezone is an IANA name the runtime knows
+ */
+export function isValidTimezone(timezone: string): boolean {
+  try {
+    new Intl.DateTimeFormat("en-US", { timeZone: timezone });
+    return true;
+  } catch {
+    return false;
+  }
+}
+
+/**
+ * A collection window: the session on a calendar date, as instants
+ */
+export interface Session {
+  /** Calendar date (YYYY-MM-DD) in the feed timezone */
+  date: string;
+  open: Date;
+  close: Date;
+  halfDay: boolean;
+}
+
+function zonedWallClock(t: Date, timeZone: string): number {
+  const parts: Record<string, number> = {};
+  const formatter = new Intl.DateTimeFormat("en-US", {
+    timeZone,
+    hourCycle: "h23",
+    year: "numeric",
+    month: "2-digit",
+    day: "2-digit",
+    hour: "2-digit",
+    minute: "2-digit",
+    second: "2-digit",
+  });
+  for (const p of formatter.formatToParts(t)) {
+    if (p.type !== "literal") parts[p.type] = Number(p.value);
+  }
+  return Date.UTC(parts.year, parts.month - 1, parts.day, parts.hour, parts.minute, parts.second);
+}
+
+/**
+ * The calendar date (YYYY-MM-DD) of instant t in timeZone
+ */
+export function zonedDate(t: Date, timeZone: string): string {
+  return new Date(zonedWallClock(t, timeZone)).toISOString().split("T")[0];
+}
+
+/**
+ * The instant of wall-clock time (HH:MM) on date (YYYY-MM-DD) in timeZone
+ */
+export function zonedTime(date: string, time: string, timeZone: string): Date {
+  const [y, m, d] = date.split("-").map(Number);
+  const [hh, mm] = time.split(":").map(Number);
+  const wall = Date.UTC(y, m - 1, d, hh, mm);
+  // The offset at the guess can differ from the offset at the answer across
+  // a DST change, so correct once more from the guess
+  const offset = (at: number) => zonedWallClock(new Date(at), timeZone) - Math.floor(at / 1000) * 1000;
+  const guess = wall - offset(wall);
+  return new Date(wall - offset(guess));
+}
+
+function addDays(date: string, days: number): string {
+  const d = new Date(`${date}T00:00:00Z`);
+  d.setUTCDate(d.getUTCDate() + days);
+  return d.toISOString().split("T")[0];
+}
+
+/**
+ * The session on a calendar date, or null on holidays and closed weekdays.
+ * Weekday overrides replace the default hours; a half day moves the close.
+ */
+export function sessionForDate(calendar: Calendar | undefined, date: string): Session | null {
+  const cal = calendar ?? {};
+  if (cal.holidays?.some((h) => h.date === date)) return null;
+
+  const weekday = cal.weekdays?.[WEEKDAYS[new Date(`${date}T00:00:00Z`).getUTCDay()]];
+  if (weekday?.closed) return null;
+
+  const openTime = weekday?.open_time ?? cal.open_time ?? "00:00";
+  let closeTime = weekday?.close_time ?? cal.close_time ?? "00:00";
+  const halfDay = cal.half_days?.find((h) => h.date === date);
+  if (halfDay) closeTime = halfDay.close_time;
+
+  const timezone = cal.timezone ?? "UTC";
+  const open = zonedTime(date, openTime, timezone);
+  // A close at or before the open is on the next day
+  const closeDate = closeTime <= openTime ? addDays(date, 1) : date;
+  return { date, open, close: zonedTime(closeDate, closeTime, timezone), halfDay: halfDay !== undefined };
+}
+
+/**
+ * The next collection window of a feed: the session in progress at from, or
+ * the first one after it. Returns null if every day in the search horizon is
+ * closed.
+ */
+export function nextSession(calendar: Calendar | undefined, from: Date, horizonDays = 366): Session | null {
+  const start = zonedDate(from, calendar?.timezone ?? "UTC");
+  // Start a day early for an overnight session still open at from
+  for (let i = -1; i <= horizonDays; i++) {
+    const session = sessionForDate(calendar, addDays(start, i));
+    if (session && session.close > from) return session;
+  }
+  return null;
+}
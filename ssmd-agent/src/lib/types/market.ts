@@ -1,10 +1,13 @@
 import { z } from "zod";
+import { type Price, priceFromDecimal, type PriceJson, priceToJson } from "./price.ts";
 
 /**
  * Kalshi Market schema - represents an individual prediction market
  * Prices are in dollars (0.00-1.00 range).
  */
 export const MarketSchema = z.object({
+  /** Exchange the ticker belongs to (default: kalshi) */
+  exchange: z.string().min(1).optional(),
   /** Unique market ticker */
   ticker: z.string().min(1),
   /** Parent event ticker */
@@ -67,6 +70,30 @@ export const MarketInsertSchema = MarketSchema.extend({
 export type Market = z.infer<typeof MarketSchema>;
 export type MarketInsert = z.infer<typeof MarketInsertSchema>;
 
+/** Market price fields, all quoted in USD */
+export const MARKET_PRICE_FIELDS = ["yes_bid", "yes_ask", "no_bid", "no_ask", "last_price"] as const;
+export type MarketPriceField = typeof MARKET_PRICE_FIELDS[number];
+
+export type MarketPrices = Record<MarketPriceField, Price | null>;
+export type MarketPricesJson = Record<MarketPriceField, PriceJson | null>;
+
+/**
+ * Typed prices of a market. Values are dollars (or NUMERIC strings of
+ * dollars), never cents; the Price carries integer 1e-4 dollar units (major_e4).
+ */
+export function marketPrices(m: Partial<Record<MarketPriceField, string | number | null>>): MarketPrices {
+  return Object.fromEntries(
+    MARKET_PRICE_FIELDS.map((field) => [field, priceFromDecimal(m[field])]),
+  ) as MarketPrices;
+}
+
+/** Wire form of market prices: major_e4 units, currency and decimal string per field */
+export function marketPricesToJson(prices: MarketPrices): MarketPricesJson {
+  return Object.fromEntries(
+    MARKET_PRICE_FIELDS.map((field) => [field, priceToJson(prices[field])]),
+  ) as MarketPricesJson;
+}
+
 /**
  * Kalshi API market response shape.
  *
@@ -3,3 +3,7 @@ export * from "./feed.ts";
 export * from "./event.ts";
 export * from "./market.ts";
 export * from "./fee.ts";
+export * from "./price.ts";
+export * from "./orderbook.ts";
+export * from "./raw-schema.ts";
+export * from "./validation.ts";
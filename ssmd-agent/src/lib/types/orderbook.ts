@@ -0,0 +1,150 @@
+/**
+ * Order book state built from NATS orderbook messages (Kalshi
+ * orderbook_snapshot/orderbook_delta, Polymarket book, Kraken Futures
+ * book_snapshot/book), reduced to top-N levels for book_snapshots.
+ *
+ * Prices are in the exchange's major unit (dollars for Kalshi and
+ * Polymarket). Kalshi books are stored from the YES side: YES levels are
+ * bids and NO levels become asks at 1 - price.
+ */
+
+/** Feeds whose orderbook messages applyBookMessage understands */
+export const BOOK_FEEDS = ["kalshi", "polymarket", "kraken-futures"];
+
+/** A price level: [price, size] */
+export type BookLevel = [number, number];
+
+/** Top-of-book levels, bids descending and asks ascending */
+export interface BookLevels {
+  bids: BookLevel[];
+  asks: BookLevel[];
+}
+
+/** Full book for one instrument: price -> size per side */
+export interface BookState {
+  feed: string;
+  ticker: string;
+  bids: Map<number, number>;
+  asks: Map<number, number>;
+  /** Exchange time of the last applied message (epoch millis), if known */
+  exchangeTs: number | null;
+  updatedAt: number;
+}
+
+/** Books keyed by `${feed}:${ticker}` */
+export type BookStore = Map<string, BookState>;
+
+function bookKey(feed: string, ticker: string): string {
+  return `${feed}:${ticker}`;
+}
+
+/** Round away float noise (0.54 - 0.01 etc.) from derived prices */
+function roundPrice(price: number): number {
+  return Math.round(price * 1e8) / 1e8;
+}
+
+function setLevel(side: Map<number, number>, price: number, size: number): void {
+  const p = roundPrice(price);
+  if (!Number.isFinite(p) || !Number.isFinite(size)) return;
+  if (size <= 0) side.delete(p);
+  else side.set(p, size);
+}
+
+function freshBook(store: BookStore, feed: string, ticker: string): BookState {
+  const book: BookState = { feed, ticker, bids: new Map(), asks: new Map(), exchangeTs: null, updatedAt: Date.now() };
+  store.set(bookKey(feed, ticker), book);
+  return book;
+}
+
+function existingBook(store: BookStore, feed: string, ticker: string): BookState | null {
+  return store.get(bookKey(feed, ticker)) ?? null;
+}
+
+function epochMillis(value: unknown): number | null {
+  if (typeof value === "number" && Number.isFinite(value)) return value;
+  if (typeof value === "string" && value !== "") {
+    const n = Number(value);
+    if (Number.isFinite(n)) return n;
+    const parsed = Date.parse(value);
+    return Number.isNaN(parsed) ? null : parsed;
+  }
+  return null;
+}
+
+// deno-lint-ignore no-explicit-any
+type Json = Record<string, any>;
+
+function applyKalshi(store: BookStore, feed: string, raw: Json): BookState | null {
+  const msg = raw.msg as Json | undefined;
+  if (!msg || typeof msg.market_ticker !== "string") return null;
+  if (raw.type === "orderbook_snapshot") {
+    const book = freshBook(store, feed, msg.market_ticker);
+    for (const [price, qty] of (msg.yes ?? []) as [number, number][]) setLevel(book.bids, price / 100, qty);
+    for (const [price, qty] of (msg.no ?? []) as [number, number][]) setLevel(book.asks, (100 - price) / 100, qty);
+    return book;
+  }
+  if (raw.type === "orderbook_delta") {
+    // Deltas before the snapshot have nothing to apply to
+    const book = existingBook(store, feed, msg.market_ticker);
+    if (!book || typeof msg.price !== "number" || typeof msg.delta !== "number") return null;
+    const side = msg.side === "no" ? book.asks : book.bids;
+    const price = msg.side === "no" ? (100 - msg.price) / 100 : msg.price / 100;
+    setLevel(side, price, (side.get(roundPrice(price)) ?? 0) + msg.delta);
+    book.exchangeTs = epochMillis(msg.ts);
+    return book;
+  }
+  return null;
+}
+
+function applyPolymarket(store: BookStore, feed: string, raw: Json): BookState | null {
+  // Some initial snapshots arrive without event_type; bids/asks identify them
+  const isBook = raw.event_type === "book" || (raw.event_type === undefined && (raw.bids || raw.buys));
+  if (!isBook || typeof raw.asset_id !== "string") return null;
+  const book = freshBook(store, feed, raw.asset_id);
+  for (const level of (raw.bids ?? raw.buys ?? []) as Json[]) setLevel(book.bids, Number(level.price), Number(level.size));
+  for (const level of (raw.asks ?? raw.sells ?? []) as Json[]) setLevel(book.asks, Number(level.price), Number(level.size));
+  book.exchangeTs = epochMillis(raw.timestamp);
+  return book;
+}
+
+function applyKrakenFutures(store: BookStore, feed: string, raw: Json): BookState | null {
+  if (typeof raw.product_id !== "string") return null;
+  if (raw.feed === "book_snapshot") {
+    const book = freshBook(store, feed, raw.product_id);
+    for (const level of (raw.bids ?? []) as Json[]) setLevel(book.bids, level.price, level.qty);
+    for (const level of (raw.asks ?? []) as Json[]) setLevel(book.asks, level.price, level.qty);
+    book.exchangeTs = epochMillis(raw.timestamp);
+    return book;
+  }
+  if (raw.feed === "book") {
+    const book = existingBook(store, feed, raw.product_id);
+    if (!book) return null;
+    // qty is the new level size; 0 removes the level
+    setLevel(raw.side === "sell" ? book.asks : book.bids, raw.price, raw.qty);
+    book.exchangeTs = epochMillis(raw.timestamp);
+    return book;
+  }
+  return null;
+}
+
+/**
+ * Apply one orderbook message to the store. Returns the updated book, or
+ * null when the message is not a book message for the feed (or is a delta
+ * for a book whose snapshot has not been seen).
+ */
+export function applyBookMessage(store: BookStore, feed: string, raw: Json): BookState | null {
+  let book: BookState | null;
+  if (feed === "kalshi") book = applyKalshi(store, feed, raw);
+  else if (feed === "polymarket") book = applyPolymarket(store, feed, raw);
+  else if (feed === "kraken-futures") book = applyKrakenFutures(store, feed, raw);
+  else book = null;
+  if (book) book.updatedAt = Date.now();
+  return book;
+}
+
+/** Top `depth` levels of a book: best bids first, best asks first */
+export function topLevels(book: Pick<BookState, "bids" | "asks">, depth: number): BookLevels {
+  const bids = [...book.bids.entries()].sort(([a], [b]) => b - a).slice(0, depth);
+  const asks = [...book.asks.entries()].sort(([a], [b]) => a - b).slice(0, depth);
+  return { bids, asks };
+}
@@ -0,0 +1,71 @@
+import { z } from "zod";
+
+/**
+ * Currencies prices are quoted in, with their minor units per major unit
+ */
+export const CURRENCY_MINOR_UNITS = {
+  USD: 100,
+} as const;
+
+export const CurrencySchema = z.enum(["USD"]);
+export type Currency = z.infer<typeof CurrencySchema>;
+
+/** Decimal places of the decimal form, matching NUMERIC(8,4) price columns */
+export const PRICE_DECIMAL_PLACES = 4;
+
+/** Integer units per major unit of major_e4 (1e-4 dollars for USD) */
+export const PRICE_SCALE = 10 ** PRICE_DECIMAL_PLACES;
+
+/**
+ * Typed price: an integer count of 1e-4 major units (major_e4, hundredths of
+ * a cent for USD) plus currency, so callers never guess whether a number is
+ * cents or dollars and sub-cent ticks stay integers (0.5550 USD is 5550).
+ */
+export const PriceSchema = z.object({
+  major_e4: z.number().int(),
+  currency: CurrencySchema,
+});
+export type Price = z.infer<typeof PriceSchema>;
+
+/**
+ * Wire form of a Price: the integer major_e4 units and the decimal major-unit
+ * string (e.g. { major_e4: 5500, currency: "USD", decimal: "0.5500" })
+ */
+export interface PriceJson {
+  major_e4: number;
+  currency: Currency;
+  decimal: string;
+}
+
+/** Price from minor units (Kalshi integer cent fields) */
+export function priceFromMinor(minor: number | null | undefined, currency: Currency = "USD"): Price | null {
+  if (minor === null || minor === undefined || !Number.isFinite(minor)) return null;
+  return { major_e4: Math.round(minor * PRICE_SCALE / CURRENCY_MINOR_UNITS[currency]), currency };
+}
+
+/**
+ * Price from a decimal major-unit value: a NUMERIC column string, a Kalshi
+ * _dollars string or a dollar number. Rounded to PRICE_DECIMAL_PLACES, so
+ * float noise never leaks into the units. Empty or invalid values are null.
+ */
+export function priceFromDecimal(value: string | number | null | undefined, currency: Currency = "USD"): Price | null {
+  if (value === null || value === undefined || value === "") return null;
+  const n = typeof value === "number" ? value : Number(value);
+  if (!Number.isFinite(n)) return null;
+  return { major_e4: Math.round(n * PRICE_SCALE), currency };
+}
+
+/** Decimal major-unit string of a price, e.g. "0.5500" */
+export function priceDecimal(price: Price): string {
+  return (price.major_e4 / PRICE_SCALE).toFixed(PRICE_DECIMAL_PLACES);
+}
+
+/** Major-unit number of a price (dollars for USD) */
+export function priceMajor(price: Price): number {
+  return Number(priceDecimal(price));
+}
+
+/** Wire form of a price, null stays null */
+export function priceToJson(price: Price | null): PriceJson | null {
+  return price ? { major_e4: price.major_e4, currency: price.currency, decimal: priceDecimal(price) } : null;
+}
@@ -0,0 +1,147 @@
+// Raw archive message schemas - field maps for validating archived JSONL
+// records, keyed by feed and message type. Built-ins mirror ssmd-schemas;
+// exchanges/schemas/*.yaml files add feeds or override a built-in version.
+import { z } from "zod";
+
+// Same JSON types as the field maps of lib/schema
+export const JsonTypeSchema = z.enum(["string", "integer", "number", "boolean", "object", "array", "null"]);
+
+export const FieldSpecSchema = z.object({
+  type: z.union([JsonTypeSchema, z.array(JsonTypeSchema).min(1)]),
+  // true = required; a string names a group where at least one member is required
+  required: z.union([z.literal(true), z.string()]).optional(),
+});
+
+// One message type of a feed. Field paths are dotted from the archive line;
+// `[]` marks per-element fields of an array (Kraken data[]). type_field names
+// the path holding the message type, for feeds the CLI has no detector for.
+export const RawSchemaSchema = z.object({
+  feed: z.string(),
+  type: z.string(),
+  version: z.string().default("v1"),
+  type_field: z.string().optional(),
+  fields: z.record(FieldSpecSchema),
+});
+
+export type JsonType = z.infer<typeof JsonTypeSchema>;
+export type FieldSpec = z.infer<typeof FieldSpecSchema>;
+export type RawSchema = z.infer<typeof RawSchemaSchema>;
+
+/** Fields every archiver line may carry */
+const ARCHIVER_FIELDS: Record<string, FieldSpec> = {
+  _received_at: { type: "integer", required: true },
+  _nats_seq: { type: "integer" },
+  _shard_id: { type: "integer" },
+};
+
+const KALSHI_ENVELOPE: Record<string, FieldSpec> = {
+  ...ARCHIVER_FIELDS,
+  type: { type: "string", required: true },
+  sid: { type: "integer" },
+  seq: { type: "integer" },
+  "msg.market_ticker": { type: "string", required: true },
+  "msg.market_id": { type: "string" },
+  "msg.ts": { type: "integer", required: true },
+};
+
+const KRAKEN_ENVELOPE: Record<string, FieldSpec> = {
+  ...ARCHIVER_FIELDS,
+  channel: { type: "string", required: true },
+  type: { type: "string" },
+  "data[].symbol": { type: "string", required: true },
+};
+
+/**
+ * Field maps mirroring the required/optional reads in ssmd-schemas. Records
+ * the parsers would skip show up as missing or type errors; fields they do
+ * not read show up as unknown (warnings).
+ */
+export const BUILTIN_RAW_SCHEMAS: RawSchema[] = [
+  {
+    feed: "kalshi",
+    type: "trade",
+    version: "v1",
+    fields: {
+      ...KALSHI_ENVELOPE,
+      "msg.trade_id": { type: "string", required: true },
+      "msg.yes_price": { type: "integer", required: "price" },
+      "msg.price": { type: "integer", required: "price" },
+      "msg.yes_price_dollars": { type: "string", required: "price" },
+      "msg.no_price": { type: "integer" },
+      "msg.no_price_dollars": { type: "string" },
+      "msg.count": { type: "integer", required: "count" },
+      "msg.count_fp": { type: "string", required: "count" },
+      "msg.taker_side": { type: "string", required: "side" },
+      "msg.side": { type: "string", required: "side" },
+    },
+  },
+  {
+    feed: "kalshi",
+    type: "ticker",
+    version: "v1",
+    fields: {
+      ...KALSHI_ENVELOPE,
+      "msg.Clock": { type: "integer" },
+      "msg.price": { type: "integer" },
+      "msg.price_dollars": { type: "string" },
+      "msg.yes_bid": { type: "integer" },
+      "msg.yes_bid_dollars": { type: "string" },
+      "msg.yes_ask": { type: "integer" },
+      "msg.yes_ask_dollars": { type: "string" },
+      "msg.no_bid": { type: "integer" },
+      "msg.no_bid_dollars": { type: "string" },
+      "msg.no_ask": { type: "integer" },
+      "msg.no_ask_dollars": { type: "string" },
+      "msg.volume": { type: "integer" },
+      "msg.volume_fp": { type: "string" },
+      "msg.open_interest": { type: "integer" },
+      "msg.open_interest_fp": { type: "string" },
+      "msg.dollar_volume": { type: "integer" },
+      "msg.dollar_open_interest": { type: "integer" },
+    },
+  },
+  {
+    feed: "kraken-spot",
+    type: "trade",
+    version: "v1",
+    fields: {
+      ...KRAKEN_ENVELOPE,
+      "data[].side": { type: "string", required: true },
+      "data[].price": { type: "number", required: true },
+      "data[].qty": { type: "number", required: true },
+      "data[].ord_type": { type: "string", required: true },
+      "data[].trade_id": { type: ["integer", "string"], required: true },
+      "data[].timestamp": { type: "string", required: true },
+    },
+  },
+  {
+    feed: "kraken-spot",
+    type: "ticker",
+    version: "v1",
+    fields: {
+      ...KRAKEN_ENVELOPE,
+      ...Object.fromEntries(
+        ["bid", "bid_qty", "ask", "ask_qty", "last", "volume", "vwap", "high", "low", "change", "change_pct"]
+          .map((f) => [`data[].${f}`, { type: "number", required: true } as FieldSpec]),
+      ),
+    },
+  },
+  {
+    feed: "binance",
+    type: "trade",
+    version: "v1",
+    fields: {
+      ...ARCHIVER_FIELDS,
+      stream: { type: "string" },
+      "data.e": { type: "string", required: true },
+      "data.E": { type: "integer" },
+      "data.s": { type: "string", required: true },
+      "data.t": { type: "integer" },
+      "data.p": { type: "string", required: true },
+      "data.q": { type: "string", required: true },
+      "data.T": { type: "integer", required: true },
+      "data.m": { type: "boolean", required: true },
+      "data.M": { type: "boolean" },
+    },
+  },
+];
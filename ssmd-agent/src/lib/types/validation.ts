@@ -0,0 +1,41 @@
+// Validation issue types and codes for ssmd validate
+// Codes are stable: JSON reports and SARIF rule ids carry them, so a code is
+// never renumbered or reused once released. Add new codes at the end of a group.
+
+export const VALIDATION_CODES = {
+  // feeds/
+  "SSMD-F001": "Feed file does not match the feed schema",
+  "SSMD-F002": "Feed name does not match its file name",
+  // schemas/
+  "SSMD-S001": "Schema is missing feed, type or version",
+  "SSMD-S002": "Schema fields are invalid",
+  "SSMD-S003": "Schema references a feed that does not exist",
+  "SSMD-S004": "Schema version is declared twice",
+  "SSMD-S005": "compatible_with is not a list of versions",
+  "SSMD-S006": "compatible_with names a version that does not exist",
+  "SSMD-S007": "compatible_with names a version this one breaks",
+  "SSMD-S008": "Schema changed without a version bump",
+  "SSMD-S009": "Schema source is incomplete",
+  "SSMD-S010": "Schema source file is missing",
+  "SSMD-S011": "Schema source file changed since its fields were generated",
+  // environments/
+  "SSMD-E001": "Environment has no feed",
+  "SSMD-E002": "Environment references a feed that does not exist",
+  "SSMD-E003": "Environment schema is not type:version",
+  "SSMD-E004": "Environment references a schema that does not exist",
+  "SSMD-E005": "Environment key source does not resolve",
+  // ssmd.lock
+  "SSMD-L001": "Config drifted from ssmd.lock",
+} as const;
+
+export type ValidationCode = keyof typeof VALIDATION_CODES;
+
+export type Severity = "error" | "warning";
+
+export interface ValidationIssue {
+  code: ValidationCode;
+  severity: Severity;
+  /** Path relative to exchanges/, e.g. "environments/prod.yaml" */
+  file: string;
+  message: string;
+}
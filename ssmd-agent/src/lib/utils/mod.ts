@@ -2,3 +2,12 @@
  * Utility module exports
  */
 export { RateLimiter, sleep, retry } from "./rate-limit.ts";
+export { Counter, Gauge, globalRegistry, Histogram, MetricsRegistry } from "./metrics.ts";
+export {
+  budgetCounterFromEnv,
+  MemoryBudgetCounter,
+  parseRateBudget,
+  RateBudget,
+  RedisBudgetCounter,
+  type BudgetCounter,
+} from "./rate-budget.ts";
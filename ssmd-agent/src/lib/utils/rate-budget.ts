@@ -0,0 +1,199 @@
+/**
+ * Rate-limit budget accounting for exchange REST APIs.
+ *
+ * A RateBudget allows `limit` requests per `windowMs` for a key (e.g.
+ * "kalshi"). Clients acquire a token before each request and wait for the
+ * next window when the budget is spent, instead of firing and tripping 429s.
+ *
+ * Counts are kept in-process by default. With RATE_BUDGET_REDIS_URL set they
+ * are shared through Redis, so backfill and secmaster sync jobs running at the
+ * same time draw on one budget. Metrics are registered on globalRegistry.
+ */
+import { connect, type Redis } from "https://deno.land/x/redis@v0.32.4/mod.ts";
+import { globalRegistry } from "./metrics.ts";
+import { sleep } from "./rate-limit.ts";
+
+export const rateBudgetRemaining = globalRegistry.gauge(
+  "ssmd_rate_budget_remaining",
+  "Requests left in the current rate-limit window",
+  ["key"],
+);
+
+export const rateBudgetWaitsTotal = globalRegistry.counter(
+  "ssmd_rate_budget_waits_total",
+  "Requests delayed to the next window because the budget was spent",
+  ["key"],
+);
+
+export const rateBudgetDegraded = globalRegistry.gauge(
+  "ssmd_rate_budget_degraded",
+  "1 when RATE_BUDGET_REDIS_URL is set but Redis was unreachable, so budgets are not shared",
+);
+
+export const rateBudgetExhaustedTotal = globalRegistry.counter(
+  "ssmd_rate_budget_exhausted_total",
+  "429 responses that exhausted the budget for the rest of the window",
+  ["key"],
+);
+
+/**
+ * Fixed-window request counter, shared or in-process.
+ */
+export interface BudgetCounter {
+  /** Add `by` to the window's count and return the new count */
+  incr(windowKey: string, by: number, ttlMs: number): Promise<number>;
+}
+
+/** In-process counter; windows older than the current one are dropped */
+export class MemoryBudgetCounter implements BudgetCounter {
+  private readonly counts = new Map<string, number>();
+
+  incr(windowKey: string, by: number): Promise<number> {
+    const prefix = windowKey.slice(0, windowKey.lastIndexOf(":") + 1);
+    for (const key of this.counts.keys()) {
+      if (key !== windowKey && key.startsWith(prefix)) this.counts.delete(key);
+    }
+    const count = (this.counts.get(windowKey) ?? 0) + by;
+    this.counts.set(windowKey, count);
+    return Promise.resolve(count);
+  }
+}
+
+/** Redis counter; each window key expires once the window has passed */
+export class RedisBudgetCounter implements BudgetCounter {
+  constructor(private readonly redis: Redis) {}
+
+  async incr(windowKey: string, by: number, ttlMs: number): Promise<number> {
+    const pipeline = this.redis.pipeline();
+    pipeline.incrby(windowKey, by);
+    pipeline.pexpire(windowKey, ttlMs);
+    const results = await pipeline.flush();
+    return results[0] as number;
+  }
+}
+
+export interface RateBudgetOptions {
+  /** Budget key, e.g. "kalshi" or "kalshi-demo" */
+  key: string;
+  /** Requests allowed per window */
+  limit: number;
+  /** Window length in ms */
+  windowMs: number;
+  /** Counter, or a pending one (see budgetCounterFromEnv); defaults to in-process */
+  counter?: BudgetCounter | Promise<BudgetCounter>;
+  /** Clock, injectable for tests */
+  now?: () => number;
+}
+
+export class RateBudget {
+  readonly key: string;
+  readonly limit: number;
+  readonly windowMs: number;
+  private readonly counter: Promise<BudgetCounter>;
+  private readonly now: () => number;
+
+  constructor(options: RateBudgetOptions) {
+    if (!Number.isInteger(options.limit) || options.limit < 1) {
+      throw new Error(`rate budget limit must be a positive integer, got ${options.limit}`);
+    }
+    if (!(options.windowMs > 0)) {
+      throw new Error(`rate budget window must be positive, got ${options.windowMs}ms`);
+    }
+    this.key = options.key;
+    this.limit = options.limit;
+    this.windowMs = options.windowMs;
+    this.counter = Promise.resolve(options.counter ?? new MemoryBudgetCounter());
+    this.now = options.now ?? Date.now;
+  }
+
+  /**
+   * Take one request from the budget, waiting for the next window while the
+   * current one is spent. Returns the requests left in the window.
+   */
+  async acquire(): Promise<number> {
+    const counter = await this.counter;
+    while (true) {
+      const now = this.now();
+      const window = Math.floor(now / this.windowMs);
+      const count = await counter.incr(this.windowKey(window), 1, this.windowMs * 2);
+      if (count <= this.limit) {
+        const remaining = this.limit - count;
+        rateBudgetRemaining.set({ key: this.key }, remaining);
+        return remaining;
+      }
+      rateBudgetRemaining.set({ key: this.key }, 0);
+      rateBudgetWaitsTotal.inc({ key: this.key });
+      await sleep((window + 1) * this.windowMs - now);
+    }
+  }
+
+  /**
+   * Spend the rest of the current window after a 429, so every client sharing
+   * the budget backs off rather than only the one that was rejected.
+   */
+  async exhaust(): Promise<void> {
+    const window = Math.floor(this.now() / this.windowMs);
+    await (await this.counter).incr(this.windowKey(window), this.limit, this.windowMs * 2);
+    rateBudgetRemaining.set({ key: this.key }, 0);
+    rateBudgetExhaustedTotal.inc({ key: this.key });
+  }
+
+  private windowKey(window: number): string {
+    return `ratebudget:${this.key}:${window}`;
+  }
+}
+
+/**
+ * Parse a "<requests>/<seconds>" budget, e.g. "10/1" or "600/60".
+ */
+export function parseRateBudget(value: string): { limit: number; windowMs: number } {
+  const match = value.trim().match(/^(\d+)\/(\d+(?:\.\d+)?)$/);
+  const limit = match ? Number(match[1]) : NaN;
+  const seconds = match ? Number(match[2]) : NaN;
+  if (!(limit >= 1) || !(seconds > 0)) {
+    throw new Error(`invalid rate budget '${value}' (expected <requests>/<seconds>, e.g. 10/1)`);
+  }
+  return { limit, windowMs: seconds * 1000 };
+}
+
+let sharedCounter: Promise<BudgetCounter> | null = null;
+
+/**
+ * Counter for budgets created from the environment: Redis when
+ * RATE_BUDGET_REDIS_URL is set, otherwise in-process. Unlike getRedis() there
+ * is no empty-database guard. An unreachable Redis falls back to counting
+ * in-process so a sync job still runs, but the budget is then no longer
+ * shared: the fallback is logged as an error and ssmd_rate_budget_degraded
+ * is set. Redis errors after connecting fail the request.
+ */
+export function budgetCounterFromEnv(): Promise<BudgetCounter> {
+  if (sharedCounter) return sharedCounter;
+
+  const redisUrl = Deno.env.get("RATE_BUDGET_REDIS_URL");
+  if (!redisUrl) {
+    sharedCounter = Promise.resolve(new MemoryBudgetCounter());
+    return sharedCounter;
+  }
+
+  sharedCounter = (async () => {
+    const url = new URL(redisUrl);
+    try {
+      const redis = await connect({
+        hostname: url.hostname,
+        port: parseInt(url.port || "6379"),
+        ...(url.password ? { password: decodeURIComponent(url.password) } : {}),
+      });
+      console.log("[rate-budget] Sharing budgets through Redis", url.hostname);
+      rateBudgetDegraded.set({}, 0);
+      return new RedisBudgetCounter(redis);
+    } catch (e) {
+      console.error(
+        `[rate-budget] Redis unavailable (${(e as Error).message}); counting in-process, ` +
+          "so concurrent jobs no longer share the budget",
+      );
+      rateBudgetDegraded.set({}, 1);
+      return new MemoryBudgetCounter();
+    }
+  })();
+  return sharedCounter;
+}
@@ -111,6 +111,7 @@ export async function validateApiKey(
       valid: false,
       status: 429,
       error: "Rate limit exceeded",
+      keyPrefix: prefix,
       rateLimitRemaining: rateLimit.remaining,
       rateLimitResetAt: rateLimit.resetAt,
     };
@@ -151,6 +152,10 @@ export function hasScope(scopes: string[], required: string): boolean {
   if (required === "billing:read" && scopes.includes("billing:write")) {
     return true;
   }
+  // datasets:download implies datasets:read
+  if (required === "datasets:read" && scopes.includes("datasets:download")) {
+    return true;
+  }
   // admin:read/write implies billing:read (admins can view billing)
   if (required === "billing:read" && (scopes.includes("admin:read") || scopes.includes("admin:write"))) {
     return true;
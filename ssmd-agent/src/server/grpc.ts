@@ -0,0 +1,259 @@
+// Secmaster gRPC API (proto/secmaster.proto), served next to the HTTP API
+import * as grpc from "@grpc/grpc-js";
+import * as protoLoader from "@grpc/proto-loader";
+import { fromFileUrl } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { getMarket, listMarkets, listMarketsUpdatedSince, timedQuery, type Database } from "../lib/db/mod.ts";
+import type { MarketRow } from "../lib/db/markets.ts";
+import { hasScope, validateApiKey, type AuthResult } from "./auth.ts";
+
+const PROTO_PATH = fromFileUrl(new URL("./proto/secmaster.proto", import.meta.url));
+const REQUIRED_SCOPE = "secmaster:read";
+
+// Rows fetched per WatchMarkets poll; a full batch polls again without waiting
+const WATCH_BATCH = 500;
+
+/** Market message as produced for proto-loader (keepCase) */
+export interface MarketMessage {
+  ticker: string;
+  event_ticker: string;
+  title: string;
+  status: string;
+  close_time: string;
+  open_time: string;
+  yes_bid?: number;
+  yes_ask?: number;
+  no_bid?: number;
+  no_ask?: number;
+  last_price?: number;
+  volume?: number;
+  volume_24h?: number;
+  open_interest?: number;
+  market_type: string;
+  result: string;
+  updated_at: string;
+  deleted_at: string;
+}
+
+export interface ListMarketsRequest {
+  category: string;
+  status: string;
+  series: string;
+  event_ticker: string;
+  closing_before: string;
+  closing_after: string;
+  as_of: string;
+  games_only: boolean;
+  limit: number;
+}
+
+interface GetMarketRequest {
+  ticker: string;
+}
+
+interface WatchMarketsRequest {
+  since: string;
+  status: string;
+  event_ticker: string;
+}
+
+export interface GrpcServerOptions {
+  port: number;
+  db: Database;
+  // WatchMarkets poll interval (default 1s)
+  pollIntervalMs?: number;
+  // Replaces API key validation in tests
+  authOverride?: (apiKey: string | null, db: Database) => Promise<AuthResult>;
+}
+
+export interface GrpcServerHandle {
+  // Resolves to the bound port once the server is listening
+  ready: Promise<number>;
+  shutdown(): void;
+}
+
+function loadService(): grpc.ServiceDefinition {
+  const definition = protoLoader.loadSync(PROTO_PATH, {
+    keepCase: true,
+    longs: Number,
+    defaults: true,
+    oneofs: true,
+  });
+  const pkg = grpc.loadPackageDefinition(definition);
+  const v1 = ((pkg.ssmd as grpc.GrpcObject).secmaster as grpc.GrpcObject).v1 as grpc.GrpcObject;
+  return (v1.Secmaster as grpc.ServiceClientConstructor).service;
+}
+
+function optionalNumber(v: string | number | null): number | undefined {
+  return v === null ? undefined : Number(v);
+}
+
+function timestamp(v: Date | null): string {
+  return v ? v.toISOString() : "";
+}
+
+/** Database row to Market message; unset decimals stay unset rather than 0 */
+export function marketToMessage(row: MarketRow): MarketMessage {
+  return {
+    ticker: row.ticker,
+    event_ticker: row.eventTicker,
+    title: row.title,
+    status: row.status,
+    close_time: timestamp(row.closeTime),
+    open_time: timestamp(row.openTime),
+    yes_bid: optionalNumber(row.yesBid),
+    yes_ask: optionalNumber(row.yesAsk),
+    no_bid: optionalNumber(row.noBid),
+    no_ask: optionalNumber(row.noAsk),
+    last_price: optionalNumber(row.lastPrice),
+    volume: optionalNumber(row.volume),
+    volume_24h: optionalNumber(row.volume24h),
+    open_interest: optionalNumber(row.openInterest),
+    market_type: row.marketType ?? "",
+    result: row.result ?? "",
+    updated_at: timestamp(row.updatedAt),
+    deleted_at: timestamp(row.deletedAt),
+  };
+}
+
+/** ListMarkets request to listMarkets options; proto3 defaults mean "unset" */
+export function listOptionsFromRequest(req: ListMarketsRequest): Parameters<typeof listMarkets>[1] {
+  return {
+    category: req.category || undefined,
+    status: req.status || undefined,
+    series: req.series || undefined,
+    eventTicker: req.event_ticker || undefined,
+    closingBefore: req.closing_before || undefined,
+    closingAfter: req.closing_after || undefined,
+    asOf: req.as_of || undefined,
+    gamesOnly: req.games_only,
+    limit: req.limit > 0 ? req.limit : undefined,
+  };
+}
+
+// API key from x-api-key or "authorization: Bearer" metadata, as over HTTP
+function extractApiKey(metadata: grpc.Metadata): string | null {
+  const key = metadata.get("x-api-key")[0];
+  if (key) return key.toString();
+  const auth = metadata.get("authorization")[0]?.toString();
+  return auth?.startsWith("Bearer ") ? auth.slice(7) : null;
+}
+
+/**
+ * Start the Secmaster service on options.port (0 picks a free port). Calls
+ * need an API key with secmaster:read.
+ */
+export function createGrpcServer(options: GrpcServerOptions): GrpcServerHandle {
+  const { db } = options;
+  const pollIntervalMs = options.pollIntervalMs ?? 1000;
+  const validate = options.authOverride ?? validateApiKey;
+
+  // Status error for a call, or null when the caller may proceed
+  const authorize = async (metadata: grpc.Metadata): Promise<Partial<grpc.StatusObject> | null> => {
+    const auth = await validate(extractApiKey(metadata), db);
+    if (!auth.valid) {
+      const code = auth.status === 429 ? grpc.status.RESOURCE_EXHAUSTED : grpc.status.UNAUTHENTICATED;
+      return { code, details: auth.error ?? "Unauthorized" };
+    }
+    if (!hasScope(auth.scopes ?? [], REQUIRED_SCOPE)) {
+      return { code: grpc.status.PERMISSION_DENIED, details: "Insufficient permissions" };
+    }
+    return null;
+  };
+
+  const internal = (err: unknown): Partial<grpc.StatusObject> => {
+    console.error("[grpc] handler error:", err);
+    return { code: grpc.status.INTERNAL, details: "Internal server error" };
+  };
+
+  const handlers: grpc.UntypedServiceImplementation = {
+    ListMarkets: async (
+      call: grpc.ServerUnaryCall<ListMarketsRequest, unknown>,
+      callback: grpc.sendUnaryData<{ markets: MarketMessage[] }>,
+    ) => {
+      const denied = await authorize(call.metadata);
+      if (denied) return callback(denied);
+      try {
+        const rows = await timedQuery("grpc_list_markets", () => listMarkets(db, listOptionsFromRequest(call.request)));
+        callback(null, { markets: rows.map(marketToMessage) });
+      } catch (err) {
+        callback(internal(err));
+      }
+    },
+
+    GetMarket: async (
+      call: grpc.ServerUnaryCall<GetMarketRequest, unknown>,
+      callback: grpc.sendUnaryData<MarketMessage>,
+    ) => {
+      const denied = await authorize(call.metadata);
+      if (denied) return callback(denied);
+      try {
+        const row = await timedQuery("grpc_get_market", () => getMarket(db, call.request.ticker));
+        if (!row) {
+          return callback({ code: grpc.status.NOT_FOUND, details: `Market ${call.request.ticker} not found` });
+        }
+        callback(null, marketToMessage(row));
+      } catch (err) {
+        callback(internal(err));
+      }
+    },
+
+    WatchMarkets: async (call: grpc.ServerWritableStream<WatchMarketsRequest, MarketMessage>) => {
+      const denied = await authorize(call.metadata);
+      if (denied) return call.emit("error", denied);
+
+      const start = call.request.since ? new Date(call.request.since) : new Date();
+      if (isNaN(start.getTime())) {
+        return call.emit("error", { code: grpc.status.INVALID_ARGUMENT, details: "since must be RFC 3339" });
+      }
+      // Postgres text timestamps keep the microseconds a Date would drop
+      let since = start.toISOString();
+      let afterTicker: string | undefined;
+      let open = true;
+      call.on("cancelled", () => {
+        open = false;
+      });
+
+      try {
+        while (open) {
+          const rows = await listMarketsUpdatedSince(db, since, {
+            afterTicker,
+            status: call.request.status || undefined,
+            eventTicker: call.request.event_ticker || undefined,
+            limit: WATCH_BATCH,
+          });
+          for (const row of rows) {
+            call.write(marketToMessage(row));
+          }
+          const last = rows.at(-1);
+          if (last) {
+            since = last.updatedAtText;
+            afterTicker = last.ticker;
+          }
+          if (rows.length < WATCH_BATCH) {
+            await new Promise((resolve) => setTimeout(resolve, pollIntervalMs));
+          }
+        }
+      } catch (err) {
+        if (open) call.emit("error", internal(err));
+      }
+    },
+  };
+
+  const server = new grpc.Server();
+  server.addService(loadService(), handlers);
+
+  const ready = new Promise<number>((resolve, reject) => {
+    server.bindAsync(`0.0.0.0:${options.port}`, grpc.ServerCredentials.createInsecure(), (err, port) => {
+      if (err) return reject(err);
+      console.log(`ssmd-data-ts gRPC listener on localhost:${port}`);
+      resolve(port);
+    });
+  });
+
+  return {
+    ready,
+    shutdown() {
+      server.forceShutdown();
+    },
+  };
+}
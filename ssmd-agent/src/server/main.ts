@@ -1,9 +1,13 @@
 // ssmd-data-ts server entry point
 import { createServer } from "./mod.ts";
 import { initDuckDB, closeDuckDB } from "../lib/duckdb/mod.ts";
+import { FEED_CONFIG, msUntilUtc, primeDatasetCache, recentDates } from "../lib/gcs/mod.ts";
+import { DEFAULT_MIGRATIONS_DIR, runMigrations } from "../lib/db/migrate.ts";
+import postgres from "postgres";
 
 const port = parseInt(Deno.env.get("PORT") ?? "8080");
 const internalPort = Deno.env.get("INTERNAL_PORT") ? parseInt(Deno.env.get("INTERNAL_PORT")!) : undefined;
+const grpcPort = Deno.env.get("GRPC_PORT") ? parseInt(Deno.env.get("GRPC_PORT")!) : undefined;
 const dataDir = Deno.env.get("DATA_DIR") ?? "/data";
 const databaseUrl = Deno.env.get("DATABASE_URL");
 const redisUrl = Deno.env.get("REDIS_URL");
@@ -33,12 +37,61 @@ if (!redisUrl) {
   Deno.exit(1);
 }
 
+// MIGRATE_ON_START=true applies pending migrations before serving; a failure
+// exits so a deploy never serves against a half-migrated schema
+if (Deno.env.get("MIGRATE_ON_START") === "true") {
+  const migrationSql = postgres(databaseUrl, { max: 1 });
+  try {
+    const ran = await runMigrations(migrationSql, Deno.env.get("MIGRATIONS_DIR") ?? DEFAULT_MIGRATIONS_DIR);
+    console.log(ran.length > 0 ? `Applied ${ran.length} migration(s)` : "Schema is up to date");
+  } catch (err) {
+    console.error("Migration failed:", (err as Error).message);
+    Deno.exit(1);
+  } finally {
+    await migrationSql.end();
+  }
+}
+
 // Initialize DuckDB for parquet queries (non-fatal if it fails)
 await initDuckDB().catch((err) => {
   console.error("DuckDB init failed (queries will be unavailable):", err.message);
 });
 
-const server = createServer({ port, dataDir, databaseUrl, redisUrl, harmanDatabaseUrls, internalPort });
+// MARKET_CACHE=true serves open-market reads from memory, refreshed every
+// MARKET_CACHE_REFRESH_MS and on secmaster deltas when MARKET_CACHE_NATS_URL is set
+const marketCache = Deno.env.get("MARKET_CACHE") === "true"
+  ? {
+    refreshIntervalMs: Deno.env.get("MARKET_CACHE_REFRESH_MS") ? parseInt(Deno.env.get("MARKET_CACHE_REFRESH_MS")!) : undefined,
+    natsUrl: Deno.env.get("MARKET_CACHE_NATS_URL"),
+    subject: Deno.env.get("MARKET_CACHE_SUBJECT"),
+  }
+  : undefined;
+
+const server = createServer({ port, dataDir, databaseUrl, redisUrl, harmanDatabaseUrls, internalPort, grpcPort, marketCache });
+
+// Optionally prime the dataset cache daily at DATASET_CACHE_PRIME_AT (HH:MM UTC)
+// with the last DATASET_CACHE_PRIME_DAYS days (default 7) of every feed.
+const primeAt = Deno.env.get("DATASET_CACHE_PRIME_AT");
+const primeBucket = Deno.env.get("GCS_BUCKET");
+if (primeAt && primeBucket) {
+  const primeDays = parseInt(Deno.env.get("DATASET_CACHE_PRIME_DAYS") ?? "7");
+  const schedulePrime = () => {
+    const delay = msUntilUtc(primeAt);
+    if (delay === null) {
+      console.error(`Invalid DATASET_CACHE_PRIME_AT=${primeAt} (expected HH:MM UTC); cache priming disabled`);
+      return;
+    }
+    setTimeout(async () => {
+      const result = await primeDatasetCache(primeBucket, Object.keys(FEED_CONFIG), recentDates(primeDays));
+      console.log(`Primed dataset cache: ${result.primed} feed/dates, ${result.failed.length} failed`);
+      for (const f of result.failed) {
+        console.error(`  ${f.feed} ${f.date}: ${f.error}`);
+      }
+      schedulePrime();
+    }, delay);
+  };
+  schedulePrime();
+}
 
 // Handle shutdown gracefully
 Deno.addSignalListener("SIGINT", async () => {
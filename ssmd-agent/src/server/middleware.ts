@@ -1,5 +1,7 @@
 // HTTP server middleware
 
+import type { Transform } from "node:stream";
+import * as zlib from "node:zlib";
 import { httpRequestDuration, httpRequestsTotal, httpInFlight } from "./metrics.ts";
 
 /**
@@ -100,6 +102,12 @@ const PARAM_RESOURCES: Record<string, string> = {
  */
 export function normalizePath(pathname: string): string {
   const segments = pathname.split("/");
+  // Pattern: /datasets/<feed>/<date>/files[/<name>] (feeds are few, dates and names are not)
+  if (segments.length >= 5 && segments[1] === "datasets" && segments[4] === "files") {
+    segments[3] = ":date";
+    if (segments.length >= 6) segments[5] = ":name";
+    return segments.join("/");
+  }
   // Pattern: /v1/<resource>/<id>[/<sub>]
   if (segments.length >= 4 && segments[1] === "v1") {
     const resource = segments[2];
@@ -143,3 +151,161 @@ export function metricsMiddleware(
     }
   };
 }
+
+/**
+ * Count the bytes of a response body as it is sent. onComplete receives the
+ * total once the body finishes, or null if the client disconnects first.
+ * Responses without a body complete immediately with 0.
+ */
+export function countResponseBytes(
+  res: Response,
+  onComplete: (bytes: number | null) => void
+): Response {
+  if (!res.body) {
+    onComplete(0);
+    return res;
+  }
+  let bytes = 0;
+  let done = false;
+  const finish = (total: number | null) => {
+    if (done) return;
+    done = true;
+    onComplete(total);
+  };
+  const reader = res.body.getReader();
+  const counted = new ReadableStream<Uint8Array>({
+    async pull(controller) {
+      const { done: ended, value } = await reader.read();
+      if (ended) {
+        controller.close();
+        finish(bytes);
+        return;
+      }
+      bytes += value.byteLength;
+      controller.enqueue(value);
+    },
+    cancel(reason) {
+      finish(null);
+      return reader.cancel(reason);
+    },
+  });
+  return new Response(counted, { status: res.status, statusText: res.statusText, headers: res.headers });
+}
+
+export type ContentEncoding = "zstd" | "gzip" | "br";
+
+/**
+ * Encodings clients may be sent, in preference order for equal q-values.
+ * Deno.serve compresses with gzip and br itself; the compression middleware
+ * adds zstd.
+ */
+export const SUPPORTED_ENCODINGS: ContentEncoding[] = ["zstd", "br", "gzip"];
+
+const createZstdCompress = (zlib as unknown as { createZstdCompress: () => Transform }).createZstdCompress;
+if (typeof createZstdCompress !== "function") {
+  throw new Error("node:zlib createZstdCompress is required for zstd responses; upgrade Deno");
+}
+
+/**
+ * Pick a response encoding from an Accept-Encoding header. Highest q-value
+ * wins, ties go to SUPPORTED_ENCODINGS order, "*" matches any encoding not
+ * listed and q=0 refuses it. Returns null for identity.
+ */
+export function negotiateEncoding(
+  acceptEncoding: string | null,
+  supported: ContentEncoding[] = SUPPORTED_ENCODINGS
+): ContentEncoding | null {
+  if (!acceptEncoding) return null;
+  const weights = new Map<string, number>();
+  for (const part of acceptEncoding.split(",")) {
+    const [name, ...params] = part.trim().toLowerCase().split(";");
+    if (!name) continue;
+    let q = 1;
+    for (const param of params) {
+      const [key, value] = param.trim().split("=");
+      if (key === "q") q = Number(value);
+    }
+    weights.set(name.trim(), Number.isFinite(q) ? q : 0);
+  }
+  let best: ContentEncoding | null = null;
+  let bestQ = 0;
+  for (const encoding of supported) {
+    const q = weights.get(encoding) ?? weights.get("*") ?? 0;
+    if (q > bestQ) {
+      best = encoding;
+      bestQ = q;
+    }
+  }
+  return best;
+}
+
+const COMPRESSIBLE_TYPES = [
+  "application/json",
+  "application/x-ndjson",
+  "application/jsonl",
+  "text/csv",
+  "text/plain",
+];
+
+/** Responses below this size (when Content-Length is known) go out as-is */
+const MIN_COMPRESS_BYTES = 1024;
+
+function compressible(res: Response): boolean {
+  if (!res.body || res.status === 204 || res.status === 206 || res.status === 304) return false;
+  // Already encoded (e.g. a raw archived .jsonl.gz) passes through untouched
+  if (res.headers.has("Content-Encoding")) return false;
+  const length = res.headers.get("Content-Length");
+  if (length !== null && Number(length) < MIN_COMPRESS_BYTES) return false;
+  // text/event-stream is left alone so events are not held in compressor buffers
+  const type = (res.headers.get("Content-Type") ?? "").split(";")[0].trim().toLowerCase();
+  return COMPRESSIBLE_TYPES.includes(type);
+}
+
+/** Adapt a node:zlib transform to a web TransformStream */
+function nodeTransformStream(transform: Transform): TransformStream<Uint8Array, Uint8Array> {
+  return new TransformStream<Uint8Array, Uint8Array>({
+    start(controller) {
+      transform.on("data", (chunk: Uint8Array) => controller.enqueue(new Uint8Array(chunk)));
+      transform.on("error", (err: Error) => controller.error(err));
+    },
+    transform(chunk) {
+      return new Promise((resolve, reject) => {
+        transform.write(chunk, (err?: Error | null) => (err ? reject(err) : resolve()));
+      });
+    },
+    flush() {
+      return new Promise((resolve) => {
+        transform.once("end", () => resolve());
+        transform.end();
+      });
+    },
+  });
+}
+
+/**
+ * zstd response compression middleware. Deno.serve already compresses
+ * JSON, JSONL and CSV with gzip or br and skips responses that carry a
+ * Content-Encoding, so this only handles clients that prefer zstd: their
+ * bodies are compressed as they stream, never buffered whole, and Deno.serve
+ * leaves them alone. Everything else (including responses that are already
+ * encoded, such as a raw archived .jsonl.gz) is passed through for Deno.serve.
+ */
+export function compression(
+  handler: (req: Request) => Promise<Response>
+): (req: Request) => Promise<Response> {
+  return async (req: Request) => {
+    const res = await handler(req);
+    if (req.method === "HEAD" || !compressible(res)) return res;
+    if (negotiateEncoding(req.headers.get("Accept-Encoding")) !== "zstd") return res;
+
+    const headers = new Headers(res.headers);
+    headers.append("Vary", "Accept-Encoding");
+    headers.set("Content-Encoding", "zstd");
+    headers.delete("Content-Length");
+    return new Response(res.body!.pipeThrough(nodeTransformStream(createZstdCompress())), {
+      status: res.status,
+      statusText: res.statusText,
+      headers,
+    });
+  };
+}
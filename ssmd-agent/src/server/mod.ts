@@ -1,14 +1,19 @@
 // Server module exports
-export { logger, cors, metricsMiddleware } from "./middleware.ts";
+export { logger, cors, metricsMiddleware, compression } from "./middleware.ts";
 export { createRouter, createFilteredRouter, API_VERSION, type RouteContext, type AuthInfo, type ApiSurface } from "./routes.ts";
 export { validateApiKey, hasScope } from "./auth.ts";
 export { initDuckDB, closeDuckDB } from "../lib/duckdb/mod.ts";
 
 import { createRouter, createFilteredRouter, type RouteContext } from "./routes.ts";
-import { logger, cors, metricsMiddleware } from "./middleware.ts";
+import { logger, cors, metricsMiddleware, compression } from "./middleware.ts";
 import { drizzle } from "drizzle-orm/postgres-js";
 import postgres from "postgres";
 import * as schema from "../lib/db/schema.ts";
+import { createPool, setQueryObserver } from "../lib/db/client.ts";
+import { MarketCache } from "../lib/db/markets.ts";
+import { dbQueryDuration, dbQueryErrorsTotal, marketCacheLookupsTotal, marketCacheSize } from "./metrics.ts";
+import { connect } from "npm:nats";
+import { createGrpcServer, type GrpcServerHandle } from "./grpc.ts";
 
 export interface ServerOptions {
   port: number;
@@ -17,11 +22,54 @@ export interface ServerOptions {
   redisUrl?: string;  // Optional, uses REDIS_URL env var if not provided
   harmanDatabaseUrls?: Map<string, string>;  // Optional, name→url for harman admin queries
   internalPort?: number;  // Optional, enables dual listener (public + internal)
+  grpcPort?: number;  // Optional, serves the Secmaster gRPC API on this port
+  marketCache?: MarketCacheConfig;  // Optional, serves open-market reads from memory
+}
+
+export interface MarketCacheConfig {
+  refreshIntervalMs?: number;
+  // Refresh as soon as `ssmd secmaster sync --publish` announces changes
+  natsUrl?: string;
+  subject?: string;
+}
+
+// Subject `ssmd secmaster sync --publish` sends market deltas on
+const DEFAULT_DELTA_SUBJECT = "secmaster.updates.markets";
+
+/**
+ * Start the market cache in the background; reads fall through to Postgres
+ * until the first load completes. Returns a function that stops it.
+ */
+function startMarketCache(cache: MarketCache, config: MarketCacheConfig): () => void {
+  cache.start()
+    .then(() => console.log(`Market cache loaded ${cache.size} open markets`))
+    .catch((err) => console.error("Market cache load failed:", err.message));
+  const sizeTimer = setInterval(() => marketCacheSize.set({}, cache.size), 5000);
+
+  let closeNats: (() => Promise<void>) | undefined;
+  if (config.natsUrl) {
+    connect({ servers: config.natsUrl })
+      .then((nc) => {
+        const sub = nc.subscribe(config.subject ?? DEFAULT_DELTA_SUBJECT);
+        (async () => {
+          for await (const _ of sub) cache.refreshSoon();
+        })();
+        closeNats = () => nc.close();
+      })
+      .catch((err) => console.error("Market cache NATS subscribe failed (timer refresh only):", err.message));
+  }
+
+  return () => {
+    cache.stop();
+    clearInterval(sizeTimer);
+    closeNats?.();
+  };
 }
 
 export interface ServerHandle {
   public: Deno.HttpServer<Deno.NetAddr>;
   internal?: Deno.HttpServer<Deno.NetAddr>;
+  grpc?: GrpcServerHandle;
   shutdown(): void;
 }
 
@@ -38,8 +86,12 @@ export function createServer(options: ServerOptions): ServerHandle {
     Deno.env.set("REDIS_URL", options.redisUrl);
   }
 
-  const sql = postgres(options.databaseUrl);
+  const sql = createPool(options.databaseUrl);
   const db = drizzle(sql, { schema });
+  setQueryObserver((name, durationMs, ok) => {
+    dbQueryDuration.observe({ query: name }, durationMs / 1000);
+    if (!ok) dbQueryErrorsTotal.inc({ query: name });
+  });
 
   // Create pools for harman databases (admin routes)
   const harmanPools = new Map<string, ReturnType<typeof postgres>>();
@@ -53,19 +105,34 @@ export function createServer(options: ServerOptions): ServerHandle {
     console.log(`Harman database connections configured: ${[...harmanPools.keys()].join(", ")}`);
   }
 
+  const marketCache = options.marketCache
+    ? new MarketCache(db, {
+      refreshIntervalMs: options.marketCache.refreshIntervalMs,
+      onLookup: (hit) => marketCacheLookupsTotal.inc({ result: hit ? "hit" : "miss" }),
+    })
+    : undefined;
+  const stopMarketCache = marketCache ? startMarketCache(marketCache, options.marketCache!) : undefined;
+
   const ctx: RouteContext = {
     dataDir: options.dataDir,
     db,
     harmanPools,
+    marketCache,
   };
 
+  // gRPC runs beside either HTTP mode; a bind failure is logged, not fatal
+  const grpcServer = options.grpcPort !== undefined ? createGrpcServer({ port: options.grpcPort, db }) : undefined;
+  grpcServer?.ready.catch((err) => {
+    console.error(`gRPC listener failed on port ${options.grpcPort}:`, err.message);
+  });
+
   if (options.internalPort) {
     // Dual listener mode: public surface on main port, all routes on internal port
     const publicRouter = createFilteredRouter(ctx, "public");
-    const publicHandler = cors(metricsMiddleware(logger(publicRouter)));
+    const publicHandler = cors(metricsMiddleware(logger(compression(publicRouter))));
 
     const internalRouter = createRouter(ctx);
-    const internalHandler = cors(metricsMiddleware(logger(internalRouter)));
+    const internalHandler = cors(metricsMiddleware(logger(compression(internalRouter))));
 
     console.log(`ssmd-data-ts public listener on http://localhost:${options.port}`);
     console.log(`ssmd-data-ts internal listener on http://localhost:${options.internalPort}`);
@@ -83,16 +150,19 @@ export function createServer(options: ServerOptions): ServerHandle {
     return {
       public: publicServer,
       internal: internalServer,
+      grpc: grpcServer,
       shutdown() {
         publicServer.shutdown();
         internalServer.shutdown();
+        grpcServer?.shutdown();
+        stopMarketCache?.();
       },
     };
   }
 
   // Single listener mode (backwards compatible): all routes on main port
   const router = createRouter(ctx);
-  const handler = cors(metricsMiddleware(logger(router)));
+  const handler = cors(metricsMiddleware(logger(compression(router))));
 
   console.log(`ssmd-data-ts listening on http://localhost:${options.port}`);
 
@@ -103,8 +173,11 @@ export function createServer(options: ServerOptions): ServerHandle {
 
   return {
     public: server,
+    grpc: grpcServer,
     shutdown() {
       server.shutdown();
+      grpcServer?.shutdown();
+      stopMarketCache?.();
     },
   };
 }
@@ -1,7 +1,8 @@
 // HTTP server routes
 import { createHash, timingSafeEqual } from "node:crypto";
-import { globalRegistry, apiRequestsTotal } from "./metrics.ts";
-import { normalizePath } from "./middleware.ts";
+import { globalRegistry, apiRequestsTotal, apiRateLimitHitsTotal, apiThrottledTotal } from "./metrics.ts";
+import { countResponseBytes, normalizePath } from "./middleware.ts";
+import { dedupKeys } from "./schema-versions.ts";
 import { validateApiKey, hasScope } from "./auth.ts";
 import { RequestLogBuffer } from "../lib/db/request-log.ts";
 import {
@@ -10,6 +11,15 @@ import {
   getEventStats,
   listMarkets,
   listMarketsWithSnapshot,
+  countMarkets,
+  marketCursorAfter,
+  toMarketResponse,
+  decodeMarketCursor,
+  MARKET_SORTS,
+  type Market,
+  type MarketSort,
+  type MarketsWithSnapshot,
+  type MarketCache,
   getMarket,
   getMarketStats,
   getMarketTimeseries,
@@ -17,7 +27,10 @@ import {
   getCurrentFee,
   getFeeAsOf,
   listCurrentFees,
+  listFeeHistory,
+  setFee,
   getFeeStats,
+  timedQuery,
   getApiKeyByPrefix,
   createApiKey,
   listApiKeysByUser,
@@ -30,11 +43,18 @@ import {
   getAllSettings,
   upsertSetting,
   listSeries,
+  getSeries,
   getSeriesStats,
+  listCategories,
+  categoryExists,
   listPairs,
+  countPairs,
+  pairCursorAfter,
+  toPairMarketResponse,
   getPair,
   getPairStats,
   getPairSnapshots,
+  getBookAt,
   listConditions,
   listTokensByCategories,
   getCondition,
@@ -44,6 +64,7 @@ import {
   getGapReports,
   lookupMarketsByIds,
   VALID_FEEDS,
+  DEFAULT_EXCHANGE,
   events,
   markets,
   pairs,
@@ -63,13 +84,34 @@ import {
   getRawSql,
   type Database,
 } from "../lib/db/mod.ts";
-import { generateApiKey, invalidateKeyCache } from "../lib/auth/mod.ts";
+import { generateApiKey, invalidateKeyCache, loadRouteLimits, RouteRateLimiter } from "../lib/auth/mod.ts";
 import { getEffectiveAuthByEmail, resolveEffectiveUser, type EffectiveUser } from "../lib/auth/effective-scopes.ts";
 import { getUsageForPrefix, getTokenUsage, trackTokenUsage } from "../lib/auth/ratelimit.ts";
 import { getGuardrailSettings, applyGuardrails, checkModelAllowed } from "../lib/guardrails/mod.ts";
-import { getRedis } from "../lib/redis/mod.ts";
-import { listParquetFiles, generateSignedUrls, FEED_CONFIG, feedDescription, getCatalog } from "../lib/gcs/mod.ts";
-import { logDataAccess } from "../lib/db/mod.ts";
+import { getRedis, getSnapRedis, parseSnapshot, snapKey } from "../lib/redis/mod.ts";
+import { getNats, liveTailStream, LIVE_TAIL_PREFIXES, MAX_TAIL_TICKERS } from "../lib/nats/mod.ts";
+import {
+  generateSignedUrls,
+  generateSignedUrl,
+  findParquetFile,
+  readFileStream,
+  downloadName,
+  FEED_CONFIG,
+  feedDescription,
+  getCatalog,
+  getDateManifest,
+  catalogStats,
+  missingDates,
+  listDateFiles,
+  listRangeFiles,
+  listDateRawFiles,
+  invalidateDatasetCache,
+  primeDatasetCache,
+  recentDates,
+} from "../lib/gcs/mod.ts";
+import { AccessAuditBuffer, accessAuditLine, listAccessAudit, logDataAccess, type AccessAuditEntry } from "../lib/db/mod.ts";
+import { sampleLines, sampleRange, type SampleRange, sanitizeToken, verifyArchive } from "../lib/archive/mod.ts";
+import { Storage } from "@google-cloud/storage";
 import { query as duckdbQuery } from "../lib/duckdb/mod.ts";
 import {
   boundaryGapSeconds,
@@ -85,13 +127,18 @@ import {
   buildEventMarketsSQL,
   buildTotalVolumeSQL,
   buildTopTickersSQL,
+  buildDatasetSQL,
+  datasetTables,
   VOLUME_UNITS,
 } from "../lib/duckdb/queries.ts";
 import { VALID_DATA_FEEDS, FEED_PATHS } from "../lib/duckdb/feed-config.ts";
+import { BOOK_FEEDS } from "../lib/types/orderbook.ts";
+import { compileFilter, FilterError, type RecordFilter } from "../lib/filter/mod.ts";
 import postgres from "postgres";
 import { and, inArray, isNull, eq, gte, lt, lte, desc, sql, ilike, like } from "drizzle-orm";
 import { createOneTimeSecret } from "../lib/ots/mod.ts";
 import { sendWelcomeEmail } from "../lib/email/welcome.ts";
+import { FeeUpdateSchema } from "../lib/types/fee.ts";
 
 const USAGE_CACHE_KEY = "cache:keys:usage";
 const USAGE_CACHE_TTL = 120; // 2 minutes
@@ -105,9 +152,13 @@ export interface RouteContext {
   dataDir: string;
   db: Database;
   harmanPools: Map<string, ReturnType<typeof postgres>>;
+  /** Optional in-memory copy of open markets (MARKET_CACHE=true) */
+  marketCache?: MarketCache;
   authOverride?: (apiKey: string | null, db: Database) => Promise<import("./auth.ts").AuthResult>;
   /** Test-only: override the email → EffectiveUser lookup performed by the X-CF-User-Email path. */
   resolveUserOverride?: (email: string) => Promise<EffectiveUser | null>;
+  /** Per-route rate limits; defaults to ROUTE_RATE_LIMITS / ROUTE_RATE_LIMITS_FILE. */
+  routeLimits?: import("../lib/auth/mod.ts").RouteLimit[];
   /** Test-only: override the Redis client used by cache-backed read endpoints. */
   redisOverride?: {
     get(key: string): Promise<string | null>;
@@ -231,22 +282,55 @@ route("GET", "/datasets", async (req) => {
   });
 }, true, "datasets:read", "public");
 
+// 400 for a category filter naming no known category, which would
+// otherwise return an empty list indistinguishable from "no data"
+async function checkCategory(url: URL): Promise<Response | null> {
+  const category = url.searchParams.get("category");
+  if (!category || await categoryExists(category)) return null;
+  return json({ error: `Unknown category: ${category} (see GET /v1/categories)` }, 400);
+}
+
+/** Exchange names as stored in events.exchange / markets.exchange */
+const EXCHANGE_PATTERN = /^[a-z0-9-]{1,32}$/;
+
+/** Optional ?exchange= filter; null when the value is malformed */
+function exchangeParam(url: URL): string | undefined | null {
+  const exchange = url.searchParams.get("exchange");
+  if (exchange === null) return undefined;
+  return EXCHANGE_PATTERN.test(exchange) ? exchange : null;
+}
+
 // Events endpoints
-route("GET", "/v1/events", async (req, ctx) => {
-  const url = new URL(req.url);
-  const events = await listEvents(ctx.db, {
+async function handleListEvents(url: URL, ctx: RouteContext): Promise<Response> {
+  const badCategory = await checkCategory(url);
+  if (badCategory) return badCategory;
+  const exchange = exchangeParam(url);
+  if (exchange === null) {
+    return json({ error: `Invalid exchange: ${url.searchParams.get("exchange")}` }, 400);
+  }
+  const events = await timedQuery("list_events", () => listEvents(ctx.db, {
+    exchange,
     category: url.searchParams.get("category") ?? undefined,
     status: url.searchParams.get("status") ?? undefined,
     series: url.searchParams.get("series") ?? undefined,
     asOf: url.searchParams.get("as_of") ?? undefined,
     limit: url.searchParams.get("limit") ? parseInt(url.searchParams.get("limit")!) : undefined,
-  });
+  }));
   return json({ events });
+}
+
+route("GET", "/v1/events", async (req, ctx) => {
+  return await handleListEvents(new URL(req.url), ctx);
 }, true, "secmaster:read", "public");
 
 route("GET", "/v1/events/:ticker", async (req, ctx) => {
   const params = (req as Request & { params: Record<string, string> }).params;
-  const event = await getEvent(ctx.db, params.ticker);
+  const url = new URL(req.url);
+  const exchange = exchangeParam(url);
+  if (exchange === null) {
+    return json({ error: `Invalid exchange: ${url.searchParams.get("exchange")}` }, 400);
+  }
+  const event = await timedQuery("get_event", () => getEvent(ctx.db, params.ticker, exchange));
   if (!event) {
     return json({ error: "Event not found" }, 404);
   }
@@ -254,8 +338,52 @@ route("GET", "/v1/events/:ticker", async (req, ctx) => {
 }, true, "secmaster:read", "public");
 
 // Markets endpoints
-route("GET", "/v1/markets", async (req, ctx) => {
-  const url = new URL(req.url);
+
+// /v1/markets parameters that only apply to the markets table, rejected with feed=kraken
+const MARKET_TABLE_PARAMS = [
+  "exchange", "category", "series", "event", "close_within_hours", "closing_before", "closing_after",
+  "open_before", "as_of", "games_only", "min_volume", "min_volume_24h", "include_snapshot",
+];
+
+async function handleListMarkets(url: URL, ctx: RouteContext): Promise<Response> {
+  const badCategory = await checkCategory(url);
+  if (badCategory) return badCategory;
+
+  // Kraken reference data lives in pairs; serve it in market shape so callers
+  // filtering by feed (Harman ticker lookup) get Kraken instruments, not Kalshi.
+  // Pairs page by updated_at only and have none of the Kalshi filters.
+  const feed = url.searchParams.get("feed");
+  if (feed === "kraken" || feed === "kraken-futures") {
+    const unsupported = MARKET_TABLE_PARAMS.filter((p) => url.searchParams.has(p));
+    if (unsupported.length > 0) {
+      return json({ error: `Not supported with feed=${feed}: ${unsupported.join(", ")}` }, 400);
+    }
+    const sort = url.searchParams.get("sort");
+    if (sort !== null && sort !== "updated_at") {
+      return json({ error: `Invalid sort for feed=${feed}: ${sort}. Valid sorts: updated_at` }, 400);
+    }
+    const cursorParam = url.searchParams.get("cursor");
+    const cursor = cursorParam ? decodeMarketCursor(cursorParam) : undefined;
+    if (cursor === null) {
+      return json({ error: "Invalid cursor" }, 400);
+    }
+
+    const filters = {
+      exchange: "kraken",
+      marketType: feed === "kraken" ? "spot" : "perpetual",
+      status: url.searchParams.get("status") ?? undefined,
+    };
+    const limit = url.searchParams.get("limit") ? parseInt(url.searchParams.get("limit")!) : 100;
+    const [rows, total] = await Promise.all([
+      timedQuery("list_pairs", () => listPairs(ctx.db, { ...filters, cursor, limit })),
+      timedQuery("count_pairs", () => countPairs(ctx.db, filters)),
+    ]);
+    const nextCursor = rows.length === limit ? await pairCursorAfter(ctx.db, rows[rows.length - 1].pairId) : null;
+
+    const response = json({ markets: rows.map(toPairMarketResponse), next_cursor: nextCursor });
+    response.headers.set("X-Total-Count", String(total));
+    return response;
+  }
 
   // Calculate closingBefore from close_within_hours if provided
   let closingBefore = url.searchParams.get("closing_before") ?? undefined;
@@ -268,7 +396,13 @@ route("GET", "/v1/markets", async (req, ctx) => {
     }
   }
 
+  const exchange = exchangeParam(url);
+  if (exchange === null) {
+    return json({ error: `Invalid exchange: ${url.searchParams.get("exchange")}` }, 400);
+  }
+
   const options = {
+    exchange,
     category: url.searchParams.get("category") ?? undefined,
     status: url.searchParams.get("status") ?? undefined,
     series: url.searchParams.get("series") ?? undefined,
@@ -278,22 +412,73 @@ route("GET", "/v1/markets", async (req, ctx) => {
     openBefore: url.searchParams.get("open_before") ?? undefined,  // ISO datetime — only markets with open_time <= this value
     asOf: url.searchParams.get("as_of") ?? undefined,
     gamesOnly: url.searchParams.get("games_only") === "true",
+    minVolume: undefined as number | undefined,
+    minVolume24h: undefined as number | undefined,
     limit: url.searchParams.get("limit") ? parseInt(url.searchParams.get("limit")!) : undefined,
   };
 
-  // If include_snapshot=true, return CDC sync metadata (snapshot_time, snapshot_lsn)
-  const includeSnapshot = url.searchParams.get("include_snapshot") === "true";
-  if (includeSnapshot) {
-    const result = await listMarketsWithSnapshot(ctx.db, options);
-    return json({
-      markets: result.markets,
-      snapshot_time: result.snapshotTime,
-      snapshot_lsn: result.snapshotLsn,
-    });
+  // Volume floors: min_volume (lifetime) and min_volume_24h, in contracts
+  for (const [param, key] of [["min_volume", "minVolume"], ["min_volume_24h", "minVolume24h"]] as const) {
+    const raw = url.searchParams.get(param);
+    if (raw === null) continue;
+    const value = Number(raw);
+    if (!Number.isInteger(value) || value < 0) {
+      return json({ error: `${param} must be a non-negative integer` }, 400);
+    }
+    options[key] = value;
   }
 
-  const markets = await listMarkets(ctx.db, options);
-  return json({ markets });
+  // Keyset pagination: sort=updated_at|close_time|volume, cursor=<next_cursor of the previous page>
+  const sort = (url.searchParams.get("sort") ?? "updated_at") as MarketSort;
+  if (!MARKET_SORTS.includes(sort)) {
+    return json({ error: `Invalid sort: ${sort}. Valid sorts: ${MARKET_SORTS.join(", ")}` }, 400);
+  }
+  const cursorParam = url.searchParams.get("cursor");
+  const cursor = cursorParam ? decodeMarketCursor(cursorParam) : undefined;
+  if (cursor === null) {
+    return json({ error: "Invalid cursor" }, 400);
+  }
+  const paged = { ...options, sort, cursor };
+
+  // If include_snapshot=true, also return CDC sync metadata (snapshot_time,
+  // snapshot_lsn); the page is then read from Postgres, never the cache
+  const includeSnapshot = url.searchParams.get("include_snapshot") === "true";
+  const cached = includeSnapshot ? undefined : ctx.marketCache?.list(paged);
+  let snapshot: MarketsWithSnapshot | undefined;
+  let markets: Market[];
+  let total: number;
+  if (cached) {
+    [markets, total] = [cached, ctx.marketCache!.count(options)!];
+  } else if (includeSnapshot) {
+    [snapshot, total] = await Promise.all([
+      timedQuery("list_markets", () => listMarketsWithSnapshot(ctx.db, paged)),
+      timedQuery("count_markets", () => countMarkets(ctx.db, options)),
+    ]);
+    markets = snapshot.markets;
+  } else {
+    [markets, total] = await Promise.all([
+      timedQuery("list_markets", () => listMarkets(ctx.db, paged)),
+      timedQuery("count_markets", () => countMarkets(ctx.db, options)),
+    ]);
+  }
+  // A full page may have more after it
+  const limit = options.limit ?? 100;
+  const last = markets[markets.length - 1];
+  const nextCursor = markets.length === limit
+    ? await marketCursorAfter(ctx.db, sort, last.ticker, last.exchange)
+    : null;
+
+  const response = json({
+    markets: markets.map(toMarketResponse),
+    next_cursor: nextCursor,
+    ...(snapshot && { snapshot_time: snapshot.snapshotTime, snapshot_lsn: snapshot.snapshotLsn }),
+  });
+  response.headers.set("X-Total-Count", String(total));
+  return response;
+}
+
+route("GET", "/v1/markets", async (req, ctx) => {
+  return await handleListMarkets(new URL(req.url), ctx);
 }, true, "secmaster:read", "public");
 
 // Cross-feed market lookup by IDs (Kalshi tickers, Kraken pair_ids, Polymarket condition/token IDs)
@@ -324,11 +509,17 @@ route("GET", "/v1/markets/lookup", async (req, ctx) => {
 
 route("GET", "/v1/markets/:ticker", async (req, ctx) => {
   const params = (req as Request & { params: Record<string, string> }).params;
-  const market = await getMarket(ctx.db, params.ticker);
+  const url = new URL(req.url);
+  const exchange = exchangeParam(url);
+  if (exchange === null) {
+    return json({ error: `Invalid exchange: ${url.searchParams.get("exchange")}` }, 400);
+  }
+  const market = ctx.marketCache?.get(params.ticker, exchange) ??
+    await timedQuery("get_market", () => getMarket(ctx.db, params.ticker, exchange));
   if (!market) {
     return json({ error: "Market not found" }, 404);
   }
-  return json(market);
+  return json(toMarketResponse(market));
 }, true, "secmaster:read", "public");
 
 // Secmaster stats endpoint (combined events + markets + pairs + conditions)
@@ -367,6 +558,22 @@ route("GET", "/v1/secmaster/markets/active-by-category", async (req, ctx) => {
   return json({ timeseries });
 }, true, "secmaster:read", "public");
 
+// Per-exchange secmaster: same filters as /v1/events and /v1/markets, pinned to one exchange
+route("GET", "/v1/secmaster/:exchange/events", async (req, ctx) => {
+  const params = (req as Request & { params: Record<string, string> }).params;
+  const url = new URL(req.url);
+  url.searchParams.set("exchange", params.exchange);
+  return await handleListEvents(url, ctx);
+}, true, "secmaster:read", "public");
+
+route("GET", "/v1/secmaster/:exchange/markets", async (req, ctx) => {
+  const params = (req as Request & { params: Record<string, string> }).params;
+  const url = new URL(req.url);
+  url.searchParams.set("exchange", params.exchange);
+  url.searchParams.delete("feed");
+  return await handleListMarkets(url, ctx);
+}, true, "secmaster:read", "public");
+
 // Lifecycle search — query markets by status across all exchanges
 route("GET", "/v1/secmaster/lifecycle", async (req, ctx) => {
   const url = new URL(req.url);
@@ -386,7 +593,7 @@ route("GET", "/v1/secmaster/lifecycle", async (req, ctx) => {
 
   // Query Kalshi markets
   if (!feed || feed === "kalshi") {
-    const conds = [isNull(markets.deletedAt)];
+    const conds = [eq(markets.exchange, DEFAULT_EXCHANGE), isNull(markets.deletedAt)];
     if (status) conds.push(eq(markets.status, status));
     if (since) conds.push(gte(markets.updatedAt, new Date(since)));
 
@@ -462,6 +669,8 @@ route("GET", "/v1/secmaster/lifecycle", async (req, ctx) => {
 // Series endpoints
 route("GET", "/v1/series", async (req, ctx) => {
   const url = new URL(req.url);
+  const badCategory = await checkCategory(url);
+  if (badCategory) return badCategory;
   const series = await listSeries({
     category: url.searchParams.get("category") ?? undefined,
     tag: url.searchParams.get("tag") ?? undefined,
@@ -476,6 +685,20 @@ route("GET", "/v1/series/stats", async (_req, _ctx) => {
   return json({ stats });
 }, true, "secmaster:read", "public");
 
+route("GET", "/v1/series/:ticker", async (req, _ctx) => {
+  const params = (req as Request & { params: Record<string, string> }).params;
+  const found = await getSeries(params.ticker);
+  if (!found) {
+    return json({ error: "Series not found" }, 404);
+  }
+  return json(found);
+}, true, "secmaster:read", "public");
+
+route("GET", "/v1/categories", async (_req, _ctx) => {
+  const categories = await listCategories();
+  return json({ categories });
+}, true, "secmaster:read", "public");
+
 // Pairs endpoints
 route("GET", "/v1/pairs", async (req, ctx) => {
   const url = new URL(req.url);
@@ -590,6 +813,23 @@ route("GET", "/v1/fees/:series", async (req, ctx) => {
   return json(fee);
 }, true, "secmaster:read", "public");
 
+route("GET", "/v1/fees/:series/history", async (req, ctx) => {
+  const params = (req as Request & { params: Record<string, string> }).params;
+  const fees = await listFeeHistory(ctx.db, params.series);
+  return json({ series_ticker: params.series, fees });
+}, true, "secmaster:read", "public");
+
+// Manual fee override; closes the period in force at effective_from
+route("PUT", "/v1/fees/:series", async (req, ctx) => {
+  const params = (req as Request & { params: Record<string, string> }).params;
+  const parsed = FeeUpdateSchema.safeParse(await req.json().catch(() => null));
+  if (!parsed.success) {
+    return json({ error: parsed.error.issues.map((i) => `${i.path.join(".") || "body"}: ${i.message}`).join("; ") }, 400);
+  }
+  const fee = await setFee(ctx.db, params.series, parsed.data);
+  return json(fee);
+}, true, "admin:write");
+
 // Health check endpoints
 route("GET", "/v1/health/daily", async (req, ctx) => {
   const url = new URL(req.url);
@@ -992,6 +1232,101 @@ route("GET", "/v1/keys/requests", async () => {
   return json({ sincePodStart: true, keys });
 }, true, "admin:read");
 
+// Structured access audit (requires ACCESS_AUDIT_DB=true to be populated)
+route("GET", "/v1/admin/audit", async (req, ctx) => {
+  const url = new URL(req.url);
+  const since = url.searchParams.get("since");
+  const until = url.searchParams.get("until");
+  const limit = Number(url.searchParams.get("limit") ?? "100");
+  if (!Number.isInteger(limit) || limit < 1 || limit > 1000) {
+    return json({ error: "limit must be an integer between 1 and 1000" }, 400);
+  }
+  const sinceDate = since ? new Date(since) : undefined;
+  const untilDate = until ? new Date(until) : undefined;
+  if ((sinceDate && isNaN(sinceDate.getTime())) || (untilDate && isNaN(untilDate.getTime()))) {
+    return json({ error: "since and until must be ISO 8601 timestamps" }, 400);
+  }
+
+  const entries = await listAccessAudit(ctx.db, {
+    keyPrefix: url.searchParams.get("key_prefix") ?? undefined,
+    dataset: url.searchParams.get("feed") ?? undefined,
+    route: url.searchParams.get("route") ?? undefined,
+    since: sinceDate,
+    until: untilDate,
+    limit,
+  });
+
+  return json({
+    entries: entries.map((e) => ({
+      id: e.id.toString(),
+      keyPrefix: e.keyPrefix,
+      userEmail: e.userEmail,
+      method: e.method,
+      route: e.route,
+      dataset: e.dataset,
+      statusCode: e.statusCode,
+      responseBytes: e.responseBytes,
+      latencyMs: e.latencyMs,
+      createdAt: e.createdAt,
+    })),
+  });
+}, true, "admin:read");
+
+// Drop cached dataset listings and manifests (all, or one feed and/or date)
+// after a backfill or re-run, instead of waiting out DATASET_CACHE_TTL_SECONDS.
+route("POST", "/v1/admin/cache/invalidate", async (req) => {
+  const url = new URL(req.url);
+  const feed = url.searchParams.get("feed") ?? undefined;
+  const date = url.searchParams.get("date") ?? undefined;
+  if (feed !== undefined && !FEED_CONFIG[feed]) {
+    return json({ error: `Invalid feed: ${feed}. Valid feeds: ${Object.keys(FEED_CONFIG).join(", ")}` }, 400);
+  }
+  if (date !== undefined && !/^\d{4}-\d{2}-\d{2}$/.test(date)) {
+    return json({ error: "date must be YYYY-MM-DD format" }, 400);
+  }
+  return json({ feed: feed ?? null, date: date ?? null, invalidated: invalidateDatasetCache(feed, date) });
+}, true, "admin:write");
+
+// Pre-load manifests and file listings for feeds/dates (default: every feed,
+// last 7 days) ahead of the morning query load.
+route("POST", "/v1/admin/cache/prime", async (req) => {
+  const bucket = Deno.env.get("GCS_BUCKET");
+  if (!bucket) {
+    return json({ error: "GCS_BUCKET not configured" }, 503);
+  }
+  const body = await req.json().catch(() => ({})) as { feeds?: unknown; dates?: unknown; days?: number };
+  const isStringArray = (v: unknown): v is string[] => Array.isArray(v) && v.every((s) => typeof s === "string");
+  if (body.feeds !== undefined && !isStringArray(body.feeds)) {
+    return json({ error: "feeds must be an array of strings" }, 400);
+  }
+  if (body.dates !== undefined && !isStringArray(body.dates)) {
+    return json({ error: "dates must be an array of strings" }, 400);
+  }
+
+  const feeds = body.feeds ?? Object.keys(FEED_CONFIG);
+  const unknown = feeds.filter((f) => !FEED_CONFIG[f]);
+  if (unknown.length > 0) {
+    return json({ error: `Invalid feed: ${unknown.join(", ")}. Valid feeds: ${Object.keys(FEED_CONFIG).join(", ")}` }, 400);
+  }
+  if (body.dates !== undefined && body.days !== undefined) {
+    return json({ error: "Pass either dates or days, not both" }, 400);
+  }
+  const days = body.days ?? 7;
+  if (!Number.isInteger(days) || days < 1 || days > 31) {
+    return json({ error: "days must be an integer between 1 and 31" }, 400);
+  }
+  if (body.dates !== undefined && body.dates.length > 31) {
+    return json({ error: "dates must list at most 31 dates" }, 400);
+  }
+  const dates = body.dates ?? recentDates(days);
+  if (dates.some((d) => !/^\d{4}-\d{2}-\d{2}$/.test(d))) {
+    return json({ error: "dates must be YYYY-MM-DD format" }, 400);
+  }
+
+  const result = await primeDatasetCache(bucket, feeds, dates);
+  return json({ feeds, dates, ...result });
+}, true, "admin:write");
+
 // Auth validation endpoint
 route("GET", "/v1/auth/validate", (req, _ctx) => {
   const auth = (req as Request & { auth: AuthInfo }).auth;
@@ -1041,7 +1376,8 @@ function endpointTier(_method: string, path: string): string {
   if (path.startsWith("/v1/markets/lookup")) return "market_lookup";
   if (path.startsWith("/v1/secmaster/")) return "secmaster";
   if (path.startsWith("/v1/events") || path.startsWith("/v1/markets") || path.startsWith("/v1/series") ||
-      path.startsWith("/v1/pairs") || path.startsWith("/v1/conditions") || path.startsWith("/v1/fees")) return "secmaster";
+      path.startsWith("/v1/pairs") || path.startsWith("/v1/conditions") || path.startsWith("/v1/fees") ||
+      path.startsWith("/v1/categories")) return "secmaster";
   if (path.startsWith("/v1/chat/completions")) return "llm_chat";
   return "data_query";
 }
@@ -1571,7 +1907,7 @@ route("GET", "/v1/data/download", async (req, ctx) => {
   }
 
   // List and sign files
-  const files = await listParquetFiles(bucket, feed, effectiveFrom, effectiveTo, msgType);
+  const files = await listRangeFiles(bucket, feed, effectiveFrom, effectiveTo, msgType);
 
   if (files.length > 200) {
     return json({ error: `Too many files (${files.length}). Maximum 200 per request. Narrow your date range or filter by type.` }, 400);
@@ -1687,27 +2023,51 @@ route("GET", "/v1/data/catalog", async (req) => {
       messageTypes: feedSummary.message_types,
     }));
 
+    const from = fromParam ?? feedSummary.date_min;
+    const to = toParam ?? feedSummary.date_max;
     return json({
       feed: feedParam,
-      from: fromParam ?? feedSummary.date_min,
-      to: toParam ?? feedSummary.date_max,
+      from,
+      to,
       dates: dateEntries,
+      // Gaps only within the feed's own history, not before its first date
+      gapDates: feedSummary.dates.length > 0
+        ? missingDates(
+          dates,
+          from > feedSummary.date_min ? from : feedSummary.date_min,
+          to < feedSummary.date_max ? to : feedSummary.date_max,
+        )
+        : [],
     });
   }
 
-  // Overview: all authorized feeds
-  const feedOverviews = allowedFeeds.map((f) => ({
+  // Overview: all authorized feeds, with roll-ups so dashboards need no per-date reads
+  const stats = catalogStats(allowedFeeds);
+  const feedOverviews = allowedFeeds.map((f, i) => ({
     feed: f.feed,
     stream: f.stream,
     messageTypes: f.message_types,
     dateMin: f.date_min,
     dateMax: f.date_max,
+    dateCount: stats.feeds[i].date_count,
+    gapDays: stats.feeds[i].gap_dates.length,
+    gapDates: stats.feeds[i].gap_dates,
     totalFiles: f.total_files,
+    totalBytes: f.total_bytes,
     totalRows: f.total_rows,
   }));
 
   return json({
     feeds: feedOverviews,
+    totals: {
+      feeds: feedOverviews.length,
+      dateMin: stats.date_min,
+      dateMax: stats.date_max,
+      totalFiles: stats.total_files,
+      totalBytes: stats.total_bytes,
+      totalRows: stats.total_rows,
+      gapDays: stats.gap_days,
+    },
     catalogGeneratedAt: catalog.generated_at,
   });
 }, true, "datasets:read", "public");
@@ -1750,16 +2110,24 @@ route("GET", "/v1/data/day", async (req) => {
   // should fail the whole request loudly rather than silently return partial data.
   const feeds = await Promise.all(
     feedNames.map(async (feed) => {
-      const files = await listParquetFiles(bucket, feed, date, date);
+      const { stream, prefix } = FEED_CONFIG[feed];
+      const [listed, manifest] = await Promise.all([
+        listDateFiles(bucket, feed, date),
+        getDateManifest(bucket, feed, stream, prefix, date),
+      ]);
+      const files = [...listed];
       files.sort((a, b) => a.name.localeCompare(b.name));
       const totalBytes = files.reduce((sum, f) => sum + f.bytes, 0);
+      // Row counts come from parquet-manifest.json; files it does not list have none
+      const rows = new Map((manifest?.files ?? []).map((f) => [f.path, f.row_count]));
       return {
         feed,
-        stream: FEED_CONFIG[feed].stream,
+        stream,
         description: feedDescription(feed),
         fileCount: files.length,
         totalBytes,
-        files: files.map((f) => ({ name: f.name, type: f.type, hour: f.hour, bytes: f.bytes })),
+        totalRows: manifest?.files ? files.reduce((sum, f) => sum + (rows.get(f.path) ?? 0), 0) : null,
+        files: files.map((f) => ({ name: f.name, type: f.type, hour: f.hour, bytes: f.bytes, rows: rows.get(f.path) ?? null })),
       };
     }),
   );
@@ -1767,6 +2135,160 @@ route("GET", "/v1/data/day", async (req) => {
   return json({ date, feeds });
 }, true, "datasets:read", "public");
 
+// Archive integrity check for one feed and date (same as `ssmd data verify`):
+// recomputes each JSONL.gz file's records, size and SHA-256 against
+// manifest.json. ?repair=true writes the recomputed manifest (admin:write;
+// add dry_run=true to only report what would change).
+route("POST", "/datasets/:feed/:date/verify", async (req) => {
+  const auth = (req as Request & { auth: AuthInfo }).auth;
+  const params = (req as Request & { params: Record<string, string> }).params;
+  const url = new URL(req.url);
+  if (!FEED_PATHS[params.feed]) {
+    return json({ error: `Invalid feed: ${params.feed}. Valid feeds: ${VALID_DATA_FEEDS.join(", ")}` }, 400);
+  }
+  if (!/^\d{4}-\d{2}-\d{2}$/.test(params.date)) {
+    return json({ error: "date must be YYYY-MM-DD format" }, 400);
+  }
+  const repair = url.searchParams.get("repair") === "true";
+  const dryRun = url.searchParams.get("dry_run") === "true";
+  if (repair && !dryRun && !hasScope(auth.scopes, "admin:write")) {
+    return json({ error: "repair requires admin:write" }, 403);
+  }
+
+  const bucket = Deno.env.get("GCS_BUCKET");
+  if (!bucket) {
+    return json({ error: "GCS_BUCKET not configured" }, 503);
+  }
+
+  const report = await verifyArchive(new Storage(), bucket, params.feed, params.date, {
+    repair,
+    dryRun,
+    keys: dedupKeys(params.feed),
+  });
+  if (!report) {
+    return json({ error: `No manifest.json for ${params.feed}/${params.date}` }, 404);
+  }
+  return json(report);
+}, true, "admin:read");
+
+/** Lifetime of the signed URL a single-file download redirects to */
+const FILE_URL_TTL_SECONDS = 15 * 60;
+
+/**
+ * Check a /datasets/:feed/:date request against the key's feed allowlist and
+ * date range. Returns an error response, or null when the request may proceed.
+ */
+function checkDatasetAccess(auth: AuthInfo, feed: string, date: string): Response | null {
+  if (!FEED_CONFIG[feed]) {
+    return json({ error: `Invalid feed: ${feed}. Valid feeds: ${Object.keys(FEED_CONFIG).join(", ")}` }, 400);
+  }
+  if (!/^\d{4}-\d{2}-\d{2}$/.test(date)) {
+    return json({ error: "date must be YYYY-MM-DD format" }, 400);
+  }
+  if (!feedAllowed(auth.allowedFeeds, feed)) {
+    return json({ error: `Key not authorized for feed: ${feed}` }, 403);
+  }
+  // '*' keys are unrestricted; dates are YYYY-MM-DD so lexical comparison is correct
+  if (!auth.allowedFeeds.includes("*") && (date < auth.dateRangeStart || date > auth.dateRangeEnd)) {
+    return json({
+      error: `Date ${date} is outside key range ${auth.dateRangeStart} to ${auth.dateRangeEnd}`,
+    }, 403);
+  }
+  return null;
+}
+
+/** ?kind= values of the file listing: parquet/CSV outputs, raw archiver JSONL.gz, or both */
+const DATASET_FILE_KINDS = ["parquet", "raw", "all"];
+
+// Raw file listing for one feed and date (metadata only, no signing).
+// ?kind=raw lists the archiver's JSONL.gz files instead of the parquet/CSV
+// outputs, ?kind=all both.
+route("GET", "/datasets/:feed/:date/files", async (req) => {
+  const auth = (req as Request & { auth: AuthInfo }).auth;
+  const params = (req as Request & { params: Record<string, string> }).params;
+  const denied = checkDatasetAccess(auth, params.feed, params.date);
+  if (denied) return denied;
+  const kind = new URL(req.url).searchParams.get("kind") ?? "parquet";
+  if (!DATASET_FILE_KINDS.includes(kind)) {
+    return json({ error: `Invalid kind: ${kind}. Valid kinds: ${DATASET_FILE_KINDS.join(", ")}` }, 400);
+  }
+
+  const bucket = Deno.env.get("GCS_BUCKET");
+  if (!bucket) {
+    return json({ error: "GCS_BUCKET not configured" }, 503);
+  }
+
+  const [parquet, raw] = await Promise.all([
+    kind === "raw" ? [] : listDateFiles(bucket, params.feed, params.date),
+    kind === "parquet" ? [] : listDateRawFiles(bucket, params.feed, params.date),
+  ]);
+  const files = [...parquet, ...raw];
+  files.sort((a, b) => a.name.localeCompare(b.name));
+  return json({
+    feed: params.feed,
+    date: params.date,
+    files: files.map((f) => ({ name: f.name, type: f.type, hour: f.hour, bytes: f.bytes })),
+  });
+}, true, "datasets:read", "public");
+
+// Raw file download. Keys with datasets:download are redirected to a
+// short-lived signed URL so the bytes come straight from GCS; other keys get
+// the bytes proxied through the API. ?redirect=false returns the signed URL
+// as JSON instead of a 302.
+route("GET", "/datasets/:feed/:date/files/:name", async (req, ctx) => {
+  const auth = (req as Request & { auth: AuthInfo }).auth;
+  const params = (req as Request & { params: Record<string, string> }).params;
+  const url = new URL(req.url);
+  const denied = checkDatasetAccess(auth, params.feed, params.date);
+  if (denied) return denied;
+
+  const bucket = Deno.env.get("GCS_BUCKET");
+  if (!bucket) {
+    return json({ error: "GCS_BUCKET not configured" }, 503);
+  }
+
+  const name = decodeURIComponent(params.name);
+  const file = await findParquetFile(bucket, params.feed, params.date, name);
+  if (!file) {
+    return json({ error: `File not found: ${params.feed}/${params.date}/${name}` }, 404);
+  }
+
+  // Audit log (fire-and-forget)
+  logDataAccess(ctx.db, {
+    keyPrefix: auth.keyPrefix,
+    userEmail: auth.userEmail,
+    feed: params.feed,
+    dateFrom: params.date,
+    dateTo: params.date,
+    msgType: file.type,
+    filesCount: 1,
+  }).catch((err) => console.error("Failed to log data access:", err));
+
+  if (hasScope(auth.scopes, "datasets:download")) {
+    const signed = await generateSignedUrl(bucket, file, FILE_URL_TTL_SECONDS);
+    if (url.searchParams.get("redirect") === "false") {
+      return json({ feed: params.feed, date: params.date, file: signed });
+    }
+    return new Response(null, {
+      status: 302,
+      headers: { "Location": signed.signedUrl, "Cache-Control": "no-store" },
+    });
+  }
+
+  const contentType = file.name.endsWith(".csv")
+    ? "text/csv"
+    : file.name.endsWith(".jsonl.gz")
+    ? "application/gzip"
+    : "application/vnd.apache.parquet";
+  return new Response(readFileStream(bucket, file), {
+    headers: {
+      "Content-Type": contentType,
+      "Content-Length": String(file.bytes),
+      "Content-Disposition": `attachment; filename="${downloadName(file)}"`,
+    },
+  });
+}, true, "datasets:read", "public");
+
 // Schema versions endpoint — static JSON mirroring Rust MessageSchema::schema_version()
 route("GET", "/v1/data/schema-versions", async () => {
   const { default: schemaVersions } = await import("./schema-versions.json", { with: { type: "json" } });
@@ -1860,6 +2382,128 @@ route("GET", "/v1/data/trades", async (req) => {
   }
 }, true, "datasets:read", "public");
 
+// Ad-hoc SQL over one day's files. DuckDB can read arbitrary paths and URLs
+// (including the server's own files), so this is limited to admin:write keys.
+const MAX_QUERY_ROWS = 10_000;
+
+route("POST", "/v1/data/query", async (req) => {
+  // deno-lint-ignore no-explicit-any
+  let body: any;
+  try {
+    body = await req.json();
+  } catch {
+    return json({ error: "Invalid JSON in request body" }, 400);
+  }
+
+  const feed = body?.feed;
+  if (typeof feed !== "string" || !VALID_DATA_FEEDS.includes(feed)) {
+    return json({ error: `Invalid or missing feed. Valid: ${VALID_DATA_FEEDS.join(", ")}` }, 400);
+  }
+  const date = body?.date;
+  if (typeof date !== "string" || !/^\d{4}-\d{2}-\d{2}$/.test(date)) {
+    return json({ error: "date must be YYYY-MM-DD format" }, 400);
+  }
+  if (typeof body?.sql !== "string") {
+    return json({ error: "sql must be a string" }, 400);
+  }
+  const limit = Math.min(Math.max(parseInt(String(body?.limit ?? 1000), 10) || 1000, 1), MAX_QUERY_ROWS);
+
+  const bucket = Deno.env.get("GCS_BUCKET");
+  if (!bucket) return json({ error: "GCS_BUCKET not configured" }, 503);
+
+  let sql: string;
+  try {
+    sql = buildDatasetSQL(bucket, feed, date, body.sql, limit);
+  } catch (err) {
+    return json({ error: (err as Error).message, tables: Object.keys(datasetTables(bucket, feed, date)) }, 400);
+  }
+
+  try {
+    const result = await duckdbQuery(sql);
+    return json({ feed, date, columns: result.columns, count: result.rows.length, rows: result.rows });
+  } catch (err) {
+    // DuckDB binder/parser errors are the caller's SQL, not a server fault
+    return json({ error: `Query failed: ${(err as Error).message}` }, 400);
+  }
+}, true, "admin:write");
+
+/** Most archived lines one /v1/data/sample request returns */
+const MAX_SAMPLE_LINES = 10000;
+
+route("GET", "/v1/data/sample", async (req) => {
+  const auth = (req as Request & { auth: AuthInfo }).auth;
+  const url = new URL(req.url);
+
+  const feed = url.searchParams.get("feed");
+  if (!feed || !VALID_DATA_FEEDS.includes(feed)) {
+    return json({ error: `Invalid or missing feed. Valid: ${VALID_DATA_FEEDS.join(", ")}` }, 400);
+  }
+
+  if (!feedAllowed(auth.allowedFeeds, feed)) {
+    return json({ error: `Key not authorized for feed: ${feed}` }, 403);
+  }
+
+  const tickers = (url.searchParams.get("ticker") ?? "").split(",").map((t) => t.trim()).filter(Boolean);
+  const filterParam = url.searchParams.get("filter");
+  if (tickers.length === 0 && !filterParam) {
+    return json({ error: "ticker or filter is required" }, 400);
+  }
+  let where: RecordFilter | undefined;
+  if (filterParam) {
+    try {
+      where = compileFilter(filterParam);
+    } catch (err) {
+      if (err instanceof FilterError) return json({ error: `Invalid filter: ${err.message}` }, 400);
+      throw err;
+    }
+  }
+
+  const date = url.searchParams.get("date") ?? undefined;
+  if (date !== undefined && !/^\d{4}-\d{2}-\d{2}$/.test(date)) {
+    return json({ error: "date must be YYYY-MM-DD format" }, 400);
+  }
+
+  let range: SampleRange;
+  try {
+    range = sampleRange(date, url.searchParams.get("from") ?? undefined, url.searchParams.get("to") ?? undefined);
+  } catch (err) {
+    return json({ error: (err as Error).message }, 400);
+  }
+
+  const first = range.dates[0];
+  const last = range.dates[range.dates.length - 1];
+  if (first < auth.dateRangeStart || last > auth.dateRangeEnd) {
+    return json({ error: `Dates ${first} to ${last} outside key range ${auth.dateRangeStart} to ${auth.dateRangeEnd}` }, 403);
+  }
+
+  const limitParam = url.searchParams.get("limit");
+  const limit = Math.min(Math.max(parseInt(limitParam ?? "100", 10) || 100, 1), MAX_SAMPLE_LINES);
+
+  const bucket = Deno.env.get("GCS_BUCKET");
+  if (!bucket) return json({ error: "GCS_BUCKET not configured" }, 503);
+
+  try {
+    const lines: string[] = [];
+    const sampled = sampleLines(new Storage(), bucket, feed, range.dates, {
+      tickers,
+      where,
+      fromMs: range.fromMs,
+      toMs: range.toMs,
+      requireManifest: date === undefined,
+    });
+    for await (const line of sampled) {
+      lines.push(line);
+      if (lines.length >= limit) break;
+    }
+    return new Response(lines.length > 0 ? lines.join("\n") + "\n" : "", {
+      headers: { "Content-Type": "application/x-ndjson" },
+    });
+  } catch (err) {
+    console.error("Sample failed:", err);
+    return json({ error: `Sample failed: ${(err as Error).message}` }, 500);
+  }
+}, true, "datasets:read", "public");
+
 route("GET", "/v1/data/prices", async (req) => {
   const auth = (req as Request & { auth: AuthInfo }).auth;
   const url = new URL(req.url);
@@ -1971,7 +2615,7 @@ route("GET", "/v1/data/events", async (req, ctx) => {
           strikeDate: events.strikeDate,
         })
         .from(events)
-        .where(and(inArray(events.eventTicker, eventIds), isNull(events.deletedAt)));
+        .where(and(eq(events.exchange, DEFAULT_EXCHANGE), inArray(events.eventTicker, eventIds), isNull(events.deletedAt)));
       for (const row of rows) {
         metadata[row.eventTicker] = {
           title: row.title,
@@ -2123,7 +2767,6 @@ route("GET", "/v1/data/freshness", async (req) => {
   const staleThresholdHours = 7;
   const results: Record<string, unknown>[] = [];
 
-  const { Storage } = await import("@google-cloud/storage");
   const storage = new Storage();
 
   for (const feed of feeds) {
@@ -2324,7 +2967,7 @@ route("GET", "/v1/data/snap", async (req) => {
     return json({ error: `Key not authorized for feed: ${feed}` }, 403);
   }
 
-  const redis = await getRedis();
+  const redis = await getSnapRedis();
   const tickersParam = url.searchParams.get("tickers");
 
   // deno-lint-ignore no-explicit-any
@@ -2340,15 +2983,15 @@ route("GET", "/v1/data/snap", async (req) => {
       return json({ error: "Maximum 500 tickers per request" }, 400);
     }
 
-    const keys = tickers.map((t) => `snap:${feed}:${t}`);
+    const keys = tickers.map((t) => snapKey(feed, t));
     const values = await redis.mget(...keys);
     for (let i = 0; i < keys.length; i++) {
       rawEntries.push({ key: tickers[i], value: values[i] ?? null });
     }
   } else {
     // SCAN for all keys matching this feed (limit 500)
-    const pattern = `snap:${feed}:*`;
-    const prefix = `snap:${feed}:`;
+    const prefix = snapKey(feed, "");
+    const pattern = `${prefix}*`;
     let cursor = 0;
     const seen = new Set<string>();
 
@@ -2373,28 +3016,12 @@ route("GET", "/v1/data/snap", async (req) => {
   }
 
   // Parse JSON values and convert Kalshi prices from cents to dollars
-  const isKalshi = feed === "kalshi";
-  const priceFields = ["yes_bid", "yes_ask", "no_bid", "no_ask", "last_price"];
-
   // deno-lint-ignore no-explicit-any
   const snapshots: any[] = [];
   for (const entry of rawEntries) {
     if (!entry.value) continue;
-    try {
-      const parsed = JSON.parse(entry.value);
-      if (isKalshi) {
-        for (const field of priceFields) {
-          if (typeof parsed[field] === "number") {
-            parsed[field] = parsed[field] / 100;
-          }
-        }
-      }
-      // Use the ticker from the key, not the payload, for consistency
-      parsed._ticker = entry.key;
-      snapshots.push(parsed);
-    } catch {
-      // Skip unparseable entries
-    }
+    const parsed = parseSnapshot(feed, entry.key, entry.value);
+    if (parsed) snapshots.push(parsed);
   }
 
   return json({
@@ -2404,6 +3031,142 @@ route("GET", "/v1/data/snap", async (req) => {
   });
 }, true, "datasets:read", "public");
 
+// Single live snapshot from Redis: "what is the current book" without NATS.
+// 404 when snap has no key (ticker never seen, or TTL lapsed).
+route("GET", "/v1/data/snap/:ticker", async (req, ctx) => {
+  const auth = (req as Request & { auth: AuthInfo }).auth;
+  const params = (req as Request & { params: Record<string, string> }).params;
+  const url = new URL(req.url);
+
+  const feed = url.searchParams.get("feed");
+  if (!feed || !VALID_DATA_FEEDS.includes(feed)) {
+    return json({ error: `Invalid or missing feed. Valid: ${VALID_DATA_FEEDS.join(", ")}` }, 400);
+  }
+  if (!feedAllowed(auth.allowedFeeds, feed)) {
+    return json({ error: `Key not authorized for feed: ${feed}` }, 403);
+  }
+
+  const ticker = decodeURIComponent(params.ticker);
+  let raw: string | null;
+  try {
+    const redis = ctx.redisOverride ?? (await getSnapRedis());
+    raw = await redis.get(snapKey(feed, ticker));
+  } catch (err) {
+    console.error(`[routes] snap redis error for ${feed}:${ticker}:`, err);
+    return json({ error: "snap cache unavailable" }, 503);
+  }
+  if (raw === null) {
+    return json({ error: `No snapshot for ${ticker} on ${feed} (not seen or expired)` }, 404);
+  }
+
+  const snapshot = parseSnapshot(feed, ticker, raw);
+  if (!snapshot) {
+    return json({ error: "Cached snapshot is not valid JSON" }, 502);
+  }
+  const snapAt = typeof snapshot._snap_at === "number" ? snapshot._snap_at : null;
+  return json({
+    feed,
+    ticker,
+    snap_at: snapAt !== null ? new Date(snapAt).toISOString() : null,
+    age_ms: snapAt !== null ? Math.max(0, Date.now() - snapAt) : null,
+    snapshot,
+  });
+}, true, "datasets:read", "public");
+
+// Order book as of a time from book_snapshots (book-snapshot-consumer).
+// ?feed=kalshi&at=<ISO datetime, default now>
+route("GET", "/v1/data/book/:ticker", async (req, ctx) => {
+  const auth = (req as Request & { auth: AuthInfo }).auth;
+  const params = (req as Request & { params: Record<string, string> }).params;
+  const url = new URL(req.url);
+
+  const feed = url.searchParams.get("feed");
+  if (!feed || !BOOK_FEEDS.includes(feed)) {
+    return json({ error: `Invalid or missing feed. Valid: ${BOOK_FEEDS.join(", ")}` }, 400);
+  }
+  if (!feedAllowed(auth.allowedFeeds, feed)) {
+    return json({ error: `Key not authorized for feed: ${feed}` }, 403);
+  }
+
+  const atParam = url.searchParams.get("at");
+  const at = atParam ? new Date(atParam) : new Date();
+  if (Number.isNaN(at.getTime())) {
+    return json({ error: "at must be an ISO datetime" }, 400);
+  }
+
+  const ticker = decodeURIComponent(params.ticker);
+  const book = await getBookAt(ctx.db, feed, ticker, at);
+  if (!book) {
+    return json({ error: `No book snapshot for ${ticker} on ${feed} at or before ${at.toISOString()}` }, 404);
+  }
+  return json({
+    feed,
+    ticker,
+    at: at.toISOString(),
+    snapshot_at: book.snapshotAt.toISOString(),
+    exchange_ts: book.exchangeTs?.toISOString() ?? null,
+    depth: book.depth,
+    bids: book.bids,
+    asks: book.asks,
+  });
+}, true, "datasets:read", "public");
+
+// Live tail — relay JSON messages from NATS as Server-Sent Events.
+// ?feed=kalshi&tickers=A,B&types=trade,ticker&max_rate=100 (messages/sec, excess dropped)
+const LIVE_TAIL_FEEDS = Object.keys(LIVE_TAIL_PREFIXES);
+const MAX_TAIL_RATE = 1000;
+
+route("GET", "/v1/data/stream", async (req) => {
+  const auth = (req as Request & { auth: AuthInfo }).auth;
+  const url = new URL(req.url);
+
+  const feed = url.searchParams.get("feed");
+  if (!feed || !LIVE_TAIL_FEEDS.includes(feed)) {
+    return json({ error: `Invalid or missing feed. Valid: ${LIVE_TAIL_FEEDS.join(", ")}` }, 400);
+  }
+  if (!feedAllowed(auth.allowedFeeds, feed)) {
+    return json({ error: `Key not authorized for feed: ${feed}` }, 403);
+  }
+
+  const splitParam = (name: string) =>
+    (url.searchParams.get(name) ?? "").split(",").map((t) => t.trim()).filter(Boolean);
+  const tickers = splitParam("tickers").map(sanitizeToken).filter(Boolean);
+  if (tickers.length > MAX_TAIL_TICKERS) {
+    return json({ error: `Maximum ${MAX_TAIL_TICKERS} tickers per stream` }, 400);
+  }
+  const maxRate = parseInt(url.searchParams.get("max_rate") ?? "100");
+  if (isNaN(maxRate) || maxRate < 1 || maxRate > MAX_TAIL_RATE) {
+    return json({ error: `max_rate must be between 1 and ${MAX_TAIL_RATE}` }, 400);
+  }
+  let where: RecordFilter | undefined;
+  const filterParam = url.searchParams.get("filter");
+  if (filterParam) {
+    try {
+      where = compileFilter(filterParam);
+    } catch (err) {
+      if (err instanceof FilterError) return json({ error: `Invalid filter: ${err.message}` }, 400);
+      throw err;
+    }
+  }
+
+  let nc;
+  try {
+    nc = await getNats();
+  } catch (err) {
+    return json({ error: `NATS unavailable: ${err instanceof Error ? err.message : String(err)}` }, 503);
+  }
+
+  const filter = { types: new Set(splitParam("types")), tickers: new Set(tickers), where };
+  const body = liveTailStream(nc, LIVE_TAIL_PREFIXES[feed], filter, maxRate, req.signal);
+  return new Response(body, {
+    headers: {
+      "Content-Type": "text/event-stream",
+      "Cache-Control": "no-cache",
+      "X-Accel-Buffering": "no",
+    },
+  });
+}, true, "datasets:read", "public");
+
 // 1-minute OHLCV bars from Redis (populated by ssmd-bar-cache).
 // Key layout: `ohlcv_1m:{feed}:{sym}` → JSON array of the last ~60 bars, oldest→newest.
 const BAR_CACHE_FEEDS = ["kraken-spot", "binance"] as const;
@@ -2722,8 +3485,9 @@ route("GET", "/v1/internal/canary-markets", async (req) => {
   const eventRows = await sql`
     SELECT e.event_ticker
     FROM events e
-    JOIN markets m ON m.event_ticker = e.event_ticker
-    WHERE e.series_ticker = ${series}
+    JOIN markets m ON m.exchange = e.exchange AND m.event_ticker = e.event_ticker
+    WHERE e.exchange = 'kalshi'
+      AND e.series_ticker = ${series}
       AND e.deleted_at IS NULL
       AND m.deleted_at IS NULL
       AND m.status IN ('active', 'open')
@@ -4835,6 +5599,20 @@ function buildHandler(
     requestLogger.start();
   }
 
+  // Access audit: stdout always, Postgres when ACCESS_AUDIT_DB=true
+  let auditLogger: AccessAuditBuffer | null = null;
+  if (Deno.env.get("ACCESS_AUDIT_DB") === "true" && ctx.db && typeof ctx.db.insert === "function") {
+    auditLogger = new AccessAuditBuffer(ctx.db);
+    auditLogger.start();
+  }
+  const recordAccess = (entry: AccessAuditEntry) => {
+    console.log(accessAuditLine(entry));
+    auditLogger?.push(entry);
+  };
+
+  // Per-route token buckets on top of the per-key tier limit
+  const routeLimiter = new RouteRateLimiter(ctx.routeLimits ?? loadRouteLimits());
+
   return async (req: Request) => {
     try {
     const url = new URL(req.url);
@@ -4864,6 +5642,16 @@ function buildHandler(
             headers["X-RateLimit-Reset"] = authResult.rateLimitResetAt!.toString();
           }
 
+          if (authResult.status === 429) {
+            const keyPrefix = authResult.keyPrefix ?? "unknown";
+            apiRateLimitHitsTotal.inc({ key_prefix: keyPrefix });
+            apiThrottledTotal.inc({ key_prefix: keyPrefix, path: r.pattern.pathname, limit: "key" });
+            if (authResult.rateLimitResetAt !== undefined) {
+              const seconds = Math.ceil((authResult.rateLimitResetAt - Date.now()) / 1000);
+              headers["Retry-After"] = Math.max(1, seconds).toString();
+            }
+          }
+
           return new Response(
             JSON.stringify({ error: authResult.error }),
             { status: authResult.status!, headers }
@@ -4906,6 +5694,24 @@ function buildHandler(
           return json({ error: "Insufficient permissions" }, 403);
         }
 
+        // Per-route limit, keyed on the (possibly proxied) caller
+        if (routeLimiter.enabled) {
+          const throttled = routeLimiter.take(authResult.keyPrefix!, r.pattern.pathname);
+          if (throttled) {
+            apiThrottledTotal.inc({ key_prefix: authResult.keyPrefix!, path: r.pattern.pathname, limit: "route" });
+            return new Response(
+              JSON.stringify({ error: "Rate limit exceeded for this endpoint" }),
+              {
+                status: 429,
+                headers: {
+                  "Content-Type": "application/json",
+                  "Retry-After": throttled.retryAfterSeconds.toString(),
+                },
+              }
+            );
+          }
+        }
+
         // Attach auth info to request for handlers that need it
         Object.defineProperty(req, "auth", {
           value: {
@@ -4925,6 +5731,7 @@ function buildHandler(
       const params = match.pathname.groups;
       Object.defineProperty(req, "params", { value: params });
 
+      const started = performance.now();
       let response: Response;
       try {
         response = await r.handler(req, ctx);
@@ -4932,6 +5739,7 @@ function buildHandler(
         console.error(`[routes] handler error: ${req.method} ${url.pathname}:`, err);
         response = json({ error: "Internal server error" }, 500);
       }
+      const latencyMs = Math.round(performance.now() - started);
 
       // Track per-key API usage in Prometheus (GMP scrapes this)
       if (r.requiresAuth && (req as Request & { auth?: AuthInfo }).auth) {
@@ -4954,6 +5762,22 @@ function buildHandler(
             responseBytes: null,
           });
         }
+
+        // Audit record is written once the body has been sent, so bytes are known
+        const status = response.status;
+        const dataset = url.searchParams.get("feed") ?? params?.feed ?? null;
+        response = countResponseBytes(response, (bytes) =>
+          recordAccess({
+            keyPrefix: auth.keyPrefix,
+            userEmail: auth.userEmail ?? null,
+            method: req.method,
+            route: path,
+            dataset,
+            statusCode: status,
+            responseBytes: bytes,
+            latencyMs,
+          })
+        );
       }
 
       // Add rate limit headers to authenticated responses
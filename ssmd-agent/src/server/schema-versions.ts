@@ -0,0 +1,24 @@
+/**
+ * Raw message schema versions per feed and type, as served by
+ * GET /v1/data/schema-versions. A type's dedup_key lists the field paths that
+ * identify one message (`[]` steps into every element of an array), so the
+ * archiver config, dedup and the quality report agree on what a duplicate is.
+ */
+import versions from "./schema-versions.json" with { type: "json" };
+
+export interface SchemaVersion {
+  version: string;
+  notes?: string;
+  dedup_key?: string[];
+}
+
+export const SCHEMA_VERSIONS: Record<string, Record<string, SchemaVersion>> = versions;
+
+/** A feed's dedup_key field paths by message type, for the types that declare one */
+export function dedupKeys(feed: string): Record<string, string[]> {
+  const keys: Record<string, string[]> = {};
+  for (const [type, schema] of Object.entries(SCHEMA_VERSIONS[feed] ?? {})) {
+    if (schema.dedup_key?.length) keys[type] = schema.dedup_key;
+  }
+  return keys;
+}
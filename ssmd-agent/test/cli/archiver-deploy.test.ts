@@ -0,0 +1,14 @@
+// test/cli/archiver-deploy.test.ts
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { dedupKeysYaml } from "../../src/cli/commands/archiver-deploy.ts";
+
+Deno.test("dedupKeysYaml renders the registry's dedup_key per type", () => {
+  assertEquals(
+    dedupKeysYaml("kraken-spot"),
+    '  dedupKeys:\n    trade: ["data[].symbol", "data[].trade_id"]\n',
+  );
+});
+
+Deno.test("dedupKeysYaml is empty for feeds without a dedup_key", () => {
+  assertEquals(dedupKeysYaml("polymarket"), "");
+});
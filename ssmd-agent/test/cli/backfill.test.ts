@@ -0,0 +1,79 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  archiveSlot,
+  buildManifest,
+  backfillDates,
+  mergeArchiveLines,
+  parseArchiveLine,
+  tradeToArchiveLine,
+} from "../../src/cli/commands/backfill.ts";
+import { toReplayRecord } from "../../src/lib/archive/mod.ts";
+
+const trade = {
+  trade_id: "t-1",
+  ticker: "KXBTCD-25JAN1017-T95000",
+  yes_price: 41,
+  no_price: 59,
+  count: 10,
+  taker_side: "yes",
+  created_time: "2025-01-10T16:07:30.250Z",
+};
+
+Deno.test("backfillDates is inclusive and bounded", () => {
+  assertEquals(backfillDates("2025-01-30", "2025-02-01"), ["2025-01-30", "2025-01-31", "2025-02-01"]);
+  assertThrows(() => backfillDates("2025-02-01", "2025-01-01"), Error, "--to");
+  assertThrows(() => backfillDates("2025-1-1", "2025-01-02"), Error, "--from");
+  assertThrows(() => backfillDates("2025-01-01", "2025-12-31"), Error, "at most");
+});
+
+Deno.test("archiveSlot floors to the archiver's 15-minute files", () => {
+  assertEquals(archiveSlot(Date.parse("2025-01-10T16:07:30Z")), { date: "2025-01-10", slot: "1600" });
+  assertEquals(archiveSlot(Date.parse("2025-01-10T23:59:59Z")), { date: "2025-01-10", slot: "2345" });
+});
+
+Deno.test("tradeToArchiveLine writes a WS trade message that replay accepts", () => {
+  const line = tradeToArchiveLine(trade)!;
+  assertEquals(line.slot, "1600");
+  assertEquals(line.receivedAt, Date.parse(trade.created_time) * 1000);
+
+  const record = toReplayRecord("kalshi", "replay.kalshi", line.line)!;
+  assertEquals(record.subject, "replay.kalshi.json.trade.KXBTCD-25JAN1017-T95000");
+  assertEquals(JSON.parse(record.payload).msg.yes_price, 41);
+  assertEquals(parseArchiveLine(line.line), line);
+  assertEquals(tradeToArchiveLine({ ...trade, created_time: "bad" }), null);
+});
+
+Deno.test("mergeArchiveLines dedupes re-fetched trades and sorts by time", () => {
+  const a = tradeToArchiveLine(trade)!;
+  const b = tradeToArchiveLine({ ...trade, trade_id: "t-0", created_time: "2025-01-10T16:01:00Z" })!;
+  assertEquals(mergeArchiveLines([a], [b, a]).map((l) => l.tradeId), ["t-0", "t-1"]);
+});
+
+Deno.test("buildManifest follows the archiver manifest with provenance", () => {
+  const a = tradeToArchiveLine(trade)!;
+  const run = {
+    source: "kalshi-rest" as const,
+    endpoints: ["/markets/trades"],
+    tickers: [trade.ticker],
+    from: "2025-01-10",
+    to: "2025-01-10",
+    fetched_at: "2026-10-16T00:00:00.000Z",
+  };
+  const manifest = buildManifest("2025-01-10", new Map([["1600", [a]]]), new Map([["1600", 123]]), [run]);
+  assertEquals(manifest.files, [{
+    name: "1600.jsonl.gz",
+    start: "2025-01-10T16:07:30.250Z",
+    end: "2025-01-10T16:07:30.250Z",
+    records: 1,
+    bytes: 123,
+    nats_start_seq: 0,
+    nats_end_seq: 0,
+    records_by_type: { trade: 1 },
+    min_ts: "2025-01-10T16:07:30.250Z",
+    max_ts: "2025-01-10T16:07:30.250Z",
+    ticker_count: 1,
+  }]);
+  assertEquals(manifest.tickers, [trade.ticker]);
+  assertEquals(manifest.source, "backfill");
+  assertEquals(manifest.provenance, [run]);
+});
@@ -0,0 +1,23 @@
+import { assertEquals, assertStringIncludes } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { buildCommitMessage, CHANGES_TRAILER } from "../../src/cli/commands/commit.ts";
+import type { ConfigDiff } from "../../src/cli/commands/diff.ts";
+
+Deno.test("buildCommitMessage summarizes changed entities with a JSON trailer", () => {
+  const diff: ConfigDiff = {
+    feeds: { added: ["kraken"], removed: [], changed: [{ name: "kalshi", changes: [{ path: "status", from: "active", to: "deprecated" }] }] },
+    schemas: { added: [], removed: [], changed: [] },
+    environments: { added: [], removed: ["staging"], changed: [] },
+  };
+
+  const message = buildCommitMessage("Add kraken feed\n", diff);
+  const lines = message.trimEnd().split("\n");
+  assertEquals(lines.slice(0, 4), ["Add kraken feed", "", "feeds: +kraken ~kalshi", "environments: -staging"]);
+
+  const trailer = lines[lines.length - 1];
+  assertStringIncludes(trailer, `${CHANGES_TRAILER}: `);
+  assertEquals(JSON.parse(trailer.slice(CHANGES_TRAILER.length + 2)), {
+    feeds: { added: ["kraken"], removed: [], changed: ["kalshi"] },
+    schemas: { added: [], removed: [], changed: [] },
+    environments: { added: [], removed: ["staging"], changed: [] },
+  });
+});
@@ -0,0 +1,29 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { formatCsv, formatTable } from "../../src/cli/commands/data-sql.ts";
+
+const result = {
+  columns: ["ticker", "n", "meta"],
+  rows: [
+    { ticker: "KXBTC-1", n: 3, meta: { side: "yes" } },
+    { ticker: "A,B", n: null, meta: 'say "hi"' },
+  ],
+};
+
+Deno.test("formatCsv quotes cells with commas and quotes", () => {
+  assertEquals(formatCsv(result), [
+    "ticker,n,meta",
+    'KXBTC-1,3,"{""side"":""yes""}"',
+    '"A,B",,"say ""hi"""',
+  ].join("\n"));
+});
+
+Deno.test("formatTable pads columns and truncates long cells", () => {
+  assertEquals(formatTable(result).split("\n"), [
+    "ticker   n  meta",
+    "-------  -  --------------",
+    'KXBTC-1  3  {"side":"yes"}',
+    "A,B         say \"hi\"",
+  ]);
+  const long = formatTable({ columns: ["s"], rows: [{ s: "x".repeat(50) }] }).split("\n")[2];
+  assertEquals(long, `${"x".repeat(39)}…`);
+});
@@ -0,0 +1,26 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { loadRawSchemaFiles } from "../../src/cli/commands/data-validate.ts";
+
+Deno.test("loadRawSchemaFiles reads raw schema YAML and ignores other files", async () => {
+  const dir = await Deno.makeTempDir();
+  try {
+    await Deno.writeTextFile(join(dir, "trade.capnp"), "struct Trade {}\n");
+    await Deno.writeTextFile(join(dir, "notes.yaml"), "owner: data-team\n");
+    await Deno.writeTextFile(
+      join(dir, "coinbase-trade.yaml"),
+      "feed: coinbase\ntype: match\ntype_field: type\nfields:\n  type: { type: string, required: true }\n  price: { type: string, required: true }\n",
+    );
+    const schemas = await loadRawSchemaFiles(dir);
+    assertEquals(schemas, [{
+      feed: "coinbase",
+      type: "match",
+      version: "v1",
+      type_field: "type",
+      fields: { type: { type: "string", required: true }, price: { type: "string", required: true } },
+    }]);
+    assertEquals(await loadRawSchemaFiles(join(dir, "missing")), []);
+  } finally {
+    await Deno.remove(dir, { recursive: true });
+  }
+});
@@ -0,0 +1,64 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { diffSnapshots, diffValues, isEmptyDiff, loadGitRef, loadWorkingTree, snapshotFromFiles } from "../../src/cli/commands/diff.ts";
+import { git } from "../../src/cli/utils/git.ts";
+
+const KALSHI_V1 = `name: kalshi
+type: websocket
+status: active
+versions:
+  - version: v1
+    effective_from: "2025-01-01"
+    endpoint: wss://api.elections.kalshi.com/trade-api/ws/v2
+`;
+
+Deno.test("diffValues reports nested changes and keys feed versions by version", () => {
+  const before = { status: "active", versions: [{ version: "v1", endpoint: "wss://a" }] };
+  const after = { status: "deprecated", versions: [{ version: "v1", endpoint: "wss://b" }, { version: "v2", endpoint: "wss://c" }] };
+
+  assertEquals(diffValues(before, after), [
+    { path: "status", from: "active", to: "deprecated" },
+    { path: "versions[v1].endpoint", from: "wss://a", to: "wss://b" },
+    { path: "versions[v2]", from: null, to: { version: "v2", endpoint: "wss://c" } },
+  ]);
+  assertEquals(diffValues(before, structuredClone(before)), []);
+});
+
+Deno.test("diffSnapshots groups added, removed and changed entities by kind", () => {
+  const before = snapshotFromFiles(new Map([
+    ["feeds/kalshi.yaml", KALSHI_V1],
+    ["environments/prod.yaml", "name: prod\nfeed: kalshi\n"],
+    ["schemas/kalshi-trade.yaml", "feed: kalshi\ntype: trade\nversion: v1\n"],
+  ]));
+  const after = snapshotFromFiles(new Map([
+    ["feeds/kalshi.yaml", KALSHI_V1.replace("status: active", "status: deprecated")],
+    ["feeds/kraken.yaml", "name: kraken\ntype: websocket\n"],
+    ["schemas/kalshi-trade.yaml", "feed: kalshi\ntype: trade\nversion: v2\n"],
+    ["README.md", "ignored"],
+  ]));
+
+  const diff = diffSnapshots(before, after);
+  assertEquals(diff.feeds.added, ["kraken"]);
+  assertEquals(diff.feeds.changed, [{ name: "kalshi", changes: [{ path: "status", from: "active", to: "deprecated" }] }]);
+  assertEquals(diff.schemas.changed[0].changes, [{ path: "version", from: "v1", to: "v2" }]);
+  assertEquals(diff.environments.removed, ["prod"]);
+  assertEquals(isEmptyDiff(diffSnapshots(after, after)), true);
+});
+
+Deno.test("loadGitRef reads exchanges/ at a ref for comparison with the working tree", async () => {
+  const repo = await Deno.makeTempDir();
+  const root = join(repo, "exchanges");
+  await Deno.mkdir(join(root, "feeds"), { recursive: true });
+  await Deno.writeTextFile(join(root, "feeds", "kalshi.yaml"), KALSHI_V1);
+  await git(["init", "-q"], repo);
+  await git(["-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "empty"], repo);
+  await git(["add", "."], repo);
+  await git(["-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "feeds"], repo);
+
+  await Deno.writeTextFile(join(root, "feeds", "kraken.yaml"), "name: kraken\ntype: websocket\n");
+
+  assertEquals(diffSnapshots(await loadGitRef(root, "HEAD"), await loadWorkingTree(root)).feeds.added, ["kraken"]);
+  assertEquals(diffSnapshots(await loadGitRef(root, "HEAD~1"), await loadWorkingTree(root)).feeds.added, ["kalshi", "kraken"]);
+
+  await Deno.remove(repo, { recursive: true });
+});
@@ -0,0 +1,20 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { dialTarget, matchingStreams } from "../../src/cli/commands/env-check.ts";
+
+Deno.test("dialTarget derives host, port and TLS from feed endpoints", () => {
+  assertEquals(dialTarget("wss://api.elections.kalshi.com/trade-api/ws/v2"), {
+    hostname: "api.elections.kalshi.com",
+    port: 443,
+    tls: true,
+  });
+  assertEquals(dialTarget("ws://localhost:9001/ws"), { hostname: "localhost", port: 9001, tls: false });
+  assertEquals(dialTarget("ws://feed.internal/ws"), { hostname: "feed.internal", port: 80, tls: false });
+});
+
+Deno.test("matchingStreams selects the environment's streams", () => {
+  const streams = ["PROD_KALSHI_CRYPTO", "PROD_KRAKEN_FUTURES", "PROD_KRAKEN", "DEV_KALSHI", "SECMASTER_CDC", "PRODUCTION"];
+  assertEquals(matchingStreams(streams, "PROD"), ["PROD_KALSHI_CRYPTO", "PROD_KRAKEN", "PROD_KRAKEN_FUTURES"]);
+  assertEquals(matchingStreams(streams, "PROD", "kalshi"), ["PROD_KALSHI_CRYPTO"]);
+  assertEquals(matchingStreams(streams, "PROD", "kraken-futures"), ["PROD_KRAKEN_FUTURES"]);
+  assertEquals(matchingStreams(streams, "DEV", "polymarket"), []);
+});
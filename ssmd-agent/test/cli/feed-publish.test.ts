@@ -0,0 +1,75 @@
+import { assertEquals, assertRejects } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { publishArgs, renderConfigMaps, SCHEMAS_CONFIGMAP } from "../../src/cli/commands/feed-publish.ts";
+
+const KALSHI_YAML = `name: kalshi
+type: websocket
+versions:
+  - version: v1
+    effective_from: "2025-12-22"
+    protocol:
+      transport: wss
+      message: json
+    endpoint: wss://api.elections.kalshi.com/trade-api/ws/v2
+
+# Component defaults for operators
+defaults:
+  connector:
+    image: ghcr.io/aaronwald/ssmd-connector
+    version: "0.4.7"
+`;
+
+async function makeExchangesDir(): Promise<string> {
+  const root = await Deno.makeTempDir();
+  for (const dir of ["feeds", "schemas", "environments"]) {
+    await Deno.mkdir(join(root, dir));
+  }
+  await Deno.writeTextFile(join(root, "feeds", "kalshi.yaml"), KALSHI_YAML);
+  await Deno.writeTextFile(join(root, "schemas", ".gitkeep"), "");
+  await Deno.writeTextFile(join(root, "schemas", "trade.capnp"), "struct Trade {}\n");
+  await Deno.writeTextFile(join(root, "environments", "prod.yaml"), "name: prod\nfeed: kalshi\n");
+  return root;
+}
+
+Deno.test("renderConfigMaps renders feed, schema and env ConfigMaps", async () => {
+  const root = await makeExchangesDir();
+  try {
+    const manifests = await renderConfigMaps(root);
+    assertEquals(manifests.map((m) => m.metadata.name), ["feed-kalshi", SCHEMAS_CONFIGMAP, "env-prod"]);
+
+    // Feeds are published verbatim, keeping comments and operator defaults
+    assertEquals(manifests[0].data["feed.yaml"], KALSHI_YAML);
+    assertEquals(manifests[0].metadata.annotations["ssmd.io/source"], "exchanges/feeds/kalshi.yaml");
+    assertEquals(Object.keys(manifests[1].data), ["trade.capnp"]);
+    assertEquals(manifests[2].data["env.yaml"], "name: prod\nfeed: kalshi\n");
+  } finally {
+    await Deno.remove(root, { recursive: true });
+  }
+});
+
+Deno.test("renderConfigMaps with a feed renders only that feed", async () => {
+  const root = await makeExchangesDir();
+  try {
+    const manifests = await renderConfigMaps(root, "kalshi");
+    assertEquals(manifests.map((m) => m.metadata.name), ["feed-kalshi"]);
+    await assertRejects(() => renderConfigMaps(root, "kraken"), Error, "Feed 'kraken' not found");
+  } finally {
+    await Deno.remove(root, { recursive: true });
+  }
+});
+
+Deno.test("renderConfigMaps rejects an invalid feed", async () => {
+  const root = await makeExchangesDir();
+  try {
+    await Deno.writeTextFile(join(root, "feeds", "broken.yaml"), "name: broken\ntype: websocket\nversions: []\n");
+    await assertRejects(() => renderConfigMaps(root), Error, "feeds/broken.yaml");
+  } finally {
+    await Deno.remove(root, { recursive: true });
+  }
+});
+
+Deno.test("publishArgs uses server-side apply", () => {
+  assertEquals(publishArgs("apply"), ["apply", "--server-side", "--field-manager=ssmd-cli", "-f", "-"]);
+  assertEquals(publishArgs("dry-run"), ["apply", "--dry-run=server", "--server-side", "--field-manager=ssmd-cli", "-f", "-"]);
+  assertEquals(publishArgs("diff"), ["diff", "--server-side", "--field-manager=ssmd-cli", "-f", "-"]);
+});
@@ -1,5 +1,13 @@
-import { assertEquals, assertExists } from "https://deno.land/std@0.224.0/assert/mod.ts";
-import { listFeeds, showFeed, createFeed } from "../../src/cli/commands/feed.ts";
+import { assertEquals, assertExists, assertRejects } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { parse as parseYaml } from "yaml";
+import {
+  addHoliday,
+  createFeed,
+  feedNextSession,
+  listFeeds,
+  setCalendar,
+  showFeed,
+} from "../../src/cli/commands/feed.ts";
 
 Deno.test("listFeeds returns feeds from directory", async () => {
   const tmpDir = await Deno.makeTempDir();
@@ -60,3 +68,54 @@ Deno.test("createFeed creates new feed file", async () => {
 
   await Deno.remove(tmpDir, { recursive: true });
 });
+
+Deno.test("setCalendar and addHoliday edit the calendar and keep other keys", async () => {
+  const tmpDir = await Deno.makeTempDir();
+  await createFeed(tmpDir, "cme", { type: "websocket", endpoint: "wss://cme.example.com/ws" });
+  const path = `${tmpDir}/cme.yaml`;
+  await Deno.writeTextFile(path, (await Deno.readTextFile(path)) + "message_types:\n  trade:\n    sequenced: true\n");
+
+  await setCalendar(tmpDir, "cme", { timezone: "America/Chicago", openTime: "08:30", closeTime: "15:00" });
+  await setCalendar(tmpDir, "cme", { weekday: "sat", closed: true });
+  await setCalendar(tmpDir, "cme", { weekday: "sun", closed: true });
+  await addHoliday(tmpDir, "cme", "2026-12-25", { name: "Christmas" });
+  await addHoliday(tmpDir, "cme", "2026-12-24", { closeTime: "12:00" });
+  await addHoliday(tmpDir, "cme", "2026-01-01");
+
+  const feed = await showFeed(tmpDir, "cme");
+  assertExists(feed?.calendar);
+  assertEquals(feed.calendar.open_time, "08:30");
+  assertEquals(feed.calendar.weekdays, { sat: { closed: true }, sun: { closed: true } });
+  assertEquals(feed.calendar.holidays?.map((h) => h.date), ["2026-01-01", "2026-12-25"]);
+  assertEquals(feed.calendar.half_days, [{ date: "2026-12-24", close_time: "12:00" }]);
+
+  const raw = parseYaml(await Deno.readTextFile(path)) as Record<string, unknown>;
+  assertEquals(raw.message_types, { trade: { sequenced: true } });
+
+  // Christmas Eve closes at noon, Christmas is skipped, the weekend is closed
+  const eve = await feedNextSession(tmpDir, "cme", new Date("2026-12-24T12:00:00Z"));
+  assertEquals(eve?.close.toISOString(), "2026-12-24T18:00:00.000Z");
+  const next = await feedNextSession(tmpDir, "cme", new Date("2026-12-24T19:00:00Z"));
+  assertEquals(next?.date, "2026-12-28");
+
+  await Deno.remove(tmpDir, { recursive: true });
+});
+
+Deno.test("calendar edits are validated before writing", async () => {
+  const tmpDir = await Deno.makeTempDir();
+  await createFeed(tmpDir, "kalshi", { type: "websocket", endpoint: "wss://kalshi.com/ws" });
+  const before = await Deno.readTextFile(`${tmpDir}/kalshi.yaml`);
+
+  await assertRejects(() => setCalendar(tmpDir, "kalshi", { timezone: "Mars/Olympus" }));
+  await assertRejects(() => setCalendar(tmpDir, "kalshi", { openTime: "25:00", closeTime: "26:00" }));
+  await assertRejects(() => setCalendar(tmpDir, "kalshi", { weekday: "fri" }), Error, "--closed");
+  await addHoliday(tmpDir, "kalshi", "2026-07-04");
+  await assertRejects(() => addHoliday(tmpDir, "kalshi", "2026-07-04"));
+  await assertRejects(() => addHoliday(tmpDir, "missing", "2026-07-04"), Error, "not found");
+
+  const after = parseYaml(await Deno.readTextFile(`${tmpDir}/kalshi.yaml`)) as { calendar?: unknown };
+  assertEquals(after.calendar, { holidays: [{ date: "2026-07-04" }] });
+  assertEquals(before.includes("calendar"), false);
+
+  await Deno.remove(tmpDir, { recursive: true });
+});
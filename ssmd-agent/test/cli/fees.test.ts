@@ -0,0 +1,27 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { feeUpdateFromFlags } from "../../src/cli/commands/fees.ts";
+
+Deno.test("feeUpdateFromFlags: defaults multiplier and leaves effective_from unset", () => {
+  const update = feeUpdateFromFlags({ _: ["fees", "set", "KXBTC"], "fee-type": "quadratic" });
+  assertEquals(update, { fee_type: "quadratic", fee_multiplier: 1.0, effective_from: undefined });
+});
+
+Deno.test("feeUpdateFromFlags: parses multiplier and effective date", () => {
+  const update = feeUpdateFromFlags({
+    "fee-type": "quadratic_with_maker_fees",
+    multiplier: "0.5",
+    "effective-from": "2026-03-01T00:00:00Z",
+  });
+  assertEquals(update.fee_multiplier, 0.5);
+  assertEquals(update.effective_from, "2026-03-01T00:00:00Z");
+});
+
+Deno.test("feeUpdateFromFlags: rejects unknown fee types", () => {
+  assertThrows(() => feeUpdateFromFlags({ "fee-type": "percent" }), Error, "fee_type");
+});
+
+Deno.test("feeUpdateFromFlags: rejects non-positive multipliers and bad dates", () => {
+  assertThrows(() => feeUpdateFromFlags({ "fee-type": "flat", multiplier: "0" }), Error, "fee_multiplier");
+  assertThrows(() => feeUpdateFromFlags({ "fee-type": "flat", multiplier: "abc" }), Error, "fee_multiplier");
+  assertThrows(() => feeUpdateFromFlags({ "fee-type": "flat", "effective-from": "March 1" }), Error, "effective_from");
+});
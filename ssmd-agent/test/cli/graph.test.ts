@@ -0,0 +1,55 @@
+import { assertEquals, assertStringIncludes } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { snapshotFromFiles } from "../../src/cli/commands/diff.ts";
+import { buildGraph, dependents, toDot } from "../../src/cli/commands/graph.ts";
+
+const snapshot = snapshotFromFiles(new Map([
+  ["feeds/kalshi.yaml", "name: kalshi\n"],
+  ["schemas/kalshi-trade.yaml", "feed: kalshi\ntype: trade\nversion: v1\nfields: {}\n"],
+  ["schemas/kalshi-trade-v2.yaml", "feed: kalshi\ntype: trade\nversion: v2\ncompatible_with: [v1]\nfields: {}\n"],
+  ["environments/prod.yaml", "feed: kalshi\nschema: trade:v1\n"],
+  ["environments/dev.yaml", "feed: kalshi\nschema: trade:v2\n"],
+  ["environments/staging.yaml", "feed: kraken\n"],
+]));
+
+Deno.test("buildGraph links schemas and environments to feeds", () => {
+  const graph = buildGraph(snapshot, [{ kind: "connector", name: "kalshi-prod", feed: "kalshi" }]);
+
+  assertEquals(graph.nodes.map((n) => n.id).sort(), [
+    "connector:kalshi-prod",
+    "environment:dev",
+    "environment:prod",
+    "environment:staging",
+    "feed:kalshi",
+    "feed:kraken",
+    "schema:kalshi/trade:v1",
+    "schema:kalshi/trade:v2",
+  ]);
+  assertEquals(graph.edges.filter((e) => e.from === "schema:kalshi/trade:v2"), [
+    { from: "schema:kalshi/trade:v2", to: "feed:kalshi", relation: "of" },
+    { from: "schema:kalshi/trade:v2", to: "schema:kalshi/trade:v1", relation: "compatible_with" },
+  ]);
+  assertEquals(graph.edges.filter((e) => e.from === "environment:prod").map((e) => e.to), [
+    "feed:kalshi",
+    "schema:kalshi/trade:v1",
+  ]);
+});
+
+Deno.test("dependents lists what breaks when a node goes away", () => {
+  const graph = buildGraph(snapshot, [{ kind: "archiver", name: "kalshi-archiver", feed: "kalshi" }]);
+
+  assertEquals(dependents(graph, "schema:kalshi/trade:v1").map((n) => n.id), ["environment:prod"]);
+  assertEquals(dependents(graph, "feed:kalshi").map((n) => n.id), [
+    "archiver:kalshi-archiver",
+    "environment:dev",
+    "environment:prod",
+  ]);
+});
+
+Deno.test("toDot renders nodes and edges", () => {
+  const dot = toDot(buildGraph(snapshot));
+
+  assertStringIncludes(dot, 'digraph ssmd {');
+  assertStringIncludes(dot, '"schema:kalshi/trade:v1" [label="kalshi/trade:v1", shape=note];');
+  assertStringIncludes(dot, '"environment:prod" -> "schema:kalshi/trade:v1" [label="uses"];');
+  assertStringIncludes(dot, '"schema:kalshi/trade:v2" -> "schema:kalshi/trade:v1" [label="compatible_with", style=dashed];');
+});
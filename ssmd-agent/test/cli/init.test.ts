@@ -1,5 +1,9 @@
-import { assertEquals, assertExists } from "https://deno.land/std@0.224.0/assert/mod.ts";
-import { initExchanges } from "../../src/cli/commands/init.ts";
+import { assertEquals, assertExists, assertRejects } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { parse as parseYaml } from "yaml";
+import { INIT_TEMPLATES, initExchanges, renderTemplate } from "../../src/cli/commands/init.ts";
+import { loadRawSchemaFiles } from "../../src/cli/commands/data-validate.ts";
+import { FeedSchema } from "../../src/lib/types/feed.ts";
+import { RawSchemaSchema } from "../../src/lib/types/raw-schema.ts";
 import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
 
 Deno.test("initExchanges creates directory structure", async () => {
@@ -44,3 +48,50 @@ Deno.test("initExchanges fails if directory exists", async () => {
   // Cleanup
   await Deno.remove(tmpDir, { recursive: true });
 });
+
+Deno.test("initExchanges --template writes a feed, schemas and a dev environment", async () => {
+  const tmpDir = await Deno.makeTempDir();
+  const targetDir = join(tmpDir, "exchanges");
+
+  await initExchanges(targetDir, { template: "kalshi" });
+
+  const feed = FeedSchema.parse(parseYaml(await Deno.readTextFile(join(targetDir, "feeds", "kalshi.yaml"))));
+  assertEquals(feed.name, "kalshi");
+  assertEquals(feed.versions[0].auth_method, "api_key");
+
+  const schemas = await loadRawSchemaFiles(join(targetDir, "schemas"));
+  assertEquals(schemas.map((s) => `${s.feed}/${s.type}`), ["kalshi/ticker", "kalshi/trade"]);
+
+  const env = parseYaml(await Deno.readTextFile(join(targetDir, "environments", "kalshi-dev.yaml"))) as {
+    feed: string;
+    keys: Record<string, { type: string; source: string }>;
+    transport: { stream: string };
+  };
+  assertEquals(env.feed, "kalshi");
+  assertEquals(env.keys.kalshi.source, "env:KALSHI_API_KEY,KALSHI_PRIVATE_KEY");
+  assertEquals(env.transport.stream, "DEV_KALSHI");
+
+  // Populated directories get no .gitkeep
+  await assertRejects(() => Deno.stat(join(targetDir, "feeds", ".gitkeep")), Deno.errors.NotFound);
+
+  await Deno.remove(tmpDir, { recursive: true });
+});
+
+Deno.test("renderTemplate produces valid files for every template", () => {
+  for (const template of INIT_TEMPLATES) {
+    const files = renderTemplate(template, new Date("2026-01-15T00:00:00Z"));
+    const feed = FeedSchema.parse(parseYaml(files[`feeds/${template}.yaml`]));
+    assertEquals(feed.versions[0].effective_from, "2026-01-15");
+
+    const schemaFiles = Object.keys(files).filter((f) => f.startsWith("schemas/"));
+    assertEquals(schemaFiles.length > 0, true);
+    for (const file of schemaFiles) {
+      assertEquals(RawSchemaSchema.parse(parseYaml(files[file])).feed, template);
+    }
+
+    const env = parseYaml(files[`environments/${template}-dev.yaml`]) as { name: string; schema: string };
+    assertEquals(env.name, `${template}-dev`);
+    assertExists(env.schema.split(":")[1]);
+  }
+  assertEquals("keys" in (parseYaml(renderTemplate("kraken")["environments/kraken-dev.yaml"]) as object), false);
+});
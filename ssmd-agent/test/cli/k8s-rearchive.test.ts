@@ -0,0 +1,120 @@
+import { assertEquals, assertStringIncludes, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  type ArchiverSchedule,
+  rearchiveConfig,
+  rearchiveJobName,
+  rearchiveSyncArgs,
+  renderRearchive,
+  reprocessPrefix,
+  resolveTarget,
+} from "../../src/cli/commands/k8s-rearchive.ts";
+
+const SCHEDULE: ArchiverSchedule = {
+  metadata: { name: "kalshi" },
+  spec: {
+    feed: "kalshi",
+    template: {
+      feed: "kalshi",
+      image: "ghcr.io/aaronwald/ssmd-archiver:0.5.0",
+      sources: [
+        { name: "crypto", stream: "PROD_KALSHI_CRYPTO", filter: "prod.kalshi.crypto.json.>" },
+        { name: "politics", stream: "PROD_KALSHI_POLITICS", filter: "prod.kalshi.politics.json.>" },
+      ],
+      storage: { remote: { type: "gcs", bucket: "ssmd-archive", prefix: "kalshi/{date}", secretRef: "gcs-key" } },
+      rotation: { maxFileAge: "5m" },
+      serviceAccountName: "ssmd-archiver",
+    },
+  },
+};
+
+interface RenderedConfigMap {
+  metadata: { name: string };
+  data: Record<string, string>;
+}
+
+interface RenderedJob {
+  spec: {
+    backoffLimit: number;
+    template: {
+      spec: {
+        restartPolicy: string;
+        serviceAccountName?: string;
+        initContainers: { image: string }[];
+        containers: { args: string[] }[];
+        volumes: { name: string }[];
+      };
+    };
+  };
+}
+
+Deno.test("rearchiveJobName is a valid label value", () => {
+  assertEquals(rearchiveJobName("kalshi", "2026-01-15", 1200), "rearchive-kalshi-2026-01-15-1200");
+  assertEquals(rearchiveJobName("Kraken_Futures", "2026-01-15", 7), "rearchive-kraken-futures-2026-01-15-7");
+  const long = rearchiveJobName("a".repeat(60), "2026-01-15", 1);
+  assertEquals(long.length <= 63, true);
+  assertEquals(long.endsWith("-"), false);
+});
+
+Deno.test("reprocessPrefix nests under the remote prefix", () => {
+  assertEquals(reprocessPrefix("kalshi/", "job"), "kalshi/reprocess/job");
+  assertEquals(reprocessPrefix("", "job"), "reprocess/job");
+});
+
+Deno.test("resolveTarget requires --source when the template has several", () => {
+  assertThrows(() => resolveTarget("kalshi", "2026-01-15", 100, undefined, SCHEDULE, {}), Error, "--source");
+
+  const t = resolveTarget("kalshi", "2026-01-15", 100, 200, SCHEDULE, { source: "politics" });
+  assertEquals(t.source, {
+    name: "politics",
+    stream: "PROD_KALSHI_POLITICS",
+    filter: "prod.kalshi.politics.json.>",
+    feed: "kalshi",
+  });
+  assertEquals(t.remote.prefix, "kalshi/2026-01-15");
+  assertEquals(t.serviceAccountName, "ssmd-archiver");
+  assertEquals(t.rotation, "5m");
+});
+
+Deno.test("resolveTarget works from flags without a schedule", () => {
+  assertThrows(() => resolveTarget("kalshi", "2026-01-15", 1, undefined, null, {}), Error, "--stream");
+
+  const t = resolveTarget("kalshi", "2026-01-15", 1, undefined, null, {
+    stream: "PROD_KALSHI",
+    filter: "prod.kalshi.json.>",
+    bucket: "b",
+  });
+  assertEquals(t.source.name, "main");
+  assertEquals(t.remote, { type: "gcs", bucket: "b", prefix: "", secretRef: undefined, endpoint: undefined, region: undefined });
+});
+
+Deno.test("renderRearchive writes a replay config and a one-shot Job", () => {
+  const target = resolveTarget("kalshi", "2026-01-15", 100, 200, SCHEDULE, { source: "crypto" });
+  const [cm, job] = renderRearchive(target) as unknown as [RenderedConfigMap, RenderedJob];
+  const name = "rearchive-kalshi-2026-01-15-100";
+
+  assertEquals(cm.metadata.name, name);
+  const config = cm.data["archiver.yaml"];
+  assertStringIncludes(config, "replay:\n  date: 2026-01-15\n  from_sequence: 100\n  to_sequence: 200\n");
+  assertStringIncludes(config, "      stream: PROD_KALSHI_CRYPTO\n");
+
+  assertEquals(job.spec.backoffLimit, 0);
+  const pod = job.spec.template.spec;
+  assertEquals(pod.restartPolicy, "Never");
+  assertEquals(pod.serviceAccountName, "ssmd-archiver");
+  assertEquals(pod.initContainers[0].image, "ghcr.io/aaronwald/ssmd-archiver:0.5.0");
+  assertEquals(pod.containers[0].args, rearchiveSyncArgs(target, name));
+  assertEquals(pod.volumes.map((v) => v.name), ["data", "config", "gcs-credentials"]);
+});
+
+Deno.test("rearchiveSyncArgs uploads under the reprocess prefix", () => {
+  const target = resolveTarget("kalshi", "2026-01-15", 100, undefined, SCHEDULE, { source: "crypto" });
+  assertEquals(rearchiveSyncArgs(target, "job"), [
+    "--source", "/data/ssmd/",
+    "--bucket", "ssmd-archive",
+    "--prefix", "kalshi/2026-01-15/reprocess/job",
+    "--name", "job",
+    "--max-attempts", "5",
+    "--credentials", "/etc/gcs/key.json",
+  ]);
+  assertEquals(rearchiveConfig(target, "job").includes("to_sequence"), false);
+});
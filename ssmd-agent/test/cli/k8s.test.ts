@@ -0,0 +1,88 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { parse as parseYaml } from "yaml";
+import { snapshotFromFiles } from "../../src/cli/commands/diff.ts";
+import { generateManifests, renderManifests } from "../../src/cli/commands/k8s.ts";
+
+const FEED = `name: kalshi
+type: websocket
+versions:
+  - version: v1
+    effective_from: "2025-01-01"
+    protocol: { transport: wss, message: json }
+    endpoint: wss://api.elections.kalshi.com/trade-api/ws/v2
+    auth_method: api_key
+`;
+
+const ENV = `name: kalshi-prod
+feed: kalshi
+schema: trade:v1
+transport:
+  type: nats
+  url: nats://nats.nats.svc.cluster.local:4222
+  stream: PROD_KALSHI
+  subject_prefix: prod.kalshi
+storage:
+  type: gcs
+  bucket: ssmd-archive
+`;
+
+const snapshot = snapshotFromFiles(new Map([
+  ["feeds/kalshi.yaml", FEED],
+  ["environments/kalshi-prod.yaml", ENV],
+  ["environments/broken.yaml", "feed: kalshi\ntransport: { type: stdout }\n"],
+]));
+
+Deno.test("generateManifests maps an environment onto Connector and Archiver specs", () => {
+  const [connector, archiver] = generateManifests(snapshot, "kalshi-prod", {
+    namespace: "ssmd",
+    connectorImage: "connector:test",
+    archiverImage: "archiver:test",
+  });
+
+  assertEquals(connector.metadata, {
+    name: "kalshi-prod",
+    namespace: "ssmd",
+    labels: { "ssmd.io/environment": "kalshi-prod", "ssmd.io/feed": "kalshi" },
+    annotations: { "ssmd.io/schema": "trade:v1" },
+  });
+  assertEquals(connector.spec, {
+    feed: "kalshi",
+    image: "connector:test",
+    transport: {
+      type: "nats",
+      url: "nats://nats.nats.svc.cluster.local:4222",
+      stream: "PROD_KALSHI",
+      subjectPrefix: "prod.kalshi",
+    },
+    secretRef: { name: "ssmd-kalshi-credentials", apiKeyField: "api-key", privateKeyField: "private-key" },
+  });
+
+  const spec = archiver.spec as Record<string, Record<string, unknown>>;
+  assertEquals((archiver.metadata as Record<string, unknown>).name, "kalshi-prod-archiver");
+  assertEquals(spec.source.filter, "prod.kalshi.>");
+  assertEquals(spec.storage.remote, {
+    type: "gcs",
+    bucket: "ssmd-archive",
+    prefix: "kalshi",
+    secretRef: "gcs-credentials",
+  });
+  assertEquals(spec.dedupKeys, { trade: ["msg.market_ticker", "msg.trade_id"] });
+  assertEquals(spec.sync, { enabled: true });
+});
+
+Deno.test("generateManifests filters by kind and renders one document per CR", () => {
+  const manifests = generateManifests(snapshot, "kalshi-prod", { kind: "archiver", namespace: "ssmd" });
+  assertEquals(manifests.map((m) => m.kind), ["Archiver"]);
+
+  const docs = renderManifests(generateManifests(snapshot, "kalshi-prod", { namespace: "ssmd" })).split("---\n");
+  assertEquals(docs.map((d) => (parseYaml(d) as Record<string, unknown>).kind), ["Connector", "Archiver"]);
+});
+
+Deno.test("generateManifests rejects missing environments and non-NATS transports", () => {
+  assertThrows(() => generateManifests(snapshot, "missing", { namespace: "ssmd" }), Error, "No environment missing");
+  assertThrows(
+    () => generateManifests(snapshot, "broken", { namespace: "ssmd" }),
+    Error,
+    "environments/broken.yaml: transport must be nats with stream and subject_prefix",
+  );
+});
@@ -0,0 +1,51 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  assessKey,
+  parseKeySource,
+  parseLedger,
+  secretLastWritten,
+} from "../../src/cli/commands/keys-audit.ts";
+
+const now = new Date("2026-06-01T00:00:00Z");
+const spec = { type: "api_key", fields: ["api_key"], rotation_days: 90 };
+
+Deno.test("parseKeySource recognizes env, sealed-secret and vault sources", () => {
+  assertEquals(parseKeySource("env:A, B"), { kind: "env", vars: ["A", "B"] });
+  assertEquals(parseKeySource("sealed-secret:ssmd/kalshi-creds"), { kind: "sealed-secret", namespace: "ssmd", name: "kalshi-creds" });
+  assertEquals(parseKeySource("sealed-secret:kalshi-creds"), { kind: "sealed-secret", namespace: null, name: "kalshi-creds" });
+  assertEquals(parseKeySource("vault:secret/ssmd/kalshi"), { kind: "vault", mount: "secret", path: "ssmd/kalshi" });
+  assertEquals(parseKeySource("vault:secret"), { kind: "unknown", raw: "vault:secret" });
+  assertEquals(parseKeySource("file:/etc/key"), { kind: "unknown", raw: "file:/etc/key" });
+  assertEquals(parseKeySource(undefined), { kind: "none" });
+});
+
+Deno.test("secretLastWritten takes the latest of creation and managed-field updates", () => {
+  assertEquals(
+    secretLastWritten({
+      metadata: {
+        creationTimestamp: "2025-01-01T00:00:00Z",
+        managedFields: [{ time: "2026-03-01T12:00:00Z" }, { time: "2025-06-01T00:00:00Z" }, {}],
+      },
+    })?.toISOString(),
+    "2026-03-01T12:00:00.000Z",
+  );
+  assertEquals(secretLastWritten({}), null);
+});
+
+Deno.test("parseLedger reads dates and rejects bad entries", () => {
+  const ledger = parseLedger("kalshi: 2026-03-01\nkraken: \"2026-04-15T10:00:00Z\"\n");
+  assertEquals(ledger.get("kalshi")?.toISOString(), "2026-03-01T00:00:00.000Z");
+  assertEquals(ledger.get("kraken")?.toISOString(), "2026-04-15T10:00:00.000Z");
+  assertEquals(parseLedger("").size, 0);
+  assertThrows(() => parseLedger("kalshi: soon"), Error, "kalshi");
+});
+
+Deno.test("assessKey classifies against the rotation window", () => {
+  const at = (daysAgo: number) => new Date(now.getTime() - daysAgo * 86_400_000);
+  assertEquals(assessKey("k", spec, at(10), "secret", now).status, "ok");
+  assertEquals(assessKey("k", spec, at(85), "secret", now).status, "due_soon");
+  assertEquals(assessKey("k", spec, at(91), "secret", now).status, "overdue");
+  assertEquals(assessKey("k", spec, null, "skipped vault", now).status, "unknown");
+  assertEquals(assessKey("k", { ...spec, rotation_days: undefined }, null, "", now).status, "no_policy");
+  assertEquals(assessKey("k", spec, at(91), "ledger", now).age_days, 91);
+});
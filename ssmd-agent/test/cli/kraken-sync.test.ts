@@ -0,0 +1,44 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { buildSpotPairRows } from "../../src/cli/commands/kraken-sync.ts";
+import type { KrakenAssetPair } from "../../src/lib/api/kraken.ts";
+
+function assetPair(overrides: Partial<KrakenAssetPair>): KrakenAssetPair {
+  return {
+    altname: "XBTUSD",
+    wsname: "XBT/USD",
+    aclass_base: "currency",
+    base: "XXBT",
+    aclass_quote: "currency",
+    quote: "ZUSD",
+    lot: "unit",
+    pair_decimals: 1,
+    lot_decimals: 8,
+    lot_multiplier: 1,
+    fees: [[0, 0.4]],
+    fee_volume_currency: "ZUSD",
+    margin_call: 80,
+    margin_stop: 40,
+    ordermin: "0.0001",
+    tick_size: "0.1",
+    status: "online",
+    ...overrides,
+  };
+}
+
+Deno.test("buildSpotPairRows keeps online pairs and attaches ticker metadata", () => {
+  const rows = buildSpotPairRows(
+    { XXBTZUSD: assetPair({}), XETHZUSD: assetPair({ altname: "ETHUSD", wsname: "ETH/USD", status: "cancel_only" }) },
+    { XXBTZUSD: { a: ["97010.1"], b: ["97000.0"], c: ["97005.5", "0.01"], v: ["120.5", "2400.25"] } },
+  );
+
+  assertEquals(rows.length, 1);
+  assertEquals(rows[0].pairId, "kraken:XXBTZUSD");
+  assertEquals([rows[0].base, rows[0].quote, rows[0].marketType], ["XBT", "USD", "spot"]);
+  assertEquals([rows[0].bid, rows[0].ask, rows[0].lastPrice, rows[0].volume24h], ["97000.0", "97010.1", "97005.5", "2400.25"]);
+});
+
+Deno.test("buildSpotPairRows leaves prices empty without a ticker", () => {
+  const [row] = buildSpotPairRows({ XXBTZUSD: assetPair({}) });
+
+  assertEquals([row.bid, row.ask, row.lastPrice, row.volume24h], [null, null, null, null]);
+});
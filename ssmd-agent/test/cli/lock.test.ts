@@ -0,0 +1,81 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { renderConfigMaps } from "../../src/cli/commands/feed-publish.ts";
+import { buildLock, diffLock, formatLock, verifyLock, writeLock } from "../../src/cli/commands/lock.ts";
+import { checkLock } from "../../src/cli/commands/validate.ts";
+
+const KALSHI_YAML = `name: kalshi
+type: websocket
+versions:
+  - version: v1
+    effective_from: "2025-12-22"
+    protocol:
+      transport: wss
+      message: json
+    endpoint: wss://api.elections.kalshi.com/trade-api/ws/v2
+  - version: v2
+    effective_from: "2026-03-01"
+    protocol:
+      transport: wss
+      message: json
+    endpoint: wss://api.elections.kalshi.com/trade-api/ws/v2
+`;
+
+async function makeExchangesDir(): Promise<string> {
+  const root = await Deno.makeTempDir();
+  for (const dir of ["feeds", "schemas", "environments"]) {
+    await Deno.mkdir(join(root, dir));
+  }
+  await Deno.writeTextFile(join(root, "feeds", "kalshi.yaml"), KALSHI_YAML);
+  await Deno.writeTextFile(join(root, "schemas", "trade.capnp"), "struct Trade {}\n");
+  await Deno.writeTextFile(join(root, "environments", "prod.yaml"), "name: prod\nfeed: kalshi\n");
+  return root;
+}
+
+Deno.test("buildLock records hashes and the latest feed version", async () => {
+  const root = await makeExchangesDir();
+  try {
+    const lock = await buildLock(await renderConfigMaps(root));
+    assertEquals(lock.feeds.kalshi.version, "v2");
+    assertEquals(lock.feeds.kalshi.effective_from, "2026-03-01");
+    assertEquals(lock.feeds.kalshi.sha256.length, 64);
+    assertEquals(Object.keys(lock.schemas), ["trade.capnp"]);
+    assertEquals(Object.keys(lock.environments), ["prod"]);
+    // Deterministic: unchanged config serializes identically
+    assertEquals(formatLock(lock), formatLock(await buildLock(await renderConfigMaps(root))));
+  } finally {
+    await Deno.remove(root, { recursive: true });
+  }
+});
+
+Deno.test("verifyLock reports drift against the written lock", async () => {
+  const root = await makeExchangesDir();
+  try {
+    assertEquals(await verifyLock(root), null);
+    assertEquals(await checkLock(root), []);
+
+    await writeLock(root);
+    assertEquals(await verifyLock(root), []);
+
+    await Deno.writeTextFile(join(root, "schemas", "trade.capnp"), "struct Trade { price @0 :Float64; }\n");
+    await Deno.writeTextFile(join(root, "environments", "dev.yaml"), "name: dev\nfeed: kalshi\n");
+    assertEquals(await verifyLock(root), ["schema trade.capnp: changed", "environment dev: added"]);
+    assertEquals((await checkLock(root)).map((i) => `${i.file}: ${i.message}`), [
+      "ssmd.lock: drifted: schema trade.capnp: changed",
+      "ssmd.lock: drifted: environment dev: added",
+    ]);
+  } finally {
+    await Deno.remove(root, { recursive: true });
+  }
+});
+
+Deno.test("diffLock reports feed version changes", () => {
+  const base = { lock_version: 1, schemas: {}, environments: {} };
+  assertEquals(
+    diffLock(
+      { ...base, feeds: { kalshi: { version: "v1", effective_from: "2025-12-22", sha256: "a" } } },
+      { ...base, feeds: { kalshi: { version: "v2", effective_from: "2026-03-01", sha256: "b" } } },
+    ),
+    ["feed kalshi: changed", "feed kalshi: version v1 -> v2"],
+  );
+});
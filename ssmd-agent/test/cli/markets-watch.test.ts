@@ -0,0 +1,53 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  csvLine,
+  parseSseEvents,
+  type Quote,
+  quoteUpdate,
+  sortQuotes,
+} from "../../src/cli/commands/markets-watch.ts";
+
+Deno.test("quoteUpdate reads Kalshi cents and dollar-string fields", () => {
+  assertEquals(
+    quoteUpdate("kalshi", "ticker", { type: "ticker", msg: { yes_bid: 41, yes_ask: 43, price: 42, volume: 1200 } }),
+    { bid: 0.41, ask: 0.43, last: 0.42, volume: 1200 },
+  );
+  assertEquals(
+    quoteUpdate("kalshi", "ticker", { msg: { yes_bid_dollars: "0.4100", yes_ask_dollars: "0.4300" } }),
+    { bid: 0.41, ask: 0.43 },
+  );
+  assertEquals(quoteUpdate("kalshi", "trade", { msg: { yes_price: 44, count: 10 } }), { last: 0.44 });
+  assertEquals(quoteUpdate("kalshi", "market_lifecycle_v2", { msg: {} }), null);
+});
+
+Deno.test("quoteUpdate reads Kraken spot and futures tickers", () => {
+  assertEquals(
+    quoteUpdate("kraken-spot", "ticker", { channel: "ticker", data: [{ bid: 97000.1, ask: 97000.2, last: 97000.1, volume: 12.5 }] }),
+    { bid: 97000.1, ask: 97000.2, last: 97000.1, volume: 12.5 },
+  );
+  assertEquals(quoteUpdate("kraken-futures", "trade", { feed: "trade", price: 97010 }), { last: 97010 });
+});
+
+Deno.test("parseSseEvents returns complete events and keeps the remainder", () => {
+  const { events, rest } = parseSseEvents(
+    'event: subscribed\ndata: {"subjects":[]}\n\n: keepalive\n\nevent: message\ndata: {"ticker":"A"}\n\nevent: mess',
+  );
+  assertEquals(events, [
+    { event: "subscribed", data: '{"subjects":[]}' },
+    { event: "message", data: '{"ticker":"A"}' },
+  ]);
+  assertEquals(rest, "event: mess");
+});
+
+Deno.test("sortQuotes puts missing values last", () => {
+  const q = (ticker: string, volume: number | null): Quote =>
+    ({ ticker, bid: null, ask: null, last: null, volume, updates: 1, updatedAt: null });
+  const quotes = [q("A", 10), q("B", null), q("C", 30)];
+  assertEquals(sortQuotes(quotes, "volume", true).map((x) => x.ticker), ["C", "A", "B"]);
+  assertEquals(sortQuotes(quotes, "volume", false).map((x) => x.ticker), ["A", "C", "B"]);
+  assertEquals(sortQuotes(quotes, "ticker", true).map((x) => x.ticker), ["C", "B", "A"]);
+});
+
+Deno.test("csvLine leaves fields the update did not carry empty", () => {
+  assertEquals(csvLine("2026-01-05T15:00:00.000Z", "A", "trade", { last: 0.44 }), "2026-01-05T15:00:00.000Z,A,trade,,,0.44,");
+});
@@ -0,0 +1,39 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { buildMarketsQuery, parseDuration } from "../../src/cli/commands/markets.ts";
+
+Deno.test("parseDuration accepts minutes, hours and days", () => {
+  assertEquals(parseDuration("30m"), 30 * 60_000);
+  assertEquals(parseDuration("24h"), 24 * 3_600_000);
+  assertEquals(parseDuration("7d"), 7 * 86_400_000);
+  assertEquals(parseDuration("0h"), null);
+  assertEquals(parseDuration("1w"), null);
+  assertEquals(parseDuration("soon"), null);
+});
+
+Deno.test("buildMarketsQuery maps list flags to /v1/markets params", () => {
+  const now = new Date("2026-01-05T12:00:00Z");
+  const params = buildMarketsQuery({
+    _: ["markets", "list"],
+    exchange: "kalshi",
+    category: "Politics",
+    status: "open",
+    "closing-within": "24h",
+    "min-volume": "1000",
+    sort: "volume",
+  }, now);
+
+  assertEquals(Object.fromEntries(params), {
+    exchange: "kalshi",
+    category: "Politics",
+    status: "open",
+    closing_before: "2026-01-06T12:00:00.000Z",
+    min_volume: "1000",
+    sort: "volume",
+  });
+});
+
+Deno.test("buildMarketsQuery rejects invalid flags", () => {
+  assertThrows(() => buildMarketsQuery({ _: [], "closing-within": "tomorrow" }), Error, "--closing-within");
+  assertThrows(() => buildMarketsQuery({ _: [], "min-volume": "-5" }), Error, "--min-volume");
+  assertThrows(() => buildMarketsQuery({ _: [], sort: "popularity" }), Error, "--sort");
+});
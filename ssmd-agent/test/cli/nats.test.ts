@@ -0,0 +1,28 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { type ConsumerLag, findLagBreaches, parseThreshold } from "../../src/cli/commands/nats.ts";
+
+function lag(consumer: string, pending: number, ackPending: number, redelivered: number): ConsumerLag {
+  return { consumer, pending, ack_pending: ackPending, redelivered, delivered_seq: 0, ack_floor_seq: 0 };
+}
+
+Deno.test("findLagBreaches reports counts strictly above each limit", () => {
+  const lags = [lag("archiver-kalshi", 5000, 10, 0), lag("signal-volume", 100, 500, 3)];
+
+  assertEquals(findLagBreaches(lags, { maxPending: 1000, maxAckPending: 500, maxRedelivered: 2 }), [
+    { consumer: "archiver-kalshi", metric: "pending", value: 5000, limit: 1000 },
+    { consumer: "signal-volume", metric: "redelivered", value: 3, limit: 2 },
+  ]);
+});
+
+Deno.test("findLagBreaches ignores unset thresholds", () => {
+  assertEquals(findLagBreaches([lag("archiver-kalshi", 5000, 5000, 5000)], {}), []);
+});
+
+Deno.test("parseThreshold accepts non-negative integers", () => {
+  assertEquals(parseThreshold("max-pending", undefined), undefined);
+  assertEquals(parseThreshold("max-pending", "0"), 0);
+  assertEquals(parseThreshold("max-pending", "1000"), 1000);
+  assertThrows(() => parseThreshold("max-pending", "-1"), Error, "--max-pending");
+  assertThrows(() => parseThreshold("max-pending", "1.5"), Error, "--max-pending");
+  assertThrows(() => parseThreshold("max-pending", "lots"), Error, "--max-pending");
+});
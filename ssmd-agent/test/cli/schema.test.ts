@@ -0,0 +1,145 @@
+import { assertEquals, assertRejects } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { parse as parseYaml } from "yaml";
+import { addSchemaVersion, checkSchema, loadSchemaVersions, rehashSchemaSources } from "../../src/cli/commands/schema.ts";
+import { rawSourceHash } from "../../src/lib/schema/mod.ts";
+import { validateWorkspace } from "../../src/cli/commands/validate.ts";
+
+const TRADE_V1 = `feed: kalshi
+type: trade
+version: v1
+fields:
+  msg.market_ticker: { type: string, required: true }
+  msg.yes_price: { type: integer, required: true }
+`;
+
+const FEED = `name: kalshi
+type: websocket
+versions:
+  - version: v1
+    effective_from: "2025-01-01"
+    protocol: { transport: wss, message: json }
+    endpoint: wss://api.elections.kalshi.com/trade-api/ws/v2
+`;
+
+async function workspace(): Promise<string> {
+  const root = await Deno.makeTempDir();
+  await Deno.mkdir(join(root, "feeds"));
+  await Deno.mkdir(join(root, "schemas"));
+  await Deno.writeTextFile(join(root, "feeds", "kalshi.yaml"), FEED);
+  await Deno.writeTextFile(join(root, "schemas", "kalshi-trade.yaml"), TRADE_V1);
+  return root;
+}
+
+Deno.test("addSchemaVersion records compatible_with for a compatible JSON Schema", async () => {
+  const root = await workspace();
+  const source = join(root, "trade-v2.json");
+  await Deno.writeTextFile(source, JSON.stringify({
+    type: "object",
+    required: ["msg"],
+    properties: {
+      msg: {
+        type: "object",
+        required: ["market_ticker", "yes_price"],
+        properties: {
+          market_ticker: { type: "string" },
+          yes_price: { type: "number" },
+          count: { type: "integer" },
+        },
+      },
+    },
+  }));
+
+  const result = await addSchemaVersion(root, "kalshi/trade", "v2", { file: source });
+  assertEquals(result.previous, "v1");
+  assertEquals(result.compatibleWith, ["v1"]);
+  const written = parseYaml(await Deno.readTextFile(join(root, "schemas", "kalshi-trade-v2.yaml"))) as Record<string, unknown>;
+  assertEquals(written.compatible_with, ["v1"]);
+  assertEquals((written.source as { format: string; file: string }).format, "json-schema");
+  assertEquals(await validateWorkspace(root), []);
+
+  // Editing the kept source without a new version is reported
+  await Deno.writeTextFile(join(root, "schemas", "sources", "kalshi-trade-v2.json"), "{}");
+  assertEquals((await validateWorkspace(root)).map((i) => i.message), [
+    "source sources/kalshi-trade-v2.json changed since its fields were generated; add a new version instead",
+  ]);
+
+  const check = checkSchema(await loadSchemaVersions(root), "kalshi/trade");
+  assertEquals([check.from, check.to], ["v1", "v2"]);
+  assertEquals(check.changes.map((c) => [c.path, c.kind, c.breaking]), [
+    ["msg.count", "added", false],
+    ["msg.yes_price", "type_changed", false],
+  ]);
+
+  await Deno.remove(root, { recursive: true });
+});
+
+Deno.test("addSchemaVersion fails on undeclared breaking changes", async () => {
+  const root = await workspace();
+  const source = join(root, "trade.proto");
+  await Deno.writeTextFile(source, `syntax = "proto2";
+message Trade {
+  message Msg {
+    required string market_ticker = 1;
+    required string yes_price = 2;
+  }
+  required Msg msg = 1;
+}
+`);
+
+  await assertRejects(
+    () => addSchemaVersion(root, "kalshi/trade", "v2", { file: source }),
+    Error,
+    "~ msg.yes_price: integer (required) -> string (required)",
+  );
+  await assertRejects(
+    () => addSchemaVersion(root, "kalshi/trade", "v2", { file: source, compatibleWith: ["v1"], breaking: true }),
+    Error,
+    "declares compatibility but has breaking changes",
+  );
+
+  const result = await addSchemaVersion(root, "kalshi/trade", "v2", { file: source, breaking: true });
+  assertEquals(result.compatibleWith, []);
+  assertEquals((await loadSchemaVersions(root)).map((v) => v.version), ["v1", "v2"]);
+
+  await Deno.remove(root, { recursive: true });
+});
+
+Deno.test("rehashSchemaSources moves raw source hashes to canonical ones", async () => {
+  const root = await workspace();
+  const proto = `syntax = "proto2";
+message Trade {
+  message Msg {
+    required string market_ticker = 1;
+    required int64 yes_price = 2;
+  }
+  required Msg msg = 1;
+}
+`;
+  await Deno.mkdir(join(root, "schemas", "sources"));
+  await Deno.writeTextFile(join(root, "schemas", "sources", "kalshi-trade-v2.proto"), proto);
+  // A version recorded before canonical hashing: raw sha256 of the bytes
+  const v2 = TRADE_V1.replace("version: v1", "version: v2") +
+    `source:\n  format: protobuf\n  file: sources/kalshi-trade-v2.proto\n  sha256: ${await rawSourceHash(new TextEncoder().encode(proto))}\n`;
+  await Deno.writeTextFile(join(root, "schemas", "kalshi-trade-v2.yaml"), v2);
+  assertEquals(await validateWorkspace(root), []);
+
+  assertEquals((await rehashSchemaSources(root, true)).map((r) => r.status), ["rehashed"]);
+  assertEquals(await Deno.readTextFile(join(root, "schemas", "kalshi-trade-v2.yaml")), v2);
+
+  assertEquals((await rehashSchemaSources(root)).map((r) => r.status), ["rehashed"]);
+  const written = parseYaml(await Deno.readTextFile(join(root, "schemas", "kalshi-trade-v2.yaml"))) as {
+    source: Record<string, string>;
+  };
+  assertEquals(Object.keys(written.source).sort(), ["canonical_sha256", "file", "format"]);
+  assertEquals((await rehashSchemaSources(root)).map((r) => r.status), ["current"]);
+
+  // Comments and formatting no longer change the hash; a field change does
+  const sourcePath = join(root, "schemas", "sources", "kalshi-trade-v2.proto");
+  await Deno.writeTextFile(sourcePath, "// reformatted\n" + proto.replace(/\n  +/g, "\n    "));
+  assertEquals(await validateWorkspace(root), []);
+  await Deno.writeTextFile(sourcePath, proto.replace("int64 yes_price", "string yes_price"));
+  assertEquals((await validateWorkspace(root)).length, 1);
+
+  await Deno.remove(root, { recursive: true });
+});
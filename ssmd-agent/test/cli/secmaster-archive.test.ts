@@ -0,0 +1,44 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  duckColumns,
+  SNAPSHOT_TABLES,
+  snapshotPrefix,
+  toSnapshotRow,
+} from "../../src/cli/commands/secmaster-archive.ts";
+
+const markets = SNAPSHOT_TABLES.find((t) => t.table === "markets")!;
+
+Deno.test("snapshotPrefix puts snapshots under secmaster/<date>/", () => {
+  assertEquals(snapshotPrefix("2026-01-05"), "secmaster/2026-01-05/");
+});
+
+Deno.test("toSnapshotRow keeps snapshot columns and normalizes postgres values", () => {
+  const row = toSnapshotRow(
+    {
+      ticker: "KXBTCD-26JAN05-T98000",
+      event_ticker: "KXBTCD-26JAN05",
+      yes_bid: "0.5200",
+      volume: "1234",
+      floor_strike: "98000",
+      can_close_early: true,
+      close_time: new Date("2026-01-05T17:00:00Z"),
+      deleted_at: null,
+      not_snapshotted: "x",
+    },
+    markets.columns,
+  );
+  assertEquals(row.ticker, "KXBTCD-26JAN05-T98000");
+  assertEquals(row.yes_bid, 0.52);
+  assertEquals(row.volume, 1234);
+  assertEquals(row.floor_strike, 98000);
+  assertEquals(row.can_close_early, true);
+  assertEquals(row.close_time, "2026-01-05T17:00:00.000Z");
+  assertEquals(row.deleted_at, null);
+  assertEquals(row.title, null);
+  assertEquals("not_snapshotted" in row, false);
+  assertEquals(Object.keys(row), Object.keys(markets.columns));
+});
+
+Deno.test("duckColumns renders a read_json columns struct", () => {
+  assertEquals(duckColumns({ ticker: "VARCHAR", volume: "BIGINT" }), "{'ticker': 'VARCHAR', 'volume': 'BIGINT'}");
+});
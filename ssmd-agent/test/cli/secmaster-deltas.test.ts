@@ -0,0 +1,126 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  DeltaPublisher,
+  type DeltaSink,
+  type MarketDeltaBatch,
+  marketDeltas,
+} from "../../src/cli/commands/secmaster-deltas.ts";
+import type { MarketRow } from "../../src/lib/db/markets.ts";
+import type { Market } from "../../src/lib/types/market.ts";
+
+const CLOSE = "2026-01-15T22:00:00.000Z";
+
+function marketRow(ticker: string, overrides: Partial<MarketRow> = {}): MarketRow {
+  return {
+    ticker,
+    eventTicker: "KXBTCD-26JAN1517",
+    title: "Bitcoin above 97,500?",
+    status: "active",
+    closeTime: new Date(CLOSE),
+    yesBid: "0.4200",
+    yesAsk: "0.4400",
+    noBid: null,
+    noAsk: null,
+    lastPrice: "0.4300",
+    volume: 1200,
+    volume24h: 300,
+    openInterest: 900,
+    floorStrike: null,
+    capStrike: null,
+    strikeType: null,
+    result: null,
+    expirationValue: null,
+    yesSubTitle: null,
+    noSubTitle: null,
+    canCloseEarly: true,
+    marketType: "binary",
+    openTime: null,
+    expectedExpirationTime: null,
+    createdAt: new Date(CLOSE),
+    updatedAt: new Date(CLOSE),
+    deletedAt: null,
+    ...overrides,
+  };
+}
+
+function apiMarket(ticker: string, overrides: Partial<Market> = {}): Market {
+  return {
+    ticker,
+    event_ticker: "KXBTCD-26JAN1517",
+    title: "Bitcoin above 97,500?",
+    status: "active",
+    close_time: "2026-01-15T22:00:00Z",
+    yes_bid: 0.45,
+    ...overrides,
+  } as Market;
+}
+
+class RecordingSink implements DeltaSink {
+  messages: Array<{ subject: string; body: MarketDeltaBatch }> = [];
+  flushes = 0;
+  publish(subject: string, data: Uint8Array): void {
+    this.messages.push({ subject, body: JSON.parse(new TextDecoder().decode(data)) });
+  }
+  flush(): Promise<void> {
+    this.flushes++;
+    return Promise.resolve();
+  }
+}
+
+Deno.test("marketDeltas reports new markets and status or close_time changes only", () => {
+  const existing = new Map([
+    ["SAME", marketRow("SAME")],
+    ["PRICE", marketRow("PRICE")],
+    ["CLOSED", marketRow("CLOSED")],
+    ["MOVED", marketRow("MOVED")],
+  ]);
+  const deltas = marketDeltas([
+    apiMarket("SAME"),
+    apiMarket("PRICE", { yes_bid: 0.9 }),
+    apiMarket("CLOSED", { status: "closed" }),
+    apiMarket("MOVED", { close_time: "2026-01-16T22:00:00Z" }),
+    apiMarket("NEW"),
+  ], existing);
+
+  assertEquals(deltas, [
+    {
+      ticker: "CLOSED",
+      event_ticker: "KXBTCD-26JAN1517",
+      status: "closed",
+      close_time: CLOSE,
+      change: "updated",
+      prev_status: "active",
+    },
+    {
+      ticker: "MOVED",
+      event_ticker: "KXBTCD-26JAN1517",
+      status: "active",
+      close_time: "2026-01-16T22:00:00.000Z",
+      change: "updated",
+      prev_close_time: CLOSE,
+    },
+    { ticker: "NEW", event_ticker: "KXBTCD-26JAN1517", status: "active", close_time: CLOSE, change: "new" },
+  ]);
+});
+
+Deno.test("DeltaPublisher batches deltas and publishes each ticker once", async () => {
+  const sink = new RecordingSink();
+  const publisher = new DeltaPublisher(sink, "secmaster.updates.markets", 2);
+  const delta = (ticker: string) => ({
+    ticker,
+    event_ticker: "E",
+    change: "new" as const,
+    status: "active",
+    close_time: null,
+  });
+
+  publisher.add([delta("A"), delta("B"), delta("A")]);
+  assertEquals(sink.messages.length, 1);
+  publisher.add([delta("C")]);
+  await publisher.flush();
+
+  assertEquals(sink.messages.map((m) => m.subject), ["secmaster.updates.markets", "secmaster.updates.markets"]);
+  assertEquals(sink.messages.map((m) => m.body.markets.map((d) => d.ticker)), [["A", "B"], ["C"]]);
+  assertEquals(publisher.published, 3);
+  assertEquals(sink.flushes, 1);
+});
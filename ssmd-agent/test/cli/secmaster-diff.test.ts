@@ -0,0 +1,152 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { diffEvents, diffMarkets, emptySyncDiff, normalizeDiffValue } from "../../src/cli/commands/secmaster-diff.ts";
+import type { EventRow } from "../../src/lib/db/events.ts";
+import type { MarketRow } from "../../src/lib/db/markets.ts";
+import type { Event } from "../../src/lib/types/event.ts";
+import type { Market } from "../../src/lib/types/market.ts";
+
+const NOW = new Date("2026-01-15T00:00:00Z");
+
+function eventRow(overrides: Partial<EventRow> = {}): EventRow {
+  return {
+    eventTicker: "KXBTCD-26JAN1517",
+    title: "Bitcoin price at 5pm",
+    category: "Crypto",
+    seriesTicker: "KXBTCD",
+    strikeDate: new Date("2026-01-15T22:00:00Z"),
+    mutuallyExclusive: false,
+    status: "active",
+    createdAt: NOW,
+    updatedAt: NOW,
+    deletedAt: null,
+    ...overrides,
+  };
+}
+
+function apiEvent(overrides: Partial<Event> = {}): Event {
+  return {
+    event_ticker: "KXBTCD-26JAN1517",
+    title: "Bitcoin price at 5pm",
+    category: "Crypto",
+    series_ticker: "KXBTCD",
+    strike_date: "2026-01-15T22:00:00Z",
+    mutually_exclusive: false,
+    status: "active",
+    ...overrides,
+  };
+}
+
+function marketRow(overrides: Partial<MarketRow> = {}): MarketRow {
+  return {
+    ticker: "KXBTCD-26JAN1517-T97499.99",
+    eventTicker: "KXBTCD-26JAN1517",
+    title: "Bitcoin above 97,500?",
+    status: "active",
+    closeTime: new Date("2026-01-15T22:00:00Z"),
+    yesBid: "0.4200",
+    yesAsk: "0.4400",
+    noBid: "0.5600",
+    noAsk: "0.5800",
+    lastPrice: "0.4300",
+    volume: 1200,
+    volume24h: 300,
+    openInterest: 900,
+    floorStrike: "97499.99",
+    capStrike: null,
+    strikeType: "greater",
+    result: "",
+    expirationValue: null,
+    yesSubTitle: null,
+    noSubTitle: null,
+    canCloseEarly: true,
+    marketType: "binary",
+    openTime: null,
+    expectedExpirationTime: null,
+    createdAt: NOW,
+    updatedAt: NOW,
+    deletedAt: null,
+    ...overrides,
+  };
+}
+
+function apiMarket(overrides: Partial<Market> = {}): Market {
+  return {
+    ticker: "KXBTCD-26JAN1517-T97499.99",
+    event_ticker: "KXBTCD-26JAN1517",
+    title: "Bitcoin above 97,500?",
+    status: "active",
+    close_time: "2026-01-15T22:00:00Z",
+    yes_bid: 0.45,
+    yes_ask: 0.47,
+    volume: 5000,
+    volume_24h: 800,
+    open_interest: 1000,
+    floor_strike: 97499.99,
+    strike_type: "greater",
+    can_close_early: true,
+    market_type: "binary",
+    ...overrides,
+  } as Market;
+}
+
+Deno.test("normalizeDiffValue compares column and API representations", () => {
+  assertEquals(normalizeDiffValue(new Date("2026-01-15T22:00:00Z")), normalizeDiffValue("2026-01-15T22:00:00Z"));
+  assertEquals(normalizeDiffValue("97499.99"), 97499.99);
+  assertEquals(normalizeDiffValue(""), null);
+  assertEquals(normalizeDiffValue(undefined), null);
+  assertEquals(normalizeDiffValue("Bitcoin"), "Bitcoin");
+});
+
+Deno.test("diffEvents splits new, updated, status transitions and unchanged", () => {
+  const diff = emptySyncDiff();
+  const existing = new Map([
+    ["A", eventRow({ eventTicker: "A" })],
+    ["B", eventRow({ eventTicker: "B" })],
+    ["C", eventRow({ eventTicker: "C" })],
+  ]);
+  diffEvents(diff, [
+    apiEvent({ event_ticker: "A" }),
+    apiEvent({ event_ticker: "B", title: "Bitcoin price at 6pm", status: "closed" }),
+    apiEvent({ event_ticker: "C", status: "settled" }),
+    apiEvent({ event_ticker: "D" }),
+  ], existing);
+
+  assertEquals(diff.events.new, ["D"]);
+  assertEquals(diff.events.updated, [
+    { ticker: "B", changes: { title: { from: "Bitcoin price at 5pm", to: "Bitcoin price at 6pm" } } },
+  ]);
+  assertEquals(diff.events.statusTransitions, [
+    { ticker: "B", from: "active", to: "closed" },
+    { ticker: "C", from: "active", to: "settled" },
+  ]);
+  assertEquals(diff.events.unchanged, 1);
+  assertEquals(diff.markets.new, []);
+});
+
+Deno.test("diffMarkets ignores prices and volume", () => {
+  const diff = emptySyncDiff();
+  diffMarkets(diff, [apiMarket()], new Map([[marketRow().ticker, marketRow()]]));
+  assertEquals(diff.markets.updated, []);
+  assertEquals(diff.markets.unchanged, 1);
+});
+
+Deno.test("diffMarkets reports settlement fields and counts a ticker once", () => {
+  const diff = emptySyncDiff();
+  const settled = apiMarket({ status: "settled", result: "yes", expiration_value: "98210.55" });
+  const existing = new Map([[marketRow().ticker, marketRow()]]);
+  diffMarkets(diff, [settled], existing);
+  // Fetched again by a later pass
+  diffMarkets(diff, [settled], existing);
+
+  assertEquals(diff.markets.statusTransitions, [
+    { ticker: "KXBTCD-26JAN1517-T97499.99", from: "active", to: "settled" },
+  ]);
+  assertEquals(diff.markets.updated, [{
+    ticker: "KXBTCD-26JAN1517-T97499.99",
+    changes: {
+      result: { from: null, to: "yes" },
+      expiration_value: { from: null, to: 98210.55 },
+    },
+  }]);
+  assertEquals(JSON.parse(JSON.stringify(diff)).markets.unchanged, 0);
+});
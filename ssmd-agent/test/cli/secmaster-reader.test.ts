@@ -0,0 +1,66 @@
+import { assertEquals, assertRejects, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { ApiError, secmasterRead, secmasterSource } from "../../src/cli/commands/secmaster-reader.ts";
+
+const QUERY = { path: "/v1/secmaster/stats", db: () => Promise.resolve({ at: new Date(0), total: 3 }) };
+
+async function withEnv(env: Record<string, string | undefined>, fn: () => Promise<void>): Promise<void> {
+  const saved = Object.fromEntries(Object.keys(env).map((k) => [k, Deno.env.get(k)]));
+  const set = (vars: Record<string, string | undefined>) => {
+    for (const [k, v] of Object.entries(vars)) v === undefined ? Deno.env.delete(k) : Deno.env.set(k, v);
+  };
+  set(env);
+  try {
+    await fn();
+  } finally {
+    set(saved);
+  }
+}
+
+async function withFetch(impl: () => Promise<Response>, fn: () => Promise<void>): Promise<void> {
+  const orig = globalThis.fetch;
+  globalThis.fetch = impl;
+  try {
+    await fn();
+  } finally {
+    globalThis.fetch = orig;
+  }
+}
+
+Deno.test("secmasterSource defaults to auto and rejects unknown values", async () => {
+  await withEnv({ SSMD_SECMASTER_SOURCE: undefined }, () => {
+    assertEquals(secmasterSource(undefined), "auto");
+    assertEquals(secmasterSource("db"), "db");
+    assertThrows(() => secmasterSource("postgres"), Error, "--source");
+    return Promise.resolve();
+  });
+  await withEnv({ SSMD_SECMASTER_SOURCE: "api" }, () => {
+    assertEquals(secmasterSource(undefined), "api");
+    return Promise.resolve();
+  });
+});
+
+Deno.test("secmasterRead falls back to the DB when the API is unreachable", async () => {
+  await withEnv({ DATABASE_URL: "postgres://ssmd@127.0.0.1:1/ssmd" }, async () => {
+    await withFetch(() => Promise.reject(new TypeError("connection refused")), async () => {
+      // Serialized like the API response
+      assertEquals(await secmasterRead("auto", QUERY), { at: "1970-01-01T00:00:00.000Z", total: 3 });
+      await assertRejects(() => secmasterRead("api", QUERY), TypeError);
+    });
+  });
+});
+
+Deno.test("secmasterRead reports API errors without falling back", async () => {
+  await withEnv({ DATABASE_URL: "postgres://ssmd@127.0.0.1:1/ssmd" }, async () => {
+    await withFetch(() => Promise.resolve(new Response('{"error":"Invalid API key"}', { status: 401 })), async () => {
+      await assertRejects(() => secmasterRead("auto", QUERY), ApiError, "API error: 401");
+    });
+  });
+});
+
+Deno.test("secmasterRead without DATABASE_URL keeps the API failure", async () => {
+  await withEnv({ DATABASE_URL: undefined }, async () => {
+    await withFetch(() => Promise.reject(new TypeError("connection refused")), async () => {
+      await assertRejects(() => secmasterRead("auto", QUERY), TypeError, "connection refused");
+    });
+  });
+});
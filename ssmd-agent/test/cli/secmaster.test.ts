@@ -1,5 +1,5 @@
 import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
-import { type SyncOptions, printSyncSummary, type SyncResult } from "../../src/cli/commands/secmaster.ts";
+import { type SyncOptions, printSyncSummary, secmasterListPath, type SyncResult } from "../../src/cli/commands/secmaster.ts";
 
 Deno.test("SyncOptions types correctly", () => {
   const options: SyncOptions = {
@@ -53,3 +53,20 @@ Deno.test("printSyncSummary handles zero values", () => {
   // Should not throw
   printSyncSummary(result);
 });
+
+Deno.test("secmasterListPath lists across exchanges by default", () => {
+  assertEquals(secmasterListPath("events", {}), "/v1/events");
+  assertEquals(
+    secmasterListPath("markets", { status: "active", event: "KXBTC-26JAN05", limit: "10" }),
+    "/v1/markets?status=active&event=KXBTC-26JAN05&limit=10",
+  );
+});
+
+Deno.test("secmasterListPath pins --exchange in the path", () => {
+  assertEquals(
+    secmasterListPath("markets", { exchange: "kalshi", series: "KXBTCD" }),
+    "/v1/secmaster/kalshi/markets?series=KXBTCD",
+  );
+  // --event only filters markets
+  assertEquals(secmasterListPath("events", { exchange: "polymarket", event: "X" }), "/v1/secmaster/polymarket/events");
+});
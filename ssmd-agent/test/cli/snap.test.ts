@@ -0,0 +1,35 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { formatAge, formatSnap } from "../../src/cli/commands/snap.ts";
+
+Deno.test("formatAge picks a readable unit", () => {
+  assertEquals(formatAge(850), "850ms");
+  assertEquals(formatAge(12_345), "12.3s");
+  assertEquals(formatAge(245_000), "4m05s");
+});
+
+Deno.test("formatSnap summarizes a merged Kalshi ticker snapshot", () => {
+  assertEquals(
+    formatSnap({
+      feed: "kalshi",
+      ticker: "KXBTCD-26JAN05-T98000",
+      snap_at: "2026-01-05T15:00:00.000Z",
+      age_ms: 1_200,
+      snapshot: { type: "ticker", msg: { yes_bid: 41, yes_ask: 43, price: 42, volume: 1200 } },
+    }),
+    [
+      "KXBTCD-26JAN05-T98000 (kalshi)",
+      "  snap_at   2026-01-05T15:00:00.000Z (1.2s ago)",
+      "  bid       0.41",
+      "  ask       0.43",
+      "  last      0.42",
+      "  volume    1200",
+    ],
+  );
+});
+
+Deno.test("formatSnap omits quote fields for feeds it cannot read", () => {
+  assertEquals(
+    formatSnap({ feed: "polymarket", ticker: "0xabc", snap_at: null, age_ms: null, snapshot: { price_changes: [] } }),
+    ["0xabc (polymarket)"],
+  );
+});
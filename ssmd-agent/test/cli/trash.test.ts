@@ -0,0 +1,76 @@
+import { assertEquals, assertRejects } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { createFeed, deleteFeed, environmentsReferencingFeed } from "../../src/cli/commands/feed.ts";
+import { deleteEnvironment } from "../../src/cli/commands/env.ts";
+import { listTrash, restoreFromTrash } from "../../src/cli/commands/trash.ts";
+
+async function exists(path: string): Promise<boolean> {
+  try {
+    await Deno.stat(path);
+    return true;
+  } catch {
+    return false;
+  }
+}
+
+async function exchanges(): Promise<string> {
+  const root = await Deno.makeTempDir();
+  await createFeed(join(root, "feeds"), "kalshi", { type: "websocket", endpoint: "wss://kalshi.com/ws" });
+  await createFeed(join(root, "feeds"), "kraken", { type: "websocket", endpoint: "wss://ws.kraken.com/v2" });
+  await Deno.mkdir(join(root, "environments"));
+  await Deno.writeTextFile(join(root, "environments", "kalshi-prod.yaml"), "name: kalshi-prod\nfeed: kalshi\n");
+  await Deno.writeTextFile(join(root, "environments", "kalshi-dev.yml"), "name: kalshi-dev\nfeed: kalshi\n");
+  return root;
+}
+
+Deno.test("deleteFeed refuses while environments reference the feed", async () => {
+  const root = await exchanges();
+  assertEquals(await environmentsReferencingFeed(root, "kalshi"), ["kalshi-dev", "kalshi-prod"]);
+
+  await assertRejects(() => deleteFeed(root, "kalshi"), Error, "kalshi-dev, kalshi-prod");
+  assertEquals(await exists(join(root, "feeds", "kalshi.yaml")), true);
+  await assertRejects(() => deleteFeed(root, "binance"), Error, "not found");
+
+  const t = await deleteFeed(root, "kraken");
+  assertEquals(t.path, join("feeds", "kraken.yaml"));
+  assertEquals(t.referenced_by, undefined);
+  assertEquals(await exists(join(root, "feeds", "kraken.yaml")), false);
+
+  await Deno.remove(root, { recursive: true });
+});
+
+Deno.test("deleteFeed --force records the references and restore undoes it", async () => {
+  const root = await exchanges();
+  const original = await Deno.readTextFile(join(root, "feeds", "kalshi.yaml"));
+
+  const t = await deleteFeed(root, "kalshi", true);
+  assertEquals(t.referenced_by, ["kalshi-dev", "kalshi-prod"]);
+  assertEquals((await listTrash(root)).map((x) => x.id), [t.id]);
+
+  const restored = await restoreFromTrash(root, "kalshi");
+  assertEquals(restored.id, t.id);
+  assertEquals(await Deno.readTextFile(join(root, "feeds", "kalshi.yaml")), original);
+  assertEquals(await listTrash(root), []);
+
+  await Deno.remove(root, { recursive: true });
+});
+
+Deno.test("deleteEnvironment trashes the env file; restore will not overwrite", async () => {
+  const root = await exchanges();
+
+  const first = await deleteEnvironment(root, "kalshi-dev");
+  assertEquals(first.kind, "environment");
+  assertEquals(first.path, join("environments", "kalshi-dev.yml"));
+  await assertRejects(() => deleteEnvironment(root, "kalshi-dev"), Error, "not found");
+
+  // Recreated after the delete
+  await Deno.writeTextFile(join(root, "environments", "kalshi-dev.yml"), "name: kalshi-dev\nfeed: kraken\n");
+  await assertRejects(() => restoreFromTrash(root, first.id), Error, "already exists");
+  await assertRejects(() => restoreFromTrash(root, "nope"), Error, "Nothing named");
+
+  await Deno.remove(join(root, "environments", "kalshi-dev.yml"));
+  await restoreFromTrash(root, first.id);
+  assertEquals(await environmentsReferencingFeed(root, "kalshi"), ["kalshi-dev", "kalshi-prod"]);
+
+  await Deno.remove(root, { recursive: true });
+});
@@ -0,0 +1,77 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { snapshotFromFiles } from "../../src/cli/commands/diff.ts";
+import {
+  checkKeySources,
+  keyCheckIssues,
+  type KeyResolvers,
+  parseKeySource,
+  SkipCheck,
+} from "../../src/cli/commands/validate-keys.ts";
+
+Deno.test("parseKeySource reads env, sealed-secret and vault sources", () => {
+  assertEquals(parseKeySource("env:A, B"), { kind: "env", vars: ["A", "B"] });
+  assertEquals(parseKeySource("sealed-secret:ssmd/kalshi-credentials"), {
+    kind: "sealed-secret",
+    namespace: "ssmd",
+    name: "kalshi-credentials",
+  });
+  assertEquals(parseKeySource("sealed-secret:kalshi-credentials"), { kind: "sealed-secret", name: "kalshi-credentials" });
+  assertEquals(parseKeySource("vault:/secret/data/ssmd/kalshi"), { kind: "vault", path: "secret/data/ssmd/kalshi" });
+  assertThrows(() => parseKeySource("file:/etc/key"), Error, "unknown key source");
+  assertThrows(() => parseKeySource("sealed-secret:a/b/c"), Error, "[namespace/]name");
+  assertThrows(() => parseKeySource("vault:"), Error, "no target");
+});
+
+const ENV = `feed: kalshi
+keys:
+  from_env: { fields: [api_key], source: "env:SET_VAR,UNSET_VAR" }
+  secret_ok: { fields: [api_key, private_key], source: "sealed-secret:ssmd/kalshi" }
+  secret_short: { fields: [api_key, private_key], source: "sealed-secret:ssmd/partial" }
+  secret_gone: { fields: [api_key], source: "sealed-secret:ssmd/gone" }
+  vault_ok: { source: "vault:secret/data/ok" }
+  vault_denied: { source: "vault:secret/data/denied" }
+  unknown: { source: "file:/etc/key" }
+`;
+
+const resolvers: KeyResolvers = {
+  env: (name) => name === "SET_VAR" ? "x" : undefined,
+  secretKeys: (_namespace, name) =>
+    Promise.resolve(({ kalshi: ["api_key", "private_key"], partial: ["api_key"] } as Record<string, string[]>)[name] ?? null),
+  vaultReadable: (path) => Promise.resolve(path === "secret/data/ok"),
+};
+
+Deno.test("checkKeySources resolves each source and reports what is missing", async () => {
+  const snapshot = snapshotFromFiles(new Map([["environments/kalshi-dev.yaml", ENV]]));
+  const checks = await checkKeySources(snapshot, resolvers);
+
+  assertEquals(checks.map((c) => [c.key, c.status, c.reason]), [
+    ["from_env", "failed", "env UNSET_VAR not set"],
+    ["secret_ok", "ok", undefined],
+    ["secret_short", "failed", "secret ssmd/partial has no private_key"],
+    ["secret_gone", "failed", "secret ssmd/gone not found"],
+    ["vault_ok", "ok", undefined],
+    ["vault_denied", "failed", "vault path secret/data/denied is not readable"],
+    ["unknown", "failed", 'unknown key source "file" (expected env:, sealed-secret: or vault:)'],
+  ]);
+  assertEquals(keyCheckIssues(checks)[0], {
+    code: "SSMD-E005",
+    severity: "error",
+    file: "environments/kalshi-dev.yaml",
+    message: "keys.from_env: env UNSET_VAR not set",
+  });
+});
+
+Deno.test("checkKeySources skips checks that cannot run, with the reason", async () => {
+  const snapshot = snapshotFromFiles(new Map([["environments/kalshi-dev.yaml", ENV]]));
+  const checks = await checkKeySources(snapshot, {
+    ...resolvers,
+    vaultReadable: () => Promise.reject(new SkipCheck("VAULT_ADDR and VAULT_TOKEN are required")),
+  });
+
+  const vault = checks.filter((c) => c.source.startsWith("vault:"));
+  assertEquals(vault.map((c) => [c.status, c.reason]), [
+    ["skipped", "VAULT_ADDR and VAULT_TOKEN are required"],
+    ["skipped", "VAULT_ADDR and VAULT_TOKEN are required"],
+  ]);
+  assertEquals(keyCheckIssues(vault), []);
+});
@@ -0,0 +1,74 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { snapshotFromFiles } from "../../src/cli/commands/diff.ts";
+import { validateSnapshot } from "../../src/cli/commands/validate.ts";
+import { buildReport, toSarif } from "../../src/cli/commands/validate-report.ts";
+import { VALIDATION_CODES } from "../../src/lib/types/validation.ts";
+
+const FEED = `name: kalshi
+type: websocket
+versions:
+  - version: v1
+    effective_from: "2025-01-01"
+    protocol: { transport: wss, message: json }
+    endpoint: wss://api.elections.kalshi.com/trade-api/ws/v2
+`;
+
+const snapshot = snapshotFromFiles(new Map([
+  ["feeds/kalshi.yaml", FEED],
+  ["environments/dev.yaml", "feed: kalshi\n"],
+  ["environments/prod.yaml", "feed: kalshi\nschema: trade:v2\n"],
+]));
+
+Deno.test("validateSnapshot issues carry stable codes", () => {
+  assertEquals(validateSnapshot(snapshot).map((i) => [i.code, i.file]), [
+    ["SSMD-E004", "environments/prod.yaml"],
+  ]);
+});
+
+Deno.test("buildReport lists every file with its result", () => {
+  const report = buildReport("/repo/exchanges", snapshot, validateSnapshot(snapshot));
+
+  assertEquals(report.ok, false);
+  assertEquals(report.summary, { files: 3, failed: 1, errors: 1, warnings: 0 });
+  assertEquals(report.files.map((f) => [f.file, f.result]), [
+    ["environments/dev.yaml", "pass"],
+    ["environments/prod.yaml", "fail"],
+    ["feeds/kalshi.yaml", "pass"],
+  ]);
+  assertEquals(report.files[1].issues, [{
+    code: "SSMD-E004",
+    severity: "error",
+    message: "schema trade:v2 of feed kalshi has no schemas/ file",
+  }]);
+});
+
+Deno.test("buildReport adds files that only issues name", () => {
+  const report = buildReport("/repo/exchanges", snapshot, [
+    { code: "SSMD-L001", severity: "error", file: "ssmd.lock", message: "drifted: environment dev: changed" },
+    { code: "SSMD-E005", severity: "warning", file: "environments/dev.yaml", message: "keys.kalshi: skipped" },
+  ]);
+
+  assertEquals(report.files.map((f) => [f.file, f.result]), [
+    ["environments/dev.yaml", "pass"],
+    ["environments/prod.yaml", "pass"],
+    ["feeds/kalshi.yaml", "pass"],
+    ["ssmd.lock", "fail"],
+  ]);
+  assertEquals(report.summary, { files: 4, failed: 1, errors: 1, warnings: 1 });
+});
+
+Deno.test("toSarif maps issues to rules and repository paths", () => {
+  // deno-lint-ignore no-explicit-any
+  const sarif = toSarif(validateSnapshot(snapshot), "exchanges/") as any;
+  const run = sarif.runs[0];
+
+  assertEquals(sarif.version, "2.1.0");
+  assertEquals(run.tool.driver.rules.length, Object.keys(VALIDATION_CODES).length);
+  assertEquals(run.results, [{
+    ruleId: "SSMD-E004",
+    ruleIndex: Object.keys(VALIDATION_CODES).indexOf("SSMD-E004"),
+    level: "error",
+    message: { text: "schema trade:v2 of feed kalshi has no schemas/ file" },
+    locations: [{ physicalLocation: { artifactLocation: { uri: "exchanges/environments/prod.yaml" } } }],
+  }]);
+});
@@ -0,0 +1,77 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { snapshotFromFiles } from "../../src/cli/commands/diff.ts";
+import { checkSchemaHashes, schemaHash, validateSnapshot } from "../../src/cli/commands/validate.ts";
+
+const FEED = `name: kalshi
+type: websocket
+versions:
+  - version: v1
+    effective_from: "2025-01-01"
+    protocol: { transport: wss, message: json }
+    endpoint: wss://api.elections.kalshi.com/trade-api/ws/v2
+`;
+
+const SCHEMA = `feed: kalshi
+type: trade
+version: v1
+fields:
+  msg.market_ticker: { type: string, required: true }
+`;
+
+Deno.test("validateSnapshot accepts a consistent workspace", () => {
+  const snapshot = snapshotFromFiles(new Map([
+    ["feeds/kalshi.yaml", FEED],
+    ["schemas/kalshi-trade.yaml", SCHEMA],
+    ["environments/kalshi-dev.yaml", "feed: kalshi\nschema: trade:v1\n"],
+  ]));
+  assertEquals(validateSnapshot(snapshot), []);
+});
+
+Deno.test("validateSnapshot reports broken feeds and dangling references", () => {
+  const snapshot = snapshotFromFiles(new Map([
+    ["feeds/kalshi.yaml", FEED],
+    ["feeds/renamed.yaml", FEED],
+    ["feeds/broken.yaml", "name: broken\ntype: carrier-pigeon\nversions: []\n"],
+    ["schemas/kraken-trade.yaml", "feed: kraken\ntype: trade\nversion: v1\nfields: {}\n"],
+    ["schemas/no-version.yaml", "feed: kalshi\ntype: ticker\n"],
+    ["environments/prod.yaml", "feed: kalshi\nschema: trade:v2\n"],
+    ["environments/orphan.yaml", "feed: polymarket\n"],
+  ]));
+
+  const byFile = (file: string) => validateSnapshot(snapshot).filter((i) => i.file === file).map((i) => i.message);
+  assertEquals(byFile("feeds/renamed.yaml"), ["feed name kalshi does not match file name renamed"]);
+  assertEquals(byFile("feeds/broken.yaml").length >= 2, true);
+  assertEquals(byFile("schemas/kraken-trade.yaml"), ["feed kraken has no feeds/kraken.yaml"]);
+  assertEquals(byFile("schemas/no-version.yaml"), ["missing version"]);
+  assertEquals(byFile("environments/prod.yaml"), ["schema trade:v2 of feed kalshi has no schemas/ file"]);
+  assertEquals(byFile("environments/orphan.yaml"), ["feed polymarket has no feeds/polymarket.yaml"]);
+});
+
+Deno.test("validateSnapshot checks compatible_with against the declared versions", () => {
+  const v2 = SCHEMA.replace("version: v1", "version: v2\ncompatible_with: [v1, v0]") + "  msg.count: { type: integer, required: true }\n";
+  const snapshot = snapshotFromFiles(new Map([
+    ["feeds/kalshi.yaml", FEED],
+    ["schemas/kalshi-trade.yaml", SCHEMA],
+    ["schemas/kalshi-trade-v2.yaml", v2],
+  ]));
+
+  assertEquals(validateSnapshot(snapshot).map((i) => i.message), [
+    "compatible_with v1: breaking change (added) at msg.count",
+    "compatible_with v0: no schema kalshi trade:v0",
+  ]);
+});
+
+Deno.test("checkSchemaHashes flags edits that keep the version", async () => {
+  const before = snapshotFromFiles(new Map([["schemas/kalshi-trade.yaml", SCHEMA]]));
+  const edited = snapshotFromFiles(new Map([["schemas/kalshi-trade.yaml", SCHEMA + "  msg.count: { type: integer }\n"]]));
+  const bumped = snapshotFromFiles(new Map([
+    ["schemas/kalshi-trade.yaml", SCHEMA.replace("version: v1", "version: v2") + "  msg.count: { type: integer }\n"],
+  ]));
+
+  assertEquals((await checkSchemaHashes(before, edited)).map((i) => i.message), [
+    "schema kalshi trade:v1 changed without a version bump",
+  ]);
+  assertEquals(await checkSchemaHashes(before, bumped), []);
+  // Key order does not change the hash
+  assertEquals(await schemaHash({ a: 1, b: { c: 2, d: 3 } }), await schemaHash({ b: { d: 3, c: 2 }, a: 1 }));
+});
@@ -0,0 +1,131 @@
+// CLI -> storage -> API end-to-end tests. Run with `make integration-test`
+// (or SSMD_INTEGRATION=1 against services started from docker-compose.yml).
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { parse as parseYaml } from "yaml";
+import { FEED_CONFIG } from "../../src/lib/gcs/mod.ts";
+import {
+  connect,
+  createBucket,
+  createKey,
+  integrationTest,
+  migrate,
+  putObject,
+  runCli,
+  startServer,
+  syntheticCatalog,
+  writeSyntheticDay,
+} from "./harness.ts";
+
+const DATES = ["2026-01-14", "2026-01-15"];
+
+Deno.test({
+  name: "integration: CLI workspace, archived day and data API agree",
+  ...integrationTest,
+  async fn(t) {
+    await migrate();
+    const { db, close } = connect();
+    const bucket = await createBucket();
+    const server = startServer(bucket);
+    const get = (path: string, key?: string, init: RequestInit = {}) =>
+      fetch(`${server.url}${path}`, { ...init, headers: key ? { "X-API-Key": key } : {} });
+
+    try {
+      const workspace = await Deno.makeTempDir();
+      let types: string[] = [];
+
+      await t.step("ssmd init --template writes a feed the API serves", async () => {
+        await runCli(["init", join(workspace, "exchanges"), "--template", "kalshi"], workspace);
+        const feed = parseYaml(await Deno.readTextFile(join(workspace, "exchanges", "feeds", "kalshi.yaml"))) as {
+          name: string;
+          message_types: Record<string, unknown>;
+        };
+        assertEquals(feed.name, "kalshi");
+        // Message types the workspace declares and the API knows how to list
+        types = Object.keys(feed.message_types).filter((m) => FEED_CONFIG.kalshi.messageTypes.includes(m));
+        assertEquals(types.length > 0, true);
+      });
+
+      await t.step("synthetic archive and catalog are written through the storage layer", async () => {
+        const layout = FEED_CONFIG.kalshi;
+        for (const date of DATES) {
+          await writeSyntheticDay(bucket, layout, date, types);
+        }
+        const catalog = syntheticCatalog([{ feed: "kalshi", ...layout, types, dates: DATES, files: DATES.length * types.length * 2 }]);
+        await putObject(bucket, "catalog.json", JSON.stringify(catalog));
+      });
+
+      const reader = await createKey(db, { scopes: ["datasets:read"], allowedFeeds: ["kalshi"] });
+      const otherFeed = await createKey(db, { scopes: ["datasets:read"], allowedFeeds: ["kraken-futures"] });
+      const admin = await createKey(db, { scopes: ["admin:write", "datasets:read"], allowedFeeds: ["*"] });
+
+      await t.step("auth rejects missing and unknown keys", async () => {
+        assertEquals((await get("/v1/data/day?date=2026-01-15")).status, 401);
+        const unknown = await get("/v1/data/day?date=2026-01-15", "sk_test_AAAAAAAA_notarealsecret");
+        assertEquals(unknown.status, 401);
+        await unknown.body?.cancel();
+      });
+
+      await t.step("GET /v1/data/day lists the archived files for the key's feeds", async () => {
+        const res = await get("/v1/data/day?date=2026-01-15", reader);
+        assertEquals(res.status, 200);
+        const body = await res.json() as { feeds: { feed: string; fileCount: number; files: { type: string }[] }[] };
+        assertEquals(body.feeds.map((f) => f.feed), ["kalshi"]);
+        assertEquals(body.feeds[0].fileCount, types.length * 2);
+        assertEquals([...new Set(body.feeds[0].files.map((f) => f.type))].sort(), [...types].sort());
+
+        const other = await (await get("/v1/data/day?date=2026-01-15", otherFeed)).json() as { feeds: { feed: string; fileCount: number }[] };
+        assertEquals(other.feeds.map((f) => f.feed), ["kraken-futures"]);
+        assertEquals(other.feeds[0].fileCount, 0);
+      });
+
+      await t.step("GET /v1/data/catalog reads the catalog and applies date filters", async () => {
+        const overview = await (await get("/v1/data/catalog", reader)).json() as { feeds: { feed: string; dateMax: string }[] };
+        assertEquals(overview.feeds.map((f) => [f.feed, f.dateMax]), [["kalshi", "2026-01-15"]]);
+
+        const filtered = await (await get("/v1/data/catalog?feed=kalshi&from=2026-01-15", reader)).json() as {
+          dates: { date: string }[];
+        };
+        assertEquals(filtered.dates.map((d) => d.date), ["2026-01-15"]);
+
+        const denied = await get("/v1/data/catalog?feed=kalshi", otherFeed);
+        assertEquals(denied.status, 404);
+        await denied.body?.cancel();
+      });
+
+      await t.step("admin routes require the admin scope", async () => {
+        const forbidden = await get("/v1/admin/cache/invalidate?feed=kalshi", reader, { method: "POST" });
+        assertEquals(forbidden.status, 403);
+        await forbidden.body?.cancel();
+
+        const ok = await get("/v1/admin/cache/invalidate?feed=kalshi", admin, { method: "POST" });
+        assertEquals(ok.status, 200);
+        assertEquals((await ok.json()).feed, "kalshi");
+      });
+
+      await t.step("cache prime rejects malformed feeds and dates", async () => {
+        const prime = (body: unknown) =>
+          fetch(`${server.url}/v1/admin/cache/prime`, {
+            method: "POST",
+            headers: { "X-API-Key": admin, "Content-Type": "application/json" },
+            body: JSON.stringify(body),
+          });
+        const tooMany = Array.from({ length: 32 }, (_, i) => `2026-01-${String(i % 28 + 1).padStart(2, "0")}`);
+        for (const body of [{ feeds: "kalshi" }, { feeds: [1] }, { dates: "2026-01-14" }, { dates: [null] }, { dates: tooMany }]) {
+          const res = await prime(body);
+          assertEquals(res.status, 400, JSON.stringify(body));
+          await res.body?.cancel();
+        }
+
+        const ok = await prime({ feeds: ["kalshi"], dates: DATES });
+        assertEquals(ok.status, 200);
+        assertEquals((await ok.json()).dates, DATES);
+      });
+
+      await Deno.remove(workspace, { recursive: true });
+    } finally {
+      await server.handle.shutdown();
+      await close();
+    }
+  },
+});
@@ -0,0 +1,45 @@
+import { assertEquals, assertRejects } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { KrakenClient, parseSpotTicker } from "../../../src/lib/api/kraken.ts";
+
+const XBTUSD_TICKER = {
+  a: ["97010.10000", "1", "1.000"],
+  b: ["97000.00000", "2", "2.000"],
+  c: ["97005.50000", "0.01"],
+  v: ["120.5", "2400.25"],
+};
+
+Deno.test("parseSpotTicker takes best bid/ask, last trade and 24h volume", () => {
+  assertEquals(parseSpotTicker(XBTUSD_TICKER), {
+    bid: "97000.00000",
+    ask: "97010.10000",
+    lastPrice: "97005.50000",
+    volume24h: "2400.25",
+  });
+  assertEquals(parseSpotTicker(undefined), { bid: null, ask: null, lastPrice: null, volume24h: null });
+});
+
+Deno.test("KrakenClient batches spot ticker requests", async () => {
+  const urls: string[] = [];
+  const client = new KrakenClient({
+    fetch: ((url: string) => {
+      urls.push(url);
+      const pairs = new URL(url).searchParams.get("pair")!.split(",");
+      const result = Object.fromEntries(pairs.map((p) => [p, XBTUSD_TICKER]));
+      return Promise.resolve(Response.json({ error: [], result }));
+    }) as typeof fetch,
+  });
+
+  const ids = Array.from({ length: 120 }, (_, i) => `PAIR${i}`);
+  const tickers = await client.fetchSpotTickers(ids);
+
+  assertEquals(urls.length, 3);
+  assertEquals(Object.keys(tickers).length, 120);
+});
+
+Deno.test("KrakenClient surfaces Kraken API errors", async () => {
+  const client = new KrakenClient({
+    fetch: (() => Promise.resolve(Response.json({ error: ["EGeneral:Unknown"], result: {} }))) as typeof fetch,
+  });
+
+  await assertRejects(() => client.fetchAssetPairs(), Error, "EGeneral:Unknown");
+});
@@ -0,0 +1,37 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { compactionRow, NO_TICKER_KEY, previousDay } from "../../../src/lib/archive/mod.ts";
+
+Deno.test("compactionRow extracts ticker, type and sequence, keeping the line", () => {
+  const line =
+    '{"type":"trade","msg":{"market_ticker":"KXBTC-26JAN05.T100"},"_received_at":1767571200000000,"_nats_seq":42}';
+  assertEquals(compactionRow("kalshi", line), {
+    ticker: "KXBTC-26JAN05.T100",
+    ticker_key: "KXBTC-26JAN05T100",
+    type: "trade",
+    received_at: 1767571200000000,
+    nats_seq: 42,
+    line,
+  });
+});
+
+Deno.test("compactionRow keeps lines without a ticker under the _none partition", () => {
+  assertEquals(compactionRow("kalshi", ""), null);
+
+  const control = compactionRow("kraken-futures", '{"event":"subscribed","feed":"trade"}');
+  assertEquals([control?.ticker, control?.ticker_key, control?.type], [null, NO_TICKER_KEY, null]);
+
+  const garbled = compactionRow("binance", '{"stream":');
+  assertEquals(garbled, {
+    ticker: null,
+    ticker_key: NO_TICKER_KEY,
+    type: null,
+    received_at: null,
+    nats_seq: null,
+    line: '{"stream":',
+  });
+});
+
+Deno.test("previousDay is the local date before now", () => {
+  assertEquals(previousDay(new Date(2026, 2, 1, 0, 30)), "2026-02-28");
+  assertEquals(previousDay(new Date(2026, 0, 1, 23, 59)), "2025-12-31");
+});
@@ -0,0 +1,81 @@
+import { assertEquals, assertNotEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  checkArchive,
+  dedupKey,
+  isDuplicate,
+  messageTimestamp,
+  newDedupWindow,
+  nextDedupFile,
+} from "../../../src/lib/archive/mod.ts";
+
+const TRADE =
+  '{"type":"trade","msg":{"market_ticker":"A","ts":1767571200,"yes_price":46},"_received_at":1767571200000000,"_nats_seq":1}';
+const REDELIVERED =
+  '{"type":"trade","msg":{"market_ticker":"A","ts":1767571200,"yes_price":46},"_received_at":1767571200250000,"_nats_seq":7}';
+const OTHER_PRICE =
+  '{"type":"trade","msg":{"market_ticker":"A","ts":1767571200,"yes_price":47},"_received_at":1767571200000000,"_nats_seq":2}';
+
+Deno.test("dedupKey ignores the archiver's fields and keys on the payload", () => {
+  assertEquals(dedupKey("kalshi", TRADE), dedupKey("kalshi", REDELIVERED));
+  assertNotEquals(dedupKey("kalshi", TRADE), dedupKey("kalshi", OTHER_PRICE));
+});
+
+Deno.test("dedupKey skips blank, unparseable and tickerless lines", () => {
+  assertEquals(dedupKey("kalshi", ""), null);
+  assertEquals(dedupKey("kalshi", '{"type":"tra'), null);
+  assertEquals(dedupKey("kalshi", '{"type":"subscribed","id":1}'), null);
+});
+
+const KEYS = { trade: ["msg.market_ticker", "msg.trade_id"] };
+
+Deno.test("dedupKey uses the type's dedup_key fields when given", () => {
+  const first = '{"type":"trade","msg":{"market_ticker":"A","trade_id":"t1","yes_price":46},"_nats_seq":1}';
+  const corrected = '{"type":"trade","msg":{"market_ticker":"A","trade_id":"t1","yes_price":47},"_nats_seq":2}';
+  const next = '{"type":"trade","msg":{"market_ticker":"A","trade_id":"t2","yes_price":46},"_nats_seq":3}';
+  assertEquals(dedupKey("kalshi", first, KEYS), dedupKey("kalshi", corrected, KEYS));
+  assertNotEquals(dedupKey("kalshi", first, KEYS), dedupKey("kalshi", next, KEYS));
+  assertNotEquals(dedupKey("kalshi", first), dedupKey("kalshi", corrected));
+});
+
+Deno.test("dedupKey falls back to the payload without dedup_key fields", () => {
+  // No trade_id: the key fields are incomplete, so the payload hash decides
+  assertEquals(dedupKey("kalshi", TRADE, KEYS), dedupKey("kalshi", REDELIVERED, KEYS));
+  assertNotEquals(dedupKey("kalshi", TRADE, KEYS), dedupKey("kalshi", OTHER_PRICE, KEYS));
+  assertEquals(
+    dedupKey("kraken-spot", '{"channel":"trade","data":[{"symbol":"BTC/USD","trade_id":5,"price":1}]}', {
+      trade: ["data[].symbol", "data[].trade_id"],
+    }),
+    dedupKey("kraken-spot", '{"channel":"trade","data":[{"symbol":"BTC/USD","trade_id":5,"price":2}]}', {
+      trade: ["data[].symbol", "data[].trade_id"],
+    }),
+  );
+});
+
+Deno.test("messageTimestamp reads each feed's exchange timestamp", () => {
+  assertEquals(messageTimestamp("kalshi", JSON.parse(TRADE)), "1767571200");
+  assertEquals(messageTimestamp("binance", { data: { E: 1767571200123, s: "BTCUSDT" } }), "1767571200123");
+  assertEquals(
+    messageTimestamp("kraken-spot", { data: [{ timestamp: "2026-01-05T00:00:00Z" }] }),
+    "2026-01-05T00:00:00Z",
+  );
+  assertEquals(messageTimestamp("polymarket", { asset_id: "1" }), null);
+});
+
+Deno.test("isDuplicate looks back one file", () => {
+  const window = newDedupWindow("kalshi");
+  assertEquals(isDuplicate(window, TRADE), false);
+  assertEquals(isDuplicate(window, REDELIVERED), true);
+  nextDedupFile(window);
+  assertEquals(isDuplicate(window, REDELIVERED), true);
+  nextDedupFile(window);
+  nextDedupFile(window);
+  assertEquals(isDuplicate(window, REDELIVERED), false);
+});
+
+Deno.test("checkArchive counts duplicates with a window", async () => {
+  const text = [TRADE, OTHER_PRICE, REDELIVERED].join("\n") + "\n";
+  const stored = new Blob([text]).stream().pipeThrough(new CompressionStream("gzip"));
+  const check = await checkArchive(stored, newDedupWindow("kalshi"));
+  assertEquals(check.records, 3);
+  assertEquals(check.duplicates, 1);
+});
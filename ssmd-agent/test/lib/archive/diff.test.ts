@@ -0,0 +1,65 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  diffManifests,
+  diffTickers,
+  fractionalChange,
+  type ManifestFile,
+  summarizeManifest,
+} from "../../../src/lib/archive/mod.ts";
+
+function file(name: string, records: number, byType: Record<string, number>): ManifestFile {
+  return {
+    name,
+    start: "2026-02-15T16:00:00.000Z",
+    end: "2026-02-15T16:15:00.000Z",
+    records,
+    bytes: records * 100,
+    nats_start_seq: 1,
+    nats_end_seq: records,
+    records_by_type: byType,
+  };
+}
+
+Deno.test("summarizeManifest totals files, records, bytes and types", () => {
+  const summary = summarizeManifest({
+    files: [file("1600.jsonl.gz", 30, { trade: 10, ticker: 20 }), file("1615.jsonl.gz", 5, { trade: 5 })],
+  });
+  assertEquals(summary, { files: 2, records: 35, bytes: 3500, recordsByType: { trade: 15, ticker: 20 } });
+  assertEquals(summarizeManifest(null), { files: 0, records: 0, bytes: 0, recordsByType: {} });
+});
+
+Deno.test("diffManifests flags metrics that fell by the threshold", () => {
+  const before = { files: 96, records: 1000, bytes: 100_000, recordsByType: { trade: 400, ticker: 600 } };
+  const after = { files: 96, records: 700, bytes: 70_000, recordsByType: { ticker: 600, orderbook: 100 } };
+  const diffs = diffManifests(before, after, 0.5);
+
+  assertEquals(diffs.map((d) => d.metric), ["files", "records", "bytes", "records.orderbook", "records.ticker", "records.trade"]);
+  assertEquals(diffs.filter((d) => d.anomalous).map((d) => d.metric), ["records.trade"]);
+  assertEquals(diffs.find((d) => d.metric === "records")!.change, -0.3);
+  // A type that only appears on the second day has no baseline
+  assertEquals(diffs.find((d) => d.metric === "records.orderbook")!.change, null);
+});
+
+Deno.test("diffTickers lists missing tickers and drops past the threshold", () => {
+  const before = [
+    { ticker: "KXBTCD-A", trades: 100, volume: 5000 },
+    { ticker: "KXBTCD-B", trades: 50, volume: 800 },
+    { ticker: "KXBTCD-C", trades: 40, volume: 400 },
+    { ticker: "KXBTCD-QUIET", trades: 3, volume: 3 },
+  ];
+  const after = [
+    { ticker: "KXBTCD-A", trades: 90, volume: 4000 },
+    { ticker: "KXBTCD-C", trades: 10, volume: 90 },
+    { ticker: "KXBTCD-NEW", trades: 7, volume: 70 },
+  ];
+  const diff = diffTickers(before, after, { threshold: 0.5, minTrades: 10 });
+
+  assertEquals(diff.missing.map((t) => t.ticker), ["KXBTCD-B"]);
+  assertEquals(diff.drops.map((d) => [d.ticker, d.change]), [["KXBTCD-C", -0.75]]);
+  assertEquals(diff.added.map((t) => t.ticker), ["KXBTCD-NEW"]);
+});
+
+Deno.test("fractionalChange is null without a baseline", () => {
+  assertEquals(fractionalChange(0, 5), null);
+  assertEquals(fractionalChange(200, 50), -0.75);
+});
@@ -0,0 +1,115 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import type { Storage } from "@google-cloud/storage";
+import {
+  addFile,
+  composeObjects,
+  type ManifestFile,
+  markGaps,
+  mergeEntries,
+  newArchiveManifest,
+  recomputeTotals,
+  writeManifestFile,
+} from "../../../src/lib/archive/mod.ts";
+
+function entry(name: string, startSeq: number, endSeq: number, overrides: Partial<ManifestFile> = {}): ManifestFile {
+  return {
+    name,
+    start: `2026-01-05T${name.slice(0, 2)}:${name.slice(2, 4)}:00Z`,
+    end: `2026-01-05T${name.slice(0, 2)}:${name.slice(2, 4)}:59Z`,
+    records: endSeq - startSeq + 1,
+    bytes: 100,
+    nats_start_seq: startSeq,
+    nats_end_seq: endSeq,
+    records_by_type: { trade: endSeq - startSeq + 1 },
+    ...overrides,
+  };
+}
+
+Deno.test("addFile replaces entries by name and keeps them sorted", () => {
+  const manifest = newArchiveManifest("kalshi", "2026-01-05");
+  addFile(manifest, entry("0015.jsonl.gz", 11, 20));
+  addFile(manifest, entry("0000.jsonl.gz", 1, 10));
+  addFile(manifest, entry("0015.jsonl.gz", 11, 25));
+  assertEquals(manifest.files.map((f) => [f.name, f.nats_end_seq]), [["0000.jsonl.gz", 10], ["0015.jsonl.gz", 25]]);
+});
+
+Deno.test("mergeEntries sums counts and widens bounds", () => {
+  const merged = mergeEntries("day.jsonl.gz", [
+    entry("0000.jsonl.gz", 1, 10, { sha256: "aa" }),
+    entry("0015.jsonl.gz", 11, 20, { records_by_type: { trade: 4, ticker: 6 } }),
+  ]);
+  assertEquals(merged, {
+    name: "day.jsonl.gz",
+    start: "2026-01-05T00:00:00Z",
+    end: "2026-01-05T00:15:59Z",
+    records: 20,
+    bytes: 200,
+    nats_start_seq: 1,
+    nats_end_seq: 20,
+    records_by_type: { trade: 14, ticker: 6 },
+  });
+  assertThrows(() => mergeEntries("x", []), Error, "No entries");
+});
+
+Deno.test("markGaps records sequence breaks once and skips entries without sequences", () => {
+  const manifest = newArchiveManifest("kalshi", "2026-01-05");
+  manifest.files = [
+    entry("0000.jsonl.gz", 1, 10),
+    entry("0030.jsonl.gz", 16, 20),
+    entry("0015.jsonl.gz", 11, 12),
+    entry("0045.jsonl.gz", 0, 0, { records: 3 }),
+  ];
+  const now = new Date("2026-01-06T00:00:00Z");
+  assertEquals(markGaps(manifest, now), [{ after_seq: 12, missing_count: 3, detected_at: now.toISOString() }]);
+  assertEquals(manifest.has_gaps, true);
+  assertEquals(markGaps(manifest, now), []);
+  assertEquals(manifest.gaps?.length, 1);
+});
+
+Deno.test("recomputeTotals derives message types and merges tickers", () => {
+  const manifest = newArchiveManifest("kalshi", "2026-01-05");
+  manifest.tickers = ["B"];
+  manifest.files = [entry("0000.jsonl.gz", 1, 10, { records_by_type: { trade: 3, ticker: 0, lifecycle: 1 } })];
+  recomputeTotals(manifest, ["A", "B"]);
+  assertEquals(manifest.message_types, ["lifecycle", "trade"]);
+  assertEquals(manifest.tickers, ["A", "B"]);
+  assertEquals(manifest.has_gaps, false);
+});
+
+Deno.test("writeManifestFile writes through a temp file", async () => {
+  const dir = await Deno.makeTempDir();
+  const path = join(dir, "kalshi", "2026-01-05", "manifest.json");
+  await writeManifestFile(path, newArchiveManifest("kalshi", "2026-01-05"));
+  assertEquals(JSON.parse(await Deno.readTextFile(path)).feed, "kalshi");
+  assertEquals([...Deno.readDirSync(join(dir, "kalshi", "2026-01-05"))].map((e) => e.name), ["manifest.json"]);
+  await Deno.remove(dir, { recursive: true });
+});
+
+Deno.test("composeObjects composes in rounds of 32 and removes temporaries", async () => {
+  const calls: [string[], string][] = [];
+  const deleted: string[] = [];
+  const storage = {
+    bucket: () => ({
+      combine: (sources: string[], dest: string) => {
+        calls.push([sources, dest]);
+        return Promise.resolve();
+      },
+      file: (name: string) => ({
+        delete: () => {
+          deleted.push(name);
+          return Promise.resolve();
+        },
+      }),
+    }),
+  } as unknown as Storage;
+
+  const sources = Array.from({ length: 40 }, (_, i) => `d/${String(i).padStart(4, "0")}.jsonl.gz`);
+  await composeObjects(storage, "bucket", sources, "d/day.jsonl.gz");
+  assertEquals(calls.map(([s, d]) => [s.length, d]), [
+    [32, "d/day.jsonl.gz.compose-0"],
+    [8, "d/day.jsonl.gz.compose-1"],
+    [2, "d/day.jsonl.gz"],
+  ]);
+  assertEquals(deleted.sort(), ["d/day.jsonl.gz.compose-0", "d/day.jsonl.gz.compose-1"]);
+});
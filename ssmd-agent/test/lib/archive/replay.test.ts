@@ -0,0 +1,40 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { parseSpeed, replayDelayMs, toReplayRecord } from "../../../src/lib/archive/mod.ts";
+
+Deno.test("parseSpeed accepts multipliers and max", () => {
+  assertEquals(parseSpeed(undefined), 1);
+  assertEquals(parseSpeed("10x"), 10);
+  assertEquals(parseSpeed("0.5"), 0.5);
+  assertEquals(parseSpeed("max"), Infinity);
+  assertThrows(() => parseSpeed("0x"), Error, "--speed");
+  assertThrows(() => parseSpeed("fast"), Error, "--speed");
+});
+
+Deno.test("toReplayRecord builds connector-style subjects and strips archiver fields", () => {
+  const line =
+    '{"type":"trade","sid":1,"msg":{"market_ticker":"KXBTCD-26JAN05-T98000"},"_received_at":1767571200000000,"_nats_seq":7}';
+  assertEquals(toReplayRecord("kalshi", "replay.kalshi", line), {
+    receivedAt: 1767571200000000,
+    seq: 7,
+    subject: "replay.kalshi.json.trade.KXBTCD-26JAN05-T98000",
+    payload: '{"type":"trade","sid":1,"msg":{"market_ticker":"KXBTCD-26JAN05-T98000"}}',
+  });
+
+  const kraken = '{"channel":"ticker","type":"update","data":[{"symbol":"BTC/USD"}],"_received_at":1}';
+  assertEquals(toReplayRecord("kraken-spot", "replay.kraken", kraken)?.subject, "replay.kraken.json.ticker.BTC-USD");
+});
+
+Deno.test("toReplayRecord skips control frames and unstamped lines", () => {
+  assertEquals(toReplayRecord("kraken-spot", "r", '{"channel":"heartbeat","_received_at":1}'), null);
+  assertEquals(toReplayRecord("kalshi", "r", '{"type":"trade","msg":{}}'), null);
+  assertEquals(toReplayRecord("kalshi", "r", "not json"), null);
+  assertEquals(toReplayRecord("kalshi", "r", ""), null);
+});
+
+Deno.test("replayDelayMs scales original spacing from the replay start", () => {
+  // 10s after the first record at 10x is due 1s after start
+  assertEquals(replayDelayMs(0, 10_000_000, 1000, 1500, 10), 500);
+  // Already late: publish immediately
+  assertEquals(replayDelayMs(0, 10_000_000, 1000, 3000, 10), 0);
+  assertEquals(replayDelayMs(0, 10_000_000, 1000, 1000, Infinity), 0);
+});
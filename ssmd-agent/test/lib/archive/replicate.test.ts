@@ -0,0 +1,102 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import type { Storage } from "@google-cloud/storage";
+import {
+  dateRange,
+  type ObjectInfo,
+  planReplication,
+  replicaDir,
+  replicateDate,
+  REPLICATION_STATUS_FILE,
+  type ReplicationStatus,
+} from "../../../src/lib/archive/mod.ts";
+
+function object(name: string, crc32c = "AAAA", size = 10): ObjectInfo {
+  return { name, size, crc32c, generation: "1" };
+}
+
+Deno.test("dateRange lists the days from --from to --to", () => {
+  assertEquals(dateRange("2026-02-27", "2026-03-01"), ["2026-02-27", "2026-02-28", "2026-03-01"]);
+  assertThrows(() => dateRange("2026-03-01", "2026-02-27"), Error, "--to must not be before --from");
+  assertThrows(() => dateRange("2026-3-1", "2026-03-02"), Error, "--from must be a date");
+});
+
+Deno.test("replicaDir puts the prefix before the feed path", () => {
+  assertEquals(replicaDir({ bucket: "b", prefix: "" }, "kalshi", "2026-01-05"), "kalshi/kalshi/crypto/2026-01-05/");
+  assertEquals(replicaDir({ bucket: "b", prefix: "/dr/" }, "kalshi", "2026-01-05"), "dr/kalshi/kalshi/crypto/2026-01-05/");
+});
+
+Deno.test("planReplication copies new and changed objects, manifest last", () => {
+  const source = [object("manifest.json"), object("0000.jsonl.gz"), object("0015.jsonl.gz", "BBBB"), object("0030.jsonl.gz")];
+  const destination = new Map([
+    ["0000.jsonl.gz", object("0000.jsonl.gz")],
+    ["0015.jsonl.gz", object("0015.jsonl.gz", "CCCC")],
+    [REPLICATION_STATUS_FILE, object(REPLICATION_STATUS_FILE)],
+  ]);
+  const { copy, current } = planReplication([...source, object(REPLICATION_STATUS_FILE)], destination);
+  assertEquals(copy.map((o) => o.name), ["0015.jsonl.gz", "0030.jsonl.gz", "manifest.json"]);
+  assertEquals(current.map((o) => o.name), ["0000.jsonl.gz"]);
+});
+
+/** In-memory buckets of name -> { data, crc32c }; copies fail for names in failing */
+function fakeStorage(buckets: Record<string, Map<string, { data: string; crc32c: string }>>, failing = new Set<string>()) {
+  const metadata = (bucket: string, name: string) => {
+    const o = buckets[bucket].get(name)!;
+    return { size: String(o.data.length), crc32c: o.crc32c, generation: "1" };
+  };
+  return {
+    bucket: (bucket: string) => ({
+      getFiles: ({ prefix }: { prefix: string }) =>
+        Promise.resolve([
+          [...buckets[bucket].keys()].filter((n) => n.startsWith(prefix)).map((name) => ({ name, metadata: metadata(bucket, name) })),
+        ]),
+      file: (name: string) => ({
+        bucket,
+        name,
+        copy: (target: { bucket: string; name: string }) => {
+          if (failing.has(name)) return Promise.reject(new Error("503 backend error"));
+          buckets[target.bucket].set(target.name, { ...buckets[bucket].get(name)! });
+          return Promise.resolve();
+        },
+        getMetadata: () => Promise.resolve([metadata(bucket, name)]),
+        download: () => {
+          const o = buckets[bucket].get(name);
+          return o ? Promise.resolve([new TextEncoder().encode(o.data)]) : Promise.reject({ code: 404 });
+        },
+        save: (data: string) => {
+          buckets[bucket].set(name, { data, crc32c: "status" });
+          return Promise.resolve();
+        },
+      }),
+    }),
+  } as unknown as Storage;
+}
+
+Deno.test("replicateDate copies, records status and resumes after a failure", async () => {
+  const dir = "kalshi/kalshi/crypto/2026-01-05/";
+  const buckets = {
+    src: new Map([
+      [`${dir}0000.jsonl.gz`, { data: "aaaa", crc32c: "c1" }],
+      [`${dir}0015.jsonl.gz`, { data: "bbbb", crc32c: "c2" }],
+      [`${dir}manifest.json`, { data: "{}", crc32c: "c3" }],
+    ]),
+    dr: new Map<string, { data: string; crc32c: string }>(),
+  };
+  const source = { bucket: "src", prefix: "" };
+  const destination = { bucket: "dr", prefix: "backup" };
+  const destDir = `backup/${dir}`;
+
+  const first = await replicateDate(fakeStorage(buckets, new Set([`${dir}0015.jsonl.gz`])), source, destination, "kalshi", "2026-01-05");
+  assertEquals([first.copied, first.current, first.failed], [2, 0, 1]);
+  let status: ReplicationStatus = JSON.parse(buckets.dr.get(`${destDir}${REPLICATION_STATUS_FILE}`)!.data);
+  assertEquals(status.complete, false);
+  assertEquals(Object.keys(status.failed), ["0015.jsonl.gz"]);
+  assertEquals(Object.keys(status.objects).sort(), ["0000.jsonl.gz", "manifest.json"]);
+
+  const second = await replicateDate(fakeStorage(buckets), source, destination, "kalshi", "2026-01-05");
+  assertEquals([second.copied, second.current, second.failed], [1, 2, 0]);
+  status = JSON.parse(buckets.dr.get(`${destDir}${REPLICATION_STATUS_FILE}`)!.data);
+  assertEquals(status.complete, true);
+  assertEquals(status.source, `gs://src/${dir}`);
+  assertEquals(Object.keys(status.objects).sort(), ["0000.jsonl.gz", "0015.jsonl.gz", "manifest.json"]);
+  assertEquals(buckets.dr.get(`${destDir}0015.jsonl.gz`)?.crc32c, "c2");
+});
@@ -0,0 +1,108 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  fileOverlaps,
+  type ManifestFile,
+  mergeByTime,
+  type MergeInput,
+  sampleRange,
+  sampleResolver,
+} from "../../../src/lib/archive/mod.ts";
+import { compileFilter } from "../../../src/lib/filter/mod.ts";
+
+function entry(start: string, end: string): ManifestFile {
+  return { name: "0000.jsonl.gz", start, end, records: 1, bytes: 1, nats_start_seq: 1, nats_end_seq: 1 };
+}
+
+function input(startMs: number, items: [string, number | null][], opened?: string[]): MergeInput<[string, number | null]> {
+  return {
+    startMs,
+    open: async function* () {
+      opened?.push(items[0][0]);
+      yield* items;
+    },
+  };
+}
+
+async function collect<T>(gen: AsyncIterable<T>, limit = Infinity): Promise<T[]> {
+  const out: T[] = [];
+  for await (const item of gen) {
+    out.push(item);
+    if (out.length >= limit) break;
+  }
+  return out;
+}
+
+Deno.test("sampleRange reads one date with optional time bounds", () => {
+  const day = sampleRange("2026-01-05", "14:00", "14:30:15");
+  assertEquals(day.dates, ["2026-01-05"]);
+  assertEquals(day.fromMs, Date.parse("2026-01-05T14:00:00Z"));
+  assertEquals(day.toMs, Date.parse("2026-01-05T14:30:15Z"));
+
+  const open = sampleRange("2026-01-05");
+  assertEquals([open.fromMs, open.toMs], [-Infinity, Infinity]);
+  assertThrows(() => sampleRange("2026-01-05", "2pm"));
+});
+
+Deno.test("sampleRange spans the days between from and to", () => {
+  const days = sampleRange(undefined, "2026-01-04", "2026-01-06");
+  assertEquals(days.dates, ["2026-01-04", "2026-01-05", "2026-01-06"]);
+  assertEquals(days.fromMs, Date.parse("2026-01-04T00:00:00Z"));
+  assertEquals(days.toMs, Date.parse("2026-01-06T23:59:59.999Z"));
+
+  const times = sampleRange(undefined, "2026-01-04T22:00:00Z", "2026-01-05T01:00:00Z");
+  assertEquals(times.dates, ["2026-01-04", "2026-01-05"]);
+
+  assertThrows(() => sampleRange(undefined, "2026-01-04"), Error, "from and to are both required without a date");
+  assertThrows(() => sampleRange(undefined, "2026-01-06", "2026-01-05"), Error, "to must not be before from");
+});
+
+Deno.test("fileOverlaps compares the manifest window with the bounds", () => {
+  const file = entry("2026-01-05T14:00:00Z", "2026-01-05T14:15:00Z");
+  const at = (iso: string) => Date.parse(iso);
+  assertEquals(fileOverlaps(file, at("2026-01-05T14:10:00Z"), at("2026-01-05T15:00:00Z")), true);
+  assertEquals(fileOverlaps(file, at("2026-01-05T14:15:01Z"), at("2026-01-05T15:00:00Z")), false);
+  assertEquals(fileOverlaps(file, -Infinity, at("2026-01-05T13:59:59Z")), false);
+  assertEquals(fileOverlaps(entry("", ""), 0, 1), true);
+});
+
+Deno.test("fileOverlaps prefers the min_ts/max_ts statistics to the file window", () => {
+  const file = { ...entry("2026-01-05T14:00:00Z", "2026-01-05T14:15:00Z"), ticker_count: 4 };
+  const stats = { ...file, min_ts: "2026-01-05T14:02:00.000Z", max_ts: "2026-01-05T14:05:00.000Z" };
+  const at = (hhmm: string) => Date.parse(`2026-01-05T${hhmm}:00Z`);
+
+  assertEquals(fileOverlaps(stats, at("14:06"), at("14:30")), false);
+  assertEquals(fileOverlaps(stats, at("14:04"), at("14:30")), true);
+  assertEquals(fileOverlaps(file, at("14:06"), at("14:30")), true);
+  // Files with no tickers hold only control frames
+  assertEquals(fileOverlaps({ ...stats, ticker_count: 0 }, -Infinity, Infinity), false);
+});
+
+Deno.test("sampleResolver exposes ticker, type and enveloped fields to filters", () => {
+  const trade = { type: "trade", msg: { market_ticker: "INXD-25", yes_price: 45 } };
+  const ticker = { type: "ticker", msg: { market_ticker: "INXD-25", yes_price: 45 } };
+  const filter = compileFilter('ticker == "INXD-25" && yes_price > 40 && type in ("trade")');
+
+  assertEquals(filter.matches(sampleResolver("kalshi", trade)), true);
+  assertEquals(filter.matches(sampleResolver("kalshi", ticker)), false);
+  assertEquals(filter.matches(sampleResolver("kalshi", trade, "OTHER")), false);
+});
+
+Deno.test("mergeByTime merges inputs into time order", async () => {
+  const merged = await collect(mergeByTime([
+    input(0, [["a1", 1], ["a2", 4], ["a3", 6]]),
+    input(0, [["b1", 2], ["b2", 4], ["b3", null], ["b4", 9]]),
+    input(-Infinity, [["c1", 3]]),
+  ], ([, t]) => t));
+  // Ties go to the earlier input; b3 has no time and stays after b2
+  assertEquals(merged.map(([name]) => name), ["a1", "b1", "c1", "a2", "b2", "b3", "a3", "b4"]);
+});
+
+Deno.test("mergeByTime opens inputs only when the merge reaches them", async () => {
+  const opened: string[] = [];
+  const merged = await collect(mergeByTime([
+    input(100, [["late", 100]], opened),
+    input(0, [["x1", 1], ["x2", 2], ["x3", 3]], opened),
+  ], ([, t]) => t), 2);
+  assertEquals(merged.map(([name]) => name), ["x1", "x2"]);
+  assertEquals(opened, ["x1"]);
+});
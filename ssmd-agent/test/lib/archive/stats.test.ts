@@ -0,0 +1,52 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  applyFileStats,
+  type ManifestFile,
+  needsStats,
+  newFileStats,
+  statsLine,
+} from "../../../src/lib/archive/mod.ts";
+
+const entry: ManifestFile = {
+  name: "1600.jsonl.gz",
+  start: "2026-02-15T16:00:00.000Z",
+  end: "2026-02-15T16:15:00.000Z",
+  records: 3,
+  bytes: 100,
+  nats_start_seq: 1,
+  nats_end_seq: 3,
+  records_by_type: { trade: 3 },
+};
+
+function line(type: string, ticker: string, receivedAt: number): string {
+  return JSON.stringify({ type, msg: { market_ticker: ticker }, _received_at: receivedAt });
+}
+
+Deno.test("statsLine gathers received-time bounds, type counts and tickers", () => {
+  const stats = newFileStats();
+  statsLine(stats, "kalshi", line("trade", "A", 1_771_171_200_000_500));
+  statsLine(stats, "kalshi", line("ticker", "B", 1_771_171_260_000_000));
+  statsLine(stats, "kalshi", line("trade", "A", 1_771_171_230_000_000));
+  statsLine(stats, "kalshi", "");
+  statsLine(stats, "kalshi", "{broken");
+
+  assertEquals(stats.records, 3);
+  assertEquals(stats.minReceivedAt, 1_771_171_200_000_500);
+  assertEquals(stats.maxReceivedAt, 1_771_171_260_000_000);
+  assertEquals(stats.recordsByType, { trade: 2, ticker: 1 });
+  assertEquals(stats.tickers.size, 2);
+});
+
+Deno.test("applyFileStats widens bounds to whole milliseconds", () => {
+  const stats = newFileStats();
+  statsLine(stats, "kalshi", line("trade", "A", 1_771_171_200_000_500));
+  statsLine(stats, "kalshi", line("trade", "A", 1_771_171_200_001_500));
+  const updated = applyFileStats(entry, stats);
+
+  assertEquals(updated.min_ts, "2026-02-15T16:00:00.000Z");
+  assertEquals(updated.max_ts, "2026-02-15T16:00:00.002Z");
+  assertEquals(updated.ticker_count, 1);
+  assertEquals(updated.records_by_type, { trade: 2 });
+  assertEquals(needsStats(entry), true);
+  assertEquals(needsStats(updated), false);
+});
@@ -0,0 +1,91 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  type FileIndex,
+  indexLine,
+  indexMatches,
+  type ManifestFile,
+  newFileIndex,
+  rangesFor,
+  sliceRanges,
+} from "../../../src/lib/archive/mod.ts";
+
+function kalshi(ticker: string, seq: number): string {
+  return JSON.stringify({ type: "ticker", msg: { market_ticker: ticker, seq }, _received_at: seq });
+}
+
+async function collect(chunks: AsyncIterable<Uint8Array>): Promise<string> {
+  const parts: Uint8Array[] = [];
+  for await (const chunk of chunks) parts.push(chunk);
+  return new TextDecoder().decode(new Uint8Array(await new Blob(parts).arrayBuffer()));
+}
+
+async function* chunked(text: string, size: number): AsyncGenerator<Uint8Array> {
+  const bytes = new TextEncoder().encode(text);
+  for (let i = 0; i < bytes.length; i += size) yield bytes.subarray(i, i + size);
+}
+
+Deno.test("indexLine records each ticker's byte ranges and counts", () => {
+  const lines = [kalshi("A", 1), kalshi("A", 2), kalshi("B", 3), "{not json", kalshi("A", 4)];
+  const index = newFileIndex();
+  for (const line of lines) indexLine(index, "kalshi", line, 0);
+
+  const offsets = [0];
+  for (const line of lines) offsets.push(offsets[offsets.length - 1] + line.length + 1);
+  assertEquals(index.lines, 5);
+  assertEquals(index.bytes, offsets[5]);
+  assertEquals(index.tickers["A"], { count: 3, ranges: [[offsets[0], offsets[2]], [offsets[4], offsets[5]]] });
+  assertEquals(index.tickers["B"], { count: 1, ranges: [[offsets[2], offsets[3]]] });
+});
+
+Deno.test("indexLine merges ranges within the merge gap", () => {
+  const index = newFileIndex();
+  for (const line of [kalshi("A", 1), kalshi("B", 2), kalshi("A", 3)]) indexLine(index, "kalshi", line, 1024);
+  assertEquals(index.tickers["A"].ranges, [[0, index.bytes]]);
+});
+
+Deno.test("rangesFor merges the ranges of several tickers and is empty for absent tickers", () => {
+  const file: FileIndex = {
+    bytes: 100,
+    lines: 4,
+    tickers: { A: { count: 2, ranges: [[0, 10], [50, 60]] }, B: { count: 1, ranges: [[10, 20]] } },
+  };
+  assertEquals(rangesFor(file, ["A", "B"]), [[0, 20], [50, 60]]);
+  assertEquals(rangesFor(file, ["C"]), []);
+});
+
+Deno.test("sliceRanges yields only the ranged bytes across chunk boundaries", async () => {
+  const text = "aaaa\nbbbb\ncccc\ndddd\n";
+  assertEquals(await collect(sliceRanges(chunked(text, 3), [[5, 10], [15, 20]])), "bbbb\ndddd\n");
+  assertEquals(await collect(sliceRanges(chunked(text, 64), [[0, 5]])), "aaaa\n");
+  assertEquals(await collect(sliceRanges(chunked(text, 3), [])), "");
+});
+
+Deno.test("sliceRanges stops reading after the last range", async () => {
+  let pulled = 0;
+  async function* counting(): AsyncGenerator<Uint8Array> {
+    for await (const chunk of chunked("aaaa\nbbbb\ncccc\ndddd\n", 5)) {
+      pulled++;
+      yield chunk;
+    }
+  }
+  assertEquals(await collect(sliceRanges(counting(), [[0, 5]])), "aaaa\n");
+  assertEquals(pulled, 1);
+});
+
+Deno.test("indexMatches rejects an index of a file rewritten since", () => {
+  const index = newFileIndex();
+  for (const line of [kalshi("A", 1), kalshi("B", 2)]) indexLine(index, "kalshi", line);
+  const entry = (records: number, bytes: number): ManifestFile => ({
+    name: "0000.jsonl.gz",
+    start: "",
+    end: "",
+    records,
+    bytes,
+    nats_start_seq: 1,
+    nats_end_seq: 2,
+  });
+
+  assertEquals(indexMatches(index, entry(2, index.bytes)), true);
+  assertEquals(indexMatches(index, entry(1, index.bytes - kalshi("B", 2).length - 1)), false);
+  assertEquals(indexMatches(index, undefined), true);
+});
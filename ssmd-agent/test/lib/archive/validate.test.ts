@@ -0,0 +1,102 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  buildSchemaRegistry,
+  newFileValidation,
+  recordType,
+  selectSchemas,
+  validateLine,
+  validateRecord,
+} from "../../../src/lib/archive/mod.ts";
+import { BUILTIN_RAW_SCHEMAS, type RawSchema } from "../../../src/lib/types/raw-schema.ts";
+
+const kalshiTrade = BUILTIN_RAW_SCHEMAS.find((s) => s.feed === "kalshi" && s.type === "trade")!;
+const krakenTrade = BUILTIN_RAW_SCHEMAS.find((s) => s.feed === "kraken-spot" && s.type === "trade")!;
+
+Deno.test("validateRecord accepts a well-formed Kalshi trade", () => {
+  const record = {
+    type: "trade",
+    sid: 1,
+    msg: {
+      market_ticker: "KXBTCD-26JAN05-T98000",
+      trade_id: "abc",
+      yes_price: 52,
+      count: 10,
+      taker_side: "yes",
+      ts: 1767571200,
+    },
+    _received_at: 1767571200000000,
+    _nats_seq: 7,
+  };
+  assertEquals(validateRecord(kalshiTrade, record), []);
+});
+
+Deno.test("validateRecord reports missing, type and unknown fields", () => {
+  const record = {
+    type: "trade",
+    msg: { market_ticker: "KXBTCD-26JAN05-T98000", trade_id: 42, count_fp: "10.00", side: "no", ts: 1, surprise: true },
+    _received_at: 1,
+  };
+  assertEquals(validateRecord(kalshiTrade, record), [
+    { kind: "type", path: "msg.trade_id", got: "integer" },
+    { kind: "unknown", path: "msg.surprise" },
+    { kind: "missing", path: "msg.yes_price|msg.price|msg.yes_price_dollars" },
+  ]);
+});
+
+Deno.test("validateRecord reports required fields of an absent object", () => {
+  const issues = validateRecord(kalshiTrade, { type: "trade", _received_at: 1 });
+  assertEquals(issues.map((i) => i.path), ["msg.market_ticker", "msg.ts", "msg.trade_id"]);
+});
+
+Deno.test("validateRecord checks each Kraken data[] element", () => {
+  const item = { symbol: "BTC/USD", side: "buy", price: 97000.5, qty: 0.01, ord_type: "market", trade_id: 1, timestamp: "2026-01-05T00:00:00Z" };
+  const record = { channel: "trade", type: "update", data: [item, { ...item, price: "97000.5", trade_id: "2" }], _received_at: 1 };
+  assertEquals(validateRecord(krakenTrade, record), [{ kind: "type", path: "data[].price", got: "string" }]);
+});
+
+Deno.test("selectSchemas narrows by type and version", () => {
+  assertEquals(selectSchemas(BUILTIN_RAW_SCHEMAS, "kalshi").map((s) => s.type), ["trade", "ticker"]);
+  assertEquals(selectSchemas(BUILTIN_RAW_SCHEMAS, "kalshi", "trade:v1").map((s) => s.type), ["trade"]);
+  assertEquals(selectSchemas(BUILTIN_RAW_SCHEMAS, "binance", "trade").length, 1);
+  assertThrows(() => selectSchemas(BUILTIN_RAW_SCHEMAS, "kalshi", "trade:v2"), Error, "known: trade:v1, ticker:v1");
+});
+
+Deno.test("validateLine counts skipped, unparseable and invalid records", () => {
+  const report = newFileValidation("0000.jsonl.gz");
+  const schemas = selectSchemas(BUILTIN_RAW_SCHEMAS, "binance");
+  const good = {
+    stream: "btcusdt@trade",
+    data: { e: "trade", E: 1, s: "BTCUSDT", t: 1, p: "97000.00", q: "0.01", T: 1, m: true, M: true },
+    _received_at: 1,
+  };
+  validateLine("binance", schemas, JSON.stringify(good), report);
+  validateLine("binance", schemas, JSON.stringify({ ...good, data: { ...good.data, m: "true" } }), report);
+  validateLine("binance", schemas, JSON.stringify({ ...good, data: { ...good.data, e: "aggTrade" } }), report);
+  validateLine("binance", schemas, "{not json", report);
+  validateLine("binance", schemas, "", report);
+  assertEquals(report, {
+    file: "0000.jsonl.gz",
+    records: 4,
+    validated: 2,
+    skipped: 1,
+    unparseable: 1,
+    invalid: 1,
+    issues: { "type trade.data.m (got string)": 1 },
+  });
+});
+
+Deno.test("buildSchemaRegistry lets files override built-ins and add feeds", () => {
+  const override: RawSchema = { feed: "kalshi", type: "trade", version: "v1", fields: { type: { type: "string" } } };
+  const added: RawSchema = { feed: "coinbase", type: "match", version: "v1", type_field: "type", fields: {} };
+  const registry = buildSchemaRegistry([override, added]);
+  assertEquals(registry.length, BUILTIN_RAW_SCHEMAS.length + 1);
+  assertEquals(selectSchemas(registry, "kalshi", "trade")[0], override);
+  assertEquals(selectSchemas(registry, "coinbase").map((s) => s.type), ["match"]);
+});
+
+Deno.test("recordType falls back to a schema's type_field", () => {
+  const schemas: RawSchema[] = [{ feed: "coinbase", type: "match", version: "v1", type_field: "event.kind", fields: {} }];
+  assertEquals(recordType("coinbase", schemas, { event: { kind: "match" } }), "match");
+  assertEquals(recordType("coinbase", schemas, { event: {} }), null);
+  assertEquals(recordType("kalshi", [], { type: "trade" }), "trade");
+});
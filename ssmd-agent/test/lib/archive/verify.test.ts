@@ -0,0 +1,130 @@
+import { assertEquals, assertMatch } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  checkArchive,
+  compareEntry,
+  groupArchiveFiles,
+  type ManifestFile,
+  repairManifest,
+} from "../../../src/lib/archive/mod.ts";
+
+const LINES = [
+  '{"type":"trade","msg":{"market_ticker":"A"},"_received_at":1767571200000000}',
+  '{"type":"ticker","msg":{"market_ticker":"B"},"_received_at":1767571260000000}',
+];
+
+async function gzip(text: string): Promise<Uint8Array> {
+  const stream = new Blob([text]).stream().pipeThrough(new CompressionStream("gzip"));
+  return new Uint8Array(await new Response(stream).arrayBuffer());
+}
+
+async function sha256(bytes: Uint8Array): Promise<string> {
+  const digest = new Uint8Array(await crypto.subtle.digest("SHA-256", bytes));
+  return [...digest].map((b) => b.toString(16).padStart(2, "0")).join("");
+}
+
+function entry(overrides: Partial<ManifestFile> = {}): ManifestFile {
+  return {
+    name: "0000.jsonl.gz",
+    start: "2026-01-05T00:00:00Z",
+    end: "2026-01-05T00:15:00Z",
+    records: 2,
+    bytes: LINES.join("\n").length + 1,
+    nats_start_seq: 1,
+    nats_end_seq: 2,
+    ...overrides,
+  };
+}
+
+Deno.test("groupArchiveFiles groups restart files into their slot", () => {
+  const names = ["p/0015.jsonl.gz", "p/0000-01.jsonl.gz", "p/manifest.json", "p/0000.jsonl.gz"];
+  assertEquals(groupArchiveFiles(names), [
+    ["0000", ["p/0000-01.jsonl.gz", "p/0000.jsonl.gz"]],
+    ["0015", ["p/0015.jsonl.gz"]],
+  ]);
+});
+
+Deno.test("checkArchive counts records and sizes and hashes the stored bytes", async () => {
+  const text = LINES.join("\n") + "\n";
+  const stored = await gzip(text);
+  const check = await checkArchive(new Blob([stored]).stream());
+
+  assertEquals(check.records, 2);
+  assertEquals(check.bytes, text.length);
+  assertEquals(check.compressedBytes, stored.length);
+  assertEquals(check.sha256, await sha256(stored));
+  assertEquals(check.truncated, null);
+  assertEquals([check.minReceivedAt, check.maxReceivedAt], [1767571200000000, 1767571260000000]);
+  assertEquals(compareEntry(entry(), check), []);
+});
+
+Deno.test("checkArchive flags a truncated gzip stream and a missing final newline", async () => {
+  const stored = await gzip(LINES.join("\n") + "\n");
+  const cut = stored.subarray(0, stored.length - 12);
+  const broken = await checkArchive(new Blob([cut]).stream());
+  assertMatch(broken.truncated ?? "", /^gzip: /);
+  assertEquals(broken.compressedBytes, cut.length);
+  assertEquals(broken.sha256, await sha256(cut));
+
+  const partial = await checkArchive(new Blob([await gzip(LINES.join("\n"))]).stream());
+  assertEquals(partial.truncated, "last line has no newline");
+  assertEquals(partial.records, 1);
+  assertEquals(compareEntry(entry(), partial).map((i) => i.kind), ["truncated", "records", "bytes"]);
+});
+
+Deno.test("compareEntry reports checksum, missing and unlisted files", async () => {
+  const check = await checkArchive(new Blob([await gzip(LINES.join("\n") + "\n")]).stream());
+  assertEquals(compareEntry(entry({ sha256: "00" }), check), [{ kind: "sha256", expected: "00", actual: check.sha256 }]);
+  assertEquals(compareEntry(entry(), undefined), [{ kind: "missing" }]);
+  assertEquals(compareEntry(undefined, check), [{ kind: "unlisted" }]);
+});
+
+Deno.test("repairManifest fixes intact entries, drops missing ones and adds unlisted files", async () => {
+  const intact = await checkArchive(new Blob([await gzip(LINES.join("\n") + "\n")]).stream());
+  const truncated = await checkArchive(new Blob([await gzip(LINES.join("\n"))]).stream());
+
+  const manifest = {
+    files: [
+      entry({ name: "0000.jsonl.gz", records: 5 }),
+      entry({ name: "0015.jsonl.gz" }),
+      entry({ name: "0030.jsonl.gz", records: 9 }),
+    ],
+  };
+  const checks = new Map([
+    ["0000.jsonl.gz", intact],
+    ["0030.jsonl.gz", truncated],
+    ["0045.jsonl.gz", intact],
+  ]);
+  assertEquals(repairManifest(manifest, checks), 3);
+
+  assertEquals(manifest.files.map((f) => f.name), ["0000.jsonl.gz", "0030.jsonl.gz", "0045.jsonl.gz"]);
+  assertEquals(manifest.files[0].records, 2);
+  assertEquals(manifest.files[0].sha256, intact.sha256);
+  // The truncated file keeps its entry for a rearchive
+  assertEquals(manifest.files[1].records, 9);
+  assertEquals(manifest.files[2].start, "2026-01-05T00:00:00.000Z");
+  assertEquals(manifest.files[2].end, "2026-01-05T00:01:00.000Z");
+
+  assertEquals(repairManifest(manifest, checks), 0);
+});
+
+Deno.test("verify expects files deleted by compaction to be missing", async () => {
+  assertEquals(compareEntry(entry(), undefined, true), []);
+
+  const intact = await checkArchive(new Blob([await gzip(LINES.join("\n") + "\n")]).stream());
+  const compaction = {
+    compacted_at: "2026-01-06T02:30:00Z",
+    path: "compacted",
+    partition_by: "ticker_key",
+    sort_by: ["ticker", "received_at", "nats_seq"],
+    sources: ["0000.jsonl.gz"],
+    records: 2,
+    files: [],
+    originals_deleted: true,
+  };
+  const manifest = {
+    files: [entry({ name: "0000.jsonl.gz", sha256: intact.sha256 }), entry({ name: "0015.jsonl.gz" })],
+    compaction,
+  };
+  assertEquals(repairManifest(manifest, new Map()), 1);
+  assertEquals(manifest.files.map((f) => f.name), ["0000.jsonl.gz"]);
+});
@@ -0,0 +1,35 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { findRouteLimit, parseRouteLimits, RouteRateLimiter } from "../../../src/lib/auth/route-ratelimit.ts";
+
+Deno.test("parseRouteLimits reads rate, optional burst and comments", () => {
+  assertEquals(parseRouteLimits("/v1/data/download=10, /v1/data/trades=60:20\n# comment\n/v1/data/*=300 # all data"), [
+    { route: "/v1/data/download", perMinute: 10, burst: 10 },
+    { route: "/v1/data/trades", perMinute: 60, burst: 20 },
+    { route: "/v1/data/*", perMinute: 300, burst: 300 },
+  ]);
+  assertEquals(parseRouteLimits(""), []);
+  assertThrows(() => parseRouteLimits("/v1/data/download"), Error, "expected /path=perMinute");
+  assertThrows(() => parseRouteLimits("/v1/data/download=0"), Error, "must be positive");
+});
+
+Deno.test("findRouteLimit takes the first exact or prefix match", () => {
+  const limits = parseRouteLimits("/v1/data/download=10,/v1/data/*=300");
+  assertEquals(findRouteLimit(limits, "/v1/data/download")?.perMinute, 10);
+  assertEquals(findRouteLimit(limits, "/v1/data/trades")?.perMinute, 300);
+  assertEquals(findRouteLimit(limits, "/v1/markets"), null);
+});
+
+Deno.test("RouteRateLimiter allows a burst then refills at the per-minute rate", () => {
+  const limiter = new RouteRateLimiter(parseRouteLimits("/v1/data/download=6:2"));
+  const t0 = 1_000_000;
+
+  assertEquals(limiter.take("sk_a", "/v1/data/download", t0), null);
+  assertEquals(limiter.take("sk_a", "/v1/data/download", t0), null);
+  // 6/min refills one token every 10s
+  assertEquals(limiter.take("sk_a", "/v1/data/download", t0), { retryAfterSeconds: 10 });
+  // Other keys and unlimited routes are unaffected
+  assertEquals(limiter.take("sk_b", "/v1/data/download", t0), null);
+  assertEquals(limiter.take("sk_a", "/v1/markets", t0), null);
+
+  assertEquals(limiter.take("sk_a", "/v1/data/download", t0 + 10_000), null);
+});
@@ -0,0 +1,47 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { accessAuditLine, type AccessAuditEntry } from "../../../src/lib/db/access-audit.ts";
+import { countResponseBytes } from "../../../src/server/middleware.ts";
+
+const ENTRY: AccessAuditEntry = {
+  keyPrefix: "sk_live_abc",
+  userEmail: "quant@example.com",
+  method: "GET",
+  route: "/v1/data/trades",
+  dataset: "kalshi",
+  statusCode: 200,
+  responseBytes: 2048,
+  latencyMs: 37,
+};
+
+Deno.test("accessAuditLine writes one structured JSON record", () => {
+  const line = accessAuditLine(ENTRY, new Date("2026-01-05T15:00:00Z"));
+  assertEquals(line.includes("\n"), false);
+  assertEquals(JSON.parse(line), {
+    log: "access",
+    ts: "2026-01-05T15:00:00.000Z",
+    key_prefix: "sk_live_abc",
+    user_email: "quant@example.com",
+    method: "GET",
+    route: "/v1/data/trades",
+    dataset: "kalshi",
+    status: 200,
+    bytes: 2048,
+    latency_ms: 37,
+  });
+});
+
+Deno.test("countResponseBytes reports body size once fully read", async () => {
+  let counted: number | null | undefined;
+  const res = countResponseBytes(new Response('{"ok":true}', { status: 201 }), (b) => (counted = b));
+  assertEquals(counted, undefined);
+  assertEquals(res.status, 201);
+  assertEquals(await res.text(), '{"ok":true}');
+  assertEquals(counted, 11);
+});
+
+Deno.test("countResponseBytes reports null when the client cancels", async () => {
+  let counted: number | null | undefined;
+  const res = countResponseBytes(new Response("streaming body"), (b) => (counted = b));
+  await res.body!.cancel();
+  assertEquals(counted, null);
+});
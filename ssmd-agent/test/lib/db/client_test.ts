@@ -0,0 +1,36 @@
+import { assertEquals, assertRejects } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { poolOptionsFromEnv, setQueryObserver, timedQuery } from "../../../src/lib/db/client.ts";
+
+Deno.test("poolOptionsFromEnv: defaults and overrides", () => {
+  const names = ["DB_POOL_MAX", "DB_IDLE_TIMEOUT_SEC", "DB_CONNECT_TIMEOUT_SEC", "DB_STATEMENT_TIMEOUT_MS"];
+  const saved = names.map((n) => Deno.env.get(n));
+  try {
+    names.forEach((n) => Deno.env.delete(n));
+    assertEquals(poolOptionsFromEnv(), { max: 10, idleTimeoutSec: 30, connectTimeoutSec: 10, statementTimeoutMs: 30000 });
+
+    Deno.env.set("DB_POOL_MAX", "25");
+    Deno.env.set("DB_STATEMENT_TIMEOUT_MS", "0");
+    Deno.env.set("DB_IDLE_TIMEOUT_SEC", "not-a-number");
+    const opts = poolOptionsFromEnv();
+    assertEquals(opts.max, 25);
+    assertEquals(opts.statementTimeoutMs, 0);
+    assertEquals(opts.idleTimeoutSec, 30);
+  } finally {
+    names.forEach((n, i) => saved[i] === undefined ? Deno.env.delete(n) : Deno.env.set(n, saved[i]!));
+  }
+});
+
+Deno.test("timedQuery: reports name and outcome to the observer", async () => {
+  const seen: Array<{ name: string; ok: boolean }> = [];
+  setQueryObserver((name, durationMs, ok) => {
+    assertEquals(durationMs >= 0, true);
+    seen.push({ name, ok });
+  });
+  try {
+    assertEquals(await timedQuery("list_markets", () => Promise.resolve(3)), 3);
+    await assertRejects(() => timedQuery("get_market", () => Promise.reject(new Error("timeout"))), Error, "timeout");
+    assertEquals(seen, [{ name: "list_markets", ok: true }, { name: "get_market", ok: false }]);
+  } finally {
+    setQueryObserver(null);
+  }
+});
@@ -0,0 +1,104 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  cacheServes,
+  type CachedMarket,
+  filterCachedMarkets,
+  MarketCache,
+  type MarketRow,
+  sortCachedMarkets,
+} from "../../../src/lib/db/markets.ts";
+import type { Database } from "../../../src/lib/db/client.ts";
+
+const NOW = new Date("2026-06-01T12:00:00Z");
+
+function entry(ticker: string, fields: Partial<MarketRow> = {}, extra: Partial<CachedMarket> = {}): CachedMarket {
+  const row = {
+    exchange: "kalshi",
+    ticker,
+    eventTicker: ticker.split("-").slice(0, 2).join("-"),
+    status: "active",
+    closeTime: new Date("2026-06-02T00:00:00Z"),
+    openTime: new Date("2026-05-01T00:00:00Z"),
+    volume: 0,
+    createdAt: new Date("2026-05-01T00:00:00Z"),
+    updatedAt: new Date("2026-06-01T00:00:00Z"),
+    deletedAt: null,
+    ...fields,
+  } as MarketRow;
+  return { row, category: "Crypto", seriesTicker: "KXBTC", isGame: false, ...extra };
+}
+
+const tickers = (entries: CachedMarket[]) => entries.map((e) => e.row.ticker);
+
+Deno.test("filterCachedMarkets: drops closed, deleted and not-yet-created markets", () => {
+  const entries = [
+    entry("KXBTC-A-1"),
+    entry("KXBTC-A-2", { closeTime: new Date("2026-06-01T11:00:00Z") }),
+    entry("KXBTC-A-3", { deletedAt: new Date("2026-05-30T00:00:00Z") }),
+    entry("KXBTC-A-4", { createdAt: new Date("2026-06-01T13:00:00Z") }),
+    entry("KXBTC-A-5", { closeTime: null }),
+  ];
+  assertEquals(tickers(filterCachedMarkets(entries, {}, NOW)), ["KXBTC-A-1", "KXBTC-A-5"]);
+});
+
+Deno.test("filterCachedMarkets: applies listMarkets filters", () => {
+  const entries = [
+    entry("KXBTC-A-1"),
+    entry("KXBTC-A-2", { status: "closed" }),
+    entry("KXNBA-B-1", {}, { category: "Sports", seriesTicker: "KXNBA", isGame: true }),
+    entry("KXBTC-A-3", { closeTime: new Date("2026-06-05T00:00:00Z") }),
+  ];
+  assertEquals(tickers(filterCachedMarkets(entries, { status: "closed" }, NOW)), ["KXBTC-A-2"]);
+  assertEquals(tickers(filterCachedMarkets(entries, { category: "Sports" }, NOW)), ["KXNBA-B-1"]);
+  assertEquals(tickers(filterCachedMarkets(entries, { series: "kxnba" }, NOW)), ["KXNBA-B-1"]);
+  assertEquals(tickers(filterCachedMarkets(entries, { gamesOnly: true }, NOW)), ["KXNBA-B-1"]);
+  assertEquals(
+    tickers(filterCachedMarkets(entries, { closingAfter: "2026-06-03T00:00:00Z" }, NOW)),
+    ["KXBTC-A-3"],
+  );
+  assertEquals(tickers(filterCachedMarkets(entries, { eventTicker: "KXBTC-A", status: "active" }, NOW)), [
+    "KXBTC-A-1",
+    "KXBTC-A-3",
+  ]);
+});
+
+Deno.test("sortCachedMarkets: matches listMarkets orderings with ticker tiebreak", () => {
+  const entries = [
+    entry("B", { volume: 5, closeTime: null, updatedAt: new Date("2026-06-01T01:00:00Z") }),
+    entry("A", { volume: 5, closeTime: new Date("2026-06-03T00:00:00Z") }),
+    entry("C", { volume: 9, closeTime: new Date("2026-06-02T00:00:00Z") }),
+  ];
+  assertEquals(tickers(sortCachedMarkets([...entries], "volume")), ["C", "B", "A"]);
+  assertEquals(tickers(sortCachedMarkets([...entries], "close_time")), ["C", "A", "B"]);
+  assertEquals(tickers(sortCachedMarkets([...entries], "updated_at")), ["B", "C", "A"]);
+});
+
+Deno.test("filterCachedMarkets and sortCachedMarkets: keep markets of exchanges sharing a ticker apart", () => {
+  const entries = [
+    entry("KXBTC-A-1", { volume: 5 }),
+    entry("KXBTC-A-1", { exchange: "polymarket", volume: 5 }),
+    entry("KXBTC-A-2", { volume: 9 }),
+  ];
+  const keys = (es: CachedMarket[]) => es.map((e) => `${e.row.exchange}:${e.row.ticker}`);
+  assertEquals(keys(filterCachedMarkets(entries, { exchange: "polymarket" }, NOW)), ["polymarket:KXBTC-A-1"]);
+  assertEquals(keys(sortCachedMarkets(filterCachedMarkets(entries, {}, NOW), "volume")), [
+    "kalshi:KXBTC-A-2",
+    "polymarket:KXBTC-A-1",
+    "kalshi:KXBTC-A-1",
+  ]);
+});
+
+Deno.test("cacheServes: only current first pages", () => {
+  assertEquals(cacheServes({ status: "active" }), true);
+  assertEquals(cacheServes({ asOf: "2026-01-01T00:00:00Z" }), false);
+  assertEquals(cacheServes({ cursor: { sortValue: "x", exchange: "kalshi", ticker: "A" } }), false);
+});
+
+Deno.test("MarketCache: misses until loaded", () => {
+  const lookups: boolean[] = [];
+  const cache = new MarketCache({} as Database, { onLookup: (hit) => lookups.push(hit) });
+  assertEquals(cache.list({}), null);
+  assertEquals(cache.get("KXBTC-A-1"), null);
+  assertEquals(cache.count({}), null);
+  assertEquals(lookups, [false, false]);
+});
@@ -0,0 +1,28 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { decodeMarketCursor, encodeMarketCursor } from "../../../src/lib/db/markets.ts";
+
+Deno.test("market cursor round-trips sort value, exchange and ticker", () => {
+  const cursor = encodeMarketCursor("2026-01-05 15:00:00.123456+00", "kalshi", "KXBTCD-26JAN0516-T97499.99");
+  assertEquals(/^[A-Za-z0-9_-]+$/.test(cursor), true);
+  assertEquals(decodeMarketCursor(cursor), {
+    sortValue: "2026-01-05 15:00:00.123456+00",
+    exchange: "kalshi",
+    ticker: "KXBTCD-26JAN0516-T97499.99",
+  });
+});
+
+Deno.test("market cursors of exchanges sharing a ticker differ", () => {
+  const sortValue = "2026-01-05 15:00:00.123456+00";
+  const kalshi = encodeMarketCursor(sortValue, "kalshi", "BTC-USD");
+  const polymarket = encodeMarketCursor(sortValue, "polymarket", "BTC-USD");
+  assertEquals(kalshi === polymarket, false);
+  assertEquals(decodeMarketCursor(polymarket)?.exchange, "polymarket");
+});
+
+Deno.test("decodeMarketCursor rejects malformed cursors", () => {
+  assertEquals(decodeMarketCursor("not-a-cursor"), null);
+  assertEquals(decodeMarketCursor(btoa(JSON.stringify({ ticker: "X" }))), null);
+  assertEquals(decodeMarketCursor(btoa(JSON.stringify([1, "kalshi", "X"]))), null);
+  // Cursors from before markets were keyed by exchange
+  assertEquals(decodeMarketCursor(btoa(JSON.stringify(["2026-01-05", "X"]))), null);
+});
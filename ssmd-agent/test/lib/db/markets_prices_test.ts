@@ -0,0 +1,27 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { scanMarketPrices, toMarketResponse } from "../../../src/lib/db/markets.ts";
+import type { Market } from "../../../src/lib/db/schema.ts";
+
+const row = {
+  ticker: "KXBTCD-26JAN0516-T97499.99",
+  eventTicker: "KXBTCD-26JAN0516",
+  yesBid: "0.4500",
+  yesAsk: "0.4700",
+  noBid: "0.5300",
+  noAsk: null,
+  lastPrice: "0.4650",
+} as unknown as Market;
+
+Deno.test("scanMarketPrices types NUMERIC dollar columns", () => {
+  const prices = scanMarketPrices(row);
+  assertEquals(prices.yes_bid, { major_e4: 4500, currency: "USD" });
+  assertEquals(prices.no_ask, null);
+  assertEquals(prices.last_price, { major_e4: 4650, currency: "USD" });
+});
+
+Deno.test("toMarketResponse keeps the raw columns and adds typed prices", () => {
+  const response = toMarketResponse(row);
+  assertEquals(response.yesBid, "0.4500");
+  assertEquals(response.prices.yes_bid, { major_e4: 4500, currency: "USD", decimal: "0.4500" });
+  assertEquals(response.prices.no_ask, null);
+});
@@ -0,0 +1,41 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import {
+  DEFAULT_MIGRATIONS_DIR,
+  listMigrations,
+  parseMigration,
+  pendingMigrations,
+} from "../../../src/lib/db/migrate.ts";
+
+Deno.test("parseMigration splits up and down sections", () => {
+  const { up, down } = parseMigration(
+    "-- migrate:up\nCREATE TABLE t (id INT);\n\n-- migrate:down\nDROP TABLE t;\n",
+  );
+  assertEquals(up, "CREATE TABLE t (id INT);");
+  assertEquals(down, "DROP TABLE t;");
+
+  assertEquals(parseMigration("-- migrate:up\nSELECT 1;").down, "");
+  assertThrows(() => parseMigration("CREATE TABLE t (id INT);"), Error, "missing -- migrate:up");
+});
+
+Deno.test("listMigrations orders by version and skips other files", async () => {
+  const dir = await Deno.makeTempDir();
+  try {
+    for (const name of ["0010_later.sql", "0002_second.sql", "0001_first.sql", "README.md", "notes.sql"]) {
+      await Deno.writeTextFile(join(dir, name), "-- migrate:up\n");
+    }
+    const migrations = await listMigrations(dir);
+    assertEquals(migrations.map((m) => `${m.version}:${m.name}`), ["0001:first", "0002:second", "0010:later"]);
+    assertEquals(pendingMigrations(migrations, ["0001", "0010"]).map((m) => m.version), ["0002"]);
+  } finally {
+    await Deno.remove(dir, { recursive: true });
+  }
+});
+
+Deno.test("repository migrations all parse", async () => {
+  const migrations = await listMigrations(DEFAULT_MIGRATIONS_DIR);
+  assertEquals(migrations.length > 0, true);
+  for (const m of migrations) {
+    assertEquals(parseMigration(await Deno.readTextFile(m.path)).up.length > 0, true, m.path);
+  }
+});
@@ -0,0 +1,47 @@
+import { assertEquals, assertStringIncludes, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { buildDatasetSQL, datasetTables, statementSeparator } from "../../../src/lib/duckdb/queries.ts";
+
+Deno.test("datasetTables names parquet types and the raw archive", () => {
+  const tables = datasetTables("bkt", "kalshi", "2026-01-05");
+  assertEquals(Object.keys(tables), ["trade", "ticker", "raw"]);
+  assertStringIncludes(tables.trade, "s3://bkt/kalshi/kalshi/crypto/2026-01-05/trade_*.parquet");
+  assertStringIncludes(tables.raw, "s3://bkt/kalshi/kalshi/crypto/2026-01-05/*.jsonl.gz");
+  assertEquals(Object.keys(datasetTables("bkt", "binance", "2026-01-05")), ["trade", "raw"]);
+});
+
+Deno.test("buildDatasetSQL defines only the tables a query mentions", () => {
+  const sql = buildDatasetSQL("bkt", "kalshi", "2026-01-05", "SELECT count(*) FROM trade;", 50);
+  assertStringIncludes(sql, "WITH trade AS (SELECT * FROM read_parquet(");
+  assertEquals(sql.includes("raw AS"), false);
+  assertEquals(sql.includes("ticker AS"), false);
+  assertEquals(sql.endsWith("SELECT * FROM (\nSELECT count(*) FROM trade\n) LIMIT 50"), true);
+});
+
+Deno.test("buildDatasetSQL rejects empty and multi-statement queries", () => {
+  assertThrows(() => buildDatasetSQL("bkt", "kalshi", "2026-01-05", "  ; ", 10), Error, "empty");
+  assertThrows(
+    () => buildDatasetSQL("bkt", "kalshi", "2026-01-05", "SELECT 1; DROP TABLE trade", 10),
+    Error,
+    "single statement",
+  );
+  assertThrows(() => buildDatasetSQL("bkt", "kalshi", "2026-01-05", "x".repeat(10_001), 10), Error, "longer");
+});
+
+Deno.test("buildDatasetSQL allows semicolons inside string literals and comments", () => {
+  const sql = buildDatasetSQL("bkt", "kalshi", "2026-01-05", "SELECT * FROM trade WHERE ticker = 'a;b';", 10);
+  assertStringIncludes(sql, "SELECT * FROM (\nSELECT * FROM trade WHERE ticker = 'a;b'\n) LIMIT 10");
+  const commented = buildDatasetSQL("bkt", "kalshi", "2026-01-05", "SELECT 1 -- one; two\n/* ; */ ;", 10);
+  assertStringIncludes(commented, "SELECT 1 -- one; two\n/* ; */\n) LIMIT 10");
+  assertThrows(
+    () => buildDatasetSQL("bkt", "kalshi", "2026-01-05", "SELECT 'a;b'; DROP TABLE trade", 10),
+    Error,
+    "single statement",
+  );
+});
+
+Deno.test("statementSeparator skips quoted text and comments", () => {
+  assertEquals(statementSeparator("SELECT 1; SELECT 2"), 8);
+  assertEquals(statementSeparator("SELECT 'it''s;' AS \"a;b\""), -1);
+  assertEquals(statementSeparator("SELECT 'it''s'; x"), 14);
+  assertEquals(statementSeparator("SELECT 1 /* ; */ -- ;\n; x"), 22);
+});
@@ -0,0 +1,61 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { compileFilter, FilterError, parseFilter, recordResolver } from "../../../src/lib/filter/mod.ts";
+
+const trade = {
+  type: "trade",
+  msg: { market_ticker: "INXD-26JAN05", yes_price_dollars: "0.5500", count: 10, taker_side: "yes" },
+};
+
+function matches(expr: string, record: Record<string, unknown> = trade, derived = { ticker: "INXD-26JAN05", type: "trade" }) {
+  return compileFilter(expr).matches(recordResolver(record, derived));
+}
+
+Deno.test("parseFilter gives && precedence over ||", () => {
+  assertEquals(parseFilter("a == 1 || b == 2 && c == 3"), {
+    kind: "or",
+    left: { kind: "compare", field: "a", op: "==", value: 1 },
+    right: {
+      kind: "and",
+      left: { kind: "compare", field: "b", op: "==", value: 2 },
+      right: { kind: "compare", field: "c", op: "==", value: 3 },
+    },
+  });
+});
+
+Deno.test("filters compare derived fields, envelope fields and dotted paths", () => {
+  assertEquals(matches('ticker == "INXD-26JAN05" && yes_price_dollars > 0.4 && type in ("trade")'), true);
+  assertEquals(matches("msg.count >= 10 && count < 11"), true);
+  assertEquals(matches('type in ("ticker", "orderbook_delta")'), false);
+  assertEquals(matches('type not in ("ticker")'), true);
+  assertEquals(matches('!(taker_side == "no") && (count == 5 || count == 10)'), true);
+});
+
+Deno.test("numeric strings compare as numbers", () => {
+  assertEquals(matches("yes_price_dollars == 0.55"), true);
+  assertEquals(matches('count == "10"'), true);
+  assertEquals(matches("yes_price_dollars <= -1"), false);
+});
+
+Deno.test("comparisons on missing fields are false", () => {
+  assertEquals(matches("price > 0"), false);
+  assertEquals(matches("price != 0"), false);
+  assertEquals(matches('ticker in ("X")', trade, { ticker: null as unknown as string, type: "trade" }), false);
+  assertEquals(matches("!(price > 0)"), true);
+});
+
+Deno.test("parse errors report the position", () => {
+  const cases: [string, number][] = [
+    ['ticker == "INXD', 10],
+    ["price >", 7],
+    ["price > 1 &&", 12],
+    ["(price > 1", 10],
+    ["price ~ 1", 6],
+    ["price 1", 6],
+    ["type in (trade)", 9],
+  ];
+  for (const [expr, position] of cases) {
+    const err = assertThrows(() => parseFilter(expr), FilterError);
+    assertEquals(err.position, position, expr);
+  }
+  assertThrows(() => parseFilter("a == 1 && ".repeat(200)), FilterError);
+});
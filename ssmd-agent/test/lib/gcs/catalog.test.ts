@@ -0,0 +1,146 @@
+import { assertEquals, assertRejects } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  catalogStats,
+  FeedDateCache,
+  type FeedSummary,
+  missingDates,
+  msUntilUtc,
+  recentDates,
+} from "../../../src/lib/gcs/catalog.ts";
+
+Deno.test("FeedDateCache serves entries until the TTL and shares concurrent loads", async () => {
+  let now = 0;
+  let loads = 0;
+  const cache = new FeedDateCache<string>(() => 1000, () => now);
+  const load = () => {
+    loads++;
+    return Promise.resolve(`v${loads}`);
+  };
+
+  const [a, b] = await Promise.all([cache.get("kalshi", "2026-01-01", load), cache.get("kalshi", "2026-01-01", load)]);
+  assertEquals([a, b, loads], ["v1", "v1", 1]);
+
+  now = 999;
+  assertEquals(await cache.get("kalshi", "2026-01-01", load), "v1");
+  now = 1000;
+  assertEquals(await cache.get("kalshi", "2026-01-01", load), "v2");
+});
+
+Deno.test("FeedDateCache invalidates by feed and/or date", async () => {
+  const cache = new FeedDateCache<number>(() => 60_000);
+  for (const feed of ["kalshi", "kraken-futures"]) {
+    for (const date of ["2026-01-01", "2026-01-02"]) {
+      await cache.get(feed, date, () => Promise.resolve(1));
+    }
+  }
+
+  assertEquals(cache.invalidate("kalshi", "2026-01-01"), 1);
+  assertEquals(cache.invalidate(undefined, "2026-01-02"), 2);
+  assertEquals(cache.size, 1);
+  assertEquals(cache.invalidate(), 1);
+  assertEquals(cache.size, 0);
+});
+
+Deno.test("FeedDateCache does not store a load that was invalidated while in flight", async () => {
+  const cache = new FeedDateCache<string>(() => 60_000);
+  let release!: (value: string) => void;
+  const stale = cache.get("kalshi", "2026-01-01", () => new Promise<string>((resolve) => release = resolve));
+
+  cache.invalidate("kalshi");
+  const fresh = cache.get("kalshi", "2026-01-01", () => Promise.resolve("fresh"));
+  release("stale");
+
+  assertEquals(await stale, "stale");
+  assertEquals(await fresh, "fresh");
+  assertEquals(await cache.get("kalshi", "2026-01-01", () => Promise.resolve("reloaded")), "fresh");
+
+  let releaseOnly!: (value: string) => void;
+  const only = cache.get("kalshi", "2026-01-02", () => new Promise<string>((resolve) => releaseOnly = resolve));
+  cache.invalidate(undefined, "2026-01-02");
+  releaseOnly("stale");
+  await only;
+  assertEquals(await cache.get("kalshi", "2026-01-02", () => Promise.resolve("reloaded")), "reloaded");
+});
+
+Deno.test("FeedDateCache evicts expired entries on read and caps its size", async () => {
+  let now = 0;
+  const cache = new FeedDateCache<number>(() => 1000, () => now, 2);
+  await cache.get("kalshi", "2026-01-01", () => Promise.resolve(1));
+  now = 1000;
+  await assertRejects(() => cache.get("kalshi", "2026-01-01", () => Promise.reject(new Error("gcs down"))));
+  assertEquals(cache.size, 0);
+
+  for (const date of ["2026-01-01", "2026-01-02", "2026-01-03"]) {
+    await cache.get("kalshi", date, () => Promise.resolve(1));
+  }
+  assertEquals(cache.size, 2);
+  assertEquals(await cache.get("kalshi", "2026-01-01", () => Promise.resolve(2)), 2);
+});
+
+Deno.test("FeedDateCache does not keep failed loads or store with a zero TTL", async () => {
+  const cache = new FeedDateCache<number>(() => 60_000);
+  await assertRejects(() => cache.get("kalshi", "2026-01-01", () => Promise.reject(new Error("gcs down"))));
+  assertEquals(await cache.get("kalshi", "2026-01-01", () => Promise.resolve(2)), 2);
+
+  const disabled = new FeedDateCache<number>(() => 0);
+  await disabled.get("kalshi", "2026-01-01", () => Promise.resolve(1));
+  assertEquals(disabled.size, 0);
+});
+
+Deno.test("recentDates returns the days before today, oldest first", () => {
+  const now = new Date("2026-03-02T08:00:00Z");
+  assertEquals(recentDates(3, now), ["2026-02-27", "2026-02-28", "2026-03-01"]);
+});
+
+Deno.test("msUntilUtc waits for the next occurrence of HH:MM", () => {
+  const now = new Date("2026-03-02T08:00:00Z");
+  assertEquals(msUntilUtc("08:30", now), 30 * 60 * 1000);
+  assertEquals(msUntilUtc("08:00", now), 24 * 60 * 60 * 1000);
+  assertEquals(msUntilUtc("07:00", now), 23 * 60 * 60 * 1000);
+  assertEquals(msUntilUtc("25:00", now), null);
+});
+
+function summary(feed: string, dates: string[], totals: { files: number; bytes: number; rows: number }): FeedSummary {
+  return {
+    feed,
+    stream: "crypto",
+    prefix: feed,
+    message_types: ["trade"],
+    date_min: dates[0] ?? "",
+    date_max: dates.at(-1) ?? "",
+    total_files: totals.files,
+    total_bytes: totals.bytes,
+    total_rows: totals.rows,
+    dates,
+    schemas: {},
+  };
+}
+
+Deno.test("missingDates lists calendar days without data", () => {
+  assertEquals(missingDates(["2026-02-27", "2026-03-02"], "2026-02-27", "2026-03-02"), ["2026-02-28", "2026-03-01"]);
+  assertEquals(missingDates(["2026-01-01"], "2026-01-01", "2026-01-01"), []);
+});
+
+Deno.test("catalogStats rolls up per-feed and catalog-wide totals", () => {
+  const stats = catalogStats([
+    summary("kalshi", ["2026-01-01", "2026-01-02", "2026-01-04"], { files: 10, bytes: 1000, rows: 50 }),
+    summary("kraken-futures", ["2025-12-30", "2025-12-31"], { files: 4, bytes: 400, rows: 20 }),
+  ]);
+
+  assertEquals(stats.feeds[0].date_count, 3);
+  assertEquals(stats.feeds[0].gap_dates, ["2026-01-03"]);
+  assertEquals(stats.feeds[1].gap_dates, []);
+  assertEquals(stats.date_min, "2025-12-30");
+  assertEquals(stats.date_max, "2026-01-04");
+  assertEquals(stats.total_files, 14);
+  assertEquals(stats.total_bytes, 1400);
+  assertEquals(stats.total_rows, 70);
+  assertEquals(stats.gap_days, 1);
+});
+
+Deno.test("catalogStats handles feeds without dates", () => {
+  const stats = catalogStats([summary("polymarket", [], { files: 0, bytes: 0, rows: 0 })]);
+  assertEquals(stats.feeds[0].gap_dates, []);
+  assertEquals(stats.date_min, null);
+  assertEquals(stats.date_max, null);
+});
@@ -0,0 +1,109 @@
+import { assert, assertEquals, assertRejects } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { join } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { Readable } from "node:stream";
+import type { Storage } from "@google-cloud/storage";
+import { GcsObjects, headLines, LocalObjects, type ObjectStorage } from "../../../src/lib/gcs/objects.ts";
+
+const text = (stream: ReadableStream<Uint8Array>) => new Response(stream).text();
+
+async function gzipLines(lines: string[]): Promise<Uint8Array> {
+  const body = ReadableStream.from([lines.join("\n") + "\n"]).pipeThrough(new TextEncoderStream())
+    .pipeThrough(new CompressionStream("gzip"));
+  return new Uint8Array(await new Response(body).arrayBuffer());
+}
+
+const LINES = Array.from({ length: 20_000 }, (_, i) => JSON.stringify({ type: "ticker", seq: i }));
+
+async function withLocal(fn: (objects: LocalObjects, root: string) => Promise<void>) {
+  const root = await Deno.makeTempDir({ prefix: "ssmd-objects-" });
+  try {
+    await Deno.mkdir(join(root, "bucket", "kalshi"), { recursive: true });
+    await fn(new LocalObjects(root), root);
+  } finally {
+    await Deno.remove(root, { recursive: true });
+  }
+}
+
+/**
+ * A GCS-shaped storage fake serving one object in small chunks, recording
+ * the read options and how many chunks were pulled.
+ */
+function gcsFake(bytes: Uint8Array, chunkSize: number) {
+  const read = { opts: [] as { start?: number; end?: number }[], chunks: 0, closed: false };
+  const storage = {
+    bucket: () => ({
+      file: () => ({
+        createReadStream: (opts: { start?: number; end?: number } = {}) => {
+          read.opts.push(opts);
+          // end is inclusive, as in the GCS client
+          const body = bytes.subarray(opts.start ?? 0, opts.end === undefined ? undefined : opts.end + 1);
+          const stream = Readable.from((function* () {
+            for (let i = 0; i < body.byteLength; i += chunkSize) {
+              read.chunks++;
+              yield body.subarray(i, i + chunkSize);
+            }
+          })());
+          stream.on("close", () => read.closed = true);
+          return stream;
+        },
+      }),
+    }),
+  } as unknown as Storage;
+  return { objects: new GcsObjects(storage), read };
+}
+
+Deno.test("LocalObjects reads whole objects and byte ranges", async () => {
+  await withLocal(async (objects, root) => {
+    await Deno.writeTextFile(join(root, "bucket", "kalshi", "a.txt"), "0123456789");
+    assertEquals(await text(objects.read("bucket", "kalshi/a.txt")), "0123456789");
+    assertEquals(await text(objects.read("bucket", "kalshi/a.txt", { start: 2, end: 5 })), "234");
+    assertEquals(await text(objects.read("bucket", "kalshi/a.txt", { start: 7 })), "789");
+    assertEquals(await text(objects.read("bucket", "kalshi/a.txt", { start: 4, end: 4 })), "");
+  });
+});
+
+Deno.test("LocalObjects fails the stream for a missing object", async () => {
+  await withLocal(async (objects) => {
+    await assertRejects(() => text(objects.read("bucket", "kalshi/missing.txt")), Deno.errors.NotFound);
+  });
+});
+
+Deno.test("LocalObjects headLines decompresses only the start of the object", async () => {
+  await withLocal(async (objects, root) => {
+    const path = join(root, "bucket", "kalshi", "0000.jsonl.gz");
+    await Deno.writeFile(path, await gzipLines(LINES));
+    assertEquals(await headLines(objects, "bucket", "kalshi/0000.jsonl.gz", 3), LINES.slice(0, 3));
+  });
+});
+
+Deno.test("GcsObjects passes ranges to GCS with an inclusive end", async () => {
+  const { objects, read } = gcsFake(new TextEncoder().encode("0123456789"), 4);
+  assertEquals(await text(objects.read("bucket", "a.txt", { start: 2, end: 5 })), "234");
+  assertEquals(await text(objects.read("bucket", "a.txt", { start: 7 })), "789");
+  assertEquals(await text(objects.read("bucket", "a.txt")), "0123456789");
+  assertEquals(read.opts, [{ start: 2, end: 4 }, { start: 7, end: undefined }, {}]);
+  // An empty range never reaches GCS
+  assertEquals(await text(objects.read("bucket", "a.txt", { start: 3, end: 3 })), "");
+  assertEquals(read.opts.length, 3);
+});
+
+Deno.test("GcsObjects headLines stops downloading at the limit", async () => {
+  const gzipped = await gzipLines(LINES);
+  const { objects, read } = gcsFake(gzipped, 256);
+  const total = Math.ceil(gzipped.byteLength / 256);
+
+  assertEquals(await headLines(objects, "bucket", "0000.jsonl.gz", 3), LINES.slice(0, 3));
+  // Only the first chunks (plus read-ahead) were pulled, and the stream was closed
+  assert(read.chunks < total / 4, `read ${read.chunks} of ${total} chunks`);
+  await new Promise((resolve) => setTimeout(resolve, 0));
+  assert(read.closed, "object stream left open");
+});
+
+Deno.test("headLines reads to the end of short objects", async () => {
+  const objects: ObjectStorage = {
+    read: () => ReadableStream.from([LINES.slice(0, 2).join("\n") + "\n"]).pipeThrough(new TextEncoderStream())
+      .pipeThrough(new CompressionStream("gzip")),
+  };
+  assertEquals(await headLines(objects, "bucket", "0000.jsonl.gz", 10), LINES.slice(0, 2));
+  assertEquals(await headLines(objects, "bucket", "0000.jsonl.gz", 0), []);
+});
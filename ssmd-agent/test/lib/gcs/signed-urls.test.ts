@@ -1,10 +1,12 @@
 import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
 import {
+  downloadName,
   FEED_CONFIG,
   feedDescription,
   type FeedInfo,
   gcsDirPrefix,
   parseFileType,
+  rawFileSlot,
   scanLayout,
   usesFlatLayout,
 } from "../../../src/lib/gcs/signed-urls.ts";
@@ -78,3 +80,16 @@ Deno.test("parseFileType: nested archiver name splits on last underscore", () =>
 Deno.test("parseFileType: nested name without underscore is skipped (null)", () => {
   assertEquals(parseFileType("ohlcv1m", false, "2026-06-20"), null);
 });
+
+Deno.test("downloadName: prefixes the date folder so multi-day downloads don't collide", () => {
+  const file = { path: "kalshi/kalshi/crypto/2026-02-15/ticker_0000.parquet", name: "ticker_0000.parquet", type: "ticker", hour: "0000", bytes: 1 };
+  assertEquals(downloadName(file), "2026-02-15-ticker_0000.parquet");
+  assertEquals(downloadName({ ...file, path: "ticker_0000.parquet" }), "ticker_0000.parquet");
+});
+
+Deno.test("rawFileSlot: archiver JSONL.gz names give their rotation slot", () => {
+  assertEquals(rawFileSlot("0015.jsonl.gz"), "0015");
+  assertEquals(rawFileSlot("0015-2.jsonl.gz"), "0015");
+  assertEquals(rawFileSlot("ticker_0015.parquet"), null);
+  assertEquals(rawFileSlot("manifest.json"), null);
+});
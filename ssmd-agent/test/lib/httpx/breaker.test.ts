@@ -0,0 +1,47 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { CircuitBreaker, CircuitOpenError } from "../../../src/lib/httpx/mod.ts";
+
+function breaker(clock: { now: number }): CircuitBreaker {
+  return new CircuitBreaker("api.test", { failureThreshold: 2, resetAfterMs: 1000, now: () => clock.now });
+}
+
+Deno.test("CircuitBreaker opens after consecutive failures", () => {
+  const clock = { now: 0 };
+  const b = breaker(clock);
+
+  b.onFailure();
+  b.check();
+  b.onFailure();
+  assertEquals(b.state, "open");
+  assertThrows(() => b.check(), CircuitOpenError, "retry in 1000ms");
+});
+
+Deno.test("CircuitBreaker success resets the failure count", () => {
+  const b = breaker({ now: 0 });
+
+  b.onFailure();
+  b.onSuccess();
+  b.onFailure();
+  assertEquals(b.state, "closed");
+});
+
+Deno.test("CircuitBreaker half-opens for a single trial request", () => {
+  const clock = { now: 0 };
+  const b = breaker(clock);
+  b.onFailure();
+  b.onFailure();
+
+  clock.now = 1000;
+  assertEquals(b.state, "half-open");
+  b.check();
+  assertThrows(() => b.check(), CircuitOpenError);
+
+  // A failed trial re-opens for another full period
+  b.onFailure();
+  assertEquals(b.state, "open");
+
+  clock.now = 2000;
+  b.check();
+  b.onSuccess();
+  assertEquals(b.state, "closed");
+});
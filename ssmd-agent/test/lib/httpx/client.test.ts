@@ -0,0 +1,122 @@
+import { assertEquals, assertRejects } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  backoffDelay,
+  CircuitOpenError,
+  HttpClient,
+  HttpError,
+  parseRetryAfter,
+} from "../../../src/lib/httpx/mod.ts";
+
+/** Fetch stub replaying responses (or thrown errors) in order */
+function stubFetch(responses: (Response | Error)[]): { fetch: typeof fetch; calls: () => number } {
+  let calls = 0;
+  const fn = (() => {
+    const next = responses[calls++];
+    return next instanceof Error ? Promise.reject(next) : Promise.resolve(next);
+  }) as typeof fetch;
+  return { fetch: fn, calls: () => calls };
+}
+
+function client(stub: { fetch: typeof fetch }, sleeps: number[] = [], host = "api.test"): [HttpClient, string] {
+  const http = new HttpClient({
+    name: "Test",
+    retry: { maxRetries: 2, initialDelayMs: 100 },
+    fetch: stub.fetch,
+    sleep: (ms) => {
+      sleeps.push(ms);
+      return Promise.resolve();
+    },
+  });
+  return [http, `https://${host}/v1/things`];
+}
+
+Deno.test("HttpClient returns the first 2xx response", async () => {
+  const stub = stubFetch([Response.json({ ok: true })]);
+  const [http, url] = client(stub, [], "ok.test");
+
+  assertEquals(await http.getJson(url), { ok: true });
+  assertEquals(stub.calls(), 1);
+});
+
+Deno.test("HttpClient retries 5xx and network errors with backoff", async () => {
+  const sleeps: number[] = [];
+  const stub = stubFetch([new TypeError("connection reset"), new Response("busy", { status: 503 }), Response.json([1])]);
+  const [http, url] = client(stub, sleeps, "flaky.test");
+
+  assertEquals(await http.getJson(url), [1]);
+  assertEquals(stub.calls(), 3);
+  assertEquals(sleeps.length, 2);
+  // 100ms then 200ms, each ±25%
+  assertEquals(sleeps[0] >= 75 && sleeps[0] <= 125, true, `first backoff ${sleeps[0]}`);
+  assertEquals(sleeps[1] >= 150 && sleeps[1] <= 250, true, `second backoff ${sleeps[1]}`);
+});
+
+Deno.test("HttpClient honours Retry-After on 429", async () => {
+  const sleeps: number[] = [];
+  const stub = stubFetch([
+    new Response("slow down", { status: 429, headers: { "Retry-After": "2" } }),
+    Response.json({}),
+  ]);
+  const [http, url] = client(stub, sleeps, "limited.test");
+
+  await http.getJson(url);
+  assertEquals(sleeps, [2000]);
+});
+
+Deno.test("HttpClient does not retry other 4xx", async () => {
+  const stub = stubFetch([new Response("missing", { status: 404 })]);
+  const [http, url] = client(stub, [], "missing.test");
+
+  const err = await assertRejects(() => http.request(url), HttpError);
+  assertEquals(err.status, 404);
+  assertEquals(err.message, "Test API error 404: missing");
+  assertEquals(stub.calls(), 1);
+});
+
+Deno.test("HttpClient gives up after maxRetries", async () => {
+  const stub = stubFetch([500, 500, 500].map((status) => new Response("down", { status })));
+  const [http, url] = client(stub, [], "down.test");
+
+  await assertRejects(() => http.request(url), HttpError, "500");
+  assertEquals(stub.calls(), 3);
+});
+
+Deno.test("HttpClient stops on caller abort", async () => {
+  const controller = new AbortController();
+  controller.abort(new Error("cancelled"));
+  const stub = stubFetch([Response.json({})]);
+  const [http, url] = client(stub, [], "abort.test");
+
+  await assertRejects(() => http.request(url, { signal: controller.signal }), Error, "cancelled");
+  assertEquals(stub.calls(), 0);
+});
+
+Deno.test("HttpClient fails fast once the host's circuit opens", async () => {
+  const stub = stubFetch(Array.from({ length: 6 }, () => new Response("down", { status: 502 })));
+  const [http, url] = client(stub, [], "breaker.test");
+
+  // Two requests of three attempts each reach the default threshold of 5
+  await assertRejects(() => http.request(url), HttpError);
+  await assertRejects(() => http.request(url), CircuitOpenError);
+  assertEquals(stub.calls(), 5);
+});
+
+Deno.test("backoffDelay doubles up to the cap", () => {
+  const policy = { maxRetries: 5, initialDelayMs: 1000, maxDelayMs: 5000 };
+  const noJitter = () => 0.5;
+
+  assertEquals(backoffDelay(0, policy, noJitter), 1000);
+  assertEquals(backoffDelay(1, policy, noJitter), 2000);
+  assertEquals(backoffDelay(5, policy, noJitter), 5000);
+  assertEquals(backoffDelay(0, policy, () => 0), 750);
+  assertEquals(backoffDelay(0, policy, () => 1), 1250);
+});
+
+Deno.test("parseRetryAfter reads seconds and HTTP dates", () => {
+  const now = Date.parse("2026-01-04T10:00:00Z");
+
+  assertEquals(parseRetryAfter("5", now), 5000);
+  assertEquals(parseRetryAfter("Sun, 04 Jan 2026 10:00:30 GMT", now), 30_000);
+  assertEquals(parseRetryAfter(null, now), undefined);
+  assertEquals(parseRetryAfter("soon", now), undefined);
+});
@@ -0,0 +1,31 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { checkpointKey, checkpointValue, parseCheckpoints } from "../../../src/lib/nats/mod.ts";
+
+Deno.test("checkpointKey joins stream and consumer and rejects dotted names", () => {
+  assertEquals(checkpointKey("PROD_KALSHI", "archiver-kalshi"), "PROD_KALSHI.archiver-kalshi");
+  assertThrows(() => checkpointKey("PROD_KALSHI", ""), Error, "required");
+  assertThrows(() => checkpointKey("PROD.KALSHI", "archiver"), Error, "invalid checkpoint key");
+  assertThrows(() => checkpointKey("PROD_KALSHI", "a/b"), Error, "invalid checkpoint key");
+});
+
+Deno.test("checkpointValue records the sequence and time", () => {
+  assertEquals(
+    checkpointValue(50, new Date("2026-01-04T10:00:00.123Z")),
+    `{"sequence":50,"updated_at":"2026-01-04T10:00:00.123Z"}`,
+  );
+});
+
+Deno.test("parseCheckpoints filters by stream and sorts", () => {
+  const data = {
+    "PROD_KALSHI.signal-volume": `{"sequence":2,"updated_at":"2026-01-04T10:00:00Z"}`,
+    "PROD_KALSHI.archiver-kalshi": `{"sequence":1,"updated_at":"2026-01-04T10:00:00Z"}`,
+    "PROD_KRAKEN.archiver-kraken": `{"sequence":3,"updated_at":"2026-01-04T10:00:00Z"}`,
+  };
+
+  assertEquals(parseCheckpoints(data, "PROD_KALSHI").map((cp) => [cp.consumer, cp.sequence]), [
+    ["archiver-kalshi", 1],
+    ["signal-volume", 2],
+  ]);
+  assertEquals(parseCheckpoints(data).length, 3);
+  assertEquals(parseCheckpoints(undefined), []);
+});
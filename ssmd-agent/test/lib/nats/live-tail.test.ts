@@ -0,0 +1,53 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  matchesTailFilter,
+  parseTailSubject,
+  RateLimiter,
+  tailResolver,
+  tailSubjects,
+} from "../../../src/lib/nats/live-tail.ts";
+import { sanitizeToken } from "../../../src/lib/archive/mod.ts";
+import { compileFilter } from "../../../src/lib/filter/mod.ts";
+
+Deno.test("parseTailSubject handles subjects with and without a category", () => {
+  assertEquals(parseTailSubject("prod.kraken.json.trade.BTC-USD"), { type: "trade", ticker: "BTC-USD" });
+  assertEquals(
+    parseTailSubject("prod.kalshi.crypto.json.ticker.KXBTCD-26JAN0516-T97499"),
+    { type: "ticker", ticker: "KXBTCD-26JAN0516-T97499" },
+  );
+  assertEquals(parseTailSubject("prod.kalshi.trade.KXBTCD-26JAN0516-T97499"), null);
+});
+
+Deno.test("tailSubjects narrows to tickers when given", () => {
+  const all = { types: new Set<string>(), tickers: new Set<string>() };
+  assertEquals(tailSubjects("prod.kalshi", all), ["prod.kalshi.>"]);
+
+  const one = { types: new Set<string>(), tickers: new Set(["KXBTCD"]) };
+  assertEquals(tailSubjects("prod.kalshi", one), ["prod.kalshi.json.*.KXBTCD", "prod.kalshi.*.json.*.KXBTCD"]);
+});
+
+Deno.test("matchesTailFilter applies type and ticker filters", () => {
+  const filter = { types: new Set(["trade"]), tickers: new Set<string>() };
+  assertEquals(matchesTailFilter({ type: "trade", ticker: "A" }, filter), true);
+  assertEquals(matchesTailFilter({ type: "ticker", ticker: "A" }, filter), false);
+});
+
+Deno.test("sanitizeToken strips wildcards and separators", () => {
+  assertEquals(sanitizeToken("BTC/USD"), "BTC-USD");
+  assertEquals(sanitizeToken("inject.*.>"), "inject");
+});
+
+Deno.test("RateLimiter drops messages over the per-second limit", () => {
+  const limiter = new RateLimiter(2);
+  assertEquals([limiter.allow(0), limiter.allow(100), limiter.allow(200)], [true, true, false]);
+  assertEquals(limiter.dropped, 1);
+  assertEquals(limiter.allow(1000), true);
+});
+
+Deno.test("tailResolver takes type and ticker from the subject", () => {
+  const filter = compileFilter('type == "trade" && ticker == "BTC-USD" && price > 100');
+  const parsed = { type: "trade", ticker: "BTC-USD" };
+  assertEquals(filter.matches(tailResolver(parsed, { data: { price: "101.5" } })), true);
+  assertEquals(filter.matches(tailResolver(parsed, { data: { price: "99" } })), false);
+  assertEquals(filter.matches(tailResolver(parsed, "not an object")), false);
+});
@@ -0,0 +1,88 @@
+import { assertAlmostEquals, assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  buildBaseline,
+  detectAnomalies,
+  qualityAlert,
+  qualityAlertSubject,
+  type QualityReport,
+  type TickerQuality,
+  zScore,
+} from "../../../src/lib/quality/mod.ts";
+
+function ticker(rate: number, gap: number | null = 10, spread: number | null = 20): TickerQuality {
+  return {
+    messages: rate * 1440,
+    rate_per_min: rate,
+    quotes: rate * 1440,
+    max_quote_gap_sec: gap,
+    last_quote_age_sec: 1,
+    spread_bps: spread === null ? null : { p50: spread, p90: spread * 2 },
+  };
+}
+
+function report(date: string, tickers: Record<string, TickerQuality>): QualityReport {
+  return {
+    feed: "kalshi",
+    source: "archive",
+    window_start: `${date}T00:00:00.000Z`,
+    window_end: `${date}T23:59:59.999Z`,
+    generated_at: `${date}T23:59:59.999Z`,
+    duplicates: 0,
+    tickers,
+  };
+}
+
+const history = [
+  report("2026-01-10", { "KXBTC-A": ticker(100), "KXBTC-B": ticker(10), "QUIET": ticker(0.1) }),
+  report("2026-01-11", { "KXBTC-A": ticker(110), "KXBTC-B": ticker(12), "QUIET": ticker(0.2) }),
+  report("2026-01-12", { "KXBTC-A": ticker(90), "KXBTC-B": ticker(11), "QUIET": ticker(0.1) }),
+];
+
+Deno.test("buildBaseline averages each metric per ticker", () => {
+  const baseline = buildBaseline(history);
+  assertEquals(baseline.days, 3);
+  const rate = baseline.tickers["KXBTC-A"].rate_per_min!;
+  assertEquals(rate.mean, 100);
+  assertAlmostEquals(rate.stddev, Math.sqrt(200 / 3));
+  assertEquals(rate.days, 3);
+  assertEquals(baseline.tickers["KXBTC-A"].spread_p50_bps, { mean: 20, stddev: 0, days: 3 });
+});
+
+Deno.test("zScore floors stddev at 10% of the mean", () => {
+  assertEquals(zScore(70, { mean: 100, stddev: 0, days: 3 }), -3);
+  assertEquals(zScore(70, { mean: 100, stddev: 15, days: 3 }), -2);
+});
+
+Deno.test("detectAnomalies flags rate drops, stale quotes, wide spreads and missing tickers", () => {
+  const baseline = buildBaseline(history);
+  const today = report("2026-01-13", {
+    "KXBTC-A": ticker(20, 10, 100),
+    "QUIET": ticker(0),
+  });
+  const anomalies = detectAnomalies(today, baseline);
+
+  assertEquals(anomalies.map((a) => [a.ticker, a.metric, a.severity]), [
+    ["KXBTC-B", "missing", "high"],
+    ["KXBTC-A", "spread_p50_bps", "high"],
+    ["KXBTC-A", "rate_per_min", "high"],
+  ]);
+});
+
+Deno.test("detectAnomalies needs minDays of history", () => {
+  const baseline = buildBaseline(history.slice(0, 2));
+  assertEquals(detectAnomalies(report("2026-01-13", {}), baseline), []);
+});
+
+Deno.test("qualityAlert subject and payload target the Notifier's alerts.<feed>.> subscription", () => {
+  const baseline = buildBaseline(history);
+  const today = report("2026-01-13", { "KXBTC-A": ticker(100, 600) });
+  const [anomaly] = detectAnomalies(today, baseline).filter((a) => a.ticker === "KXBTC-A");
+  assertEquals(anomaly.metric, "max_quote_gap_sec");
+  assertEquals(qualityAlertSubject("kalshi", anomaly), "alerts.kalshi.quality.max_quote_gap_sec.KXBTC-A");
+
+  const alert = qualityAlert(today, anomaly, Date.parse("2026-01-14T00:00:00Z"));
+  assertEquals(alert.type, "quality");
+  assertEquals(alert.feed, "kalshi");
+  assertEquals(alert.value, 600);
+  assertEquals(alert.detected_at, "2026-01-14T00:00:00.000Z");
+});
@@ -0,0 +1,59 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { extractQuote, histogramPercentile, QualityAccumulator, SPREAD_BUCKETS_BPS, spreadBps } from "../../../src/lib/quality/mod.ts";
+
+Deno.test("extractQuote reads Kalshi cents and dollar formats", () => {
+  assertEquals(extractQuote("kalshi", { type: "ticker", msg: { yes_bid: 40, yes_ask: 42 } }), { bid: 0.4, ask: 0.42 });
+  assertEquals(
+    extractQuote("kalshi", { type: "ticker", msg: { yes_bid_dollars: "0.4000", yes_ask_dollars: "0.4200" } }),
+    { bid: 0.4, ask: 0.42 },
+  );
+  assertEquals(extractQuote("kalshi", { type: "trade", msg: { yes_price: 41 } }), null);
+});
+
+Deno.test("extractQuote reads Kraken spot tickers and ignores trade-only feeds", () => {
+  const ticker = { channel: "ticker", type: "update", data: [{ symbol: "BTC/USD", bid: 100, ask: 101 }] };
+  assertEquals(extractQuote("kraken-spot", ticker), { bid: 100, ask: 101 });
+  assertEquals(extractQuote("kraken-spot", { channel: "trade", data: [{ symbol: "BTC/USD", price: 100 }] }), null);
+  assertEquals(extractQuote("binance", { data: { e: "trade", s: "BTCUSDT", p: "100" } }), null);
+});
+
+Deno.test("spreadBps skips empty and crossed books", () => {
+  assertEquals(spreadBps({ bid: 99, ask: 101 }), 200);
+  assertEquals(spreadBps({ bid: 0, ask: 0.05 }), null);
+  assertEquals(spreadBps({ bid: 101, ask: 99 }), null);
+});
+
+Deno.test("histogramPercentile returns bucket upper bounds", () => {
+  const counts = SPREAD_BUCKETS_BPS.map(() => 0);
+  counts[2] = 5; // <= 5 bps
+  counts[5] = 4; // <= 50 bps
+  counts[8] = 1; // <= 500 bps
+  assertEquals(histogramPercentile(counts, 0.5), 5);
+  assertEquals(histogramPercentile(counts, 0.9), 50);
+  assertEquals(histogramPercentile(counts.map(() => 0), 0.5), null);
+});
+
+Deno.test("QualityAccumulator reports rate, quote gaps and spreads per ticker", () => {
+  const acc = new QualityAccumulator("kraken-spot");
+  const start = Date.parse("2026-01-15T00:00:00Z");
+  acc.add("BTC/USD", start + 10_000, { bid: 99.95, ask: 100.05 });
+  acc.add("BTC/USD", start + 20_000, null);
+  acc.add("BTC/USD", start + 70_000, { bid: 99.95, ask: 100.05 });
+  // Out of order (overlapping archive file): counted, but not a gap
+  acc.add("BTC/USD", start + 30_000, { bid: 99.95, ask: 100.05 });
+  acc.add("ETH/USD", start + 5_000, null);
+
+  const report = acc.report("archive", start, start + 120_000, start + 120_000);
+  assertEquals(report.window_start, "2026-01-15T00:00:00.000Z");
+  assertEquals(Object.keys(report.tickers), ["BTC/USD", "ETH/USD"]);
+  assertEquals(report.tickers["BTC/USD"], {
+    messages: 4,
+    rate_per_min: 2,
+    quotes: 3,
+    max_quote_gap_sec: 60,
+    last_quote_age_sec: 50,
+    spread_bps: { p50: 10, p90: 10 },
+  });
+  assertEquals(report.tickers["ETH/USD"].spread_bps, null);
+  assertEquals(report.tickers["ETH/USD"].last_quote_age_sec, null);
+});
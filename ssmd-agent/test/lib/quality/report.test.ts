@@ -0,0 +1,36 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { newDedupWindow } from "../../../src/lib/archive/mod.ts";
+import { observeMessage, previousDates, QualityAccumulator, qualityReportPath } from "../../../src/lib/quality/mod.ts";
+
+Deno.test("previousDates lists the days before a date, most recent first", () => {
+  assertEquals(previousDates("2026-03-02", 3), ["2026-03-01", "2026-02-28", "2026-02-27"]);
+});
+
+Deno.test("qualityReportPath sits next to the day's manifest", () => {
+  assertEquals(qualityReportPath("kalshi", "2026-03-02"), "kalshi/kalshi/crypto/2026-03-02/quality.json");
+});
+
+Deno.test("observeMessage skips messages without a ticker and falls back to the subject ticker", () => {
+  const acc = new QualityAccumulator("kalshi");
+  observeMessage(acc, { type: "subscribed", msg: { channel: "ticker" } }, 0);
+  observeMessage(acc, { type: "ticker", msg: { market_ticker: "KXBTC-A", yes_bid: 40, yes_ask: 42 } }, 1000);
+  observeMessage(acc, { type: "trade", msg: {} }, 2000, "KXBTC-B");
+
+  const report = acc.report("live", 0, 60_000, 60_000);
+  assertEquals(Object.keys(report.tickers), ["KXBTC-A", "KXBTC-B"]);
+  assertEquals(report.tickers["KXBTC-A"].quotes, 1);
+  assertEquals(report.tickers["KXBTC-B"].quotes, 0);
+});
+
+Deno.test("observeMessage counts duplicates apart from the ticker metrics", () => {
+  const acc = new QualityAccumulator("kalshi");
+  const window = newDedupWindow("kalshi", { trade: ["msg.market_ticker", "msg.trade_id"] });
+  const trade = { type: "trade", msg: { market_ticker: "KXBTC-A", trade_id: "t1", yes_price: 40 } };
+  observeMessage(acc, trade, 1000, undefined, window);
+  observeMessage(acc, { ...trade, _received_at: 1_250_000 }, 1250, undefined, window);
+  observeMessage(acc, { ...trade, msg: { ...trade.msg, trade_id: "t2" } }, 2000, undefined, window);
+
+  const report = acc.report("archive", 0, 60_000, 60_000);
+  assertEquals(report.duplicates, 1);
+  assertEquals(report.tickers["KXBTC-A"].messages, 2);
+});
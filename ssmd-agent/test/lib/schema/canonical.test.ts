@@ -0,0 +1,66 @@
+import { assertEquals, assertNotEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { canonicalizeSource, canonicalSourceHash, rawSourceHash, sourceMatches } from "../../../src/lib/schema/mod.ts";
+
+const enc = (s: string) => new TextEncoder().encode(s);
+
+const PROTO = `syntax = "proto3";
+import "b.proto";
+import "a.proto";
+
+// A trade
+message Trade {
+  string ticker = 1;   /* market */
+  int64 price = 2;
+}
+`;
+
+const PROTO_REFORMATTED = `syntax="proto3";
+import "a.proto";   // sorted differently
+import "b.proto";
+message Trade { string ticker=1; int64   price = 2; }
+`;
+
+Deno.test("canonicalizeSource ignores protobuf comments, whitespace and import order", async () => {
+  assertEquals(canonicalizeSource("protobuf", PROTO), canonicalizeSource("protobuf", PROTO_REFORMATTED));
+  assertEquals(await canonicalSourceHash("protobuf", enc(PROTO)), await canonicalSourceHash("protobuf", enc(PROTO_REFORMATTED)));
+  assertNotEquals(await rawSourceHash(enc(PROTO)), await rawSourceHash(enc(PROTO_REFORMATTED)));
+
+  // Whitespace inside string literals and field changes still count
+  assertNotEquals(canonicalizeSource("protobuf", PROTO), canonicalizeSource("protobuf", PROTO.replace('"a.proto"', '"a .proto"')));
+  assertNotEquals(canonicalizeSource("protobuf", PROTO), canonicalizeSource("protobuf", PROTO.replace("price = 2", "price = 3")));
+});
+
+Deno.test("canonicalizeSource handles capnp comments and using imports", () => {
+  const a = `@0xdbb9ad1f14bf0b36;
+using Json = import "/capnp/compat/json.capnp";
+using Cxx = import "/capnp/c++.capnp";
+struct Trade {  # a trade
+  ticker @0 :Text;
+}
+`;
+  const b = `@0xdbb9ad1f14bf0b36;
+using Cxx = import "/capnp/c++.capnp";
+using Json = import "/capnp/compat/json.capnp";
+struct Trade {
+  ticker @0 : Text;  # the market
+}`;
+  assertEquals(canonicalizeSource("capnp", a), canonicalizeSource("capnp", b));
+});
+
+Deno.test("canonicalizeSource sorts JSON Schema keys", () => {
+  assertEquals(
+    canonicalizeSource("json-schema", '{"type": "object", "properties": {"b": {"type": "string"}, "a": {"type": "integer"}}}'),
+    canonicalizeSource("json-schema", '{\n  "properties": {"a": {"type": "integer"}, "b": {"type": "string"}},\n  "type": "object"\n}'),
+  );
+});
+
+Deno.test("sourceMatches prefers the canonical hash and falls back to raw bytes", async () => {
+  const raw = await rawSourceHash(enc(PROTO));
+  const canonical = await canonicalSourceHash("protobuf", enc(PROTO));
+
+  assertEquals(await sourceMatches("protobuf", enc(PROTO_REFORMATTED), { canonical_sha256: canonical }), true);
+  // Older records hold only the raw hash, which a reformat no longer matches
+  assertEquals(await sourceMatches("protobuf", enc(PROTO), { sha256: raw }), true);
+  assertEquals(await sourceMatches("protobuf", enc(PROTO_REFORMATTED), { sha256: raw }), false);
+  assertEquals(await sourceMatches("protobuf", enc(PROTO), {}), false);
+});
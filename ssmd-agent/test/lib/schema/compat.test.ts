@@ -0,0 +1,123 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { compareFields, fieldsFromCapnp, fieldsFromJsonSchema, fieldsFromProto, isBreaking } from "../../../src/lib/schema/mod.ts";
+
+Deno.test("compareFields classifies changes for backward compatibility", () => {
+  const v1 = {
+    "msg.ticker": { type: "string", required: true },
+    "msg.price": { type: "integer", required: true },
+    "msg.side": { type: "string" },
+    "msg.ts": { type: "integer" },
+  } as const;
+  const v2 = {
+    "msg.ticker": { type: "string", required: true },
+    "msg.price": { type: "number", required: true },
+    "msg.count": { type: "integer" },
+    "msg.ts": { type: "string", required: true },
+  } as const;
+
+  assertEquals(compareFields(v1, v2).map((c) => [c.path, c.kind, c.breaking]), [
+    ["msg.count", "added", false],
+    ["msg.price", "type_changed", false],
+    ["msg.side", "removed", false],
+    ["msg.ts", "type_changed", true],
+    ["msg.ts", "required_changed", true],
+  ]);
+  assertEquals(isBreaking(compareFields(v1, v1)), false);
+  assertEquals(isBreaking(compareFields(v1, { ...v1, "msg.id": { type: "string", required: true } })), true);
+});
+
+Deno.test("fieldsFromJsonSchema flattens nested objects, arrays and local refs to leaf paths", () => {
+  const fields = fieldsFromJsonSchema({
+    type: "object",
+    required: ["type", "msg"],
+    properties: {
+      type: { type: "string" },
+      msg: {
+        type: "object",
+        required: ["market_ticker"],
+        properties: {
+          market_ticker: { type: "string" },
+          yes_price: { type: ["integer", "null"] },
+        },
+      },
+      data: { type: "array", items: { $ref: "#/$defs/trade" } },
+    },
+    $defs: {
+      trade: { type: "object", required: ["qty"], properties: { qty: { type: "number" } } },
+    },
+  });
+
+  assertEquals(fields, {
+    type: { type: "string", required: true },
+    "msg.market_ticker": { type: "string", required: true },
+    "msg.yes_price": { type: ["integer", "null"] },
+    "data[].qty": { type: "number", required: true },
+  });
+  assertThrows(() => fieldsFromJsonSchema({ properties: { a: { $ref: "other.json#/a" } } }), Error, "local $refs");
+});
+
+Deno.test("fieldsFromProto maps scalars, enums, nested and repeated messages", () => {
+  const proto = `
+syntax = "proto2";
+// Trade as published by the connector
+message Trade {
+  required string ticker = 1;
+  optional int64 price = 2; /* cents */
+  enum Side { YES = 0; NO = 1; }
+  optional Side side = 3;
+  repeated Fill fills = 4;
+  map<string, string> tags = 5;
+  oneof size { int32 count = 6; string count_fp = 7; }
+  message Fill {
+    required double qty = 1;
+  }
+}
+message Other { optional string x = 1; }
+`;
+  assertEquals(fieldsFromProto(proto, "Trade"), {
+    ticker: { type: "string", required: true },
+    price: { type: "integer" },
+    side: { type: "string" },
+    "fills[].qty": { type: "number", required: true },
+    tags: { type: "object" },
+    count: { type: "integer" },
+    count_fp: { type: "string" },
+  });
+  assertThrows(() => fieldsFromProto(proto), Error, "2 top-level messages");
+  assertThrows(() => fieldsFromProto("message A { optional Missing m = 1; }"), Error, "unknown type Missing");
+});
+
+Deno.test("fieldsFromCapnp flattens structs, lists, groups and unions", () => {
+  const capnp = `@0xdbb9ad1f14bf0b36;
+using Cxx = import "/capnp/c++.capnp";
+
+struct Trade {
+  ticker @0 :Text;            # market ticker
+  price @1 :Int64 = 0;
+  side @2 :Side;
+  fills @3 :List(Fill);
+  tags @4 :List(Text);
+  union {
+    count @5 :Int32;
+    countFp @6 :Text;
+  }
+  meta :group {
+    source @7 :Text;
+  }
+
+  enum Side { yes @0; no @1; }
+  struct Fill { qty @0 :Float64; }
+}
+`;
+  assertEquals(fieldsFromCapnp(capnp), {
+    ticker: { type: "string" },
+    price: { type: "integer" },
+    side: { type: "string" },
+    "fills[].qty": { type: "number" },
+    tags: { type: "array" },
+    count: { type: "integer" },
+    countFp: { type: "string" },
+    "meta.source": { type: "string" },
+  });
+  assertThrows(() => fieldsFromCapnp(capnp, "Quote"), Error, "struct Quote not found");
+});
@@ -0,0 +1,114 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  diffEnvironments,
+  type Environment,
+  getDefaultConfig,
+  mergeEnvironment,
+  promoteEnvironment,
+  type RawEnvConfig,
+  resolveEnvConfig,
+} from "../../../src/lib/types/env-config.ts";
+
+const RAW: RawEnvConfig = {
+  "current-env": "dev",
+  environments: {
+    base: {
+      cluster: "homelab",
+      namespace: "ssmd",
+      nats: { url: "nats://nats.nats.svc.cluster.local:4222", stream_prefix: "PROD" },
+      storage: { type: "gcs", bucket: "ssmd-archives" },
+      secrets: { kalshi: "ssmd/kalshi-credentials" },
+    },
+    prod: { extends: "base" },
+    dev: {
+      extends: "prod",
+      namespace: "ssmd-dev",
+      nats: { stream_prefix: "DEV" },
+      storage: { type: "local", path: "/mnt/ssmd-dev" },
+      secrets: null,
+    },
+  },
+};
+
+Deno.test("resolveEnvConfig merges extends chains over their base", () => {
+  const config = resolveEnvConfig(RAW);
+  assertEquals(config.environments.prod, config.environments.base);
+  assertEquals(config.environments.dev, {
+    cluster: "homelab",
+    namespace: "ssmd-dev",
+    nats: { url: "nats://nats.nats.svc.cluster.local:4222", stream_prefix: "DEV" },
+    // A different storage type replaces the base storage instead of merging into it
+    storage: { type: "local", path: "/mnt/ssmd-dev" },
+  });
+});
+
+Deno.test("resolveEnvConfig rejects cycles, unknown bases and incomplete results", () => {
+  const cycle: RawEnvConfig = {
+    "current-env": "a",
+    environments: { a: { extends: "b" }, b: { extends: "c" }, c: { extends: "a" } },
+  };
+  assertThrows(() => resolveEnvConfig(cycle), Error, "a -> b -> c -> a");
+
+  const self: RawEnvConfig = { "current-env": "a", environments: { a: { extends: "a" } } };
+  assertThrows(() => resolveEnvConfig(self), Error, "cycle");
+
+  const unknown: RawEnvConfig = { "current-env": "a", environments: { a: { extends: "missing" } } };
+  assertThrows(() => resolveEnvConfig(unknown), Error, "'a' extends unknown environment 'missing'");
+
+  const incomplete: RawEnvConfig = { "current-env": "a", environments: { a: { cluster: "homelab" } } };
+  assertThrows(() => resolveEnvConfig(incomplete), Error, "Environment 'a'");
+});
+
+Deno.test("resolveEnvConfig leaves configs without extends unchanged", () => {
+  assertEquals(resolveEnvConfig(getDefaultConfig()), getDefaultConfig());
+});
+
+Deno.test("mergeEnvironment merges objects, replaces values and drops nulls", () => {
+  assertEquals(
+    mergeEnvironment({ a: 1, o: { x: 1, y: 2 }, l: [1, 2], gone: true }, { o: { y: 3 }, l: [3], gone: null }),
+    { a: 1, o: { x: 1, y: 3 }, l: [3] },
+  );
+});
+
+const DEV: Environment = {
+  cluster: "gke-ssmd-dev",
+  namespace: "ssmd-dev",
+  nats: { url: "nats://nats-dev:4222", stream_prefix: "DEV" },
+  storage: { type: "s3", bucket: "ssmd-dev-archives", region: "us-east-2" },
+};
+
+const PROD: Environment = {
+  cluster: "homelab",
+  namespace: "ssmd",
+  nats: { url: "nats://nats.nats.svc.cluster.local:4222", stream_prefix: "PROD" },
+  storage: { type: "s3", bucket: "ssmd-archives", region: "us-east-1" },
+  secrets: { kalshi: "ssmd/kalshi-credentials" },
+};
+
+Deno.test("promoteEnvironment copies the source but keeps target URLs, buckets and secrets", () => {
+  const promoted = promoteEnvironment(DEV, PROD);
+  assertEquals(promoted, { ...PROD, storage: { type: "s3", bucket: "ssmd-archives", region: "us-east-2" } });
+  assertEquals(diffEnvironments(PROD, promoted), [
+    { path: "storage.region", from: "us-east-1", to: "us-east-2" },
+  ]);
+
+  // Secrets the target lacks are not copied from the source
+  const noSecrets = promoteEnvironment(PROD, DEV);
+  assertEquals(noSecrets.secrets, undefined);
+  assertEquals(noSecrets.storage, { type: "s3", bucket: "ssmd-dev-archives", region: "us-east-1" });
+});
+
+Deno.test("promoteEnvironment rejects a storage type the target has no location for", () => {
+  const local: Environment = { ...DEV, storage: { type: "local", path: "/mnt/ssmd-dev" } };
+  assertThrows(() => promoteEnvironment(local, PROD), Error, "storage");
+});
+
+Deno.test("diffEnvironments reports added, removed and changed leaves", () => {
+  const after: Environment = { ...PROD, secrets: undefined, storage: { type: "gcs", bucket: "ssmd-archives" } };
+  assertEquals(diffEnvironments(PROD, after), [
+    { path: "secrets.kalshi", from: "ssmd/kalshi-credentials" },
+    { path: "storage.region", from: "us-east-1" },
+    { path: "storage.type", from: "s3", to: "gcs" },
+  ]);
+  assertEquals(diffEnvironments(PROD, { ...PROD, nats: { ...PROD.nats } }), []);
+});
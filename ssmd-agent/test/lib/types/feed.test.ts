@@ -1,8 +1,11 @@
 import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
 import {
+  type Calendar,
+  CalendarSchema,
   FeedSchema,
   getLatestVersion,
   getVersionForDate,
+  nextSession,
   type Feed,
 } from "../../../src/lib/types/feed.ts";
 
@@ -163,3 +166,72 @@ Deno.test("FeedSchema defaults status to active", () => {
   const result = FeedSchema.parse(feed);
   assertEquals(result.status, "active");
 });
+
+const NYSE: Calendar = {
+  timezone: "America/New_York",
+  open_time: "09:30",
+  close_time: "16:00",
+  weekdays: { sat: { closed: true }, sun: { closed: true } },
+  holidays: [{ date: "2026-11-26", name: "Thanksgiving" }],
+  half_days: [{ date: "2026-11-27", close_time: "13:00" }],
+};
+
+Deno.test("CalendarSchema rejects bad timezones, hours and duplicate dates", () => {
+  assertEquals(CalendarSchema.safeParse(NYSE).success, true);
+  assertEquals(CalendarSchema.safeParse({ timezone: "Mars/Olympus" }).success, false);
+  assertEquals(CalendarSchema.safeParse({ open_time: "9:30", close_time: "16:00" }).success, false);
+  assertEquals(CalendarSchema.safeParse({ open_time: "09:30" }).success, false);
+  assertEquals(CalendarSchema.safeParse({ weekdays: { sat: { closed: true, open_time: "10:00", close_time: "12:00" } } }).success, false);
+  assertEquals(CalendarSchema.safeParse({ weekdays: { someday: { closed: true } } }).success, false);
+  assertEquals(CalendarSchema.safeParse({
+    holidays: [{ date: "2026-12-25" }],
+    half_days: [{ date: "2026-12-25", close_time: "13:00" }],
+  }).success, false);
+});
+
+Deno.test("nextSession returns the session in progress", () => {
+  const s = nextSession(NYSE, new Date("2026-11-24T15:00:00Z"));
+  assertEquals(s?.date, "2026-11-24");
+  assertEquals(s?.open.toISOString(), "2026-11-24T14:30:00.000Z");
+  assertEquals(s?.close.toISOString(), "2026-11-24T21:00:00.000Z");
+});
+
+Deno.test("nextSession skips holidays and closes early on half days", () => {
+  // After the close on Wednesday; Thursday is Thanksgiving
+  const s = nextSession(NYSE, new Date("2026-11-25T22:00:00Z"));
+  assertEquals(s?.date, "2026-11-27");
+  assertEquals(s?.halfDay, true);
+  assertEquals(s?.close.toISOString(), "2026-11-27T18:00:00.000Z");
+
+  // Friday after the early close rolls to Monday
+  assertEquals(nextSession(NYSE, new Date("2026-11-27T19:00:00Z"))?.date, "2026-11-30");
+});
+
+Deno.test("nextSession follows DST and overnight sessions", () => {
+  // Summer open is 13:30 UTC
+  assertEquals(nextSession(NYSE, new Date("2026-07-01T12:00:00Z"))?.open.toISOString(), "2026-07-01T13:30:00.000Z");
+
+  // CME-style session: 18:00 to 17:00 the next day, Sunday through Thursday opens
+  const cme: Calendar = {
+    timezone: "America/Chicago",
+    open_time: "18:00",
+    close_time: "17:00",
+    weekdays: { fri: { closed: true }, sat: { closed: true } },
+  };
+  // Thursday's session ended Friday afternoon; Saturday waits for Sunday evening
+  const s = nextSession(cme, new Date("2026-01-17T15:00:00Z"));
+  assertEquals(s?.date, "2026-01-18");
+  assertEquals(s?.open.toISOString(), "2026-01-19T00:00:00.000Z");
+  // Tuesday 02:00 UTC is still in Monday's session
+  assertEquals(nextSession(cme, new Date("2026-01-20T02:00:00Z"))?.date, "2026-01-19");
+});
+
+Deno.test("nextSession without hours is a full day, null when always closed", () => {
+  const s = nextSession(undefined, new Date("2026-01-15T12:00:00Z"));
+  assertEquals(s?.open.toISOString(), "2026-01-15T00:00:00.000Z");
+  assertEquals(s?.close.toISOString(), "2026-01-16T00:00:00.000Z");
+
+  const closed = { closed: true };
+  const weekdays = { sun: closed, mon: closed, tue: closed, wed: closed, thu: closed, fri: closed, sat: closed };
+  assertEquals(nextSession({ weekdays }, new Date("2026-01-15T12:00:00Z"), 14), null);
+});
@@ -1,5 +1,11 @@
 import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
-import { MarketSchema, fromKalshiMarket, type KalshiMarket } from "../../../src/lib/types/market.ts";
+import {
+  fromKalshiMarket,
+  type KalshiMarket,
+  marketPrices,
+  marketPricesToJson,
+  MarketSchema,
+} from "../../../src/lib/types/market.ts";
 
 Deno.test("MarketSchema validates valid market", () => {
   const market = {
@@ -175,3 +181,20 @@ Deno.test("fromKalshiMarket maps status correctly", () => {
   });
   assertEquals(unknownMarket.status, "closed");
 });
+
+Deno.test("marketPrices types dollar prices as integer major_e4 units", () => {
+  const prices = marketPrices({ yes_bid: 0.45, yes_ask: "0.4700", no_bid: null, last_price: "0.4650" });
+
+  assertEquals(prices.yes_bid, { major_e4: 4500, currency: "USD" });
+  assertEquals(prices.yes_ask, { major_e4: 4700, currency: "USD" });
+  assertEquals(prices.no_bid, null);
+  assertEquals(prices.no_ask, null);
+  assertEquals(prices.last_price, { major_e4: 4650, currency: "USD" });
+});
+
+Deno.test("marketPricesToJson emits major_e4, currency and decimal per field", () => {
+  const json = marketPricesToJson(marketPrices({ yes_bid: "0.0700" }));
+
+  assertEquals(json.yes_bid, { major_e4: 700, currency: "USD", decimal: "0.0700" });
+  assertEquals(json.last_price, null);
+});
@@ -0,0 +1,105 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { applyBookMessage, type BookStore, topLevels } from "../../../src/lib/types/orderbook.ts";
+import { snapshotRows } from "../../../src/cli/commands/book-snapshot-consumer.ts";
+
+function kalshiSnapshot(ticker: string) {
+  return {
+    type: "orderbook_snapshot",
+    msg: { market_ticker: ticker, yes: [[50, 10], [54, 5]], no: [[40, 7], [44, 3]] },
+  };
+}
+
+Deno.test("applyBookMessage builds a Kalshi book with NO levels as asks", () => {
+  const store: BookStore = new Map();
+  const book = applyBookMessage(store, "kalshi", kalshiSnapshot("KXBTC-1"));
+  assertEquals(book?.ticker, "KXBTC-1");
+  assertEquals(topLevels(book!, 10), {
+    bids: [[0.54, 5], [0.5, 10]],
+    asks: [[0.56, 3], [0.6, 7]],
+  });
+});
+
+Deno.test("applyBookMessage applies Kalshi deltas and drops empty levels", () => {
+  const store: BookStore = new Map();
+  applyBookMessage(store, "kalshi", kalshiSnapshot("KXBTC-1"));
+  applyBookMessage(store, "kalshi", {
+    type: "orderbook_delta",
+    msg: { market_ticker: "KXBTC-1", side: "yes", price: 54, delta: -5, ts: 1767225600000 },
+  });
+  const book = applyBookMessage(store, "kalshi", {
+    type: "orderbook_delta",
+    msg: { market_ticker: "KXBTC-1", side: "no", price: 44, delta: 2 },
+  });
+  assertEquals(topLevels(book!, 1), { bids: [[0.5, 10]], asks: [[0.56, 5]] });
+});
+
+Deno.test("applyBookMessage ignores deltas before the snapshot", () => {
+  const store: BookStore = new Map();
+  const book = applyBookMessage(store, "kalshi", {
+    type: "orderbook_delta",
+    msg: { market_ticker: "KXBTC-1", side: "yes", price: 54, delta: 5 },
+  });
+  assertEquals(book, null);
+  assertEquals(store.size, 0);
+});
+
+Deno.test("applyBookMessage replaces Polymarket books", () => {
+  const store: BookStore = new Map();
+  applyBookMessage(store, "polymarket", {
+    event_type: "book",
+    asset_id: "123",
+    bids: [{ price: "0.40", size: "100" }],
+    asks: [{ price: "0.45", size: "50" }],
+  });
+  const book = applyBookMessage(store, "polymarket", {
+    event_type: "book",
+    asset_id: "123",
+    bids: [{ price: "0.41", size: "20" }],
+    asks: [],
+    timestamp: "1767225600000",
+  });
+  assertEquals(topLevels(book!, 10), { bids: [[0.41, 20]], asks: [] });
+  assertEquals(book!.exchangeTs, 1767225600000);
+});
+
+Deno.test("applyBookMessage applies Kraken Futures book updates", () => {
+  const store: BookStore = new Map();
+  applyBookMessage(store, "kraken-futures", {
+    feed: "book_snapshot",
+    product_id: "PF_XBTUSD",
+    bids: [{ price: 100000, qty: 1 }, { price: 99999, qty: 2 }],
+    asks: [{ price: 100001, qty: 3 }],
+  });
+  applyBookMessage(store, "kraken-futures", { feed: "book", product_id: "PF_XBTUSD", side: "buy", price: 100000, qty: 0 });
+  const book = applyBookMessage(store, "kraken-futures", {
+    feed: "book",
+    product_id: "PF_XBTUSD",
+    side: "sell",
+    price: 100002,
+    qty: 4,
+  });
+  assertEquals(topLevels(book!, 10), {
+    bids: [[99999, 2]],
+    asks: [[100001, 3], [100002, 4]],
+  });
+});
+
+Deno.test("applyBookMessage ignores unknown feeds and non-book messages", () => {
+  const store: BookStore = new Map();
+  assertEquals(applyBookMessage(store, "binance", kalshiSnapshot("KXBTC-1")), null);
+  assertEquals(applyBookMessage(store, "kalshi", { type: "trade", msg: { market_ticker: "KXBTC-1" } }), null);
+});
+
+Deno.test("snapshotRows skips books not updated since the last flush", () => {
+  const store: BookStore = new Map();
+  applyBookMessage(store, "kalshi", kalshiSnapshot("KXBTC-1"));
+  applyBookMessage(store, "kalshi", kalshiSnapshot("KXBTC-2"));
+  store.get("kalshi:KXBTC-1")!.updatedAt = 1000;
+
+  const rows = snapshotRows(store, 1, 2000);
+  assertEquals(rows.length, 1);
+  assertEquals(rows[0].ticker, "KXBTC-2");
+  assertEquals(rows[0].depth, 1);
+  assertEquals(rows[0].bids, [[0.54, 5]]);
+  assertEquals(rows[0].exchangeTs, null);
+});
@@ -0,0 +1,56 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  priceDecimal,
+  priceFromDecimal,
+  priceFromMinor,
+  priceMajor,
+  PriceSchema,
+  priceToJson,
+} from "../../../src/lib/types/price.ts";
+
+Deno.test("priceFromDecimal parses NUMERIC strings and dollar numbers", () => {
+  assertEquals(priceFromDecimal("0.5500"), { major_e4: 5500, currency: "USD" });
+  assertEquals(priceFromDecimal(0.29), { major_e4: 2900, currency: "USD" });
+  assertEquals(priceFromDecimal("1.0000"), { major_e4: 10000, currency: "USD" });
+});
+
+Deno.test("priceFromDecimal keeps sub-cent ticks as integer units", () => {
+  assertEquals(priceFromDecimal("0.4650"), { major_e4: 4650, currency: "USD" });
+  assertEquals(priceFromDecimal("0.0001"), { major_e4: 1, currency: "USD" });
+  assertEquals(Number.isInteger(priceFromDecimal(0.1 + 0.2)!.major_e4), true);
+  assertEquals(PriceSchema.safeParse(priceFromDecimal("0.5550")).success, true);
+});
+
+Deno.test("priceFromDecimal rounds past four decimal places", () => {
+  assertEquals(priceFromDecimal("0.46504"), { major_e4: 4650, currency: "USD" });
+  assertEquals(priceFromDecimal("0.46506"), { major_e4: 4651, currency: "USD" });
+});
+
+Deno.test("PriceSchema rejects fractional units", () => {
+  assertEquals(PriceSchema.safeParse({ major_e4: 46.5, currency: "USD" }).success, false);
+});
+
+Deno.test("priceFromDecimal treats empty and invalid values as null", () => {
+  assertEquals(priceFromDecimal(null), null);
+  assertEquals(priceFromDecimal(undefined), null);
+  assertEquals(priceFromDecimal(""), null);
+  assertEquals(priceFromDecimal("n/a"), null);
+});
+
+Deno.test("priceFromMinor takes integer cents", () => {
+  assertEquals(priceFromMinor(45), { major_e4: 4500, currency: "USD" });
+  assertEquals(priceFromMinor(null), null);
+  assertEquals(priceFromMinor(Number.NaN), null);
+});
+
+Deno.test("priceDecimal and priceMajor convert units to dollars", () => {
+  const price = priceFromMinor(7)!;
+  assertEquals(priceDecimal(price), "0.0700");
+  assertEquals(priceMajor(price), 0.07);
+  assertEquals(priceDecimal(priceFromDecimal("0.5550")!), "0.5550");
+});
+
+Deno.test("priceToJson emits integer units and the decimal form", () => {
+  assertEquals(priceToJson(priceFromDecimal("0.4650")), { major_e4: 4650, currency: "USD", decimal: "0.4650" });
+  assertEquals(priceToJson(null), null);
+});
@@ -0,0 +1,74 @@
+import { assertEquals, assertThrows } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import {
+  budgetCounterFromEnv,
+  MemoryBudgetCounter,
+  parseRateBudget,
+  RateBudget,
+  rateBudgetDegraded,
+} from "../../../src/lib/utils/rate-budget.ts";
+
+Deno.test("RateBudget counts down within a window", async () => {
+  const budget = new RateBudget({ key: "test", limit: 3, windowMs: 60_000, now: () => 1_000 });
+
+  assertEquals(await budget.acquire(), 2);
+  assertEquals(await budget.acquire(), 1);
+  assertEquals(await budget.acquire(), 0);
+});
+
+Deno.test("RateBudget waits for the next window once spent", async () => {
+  const clock = [950, 960, 1_000];
+  const budget = new RateBudget({ key: "test", limit: 1, windowMs: 100, now: () => clock.shift() ?? 1_000 });
+
+  await budget.acquire();
+  const start = Date.now();
+  assertEquals(await budget.acquire(), 0);
+  const elapsed = Date.now() - start;
+
+  // Slept the 40ms left in the spent window
+  assertEquals(elapsed >= 35, true, `Expected >= 35ms, got ${elapsed}ms`);
+});
+
+Deno.test("RateBudget shares a counter between clients", async () => {
+  const counter = new MemoryBudgetCounter();
+  const backfill = new RateBudget({ key: "kalshi", limit: 5, windowMs: 60_000, counter, now: () => 0 });
+  const secmaster = new RateBudget({ key: "kalshi", limit: 5, windowMs: 60_000, counter, now: () => 0 });
+
+  await backfill.acquire();
+  await backfill.acquire();
+  assertEquals(await secmaster.acquire(), 2);
+});
+
+Deno.test("RateBudget exhaust spends the rest of the window", async () => {
+  const counter = new MemoryBudgetCounter();
+  let now = 0;
+  const budget = new RateBudget({ key: "kalshi", limit: 5, windowMs: 100, counter, now: () => now });
+
+  await budget.exhaust();
+  now = 100;
+  assertEquals(await budget.acquire(), 4);
+});
+
+Deno.test("RateBudget rejects invalid limits", () => {
+  assertThrows(() => new RateBudget({ key: "test", limit: 0, windowMs: 1000 }), Error, "positive integer");
+  assertThrows(() => new RateBudget({ key: "test", limit: 1, windowMs: 0 }), Error, "window");
+});
+
+Deno.test("parseRateBudget reads requests per seconds", () => {
+  assertEquals(parseRateBudget("10/1"), { limit: 10, windowMs: 1000 });
+  assertEquals(parseRateBudget("600/60"), { limit: 600, windowMs: 60_000 });
+  assertEquals(parseRateBudget("5/0.5"), { limit: 5, windowMs: 500 });
+  assertThrows(() => parseRateBudget("10"), Error, "invalid rate budget");
+  assertThrows(() => parseRateBudget("0/1"), Error, "invalid rate budget");
+  assertThrows(() => parseRateBudget("10/0"), Error, "invalid rate budget");
+});
+
+Deno.test("budgetCounterFromEnv counts in-process and reports degraded when Redis is unreachable", async () => {
+  Deno.env.set("RATE_BUDGET_REDIS_URL", "redis://127.0.0.1:1");
+  try {
+    const counter = await budgetCounterFromEnv();
+    assertEquals(counter instanceof MemoryBudgetCounter, true);
+    assertEquals(rateBudgetDegraded.format().split("\n").pop(), "ssmd_rate_budget_degraded 1");
+  } finally {
+    Deno.env.delete("RATE_BUDGET_REDIS_URL");
+  }
+});
@@ -67,12 +67,15 @@ const AUTH_ENDPOINTS = [
   "GET /v1/data/catalog",
   "GET /v1/data/schemas",
   "GET /v1/data/trades?feed=kalshi",
+  "GET /v1/data/sample?feed=kalshi&ticker=A",
   "GET /v1/data/prices?feed=kalshi",
   "GET /v1/data/events?feed=kalshi",
   "GET /v1/data/volume",
   "GET /v1/data/freshness",
   "GET /v1/data/download?feed=kalshi&from=2026-01-01&to=2026-01-02",
   "GET /v1/data/day?date=2026-01-01",
+  "GET /v1/data/stream?feed=kalshi",
+  "GET /v1/data/snap/KXTEST?feed=kalshi",
   "GET /v1/markets/lookup?ids=TICKER1",
   "GET /v1/events",
   "GET /v1/markets",
@@ -82,6 +85,7 @@ const AUTH_ENDPOINTS = [
   "GET /v1/fees",
   "GET /v1/keys",
   "GET /v1/settings",
+  "GET /v1/admin/audit",
   "GET /v1/billing/summary?key_prefix=sk_test",
   "GET /v1/billing/report",
   "GET /v1/billing/export",
@@ -205,6 +209,63 @@ Deno.test("secmaster:read key cannot access datasets", async () => {
   assertEquals(res.status, 403);
 });
 
+// --- /v1/data/stream validation tests ---
+
+Deno.test("GET /v1/data/stream rejects unknown feed", async () => {
+  const router = createTestRouter();
+  const res = await router(makeReq("/v1/data/stream?feed=nasdaq"));
+  assertEquals(res.status, 400);
+  const body = await res.json();
+  assertExists(body.error);
+});
+
+Deno.test("GET /v1/data/stream rejects unauthorized feed", async () => {
+  const router = createTestRouter(mockAuth({ allowedFeeds: ["kalshi"] }));
+  const res = await router(makeReq("/v1/data/stream?feed=polymarket"));
+  assertEquals(res.status, 403);
+});
+
+Deno.test("GET /v1/data/stream rejects out-of-range max_rate", async () => {
+  const router = createTestRouter();
+  const res = await router(makeReq("/v1/data/stream?feed=kalshi&max_rate=0"));
+  assertEquals(res.status, 400);
+});
+
+// --- Per-route rate limit tests ---
+
+Deno.test("Per-route limit returns 429 with Retry-After", async () => {
+  const router = createRouter({
+    dataDir: "/tmp/test-data",
+    db: {} as Database,
+    harmanPools: new Map(),
+    authOverride: () => Promise.resolve(mockAuth()),
+    routeLimits: [{ route: "/v1/data/stream", perMinute: 1, burst: 1 }],
+  });
+  const first = await router(makeReq("/v1/data/stream?feed=nasdaq"));
+  assertEquals(first.status, 400);
+  await first.body?.cancel();
+
+  const second = await router(makeReq("/v1/data/stream?feed=nasdaq"));
+  assertEquals(second.status, 429);
+  assertEquals(second.headers.get("Retry-After"), "60");
+  const body = await second.json();
+  assertExists(body.error);
+});
+
+// --- /v1/admin/audit tests ---
+
+Deno.test("GET /v1/admin/audit requires admin:read", async () => {
+  const router = createTestRouter();
+  const res = await router(makeReq("/v1/admin/audit"));
+  assertEquals(res.status, 403);
+});
+
+Deno.test("GET /v1/admin/audit rejects bad limit and timestamps", async () => {
+  const router = createTestRouter(mockAuth({ scopes: ["admin:read"] }));
+  assertEquals((await router(makeReq("/v1/admin/audit?limit=5000"))).status, 400);
+  assertEquals((await router(makeReq("/v1/admin/audit?since=yesterday"))).status, 400);
+});
+
 // --- /v1/data/day validation tests ---
 
 Deno.test("GET /v1/data/day requires date param", async () => {
@@ -492,6 +553,30 @@ Deno.test("GET /v1/data/trades with invalid feed returns 400", async () => {
   assertExists(body.error);
 });
 
+Deno.test("GET /v1/data/sample without ticker returns 400", async () => {
+  const router = createTestRouter(mockAuth({ scopes: ["datasets:read"] }));
+  const res = await router(makeReq("/v1/data/sample?feed=kalshi&date=2026-01-05"));
+  assertEquals(res.status, 400);
+  const body = await res.json();
+  assertExists(body.error);
+});
+
+Deno.test("GET /v1/data/sample without a date requires from and to", async () => {
+  const router = createTestRouter(mockAuth({ scopes: ["datasets:read"] }));
+  const res = await router(makeReq("/v1/data/sample?feed=kalshi&ticker=A&from=2026-01-05"));
+  assertEquals(res.status, 400);
+  const body = await res.json();
+  assertEquals(body.error, "from and to are both required without a date");
+});
+
+Deno.test("GET /v1/data/sample with to before from returns 400", async () => {
+  const router = createTestRouter(mockAuth({ scopes: ["datasets:read"] }));
+  const res = await router(makeReq("/v1/data/sample?feed=kalshi&ticker=A&from=2026-01-06&to=2026-01-05"));
+  assertEquals(res.status, 400);
+  const body = await res.json();
+  assertExists(body.error);
+});
+
 Deno.test("GET /v1/data/prices without feed returns 400", async () => {
   const router = createTestRouter(mockAuth({ scopes: ["datasets:read"] }));
   const req = makeReq("/v1/data/prices");
@@ -519,6 +604,48 @@ Deno.test("GET /v1/markets/lookup without ids returns 400", async () => {
   assertExists(body.error);
 });
 
+Deno.test("GET /v1/markets with unknown sort returns 400", async () => {
+  const router = createTestRouter(mockAuth({ scopes: ["secmaster:read"] }));
+  const req = makeReq("/v1/markets?sort=ticker");
+  const res = await router(req);
+  assertEquals(res.status, 400);
+  const body = await res.json();
+  assertExists(body.error);
+});
+
+Deno.test("GET /v1/markets with malformed cursor returns 400", async () => {
+  const router = createTestRouter(mockAuth({ scopes: ["secmaster:read"] }));
+  const req = makeReq("/v1/markets?cursor=not-a-cursor");
+  const res = await router(req);
+  assertEquals(res.status, 400);
+  const body = await res.json();
+  assertEquals(body.error, "Invalid cursor");
+});
+
+Deno.test("GET /v1/markets with negative min_volume returns 400", async () => {
+  const router = createTestRouter(mockAuth({ scopes: ["secmaster:read"] }));
+  const res = await router(makeReq("/v1/markets?min_volume=-1"));
+  assertEquals(res.status, 400);
+  const body = await res.json();
+  assertEquals(body.error, "min_volume must be a non-negative integer");
+});
+
+Deno.test("GET /v1/markets with feed=kraken rejects Kalshi-only filters", async () => {
+  const router = createTestRouter(mockAuth({ scopes: ["secmaster:read"] }));
+  const res = await router(makeReq("/v1/markets?feed=kraken&min_volume=10&include_snapshot=true"));
+  assertEquals(res.status, 400);
+  const body = await res.json();
+  assertEquals(body.error, "Not supported with feed=kraken: min_volume, include_snapshot");
+});
+
+Deno.test("GET /v1/markets with feed=kraken-futures rejects sort other than updated_at", async () => {
+  const router = createTestRouter(mockAuth({ scopes: ["secmaster:read"] }));
+  const res = await router(makeReq("/v1/markets?feed=kraken-futures&sort=volume"));
+  assertEquals(res.status, 400);
+  const body = await res.json();
+  assertEquals(body.error, "Invalid sort for feed=kraken-futures: volume. Valid sorts: updated_at");
+});
+
 Deno.test("GET /v1/billing/summary without key_prefix returns 400", async () => {
   const router = createTestRouter(mockAuth({ scopes: ["admin:read"] }));
   const req = makeReq("/v1/billing/summary");
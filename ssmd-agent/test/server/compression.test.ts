@@ -0,0 +1,67 @@
+import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import { zstdDecompressSync } from "node:zlib";
+import { compression, negotiateEncoding } from "../../src/server/middleware.ts";
+
+const BODY = JSON.stringify({ rows: Array.from({ length: 500 }, (_, i) => ({ i, ticker: "KXBTC-1" })) });
+
+function handler(headers: Record<string, string> = { "Content-Type": "application/json" }) {
+  return compression(() => Promise.resolve(new Response(BODY, { headers })));
+}
+
+function get(acceptEncoding?: string): Request {
+  return new Request("http://localhost/v1/data/trades", {
+    headers: acceptEncoding ? { "Accept-Encoding": acceptEncoding } : {},
+  });
+}
+
+Deno.test("negotiateEncoding prefers zstd, then br, then gzip, and honours q-values", () => {
+  assertEquals(negotiateEncoding("gzip, zstd"), "zstd");
+  assertEquals(negotiateEncoding("gzip, br"), "br");
+  assertEquals(negotiateEncoding("zstd;q=0.5, gzip"), "gzip");
+  assertEquals(negotiateEncoding("*"), "zstd");
+  assertEquals(negotiateEncoding("*, zstd;q=0"), "br");
+  assertEquals(negotiateEncoding("identity"), null);
+  assertEquals(negotiateEncoding(null), null);
+});
+
+Deno.test("compression zstd-encodes JSON when the client prefers zstd", async () => {
+  const res = await handler()(get("zstd, gzip"));
+  assertEquals(res.headers.get("Content-Encoding"), "zstd");
+  assertEquals(res.headers.get("Vary"), "Accept-Encoding");
+  assertEquals(res.headers.get("Content-Length"), null);
+  const decoded = zstdDecompressSync(new Uint8Array(await res.arrayBuffer()));
+  assertEquals(new TextDecoder().decode(decoded), BODY);
+});
+
+Deno.test("compression leaves gzip to Deno.serve", async () => {
+  // Deno.serve gzips responses without a Content-Encoding itself
+  for (const accept of ["gzip", "zstd;q=0.5, gzip"]) {
+    const res = await handler()(get(accept));
+    assertEquals(res.headers.get("Content-Encoding"), null);
+    assertEquals(await res.text(), BODY);
+  }
+});
+
+Deno.test("compression leaves the body as-is without Accept-Encoding", async () => {
+  const res = await handler()(get());
+  assertEquals(res.headers.get("Content-Encoding"), null);
+  assertEquals(await res.text(), BODY);
+});
+
+Deno.test("compression passes already-encoded responses through for gzip and zstd clients", async () => {
+  for (const accept of ["gzip", "zstd"]) {
+    const res = await handler({ "Content-Type": "application/jsonl", "Content-Encoding": "gzip" })(get(accept));
+    assertEquals(res.headers.get("Content-Encoding"), "gzip");
+    assertEquals(await res.text(), BODY);
+  }
+});
+
+Deno.test("compression skips event streams and small bodies", async () => {
+  const sse = await handler({ "Content-Type": "text/event-stream" })(get("zstd"));
+  assertEquals(sse.headers.get("Content-Encoding"), null);
+  await sse.body?.cancel();
+
+  const small = await handler({ "Content-Type": "application/json", "Content-Length": "12" })(get("zstd"));
+  assertEquals(small.headers.get("Content-Encoding"), null);
+  await small.body?.cancel();
+});
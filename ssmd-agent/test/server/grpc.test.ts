@@ -0,0 +1,130 @@
+import { assertEquals, assertRejects } from "https://deno.land/std@0.224.0/assert/mod.ts";
+import * as grpc from "@grpc/grpc-js";
+import * as protoLoader from "@grpc/proto-loader";
+import { fromFileUrl } from "https://deno.land/std@0.224.0/path/mod.ts";
+import { createGrpcServer, listOptionsFromRequest, marketToMessage } from "../../src/server/grpc.ts";
+import type { Database } from "../../src/lib/db/mod.ts";
+import type { MarketRow } from "../../src/lib/db/markets.ts";
+
+const PROTO_PATH = fromFileUrl(new URL("../../src/server/proto/secmaster.proto", import.meta.url));
+
+const row: MarketRow = {
+  ticker: "INXD-25JAN01-B5000",
+  eventTicker: "INXD-25JAN01",
+  title: "S&P 500 above 5000?",
+  status: "open",
+  closeTime: new Date("2025-01-01T21:00:00Z"),
+  yesBid: "0.4500",
+  yesAsk: "0.4700",
+  noBid: null,
+  noAsk: null,
+  lastPrice: "0.4600",
+  volume: 1200,
+  volume24h: null,
+  openInterest: 300,
+  floorStrike: "5000",
+  capStrike: null,
+  strikeType: "greater",
+  result: null,
+  expirationValue: null,
+  yesSubTitle: null,
+  noSubTitle: null,
+  canCloseEarly: true,
+  marketType: "binary",
+  openTime: null,
+  expectedExpirationTime: null,
+  createdAt: new Date("2024-12-01T00:00:00Z"),
+  updatedAt: new Date("2024-12-31T12:00:00Z"),
+  deletedAt: null,
+};
+
+Deno.test("marketToMessage converts decimals and leaves unset fields unset", () => {
+  const m = marketToMessage(row);
+  assertEquals(m.yes_bid, 0.45);
+  assertEquals(m.last_price, 0.46);
+  assertEquals(m.no_bid, undefined);
+  assertEquals(m.volume, 1200);
+  assertEquals(m.volume_24h, undefined);
+  assertEquals(m.close_time, "2025-01-01T21:00:00.000Z");
+  assertEquals(m.open_time, "");
+  assertEquals(m.result, "");
+  assertEquals(m.deleted_at, "");
+});
+
+Deno.test("listOptionsFromRequest treats proto3 defaults as unset", () => {
+  assertEquals(
+    listOptionsFromRequest({
+      category: "Economics",
+      status: "",
+      series: "",
+      event_ticker: "",
+      closing_before: "",
+      closing_after: "",
+      as_of: "",
+      games_only: false,
+      limit: 0,
+    }),
+    {
+      category: "Economics",
+      status: undefined,
+      series: undefined,
+      eventTicker: undefined,
+      closingBefore: undefined,
+      closingAfter: undefined,
+      asOf: undefined,
+      gamesOnly: false,
+      limit: undefined,
+    },
+  );
+});
+
+function client(port: number) {
+  const pkg = grpc.loadPackageDefinition(protoLoader.loadSync(PROTO_PATH, { keepCase: true }));
+  const v1 = ((pkg.ssmd as grpc.GrpcObject).secmaster as grpc.GrpcObject).v1 as grpc.GrpcObject;
+  const Secmaster = v1.Secmaster as grpc.ServiceClientConstructor;
+  return new Secmaster(`localhost:${port}`, grpc.credentials.createInsecure());
+}
+
+function getMarket(c: grpc.Client, metadata = new grpc.Metadata()): Promise<unknown> {
+  return new Promise((resolve, reject) => {
+    // deno-lint-ignore no-explicit-any
+    (c as any).GetMarket({ ticker: "INXD" }, metadata, (err: grpc.ServiceError | null, res: unknown) => {
+      if (err) reject(err);
+      else resolve(res);
+    });
+  });
+}
+
+Deno.test({
+  name: "gRPC calls require an API key with secmaster:read",
+  sanitizeOps: false,
+  sanitizeResources: false,
+  fn: async () => {
+    const server = createGrpcServer({
+      port: 0,
+      db: {} as Database,
+      authOverride: (key) =>
+        Promise.resolve(
+          key === "sk_live_reader"
+            ? { valid: true, scopes: ["secmaster:read"] }
+            : key
+            ? { valid: true, scopes: ["datasets:read"] }
+            : { valid: false, status: 401, error: "Missing API key" },
+        ),
+    });
+    const c = client(await server.ready);
+    try {
+      const missing = await assertRejects(() => getMarket(c)) as grpc.ServiceError;
+      assertEquals(missing.code, grpc.status.UNAUTHENTICATED);
+      assertEquals(missing.details, "Missing API key");
+
+      const md = new grpc.Metadata();
+      md.set("authorization", "Bearer sk_live_other");
+      const denied = await assertRejects(() => getMarket(c, md)) as grpc.ServiceError;
+      assertEquals(denied.code, grpc.status.PERMISSION_DENIED);
+    } finally {
+      c.close();
+      server.shutdown();
+    }
+  },
+});
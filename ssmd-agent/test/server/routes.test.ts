@@ -130,6 +130,29 @@ Deno.test("PUT /v1/settings/:key returns 401 without API key", async () => {
   assertEquals(res.status, 401);
 });
 
+Deno.test("GET /v1/categories returns 401 without API key", async () => {
+  const router = createTestRouter();
+  const res = await router(new Request("http://localhost/v1/categories"));
+  assertEquals(res.status, 401);
+});
+
+Deno.test("POST /datasets/:feed/:date/verify returns 401 without API key", async () => {
+  const router = createTestRouter();
+  const res = await router(new Request("http://localhost/datasets/kalshi/2026-01-05/verify", { method: "POST" }));
+  assertEquals(res.status, 401);
+});
+
+Deno.test("PUT /v1/fees/:series returns 401 without API key", async () => {
+  const router = createTestRouter();
+  const req = new Request("http://localhost/v1/fees/KXBTC", {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ fee_type: "quadratic", fee_multiplier: 1.0 }),
+  });
+  const res = await router(req);
+  assertEquals(res.status, 401);
+});
+
 Deno.test("POST /v1/chat/completions returns 401 without API key", async () => {
   const router = createTestRouter();
   const req = new Request("http://localhost/v1/chat/completions", {
@@ -410,6 +433,72 @@ Deno.test("GET /v1/data/ohlcv/1m/symbols returns 401 without API key", async ()
   assertEquals(res.status, 401);
 });
 
+// --- GET /v1/data/snap/:ticker (single snap lookup via Redis) ---
+
+Deno.test("GET /v1/data/snap/:ticker returns the snapshot with Kalshi prices in dollars", async () => {
+  const snapAt = Date.now() - 1_500;
+  const router = createBarCacheRouter(["kalshi"], {
+    "snap:kalshi:KXBTCD-26JAN05-T98000": JSON.stringify({
+      type: "ticker",
+      yes_bid: 41,
+      yes_ask: 43,
+      _snap_at: snapAt,
+      msg: { market_ticker: "KXBTCD-26JAN05-T98000", yes_bid: 41 },
+    }),
+  });
+
+  const res = await router(new Request(
+    "http://localhost/v1/data/snap/KXBTCD-26JAN05-T98000?feed=kalshi",
+    { headers: { "X-API-Key": "test_pref.secret" } },
+  ));
+
+  assertEquals(res.status, 200);
+  const body = await res.json();
+  assertEquals(body.feed, "kalshi");
+  assertEquals(body.ticker, "KXBTCD-26JAN05-T98000");
+  assertEquals(body.snapshot.yes_bid, 0.41);
+  assertEquals(body.snapshot.yes_ask, 0.43);
+  assertEquals(body.snapshot._ticker, "KXBTCD-26JAN05-T98000");
+  assertEquals(body.snap_at, new Date(snapAt).toISOString());
+  assertEquals(body.age_ms >= 1_500, true);
+});
+
+Deno.test("GET /v1/data/snap/:ticker decodes tickers containing a slash", async () => {
+  const router = createBarCacheRouter(["kraken-spot"], {
+    "snap:kraken-spot:BTC/USD": JSON.stringify({ symbol: "BTC/USD", bid: 97000.1 }),
+  });
+
+  const res = await router(new Request(
+    "http://localhost/v1/data/snap/BTC%2FUSD?feed=kraken-spot",
+    { headers: { "X-API-Key": "test_pref.secret" } },
+  ));
+
+  assertEquals(res.status, 200);
+  const body = await res.json();
+  assertEquals(body.snapshot.bid, 97000.1);
+  assertEquals(body.snap_at, null);
+});
+
+Deno.test("GET /v1/data/snap/:ticker returns 404 when snap has no key", async () => {
+  const router = createBarCacheRouter(["kalshi"], {});
+  const res = await router(new Request(
+    "http://localhost/v1/data/snap/KXMISSING?feed=kalshi",
+    { headers: { "X-API-Key": "test_pref.secret" } },
+  ));
+  assertEquals(res.status, 404);
+  await res.json();
+});
+
+Deno.test("GET /v1/data/snap/:ticker returns 403 when feed is not authorized", async () => {
+  const router = createBarCacheRouter(["kalshi"], {});
+  const res = await router(new Request(
+    "http://localhost/v1/data/snap/PF_XBTUSD?feed=kraken-futures",
+    { headers: { "X-API-Key": "test_pref.secret" } },
+  ));
+  assertEquals(res.status, 403);
+  await res.json();
+});
+
 // --- GET /v1/internal/ohlcv-rest-bars (external REST OHLCV normalizer) ---
 
 // Build a router whose auth always succeeds with admin:read scope. The route
@@ -623,3 +712,70 @@ Deno.test("GET /v1/internal/ohlcv-rest-bars returns 400 for non-integer limit",
   const body = await res.json();
   assertEquals(typeof body.error === "string" && body.error.includes("limit"), true);
 });
+
+// --- /datasets/:feed/:date/files (raw file listing and download) ---
+
+function createDatasetFilesRouter(allowedFeeds: string[], scopes = ["datasets:read"]) {
+  const ctx: RouteContext = {
+    dataDir: "/tmp/test-data",
+    db: mockDb,
+    harmanPools: new Map(),
+    authOverride: () =>
+      Promise.resolve({
+        valid: true,
+        userId: "u1",
+        userEmail: "test@example.com",
+        scopes,
+        keyPrefix: "test_pref",
+        allowedFeeds,
+        dateRangeStart: "2026-01-01",
+        dateRangeEnd: "2026-06-30",
+        billable: false,
+      }),
+  };
+  return createRouter(ctx);
+}
+
+function datasetFilesRequest(path: string) {
+  return new Request(`http://localhost${path}`, { headers: { "X-API-Key": "test_pref.secret" } });
+}
+
+Deno.test("GET /datasets/:feed/:date/files returns 401 without API key", async () => {
+  const router = createTestRouter();
+  const res = await router(new Request("http://localhost/datasets/kalshi/2026-02-15/files"));
+  assertEquals(res.status, 401);
+  await res.body?.cancel();
+});
+
+Deno.test("GET /datasets/:feed/:date/files rejects unknown feeds and bad dates", async () => {
+  const router = createDatasetFilesRouter(["*"]);
+
+  const badFeed = await router(datasetFilesRequest("/datasets/nope/2026-02-15/files"));
+  assertEquals(badFeed.status, 400);
+  await badFeed.body?.cancel();
+
+  const badDate = await router(datasetFilesRequest("/datasets/kalshi/20260215/files/ticker_0000.parquet"));
+  assertEquals(badDate.status, 400);
+  await badDate.body?.cancel();
+});
+
+Deno.test("GET /datasets/:feed/:date/files enforces the key's feeds and date range", async () => {
+  const router = createDatasetFilesRouter(["kalshi"]);
+
+  const otherFeed = await router(datasetFilesRequest("/datasets/binance/2026-02-15/files"));
+  assertEquals(otherFeed.status, 403);
+  await otherFeed.body?.cancel();
+
+  const outOfRange = await router(datasetFilesRequest("/datasets/kalshi/2026-07-01/files/ticker_0000.parquet"));
+  assertEquals(outOfRange.status, 403);
+  const body = await outOfRange.json();
+  assertEquals(body.error, "Date 2026-07-01 is outside key range 2026-01-01 to 2026-06-30");
+});
+
+Deno.test("GET /datasets/:feed/:date/files rejects an unknown kind", async () => {
+  const router = createDatasetFilesRouter(["*"]);
+  const res = await router(datasetFilesRequest("/datasets/kalshi/2026-02-15/files?kind=jsonl"));
+  assertEquals(res.status, 400);
+  const body = await res.json();
+  assertEquals(body.error, "Invalid kind: jsonl. Valid kinds: parquet, raw, all");
+});
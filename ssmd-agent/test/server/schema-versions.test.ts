@@ -1,6 +1,13 @@
 import { assertEquals } from "https://deno.land/std@0.224.0/assert/mod.ts";
 import versions from "../../src/server/schema-versions.json" with { type: "json" };
+import { dedupKeys } from "../../src/server/schema-versions.ts";
 
 Deno.test("binance trade schema version mirrors the Rust 1.1.0 bump", () => {
   assertEquals(versions.binance.trade.version, "1.1.0");
 });
+
+Deno.test("dedupKeys lists only the types that declare a dedup_key", () => {
+  assertEquals(dedupKeys("kalshi"), { trade: ["msg.market_ticker", "msg.trade_id"] });
+  assertEquals(dedupKeys("binance"), { trade: ["data.s", "data.t"] });
+  assertEquals(dedupKeys("polymarket"), {});
+});
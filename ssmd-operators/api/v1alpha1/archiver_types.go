@@ -45,6 +45,15 @@ type ArchiverSpec struct {
 	// +optional
 	Source *ArchiverSourceConfig `json:"source,omitempty"`
 
+	// ProvisionStream makes the operator create or update the source streams
+	// and durable consumers before starting the archiver
+	// +optional
+	ProvisionStream bool `json:"provisionStream,omitempty"`
+
+	// Stream configures the JetStream streams provisioned when provisionStream is set
+	// +optional
+	Stream *StreamProvisionConfig `json:"stream,omitempty"`
+
 	// Storage configures local and remote storage
 	// +optional
 	Storage *StorageConfig `json:"storage,omitempty"`
@@ -53,6 +62,12 @@ type ArchiverSpec struct {
 	// +optional
 	Rotation *RotationConfig `json:"rotation,omitempty"`
 
+	// DedupKeys lists, per message type, the field paths that identify one
+	// message (the schema registry's dedup_key). Rendered into the archiver
+	// config and recorded in each day's manifest.
+	// +optional
+	DedupKeys map[string][]string `json:"dedupKeys,omitempty"`
+
 	// Sync configures remote sync settings
 	// +optional
 	Sync *SyncConfig `json:"sync,omitempty"`
@@ -61,6 +76,16 @@ type ArchiverSpec struct {
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 
+	// PriorityClassName is the PriorityClass for the archiver pod.
+	// Defaults to the operator's --archiver-priority-class.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PodTemplate configures image pull secrets, scheduling and annotations for the archiver pod.
+	// podTemplate.serviceAccountName takes precedence over serviceAccountName.
+	// +optional
+	PodTemplate *PodTemplateConfig `json:"podTemplate,omitempty"`
+
 	// Format specifies the output format (only "jsonl" is supported; parquet is generated offline)
 	// +kubebuilder:validation:Enum=jsonl
 	// +kubebuilder:default=jsonl
@@ -149,6 +174,13 @@ type LocalStorageConfig struct {
 	// StorageClass is the storage class to use for PVC creation
 	// +optional
 	StorageClass string `json:"storageClass,omitempty"`
+
+	// FillingThresholdPercent sets the StorageFilling condition once PVC usage reaches it
+	// +kubebuilder:default=80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	FillingThresholdPercent *int32 `json:"fillingThresholdPercent,omitempty"`
 }
 
 // RemoteStorageConfig defines remote storage settings
@@ -166,9 +198,20 @@ type RemoteStorageConfig struct {
 	// +optional
 	Prefix string `json:"prefix,omitempty"`
 
-	// SecretRef references the credentials secret
+	// SecretRef references the credentials secret: a GCS service account key
+	// under key.json, or AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY for s3
 	// +optional
 	SecretRef string `json:"secretRef,omitempty"`
+
+	// Endpoint overrides the S3 endpoint for S3-compatible stores such as MinIO
+	// (e.g. http://minio.minio:9000); path-style addressing is used when set
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Region is the S3 region
+	// +kubebuilder:default="us-east-1"
+	// +optional
+	Region string `json:"region,omitempty"`
 }
 
 // RotationConfig defines file rotation settings
@@ -198,6 +241,23 @@ type SyncConfig struct {
 	// +kubebuilder:default="final"
 	// +optional
 	OnDelete string `json:"onDelete,omitempty"`
+
+	// Image is the ssmd-sync image used by sync Jobs
+	// (defaults to ghcr.io/aaronwald/ssmd-sync:latest)
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// MaxAttempts is the number of upload attempts per object before the sync fails
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxAttempts *int32 `json:"maxAttempts,omitempty"`
+
+	// FinalSyncTimeout is how long deletion waits for the final sync Job before
+	// removing the finalizer anyway, measured from when the Archiver was deleted
+	// +kubebuilder:default="30m"
+	// +optional
+	FinalSyncTimeout *metav1.Duration `json:"finalSyncTimeout,omitempty"`
 }
 
 // ArchiverPhase represents the current phase of the Archiver
@@ -255,6 +315,11 @@ type ArchiverStatus struct {
 	// +optional
 	DuplicatesFiltered int64 `json:"duplicatesFiltered,omitempty"`
 
+	// StreamLag is the number of stream messages not yet delivered to the
+	// archiver's consumers (reported when provisionStream is set)
+	// +optional
+	StreamLag int64 `json:"streamLag,omitempty"`
+
 	// Conditions represent the current state of the Archiver
 	// +listType=map
 	// +listMapKey=type
@@ -268,6 +333,7 @@ type ArchiverStatus struct {
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
 // +kubebuilder:printcolumn:name="Archived",type="integer",JSONPath=".status.messagesArchived"
 // +kubebuilder:printcolumn:name="Bytes",type="integer",JSONPath=".status.bytesWritten"
+// +kubebuilder:printcolumn:name="Lag",type="integer",JSONPath=".status.streamLag",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Archiver is the Schema for the archivers API
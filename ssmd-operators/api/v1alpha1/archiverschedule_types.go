@@ -0,0 +1,179 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArchiverScheduleSpec defines the desired state of ArchiverSchedule
+type ArchiverScheduleSpec struct {
+	// Feed is the feed whose calendar timezone defines the trading day
+	// (read from the feed-<feed> ConfigMap)
+	// +kubebuilder:validation:Required
+	Feed string `json:"feed"`
+
+	// Timezone overrides the feed calendar timezone (IANA name, e.g. "America/New_York").
+	// Defaults to the feed calendar timezone, else UTC.
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
+	// RolloverTime is the local time (HH:MM) at which the next day's Archiver is created
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +kubebuilder:default="23:30"
+	// +optional
+	RolloverTime string `json:"rolloverTime,omitempty"`
+
+	// DeleteAfter is how long after its day ends a day's Archiver is deleted,
+	// which runs its final sync (e.g. "1h")
+	// +kubebuilder:default="1h"
+	// +optional
+	DeleteAfter *metav1.Duration `json:"deleteAfter,omitempty"`
+
+	// Template is the spec of each day's Archiver. "{date}" (YYYY-MM-DD) in any
+	// string field, e.g. consumer names or the remote prefix, is replaced with the day.
+	// +kubebuilder:validation:Required
+	Template ArchiverSpec `json:"template"`
+
+	// Compaction runs a daily CronJob that compacts the previous day's JSONL
+	// archive into ticker-partitioned parquet ("ssmd data compact")
+	// +optional
+	Compaction *CompactionConfig `json:"compaction,omitempty"`
+
+	// SecmasterArchive runs a daily CronJob that snapshots the secmaster
+	// events and markets tables as parquet to the archive bucket
+	// +optional
+	SecmasterArchive *SecmasterArchiveConfig `json:"secmasterArchive,omitempty"`
+}
+
+// CompactionConfig defines the daily archive compaction CronJob
+type CompactionConfig struct {
+	// Enabled enables the compaction CronJob
+	// +kubebuilder:default=true
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Schedule is the cron schedule, evaluated in the trading day timezone.
+	// It should fall after the previous day's Archiver has been deleted and
+	// made its final sync (see deleteAfter).
+	// +kubebuilder:default="30 2 * * *"
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Image is the ssmd CLI image running "data compact"
+	// (defaults to ghcr.io/aaronwald/ssmd-data-ts:latest)
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Bucket is the archive bucket (defaults to the template's remote storage bucket)
+	// +optional
+	Bucket string `json:"bucket,omitempty"`
+
+	// DeleteOriginals deletes the day's JSONL.gz files once the parquet is
+	// uploaded and recorded in the manifest
+	// +optional
+	DeleteOriginals bool `json:"deleteOriginals,omitempty"`
+}
+
+// SecmasterArchiveConfig defines the daily secmaster snapshot CronJob
+type SecmasterArchiveConfig struct {
+	// Enabled enables the snapshot CronJob
+	// +kubebuilder:default=true
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Schedule is the cron schedule, evaluated in the trading day timezone
+	// +kubebuilder:default="15 0 * * *"
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Image is the ssmd CLI image running "secmaster archive"
+	// (defaults to ghcr.io/aaronwald/ssmd-data-ts:latest)
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Bucket is the archive bucket (defaults to the template's remote storage bucket)
+	// +optional
+	Bucket string `json:"bucket,omitempty"`
+
+	// DatabaseSecretRef references the secret containing database-url
+	// +kubebuilder:validation:Required
+	DatabaseSecretRef corev1.LocalObjectReference `json:"databaseSecretRef"`
+}
+
+// ArchiverScheduleStatus defines the observed state of ArchiverSchedule
+type ArchiverScheduleStatus struct {
+	// Timezone is the resolved timezone of the trading day
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
+	// CurrentDate is the current trading day (YYYY-MM-DD)
+	// +optional
+	CurrentDate string `json:"currentDate,omitempty"`
+
+	// Archivers are the names of the Archivers managed by this schedule
+	// +optional
+	Archivers []string `json:"archivers,omitempty"`
+
+	// LastRolloverAt is when the schedule last created an Archiver
+	// +optional
+	LastRolloverAt *metav1.Time `json:"lastRolloverAt,omitempty"`
+
+	// Conditions represent the current state of the ArchiverSchedule
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Feed",type="string",JSONPath=".spec.feed"
+// +kubebuilder:printcolumn:name="Timezone",type="string",JSONPath=".status.timezone"
+// +kubebuilder:printcolumn:name="Date",type="string",JSONPath=".status.currentDate"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ArchiverSchedule is the Schema for the archiverschedules API
+type ArchiverSchedule struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of ArchiverSchedule
+	// +required
+	Spec ArchiverScheduleSpec `json:"spec"`
+
+	// status defines the observed state of ArchiverSchedule
+	// +optional
+	Status ArchiverScheduleStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ArchiverScheduleList contains a list of ArchiverSchedule
+type ArchiverScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []ArchiverSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ArchiverSchedule{}, &ArchiverScheduleList{})
+}
@@ -27,6 +27,11 @@ type ConnectorSpec struct {
 	// +kubebuilder:validation:Required
 	Feed string `json:"feed"`
 
+	// Exchange selects per-exchange defaults (endpoint, stream, credential env names)
+	// used when the feed ConfigMap or CR spec does not set them
+	// +optional
+	Exchange ExchangeType `json:"exchange,omitempty"`
+
 	// Date is the trading date (optional, used for historical backfill)
 	// +optional
 	Date string `json:"date,omitempty"`
@@ -59,6 +64,10 @@ type ConnectorSpec struct {
 	// +optional
 	ExcludeCategories []string `json:"excludeCategories,omitempty"`
 
+	// Subscriptions lists explicit tickers/channels to subscribe to
+	// +optional
+	Subscriptions *SubscriptionsConfig `json:"subscriptions,omitempty"`
+
 	// Transport configures the NATS connection
 	// +optional
 	Transport *TransportConfig `json:"transport,omitempty"`
@@ -71,10 +80,39 @@ type ConnectorSpec struct {
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 
+	// PriorityClassName is the PriorityClass for the connector pod.
+	// Defaults to the operator's --connector-priority-class.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PodTemplate configures image pull secrets, scheduling and annotations for the connector pod
+	// +optional
+	PodTemplate *PodTemplateConfig `json:"podTemplate,omitempty"`
+
+	// HighAvailability adds a PodDisruptionBudget and topology spread constraints for the connector pods
+	// +optional
+	HighAvailability *HighAvailabilityConfig `json:"highAvailability,omitempty"`
+
+	// ProvisionStream makes the operator create or update the transport's
+	// JetStream stream before starting the connector
+	// +optional
+	ProvisionStream bool `json:"provisionStream,omitempty"`
+
+	// Stream configures the JetStream stream provisioned when provisionStream is set
+	// +optional
+	Stream *StreamProvisionConfig `json:"stream,omitempty"`
+
 	// Cdc configures CDC-driven dynamic market subscriptions
 	// +optional
 	Cdc *CdcConfig `json:"cdc,omitempty"`
 
+	// DegradedAfterMinutes sets the Degraded condition when no message has
+	// arrived for this many minutes
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DegradedAfterMinutes *int32 `json:"degradedAfterMinutes,omitempty"`
+
 	// EnvVars specifies additional environment variables to set on the connector pod
 	// Use for exchange-specific config like KRAKEN_SYMBOLS
 	// +optional
@@ -85,6 +123,72 @@ type ConnectorSpec struct {
 	// When specified, these are used INSTEAD of the legacy secretRef env var mapping
 	// +optional
 	SecretEnvVars []SecretEnvMapping `json:"secretEnvVars,omitempty"`
+
+	// CredentialFiles mounts the secretRef/secretEnvVars credentials as files
+	// under /etc/ssmd/credentials instead of env vars. The pod template carries
+	// a checksum of the referenced Secret keys, so the connector is recreated
+	// once when a Secret is rotated and not on unrelated Secret changes.
+	// +optional
+	CredentialFiles bool `json:"credentialFiles,omitempty"`
+
+	// Canary runs a candidate image in a single pod alongside the primary
+	// Deployment, then promotes it to spec.image or tears it down
+	// +optional
+	Canary *CanaryConfig `json:"canary,omitempty"`
+}
+
+// CanaryConfig configures a canary run of a new connector image. The canary
+// is judged against the primary over the window: it fails if it restarts,
+// publishes too slowly, or hits more parse errors than the primary.
+type CanaryConfig struct {
+	// Image is the candidate connector image
+	// +kubebuilder:validation:Required
+	Image string `json:"image"`
+
+	// Trafficless publishes the canary's messages under "canary.<subjectPrefix>",
+	// outside the JetStream stream, so consumers never see them. Set false to
+	// publish on the primary's subjects.
+	// +kubebuilder:default=true
+	// +optional
+	Trafficless *bool `json:"trafficless,omitempty"`
+
+	// DurationMinutes is how long the canary runs once ready before the verdict
+	// +kubebuilder:default=15
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DurationMinutes *int32 `json:"durationMinutes,omitempty"`
+
+	// MinRatePercent is the lowest canary message rate, as a percent of the
+	// primary's per-pod rate, that still passes
+	// +kubebuilder:default=80
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MinRatePercent *int32 `json:"minRatePercent,omitempty"`
+
+	// MaxErrorRatePercent is how many percentage points the canary's parse
+	// errors (as a percent of its messages) may exceed the primary's
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxErrorRatePercent *int32 `json:"maxErrorRatePercent,omitempty"`
+}
+
+// SubscriptionsConfig defines what the connector subscribes to
+type SubscriptionsConfig struct {
+	// Tickers are exchange-native market identifiers (Kalshi tickers, Kraken symbols,
+	// Polymarket token IDs)
+	// +optional
+	Tickers []string `json:"tickers,omitempty"`
+
+	// Channels are the WebSocket channels to subscribe to (e.g., ticker, trade, orderbook_delta)
+	// +optional
+	Channels []string `json:"channels,omitempty"`
+
+	// OrderbookDepth is the order book depth to request on channels that support it
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	OrderbookDepth *int32 `json:"orderbookDepth,omitempty"`
 }
 
 // TransportConfig defines NATS transport settings
@@ -171,6 +275,54 @@ const (
 	ConnectorPhaseTerminated ConnectorPhase = "Terminated"
 )
 
+// CanaryVerdict is the outcome of a canary run
+// +kubebuilder:validation:Enum=Running;Promoted;Failed
+type CanaryVerdict string
+
+const (
+	CanaryVerdictRunning  CanaryVerdict = "Running"
+	CanaryVerdictPromoted CanaryVerdict = "Promoted"
+	CanaryVerdictFailed   CanaryVerdict = "Failed"
+)
+
+// CanaryStatus records the latest canary run. A verdict is final for its
+// image; change spec.canary.image to run another canary.
+type CanaryStatus struct {
+	// Image is the canary image
+	Image string `json:"image"`
+
+	// Verdict is Running until the window ends, then Promoted or Failed
+	Verdict CanaryVerdict `json:"verdict"`
+
+	// Message explains the verdict
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// StartedAt is when the canary pod became ready and the window began
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// CompletedAt is when the verdict was reached
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+
+	// PrimaryBaselineMessages is the primary's message counter at StartedAt
+	// +optional
+	PrimaryBaselineMessages int64 `json:"primaryBaselineMessages,omitempty"`
+
+	// PrimaryBaselineParseErrors is the primary's parse error counter at StartedAt
+	// +optional
+	PrimaryBaselineParseErrors int64 `json:"primaryBaselineParseErrors,omitempty"`
+
+	// PrimaryMessagesPerMinute is the primary's per-pod rate over the window
+	// +optional
+	PrimaryMessagesPerMinute int64 `json:"primaryMessagesPerMinute,omitempty"`
+
+	// CanaryMessagesPerMinute is the canary's rate over the window
+	// +optional
+	CanaryMessagesPerMinute int64 `json:"canaryMessagesPerMinute,omitempty"`
+}
+
 // ConnectionState represents the WebSocket connection state
 // +kubebuilder:validation:Enum=connected;reconnecting;disconnected
 type ConnectionState string
@@ -207,6 +359,19 @@ type ConnectorStatus struct {
 	// +optional
 	ConnectionState ConnectionState `json:"connectionState,omitempty"`
 
+	// ActiveSubscriptions is the number of tickers the running connector subscribes to
+	// +optional
+	ActiveSubscriptions int32 `json:"activeSubscriptions,omitempty"`
+
+	// ReconnectCount is the number of connector container restarts. The connector
+	// exits when its WebSocket goes stale, so each restart is a reconnect.
+	// +optional
+	ReconnectCount int32 `json:"reconnectCount,omitempty"`
+
+	// Canary is the latest canary run, if spec.canary is set
+	// +optional
+	Canary *CanaryStatus `json:"canary,omitempty"`
+
 	// Conditions represent the current state of the Connector
 	// +listType=map
 	// +listMapKey=type
@@ -219,6 +384,7 @@ type ConnectorStatus struct {
 // +kubebuilder:printcolumn:name="Feed",type="string",JSONPath=".spec.feed"
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
 // +kubebuilder:printcolumn:name="Messages",type="integer",JSONPath=".status.messagesPublished"
+// +kubebuilder:printcolumn:name="Subscriptions",type="integer",JSONPath=".status.activeSubscriptions",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Connector is the Schema for the connectors API
@@ -22,13 +22,14 @@ import (
 )
 
 // ExchangeType identifies the exchange backend
-// +kubebuilder:validation:Enum=kalshi;kraken;polymarket;test
+// +kubebuilder:validation:Enum=kalshi;kraken;polymarket;coinbase;test
 type ExchangeType string
 
 const (
 	ExchangeTypeKalshi     ExchangeType = "kalshi"
 	ExchangeTypeKraken     ExchangeType = "kraken"
 	ExchangeTypePolymarket ExchangeType = "polymarket"
+	ExchangeTypeCoinbase   ExchangeType = "coinbase"
 	ExchangeTypeTest       ExchangeType = "test"
 )
 
@@ -48,7 +49,10 @@ type ExchangeConfig struct {
 	// +kubebuilder:validation:Required
 	Type ExchangeType `json:"type"`
 
-	// Environment is the exchange environment (demo, prod, test)
+	// Environment is the exchange environment (demo, prod, test). Changing it
+	// on a running Harman switches blue/green: the new environment's Deployment
+	// starts with trading disabled, is checked for health and auth, and only
+	// then takes over the Service. A failed switch rolls back to the old one.
 	// +kubebuilder:validation:Required
 	Environment ExchangeEnvironment `json:"environment"`
 
@@ -71,6 +75,15 @@ type RiskConfig struct {
 	MaxNotional string `json:"maxNotional,omitempty"`
 }
 
+// TradingConfig controls whether harman may trade
+type TradingConfig struct {
+	// Enabled is the kill switch. When false the operator scales harman to zero,
+	// halting all order flow until it is set back to true.
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
 // DatabaseConfig defines the database connection settings
 type DatabaseConfig struct {
 	// SecretRef references the secret containing database-url
@@ -99,6 +112,10 @@ type HarmanSpec struct {
 	// +optional
 	Risk *RiskConfig `json:"risk,omitempty"`
 
+	// Trading holds the declarative kill switch
+	// +optional
+	Trading *TradingConfig `json:"trading,omitempty"`
+
 	// Database defines the database connection settings
 	// +kubebuilder:validation:Required
 	Database DatabaseConfig `json:"database"`
@@ -116,6 +133,19 @@ type HarmanSpec struct {
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 
+	// PriorityClassName is the PriorityClass for the harman pod.
+	// Defaults to the operator's --harman-priority-class.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PodTemplate configures image pull secrets, scheduling and annotations for the harman pod
+	// +optional
+	PodTemplate *PodTemplateConfig `json:"podTemplate,omitempty"`
+
+	// HighAvailability adds a PodDisruptionBudget and topology spread constraints for the harman pods
+	// +optional
+	HighAvailability *HighAvailabilityConfig `json:"highAvailability,omitempty"`
+
 	// EnvVars are additional environment variables injected into the harman container.
 	// Use for optional config like AUTH_VALIDATE_URL without needing a CRD schema change.
 	// +optional
@@ -123,15 +153,70 @@ type HarmanSpec struct {
 }
 
 // HarmanPhase represents the current phase of the Harman
-// +kubebuilder:validation:Enum=Pending;Running;Failed
+// +kubebuilder:validation:Enum=Pending;Running;Halted;Failed
 type HarmanPhase string
 
 const (
 	HarmanPhasePending HarmanPhase = "Pending"
 	HarmanPhaseRunning HarmanPhase = "Running"
+	HarmanPhaseHalted  HarmanPhase = "Halted"
 	HarmanPhaseFailed  HarmanPhase = "Failed"
 )
 
+// EnvironmentSwitchPhase is the progress of a switch between exchange environments
+// +kubebuilder:validation:Enum=Provisioning;Verifying;Switching;Complete;RolledBack
+type EnvironmentSwitchPhase string
+
+const (
+	// EnvironmentSwitchProvisioning waits for the new environment's Deployment to become ready
+	EnvironmentSwitchProvisioning EnvironmentSwitchPhase = "Provisioning"
+	// EnvironmentSwitchVerifying checks the new environment's health and auth
+	EnvironmentSwitchVerifying EnvironmentSwitchPhase = "Verifying"
+	// EnvironmentSwitchSwitching has moved the Service and waits for trading to start
+	EnvironmentSwitchSwitching EnvironmentSwitchPhase = "Switching"
+	// EnvironmentSwitchComplete means the new environment is active
+	EnvironmentSwitchComplete EnvironmentSwitchPhase = "Complete"
+	// EnvironmentSwitchRolledBack means the switch failed and the old environment kept trading
+	EnvironmentSwitchRolledBack EnvironmentSwitchPhase = "RolledBack"
+)
+
+// EnvironmentSwitchStatus records the latest blue/green switch between exchange environments
+type EnvironmentSwitchStatus struct {
+	// From is the environment being switched away from
+	From ExchangeEnvironment `json:"from"`
+
+	// To is the environment being switched to
+	To ExchangeEnvironment `json:"to"`
+
+	// Phase is the progress of the switch
+	Phase EnvironmentSwitchPhase `json:"phase"`
+
+	// Message explains the phase, or why the switch rolled back
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Deployment is the new environment's Deployment
+	// +optional
+	Deployment string `json:"deployment,omitempty"`
+
+	// ObservedGeneration is the Harman generation the switch started from.
+	// A rolled back switch is retried once the spec changes again.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// StartedAt is when the switch began
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// LastTransitionTime is when the switch entered its current phase
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// CompletedAt is when the switch completed or rolled back
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
 // HarmanStatus defines the observed state of Harman
 type HarmanStatus struct {
 	// Phase is the current lifecycle phase
@@ -146,6 +231,31 @@ type HarmanStatus struct {
 	// +optional
 	Service string `json:"service,omitempty"`
 
+	// ActiveEnvironment is the exchange environment the Service routes to.
+	// It trails spec.exchange.environment until a switch completes.
+	// +optional
+	ActiveEnvironment ExchangeEnvironment `json:"activeEnvironment,omitempty"`
+
+	// EnvironmentSwitch is the latest switch between exchange environments
+	// +optional
+	EnvironmentSwitch *EnvironmentSwitchStatus `json:"environmentSwitch,omitempty"`
+
+	// OpenOrders is the number of open orders, from harman's /v1/orders?state=open
+	// +optional
+	OpenOrders int32 `json:"openOrders,omitempty"`
+
+	// OpenPositions is the number of tickers with a non-zero position, from /v1/admin/positions
+	// +optional
+	OpenPositions int32 `json:"openPositions,omitempty"`
+
+	// TotalNotional is the notional of open orders, from /v1/admin/risk
+	// +optional
+	TotalNotional string `json:"totalNotional,omitempty"`
+
+	// LastOrderAt is when the most recent order was created
+	// +optional
+	LastOrderAt *metav1.Time `json:"lastOrderAt,omitempty"`
+
 	// Conditions represent the current state of the Harman
 	// +listType=map
 	// +listMapKey=type
@@ -157,7 +267,12 @@ type HarmanStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Exchange",type="string",JSONPath=".spec.exchange.type"
 // +kubebuilder:printcolumn:name="Env",type="string",JSONPath=".spec.exchange.environment"
+// +kubebuilder:printcolumn:name="Active Env",type="string",JSONPath=".status.activeEnvironment",priority=1
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Open Orders",type="integer",JSONPath=".status.openOrders"
+// +kubebuilder:printcolumn:name="Notional",type="string",JSONPath=".status.totalNotional"
+// +kubebuilder:printcolumn:name="Positions",type="integer",JSONPath=".status.openPositions",priority=1
+// +kubebuilder:printcolumn:name="Last Order",type="date",JSONPath=".status.lastOrderAt"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Harman is the Schema for the harmans API
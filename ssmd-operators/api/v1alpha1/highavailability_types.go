@@ -0,0 +1,60 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// HighAvailabilityConfig protects a workload's pods from voluntary disruption
+// such as node drains, and spreads them across failure domains
+// +kubebuilder:validation:XValidation:rule="!(has(self.minAvailable) && has(self.maxUnavailable))",message="minAvailable and maxUnavailable are mutually exclusive"
+type HighAvailabilityConfig struct {
+	// MinAvailable is the PodDisruptionBudget's minimum available pods.
+	// Defaults to 1 when maxUnavailable is not set. With a single replica this
+	// blocks eviction, so a drain waits until the pod is moved by hand.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the PodDisruptionBudget's maximum unavailable pods
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// TopologySpread spreads the pods across each listed topology domain
+	// +optional
+	TopologySpread []TopologySpreadConfig `json:"topologySpread,omitempty"`
+}
+
+// TopologySpreadConfig is a topology spread constraint over the workload's own pods
+type TopologySpreadConfig struct {
+	// TopologyKey is the node label defining the domains (e.g., topology.kubernetes.io/zone)
+	// +kubebuilder:validation:Required
+	TopologyKey string `json:"topologyKey"`
+
+	// MaxSkew is the allowed difference in pod count between domains
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxSkew int32 `json:"maxSkew,omitempty"`
+
+	// WhenUnsatisfiable is DoNotSchedule or ScheduleAnyway
+	// +kubebuilder:default=ScheduleAnyway
+	// +kubebuilder:validation:Enum=DoNotSchedule;ScheduleAnyway
+	// +optional
+	WhenUnsatisfiable corev1.UnsatisfiableConstraintAction `json:"whenUnsatisfiable,omitempty"`
+}
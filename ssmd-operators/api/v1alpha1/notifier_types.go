@@ -39,6 +39,23 @@ type NotifierSpec struct {
 	// Resources configures CPU/memory for the notifier pod
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// PriorityClassName is the PriorityClass for the notifier pod.
+	// Defaults to the operator's --notifier-priority-class.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PodTemplate configures image pull secrets, scheduling and annotations for the notifier pod
+	// +optional
+	PodTemplate *PodTemplateConfig `json:"podTemplate,omitempty"`
+
+	// DeliveryDegradedPercent sets the DeliveryDegraded condition when at least this
+	// percentage of a destination's notifications failed since the last check
+	// +kubebuilder:default=20
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	DeliveryDegradedPercent *int32 `json:"deliveryDegradedPercent,omitempty"`
 }
 
 // NotifierSourceConfig defines what NATS subjects to subscribe to
@@ -54,13 +71,19 @@ type NotifierSourceConfig struct {
 }
 
 // NotifierDestination defines a notification destination with routing rules
+// +kubebuilder:validation:XValidation:rule="self.type != 'pagerduty' || has(self.pagerduty)",message="pagerduty destinations require pagerduty settings"
+// +kubebuilder:validation:XValidation:rule="self.type != 'webhook' || has(self.webhook)",message="webhook destinations require webhook settings"
+// +kubebuilder:validation:XValidation:rule="!has(self.slack) || self.type == 'slack'",message="slack settings require type slack"
+// +kubebuilder:validation:XValidation:rule="!has(self.pagerduty) || self.type == 'pagerduty'",message="pagerduty settings require type pagerduty"
+// +kubebuilder:validation:XValidation:rule="!has(self.webhook) || self.type == 'webhook'",message="webhook settings require type webhook"
+// +kubebuilder:validation:XValidation:rule="!has(self.email) || self.type == 'email'",message="email settings require type email"
 type NotifierDestination struct {
 	// Name is the unique name for this destination
 	// +kubebuilder:validation:Required
 	Name string `json:"name"`
 
-	// Type is the destination type (ntfy, slack, discord, email)
-	// +kubebuilder:validation:Enum=ntfy;slack;discord;email
+	// Type is the destination type (ntfy, slack, discord, email, pagerduty, webhook)
+	// +kubebuilder:validation:Enum=ntfy;slack;discord;email;pagerduty;webhook
 	// +kubebuilder:validation:Required
 	Type string `json:"type"`
 
@@ -68,6 +91,22 @@ type NotifierDestination struct {
 	// +optional
 	Config *DestinationConfig `json:"config,omitempty"`
 
+	// Slack configures a slack destination
+	// +optional
+	Slack *SlackDestination `json:"slack,omitempty"`
+
+	// PagerDuty configures a pagerduty destination
+	// +optional
+	PagerDuty *PagerDutyDestination `json:"pagerduty,omitempty"`
+
+	// Webhook configures a generic HTTP webhook destination
+	// +optional
+	Webhook *WebhookDestination `json:"webhook,omitempty"`
+
+	// Email configures an email destination sent over SMTP
+	// +optional
+	Email *EmailDestination `json:"email,omitempty"`
+
 	// SecretRef references a secret containing credentials
 	// +optional
 	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
@@ -116,6 +155,90 @@ type DestinationConfig struct {
 	SMTP string `json:"smtp,omitempty"`
 }
 
+// SlackDestination configures a Slack incoming webhook
+type SlackDestination struct {
+	// WebhookURL is the incoming webhook URL (use webhookUrlField to keep it in the secret)
+	// +kubebuilder:validation:Pattern=`^https://`
+	// +optional
+	WebhookURL string `json:"webhookUrl,omitempty"`
+
+	// WebhookURLField is the key in secretRef holding the incoming webhook URL
+	// +optional
+	WebhookURLField string `json:"webhookUrlField,omitempty"`
+
+	// Channel overrides the webhook's default channel (e.g., "#alerts")
+	// +optional
+	Channel string `json:"channel,omitempty"`
+}
+
+// PagerDutyDestination configures PagerDuty Events API v2 alerts
+type PagerDutyDestination struct {
+	// RoutingKeyField is the key in secretRef holding the integration routing key
+	// +kubebuilder:default="routing-key"
+	// +optional
+	RoutingKeyField string `json:"routingKeyField,omitempty"`
+
+	// Severity is the severity of triggered alerts
+	// +kubebuilder:validation:Enum=critical;error;warning;info
+	// +kubebuilder:default="error"
+	// +optional
+	Severity string `json:"severity,omitempty"`
+}
+
+// WebhookDestination configures a generic HTTP webhook
+type WebhookDestination struct {
+	// URL is the endpoint fires are posted to
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Method is the HTTP method
+	// +kubebuilder:validation:Enum=POST;PUT
+	// +kubebuilder:default="POST"
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Headers are sent with every request
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// SecretHeaders maps header names to keys in secretRef holding their values
+	// (e.g., Authorization: auth-header)
+	// +optional
+	SecretHeaders map[string]string `json:"secretHeaders,omitempty"`
+}
+
+// EmailDestination configures email delivery over SMTP
+type EmailDestination struct {
+	// To is the list of recipient addresses
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	To []string `json:"to"`
+
+	// From is the sender address
+	// +kubebuilder:validation:Required
+	From string `json:"from"`
+
+	// Host is the SMTP server host
+	// +kubebuilder:validation:Required
+	Host string `json:"host"`
+
+	// Port is the SMTP server port
+	// +kubebuilder:default=587
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// UsernameField is the key in secretRef holding the SMTP username
+	// +optional
+	UsernameField string `json:"usernameField,omitempty"`
+
+	// PasswordField is the key in secretRef holding the SMTP password
+	// +optional
+	PasswordField string `json:"passwordField,omitempty"`
+}
+
 // MatchRule defines a routing rule for a destination
 type MatchRule struct {
 	// Field is the JSON path to the field to match (e.g., "payload.dollarVolume")
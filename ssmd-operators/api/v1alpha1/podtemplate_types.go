@@ -0,0 +1,50 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodTemplateConfig configures scheduling and registry access for the pods an
+// ssmd resource runs
+type PodTemplateConfig struct {
+	// ImagePullSecrets are the registry secrets for the pod.
+	// Defaults to ghcr-secret.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// NodeSelector restricts the pod to nodes with matching labels
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations allow the pod to schedule onto tainted nodes
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity sets node and pod affinity rules for the pod
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Annotations are added to the pod template (e.g., for service mesh or monitoring)
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// ServiceAccountName is the Kubernetes ServiceAccount for the pod
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
@@ -17,16 +17,25 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"encoding/json"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // SignalSpec defines the desired state of Signal
+// +kubebuilder:validation:XValidation:rule="!has(self.mode) || self.mode != 'backtest' || has(self.backtest)",message="backtest is required when mode is backtest"
 type SignalSpec struct {
-	// Signals is the list of signal IDs to run in this pod
+	// Mode is live (a Deployment evaluating market data from NATS) or
+	// backtest (a Job evaluating an archived dataset once)
+	// +kubebuilder:default=live
+	// +optional
+	Mode SignalMode `json:"mode,omitempty"`
+
+	// Signals is the list of signals to run in this pod
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinItems=1
-	Signals []string `json:"signals"`
+	Signals []SignalConfig `json:"signals"`
 
 	// Source configures the NATS source for market data
 	// +kubebuilder:validation:Required
@@ -45,6 +54,125 @@ type SignalSpec struct {
 	// Resources configures CPU/memory for the signal pod
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// PriorityClassName is the PriorityClass for the signal pod.
+	// Defaults to the operator's --signal-priority-class.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PodTemplate configures image pull secrets, scheduling and annotations for the signal pod
+	// +optional
+	PodTemplate *PodTemplateConfig `json:"podTemplate,omitempty"`
+
+	// Backtest configures the dataset and output of a backtest run (mode: backtest)
+	// +optional
+	Backtest *SignalBacktestConfig `json:"backtest,omitempty"`
+}
+
+// SignalMode selects how a Signal runs
+// +kubebuilder:validation:Enum=live;backtest
+type SignalMode string
+
+const (
+	SignalModeLive     SignalMode = "live"
+	SignalModeBacktest SignalMode = "backtest"
+)
+
+// SignalBacktestConfig configures a backtest run over archived data
+type SignalBacktestConfig struct {
+	// Dataset selects the archived data to replay
+	// +kubebuilder:validation:Required
+	Dataset SignalDatasetConfig `json:"dataset"`
+
+	// Output is where the backtest writes its fires
+	// +kubebuilder:validation:Required
+	Output SignalBacktestOutput `json:"output"`
+}
+
+// SignalDatasetConfig selects an archived feed and date, read from the data API
+// (the default) or from a PVC holding archiver output
+// +kubebuilder:validation:XValidation:rule="!(has(self.pvcName) && has(self.dataApi))",message="set pvcName or dataApi, not both"
+type SignalDatasetConfig struct {
+	// Feed is the archived feed name (e.g., "kalshi")
+	// +kubebuilder:validation:Required
+	Feed string `json:"feed"`
+
+	// Date is the day to replay (YYYY-MM-DD)
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`
+	Date string `json:"date"`
+
+	// DataAPI configures the data API the dataset is downloaded from
+	// +optional
+	DataAPI *SignalDataAPIConfig `json:"dataApi,omitempty"`
+
+	// PVCName reads the dataset from this PVC (mounted read-only at /dataset)
+	// instead of the data API
+	// +optional
+	PVCName string `json:"pvcName,omitempty"`
+
+	// Path is the dataset directory within the PVC (default: {feed}/{date})
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// SignalDataAPIConfig configures access to the ssmd data API
+type SignalDataAPIConfig struct {
+	// URL is the data API base URL
+	// +kubebuilder:default="http://ssmd-data-ts-internal.ssmd.svc.cluster.local:8081"
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// APIKeySecretRef is the secret key holding an API key, set as SSMD_API_KEY
+	// +optional
+	APIKeySecretRef *corev1.SecretKeySelector `json:"apiKeySecretRef,omitempty"`
+}
+
+// SignalBacktestOutput is the destination of backtest fires; set exactly one field
+// +kubebuilder:validation:XValidation:rule="has(self.subject) != has(self.gcsPath)",message="set exactly one of subject or gcsPath"
+type SignalBacktestOutput struct {
+	// Subject is the NATS subject fires are published to (on source.natsUrl)
+	// +optional
+	Subject string `json:"subject,omitempty"`
+
+	// GCSPath is the gs:// path fires are written to (e.g., "gs://ssmd-backtests/momentum/")
+	// +kubebuilder:validation:Pattern=`^gs://`
+	// +optional
+	GCSPath string `json:"gcsPath,omitempty"`
+}
+
+// SignalConfig configures one signal run by the pod
+type SignalConfig struct {
+	// Name is the signal ID
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=momentum;volatility;spread-tracker;volume-1m-1min;volume-1m-5min;volume-1m-15min;volume-1m-30min
+	Name string `json:"name"`
+
+	// Params are signal-specific parameters (e.g., threshold: "1000000")
+	// +optional
+	Params map[string]string `json:"params,omitempty"`
+
+	// Windows overrides the signal's window sizes (e.g., ["1m", "30m"])
+	// +kubebuilder:validation:items:Pattern=`^[0-9]+(s|m|h)$`
+	// +optional
+	Windows []string `json:"windows,omitempty"`
+
+	// OutputSubject overrides the subject fires are published to
+	// (default: {outputPrefix}.{name}.fires)
+	// +optional
+	OutputSubject string `json:"outputSubject,omitempty"`
+}
+
+// UnmarshalJSON also accepts a bare signal ID, the format of spec.signals
+// before it took objects, so Signals stored by older versions still decode.
+func (c *SignalConfig) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*c = SignalConfig{Name: name}
+		return nil
+	}
+	type signalConfig SignalConfig
+	return json.Unmarshal(data, (*signalConfig)(c))
 }
 
 // SignalSourceConfig defines the NATS source settings for signals
@@ -72,13 +200,14 @@ type SignalSourceConfig struct {
 }
 
 // SignalPhase represents the current phase of the Signal
-// +kubebuilder:validation:Enum=Pending;Running;Failed
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
 type SignalPhase string
 
 const (
-	SignalPhasePending SignalPhase = "Pending"
-	SignalPhaseRunning SignalPhase = "Running"
-	SignalPhaseFailed  SignalPhase = "Failed"
+	SignalPhasePending   SignalPhase = "Pending"
+	SignalPhaseRunning   SignalPhase = "Running"
+	SignalPhaseSucceeded SignalPhase = "Succeeded"
+	SignalPhaseFailed    SignalPhase = "Failed"
 )
 
 // SignalMetrics contains per-signal metrics
@@ -107,6 +236,10 @@ type SignalStatus struct {
 	// +optional
 	Deployment string `json:"deployment,omitempty"`
 
+	// Job is the name of the backtest Job (mode: backtest)
+	// +optional
+	Job string `json:"job,omitempty"`
+
 	// SignalMetrics contains per-signal metrics
 	// +optional
 	SignalMetrics []SignalMetrics `json:"signalMetrics,omitempty"`
@@ -120,8 +253,9 @@ type SignalStatus struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
-// +kubebuilder:printcolumn:name="Signals",type="string",JSONPath=".spec.signals"
+// +kubebuilder:printcolumn:name="Signals",type="string",JSONPath=".spec.signals[*].name"
 // +kubebuilder:printcolumn:name="Stream",type="string",JSONPath=".spec.source.stream"
+// +kubebuilder:printcolumn:name="Mode",type="string",JSONPath=".spec.mode"
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
@@ -36,6 +36,57 @@ type SnapSubscription struct {
 	Subject string `json:"subject"`
 }
 
+// SnapServiceMonitorConfig configures a Prometheus Operator ServiceMonitor for the snap Service
+type SnapServiceMonitorConfig struct {
+	// Enabled creates a ServiceMonitor scraping /metrics on the snap Service
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Interval is the Prometheus scrape interval
+	// +kubebuilder:default="30s"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(s|m)$`
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// Labels are added to the ServiceMonitor so a Prometheus serviceMonitorSelector selects it
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// SnapAutoscalingConfig configures a HorizontalPodAutoscaler for the snap Deployment
+// +kubebuilder:validation:XValidation:rule="has(self.targetCPUUtilizationPercentage) || has(self.targetMessagesPerSecond)",message="set targetCPUUtilizationPercentage or targetMessagesPerSecond"
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must not exceed maxReplicas"
+type SnapAutoscalingConfig struct {
+	// MinReplicas is the lower replica bound
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper replica bound
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage scales on average CPU utilization of the requested CPU
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMessagesPerSecond scales on the average ticker messages per second per pod.
+	// Requires a custom metrics adapter serving MessagesPerSecondMetric.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetMessagesPerSecond *int32 `json:"targetMessagesPerSecond,omitempty"`
+
+	// MessagesPerSecondMetric is the custom pods metric for TargetMessagesPerSecond,
+	// typically rate(snap_messages_received_total) exposed through the adapter
+	// +kubebuilder:default="snap_messages_received_per_second"
+	// +optional
+	MessagesPerSecondMetric string `json:"messagesPerSecondMetric,omitempty"`
+}
+
 // SnapSpec defines the desired state of Snap
 type SnapSpec struct {
 	// Image is the container image to use
@@ -66,9 +117,31 @@ type SnapSpec struct {
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 
+	// PriorityClassName is the PriorityClass for the snap pod.
+	// Defaults to the operator's --snap-priority-class.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PodTemplate configures image pull secrets, scheduling and annotations for the snap pod
+	// +optional
+	PodTemplate *PodTemplateConfig `json:"podTemplate,omitempty"`
+
+	// HighAvailability adds a PodDisruptionBudget and topology spread constraints for the snap pods
+	// +optional
+	HighAvailability *HighAvailabilityConfig `json:"highAvailability,omitempty"`
+
 	// EnvVars specifies additional environment variables to set on the snap pod
 	// +optional
 	EnvVars []corev1.EnvVar `json:"envVars,omitempty"`
+
+	// ServiceMonitor configures an optional Prometheus Operator ServiceMonitor
+	// +optional
+	ServiceMonitor *SnapServiceMonitorConfig `json:"serviceMonitor,omitempty"`
+
+	// Autoscaling configures an optional HorizontalPodAutoscaler. When set the
+	// HPA owns the replica count.
+	// +optional
+	Autoscaling *SnapAutoscalingConfig `json:"autoscaling,omitempty"`
 }
 
 // SnapPhase represents the current phase of the Snap
@@ -91,6 +164,14 @@ type SnapStatus struct {
 	// +optional
 	Deployment string `json:"deployment,omitempty"`
 
+	// Service is the name of the created Service
+	// +optional
+	Service string `json:"service,omitempty"`
+
+	// Replicas is the current number of ready snap pods
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
 	// Conditions represent the current state of the Snap
 	// +listType=map
 	// +listMapKey=type
@@ -102,6 +183,7 @@ type SnapStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Feeds",type="string",JSONPath=".spec.subscriptions[*].feed"
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.replicas"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Snap is the Schema for the snaps API
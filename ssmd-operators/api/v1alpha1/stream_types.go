@@ -0,0 +1,58 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// StreamProvisionConfig defines the JetStream stream settings the operator
+// provisions when provisionStream is set
+type StreamProvisionConfig struct {
+	// Subjects captured by the stream. When unset, a new stream captures
+	// "<subjectPrefix>.>" for connectors or the source filters for archivers,
+	// and an existing stream keeps its subjects.
+	// +optional
+	Subjects []string `json:"subjects,omitempty"`
+
+	// Retention is the stream retention policy
+	// +kubebuilder:validation:Enum=limits;interest;workqueue
+	// +kubebuilder:default="limits"
+	// +optional
+	Retention string `json:"retention,omitempty"`
+
+	// MaxAge is how long messages are kept (e.g., "48h"); unset keeps them until other limits apply
+	// +optional
+	MaxAge string `json:"maxAge,omitempty"`
+
+	// MaxBytes caps the stream size; unset means unlimited
+	// +optional
+	MaxBytes *resource.Quantity `json:"maxBytes,omitempty"`
+
+	// Storage is the stream storage backend
+	// +kubebuilder:validation:Enum=file;memory
+	// +kubebuilder:default="file"
+	// +optional
+	Storage string `json:"storage,omitempty"`
+
+	// Replicas is the number of stream replicas
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=5
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TenantConfigSpec defines a desk's settings for the ssmd resources in its namespace
+type TenantConfigSpec struct {
+	// Tenant names the desk. It is set as the ssmd.io/tenant label on every
+	// object the operator creates and recorded on the JetStream streams it
+	// provisions, so another tenant's operator cannot change them.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	Tenant string `json:"tenant"`
+
+	// Labels are added to every object the operator creates, alongside ssmd.io/tenant
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Images are the default images by component ("archiver", "connector",
+	// "harman", "notifier"). They take precedence over the image versions ConfigMap.
+	// +optional
+	Images map[string]string `json:"images,omitempty"`
+
+	// Resources are the default container resources by component, used when
+	// a CR does not set spec.resources
+	// +optional
+	Resources map[string]corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Tenant",type="string",JSONPath=".spec.tenant"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// TenantConfig is the Schema for the tenantconfigs API
+type TenantConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the tenant settings
+	// +required
+	Spec TenantConfigSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// TenantConfigList contains a list of TenantConfig
+type TenantConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []TenantConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TenantConfig{}, &TenantConfigList{})
+}
@@ -24,6 +24,7 @@ import (
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -85,6 +86,127 @@ func (in *ArchiverList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchiverSchedule) DeepCopyInto(out *ArchiverSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiverSchedule.
+func (in *ArchiverSchedule) DeepCopy() *ArchiverSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchiverSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ArchiverSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchiverScheduleList) DeepCopyInto(out *ArchiverScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ArchiverSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiverScheduleList.
+func (in *ArchiverScheduleList) DeepCopy() *ArchiverScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchiverScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ArchiverScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchiverScheduleSpec) DeepCopyInto(out *ArchiverScheduleSpec) {
+	*out = *in
+	if in.DeleteAfter != nil {
+		in, out := &in.DeleteAfter, &out.DeleteAfter
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Compaction != nil {
+		in, out := &in.Compaction, &out.Compaction
+		*out = new(CompactionConfig)
+		**out = **in
+	}
+	if in.SecmasterArchive != nil {
+		in, out := &in.SecmasterArchive, &out.SecmasterArchive
+		*out = new(SecmasterArchiveConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiverScheduleSpec.
+func (in *ArchiverScheduleSpec) DeepCopy() *ArchiverScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchiverScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchiverScheduleStatus) DeepCopyInto(out *ArchiverScheduleStatus) {
+	*out = *in
+	if in.Archivers != nil {
+		in, out := &in.Archivers, &out.Archivers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastRolloverAt != nil {
+		in, out := &in.LastRolloverAt, &out.LastRolloverAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiverScheduleStatus.
+func (in *ArchiverScheduleStatus) DeepCopy() *ArchiverScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchiverScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArchiverSourceConfig) DeepCopyInto(out *ArchiverSourceConfig) {
 	*out = *in
@@ -118,6 +240,11 @@ func (in *ArchiverSpec) DeepCopyInto(out *ArchiverSpec) {
 		*out = new(ArchiverSourceConfig)
 		**out = **in
 	}
+	if in.Stream != nil {
+		in, out := &in.Stream, &out.Stream
+		*out = new(StreamProvisionConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Storage != nil {
 		in, out := &in.Storage, &out.Storage
 		*out = new(StorageConfig)
@@ -128,16 +255,37 @@ func (in *ArchiverSpec) DeepCopyInto(out *ArchiverSpec) {
 		*out = new(RotationConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DedupKeys != nil {
+		in, out := &in.DedupKeys, &out.DedupKeys
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.Sync != nil {
 		in, out := &in.Sync, &out.Sync
 		*out = new(SyncConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(PodTemplateConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiverSpec.
@@ -196,6 +344,64 @@ func (in *AuthConfig) DeepCopy() *AuthConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryConfig) DeepCopyInto(out *CanaryConfig) {
+	*out = *in
+	if in.Trafficless != nil {
+		in, out := &in.Trafficless, &out.Trafficless
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DurationMinutes != nil {
+		in, out := &in.DurationMinutes, &out.DurationMinutes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinRatePercent != nil {
+		in, out := &in.MinRatePercent, &out.MinRatePercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxErrorRatePercent != nil {
+		in, out := &in.MaxErrorRatePercent, &out.MaxErrorRatePercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryConfig.
+func (in *CanaryConfig) DeepCopy() *CanaryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStatus.
+func (in *CanaryStatus) DeepCopy() *CanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CdcConfig) DeepCopyInto(out *CdcConfig) {
 	*out = *in
@@ -211,6 +417,21 @@ func (in *CdcConfig) DeepCopy() *CdcConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompactionConfig) DeepCopyInto(out *CompactionConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompactionConfig.
+func (in *CompactionConfig) DeepCopy() *CompactionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CompactionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Connector) DeepCopyInto(out *Connector) {
 	*out = *in
@@ -293,6 +514,11 @@ func (in *ConnectorSpec) DeepCopyInto(out *ConnectorSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Subscriptions != nil {
+		in, out := &in.Subscriptions, &out.Subscriptions
+		*out = new(SubscriptionsConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Transport != nil {
 		in, out := &in.Transport, &out.Transport
 		*out = new(TransportConfig)
@@ -308,11 +534,31 @@ func (in *ConnectorSpec) DeepCopyInto(out *ConnectorSpec) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(PodTemplateConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(HighAvailabilityConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Stream != nil {
+		in, out := &in.Stream, &out.Stream
+		*out = new(StreamProvisionConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Cdc != nil {
 		in, out := &in.Cdc, &out.Cdc
 		*out = new(CdcConfig)
 		**out = **in
 	}
+	if in.DegradedAfterMinutes != nil {
+		in, out := &in.DegradedAfterMinutes, &out.DegradedAfterMinutes
+		*out = new(int32)
+		**out = **in
+	}
 	if in.EnvVars != nil {
 		in, out := &in.EnvVars, &out.EnvVars
 		*out = make([]v1.EnvVar, len(*in))
@@ -325,6 +571,11 @@ func (in *ConnectorSpec) DeepCopyInto(out *ConnectorSpec) {
 		*out = make([]SecretEnvMapping, len(*in))
 		copy(*out, *in)
 	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectorSpec.
@@ -348,6 +599,11 @@ func (in *ConnectorStatus) DeepCopyInto(out *ConnectorStatus) {
 		in, out := &in.LastMessageAt, &out.LastMessageAt
 		*out = (*in).DeepCopy()
 	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -417,6 +673,58 @@ func (in *DestinationMetrics) DeepCopy() *DestinationMetrics {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmailDestination) DeepCopyInto(out *EmailDestination) {
+	*out = *in
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmailDestination.
+func (in *EmailDestination) DeepCopy() *EmailDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(EmailDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvironmentSwitchStatus) DeepCopyInto(out *EnvironmentSwitchStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvironmentSwitchStatus.
+func (in *EnvironmentSwitchStatus) DeepCopy() *EnvironmentSwitchStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvironmentSwitchStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExchangeConfig) DeepCopyInto(out *ExchangeConfig) {
 	*out = *in
@@ -505,6 +813,11 @@ func (in *HarmanSpec) DeepCopyInto(out *HarmanSpec) {
 		*out = new(RiskConfig)
 		**out = **in
 	}
+	if in.Trading != nil {
+		in, out := &in.Trading, &out.Trading
+		*out = new(TradingConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Database = in.Database
 	out.Auth = in.Auth
 	if in.Resources != nil {
@@ -512,6 +825,16 @@ func (in *HarmanSpec) DeepCopyInto(out *HarmanSpec) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(PodTemplateConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(HighAvailabilityConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.EnvVars != nil {
 		in, out := &in.EnvVars, &out.EnvVars
 		*out = make([]v1.EnvVar, len(*in))
@@ -534,6 +857,15 @@ func (in *HarmanSpec) DeepCopy() *HarmanSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HarmanStatus) DeepCopyInto(out *HarmanStatus) {
 	*out = *in
+	if in.EnvironmentSwitch != nil {
+		in, out := &in.EnvironmentSwitch, &out.EnvironmentSwitch
+		*out = new(EnvironmentSwitchStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastOrderAt != nil {
+		in, out := &in.LastOrderAt, &out.LastOrderAt
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -553,6 +885,36 @@ func (in *HarmanStatus) DeepCopy() *HarmanStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HighAvailabilityConfig) DeepCopyInto(out *HighAvailabilityConfig) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.TopologySpread != nil {
+		in, out := &in.TopologySpread, &out.TopologySpread
+		*out = make([]TopologySpreadConfig, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HighAvailabilityConfig.
+func (in *HighAvailabilityConfig) DeepCopy() *HighAvailabilityConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HighAvailabilityConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalStorageConfig) DeepCopyInto(out *LocalStorageConfig) {
 	*out = *in
@@ -561,6 +923,11 @@ func (in *LocalStorageConfig) DeepCopyInto(out *LocalStorageConfig) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.FillingThresholdPercent != nil {
+		in, out := &in.FillingThresholdPercent, &out.FillingThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalStorageConfig.
@@ -623,6 +990,26 @@ func (in *NotifierDestination) DeepCopyInto(out *NotifierDestination) {
 		*out = new(DestinationConfig)
 		**out = **in
 	}
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(SlackDestination)
+		**out = **in
+	}
+	if in.PagerDuty != nil {
+		in, out := &in.PagerDuty, &out.PagerDuty
+		*out = new(PagerDutyDestination)
+		**out = **in
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookDestination)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Email != nil {
+		in, out := &in.Email, &out.Email
+		*out = new(EmailDestination)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SecretRef != nil {
 		in, out := &in.SecretRef, &out.SecretRef
 		*out = new(v1.LocalObjectReference)
@@ -713,6 +1100,16 @@ func (in *NotifierSpec) DeepCopyInto(out *NotifierSpec) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(PodTemplateConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeliveryDegradedPercent != nil {
+		in, out := &in.DeliveryDegradedPercent, &out.DeliveryDegradedPercent
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifierSpec.
@@ -755,32 +1152,93 @@ func (in *NotifierStatus) DeepCopy() *NotifierStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RemoteStorageConfig) DeepCopyInto(out *RemoteStorageConfig) {
+func (in *PagerDutyDestination) DeepCopyInto(out *PagerDutyDestination) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteStorageConfig.
-func (in *RemoteStorageConfig) DeepCopy() *RemoteStorageConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyDestination.
+func (in *PagerDutyDestination) DeepCopy() *PagerDutyDestination {
 	if in == nil {
 		return nil
 	}
-	out := new(RemoteStorageConfig)
+	out := new(PagerDutyDestination)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RiskConfig) DeepCopyInto(out *RiskConfig) {
+func (in *PodTemplateConfig) DeepCopyInto(out *PodTemplateConfig) {
 	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RiskConfig.
-func (in *RiskConfig) DeepCopy() *RiskConfig {
-	if in == nil {
-		return nil
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
 	}
-	out := new(RiskConfig)
-	in.DeepCopyInto(out)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodTemplateConfig.
+func (in *PodTemplateConfig) DeepCopy() *PodTemplateConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PodTemplateConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteStorageConfig) DeepCopyInto(out *RemoteStorageConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteStorageConfig.
+func (in *RemoteStorageConfig) DeepCopy() *RemoteStorageConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteStorageConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RiskConfig) DeepCopyInto(out *RiskConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RiskConfig.
+func (in *RiskConfig) DeepCopy() *RiskConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RiskConfig)
+	in.DeepCopyInto(out)
 	return out
 }
 
@@ -804,6 +1262,22 @@ func (in *RotationConfig) DeepCopy() *RotationConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecmasterArchiveConfig) DeepCopyInto(out *SecmasterArchiveConfig) {
+	*out = *in
+	out.DatabaseSecretRef = in.DatabaseSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecmasterArchiveConfig.
+func (in *SecmasterArchiveConfig) DeepCopy() *SecmasterArchiveConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SecmasterArchiveConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretEnvMapping) DeepCopyInto(out *SecretEnvMapping) {
 	*out = *in
@@ -861,6 +1335,105 @@ func (in *Signal) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignalBacktestConfig) DeepCopyInto(out *SignalBacktestConfig) {
+	*out = *in
+	in.Dataset.DeepCopyInto(&out.Dataset)
+	out.Output = in.Output
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignalBacktestConfig.
+func (in *SignalBacktestConfig) DeepCopy() *SignalBacktestConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SignalBacktestConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignalBacktestOutput) DeepCopyInto(out *SignalBacktestOutput) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignalBacktestOutput.
+func (in *SignalBacktestOutput) DeepCopy() *SignalBacktestOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(SignalBacktestOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignalConfig) DeepCopyInto(out *SignalConfig) {
+	*out = *in
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignalConfig.
+func (in *SignalConfig) DeepCopy() *SignalConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SignalConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignalDataAPIConfig) DeepCopyInto(out *SignalDataAPIConfig) {
+	*out = *in
+	if in.APIKeySecretRef != nil {
+		in, out := &in.APIKeySecretRef, &out.APIKeySecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignalDataAPIConfig.
+func (in *SignalDataAPIConfig) DeepCopy() *SignalDataAPIConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SignalDataAPIConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignalDatasetConfig) DeepCopyInto(out *SignalDatasetConfig) {
+	*out = *in
+	if in.DataAPI != nil {
+		in, out := &in.DataAPI, &out.DataAPI
+		*out = new(SignalDataAPIConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignalDatasetConfig.
+func (in *SignalDatasetConfig) DeepCopy() *SignalDatasetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SignalDatasetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SignalList) DeepCopyInto(out *SignalList) {
 	*out = *in
@@ -942,8 +1515,10 @@ func (in *SignalSpec) DeepCopyInto(out *SignalSpec) {
 	*out = *in
 	if in.Signals != nil {
 		in, out := &in.Signals, &out.Signals
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+		*out = make([]SignalConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	in.Source.DeepCopyInto(&out.Source)
 	if in.Resources != nil {
@@ -951,6 +1526,16 @@ func (in *SignalSpec) DeepCopyInto(out *SignalSpec) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(PodTemplateConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backtest != nil {
+		in, out := &in.Backtest, &out.Backtest
+		*out = new(SignalBacktestConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignalSpec.
@@ -992,6 +1577,21 @@ func (in *SignalStatus) DeepCopy() *SignalStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackDestination) DeepCopyInto(out *SlackDestination) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackDestination.
+func (in *SlackDestination) DeepCopy() *SlackDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Snap) DeepCopyInto(out *Snap) {
 	*out = *in
@@ -1019,6 +1619,36 @@ func (in *Snap) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapAutoscalingConfig) DeepCopyInto(out *SnapAutoscalingConfig) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetMessagesPerSecond != nil {
+		in, out := &in.TargetMessagesPerSecond, &out.TargetMessagesPerSecond
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapAutoscalingConfig.
+func (in *SnapAutoscalingConfig) DeepCopy() *SnapAutoscalingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapAutoscalingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SnapList) DeepCopyInto(out *SnapList) {
 	*out = *in
@@ -1051,6 +1681,28 @@ func (in *SnapList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapServiceMonitorConfig) DeepCopyInto(out *SnapServiceMonitorConfig) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapServiceMonitorConfig.
+func (in *SnapServiceMonitorConfig) DeepCopy() *SnapServiceMonitorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapServiceMonitorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SnapSpec) DeepCopyInto(out *SnapSpec) {
 	*out = *in
@@ -1064,6 +1716,16 @@ func (in *SnapSpec) DeepCopyInto(out *SnapSpec) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(PodTemplateConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(HighAvailabilityConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.EnvVars != nil {
 		in, out := &in.EnvVars, &out.EnvVars
 		*out = make([]v1.EnvVar, len(*in))
@@ -1071,6 +1733,16 @@ func (in *SnapSpec) DeepCopyInto(out *SnapSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ServiceMonitor != nil {
+		in, out := &in.ServiceMonitor, &out.ServiceMonitor
+		*out = new(SnapServiceMonitorConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(SnapAutoscalingConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapSpec.
@@ -1160,9 +1832,79 @@ func (in *StorageConfig) DeepCopy() *StorageConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamProvisionConfig) DeepCopyInto(out *StreamProvisionConfig) {
+	*out = *in
+	if in.Subjects != nil {
+		in, out := &in.Subjects, &out.Subjects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxBytes != nil {
+		in, out := &in.MaxBytes, &out.MaxBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamProvisionConfig.
+func (in *StreamProvisionConfig) DeepCopy() *StreamProvisionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamProvisionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionsConfig) DeepCopyInto(out *SubscriptionsConfig) {
+	*out = *in
+	if in.Tickers != nil {
+		in, out := &in.Tickers, &out.Tickers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OrderbookDepth != nil {
+		in, out := &in.OrderbookDepth, &out.OrderbookDepth
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionsConfig.
+func (in *SubscriptionsConfig) DeepCopy() *SubscriptionsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriptionsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncConfig) DeepCopyInto(out *SyncConfig) {
 	*out = *in
+	if in.MaxAttempts != nil {
+		in, out := &in.MaxAttempts, &out.MaxAttempts
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FinalSyncTimeout != nil {
+		in, out := &in.FinalSyncTimeout, &out.FinalSyncTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncConfig.
@@ -1175,6 +1917,135 @@ func (in *SyncConfig) DeepCopy() *SyncConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantConfig) DeepCopyInto(out *TenantConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantConfig.
+func (in *TenantConfig) DeepCopy() *TenantConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TenantConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantConfigList) DeepCopyInto(out *TenantConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TenantConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantConfigList.
+func (in *TenantConfigList) DeepCopy() *TenantConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TenantConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantConfigSpec) DeepCopyInto(out *TenantConfigSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(map[string]v1.ResourceRequirements, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantConfigSpec.
+func (in *TenantConfigSpec) DeepCopy() *TenantConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologySpreadConfig) DeepCopyInto(out *TopologySpreadConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologySpreadConfig.
+func (in *TopologySpreadConfig) DeepCopy() *TopologySpreadConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologySpreadConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TradingConfig) DeepCopyInto(out *TradingConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TradingConfig.
+func (in *TradingConfig) DeepCopy() *TradingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TradingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TransportConfig) DeepCopyInto(out *TransportConfig) {
 	*out = *in
@@ -1189,3 +2060,32 @@ func (in *TransportConfig) DeepCopy() *TransportConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookDestination) DeepCopyInto(out *WebhookDestination) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecretHeaders != nil {
+		in, out := &in.SecretHeaders, &out.SecretHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookDestination.
+func (in *WebhookDestination) DeepCopy() *WebhookDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookDestination)
+	in.DeepCopyInto(out)
+	return out
+}
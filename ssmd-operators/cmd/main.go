@@ -20,6 +20,8 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	// Embed the timezone database for ArchiverSchedule calendars
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -27,6 +29,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -37,6 +40,9 @@ import (
 
 	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
 	"github.com/aaronwald/ssmd/ssmd-operators/internal/controller"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/options"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/tenant"
+	webhookv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -54,20 +60,14 @@ func init() {
 
 // nolint:gocyclo
 func main() {
-	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
-	var enableLeaderElection bool
-	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
-	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
-		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
-	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
-	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
-		"Enable leader election for controller manager. "+
-			"Enabling this will ensure there is only one active controller manager.")
+	var enableWebhooks bool
+	var imageVersionsConfigMap string
+	managerFlags := options.BindFlags(flag.CommandLine)
 	flag.BoolVar(&secureMetrics, "metrics-secure", true,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
@@ -79,6 +79,10 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the validating/defaulting admission webhooks. Requires a webhook certificate.")
+	flag.StringVar(&imageVersionsConfigMap, "image-versions-configmap", webhookv1alpha1.DefaultImageVersionsConfigMap,
+		"ConfigMap (in each CR's namespace) mapping components to default images, used by the defaulting webhooks.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -87,6 +91,13 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	managerOptions, err := managerFlags.Resolve()
+	if err != nil {
+		setupLog.Error(err, "invalid operator options")
+		os.Exit(1)
+	}
+	setupLog.Info("operator options", "options", managerOptions.String())
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -124,7 +135,7 @@ func main() {
 	// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.4/pkg/metrics/server
 	// - https://book.kubebuilder.io/reference/metrics.html
 	metricsServerOptions := metricsserver.Options{
-		BindAddress:   metricsAddr,
+		BindAddress:   managerOptions.MetricsBindAddress,
 		SecureServing: secureMetrics,
 		TLSOpts:       tlsOpts,
 	}
@@ -156,11 +167,12 @@ func main() {
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  managerOptions.CacheOptions(),
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "fd71ed73.ssmd.io",
+		HealthProbeBindAddress: managerOptions.HealthProbeBindAddress,
+		LeaderElection:         managerOptions.LeaderElection,
+		LeaderElectionID:       managerOptions.LeaderElectionID,
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -178,48 +190,117 @@ func main() {
 		os.Exit(1)
 	}
 
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create clientset")
+		os.Exit(1)
+	}
+
+	// Children are written with the tenant labels of their namespace
+	tenantClient := tenant.NewClient(mgr.GetClient(), managerOptions.TenantConfig)
+
 	if err := (&controller.ConnectorReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                   tenantClient,
+		Scheme:                   mgr.GetScheme(),
+		Recorder:                 mgr.GetEventRecorder("connector-controller"),
+		MaxConcurrentReconciles:  managerOptions.Concurrency("connector"),
+		DefaultPriorityClassName: managerOptions.PriorityClassName("connector"),
+		DefaultResources:         managerOptions.Resources("connector"),
+		TenantConfig:             managerOptions.TenantConfig,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Connector")
 		os.Exit(1)
 	}
 	if err := (&controller.ArchiverReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                   tenantClient,
+		Scheme:                   mgr.GetScheme(),
+		Recorder:                 mgr.GetEventRecorder("archiver-controller"),
+		MaxConcurrentReconciles:  managerOptions.Concurrency("archiver"),
+		DefaultPriorityClassName: managerOptions.PriorityClassName("archiver"),
+		DefaultResources:         managerOptions.Resources("archiver"),
+		SyncPriorityClassName:    managerOptions.PriorityClassName("sync"),
+		SyncResources:            managerOptions.Resources("sync"),
+		VolumeStats:              controller.KubeletVolumeStats(clientset),
+		TenantConfig:             managerOptions.TenantConfig,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Archiver")
 		os.Exit(1)
 	}
+	if err := (&controller.ArchiverScheduleReconciler{
+		Client:                      tenantClient,
+		Scheme:                      mgr.GetScheme(),
+		Recorder:                    mgr.GetEventRecorder("archiverschedule-controller"),
+		MaxConcurrentReconciles:     managerOptions.Concurrency("archiverschedule"),
+		CompactionPriorityClassName: managerOptions.PriorityClassName("compaction"),
+		CompactionResources:         managerOptions.Resources("compaction"),
+		SecmasterPriorityClassName:  managerOptions.PriorityClassName("secmaster"),
+		SecmasterResources:          managerOptions.Resources("secmaster"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ArchiverSchedule")
+		os.Exit(1)
+	}
 	if err := (&controller.SignalReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                   tenantClient,
+		Scheme:                   mgr.GetScheme(),
+		Recorder:                 mgr.GetEventRecorder("signal-controller"),
+		MaxConcurrentReconciles:  managerOptions.Concurrency("signal"),
+		DefaultPriorityClassName: managerOptions.PriorityClassName("signal"),
+		DefaultResources:         managerOptions.Resources("signal"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Signal")
 		os.Exit(1)
 	}
 	if err := (&controller.NotifierReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                   tenantClient,
+		Scheme:                   mgr.GetScheme(),
+		Recorder:                 mgr.GetEventRecorder("notifier-controller"),
+		MaxConcurrentReconciles:  managerOptions.Concurrency("notifier"),
+		DefaultPriorityClassName: managerOptions.PriorityClassName("notifier"),
+		DefaultResources:         managerOptions.Resources("notifier"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Notifier")
 		os.Exit(1)
 	}
 	if err := (&controller.SnapReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                   tenantClient,
+		Scheme:                   mgr.GetScheme(),
+		Recorder:                 mgr.GetEventRecorder("snap-controller"),
+		MaxConcurrentReconciles:  managerOptions.Concurrency("snap"),
+		DefaultPriorityClassName: managerOptions.PriorityClassName("snap"),
+		DefaultResources:         managerOptions.Resources("snap"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Snap")
 		os.Exit(1)
 	}
 	if err := (&controller.HarmanReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                   tenantClient,
+		Scheme:                   mgr.GetScheme(),
+		Recorder:                 mgr.GetEventRecorder("harman-controller"),
+		MaxConcurrentReconciles:  managerOptions.Concurrency("harman"),
+		DefaultPriorityClassName: managerOptions.PriorityClassName("harman"),
+		DefaultResources:         managerOptions.Resources("harman"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Harman")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err := webhookv1alpha1.SetupArchiverWebhookWithManager(mgr, imageVersionsConfigMap, managerOptions.TenantConfig); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Archiver")
+			os.Exit(1)
+		}
+		if err := webhookv1alpha1.SetupConnectorWebhookWithManager(mgr, imageVersionsConfigMap, managerOptions.TenantConfig); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Connector")
+			os.Exit(1)
+		}
+		if err := webhookv1alpha1.SetupNotifierWebhookWithManager(mgr, imageVersionsConfigMap, managerOptions.TenantConfig); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Notifier")
+			os.Exit(1)
+		}
+		if err := webhookv1alpha1.SetupHarmanWebhookWithManager(mgr, imageVersionsConfigMap, managerOptions.TenantConfig); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Harman")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,160 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// ssmd-sync uploads an archiver's local data directory to GCS or S3 with per-object
+// checksum verification. It is run by the operator as the Archiver sync Job.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/archivesync"
+)
+
+var log = ctrl.Log.WithName("ssmd-sync")
+
+func main() {
+	var source, remoteType, bucket, prefix, name, credentials string
+	var endpoint, region string
+	var statusConfigMap, namespace string
+	var maxAttempts int
+	var backoff, minAge time.Duration
+	flag.StringVar(&source, "source", "/data/ssmd", "Local directory to sync.")
+	flag.StringVar(&remoteType, "type", "gcs", "Remote storage type: gcs or s3.")
+	flag.StringVar(&bucket, "bucket", "", "Destination bucket.")
+	flag.StringVar(&prefix, "prefix", "", "Object key prefix within the bucket.")
+	flag.StringVar(&name, "name", "sync", "Name recorded in the sync receipt (typically the Archiver name).")
+	flag.StringVar(&credentials, "credentials", "",
+		"Path to a GCP service account key file. Leave empty to use Workload Identity.")
+	flag.StringVar(&endpoint, "endpoint", "",
+		"S3 endpoint override for S3-compatible stores such as MinIO (uses path-style addressing).")
+	flag.StringVar(&region, "region", "us-east-1", "S3 region.")
+	flag.StringVar(&statusConfigMap, "status-configmap", "",
+		"ConfigMap to write sync counts to for the operator. Leave empty to skip.")
+	flag.StringVar(&namespace, "namespace", os.Getenv("POD_NAMESPACE"), "Namespace of the status ConfigMap.")
+	flag.IntVar(&maxAttempts, "max-attempts", 5, "Upload attempts per object before giving up.")
+	flag.DurationVar(&backoff, "backoff", 2*time.Second, "Initial retry backoff, doubled on each attempt.")
+	flag.DurationVar(&minAge, "min-age", 0,
+		"Skip files modified more recently than this (files still being written). 0 syncs everything.")
+	opts := zap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	if bucket == "" {
+		log.Info("--bucket is required")
+		os.Exit(2)
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+
+	if _, err := os.Stat(source); os.IsNotExist(err) {
+		log.Info("No data to sync, skipping", "source", source)
+		return
+	}
+
+	var remote archivesync.Remote
+	switch remoteType {
+	case "gcs":
+		gcs, err := archivesync.NewGCSRemote(ctx, bucket, credentials)
+		if err != nil {
+			log.Error(err, "Failed to create GCS client")
+			os.Exit(1)
+		}
+		remote = gcs
+	case "s3":
+		creds, err := archivesync.S3CredentialsFromEnv()
+		if err != nil {
+			log.Error(err, "Missing S3 credentials")
+			os.Exit(1)
+		}
+		s3, err := archivesync.NewS3Remote(bucket, region, endpoint, creds)
+		if err != nil {
+			log.Error(err, "Failed to create S3 client")
+			os.Exit(1)
+		}
+		remote = s3
+	default:
+		log.Info("--type must be gcs or s3", "type", remoteType)
+		os.Exit(2)
+	}
+
+	syncer := &archivesync.Syncer{
+		Remote:      remote,
+		LocalRoot:   source,
+		Prefix:      prefix,
+		Name:        name,
+		MaxAttempts: maxAttempts,
+		Backoff:     backoff,
+		MinAge:      minAge,
+	}
+
+	log.Info("Starting sync", "source", source, "type", remoteType, "bucket", bucket, "prefix", prefix)
+	receipt, syncErr := syncer.Run(ctx)
+	if receipt != nil {
+		for _, f := range receipt.Files {
+			if f.Action == "failed" {
+				log.Info("File failed to sync", "key", f.Key, "attempts", f.Attempts, "error", f.Error)
+			}
+		}
+		log.Info("Sync finished",
+			"uploaded", receipt.FilesUploaded,
+			"skipped", receipt.FilesSkipped,
+			"failed", receipt.FilesFailed,
+			"bytes", receipt.BytesUploaded)
+
+		if statusConfigMap != "" {
+			reportStatus(ctx, namespace, statusConfigMap, name, receipt)
+		}
+	}
+
+	if syncErr != nil {
+		log.Error(syncErr, "Sync failed")
+		os.Exit(1)
+	}
+}
+
+// reportStatus writes the sync counts to a ConfigMap. Failures are logged but
+// do not fail the sync; the receipt in the bucket is the source of truth.
+func reportStatus(ctx context.Context, namespace, configMap, name string, receipt *archivesync.Receipt) {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		log.Error(err, "Not running in cluster, skipping status report")
+		return
+	}
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		log.Error(err, "Failed to create Kubernetes client, skipping status report")
+		return
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "ssmd-archiver-sync",
+		"app.kubernetes.io/instance":   name,
+		"app.kubernetes.io/managed-by": "ssmd-operator",
+	}
+	if err := archivesync.ReportStatus(ctx, cs, namespace, configMap, labels, receipt); err != nil {
+		log.Error(err, "Failed to write status ConfigMap", "configmap", configMap)
+	}
+}
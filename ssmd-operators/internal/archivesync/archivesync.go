@@ -0,0 +1,323 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package archivesync copies an archiver's local data directory to remote
+// object storage, verifying every object by checksum.
+//
+// Unlike `gsutil rsync`, a sync either verifies every object or fails loudly:
+// each upload is retried until the remote MD5 matches the local file (or the
+// remote verified the upload itself when it cannot report an MD5), and a
+// receipt listing what was uploaded/skipped/failed is written to the bucket.
+package archivesync
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotExist is returned by Remote.Stat when the object does not exist.
+var ErrNotExist = errors.New("object does not exist")
+
+// ReceiptDir is the directory (relative to the remote prefix) receipts are written to.
+const ReceiptDir = ".ssmd-sync"
+
+// ObjectInfo describes a remote object.
+type ObjectInfo struct {
+	Size int64
+	MD5  []byte
+}
+
+// Remote is the object storage backend a Syncer uploads to.
+type Remote interface {
+	// Stat returns object metadata, or ErrNotExist if the object is missing.
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+
+	// Put uploads the object and returns the metadata the remote recorded.
+	Put(ctx context.Context, key string, r io.Reader, size int64) (*ObjectInfo, error)
+}
+
+// Syncer uploads every file under LocalRoot to Remote under Prefix.
+type Syncer struct {
+	Remote Remote
+
+	// LocalRoot is the local directory to sync
+	LocalRoot string
+
+	// Prefix is prepended to every object key (no leading/trailing slash needed)
+	Prefix string
+
+	// Name identifies the sync in receipts (typically the Archiver name)
+	Name string
+
+	// MaxAttempts is the number of upload attempts per object (defaults to 5)
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry; doubled on each attempt (defaults to 1s)
+	Backoff time.Duration
+
+	// MinAge skips files modified more recently than this, so a periodic sync
+	// does not upload files the archiver is still writing (0 syncs everything)
+	MinAge time.Duration
+
+	// now is overridable in tests
+	now func() time.Time
+}
+
+// FileResult is the outcome for a single file.
+type FileResult struct {
+	Key      string `json:"key"`
+	Size     int64  `json:"size"`
+	MD5      string `json:"md5"`
+	Action   string `json:"action"` // uploaded, skipped, failed
+	Attempts int    `json:"attempts,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Receipt summarises a completed sync. It is written to the bucket and
+// reported back to the operator.
+type Receipt struct {
+	Name          string       `json:"name"`
+	Source        string       `json:"source"`
+	Destination   string       `json:"destination"`
+	StartedAt     time.Time    `json:"startedAt"`
+	CompletedAt   time.Time    `json:"completedAt"`
+	FilesUploaded int          `json:"filesUploaded"`
+	FilesSkipped  int          `json:"filesSkipped"`
+	FilesFailed   int          `json:"filesFailed"`
+	BytesUploaded int64        `json:"bytesUploaded"`
+	Files         []FileResult `json:"files"`
+}
+
+// Failed reports whether any object could not be verified.
+func (r *Receipt) Failed() bool {
+	return r.FilesFailed > 0
+}
+
+// Run syncs all files and writes a receipt. It returns the receipt even when
+// some files failed; the error is non-nil if any file failed or the receipt
+// could not be written.
+func (s *Syncer) Run(ctx context.Context) (*Receipt, error) {
+	now := s.now
+	if now == nil {
+		now = time.Now
+	}
+
+	receipt := &Receipt{
+		Name:        s.Name,
+		Source:      s.LocalRoot,
+		Destination: s.Prefix,
+		StartedAt:   now().UTC(),
+	}
+
+	files, err := s.listFiles(now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rel := range files {
+		if err := ctx.Err(); err != nil {
+			return receipt, err
+		}
+		result := s.syncFile(ctx, rel)
+		switch result.Action {
+		case "uploaded":
+			receipt.FilesUploaded++
+			receipt.BytesUploaded += result.Size
+		case "skipped":
+			receipt.FilesSkipped++
+		default:
+			receipt.FilesFailed++
+		}
+		receipt.Files = append(receipt.Files, result)
+	}
+
+	receipt.CompletedAt = now().UTC()
+
+	if err := s.writeReceipt(ctx, receipt); err != nil {
+		return receipt, fmt.Errorf("failed to write sync receipt: %w", err)
+	}
+
+	if receipt.Failed() {
+		return receipt, fmt.Errorf("%d of %d files failed to sync", receipt.FilesFailed, len(files))
+	}
+	return receipt, nil
+}
+
+// listFiles returns all regular files under LocalRoot as slash-separated
+// relative paths, sorted. Hidden files (e.g. the .sync-ready marker) and files
+// younger than MinAge are skipped.
+func (s *Syncer) listFiles(now time.Time) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(s.LocalRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != s.LocalRoot {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if s.MinAge > 0 {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if now.Sub(info.ModTime()) < s.MinAge {
+				return nil
+			}
+		}
+		rel, err := filepath.Rel(s.LocalRoot, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", s.LocalRoot, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// syncFile uploads a single file unless an identical object already exists.
+func (s *Syncer) syncFile(ctx context.Context, rel string) FileResult {
+	key := s.key(rel)
+	result := FileResult{Key: key, Action: "failed"}
+
+	localPath := filepath.Join(s.LocalRoot, filepath.FromSlash(rel))
+	sum, size, err := fileMD5(localPath)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Size = size
+	result.MD5 = fmt.Sprintf("%x", sum)
+
+	// Skip objects that are already present and identical
+	if info, err := s.Remote.Stat(ctx, key); err == nil && info.Size == size && bytes.Equal(info.MD5, sum) {
+		result.Action = "skipped"
+		return result
+	}
+
+	maxAttempts := s.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	backoff := s.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		result.Attempts = attempt
+		err = s.upload(ctx, localPath, key, size, sum)
+		if err == nil {
+			result.Action = "uploaded"
+			result.Error = ""
+			return result
+		}
+		result.Error = err.Error()
+		if attempt < maxAttempts {
+			select {
+			case <-ctx.Done():
+				result.Error = ctx.Err().Error()
+				return result
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+	}
+	return result
+}
+
+// upload puts the file and verifies the remote checksum matches. A nil remote
+// MD5 means the remote cannot report one and checked the upload itself.
+func (s *Syncer) upload(ctx context.Context, localPath, key string, size int64, sum []byte) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := s.Remote.Put(ctx, key, f, size)
+	if err != nil {
+		return err
+	}
+	if info.Size != size {
+		return fmt.Errorf("size mismatch after upload: local %d, remote %d", size, info.Size)
+	}
+	if info.MD5 != nil && !bytes.Equal(info.MD5, sum) {
+		return fmt.Errorf("checksum mismatch after upload: local %x, remote %x", sum, info.MD5)
+	}
+	return nil
+}
+
+// writeReceipt uploads the receipt JSON under ReceiptDir.
+func (s *Syncer) writeReceipt(ctx context.Context, receipt *Receipt) error {
+	data, err := json.MarshalIndent(receipt, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := s.Name
+	if name == "" {
+		name = "sync"
+	}
+	key := s.key(path.Join(ReceiptDir, fmt.Sprintf("%s-%s.json", name, receipt.CompletedAt.Format("20060102T150405Z"))))
+	_, err = s.Remote.Put(ctx, key, bytes.NewReader(data), int64(len(data)))
+	return err
+}
+
+// key builds the object key for a relative path.
+func (s *Syncer) key(rel string) string {
+	prefix := strings.Trim(s.Prefix, "/")
+	if prefix == "" {
+		return rel
+	}
+	return prefix + "/" + rel
+}
+
+// fileMD5 returns the MD5 digest and size of a file.
+func fileMD5(p string) ([]byte, int64, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := md5.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return nil, 0, err
+	}
+	return h.Sum(nil), n, nil
+}
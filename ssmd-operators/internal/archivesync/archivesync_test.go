@@ -0,0 +1,265 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archivesync
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRemote is an in-memory Remote. failPuts makes the next N puts of a key fail;
+// corrupt makes puts of a key record a wrong checksum.
+type fakeRemote struct {
+	objects  map[string][]byte
+	puts     map[string]int
+	failPuts map[string]int
+	corrupt  map[string]bool
+}
+
+func newFakeRemote() *fakeRemote {
+	return &fakeRemote{
+		objects:  map[string][]byte{},
+		puts:     map[string]int{},
+		failPuts: map[string]int{},
+		corrupt:  map[string]bool{},
+	}
+}
+
+func (f *fakeRemote) Stat(_ context.Context, key string) (*ObjectInfo, error) {
+	data, ok := f.objects[key]
+	if !ok {
+		return nil, ErrNotExist
+	}
+	sum := md5.Sum(data)
+	return &ObjectInfo{Size: int64(len(data)), MD5: sum[:]}, nil
+}
+
+func (f *fakeRemote) Put(_ context.Context, key string, r io.Reader, _ int64) (*ObjectInfo, error) {
+	f.puts[key]++
+	if f.failPuts[key] > 0 {
+		f.failPuts[key]--
+		return nil, errors.New("transient error")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if f.corrupt[key] {
+		data = append(data, 'x')
+	}
+	f.objects[key] = data
+	sum := md5.Sum(data)
+	return &ObjectInfo{Size: int64(len(data)), MD5: sum[:]}, nil
+}
+
+func (f *fakeRemote) receiptKeys() []string {
+	var keys []string
+	for k := range f.objects {
+		if strings.Contains(k, ReceiptDir+"/") {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func newTestSyncer(remote Remote, root string) *Syncer {
+	return &Syncer{
+		Remote:    remote,
+		LocalRoot: root,
+		Prefix:    "kalshi",
+		Name:      "archiver-test",
+		Backoff:   time.Millisecond,
+		now:       func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+}
+
+// --- TestRun ---
+
+func TestRun_UploadsAllFiles(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"2026-01-02/trades-0300.jsonl.gz": "a",
+		"2026-01-02/trades-0400.jsonl.gz": "bb",
+		".sync-ready":                     "",
+	})
+	remote := newFakeRemote()
+
+	receipt, err := newTestSyncer(remote, root).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receipt.FilesUploaded != 2 || receipt.FilesSkipped != 0 || receipt.FilesFailed != 0 {
+		t.Errorf("unexpected counts: %+v", receipt)
+	}
+	if receipt.BytesUploaded != 3 {
+		t.Errorf("expected 3 bytes uploaded, got %d", receipt.BytesUploaded)
+	}
+	if _, ok := remote.objects["kalshi/2026-01-02/trades-0300.jsonl.gz"]; !ok {
+		t.Error("expected object under prefix")
+	}
+	if _, ok := remote.objects["kalshi/.sync-ready"]; ok {
+		t.Error("hidden files should not be synced")
+	}
+	keys := remote.receiptKeys()
+	if len(keys) != 1 || keys[0] != "kalshi/.ssmd-sync/archiver-test-20260102T030405Z.json" {
+		t.Errorf("unexpected receipt keys: %v", keys)
+	}
+}
+
+func TestRun_MinAgeSkipsRecentFiles(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"2026-01-02/trades-0300.jsonl.gz": "closed",
+		"2026-01-02/trades-0400.jsonl.gz": "open",
+	})
+	syncer := newTestSyncer(newFakeRemote(), root)
+	syncer.MinAge = 15 * time.Minute
+	now := syncer.now()
+	if err := os.Chtimes(filepath.Join(root, "2026-01-02", "trades-0300.jsonl.gz"), now, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(root, "2026-01-02", "trades-0400.jsonl.gz"), now, now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	receipt, err := syncer.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receipt.FilesUploaded != 1 || receipt.Files[0].Key != "kalshi/2026-01-02/trades-0300.jsonl.gz" {
+		t.Errorf("expected only the closed file uploaded, got %+v", receipt.Files)
+	}
+}
+
+func TestRun_SkipsIdenticalObjects(t *testing.T) {
+	root := writeFiles(t, map[string]string{"a.jsonl.gz": "same", "b.jsonl.gz": "new"})
+	remote := newFakeRemote()
+	remote.objects["kalshi/a.jsonl.gz"] = []byte("same")
+	remote.objects["kalshi/b.jsonl.gz"] = []byte("old")
+
+	receipt, err := newTestSyncer(remote, root).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receipt.FilesSkipped != 1 || receipt.FilesUploaded != 1 {
+		t.Errorf("expected 1 skipped and 1 uploaded, got %+v", receipt)
+	}
+	if remote.puts["kalshi/a.jsonl.gz"] != 0 {
+		t.Error("identical object should not be re-uploaded")
+	}
+	if string(remote.objects["kalshi/b.jsonl.gz"]) != "new" {
+		t.Error("changed object should be overwritten")
+	}
+}
+
+func TestRun_RetriesTransientFailures(t *testing.T) {
+	root := writeFiles(t, map[string]string{"a.jsonl.gz": "data"})
+	remote := newFakeRemote()
+	remote.failPuts["kalshi/a.jsonl.gz"] = 2
+
+	receipt, err := newTestSyncer(remote, root).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receipt.Files[0].Attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", receipt.Files[0].Attempts)
+	}
+}
+
+func TestRun_FailsOnChecksumMismatch(t *testing.T) {
+	root := writeFiles(t, map[string]string{"a.jsonl.gz": "data", "b.jsonl.gz": "ok"})
+	remote := newFakeRemote()
+	remote.corrupt["kalshi/a.jsonl.gz"] = true
+
+	s := newTestSyncer(remote, root)
+	s.MaxAttempts = 2
+	receipt, err := s.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected error when an object cannot be verified")
+	}
+	if receipt.FilesFailed != 1 || receipt.FilesUploaded != 1 {
+		t.Errorf("expected 1 failed and 1 uploaded, got %+v", receipt)
+	}
+	if remote.puts["kalshi/a.jsonl.gz"] != 2 {
+		t.Errorf("expected 2 attempts, got %d", remote.puts["kalshi/a.jsonl.gz"])
+	}
+	if len(remote.receiptKeys()) != 1 {
+		t.Error("receipt should be written even when files fail")
+	}
+}
+
+func TestRun_RerunIsIdempotent(t *testing.T) {
+	root := writeFiles(t, map[string]string{"a.jsonl.gz": "a", "b.jsonl.gz": "b"})
+	remote := newFakeRemote()
+
+	if _, err := newTestSyncer(remote, root).Run(context.Background()); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	receipt, err := newTestSyncer(remote, root).Run(context.Background())
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if receipt.FilesSkipped != 2 || receipt.FilesUploaded != 0 {
+		t.Errorf("expected all files skipped on rerun, got %+v", receipt)
+	}
+}
+
+// --- TestParseStatusData ---
+
+func TestParseStatusData_RoundTrip(t *testing.T) {
+	receipt := &Receipt{
+		CompletedAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		FilesUploaded: 3,
+		FilesSkipped:  4,
+		FilesFailed:   1,
+		BytesUploaded: 1234,
+	}
+	status := ParseStatusData(StatusData(receipt))
+	if status == nil {
+		t.Fatal("expected status")
+	}
+	if !status.CompletedAt.Equal(receipt.CompletedAt) || status.FilesUploaded != 3 ||
+		status.FilesSkipped != 4 || status.FilesFailed != 1 || status.BytesUploaded != 1234 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestParseStatusData_Empty(t *testing.T) {
+	if ParseStatusData(map[string]string{}) != nil {
+		t.Error("expected nil for empty data")
+	}
+}
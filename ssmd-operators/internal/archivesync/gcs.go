@@ -0,0 +1,197 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archivesync
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+const (
+	gcsScope        = "https://www.googleapis.com/auth/devstorage.read_write"
+	gcsAPIBase      = "https://storage.googleapis.com"
+	metadataToken   = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	defaultTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+// GCSRemote uploads objects to a GCS bucket via the JSON API.
+type GCSRemote struct {
+	Bucket  string
+	client  *http.Client
+	baseURL string
+}
+
+// NewGCSRemote creates a GCS remote. If keyFile is set, the service account
+// key is used; otherwise tokens come from the GKE metadata server (Workload Identity).
+func NewGCSRemote(ctx context.Context, bucket, keyFile string) (*GCSRemote, error) {
+	var ts oauth2.TokenSource
+	if keyFile != "" {
+		cfg, err := jwtConfigFromKeyFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		ts = cfg.TokenSource(ctx)
+	} else {
+		ts = oauth2.ReuseTokenSource(nil, &metadataTokenSource{ctx: ctx})
+	}
+
+	return &GCSRemote{
+		Bucket:  bucket,
+		client:  &http.Client{Transport: &oauth2.Transport{Source: ts}, Timeout: 10 * time.Minute},
+		baseURL: gcsAPIBase,
+	}, nil
+}
+
+// gcsObject is the subset of the GCS object resource we use.
+type gcsObject struct {
+	Size    string `json:"size"`
+	MD5Hash string `json:"md5Hash"`
+}
+
+func (o *gcsObject) info() (*ObjectInfo, error) {
+	size, err := strconv.ParseInt(o.Size, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object size %q: %w", o.Size, err)
+	}
+	// Composite objects have no md5Hash; treat as unknown so they are re-uploaded
+	sum, _ := base64.StdEncoding.DecodeString(o.MD5Hash)
+	return &ObjectInfo{Size: size, MD5: sum}, nil
+}
+
+// Stat returns object metadata, or ErrNotExist.
+func (g *GCSRemote) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?fields=size,md5Hash", g.baseURL, url.PathEscape(g.Bucket), url.PathEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return g.do(req)
+}
+
+// Put uploads the object with a single media upload. GCS objects are written
+// atomically, so a failed upload never leaves a partial object behind.
+func (g *GCSRemote) Put(ctx context.Context, key string, r io.Reader, size int64) (*ObjectInfo, error) {
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&fields=size,md5Hash&name=%s",
+		g.baseURL, url.PathEscape(g.Bucket), url.QueryEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, r)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	return g.do(req)
+}
+
+func (g *GCSRemote) do(req *http.Request) (*ObjectInfo, error) {
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotExist
+	}
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("gcs %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, body)
+	}
+
+	var obj gcsObject
+	if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+		return nil, fmt.Errorf("failed to decode gcs response: %w", err)
+	}
+	return obj.info()
+}
+
+// serviceAccountKey is the subset of a GCP service account key file we use.
+type serviceAccountKey struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// jwtConfigFromKeyFile builds a JWT token config from a service account key file.
+func jwtConfigFromKeyFile(keyFile string) (*jwt.Config, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	var key serviceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", keyFile, err)
+	}
+	tokenURL := key.TokenURI
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+	return &jwt.Config{
+		Email:        key.ClientEmail,
+		PrivateKey:   []byte(key.PrivateKey),
+		PrivateKeyID: key.PrivateKeyID,
+		Scopes:       []string{gcsScope},
+		TokenURL:     tokenURL,
+	}, nil
+}
+
+// metadataTokenSource fetches access tokens from the GKE metadata server.
+type metadataTokenSource struct {
+	ctx context.Context
+}
+
+func (m *metadataTokenSource) Token() (*oauth2.Token, error) {
+	req, err := http.NewRequestWithContext(m.ctx, http.MethodGet, metadataToken, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("metadata server unavailable: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata server returned %s", resp.Status)
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+		TokenType   string `json:"token_type"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata token: %w", err)
+	}
+	return &oauth2.Token{
+		AccessToken: tok.AccessToken,
+		TokenType:   tok.TokenType,
+		Expiry:      time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second),
+	}, nil
+}
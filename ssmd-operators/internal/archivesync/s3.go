@@ -0,0 +1,322 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archivesync
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Region = "us-east-1"
+
+	// s3UnsignedPayload lets uploads stream without a SHA-256 of the body;
+	// integrity is checked by S3 against the Content-MD5 header instead
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3EmptyPayload    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	// s3MD5Metadata holds the hex MD5 sent as Content-MD5, so Stat can report
+	// it for objects whose ETag is not their MD5 (SSE-KMS, SSE-C)
+	s3MD5Metadata = "X-Amz-Meta-Md5"
+)
+
+// S3Credentials are static AWS credentials.
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3CredentialsFromEnv reads AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// (optionally) AWS_SESSION_TOKEN.
+func S3CredentialsFromEnv() (S3Credentials, error) {
+	creds := S3Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return creds, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	return creds, nil
+}
+
+// S3Remote uploads objects to an S3 (or S3-compatible, e.g. MinIO) bucket
+// using SigV4-signed REST requests.
+type S3Remote struct {
+	Bucket string
+	Region string
+
+	creds  S3Credentials
+	client *http.Client
+
+	// baseURL is the endpoint; with pathStyle the bucket is the first path
+	// segment, otherwise it is the host's first label
+	baseURL   *url.URL
+	pathStyle bool
+
+	// now is overridable in tests
+	now func() time.Time
+}
+
+// NewS3Remote creates an S3 remote. With an empty endpoint it targets AWS using
+// virtual-hosted addressing; a custom endpoint (e.g. http://minio.minio:9000)
+// uses path-style addressing.
+func NewS3Remote(bucket, region, endpoint string, creds S3Credentials) (*S3Remote, error) {
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	pathStyle := endpoint != ""
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, region)
+	}
+	base, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint %q: %w", endpoint, err)
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q: must be an absolute URL", endpoint)
+	}
+
+	return &S3Remote{
+		Bucket:    bucket,
+		Region:    region,
+		creds:     creds,
+		client:    &http.Client{Timeout: 10 * time.Minute},
+		baseURL:   base,
+		pathStyle: pathStyle,
+	}, nil
+}
+
+// objectURL returns the URL of an object.
+func (s *S3Remote) objectURL(key string) *url.URL {
+	u := *s.baseURL
+	objectPath := "/" + key
+	if s.pathStyle {
+		objectPath = "/" + s.Bucket + objectPath
+	}
+	u.Path = s.baseURL.Path + objectPath
+	u.RawPath = s.baseURL.Path + awsURIEncode(objectPath, false)
+	return &u
+}
+
+// Stat returns object metadata, or ErrNotExist.
+func (s *S3Remote) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, s3EmptyPayload)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotExist
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("s3 HEAD %s: %s", key, resp.Status)
+	}
+
+	size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object size for %s: %w", key, err)
+	}
+	return &ObjectInfo{Size: size, MD5: objectMD5(resp.Header)}, nil
+}
+
+// Put uploads the object with a single PUT. S3 objects are written atomically,
+// so a failed upload never leaves a partial object behind. Seekable readers
+// (files, receipts) are hashed first and sent with Content-MD5, so S3 rejects
+// a body that arrives corrupted and the digest no longer depends on the ETag.
+// The digest is also stored as object metadata for later Stats.
+func (s *S3Remote) Put(ctx context.Context, key string, r io.Reader, size int64) (*ObjectInfo, error) {
+	var sum []byte
+	if rs, ok := r.(io.ReadSeeker); ok {
+		h := md5.New()
+		if _, err := io.Copy(h, rs); err != nil {
+			return nil, err
+		}
+		if _, err := rs.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		sum = h.Sum(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), r)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if sum != nil {
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum))
+		req.Header.Set(s3MD5Metadata, hex.EncodeToString(sum))
+	}
+	s.sign(req, s3UnsignedPayload)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("s3 PUT %s: %s: %s", key, resp.Status, body)
+	}
+	if sum != nil {
+		// S3 accepted the body against Content-MD5
+		return &ObjectInfo{Size: size, MD5: sum}, nil
+	}
+	return &ObjectInfo{Size: size, MD5: objectMD5(resp.Header)}, nil
+}
+
+// objectMD5 is the object's MD5 from its response headers: the MD5 metadata
+// written by Put, else the ETag. Nil when neither is one: the ETag of an
+// SSE-KMS or SSE-C encrypted object is not its MD5 even though it looks like
+// one.
+func objectMD5(h http.Header) []byte {
+	if sum, err := hex.DecodeString(h.Get(s3MD5Metadata)); err == nil && len(sum) == md5.Size {
+		return sum
+	}
+	if strings.HasPrefix(h.Get("X-Amz-Server-Side-Encryption"), "aws:kms") ||
+		h.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm") != "" {
+		return nil
+	}
+	return etagMD5(h.Get("ETag"))
+}
+
+// etagMD5 decodes a single-part upload ETag (the hex MD5 of the object).
+// Multipart ETags are not MD5s and decode to nil.
+func etagMD5(etag string) []byte {
+	etag = strings.Trim(etag, `"`)
+	if len(etag) != 32 {
+		return nil
+	}
+	sum, err := hex.DecodeString(etag)
+	if err != nil {
+		return nil
+	}
+	return sum
+}
+
+// sign adds AWS Signature Version 4 headers to the request.
+func (s *S3Remote) sign(req *http.Request, payloadHash string) {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	t := now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.creds.SessionToken)
+	}
+
+	// Canonical headers: host plus every header set so far, lowercased and sorted
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.Region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.creds.SecretAccessKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// awsURIEncode percent-encodes everything except unreserved characters, as
+// SigV4 requires. Slashes are kept unless encodeSlash is set.
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
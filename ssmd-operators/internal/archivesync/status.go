@@ -0,0 +1,102 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archivesync
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Keys of the status ConfigMap written by the sync tool and read by the operator.
+const (
+	StatusKeyCompletedAt   = "completedAt"
+	StatusKeyFilesUploaded = "filesUploaded"
+	StatusKeyFilesSkipped  = "filesSkipped"
+	StatusKeyFilesFailed   = "filesFailed"
+	StatusKeyBytesUploaded = "bytesUploaded"
+)
+
+// StatusData renders a receipt as ConfigMap data.
+func StatusData(receipt *Receipt) map[string]string {
+	return map[string]string{
+		StatusKeyCompletedAt:   receipt.CompletedAt.Format(time.RFC3339),
+		StatusKeyFilesUploaded: strconv.Itoa(receipt.FilesUploaded),
+		StatusKeyFilesSkipped:  strconv.Itoa(receipt.FilesSkipped),
+		StatusKeyFilesFailed:   strconv.Itoa(receipt.FilesFailed),
+		StatusKeyBytesUploaded: strconv.FormatInt(receipt.BytesUploaded, 10),
+	}
+}
+
+// SyncStatus is the parsed content of a status ConfigMap.
+type SyncStatus struct {
+	CompletedAt   time.Time
+	FilesUploaded int32
+	FilesSkipped  int32
+	FilesFailed   int32
+	BytesUploaded int64
+}
+
+// ParseStatusData parses ConfigMap data written by ReportStatus.
+// Returns nil if the data has no completion timestamp.
+func ParseStatusData(data map[string]string) *SyncStatus {
+	completedAt, err := time.Parse(time.RFC3339, data[StatusKeyCompletedAt])
+	if err != nil {
+		return nil
+	}
+	atoi32 := func(key string) int32 {
+		v, _ := strconv.ParseInt(data[key], 10, 32)
+		return int32(v)
+	}
+	bytesUploaded, _ := strconv.ParseInt(data[StatusKeyBytesUploaded], 10, 64)
+	return &SyncStatus{
+		CompletedAt:   completedAt,
+		FilesUploaded: atoi32(StatusKeyFilesUploaded),
+		FilesSkipped:  atoi32(StatusKeyFilesSkipped),
+		FilesFailed:   atoi32(StatusKeyFilesFailed),
+		BytesUploaded: bytesUploaded,
+	}
+}
+
+// ReportStatus creates or updates the named ConfigMap with the receipt counts.
+func ReportStatus(ctx context.Context, cs kubernetes.Interface, namespace, name string, labels map[string]string, receipt *Receipt) error {
+	configMaps := cs.CoreV1().ConfigMaps(namespace)
+
+	existing, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    labels,
+			},
+			Data: StatusData(receipt),
+		}, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	existing.Data = StatusData(receipt)
+	_, err = configMaps.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+func TestConstructConfigMap_DedupKeys(t *testing.T) {
+	r := &ArchiverReconciler{}
+	archiver := &ssmdv1alpha1.Archiver{
+		ObjectMeta: metav1.ObjectMeta{Name: "kraken", Namespace: "ssmd"},
+		Spec: ssmdv1alpha1.ArchiverSpec{
+			Feed: "kraken-spot",
+			DedupKeys: map[string][]string{
+				"trade": {"data[].symbol", "data[].trade_id"},
+			},
+		},
+	}
+
+	got := r.constructConfigMap(archiver).Data["archiver.yaml"]
+	want := "\ndedup_keys:\n  trade:\n    - \"data[].symbol\"\n    - \"data[].trade_id\"\n"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("archiver.yaml does not end with the dedup keys:\n%s", got)
+	}
+}
+
+func TestConstructConfigMap_NoDedupKeys(t *testing.T) {
+	r := &ArchiverReconciler{}
+	archiver := &ssmdv1alpha1.Archiver{
+		ObjectMeta: metav1.ObjectMeta{Name: "kalshi", Namespace: "ssmd"},
+	}
+
+	if got := r.constructConfigMap(archiver).Data["archiver.yaml"]; strings.Contains(got, "dedup_keys") {
+		t.Errorf("archiver.yaml has dedup_keys without spec.dedupKeys:\n%s", got)
+	}
+}
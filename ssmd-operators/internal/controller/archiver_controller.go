@@ -20,12 +20,15 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -33,32 +36,77 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/archivesync"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/feedconfig"
 )
 
 const (
 	archiverFinalizer = "ssmd.ssmd.io/archiver-finalizer"
+	defaultSyncImage  = "ghcr.io/aaronwald/ssmd-sync:latest"
+
+	// archiverMetricsPort is where the archiver serves /metrics
+	archiverMetricsPort = 8080
+
+	// defaultFillingThresholdPercent is used when storage.local.fillingThresholdPercent is unset
+	defaultFillingThresholdPercent = 80
 )
 
 // ArchiverReconciler reconciles a Archiver object
 type ArchiverReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events for reconcile actions (skipped if nil)
+	Recorder events.EventRecorder
+
+	// MaxConcurrentReconciles is the number of parallel reconciles (1 if unset)
+	MaxConcurrentReconciles int
+
+	// DefaultPriorityClassName is used for pods whose CR does not set priorityClassName
+	DefaultPriorityClassName string
+
+	// DefaultResources are used for pods whose CR does not set resources
+	DefaultResources *corev1.ResourceRequirements
+
+	// SyncPriorityClassName is used for final sync Jobs (defaults to the archiver's priority class)
+	SyncPriorityClassName string
+
+	// SyncResources are the sync container's resources (unset if nil)
+	SyncResources *corev1.ResourceRequirements
+
+	// VolumeStats reports PVC usage for the StorageFilling condition (skipped if nil)
+	VolumeStats VolumeStatsFunc
+
+	// TenantConfig names the TenantConfig whose tenant is recorded on
+	// provisioned streams (skipped if empty)
+	TenantConfig string
+
+	scrape metricsScraper
+
+	// dialJetStream connects to NATS for stream provisioning (defaults to dialJetStream; overridden in tests)
+	dialJetStream jetStreamDialer
 }
 
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=archivers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=archivers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=archivers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes/proxy,verbs=get
 
 // Reconcile moves the cluster state toward the desired state for an Archiver
 func (r *ArchiverReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -95,6 +143,9 @@ func (r *ArchiverReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
+	// Provision source streams and consumers if requested
+	r.provisionStreams(ctx, archiver)
+
 	// Reconcile ConfigMap
 	if _, err := r.reconcileConfigMap(ctx, archiver); err != nil {
 		return ctrl.Result{}, err
@@ -106,12 +157,18 @@ func (r *ArchiverReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return result, err
 	}
 
+	// Reconcile the periodic sync CronJob
+	if err := r.reconcileSyncCronJob(ctx, archiver); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Update status
 	if err := r.updateStatus(ctx, archiver); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	// Requeue to refresh progress from the archiver's metrics
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 }
 
 // reconcileDelete handles cleanup when the Archiver is deleted
@@ -121,12 +178,27 @@ func (r *ArchiverReconciler) reconcileDelete(ctx context.Context, archiver *ssmd
 	if controllerutil.ContainsFinalizer(archiver, archiverFinalizer) {
 		log.Info("Cleaning up Archiver resources", "name", archiver.Name)
 
+		// Step 0: Delete the periodic sync CronJob and its Jobs so none holds the
+		// PVC or races the final sync
+		cronJob := &batchv1.CronJob{}
+		if err := r.Get(ctx, types.NamespacedName{Name: r.syncCronJobName(archiver), Namespace: archiver.Namespace}, cronJob); err == nil {
+			if cronJob.DeletionTimestamp.IsZero() {
+				if err := recordChildEvent(r.Recorder, archiver, eventActionDelete, "CronJob", cronJob.Name, r.Delete(ctx, cronJob, client.PropagationPolicy(metav1.DeletePropagationForeground))); err != nil && !errors.IsNotFound(err) {
+					return ctrl.Result{}, err
+				}
+			}
+			log.Info("Waiting for sync CronJob to be deleted", "name", cronJob.Name)
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		} else if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
 		// Step 1: Delete the Deployment FIRST (before creating sync job)
 		// This ensures the PVC is released before the sync job tries to mount it
 		deploymentName := r.deploymentName(archiver)
 		deployment := &appsv1.Deployment{}
 		if err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: archiver.Namespace}, deployment); err == nil {
-			if err := r.Delete(ctx, deployment); err != nil && !errors.IsNotFound(err) {
+			if err := recordChildEvent(r.Recorder, archiver, eventActionDelete, "Deployment", deploymentName, r.Delete(ctx, deployment)); err != nil && !errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
 			log.Info("Deleted Deployment, waiting for pods to terminate", "name", deploymentName)
@@ -154,17 +226,16 @@ func (r *ArchiverReconciler) reconcileDelete(ctx context.Context, archiver *ssmd
 			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 		}
 
-		// Step 3: Create final sync job if sync is enabled and onDelete == "final"
-		// Now safe to create since PVC is released
+		// Step 3: Run the final sync if sync is enabled and onDelete == "final",
+		// holding the finalizer until it finishes so no data is left behind
 		if archiver.Spec.Sync != nil && archiver.Spec.Sync.Enabled && archiver.Spec.Sync.OnDelete == "final" {
 			if archiver.Spec.Storage != nil && archiver.Spec.Storage.Remote != nil && archiver.Spec.Storage.Remote.Bucket != "" {
-				log.Info("Creating final sync job")
-				job := r.constructSyncJob(archiver)
-				if err := r.Create(ctx, job); err != nil && !errors.IsAlreadyExists(err) {
-					log.Error(err, "Failed to create final sync job")
-					// Don't block deletion, just log the error
-				} else {
-					log.Info("Final sync job created", "job", job.Name)
+				done, err := r.reconcileFinalSync(ctx, archiver)
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+				if !done {
+					return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 				}
 			}
 		}
@@ -181,6 +252,80 @@ func (r *ArchiverReconciler) reconcileDelete(ctx context.Context, archiver *ssmd
 	return ctrl.Result{}, nil
 }
 
+// reconcileFinalSync creates the final sync Job and reports whether deletion can
+// proceed: the Job completed, or sync.finalSyncTimeout has passed since the
+// Archiver was deleted. A Job that fails before then is deleted and recreated
+// on the next reconcile. Progress is reported through the FinalSyncing condition.
+func (r *ArchiverReconciler) reconcileFinalSync(ctx context.Context, archiver *ssmdv1alpha1.Archiver) (bool, error) {
+	log := logf.FromContext(ctx)
+
+	desired := r.constructSyncJob(archiver)
+	condition := metav1.Condition{
+		Type:               "FinalSyncing",
+		Status:             metav1.ConditionTrue,
+		Reason:             "FinalSyncRunning",
+		Message:            fmt.Sprintf("Waiting for final sync Job %s", desired.Name),
+		LastTransitionTime: metav1.Now(),
+	}
+	timeout := r.finalSyncTimeout(archiver)
+	timedOut := time.Since(archiver.DeletionTimestamp.Time) > timeout
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: archiver.Namespace}, job)
+	switch {
+	case err != nil && !errors.IsNotFound(err):
+		return false, err
+	case err == nil && jobFinished(job, batchv1.JobComplete):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "FinalSyncComplete"
+		condition.Message = fmt.Sprintf("Final sync Job %s completed", job.Name)
+		recordEvent(r.Recorder, archiver, corev1.EventTypeNormal, condition.Reason, eventActionDelete, condition.Message)
+	case err == nil && jobFinished(job, batchv1.JobFailed) && !timedOut:
+		if job.DeletionTimestamp.IsZero() {
+			log.Info("Final sync job failed, retrying", "job", job.Name)
+			if err := recordChildEvent(r.Recorder, archiver, eventActionDelete, "Job", job.Name, r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))); err != nil && !errors.IsNotFound(err) {
+				return false, err
+			}
+			recordEvent(r.Recorder, archiver, corev1.EventTypeWarning, "FinalSyncFailed", eventActionDelete,
+				fmt.Sprintf("Final sync Job %s failed, retrying until %s", job.Name, archiver.DeletionTimestamp.Add(timeout).Format(time.RFC3339)))
+		}
+		condition.Reason = "FinalSyncRetrying"
+		condition.Message = fmt.Sprintf("Final sync Job %s failed, retrying", job.Name)
+	case timedOut:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "FinalSyncTimedOut"
+		condition.Message = fmt.Sprintf("Final sync did not finish within %s, removing finalizer", timeout)
+		recordEvent(r.Recorder, archiver, corev1.EventTypeWarning, condition.Reason, eventActionDelete, condition.Message)
+	case errors.IsNotFound(err):
+		log.Info("Creating final sync job", "job", desired.Name)
+		if err := recordChildEvent(r.Recorder, archiver, eventActionCreate, "Job", desired.Name, r.Create(ctx, desired)); err != nil && !errors.IsAlreadyExists(err) {
+			return false, err
+		}
+		condition.Reason = "FinalSyncStarted"
+		condition.Message = fmt.Sprintf("Created final sync Job %s", desired.Name)
+	}
+
+	done := condition.Status == metav1.ConditionFalse
+	if done {
+		log.Info("Final sync finished", "reason", condition.Reason)
+	} else {
+		archiver.Status.Phase = ssmdv1alpha1.ArchiverPhaseSyncing
+	}
+	meta.SetStatusCondition(&archiver.Status.Conditions, condition)
+	if err := r.Status().Update(ctx, archiver); err != nil {
+		return false, err
+	}
+	return done, nil
+}
+
+// finalSyncTimeout returns sync.finalSyncTimeout, defaulting to 30 minutes
+func (r *ArchiverReconciler) finalSyncTimeout(archiver *ssmdv1alpha1.Archiver) time.Duration {
+	if archiver.Spec.Sync != nil && archiver.Spec.Sync.FinalSyncTimeout != nil {
+		return archiver.Spec.Sync.FinalSyncTimeout.Duration
+	}
+	return 30 * time.Minute
+}
+
 // reconcilePVC ensures the PVC exists for local storage
 func (r *ArchiverReconciler) reconcilePVC(ctx context.Context, archiver *ssmdv1alpha1.Archiver) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
@@ -200,7 +345,7 @@ func (r *ArchiverReconciler) reconcilePVC(ctx context.Context, archiver *ssmdv1a
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating PVC", "name", local.PVCName)
-		if err := r.Create(ctx, pvc); err != nil {
+		if err := recordChildEvent(r.Recorder, archiver, eventActionCreate, "PersistentVolumeClaim", local.PVCName, r.Create(ctx, pvc)); err != nil {
 			return ctrl.Result{}, err
 		}
 	} else if err != nil {
@@ -267,7 +412,7 @@ func (r *ArchiverReconciler) reconcileConfigMap(ctx context.Context, archiver *s
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating ConfigMap", "name", configMapName)
-		if err := r.Create(ctx, desiredConfigMap); err != nil {
+		if err := recordChildEvent(r.Recorder, archiver, eventActionCreate, "ConfigMap", configMapName, r.Create(ctx, desiredConfigMap)); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -279,7 +424,7 @@ func (r *ArchiverReconciler) reconcileConfigMap(ctx context.Context, archiver *s
 	if configMap.Data["archiver.yaml"] != desiredConfigMap.Data["archiver.yaml"] {
 		configMap.Data = desiredConfigMap.Data
 		log.Info("Updating ConfigMap", "name", configMapName)
-		if err := r.Update(ctx, configMap); err != nil {
+		if err := recordChildEvent(r.Recorder, archiver, eventActionUpdate, "ConfigMap", configMapName, r.Update(ctx, configMap)); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
@@ -380,6 +525,22 @@ func (r *ArchiverReconciler) constructConfigMap(archiver *ssmdv1alpha1.Archiver)
 		archiverYAML.WriteString("  interval: 15m\n")
 	}
 
+	// Dedup keys per message type, recorded in each day's manifest
+	if len(archiver.Spec.DedupKeys) > 0 {
+		archiverYAML.WriteString("\ndedup_keys:\n")
+		msgTypes := make([]string, 0, len(archiver.Spec.DedupKeys))
+		for msgType := range archiver.Spec.DedupKeys {
+			msgTypes = append(msgTypes, msgType)
+		}
+		sort.Strings(msgTypes)
+		for _, msgType := range msgTypes {
+			archiverYAML.WriteString(fmt.Sprintf("  %s:\n", msgType))
+			for _, field := range archiver.Spec.DedupKeys[msgType] {
+				archiverYAML.WriteString(fmt.Sprintf("    - %q\n", field))
+			}
+		}
+	}
+
 	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.configMapName(archiver),
@@ -401,18 +562,26 @@ func (r *ArchiverReconciler) configMapName(archiver *ssmdv1alpha1.Archiver) stri
 func (r *ArchiverReconciler) reconcileDeployment(ctx context.Context, archiver *ssmdv1alpha1.Archiver) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
+	var feedConfig *feedconfig.Config
+	if archiver.Spec.Image == "" && archiver.Spec.Feed != "" {
+		var err error
+		if feedConfig, err = feedconfig.Load(ctx, r.Client, archiver.Namespace, archiver.Spec.Feed); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	deploymentName := r.deploymentName(archiver)
 	deployment := &appsv1.Deployment{}
 	err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: archiver.Namespace}, deployment)
 
 	if errors.IsNotFound(err) {
 		// Create new Deployment
-		deployment = r.constructDeployment(archiver)
+		deployment = r.constructDeployment(archiver, feedConfig)
 		if err := controllerutil.SetControllerReference(archiver, deployment, r.Scheme); err != nil {
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating Deployment", "name", deploymentName)
-		if err := r.Create(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, archiver, eventActionCreate, "Deployment", deploymentName, r.Create(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -421,11 +590,11 @@ func (r *ArchiverReconciler) reconcileDeployment(ctx context.Context, archiver *
 	}
 
 	// Update existing Deployment if needed
-	desired := r.constructDeployment(archiver)
-	if r.deploymentNeedsUpdate(deployment, desired) {
-		deployment.Spec = desired.Spec
+	desired := r.constructDeployment(archiver, feedConfig)
+	if deploymentNeedsUpdate(deployment, desired) {
+		updateDeploymentSpec(deployment, desired)
 		log.Info("Updating Deployment", "name", deploymentName)
-		if err := r.Update(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, archiver, eventActionUpdate, "Deployment", deploymentName, r.Update(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
@@ -434,7 +603,7 @@ func (r *ArchiverReconciler) reconcileDeployment(ctx context.Context, archiver *
 }
 
 // constructDeployment builds the Deployment spec for an Archiver
-func (r *ArchiverReconciler) constructDeployment(archiver *ssmdv1alpha1.Archiver) *appsv1.Deployment {
+func (r *ArchiverReconciler) constructDeployment(archiver *ssmdv1alpha1.Archiver, feedConfig *feedconfig.Config) *appsv1.Deployment {
 	labels := map[string]string{
 		"app.kubernetes.io/name":       "ssmd-archiver",
 		"app.kubernetes.io/instance":   archiver.Name,
@@ -446,8 +615,11 @@ func (r *ArchiverReconciler) constructDeployment(archiver *ssmdv1alpha1.Archiver
 		replicas = *archiver.Spec.Replicas
 	}
 
-	// Default image if not specified
+	// Determine image: spec > feed defaults > hardcoded default
 	image := archiver.Spec.Image
+	if image == "" {
+		image = feedConfig.ArchiverDefaults().ImageRef()
+	}
 	if image == "" {
 		image = "ghcr.io/aaronwald/ssmd-archiver:latest"
 	}
@@ -494,7 +666,8 @@ func (r *ArchiverReconciler) constructDeployment(archiver *ssmdv1alpha1.Archiver
 	}
 
 	// Add GCS credentials secret volume if specified
-	if archiver.Spec.Storage != nil && archiver.Spec.Storage.Remote != nil && archiver.Spec.Storage.Remote.SecretRef != "" {
+	if archiver.Spec.Storage != nil && archiver.Spec.Storage.Remote != nil && archiver.Spec.Storage.Remote.SecretRef != "" &&
+		archiver.Spec.Storage.Remote.Type != "s3" {
 		volumes = append(volumes, corev1.Volume{
 			Name: "gcs-credentials",
 			VolumeSource: corev1.VolumeSource{
@@ -548,12 +721,10 @@ func (r *ArchiverReconciler) constructDeployment(archiver *ssmdv1alpha1.Archiver
 		VolumeMounts: volumeMounts,
 	}
 
-	// Add resource requirements if specified
-	if archiver.Spec.Resources != nil {
-		container.Resources = *archiver.Spec.Resources
-	}
+	// Add resource requirements, falling back to the operator default
+	container.Resources = containerResources(archiver.Spec.Resources, r.DefaultResources)
 
-	return &appsv1.Deployment{
+	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.deploymentName(archiver),
 			Namespace: archiver.Namespace,
@@ -572,75 +743,25 @@ func (r *ArchiverReconciler) constructDeployment(archiver *ssmdv1alpha1.Archiver
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
+					PriorityClassName:  priorityClassName(archiver.Spec.PriorityClassName, r.DefaultPriorityClassName),
 					ServiceAccountName: archiver.Spec.ServiceAccountName,
 					Containers:         []corev1.Container{container},
 					Volumes:            volumes,
-					ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "ghcr-secret"}},
 				},
 			},
 		},
 	}
-}
-
-// deploymentNeedsUpdate checks if the Deployment needs to be updated
-func (r *ArchiverReconciler) deploymentNeedsUpdate(current, desired *appsv1.Deployment) bool {
-	// Check strategy type (Recreate vs RollingUpdate)
-	if current.Spec.Strategy.Type != desired.Spec.Strategy.Type {
-		return true
-	}
-
-	// Check replicas
-	if *current.Spec.Replicas != *desired.Spec.Replicas {
-		return true
-	}
 
-	if len(current.Spec.Template.Spec.Containers) == 0 || len(desired.Spec.Template.Spec.Containers) == 0 {
-		return len(current.Spec.Template.Spec.Containers) != len(desired.Spec.Template.Spec.Containers)
-	}
-
-	currentContainer := &current.Spec.Template.Spec.Containers[0]
-	desiredContainer := &desired.Spec.Template.Spec.Containers[0]
-
-	// Check image
-	if currentContainer.Image != desiredContainer.Image {
-		return true
-	}
-
-	// Check environment variables
-	if !reflect.DeepEqual(currentContainer.Env, desiredContainer.Env) {
-		return true
-	}
-
-	// Check resource requirements (Autopilot-safe: only check desired fields)
-	if !resourcesMatch(currentContainer.Resources, desiredContainer.Resources) {
-		return true
-	}
-
-	// Check volume mounts
-	if !reflect.DeepEqual(currentContainer.VolumeMounts, desiredContainer.VolumeMounts) {
-		return true
-	}
-
-	// Check args
-	if !reflect.DeepEqual(currentContainer.Args, desiredContainer.Args) {
-		return true
-	}
-
-	// Check volumes
-	if !reflect.DeepEqual(current.Spec.Template.Spec.Volumes, desired.Spec.Template.Spec.Volumes) {
-		return true
-	}
-
-	// Check ServiceAccountName
-	if current.Spec.Template.Spec.ServiceAccountName != desired.Spec.Template.Spec.ServiceAccountName {
-		return true
-	}
-
-	return false
+	applyPodTemplate(&deployment.Spec.Template, archiver.Spec.PodTemplate)
+	setSpecHash(deployment)
+	return deployment
 }
 
 // updateStatus updates the Archiver status based on Deployment state
 func (r *ArchiverReconciler) updateStatus(ctx context.Context, archiver *ssmdv1alpha1.Archiver) error {
+	previousPhase := archiver.Status.Phase
+	defer func() { recordPhaseChange(r.Recorder, archiver, previousPhase, archiver.Status.Phase) }()
+
 	deploymentName := r.deploymentName(archiver)
 	deployment := &appsv1.Deployment{}
 	err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: archiver.Namespace}, deployment)
@@ -697,7 +818,20 @@ func (r *ArchiverReconciler) updateStatus(ctx context.Context, archiver *ssmdv1a
 				storageCondition.Message = "PVC is not bound"
 			}
 		}
-		meta.SetStatusCondition(&archiver.Status.Conditions, storageCondition)
+		setConditionWithEvent(r.Recorder, archiver, &archiver.Status.Conditions, storageCondition)
+
+		// Progress from the running archiver pods
+		if err := r.updateProgressStatus(ctx, archiver); err != nil {
+			return err
+		}
+	}
+
+	// Sync progress reported by ssmd-sync
+	if err := r.updateSyncStatus(ctx, archiver); err != nil {
+		return err
+	}
+	if err := r.updateSyncJobStatus(ctx, archiver); err != nil {
+		return err
 	}
 
 	return r.Status().Update(ctx, archiver)
@@ -709,17 +843,122 @@ func (r *ArchiverReconciler) deploymentName(archiver *ssmdv1alpha1.Archiver) str
 }
 
 // constructSyncJob builds a Job to sync local data to GCS on archiver deletion.
-// Supports two auth modes:
-// - Workload Identity (GKE): set serviceAccountName on the Archiver CR, omit secretRef
-// - Key file (homelab/non-GKE): set secretRef on remote storage config
 func (r *ArchiverReconciler) constructSyncJob(archiver *ssmdv1alpha1.Archiver) *batchv1.Job {
-	labels := map[string]string{
+	labels := r.syncLabels(archiver)
+	localPath := r.syncLocalPath(archiver)
+
+	// Marker file path - archiver writes this after flushing all data
+	markerPath := localPath + ".sync-ready"
+
+	podSpec := r.syncPodSpec(archiver, localPath)
+	// Init container waits for archiver to write .sync-ready marker
+	podSpec.InitContainers = []corev1.Container{{
+		Name:  "wait-for-flush",
+		Image: "busybox:1.36",
+		Command: []string{
+			"sh", "-c",
+			fmt.Sprintf(`echo "Waiting for archiver to flush (marker: %s)..."
+timeout=120
+elapsed=0
+while [ ! -f "%s" ] && [ $elapsed -lt $timeout ]; do
+  sleep 2
+  elapsed=$((elapsed + 2))
+  echo "Waiting... ($elapsed/$timeout seconds)"
+done
+if [ -f "%s" ]; then
+  echo "Marker found: $(cat %s)"
+  rm -f "%s"
+  exit 0
+else
+  echo "WARNING: Marker not found after ${timeout}s, proceeding anyway"
+  exit 0
+fi`, markerPath, markerPath, markerPath, markerPath, markerPath),
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "data", MountPath: "/data"},
+		},
+	}}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-final-sync", archiver.Name),
+			Namespace: archiver.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: podSpec,
+			},
+		},
+	}
+}
+
+// constructSyncCronJob builds the CronJob that syncs closed files to GCS on sync.schedule
+func (r *ArchiverReconciler) constructSyncCronJob(archiver *ssmdv1alpha1.Archiver) *batchv1.CronJob {
+	labels := r.syncLabels(archiver)
+
+	// Files untouched for a full rotation interval have been closed by the archiver
+	podSpec := r.syncPodSpec(archiver, r.syncLocalPath(archiver), "--min-age", r.rotationInterval(archiver).String())
+
+	// The PVC is ReadWriteOnce, so the sync pod must run on the archiver's node
+	podSpec.Affinity = &corev1.Affinity{
+		PodAffinity: &corev1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"app.kubernetes.io/name":     "ssmd-archiver",
+						"app.kubernetes.io/instance": archiver.Name,
+					},
+				},
+				TopologyKey: "kubernetes.io/hostname",
+			}},
+		},
+	}
+
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.syncCronJobName(archiver),
+			Namespace: archiver.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   archiver.Spec.Sync.Schedule,
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: int32Ptr(1),
+			FailedJobsHistoryLimit:     int32Ptr(3),
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: batchv1.JobSpec{
+					// ssmd-sync retries uploads itself; the next schedule picks up anything left
+					BackoffLimit: int32Ptr(0),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: labels,
+						},
+						Spec: podSpec,
+					},
+				},
+			},
+		},
+	}
+}
+
+// syncLabels returns the labels for sync Jobs and CronJobs
+func (r *ArchiverReconciler) syncLabels(archiver *ssmdv1alpha1.Archiver) map[string]string {
+	return map[string]string{
 		"app.kubernetes.io/name":       "ssmd-archiver-sync",
 		"app.kubernetes.io/instance":   archiver.Name,
 		"app.kubernetes.io/managed-by": "ssmd-operator",
 	}
+}
 
-	// Build the gsutil rsync command
+// syncLocalPath returns the local data directory to sync, with a trailing slash
+func (r *ArchiverReconciler) syncLocalPath(archiver *ssmdv1alpha1.Archiver) string {
 	localPath := "/data/ssmd/"
 	if archiver.Spec.Storage.Local != nil && archiver.Spec.Storage.Local.Path != "" {
 		localPath = archiver.Spec.Storage.Local.Path
@@ -727,42 +966,44 @@ func (r *ArchiverReconciler) constructSyncJob(archiver *ssmdv1alpha1.Archiver) *
 			localPath += "/"
 		}
 	}
+	return localPath
+}
 
-	// Marker file path - archiver writes this after flushing all data
-	markerPath := localPath + ".sync-ready"
+// syncPodSpec builds the pod spec running ssmd-sync against the Archiver's PVC
+func (r *ArchiverReconciler) syncPodSpec(archiver *ssmdv1alpha1.Archiver, localPath string, extraArgs ...string) corev1.PodSpec {
+	// Sync Jobs run at the sync priority class, else the archiver's own
+	archiverPriorityClass := priorityClassName(archiver.Spec.PriorityClassName, r.DefaultPriorityClassName)
 
-	remotePath := fmt.Sprintf("gs://%s/", archiver.Spec.Storage.Remote.Bucket)
-	if archiver.Spec.Storage.Remote.Prefix != "" {
-		remotePath = fmt.Sprintf("gs://%s/%s/", archiver.Spec.Storage.Remote.Bucket, archiver.Spec.Storage.Remote.Prefix)
+	image := defaultSyncImage
+	maxAttempts := int32(5)
+	if archiver.Spec.Sync != nil {
+		if archiver.Spec.Sync.Image != "" {
+			image = archiver.Spec.Sync.Image
+		}
+		if archiver.Spec.Sync.MaxAttempts != nil {
+			maxAttempts = *archiver.Spec.Sync.MaxAttempts
+		}
 	}
 
-	// Build sync command and volumes based on auth mode
-	useKeyFile := archiver.Spec.Storage.Remote.SecretRef != ""
-
-	var syncCommand string
-	var syncVolumeMounts []corev1.VolumeMount
-	var syncEnv []corev1.EnvVar
-
-	syncVolumeMounts = []corev1.VolumeMount{
-		{Name: "data", MountPath: "/data"},
+	args := []string{
+		"--source", localPath,
+		"--bucket", archiver.Spec.Storage.Remote.Bucket,
+		"--prefix", archiver.Spec.Storage.Remote.Prefix,
+		"--name", archiver.Name,
+		"--status-configmap", r.syncStatusConfigMapName(archiver),
+		"--max-attempts", fmt.Sprintf("%d", maxAttempts),
 	}
+	args = append(args, extraArgs...)
 
-	syncCmd := fmt.Sprintf(`if [ -d "%s" ] && [ "$(ls -A %s 2>/dev/null)" ]; then echo "Syncing %s to %s"; gsutil -m rsync -r %s %s; else echo "No data at %s, skipping"; fi`, localPath, localPath, localPath, remotePath, localPath, remotePath, localPath)
-
-	if useKeyFile {
-		// Key-file auth: activate service account before gsutil
-		syncCommand = fmt.Sprintf(`gcloud auth activate-service-account --key-file=/etc/gcs/key.json && %s`, syncCmd)
-		syncVolumeMounts = append(syncVolumeMounts, corev1.VolumeMount{
-			Name: "gcs-credentials", MountPath: "/etc/gcs", ReadOnly: true,
-		})
-		syncEnv = []corev1.EnvVar{{
-			Name:  "GOOGLE_APPLICATION_CREDENTIALS",
-			Value: "/etc/gcs/key.json",
-		}}
-	} else {
-		// Workload Identity: gsutil uses credentials from GKE metadata server
-		syncCommand = syncCmd
+	syncVolumeMounts := []corev1.VolumeMount{
+		{Name: "data", MountPath: "/data"},
 	}
+	syncEnv := []corev1.EnvVar{{
+		Name: "POD_NAMESPACE",
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+		},
+	}}
 
 	volumes := []corev1.Volume{
 		{
@@ -775,7 +1016,36 @@ func (r *ArchiverReconciler) constructSyncJob(archiver *ssmdv1alpha1.Archiver) *
 		},
 	}
 
-	if useKeyFile {
+	remote := archiver.Spec.Storage.Remote
+	if remote.Type == "s3" {
+		args = append(args, "--type", "s3")
+		if remote.Region != "" {
+			args = append(args, "--region", remote.Region)
+		}
+		if remote.Endpoint != "" {
+			args = append(args, "--endpoint", remote.Endpoint)
+		}
+		// Static keys from the secret; the session token is optional
+		if remote.SecretRef != "" {
+			for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"} {
+				syncEnv = append(syncEnv, corev1.EnvVar{
+					Name: key,
+					ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: remote.SecretRef},
+							Key:                  key,
+							Optional:             boolPtr(key == "AWS_SESSION_TOKEN"),
+						},
+					},
+				})
+			}
+		}
+	} else if remote.SecretRef != "" {
+		// Key-file auth; otherwise ssmd-sync uses Workload Identity via the metadata server
+		args = append(args, "--credentials", "/etc/gcs/key.json")
+		syncVolumeMounts = append(syncVolumeMounts, corev1.VolumeMount{
+			Name: "gcs-credentials", MountPath: "/etc/gcs", ReadOnly: true,
+		})
 		volumes = append(volumes, corev1.Volume{
 			Name: "gcs-credentials",
 			VolumeSource: corev1.VolumeSource{
@@ -786,62 +1056,352 @@ func (r *ArchiverReconciler) constructSyncJob(archiver *ssmdv1alpha1.Archiver) *
 		})
 	}
 
-	return &batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-final-sync", archiver.Name),
-			Namespace: archiver.Namespace,
-			Labels:    labels,
-		},
-		Spec: batchv1.JobSpec{
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
-				},
-				Spec: corev1.PodSpec{
-					ServiceAccountName: archiver.Spec.ServiceAccountName,
-					RestartPolicy:      corev1.RestartPolicyNever,
-					// Init container waits for archiver to write .sync-ready marker
-					InitContainers: []corev1.Container{{
-						Name:  "wait-for-flush",
-						Image: "busybox:1.36",
-						Command: []string{
-							"sh", "-c",
-							fmt.Sprintf(`echo "Waiting for archiver to flush (marker: %s)..."
-timeout=120
-elapsed=0
-while [ ! -f "%s" ] && [ $elapsed -lt $timeout ]; do
-  sleep 2
-  elapsed=$((elapsed + 2))
-  echo "Waiting... ($elapsed/$timeout seconds)"
-done
-if [ -f "%s" ]; then
-  echo "Marker found: $(cat %s)"
-  rm -f "%s"
-  exit 0
-else
-  echo "WARNING: Marker not found after ${timeout}s, proceeding anyway"
-  exit 0
-fi`, markerPath, markerPath, markerPath, markerPath, markerPath),
-						},
-						VolumeMounts: []corev1.VolumeMount{
-							{Name: "data", MountPath: "/data"},
-						},
-					}},
-					Containers: []corev1.Container{{
-						Name:  "sync",
-						Image: "gcr.io/google.com/cloudsdktool/google-cloud-cli:slim",
-						Command: []string{
-							"sh", "-c",
-							syncCommand,
-						},
-						VolumeMounts: syncVolumeMounts,
-						Env:          syncEnv,
-					}},
-					Volumes: volumes,
-				},
-			},
-		},
+	podSpec := corev1.PodSpec{
+		PriorityClassName:  priorityClassName(r.SyncPriorityClassName, archiverPriorityClass),
+		ServiceAccountName: archiver.Spec.ServiceAccountName,
+		RestartPolicy:      corev1.RestartPolicyNever,
+		Containers: []corev1.Container{{
+			Name:         "sync",
+			Image:        image,
+			Args:         args,
+			VolumeMounts: syncVolumeMounts,
+			Env:          syncEnv,
+			Resources:    containerResources(nil, r.SyncResources),
+		}},
+		ImagePullSecrets: imagePullSecrets(archiver.Spec.PodTemplate),
+		Volumes:          volumes,
+	}
+
+	// Sync pods share the archiver's identity and node placement (not its
+	// affinity, which the CronJob sets to follow the archiver pod)
+	if podTemplate := archiver.Spec.PodTemplate; podTemplate != nil {
+		if podTemplate.ServiceAccountName != "" {
+			podSpec.ServiceAccountName = podTemplate.ServiceAccountName
+		}
+		podSpec.NodeSelector = podTemplate.NodeSelector
+		podSpec.Tolerations = podTemplate.Tolerations
+	}
+	return podSpec
+}
+
+// rotationInterval parses spec.rotation.maxFileAge the way the archiver does
+// (<n>s, <n>m, <n>h or <n>d), defaulting to 15m
+func (r *ArchiverReconciler) rotationInterval(archiver *ssmdv1alpha1.Archiver) time.Duration {
+	const defaultInterval = 15 * time.Minute
+	if archiver.Spec.Rotation == nil || len(archiver.Spec.Rotation.MaxFileAge) < 2 {
+		return defaultInterval
+	}
+	maxFileAge := strings.TrimSpace(archiver.Spec.Rotation.MaxFileAge)
+	n, err := strconv.Atoi(maxFileAge[:len(maxFileAge)-1])
+	if err != nil || n <= 0 {
+		return defaultInterval
+	}
+	switch maxFileAge[len(maxFileAge)-1] {
+	case 's':
+		return time.Duration(n) * time.Second
+	case 'm':
+		return time.Duration(n) * time.Minute
+	case 'h':
+		return time.Duration(n) * time.Hour
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour
+	default:
+		return defaultInterval
+	}
+}
+
+// periodicSyncEnabled reports whether the Archiver wants a scheduled sync CronJob
+func (r *ArchiverReconciler) periodicSyncEnabled(archiver *ssmdv1alpha1.Archiver) bool {
+	return archiver.Spec.Sync != nil && archiver.Spec.Sync.Enabled && archiver.Spec.Sync.Schedule != "" &&
+		archiver.Spec.Storage != nil && archiver.Spec.Storage.Remote != nil && archiver.Spec.Storage.Remote.Bucket != "" &&
+		archiver.Spec.Storage.Local != nil && archiver.Spec.Storage.Local.PVCName != ""
+}
+
+// syncCronJobName returns the periodic sync CronJob name for an Archiver
+func (r *ArchiverReconciler) syncCronJobName(archiver *ssmdv1alpha1.Archiver) string {
+	return fmt.Sprintf("%s-sync", archiver.Name)
+}
+
+// reconcileSyncCronJob ensures the periodic sync CronJob matches sync.schedule,
+// deleting it when periodic sync is disabled
+func (r *ArchiverReconciler) reconcileSyncCronJob(ctx context.Context, archiver *ssmdv1alpha1.Archiver) error {
+	log := logf.FromContext(ctx)
+
+	cronJob := &batchv1.CronJob{}
+	err := r.Get(ctx, types.NamespacedName{Name: r.syncCronJobName(archiver), Namespace: archiver.Namespace}, cronJob)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if !r.periodicSyncEnabled(archiver) {
+		if exists && metav1.IsControlledBy(cronJob, archiver) {
+			log.Info("Deleting sync CronJob", "name", cronJob.Name)
+			if err := recordChildEvent(r.Recorder, archiver, eventActionDelete, "CronJob", cronJob.Name, r.Delete(ctx, cronJob)); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	desired := r.constructSyncCronJob(archiver)
+	if !exists {
+		if err := controllerutil.SetControllerReference(archiver, desired, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("Creating sync CronJob", "name", desired.Name, "schedule", desired.Spec.Schedule)
+		return recordChildEvent(r.Recorder, archiver, eventActionCreate, "CronJob", desired.Name, r.Create(ctx, desired))
+	}
+
+	if r.cronJobNeedsUpdate(cronJob, desired) {
+		cronJob.Spec = desired.Spec
+		log.Info("Updating sync CronJob", "name", cronJob.Name)
+		return recordChildEvent(r.Recorder, archiver, eventActionUpdate, "CronJob", cronJob.Name, r.Update(ctx, cronJob))
+	}
+
+	return nil
+}
+
+// cronJobNeedsUpdate checks if the sync CronJob needs to be updated
+func (r *ArchiverReconciler) cronJobNeedsUpdate(current, desired *batchv1.CronJob) bool {
+	// Check schedule
+	if current.Spec.Schedule != desired.Spec.Schedule {
+		return true
+	}
+
+	currentPod := &current.Spec.JobTemplate.Spec.Template.Spec
+	desiredPod := &desired.Spec.JobTemplate.Spec.Template.Spec
+	if len(currentPod.Containers) == 0 {
+		return true
+	}
+
+	// Check image and args
+	if currentPod.Containers[0].Image != desiredPod.Containers[0].Image {
+		return true
+	}
+	if !reflect.DeepEqual(currentPod.Containers[0].Args, desiredPod.Containers[0].Args) {
+		return true
+	}
+	if !equality.Semantic.DeepEqual(currentPod.Containers[0].Resources, desiredPod.Containers[0].Resources) {
+		return true
 	}
+
+	// Check volumes
+	if !reflect.DeepEqual(currentPod.Volumes, desiredPod.Volumes) {
+		return true
+	}
+
+	// Check ServiceAccountName and priority class
+	if currentPod.ServiceAccountName != desiredPod.ServiceAccountName {
+		return true
+	}
+	if currentPod.PriorityClassName != desiredPod.PriorityClassName {
+		return true
+	}
+
+	// Check pull secrets and node placement
+	if !reflect.DeepEqual(currentPod.ImagePullSecrets, desiredPod.ImagePullSecrets) {
+		return true
+	}
+	if !reflect.DeepEqual(currentPod.NodeSelector, desiredPod.NodeSelector) {
+		return true
+	}
+	if !reflect.DeepEqual(currentPod.Tolerations, desiredPod.Tolerations) {
+		return true
+	}
+
+	return false
+}
+
+// syncStatusConfigMapName returns the ConfigMap ssmd-sync reports counts to
+func (r *ArchiverReconciler) syncStatusConfigMapName(archiver *ssmdv1alpha1.Archiver) string {
+	return fmt.Sprintf("%s-sync-status", archiver.Name)
+}
+
+// updateSyncStatus copies the last sync counts from the sync status ConfigMap
+// into the Archiver status. Missing ConfigMaps are ignored (no sync has run yet).
+func (r *ArchiverReconciler) updateSyncStatus(ctx context.Context, archiver *ssmdv1alpha1.Archiver) error {
+	configMap := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: r.syncStatusConfigMapName(archiver), Namespace: archiver.Namespace}, configMap)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	syncStatus := archivesync.ParseStatusData(configMap.Data)
+	if syncStatus == nil {
+		return nil
+	}
+
+	lastSyncAt := metav1.NewTime(syncStatus.CompletedAt)
+	archiver.Status.LastSyncAt = &lastSyncAt
+	archiver.Status.LastSyncFiles = syncStatus.FilesUploaded
+
+	condition := metav1.Condition{
+		Type:               "Synced",
+		Status:             metav1.ConditionTrue,
+		Reason:             "SyncVerified",
+		Message:            fmt.Sprintf("Last sync uploaded %d files, %d already present", syncStatus.FilesUploaded, syncStatus.FilesSkipped),
+		LastTransitionTime: metav1.Now(),
+	}
+	if syncStatus.FilesFailed > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SyncIncomplete"
+		condition.Message = fmt.Sprintf("Last sync failed to verify %d files", syncStatus.FilesFailed)
+	}
+	setConditionWithEvent(r.Recorder, archiver, &archiver.Status.Conditions, condition)
+
+	return nil
+}
+
+// archiverMetrics is the subset of archiver metrics surfaced in the Archiver status
+type archiverMetrics struct {
+	MessagesArchived int64
+	BytesWritten     int64
+	FilesWritten     int32
+}
+
+// parseArchiverMetrics extracts status values from an archiver's /metrics
+func parseArchiverMetrics(families metricFamilies) archiverMetrics {
+	var m archiverMetrics
+
+	if total, ok := families.sum("ssmd_archiver_messages_total"); ok {
+		m.MessagesArchived = int64(total)
+	}
+	if total, ok := families.sum("ssmd_archiver_bytes_total"); ok {
+		m.BytesWritten = int64(total)
+	}
+	if total, ok := families.sum("ssmd_archiver_files_rotated_total"); ok {
+		m.FilesWritten = int32(total)
+	}
+
+	return m
+}
+
+// updateProgressStatus scrapes the Archiver's pods and copies their progress into
+// status, then sets StorageFilling from the PVC usage reported by the kubelet.
+// Scrape and stats failures are logged and leave the previous values in place.
+func (r *ArchiverReconciler) updateProgressStatus(ctx context.Context, archiver *ssmdv1alpha1.Archiver) error {
+	log := logf.FromContext(ctx)
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(archiver.Namespace), client.MatchingLabels{
+		"app.kubernetes.io/name":     "ssmd-archiver",
+		"app.kubernetes.io/instance": archiver.Name,
+	}); err != nil {
+		return err
+	}
+
+	scrape := r.scrape
+	if scrape == nil {
+		scrape = scrapeMetrics
+	}
+
+	var total archiverMetrics
+	var usage *VolumeUsage
+	scraped := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+
+		url := fmt.Sprintf("http://%s:%d/metrics", pod.Status.PodIP, archiverMetricsPort)
+		if families, err := scrape(ctx, url); err != nil {
+			log.V(1).Info("Failed to scrape archiver metrics", "pod", pod.Name, "error", err.Error())
+		} else {
+			m := parseArchiverMetrics(families)
+			total.MessagesArchived += m.MessagesArchived
+			total.BytesWritten += m.BytesWritten
+			total.FilesWritten += m.FilesWritten
+			scraped++
+		}
+
+		if r.VolumeStats != nil && usage == nil {
+			u, err := r.VolumeStats(ctx, pod, "data")
+			if err != nil {
+				log.V(1).Info("Failed to get archiver volume stats", "pod", pod.Name, "error", err.Error())
+			}
+			usage = u
+		}
+	}
+
+	if scraped > 0 {
+		// files_rotated_total only counts up within a pod's lifetime, so a rise means a flush
+		if total.FilesWritten > archiver.Status.FilesWritten {
+			now := metav1.Now()
+			archiver.Status.LastFlushAt = &now
+		}
+		archiver.Status.MessagesArchived = total.MessagesArchived
+		archiver.Status.BytesWritten = total.BytesWritten
+		archiver.Status.FilesWritten = total.FilesWritten
+	}
+
+	if usage != nil {
+		meta.SetStatusCondition(&archiver.Status.Conditions, r.storageFillingCondition(archiver, usage))
+	}
+
+	return nil
+}
+
+// storageFillingCondition returns the StorageFilling condition for the given PVC usage
+func (r *ArchiverReconciler) storageFillingCondition(archiver *ssmdv1alpha1.Archiver, usage *VolumeUsage) metav1.Condition {
+	threshold := int32(defaultFillingThresholdPercent)
+	if archiver.Spec.Storage != nil && archiver.Spec.Storage.Local != nil && archiver.Spec.Storage.Local.FillingThresholdPercent != nil {
+		threshold = *archiver.Spec.Storage.Local.FillingThresholdPercent
+	}
+
+	var percent int64
+	if usage.CapacityBytes > 0 {
+		percent = usage.UsedBytes * 100 / usage.CapacityBytes
+	}
+
+	condition := metav1.Condition{
+		Type:               "StorageFilling",
+		Status:             metav1.ConditionFalse,
+		Reason:             "UsageBelowThreshold",
+		Message:            fmt.Sprintf("PVC is %d%% full (threshold %d%%)", percent, threshold),
+		LastTransitionTime: metav1.Now(),
+	}
+	if percent >= int64(threshold) {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "UsageAboveThreshold"
+	}
+	return condition
+}
+
+// updateSyncJobStatus marks Synced false when the last scheduled sync Job failed
+// without reporting (e.g. it could not start), since the status ConfigMap is then stale
+func (r *ArchiverReconciler) updateSyncJobStatus(ctx context.Context, archiver *ssmdv1alpha1.Archiver) error {
+	cronJob := &batchv1.CronJob{}
+	err := r.Get(ctx, types.NamespacedName{Name: r.syncCronJobName(archiver), Namespace: archiver.Namespace}, cronJob)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	lastSchedule := cronJob.Status.LastScheduleTime
+	if lastSchedule == nil || len(cronJob.Status.Active) > 0 {
+		return nil
+	}
+	if cronJob.Status.LastSuccessfulTime != nil && !cronJob.Status.LastSuccessfulTime.Before(lastSchedule) {
+		return nil
+	}
+	if archiver.Status.LastSyncAt != nil && !archiver.Status.LastSyncAt.Before(lastSchedule) {
+		// The Job reported its counts; Synced already reflects the failure
+		return nil
+	}
+
+	setConditionWithEvent(r.Recorder, archiver, &archiver.Status.Conditions, metav1.Condition{
+		Type:               "Synced",
+		Status:             metav1.ConditionFalse,
+		Reason:             "SyncJobFailed",
+		Message:            fmt.Sprintf("Sync scheduled at %s did not complete", lastSchedule.UTC().Format(time.RFC3339)),
+		LastTransitionTime: metav1.Now(),
+	})
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -851,6 +1411,8 @@ func (r *ArchiverReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.ConfigMap{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&batchv1.CronJob{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Named("archiver").
 		Complete(r)
 }
@@ -0,0 +1,523 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/feedconfig"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newSyncTestArchiver builds an Archiver with local and remote storage and a sync schedule.
+func newSyncTestArchiver() *ssmdv1alpha1.Archiver {
+	archiver := newTestArchiver()
+	archiver.UID = "archiver-uid"
+	archiver.Spec.Storage.Remote = &ssmdv1alpha1.RemoteStorageConfig{Bucket: "ssmd-archive", Prefix: "kalshi"}
+	archiver.Spec.Sync = &ssmdv1alpha1.SyncConfig{Enabled: true, Schedule: "0 * * * *"}
+	return archiver
+}
+
+func newSyncTestReconciler(objs ...runtime.Object) *ArchiverReconciler {
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+
+	return &ArchiverReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme: scheme,
+	}
+}
+
+// --- TestConstructSyncCronJob ---
+
+func TestConstructSyncCronJob(t *testing.T) {
+	r := &ArchiverReconciler{}
+	archiver := newSyncTestArchiver()
+	archiver.Spec.Rotation = &ssmdv1alpha1.RotationConfig{MaxFileAge: "1h"}
+
+	cronJob := r.constructSyncCronJob(archiver)
+
+	if cronJob.Name != "archiver-test-sync" || cronJob.Spec.Schedule != "0 * * * *" {
+		t.Errorf("unexpected name/schedule: %s %s", cronJob.Name, cronJob.Spec.Schedule)
+	}
+	if cronJob.Spec.ConcurrencyPolicy != batchv1.ForbidConcurrent {
+		t.Errorf("ConcurrencyPolicy = %s, want Forbid", cronJob.Spec.ConcurrencyPolicy)
+	}
+	podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	if len(podSpec.InitContainers) != 0 {
+		t.Error("periodic sync should not wait for the final flush marker")
+	}
+	args := podSpec.Containers[0].Args
+	if i := slices.Index(args, "--min-age"); i < 0 || args[i+1] != "1h0m0s" {
+		t.Errorf("expected --min-age 1h0m0s, got %v", args)
+	}
+	if podSpec.Affinity == nil || podSpec.Affinity.PodAffinity == nil {
+		t.Fatal("expected pod affinity to the archiver's node")
+	}
+	term := podSpec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0]
+	if term.TopologyKey != "kubernetes.io/hostname" || term.LabelSelector.MatchLabels["app.kubernetes.io/instance"] != "archiver-test" {
+		t.Errorf("unexpected affinity term: %+v", term)
+	}
+}
+
+func TestConstructSyncJob_KeepsFlushWait(t *testing.T) {
+	r := &ArchiverReconciler{}
+	job := r.constructSyncJob(newSyncTestArchiver())
+
+	podSpec := job.Spec.Template.Spec
+	if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Name != "wait-for-flush" {
+		t.Errorf("expected wait-for-flush init container, got %+v", podSpec.InitContainers)
+	}
+	if slices.Contains(podSpec.Containers[0].Args, "--min-age") {
+		t.Error("final sync should upload every file")
+	}
+}
+
+// --- TestRotationInterval ---
+
+func TestRotationInterval(t *testing.T) {
+	r := &ArchiverReconciler{}
+	tests := []struct {
+		maxFileAge string
+		want       time.Duration
+	}{
+		{"", 15 * time.Minute},
+		{"30s", 30 * time.Second},
+		{"5m", 5 * time.Minute},
+		{"2h", 2 * time.Hour},
+		{"1d", 24 * time.Hour},
+		{"0m", 15 * time.Minute},
+		{"bogus", 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		archiver := newSyncTestArchiver()
+		archiver.Spec.Rotation = &ssmdv1alpha1.RotationConfig{MaxFileAge: tt.maxFileAge}
+		if got := r.rotationInterval(archiver); got != tt.want {
+			t.Errorf("rotationInterval(%q) = %s, want %s", tt.maxFileAge, got, tt.want)
+		}
+	}
+}
+
+// --- TestReconcileSyncCronJob ---
+
+func TestReconcileSyncCronJob_CreatesAndUpdates(t *testing.T) {
+	r := newSyncTestReconciler()
+	archiver := newSyncTestArchiver()
+	ctx := context.Background()
+
+	if err := r.reconcileSyncCronJob(ctx, archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cronJob := &batchv1.CronJob{}
+	key := types.NamespacedName{Name: "archiver-test-sync", Namespace: "ssmd"}
+	if err := r.Get(ctx, key, cronJob); err != nil {
+		t.Fatalf("expected CronJob to be created: %v", err)
+	}
+	if !metav1.IsControlledBy(cronJob, archiver) {
+		t.Error("expected CronJob to be owned by the Archiver")
+	}
+
+	archiver.Spec.Sync.Schedule = "*/30 * * * *"
+	if err := r.reconcileSyncCronJob(ctx, archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(ctx, key, cronJob); err != nil {
+		t.Fatal(err)
+	}
+	if cronJob.Spec.Schedule != "*/30 * * * *" {
+		t.Errorf("Schedule = %q, want updated schedule", cronJob.Spec.Schedule)
+	}
+}
+
+func TestReconcileSyncCronJob_DeletesWhenScheduleRemoved(t *testing.T) {
+	r := newSyncTestReconciler()
+	archiver := newSyncTestArchiver()
+	ctx := context.Background()
+
+	if err := r.reconcileSyncCronJob(ctx, archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	archiver.Spec.Sync.Schedule = ""
+	if err := r.reconcileSyncCronJob(ctx, archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cronJob := &batchv1.CronJob{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "archiver-test-sync", Namespace: "ssmd"}, cronJob); err == nil {
+		t.Error("expected CronJob to be deleted")
+	}
+}
+
+func TestReconcileSyncCronJob_NoRemote(t *testing.T) {
+	r := newSyncTestReconciler()
+	archiver := newSyncTestArchiver()
+	archiver.Spec.Storage.Remote = nil
+
+	if err := r.reconcileSyncCronJob(context.Background(), archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cronJobs := &batchv1.CronJobList{}
+	if err := r.List(context.Background(), cronJobs); err != nil {
+		t.Fatal(err)
+	}
+	if len(cronJobs.Items) != 0 {
+		t.Errorf("expected no CronJob without remote storage, got %d", len(cronJobs.Items))
+	}
+}
+
+// --- TestUpdateSyncJobStatus ---
+
+func newSyncCronJob(lastSchedule, lastSuccess *time.Time) *batchv1.CronJob {
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "archiver-test-sync", Namespace: "ssmd"},
+	}
+	if lastSchedule != nil {
+		cronJob.Status.LastScheduleTime = &metav1.Time{Time: *lastSchedule}
+	}
+	if lastSuccess != nil {
+		cronJob.Status.LastSuccessfulTime = &metav1.Time{Time: *lastSuccess}
+	}
+	return cronJob
+}
+
+func TestUpdateSyncJobStatus_FailedWithoutReport(t *testing.T) {
+	scheduled := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	r := newSyncTestReconciler(newSyncCronJob(&scheduled, nil))
+	archiver := newSyncTestArchiver()
+
+	if err := r.updateSyncJobStatus(context.Background(), archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	condition := meta.FindStatusCondition(archiver.Status.Conditions, "Synced")
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "SyncJobFailed" {
+		t.Errorf("expected Synced=False/SyncJobFailed, got %+v", condition)
+	}
+}
+
+func TestUpdateSyncJobStatus_Succeeded(t *testing.T) {
+	scheduled := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	succeeded := scheduled.Add(2 * time.Minute)
+	r := newSyncTestReconciler(newSyncCronJob(&scheduled, &succeeded))
+	archiver := newSyncTestArchiver()
+
+	if err := r.updateSyncJobStatus(context.Background(), archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if meta.FindStatusCondition(archiver.Status.Conditions, "Synced") != nil {
+		t.Error("expected Synced to be left to the status ConfigMap")
+	}
+}
+
+func TestUpdateSyncJobStatus_FailedButReported(t *testing.T) {
+	scheduled := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	r := newSyncTestReconciler(newSyncCronJob(&scheduled, nil))
+	archiver := newSyncTestArchiver()
+	lastSyncAt := metav1.NewTime(scheduled.Add(5 * time.Minute))
+	archiver.Status.LastSyncAt = &lastSyncAt
+
+	if err := r.updateSyncJobStatus(context.Background(), archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if meta.FindStatusCondition(archiver.Status.Conditions, "Synced") != nil {
+		t.Error("expected the reported sync result to stand")
+	}
+}
+
+// --- TestSyncPodSpec (remote type) ---
+
+func TestSyncPodSpec_S3(t *testing.T) {
+	r := &ArchiverReconciler{}
+	archiver := newSyncTestArchiver()
+	archiver.Spec.Storage.Remote = &ssmdv1alpha1.RemoteStorageConfig{
+		Type:      "s3",
+		Bucket:    "ssmd-archive",
+		Prefix:    "kalshi",
+		SecretRef: "minio-credentials",
+		Endpoint:  "http://minio.minio:9000",
+		Region:    "us-east-1",
+	}
+
+	podSpec := r.syncPodSpec(archiver, "/data/ssmd/")
+
+	args := podSpec.Containers[0].Args
+	for flag, want := range map[string]string{"--type": "s3", "--endpoint": "http://minio.minio:9000", "--region": "us-east-1"} {
+		if i := slices.Index(args, flag); i < 0 || args[i+1] != want {
+			t.Errorf("expected %s %s, got %v", flag, want, args)
+		}
+	}
+	if slices.Contains(args, "--credentials") {
+		t.Error("s3 sync should not use a GCS key file")
+	}
+
+	env := map[string]*corev1.EnvVarSource{}
+	for _, e := range podSpec.Containers[0].Env {
+		env[e.Name] = e.ValueFrom
+	}
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"} {
+		if env[name] == nil || env[name].SecretKeyRef.Name != "minio-credentials" || *env[name].SecretKeyRef.Optional {
+			t.Errorf("expected required %s from minio-credentials, got %+v", name, env[name])
+		}
+	}
+	if env["AWS_SESSION_TOKEN"] == nil || !*env["AWS_SESSION_TOKEN"].SecretKeyRef.Optional {
+		t.Error("expected optional AWS_SESSION_TOKEN")
+	}
+	for _, v := range podSpec.Volumes {
+		if v.Name == "gcs-credentials" {
+			t.Error("s3 sync should not mount gcs-credentials")
+		}
+	}
+}
+
+func TestSyncPodSpec_GCSKeyFile(t *testing.T) {
+	r := &ArchiverReconciler{}
+	archiver := newSyncTestArchiver()
+	archiver.Spec.Storage.Remote.SecretRef = "gcs-credentials"
+
+	podSpec := r.syncPodSpec(archiver, "/data/ssmd/")
+
+	args := podSpec.Containers[0].Args
+	if i := slices.Index(args, "--credentials"); i < 0 || args[i+1] != "/etc/gcs/key.json" {
+		t.Errorf("expected GCS key file, got %v", args)
+	}
+	if slices.Contains(args, "--type") {
+		t.Errorf("gcs is the ssmd-sync default, got %v", args)
+	}
+}
+
+func TestSyncPodSpec_PodTemplate(t *testing.T) {
+	r := &ArchiverReconciler{}
+	archiver := newSyncTestArchiver()
+	archiver.Spec.ServiceAccountName = "ssmd-archiver"
+	archiver.Spec.PodTemplate = &ssmdv1alpha1.PodTemplateConfig{
+		ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "registry-secret"}},
+		NodeSelector:       map[string]string{"pool": "storage"},
+		Affinity:           &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}},
+		ServiceAccountName: "ssmd-archiver-wi",
+	}
+
+	podSpec := r.syncPodSpec(archiver, "/data/ssmd/")
+
+	if podSpec.ServiceAccountName != "ssmd-archiver-wi" {
+		t.Errorf("podTemplate service account should take precedence, got %q", podSpec.ServiceAccountName)
+	}
+	if len(podSpec.ImagePullSecrets) != 1 || podSpec.ImagePullSecrets[0].Name != "registry-secret" {
+		t.Errorf("expected registry-secret, got %v", podSpec.ImagePullSecrets)
+	}
+	if podSpec.NodeSelector["pool"] != "storage" {
+		t.Errorf("expected node selector, got %v", podSpec.NodeSelector)
+	}
+	if podSpec.Affinity != nil {
+		t.Error("sync pods should not take the archiver's affinity")
+	}
+}
+
+func TestConstructDeployment_S3SkipsGCSCredentials(t *testing.T) {
+	r := &ArchiverReconciler{}
+	archiver := newSyncTestArchiver()
+	archiver.Spec.Storage.Remote = &ssmdv1alpha1.RemoteStorageConfig{Type: "s3", Bucket: "ssmd-archive", SecretRef: "aws-credentials"}
+
+	dep := r.constructDeployment(archiver, nil)
+
+	for _, e := range dep.Spec.Template.Spec.Containers[0].Env {
+		if e.Name == "GOOGLE_APPLICATION_CREDENTIALS" {
+			t.Error("s3 archiver should not set GOOGLE_APPLICATION_CREDENTIALS")
+		}
+	}
+}
+
+func TestConstructDeployment_FeedDefaultImage(t *testing.T) {
+	r := &ArchiverReconciler{}
+	archiver := newSyncTestArchiver()
+	feedConfig := &feedconfig.Config{Defaults: &feedconfig.Defaults{
+		Archiver: &feedconfig.ComponentDefaults{Image: "ghcr.io/aaronwald/ssmd-archiver", Version: "0.4.8"},
+	}}
+
+	dep := r.constructDeployment(archiver, feedConfig)
+	if got := dep.Spec.Template.Spec.Containers[0].Image; got != "ghcr.io/aaronwald/ssmd-archiver:0.4.8" {
+		t.Errorf("expected feed default image, got %s", got)
+	}
+
+	archiver.Spec.Image = "ghcr.io/aaronwald/ssmd-archiver:pinned"
+	dep = r.constructDeployment(archiver, feedConfig)
+	if got := dep.Spec.Template.Spec.Containers[0].Image; got != "ghcr.io/aaronwald/ssmd-archiver:pinned" {
+		t.Errorf("expected spec image to win, got %s", got)
+	}
+}
+
+// --- TestReconcileDelete_FinalSync ---
+
+// newDeletingArchiver returns an Archiver with the finalizer, deleted at deletedAt
+func newDeletingArchiver(deletedAt time.Time) *ssmdv1alpha1.Archiver {
+	archiver := newSyncTestArchiver()
+	archiver.Spec.Sync.OnDelete = "final"
+	archiver.Finalizers = []string{archiverFinalizer}
+	archiver.DeletionTimestamp = &metav1.Time{Time: deletedAt}
+	return archiver
+}
+
+func newFinalSyncTestReconciler(archiver *ssmdv1alpha1.Archiver) *ArchiverReconciler {
+	r := newSyncTestReconciler()
+	_ = appsv1.AddToScheme(r.Scheme)
+	r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(archiver).
+		WithStatusSubresource(&ssmdv1alpha1.Archiver{}).Build()
+	return r
+}
+
+func TestReconcileDelete_WaitsForFinalSync(t *testing.T) {
+	ctx := context.Background()
+	archiver := newDeletingArchiver(time.Now())
+	r := newFinalSyncTestReconciler(archiver)
+
+	result, err := r.reconcileDelete(ctx, archiver)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Error("expected a requeue while the final sync runs")
+	}
+	if !slices.Contains(archiver.Finalizers, archiverFinalizer) {
+		t.Fatal("finalizer removed before the final sync finished")
+	}
+	if c := meta.FindStatusCondition(archiver.Status.Conditions, "FinalSyncing"); c == nil || c.Status != metav1.ConditionTrue {
+		t.Errorf("expected FinalSyncing=True, got %+v", c)
+	}
+
+	// Complete the Job; the next reconcile releases the finalizer
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "archiver-test-final-sync", Namespace: "ssmd"}, job); err != nil {
+		t.Fatalf("expected final sync Job: %v", err)
+	}
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	if err := r.Status().Update(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.reconcileDelete(ctx, archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slices.Contains(archiver.Finalizers, archiverFinalizer) {
+		t.Error("expected finalizer removed after the final sync completed")
+	}
+	if c := meta.FindStatusCondition(archiver.Status.Conditions, "FinalSyncing"); c == nil || c.Reason != "FinalSyncComplete" {
+		t.Errorf("expected FinalSyncComplete, got %+v", c)
+	}
+}
+
+func TestReconcileDelete_RetriesFailedFinalSync(t *testing.T) {
+	ctx := context.Background()
+	archiver := newDeletingArchiver(time.Now())
+	r := newFinalSyncTestReconciler(archiver)
+
+	if _, err := r.reconcileDelete(ctx, archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	key := types.NamespacedName{Name: "archiver-test-final-sync", Namespace: "ssmd"}
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, key, job); err != nil {
+		t.Fatalf("expected final sync Job: %v", err)
+	}
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	if err := r.Status().Update(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failure before the timeout deletes the Job and keeps the finalizer
+	result, err := r.reconcileDelete(ctx, archiver)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Error("expected a requeue after the final sync failed")
+	}
+	if !slices.Contains(archiver.Finalizers, archiverFinalizer) {
+		t.Fatal("finalizer removed after a failed final sync before the timeout")
+	}
+	if c := meta.FindStatusCondition(archiver.Status.Conditions, "FinalSyncing"); c == nil || c.Status != metav1.ConditionTrue || c.Reason != "FinalSyncRetrying" {
+		t.Errorf("expected FinalSyncing=True with FinalSyncRetrying, got %+v", c)
+	}
+	if err := r.Get(ctx, key, &batchv1.Job{}); !errors.IsNotFound(err) {
+		t.Fatalf("expected the failed Job deleted, got %v", err)
+	}
+
+	// The next reconcile starts a new Job
+	if _, err := r.reconcileDelete(ctx, archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(ctx, key, job); err != nil || jobFinished(job, batchv1.JobFailed) {
+		t.Fatalf("expected a new final sync Job: job=%+v err=%v", job.Status, err)
+	}
+	if !slices.Contains(archiver.Finalizers, archiverFinalizer) {
+		t.Error("finalizer removed while the retried final sync runs")
+	}
+}
+
+func TestReconcileDelete_FailedFinalSyncAfterTimeout(t *testing.T) {
+	ctx := context.Background()
+	archiver := newDeletingArchiver(time.Now().Add(-time.Hour))
+	archiver.Spec.Sync.FinalSyncTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+	r := newFinalSyncTestReconciler(archiver)
+	failed := r.constructSyncJob(archiver)
+	failed.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	if err := r.Create(ctx, failed); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.reconcileDelete(ctx, archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slices.Contains(archiver.Finalizers, archiverFinalizer) {
+		t.Error("expected finalizer removed once the timeout passed")
+	}
+	if c := meta.FindStatusCondition(archiver.Status.Conditions, "FinalSyncing"); c == nil || c.Reason != "FinalSyncTimedOut" {
+		t.Errorf("expected FinalSyncTimedOut, got %+v", c)
+	}
+}
+
+func TestReconcileDelete_FinalSyncTimeout(t *testing.T) {
+	ctx := context.Background()
+	archiver := newDeletingArchiver(time.Now().Add(-time.Hour))
+	archiver.Spec.Sync.FinalSyncTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+	r := newFinalSyncTestReconciler(archiver)
+
+	if _, err := r.reconcileDelete(ctx, archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slices.Contains(archiver.Finalizers, archiverFinalizer) {
+		t.Error("expected finalizer removed once the timeout passed")
+	}
+	if c := meta.FindStatusCondition(archiver.Status.Conditions, "FinalSyncing"); c == nil || c.Reason != "FinalSyncTimedOut" {
+		t.Errorf("expected FinalSyncTimedOut, got %+v", c)
+	}
+}
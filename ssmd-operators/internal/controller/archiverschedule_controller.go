@@ -0,0 +1,701 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/feedconfig"
+)
+
+const (
+	// archiverScheduleLabel and archiverDateLabel identify the Archivers created by a schedule
+	archiverScheduleLabel = "ssmd.ssmd.io/archiver-schedule"
+	archiverDateLabel     = "ssmd.ssmd.io/date"
+
+	defaultRolloverTime = "23:30"
+	defaultDeleteAfter  = time.Hour
+
+	// maxScheduleRequeue bounds how long the schedule sleeps between reconciles
+	maxScheduleRequeue = time.Hour
+
+	dateLayout = "2006-01-02"
+
+	defaultCompactionImage    = "ghcr.io/aaronwald/ssmd-data-ts:latest"
+	defaultCompactionSchedule = "30 2 * * *"
+
+	defaultSecmasterArchiveImage    = "ghcr.io/aaronwald/ssmd-data-ts:latest"
+	defaultSecmasterArchiveSchedule = "15 0 * * *"
+)
+
+// ArchiverScheduleReconciler reconciles a ArchiverSchedule object
+type ArchiverScheduleReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events for reconcile actions (skipped if nil)
+	Recorder events.EventRecorder
+
+	// CompactionPriorityClassName is used for compaction Jobs (defaults to the
+	// archiver template's priority class)
+	CompactionPriorityClassName string
+
+	// CompactionResources are the compaction container's resources (unset if nil)
+	CompactionResources *corev1.ResourceRequirements
+
+	// MaxConcurrentReconciles is the number of parallel reconciles (1 if unset)
+	MaxConcurrentReconciles int
+
+	// SecmasterPriorityClassName is used for secmaster archive Jobs (defaults
+	// to the archiver template's priority class)
+	SecmasterPriorityClassName string
+
+	// SecmasterResources are the secmaster archive container's resources (unset if nil)
+	SecmasterResources *corev1.ResourceRequirements
+
+	// now is overridable in tests
+	now func() time.Time
+}
+
+// +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=archiverschedules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=archiverschedules/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=archiverschedules/finalizers,verbs=update
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=archivers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile keeps one Archiver per trading day: tomorrow's is created at the
+// rollover time and yesterday's is deleted (running its final sync) once
+// deleteAfter has passed. With compaction, a daily CronJob compacts the
+// previous day's archive to parquet; with secmasterArchive, another snapshots
+// the secmaster tables.
+func (r *ArchiverScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	// Fetch the ArchiverSchedule instance
+	schedule := &ssmdv1alpha1.ArchiverSchedule{}
+	if err := r.Get(ctx, req.NamespacedName, schedule); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("ArchiverSchedule resource not found, likely deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get ArchiverSchedule")
+		return ctrl.Result{}, err
+	}
+
+	// Resolve the trading day timezone
+	loc, err := r.resolveTimezone(ctx, schedule)
+	if err != nil {
+		log.Error(err, "Invalid timezone")
+		meta.SetStatusCondition(&schedule.Status.Conditions, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			Reason:             "TimezoneInvalid",
+			Message:            err.Error(),
+			LastTransitionTime: metav1.Now(),
+		})
+		if err := r.Status().Update(ctx, schedule); err != nil {
+			return ctrl.Result{}, err
+		}
+		// The feed ConfigMap is not watched, so check again later
+		return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
+	}
+
+	hour, minute, err := parseRolloverTime(schedule.Spec.RolloverTime)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := r.clock().In(loc)
+	today := startOfDay(now)
+	rolloverAt := time.Date(today.Year(), today.Month(), today.Day(), hour, minute, 0, 0, loc)
+
+	// Today's Archiver always runs; tomorrow's starts at the rollover time
+	desired := []time.Time{today}
+	if !now.Before(rolloverAt) {
+		desired = append(desired, today.AddDate(0, 0, 1))
+	}
+
+	for _, day := range desired {
+		created, err := r.reconcileArchiver(ctx, schedule, day.Format(dateLayout))
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if created {
+			schedule.Status.LastRolloverAt = &metav1.Time{Time: r.clock()}
+		}
+	}
+
+	// Delete Archivers whose day ended more than deleteAfter ago
+	nextDeletion, err := r.deleteExpiredArchivers(ctx, schedule, now, loc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Reconcile the daily archive compaction CronJob
+	if err := r.reconcileCompactionCronJob(ctx, schedule, loc); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Reconcile the daily secmaster snapshot CronJob
+	if err := r.reconcileSecmasterCronJob(ctx, schedule, loc); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateStatus(ctx, schedule, loc, today); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Wake up for the next rollover or deletion
+	next := rolloverAt
+	if !now.Before(rolloverAt) {
+		next = rolloverAt.AddDate(0, 0, 1)
+	}
+	if !nextDeletion.IsZero() && nextDeletion.Before(next) {
+		next = nextDeletion
+	}
+	return ctrl.Result{RequeueAfter: min(next.Sub(now)+time.Second, maxScheduleRequeue)}, nil
+}
+
+// reconcileArchiver creates the Archiver for a day if it does not exist.
+// Existing Archivers are left as is; template changes apply from the next day.
+func (r *ArchiverScheduleReconciler) reconcileArchiver(ctx context.Context, schedule *ssmdv1alpha1.ArchiverSchedule, date string) (bool, error) {
+	log := logf.FromContext(ctx)
+
+	archiver := &ssmdv1alpha1.Archiver{}
+	err := r.Get(ctx, types.NamespacedName{Name: archiverNameForDate(schedule, date), Namespace: schedule.Namespace}, archiver)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.IsNotFound(err) {
+		return false, err
+	}
+
+	archiver, err = r.constructArchiver(schedule, date)
+	if err != nil {
+		return false, err
+	}
+	if err := controllerutil.SetControllerReference(schedule, archiver, r.Scheme); err != nil {
+		return false, err
+	}
+	log.Info("Creating Archiver", "name", archiver.Name, "date", date)
+	if err := recordChildEvent(r.Recorder, schedule, eventActionCreate, "Archiver", archiver.Name, r.Create(ctx, archiver)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// constructArchiver builds the Archiver for a day from the schedule template
+func (r *ArchiverScheduleReconciler) constructArchiver(schedule *ssmdv1alpha1.ArchiverSchedule, date string) (*ssmdv1alpha1.Archiver, error) {
+	spec, err := renderArchiverTemplate(schedule.Spec.Template, date)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssmdv1alpha1.Archiver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      archiverNameForDate(schedule, date),
+			Namespace: schedule.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":       "ssmd-archiver",
+				"app.kubernetes.io/instance":   archiverNameForDate(schedule, date),
+				"app.kubernetes.io/managed-by": "ssmd-operator",
+				archiverScheduleLabel:          schedule.Name,
+				archiverDateLabel:              date,
+			},
+		},
+		Spec: spec,
+	}, nil
+}
+
+// deleteExpiredArchivers deletes the schedule's Archivers whose day ended at
+// least deleteAfter ago, and returns when the next one is due (zero if none).
+func (r *ArchiverScheduleReconciler) deleteExpiredArchivers(ctx context.Context, schedule *ssmdv1alpha1.ArchiverSchedule, now time.Time, loc *time.Location) (time.Time, error) {
+	log := logf.FromContext(ctx)
+
+	archivers := &ssmdv1alpha1.ArchiverList{}
+	if err := r.List(ctx, archivers, client.InNamespace(schedule.Namespace), client.MatchingLabels{archiverScheduleLabel: schedule.Name}); err != nil {
+		return time.Time{}, err
+	}
+
+	deleteAfter := defaultDeleteAfter
+	if schedule.Spec.DeleteAfter != nil {
+		deleteAfter = schedule.Spec.DeleteAfter.Duration
+	}
+
+	var next time.Time
+	for i := range archivers.Items {
+		archiver := &archivers.Items[i]
+		if !archiver.DeletionTimestamp.IsZero() {
+			continue
+		}
+		day, err := time.ParseInLocation(dateLayout, archiver.Labels[archiverDateLabel], loc)
+		if err != nil {
+			log.Info("Skipping Archiver with invalid date label", "name", archiver.Name)
+			continue
+		}
+
+		expiresAt := day.AddDate(0, 0, 1).Add(deleteAfter)
+		if now.Before(expiresAt) {
+			if next.IsZero() || expiresAt.Before(next) {
+				next = expiresAt
+			}
+			continue
+		}
+
+		// Deleting runs the Archiver's finalizer, which does the final sync
+		log.Info("Deleting expired Archiver", "name", archiver.Name)
+		if err := recordChildEvent(r.Recorder, schedule, eventActionDelete, "Archiver", archiver.Name, r.Delete(ctx, archiver)); err != nil && !errors.IsNotFound(err) {
+			return time.Time{}, err
+		}
+	}
+	return next, nil
+}
+
+// compactionCronJobName returns the archive compaction CronJob name for a schedule
+func compactionCronJobName(schedule *ssmdv1alpha1.ArchiverSchedule) string {
+	return fmt.Sprintf("%s-compact", schedule.Name)
+}
+
+// compactionBucket returns compaction.bucket, else the template's remote bucket
+func compactionBucket(schedule *ssmdv1alpha1.ArchiverSchedule) string {
+	if cfg := schedule.Spec.Compaction; cfg != nil && cfg.Bucket != "" {
+		return cfg.Bucket
+	}
+	if storage := schedule.Spec.Template.Storage; storage != nil && storage.Remote != nil {
+		return storage.Remote.Bucket
+	}
+	return ""
+}
+
+// compactionEnabled reports whether the schedule wants the compaction CronJob
+func compactionEnabled(schedule *ssmdv1alpha1.ArchiverSchedule) bool {
+	cfg := schedule.Spec.Compaction
+	return cfg != nil && cfg.Enabled && compactionBucket(schedule) != ""
+}
+
+// constructCompactionCronJob builds the CronJob running "ssmd data compact" on
+// the previous trading day, which writes ticker-partitioned parquet under
+// <date>/compacted/ and records it in the day's manifest.json
+func (r *ArchiverScheduleReconciler) constructCompactionCronJob(schedule *ssmdv1alpha1.ArchiverSchedule, loc *time.Location) *batchv1.CronJob {
+	cfg := schedule.Spec.Compaction
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "ssmd-archive-compaction",
+		"app.kubernetes.io/instance":   schedule.Name,
+		"app.kubernetes.io/managed-by": "ssmd-operator",
+		archiverScheduleLabel:          schedule.Name,
+	}
+
+	image := defaultCompactionImage
+	if cfg.Image != "" {
+		image = cfg.Image
+	}
+	cronSchedule := defaultCompactionSchedule
+	if cfg.Schedule != "" {
+		cronSchedule = cfg.Schedule
+	}
+	timeZone := loc.String()
+
+	args := []string{"data", "compact", schedule.Spec.Feed, "yesterday", "--bucket", compactionBucket(schedule)}
+	if cfg.DeleteOriginals {
+		args = append(args, "--delete-originals")
+	}
+
+	// Compaction pods share the archiver's identity, so they can rewrite the same bucket
+	template := schedule.Spec.Template
+	podSpec := corev1.PodSpec{
+		PriorityClassName:  priorityClassName(r.CompactionPriorityClassName, template.PriorityClassName),
+		ServiceAccountName: template.ServiceAccountName,
+		RestartPolicy:      corev1.RestartPolicyNever,
+		Containers: []corev1.Container{{
+			Name:      "compact",
+			Image:     image,
+			Resources: containerResources(nil, r.CompactionResources),
+			// DuckDB writes the parquet files, so the CLI needs FFI and /tmp
+			Command: []string{"deno", "run", "--allow-net", "--allow-env", "--allow-read", "--allow-sys", "--allow-ffi", "--allow-write=/tmp", "src/cli/main.ts"},
+			Args:    args,
+			// "yesterday" is resolved in TZ, so it names the trading day that just ended
+			Env: []corev1.EnvVar{{Name: "TZ", Value: timeZone}},
+		}},
+		ImagePullSecrets: imagePullSecrets(template.PodTemplate),
+	}
+	if template.PodTemplate != nil && template.PodTemplate.ServiceAccountName != "" {
+		podSpec.ServiceAccountName = template.PodTemplate.ServiceAccountName
+	}
+
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      compactionCronJobName(schedule),
+			Namespace: schedule.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   cronSchedule,
+			TimeZone:                   &timeZone,
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: int32Ptr(1),
+			FailedJobsHistoryLimit:     int32Ptr(3),
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: batchv1.JobSpec{
+					// Compaction checks before it deletes anything, so a failed run
+					// is safe to retry once; after that it needs a look
+					BackoffLimit: int32Ptr(1),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: labels,
+						},
+						Spec: podSpec,
+					},
+				},
+			},
+		},
+	}
+}
+
+// reconcileCompactionCronJob ensures the compaction CronJob matches
+// spec.compaction, deleting it when compaction is disabled
+func (r *ArchiverScheduleReconciler) reconcileCompactionCronJob(ctx context.Context, schedule *ssmdv1alpha1.ArchiverSchedule, loc *time.Location) error {
+	log := logf.FromContext(ctx)
+
+	cronJob := &batchv1.CronJob{}
+	err := r.Get(ctx, types.NamespacedName{Name: compactionCronJobName(schedule), Namespace: schedule.Namespace}, cronJob)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if !compactionEnabled(schedule) {
+		if exists && metav1.IsControlledBy(cronJob, schedule) {
+			log.Info("Deleting compaction CronJob", "name", cronJob.Name)
+			if err := recordChildEvent(r.Recorder, schedule, eventActionDelete, "CronJob", cronJob.Name, r.Delete(ctx, cronJob)); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	desired := r.constructCompactionCronJob(schedule, loc)
+	if !exists {
+		if err := controllerutil.SetControllerReference(schedule, desired, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("Creating compaction CronJob", "name", desired.Name, "schedule", desired.Spec.Schedule)
+		return recordChildEvent(r.Recorder, schedule, eventActionCreate, "CronJob", desired.Name, r.Create(ctx, desired))
+	}
+
+	if scheduleCronJobNeedsUpdate(cronJob, desired) {
+		cronJob.Spec = desired.Spec
+		log.Info("Updating compaction CronJob", "name", cronJob.Name)
+		return recordChildEvent(r.Recorder, schedule, eventActionUpdate, "CronJob", cronJob.Name, r.Update(ctx, cronJob))
+	}
+	return nil
+}
+
+// scheduleCronJobNeedsUpdate checks the fields a schedule controls on its CronJobs
+func scheduleCronJobNeedsUpdate(current, desired *batchv1.CronJob) bool {
+	if current.Spec.Schedule != desired.Spec.Schedule ||
+		!reflect.DeepEqual(current.Spec.TimeZone, desired.Spec.TimeZone) {
+		return true
+	}
+	currentPod := &current.Spec.JobTemplate.Spec.Template.Spec
+	desiredPod := &desired.Spec.JobTemplate.Spec.Template.Spec
+	if len(currentPod.Containers) == 0 {
+		return true
+	}
+	currentContainer, desiredContainer := currentPod.Containers[0], desiredPod.Containers[0]
+	return currentContainer.Image != desiredContainer.Image ||
+		!reflect.DeepEqual(currentContainer.Command, desiredContainer.Command) ||
+		!reflect.DeepEqual(currentContainer.Args, desiredContainer.Args) ||
+		!reflect.DeepEqual(currentContainer.Env, desiredContainer.Env) ||
+		currentPod.ServiceAccountName != desiredPod.ServiceAccountName ||
+		!equality.Semantic.DeepEqual(currentContainer.Resources, desiredContainer.Resources) ||
+		currentPod.PriorityClassName != desiredPod.PriorityClassName ||
+		!reflect.DeepEqual(currentPod.ImagePullSecrets, desiredPod.ImagePullSecrets)
+}
+
+// secmasterCronJobName returns the secmaster snapshot CronJob name for a schedule
+func secmasterCronJobName(schedule *ssmdv1alpha1.ArchiverSchedule) string {
+	return fmt.Sprintf("%s-secmaster", schedule.Name)
+}
+
+// secmasterArchiveBucket returns secmasterArchive.bucket, else the template's remote bucket
+func secmasterArchiveBucket(schedule *ssmdv1alpha1.ArchiverSchedule) string {
+	if cfg := schedule.Spec.SecmasterArchive; cfg != nil && cfg.Bucket != "" {
+		return cfg.Bucket
+	}
+	if storage := schedule.Spec.Template.Storage; storage != nil && storage.Remote != nil {
+		return storage.Remote.Bucket
+	}
+	return ""
+}
+
+// secmasterArchiveEnabled reports whether the schedule wants the snapshot CronJob
+func secmasterArchiveEnabled(schedule *ssmdv1alpha1.ArchiverSchedule) bool {
+	cfg := schedule.Spec.SecmasterArchive
+	return cfg != nil && cfg.Enabled && cfg.DatabaseSecretRef.Name != "" && secmasterArchiveBucket(schedule) != ""
+}
+
+// constructSecmasterCronJob builds the CronJob running "ssmd secmaster archive",
+// which writes the day's events/markets parquet under secmaster/YYYY-MM-DD/
+func (r *ArchiverScheduleReconciler) constructSecmasterCronJob(schedule *ssmdv1alpha1.ArchiverSchedule, loc *time.Location) *batchv1.CronJob {
+	cfg := schedule.Spec.SecmasterArchive
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "ssmd-secmaster-archive",
+		"app.kubernetes.io/instance":   schedule.Name,
+		"app.kubernetes.io/managed-by": "ssmd-operator",
+		archiverScheduleLabel:          schedule.Name,
+	}
+
+	image := defaultSecmasterArchiveImage
+	if cfg.Image != "" {
+		image = cfg.Image
+	}
+	cronSchedule := defaultSecmasterArchiveSchedule
+	if cfg.Schedule != "" {
+		cronSchedule = cfg.Schedule
+	}
+	timeZone := loc.String()
+
+	// Archive pods share the archiver's identity, so they can write the same bucket
+	template := schedule.Spec.Template
+	podSpec := corev1.PodSpec{
+		PriorityClassName:  priorityClassName(r.SecmasterPriorityClassName, template.PriorityClassName),
+		ServiceAccountName: template.ServiceAccountName,
+		RestartPolicy:      corev1.RestartPolicyNever,
+		Containers: []corev1.Container{{
+			Name:      "secmaster-archive",
+			Image:     image,
+			Resources: containerResources(nil, r.SecmasterResources),
+			// DuckDB writes the parquet files, so the CLI needs FFI and /tmp
+			Command: []string{"deno", "run", "--allow-net", "--allow-env", "--allow-read", "--allow-sys", "--allow-ffi", "--allow-write=/tmp", "src/cli/main.ts"},
+			Args:    []string{"secmaster", "archive", "--bucket", secmasterArchiveBucket(schedule)},
+			Env: []corev1.EnvVar{{
+				Name: "DATABASE_URL",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: cfg.DatabaseSecretRef,
+						Key:                  "database-url",
+					},
+				},
+			}},
+		}},
+		ImagePullSecrets: imagePullSecrets(template.PodTemplate),
+	}
+	if template.PodTemplate != nil && template.PodTemplate.ServiceAccountName != "" {
+		podSpec.ServiceAccountName = template.PodTemplate.ServiceAccountName
+	}
+
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secmasterCronJobName(schedule),
+			Namespace: schedule.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   cronSchedule,
+			TimeZone:                   &timeZone,
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: int32Ptr(1),
+			FailedJobsHistoryLimit:     int32Ptr(3),
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: batchv1.JobSpec{
+					// Retry a failed snapshot once; after that the next day's run takes over
+					BackoffLimit: int32Ptr(1),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: labels,
+						},
+						Spec: podSpec,
+					},
+				},
+			},
+		},
+	}
+}
+
+// reconcileSecmasterCronJob ensures the secmaster snapshot CronJob matches
+// secmasterArchive, deleting it when the snapshot is disabled
+func (r *ArchiverScheduleReconciler) reconcileSecmasterCronJob(ctx context.Context, schedule *ssmdv1alpha1.ArchiverSchedule, loc *time.Location) error {
+	log := logf.FromContext(ctx)
+
+	cronJob := &batchv1.CronJob{}
+	err := r.Get(ctx, types.NamespacedName{Name: secmasterCronJobName(schedule), Namespace: schedule.Namespace}, cronJob)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if !secmasterArchiveEnabled(schedule) {
+		if exists && metav1.IsControlledBy(cronJob, schedule) {
+			log.Info("Deleting secmaster CronJob", "name", cronJob.Name)
+			if err := recordChildEvent(r.Recorder, schedule, eventActionDelete, "CronJob", cronJob.Name, r.Delete(ctx, cronJob)); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	desired := r.constructSecmasterCronJob(schedule, loc)
+	if !exists {
+		if err := controllerutil.SetControllerReference(schedule, desired, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("Creating secmaster CronJob", "name", desired.Name, "schedule", desired.Spec.Schedule)
+		return recordChildEvent(r.Recorder, schedule, eventActionCreate, "CronJob", desired.Name, r.Create(ctx, desired))
+	}
+
+	if scheduleCronJobNeedsUpdate(cronJob, desired) {
+		cronJob.Spec = desired.Spec
+		log.Info("Updating secmaster CronJob", "name", cronJob.Name)
+		return recordChildEvent(r.Recorder, schedule, eventActionUpdate, "CronJob", cronJob.Name, r.Update(ctx, cronJob))
+	}
+	return nil
+}
+
+// updateStatus records the current day and the schedule's Archivers
+func (r *ArchiverScheduleReconciler) updateStatus(ctx context.Context, schedule *ssmdv1alpha1.ArchiverSchedule, loc *time.Location, today time.Time) error {
+	archivers := &ssmdv1alpha1.ArchiverList{}
+	if err := r.List(ctx, archivers, client.InNamespace(schedule.Namespace), client.MatchingLabels{archiverScheduleLabel: schedule.Name}); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(archivers.Items))
+	for _, archiver := range archivers.Items {
+		if archiver.DeletionTimestamp.IsZero() {
+			names = append(names, archiver.Name)
+		}
+	}
+	sort.Strings(names)
+
+	schedule.Status.Timezone = loc.String()
+	schedule.Status.CurrentDate = today.Format(dateLayout)
+	schedule.Status.Archivers = names
+
+	meta.SetStatusCondition(&schedule.Status.Conditions, metav1.Condition{
+		Type:               "Ready",
+		Status:             metav1.ConditionTrue,
+		Reason:             "Scheduled",
+		Message:            fmt.Sprintf("Archiving %s (%s)", schedule.Status.CurrentDate, schedule.Status.Timezone),
+		LastTransitionTime: metav1.Now(),
+	})
+
+	return r.Status().Update(ctx, schedule)
+}
+
+// resolveTimezone returns spec.timezone, else the feed calendar timezone, else UTC
+func (r *ArchiverScheduleReconciler) resolveTimezone(ctx context.Context, schedule *ssmdv1alpha1.ArchiverSchedule) (*time.Location, error) {
+	name := schedule.Spec.Timezone
+	if name == "" {
+		feedConfig, err := feedconfig.Load(ctx, r.Client, schedule.Namespace, schedule.Spec.Feed)
+		if err != nil {
+			return nil, err
+		}
+		name = feedConfig.Timezone()
+	}
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// clock returns the current time
+func (r *ArchiverScheduleReconciler) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// archiverNameForDate returns the name of a day's Archiver
+func archiverNameForDate(schedule *ssmdv1alpha1.ArchiverSchedule, date string) string {
+	return fmt.Sprintf("%s-%s", schedule.Name, date)
+}
+
+// renderArchiverTemplate replaces "{date}" in every string field of the template
+func renderArchiverTemplate(template ssmdv1alpha1.ArchiverSpec, date string) (ssmdv1alpha1.ArchiverSpec, error) {
+	var spec ssmdv1alpha1.ArchiverSpec
+	raw, err := json.Marshal(template)
+	if err != nil {
+		return spec, err
+	}
+	rendered := strings.ReplaceAll(string(raw), "{date}", date)
+	if err := json.Unmarshal([]byte(rendered), &spec); err != nil {
+		return spec, fmt.Errorf("failed to render archiver template: %w", err)
+	}
+	return spec, nil
+}
+
+// parseRolloverTime parses "HH:MM", defaulting to 23:30
+func parseRolloverTime(value string) (int, int, error) {
+	if value == "" {
+		value = defaultRolloverTime
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid rolloverTime %q: %w", value, err)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// startOfDay returns midnight of t's day in t's location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ArchiverScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ssmdv1alpha1.ArchiverSchedule{}).
+		Owns(&ssmdv1alpha1.Archiver{}).
+		Owns(&batchv1.CronJob{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		Named("archiverschedule").
+		Complete(r)
+}
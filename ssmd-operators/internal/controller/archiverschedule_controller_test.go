@@ -0,0 +1,514 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestArchiverSchedule() *ssmdv1alpha1.ArchiverSchedule {
+	return &ssmdv1alpha1.ArchiverSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "kalshi", Namespace: "ssmd", UID: "schedule-uid"},
+		Spec: ssmdv1alpha1.ArchiverScheduleSpec{
+			Feed:         "kalshi",
+			Timezone:     "America/New_York",
+			RolloverTime: "23:30",
+			DeleteAfter:  &metav1.Duration{Duration: time.Hour},
+			Template: ssmdv1alpha1.ArchiverSpec{
+				Image: "ghcr.io/aaronwald/ssmd-archiver:0.4.8",
+				Source: &ssmdv1alpha1.ArchiverSourceConfig{
+					Stream:   "PROD_KALSHI",
+					Consumer: "archiver-{date}",
+				},
+			},
+		},
+	}
+}
+
+func newScheduledArchiver(schedule *ssmdv1alpha1.ArchiverSchedule, date string) *ssmdv1alpha1.Archiver {
+	return &ssmdv1alpha1.Archiver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      archiverNameForDate(schedule, date),
+			Namespace: schedule.Namespace,
+			Labels:    map[string]string{archiverScheduleLabel: schedule.Name, archiverDateLabel: date},
+		},
+	}
+}
+
+// newScheduleTestReconciler returns a reconciler whose clock is the given New York time
+func newScheduleTestReconciler(t *testing.T, now string, objs ...client.Object) *ArchiverScheduleReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at, err := time.ParseInLocation("2006-01-02 15:04", now, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &ArchiverScheduleReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithStatusSubresource(&ssmdv1alpha1.ArchiverSchedule{}).Build(),
+		Scheme: scheme,
+		now:    func() time.Time { return at },
+	}
+}
+
+func reconcileSchedule(t *testing.T, r *ArchiverScheduleReconciler) ctrl.Result {
+	t.Helper()
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "kalshi", Namespace: "ssmd"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result
+}
+
+func archiverExists(t *testing.T, r *ArchiverScheduleReconciler, name string) bool {
+	t.Helper()
+	err := r.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "ssmd"}, &ssmdv1alpha1.Archiver{})
+	if err != nil && !errors.IsNotFound(err) {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+// --- TestArchiverScheduleReconcile ---
+
+func TestArchiverScheduleReconcile_CreatesTodaysArchiver(t *testing.T) {
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", newTestArchiverSchedule())
+
+	result := reconcileSchedule(t, r)
+
+	archiver := &ssmdv1alpha1.Archiver{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "kalshi-2026-01-04", Namespace: "ssmd"}, archiver); err != nil {
+		t.Fatalf("expected today's Archiver: %v", err)
+	}
+	if archiver.Spec.Source.Consumer != "archiver-2026-01-04" {
+		t.Errorf("expected {date} substituted in consumer, got %s", archiver.Spec.Source.Consumer)
+	}
+	if archiver.Labels[archiverDateLabel] != "2026-01-04" {
+		t.Errorf("expected date label, got %v", archiver.Labels)
+	}
+	if len(archiver.OwnerReferences) != 1 || archiver.OwnerReferences[0].Kind != "ArchiverSchedule" {
+		t.Errorf("expected Archiver owned by the schedule, got %+v", archiver.OwnerReferences)
+	}
+	if archiverExists(t, r, "kalshi-2026-01-05") {
+		t.Error("tomorrow's Archiver should not exist before the rollover time")
+	}
+	if result.RequeueAfter != maxScheduleRequeue {
+		t.Errorf("expected requeue capped at %s, got %s", maxScheduleRequeue, result.RequeueAfter)
+	}
+
+	schedule := &ssmdv1alpha1.ArchiverSchedule{}
+	_ = r.Get(context.Background(), types.NamespacedName{Name: "kalshi", Namespace: "ssmd"}, schedule)
+	if schedule.Status.CurrentDate != "2026-01-04" || schedule.Status.Timezone != "America/New_York" {
+		t.Errorf("unexpected status: %+v", schedule.Status)
+	}
+	if schedule.Status.LastRolloverAt == nil {
+		t.Error("expected LastRolloverAt to be set")
+	}
+	if !meta.IsStatusConditionTrue(schedule.Status.Conditions, "Ready") {
+		t.Errorf("expected Ready=True, got %+v", schedule.Status.Conditions)
+	}
+}
+
+func TestArchiverScheduleReconcile_RolloverCreatesTomorrow(t *testing.T) {
+	schedule := newTestArchiverSchedule()
+	r := newScheduleTestReconciler(t, "2026-01-04 23:45", schedule, newScheduledArchiver(schedule, "2026-01-04"))
+
+	result := reconcileSchedule(t, r)
+
+	if !archiverExists(t, r, "kalshi-2026-01-05") {
+		t.Error("expected tomorrow's Archiver after the rollover time")
+	}
+	if !archiverExists(t, r, "kalshi-2026-01-04") {
+		t.Error("today's Archiver should keep running until its day ends")
+	}
+	if result.RequeueAfter != maxScheduleRequeue {
+		t.Errorf("expected requeue capped at %s, got %s", maxScheduleRequeue, result.RequeueAfter)
+	}
+}
+
+func TestArchiverScheduleReconcile_RequeuesAtRollover(t *testing.T) {
+	r := newScheduleTestReconciler(t, "2026-01-04 23:00", newTestArchiverSchedule())
+
+	result := reconcileSchedule(t, r)
+
+	if result.RequeueAfter != 30*time.Minute+time.Second {
+		t.Errorf("expected requeue at the rollover time, got %s", result.RequeueAfter)
+	}
+}
+
+func TestArchiverScheduleReconcile_DeletesExpiredArchiver(t *testing.T) {
+	schedule := newTestArchiverSchedule()
+	r := newScheduleTestReconciler(t, "2026-01-05 01:30", schedule,
+		newScheduledArchiver(schedule, "2026-01-04"), newScheduledArchiver(schedule, "2026-01-05"))
+
+	reconcileSchedule(t, r)
+
+	if archiverExists(t, r, "kalshi-2026-01-04") {
+		t.Error("expected yesterday's Archiver deleted after deleteAfter")
+	}
+	if !archiverExists(t, r, "kalshi-2026-01-05") {
+		t.Error("expected today's Archiver to remain")
+	}
+}
+
+func TestArchiverScheduleReconcile_KeepsArchiverUntilDeleteAfter(t *testing.T) {
+	schedule := newTestArchiverSchedule()
+	r := newScheduleTestReconciler(t, "2026-01-05 00:30", schedule,
+		newScheduledArchiver(schedule, "2026-01-04"), newScheduledArchiver(schedule, "2026-01-05"))
+
+	result := reconcileSchedule(t, r)
+
+	if !archiverExists(t, r, "kalshi-2026-01-04") {
+		t.Error("yesterday's Archiver should remain until deleteAfter has passed")
+	}
+	if result.RequeueAfter != 30*time.Minute+time.Second {
+		t.Errorf("expected requeue at the deletion time, got %s", result.RequeueAfter)
+	}
+}
+
+func TestArchiverScheduleReconcile_FeedCalendarTimezone(t *testing.T) {
+	schedule := newTestArchiverSchedule()
+	schedule.Spec.Timezone = ""
+	feed := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "feed-kalshi", Namespace: "ssmd"},
+		Data:       map[string]string{"feed.yaml": "name: kalshi\ncalendar:\n  timezone: Asia/Tokyo\n"},
+	}
+	// 10:00 in New York is 00:00 the next day in Tokyo
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", schedule, feed)
+
+	reconcileSchedule(t, r)
+
+	if !archiverExists(t, r, "kalshi-2026-01-05") {
+		t.Error("expected the Archiver dated in the feed calendar timezone")
+	}
+}
+
+func TestArchiverScheduleReconcile_InvalidTimezone(t *testing.T) {
+	schedule := newTestArchiverSchedule()
+	schedule.Spec.Timezone = "Mars/Olympus_Mons"
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", schedule)
+
+	reconcileSchedule(t, r)
+
+	_ = r.Get(context.Background(), types.NamespacedName{Name: "kalshi", Namespace: "ssmd"}, schedule)
+	condition := meta.FindStatusCondition(schedule.Status.Conditions, "Ready")
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "TimezoneInvalid" {
+		t.Errorf("expected Ready=False/TimezoneInvalid, got %+v", condition)
+	}
+	if archiverExists(t, r, "kalshi-2026-01-04") {
+		t.Error("no Archiver should be created with an invalid timezone")
+	}
+}
+
+// --- TestArchiverScheduleSecmasterArchive ---
+
+func newSecmasterArchiveSchedule() *ssmdv1alpha1.ArchiverSchedule {
+	schedule := newTestArchiverSchedule()
+	schedule.Spec.Template.ServiceAccountName = "ssmd-archiver"
+	schedule.Spec.Template.Storage = &ssmdv1alpha1.StorageConfig{
+		Remote: &ssmdv1alpha1.RemoteStorageConfig{Bucket: "ssmd-archive", Prefix: "kalshi/{date}"},
+	}
+	schedule.Spec.SecmasterArchive = &ssmdv1alpha1.SecmasterArchiveConfig{
+		Enabled:           true,
+		DatabaseSecretRef: corev1.LocalObjectReference{Name: "ssmd-db"},
+	}
+	return schedule
+}
+
+func TestArchiverScheduleReconcile_CreatesSecmasterCronJob(t *testing.T) {
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", newSecmasterArchiveSchedule())
+
+	reconcileSchedule(t, r)
+
+	cronJob := &batchv1.CronJob{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "kalshi-secmaster", Namespace: "ssmd"}, cronJob); err != nil {
+		t.Fatalf("expected secmaster CronJob: %v", err)
+	}
+	if cronJob.Spec.Schedule != defaultSecmasterArchiveSchedule {
+		t.Errorf("expected default schedule, got %q", cronJob.Spec.Schedule)
+	}
+	if cronJob.Spec.TimeZone == nil || *cronJob.Spec.TimeZone != "America/New_York" {
+		t.Errorf("expected schedule timezone, got %v", cronJob.Spec.TimeZone)
+	}
+	pod := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	container := pod.Containers[0]
+	if container.Image != defaultSecmasterArchiveImage {
+		t.Errorf("expected default image, got %s", container.Image)
+	}
+	wantArgs := []string{"secmaster", "archive", "--bucket", "ssmd-archive"}
+	if !reflect.DeepEqual(container.Args, wantArgs) {
+		t.Errorf("expected args %v, got %v", wantArgs, container.Args)
+	}
+	if len(container.Env) != 1 || container.Env[0].Name != "DATABASE_URL" ||
+		container.Env[0].ValueFrom.SecretKeyRef.Name != "ssmd-db" || container.Env[0].ValueFrom.SecretKeyRef.Key != "database-url" {
+		t.Errorf("expected DATABASE_URL from ssmd-db, got %+v", container.Env)
+	}
+	if pod.ServiceAccountName != "ssmd-archiver" {
+		t.Errorf("expected the archiver service account, got %q", pod.ServiceAccountName)
+	}
+	if len(cronJob.OwnerReferences) != 1 || cronJob.OwnerReferences[0].Kind != "ArchiverSchedule" {
+		t.Errorf("expected CronJob owned by the schedule, got %+v", cronJob.OwnerReferences)
+	}
+}
+
+func TestArchiverScheduleReconcile_SecmasterDefaults(t *testing.T) {
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", newSecmasterArchiveSchedule())
+	r.SecmasterPriorityClassName = "ssmd-batch"
+	r.SecmasterResources = &corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+	}
+
+	reconcileSchedule(t, r)
+
+	cronJob := &batchv1.CronJob{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "kalshi-secmaster", Namespace: "ssmd"}, cronJob); err != nil {
+		t.Fatalf("expected secmaster CronJob: %v", err)
+	}
+	pod := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	if pod.PriorityClassName != "ssmd-batch" {
+		t.Errorf("expected ssmd-batch priority class, got %q", pod.PriorityClassName)
+	}
+	if got := pod.Containers[0].Resources.Limits.Memory(); !got.Equal(resource.MustParse("256Mi")) {
+		t.Errorf("expected 256Mi memory limit, got %v", got)
+	}
+}
+
+func TestArchiverScheduleReconcile_UpdatesSecmasterCronJob(t *testing.T) {
+	schedule := newSecmasterArchiveSchedule()
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", schedule)
+	reconcileSchedule(t, r)
+
+	current := &ssmdv1alpha1.ArchiverSchedule{}
+	_ = r.Get(context.Background(), types.NamespacedName{Name: "kalshi", Namespace: "ssmd"}, current)
+	current.Spec.SecmasterArchive.Schedule = "0 2 * * *"
+	current.Spec.SecmasterArchive.Bucket = "ssmd-reference"
+	if err := r.Update(context.Background(), current); err != nil {
+		t.Fatal(err)
+	}
+	reconcileSchedule(t, r)
+
+	cronJob := &batchv1.CronJob{}
+	_ = r.Get(context.Background(), types.NamespacedName{Name: "kalshi-secmaster", Namespace: "ssmd"}, cronJob)
+	if cronJob.Spec.Schedule != "0 2 * * *" {
+		t.Errorf("expected updated schedule, got %q", cronJob.Spec.Schedule)
+	}
+	if args := cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args; args[len(args)-1] != "ssmd-reference" {
+		t.Errorf("expected bucket override, got %v", args)
+	}
+}
+
+func TestArchiverScheduleReconcile_DeletesSecmasterCronJobWhenDisabled(t *testing.T) {
+	schedule := newSecmasterArchiveSchedule()
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", schedule)
+	reconcileSchedule(t, r)
+
+	current := &ssmdv1alpha1.ArchiverSchedule{}
+	_ = r.Get(context.Background(), types.NamespacedName{Name: "kalshi", Namespace: "ssmd"}, current)
+	current.Spec.SecmasterArchive.Enabled = false
+	if err := r.Update(context.Background(), current); err != nil {
+		t.Fatal(err)
+	}
+	reconcileSchedule(t, r)
+
+	err := r.Get(context.Background(), types.NamespacedName{Name: "kalshi-secmaster", Namespace: "ssmd"}, &batchv1.CronJob{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected secmaster CronJob deleted, got %v", err)
+	}
+}
+
+func TestArchiverScheduleReconcile_NoSecmasterCronJobWithoutBucket(t *testing.T) {
+	schedule := newSecmasterArchiveSchedule()
+	schedule.Spec.Template.Storage = nil
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", schedule)
+
+	reconcileSchedule(t, r)
+
+	err := r.Get(context.Background(), types.NamespacedName{Name: "kalshi-secmaster", Namespace: "ssmd"}, &batchv1.CronJob{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected no secmaster CronJob without a bucket, got %v", err)
+	}
+}
+
+// --- TestRenderArchiverTemplate ---
+
+// newCompactingSchedule returns a schedule archiving to a bucket with compaction enabled
+func newCompactingSchedule() *ssmdv1alpha1.ArchiverSchedule {
+	schedule := newTestArchiverSchedule()
+	schedule.Spec.Template.Storage = &ssmdv1alpha1.StorageConfig{
+		Remote: &ssmdv1alpha1.RemoteStorageConfig{Type: "gcs", Bucket: "ssmd-archive"},
+	}
+	schedule.Spec.Compaction = &ssmdv1alpha1.CompactionConfig{Enabled: true}
+	return schedule
+}
+
+func getCompactionCronJob(t *testing.T, r *ArchiverScheduleReconciler) (*batchv1.CronJob, bool) {
+	t.Helper()
+	cronJob := &batchv1.CronJob{}
+	err := r.Get(context.Background(), types.NamespacedName{Name: "kalshi-compact", Namespace: "ssmd"}, cronJob)
+	if err != nil && !errors.IsNotFound(err) {
+		t.Fatal(err)
+	}
+	return cronJob, err == nil
+}
+
+func TestArchiverScheduleReconcile_CreatesCompactionCronJob(t *testing.T) {
+	schedule := newCompactingSchedule()
+	schedule.Spec.Compaction.DeleteOriginals = true
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", schedule)
+	r.CompactionPriorityClassName = "ssmd-batch"
+	r.CompactionResources = &corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+	}
+
+	reconcileSchedule(t, r)
+
+	cronJob, ok := getCompactionCronJob(t, r)
+	if !ok {
+		t.Fatal("expected the compaction CronJob")
+	}
+	if cronJob.Spec.Schedule != defaultCompactionSchedule {
+		t.Errorf("expected default schedule, got %s", cronJob.Spec.Schedule)
+	}
+	if cronJob.Spec.TimeZone == nil || *cronJob.Spec.TimeZone != "America/New_York" {
+		t.Errorf("expected the schedule's timezone, got %v", cronJob.Spec.TimeZone)
+	}
+	pod := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	want := []string{"data", "compact", "kalshi", "yesterday", "--bucket", "ssmd-archive", "--delete-originals"}
+	if !reflect.DeepEqual(pod.Containers[0].Args, want) {
+		t.Errorf("expected args %v, got %v", want, pod.Containers[0].Args)
+	}
+	if pod.Containers[0].Image != defaultCompactionImage {
+		t.Errorf("expected default image, got %s", pod.Containers[0].Image)
+	}
+	if len(pod.Containers[0].Env) != 1 || pod.Containers[0].Env[0].Value != "America/New_York" {
+		t.Errorf("expected TZ set to the schedule's timezone, got %+v", pod.Containers[0].Env)
+	}
+	if pod.PriorityClassName != "ssmd-batch" {
+		t.Errorf("expected compaction priority class, got %q", pod.PriorityClassName)
+	}
+	if got := pod.Containers[0].Resources.Limits.Memory(); !got.Equal(resource.MustParse("2Gi")) {
+		t.Errorf("expected 2Gi memory limit, got %v", got)
+	}
+	if len(cronJob.OwnerReferences) != 1 || cronJob.OwnerReferences[0].Kind != "ArchiverSchedule" {
+		t.Errorf("expected CronJob owned by the schedule, got %+v", cronJob.OwnerReferences)
+	}
+}
+
+func TestArchiverScheduleReconcile_UpdatesCompactionCronJob(t *testing.T) {
+	schedule := newCompactingSchedule()
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", schedule)
+	reconcileSchedule(t, r)
+
+	_ = r.Get(context.Background(), types.NamespacedName{Name: "kalshi", Namespace: "ssmd"}, schedule)
+	schedule.Spec.Compaction.Schedule = "0 4 * * *"
+	schedule.Spec.Compaction.Bucket = "other-bucket"
+	if err := r.Update(context.Background(), schedule); err != nil {
+		t.Fatal(err)
+	}
+	reconcileSchedule(t, r)
+
+	cronJob, _ := getCompactionCronJob(t, r)
+	if cronJob.Spec.Schedule != "0 4 * * *" {
+		t.Errorf("expected updated schedule, got %s", cronJob.Spec.Schedule)
+	}
+	if args := cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args; args[5] != "other-bucket" {
+		t.Errorf("expected compaction.bucket to override the template bucket, got %v", args)
+	}
+}
+
+func TestArchiverScheduleReconcile_DeletesCompactionCronJobWhenDisabled(t *testing.T) {
+	schedule := newCompactingSchedule()
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", schedule)
+	reconcileSchedule(t, r)
+
+	_ = r.Get(context.Background(), types.NamespacedName{Name: "kalshi", Namespace: "ssmd"}, schedule)
+	schedule.Spec.Compaction.Enabled = false
+	if err := r.Update(context.Background(), schedule); err != nil {
+		t.Fatal(err)
+	}
+	reconcileSchedule(t, r)
+
+	if _, ok := getCompactionCronJob(t, r); ok {
+		t.Error("expected the compaction CronJob deleted when compaction is disabled")
+	}
+}
+
+func TestArchiverScheduleReconcile_NoCompactionWithoutBucket(t *testing.T) {
+	schedule := newTestArchiverSchedule()
+	schedule.Spec.Compaction = &ssmdv1alpha1.CompactionConfig{Enabled: true}
+	r := newScheduleTestReconciler(t, "2026-01-04 10:00", schedule)
+
+	reconcileSchedule(t, r)
+
+	if _, ok := getCompactionCronJob(t, r); ok {
+		t.Error("compaction needs a bucket; expected no CronJob")
+	}
+}
+
+func TestRenderArchiverTemplate(t *testing.T) {
+	template := ssmdv1alpha1.ArchiverSpec{
+		Source: &ssmdv1alpha1.ArchiverSourceConfig{Consumer: "archiver-{date}"},
+		Storage: &ssmdv1alpha1.StorageConfig{
+			Remote: &ssmdv1alpha1.RemoteStorageConfig{Prefix: "kalshi/{date}"},
+		},
+	}
+
+	spec, err := renderArchiverTemplate(template, "2026-01-04")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Source.Consumer != "archiver-2026-01-04" || spec.Storage.Remote.Prefix != "kalshi/2026-01-04" {
+		t.Errorf("unexpected rendered spec: %+v", spec)
+	}
+	if template.Source.Consumer != "archiver-{date}" {
+		t.Error("template should not be modified")
+	}
+}
+
+func TestParseRolloverTime(t *testing.T) {
+	if h, m, err := parseRolloverTime(""); err != nil || h != 23 || m != 30 {
+		t.Errorf("expected default 23:30, got %d:%d (%v)", h, m, err)
+	}
+	if _, _, err := parseRolloverTime("25:00"); err == nil {
+		t.Error("expected error for invalid time")
+	}
+}
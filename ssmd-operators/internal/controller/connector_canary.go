@@ -0,0 +1,358 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/feedconfig"
+)
+
+const (
+	// canaryOfLabel marks a canary's Deployment, pods and ConfigMap with the Connector it tests
+	canaryOfLabel = "ssmd.io/canary-of"
+
+	// canarySubjectPrefix is prepended to a trafficless canary's subject prefix.
+	// Streams capture their feed's prefix, so canary subjects stay out of them.
+	canarySubjectPrefix = "canary."
+
+	// canaryPollInterval is how often a running canary is checked
+	canaryPollInterval = 30 * time.Second
+
+	defaultCanaryDurationMinutes     = 15
+	defaultCanaryMinRatePercent      = 80
+	defaultCanaryMaxErrorRatePercent = 1
+)
+
+// canaryName is the name the canary's Deployment and ConfigMap are derived from
+func canaryName(connector *ssmdv1alpha1.Connector) string {
+	return connector.Name + "-canary"
+}
+
+// canaryConnector derives the Connector the canary is built from: the primary's
+// spec with the canary image, one replica and no PDB, under its own name so its
+// pods, ConfigMap and CDC consumer are separate from the primary's
+func (r *ConnectorReconciler) canaryConnector(connector *ssmdv1alpha1.Connector, feedConfig *feedconfig.Config) *ssmdv1alpha1.Connector {
+	cfg := connector.Spec.Canary
+	canary := connector.DeepCopy()
+	canary.Name = canaryName(connector)
+	canary.Spec.Image = cfg.Image
+	canary.Spec.Replicas = int32Ptr(1)
+	canary.Spec.HighAvailability = nil
+	canary.Spec.Canary = nil
+	if canary.Spec.Cdc != nil && canary.Spec.Cdc.ConsumerName != "" {
+		canary.Spec.Cdc.ConsumerName += "-canary"
+	}
+	if cfg.Trafficless == nil || *cfg.Trafficless {
+		_, _, subjectPrefix := r.natsTransport(connector, feedConfig)
+		if canary.Spec.Transport == nil {
+			canary.Spec.Transport = &ssmdv1alpha1.TransportConfig{}
+		}
+		canary.Spec.Transport.SubjectPrefix = canarySubjectPrefix + subjectPrefix
+	}
+	return canary
+}
+
+// reconcileCanary runs spec.canary: it starts the canary Deployment, begins the
+// window once the canary pod is ready, and at the end of the window records a
+// verdict, promoting the image to spec.image on success. The canary is torn
+// down once it has a verdict or spec.canary is removed. Returns when to check
+// again (0 when no canary is running).
+func (r *ConnectorReconciler) reconcileCanary(ctx context.Context, connector *ssmdv1alpha1.Connector, feedConfig *feedconfig.Config) (time.Duration, error) {
+	cfg := connector.Spec.Canary
+	if cfg == nil {
+		if err := r.deleteCanary(ctx, connector); err != nil {
+			return 0, err
+		}
+		if connector.Status.Canary != nil {
+			connector.Status.Canary = nil
+			return 0, r.Status().Update(ctx, connector)
+		}
+		return 0, nil
+	}
+
+	status := connector.Status.Canary
+	if status != nil && status.Image == cfg.Image && status.Verdict != ssmdv1alpha1.CanaryVerdictRunning {
+		return 0, r.deleteCanary(ctx, connector)
+	}
+	if status == nil || status.Image != cfg.Image {
+		status = &ssmdv1alpha1.CanaryStatus{
+			Image:   cfg.Image,
+			Verdict: ssmdv1alpha1.CanaryVerdictRunning,
+			Message: "Waiting for the canary pod to become ready",
+		}
+		connector.Status.Canary = status
+	}
+
+	deployment, err := r.applyCanary(ctx, connector, feedConfig)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	window := time.Duration(int32OrDefault(cfg.DurationMinutes, defaultCanaryDurationMinutes)) * time.Minute
+
+	if status.StartedAt == nil {
+		if deployment.Status.ReadyReplicas == 0 {
+			if !deployment.CreationTimestamp.IsZero() && now.Sub(deployment.CreationTimestamp.Time) > window {
+				return 0, r.finishCanary(ctx, connector, false, fmt.Sprintf("Canary pod not ready after %s", window))
+			}
+			return canaryPollInterval, r.Status().Update(ctx, connector)
+		}
+		primary, _, scraped, err := r.scrapeConnectorPods(ctx, connector.Namespace, connector.Name)
+		if err != nil {
+			return 0, err
+		}
+		if scraped == 0 {
+			status.Message = "Waiting for primary metrics"
+			return canaryPollInterval, r.Status().Update(ctx, connector)
+		}
+		started := metav1.NewTime(now)
+		status.StartedAt = &started
+		status.PrimaryBaselineMessages = primary.MessagesPublished
+		status.PrimaryBaselineParseErrors = primary.ParseErrors
+		status.Message = fmt.Sprintf("Canary running until %s", now.Add(window).UTC().Format(time.RFC3339))
+		return canaryPollInterval, r.Status().Update(ctx, connector)
+	}
+
+	canary, restarts, canaryScraped, err := r.scrapeConnectorPods(ctx, connector.Namespace, canaryName(connector))
+	if err != nil {
+		return 0, err
+	}
+	if restarts > 0 {
+		return 0, r.finishCanary(ctx, connector, false, fmt.Sprintf("Canary restarted %d times", restarts))
+	}
+	elapsed := now.Sub(status.StartedAt.Time)
+	if elapsed < window {
+		return min(window-elapsed, canaryPollInterval), nil
+	}
+
+	primary, _, primaryScraped, err := r.scrapeConnectorPods(ctx, connector.Namespace, connector.Name)
+	if err != nil {
+		return 0, err
+	}
+	if primaryScraped == 0 || canaryScraped == 0 {
+		status.Message = "Window complete, waiting for metrics from both the primary and the canary"
+		return canaryPollInterval, r.Status().Update(ctx, connector)
+	}
+
+	result := evaluateCanary(cfg, canaryWindow{
+		Minutes:            elapsed.Minutes(),
+		PrimaryPods:        primaryScraped,
+		PrimaryMessages:    counterDelta(primary.MessagesPublished, status.PrimaryBaselineMessages),
+		PrimaryParseErrors: counterDelta(primary.ParseErrors, status.PrimaryBaselineParseErrors),
+		CanaryMessages:     canary.MessagesPublished,
+		CanaryParseErrors:  canary.ParseErrors,
+	})
+	status.PrimaryMessagesPerMinute = int64(math.Round(result.PrimaryRate))
+	status.CanaryMessagesPerMinute = int64(math.Round(result.CanaryRate))
+	return 0, r.finishCanary(ctx, connector, result.Promote, result.Message)
+}
+
+// finishCanary records the verdict, promotes the image on success and tears the canary down
+func (r *ConnectorReconciler) finishCanary(ctx context.Context, connector *ssmdv1alpha1.Connector, promote bool, message string) error {
+	log := logf.FromContext(ctx)
+	status := *connector.Status.Canary
+
+	if promote {
+		// Patching returns the stored object, so the verdict is set afterwards
+		patch := client.MergeFrom(connector.DeepCopy())
+		connector.Spec.Image = status.Image
+		if err := r.Patch(ctx, connector, patch); err != nil {
+			return err
+		}
+		status.Verdict = ssmdv1alpha1.CanaryVerdictPromoted
+		log.Info("Canary promoted", "image", status.Image)
+		recordEvent(r.Recorder, connector, corev1.EventTypeNormal, "CanaryPromoted", eventActionUpdate,
+			"Promoted %s: %s", status.Image, message)
+	} else {
+		status.Verdict = ssmdv1alpha1.CanaryVerdictFailed
+		log.Info("Canary failed", "image", status.Image, "reason", message)
+		recordEvent(r.Recorder, connector, corev1.EventTypeWarning, "CanaryFailed", eventActionUpdateStatus,
+			"Canary %s failed: %s", status.Image, message)
+	}
+	completed := metav1.Now()
+	status.CompletedAt = &completed
+	status.Message = message
+	connector.Status.Canary = &status
+
+	if err := r.Status().Update(ctx, connector); err != nil {
+		return err
+	}
+	return r.deleteCanary(ctx, connector)
+}
+
+// applyCanary creates or updates the canary's ConfigMap and Deployment and returns the Deployment
+func (r *ConnectorReconciler) applyCanary(ctx context.Context, connector *ssmdv1alpha1.Connector, feedConfig *feedconfig.Config) (*appsv1.Deployment, error) {
+	canary := r.canaryConnector(connector, feedConfig)
+
+	desiredConfigMap := r.constructConfigMap(canary, feedConfig)
+	desiredConfigMap.Labels[canaryOfLabel] = connector.Name
+	configMap := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: desiredConfigMap.Name, Namespace: connector.Namespace}, configMap)
+	switch {
+	case errors.IsNotFound(err):
+		if err := controllerutil.SetControllerReference(connector, desiredConfigMap, r.Scheme); err != nil {
+			return nil, err
+		}
+		if err := recordChildEvent(r.Recorder, connector, eventActionCreate, "ConfigMap", desiredConfigMap.Name, r.Create(ctx, desiredConfigMap)); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case configMap.Data["feed.yaml"] != desiredConfigMap.Data["feed.yaml"] || configMap.Data["env.yaml"] != desiredConfigMap.Data["env.yaml"]:
+		configMap.Data = desiredConfigMap.Data
+		if err := recordChildEvent(r.Recorder, connector, eventActionUpdate, "ConfigMap", configMap.Name, r.Update(ctx, configMap)); err != nil {
+			return nil, err
+		}
+	}
+
+	desired := r.constructDeployment(ctx, canary, feedConfig)
+	desired.Labels[canaryOfLabel] = connector.Name
+	desired.Spec.Template.Labels[canaryOfLabel] = connector.Name
+	setSpecHash(desired)
+	deployment := &appsv1.Deployment{}
+	err = r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: connector.Namespace}, deployment)
+	switch {
+	case errors.IsNotFound(err):
+		if err := controllerutil.SetControllerReference(connector, desired, r.Scheme); err != nil {
+			return nil, err
+		}
+		logf.FromContext(ctx).Info("Creating canary Deployment", "name", desired.Name, "image", canary.Spec.Image)
+		if err := recordChildEvent(r.Recorder, connector, eventActionCreate, "Deployment", desired.Name, r.Create(ctx, desired)); err != nil {
+			return nil, err
+		}
+		return desired, nil
+	case err != nil:
+		return nil, err
+	case deploymentNeedsUpdate(deployment, desired):
+		updateDeploymentSpec(deployment, desired)
+		if err := recordChildEvent(r.Recorder, connector, eventActionUpdate, "Deployment", deployment.Name, r.Update(ctx, deployment)); err != nil {
+			return nil, err
+		}
+	}
+	return deployment, nil
+}
+
+// deleteCanary removes the canary's Deployment and ConfigMap, if present
+func (r *ConnectorReconciler) deleteCanary(ctx context.Context, connector *ssmdv1alpha1.Connector) error {
+	canary := &ssmdv1alpha1.Connector{ObjectMeta: metav1.ObjectMeta{Name: canaryName(connector), Namespace: connector.Namespace}}
+
+	deployment := &appsv1.Deployment{}
+	err := r.Get(ctx, types.NamespacedName{Name: r.deploymentName(canary), Namespace: connector.Namespace}, deployment)
+	if err == nil {
+		err = recordChildEvent(r.Recorder, connector, eventActionDelete, "Deployment", deployment.Name, r.Delete(ctx, deployment))
+	}
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = r.Get(ctx, types.NamespacedName{Name: r.configMapName(canary), Namespace: connector.Namespace}, configMap)
+	if err == nil {
+		err = recordChildEvent(r.Recorder, connector, eventActionDelete, "ConfigMap", configMap.Name, r.Delete(ctx, configMap))
+	}
+	return client.IgnoreNotFound(err)
+}
+
+// canaryWindow is what the primary and the canary published over a canary window
+type canaryWindow struct {
+	Minutes            float64
+	PrimaryPods        int
+	PrimaryMessages    int64
+	PrimaryParseErrors int64
+	CanaryMessages     int64
+	CanaryParseErrors  int64
+}
+
+// canaryResult is the verdict for a canary window, with rates in messages per minute
+type canaryResult struct {
+	Promote     bool
+	Message     string
+	PrimaryRate float64
+	CanaryRate  float64
+}
+
+// evaluateCanary compares the canary's message rate with the primary's per-pod
+// rate, and their parse error rates. A quiet primary (no messages) passes any
+// canary rate.
+func evaluateCanary(cfg *ssmdv1alpha1.CanaryConfig, w canaryWindow) canaryResult {
+	minRatePercent := int32OrDefault(cfg.MinRatePercent, defaultCanaryMinRatePercent)
+	maxErrorRatePercent := int32OrDefault(cfg.MaxErrorRatePercent, defaultCanaryMaxErrorRatePercent)
+
+	var result canaryResult
+	if w.Minutes > 0 && w.PrimaryPods > 0 {
+		result.PrimaryRate = float64(w.PrimaryMessages) / float64(w.PrimaryPods) / w.Minutes
+		result.CanaryRate = float64(w.CanaryMessages) / w.Minutes
+	}
+	primaryErrors := errorPercent(w.PrimaryParseErrors, w.PrimaryMessages)
+	canaryErrors := errorPercent(w.CanaryParseErrors, w.CanaryMessages)
+
+	switch {
+	case result.CanaryRate < result.PrimaryRate*float64(minRatePercent)/100:
+		result.Message = fmt.Sprintf("Canary published %.0f msg/min, below %d%% of the primary's %.0f msg/min",
+			result.CanaryRate, minRatePercent, result.PrimaryRate)
+	case canaryErrors > primaryErrors+float64(maxErrorRatePercent):
+		result.Message = fmt.Sprintf("Canary parse errors %.2f%% exceed the primary's %.2f%% by more than %d points",
+			canaryErrors, primaryErrors, maxErrorRatePercent)
+	default:
+		result.Promote = true
+		result.Message = fmt.Sprintf("Canary published %.0f msg/min (primary %.0f msg/min) with %.2f%% parse errors",
+			result.CanaryRate, result.PrimaryRate, canaryErrors)
+	}
+	return result
+}
+
+// errorPercent is parse errors as a percent of messages; errors with no messages count as 100%
+func errorPercent(parseErrors, messages int64) float64 {
+	if messages == 0 {
+		if parseErrors > 0 {
+			return 100
+		}
+		return 0
+	}
+	return float64(parseErrors) / float64(messages) * 100
+}
+
+// counterDelta is the increase of a counter since baseline. A counter below its
+// baseline was reset by a pod restart, so its current value is the increase.
+func counterDelta(current, baseline int64) int64 {
+	if current < baseline {
+		return current
+	}
+	return current - baseline
+}
+
+func int32OrDefault(v *int32, def int32) int32 {
+	if v == nil {
+		return def
+	}
+	return *v
+}
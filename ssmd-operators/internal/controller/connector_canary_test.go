@@ -0,0 +1,416 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// canaryTestMetrics renders connector metrics with the given counters
+func canaryTestMetrics(messages, parseErrors int) string {
+	return fmt.Sprintf(`# TYPE ssmd_connector_messages_total counter
+ssmd_connector_messages_total{feed="kalshi",message_type="ticker"} %d
+# TYPE ssmd_connector_parse_errors_total counter
+ssmd_connector_parse_errors_total{feed="kalshi"} %d
+`, messages, parseErrors)
+}
+
+// newCanaryTestReconciler builds a ConnectorReconciler whose scrape returns
+// metrics by pod IP, backed by a fake client holding the connector.
+func newCanaryTestReconciler(t *testing.T, connector *ssmdv1alpha1.Connector, metricsByIP map[string]string, objs ...client.Object) *ConnectorReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	return &ConnectorReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(append(objs, connector)...).
+			WithStatusSubresource(&ssmdv1alpha1.Connector{}).Build(),
+		Scheme: scheme,
+		scrape: func(ctx context.Context, url string) (metricFamilies, error) {
+			for ip, text := range metricsByIP {
+				if strings.Contains(url, "//"+ip+":") {
+					return parseTestMetrics(t, text), nil
+				}
+			}
+			return nil, fmt.Errorf("no metrics for %s", url)
+		},
+	}
+}
+
+func newCanaryTestConnector() *ssmdv1alpha1.Connector {
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Spec.Image = "ghcr.io/aaronwald/ssmd-connector:1.0.0"
+	connector.Spec.Canary = &ssmdv1alpha1.CanaryConfig{Image: "ghcr.io/aaronwald/ssmd-connector:1.1.0"}
+	return connector
+}
+
+func newInstancePod(name, instance, ip string, restarts int32) *corev1.Pod {
+	pod := newConnectorPod(name, corev1.PodRunning, restarts)
+	pod.Labels["app.kubernetes.io/instance"] = instance
+	pod.Status.PodIP = ip
+	return pod
+}
+
+// startedCanaryStatus is a canary whose window began at startedAt with the given primary baseline
+func startedCanaryStatus(image string, startedAt time.Time, baselineMessages int64) *ssmdv1alpha1.CanaryStatus {
+	started := metav1.NewTime(startedAt)
+	return &ssmdv1alpha1.CanaryStatus{
+		Image:                   image,
+		Verdict:                 ssmdv1alpha1.CanaryVerdictRunning,
+		StartedAt:               &started,
+		PrimaryBaselineMessages: baselineMessages,
+	}
+}
+
+func getConnector(t *testing.T, r *ConnectorReconciler) *ssmdv1alpha1.Connector {
+	t.Helper()
+	connector := &ssmdv1alpha1.Connector{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "connector-test", Namespace: "ssmd"}, connector); err != nil {
+		t.Fatalf("failed to get connector: %v", err)
+	}
+	return connector
+}
+
+func canaryDeploymentExists(t *testing.T, r *ConnectorReconciler) bool {
+	t.Helper()
+	err := r.Get(context.Background(), types.NamespacedName{Name: "connector-test-canary-connector", Namespace: "ssmd"}, &appsv1.Deployment{})
+	if err != nil && !errors.IsNotFound(err) {
+		t.Fatalf("failed to get canary deployment: %v", err)
+	}
+	return err == nil
+}
+
+// --- TestEvaluateCanary ---
+
+func TestEvaluateCanary(t *testing.T) {
+	cfg := &ssmdv1alpha1.CanaryConfig{Image: "canary"}
+	tests := []struct {
+		name    string
+		window  canaryWindow
+		promote bool
+		message string
+	}{
+		{
+			name:    "matching rate",
+			window:  canaryWindow{Minutes: 10, PrimaryPods: 2, PrimaryMessages: 2000, CanaryMessages: 950},
+			promote: true,
+		},
+		{
+			name:    "slow canary",
+			window:  canaryWindow{Minutes: 10, PrimaryPods: 1, PrimaryMessages: 1000, CanaryMessages: 700},
+			message: "below 80% of the primary's 100 msg/min",
+		},
+		{
+			name:    "parse errors",
+			window:  canaryWindow{Minutes: 10, PrimaryPods: 1, PrimaryMessages: 1000, CanaryMessages: 1000, CanaryParseErrors: 50},
+			message: "Canary parse errors 5.00%",
+		},
+		{
+			name:    "errors within tolerance of the primary's",
+			window:  canaryWindow{Minutes: 10, PrimaryPods: 1, PrimaryMessages: 1000, PrimaryParseErrors: 40, CanaryMessages: 1000, CanaryParseErrors: 45},
+			promote: true,
+		},
+		{
+			name:    "quiet primary",
+			window:  canaryWindow{Minutes: 10, PrimaryPods: 1},
+			promote: true,
+		},
+		{
+			name:    "canary errors without messages",
+			window:  canaryWindow{Minutes: 10, PrimaryPods: 1, CanaryParseErrors: 3},
+			message: "Canary parse errors 100.00%",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := evaluateCanary(cfg, tt.window)
+			if result.Promote != tt.promote {
+				t.Errorf("Promote = %v, want %v (%s)", result.Promote, tt.promote, result.Message)
+			}
+			if tt.message != "" && !strings.Contains(result.Message, tt.message) {
+				t.Errorf("Message = %q, want it to contain %q", result.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestEvaluateCanary_CustomThresholds(t *testing.T) {
+	cfg := &ssmdv1alpha1.CanaryConfig{Image: "canary", MinRatePercent: int32Ptr(50), MaxErrorRatePercent: int32Ptr(10)}
+	result := evaluateCanary(cfg, canaryWindow{Minutes: 10, PrimaryPods: 1, PrimaryMessages: 1000, CanaryMessages: 600, CanaryParseErrors: 30})
+	if !result.Promote {
+		t.Errorf("expected promotion within custom thresholds, got %q", result.Message)
+	}
+	if result.PrimaryRate != 100 || result.CanaryRate != 60 {
+		t.Errorf("rates = %v/%v, want 100/60", result.PrimaryRate, result.CanaryRate)
+	}
+}
+
+func TestCounterDelta(t *testing.T) {
+	if got := counterDelta(150, 100); got != 50 {
+		t.Errorf("counterDelta(150, 100) = %d, want 50", got)
+	}
+	if got := counterDelta(30, 100); got != 30 {
+		t.Errorf("counterDelta after reset = %d, want 30", got)
+	}
+}
+
+// --- TestCanaryConnector ---
+
+func TestCanaryConnector_Trafficless(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newCanaryTestConnector()
+	connector.Spec.Replicas = int32Ptr(3)
+	connector.Spec.HighAvailability = &ssmdv1alpha1.HighAvailabilityConfig{}
+	connector.Spec.Cdc = &ssmdv1alpha1.CdcConfig{Enabled: true, ConsumerName: "kalshi-cdc"}
+	_, _, primaryPrefix := r.natsTransport(connector, nil)
+
+	canary := r.canaryConnector(connector, nil)
+
+	if canary.Name != "connector-test-canary" || canary.Spec.Image != "ghcr.io/aaronwald/ssmd-connector:1.1.0" {
+		t.Errorf("canary name/image = %s/%s", canary.Name, canary.Spec.Image)
+	}
+	if *canary.Spec.Replicas != 1 || canary.Spec.HighAvailability != nil || canary.Spec.Canary != nil {
+		t.Errorf("expected one replica without HA or canary, got %+v", canary.Spec)
+	}
+	if canary.Spec.Cdc.ConsumerName != "kalshi-cdc-canary" {
+		t.Errorf("CDC consumer = %q, want kalshi-cdc-canary", canary.Spec.Cdc.ConsumerName)
+	}
+	if _, _, prefix := r.natsTransport(canary, nil); prefix != "canary."+primaryPrefix {
+		t.Errorf("subject prefix = %q, want canary.%s", prefix, primaryPrefix)
+	}
+	if connector.Spec.Cdc.ConsumerName != "kalshi-cdc" || *connector.Spec.Replicas != 3 {
+		t.Error("canaryConnector modified the primary's spec")
+	}
+}
+
+func TestCanaryConnector_WithTraffic(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newCanaryTestConnector()
+	connector.Spec.Canary.Trafficless = boolPtr(false)
+	_, _, primaryPrefix := r.natsTransport(connector, nil)
+
+	if _, _, prefix := r.natsTransport(r.canaryConnector(connector, nil), nil); prefix != primaryPrefix {
+		t.Errorf("subject prefix = %q, want the primary's %q", prefix, primaryPrefix)
+	}
+}
+
+// --- TestReconcileCanary ---
+
+func TestReconcileCanary_CreatesCanaryAndWaitsForReady(t *testing.T) {
+	connector := newCanaryTestConnector()
+	r := newCanaryTestReconciler(t, connector, nil)
+	ctx := context.Background()
+
+	requeue, err := r.reconcileCanary(ctx, connector, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requeue != canaryPollInterval {
+		t.Errorf("requeue = %s, want %s", requeue, canaryPollInterval)
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "connector-test-canary-connector", Namespace: "ssmd"}, deployment); err != nil {
+		t.Fatalf("expected canary deployment: %v", err)
+	}
+	if image := deployment.Spec.Template.Spec.Containers[0].Image; image != "ghcr.io/aaronwald/ssmd-connector:1.1.0" {
+		t.Errorf("canary image = %s", image)
+	}
+	if deployment.Spec.Template.Labels["app.kubernetes.io/instance"] != "connector-test-canary" ||
+		deployment.Spec.Template.Labels[canaryOfLabel] != "connector-test" {
+		t.Errorf("unexpected canary pod labels %v", deployment.Spec.Template.Labels)
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "connector-test-canary-config", Namespace: "ssmd"}, configMap); err != nil {
+		t.Fatalf("expected canary configmap: %v", err)
+	}
+	if !strings.Contains(configMap.Data["env.yaml"], "subject_prefix: canary.") {
+		t.Errorf("expected a canary subject prefix in env.yaml:\n%s", configMap.Data["env.yaml"])
+	}
+
+	status := getConnector(t, r).Status.Canary
+	if status == nil || status.Verdict != ssmdv1alpha1.CanaryVerdictRunning || status.StartedAt != nil {
+		t.Errorf("expected a running canary waiting for ready, got %+v", status)
+	}
+}
+
+func TestReconcileCanary_StartsWindowWhenReady(t *testing.T) {
+	connector := newCanaryTestConnector()
+	r := newCanaryTestReconciler(t, connector, map[string]string{"10.0.0.1": canaryTestMetrics(5000, 2)},
+		newInstancePod("connector-test-abc", "connector-test", "10.0.0.1", 0))
+	ctx := context.Background()
+
+	if _, err := r.reconcileCanary(ctx, connector, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "connector-test-canary-connector", Namespace: "ssmd"}, deployment); err != nil {
+		t.Fatalf("expected canary deployment: %v", err)
+	}
+	deployment.Status.ReadyReplicas = 1
+	if err := r.Status().Update(ctx, deployment); err != nil {
+		t.Fatalf("failed to mark canary ready: %v", err)
+	}
+
+	connector = getConnector(t, r)
+	if _, err := r.reconcileCanary(ctx, connector, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	status := getConnector(t, r).Status.Canary
+	if status.StartedAt == nil || status.PrimaryBaselineMessages != 5000 || status.PrimaryBaselineParseErrors != 2 {
+		t.Errorf("expected the window to start from the primary's counters, got %+v", status)
+	}
+}
+
+func TestReconcileCanary_PromotesAfterWindow(t *testing.T) {
+	connector := newCanaryTestConnector()
+	connector.Status.Canary = startedCanaryStatus(connector.Spec.Canary.Image, time.Now().Add(-20*time.Minute), 1000)
+	r := newCanaryTestReconciler(t, connector, map[string]string{
+		"10.0.0.1": canaryTestMetrics(3000, 0),
+		"10.0.0.2": canaryTestMetrics(1900, 0),
+	},
+		newInstancePod("connector-test-abc", "connector-test", "10.0.0.1", 0),
+		newInstancePod("connector-test-canary-abc", "connector-test-canary", "10.0.0.2", 0))
+
+	requeue, err := r.reconcileCanary(context.Background(), connector, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requeue != 0 {
+		t.Errorf("requeue = %s, want 0 after the verdict", requeue)
+	}
+
+	stored := getConnector(t, r)
+	if stored.Spec.Image != "ghcr.io/aaronwald/ssmd-connector:1.1.0" {
+		t.Errorf("spec.image = %s, want the promoted canary image", stored.Spec.Image)
+	}
+	status := stored.Status.Canary
+	if status == nil || status.Verdict != ssmdv1alpha1.CanaryVerdictPromoted || status.CompletedAt == nil {
+		t.Fatalf("expected Promoted verdict, got %+v", status)
+	}
+	if status.PrimaryMessagesPerMinute != 100 || status.CanaryMessagesPerMinute != 95 {
+		t.Errorf("rates = %d/%d, want 100/95", status.PrimaryMessagesPerMinute, status.CanaryMessagesPerMinute)
+	}
+	if canaryDeploymentExists(t, r) {
+		t.Error("expected the canary deployment to be torn down")
+	}
+}
+
+func TestReconcileCanary_FailsOnRestart(t *testing.T) {
+	connector := newCanaryTestConnector()
+	connector.Status.Canary = startedCanaryStatus(connector.Spec.Canary.Image, time.Now().Add(-time.Minute), 1000)
+	r := newCanaryTestReconciler(t, connector, map[string]string{"10.0.0.2": canaryTestMetrics(10, 0)},
+		newInstancePod("connector-test-canary-abc", "connector-test-canary", "10.0.0.2", 2))
+
+	if _, err := r.reconcileCanary(context.Background(), connector, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stored := getConnector(t, r)
+	if stored.Spec.Image != "ghcr.io/aaronwald/ssmd-connector:1.0.0" {
+		t.Errorf("spec.image = %s, want the primary image kept", stored.Spec.Image)
+	}
+	if status := stored.Status.Canary; status.Verdict != ssmdv1alpha1.CanaryVerdictFailed || !strings.Contains(status.Message, "restarted 2 times") {
+		t.Errorf("expected Failed verdict for restarts, got %+v", status)
+	}
+	if canaryDeploymentExists(t, r) {
+		t.Error("expected the canary deployment to be torn down")
+	}
+}
+
+func TestReconcileCanary_WaitsUntilWindowEnds(t *testing.T) {
+	connector := newCanaryTestConnector()
+	connector.Status.Canary = startedCanaryStatus(connector.Spec.Canary.Image, time.Now().Add(-14*time.Minute-50*time.Second), 1000)
+	r := newCanaryTestReconciler(t, connector, nil)
+
+	requeue, err := r.reconcileCanary(context.Background(), connector, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requeue <= 0 || requeue > 10*time.Second {
+		t.Errorf("requeue = %s, want the ~10s left in the window", requeue)
+	}
+	if getConnector(t, r).Status.Canary.Verdict != ssmdv1alpha1.CanaryVerdictRunning {
+		t.Error("expected the canary to keep running")
+	}
+}
+
+func TestReconcileCanary_VerdictIsFinalForImage(t *testing.T) {
+	connector := newCanaryTestConnector()
+	connector.Status.Canary = &ssmdv1alpha1.CanaryStatus{Image: connector.Spec.Canary.Image, Verdict: ssmdv1alpha1.CanaryVerdictFailed}
+	r := newCanaryTestReconciler(t, connector, nil)
+
+	if _, err := r.reconcileCanary(context.Background(), connector, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if canaryDeploymentExists(t, r) {
+		t.Error("expected no canary for an image that already has a verdict")
+	}
+
+	// A new image starts a new run
+	connector = getConnector(t, r)
+	connector.Spec.Canary.Image = "ghcr.io/aaronwald/ssmd-connector:1.2.0"
+	if _, err := r.reconcileCanary(context.Background(), connector, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !canaryDeploymentExists(t, r) {
+		t.Error("expected a canary for the new image")
+	}
+	if status := getConnector(t, r).Status.Canary; status.Image != "ghcr.io/aaronwald/ssmd-connector:1.2.0" || status.Verdict != ssmdv1alpha1.CanaryVerdictRunning {
+		t.Errorf("expected a running canary for the new image, got %+v", status)
+	}
+}
+
+func TestReconcileCanary_RemovedSpecTearsDown(t *testing.T) {
+	connector := newCanaryTestConnector()
+	r := newCanaryTestReconciler(t, connector, nil)
+	ctx := context.Background()
+	if _, err := r.reconcileCanary(ctx, connector, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	connector = getConnector(t, r)
+	connector.Spec.Canary = nil
+	if _, err := r.reconcileCanary(ctx, connector, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if canaryDeploymentExists(t, r) {
+		t.Error("expected the canary deployment to be deleted")
+	}
+	if getConnector(t, r).Status.Canary != nil {
+		t.Error("expected canary status to be cleared")
+	}
+}
@@ -19,25 +19,28 @@ package controller
 import (
 	"context"
 	"fmt"
-	"reflect"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
-	"sigs.k8s.io/yaml"
 
 	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/feedconfig"
 )
 
 const (
@@ -48,14 +51,47 @@ const (
 type ConnectorReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events for reconcile actions (skipped if nil)
+	Recorder events.EventRecorder
+
+	// MaxConcurrentReconciles is the number of parallel reconciles (1 if unset)
+	MaxConcurrentReconciles int
+
+	// DefaultPriorityClassName is used for pods whose CR does not set priorityClassName
+	DefaultPriorityClassName string
+
+	// DefaultResources are used for pods whose CR does not set resources
+	DefaultResources *corev1.ResourceRequirements
+
+	// TenantConfig names the TenantConfig whose tenant is recorded on
+	// provisioned streams (skipped if empty)
+	TenantConfig string
+
+	// scrape fetches connector /metrics (defaults to scrapeMetrics; overridden in tests)
+	scrape metricsScraper
+
+	// dialJetStream connects to NATS for stream provisioning (defaults to dialJetStream; overridden in tests)
+	dialJetStream jetStreamDialer
 }
 
+const (
+	// connectorMetricsPort is the connector's /health, /ready and /metrics port
+	connectorMetricsPort = 8080
+
+	// defaultDegradedAfterMinutes is used when spec.degradedAfterMinutes is unset
+	defaultDegradedAfterMinutes = 5
+)
+
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=connectors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=connectors/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=connectors/finalizers,verbs=update
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 
 // Reconcile moves the cluster state toward the desired state for a Connector
 func (r *ConnectorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -86,7 +122,7 @@ func (r *ConnectorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	// Validate feed ConfigMap exists
-	feedConfig, err := r.getFeedConfig(ctx, connector.Namespace, connector.Spec.Feed)
+	feedConfig, err := feedconfig.Load(ctx, r.Client, connector.Namespace, connector.Spec.Feed)
 	if err != nil {
 		log.Error(err, "Failed to read feed ConfigMap")
 		return ctrl.Result{}, err
@@ -95,6 +131,9 @@ func (r *ConnectorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		log.Info("Feed ConfigMap not found, using legacy inline config", "feed", connector.Spec.Feed)
 	}
 
+	// Provision the transport stream if requested
+	r.provisionStream(ctx, connector, feedConfig)
+
 	// Reconcile the ConfigMap (feed and env configs)
 	if _, err := r.reconcileConfigMap(ctx, connector, feedConfig); err != nil {
 		return ctrl.Result{}, err
@@ -106,13 +145,29 @@ func (r *ConnectorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return result, err
 	}
 
+	// Reconcile the PodDisruptionBudget
+	if err := reconcilePodDisruptionBudget(ctx, r.Client, r.Scheme, r.Recorder, connector, connector.Spec.HighAvailability,
+		r.deploymentName(connector), r.constructDeployment(ctx, connector, feedConfig).Spec.Selector); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Update status
 	if err := r.updateStatus(ctx, connector); err != nil {
 		return ctrl.Result{}, err
 	}
 
+	// Run spec.canary, judging it against the primary's freshly scraped metrics
+	requeueAfter := 30 * time.Second
+	canaryRequeue, err := r.reconcileCanary(ctx, connector, feedConfig)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if canaryRequeue > 0 && canaryRequeue < requeueAfter {
+		requeueAfter = canaryRequeue
+	}
+
 	// Requeue every 30 seconds to update metrics
-	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // reconcileDelete handles cleanup when the Connector is deleted
@@ -126,7 +181,7 @@ func (r *ConnectorReconciler) reconcileDelete(ctx context.Context, connector *ss
 		deploymentName := r.deploymentName(connector)
 		deployment := &appsv1.Deployment{}
 		if err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: connector.Namespace}, deployment); err == nil {
-			if err := r.Delete(ctx, deployment); err != nil && !errors.IsNotFound(err) {
+			if err := recordChildEvent(r.Recorder, connector, eventActionDelete, "Deployment", deploymentName, r.Delete(ctx, deployment)); err != nil && !errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
 			log.Info("Deleted Deployment", "name", deploymentName)
@@ -136,12 +191,16 @@ func (r *ConnectorReconciler) reconcileDelete(ctx context.Context, connector *ss
 		configMapName := r.configMapName(connector)
 		configMap := &corev1.ConfigMap{}
 		if err := r.Get(ctx, types.NamespacedName{Name: configMapName, Namespace: connector.Namespace}, configMap); err == nil {
-			if err := r.Delete(ctx, configMap); err != nil && !errors.IsNotFound(err) {
+			if err := recordChildEvent(r.Recorder, connector, eventActionDelete, "ConfigMap", configMapName, r.Delete(ctx, configMap)); err != nil && !errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
 			log.Info("Deleted ConfigMap", "name", configMapName)
 		}
 
+		if err := r.deleteCanary(ctx, connector); err != nil {
+			return ctrl.Result{}, err
+		}
+
 		// Remove finalizer
 		controllerutil.RemoveFinalizer(connector, connectorFinalizer)
 		if err := r.Update(ctx, connector); err != nil {
@@ -153,7 +212,7 @@ func (r *ConnectorReconciler) reconcileDelete(ctx context.Context, connector *ss
 }
 
 // reconcileConfigMap ensures the ConfigMap with feed and env configs exists
-func (r *ConnectorReconciler) reconcileConfigMap(ctx context.Context, connector *ssmdv1alpha1.Connector, feedConfig *FeedConfig) (ctrl.Result, error) {
+func (r *ConnectorReconciler) reconcileConfigMap(ctx context.Context, connector *ssmdv1alpha1.Connector, feedConfig *feedconfig.Config) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
 	configMapName := r.configMapName(connector)
@@ -167,7 +226,7 @@ func (r *ConnectorReconciler) reconcileConfigMap(ctx context.Context, connector
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating ConfigMap", "name", configMapName)
-		if err := r.Create(ctx, desiredConfigMap); err != nil {
+		if err := recordChildEvent(r.Recorder, connector, eventActionCreate, "ConfigMap", configMapName, r.Create(ctx, desiredConfigMap)); err != nil {
 			return ctrl.Result{}, err
 		}
 	} else if err != nil {
@@ -178,7 +237,7 @@ func (r *ConnectorReconciler) reconcileConfigMap(ctx context.Context, connector
 			configMap.Data["env.yaml"] != desiredConfigMap.Data["env.yaml"] {
 			configMap.Data = desiredConfigMap.Data
 			log.Info("Updating ConfigMap", "name", configMapName)
-			if err := r.Update(ctx, configMap); err != nil {
+			if err := recordChildEvent(r.Recorder, connector, eventActionUpdate, "ConfigMap", configMapName, r.Update(ctx, configMap)); err != nil {
 				return ctrl.Result{}, err
 			}
 		}
@@ -188,7 +247,7 @@ func (r *ConnectorReconciler) reconcileConfigMap(ctx context.Context, connector
 }
 
 // constructConfigMap builds the ConfigMap with feed and env configuration
-func (r *ConnectorReconciler) constructConfigMap(connector *ssmdv1alpha1.Connector, feedConfig *FeedConfig) *corev1.ConfigMap {
+func (r *ConnectorReconciler) constructConfigMap(connector *ssmdv1alpha1.Connector, feedConfig *feedconfig.Config) *corev1.ConfigMap {
 	feedYAML := r.buildFeedYAML(connector, feedConfig)
 	envYAML := r.buildEnvYAML(connector, feedConfig)
 
@@ -212,26 +271,36 @@ func (r *ConnectorReconciler) constructConfigMap(connector *ssmdv1alpha1.Connect
 
 // buildFeedYAML generates the feed.yaml content.
 // If a feed ConfigMap exists, uses its endpoint/auth/display_name.
-// Falls back to legacy Kalshi defaults for backward compatibility.
-func (r *ConnectorReconciler) buildFeedYAML(connector *ssmdv1alpha1.Connector, feedConfig *FeedConfig) string {
+// Otherwise falls back to the spec.exchange profile, if set.
+func (r *ConnectorReconciler) buildFeedYAML(connector *ssmdv1alpha1.Connector, feedConfig *feedconfig.Config) string {
 	displayName := connector.Spec.Feed
 	endpoint := ""
 	authMethod := "none"
 
+	if profile := r.exchangeProfile(connector); profile != nil {
+		displayName = profile.DisplayName
+		endpoint = profile.Endpoint
+		authMethod = profile.AuthMethod
+	}
+
+	rateLimit := 0
 	if feedConfig != nil {
-		displayName = feedConfig.DisplayName
-		if len(feedConfig.Versions) > 0 {
-			endpoint = feedConfig.Versions[0].Endpoint
-			authMethod = feedConfig.Versions[0].AuthMethod
+		if feedConfig.DisplayName != "" {
+			displayName = feedConfig.DisplayName
+		}
+		if v := feedConfig.ActiveVersion(time.Now()); v != nil {
+			endpoint = v.Endpoint
+			authMethod = v.AuthMethod
+			rateLimit = v.RateLimitPerSecond
 		}
 	}
 
-	// If no feed ConfigMap, fall back to empty endpoint (connector binary will error clearly)
+	// If no feed ConfigMap or exchange profile, use a placeholder (connector binary will error clearly)
 	if endpoint == "" {
 		endpoint = fmt.Sprintf("wss://%s.example.com/MISSING_FEED_CONFIGMAP", connector.Spec.Feed)
 	}
 
-	return fmt.Sprintf(`name: %s
+	feedYAML := fmt.Sprintf(`name: %s
 display_name: %s
 type: websocket
 status: active
@@ -244,18 +313,27 @@ versions:
     endpoint: %s
     auth_method: %s
 `, connector.Spec.Feed, displayName, endpoint, authMethod)
+	if rateLimit > 0 {
+		feedYAML += fmt.Sprintf("    rate_limit_per_second: %d\n", rateLimit)
+	}
+	return feedYAML
 }
 
-// buildEnvYAML generates the env.yaml content.
-// Reads NATS defaults from feed ConfigMap, with CR spec overrides.
-func (r *ConnectorReconciler) buildEnvYAML(connector *ssmdv1alpha1.Connector, feedConfig *FeedConfig) string {
-	natsURL := "nats://nats.nats.svc.cluster.local:4222"
-	stream := ""
-	subjectPrefix := ""
+// natsTransport resolves the NATS URL, stream and subject prefix.
+// Reads NATS defaults from the exchange profile and feed ConfigMap, with CR spec overrides.
+func (r *ConnectorReconciler) natsTransport(connector *ssmdv1alpha1.Connector, feedConfig *feedconfig.Config) (natsURL, stream, subjectPrefix string) {
+	natsURL = "nats://nats.nats.svc.cluster.local:4222"
+	profile := r.exchangeProfile(connector)
 
-	// Start with feed ConfigMap defaults
-	if feedConfig != nil && feedConfig.Defaults != nil && feedConfig.Defaults.Connector != nil {
-		if t := feedConfig.Defaults.Connector.Transport; t != nil {
+	// Start with exchange profile defaults
+	if profile != nil {
+		stream = profile.Stream
+		subjectPrefix = profile.SubjectPrefix
+	}
+
+	// Feed ConfigMap defaults override the exchange profile
+	if defaults := feedConfig.ConnectorDefaults(); defaults != nil {
+		if t := defaults.Transport; t != nil {
 			if t.Stream != "" {
 				stream = t.Stream
 			}
@@ -278,10 +356,21 @@ func (r *ConnectorReconciler) buildEnvYAML(connector *ssmdv1alpha1.Connector, fe
 		}
 	}
 
-	// Determine auth method from feed ConfigMap
+	return natsURL, stream, subjectPrefix
+}
+
+// buildEnvYAML generates the env.yaml content
+func (r *ConnectorReconciler) buildEnvYAML(connector *ssmdv1alpha1.Connector, feedConfig *feedconfig.Config) string {
+	natsURL, stream, subjectPrefix := r.natsTransport(connector, feedConfig)
+	profile := r.exchangeProfile(connector)
+
+	// Determine auth method from feed ConfigMap, else the exchange profile
 	authMethod := "none"
-	if feedConfig != nil && len(feedConfig.Versions) > 0 {
-		authMethod = feedConfig.Versions[0].AuthMethod
+	if profile != nil {
+		authMethod = profile.AuthMethod
+	}
+	if v := feedConfig.ActiveVersion(time.Now()); v != nil {
+		authMethod = v.AuthMethod
 	}
 
 	envConfig := fmt.Sprintf(`name: prod
@@ -305,21 +394,23 @@ schema: trade:v1
 				}
 			}
 		} else if connector.Spec.SecretRef != nil {
-			// Legacy: derive env var names from feed name
-			feedUpper := strings.ToUpper(connector.Spec.Feed)
-			apiKeyEnvVar = feedUpper + "_API_KEY"
-			privateKeyEnvVar = feedUpper + "_PRIVATE_KEY"
+			apiKeyEnvVar, privateKeyEnvVar = r.credentialEnvNames(connector)
 		}
 
 		if apiKeyEnvVar != "" && privateKeyEnvVar != "" {
+			// Mounted credential files are named after the env vars they replace
+			source := fmt.Sprintf("env:%s,%s", apiKeyEnvVar, privateKeyEnvVar)
+			if connector.Spec.CredentialFiles {
+				source = credentialFilesSource(apiKeyEnvVar, privateKeyEnvVar)
+			}
 			envConfig += fmt.Sprintf(`keys:
   %s:
     type: api_key
     fields:
       - api_key
       - private_key
-    source: "env:%s,%s"
-`, connector.Spec.Feed, apiKeyEnvVar, privateKeyEnvVar)
+    source: "%s"
+`, connector.Spec.Feed, source)
 		}
 	}
 
@@ -352,11 +443,12 @@ storage:
 		envConfig += fmt.Sprintf(`secmaster:
   url: "http://ssmd-data-ts.%s.svc.cluster.local:8080"
   categories:
-%s%s%ssubscription:
-  batch_size: 100
-  retry_attempts: 3
-  retry_delay_ms: 1000
-`, connector.Namespace, categoriesYAML, closeWithinHoursYAML, gamesOnlyYAML)
+%s%s%s`, connector.Namespace, categoriesYAML, closeWithinHoursYAML, gamesOnlyYAML)
+	}
+
+	// Add subscription config: batching for secmaster-driven subscriptions, plus explicit tickers/channels
+	if subscriptionYAML := r.buildSubscriptionYAML(connector); subscriptionYAML != "" {
+		envConfig += "subscription:\n" + subscriptionYAML
 	}
 
 	// Add CDC config if enabled
@@ -380,10 +472,48 @@ storage:
 	return envConfig
 }
 
+// buildSubscriptionYAML generates the body of the env.yaml subscription section
+func (r *ConnectorReconciler) buildSubscriptionYAML(connector *ssmdv1alpha1.Connector) string {
+	var b strings.Builder
+
+	if len(connector.Spec.Categories) > 0 {
+		b.WriteString("  batch_size: 100\n  retry_attempts: 3\n  retry_delay_ms: 1000\n")
+	}
+
+	if subs := connector.Spec.Subscriptions; subs != nil {
+		if len(subs.Tickers) > 0 {
+			b.WriteString("  tickers:\n")
+			for _, ticker := range subs.Tickers {
+				fmt.Fprintf(&b, "    - %s\n", ticker)
+			}
+		}
+		if len(subs.Channels) > 0 {
+			b.WriteString("  channels:\n")
+			for _, channel := range subs.Channels {
+				fmt.Fprintf(&b, "    - %s\n", channel)
+			}
+		}
+		if subs.OrderbookDepth != nil {
+			fmt.Fprintf(&b, "  orderbook_depth: %d\n", *subs.OrderbookDepth)
+		}
+	}
+
+	return b.String()
+}
+
 // reconcileDeployment ensures the Deployment exists and matches the desired state
-func (r *ConnectorReconciler) reconcileDeployment(ctx context.Context, connector *ssmdv1alpha1.Connector, feedConfig *FeedConfig) (ctrl.Result, error) {
+func (r *ConnectorReconciler) reconcileDeployment(ctx context.Context, connector *ssmdv1alpha1.Connector, feedConfig *feedconfig.Config) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
+	// Credential files carry a checksum of their Secret keys so rotation rolls the pods
+	checksum := ""
+	if connector.Spec.CredentialFiles {
+		var err error
+		if checksum, err = r.credentialsChecksum(ctx, connector, r.credentialItems(connector)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	deploymentName := r.deploymentName(connector)
 	deployment := &appsv1.Deployment{}
 	err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: connector.Namespace}, deployment)
@@ -391,11 +521,14 @@ func (r *ConnectorReconciler) reconcileDeployment(ctx context.Context, connector
 	if errors.IsNotFound(err) {
 		// Create new Deployment
 		deployment = r.constructDeployment(ctx, connector, feedConfig)
+		if checksum != "" {
+			setCredentialsChecksum(deployment, checksum)
+		}
 		if err := controllerutil.SetControllerReference(connector, deployment, r.Scheme); err != nil {
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating Deployment", "name", deploymentName)
-		if err := r.Create(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, connector, eventActionCreate, "Deployment", deploymentName, r.Create(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -405,10 +538,16 @@ func (r *ConnectorReconciler) reconcileDeployment(ctx context.Context, connector
 
 	// Update existing Deployment if needed
 	desired := r.constructDeployment(ctx, connector, feedConfig)
-	if r.deploymentNeedsUpdate(deployment, desired) {
-		deployment.Spec = desired.Spec
+	if checksum != "" {
+		setCredentialsChecksum(desired, checksum)
+		if previous := deployment.Spec.Template.Annotations[credentialsChecksumAnnotation]; previous != "" && previous != checksum {
+			log.Info("Credentials rotated, recreating connector pods", "name", deploymentName)
+		}
+	}
+	if deploymentNeedsUpdate(deployment, desired) {
+		updateDeploymentSpec(deployment, desired)
 		log.Info("Updating Deployment", "name", deploymentName)
-		if err := r.Update(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, connector, eventActionUpdate, "Deployment", deploymentName, r.Update(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
@@ -417,7 +556,7 @@ func (r *ConnectorReconciler) reconcileDeployment(ctx context.Context, connector
 }
 
 // constructDeployment builds the Deployment spec for a Connector
-func (r *ConnectorReconciler) constructDeployment(ctx context.Context, connector *ssmdv1alpha1.Connector, feedConfig *FeedConfig) *appsv1.Deployment {
+func (r *ConnectorReconciler) constructDeployment(ctx context.Context, connector *ssmdv1alpha1.Connector, feedConfig *feedconfig.Config) *appsv1.Deployment {
 	log := logf.FromContext(ctx)
 
 	labels := map[string]string{
@@ -436,12 +575,8 @@ func (r *ConnectorReconciler) constructDeployment(ctx context.Context, connector
 	image := connector.Spec.Image
 	if image == "" {
 		// Try to get defaults from feed ConfigMap
-		if feedConfig != nil && feedConfig.Defaults != nil && feedConfig.Defaults.Connector != nil {
-			fc := feedConfig.Defaults.Connector
-			if fc.Image != "" && fc.Version != "" {
-				image = fmt.Sprintf("%s:%s", fc.Image, fc.Version)
-				log.Info("Using image from feed defaults", "image", image)
-			}
+		if image = feedConfig.ConnectorDefaults().ImageRef(); image != "" {
+			log.Info("Using image from feed defaults", "image", image)
 		}
 		// Fall back to hardcoded default
 		if image == "" {
@@ -461,51 +596,16 @@ func (r *ConnectorReconciler) constructDeployment(ctx context.Context, connector
 	}
 	env = append(env, corev1.EnvVar{Name: "NATS_URL", Value: natsURL})
 
-	// Add secret env vars (new generic field)
-	if len(connector.Spec.SecretEnvVars) > 0 {
-		for _, sev := range connector.Spec.SecretEnvVars {
-			env = append(env, corev1.EnvVar{
-				Name: sev.EnvName,
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{Name: sev.SecretName},
-						Key:                  sev.SecretKey,
-					},
-				},
-			})
-		}
-	} else if connector.Spec.SecretRef != nil {
-		// Legacy secretRef handling: derive env var names from feed name
-		feedUpper := strings.ToUpper(connector.Spec.Feed)
-		apiKeyField := "api-key"
-		if connector.Spec.SecretRef.APIKeyField != "" {
-			apiKeyField = connector.Spec.SecretRef.APIKeyField
-		}
-		privateKeyField := "private-key"
-		if connector.Spec.SecretRef.PrivateKeyField != "" {
-			privateKeyField = connector.Spec.SecretRef.PrivateKeyField
-		}
+	// Add credentials as env vars, unless credentialFiles mounts them as files
+	credentials := r.credentialItems(connector)
+	if !connector.Spec.CredentialFiles {
+		env = append(env, credentialEnvVars(credentials)...)
+	}
 
-		env = append(env,
-			corev1.EnvVar{
-				Name: feedUpper + "_API_KEY",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{Name: connector.Spec.SecretRef.Name},
-						Key:                  apiKeyField,
-					},
-				},
-			},
-			corev1.EnvVar{
-				Name: feedUpper + "_PRIVATE_KEY",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{Name: connector.Spec.SecretRef.Name},
-						Key:                  privateKeyField,
-					},
-				},
-			},
-		)
+	// Add subscribed tickers for exchanges that read them from env (e.g. KRAKEN_SYMBOLS),
+	// unless the CR sets that env var explicitly
+	if tickersEnv := r.tickersEnvVar(connector); tickersEnv != nil {
+		env = append(env, *tickersEnv)
 	}
 
 	// Add additional env vars from CR spec
@@ -574,12 +674,32 @@ func (r *ConnectorReconciler) constructDeployment(ctx context.Context, connector
 		},
 	}
 
-	// Add resource requirements if specified
-	if connector.Spec.Resources != nil {
-		container.Resources = *connector.Spec.Resources
+	// Add resource requirements, falling back to the operator default
+	container.Resources = containerResources(connector.Spec.Resources, r.DefaultResources)
+
+	volumes := []corev1.Volume{
+		{
+			Name: "config",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: r.configMapName(connector),
+					},
+					DefaultMode: int32Ptr(420),
+				},
+			},
+		},
+	}
+	if connector.Spec.CredentialFiles && len(credentials) > 0 {
+		volumes = append(volumes, credentialFilesVolume(credentials))
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      credentialsVolume,
+			MountPath: credentialsMountPath,
+			ReadOnly:  true,
+		})
 	}
 
-	return &appsv1.Deployment{
+	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.deploymentName(connector),
 			Namespace: connector.Namespace,
@@ -598,6 +718,7 @@ func (r *ConnectorReconciler) constructDeployment(ctx context.Context, connector
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
+					PriorityClassName: priorityClassName(connector.Spec.PriorityClassName, r.DefaultPriorityClassName),
 					SecurityContext: &corev1.PodSecurityContext{
 						RunAsNonRoot: boolPtr(true),
 						RunAsUser:    int64Ptr(1000),
@@ -607,86 +728,24 @@ func (r *ConnectorReconciler) constructDeployment(ctx context.Context, connector
 							Type: corev1.SeccompProfileTypeRuntimeDefault,
 						},
 					},
-					ImagePullSecrets: []corev1.LocalObjectReference{
-						{Name: "ghcr-secret"},
-					},
 					Containers: []corev1.Container{container},
-					Volumes: []corev1.Volume{
-						{
-							Name: "config",
-							VolumeSource: corev1.VolumeSource{
-								ConfigMap: &corev1.ConfigMapVolumeSource{
-									LocalObjectReference: corev1.LocalObjectReference{
-										Name: r.configMapName(connector),
-									},
-									DefaultMode: int32Ptr(420),
-								},
-							},
-						},
-					},
+					Volumes:    volumes,
 				},
 			},
 		},
 	}
-}
-
-// deploymentNeedsUpdate checks if the Deployment needs to be updated
-func (r *ConnectorReconciler) deploymentNeedsUpdate(current, desired *appsv1.Deployment) bool {
-	// Check replicas
-	if *current.Spec.Replicas != *desired.Spec.Replicas {
-		return true
-	}
-
-	if len(current.Spec.Template.Spec.Containers) == 0 || len(desired.Spec.Template.Spec.Containers) == 0 {
-		return len(current.Spec.Template.Spec.Containers) != len(desired.Spec.Template.Spec.Containers)
-	}
-
-	currentContainer := &current.Spec.Template.Spec.Containers[0]
-	desiredContainer := &desired.Spec.Template.Spec.Containers[0]
-
-	// Check image
-	if currentContainer.Image != desiredContainer.Image {
-		return true
-	}
-
-	// Check environment variables
-	if !reflect.DeepEqual(currentContainer.Env, desiredContainer.Env) {
-		return true
-	}
-
-	// Check resource requirements (Autopilot-safe: only check desired fields)
-	if !resourcesMatch(currentContainer.Resources, desiredContainer.Resources) {
-		return true
-	}
-
-	// Check volume mounts
-	if !reflect.DeepEqual(currentContainer.VolumeMounts, desiredContainer.VolumeMounts) {
-		return true
-	}
-
-	// Check args
-	if !reflect.DeepEqual(currentContainer.Args, desiredContainer.Args) {
-		return true
-	}
 
-	// Check volumes
-	if !reflect.DeepEqual(current.Spec.Template.Spec.Volumes, desired.Spec.Template.Spec.Volumes) {
-		return true
-	}
-
-	// Check security context
-	if !reflect.DeepEqual(current.Spec.Template.Spec.SecurityContext, desired.Spec.Template.Spec.SecurityContext) {
-		return true
-	}
-	if !reflect.DeepEqual(currentContainer.SecurityContext, desiredContainer.SecurityContext) {
-		return true
-	}
-
-	return false
+	applyPodTemplate(&deployment.Spec.Template, connector.Spec.PodTemplate)
+	applyTopologySpread(&deployment.Spec.Template, connector.Spec.HighAvailability, deployment.Spec.Selector)
+	setSpecHash(deployment)
+	return deployment
 }
 
 // updateStatus updates the Connector status based on Deployment state
 func (r *ConnectorReconciler) updateStatus(ctx context.Context, connector *ssmdv1alpha1.Connector) error {
+	previousPhase := connector.Status.Phase
+	defer func() { recordPhaseChange(r.Recorder, connector, previousPhase, connector.Status.Phase) }()
+
 	deploymentName := r.deploymentName(connector)
 	deployment := &appsv1.Deployment{}
 	err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: connector.Namespace}, deployment)
@@ -700,12 +759,16 @@ func (r *ConnectorReconciler) updateStatus(ctx context.Context, connector *ssmdv
 		connector.Status.Deployment = deploymentName
 
 		// Determine phase from Deployment status
+		connector.Status.ActiveSubscriptions = 0
 		if deployment.Status.ReadyReplicas > 0 {
 			connector.Status.Phase = ssmdv1alpha1.ConnectorPhaseRunning
 			now := metav1.Now()
 			if connector.Status.StartedAt == nil {
 				connector.Status.StartedAt = &now
 			}
+			if connector.Spec.Subscriptions != nil {
+				connector.Status.ActiveSubscriptions = int32(len(connector.Spec.Subscriptions.Tickers))
+			}
 		} else if deployment.Status.Replicas > 0 {
 			connector.Status.Phase = ssmdv1alpha1.ConnectorPhaseStarting
 		} else {
@@ -726,96 +789,236 @@ func (r *ConnectorReconciler) updateStatus(ctx context.Context, connector *ssmdv
 			condition.Message = "Deployment is ready"
 		}
 		meta.SetStatusCondition(&connector.Status.Conditions, condition)
+
+		// Metrics from the running connector pods
+		if err := r.updateMetricsStatus(ctx, connector); err != nil {
+			return err
+		}
+		if degraded := r.degradedCondition(connector, time.Now()); degraded != nil {
+			meta.SetStatusCondition(&connector.Status.Conditions, *degraded)
+		}
 	}
 
 	return r.Status().Update(ctx, connector)
 }
 
-// deploymentName returns the Deployment name for a Connector
-func (r *ConnectorReconciler) deploymentName(connector *ssmdv1alpha1.Connector) string {
-	return fmt.Sprintf("%s-connector", connector.Name)
+// connectorMetrics is the subset of connector metrics surfaced in the Connector status
+type connectorMetrics struct {
+	MessagesPublished int64
+	ParseErrors       int64
+	LastMessageAt     *time.Time
+	ShardsConnected   int
+	ShardsTotal       int
+	MarketsSubscribed *int32
 }
 
-// configMapName returns the ConfigMap name for a Connector
-func (r *ConnectorReconciler) configMapName(connector *ssmdv1alpha1.Connector) string {
-	return fmt.Sprintf("%s-config", connector.Name)
-}
+// parseConnectorMetrics extracts status values from a connector's /metrics
+func parseConnectorMetrics(families metricFamilies) connectorMetrics {
+	var m connectorMetrics
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *ConnectorReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&ssmdv1alpha1.Connector{}).
-		Owns(&appsv1.Deployment{}).
-		Owns(&corev1.ConfigMap{}).
-		Named("connector").
-		Complete(r)
-}
+	if total, ok := families.sum("ssmd_connector_messages_total"); ok {
+		m.MessagesPublished = int64(total)
+	}
+	if parseErrors, ok := families.sum("ssmd_connector_parse_errors_total"); ok {
+		m.ParseErrors = int64(parseErrors)
+	}
+	if ts, ok := families.max("ssmd_connector_last_activity_timestamp"); ok && ts > 0 {
+		lastMessageAt := time.Unix(int64(ts), 0)
+		m.LastMessageAt = &lastMessageAt
+	}
+	for _, v := range families.values("ssmd_connector_websocket_connected") {
+		m.ShardsTotal++
+		if v > 0 {
+			m.ShardsConnected++
+		}
+	}
+	if subscribed, ok := families.sum("ssmd_connector_markets_subscribed"); ok {
+		m.MarketsSubscribed = int32Ptr(int32(subscribed))
+	}
 
-// FeedVersion represents a feed protocol version
-type FeedVersion struct {
-	Version       string `yaml:"version"`
-	EffectiveFrom string `yaml:"effective_from"`
-	Protocol      struct {
-		Transport string `yaml:"transport"`
-		Message   string `yaml:"message"`
-	} `yaml:"protocol"`
-	Endpoint   string `yaml:"endpoint"`
-	AuthMethod string `yaml:"auth_method"`
+	return m
 }
 
-// FeedTransportDefaults represents default transport config
-type FeedTransportDefaults struct {
-	Type          string `yaml:"type"`
-	Stream        string `yaml:"stream"`
-	SubjectPrefix string `yaml:"subjectPrefix"`
+// add merges metrics from another pod
+func (m *connectorMetrics) add(other connectorMetrics) {
+	m.MessagesPublished += other.MessagesPublished
+	m.ParseErrors += other.ParseErrors
+	if other.LastMessageAt != nil && (m.LastMessageAt == nil || other.LastMessageAt.After(*m.LastMessageAt)) {
+		m.LastMessageAt = other.LastMessageAt
+	}
+	m.ShardsConnected += other.ShardsConnected
+	m.ShardsTotal += other.ShardsTotal
+	if other.MarketsSubscribed != nil {
+		total := *other.MarketsSubscribed
+		if m.MarketsSubscribed != nil {
+			total += *m.MarketsSubscribed
+		}
+		m.MarketsSubscribed = &total
+	}
 }
 
-// FeedConnectorDefaults represents connector defaults from feed ConfigMap
-type FeedConnectorDefaults struct {
-	Image     string                 `yaml:"image"`
-	Version   string                 `yaml:"version"`
-	Transport *FeedTransportDefaults `yaml:"transport,omitempty"`
-}
+// updateMetricsStatus scrapes the Connector's pods and copies their metrics into status.
+// Scrape failures are logged and leave the previous values in place.
+func (r *ConnectorReconciler) updateMetricsStatus(ctx context.Context, connector *ssmdv1alpha1.Connector) error {
+	total, restarts, scraped, err := r.scrapeConnectorPods(ctx, connector.Namespace, connector.Name)
+	if err != nil {
+		return err
+	}
 
-// FeedDefaults represents the defaults section
-type FeedDefaults struct {
-	Connector *FeedConnectorDefaults `yaml:"connector,omitempty"`
-}
+	connector.Status.ReconnectCount = restarts
+	if scraped == 0 {
+		return nil
+	}
+
+	connector.Status.MessagesPublished = total.MessagesPublished
+	if total.LastMessageAt != nil {
+		lastMessageAt := metav1.NewTime(*total.LastMessageAt)
+		connector.Status.LastMessageAt = &lastMessageAt
+	}
+	if total.MarketsSubscribed != nil {
+		connector.Status.ActiveSubscriptions = *total.MarketsSubscribed
+	}
+	switch {
+	case total.ShardsTotal == 0:
+		// Connector has not reported WebSocket state yet
+	case total.ShardsConnected == total.ShardsTotal:
+		connector.Status.ConnectionState = ssmdv1alpha1.ConnectionStateConnected
+	case total.ShardsConnected > 0:
+		connector.Status.ConnectionState = ssmdv1alpha1.ConnectionStateReconnecting
+	default:
+		connector.Status.ConnectionState = ssmdv1alpha1.ConnectionStateDisconnected
+	}
 
-// FeedConfig represents a parsed feed.yaml from a feed ConfigMap
-type FeedConfig struct {
-	Name        string        `yaml:"name"`
-	DisplayName string        `yaml:"display_name"`
-	Type        string        `yaml:"type"`
-	Status      string        `yaml:"status"`
-	Versions    []FeedVersion `yaml:"versions"`
-	Defaults    *FeedDefaults `yaml:"defaults,omitempty"`
+	return nil
 }
 
-// getFeedConfig reads and parses the feed ConfigMap for a given feed name
-func (r *ConnectorReconciler) getFeedConfig(ctx context.Context, namespace, feedName string) (*FeedConfig, error) {
-	configMapName := fmt.Sprintf("feed-%s", feedName)
-	configMap := &corev1.ConfigMap{}
+// scrapeConnectorPods sums the metrics of the running connector pods with the
+// given instance label, returning the connector container restarts across all
+// its pods and how many pods were scraped. Scrape failures are logged and skipped.
+func (r *ConnectorReconciler) scrapeConnectorPods(ctx context.Context, namespace, instance string) (connectorMetrics, int32, int, error) {
+	log := logf.FromContext(ctx)
 
-	err := r.Get(ctx, types.NamespacedName{Name: configMapName, Namespace: namespace}, configMap)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return nil, nil
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabels{
+		"app.kubernetes.io/name":     "ssmd-connector",
+		"app.kubernetes.io/instance": instance,
+	}); err != nil {
+		return connectorMetrics{}, 0, 0, err
+	}
+
+	scrape := r.scrape
+	if scrape == nil {
+		scrape = scrapeMetrics
+	}
+
+	var total connectorMetrics
+	var restarts int32
+	scraped := 0
+	for _, pod := range pods.Items {
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Name == "connector" {
+				restarts += cs.RestartCount
+			}
 		}
-		return nil, err
+
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		url := fmt.Sprintf("http://%s:%d/metrics", pod.Status.PodIP, connectorMetricsPort)
+		families, err := scrape(ctx, url)
+		if err != nil {
+			log.V(1).Info("Failed to scrape connector metrics", "pod", pod.Name, "error", err.Error())
+			continue
+		}
+		total.add(parseConnectorMetrics(families))
+		scraped++
+	}
+	return total, restarts, scraped, nil
+}
+
+// degradedCondition returns the Degraded condition for a running Connector, or nil
+// if it is not running. Degraded is true when no message has arrived within
+// spec.degradedAfterMinutes (measured from startup if no message has arrived yet).
+func (r *ConnectorReconciler) degradedCondition(connector *ssmdv1alpha1.Connector, now time.Time) *metav1.Condition {
+	if connector.Status.Phase != ssmdv1alpha1.ConnectorPhaseRunning {
+		return nil
+	}
+
+	since := connector.Status.LastMessageAt
+	if since == nil {
+		since = connector.Status.StartedAt
+	}
+	if since == nil {
+		return nil
 	}
 
-	feedYAML, ok := configMap.Data["feed.yaml"]
-	if !ok {
-		return nil, nil
+	threshold := time.Duration(defaultDegradedAfterMinutes) * time.Minute
+	if connector.Spec.DegradedAfterMinutes != nil {
+		threshold = time.Duration(*connector.Spec.DegradedAfterMinutes) * time.Minute
 	}
 
-	var feed FeedConfig
-	if err := yaml.Unmarshal([]byte(feedYAML), &feed); err != nil {
-		return nil, fmt.Errorf("failed to parse feed ConfigMap %s: %w", configMapName, err)
+	condition := &metav1.Condition{
+		Type:               "Degraded",
+		Status:             metav1.ConditionFalse,
+		Reason:             "ReceivingMessages",
+		Message:            "Connector is receiving messages",
+		LastTransitionTime: metav1.NewTime(now),
+	}
+	if idle := now.Sub(since.Time); idle > threshold {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "NoRecentMessages"
+		condition.Message = fmt.Sprintf("No messages for %s", idle.Truncate(time.Second))
 	}
+	return condition
+}
 
-	return &feed, nil
+// tickersEnvVar returns the env var carrying spec.subscriptions.tickers for the
+// Connector's exchange, or nil if the exchange has none or the CR already sets it.
+func (r *ConnectorReconciler) tickersEnvVar(connector *ssmdv1alpha1.Connector) *corev1.EnvVar {
+	profile := r.exchangeProfile(connector)
+	if profile == nil || profile.TickersEnv == "" ||
+		connector.Spec.Subscriptions == nil || len(connector.Spec.Subscriptions.Tickers) == 0 {
+		return nil
+	}
+	for _, e := range connector.Spec.EnvVars {
+		if e.Name == profile.TickersEnv {
+			return nil
+		}
+	}
+	return &corev1.EnvVar{Name: profile.TickersEnv, Value: strings.Join(connector.Spec.Subscriptions.Tickers, ",")}
+}
+
+// credentialEnvNames returns the env var names for secretRef credentials.
+// Uses the exchange profile if spec.exchange is set, else derives them from the feed name.
+func (r *ConnectorReconciler) credentialEnvNames(connector *ssmdv1alpha1.Connector) (string, string) {
+	if profile := r.exchangeProfile(connector); profile != nil {
+		return profile.APIKeyEnv, profile.PrivateKeyEnv
+	}
+	feedUpper := strings.ToUpper(connector.Spec.Feed)
+	return feedUpper + "_API_KEY", feedUpper + "_PRIVATE_KEY"
+}
+
+// deploymentName returns the Deployment name for a Connector
+func (r *ConnectorReconciler) deploymentName(connector *ssmdv1alpha1.Connector) string {
+	return fmt.Sprintf("%s-connector", connector.Name)
+}
+
+// configMapName returns the ConfigMap name for a Connector
+func (r *ConnectorReconciler) configMapName(connector *ssmdv1alpha1.Connector) string {
+	return fmt.Sprintf("%s-config", connector.Name)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ConnectorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ssmdv1alpha1.Connector{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&corev1.ConfigMap{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.connectorsForSecret)).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		Named("connector").
+		Complete(r)
 }
 
 func boolPtr(b bool) *bool    { return &b }
@@ -0,0 +1,206 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+const (
+	// credentialsMountPath is where credentialFiles mounts the credential Secrets
+	credentialsMountPath = "/etc/ssmd/credentials"
+	credentialsVolume    = "credentials"
+
+	// credentialsChecksumAnnotation records the hash of the mounted Secret keys
+	// on the pod template, so a rotation rolls the Deployment
+	credentialsChecksumAnnotation = "ssmd.io/credentials-checksum"
+)
+
+// credentialItem is one credential: the Secret key and the env var (or file) name it is exposed as
+type credentialItem struct {
+	EnvName    string
+	SecretName string
+	SecretKey  string
+}
+
+// credentialItems returns the Connector's credentials from secretEnvVars, else the legacy secretRef
+func (r *ConnectorReconciler) credentialItems(connector *ssmdv1alpha1.Connector) []credentialItem {
+	if len(connector.Spec.SecretEnvVars) > 0 {
+		items := make([]credentialItem, 0, len(connector.Spec.SecretEnvVars))
+		for _, sev := range connector.Spec.SecretEnvVars {
+			items = append(items, credentialItem{EnvName: sev.EnvName, SecretName: sev.SecretName, SecretKey: sev.SecretKey})
+		}
+		return items
+	}
+	if connector.Spec.SecretRef == nil {
+		return nil
+	}
+
+	// Legacy secretRef handling: env var names from exchange profile or feed name
+	apiKeyEnvVar, privateKeyEnvVar := r.credentialEnvNames(connector)
+	apiKeyField := "api-key"
+	if connector.Spec.SecretRef.APIKeyField != "" {
+		apiKeyField = connector.Spec.SecretRef.APIKeyField
+	}
+	privateKeyField := "private-key"
+	if connector.Spec.SecretRef.PrivateKeyField != "" {
+		privateKeyField = connector.Spec.SecretRef.PrivateKeyField
+	}
+	return []credentialItem{
+		{EnvName: apiKeyEnvVar, SecretName: connector.Spec.SecretRef.Name, SecretKey: apiKeyField},
+		{EnvName: privateKeyEnvVar, SecretName: connector.Spec.SecretRef.Name, SecretKey: privateKeyField},
+	}
+}
+
+// credentialEnvVars exposes each credential as an env var read from its Secret
+func credentialEnvVars(items []credentialItem) []corev1.EnvVar {
+	env := make([]corev1.EnvVar, 0, len(items))
+	for _, item := range items {
+		env = append(env, corev1.EnvVar{
+			Name: item.EnvName,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: item.SecretName},
+					Key:                  item.SecretKey,
+				},
+			},
+		})
+	}
+	return env
+}
+
+// credentialFilesVolume projects each credential to <credentialsMountPath>/<env name>,
+// one source per Secret in first-referenced order
+func credentialFilesVolume(items []credentialItem) corev1.Volume {
+	var sources []corev1.VolumeProjection
+	bySecret := map[string]int{}
+	for _, item := range items {
+		i, ok := bySecret[item.SecretName]
+		if !ok {
+			i = len(sources)
+			bySecret[item.SecretName] = i
+			sources = append(sources, corev1.VolumeProjection{
+				Secret: &corev1.SecretProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: item.SecretName},
+				},
+			})
+		}
+		sources[i].Secret.Items = append(sources[i].Secret.Items, corev1.KeyToPath{Key: item.SecretKey, Path: item.EnvName})
+	}
+	return corev1.Volume{
+		Name: credentialsVolume,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources:     sources,
+				DefaultMode: int32Ptr(0o440),
+			},
+		},
+	}
+}
+
+// credentialFilesSource returns the env.yaml key source reading the mounted files
+// for the given env names, e.g. "file:/etc/ssmd/credentials/KALSHI_API_KEY,..."
+func credentialFilesSource(envNames ...string) string {
+	paths := make([]string, 0, len(envNames))
+	for _, name := range envNames {
+		paths = append(paths, path.Join(credentialsMountPath, name))
+	}
+	return "file:" + strings.Join(paths, ",")
+}
+
+// credentialsChecksum hashes the referenced Secret keys. Missing Secrets and
+// keys hash as missing, so their creation also rolls the connector.
+func (r *ConnectorReconciler) credentialsChecksum(ctx context.Context, connector *ssmdv1alpha1.Connector, items []credentialItem) (string, error) {
+	hash := sha256.New()
+	secrets := map[string]*corev1.Secret{}
+	for _, item := range items {
+		secret, fetched := secrets[item.SecretName]
+		if !fetched {
+			secret = &corev1.Secret{}
+			err := r.Get(ctx, types.NamespacedName{Name: item.SecretName, Namespace: connector.Namespace}, secret)
+			if errors.IsNotFound(err) {
+				secret = nil
+			} else if err != nil {
+				return "", err
+			}
+			secrets[item.SecretName] = secret
+		}
+
+		hash.Write([]byte(item.SecretName + "/" + item.SecretKey + "="))
+		var value []byte
+		ok := false
+		if secret != nil {
+			value, ok = secret.Data[item.SecretKey]
+		}
+		if ok {
+			hash.Write(value)
+		} else {
+			hash.Write([]byte("<missing>"))
+		}
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+// setCredentialsChecksum annotates the pod template with the credentials
+// checksum and re-stamps the spec hash, so only a real rotation rolls the
+// Deployment (Recreate strategy: the old pod stops before the new one starts)
+func setCredentialsChecksum(deployment *appsv1.Deployment, checksum string) {
+	metav1.SetMetaDataAnnotation(&deployment.Spec.Template.ObjectMeta, credentialsChecksumAnnotation, checksum)
+	setSpecHash(deployment)
+}
+
+// connectorsForSecret maps a Secret event to the credentialFiles Connectors that mount it
+func (r *ConnectorReconciler) connectorsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	connectors := &ssmdv1alpha1.ConnectorList{}
+	if err := r.List(ctx, connectors, client.InNamespace(obj.GetNamespace())); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list Connectors for Secret", "secret", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range connectors.Items {
+		connector := &connectors.Items[i]
+		if !connector.Spec.CredentialFiles {
+			continue
+		}
+		for _, item := range r.credentialItems(connector) {
+			if item.SecretName == obj.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: connector.Name, Namespace: connector.Namespace},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// connectorExchangeProfile holds the per-exchange defaults used when a Connector
+// sets spec.exchange. A feed ConfigMap and the CR spec both override these.
+type connectorExchangeProfile struct {
+	DisplayName   string
+	Endpoint      string
+	AuthMethod    string
+	Stream        string
+	SubjectPrefix string
+
+	// APIKeyEnv and PrivateKeyEnv are the env var names the connector binary
+	// reads credentials from (mirrors HarmanReconciler.exchangeEnvVars)
+	APIKeyEnv     string
+	PrivateKeyEnv string
+
+	// TickersEnv is the env var the connector reads a comma-separated
+	// subscription list from, if the exchange supports one
+	TickersEnv string
+}
+
+// connectorExchangeProfiles maps each supported exchange to its defaults
+var connectorExchangeProfiles = map[ssmdv1alpha1.ExchangeType]connectorExchangeProfile{
+	ssmdv1alpha1.ExchangeTypeKalshi: {
+		DisplayName:   "Kalshi Exchange",
+		Endpoint:      "wss://api.elections.kalshi.com/trade-api/ws/v2",
+		AuthMethod:    "api_key",
+		Stream:        "PROD_KALSHI",
+		SubjectPrefix: "prod.kalshi",
+		APIKeyEnv:     "KALSHI_API_KEY",
+		PrivateKeyEnv: "KALSHI_PRIVATE_KEY",
+	},
+	ssmdv1alpha1.ExchangeTypeKraken: {
+		DisplayName:   "Kraken Exchange",
+		Endpoint:      "wss://ws.kraken.com/v2",
+		AuthMethod:    "none",
+		Stream:        "PROD_KRAKEN",
+		SubjectPrefix: "prod.kraken",
+		APIKeyEnv:     "KRAKEN_API_KEY",
+		PrivateKeyEnv: "KRAKEN_API_SECRET",
+		TickersEnv:    "KRAKEN_SYMBOLS",
+	},
+	ssmdv1alpha1.ExchangeTypePolymarket: {
+		DisplayName:   "Polymarket CLOB",
+		Endpoint:      "wss://ws-subscriptions-clob.polymarket.com/ws/market",
+		AuthMethod:    "none",
+		Stream:        "PROD_POLYMARKET",
+		SubjectPrefix: "prod.polymarket",
+		APIKeyEnv:     "POLYMARKET_API_KEY",
+		PrivateKeyEnv: "POLYMARKET_SECRET",
+		TickersEnv:    "POLYMARKET_TOKEN_IDS",
+	},
+	ssmdv1alpha1.ExchangeTypeCoinbase: {
+		DisplayName:   "Coinbase Advanced Trade",
+		Endpoint:      "wss://advanced-trade-ws.coinbase.com",
+		AuthMethod:    "none",
+		Stream:        "PROD_COINBASE",
+		SubjectPrefix: "prod.coinbase",
+		APIKeyEnv:     "COINBASE_API_KEY",
+		PrivateKeyEnv: "COINBASE_API_SECRET",
+	},
+}
+
+// exchangeProfile returns the profile for the Connector's exchange, or nil if
+// spec.exchange is unset or has no profile (e.g. the test exchange).
+func (r *ConnectorReconciler) exchangeProfile(connector *ssmdv1alpha1.Connector) *connectorExchangeProfile {
+	if connector.Spec.Exchange == "" {
+		return nil
+	}
+	profile, ok := connectorExchangeProfiles[connector.Spec.Exchange]
+	if !ok {
+		return nil
+	}
+	return &profile
+}
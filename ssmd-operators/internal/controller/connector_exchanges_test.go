@@ -0,0 +1,288 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/feedconfig"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestConnector builds a Connector CR for unit tests.
+func newTestConnector(feed string, exchange ssmdv1alpha1.ExchangeType) *ssmdv1alpha1.Connector {
+	return &ssmdv1alpha1.Connector{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "connector-test",
+			Namespace: "ssmd",
+		},
+		Spec: ssmdv1alpha1.ConnectorSpec{
+			Feed:     feed,
+			Exchange: exchange,
+		},
+	}
+}
+
+// envVarNames returns the env var names of the connector container.
+func envVarNames(r *ConnectorReconciler, connector *ssmdv1alpha1.Connector) map[string]bool {
+	dep := r.constructDeployment(context.Background(), connector, nil)
+	names := map[string]bool{}
+	for _, e := range dep.Spec.Template.Spec.Containers[0].Env {
+		names[e.Name] = true
+	}
+	return names
+}
+
+// --- TestBuildFeedYAML ---
+
+func TestBuildFeedYAML_ExchangeProfile(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kraken-spot", ssmdv1alpha1.ExchangeTypeKraken)
+
+	feedYAML := r.buildFeedYAML(connector, nil)
+
+	if !strings.Contains(feedYAML, "endpoint: wss://ws.kraken.com/v2") {
+		t.Errorf("expected kraken endpoint, got:\n%s", feedYAML)
+	}
+	if !strings.Contains(feedYAML, "auth_method: none") {
+		t.Errorf("expected auth_method none, got:\n%s", feedYAML)
+	}
+}
+
+func TestBuildFeedYAML_FeedConfigOverridesProfile(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	feedConfig := &feedconfig.Config{
+		DisplayName: "Kalshi Demo",
+		Versions:    []feedconfig.Version{{Endpoint: "wss://demo-api.kalshi.co/trade-api/ws/v2", AuthMethod: "api_key"}},
+	}
+
+	feedYAML := r.buildFeedYAML(connector, feedConfig)
+
+	if !strings.Contains(feedYAML, "endpoint: wss://demo-api.kalshi.co/trade-api/ws/v2") {
+		t.Errorf("expected feed ConfigMap endpoint, got:\n%s", feedYAML)
+	}
+}
+
+func TestBuildFeedYAML_FeedConfigRateLimit(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kalshi", "")
+	feedConfig := &feedconfig.Config{
+		DisplayName: "Kalshi Exchange",
+		Versions: []feedconfig.Version{
+			{EffectiveFrom: "2025-12-22", Endpoint: "wss://api.elections.kalshi.com/trade-api/ws/v2", AuthMethod: "api_key", RateLimitPerSecond: 10},
+		},
+	}
+
+	feedYAML := r.buildFeedYAML(connector, feedConfig)
+
+	for _, want := range []string{"display_name: Kalshi Exchange", "auth_method: api_key", "rate_limit_per_second: 10"} {
+		if !strings.Contains(feedYAML, want) {
+			t.Errorf("expected %q, got:\n%s", want, feedYAML)
+		}
+	}
+}
+
+func TestBuildFeedYAML_NoExchangeNoFeedConfig(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kalshi", "")
+
+	feedYAML := r.buildFeedYAML(connector, nil)
+
+	if !strings.Contains(feedYAML, "MISSING_FEED_CONFIGMAP") {
+		t.Errorf("expected placeholder endpoint, got:\n%s", feedYAML)
+	}
+}
+
+// --- TestBuildEnvYAML ---
+
+func TestBuildEnvYAML_ExchangeProfileTransport(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("polymarket", ssmdv1alpha1.ExchangeTypePolymarket)
+
+	envYAML := r.buildEnvYAML(connector, nil)
+
+	if !strings.Contains(envYAML, "stream: PROD_POLYMARKET") || !strings.Contains(envYAML, "subject_prefix: prod.polymarket") {
+		t.Errorf("expected polymarket transport defaults, got:\n%s", envYAML)
+	}
+}
+
+func TestBuildEnvYAML_SpecTransportOverridesProfile(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Spec.Transport = &ssmdv1alpha1.TransportConfig{SubjectPrefix: "prod.kalshi.main"}
+
+	envYAML := r.buildEnvYAML(connector, nil)
+
+	if !strings.Contains(envYAML, "stream: PROD_KALSHI") || !strings.Contains(envYAML, "subject_prefix: prod.kalshi.main") {
+		t.Errorf("expected spec subject prefix with profile stream, got:\n%s", envYAML)
+	}
+}
+
+func TestBuildEnvYAML_ExchangeKeyEnvNames(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kalshi-demo", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Spec.SecretRef = &ssmdv1alpha1.SecretReference{Name: "kalshi-credentials"}
+
+	envYAML := r.buildEnvYAML(connector, nil)
+
+	if !strings.Contains(envYAML, `source: "env:KALSHI_API_KEY,KALSHI_PRIVATE_KEY"`) {
+		t.Errorf("expected exchange credential env names, got:\n%s", envYAML)
+	}
+}
+
+// --- TestConstructDeployment (connector credentials) ---
+
+func TestConnectorCredentials_ExchangeProfile(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kraken-spot", ssmdv1alpha1.ExchangeTypeKraken)
+	connector.Spec.SecretRef = &ssmdv1alpha1.SecretReference{Name: "kraken-credentials"}
+
+	names := envVarNames(r, connector)
+
+	if !names["KRAKEN_API_KEY"] || !names["KRAKEN_API_SECRET"] {
+		t.Errorf("expected kraken credential env vars, got %v", names)
+	}
+	if names["KRAKEN-SPOT_API_KEY"] {
+		t.Error("feed-derived env var names should not be used when exchange is set")
+	}
+}
+
+func TestConnectorCredentials_LegacyFeedName(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kalshi", "")
+	connector.Spec.SecretRef = &ssmdv1alpha1.SecretReference{Name: "kalshi-credentials"}
+
+	names := envVarNames(r, connector)
+
+	if !names["KALSHI_API_KEY"] || !names["KALSHI_PRIVATE_KEY"] {
+		t.Errorf("expected feed-derived env vars, got %v", names)
+	}
+}
+
+func TestConnectorExchangeProfiles_AllExchangesCovered(t *testing.T) {
+	for _, exchange := range []ssmdv1alpha1.ExchangeType{
+		ssmdv1alpha1.ExchangeTypeKalshi,
+		ssmdv1alpha1.ExchangeTypeKraken,
+		ssmdv1alpha1.ExchangeTypePolymarket,
+		ssmdv1alpha1.ExchangeTypeCoinbase,
+	} {
+		profile, ok := connectorExchangeProfiles[exchange]
+		if !ok {
+			t.Errorf("missing profile for %s", exchange)
+			continue
+		}
+		if profile.Endpoint == "" || profile.Stream == "" || profile.APIKeyEnv == "" || profile.PrivateKeyEnv == "" {
+			t.Errorf("incomplete profile for %s: %+v", exchange, profile)
+		}
+	}
+}
+
+// --- TestSubscriptions ---
+
+func TestBuildEnvYAML_Subscriptions(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Spec.Subscriptions = &ssmdv1alpha1.SubscriptionsConfig{
+		Tickers:        []string{"KXBTCD-26JAN0117-T100000", "KXETHD-26JAN0117-T4000"},
+		Channels:       []string{"ticker", "orderbook_delta"},
+		OrderbookDepth: int32Ptr(10),
+	}
+
+	envYAML := r.buildEnvYAML(connector, nil)
+
+	want := `subscription:
+  tickers:
+    - KXBTCD-26JAN0117-T100000
+    - KXETHD-26JAN0117-T4000
+  channels:
+    - ticker
+    - orderbook_delta
+  orderbook_depth: 10
+`
+	if !strings.Contains(envYAML, want) {
+		t.Errorf("expected subscription section, got:\n%s", envYAML)
+	}
+}
+
+func TestBuildEnvYAML_SubscriptionsWithCategories(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Spec.Categories = []string{"Crypto"}
+	connector.Spec.Subscriptions = &ssmdv1alpha1.SubscriptionsConfig{Channels: []string{"ticker"}}
+
+	envYAML := r.buildEnvYAML(connector, nil)
+
+	if strings.Count(envYAML, "subscription:") != 1 {
+		t.Errorf("expected a single subscription section, got:\n%s", envYAML)
+	}
+	if !strings.Contains(envYAML, "subscription:\n  batch_size: 100\n  retry_attempts: 3\n  retry_delay_ms: 1000\n  channels:\n    - ticker\n") {
+		t.Errorf("expected batching and channels in subscription section, got:\n%s", envYAML)
+	}
+}
+
+func TestBuildEnvYAML_NoSubscriptionSection(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kraken", ssmdv1alpha1.ExchangeTypeKraken)
+
+	if envYAML := r.buildEnvYAML(connector, nil); strings.Contains(envYAML, "subscription:") {
+		t.Errorf("expected no subscription section, got:\n%s", envYAML)
+	}
+}
+
+func TestTickersEnvVar_Kraken(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kraken", ssmdv1alpha1.ExchangeTypeKraken)
+	connector.Spec.Subscriptions = &ssmdv1alpha1.SubscriptionsConfig{Tickers: []string{"BTC/USD", "ETH/USD"}}
+
+	dep := r.constructDeployment(context.Background(), connector, nil)
+
+	var got string
+	for _, e := range dep.Spec.Template.Spec.Containers[0].Env {
+		if e.Name == "KRAKEN_SYMBOLS" {
+			got = e.Value
+		}
+	}
+	if got != "BTC/USD,ETH/USD" {
+		t.Errorf("KRAKEN_SYMBOLS = %q, want %q", got, "BTC/USD,ETH/USD")
+	}
+}
+
+func TestTickersEnvVar_ExplicitEnvVarWins(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kraken", ssmdv1alpha1.ExchangeTypeKraken)
+	connector.Spec.Subscriptions = &ssmdv1alpha1.SubscriptionsConfig{Tickers: []string{"BTC/USD"}}
+	connector.Spec.EnvVars = []corev1.EnvVar{{Name: "KRAKEN_SYMBOLS", Value: "SOL/USD"}}
+
+	if env := r.tickersEnvVar(connector); env != nil {
+		t.Errorf("expected no generated env var when CR sets KRAKEN_SYMBOLS, got %+v", env)
+	}
+}
+
+func TestTickersEnvVar_KalshiHasNone(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Spec.Subscriptions = &ssmdv1alpha1.SubscriptionsConfig{Tickers: []string{"KXBTCD-26JAN0117-T100000"}}
+
+	if env := r.tickersEnvVar(connector); env != nil {
+		t.Errorf("expected no tickers env var for kalshi, got %+v", env)
+	}
+}
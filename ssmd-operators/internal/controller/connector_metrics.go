@@ -0,0 +1,156 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// metricsScrapeTimeout bounds a single /metrics request so a hung pod cannot stall reconciles
+const metricsScrapeTimeout = 5 * time.Second
+
+// metricFamilies is a parsed Prometheus text exposition, keyed by metric name
+type metricFamilies map[string]*dto.MetricFamily
+
+// metricsScraper fetches and parses a Prometheus /metrics endpoint
+type metricsScraper func(ctx context.Context, url string) (metricFamilies, error)
+
+var metricsHTTPClient = &http.Client{Timeout: metricsScrapeTimeout}
+
+// scrapeMetrics is the default metricsScraper: an HTTP GET parsed as Prometheus text format
+func scrapeMetrics(ctx context.Context, url string) (metricFamilies, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := metricsHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics from %s: %w", url, err)
+	}
+	return families, nil
+}
+
+// sum adds up every sample of a counter or gauge family. ok is false if the family is absent.
+func (f metricFamilies) sum(name string) (total float64, ok bool) {
+	family, ok := f[name]
+	if !ok {
+		return 0, false
+	}
+	for _, m := range family.GetMetric() {
+		total += metricValue(m)
+	}
+	return total, true
+}
+
+// max returns the largest sample of a family. ok is false if the family is absent or empty.
+func (f metricFamilies) max(name string) (max float64, ok bool) {
+	family, found := f[name]
+	if !found {
+		return 0, false
+	}
+	for i, m := range family.GetMetric() {
+		v := metricValue(m)
+		if i == 0 || v > max {
+			max = v
+		}
+		ok = true
+	}
+	return max, ok
+}
+
+// values returns every sample of a family
+func (f metricFamilies) values(name string) []float64 {
+	family, ok := f[name]
+	if !ok {
+		return nil
+	}
+	values := make([]float64, 0, len(family.GetMetric()))
+	for _, m := range family.GetMetric() {
+		values = append(values, metricValue(m))
+	}
+	return values
+}
+
+// sumByLabel adds up the samples of a family for each value of label
+func (f metricFamilies) sumByLabel(name, label string) map[string]float64 {
+	family, ok := f[name]
+	if !ok {
+		return nil
+	}
+	sums := map[string]float64{}
+	for _, m := range family.GetMetric() {
+		sums[labelValue(m, label)] += metricValue(m)
+	}
+	return sums
+}
+
+// maxByLabel returns the largest sample of a family for each value of label
+func (f metricFamilies) maxByLabel(name, label string) map[string]float64 {
+	family, ok := f[name]
+	if !ok {
+		return nil
+	}
+	maxes := map[string]float64{}
+	for _, m := range family.GetMetric() {
+		key := labelValue(m, label)
+		if v, seen := maxes[key]; !seen || metricValue(m) > v {
+			maxes[key] = metricValue(m)
+		}
+	}
+	return maxes
+}
+
+// labelValue returns the value of label on m, or "" if it is not set
+func labelValue(m *dto.Metric, label string) string {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == label {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
+
+func metricValue(m *dto.Metric) float64 {
+	switch {
+	case m.GetCounter() != nil:
+		return m.GetCounter().GetValue()
+	case m.GetGauge() != nil:
+		return m.GetGauge().GetValue()
+	case m.GetUntyped() != nil:
+		return m.GetUntyped().GetValue()
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testConnectorMetrics = `# HELP ssmd_connector_messages_total Total messages received
+# TYPE ssmd_connector_messages_total counter
+ssmd_connector_messages_total{feed="kalshi",category="Crypto",shard="0",message_type="ticker"} 1200
+ssmd_connector_messages_total{feed="kalshi",category="Crypto",shard="0",message_type="trade"} 34
+ssmd_connector_messages_total{feed="kalshi",category="Crypto",shard="1",message_type="ticker"} 800
+# HELP ssmd_connector_last_activity_timestamp Last activity
+# TYPE ssmd_connector_last_activity_timestamp gauge
+ssmd_connector_last_activity_timestamp{feed="kalshi",category="Crypto",shard="0"} 1767225600
+ssmd_connector_last_activity_timestamp{feed="kalshi",category="Crypto",shard="1"} 1767225540
+# HELP ssmd_connector_websocket_connected WebSocket connected
+# TYPE ssmd_connector_websocket_connected gauge
+ssmd_connector_websocket_connected{feed="kalshi",category="Crypto",shard="0"} 1
+ssmd_connector_websocket_connected{feed="kalshi",category="Crypto",shard="1"} 0
+# HELP ssmd_connector_markets_subscribed Markets subscribed
+# TYPE ssmd_connector_markets_subscribed gauge
+ssmd_connector_markets_subscribed{feed="kalshi",category="Crypto",shard="0"} 250
+ssmd_connector_markets_subscribed{feed="kalshi",category="Crypto",shard="1"} 120
+`
+
+func parseTestMetrics(t *testing.T, text string) metricFamilies {
+	t.Helper()
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("failed to parse test metrics: %v", err)
+	}
+	return families
+}
+
+// newMetricsTestReconciler builds a ConnectorReconciler backed by a fake client
+// holding the given objects, with scrape returning the given metrics text.
+func newMetricsTestReconciler(t *testing.T, text string, scrapeErr error, objs ...runtime.Object) *ConnectorReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	return &ConnectorReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme: scheme,
+		scrape: func(ctx context.Context, url string) (metricFamilies, error) {
+			if scrapeErr != nil {
+				return nil, scrapeErr
+			}
+			return parseTestMetrics(t, text), nil
+		},
+	}
+}
+
+func newConnectorPod(name string, phase corev1.PodPhase, restarts int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ssmd",
+			Labels: map[string]string{
+				"app.kubernetes.io/name":     "ssmd-connector",
+				"app.kubernetes.io/instance": "connector-test",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+			PodIP: "10.0.0.1",
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "connector", RestartCount: restarts},
+			},
+		},
+	}
+}
+
+// --- TestParseConnectorMetrics ---
+
+func TestParseConnectorMetrics(t *testing.T) {
+	m := parseConnectorMetrics(parseTestMetrics(t, testConnectorMetrics))
+
+	if m.MessagesPublished != 2034 {
+		t.Errorf("MessagesPublished = %d, want 2034", m.MessagesPublished)
+	}
+	if m.LastMessageAt == nil || m.LastMessageAt.Unix() != 1767225600 {
+		t.Errorf("LastMessageAt = %v, want unix 1767225600", m.LastMessageAt)
+	}
+	if m.ShardsConnected != 1 || m.ShardsTotal != 2 {
+		t.Errorf("shards connected/total = %d/%d, want 1/2", m.ShardsConnected, m.ShardsTotal)
+	}
+	if m.MarketsSubscribed == nil || *m.MarketsSubscribed != 370 {
+		t.Errorf("MarketsSubscribed = %v, want 370", m.MarketsSubscribed)
+	}
+}
+
+func TestParseConnectorMetrics_Empty(t *testing.T) {
+	m := parseConnectorMetrics(metricFamilies{})
+
+	if m.MessagesPublished != 0 || m.LastMessageAt != nil || m.ShardsTotal != 0 || m.MarketsSubscribed != nil {
+		t.Errorf("expected zero metrics, got %+v", m)
+	}
+}
+
+func TestScrapeMetrics_HTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testConnectorMetrics))
+	}))
+	defer srv.Close()
+
+	families, err := scrapeMetrics(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := families["ssmd_connector_messages_total"]; !ok {
+		t.Error("expected ssmd_connector_messages_total family")
+	}
+}
+
+func TestScrapeMetrics_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	if _, err := scrapeMetrics(context.Background(), srv.URL); err == nil {
+		t.Error("expected error for non-200 response")
+	}
+}
+
+// --- TestUpdateMetricsStatus ---
+
+func TestUpdateMetricsStatus(t *testing.T) {
+	r := newMetricsTestReconciler(t, testConnectorMetrics, nil,
+		newConnectorPod("connector-test-abc", corev1.PodRunning, 3))
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+
+	if err := r.updateMetricsStatus(context.Background(), connector); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if connector.Status.MessagesPublished != 2034 {
+		t.Errorf("MessagesPublished = %d, want 2034", connector.Status.MessagesPublished)
+	}
+	if connector.Status.ActiveSubscriptions != 370 {
+		t.Errorf("ActiveSubscriptions = %d, want 370", connector.Status.ActiveSubscriptions)
+	}
+	if connector.Status.ConnectionState != ssmdv1alpha1.ConnectionStateReconnecting {
+		t.Errorf("ConnectionState = %q, want %q", connector.Status.ConnectionState, ssmdv1alpha1.ConnectionStateReconnecting)
+	}
+	if connector.Status.ReconnectCount != 3 {
+		t.Errorf("ReconnectCount = %d, want 3", connector.Status.ReconnectCount)
+	}
+	if connector.Status.LastMessageAt == nil {
+		t.Error("expected LastMessageAt to be set")
+	}
+}
+
+func TestUpdateMetricsStatus_ScrapeFailureKeepsPrevious(t *testing.T) {
+	r := newMetricsTestReconciler(t, "", errors.New("connection refused"),
+		newConnectorPod("connector-test-abc", corev1.PodRunning, 1))
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Status.MessagesPublished = 42
+
+	if err := r.updateMetricsStatus(context.Background(), connector); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if connector.Status.MessagesPublished != 42 {
+		t.Errorf("MessagesPublished = %d, want previous value 42", connector.Status.MessagesPublished)
+	}
+	if connector.Status.ReconnectCount != 1 {
+		t.Errorf("ReconnectCount = %d, want 1", connector.Status.ReconnectCount)
+	}
+}
+
+func TestUpdateMetricsStatus_SkipsPendingPods(t *testing.T) {
+	r := newMetricsTestReconciler(t, testConnectorMetrics, nil,
+		newConnectorPod("connector-test-abc", corev1.PodPending, 0))
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+
+	if err := r.updateMetricsStatus(context.Background(), connector); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if connector.Status.MessagesPublished != 0 {
+		t.Errorf("MessagesPublished = %d, want 0 (pending pod not scraped)", connector.Status.MessagesPublished)
+	}
+}
+
+// --- TestDegradedCondition ---
+
+func TestDegradedCondition_RecentMessage(t *testing.T) {
+	r := &ConnectorReconciler{}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Status.Phase = ssmdv1alpha1.ConnectorPhaseRunning
+	lastMessageAt := metav1.NewTime(now.Add(-time.Minute))
+	connector.Status.LastMessageAt = &lastMessageAt
+
+	condition := r.degradedCondition(connector, now)
+
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		t.Errorf("expected Degraded=False, got %+v", condition)
+	}
+}
+
+func TestDegradedCondition_Stale(t *testing.T) {
+	r := &ConnectorReconciler{}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Status.Phase = ssmdv1alpha1.ConnectorPhaseRunning
+	lastMessageAt := metav1.NewTime(now.Add(-10 * time.Minute))
+	connector.Status.LastMessageAt = &lastMessageAt
+
+	condition := r.degradedCondition(connector, now)
+
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != "NoRecentMessages" {
+		t.Errorf("expected Degraded=True/NoRecentMessages, got %+v", condition)
+	}
+}
+
+func TestDegradedCondition_CustomThreshold(t *testing.T) {
+	r := &ConnectorReconciler{}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Spec.DegradedAfterMinutes = int32Ptr(30)
+	connector.Status.Phase = ssmdv1alpha1.ConnectorPhaseRunning
+	lastMessageAt := metav1.NewTime(now.Add(-10 * time.Minute))
+	connector.Status.LastMessageAt = &lastMessageAt
+
+	condition := r.degradedCondition(connector, now)
+
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		t.Errorf("expected Degraded=False within 30m threshold, got %+v", condition)
+	}
+}
+
+func TestDegradedCondition_NoMessagesSinceStart(t *testing.T) {
+	r := &ConnectorReconciler{}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Status.Phase = ssmdv1alpha1.ConnectorPhaseRunning
+	startedAt := metav1.NewTime(now.Add(-6 * time.Minute))
+	connector.Status.StartedAt = &startedAt
+
+	condition := r.degradedCondition(connector, now)
+
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Errorf("expected Degraded=True when no message since start, got %+v", condition)
+	}
+}
+
+func TestDegradedCondition_NotRunning(t *testing.T) {
+	r := &ConnectorReconciler{}
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Status.Phase = ssmdv1alpha1.ConnectorPhaseStarting
+
+	if condition := r.degradedCondition(connector, time.Now()); condition != nil {
+		t.Errorf("expected no condition when not running, got %+v", condition)
+	}
+}
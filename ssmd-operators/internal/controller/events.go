@@ -0,0 +1,101 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+)
+
+// Event actions, in the verb form events.k8s.io expects
+const (
+	eventActionCreate       = "Create"
+	eventActionUpdate       = "Update"
+	eventActionDelete       = "Delete"
+	eventActionUpdateStatus = "UpdateStatus"
+)
+
+// recordEvent emits an Event regarding obj. Reconcilers built without a
+// Recorder (as in unit tests) skip it.
+func recordEvent(recorder events.EventRecorder, obj runtime.Object, eventtype, reason, action, note string, args ...any) {
+	if recorder == nil {
+		return
+	}
+	recorder.Eventf(obj, nil, eventtype, reason, action, note, args...)
+}
+
+// recordChildEvent records creating, updating or deleting one of owner's child
+// resources and returns err, so it can wrap the API call. A failed call is
+// recorded as a Warning; deleting an object that is already gone, or creating
+// one that already exists, records nothing.
+func recordChildEvent(recorder events.EventRecorder, owner runtime.Object, action, kind, name string, err error) error {
+	if (action == eventActionDelete && errors.IsNotFound(err)) || (action == eventActionCreate && errors.IsAlreadyExists(err)) {
+		return err
+	}
+	if err != nil {
+		recordEvent(recorder, owner, corev1.EventTypeWarning, action+"Failed", action,
+			"Failed to %s %s %s: %v", strings.ToLower(action), kind, name, err)
+		return err
+	}
+	recordEvent(recorder, owner, corev1.EventTypeNormal, action+"d", action, "%sd %s %s", action, kind, name)
+	return nil
+}
+
+// recordPhaseChange records a status phase transition. Moving to Failed is a Warning.
+func recordPhaseChange[P ~string](recorder events.EventRecorder, obj runtime.Object, previous, current P) {
+	if previous == current || current == "" {
+		return
+	}
+	eventtype := corev1.EventTypeNormal
+	if current == "Failed" {
+		eventtype = corev1.EventTypeWarning
+	}
+	from := string(previous)
+	if from == "" {
+		from = "<none>"
+	}
+	recordEvent(recorder, obj, eventtype, "Phase"+string(current), eventActionUpdateStatus,
+		"Phase changed from %s to %s", from, current)
+}
+
+// setConditionWithEvent sets condition and records its transitions: a Warning
+// when it turns False or fails for a new reason, and a Normal Event when it
+// recovers. Only for conditions where True is healthy.
+func setConditionWithEvent(recorder events.EventRecorder, obj runtime.Object, conditions *[]metav1.Condition, condition metav1.Condition) {
+	// Copy the previous condition; SetStatusCondition updates it in place
+	var previous *metav1.Condition
+	if existing := meta.FindStatusCondition(*conditions, condition.Type); existing != nil {
+		previous = existing.DeepCopy()
+	}
+	meta.SetStatusCondition(conditions, condition)
+
+	switch {
+	case condition.Status == metav1.ConditionFalse &&
+		(previous == nil || previous.Status != metav1.ConditionFalse || previous.Reason != condition.Reason):
+		recordEvent(recorder, obj, corev1.EventTypeWarning, condition.Reason, eventActionUpdateStatus,
+			"%s: %s", condition.Type, condition.Message)
+	case condition.Status == metav1.ConditionTrue && previous != nil && previous.Status == metav1.ConditionFalse:
+		recordEvent(recorder, obj, corev1.EventTypeNormal, condition.Reason, eventActionUpdateStatus,
+			"%s: %s", condition.Type, condition.Message)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// drainEvents returns the events recorded so far
+func drainEvents(recorder *events.FakeRecorder) []string {
+	var recorded []string
+	for {
+		select {
+		case e := <-recorder.Events:
+			recorded = append(recorded, e)
+		default:
+			return recorded
+		}
+	}
+}
+
+// --- TestRecordChildEvent ---
+
+func TestRecordChildEvent(t *testing.T) {
+	recorder := events.NewFakeRecorder(10)
+	snap := newTestSnap()
+	failure := fmt.Errorf("quota exceeded")
+
+	if err := recordChildEvent(recorder, snap, eventActionCreate, "Deployment", "snap-kalshi", nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := recordChildEvent(recorder, snap, eventActionUpdate, "Service", "snap-kalshi", failure); err != failure {
+		t.Errorf("expected the API error to be returned, got %v", err)
+	}
+
+	got := drainEvents(recorder)
+	want := []string{
+		"Normal Created Created Deployment snap-kalshi",
+		"Warning UpdateFailed Failed to update Service snap-kalshi: quota exceeded",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func TestRecordChildEvent_SkipsBenignErrors(t *testing.T) {
+	recorder := events.NewFakeRecorder(10)
+	snap := newTestSnap()
+	gr := schema.GroupResource{Resource: "deployments"}
+
+	_ = recordChildEvent(recorder, snap, eventActionDelete, "Deployment", "snap-kalshi", errors.NewNotFound(gr, "snap-kalshi"))
+	_ = recordChildEvent(recorder, snap, eventActionCreate, "Deployment", "snap-kalshi", errors.NewAlreadyExists(gr, "snap-kalshi"))
+
+	if got := drainEvents(recorder); len(got) != 0 {
+		t.Errorf("expected no events, got %q", got)
+	}
+}
+
+func TestRecordChildEvent_NilRecorder(t *testing.T) {
+	if err := recordChildEvent(nil, newTestSnap(), eventActionDelete, "Deployment", "snap-kalshi", nil); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// --- TestRecordPhaseChange ---
+
+func TestRecordPhaseChange(t *testing.T) {
+	recorder := events.NewFakeRecorder(10)
+	signal := &ssmdv1alpha1.Signal{}
+
+	recordPhaseChange(recorder, signal, "", ssmdv1alpha1.SignalPhasePending)
+	recordPhaseChange(recorder, signal, ssmdv1alpha1.SignalPhasePending, ssmdv1alpha1.SignalPhasePending)
+	recordPhaseChange(recorder, signal, ssmdv1alpha1.SignalPhaseRunning, ssmdv1alpha1.SignalPhaseFailed)
+
+	got := drainEvents(recorder)
+	want := []string{
+		"Normal PhasePending Phase changed from <none> to Pending",
+		"Warning PhaseFailed Phase changed from Running to Failed",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+// --- TestSetConditionWithEvent ---
+
+func TestSetConditionWithEvent(t *testing.T) {
+	recorder := events.NewFakeRecorder(10)
+	archiver := &ssmdv1alpha1.Archiver{}
+	healthy := metav1.Condition{Type: "StorageHealthy", Status: metav1.ConditionTrue, Reason: "StorageOK", Message: "Storage is configured"}
+	notBound := metav1.Condition{Type: "StorageHealthy", Status: metav1.ConditionFalse, Reason: "PVCNotBound", Message: "PVC is not bound"}
+
+	// Healthy on first sight is not news
+	setConditionWithEvent(recorder, archiver, &archiver.Status.Conditions, healthy)
+	// Turning unhealthy warns once, not on every reconcile
+	setConditionWithEvent(recorder, archiver, &archiver.Status.Conditions, notBound)
+	setConditionWithEvent(recorder, archiver, &archiver.Status.Conditions, notBound)
+	// Recovery is recorded
+	setConditionWithEvent(recorder, archiver, &archiver.Status.Conditions, healthy)
+
+	got := drainEvents(recorder)
+	want := []string{
+		"Warning PVCNotBound StorageHealthy: PVC is not bound",
+		"Normal StorageOK StorageHealthy: Storage is configured",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+// --- TestReconcileRecordsEvents ---
+
+func TestSnapReconcile_RecordsEvents(t *testing.T) {
+	snap := newTestSnap()
+	r := newSnapTestReconciler(snap)
+	recorder := events.NewFakeRecorder(20)
+	r.Recorder = recorder
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "snap-kalshi", Namespace: "ssmd"}}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := drainEvents(recorder)
+	for _, want := range []string{
+		"Normal Created Created Deployment snap-kalshi",
+		"Normal Created Created Service snap-kalshi",
+		"Normal PhasePending Phase changed from <none> to Pending",
+	} {
+		if !slices.Contains(got, want) {
+			t.Errorf("missing event %q in %q", want, got)
+		}
+	}
+}
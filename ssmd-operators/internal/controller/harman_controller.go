@@ -18,17 +18,21 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -43,14 +47,35 @@ const (
 type HarmanReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events for reconcile actions (skipped if nil)
+	Recorder events.EventRecorder
+
+	// MaxConcurrentReconciles is the number of parallel reconciles (1 if unset)
+	MaxConcurrentReconciles int
+
+	// DefaultPriorityClassName is used for pods whose CR does not set priorityClassName
+	DefaultPriorityClassName string
+
+	// DefaultResources are used for pods whose CR does not set resources
+	DefaultResources *corev1.ResourceRequirements
+
+	// pollStatus fetches harman's trading state; defaults to pollHarmanStatus
+	pollStatus harmanStatusPoller
+
+	// verifyEnvironment checks an environment switch target; defaults to verifyHarmanEnvironment
+	verifyEnvironment harmanEnvironmentVerifier
 }
 
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=harmans,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=harmans/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=harmans/finalizers,verbs=update
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 
 // Reconcile moves the cluster state toward the desired state for a Harman
 func (r *HarmanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -80,12 +105,31 @@ func (r *HarmanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		}
 	}
 
+	// Switch blue/green when spec.exchange.environment changes. The spec's
+	// Deployment is only reconciled once its environment is active.
+	switchRequeue, err := r.reconcileEnvironmentSwitch(ctx, harman)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if switchRequeue > 0 {
+		if err := r.updateStatus(ctx, harman); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: switchRequeue}, nil
+	}
+
 	// Reconcile the Deployment
 	result, err := r.reconcileDeployment(ctx, harman)
 	if err != nil {
 		return result, err
 	}
 
+	// Reconcile the PodDisruptionBudget
+	if err := reconcilePodDisruptionBudget(ctx, r.Client, r.Scheme, r.Recorder, harman, harman.Spec.HighAvailability,
+		r.deploymentName(harman), r.constructDeployment(harman).Spec.Selector); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Reconcile the Service
 	if result, err := r.reconcileService(ctx, harman); err != nil {
 		return result, err
@@ -96,7 +140,8 @@ func (r *HarmanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	// Requeue to refresh the trading state from harman's API
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 }
 
 // reconcileDelete handles cleanup when the Harman is deleted
@@ -110,7 +155,7 @@ func (r *HarmanReconciler) reconcileDelete(ctx context.Context, harman *ssmdv1al
 		svcName := r.serviceName(harman)
 		svc := &corev1.Service{}
 		if err := r.Get(ctx, types.NamespacedName{Name: svcName, Namespace: harman.Namespace}, svc); err == nil {
-			if err := r.Delete(ctx, svc); err != nil && !errors.IsNotFound(err) {
+			if err := recordChildEvent(r.Recorder, harman, eventActionDelete, "Service", svcName, r.Delete(ctx, svc)); err != nil && !errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
 			log.Info("Deleted Service", "name", svcName)
@@ -120,12 +165,23 @@ func (r *HarmanReconciler) reconcileDelete(ctx context.Context, harman *ssmdv1al
 		deploymentName := r.deploymentName(harman)
 		deployment := &appsv1.Deployment{}
 		if err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: harman.Namespace}, deployment); err == nil {
-			if err := r.Delete(ctx, deployment); err != nil && !errors.IsNotFound(err) {
+			if err := recordChildEvent(r.Recorder, harman, eventActionDelete, "Deployment", deploymentName, r.Delete(ctx, deployment)); err != nil && !errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
 			log.Info("Deleted Deployment", "name", deploymentName)
 		}
 
+		// Delete the standby Deployment of a switch in progress
+		if switchInProgress(harman) {
+			standbyName := harman.Status.EnvironmentSwitch.Deployment
+			standby := &appsv1.Deployment{}
+			if err := r.Get(ctx, types.NamespacedName{Name: standbyName, Namespace: harman.Namespace}, standby); err == nil {
+				if err := recordChildEvent(r.Recorder, harman, eventActionDelete, "Deployment", standbyName, r.Delete(ctx, standby)); err != nil && !errors.IsNotFound(err) {
+					return ctrl.Result{}, err
+				}
+			}
+		}
+
 		// Remove finalizer
 		controllerutil.RemoveFinalizer(harman, harmanFinalizer)
 		if err := r.Update(ctx, harman); err != nil {
@@ -151,7 +207,7 @@ func (r *HarmanReconciler) reconcileDeployment(ctx context.Context, harman *ssmd
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating Deployment", "name", deploymentName)
-		if err := r.Create(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, harman, eventActionCreate, "Deployment", deploymentName, r.Create(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -161,10 +217,10 @@ func (r *HarmanReconciler) reconcileDeployment(ctx context.Context, harman *ssmd
 
 	// Update existing Deployment if needed
 	desired := r.constructDeployment(harman)
-	if r.deploymentNeedsUpdate(deployment, desired) {
-		deployment.Spec = desired.Spec
+	if deploymentNeedsUpdate(deployment, desired) {
+		updateDeploymentSpec(deployment, desired)
 		log.Info("Updating Deployment", "name", deploymentName)
-		if err := r.Update(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, harman, eventActionUpdate, "Deployment", deploymentName, r.Update(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
@@ -182,7 +238,7 @@ func (r *HarmanReconciler) constructDeployment(harman *ssmdv1alpha1.Harman) *app
 		"ssmd.io/environment":          string(harman.Spec.Exchange.Environment),
 	}
 
-	replicas := int32(1)
+	replicas := desiredReplicas(harman)
 
 	// Defaults
 	listenAddr := harman.Spec.ListenAddr
@@ -291,12 +347,10 @@ func (r *HarmanReconciler) constructDeployment(harman *ssmdv1alpha1.Harman) *app
 		},
 	}
 
-	// Add resource requirements if specified
-	if harman.Spec.Resources != nil {
-		container.Resources = *harman.Spec.Resources
-	}
+	// Add resource requirements, falling back to the operator default
+	container.Resources = containerResources(harman.Spec.Resources, r.DefaultResources)
 
-	return &appsv1.Deployment{
+	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.deploymentName(harman),
 			Namespace: harman.Namespace,
@@ -318,12 +372,17 @@ func (r *HarmanReconciler) constructDeployment(harman *ssmdv1alpha1.Harman) *app
 					},
 				},
 				Spec: corev1.PodSpec{
-					Containers:       []corev1.Container{container},
-					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "ghcr-secret"}},
+					PriorityClassName: priorityClassName(harman.Spec.PriorityClassName, r.DefaultPriorityClassName),
+					Containers:        []corev1.Container{container},
 				},
 			},
 		},
 	}
+
+	applyPodTemplate(&deployment.Spec.Template, harman.Spec.PodTemplate)
+	applyTopologySpread(&deployment.Spec.Template, harman.Spec.HighAvailability, deployment.Spec.Selector)
+	setSpecHash(deployment)
+	return deployment
 }
 
 // exchangeEnvVars returns exchange-specific credential environment variables
@@ -406,75 +465,11 @@ func (r *HarmanReconciler) exchangeEnvVars(harman *ssmdv1alpha1.Harman) []corev1
 	}
 }
 
-// envVarsEqual compares two env var slices ignoring order.
-// Compares by name set and, for each name, value or secretKeyRef.
-func envVarsEqual(a, b []corev1.EnvVar) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	aMap := make(map[string]corev1.EnvVar, len(a))
-	for _, e := range a {
-		aMap[e.Name] = e
-	}
-	for _, be := range b {
-		ae, ok := aMap[be.Name]
-		if !ok {
-			return false
-		}
-		// Compare literal values
-		if ae.Value != be.Value {
-			return false
-		}
-		// Compare secret refs
-		if (ae.ValueFrom == nil) != (be.ValueFrom == nil) {
-			return false
-		}
-		if ae.ValueFrom != nil && be.ValueFrom != nil {
-			if (ae.ValueFrom.SecretKeyRef == nil) != (be.ValueFrom.SecretKeyRef == nil) {
-				return false
-			}
-			if ae.ValueFrom.SecretKeyRef != nil && be.ValueFrom.SecretKeyRef != nil {
-				if ae.ValueFrom.SecretKeyRef.LocalObjectReference.Name != be.ValueFrom.SecretKeyRef.LocalObjectReference.Name {
-					return false
-				}
-				if ae.ValueFrom.SecretKeyRef.Key != be.ValueFrom.SecretKeyRef.Key {
-					return false
-				}
-			}
-		}
-	}
-	return true
-}
-
-// deploymentNeedsUpdate checks if the Deployment needs to be updated
-func (r *HarmanReconciler) deploymentNeedsUpdate(current, desired *appsv1.Deployment) bool {
-	if len(current.Spec.Template.Spec.Containers) == 0 || len(desired.Spec.Template.Spec.Containers) == 0 {
-		return len(current.Spec.Template.Spec.Containers) != len(desired.Spec.Template.Spec.Containers)
-	}
-
-	currentContainer := &current.Spec.Template.Spec.Containers[0]
-	desiredContainer := &desired.Spec.Template.Spec.Containers[0]
-
-	// Check image
-	if currentContainer.Image != desiredContainer.Image {
-		return true
-	}
-
-	// Check environment variables (order-independent)
-	if !envVarsEqual(currentContainer.Env, desiredContainer.Env) {
-		return true
-	}
-
-	// Check resource requirements (Autopilot-safe: only check desired fields)
-	if !resourcesMatch(currentContainer.Resources, desiredContainer.Resources) {
-		return true
-	}
-
-	return false
-}
-
 // updateStatus updates the Harman status based on Deployment state
 func (r *HarmanReconciler) updateStatus(ctx context.Context, harman *ssmdv1alpha1.Harman) error {
+	previousPhase := harman.Status.Phase
+	defer func() { recordPhaseChange(r.Recorder, harman, previousPhase, harman.Status.Phase) }()
+
 	deploymentName := r.deploymentName(harman)
 	deployment := &appsv1.Deployment{}
 	err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: harman.Namespace}, deployment)
@@ -490,7 +485,9 @@ func (r *HarmanReconciler) updateStatus(ctx context.Context, harman *ssmdv1alpha
 		harman.Status.Service = r.serviceName(harman)
 
 		// Determine phase from Deployment status
-		if deployment.Status.ReadyReplicas > 0 {
+		if !tradingEnabled(harman) {
+			harman.Status.Phase = ssmdv1alpha1.HarmanPhaseHalted
+		} else if deployment.Status.ReadyReplicas > 0 {
 			harman.Status.Phase = ssmdv1alpha1.HarmanPhaseRunning
 		} else {
 			harman.Status.Phase = ssmdv1alpha1.HarmanPhasePending
@@ -509,14 +506,64 @@ func (r *HarmanReconciler) updateStatus(ctx context.Context, harman *ssmdv1alpha
 			condition.Reason = "DeploymentReady"
 			condition.Message = "Harman order gateway is running"
 		}
+		if !tradingEnabled(harman) {
+			condition.Reason = "TradingHalted"
+			condition.Message = "Trading is disabled by spec.trading.enabled"
+		}
 		meta.SetStatusCondition(&harman.Status.Conditions, condition)
+		meta.SetStatusCondition(&harman.Status.Conditions, tradingHaltedCondition(harman))
+
+		// Orders and positions from the running harman
+		if harman.Status.Phase == ssmdv1alpha1.HarmanPhaseRunning {
+			r.updateTradingStatus(ctx, harman)
+		}
 	}
 
 	return r.Status().Update(ctx, harman)
 }
 
-// deploymentName returns the Deployment name for a Harman
+// tradingEnabled reports whether the kill switch allows harman to run
+func tradingEnabled(harman *ssmdv1alpha1.Harman) bool {
+	trading := harman.Spec.Trading
+	return trading == nil || trading.Enabled == nil || *trading.Enabled
+}
+
+// desiredReplicas is harman's replica count. The kill switch scales harman to
+// zero rather than relying on it to refuse orders.
+func desiredReplicas(harman *ssmdv1alpha1.Harman) int32 {
+	if !tradingEnabled(harman) {
+		return 0
+	}
+	return 1
+}
+
+// tradingHaltedCondition reports the state of the kill switch
+func tradingHaltedCondition(harman *ssmdv1alpha1.Harman) metav1.Condition {
+	if !tradingEnabled(harman) {
+		return metav1.Condition{
+			Type:               "TradingHalted",
+			Status:             metav1.ConditionTrue,
+			Reason:             "KillSwitch",
+			Message:            "spec.trading.enabled is false; harman is scaled to zero",
+			LastTransitionTime: metav1.Now(),
+		}
+	}
+	return metav1.Condition{
+		Type:               "TradingHalted",
+		Status:             metav1.ConditionFalse,
+		Reason:             "TradingEnabled",
+		Message:            "Trading is enabled",
+		LastTransitionTime: metav1.Now(),
+	}
+}
+
+// deploymentName returns the name of the active environment's Deployment. It
+// is the Harman's name until an environment switch moves it to the new
+// environment's Deployment.
 func (r *HarmanReconciler) deploymentName(harman *ssmdv1alpha1.Harman) string {
+	if harman.Status.Deployment != "" {
+		return harman.Status.Deployment
+	}
 	return harman.Name
 }
 
@@ -540,7 +587,7 @@ func (r *HarmanReconciler) reconcileService(ctx context.Context, harman *ssmdv1a
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating Service", "name", svcName)
-		if err := r.Create(ctx, svc); err != nil {
+		if err := recordChildEvent(r.Recorder, harman, eventActionCreate, "Service", svcName, r.Create(ctx, svc)); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -554,7 +601,7 @@ func (r *HarmanReconciler) reconcileService(ctx context.Context, harman *ssmdv1a
 		svc.Spec.Selector = desired.Spec.Selector
 		svc.Spec.Ports = desired.Spec.Ports
 		log.Info("Updating Service", "name", svcName)
-		if err := r.Update(ctx, svc); err != nil {
+		if err := recordChildEvent(r.Recorder, harman, eventActionUpdate, "Service", svcName, r.Update(ctx, svc)); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
@@ -581,6 +628,7 @@ func (r *HarmanReconciler) constructService(harman *ssmdv1alpha1.Harman) *corev1
 			Selector: map[string]string{
 				"app.kubernetes.io/name":     "ssmd-harman",
 				"app.kubernetes.io/instance": harman.Name,
+				"ssmd.io/environment":        string(serviceEnvironment(harman)),
 			},
 			Ports: []corev1.ServicePort{
 				{
@@ -623,7 +671,9 @@ func (r *HarmanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&ssmdv1alpha1.Harman{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
 		Owns(&corev1.Service{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Named("harman").
 		Complete(r)
 }
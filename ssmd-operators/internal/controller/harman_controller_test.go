@@ -22,6 +22,7 @@ import (
 	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -209,6 +210,28 @@ func TestConstructDeployment_Labels(t *testing.T) {
 	}
 }
 
+func TestConstructDeployment_PodTemplate(t *testing.T) {
+	r := newTestReconciler()
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, &corev1.LocalObjectReference{Name: "kalshi-secret"})
+	harman.Spec.PodTemplate = &ssmdv1alpha1.PodTemplateConfig{
+		NodeSelector: map[string]string{"pool": "trading"},
+		Annotations:  map[string]string{"prometheus.io/scrape": "false"},
+	}
+
+	dep := r.constructDeployment(harman)
+
+	podSpec := dep.Spec.Template.Spec
+	if podSpec.NodeSelector["pool"] != "trading" {
+		t.Errorf("expected node selector pool=trading, got %v", podSpec.NodeSelector)
+	}
+	if len(podSpec.ImagePullSecrets) != 1 || podSpec.ImagePullSecrets[0].Name != "ghcr-secret" {
+		t.Errorf("expected default ghcr-secret, got %v", podSpec.ImagePullSecrets)
+	}
+	if dep.Spec.Template.Annotations["prometheus.io/scrape"] != "true" {
+		t.Error("podTemplate annotations should not override prometheus scrape config")
+	}
+}
+
 func TestConstructDeployment_EnvVars(t *testing.T) {
 	r := newTestReconciler()
 	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, &corev1.LocalObjectReference{Name: "kalshi-secret"})
@@ -339,6 +362,103 @@ func TestConstructDeployment_SecurityContext(t *testing.T) {
 	}
 }
 
+func TestConstructDeployment_PriorityClassDefault(t *testing.T) {
+	r := newTestReconciler()
+	r.DefaultPriorityClassName = "ssmd-critical"
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, &corev1.LocalObjectReference{Name: "kalshi-secret"})
+
+	dep := r.constructDeployment(harman)
+
+	if got := dep.Spec.Template.Spec.PriorityClassName; got != "ssmd-critical" {
+		t.Errorf("priorityClassName = %q, want %q", got, "ssmd-critical")
+	}
+}
+
+func TestConstructDeployment_PriorityClassOverride(t *testing.T) {
+	r := newTestReconciler()
+	r.DefaultPriorityClassName = "ssmd-critical"
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, &corev1.LocalObjectReference{Name: "kalshi-secret"})
+	harman.Spec.PriorityClassName = "ssmd-standard"
+
+	dep := r.constructDeployment(harman)
+
+	if got := dep.Spec.Template.Spec.PriorityClassName; got != "ssmd-standard" {
+		t.Errorf("priorityClassName = %q, want %q", got, "ssmd-standard")
+	}
+}
+
+func TestConstructDeployment_ResourcesDefault(t *testing.T) {
+	r := newTestReconciler()
+	r.DefaultResources = &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+	}
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, &corev1.LocalObjectReference{Name: "kalshi-secret"})
+
+	dep := r.constructDeployment(harman)
+	if got := dep.Spec.Template.Spec.Containers[0].Resources.Requests.Memory(); !got.Equal(resource.MustParse("512Mi")) {
+		t.Errorf("memory request = %v, want 512Mi", got)
+	}
+
+	harman.Spec.Resources = &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	dep = r.constructDeployment(harman)
+	if got := dep.Spec.Template.Spec.Containers[0].Resources.Requests.Memory(); !got.Equal(resource.MustParse("1Gi")) {
+		t.Errorf("memory request = %v, want 1Gi from spec", got)
+	}
+}
+
+func TestDeploymentNeedsUpdate_PriorityClassChanged(t *testing.T) {
+	r := newTestReconciler()
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, &corev1.LocalObjectReference{Name: "kalshi-secret"})
+
+	current := r.constructDeployment(harman)
+	harman.Spec.PriorityClassName = "ssmd-critical"
+	desired := r.constructDeployment(harman)
+
+	if !deploymentNeedsUpdate(current, desired) {
+		t.Error("expected update when priorityClassName changes")
+	}
+}
+
+func TestConstructDeployment_TradingDisabled(t *testing.T) {
+	r := newTestReconciler()
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, &corev1.LocalObjectReference{Name: "kalshi-secret"})
+
+	current := r.constructDeployment(harman)
+	if *current.Spec.Replicas != 1 {
+		t.Errorf("replicas = %d, want 1 while trading is enabled", *current.Spec.Replicas)
+	}
+
+	disabled := false
+	harman.Spec.Trading = &ssmdv1alpha1.TradingConfig{Enabled: &disabled}
+	desired := r.constructDeployment(harman)
+
+	if *desired.Spec.Replicas != 0 {
+		t.Errorf("replicas = %d, want 0 when trading is disabled", *desired.Spec.Replicas)
+	}
+	if !deploymentNeedsUpdate(current, desired) {
+		t.Error("expected update when the kill switch is flipped")
+	}
+}
+
+// --- TestTradingHaltedCondition ---
+
+func TestTradingHaltedCondition(t *testing.T) {
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, &corev1.LocalObjectReference{Name: "kalshi-secret"})
+
+	if cond := tradingHaltedCondition(harman); cond.Status != metav1.ConditionFalse {
+		t.Errorf("TradingHalted = %s, want False when spec.trading is unset", cond.Status)
+	}
+
+	disabled := false
+	harman.Spec.Trading = &ssmdv1alpha1.TradingConfig{Enabled: &disabled}
+	cond := tradingHaltedCondition(harman)
+	if cond.Type != "TradingHalted" || cond.Status != metav1.ConditionTrue || cond.Reason != "KillSwitch" {
+		t.Errorf("unexpected condition %+v", cond)
+	}
+}
+
 // --- TestConstructService ---
 
 func TestConstructService_Basic(t *testing.T) {
@@ -387,117 +507,6 @@ func TestConstructService_Selector(t *testing.T) {
 	}
 }
 
-// --- TestEnvVarsEqual ---
-
-func TestEnvVarsEqual_SameOrder(t *testing.T) {
-	a := []corev1.EnvVar{
-		{Name: "A", Value: "1"},
-		{Name: "B", Value: "2"},
-	}
-	b := []corev1.EnvVar{
-		{Name: "A", Value: "1"},
-		{Name: "B", Value: "2"},
-	}
-	if !envVarsEqual(a, b) {
-		t.Error("expected equal for same-order env vars")
-	}
-}
-
-func TestEnvVarsEqual_DifferentOrder(t *testing.T) {
-	a := []corev1.EnvVar{
-		{Name: "B", Value: "2"},
-		{Name: "A", Value: "1"},
-	}
-	b := []corev1.EnvVar{
-		{Name: "A", Value: "1"},
-		{Name: "B", Value: "2"},
-	}
-	if !envVarsEqual(a, b) {
-		t.Error("expected equal for different-order env vars")
-	}
-}
-
-func TestEnvVarsEqual_DifferentValues(t *testing.T) {
-	a := []corev1.EnvVar{
-		{Name: "A", Value: "1"},
-	}
-	b := []corev1.EnvVar{
-		{Name: "A", Value: "2"},
-	}
-	if envVarsEqual(a, b) {
-		t.Error("expected not equal for different values")
-	}
-}
-
-func TestEnvVarsEqual_DifferentLengths(t *testing.T) {
-	a := []corev1.EnvVar{
-		{Name: "A", Value: "1"},
-	}
-	b := []corev1.EnvVar{
-		{Name: "A", Value: "1"},
-		{Name: "B", Value: "2"},
-	}
-	if envVarsEqual(a, b) {
-		t.Error("expected not equal for different lengths")
-	}
-}
-
-func TestEnvVarsEqual_SecretRefs(t *testing.T) {
-	a := []corev1.EnvVar{
-		{
-			Name: "SECRET",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{Name: "my-secret"},
-					Key:                  "key1",
-				},
-			},
-		},
-	}
-	b := []corev1.EnvVar{
-		{
-			Name: "SECRET",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{Name: "my-secret"},
-					Key:                  "key1",
-				},
-			},
-		},
-	}
-	if !envVarsEqual(a, b) {
-		t.Error("expected equal for matching secret refs")
-	}
-}
-
-func TestEnvVarsEqual_DifferentSecretRefs(t *testing.T) {
-	a := []corev1.EnvVar{
-		{
-			Name: "SECRET",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{Name: "secret-a"},
-					Key:                  "key1",
-				},
-			},
-		},
-	}
-	b := []corev1.EnvVar{
-		{
-			Name: "SECRET",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{Name: "secret-b"},
-					Key:                  "key1",
-				},
-			},
-		},
-	}
-	if envVarsEqual(a, b) {
-		t.Error("expected not equal for different secret refs")
-	}
-}
-
 // --- TestServiceNeedsUpdate ---
 
 func TestServiceNeedsUpdate_NoChange(t *testing.T) {
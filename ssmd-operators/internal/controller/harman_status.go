@@ -0,0 +1,191 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// harmanTradingState is the order and position summary polled from harman's HTTP API
+type harmanTradingState struct {
+	OpenOrders    int32
+	OpenPositions int32
+	TotalNotional string
+	LastOrderAt   *time.Time
+}
+
+// harmanStatusPoller fetches the trading state from the harman API at baseURL
+type harmanStatusPoller func(ctx context.Context, baseURL, adminToken string) (*harmanTradingState, error)
+
+var harmanHTTPClient = &http.Client{Timeout: metricsScrapeTimeout}
+
+// pollHarmanStatus is the default harmanStatusPoller. It reads open orders and
+// today's orders from /v1/orders, positions from /v1/admin/positions and open
+// notional from /v1/admin/risk, all for the admin token's session.
+func pollHarmanStatus(ctx context.Context, baseURL, adminToken string) (*harmanTradingState, error) {
+	var state harmanTradingState
+
+	var open struct {
+		Orders []json.RawMessage `json:"orders"`
+	}
+	if err := harmanGet(ctx, baseURL+"/v1/orders?state=open", adminToken, &open); err != nil {
+		return nil, err
+	}
+	state.OpenOrders = int32(len(open.Orders))
+
+	var today struct {
+		Orders []struct {
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"orders"`
+	}
+	if err := harmanGet(ctx, baseURL+"/v1/orders?state=today", adminToken, &today); err != nil {
+		return nil, err
+	}
+	for _, o := range today.Orders {
+		if state.LastOrderAt == nil || o.CreatedAt.After(*state.LastOrderAt) {
+			createdAt := o.CreatedAt
+			state.LastOrderAt = &createdAt
+		}
+	}
+
+	var positions struct {
+		Positions []struct {
+			// rust_decimal serializes as a JSON string
+			NetQuantity json.RawMessage `json:"net_quantity"`
+		} `json:"positions"`
+	}
+	if err := harmanGet(ctx, baseURL+"/v1/admin/positions", adminToken, &positions); err != nil {
+		return nil, err
+	}
+	for _, p := range positions.Positions {
+		quantity, err := strconv.ParseFloat(strings.Trim(string(p.NetQuantity), `"`), 64)
+		if err == nil && quantity != 0 {
+			state.OpenPositions++
+		}
+	}
+
+	var risk struct {
+		OpenNotional string `json:"open_notional"`
+	}
+	if err := harmanGet(ctx, baseURL+"/v1/admin/risk", adminToken, &risk); err != nil {
+		return nil, err
+	}
+	state.TotalNotional = risk.OpenNotional
+
+	return &state, nil
+}
+
+// verifyHarmanEnvironment is the default harmanEnvironmentVerifier. /health
+// fails unless harman reaches its database, and harman only starts once the
+// exchange accepted its credentials; /v1/me checks the admin token and reports
+// the environment harman runs against.
+func verifyHarmanEnvironment(ctx context.Context, baseURL, adminToken string) (string, error) {
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := harmanGet(ctx, baseURL+"/health", adminToken, &health); err != nil {
+		return "", err
+	}
+
+	var me struct {
+		Environment string `json:"environment"`
+	}
+	if err := harmanGet(ctx, baseURL+"/v1/me", adminToken, &me); err != nil {
+		return "", err
+	}
+	return me.Environment, nil
+}
+
+// harmanGet decodes the JSON response of an authenticated GET into out
+func harmanGet(ctx context.Context, url, token string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := harmanHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
+
+// updateTradingStatus polls the running harman through its Service and copies
+// its trading state into status. Status keeps its previous values if harman
+// cannot be reached, so a restart does not blank the printer columns.
+func (r *HarmanReconciler) updateTradingStatus(ctx context.Context, harman *ssmdv1alpha1.Harman) {
+	log := logf.FromContext(ctx)
+
+	adminToken, err := r.adminToken(ctx, harman)
+	if err != nil {
+		log.V(1).Info("Failed to read harman admin token", "error", err.Error())
+		return
+	}
+
+	poll := r.pollStatus
+	if poll == nil {
+		poll = pollHarmanStatus
+	}
+	baseURL := fmt.Sprintf("http://%s.%s.svc:8080", r.serviceName(harman), harman.Namespace)
+	state, err := poll(ctx, baseURL, adminToken)
+	if err != nil {
+		log.V(1).Info("Failed to poll harman status", "error", err.Error())
+		return
+	}
+
+	harman.Status.OpenOrders = state.OpenOrders
+	harman.Status.OpenPositions = state.OpenPositions
+	harman.Status.TotalNotional = state.TotalNotional
+	if state.LastOrderAt != nil {
+		lastOrderAt := metav1.NewTime(*state.LastOrderAt)
+		harman.Status.LastOrderAt = &lastOrderAt
+	}
+}
+
+// adminToken reads the admin-token from the Harman's auth Secret
+func (r *HarmanReconciler) adminToken(ctx context.Context, harman *ssmdv1alpha1.Harman) (string, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: harman.Spec.Auth.SecretRef.Name, Namespace: harman.Namespace}, secret); err != nil {
+		return "", err
+	}
+	adminToken := string(secret.Data["admin-token"])
+	if adminToken == "" {
+		return "", fmt.Errorf("secret %s has no admin-token", secret.Name)
+	}
+	return adminToken, nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// newHarmanAPI serves canned responses for the endpoints pollHarmanStatus reads
+func newHarmanAPI(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	responses := map[string]string{
+		"/v1/orders?state=open": `{"orders":[{"id":1},{"id":2},{"id":3}]}`,
+		"/v1/orders?state=today": `{"orders":[
+			{"id":1,"created_at":"2026-01-05T14:00:00+00:00"},
+			{"id":4,"created_at":"2026-01-05T15:30:00+00:00"}]}`,
+		"/v1/admin/positions": `{"positions":[
+			{"ticker":"KXBTC-26JAN05","net_quantity":"5"},
+			{"ticker":"KXETH-26JAN05","net_quantity":"0"},
+			{"ticker":"KXSOL-26JAN05","net_quantity":"-2"}]}`,
+		"/v1/admin/risk": `{"max_notional":"100","open_notional":"42.50","available_notional":"57.50"}`,
+		"/health":        `{"status":"healthy","suspended":false}`,
+		"/v1/me":         `{"session_id":1,"exchange":"kalshi","environment":"demo"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := responses[req.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// --- TestPollHarmanStatus ---
+
+func TestPollHarmanStatus(t *testing.T) {
+	srv := newHarmanAPI(t, "admin-secret")
+
+	state, err := pollHarmanStatus(context.Background(), srv.URL, "admin-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.OpenOrders != 3 || state.OpenPositions != 2 || state.TotalNotional != "42.50" {
+		t.Errorf("unexpected state %+v", state)
+	}
+	if state.LastOrderAt == nil || !state.LastOrderAt.Equal(time.Date(2026, 1, 5, 15, 30, 0, 0, time.UTC)) {
+		t.Errorf("expected last order at 15:30, got %v", state.LastOrderAt)
+	}
+}
+
+func TestPollHarmanStatus_Unauthorized(t *testing.T) {
+	srv := newHarmanAPI(t, "admin-secret")
+
+	if _, err := pollHarmanStatus(context.Background(), srv.URL, "wrong"); err == nil {
+		t.Error("expected error for rejected token")
+	}
+}
+
+// --- TestVerifyHarmanEnvironment ---
+
+func TestVerifyHarmanEnvironment(t *testing.T) {
+	srv := newHarmanAPI(t, "admin-secret")
+
+	env, err := verifyHarmanEnvironment(context.Background(), srv.URL, "admin-secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env != "demo" {
+		t.Errorf("environment = %q, want demo", env)
+	}
+	if _, err := verifyHarmanEnvironment(context.Background(), srv.URL, "wrong"); err == nil {
+		t.Error("expected error for rejected token")
+	}
+}
+
+// --- TestUpdateTradingStatus ---
+
+func newTradingTestReconciler(poll harmanStatusPoller) *HarmanReconciler {
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	return &HarmanReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "harman-auth", Namespace: "ssmd"},
+			Data:       map[string][]byte{"admin-token": []byte("admin-secret")},
+		}).Build(),
+		Scheme:     scheme,
+		pollStatus: poll,
+	}
+}
+
+func TestUpdateTradingStatus(t *testing.T) {
+	lastOrderAt := time.Date(2026, 1, 5, 15, 30, 0, 0, time.UTC)
+	var gotURL, gotToken string
+	r := newTradingTestReconciler(func(ctx context.Context, baseURL, adminToken string) (*harmanTradingState, error) {
+		gotURL, gotToken = baseURL, adminToken
+		return &harmanTradingState{OpenOrders: 3, OpenPositions: 2, TotalNotional: "42.50", LastOrderAt: &lastOrderAt}, nil
+	})
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, nil)
+	harman.Spec.Auth.SecretRef.Name = "harman-auth"
+
+	r.updateTradingStatus(context.Background(), harman)
+
+	if gotURL != "http://harman-test.ssmd.svc:8080" || gotToken != "admin-secret" {
+		t.Errorf("unexpected poll of %q with token %q", gotURL, gotToken)
+	}
+	status := harman.Status
+	if status.OpenOrders != 3 || status.OpenPositions != 2 || status.TotalNotional != "42.50" {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if status.LastOrderAt == nil || !status.LastOrderAt.Time.Equal(lastOrderAt) {
+		t.Errorf("unexpected lastOrderAt %v", status.LastOrderAt)
+	}
+}
+
+func TestUpdateTradingStatus_PollFailureKeepsPrevious(t *testing.T) {
+	r := newTradingTestReconciler(func(ctx context.Context, baseURL, adminToken string) (*harmanTradingState, error) {
+		return nil, errors.New("connection refused")
+	})
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, nil)
+	harman.Spec.Auth.SecretRef.Name = "harman-auth"
+	harman.Status.OpenOrders = 7
+
+	r.updateTradingStatus(context.Background(), harman)
+
+	if harman.Status.OpenOrders != 7 {
+		t.Errorf("expected previous open orders to be kept, got %d", harman.Status.OpenOrders)
+	}
+}
+
+func TestUpdateTradingStatus_MissingSecret(t *testing.T) {
+	r := newTradingTestReconciler(func(ctx context.Context, baseURL, adminToken string) (*harmanTradingState, error) {
+		t.Fatal("unexpected poll without an admin token")
+		return nil, nil
+	})
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, nil)
+	harman.Spec.Auth.SecretRef.Name = "missing"
+
+	r.updateTradingStatus(context.Background(), harman)
+}
@@ -0,0 +1,430 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+const (
+	// harmanSwitchTimeout bounds each phase of an environment switch before it rolls back
+	harmanSwitchTimeout = 10 * time.Minute
+
+	// harmanSwitchPollInterval is how often a switch in progress is checked
+	harmanSwitchPollInterval = 10 * time.Second
+)
+
+// harmanEnvironmentVerifier checks that the harman at baseURL is healthy and
+// accepts the admin token, and returns the exchange environment it reports
+type harmanEnvironmentVerifier func(ctx context.Context, baseURL, adminToken string) (string, error)
+
+// activeEnvironment is the environment the Service routes to. Harmans created
+// before environment switches record none, so they are on the spec's.
+func activeEnvironment(harman *ssmdv1alpha1.Harman) ssmdv1alpha1.ExchangeEnvironment {
+	if harman.Status.ActiveEnvironment != "" {
+		return harman.Status.ActiveEnvironment
+	}
+	return harman.Spec.Exchange.Environment
+}
+
+// serviceEnvironment is the environment the Service selects: the switch target
+// once it has taken over, otherwise the active environment
+func serviceEnvironment(harman *ssmdv1alpha1.Harman) ssmdv1alpha1.ExchangeEnvironment {
+	if sw := harman.Status.EnvironmentSwitch; sw != nil && sw.Phase == ssmdv1alpha1.EnvironmentSwitchSwitching {
+		return sw.To
+	}
+	return activeEnvironment(harman)
+}
+
+// switchInProgress reports whether an environment switch has started and not finished
+func switchInProgress(harman *ssmdv1alpha1.Harman) bool {
+	sw := harman.Status.EnvironmentSwitch
+	return sw != nil && sw.Phase != ssmdv1alpha1.EnvironmentSwitchComplete && sw.Phase != ssmdv1alpha1.EnvironmentSwitchRolledBack
+}
+
+// standbyDeploymentName is the Deployment name for a Harman's switch target
+func (r *HarmanReconciler) standbyDeploymentName(harman *ssmdv1alpha1.Harman, env ssmdv1alpha1.ExchangeEnvironment) string {
+	return harman.Name + "-" + string(env)
+}
+
+// constructStandbyDeployment builds the switch target's Deployment: the spec's
+// Deployment with one replica and trading disabled. The Service does not
+// select it, so no orders reach it, and its auto-pump, auto-reconcile and
+// NATS price monitor are off so it places none on its own.
+func (r *HarmanReconciler) constructStandbyDeployment(harman *ssmdv1alpha1.Harman, name string) *appsv1.Deployment {
+	deployment := r.constructDeployment(harman)
+	deployment.Name = name
+	replicas := int32(1)
+	deployment.Spec.Replicas = &replicas
+
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	env := make([]corev1.EnvVar, 0, len(container.Env)+2)
+	for _, e := range container.Env {
+		switch e.Name {
+		case "AUTO_PUMP", "RECONCILE_INTERVAL_SECS", "NATS_URL":
+			continue
+		}
+		env = append(env, e)
+	}
+	container.Env = append(env,
+		corev1.EnvVar{Name: "AUTO_PUMP", Value: "false"},
+		corev1.EnvVar{Name: "RECONCILE_INTERVAL_SECS", Value: "0"},
+	)
+
+	setSpecHash(deployment)
+	return deployment
+}
+
+// reconcileEnvironmentSwitch moves a Harman to spec.exchange.environment
+// blue/green. The new environment's Deployment starts with trading disabled
+// (Provisioning), its pod is checked for health, auth and environment
+// (Verifying), then the Service moves to it, the old Deployment is scaled to
+// zero and the new one is restarted with trading enabled (Switching). Once it
+// is ready the old Deployment is deleted (Complete). A phase that times out, or
+// a spec that moves back to the active environment, rolls back to the old
+// Deployment (RolledBack); a rolled back switch is retried on the next spec
+// change. Returns when to check again, or 0 when no switch is pending and the
+// spec's Deployment can be reconciled.
+func (r *HarmanReconciler) reconcileEnvironmentSwitch(ctx context.Context, harman *ssmdv1alpha1.Harman) (time.Duration, error) {
+	target := harman.Spec.Exchange.Environment
+	sw := harman.Status.EnvironmentSwitch
+
+	if activeEnvironment(harman) == target {
+		harman.Status.ActiveEnvironment = target
+		if switchInProgress(harman) {
+			return 0, r.rollbackEnvironmentSwitch(ctx, harman,
+				fmt.Sprintf("spec.exchange.environment returned to %s", target))
+		}
+		return 0, nil
+	}
+
+	if switchInProgress(harman) && sw.To != target {
+		if err := r.rollbackEnvironmentSwitch(ctx, harman, fmt.Sprintf("Superseded by a switch to %s", target)); err != nil {
+			return 0, err
+		}
+		sw = harman.Status.EnvironmentSwitch
+	}
+
+	// Keep the kill switch in force on the active Deployment while the spec
+	// describes the environment being switched to
+	if !switchInProgress(harman) || sw.Phase != ssmdv1alpha1.EnvironmentSwitchSwitching {
+		if err := r.scaleDeployment(ctx, harman, r.deploymentName(harman), desiredReplicas(harman)); err != nil {
+			return 0, err
+		}
+	}
+
+	if !switchInProgress(harman) {
+		if sw != nil && sw.To == target && sw.ObservedGeneration == harman.Generation {
+			// Rolled back; wait for the spec to change before retrying
+			return 30 * time.Second, nil
+		}
+		active := &appsv1.Deployment{}
+		err := r.Get(ctx, types.NamespacedName{Name: r.deploymentName(harman), Namespace: harman.Namespace}, active)
+		if errors.IsNotFound(err) {
+			// Nothing is trading, so there is nothing to switch away from
+			harman.Status.ActiveEnvironment = target
+			return 0, nil
+		} else if err != nil {
+			return 0, err
+		}
+		return harmanSwitchPollInterval, r.startEnvironmentSwitch(ctx, harman)
+	}
+
+	switch sw.Phase {
+	case ssmdv1alpha1.EnvironmentSwitchProvisioning:
+		return r.provisionStandby(ctx, harman)
+	case ssmdv1alpha1.EnvironmentSwitchVerifying:
+		return r.verifyStandby(ctx, harman)
+	case ssmdv1alpha1.EnvironmentSwitchSwitching:
+		return r.finishEnvironmentSwitch(ctx, harman)
+	}
+	return 0, nil
+}
+
+// startEnvironmentSwitch records a new switch and pins the Service to the
+// active environment before the standby's pods exist
+func (r *HarmanReconciler) startEnvironmentSwitch(ctx context.Context, harman *ssmdv1alpha1.Harman) error {
+	from, to := activeEnvironment(harman), harman.Spec.Exchange.Environment
+	now := metav1.Now()
+	harman.Status.ActiveEnvironment = from
+	harman.Status.EnvironmentSwitch = &ssmdv1alpha1.EnvironmentSwitchStatus{
+		From:               from,
+		To:                 to,
+		Phase:              ssmdv1alpha1.EnvironmentSwitchProvisioning,
+		Message:            fmt.Sprintf("Starting %s with trading disabled", to),
+		Deployment:         r.standbyDeploymentName(harman, to),
+		ObservedGeneration: harman.Generation,
+		StartedAt:          &now,
+		LastTransitionTime: &now,
+	}
+	logf.FromContext(ctx).Info("Starting environment switch", "from", from, "to", to)
+	recordEvent(r.Recorder, harman, corev1.EventTypeNormal, "EnvironmentSwitchStarted", eventActionUpdate,
+		"Switching from %s to %s", from, to)
+
+	if _, err := r.reconcileService(ctx, harman); err != nil {
+		return err
+	}
+	_, err := r.applyStandbyDeployment(ctx, harman)
+	return err
+}
+
+// provisionStandby waits for the standby Deployment to become ready
+func (r *HarmanReconciler) provisionStandby(ctx context.Context, harman *ssmdv1alpha1.Harman) (time.Duration, error) {
+	sw := harman.Status.EnvironmentSwitch
+	standby, err := r.applyStandbyDeployment(ctx, harman)
+	if err != nil {
+		return 0, err
+	}
+	if standby.Status.ReadyReplicas > 0 {
+		setSwitchPhase(sw, ssmdv1alpha1.EnvironmentSwitchVerifying, fmt.Sprintf("Checking %s health and auth", sw.To))
+		return r.verifyStandby(ctx, harman)
+	}
+	if switchPhaseExpired(sw) {
+		return 0, r.rollbackEnvironmentSwitch(ctx, harman,
+			fmt.Sprintf("%s not ready after %s", standby.Name, harmanSwitchTimeout))
+	}
+	return harmanSwitchPollInterval, nil
+}
+
+// verifyStandby checks the standby pod's health, auth and environment through
+// its pod IP, then moves the Service to it
+func (r *HarmanReconciler) verifyStandby(ctx context.Context, harman *ssmdv1alpha1.Harman) (time.Duration, error) {
+	sw := harman.Status.EnvironmentSwitch
+	if err := r.checkStandby(ctx, harman); err != nil {
+		if switchPhaseExpired(sw) {
+			return 0, r.rollbackEnvironmentSwitch(ctx, harman,
+				fmt.Sprintf("%s failed verification: %v", sw.To, err))
+		}
+		sw.Message = fmt.Sprintf("Verifying %s: %v", sw.To, err)
+		return harmanSwitchPollInterval, nil
+	}
+
+	// Flip the Service, stop the old environment and enable trading on the new one
+	setSwitchPhase(sw, ssmdv1alpha1.EnvironmentSwitchSwitching,
+		fmt.Sprintf("Service moved to %s; enabling trading", sw.To))
+	logf.FromContext(ctx).Info("Environment verified, switching Service", "to", sw.To)
+	if _, err := r.reconcileService(ctx, harman); err != nil {
+		return 0, err
+	}
+	if err := r.scaleDeployment(ctx, harman, r.deploymentName(harman), 0); err != nil {
+		return 0, err
+	}
+	if err := r.promoteStandby(ctx, harman); err != nil {
+		return 0, err
+	}
+	return harmanSwitchPollInterval, nil
+}
+
+// checkStandby verifies a ready standby pod
+func (r *HarmanReconciler) checkStandby(ctx context.Context, harman *ssmdv1alpha1.Harman) error {
+	sw := harman.Status.EnvironmentSwitch
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(harman.Namespace), client.MatchingLabels{
+		"app.kubernetes.io/name":     "ssmd-harman",
+		"app.kubernetes.io/instance": harman.Name,
+		"ssmd.io/environment":        string(sw.To),
+	}); err != nil {
+		return err
+	}
+	var podIP string
+	for i := range pods.Items {
+		if pod := &pods.Items[i]; podReady(pod) && pod.Status.PodIP != "" {
+			podIP = pod.Status.PodIP
+			break
+		}
+	}
+	if podIP == "" {
+		return fmt.Errorf("no ready pod")
+	}
+
+	adminToken, err := r.adminToken(ctx, harman)
+	if err != nil {
+		return err
+	}
+	verify := r.verifyEnvironment
+	if verify == nil {
+		verify = verifyHarmanEnvironment
+	}
+	env, err := verify(ctx, fmt.Sprintf("http://%s:8080", podIP), adminToken)
+	if err != nil {
+		return err
+	}
+	if env != string(sw.To) {
+		return fmt.Errorf("harman reports environment %q", env)
+	}
+	return nil
+}
+
+// finishEnvironmentSwitch waits for the promoted Deployment, then deletes the
+// old one and makes the new environment active
+func (r *HarmanReconciler) finishEnvironmentSwitch(ctx context.Context, harman *ssmdv1alpha1.Harman) (time.Duration, error) {
+	sw := harman.Status.EnvironmentSwitch
+	standby := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: sw.Deployment, Namespace: harman.Namespace}, standby); err != nil {
+		if errors.IsNotFound(err) {
+			return 0, r.rollbackEnvironmentSwitch(ctx, harman, fmt.Sprintf("%s was deleted", sw.Deployment))
+		}
+		return 0, err
+	}
+	rolledOut := standby.Status.ObservedGeneration >= standby.Generation && standby.Status.ReadyReplicas > 0
+	if !rolledOut && tradingEnabled(harman) {
+		if switchPhaseExpired(sw) {
+			return 0, r.rollbackEnvironmentSwitch(ctx, harman,
+				fmt.Sprintf("%s not ready with trading enabled after %s", sw.Deployment, harmanSwitchTimeout))
+		}
+		return harmanSwitchPollInterval, nil
+	}
+
+	oldName := r.deploymentName(harman)
+	old := &appsv1.Deployment{}
+	err := r.Get(ctx, types.NamespacedName{Name: oldName, Namespace: harman.Namespace}, old)
+	if err == nil {
+		err = recordChildEvent(r.Recorder, harman, eventActionDelete, "Deployment", oldName, r.Delete(ctx, old))
+	}
+	if client.IgnoreNotFound(err) != nil {
+		return 0, err
+	}
+	// The PodDisruptionBudget is named after the Deployment
+	if err := reconcilePodDisruptionBudget(ctx, r.Client, r.Scheme, r.Recorder, harman, nil, oldName, nil); err != nil {
+		return 0, err
+	}
+
+	completed := metav1.Now()
+	setSwitchPhase(sw, ssmdv1alpha1.EnvironmentSwitchComplete, fmt.Sprintf("Switched from %s to %s", sw.From, sw.To))
+	sw.CompletedAt = &completed
+	harman.Status.ActiveEnvironment = sw.To
+	harman.Status.Deployment = sw.Deployment
+	logf.FromContext(ctx).Info("Environment switch complete", "from", sw.From, "to", sw.To)
+	recordEvent(r.Recorder, harman, corev1.EventTypeNormal, "EnvironmentSwitched", eventActionUpdate,
+		"Switched from %s to %s", sw.From, sw.To)
+	return 0, nil
+}
+
+// rollbackEnvironmentSwitch points the Service back at the active environment,
+// restores the old Deployment's replicas and deletes the standby
+func (r *HarmanReconciler) rollbackEnvironmentSwitch(ctx context.Context, harman *ssmdv1alpha1.Harman, reason string) error {
+	sw := harman.Status.EnvironmentSwitch
+	completed := metav1.Now()
+	setSwitchPhase(sw, ssmdv1alpha1.EnvironmentSwitchRolledBack, reason)
+	sw.CompletedAt = &completed
+	logf.FromContext(ctx).Info("Environment switch rolled back", "from", sw.From, "to", sw.To, "reason", reason)
+	recordEvent(r.Recorder, harman, corev1.EventTypeWarning, "EnvironmentSwitchRolledBack", eventActionUpdate,
+		"Switch from %s to %s rolled back: %s", sw.From, sw.To, reason)
+
+	if _, err := r.reconcileService(ctx, harman); err != nil {
+		return err
+	}
+	if err := r.scaleDeployment(ctx, harman, r.deploymentName(harman), desiredReplicas(harman)); err != nil {
+		return err
+	}
+	standby := &appsv1.Deployment{}
+	err := r.Get(ctx, types.NamespacedName{Name: sw.Deployment, Namespace: harman.Namespace}, standby)
+	if err == nil {
+		err = recordChildEvent(r.Recorder, harman, eventActionDelete, "Deployment", sw.Deployment, r.Delete(ctx, standby))
+	}
+	return client.IgnoreNotFound(err)
+}
+
+// applyStandbyDeployment creates or updates the standby Deployment and returns it
+func (r *HarmanReconciler) applyStandbyDeployment(ctx context.Context, harman *ssmdv1alpha1.Harman) (*appsv1.Deployment, error) {
+	name := harman.Status.EnvironmentSwitch.Deployment
+	desired := r.constructStandbyDeployment(harman, name)
+
+	deployment := &appsv1.Deployment{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: harman.Namespace}, deployment)
+	switch {
+	case errors.IsNotFound(err):
+		if err := controllerutil.SetControllerReference(harman, desired, r.Scheme); err != nil {
+			return nil, err
+		}
+		logf.FromContext(ctx).Info("Creating standby Deployment", "name", name)
+		if err := recordChildEvent(r.Recorder, harman, eventActionCreate, "Deployment", name, r.Create(ctx, desired)); err != nil {
+			return nil, err
+		}
+		return desired, nil
+	case err != nil:
+		return nil, err
+	case deploymentNeedsUpdate(deployment, desired):
+		updateDeploymentSpec(deployment, desired)
+		if err := recordChildEvent(r.Recorder, harman, eventActionUpdate, "Deployment", name, r.Update(ctx, deployment)); err != nil {
+			return nil, err
+		}
+	}
+	return deployment, nil
+}
+
+// promoteStandby restarts the standby with the spec's Deployment, trading enabled
+func (r *HarmanReconciler) promoteStandby(ctx context.Context, harman *ssmdv1alpha1.Harman) error {
+	name := harman.Status.EnvironmentSwitch.Deployment
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: harman.Namespace}, deployment); err != nil {
+		return err
+	}
+	desired := r.constructDeployment(harman)
+	updateDeploymentSpec(deployment, desired)
+	return recordChildEvent(r.Recorder, harman, eventActionUpdate, "Deployment", name, r.Update(ctx, deployment))
+}
+
+// scaleDeployment sets a Deployment's replicas, if it exists
+func (r *HarmanReconciler) scaleDeployment(ctx context.Context, harman *ssmdv1alpha1.Harman, name string, replicas int32) error {
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: harman.Namespace}, deployment); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == replicas {
+		return nil
+	}
+	deployment.Spec.Replicas = &replicas
+	return recordChildEvent(r.Recorder, harman, eventActionUpdate, "Deployment", name, r.Update(ctx, deployment))
+}
+
+// setSwitchPhase moves the switch to phase
+func setSwitchPhase(sw *ssmdv1alpha1.EnvironmentSwitchStatus, phase ssmdv1alpha1.EnvironmentSwitchPhase, message string) {
+	now := metav1.Now()
+	sw.Phase = phase
+	sw.Message = message
+	sw.LastTransitionTime = &now
+}
+
+// switchPhaseExpired reports whether the switch has been in its phase longer than harmanSwitchTimeout
+func switchPhaseExpired(sw *ssmdv1alpha1.EnvironmentSwitchStatus) bool {
+	return sw.LastTransitionTime != nil && time.Since(sw.LastTransitionTime.Time) > harmanSwitchTimeout
+}
+
+// podReady reports whether the pod's Ready condition is True
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,351 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// newSwitchTestReconciler builds a HarmanReconciler with a running demo
+// Deployment and Service, and the harman switched to prod in its spec
+func newSwitchTestReconciler(t *testing.T, verify harmanEnvironmentVerifier) (*HarmanReconciler, *ssmdv1alpha1.Harman) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	_ = policyv1.AddToScheme(scheme)
+
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, &corev1.LocalObjectReference{Name: "kalshi-secret"})
+	harman.Spec.Auth.SecretRef.Name = "harman-auth"
+	harman.Status.ActiveEnvironment = ssmdv1alpha1.ExchangeEnvironmentDemo
+
+	r := &HarmanReconciler{Scheme: scheme, verifyEnvironment: verify}
+	demo := r.constructDeployment(harman)
+	service := r.constructService(harman)
+	r.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(harman, demo, service, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "harman-auth", Namespace: "ssmd"},
+		Data:       map[string][]byte{"admin-token": []byte("admin-secret")},
+	}).WithStatusSubresource(&ssmdv1alpha1.Harman{}).Build()
+
+	harman.Spec.Exchange.Environment = ssmdv1alpha1.ExchangeEnvironmentProd
+	harman.Spec.Exchange.BaseURL = "https://api.elections.kalshi.com"
+	return r, harman
+}
+
+// markStandbyReady marks the standby Deployment ready and adds its ready pod
+func markStandbyReady(t *testing.T, r *HarmanReconciler) {
+	t.Helper()
+	ctx := context.Background()
+	deployment := getDeployment(t, r, "harman-test-prod")
+	deployment.Status.ReadyReplicas = 1
+	if err := r.Status().Update(ctx, deployment); err != nil {
+		t.Fatalf("failed to mark standby ready: %v", err)
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "harman-test-prod-abc", Namespace: "ssmd", Labels: deployment.Spec.Template.Labels},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      "10.0.0.7",
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	if err := r.Create(ctx, pod); err != nil && !apierrors.IsAlreadyExists(err) {
+		t.Fatalf("failed to create standby pod: %v", err)
+	}
+}
+
+func getDeployment(t *testing.T, r *HarmanReconciler, name string) *appsv1.Deployment {
+	t.Helper()
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "ssmd"}, deployment); err != nil {
+		t.Fatalf("failed to get deployment %s: %v", name, err)
+	}
+	return deployment
+}
+
+func deploymentExists(t *testing.T, r *HarmanReconciler, name string) bool {
+	t.Helper()
+	err := r.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "ssmd"}, &appsv1.Deployment{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		t.Fatalf("failed to get deployment %s: %v", name, err)
+	}
+	return err == nil
+}
+
+func serviceEnvironmentSelector(t *testing.T, r *HarmanReconciler) string {
+	t.Helper()
+	svc := &corev1.Service{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "harman-test", Namespace: "ssmd"}, svc); err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+	return svc.Spec.Selector["ssmd.io/environment"]
+}
+
+func envValue(container corev1.Container, name string) (string, bool) {
+	for _, e := range container.Env {
+		if e.Name == name {
+			return e.Value, true
+		}
+	}
+	return "", false
+}
+
+// --- TestConstructStandbyDeployment ---
+
+func TestConstructStandbyDeployment_TradingDisabled(t *testing.T) {
+	r := newTestReconciler()
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, nil)
+	harman.Spec.Trading = &ssmdv1alpha1.TradingConfig{Enabled: boolPtr(false)}
+	harman.Spec.EnvVars = []corev1.EnvVar{
+		{Name: "AUTO_PUMP", Value: "true"},
+		{Name: "NATS_URL", Value: "nats://nats:4222"},
+		{Name: "AUTH_VALIDATE_URL", Value: "http://data-ts/v1/auth/validate"},
+	}
+
+	deployment := r.constructStandbyDeployment(harman, "harman-test-prod")
+
+	if deployment.Name != "harman-test-prod" || *deployment.Spec.Replicas != 1 {
+		t.Errorf("name/replicas = %s/%d, want harman-test-prod/1", deployment.Name, *deployment.Spec.Replicas)
+	}
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if v, _ := envValue(container, "AUTO_PUMP"); v != "false" {
+		t.Errorf("AUTO_PUMP = %q, want false", v)
+	}
+	if v, _ := envValue(container, "RECONCILE_INTERVAL_SECS"); v != "0" {
+		t.Errorf("RECONCILE_INTERVAL_SECS = %q, want 0", v)
+	}
+	if _, ok := envValue(container, "NATS_URL"); ok {
+		t.Error("expected NATS_URL to be removed")
+	}
+	if _, ok := envValue(container, "AUTH_VALIDATE_URL"); !ok {
+		t.Error("expected other env vars to be kept")
+	}
+	if deployment.Annotations[specHashAnnotation] == r.constructDeployment(harman).Annotations[specHashAnnotation] {
+		t.Error("expected the standby spec hash to differ from the trading Deployment's")
+	}
+}
+
+// --- TestServiceEnvironment ---
+
+func TestConstructService_SelectsActiveEnvironment(t *testing.T) {
+	r := newTestReconciler()
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, nil)
+	harman.Spec.Exchange.Environment = ssmdv1alpha1.ExchangeEnvironmentProd
+	harman.Status.ActiveEnvironment = ssmdv1alpha1.ExchangeEnvironmentDemo
+
+	if env := r.constructService(harman).Spec.Selector["ssmd.io/environment"]; env != "demo" {
+		t.Errorf("selector environment = %q, want demo until the switch", env)
+	}
+
+	harman.Status.EnvironmentSwitch = &ssmdv1alpha1.EnvironmentSwitchStatus{
+		From: ssmdv1alpha1.ExchangeEnvironmentDemo, To: ssmdv1alpha1.ExchangeEnvironmentProd,
+		Phase: ssmdv1alpha1.EnvironmentSwitchSwitching,
+	}
+	if env := r.constructService(harman).Spec.Selector["ssmd.io/environment"]; env != "prod" {
+		t.Errorf("selector environment = %q, want prod while switching", env)
+	}
+}
+
+// --- TestReconcileEnvironmentSwitch ---
+
+func TestReconcileEnvironmentSwitch_SameEnvironment(t *testing.T) {
+	r, harman := newSwitchTestReconciler(t, nil)
+	harman.Spec.Exchange.Environment = ssmdv1alpha1.ExchangeEnvironmentDemo
+
+	requeue, err := r.reconcileEnvironmentSwitch(context.Background(), harman)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requeue != 0 || harman.Status.EnvironmentSwitch != nil {
+		t.Errorf("expected no switch, got requeue %s and %+v", requeue, harman.Status.EnvironmentSwitch)
+	}
+}
+
+func TestReconcileEnvironmentSwitch_Completes(t *testing.T) {
+	var verifiedURL string
+	r, harman := newSwitchTestReconciler(t, func(ctx context.Context, baseURL, adminToken string) (string, error) {
+		verifiedURL = baseURL
+		if adminToken != "admin-secret" {
+			return "", errors.New("401 Unauthorized")
+		}
+		return "prod", nil
+	})
+	ctx := context.Background()
+
+	// Start: the standby runs with trading disabled, the Service stays on demo
+	if _, err := r.reconcileEnvironmentSwitch(ctx, harman); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sw := harman.Status.EnvironmentSwitch
+	if sw == nil || sw.Phase != ssmdv1alpha1.EnvironmentSwitchProvisioning || sw.From != "demo" || sw.To != "prod" {
+		t.Fatalf("expected a Provisioning switch from demo to prod, got %+v", sw)
+	}
+	standby := getDeployment(t, r, "harman-test-prod")
+	if v, _ := envValue(standby.Spec.Template.Spec.Containers[0], "AUTO_PUMP"); v != "false" {
+		t.Errorf("standby AUTO_PUMP = %q, want false", v)
+	}
+	if env := serviceEnvironmentSelector(t, r); env != "demo" {
+		t.Errorf("service environment = %q, want demo", env)
+	}
+
+	// Ready and verified: the Service moves to prod and demo scales to zero
+	markStandbyReady(t, r)
+	if _, err := r.reconcileEnvironmentSwitch(ctx, harman); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if harman.Status.EnvironmentSwitch.Phase != ssmdv1alpha1.EnvironmentSwitchSwitching {
+		t.Fatalf("expected Switching, got %+v", harman.Status.EnvironmentSwitch)
+	}
+	if verifiedURL != "http://10.0.0.7:8080" {
+		t.Errorf("verified %q, want the standby pod", verifiedURL)
+	}
+	if env := serviceEnvironmentSelector(t, r); env != "prod" {
+		t.Errorf("service environment = %q, want prod", env)
+	}
+	if replicas := *getDeployment(t, r, "harman-test").Spec.Replicas; replicas != 0 {
+		t.Errorf("demo replicas = %d, want 0", replicas)
+	}
+	promoted := getDeployment(t, r, "harman-test-prod")
+	if _, ok := envValue(promoted.Spec.Template.Spec.Containers[0], "AUTO_PUMP"); ok {
+		t.Error("expected the promoted Deployment to run with the spec's trading settings")
+	}
+
+	// Promoted and ready: demo is deleted and prod is active
+	requeue, err := r.reconcileEnvironmentSwitch(ctx, harman)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requeue != 0 {
+		t.Errorf("requeue = %s, want 0 once the switch completes", requeue)
+	}
+	if harman.Status.EnvironmentSwitch.Phase != ssmdv1alpha1.EnvironmentSwitchComplete ||
+		harman.Status.ActiveEnvironment != "prod" || harman.Status.Deployment != "harman-test-prod" {
+		t.Errorf("expected prod active on harman-test-prod, got %+v", harman.Status)
+	}
+	if r.deploymentName(harman) != "harman-test-prod" {
+		t.Errorf("deploymentName = %s, want harman-test-prod", r.deploymentName(harman))
+	}
+	if deploymentExists(t, r, "harman-test") {
+		t.Error("expected the demo deployment to be deleted")
+	}
+}
+
+func TestReconcileEnvironmentSwitch_RollsBackOnFailedVerification(t *testing.T) {
+	r, harman := newSwitchTestReconciler(t, func(ctx context.Context, baseURL, adminToken string) (string, error) {
+		return "demo", nil
+	})
+	ctx := context.Background()
+
+	if _, err := r.reconcileEnvironmentSwitch(ctx, harman); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	markStandbyReady(t, r)
+	if _, err := r.reconcileEnvironmentSwitch(ctx, harman); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sw := harman.Status.EnvironmentSwitch
+	if sw.Phase != ssmdv1alpha1.EnvironmentSwitchVerifying || !strings.Contains(sw.Message, `"demo"`) {
+		t.Fatalf("expected verification to keep retrying, got %+v", sw)
+	}
+
+	// Past the timeout the switch rolls back
+	expired := metav1.NewTime(time.Now().Add(-harmanSwitchTimeout - time.Minute))
+	sw.LastTransitionTime = &expired
+	if _, err := r.reconcileEnvironmentSwitch(ctx, harman); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sw.Phase != ssmdv1alpha1.EnvironmentSwitchRolledBack || !strings.Contains(sw.Message, "failed verification") {
+		t.Errorf("expected RolledBack, got %+v", sw)
+	}
+	if harman.Status.ActiveEnvironment != "demo" || serviceEnvironmentSelector(t, r) != "demo" {
+		t.Error("expected demo to stay active")
+	}
+	if deploymentExists(t, r, "harman-test-prod") {
+		t.Error("expected the standby deployment to be deleted")
+	}
+	if replicas := *getDeployment(t, r, "harman-test").Spec.Replicas; replicas != 1 {
+		t.Errorf("demo replicas = %d, want 1", replicas)
+	}
+
+	// Not retried until the spec changes
+	requeue, err := r.reconcileEnvironmentSwitch(ctx, harman)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requeue == 0 || deploymentExists(t, r, "harman-test-prod") {
+		t.Error("expected the rolled back switch to wait for a spec change")
+	}
+	harman.Generation++
+	if _, err := r.reconcileEnvironmentSwitch(ctx, harman); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if harman.Status.EnvironmentSwitch.Phase != ssmdv1alpha1.EnvironmentSwitchProvisioning || !deploymentExists(t, r, "harman-test-prod") {
+		t.Errorf("expected a new switch after the spec changed, got %+v", harman.Status.EnvironmentSwitch)
+	}
+}
+
+func TestReconcileEnvironmentSwitch_RevertedSpecRollsBack(t *testing.T) {
+	r, harman := newSwitchTestReconciler(t, nil)
+	ctx := context.Background()
+
+	if _, err := r.reconcileEnvironmentSwitch(ctx, harman); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	harman.Spec.Exchange.Environment = ssmdv1alpha1.ExchangeEnvironmentDemo
+
+	requeue, err := r.reconcileEnvironmentSwitch(ctx, harman)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requeue != 0 {
+		t.Errorf("requeue = %s, want 0 so the demo Deployment is reconciled", requeue)
+	}
+	if harman.Status.EnvironmentSwitch.Phase != ssmdv1alpha1.EnvironmentSwitchRolledBack {
+		t.Errorf("expected RolledBack, got %+v", harman.Status.EnvironmentSwitch)
+	}
+	if deploymentExists(t, r, "harman-test-prod") {
+		t.Error("expected the standby deployment to be deleted")
+	}
+}
+
+func TestReconcileEnvironmentSwitch_KillSwitchDuringSwitch(t *testing.T) {
+	r, harman := newSwitchTestReconciler(t, nil)
+	harman.Spec.Trading = &ssmdv1alpha1.TradingConfig{Enabled: boolPtr(false)}
+
+	if _, err := r.reconcileEnvironmentSwitch(context.Background(), harman); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if replicas := *getDeployment(t, r, "harman-test").Spec.Replicas; replicas != 0 {
+		t.Errorf("demo replicas = %d, want 0 with trading disabled", replicas)
+	}
+}
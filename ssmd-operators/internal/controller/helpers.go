@@ -17,38 +17,120 @@ limitations under the License.
 package controller
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
 )
 
-// resourcesMatch checks that all desired resources are satisfied by current.
-// A resource is "satisfied" if current >= desired (Autopilot may bump values up).
-// Extra resources in current (added by Autopilot) are ignored.
-//
-// This prevents update loops on GKE Autopilot where:
-// 1. Operator sets desired resources (e.g., requests.memory=128Mi)
-// 2. Autopilot bumps to meet minimums (e.g., requests.memory=256Mi)
-// 3. Exact comparison sees 256Mi != 128Mi → triggers update
-// 4. Update resets to 128Mi → Autopilot bumps again → infinite loop
-func resourcesMatch(current, desired corev1.ResourceRequirements) bool {
-	for key, desiredQty := range desired.Requests {
-		currentQty, ok := current.Requests[key]
-		if !ok {
-			return false
+// defaultImagePullSecret is the registry secret used when a CR sets no imagePullSecrets
+const defaultImagePullSecret = "ghcr-secret"
+
+// specHashAnnotation records the hash of the Deployment spec the operator last applied
+const specHashAnnotation = "ssmd.io/spec-hash"
+
+// priorityClassName returns the CR's priority class, falling back to the operator default.
+func priorityClassName(specValue, defaultValue string) string {
+	if specValue != "" {
+		return specValue
+	}
+	return defaultValue
+}
+
+// containerResources returns the CR's resources, falling back to the operator default.
+func containerResources(specValue, defaultValue *corev1.ResourceRequirements) corev1.ResourceRequirements {
+	if specValue != nil {
+		return *specValue
+	}
+	if defaultValue != nil {
+		return *defaultValue.DeepCopy()
+	}
+	return corev1.ResourceRequirements{}
+}
+
+// imagePullSecrets returns the CR's image pull secrets, falling back to ghcr-secret.
+func imagePullSecrets(podTemplate *ssmdv1alpha1.PodTemplateConfig) []corev1.LocalObjectReference {
+	if podTemplate != nil && len(podTemplate.ImagePullSecrets) > 0 {
+		return podTemplate.ImagePullSecrets
+	}
+	return []corev1.LocalObjectReference{{Name: defaultImagePullSecret}}
+}
+
+// applyPodTemplate applies a CR's podTemplate settings to a pod template.
+// Annotations set by the operator (e.g. prometheus scrape config) win over podTemplate annotations.
+func applyPodTemplate(template *corev1.PodTemplateSpec, podTemplate *ssmdv1alpha1.PodTemplateConfig) {
+	template.Spec.ImagePullSecrets = imagePullSecrets(podTemplate)
+	if podTemplate == nil {
+		return
+	}
+
+	if len(podTemplate.NodeSelector) > 0 {
+		template.Spec.NodeSelector = podTemplate.NodeSelector
+	}
+	if len(podTemplate.Tolerations) > 0 {
+		template.Spec.Tolerations = podTemplate.Tolerations
+	}
+	if podTemplate.Affinity != nil {
+		template.Spec.Affinity = podTemplate.Affinity
+	}
+	if podTemplate.ServiceAccountName != "" {
+		template.Spec.ServiceAccountName = podTemplate.ServiceAccountName
+	}
+
+	for key, value := range podTemplate.Annotations {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
 		}
-		// Current must be >= desired (Autopilot may have bumped it)
-		if currentQty.Cmp(desiredQty) < 0 {
-			return false
+		if _, ok := template.Annotations[key]; !ok {
+			template.Annotations[key] = value
 		}
 	}
-	for key, desiredQty := range desired.Limits {
-		currentQty, ok := current.Limits[key]
-		if !ok {
-			return false
-		}
-		// Current must be >= desired
-		if currentQty.Cmp(desiredQty) < 0 {
-			return false
+}
+
+// specHash returns a stable hash of a desired Deployment or Job spec
+func specHash(spec any) string {
+	// json.Marshal sorts map keys, so equal specs always hash the same
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// setSpecHash stamps a desired Deployment with the hash of its spec
+func setSpecHash(deployment *appsv1.Deployment) {
+	metav1.SetMetaDataAnnotation(&deployment.ObjectMeta, specHashAnnotation, specHash(&deployment.Spec))
+}
+
+// deploymentNeedsUpdate checks if the Deployment was applied from a different desired spec.
+//
+// Comparing hashes of the desired spec instead of individual fields means any
+// change the operator makes (env, volumes, args, scheduling, ...) rolls the
+// Deployment, while values defaulted or bumped by the API server or GKE
+// Autopilot (e.g. resource requests) never cause update loops.
+func deploymentNeedsUpdate(current, desired *appsv1.Deployment) bool {
+	return current.Annotations[specHashAnnotation] != desired.Annotations[specHashAnnotation]
+}
+
+// updateDeploymentSpec copies the desired spec and its hash onto the current Deployment
+func updateDeploymentSpec(current, desired *appsv1.Deployment) {
+	current.Spec = desired.Spec
+	metav1.SetMetaDataAnnotation(&current.ObjectMeta, specHashAnnotation, desired.Annotations[specHashAnnotation])
+}
+
+// jobFinished reports whether the Job has the terminal condition (Complete or Failed)
+func jobFinished(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return true
 		}
 	}
-	return true
+	return false
 }
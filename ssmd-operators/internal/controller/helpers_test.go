@@ -19,140 +19,205 @@ package controller
 import (
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
 )
 
-func TestResourcesMatch_ExactMatch(t *testing.T) {
-	current := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("250m"),
-			corev1.ResourceMemory: resource.MustParse("128Mi"),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("256Mi"),
-		},
+func TestPriorityClassName_SpecWins(t *testing.T) {
+	if got := priorityClassName("ssmd-standard", "ssmd-critical"); got != "ssmd-standard" {
+		t.Errorf("expected spec value, got %q", got)
 	}
-	desired := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("250m"),
-			corev1.ResourceMemory: resource.MustParse("128Mi"),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("256Mi"),
-		},
+}
+
+func TestPriorityClassName_FallsBackToDefault(t *testing.T) {
+	if got := priorityClassName("", "ssmd-critical"); got != "ssmd-critical" {
+		t.Errorf("expected default, got %q", got)
 	}
-	if !resourcesMatch(current, desired) {
-		t.Error("expected resources to match")
+	if got := priorityClassName("", ""); got != "" {
+		t.Errorf("expected empty, got %q", got)
 	}
 }
 
-func TestResourcesMatch_AutopilotAddsExtra(t *testing.T) {
-	// Autopilot adds limits.cpu that we didn't set — should still match
-	current := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("250m"),
-			corev1.ResourceMemory: resource.MustParse("128Mi"),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("250m"), // Added by Autopilot
-			corev1.ResourceMemory: resource.MustParse("256Mi"),
-		},
+func TestContainerResources_SpecWins(t *testing.T) {
+	spec := &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}}
+	def := &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}}
+
+	got := containerResources(spec, def)
+	if !got.Limits.Memory().Equal(resource.MustParse("1Gi")) {
+		t.Errorf("expected spec limits, got %v", got.Limits)
 	}
-	desired := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("250m"),
-			corev1.ResourceMemory: resource.MustParse("128Mi"),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("256Mi"),
-		},
+}
+
+func TestContainerResources_FallsBackToDefault(t *testing.T) {
+	def := &corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}}
+
+	got := containerResources(nil, def)
+	if !got.Requests.Cpu().Equal(resource.MustParse("500m")) {
+		t.Errorf("expected default requests, got %v", got.Requests)
 	}
-	if !resourcesMatch(current, desired) {
-		t.Error("expected resources to match (Autopilot added extra field)")
+	got.Requests[corev1.ResourceCPU] = resource.MustParse("1")
+	if !def.Requests.Cpu().Equal(resource.MustParse("500m")) {
+		t.Error("expected the default to be copied, not shared")
+	}
+	if got := containerResources(nil, nil); got.Requests != nil || got.Limits != nil {
+		t.Errorf("expected empty requirements, got %+v", got)
 	}
 }
 
-func TestResourcesMatch_AutopilotBumpsUp(t *testing.T) {
-	// Autopilot bumped CPU from 250m to 500m — current >= desired, should match
-	current := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("500m"),
-			corev1.ResourceMemory: resource.MustParse("128Mi"),
-		},
-	}
-	desired := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("250m"),
-			corev1.ResourceMemory: resource.MustParse("128Mi"),
-		},
+func TestApplyPodTemplate_DefaultsImagePullSecret(t *testing.T) {
+	template := &corev1.PodTemplateSpec{}
+	applyPodTemplate(template, nil)
+
+	if len(template.Spec.ImagePullSecrets) != 1 || template.Spec.ImagePullSecrets[0].Name != "ghcr-secret" {
+		t.Errorf("expected ghcr-secret, got %v", template.Spec.ImagePullSecrets)
 	}
-	if !resourcesMatch(current, desired) {
-		t.Error("expected resources to match (current >= desired after Autopilot bump)")
+	if template.Spec.NodeSelector != nil || template.Spec.Tolerations != nil || template.Spec.Affinity != nil {
+		t.Error("expected no scheduling settings without a podTemplate")
 	}
 }
 
-func TestResourcesMatch_CurrentBelowDesired(t *testing.T) {
-	// Desired is higher than current — should NOT match (need update)
-	current := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("100m"),
-			corev1.ResourceMemory: resource.MustParse("128Mi"),
-		},
+func TestApplyPodTemplate_AppliesSettings(t *testing.T) {
+	template := &corev1.PodTemplateSpec{}
+	template.Annotations = map[string]string{"prometheus.io/port": "8080"}
+	applyPodTemplate(template, &ssmdv1alpha1.PodTemplateConfig{
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-secret"}},
+		NodeSelector:     map[string]string{"pool": "market-data"},
+		Tolerations: []corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "ssmd", Effect: corev1.TaintEffectNoSchedule},
+		},
+		Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}},
+		Annotations: map[string]string{
+			"prometheus.io/port":      "9999",
+			"sidecar.istio.io/inject": "false",
+		},
+		ServiceAccountName: "ssmd-connector",
+	})
+
+	spec := template.Spec
+	if len(spec.ImagePullSecrets) != 1 || spec.ImagePullSecrets[0].Name != "registry-secret" {
+		t.Errorf("expected registry-secret, got %v", spec.ImagePullSecrets)
 	}
-	desired := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("250m"),
-			corev1.ResourceMemory: resource.MustParse("128Mi"),
-		},
+	if spec.NodeSelector["pool"] != "market-data" {
+		t.Errorf("expected node selector, got %v", spec.NodeSelector)
+	}
+	if len(spec.Tolerations) != 1 || spec.Tolerations[0].Key != "dedicated" {
+		t.Errorf("expected toleration, got %v", spec.Tolerations)
 	}
-	if resourcesMatch(current, desired) {
-		t.Error("expected resources to NOT match (current < desired)")
+	if spec.Affinity == nil || spec.Affinity.NodeAffinity == nil {
+		t.Error("expected node affinity")
+	}
+	if spec.ServiceAccountName != "ssmd-connector" {
+		t.Errorf("expected service account ssmd-connector, got %q", spec.ServiceAccountName)
+	}
+	if template.Annotations["sidecar.istio.io/inject"] != "false" {
+		t.Errorf("expected podTemplate annotation, got %v", template.Annotations)
+	}
+	if template.Annotations["prometheus.io/port"] != "8080" {
+		t.Errorf("operator annotation should win, got %q", template.Annotations["prometheus.io/port"])
 	}
 }
 
-func TestResourcesMatch_DesiredFieldMissing(t *testing.T) {
-	// We want memory limit but current doesn't have it
-	current := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU: resource.MustParse("250m"),
-		},
+func newHashTestDeployment() *appsv1.Deployment {
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "connector",
+						Image: "ghcr.io/aaronwald/ssmd-connector:0.1.0",
+						Env:   []corev1.EnvVar{{Name: "RUST_LOG", Value: "info"}},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+						},
+					}},
+				},
+			},
+		},
+	}
+	setSpecHash(deployment)
+	return deployment
+}
+
+func TestSpecHash_Stable(t *testing.T) {
+	a := newHashTestDeployment()
+	b := newHashTestDeployment()
+
+	if a.Annotations[specHashAnnotation] == "" {
+		t.Fatal("expected spec hash annotation")
 	}
-	desired := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU: resource.MustParse("250m"),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceMemory: resource.MustParse("256Mi"),
-		},
+	if deploymentNeedsUpdate(a, b) {
+		t.Error("expected identical specs to hash the same")
 	}
-	if resourcesMatch(current, desired) {
-		t.Error("expected resources to NOT match (desired limit missing from current)")
+}
+
+func TestDeploymentNeedsUpdate_DetectsDrift(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(spec *corev1.PodSpec)
+	}{
+		{"env", func(spec *corev1.PodSpec) { spec.Containers[0].Env[0].Value = "debug" }},
+		{"args", func(spec *corev1.PodSpec) { spec.Containers[0].Args = []string{"--verbose"} }},
+		{"volumes", func(spec *corev1.PodSpec) { spec.Volumes = []corev1.Volume{{Name: "data"}} }},
+		{"nodeSelector", func(spec *corev1.PodSpec) { spec.NodeSelector = map[string]string{"pool": "md"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := newHashTestDeployment()
+			desired := newHashTestDeployment()
+			tt.mutate(&desired.Spec.Template.Spec)
+			setSpecHash(desired)
+
+			if !deploymentNeedsUpdate(current, desired) {
+				t.Errorf("expected %s change to trigger an update", tt.name)
+			}
+		})
 	}
 }
 
-func TestResourcesMatch_BothEmpty(t *testing.T) {
-	current := corev1.ResourceRequirements{}
-	desired := corev1.ResourceRequirements{}
-	if !resourcesMatch(current, desired) {
-		t.Error("expected empty resources to match")
+func TestDeploymentNeedsUpdate_IgnoresServerSideChanges(t *testing.T) {
+	current := newHashTestDeployment()
+	desired := newHashTestDeployment()
+
+	// Autopilot bumps requests and the API server fills defaults on current only
+	current.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory] = resource.MustParse("512Mi")
+	current.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+
+	if deploymentNeedsUpdate(current, desired) {
+		t.Error("server-side changes to current should not trigger an update")
 	}
 }
 
-func TestResourcesMatch_DesiredEmptyCurrentHasResources(t *testing.T) {
-	// CR doesn't specify resources, but Autopilot sets them — should match
-	current := corev1.ResourceRequirements{
-		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("250m"),
-			corev1.ResourceMemory: resource.MustParse("512Mi"),
-		},
-		Limits: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse("250m"),
-			corev1.ResourceMemory: resource.MustParse("512Mi"),
-		},
+func TestDeploymentNeedsUpdate_MissingHash(t *testing.T) {
+	// Deployments created before the hash annotation existed are updated once
+	current := newHashTestDeployment()
+	delete(current.Annotations, specHashAnnotation)
+
+	if !deploymentNeedsUpdate(current, newHashTestDeployment()) {
+		t.Error("expected update when current has no spec hash")
+	}
+}
+
+func TestUpdateDeploymentSpec_CopiesHash(t *testing.T) {
+	current := newHashTestDeployment()
+	current.Annotations["deployment.kubernetes.io/revision"] = "3"
+	desired := newHashTestDeployment()
+	desired.Spec.Template.Spec.Containers[0].Image = "ghcr.io/aaronwald/ssmd-connector:0.2.0"
+	setSpecHash(desired)
+
+	updateDeploymentSpec(current, desired)
+
+	if deploymentNeedsUpdate(current, desired) {
+		t.Error("expected no update after copying the desired spec")
+	}
+	if current.Spec.Template.Spec.Containers[0].Image != "ghcr.io/aaronwald/ssmd-connector:0.2.0" {
+		t.Errorf("expected desired image, got %q", current.Spec.Template.Spec.Containers[0].Image)
 	}
-	desired := corev1.ResourceRequirements{}
-	if !resourcesMatch(current, desired) {
-		t.Error("expected match when desired is empty (Autopilot can set whatever it wants)")
+	if current.Annotations["deployment.kubernetes.io/revision"] != "3" {
+		t.Error("expected other annotations to be kept")
 	}
 }
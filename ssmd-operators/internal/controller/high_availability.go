@@ -0,0 +1,121 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// applyTopologySpread adds a topology spread constraint over the workload's own
+// pods for each domain in the HighAvailability config
+func applyTopologySpread(template *corev1.PodTemplateSpec, ha *ssmdv1alpha1.HighAvailabilityConfig, selector *metav1.LabelSelector) {
+	if ha == nil {
+		return
+	}
+	for _, spread := range ha.TopologySpread {
+		maxSkew := spread.MaxSkew
+		if maxSkew == 0 {
+			maxSkew = 1
+		}
+		whenUnsatisfiable := spread.WhenUnsatisfiable
+		if whenUnsatisfiable == "" {
+			whenUnsatisfiable = corev1.ScheduleAnyway
+		}
+		template.Spec.TopologySpreadConstraints = append(template.Spec.TopologySpreadConstraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           maxSkew,
+			TopologyKey:       spread.TopologyKey,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector:     selector.DeepCopy(),
+		})
+	}
+}
+
+// constructPodDisruptionBudget builds the PDB covering the pods matched by selector
+func constructPodDisruptionBudget(name, namespace string, ha *ssmdv1alpha1.HighAvailabilityConfig, selector *metav1.LabelSelector) *policyv1.PodDisruptionBudget {
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    selector.MatchLabels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector:       selector.DeepCopy(),
+			MinAvailable:   ha.MinAvailable,
+			MaxUnavailable: ha.MaxUnavailable,
+		},
+	}
+	if pdb.Spec.MinAvailable == nil && pdb.Spec.MaxUnavailable == nil {
+		minAvailable := intstr.FromInt32(1)
+		pdb.Spec.MinAvailable = &minAvailable
+	}
+	metav1.SetMetaDataAnnotation(&pdb.ObjectMeta, specHashAnnotation, specHash(&pdb.Spec))
+	return pdb
+}
+
+// reconcilePodDisruptionBudget creates or updates the owner's PDB when
+// HighAvailability is set and removes it otherwise. The PDB shares the
+// Deployment's name and selector.
+func reconcilePodDisruptionBudget(ctx context.Context, c client.Client, scheme *runtime.Scheme, recorder events.EventRecorder,
+	owner client.Object, ha *ssmdv1alpha1.HighAvailabilityConfig, name string, selector *metav1.LabelSelector) error {
+	log := logf.FromContext(ctx)
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: owner.GetNamespace()}, pdb)
+	found := err == nil
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if ha == nil {
+		if found {
+			log.Info("Deleting PodDisruptionBudget", "name", name)
+			return client.IgnoreNotFound(recordChildEvent(recorder, owner, eventActionDelete, "PodDisruptionBudget", name, c.Delete(ctx, pdb)))
+		}
+		return nil
+	}
+
+	desired := constructPodDisruptionBudget(name, owner.GetNamespace(), ha, selector)
+	if !found {
+		if err := controllerutil.SetControllerReference(owner, desired, scheme); err != nil {
+			return err
+		}
+		log.Info("Creating PodDisruptionBudget", "name", name)
+		return recordChildEvent(recorder, owner, eventActionCreate, "PodDisruptionBudget", name, c.Create(ctx, desired))
+	}
+
+	if pdb.Annotations[specHashAnnotation] != desired.Annotations[specHashAnnotation] {
+		pdb.Spec = desired.Spec
+		metav1.SetMetaDataAnnotation(&pdb.ObjectMeta, specHashAnnotation, desired.Annotations[specHashAnnotation])
+		log.Info("Updating PodDisruptionBudget", "name", name)
+		return recordChildEvent(recorder, owner, eventActionUpdate, "PodDisruptionBudget", name, c.Update(ctx, pdb))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// --- TestApplyTopologySpread ---
+
+func TestApplyTopologySpread(t *testing.T) {
+	snap := newTestSnap()
+	snap.Spec.HighAvailability = &ssmdv1alpha1.HighAvailabilityConfig{
+		TopologySpread: []ssmdv1alpha1.TopologySpreadConfig{
+			{TopologyKey: "topology.kubernetes.io/zone"},
+			{TopologyKey: "kubernetes.io/hostname", MaxSkew: 2, WhenUnsatisfiable: corev1.DoNotSchedule},
+		},
+	}
+
+	deployment := (&SnapReconciler{}).constructDeployment(snap)
+
+	constraints := deployment.Spec.Template.Spec.TopologySpreadConstraints
+	if len(constraints) != 2 {
+		t.Fatalf("expected 2 constraints, got %+v", constraints)
+	}
+	if constraints[0].MaxSkew != 1 || constraints[0].WhenUnsatisfiable != corev1.ScheduleAnyway {
+		t.Errorf("expected defaults on first constraint, got %+v", constraints[0])
+	}
+	if constraints[1].MaxSkew != 2 || constraints[1].WhenUnsatisfiable != corev1.DoNotSchedule {
+		t.Errorf("unexpected second constraint %+v", constraints[1])
+	}
+	if constraints[0].LabelSelector.MatchLabels["app.kubernetes.io/instance"] != "snap-kalshi" {
+		t.Errorf("expected constraint to select the snap pods, got %v", constraints[0].LabelSelector)
+	}
+}
+
+// --- TestConstructPodDisruptionBudget ---
+
+func TestConstructPodDisruptionBudget_DefaultMinAvailable(t *testing.T) {
+	r := newTestReconciler()
+	harman := newTestHarman(ssmdv1alpha1.ExchangeTypeKalshi, &corev1.LocalObjectReference{Name: "kalshi-secret"})
+	selector := r.constructDeployment(harman).Spec.Selector
+
+	pdb := constructPodDisruptionBudget("harman-test", "ssmd", &ssmdv1alpha1.HighAvailabilityConfig{}, selector)
+
+	if pdb.Spec.MinAvailable == nil || pdb.Spec.MinAvailable.IntValue() != 1 || pdb.Spec.MaxUnavailable != nil {
+		t.Errorf("expected minAvailable 1, got %+v", pdb.Spec)
+	}
+	if pdb.Spec.Selector.MatchLabels["ssmd.io/exchange"] != "kalshi" {
+		t.Errorf("expected the Deployment selector, got %v", pdb.Spec.Selector)
+	}
+}
+
+func TestConstructPodDisruptionBudget_MaxUnavailable(t *testing.T) {
+	maxUnavailable := intstr.FromString("50%")
+	pdb := constructPodDisruptionBudget("snap-kalshi", "ssmd",
+		&ssmdv1alpha1.HighAvailabilityConfig{MaxUnavailable: &maxUnavailable},
+		(&SnapReconciler{}).constructDeployment(newTestSnap()).Spec.Selector)
+
+	if pdb.Spec.MinAvailable != nil || pdb.Spec.MaxUnavailable.String() != "50%" {
+		t.Errorf("expected only maxUnavailable 50%%, got %+v", pdb.Spec)
+	}
+}
+
+// --- TestReconcilePodDisruptionBudget ---
+
+func TestReconcilePodDisruptionBudget(t *testing.T) {
+	snap := newTestSnap()
+	r := newSnapTestReconciler(snap)
+	selector := r.constructDeployment(snap).Spec.Selector
+	key := types.NamespacedName{Name: "snap-kalshi", Namespace: "ssmd"}
+	ctx := context.Background()
+
+	ha := &ssmdv1alpha1.HighAvailabilityConfig{}
+	if err := reconcilePodDisruptionBudget(ctx, r.Client, r.Scheme, nil, snap, ha, key.Name, selector); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pdb := &policyv1.PodDisruptionBudget{}
+	if err := r.Get(ctx, key, pdb); err != nil {
+		t.Fatalf("expected PDB, got %v", err)
+	}
+	if len(pdb.OwnerReferences) != 1 || pdb.OwnerReferences[0].Name != "snap-kalshi" {
+		t.Errorf("expected PDB owned by the Snap, got %+v", pdb.OwnerReferences)
+	}
+
+	minAvailable := intstr.FromInt32(2)
+	ha.MinAvailable = &minAvailable
+	if err := reconcilePodDisruptionBudget(ctx, r.Client, r.Scheme, nil, snap, ha, key.Name, selector); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(ctx, key, pdb); err != nil || pdb.Spec.MinAvailable.IntValue() != 2 {
+		t.Errorf("expected minAvailable updated to 2, got %v (%v)", pdb.Spec.MinAvailable, err)
+	}
+
+	if err := reconcilePodDisruptionBudget(ctx, r.Client, r.Scheme, nil, snap, nil, key.Name, selector); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(ctx, key, &policyv1.PodDisruptionBudget{}); !errors.IsNotFound(err) {
+		t.Errorf("expected PDB to be removed, got %v", err)
+	}
+}
@@ -20,8 +20,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"reflect"
 	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -31,8 +31,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -41,20 +43,43 @@ import (
 
 const (
 	notifierFinalizer = "ssmd.ssmd.io/notifier-finalizer"
+
+	// notifierMetricsPort is the notifier's /health, /ready and /metrics port
+	notifierMetricsPort = 9090
+
+	// defaultDeliveryDegradedPercent is the failure percentage that marks delivery degraded
+	defaultDeliveryDegradedPercent = 20
 )
 
 // NotifierReconciler reconciles a Notifier object
 type NotifierReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events for reconcile actions (skipped if nil)
+	Recorder events.EventRecorder
+
+	// MaxConcurrentReconciles is the number of parallel reconciles (1 if unset)
+	MaxConcurrentReconciles int
+
+	// DefaultPriorityClassName is used for pods whose CR does not set priorityClassName
+	DefaultPriorityClassName string
+
+	// DefaultResources are used for pods whose CR does not set resources
+	DefaultResources *corev1.ResourceRequirements
+
+	// scrape fetches pod metrics; defaults to scrapeMetrics
+	scrape metricsScraper
 }
 
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=notifiers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=notifiers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=notifiers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 
 // Reconcile moves the cluster state toward the desired state for a Notifier
 func (r *NotifierReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -84,6 +109,36 @@ func (r *NotifierReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
+	// Check destination secrets before rolling out a config that references them
+	missing, err := r.missingSecretKeys(ctx, notifier)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(missing) > 0 {
+		log.Info("Destination secrets are incomplete, not updating Deployment", "missing", missing)
+		recordPhaseChange(r.Recorder, notifier, notifier.Status.Phase, ssmdv1alpha1.NotifierPhaseFailed)
+		notifier.Status.Phase = ssmdv1alpha1.NotifierPhaseFailed
+		setConditionWithEvent(r.Recorder, notifier, &notifier.Status.Conditions, metav1.Condition{
+			Type:               "SecretsReady",
+			Status:             metav1.ConditionFalse,
+			Reason:             "SecretKeyMissing",
+			Message:            strings.Join(missing, "; "),
+			LastTransitionTime: metav1.Now(),
+		})
+		if err := r.Status().Update(ctx, notifier); err != nil {
+			return ctrl.Result{}, err
+		}
+		// Secrets are not watched, so check again later
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+	setConditionWithEvent(r.Recorder, notifier, &notifier.Status.Conditions, metav1.Condition{
+		Type:               "SecretsReady",
+		Status:             metav1.ConditionTrue,
+		Reason:             "SecretsFound",
+		Message:            "All destination secret keys exist",
+		LastTransitionTime: metav1.Now(),
+	})
+
 	// Reconcile the ConfigMap (destinations config)
 	if _, err := r.reconcileConfigMap(ctx, notifier); err != nil {
 		return ctrl.Result{}, err
@@ -100,7 +155,8 @@ func (r *NotifierReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	// Requeue to refresh delivery metrics from the notifier pods
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 }
 
 // reconcileDelete handles cleanup when the Notifier is deleted
@@ -114,7 +170,7 @@ func (r *NotifierReconciler) reconcileDelete(ctx context.Context, notifier *ssmd
 		deploymentName := r.deploymentName(notifier)
 		deployment := &appsv1.Deployment{}
 		if err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: notifier.Namespace}, deployment); err == nil {
-			if err := r.Delete(ctx, deployment); err != nil && !errors.IsNotFound(err) {
+			if err := recordChildEvent(r.Recorder, notifier, eventActionDelete, "Deployment", deploymentName, r.Delete(ctx, deployment)); err != nil && !errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
 			log.Info("Deleted Deployment", "name", deploymentName)
@@ -124,7 +180,7 @@ func (r *NotifierReconciler) reconcileDelete(ctx context.Context, notifier *ssmd
 		configMapName := r.configMapName(notifier)
 		configMap := &corev1.ConfigMap{}
 		if err := r.Get(ctx, types.NamespacedName{Name: configMapName, Namespace: notifier.Namespace}, configMap); err == nil {
-			if err := r.Delete(ctx, configMap); err != nil && !errors.IsNotFound(err) {
+			if err := recordChildEvent(r.Recorder, notifier, eventActionDelete, "ConfigMap", configMapName, r.Delete(ctx, configMap)); err != nil && !errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
 			log.Info("Deleted ConfigMap", "name", configMapName)
@@ -155,7 +211,7 @@ func (r *NotifierReconciler) reconcileConfigMap(ctx context.Context, notifier *s
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating ConfigMap", "name", configMapName)
-		if err := r.Create(ctx, desiredConfigMap); err != nil {
+		if err := recordChildEvent(r.Recorder, notifier, eventActionCreate, "ConfigMap", configMapName, r.Create(ctx, desiredConfigMap)); err != nil {
 			return ctrl.Result{}, err
 		}
 	} else if err != nil {
@@ -165,7 +221,7 @@ func (r *NotifierReconciler) reconcileConfigMap(ctx context.Context, notifier *s
 		if configMap.Data["destinations.json"] != desiredConfigMap.Data["destinations.json"] {
 			configMap.Data = desiredConfigMap.Data
 			log.Info("Updating ConfigMap", "name", configMapName)
-			if err := r.Update(ctx, configMap); err != nil {
+			if err := recordChildEvent(r.Recorder, notifier, eventActionUpdate, "ConfigMap", configMapName, r.Update(ctx, configMap)); err != nil {
 				return ctrl.Result{}, err
 			}
 		}
@@ -210,7 +266,7 @@ func (r *NotifierReconciler) reconcileDeployment(ctx context.Context, notifier *
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating Deployment", "name", deploymentName)
-		if err := r.Create(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, notifier, eventActionCreate, "Deployment", deploymentName, r.Create(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -220,10 +276,10 @@ func (r *NotifierReconciler) reconcileDeployment(ctx context.Context, notifier *
 
 	// Update existing Deployment if needed
 	desired := r.constructDeployment(notifier)
-	if r.deploymentNeedsUpdate(deployment, desired) {
-		deployment.Spec = desired.Spec
+	if deploymentNeedsUpdate(deployment, desired) {
+		updateDeploymentSpec(deployment, desired)
 		log.Info("Updating Deployment", "name", deploymentName)
-		if err := r.Update(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, notifier, eventActionUpdate, "Deployment", deploymentName, r.Update(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
@@ -327,12 +383,10 @@ func (r *NotifierReconciler) constructDeployment(notifier *ssmdv1alpha1.Notifier
 		},
 	}
 
-	// Add resource requirements if specified
-	if notifier.Spec.Resources != nil {
-		container.Resources = *notifier.Spec.Resources
-	}
+	// Add resource requirements, falling back to the operator default
+	container.Resources = containerResources(notifier.Spec.Resources, r.DefaultResources)
 
-	return &appsv1.Deployment{
+	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.deploymentName(notifier),
 			Namespace: notifier.Namespace,
@@ -348,50 +402,17 @@ func (r *NotifierReconciler) constructDeployment(notifier *ssmdv1alpha1.Notifier
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
-					Containers:       []corev1.Container{container},
-					Volumes:          volumes,
-					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "ghcr-secret"}},
+					PriorityClassName: priorityClassName(notifier.Spec.PriorityClassName, r.DefaultPriorityClassName),
+					Containers:        []corev1.Container{container},
+					Volumes:           volumes,
 				},
 			},
 		},
 	}
-}
-
-// deploymentNeedsUpdate checks if the Deployment needs to be updated
-func (r *NotifierReconciler) deploymentNeedsUpdate(current, desired *appsv1.Deployment) bool {
-	if len(current.Spec.Template.Spec.Containers) == 0 || len(desired.Spec.Template.Spec.Containers) == 0 {
-		return len(current.Spec.Template.Spec.Containers) != len(desired.Spec.Template.Spec.Containers)
-	}
-
-	currentContainer := &current.Spec.Template.Spec.Containers[0]
-	desiredContainer := &desired.Spec.Template.Spec.Containers[0]
 
-	// Check image
-	if currentContainer.Image != desiredContainer.Image {
-		return true
-	}
-
-	// Check environment variables
-	if !reflect.DeepEqual(currentContainer.Env, desiredContainer.Env) {
-		return true
-	}
-
-	// Check resource requirements (Autopilot-safe: only check desired fields)
-	if !resourcesMatch(currentContainer.Resources, desiredContainer.Resources) {
-		return true
-	}
-
-	// Check volume mounts
-	if !reflect.DeepEqual(currentContainer.VolumeMounts, desiredContainer.VolumeMounts) {
-		return true
-	}
-
-	// Check volumes
-	if !reflect.DeepEqual(current.Spec.Template.Spec.Volumes, desired.Spec.Template.Spec.Volumes) {
-		return true
-	}
-
-	return false
+	applyPodTemplate(&deployment.Spec.Template, notifier.Spec.PodTemplate)
+	setSpecHash(deployment)
+	return deployment
 }
 
 // getEnvValue returns the value of an env var by name
@@ -406,6 +427,9 @@ func getEnvValue(envs []corev1.EnvVar, name string) string {
 
 // updateStatus updates the Notifier status based on Deployment state
 func (r *NotifierReconciler) updateStatus(ctx context.Context, notifier *ssmdv1alpha1.Notifier) error {
+	previousPhase := notifier.Status.Phase
+	defer func() { recordPhaseChange(r.Recorder, notifier, previousPhase, notifier.Status.Phase) }()
+
 	deploymentName := r.deploymentName(notifier)
 	deployment := &appsv1.Deployment{}
 	err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: notifier.Namespace}, deployment)
@@ -425,12 +449,14 @@ func (r *NotifierReconciler) updateStatus(ctx context.Context, notifier *ssmdv1a
 			notifier.Status.Phase = ssmdv1alpha1.NotifierPhasePending
 		}
 
-		// Initialize destination metrics if not present
-		if notifier.Status.DestinationMetrics == nil {
-			notifier.Status.DestinationMetrics = make([]ssmdv1alpha1.DestinationMetrics, len(notifier.Spec.Destinations))
-			for i, d := range notifier.Spec.Destinations {
-				notifier.Status.DestinationMetrics[i] = ssmdv1alpha1.DestinationMetrics{
-					Name: d.Name,
+		// One metrics entry per destination, keeping counts of destinations still configured
+		previous := notifier.Status.DestinationMetrics
+		notifier.Status.DestinationMetrics = make([]ssmdv1alpha1.DestinationMetrics, len(notifier.Spec.Destinations))
+		for i, d := range notifier.Spec.Destinations {
+			notifier.Status.DestinationMetrics[i] = ssmdv1alpha1.DestinationMetrics{Name: d.Name}
+			for _, p := range previous {
+				if p.Name == d.Name {
+					notifier.Status.DestinationMetrics[i] = p
 				}
 			}
 		}
@@ -449,11 +475,175 @@ func (r *NotifierReconciler) updateStatus(ctx context.Context, notifier *ssmdv1a
 			condition.Message = fmt.Sprintf("Routing to %d destinations", len(notifier.Spec.Destinations))
 		}
 		meta.SetStatusCondition(&notifier.Status.Conditions, condition)
+
+		// Delivery counters from the running notifier pods
+		if err := r.updateMetricsStatus(ctx, notifier); err != nil {
+			return err
+		}
+		if degraded := r.deliveryDegradedCondition(notifier, previous); degraded != nil {
+			meta.SetStatusCondition(&notifier.Status.Conditions, *degraded)
+		}
 	}
 
 	return r.Status().Update(ctx, notifier)
 }
 
+// notifierMetrics holds the values scraped from a notifier's /metrics, keyed by destination
+type notifierMetrics struct {
+	FiresReceived int64
+	Sent          map[string]int64
+	Failed        map[string]int64
+	LastSentAt    map[string]time.Time
+}
+
+// parseNotifierMetrics extracts status values from a notifier's /metrics
+func parseNotifierMetrics(families metricFamilies) notifierMetrics {
+	m := notifierMetrics{
+		Sent:       map[string]int64{},
+		Failed:     map[string]int64{},
+		LastSentAt: map[string]time.Time{},
+	}
+
+	if total, ok := families.sum("ssmd_notifier_fires_received_total"); ok {
+		m.FiresReceived = int64(total)
+	}
+	for dest, v := range families.sumByLabel("ssmd_notifier_notifications_sent_total", "destination") {
+		m.Sent[dest] = int64(v)
+	}
+	for dest, v := range families.sumByLabel("ssmd_notifier_notifications_failed_total", "destination") {
+		m.Failed[dest] = int64(v)
+	}
+	for dest, ts := range families.maxByLabel("ssmd_notifier_last_sent_timestamp", "destination") {
+		if ts > 0 {
+			m.LastSentAt[dest] = time.Unix(int64(ts), 0)
+		}
+	}
+
+	return m
+}
+
+// add merges metrics from another pod
+func (m *notifierMetrics) add(other notifierMetrics) {
+	m.FiresReceived += other.FiresReceived
+	for dest, v := range other.Sent {
+		m.Sent[dest] += v
+	}
+	for dest, v := range other.Failed {
+		m.Failed[dest] += v
+	}
+	for dest, ts := range other.LastSentAt {
+		if ts.After(m.LastSentAt[dest]) {
+			m.LastSentAt[dest] = ts
+		}
+	}
+}
+
+// updateMetricsStatus scrapes the Notifier's pods and copies their delivery counters into status.
+// Status keeps its previous values if no pod could be scraped.
+func (r *NotifierReconciler) updateMetricsStatus(ctx context.Context, notifier *ssmdv1alpha1.Notifier) error {
+	log := logf.FromContext(ctx)
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(notifier.Namespace), client.MatchingLabels{
+		"app.kubernetes.io/name":     "ssmd-notifier",
+		"app.kubernetes.io/instance": notifier.Name,
+	}); err != nil {
+		return err
+	}
+
+	scrape := r.scrape
+	if scrape == nil {
+		scrape = scrapeMetrics
+	}
+
+	total := notifierMetrics{Sent: map[string]int64{}, Failed: map[string]int64{}, LastSentAt: map[string]time.Time{}}
+	scraped := 0
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		url := fmt.Sprintf("http://%s:%d/metrics", pod.Status.PodIP, notifierMetricsPort)
+		families, err := scrape(ctx, url)
+		if err != nil {
+			log.V(1).Info("Failed to scrape notifier metrics", "pod", pod.Name, "error", err.Error())
+			continue
+		}
+		total.add(parseNotifierMetrics(families))
+		scraped++
+	}
+	if scraped == 0 {
+		return nil
+	}
+
+	notifier.Status.FiresReceived = total.FiresReceived
+	for i := range notifier.Status.DestinationMetrics {
+		dm := &notifier.Status.DestinationMetrics[i]
+		dm.Sent = int32(total.Sent[dm.Name])
+		dm.Failed = int32(total.Failed[dm.Name])
+		if ts, ok := total.LastSentAt[dm.Name]; ok {
+			lastSentAt := metav1.NewTime(ts)
+			dm.LastSentAt = &lastSentAt
+		}
+	}
+	return nil
+}
+
+// deliveryDegradedCondition returns the DeliveryDegraded condition for a running
+// Notifier, or nil if there is nothing new to judge. It compares each
+// destination's counters with previous (the status before this scrape):
+// delivery is degraded when failures make up at least spec.deliveryDegradedPercent
+// of any destination's notifications since then. A counter that went backwards
+// means the pod restarted, so its current value is taken as the delta.
+func (r *NotifierReconciler) deliveryDegradedCondition(notifier *ssmdv1alpha1.Notifier, previous []ssmdv1alpha1.DestinationMetrics) *metav1.Condition {
+	if notifier.Status.Phase != ssmdv1alpha1.NotifierPhaseRunning {
+		return nil
+	}
+
+	threshold := int64(defaultDeliveryDegradedPercent)
+	if notifier.Spec.DeliveryDegradedPercent != nil {
+		threshold = int64(*notifier.Spec.DeliveryDegradedPercent)
+	}
+
+	var degraded []string
+	attempted := false
+	for _, current := range notifier.Status.DestinationMetrics {
+		var before ssmdv1alpha1.DestinationMetrics
+		for _, p := range previous {
+			if p.Name == current.Name {
+				before = p
+			}
+		}
+		sent, failed := int64(current.Sent-before.Sent), int64(current.Failed-before.Failed)
+		if sent < 0 || failed < 0 {
+			sent, failed = int64(current.Sent), int64(current.Failed)
+		}
+		if sent+failed == 0 {
+			continue
+		}
+		attempted = true
+		if failed*100 >= threshold*(sent+failed) {
+			degraded = append(degraded, fmt.Sprintf("%s (%d of %d failed)", current.Name, failed, sent+failed))
+		}
+	}
+	if !attempted {
+		return nil
+	}
+
+	condition := &metav1.Condition{
+		Type:               "DeliveryDegraded",
+		Status:             metav1.ConditionFalse,
+		Reason:             "Delivering",
+		Message:            "Notifications are being delivered",
+		LastTransitionTime: metav1.Now(),
+	}
+	if len(degraded) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "FailureRateHigh"
+		condition.Message = fmt.Sprintf("Failing destinations: %s", strings.Join(degraded, ", "))
+	}
+	return condition
+}
+
 // deploymentName returns the Deployment name for a Notifier
 func (r *NotifierReconciler) deploymentName(notifier *ssmdv1alpha1.Notifier) string {
 	return notifier.Name
@@ -470,6 +660,7 @@ func (r *NotifierReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&ssmdv1alpha1.Notifier{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.ConfigMap{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Named("notifier").
 		Complete(r)
 }
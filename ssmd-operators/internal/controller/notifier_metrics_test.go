@@ -0,0 +1,204 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+const testNotifierMetrics = `# HELP ssmd_notifier_fires_received_total Signal fires received from NATS
+# TYPE ssmd_notifier_fires_received_total counter
+ssmd_notifier_fires_received_total 57
+# HELP ssmd_notifier_notifications_sent_total Notifications delivered
+# TYPE ssmd_notifier_notifications_sent_total counter
+ssmd_notifier_notifications_sent_total{destination="ntfy"} 40
+ssmd_notifier_notifications_sent_total{destination="oncall"} 5
+# HELP ssmd_notifier_notifications_failed_total Notifications that failed to deliver
+# TYPE ssmd_notifier_notifications_failed_total counter
+ssmd_notifier_notifications_failed_total{destination="ntfy"} 2
+ssmd_notifier_notifications_failed_total{destination="oncall"} 5
+# HELP ssmd_notifier_last_sent_timestamp Last successful delivery
+# TYPE ssmd_notifier_last_sent_timestamp gauge
+ssmd_notifier_last_sent_timestamp{destination="ntfy"} 1767225600
+`
+
+// newNotifierMetricsTestReconciler builds a NotifierReconciler backed by a fake
+// client holding the given objects, with scrape returning the given metrics text.
+func newNotifierMetricsTestReconciler(t *testing.T, text string, scrapeErr error, objs ...runtime.Object) *NotifierReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	return &NotifierReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		Scheme: scheme,
+		scrape: func(ctx context.Context, url string) (metricFamilies, error) {
+			if scrapeErr != nil {
+				return nil, scrapeErr
+			}
+			return parseTestMetrics(t, text), nil
+		},
+	}
+}
+
+func newNotifierPod(phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "alerts-0",
+			Namespace: "ssmd",
+			Labels: map[string]string{
+				"app.kubernetes.io/name":     "ssmd-notifier",
+				"app.kubernetes.io/instance": "alerts",
+			},
+		},
+		Status: corev1.PodStatus{Phase: phase, PodIP: "10.0.0.2"},
+	}
+}
+
+func newMetricsTestNotifier() *ssmdv1alpha1.Notifier {
+	notifier := newSecretsTestNotifier(
+		ssmdv1alpha1.NotifierDestination{Name: "ntfy", Type: "ntfy"},
+		ssmdv1alpha1.NotifierDestination{Name: "oncall", Type: "ntfy"},
+	)
+	notifier.Status.Phase = ssmdv1alpha1.NotifierPhaseRunning
+	notifier.Status.DestinationMetrics = []ssmdv1alpha1.DestinationMetrics{{Name: "ntfy"}, {Name: "oncall"}}
+	return notifier
+}
+
+// --- TestParseNotifierMetrics ---
+
+func TestParseNotifierMetrics(t *testing.T) {
+	m := parseNotifierMetrics(parseTestMetrics(t, testNotifierMetrics))
+
+	if m.FiresReceived != 57 {
+		t.Errorf("expected 57 fires, got %d", m.FiresReceived)
+	}
+	if m.Sent["ntfy"] != 40 || m.Failed["ntfy"] != 2 || m.Sent["oncall"] != 5 || m.Failed["oncall"] != 5 {
+		t.Errorf("unexpected counters sent=%v failed=%v", m.Sent, m.Failed)
+	}
+	if !m.LastSentAt["ntfy"].Equal(time.Unix(1767225600, 0)) {
+		t.Errorf("unexpected last sent %v", m.LastSentAt)
+	}
+	if _, ok := m.LastSentAt["oncall"]; ok {
+		t.Error("expected no last sent time for oncall")
+	}
+}
+
+// --- TestNotifierUpdateMetricsStatus ---
+
+func TestNotifierUpdateMetricsStatus(t *testing.T) {
+	r := newNotifierMetricsTestReconciler(t, testNotifierMetrics, nil, newNotifierPod(corev1.PodRunning))
+	notifier := newMetricsTestNotifier()
+
+	if err := r.updateMetricsStatus(context.Background(), notifier); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if notifier.Status.FiresReceived != 57 {
+		t.Errorf("expected 57 fires, got %d", notifier.Status.FiresReceived)
+	}
+	ntfy := notifier.Status.DestinationMetrics[0]
+	if ntfy.Sent != 40 || ntfy.Failed != 2 || ntfy.LastSentAt == nil {
+		t.Errorf("unexpected ntfy metrics %+v", ntfy)
+	}
+}
+
+func TestNotifierUpdateMetricsStatus_ScrapeFailureKeepsPrevious(t *testing.T) {
+	r := newNotifierMetricsTestReconciler(t, "", errors.New("connection refused"), newNotifierPod(corev1.PodRunning))
+	notifier := newMetricsTestNotifier()
+	notifier.Status.DestinationMetrics[0].Sent = 12
+
+	if err := r.updateMetricsStatus(context.Background(), notifier); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if notifier.Status.DestinationMetrics[0].Sent != 12 {
+		t.Errorf("expected previous count to be kept, got %d", notifier.Status.DestinationMetrics[0].Sent)
+	}
+}
+
+// --- TestDeliveryDegradedCondition ---
+
+func TestDeliveryDegradedCondition_FailureRateHigh(t *testing.T) {
+	r := &NotifierReconciler{}
+	notifier := newMetricsTestNotifier()
+	previous := []ssmdv1alpha1.DestinationMetrics{{Name: "ntfy", Sent: 30, Failed: 2}, {Name: "oncall", Sent: 4, Failed: 1}}
+	notifier.Status.DestinationMetrics = []ssmdv1alpha1.DestinationMetrics{{Name: "ntfy", Sent: 40, Failed: 2}, {Name: "oncall", Sent: 5, Failed: 5}}
+
+	condition := r.deliveryDegradedCondition(notifier, previous)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("expected DeliveryDegraded=True, got %+v", condition)
+	}
+	if !strings.Contains(condition.Message, "oncall (4 of 5 failed)") || strings.Contains(condition.Message, "ntfy") {
+		t.Errorf("unexpected message %q", condition.Message)
+	}
+}
+
+func TestDeliveryDegradedCondition_CustomThreshold(t *testing.T) {
+	r := &NotifierReconciler{}
+	notifier := newMetricsTestNotifier()
+	notifier.Spec.DeliveryDegradedPercent = int32Ptr(90)
+	notifier.Status.DestinationMetrics = []ssmdv1alpha1.DestinationMetrics{{Name: "ntfy", Sent: 1, Failed: 4}}
+
+	condition := r.deliveryDegradedCondition(notifier, nil)
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		t.Errorf("expected DeliveryDegraded=False below 90%%, got %+v", condition)
+	}
+}
+
+func TestDeliveryDegradedCondition_CounterReset(t *testing.T) {
+	r := &NotifierReconciler{}
+	notifier := newMetricsTestNotifier()
+	previous := []ssmdv1alpha1.DestinationMetrics{{Name: "ntfy", Sent: 100, Failed: 0}}
+	notifier.Status.DestinationMetrics = []ssmdv1alpha1.DestinationMetrics{{Name: "ntfy", Sent: 1, Failed: 3}}
+
+	condition := r.deliveryDegradedCondition(notifier, previous)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Errorf("expected restarted pod's counters to count in full, got %+v", condition)
+	}
+}
+
+func TestDeliveryDegradedCondition_NoNewDeliveries(t *testing.T) {
+	r := &NotifierReconciler{}
+	notifier := newMetricsTestNotifier()
+	notifier.Status.DestinationMetrics = []ssmdv1alpha1.DestinationMetrics{{Name: "ntfy", Sent: 5, Failed: 5}}
+
+	if condition := r.deliveryDegradedCondition(notifier, notifier.Status.DestinationMetrics); condition != nil {
+		t.Errorf("expected no condition without new deliveries, got %+v", condition)
+	}
+}
+
+func TestDeliveryDegradedCondition_NotRunning(t *testing.T) {
+	r := &NotifierReconciler{}
+	notifier := newMetricsTestNotifier()
+	notifier.Status.Phase = ssmdv1alpha1.NotifierPhasePending
+	notifier.Status.DestinationMetrics = []ssmdv1alpha1.DestinationMetrics{{Name: "ntfy", Failed: 5}}
+
+	if condition := r.deliveryDegradedCondition(notifier, nil); condition != nil {
+		t.Errorf("expected no condition when not running, got %+v", condition)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// destinationSecretKeys returns the keys a destination reads from its secretRef
+func destinationSecretKeys(dest ssmdv1alpha1.NotifierDestination) []string {
+	var keys []string
+	if dest.Slack != nil && dest.Slack.WebhookURLField != "" {
+		keys = append(keys, dest.Slack.WebhookURLField)
+	}
+	if dest.PagerDuty != nil {
+		routingKeyField := dest.PagerDuty.RoutingKeyField
+		if routingKeyField == "" {
+			routingKeyField = "routing-key"
+		}
+		keys = append(keys, routingKeyField)
+	}
+	if dest.Webhook != nil {
+		headers := make([]string, 0, len(dest.Webhook.SecretHeaders))
+		for header := range dest.Webhook.SecretHeaders {
+			headers = append(headers, header)
+		}
+		sort.Strings(headers)
+		for _, header := range headers {
+			keys = append(keys, dest.Webhook.SecretHeaders[header])
+		}
+	}
+	if dest.Email != nil {
+		for _, key := range []string{dest.Email.UsernameField, dest.Email.PasswordField} {
+			if key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// missingSecretKeys describes each destination secret or key that does not exist
+func (r *NotifierReconciler) missingSecretKeys(ctx context.Context, notifier *ssmdv1alpha1.Notifier) ([]string, error) {
+	var missing []string
+	for _, dest := range notifier.Spec.Destinations {
+		keys := destinationSecretKeys(dest)
+		if len(keys) == 0 {
+			continue
+		}
+		if dest.SecretRef == nil {
+			missing = append(missing, fmt.Sprintf("destination %s: secretRef is required", dest.Name))
+			continue
+		}
+
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: dest.SecretRef.Name, Namespace: notifier.Namespace}, secret)
+		if errors.IsNotFound(err) {
+			missing = append(missing, fmt.Sprintf("destination %s: secret %s not found", dest.Name, dest.SecretRef.Name))
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if _, ok := secret.Data[key]; !ok {
+				missing = append(missing, fmt.Sprintf("destination %s: secret %s has no key %s", dest.Name, dest.SecretRef.Name, key))
+			}
+		}
+	}
+	return missing, nil
+}
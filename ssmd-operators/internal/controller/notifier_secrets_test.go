@@ -0,0 +1,177 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+func newSecretsTestNotifier(destinations ...ssmdv1alpha1.NotifierDestination) *ssmdv1alpha1.Notifier {
+	return &ssmdv1alpha1.Notifier{
+		ObjectMeta: metav1.ObjectMeta{Name: "alerts", Namespace: "ssmd"},
+		Spec: ssmdv1alpha1.NotifierSpec{
+			Source:       ssmdv1alpha1.NotifierSourceConfig{Subjects: []string{"signals.>"}},
+			Destinations: destinations,
+			Image:        "ghcr.io/aaronwald/ssmd-notifier:0.1.0",
+		},
+	}
+}
+
+func newSecretsTestReconciler(objs ...client.Object) *NotifierReconciler {
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	return &NotifierReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithStatusSubresource(&ssmdv1alpha1.Notifier{}).Build(),
+		Scheme: scheme,
+	}
+}
+
+func pagerDutyDestination() ssmdv1alpha1.NotifierDestination {
+	return ssmdv1alpha1.NotifierDestination{
+		Name:      "oncall",
+		Type:      "pagerduty",
+		PagerDuty: &ssmdv1alpha1.PagerDutyDestination{},
+		SecretRef: &corev1.LocalObjectReference{Name: "pagerduty"},
+	}
+}
+
+// --- TestDestinationSecretKeys ---
+
+func TestDestinationSecretKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		dest ssmdv1alpha1.NotifierDestination
+		want []string
+	}{
+		{"ntfy", ssmdv1alpha1.NotifierDestination{Type: "ntfy"}, nil},
+		{"slack", ssmdv1alpha1.NotifierDestination{Type: "slack", Slack: &ssmdv1alpha1.SlackDestination{WebhookURLField: "url"}}, []string{"url"}},
+		{"pagerduty default", pagerDutyDestination(), []string{"routing-key"}},
+		{"webhook", ssmdv1alpha1.NotifierDestination{Type: "webhook", Webhook: &ssmdv1alpha1.WebhookDestination{
+			SecretHeaders: map[string]string{"X-Token": "token", "Authorization": "auth"},
+		}}, []string{"auth", "token"}},
+		{"email", ssmdv1alpha1.NotifierDestination{Type: "email", Email: &ssmdv1alpha1.EmailDestination{PasswordField: "password"}}, []string{"password"}},
+	}
+	for _, tt := range tests {
+		if got := destinationSecretKeys(tt.dest); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// --- TestMissingSecretKeys ---
+
+func TestMissingSecretKeys(t *testing.T) {
+	noRef := pagerDutyDestination()
+	noRef.Name = "noref"
+	noRef.SecretRef = nil
+	missingSecret := pagerDutyDestination()
+	missingSecret.Name = "gone"
+	missingSecret.SecretRef = &corev1.LocalObjectReference{Name: "gone"}
+	wrongKey := pagerDutyDestination()
+	wrongKey.Name = "wrongkey"
+	wrongKey.PagerDuty.RoutingKeyField = "integration-key"
+
+	r := newSecretsTestReconciler(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pagerduty", Namespace: "ssmd"},
+		Data:       map[string][]byte{"routing-key": []byte("R0UT1NG")},
+	})
+	notifier := newSecretsTestNotifier(pagerDutyDestination(), noRef, missingSecret, wrongKey)
+
+	missing, err := r.missingSecretKeys(context.Background(), notifier)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"destination noref: secretRef is required",
+		"destination gone: secret gone not found",
+		"destination wrongkey: secret pagerduty has no key integration-key",
+	}
+	if !reflect.DeepEqual(missing, want) {
+		t.Errorf("got %v, want %v", missing, want)
+	}
+}
+
+// --- TestNotifierReconcile ---
+
+func TestNotifierReconcile_MissingSecretKeyBlocksRollout(t *testing.T) {
+	notifier := newSecretsTestNotifier(pagerDutyDestination())
+	r := newSecretsTestReconciler(notifier)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "alerts", Namespace: "ssmd"}}
+
+	result, err := r.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Error("expected a requeue to check the secret again")
+	}
+	if err := r.Get(context.Background(), req.NamespacedName, &appsv1.Deployment{}); !errors.IsNotFound(err) {
+		t.Errorf("expected no Deployment, got %v", err)
+	}
+
+	updated := &ssmdv1alpha1.Notifier{}
+	if err := r.Get(context.Background(), req.NamespacedName, updated); err != nil {
+		t.Fatal(err)
+	}
+	condition := meta.FindStatusCondition(updated.Status.Conditions, "SecretsReady")
+	if updated.Status.Phase != ssmdv1alpha1.NotifierPhaseFailed || condition == nil || condition.Status != metav1.ConditionFalse {
+		t.Fatalf("expected Failed phase with SecretsReady=False, got %s %+v", updated.Status.Phase, condition)
+	}
+	if !strings.Contains(condition.Message, "secret pagerduty not found") {
+		t.Errorf("unexpected message %q", condition.Message)
+	}
+
+	// Creating the secret lets the next reconcile roll out the Deployment
+	if err := r.Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pagerduty", Namespace: "ssmd"},
+		Data:       map[string][]byte{"routing-key": []byte("R0UT1NG")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(context.Background(), req.NamespacedName, &appsv1.Deployment{}); err != nil {
+		t.Errorf("expected Deployment, got %v", err)
+	}
+	if err := r.Get(context.Background(), req.NamespacedName, updated); err != nil {
+		t.Fatal(err)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, "SecretsReady") {
+		t.Errorf("expected SecretsReady=True, got %+v", updated.Status.Conditions)
+	}
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+const (
+	// backtestDatasetPath is where a dataset PVC is mounted in the backtest pod
+	backtestDatasetPath = "/dataset"
+
+	// defaultDataAPIURL is the in-cluster data API backtests download datasets from
+	defaultDataAPIURL = "http://ssmd-data-ts-internal.ssmd.svc.cluster.local:8081"
+)
+
+// isBacktest reports whether the Signal runs as a backtest Job
+func isBacktest(signal *ssmdv1alpha1.Signal) bool {
+	return signal.Spec.Mode == ssmdv1alpha1.SignalModeBacktest && signal.Spec.Backtest != nil
+}
+
+// backtestConfig renders the backtest section of signal.yaml
+func backtestConfig(signal *ssmdv1alpha1.Signal) string {
+	backtest := signal.Spec.Backtest
+	dataset := backtest.Dataset
+
+	config := fmt.Sprintf("\nmode: backtest\nbacktest:\n  feed: %s\n  date: %s\n", dataset.Feed, dataset.Date)
+	if dataset.PVCName != "" {
+		config += fmt.Sprintf("  dataset: %s\n", backtestDatasetDir(dataset))
+	} else {
+		dataAPIURL := defaultDataAPIURL
+		if dataset.DataAPI != nil && dataset.DataAPI.URL != "" {
+			dataAPIURL = dataset.DataAPI.URL
+		}
+		config += fmt.Sprintf("  dataApi: %s\n", dataAPIURL)
+	}
+
+	config += "  output:\n"
+	if backtest.Output.Subject != "" {
+		config += fmt.Sprintf("    subject: %s\n", backtest.Output.Subject)
+	}
+	if backtest.Output.GCSPath != "" {
+		config += fmt.Sprintf("    gcsPath: %s\n", backtest.Output.GCSPath)
+	}
+	return config
+}
+
+// backtestDatasetDir returns the dataset directory inside the mounted PVC
+func backtestDatasetDir(dataset ssmdv1alpha1.SignalDatasetConfig) string {
+	if dataset.Path != "" {
+		return path.Join(backtestDatasetPath, dataset.Path)
+	}
+	return path.Join(backtestDatasetPath, dataset.Feed, dataset.Date)
+}
+
+// backtestJobName returns the Job name for a Signal's backtest
+func (r *SignalReconciler) backtestJobName(signal *ssmdv1alpha1.Signal) string {
+	return signal.Name + "-backtest"
+}
+
+// reconcileBacktestJob creates the backtest Job. Job templates are immutable,
+// so a Job built from a different spec is deleted and recreated on the next reconcile.
+func (r *SignalReconciler) reconcileBacktestJob(ctx context.Context, signal *ssmdv1alpha1.Signal) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	jobName := r.backtestJobName(signal)
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Name: jobName, Namespace: signal.Namespace}, job)
+
+	desired := r.constructBacktestJob(signal)
+	if errors.IsNotFound(err) {
+		if err := controllerutil.SetControllerReference(signal, desired, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info("Creating backtest Job", "name", jobName)
+		if err := recordChildEvent(r.Recorder, signal, eventActionCreate, "Job", jobName, r.Create(ctx, desired)); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if job.Annotations[specHashAnnotation] != desired.Annotations[specHashAnnotation] {
+		log.Info("Backtest spec changed, replacing Job", "name", jobName)
+		if err := recordChildEvent(r.Recorder, signal, eventActionDelete, "Job", jobName, r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground))); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// constructBacktestJob builds the Job that runs the signals once over the dataset
+func (r *SignalReconciler) constructBacktestJob(signal *ssmdv1alpha1.Signal) *batchv1.Job {
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "ssmd-signal-backtest",
+		"app.kubernetes.io/instance":   signal.Name,
+		"app.kubernetes.io/managed-by": "ssmd-operator",
+	}
+	dataset := signal.Spec.Backtest.Dataset
+
+	container := corev1.Container{
+		Name:  "signal-runner",
+		Image: signal.Spec.Image,
+		Args: []string{
+			"run",
+			"--allow-net",
+			"--allow-env",
+			"--allow-read",
+			"src/cli/commands/signal-runner.ts",
+			"--config", "/config/signal.yaml",
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "config", MountPath: "/config", ReadOnly: true},
+		},
+	}
+	container.Resources = containerResources(signal.Spec.Resources, r.DefaultResources)
+	if dataset.DataAPI != nil && dataset.DataAPI.APIKeySecretRef != nil {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:      "SSMD_API_KEY",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: dataset.DataAPI.APIKeySecretRef},
+		})
+	}
+
+	volumes := []corev1.Volume{{
+		Name: "config",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: r.configMapName(signal)},
+				DefaultMode:          int32Ptr(420),
+			},
+		},
+	}}
+	if dataset.PVCName != "" {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name: "dataset", MountPath: backtestDatasetPath, ReadOnly: true,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: "dataset",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: dataset.PVCName,
+					ReadOnly:  true,
+				},
+			},
+		})
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.backtestJobName(signal),
+			Namespace: signal.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			// A backtest is deterministic; rerunning a failed one only repeats the failure
+			BackoffLimit: int32Ptr(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					PriorityClassName: priorityClassName(signal.Spec.PriorityClassName, r.DefaultPriorityClassName),
+					RestartPolicy:     corev1.RestartPolicyNever,
+					Containers:        []corev1.Container{container},
+					Volumes:           volumes,
+				},
+			},
+		},
+	}
+
+	applyPodTemplate(&job.Spec.Template, signal.Spec.PodTemplate)
+	metav1.SetMetaDataAnnotation(&job.ObjectMeta, specHashAnnotation, specHash(&job.Spec))
+	return job
+}
+
+// deleteInactiveWorkload removes the Deployment or backtest Job left over from
+// the Signal's other mode
+func (r *SignalReconciler) deleteInactiveWorkload(ctx context.Context, signal *ssmdv1alpha1.Signal) error {
+	var obj client.Object = &appsv1.Deployment{}
+	kind, name := "Deployment", r.deploymentName(signal)
+	if !isBacktest(signal) {
+		obj = &batchv1.Job{}
+		kind, name = "Job", r.backtestJobName(signal)
+	}
+
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: signal.Namespace}, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	logf.FromContext(ctx).Info("Deleting workload from previous mode", "name", name, "mode", signal.Spec.Mode)
+	if err := recordChildEvent(r.Recorder, signal, eventActionDelete, kind, name, r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground))); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// updateBacktestStatus sets the Signal phase and Ready condition from its backtest Job
+func (r *SignalReconciler) updateBacktestStatus(ctx context.Context, signal *ssmdv1alpha1.Signal) (metav1.Condition, error) {
+	signal.Status.Deployment = ""
+	condition := metav1.Condition{
+		Type:               "Ready",
+		Status:             metav1.ConditionFalse,
+		Reason:             "BacktestPending",
+		Message:            "Backtest Job has not started",
+		LastTransitionTime: metav1.Now(),
+	}
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Name: r.backtestJobName(signal), Namespace: signal.Namespace}, job)
+	if errors.IsNotFound(err) {
+		signal.Status.Phase = ssmdv1alpha1.SignalPhasePending
+		signal.Status.Job = ""
+		return condition, nil
+	} else if err != nil {
+		return condition, err
+	}
+	signal.Status.Job = job.Name
+
+	dataset := signal.Spec.Backtest.Dataset
+	switch {
+	case job.Status.Succeeded > 0:
+		signal.Status.Phase = ssmdv1alpha1.SignalPhaseSucceeded
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "BacktestComplete"
+		condition.Message = fmt.Sprintf("Backtest of %s %s complete", dataset.Feed, dataset.Date)
+	case job.Status.Failed > 0:
+		signal.Status.Phase = ssmdv1alpha1.SignalPhaseFailed
+		condition.Reason = "BacktestFailed"
+		condition.Message = fmt.Sprintf("Backtest Job %s failed", job.Name)
+	case job.Status.Active > 0:
+		signal.Status.Phase = ssmdv1alpha1.SignalPhaseRunning
+		condition.Reason = "BacktestRunning"
+		condition.Message = fmt.Sprintf("Replaying %s %s", dataset.Feed, dataset.Date)
+	default:
+		signal.Status.Phase = ssmdv1alpha1.SignalPhasePending
+	}
+	return condition, nil
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+func newBacktestSignal(dataset ssmdv1alpha1.SignalDatasetConfig, output ssmdv1alpha1.SignalBacktestOutput) *ssmdv1alpha1.Signal {
+	signal := newTestSignal(ssmdv1alpha1.SignalConfig{Name: "volume-1m-5min"})
+	signal.Spec.Mode = ssmdv1alpha1.SignalModeBacktest
+	signal.Spec.Backtest = &ssmdv1alpha1.SignalBacktestConfig{Dataset: dataset, Output: output}
+	return signal
+}
+
+func newSignalTestReconciler(objs ...client.Object) *SignalReconciler {
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+
+	return &SignalReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithStatusSubresource(&ssmdv1alpha1.Signal{}).Build(),
+		Scheme: scheme,
+	}
+}
+
+// --- TestBacktestConfig ---
+
+func TestBacktestConfig_DataAPI(t *testing.T) {
+	r := &SignalReconciler{}
+	signal := newBacktestSignal(
+		ssmdv1alpha1.SignalDatasetConfig{Feed: "kalshi", Date: "2026-01-05"},
+		ssmdv1alpha1.SignalBacktestOutput{Subject: "backtests.volume"},
+	)
+
+	want := `
+mode: backtest
+backtest:
+  feed: kalshi
+  date: 2026-01-05
+  dataApi: http://ssmd-data-ts-internal.ssmd.svc.cluster.local:8081
+  output:
+    subject: backtests.volume
+`
+	if config := r.constructConfigMap(signal).Data["signal.yaml"]; !strings.HasSuffix(config, want) {
+		t.Errorf("expected backtest section:\n%s\ngot:\n%s", want, config)
+	}
+}
+
+func TestBacktestConfig_PVC(t *testing.T) {
+	signal := newBacktestSignal(
+		ssmdv1alpha1.SignalDatasetConfig{Feed: "kalshi", Date: "2026-01-05", PVCName: "archiver-data"},
+		ssmdv1alpha1.SignalBacktestOutput{GCSPath: "gs://ssmd-backtests/volume/"},
+	)
+
+	config := backtestConfig(signal)
+	if !strings.Contains(config, "  dataset: /dataset/kalshi/2026-01-05\n") || !strings.Contains(config, "    gcsPath: gs://ssmd-backtests/volume/\n") {
+		t.Errorf("unexpected backtest config:\n%s", config)
+	}
+
+	signal.Spec.Backtest.Dataset.Path = "ssmd/kalshi/2026-01-05"
+	if config := backtestConfig(signal); !strings.Contains(config, "  dataset: /dataset/ssmd/kalshi/2026-01-05\n") {
+		t.Errorf("expected explicit dataset path, got:\n%s", config)
+	}
+}
+
+func TestBacktestConfig_LiveModeOmitted(t *testing.T) {
+	r := &SignalReconciler{}
+	if config := r.constructConfigMap(newTestSignal(ssmdv1alpha1.SignalConfig{Name: "momentum"})).Data["signal.yaml"]; strings.Contains(config, "backtest") {
+		t.Errorf("expected no backtest section in live mode, got:\n%s", config)
+	}
+}
+
+// --- TestConstructBacktestJob ---
+
+func TestConstructBacktestJob_PVC(t *testing.T) {
+	r := &SignalReconciler{}
+	job := r.constructBacktestJob(newBacktestSignal(
+		ssmdv1alpha1.SignalDatasetConfig{Feed: "kalshi", Date: "2026-01-05", PVCName: "archiver-data"},
+		ssmdv1alpha1.SignalBacktestOutput{Subject: "backtests.volume"},
+	))
+
+	if job.Name != "kalshi-volume-backtest" || *job.Spec.BackoffLimit != 0 {
+		t.Errorf("unexpected job %s backoffLimit %d", job.Name, *job.Spec.BackoffLimit)
+	}
+	pod := job.Spec.Template.Spec
+	if pod.RestartPolicy != corev1.RestartPolicyNever {
+		t.Errorf("expected RestartPolicy Never, got %s", pod.RestartPolicy)
+	}
+	var claim *corev1.PersistentVolumeClaimVolumeSource
+	for _, v := range pod.Volumes {
+		if v.Name == "dataset" {
+			claim = v.PersistentVolumeClaim
+		}
+	}
+	if claim == nil || claim.ClaimName != "archiver-data" || !claim.ReadOnly {
+		t.Errorf("expected read-only dataset PVC, got %+v", pod.Volumes)
+	}
+	if job.Annotations[specHashAnnotation] == "" {
+		t.Error("expected spec hash annotation")
+	}
+}
+
+func TestConstructBacktestJob_DataAPIKey(t *testing.T) {
+	r := &SignalReconciler{}
+	job := r.constructBacktestJob(newBacktestSignal(
+		ssmdv1alpha1.SignalDatasetConfig{Feed: "kalshi", Date: "2026-01-05", DataAPI: &ssmdv1alpha1.SignalDataAPIConfig{
+			APIKeySecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ssmd-api"}, Key: "key"},
+		}},
+		ssmdv1alpha1.SignalBacktestOutput{Subject: "backtests.volume"},
+	))
+
+	env := job.Spec.Template.Spec.Containers[0].Env
+	if len(env) != 1 || env[0].Name != "SSMD_API_KEY" || env[0].ValueFrom.SecretKeyRef.Name != "ssmd-api" {
+		t.Errorf("expected SSMD_API_KEY from secret, got %+v", env)
+	}
+	if len(job.Spec.Template.Spec.Volumes) != 1 {
+		t.Errorf("expected only the config volume, got %+v", job.Spec.Template.Spec.Volumes)
+	}
+}
+
+// --- TestSignalReconcile_Backtest ---
+
+func TestSignalReconcile_BacktestRunsJob(t *testing.T) {
+	signal := newBacktestSignal(
+		ssmdv1alpha1.SignalDatasetConfig{Feed: "kalshi", Date: "2026-01-05"},
+		ssmdv1alpha1.SignalBacktestOutput{Subject: "backtests.volume"},
+	)
+	live := &appsv1.Deployment{}
+	live.Name, live.Namespace = "kalshi-volume", "ssmd"
+	r := newSignalTestReconciler(signal, live)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "kalshi-volume", Namespace: "ssmd"}}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(context.Background(), req.NamespacedName, &appsv1.Deployment{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the live Deployment to be removed, got %v", err)
+	}
+	job := &batchv1.Job{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "kalshi-volume-backtest", Namespace: "ssmd"}, job); err != nil {
+		t.Fatalf("expected backtest Job, got %v", err)
+	}
+
+	// The Job completing marks the Signal Succeeded
+	job.Status.Succeeded = 1
+	if err := r.Status().Update(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	updated := &ssmdv1alpha1.Signal{}
+	if err := r.Get(context.Background(), req.NamespacedName, updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status.Phase != ssmdv1alpha1.SignalPhaseSucceeded || updated.Status.Job != "kalshi-volume-backtest" {
+		t.Errorf("expected Succeeded with job name, got %s %q", updated.Status.Phase, updated.Status.Job)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, "Ready") {
+		t.Errorf("expected Ready=True, got %+v", updated.Status.Conditions)
+	}
+}
+
+func TestSignalReconcile_BacktestSpecChangeReplacesJob(t *testing.T) {
+	signal := newBacktestSignal(
+		ssmdv1alpha1.SignalDatasetConfig{Feed: "kalshi", Date: "2026-01-05"},
+		ssmdv1alpha1.SignalBacktestOutput{Subject: "backtests.volume"},
+	)
+	r := newSignalTestReconciler(signal)
+	stale := r.constructBacktestJob(signal)
+	stale.Annotations[specHashAnnotation] = "stale"
+	if err := r.Create(context.Background(), stale); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := r.reconcileBacktestJob(context.Background(), signal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Error("expected a requeue to recreate the Job")
+	}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: stale.Name, Namespace: "ssmd"}, &batchv1.Job{}); !errors.IsNotFound(err) {
+		t.Errorf("expected stale Job to be deleted, got %v", err)
+	}
+}
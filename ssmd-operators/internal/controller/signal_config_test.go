@@ -0,0 +1,149 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+func newTestSignal(signals ...ssmdv1alpha1.SignalConfig) *ssmdv1alpha1.Signal {
+	return &ssmdv1alpha1.Signal{
+		ObjectMeta: metav1.ObjectMeta{Name: "kalshi-volume", Namespace: "ssmd"},
+		Spec: ssmdv1alpha1.SignalSpec{
+			Signals: signals,
+			Source:  ssmdv1alpha1.SignalSourceConfig{Stream: "PROD_KALSHI"},
+			Image:   "ghcr.io/aaronwald/ssmd-signal-runner:0.1.1",
+		},
+	}
+}
+
+// --- TestConstructConfigMap ---
+
+func TestConstructConfigMap_SignalDefaults(t *testing.T) {
+	r := &SignalReconciler{}
+	cm := r.constructConfigMap(newTestSignal(ssmdv1alpha1.SignalConfig{Name: "volume-1m-30min"}))
+
+	if !strings.Contains(cm.Data["signal.yaml"], "\nsignals:\n  - name: volume-1m-30min\n\noutput:\n") {
+		t.Errorf("expected a bare signal entry, got:\n%s", cm.Data["signal.yaml"])
+	}
+}
+
+func TestConstructConfigMap_SignalOverrides(t *testing.T) {
+	r := &SignalReconciler{}
+	cm := r.constructConfigMap(newTestSignal(ssmdv1alpha1.SignalConfig{
+		Name:          "momentum",
+		Params:        map[string]string{"threshold": "0.05", "minTrades": "3"},
+		Windows:       []string{"1m", "5m"},
+		OutputSubject: "signals.kalshi.momentum.fast",
+	}))
+
+	want := `signals:
+  - name: momentum
+    params:
+      minTrades: "3"
+      threshold: "0.05"
+    windows:
+      - 1m
+      - 5m
+    outputSubject: signals.kalshi.momentum.fast
+`
+	if !strings.Contains(cm.Data["signal.yaml"], want) {
+		t.Errorf("expected signal overrides:\n%s\ngot:\n%s", want, cm.Data["signal.yaml"])
+	}
+}
+
+// --- TestSignalConfig ---
+
+func TestSignalConfig_UnmarshalLegacyString(t *testing.T) {
+	var spec ssmdv1alpha1.SignalSpec
+	if err := json.Unmarshal([]byte(`{"signals":["volume-1m-5min",{"name":"momentum","windows":["1m"]}]}`), &spec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []ssmdv1alpha1.SignalConfig{{Name: "volume-1m-5min"}, {Name: "momentum", Windows: []string{"1m"}}}
+	if !reflect.DeepEqual(spec.Signals, want) {
+		t.Errorf("got %+v, want %+v", spec.Signals, want)
+	}
+}
+
+// --- TestSignalCRD ---
+
+// validateSignalSpec checks a spec against the generated Signal CRD schema, as
+// the API server does on create
+func validateSignalSpec(t *testing.T, spec string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "config", "crd", "bases", "ssmd.ssmd.io_signals.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var crd apiextensionsv1.CustomResourceDefinition
+	if err := yaml.Unmarshal(data, &crd); err != nil {
+		t.Fatal(err)
+	}
+	var schema apiextensions.JSONSchemaProps
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(
+		crd.Spec.Versions[0].Schema.OpenAPIV3Schema, &schema, nil); err != nil {
+		t.Fatal(err)
+	}
+	validator, _, err := validation.NewSchemaValidator(&schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var obj map[string]any
+	if err := yaml.Unmarshal([]byte("apiVersion: ssmd.ssmd.io/v1alpha1\nkind: Signal\nspec:\n"+spec), &obj); err != nil {
+		t.Fatal(err)
+	}
+	var errs []string
+	for _, e := range validation.ValidateCustomResource(nil, obj, validator) {
+		errs = append(errs, e.Error())
+	}
+	return errs
+}
+
+func TestSignalCRD_KnownSignalName(t *testing.T) {
+	errs := validateSignalSpec(t, `
+  image: ghcr.io/aaronwald/ssmd-signal-runner:0.1.1
+  source: {stream: PROD_KALSHI}
+  signals: [{name: momentum, windows: [1m, 5m]}]
+`)
+	if len(errs) != 0 {
+		t.Errorf("expected a valid spec, got %v", errs)
+	}
+}
+
+func TestSignalCRD_RejectsUnknownSignalName(t *testing.T) {
+	errs := validateSignalSpec(t, `
+  image: ghcr.io/aaronwald/ssmd-signal-runner:0.1.1
+  source: {stream: PROD_KALSHI}
+  signals: [{name: momentum}, {name: not-a-signal}]
+`)
+	if len(errs) != 1 || !strings.Contains(errs[0], "spec.signals[1].name") || !strings.Contains(errs[0], "Unsupported value") {
+		t.Errorf("expected spec.signals[1].name to be rejected, got %v", errs)
+	}
+}
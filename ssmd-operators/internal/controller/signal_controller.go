@@ -19,17 +19,20 @@ package controller
 import (
 	"context"
 	"fmt"
-	"reflect"
+	"sort"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -44,12 +47,26 @@ const (
 type SignalReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events for reconcile actions (skipped if nil)
+	Recorder events.EventRecorder
+
+	// MaxConcurrentReconciles is the number of parallel reconciles (1 if unset)
+	MaxConcurrentReconciles int
+
+	// DefaultPriorityClassName is used for pods whose CR does not set priorityClassName
+	DefaultPriorityClassName string
+
+	// DefaultResources are used for pods whose CR does not set resources
+	DefaultResources *corev1.ResourceRequirements
 }
 
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=signals,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=signals/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=signals/finalizers,verbs=update
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile moves the cluster state toward the desired state for a Signal
@@ -85,8 +102,19 @@ func (r *SignalReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
-	// Reconcile the Deployment
-	result, err := r.reconcileDeployment(ctx, signal)
+	// Remove the Deployment or backtest Job of the mode not in use
+	if err := r.deleteInactiveWorkload(ctx, signal); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Reconcile the backtest Job or the live Deployment
+	var result ctrl.Result
+	var err error
+	if isBacktest(signal) {
+		result, err = r.reconcileBacktestJob(ctx, signal)
+	} else {
+		result, err = r.reconcileDeployment(ctx, signal)
+	}
 	if err != nil {
 		return result, err
 	}
@@ -96,7 +124,7 @@ func (r *SignalReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return result, nil
 }
 
 // reconcileDelete handles cleanup when the Signal is deleted
@@ -110,17 +138,27 @@ func (r *SignalReconciler) reconcileDelete(ctx context.Context, signal *ssmdv1al
 		deploymentName := r.deploymentName(signal)
 		deployment := &appsv1.Deployment{}
 		if err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: signal.Namespace}, deployment); err == nil {
-			if err := r.Delete(ctx, deployment); err != nil && !errors.IsNotFound(err) {
+			if err := recordChildEvent(r.Recorder, signal, eventActionDelete, "Deployment", deploymentName, r.Delete(ctx, deployment)); err != nil && !errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
 			log.Info("Deleted Deployment", "name", deploymentName)
 		}
 
+		// Delete the backtest Job and its pods
+		jobName := r.backtestJobName(signal)
+		job := &batchv1.Job{}
+		if err := r.Get(ctx, types.NamespacedName{Name: jobName, Namespace: signal.Namespace}, job); err == nil {
+			if err := recordChildEvent(r.Recorder, signal, eventActionDelete, "Job", jobName, r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))); err != nil && !errors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+			log.Info("Deleted backtest Job", "name", jobName)
+		}
+
 		// Delete the ConfigMap
 		configMapName := r.configMapName(signal)
 		configMap := &corev1.ConfigMap{}
 		if err := r.Get(ctx, types.NamespacedName{Name: configMapName, Namespace: signal.Namespace}, configMap); err == nil {
-			if err := r.Delete(ctx, configMap); err != nil && !errors.IsNotFound(err) {
+			if err := recordChildEvent(r.Recorder, signal, eventActionDelete, "ConfigMap", configMapName, r.Delete(ctx, configMap)); err != nil && !errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
 			log.Info("Deleted ConfigMap", "name", configMapName)
@@ -151,7 +189,7 @@ func (r *SignalReconciler) reconcileConfigMap(ctx context.Context, signal *ssmdv
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating ConfigMap", "name", configMapName)
-		if err := r.Create(ctx, desiredConfigMap); err != nil {
+		if err := recordChildEvent(r.Recorder, signal, eventActionCreate, "ConfigMap", configMapName, r.Create(ctx, desiredConfigMap)); err != nil {
 			return ctrl.Result{}, err
 		}
 	} else if err != nil {
@@ -161,7 +199,7 @@ func (r *SignalReconciler) reconcileConfigMap(ctx context.Context, signal *ssmdv
 		if configMap.Data["signal.yaml"] != desiredConfigMap.Data["signal.yaml"] {
 			configMap.Data = desiredConfigMap.Data
 			log.Info("Updating ConfigMap", "name", configMapName)
-			if err := r.Update(ctx, configMap); err != nil {
+			if err := recordChildEvent(r.Recorder, signal, eventActionUpdate, "ConfigMap", configMapName, r.Update(ctx, configMap)); err != nil {
 				return ctrl.Result{}, err
 			}
 		}
@@ -194,10 +232,30 @@ func (r *SignalReconciler) constructConfigMap(signal *ssmdv1alpha1.Signal) *core
 		signalConfig += fmt.Sprintf("  filter: %s\n", signal.Spec.Source.Filter)
 	}
 
-	// Add signals list
+	// Add signals with their parameter overrides
 	signalConfig += "\nsignals:\n"
 	for _, s := range signal.Spec.Signals {
-		signalConfig += fmt.Sprintf("  - %s\n", s)
+		signalConfig += fmt.Sprintf("  - name: %s\n", s.Name)
+		if len(s.Params) > 0 {
+			signalConfig += "    params:\n"
+			keys := make([]string, 0, len(s.Params))
+			for k := range s.Params {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				signalConfig += fmt.Sprintf("      %s: %q\n", k, s.Params[k])
+			}
+		}
+		if len(s.Windows) > 0 {
+			signalConfig += "    windows:\n"
+			for _, w := range s.Windows {
+				signalConfig += fmt.Sprintf("      - %s\n", w)
+			}
+		}
+		if s.OutputSubject != "" {
+			signalConfig += fmt.Sprintf("    outputSubject: %s\n", s.OutputSubject)
+		}
 	}
 
 	// Add output prefix
@@ -207,6 +265,11 @@ func (r *SignalReconciler) constructConfigMap(signal *ssmdv1alpha1.Signal) *core
 	}
 	signalConfig += fmt.Sprintf("\noutput:\n  prefix: %s\n", outputPrefix)
 
+	// Add the dataset and output of a backtest run
+	if isBacktest(signal) {
+		signalConfig += backtestConfig(signal)
+	}
+
 	// Add filters if specified
 	if len(signal.Spec.Source.Categories) > 0 || len(signal.Spec.Source.Tickers) > 0 {
 		signalConfig += "\nfilters:\n"
@@ -256,7 +319,7 @@ func (r *SignalReconciler) reconcileDeployment(ctx context.Context, signal *ssmd
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating Deployment", "name", deploymentName)
-		if err := r.Create(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, signal, eventActionCreate, "Deployment", deploymentName, r.Create(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -266,10 +329,10 @@ func (r *SignalReconciler) reconcileDeployment(ctx context.Context, signal *ssmd
 
 	// Update existing Deployment if needed
 	desired := r.constructDeployment(signal)
-	if r.deploymentNeedsUpdate(deployment, desired) {
-		deployment.Spec = desired.Spec
+	if deploymentNeedsUpdate(deployment, desired) {
+		updateDeploymentSpec(deployment, desired)
 		log.Info("Updating Deployment", "name", deploymentName)
-		if err := r.Update(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, signal, eventActionUpdate, "Deployment", deploymentName, r.Update(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
@@ -311,12 +374,10 @@ func (r *SignalReconciler) constructDeployment(signal *ssmdv1alpha1.Signal) *app
 		// Health is determined by process running (restartPolicy handles failures)
 	}
 
-	// Add resource requirements if specified
-	if signal.Spec.Resources != nil {
-		container.Resources = *signal.Spec.Resources
-	}
+	// Add resource requirements, falling back to the operator default
+	container.Resources = containerResources(signal.Spec.Resources, r.DefaultResources)
 
-	return &appsv1.Deployment{
+	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.deploymentName(signal),
 			Namespace: signal.Namespace,
@@ -332,8 +393,8 @@ func (r *SignalReconciler) constructDeployment(signal *ssmdv1alpha1.Signal) *app
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
-					Containers:       []corev1.Container{container},
-					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "ghcr-secret"}},
+					PriorityClassName: priorityClassName(signal.Spec.PriorityClassName, r.DefaultPriorityClassName),
+					Containers:        []corev1.Container{container},
 					Volumes: []corev1.Volume{
 						{
 							Name: "config",
@@ -351,47 +412,27 @@ func (r *SignalReconciler) constructDeployment(signal *ssmdv1alpha1.Signal) *app
 			},
 		},
 	}
-}
-
-// deploymentNeedsUpdate checks if the Deployment needs to be updated
-func (r *SignalReconciler) deploymentNeedsUpdate(current, desired *appsv1.Deployment) bool {
-	if len(current.Spec.Template.Spec.Containers) == 0 || len(desired.Spec.Template.Spec.Containers) == 0 {
-		return len(current.Spec.Template.Spec.Containers) != len(desired.Spec.Template.Spec.Containers)
-	}
-
-	currentContainer := &current.Spec.Template.Spec.Containers[0]
-	desiredContainer := &desired.Spec.Template.Spec.Containers[0]
-
-	// Check image
-	if currentContainer.Image != desiredContainer.Image {
-		return true
-	}
-
-	// Check resource requirements (Autopilot-safe: only check desired fields)
-	if !resourcesMatch(currentContainer.Resources, desiredContainer.Resources) {
-		return true
-	}
 
-	// Check volume mounts
-	if !reflect.DeepEqual(currentContainer.VolumeMounts, desiredContainer.VolumeMounts) {
-		return true
-	}
+	applyPodTemplate(&deployment.Spec.Template, signal.Spec.PodTemplate)
+	setSpecHash(deployment)
+	return deployment
+}
 
-	// Check args
-	if !reflect.DeepEqual(currentContainer.Args, desiredContainer.Args) {
-		return true
-	}
+// updateStatus updates the Signal status based on Deployment or backtest Job state
+func (r *SignalReconciler) updateStatus(ctx context.Context, signal *ssmdv1alpha1.Signal) error {
+	previousPhase := signal.Status.Phase
+	defer func() { recordPhaseChange(r.Recorder, signal, previousPhase, signal.Status.Phase) }()
 
-	// Check volumes
-	if !reflect.DeepEqual(current.Spec.Template.Spec.Volumes, desired.Spec.Template.Spec.Volumes) {
-		return true
+	if isBacktest(signal) {
+		condition, err := r.updateBacktestStatus(ctx, signal)
+		if err != nil {
+			return err
+		}
+		meta.SetStatusCondition(&signal.Status.Conditions, condition)
+		return r.Status().Update(ctx, signal)
 	}
+	signal.Status.Job = ""
 
-	return false
-}
-
-// updateStatus updates the Signal status based on Deployment state
-func (r *SignalReconciler) updateStatus(ctx context.Context, signal *ssmdv1alpha1.Signal) error {
 	deploymentName := r.deploymentName(signal)
 	deployment := &appsv1.Deployment{}
 	err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: signal.Namespace}, deployment)
@@ -416,7 +457,7 @@ func (r *SignalReconciler) updateStatus(ctx context.Context, signal *ssmdv1alpha
 			signal.Status.SignalMetrics = make([]ssmdv1alpha1.SignalMetrics, len(signal.Spec.Signals))
 			for i, s := range signal.Spec.Signals {
 				signal.Status.SignalMetrics[i] = ssmdv1alpha1.SignalMetrics{
-					Signal: s,
+					Signal: s.Name,
 				}
 			}
 		}
@@ -450,6 +491,8 @@ func (r *SignalReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&ssmdv1alpha1.Signal{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&batchv1.Job{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Named("signal").
 		Complete(r)
 }
@@ -52,7 +52,7 @@ var _ = Describe("Signal Controller", func() {
 						Namespace: "default",
 					},
 					Spec: ssmdv1alpha1.SignalSpec{
-						Signals: []string{"test-signal-1"},
+						Signals: []ssmdv1alpha1.SignalConfig{{Name: "volume-1m-5min"}},
 						Source: ssmdv1alpha1.SignalSourceConfig{
 							Stream: "TEST_STREAM",
 						},
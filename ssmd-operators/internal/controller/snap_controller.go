@@ -20,18 +20,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"reflect"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -46,12 +49,29 @@ const (
 type SnapReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Recorder emits Kubernetes Events for reconcile actions (skipped if nil)
+	Recorder events.EventRecorder
+
+	// MaxConcurrentReconciles is the number of parallel reconciles (1 if unset)
+	MaxConcurrentReconciles int
+
+	// DefaultPriorityClassName is used for pods whose CR does not set priorityClassName
+	DefaultPriorityClassName string
+
+	// DefaultResources are used for pods whose CR does not set resources
+	DefaultResources *corev1.ResourceRequirements
 }
 
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=snaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=snaps/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=snaps/finalizers,verbs=update
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile moves the cluster state toward the desired state for a Snap
 func (r *SnapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -87,6 +107,23 @@ func (r *SnapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return result, err
 	}
 
+	// Reconcile the PodDisruptionBudget
+	if err := reconcilePodDisruptionBudget(ctx, r.Client, r.Scheme, r.Recorder, snap, snap.Spec.HighAvailability,
+		r.deploymentName(snap), r.constructDeployment(snap).Spec.Selector); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Reconcile the Service, ServiceMonitor and HPA
+	if err := r.reconcileService(ctx, snap); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileServiceMonitor(ctx, snap); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileHPA(ctx, snap); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Update status
 	if err := r.updateStatus(ctx, snap); err != nil {
 		return ctrl.Result{}, err
@@ -106,7 +143,7 @@ func (r *SnapReconciler) reconcileDelete(ctx context.Context, snap *ssmdv1alpha1
 		deploymentName := r.deploymentName(snap)
 		deployment := &appsv1.Deployment{}
 		if err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: snap.Namespace}, deployment); err == nil {
-			if err := r.Delete(ctx, deployment); err != nil && !errors.IsNotFound(err) {
+			if err := recordChildEvent(r.Recorder, snap, eventActionDelete, "Deployment", deploymentName, r.Delete(ctx, deployment)); err != nil && !errors.IsNotFound(err) {
 				return ctrl.Result{}, err
 			}
 			log.Info("Deleted Deployment", "name", deploymentName)
@@ -137,7 +174,7 @@ func (r *SnapReconciler) reconcileDeployment(ctx context.Context, snap *ssmdv1al
 			return ctrl.Result{}, err
 		}
 		log.Info("Creating Deployment", "name", deploymentName)
-		if err := r.Create(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, snap, eventActionCreate, "Deployment", deploymentName, r.Create(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -147,10 +184,15 @@ func (r *SnapReconciler) reconcileDeployment(ctx context.Context, snap *ssmdv1al
 
 	// Update existing Deployment if needed
 	desired := r.constructDeployment(snap)
-	if r.deploymentNeedsUpdate(deployment, desired) {
-		deployment.Spec = desired.Spec
+	if deploymentNeedsUpdate(deployment, desired) {
+		// Leave the replica count to the HPA when autoscaling
+		replicas := deployment.Spec.Replicas
+		updateDeploymentSpec(deployment, desired)
+		if snap.Spec.Autoscaling != nil {
+			deployment.Spec.Replicas = replicas
+		}
 		log.Info("Updating Deployment", "name", deploymentName)
-		if err := r.Update(ctx, deployment); err != nil {
+		if err := recordChildEvent(r.Recorder, snap, eventActionUpdate, "Deployment", deploymentName, r.Update(ctx, deployment)); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
@@ -160,13 +202,13 @@ func (r *SnapReconciler) reconcileDeployment(ctx context.Context, snap *ssmdv1al
 
 // constructDeployment builds the Deployment spec for a Snap
 func (r *SnapReconciler) constructDeployment(snap *ssmdv1alpha1.Snap) *appsv1.Deployment {
-	labels := map[string]string{
-		"app.kubernetes.io/name":       "ssmd-snap",
-		"app.kubernetes.io/instance":   snap.Name,
-		"app.kubernetes.io/managed-by": "ssmd-operator",
-	}
+	labels := snapLabels(snap)
 
+	// With autoscaling the HPA sets replicas; the Deployment starts at minReplicas
 	replicas := int32(1)
+	if snap.Spec.Autoscaling != nil && snap.Spec.Autoscaling.MinReplicas != nil {
+		replicas = *snap.Spec.Autoscaling.MinReplicas
+	}
 
 	// Defaults
 	natsURL := snap.Spec.NatsURL
@@ -190,7 +232,7 @@ func (r *SnapReconciler) constructDeployment(snap *ssmdv1alpha1.Snap) *appsv1.De
 		{Name: "REDIS_URL", Value: redisURL},
 		{Name: "SNAP_SUBSCRIPTIONS", Value: string(subsJSON)},
 		{Name: "SNAP_TTL_SECS", Value: fmt.Sprintf("%d", ttlSecs)},
-		{Name: "SNAP_LISTEN_ADDR", Value: "0.0.0.0:9090"},
+		{Name: "SNAP_LISTEN_ADDR", Value: fmt.Sprintf("0.0.0.0:%d", snapPort)},
 	}
 
 	// Append user-specified env vars
@@ -205,13 +247,13 @@ func (r *SnapReconciler) constructDeployment(snap *ssmdv1alpha1.Snap) *appsv1.De
 		Image: snap.Spec.Image,
 		Env:   env,
 		Ports: []corev1.ContainerPort{
-			{Name: "http", ContainerPort: 9090, Protocol: corev1.ProtocolTCP},
+			{Name: "http", ContainerPort: snapPort, Protocol: corev1.ProtocolTCP},
 		},
 		LivenessProbe: &corev1.Probe{
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
 					Path: "/healthz",
-					Port: intstr.FromInt(9090),
+					Port: intstr.FromInt(snapPort),
 				},
 			},
 			InitialDelaySeconds: 10,
@@ -221,7 +263,7 @@ func (r *SnapReconciler) constructDeployment(snap *ssmdv1alpha1.Snap) *appsv1.De
 			ProbeHandler: corev1.ProbeHandler{
 				HTTPGet: &corev1.HTTPGetAction{
 					Path: "/healthz",
-					Port: intstr.FromInt(9090),
+					Port: intstr.FromInt(snapPort),
 				},
 			},
 			InitialDelaySeconds: 5,
@@ -237,12 +279,10 @@ func (r *SnapReconciler) constructDeployment(snap *ssmdv1alpha1.Snap) *appsv1.De
 		},
 	}
 
-	// Add resource requirements if specified
-	if snap.Spec.Resources != nil {
-		container.Resources = *snap.Spec.Resources
-	}
+	// Add resource requirements, falling back to the operator default
+	container.Resources = containerResources(snap.Spec.Resources, r.DefaultResources)
 
-	return &appsv1.Deployment{
+	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.deploymentName(snap),
 			Namespace: snap.Namespace,
@@ -268,43 +308,24 @@ func (r *SnapReconciler) constructDeployment(snap *ssmdv1alpha1.Snap) *appsv1.De
 					},
 				},
 				Spec: corev1.PodSpec{
-					Containers:       []corev1.Container{container},
-					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "ghcr-secret"}},
+					PriorityClassName: priorityClassName(snap.Spec.PriorityClassName, r.DefaultPriorityClassName),
+					Containers:        []corev1.Container{container},
 				},
 			},
 		},
 	}
-}
-
-// deploymentNeedsUpdate checks if the Deployment needs to be updated
-func (r *SnapReconciler) deploymentNeedsUpdate(current, desired *appsv1.Deployment) bool {
-	if len(current.Spec.Template.Spec.Containers) == 0 || len(desired.Spec.Template.Spec.Containers) == 0 {
-		return len(current.Spec.Template.Spec.Containers) != len(desired.Spec.Template.Spec.Containers)
-	}
-
-	currentContainer := &current.Spec.Template.Spec.Containers[0]
-	desiredContainer := &desired.Spec.Template.Spec.Containers[0]
-
-	// Check image
-	if currentContainer.Image != desiredContainer.Image {
-		return true
-	}
-
-	// Check environment variables
-	if !reflect.DeepEqual(currentContainer.Env, desiredContainer.Env) {
-		return true
-	}
 
-	// Check resource requirements (Autopilot-safe: only check desired fields)
-	if !resourcesMatch(currentContainer.Resources, desiredContainer.Resources) {
-		return true
-	}
-
-	return false
+	applyPodTemplate(&deployment.Spec.Template, snap.Spec.PodTemplate)
+	applyTopologySpread(&deployment.Spec.Template, snap.Spec.HighAvailability, deployment.Spec.Selector)
+	setSpecHash(deployment)
+	return deployment
 }
 
 // updateStatus updates the Snap status based on Deployment state
 func (r *SnapReconciler) updateStatus(ctx context.Context, snap *ssmdv1alpha1.Snap) error {
+	previousPhase := snap.Status.Phase
+	defer func() { recordPhaseChange(r.Recorder, snap, previousPhase, snap.Status.Phase) }()
+
 	deploymentName := r.deploymentName(snap)
 	deployment := &appsv1.Deployment{}
 	err := r.Get(ctx, types.NamespacedName{Name: deploymentName, Namespace: snap.Namespace}, deployment)
@@ -312,10 +333,14 @@ func (r *SnapReconciler) updateStatus(ctx context.Context, snap *ssmdv1alpha1.Sn
 	if errors.IsNotFound(err) {
 		snap.Status.Phase = ssmdv1alpha1.SnapPhasePending
 		snap.Status.Deployment = ""
+		snap.Status.Service = ""
+		snap.Status.Replicas = 0
 	} else if err != nil {
 		return err
 	} else {
 		snap.Status.Deployment = deploymentName
+		snap.Status.Service = r.serviceName(snap)
+		snap.Status.Replicas = deployment.Status.ReadyReplicas
 
 		// Determine phase from Deployment status
 		if deployment.Status.ReadyReplicas > 0 {
@@ -353,6 +378,10 @@ func (r *SnapReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&ssmdv1alpha1.Snap{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&corev1.Service{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Named("snap").
 		Complete(r)
 }
@@ -0,0 +1,305 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+const (
+	// snapPort serves /healthz and /metrics on the snap pod
+	snapPort = 9090
+
+	defaultSnapMessagesMetric = "snap_messages_received_per_second"
+)
+
+// serviceMonitorGVK is the Prometheus Operator ServiceMonitor kind. The operator
+// does not depend on the prometheus-operator API module, so ServiceMonitors are
+// handled as unstructured objects.
+var serviceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+
+// snapLabels returns the labels shared by a Snap's Deployment, Service and ServiceMonitor
+func snapLabels(snap *ssmdv1alpha1.Snap) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       "ssmd-snap",
+		"app.kubernetes.io/instance":   snap.Name,
+		"app.kubernetes.io/managed-by": "ssmd-operator",
+	}
+}
+
+// serviceName returns the Service name for a Snap
+func (r *SnapReconciler) serviceName(snap *ssmdv1alpha1.Snap) string {
+	return snap.Name
+}
+
+// reconcileService ensures the ClusterIP Service in front of the snap pods exists
+func (r *SnapReconciler) reconcileService(ctx context.Context, snap *ssmdv1alpha1.Snap) error {
+	log := logf.FromContext(ctx)
+
+	svcName := r.serviceName(snap)
+	svc := &corev1.Service{}
+	err := r.Get(ctx, types.NamespacedName{Name: svcName, Namespace: snap.Namespace}, svc)
+
+	desired := r.constructService(snap)
+	if errors.IsNotFound(err) {
+		if err := controllerutil.SetControllerReference(snap, desired, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("Creating Service", "name", svcName)
+		return recordChildEvent(r.Recorder, snap, eventActionCreate, "Service", svcName, r.Create(ctx, desired))
+	} else if err != nil {
+		return err
+	}
+
+	if svc.Annotations[specHashAnnotation] != desired.Annotations[specHashAnnotation] {
+		svc.Labels = desired.Labels
+		svc.Spec.Selector = desired.Spec.Selector
+		svc.Spec.Ports = desired.Spec.Ports
+		metav1.SetMetaDataAnnotation(&svc.ObjectMeta, specHashAnnotation, desired.Annotations[specHashAnnotation])
+		log.Info("Updating Service", "name", svcName)
+		return recordChildEvent(r.Recorder, snap, eventActionUpdate, "Service", svcName, r.Update(ctx, svc))
+	}
+	return nil
+}
+
+// constructService builds the Service for a Snap
+func (r *SnapReconciler) constructService(snap *ssmdv1alpha1.Snap) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.serviceName(snap),
+			Namespace: snap.Namespace,
+			Labels:    snapLabels(snap),
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{
+				"app.kubernetes.io/name":     "ssmd-snap",
+				"app.kubernetes.io/instance": snap.Name,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       snapPort,
+					TargetPort: intstr.FromString("http"),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+	// Hash only the fields the controller owns; the API server fills in clusterIP and friends
+	metav1.SetMetaDataAnnotation(&svc.ObjectMeta, specHashAnnotation, specHash(map[string]any{
+		"selector": svc.Spec.Selector,
+		"ports":    svc.Spec.Ports,
+	}))
+	return svc
+}
+
+// reconcileServiceMonitor creates, updates or removes the Snap's ServiceMonitor.
+// Clusters without the Prometheus Operator CRDs are skipped.
+func (r *SnapReconciler) reconcileServiceMonitor(ctx context.Context, snap *ssmdv1alpha1.Snap) error {
+	log := logf.FromContext(ctx)
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(serviceMonitorGVK)
+	err := r.Get(ctx, types.NamespacedName{Name: r.serviceName(snap), Namespace: snap.Namespace}, current)
+	if meta.IsNoMatchError(err) {
+		if snap.Spec.ServiceMonitor != nil && snap.Spec.ServiceMonitor.Enabled {
+			log.Info("ServiceMonitor CRD not installed, skipping", "name", r.serviceName(snap))
+		}
+		return nil
+	}
+	found := err == nil
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if snap.Spec.ServiceMonitor == nil || !snap.Spec.ServiceMonitor.Enabled {
+		if found {
+			log.Info("Deleting ServiceMonitor", "name", current.GetName())
+			return client.IgnoreNotFound(recordChildEvent(r.Recorder, snap, eventActionDelete, "ServiceMonitor", current.GetName(), r.Delete(ctx, current)))
+		}
+		return nil
+	}
+
+	desired := r.constructServiceMonitor(snap)
+	if !found {
+		if err := controllerutil.SetControllerReference(snap, desired, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("Creating ServiceMonitor", "name", desired.GetName())
+		return recordChildEvent(r.Recorder, snap, eventActionCreate, "ServiceMonitor", desired.GetName(), r.Create(ctx, desired))
+	}
+
+	if current.GetAnnotations()[specHashAnnotation] != desired.GetAnnotations()[specHashAnnotation] {
+		current.SetLabels(desired.GetLabels())
+		current.SetAnnotations(desired.GetAnnotations())
+		current.Object["spec"] = desired.Object["spec"]
+		log.Info("Updating ServiceMonitor", "name", current.GetName())
+		return recordChildEvent(r.Recorder, snap, eventActionUpdate, "ServiceMonitor", current.GetName(), r.Update(ctx, current))
+	}
+	return nil
+}
+
+// constructServiceMonitor builds the ServiceMonitor scraping the Snap's Service
+func (r *SnapReconciler) constructServiceMonitor(snap *ssmdv1alpha1.Snap) *unstructured.Unstructured {
+	config := snap.Spec.ServiceMonitor
+	interval := config.Interval
+	if interval == "" {
+		interval = "30s"
+	}
+
+	labels := snapLabels(snap)
+	for k, v := range config.Labels {
+		labels[k] = v
+	}
+	spec := map[string]any{
+		"selector": map[string]any{
+			"matchLabels": map[string]any{
+				"app.kubernetes.io/name":     "ssmd-snap",
+				"app.kubernetes.io/instance": snap.Name,
+			},
+		},
+		"endpoints": []any{
+			map[string]any{"port": "http", "path": "/metrics", "interval": interval},
+		},
+	}
+
+	sm := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	sm.SetGroupVersionKind(serviceMonitorGVK)
+	sm.SetName(r.serviceName(snap))
+	sm.SetNamespace(snap.Namespace)
+	sm.SetLabels(labels)
+	sm.SetAnnotations(map[string]string{specHashAnnotation: specHash(map[string]any{"labels": labels, "spec": spec})})
+	return sm
+}
+
+// hpaName returns the HorizontalPodAutoscaler name for a Snap
+func (r *SnapReconciler) hpaName(snap *ssmdv1alpha1.Snap) string {
+	return snap.Name
+}
+
+// reconcileHPA creates, updates or removes the Snap's HorizontalPodAutoscaler
+func (r *SnapReconciler) reconcileHPA(ctx context.Context, snap *ssmdv1alpha1.Snap) error {
+	log := logf.FromContext(ctx)
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	err := r.Get(ctx, types.NamespacedName{Name: r.hpaName(snap), Namespace: snap.Namespace}, hpa)
+	found := err == nil
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	if snap.Spec.Autoscaling == nil {
+		if found {
+			log.Info("Deleting HorizontalPodAutoscaler", "name", hpa.Name)
+			return client.IgnoreNotFound(recordChildEvent(r.Recorder, snap, eventActionDelete, "HorizontalPodAutoscaler", hpa.Name, r.Delete(ctx, hpa)))
+		}
+		return nil
+	}
+
+	desired := r.constructHPA(snap)
+	if !found {
+		if err := controllerutil.SetControllerReference(snap, desired, r.Scheme); err != nil {
+			return err
+		}
+		log.Info("Creating HorizontalPodAutoscaler", "name", desired.Name)
+		return recordChildEvent(r.Recorder, snap, eventActionCreate, "HorizontalPodAutoscaler", desired.Name, r.Create(ctx, desired))
+	}
+
+	if hpa.Annotations[specHashAnnotation] != desired.Annotations[specHashAnnotation] {
+		hpa.Spec = desired.Spec
+		metav1.SetMetaDataAnnotation(&hpa.ObjectMeta, specHashAnnotation, desired.Annotations[specHashAnnotation])
+		log.Info("Updating HorizontalPodAutoscaler", "name", hpa.Name)
+		return recordChildEvent(r.Recorder, snap, eventActionUpdate, "HorizontalPodAutoscaler", hpa.Name, r.Update(ctx, hpa))
+	}
+	return nil
+}
+
+// constructHPA builds the HorizontalPodAutoscaler targeting the Snap's Deployment
+func (r *SnapReconciler) constructHPA(snap *ssmdv1alpha1.Snap) *autoscalingv2.HorizontalPodAutoscaler {
+	config := snap.Spec.Autoscaling
+	minReplicas := config.MinReplicas
+	if minReplicas == nil {
+		minReplicas = int32Ptr(1)
+	}
+
+	var metrics []autoscalingv2.MetricSpec
+	if config.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: config.TargetCPUUtilizationPercentage,
+				},
+			},
+		})
+	}
+	if config.TargetMessagesPerSecond != nil {
+		metric := config.MessagesPerSecondMetric
+		if metric == "" {
+			metric = defaultSnapMessagesMetric
+		}
+		metrics = append(metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{Name: metric},
+				Target: autoscalingv2.MetricTarget{
+					Type:         autoscalingv2.AverageValueMetricType,
+					AverageValue: resource.NewQuantity(int64(*config.TargetMessagesPerSecond), resource.DecimalSI),
+				},
+			},
+		})
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.hpaName(snap),
+			Namespace: snap.Namespace,
+			Labels:    snapLabels(snap),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       r.deploymentName(snap),
+			},
+			MinReplicas: minReplicas,
+			MaxReplicas: config.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+	metav1.SetMetaDataAnnotation(&hpa.ObjectMeta, specHashAnnotation, specHash(&hpa.Spec))
+	return hpa
+}
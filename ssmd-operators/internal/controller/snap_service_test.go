@@ -0,0 +1,208 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+func newTestSnap() *ssmdv1alpha1.Snap {
+	return &ssmdv1alpha1.Snap{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap-kalshi", Namespace: "ssmd"},
+		Spec: ssmdv1alpha1.SnapSpec{
+			Image: "ghcr.io/aaronwald/ssmd-snap:0.1.0",
+			Subscriptions: []ssmdv1alpha1.SnapSubscription{
+				{Stream: "PROD_KALSHI", Feed: "kalshi", Subject: "prod.kalshi.json.ticker.>"},
+			},
+		},
+	}
+}
+
+func newSnapTestReconciler(objs ...client.Object) *SnapReconciler {
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = autoscalingv2.AddToScheme(scheme)
+	_ = policyv1.AddToScheme(scheme)
+
+	return &SnapReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithStatusSubresource(&ssmdv1alpha1.Snap{}).Build(),
+		Scheme: scheme,
+	}
+}
+
+// --- TestConstructService ---
+
+func TestSnapConstructService(t *testing.T) {
+	r := &SnapReconciler{}
+	svc := r.constructService(newTestSnap())
+
+	if svc.Spec.Type != corev1.ServiceTypeClusterIP {
+		t.Errorf("service type = %s, want ClusterIP", svc.Spec.Type)
+	}
+	if len(svc.Spec.Ports) != 1 || svc.Spec.Ports[0].Port != snapPort || svc.Spec.Ports[0].Name != "http" {
+		t.Errorf("unexpected ports %+v", svc.Spec.Ports)
+	}
+	if svc.Spec.Selector["app.kubernetes.io/instance"] != "snap-kalshi" {
+		t.Errorf("unexpected selector %v", svc.Spec.Selector)
+	}
+}
+
+// --- TestConstructServiceMonitor ---
+
+func TestSnapConstructServiceMonitor(t *testing.T) {
+	r := &SnapReconciler{}
+	snap := newTestSnap()
+	snap.Spec.ServiceMonitor = &ssmdv1alpha1.SnapServiceMonitorConfig{
+		Enabled: true,
+		Labels:  map[string]string{"release": "prometheus"},
+	}
+
+	sm := r.constructServiceMonitor(snap)
+
+	if sm.GetKind() != "ServiceMonitor" || sm.GetLabels()["release"] != "prometheus" {
+		t.Errorf("unexpected ServiceMonitor %s labels %v", sm.GetKind(), sm.GetLabels())
+	}
+	endpoints, _, _ := unstructured.NestedSlice(sm.Object, "spec", "endpoints")
+	if len(endpoints) != 1 || endpoints[0].(map[string]any)["interval"] != "30s" {
+		t.Errorf("expected one endpoint with the default interval, got %v", endpoints)
+	}
+}
+
+// --- TestConstructHPA ---
+
+func TestSnapConstructHPA_CPU(t *testing.T) {
+	r := &SnapReconciler{}
+	snap := newTestSnap()
+	snap.Spec.Autoscaling = &ssmdv1alpha1.SnapAutoscalingConfig{
+		MaxReplicas:                    4,
+		TargetCPUUtilizationPercentage: int32Ptr(70),
+	}
+
+	hpa := r.constructHPA(snap)
+
+	if *hpa.Spec.MinReplicas != 1 || hpa.Spec.MaxReplicas != 4 || hpa.Spec.ScaleTargetRef.Name != "snap-kalshi" {
+		t.Errorf("unexpected HPA spec %+v", hpa.Spec)
+	}
+	if len(hpa.Spec.Metrics) != 1 || hpa.Spec.Metrics[0].Resource == nil || *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization != 70 {
+		t.Errorf("expected CPU utilization metric, got %+v", hpa.Spec.Metrics)
+	}
+}
+
+func TestSnapConstructHPA_MessagesPerSecond(t *testing.T) {
+	r := &SnapReconciler{}
+	snap := newTestSnap()
+	snap.Spec.Autoscaling = &ssmdv1alpha1.SnapAutoscalingConfig{
+		MinReplicas:             int32Ptr(2),
+		MaxReplicas:             6,
+		TargetMessagesPerSecond: int32Ptr(500),
+	}
+
+	hpa := r.constructHPA(snap)
+
+	if len(hpa.Spec.Metrics) != 1 || hpa.Spec.Metrics[0].Pods == nil {
+		t.Fatalf("expected pods metric, got %+v", hpa.Spec.Metrics)
+	}
+	pods := hpa.Spec.Metrics[0].Pods
+	if pods.Metric.Name != "snap_messages_received_per_second" || pods.Target.AverageValue.Value() != 500 {
+		t.Errorf("unexpected pods metric %+v", pods)
+	}
+	if replicas := *r.constructDeployment(snap).Spec.Replicas; replicas != 2 {
+		t.Errorf("deployment replicas = %d, want minReplicas 2", replicas)
+	}
+}
+
+// --- TestSnapReconcile ---
+
+func TestSnapReconcile_CreatesServiceAndHPA(t *testing.T) {
+	snap := newTestSnap()
+	snap.Spec.Autoscaling = &ssmdv1alpha1.SnapAutoscalingConfig{MaxReplicas: 3, TargetCPUUtilizationPercentage: int32Ptr(80)}
+	r := newSnapTestReconciler(snap)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "snap-kalshi", Namespace: "ssmd"}}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(context.Background(), req.NamespacedName, &corev1.Service{}); err != nil {
+		t.Errorf("expected Service, got %v", err)
+	}
+	if err := r.Get(context.Background(), req.NamespacedName, &autoscalingv2.HorizontalPodAutoscaler{}); err != nil {
+		t.Errorf("expected HPA, got %v", err)
+	}
+
+	// Removing autoscaling deletes the HPA
+	updated := &ssmdv1alpha1.Snap{}
+	if err := r.Get(context.Background(), req.NamespacedName, updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status.Service != "snap-kalshi" {
+		t.Errorf("status.service = %q, want snap-kalshi", updated.Status.Service)
+	}
+	updated.Spec.Autoscaling = nil
+	if err := r.Update(context.Background(), updated); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Get(context.Background(), req.NamespacedName, &autoscalingv2.HorizontalPodAutoscaler{}); !errors.IsNotFound(err) {
+		t.Errorf("expected HPA to be deleted, got %v", err)
+	}
+}
+
+func TestSnapReconcileDeployment_KeepsHPAReplicas(t *testing.T) {
+	snap := newTestSnap()
+	snap.Spec.Autoscaling = &ssmdv1alpha1.SnapAutoscalingConfig{MaxReplicas: 5, TargetCPUUtilizationPercentage: int32Ptr(80)}
+	r := newSnapTestReconciler(snap)
+	scaled := r.constructDeployment(snap)
+	scaled.Spec.Replicas = int32Ptr(4)
+	if err := r.Create(context.Background(), scaled); err != nil {
+		t.Fatal(err)
+	}
+
+	snap.Spec.Image = "ghcr.io/aaronwald/ssmd-snap:0.2.0"
+	if _, err := r.reconcileDeployment(context.Background(), snap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "snap-kalshi", Namespace: "ssmd"}, deployment); err != nil {
+		t.Fatal(err)
+	}
+	if *deployment.Spec.Replicas != 4 || deployment.Spec.Template.Spec.Containers[0].Image != snap.Spec.Image {
+		t.Errorf("expected image update with HPA replicas kept, got %d replicas image %s",
+			*deployment.Spec.Replicas, deployment.Spec.Template.Spec.Containers[0].Image)
+	}
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/feedconfig"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/jetstream"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/tenant"
+)
+
+const (
+	// streamProvisionedCondition reports whether the JetStream stream and consumers exist
+	streamProvisionedCondition = "StreamProvisioned"
+
+	// streamProvisionTimeout bounds provisioning so an unreachable NATS server cannot stall reconciles
+	streamProvisionTimeout = 10 * time.Second
+)
+
+// jetStreamClient creates JetStream streams and durable consumers
+type jetStreamClient interface {
+	EnsureStream(ctx context.Context, cfg jetstream.StreamConfig) (bool, error)
+	EnsureConsumer(ctx context.Context, stream string, cfg jetstream.ConsumerConfig) (*jetstream.ConsumerInfo, error)
+	Close() error
+}
+
+// jetStreamDialer connects to the NATS server at url
+type jetStreamDialer func(ctx context.Context, url string) (jetStreamClient, error)
+
+// jetStreamConn is a jetstream.Client that owns its connection
+type jetStreamConn struct {
+	*jetstream.Client
+	conn *jetstream.Conn
+}
+
+// Close closes the NATS connection
+func (c *jetStreamConn) Close() error {
+	return c.conn.Close()
+}
+
+// dialJetStream is the default jetStreamDialer
+func dialJetStream(ctx context.Context, url string) (jetStreamClient, error) {
+	conn, err := jetstream.Dial(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return &jetStreamConn{Client: jetstream.NewClient(conn), conn: conn}, nil
+}
+
+// streamConfig builds the JetStream config for a stream from the CR's stream settings.
+// defaultSubjects are only used if the stream has to be created.
+func streamConfig(name string, defaultSubjects []string, spec *ssmdv1alpha1.StreamProvisionConfig) (jetstream.StreamConfig, error) {
+	cfg := jetstream.StreamConfig{Name: name, DefaultSubjects: defaultSubjects}
+	if spec == nil {
+		return cfg, nil
+	}
+	cfg.Subjects = spec.Subjects
+	cfg.Retention = spec.Retention
+	cfg.Storage = spec.Storage
+	if spec.MaxAge != "" {
+		maxAge, err := time.ParseDuration(spec.MaxAge)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid stream.maxAge %q: %w", spec.MaxAge, err)
+		}
+		cfg.MaxAge = maxAge
+	}
+	if spec.MaxBytes != nil {
+		cfg.MaxBytes = spec.MaxBytes.Value()
+	}
+	if spec.Replicas != nil {
+		cfg.Replicas = int(*spec.Replicas)
+	}
+	return cfg, nil
+}
+
+// provisionedCondition builds the StreamProvisioned condition for a provisioning outcome
+func provisionedCondition(streams []string, err error) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:               streamProvisionedCondition,
+			Status:             metav1.ConditionFalse,
+			Reason:             "ProvisionFailed",
+			Message:            err.Error(),
+			LastTransitionTime: metav1.Now(),
+		}
+	}
+	return metav1.Condition{
+		Type:               streamProvisionedCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Provisioned",
+		Message:            fmt.Sprintf("Stream %s provisioned", strings.Join(streams, ", ")),
+		LastTransitionTime: metav1.Now(),
+	}
+}
+
+// provisionStream creates or updates the connector's transport stream and
+// records the outcome in the StreamProvisioned condition. Failures do not stop
+// the rollout; the connector reports a missing stream itself.
+func (r *ConnectorReconciler) provisionStream(ctx context.Context, connector *ssmdv1alpha1.Connector, feedConfig *feedconfig.Config) {
+	if !connector.Spec.ProvisionStream {
+		meta.RemoveStatusCondition(&connector.Status.Conditions, streamProvisionedCondition)
+		return
+	}
+
+	natsURL, stream, subjectPrefix := r.natsTransport(connector, feedConfig)
+	err := r.ensureStream(ctx, connector, natsURL, stream, subjectPrefix)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to provision JetStream stream", "stream", stream)
+	}
+	meta.SetStatusCondition(&connector.Status.Conditions, provisionedCondition([]string{stream}, err))
+}
+
+// ensureStream creates or updates the connector's stream, capturing <subjectPrefix>.> when created
+func (r *ConnectorReconciler) ensureStream(ctx context.Context, connector *ssmdv1alpha1.Connector, natsURL, stream, subjectPrefix string) error {
+	if stream == "" {
+		return fmt.Errorf("no transport stream configured")
+	}
+	var subjects []string
+	if subjectPrefix != "" {
+		subjects = []string{subjectPrefix + ".>"}
+	}
+	cfg, err := streamConfig(stream, subjects, connector.Spec.Stream)
+	if err != nil {
+		return err
+	}
+	if cfg.Tenant, err = tenant.Name(ctx, r.Client, connector.Namespace, r.TenantConfig); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, streamProvisionTimeout)
+	defer cancel()
+	dial := r.dialJetStream
+	if dial == nil {
+		dial = dialJetStream
+	}
+	js, err := dial(ctx, natsURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	defer func() { _ = js.Close() }()
+
+	_, err = js.EnsureStream(ctx, cfg)
+	return err
+}
+
+// archiverConsumer is a durable consumer an archiver reads from
+type archiverConsumer struct {
+	Stream   string
+	Consumer string
+	Filter   string
+}
+
+// archiverConsumers returns the consumers of the archiver's sources, or of its legacy source
+func archiverConsumers(archiver *ssmdv1alpha1.Archiver) []archiverConsumer {
+	var consumers []archiverConsumer
+	for _, source := range archiver.Spec.Sources {
+		consumers = append(consumers, archiverConsumer{Stream: source.Stream, Consumer: source.Consumer, Filter: source.Filter})
+	}
+	if len(consumers) == 0 && archiver.Spec.Source != nil {
+		consumer := archiver.Spec.Source.Consumer
+		if consumer == "" {
+			consumer = fmt.Sprintf("%s-archiver", archiver.Name)
+		}
+		consumers = append(consumers, archiverConsumer{Stream: archiver.Spec.Source.Stream, Consumer: consumer, Filter: archiver.Spec.Source.Filter})
+	}
+	return consumers
+}
+
+// provisionStreams creates or updates the archiver's source streams and
+// consumers, and reports the consumers' combined lag. Failures are recorded in
+// the StreamProvisioned condition without stopping the rollout.
+func (r *ArchiverReconciler) provisionStreams(ctx context.Context, archiver *ssmdv1alpha1.Archiver) {
+	if !archiver.Spec.ProvisionStream {
+		meta.RemoveStatusCondition(&archiver.Status.Conditions, streamProvisionedCondition)
+		archiver.Status.StreamLag = 0
+		return
+	}
+
+	streams, lag, err := r.ensureStreams(ctx, archiver)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to provision JetStream streams")
+	} else {
+		archiver.Status.StreamLag = lag
+	}
+	meta.SetStatusCondition(&archiver.Status.Conditions, provisionedCondition(streams, err))
+}
+
+// ensureStreams creates or updates each source stream, capturing its source
+// filters when created, then each durable consumer. It returns the stream
+// names and the number of messages pending across the consumers.
+func (r *ArchiverReconciler) ensureStreams(ctx context.Context, archiver *ssmdv1alpha1.Archiver) ([]string, int64, error) {
+	consumers := archiverConsumers(archiver)
+	if len(consumers) == 0 {
+		return nil, 0, fmt.Errorf("no sources configured")
+	}
+
+	// Group filters by stream, keeping source order
+	var streams []string
+	filters := map[string][]string{}
+	for _, c := range consumers {
+		if c.Stream == "" {
+			return nil, 0, fmt.Errorf("source consumer %s has no stream", c.Consumer)
+		}
+		if _, ok := filters[c.Stream]; !ok {
+			streams = append(streams, c.Stream)
+			filters[c.Stream] = nil
+		}
+		if c.Filter != "" && !slices.Contains(filters[c.Stream], c.Filter) {
+			filters[c.Stream] = append(filters[c.Stream], c.Filter)
+		}
+	}
+
+	tenantName, err := tenant.Name(ctx, r.Client, archiver.Namespace, r.TenantConfig)
+	if err != nil {
+		return streams, 0, err
+	}
+	var configs []jetstream.StreamConfig
+	for _, stream := range streams {
+		cfg, err := streamConfig(stream, filters[stream], archiver.Spec.Stream)
+		if err != nil {
+			return streams, 0, err
+		}
+		cfg.Tenant = tenantName
+		configs = append(configs, cfg)
+	}
+
+	natsURL := "nats://nats.nats.svc.cluster.local:4222"
+	if archiver.Spec.Source != nil && archiver.Spec.Source.URL != "" {
+		natsURL = archiver.Spec.Source.URL
+	}
+	ctx, cancel := context.WithTimeout(ctx, streamProvisionTimeout)
+	defer cancel()
+	dial := r.dialJetStream
+	if dial == nil {
+		dial = dialJetStream
+	}
+	js, err := dial(ctx, natsURL)
+	if err != nil {
+		return streams, 0, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	defer func() { _ = js.Close() }()
+
+	for _, cfg := range configs {
+		if _, err := js.EnsureStream(ctx, cfg); err != nil {
+			return streams, 0, fmt.Errorf("stream %s: %w", cfg.Name, err)
+		}
+	}
+	var lag int64
+	for _, c := range consumers {
+		info, err := js.EnsureConsumer(ctx, c.Stream, jetstream.ConsumerConfig{Durable: c.Consumer, FilterSubject: c.Filter})
+		if err != nil {
+			return streams, 0, fmt.Errorf("consumer %s on %s: %w", c.Consumer, c.Stream, err)
+		}
+		lag += int64(info.NumPending)
+	}
+	return streams, lag, nil
+}
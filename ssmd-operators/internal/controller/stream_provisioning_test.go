@@ -0,0 +1,250 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/jetstream"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/tenant"
+)
+
+// fakeJetStream records provisioning calls instead of talking to NATS
+type fakeJetStream struct {
+	url       string
+	streams   []jetstream.StreamConfig
+	consumers map[string]jetstream.ConsumerConfig
+	pending   map[string]uint64
+	streamErr error
+	closed    bool
+}
+
+func (f *fakeJetStream) EnsureStream(ctx context.Context, cfg jetstream.StreamConfig) (bool, error) {
+	if f.streamErr != nil {
+		return false, f.streamErr
+	}
+	f.streams = append(f.streams, cfg)
+	return true, nil
+}
+
+func (f *fakeJetStream) EnsureConsumer(ctx context.Context, stream string, cfg jetstream.ConsumerConfig) (*jetstream.ConsumerInfo, error) {
+	if f.consumers == nil {
+		f.consumers = map[string]jetstream.ConsumerConfig{}
+	}
+	f.consumers[stream+"/"+cfg.Durable] = cfg
+	return &jetstream.ConsumerInfo{NumPending: f.pending[cfg.Durable]}, nil
+}
+
+func (f *fakeJetStream) Close() error {
+	f.closed = true
+	return nil
+}
+
+// dialer returns a jetStreamDialer that hands out f
+func (f *fakeJetStream) dialer() jetStreamDialer {
+	return func(ctx context.Context, url string) (jetStreamClient, error) {
+		f.url = url
+		return f, nil
+	}
+}
+
+// --- TestStreamConfig ---
+
+func TestStreamConfig_Defaults(t *testing.T) {
+	cfg, err := streamConfig("PROD_KALSHI", []string{"prod.kalshi.>"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := jetstream.StreamConfig{Name: "PROD_KALSHI", DefaultSubjects: []string{"prod.kalshi.>"}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestStreamConfig_FromSpec(t *testing.T) {
+	maxBytes := resource.MustParse("10Gi")
+	cfg, err := streamConfig("PROD_KALSHI", nil, &ssmdv1alpha1.StreamProvisionConfig{
+		Subjects:  []string{"prod.kalshi.json.>"},
+		Retention: "interest",
+		MaxAge:    "48h",
+		MaxBytes:  &maxBytes,
+		Replicas:  int32Ptr(3),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxAge != 48*time.Hour || cfg.MaxBytes != 10<<30 || cfg.Replicas != 3 || cfg.Retention != "interest" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Subjects, []string{"prod.kalshi.json.>"}) {
+		t.Errorf("expected explicit subjects, got %v", cfg.Subjects)
+	}
+}
+
+func TestStreamConfig_InvalidMaxAge(t *testing.T) {
+	if _, err := streamConfig("PROD_KALSHI", nil, &ssmdv1alpha1.StreamProvisionConfig{MaxAge: "2 days"}); err == nil {
+		t.Error("expected error for invalid maxAge")
+	}
+}
+
+// --- TestConnectorProvisionStream ---
+
+func TestConnectorProvisionStream_UsesTransport(t *testing.T) {
+	js := &fakeJetStream{}
+	r := &ConnectorReconciler{dialJetStream: js.dialer()}
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Spec.ProvisionStream = true
+	connector.Spec.Transport = &ssmdv1alpha1.TransportConfig{URL: "nats://nats.test:4222", Stream: "TEST_KALSHI", SubjectPrefix: "test.kalshi"}
+
+	r.provisionStream(context.Background(), connector, nil)
+
+	if js.url != "nats://nats.test:4222" || !js.closed {
+		t.Errorf("expected a closed connection to the transport URL, got %q (closed %v)", js.url, js.closed)
+	}
+	if len(js.streams) != 1 || js.streams[0].Name != "TEST_KALSHI" || !reflect.DeepEqual(js.streams[0].DefaultSubjects, []string{"test.kalshi.>"}) {
+		t.Errorf("unexpected streams %+v", js.streams)
+	}
+	if !meta.IsStatusConditionTrue(connector.Status.Conditions, streamProvisionedCondition) {
+		t.Errorf("expected StreamProvisioned=True, got %+v", connector.Status.Conditions)
+	}
+}
+
+func TestConnectorProvisionStream_RecordsTenant(t *testing.T) {
+	js := &fakeJetStream{}
+	r := newCredentialsTestReconciler(&ssmdv1alpha1.TenantConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: tenant.DefaultConfigName, Namespace: "ssmd"},
+		Spec:       ssmdv1alpha1.TenantConfigSpec{Tenant: "desk-a"},
+	})
+	r.dialJetStream = js.dialer()
+	r.TenantConfig = tenant.DefaultConfigName
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Spec.ProvisionStream = true
+	connector.Spec.Transport = &ssmdv1alpha1.TransportConfig{Stream: "TEST_KALSHI", SubjectPrefix: "test.kalshi"}
+
+	r.provisionStream(context.Background(), connector, nil)
+
+	if len(js.streams) != 1 || js.streams[0].Tenant != "desk-a" {
+		t.Errorf("expected the stream provisioned for desk-a, got %+v", js.streams)
+	}
+}
+
+func TestConnectorProvisionStream_Failure(t *testing.T) {
+	js := &fakeJetStream{streamErr: errors.New("insufficient resources")}
+	r := &ConnectorReconciler{dialJetStream: js.dialer()}
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Spec.ProvisionStream = true
+
+	r.provisionStream(context.Background(), connector, nil)
+
+	condition := meta.FindStatusCondition(connector.Status.Conditions, streamProvisionedCondition)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Message != "insufficient resources" {
+		t.Errorf("expected StreamProvisioned=False with the NATS error, got %+v", condition)
+	}
+}
+
+func TestConnectorProvisionStream_Disabled(t *testing.T) {
+	r := &ConnectorReconciler{dialJetStream: func(ctx context.Context, url string) (jetStreamClient, error) {
+		t.Fatal("unexpected NATS connection")
+		return nil, nil
+	}}
+	connector := newTestConnector("kalshi", ssmdv1alpha1.ExchangeTypeKalshi)
+	connector.Status.Conditions = []metav1.Condition{{Type: streamProvisionedCondition, Status: metav1.ConditionTrue}}
+
+	r.provisionStream(context.Background(), connector, nil)
+
+	if meta.FindStatusCondition(connector.Status.Conditions, streamProvisionedCondition) != nil {
+		t.Error("expected StreamProvisioned to be removed when provisioning is off")
+	}
+}
+
+// --- TestArchiverProvisionStreams ---
+
+func TestArchiverProvisionStreams_SourcesAndLag(t *testing.T) {
+	js := &fakeJetStream{pending: map[string]uint64{"archiver-politics": 120, "archiver-sports": 30}}
+	r := &ArchiverReconciler{dialJetStream: js.dialer()}
+	archiver := newTestArchiver()
+	archiver.Spec.ProvisionStream = true
+	archiver.Spec.Stream = &ssmdv1alpha1.StreamProvisionConfig{MaxAge: "24h"}
+	archiver.Spec.Sources = []ssmdv1alpha1.SourceConfig{
+		{Name: "politics", Stream: "PROD_KALSHI", Consumer: "archiver-politics", Filter: "prod.kalshi.politics.>"},
+		{Name: "sports", Stream: "PROD_KALSHI", Consumer: "archiver-sports", Filter: "prod.kalshi.sports.>"},
+	}
+
+	r.provisionStreams(context.Background(), archiver)
+
+	if len(js.streams) != 1 {
+		t.Fatalf("expected one stream, got %+v", js.streams)
+	}
+	wantSubjects := []string{"prod.kalshi.politics.>", "prod.kalshi.sports.>"}
+	if !reflect.DeepEqual(js.streams[0].DefaultSubjects, wantSubjects) || js.streams[0].MaxAge != 24*time.Hour {
+		t.Errorf("unexpected stream config %+v", js.streams[0])
+	}
+	if js.consumers["PROD_KALSHI/archiver-sports"].FilterSubject != "prod.kalshi.sports.>" {
+		t.Errorf("unexpected consumers %+v", js.consumers)
+	}
+	if archiver.Status.StreamLag != 150 {
+		t.Errorf("expected lag 150, got %d", archiver.Status.StreamLag)
+	}
+	if !meta.IsStatusConditionTrue(archiver.Status.Conditions, streamProvisionedCondition) {
+		t.Errorf("expected StreamProvisioned=True, got %+v", archiver.Status.Conditions)
+	}
+}
+
+func TestArchiverProvisionStreams_LegacySource(t *testing.T) {
+	js := &fakeJetStream{}
+	r := &ArchiverReconciler{dialJetStream: js.dialer()}
+	archiver := newTestArchiver()
+	archiver.Spec.ProvisionStream = true
+	archiver.Spec.Source = &ssmdv1alpha1.ArchiverSourceConfig{URL: "nats://nats.test:4222", Stream: "PROD_KALSHI", Filter: "prod.kalshi.json.>"}
+
+	r.provisionStreams(context.Background(), archiver)
+
+	if js.url != "nats://nats.test:4222" {
+		t.Errorf("expected source URL, got %q", js.url)
+	}
+	if _, ok := js.consumers["PROD_KALSHI/archiver-test-archiver"]; !ok {
+		t.Errorf("expected default consumer name, got %+v", js.consumers)
+	}
+}
+
+func TestArchiverProvisionStreams_DialFailureKeepsLag(t *testing.T) {
+	r := &ArchiverReconciler{dialJetStream: func(ctx context.Context, url string) (jetStreamClient, error) {
+		return nil, errors.New("connection refused")
+	}}
+	archiver := newTestArchiver()
+	archiver.Spec.ProvisionStream = true
+	archiver.Spec.Source = &ssmdv1alpha1.ArchiverSourceConfig{Stream: "PROD_KALSHI"}
+	archiver.Status.StreamLag = 42
+
+	r.provisionStreams(context.Background(), archiver)
+
+	if archiver.Status.StreamLag != 42 {
+		t.Errorf("expected previous lag to be kept, got %d", archiver.Status.StreamLag)
+	}
+	if meta.IsStatusConditionTrue(archiver.Status.Conditions, streamProvisionedCondition) {
+		t.Error("expected StreamProvisioned=False")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// VolumeUsage is the filesystem usage of a pod volume
+type VolumeUsage struct {
+	UsedBytes     int64
+	CapacityBytes int64
+}
+
+// VolumeStatsFunc returns usage of the named volume of a pod, or nil if the
+// kubelet has not reported it yet
+type VolumeStatsFunc func(ctx context.Context, pod *corev1.Pod, volumeName string) (*VolumeUsage, error)
+
+// kubeletSummary is the subset of the kubelet /stats/summary response we read
+type kubeletSummary struct {
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		Volumes []struct {
+			Name          string `json:"name"`
+			UsedBytes     *int64 `json:"usedBytes"`
+			CapacityBytes *int64 `json:"capacityBytes"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// KubeletVolumeStats returns a VolumeStatsFunc that reads the kubelet stats summary
+// of the pod's node through the API server node proxy (requires nodes/proxy get).
+func KubeletVolumeStats(clientset kubernetes.Interface) VolumeStatsFunc {
+	return func(ctx context.Context, pod *corev1.Pod, volumeName string) (*VolumeUsage, error) {
+		if pod.Spec.NodeName == "" {
+			return nil, nil
+		}
+		raw, err := clientset.CoreV1().RESTClient().Get().
+			Resource("nodes").Name(pod.Spec.NodeName).
+			SubResource("proxy").Suffix("stats/summary").
+			DoRaw(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stats summary for node %s: %w", pod.Spec.NodeName, err)
+		}
+		return parseVolumeUsage(raw, pod, volumeName)
+	}
+}
+
+// parseVolumeUsage finds the pod volume in a kubelet stats summary
+func parseVolumeUsage(raw []byte, pod *corev1.Pod, volumeName string) (*VolumeUsage, error) {
+	var summary kubeletSummary
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse stats summary: %w", err)
+	}
+	for _, p := range summary.Pods {
+		if p.PodRef.Name != pod.Name || p.PodRef.Namespace != pod.Namespace {
+			continue
+		}
+		for _, v := range p.Volumes {
+			if v.Name == volumeName && v.UsedBytes != nil && v.CapacityBytes != nil {
+				return &VolumeUsage{UsedBytes: *v.UsedBytes, CapacityBytes: *v.CapacityBytes}, nil
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testStatsSummary = `{
+  "node": {"nodeName": "node-1"},
+  "pods": [
+    {
+      "podRef": {"name": "other-pod", "namespace": "ssmd"},
+      "volume": [{"name": "data", "usedBytes": 1, "capacityBytes": 100}]
+    },
+    {
+      "podRef": {"name": "archiver-test-abc", "namespace": "ssmd"},
+      "volume": [
+        {"name": "config", "usedBytes": 4096, "capacityBytes": 1000000},
+        {"name": "data", "usedBytes": 85000, "capacityBytes": 100000}
+      ]
+    }
+  ]
+}`
+
+const testArchiverMetrics = `# TYPE ssmd_archiver_messages_total counter
+ssmd_archiver_messages_total{feed="kalshi",stream="PROD_KALSHI",message_type="ticker"} 900
+ssmd_archiver_messages_total{feed="kalshi",stream="PROD_KALSHI",message_type="trade"} 100
+# TYPE ssmd_archiver_bytes_total counter
+ssmd_archiver_bytes_total{feed="kalshi",stream="PROD_KALSHI"} 524288
+# TYPE ssmd_archiver_files_rotated_total counter
+ssmd_archiver_files_rotated_total{feed="kalshi",stream="PROD_KALSHI"} 4
+`
+
+func newArchiverPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ssmd",
+			Labels: map[string]string{
+				"app.kubernetes.io/name":     "ssmd-archiver",
+				"app.kubernetes.io/instance": "archiver-test",
+			},
+		},
+		Spec:   corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.2"},
+	}
+}
+
+func newTestArchiver() *ssmdv1alpha1.Archiver {
+	return &ssmdv1alpha1.Archiver{
+		ObjectMeta: metav1.ObjectMeta{Name: "archiver-test", Namespace: "ssmd"},
+		Spec: ssmdv1alpha1.ArchiverSpec{
+			Storage: &ssmdv1alpha1.StorageConfig{
+				Local: &ssmdv1alpha1.LocalStorageConfig{PVCName: "archiver-data"},
+			},
+		},
+	}
+}
+
+// --- TestParseVolumeUsage ---
+
+func TestParseVolumeUsage(t *testing.T) {
+	usage, err := parseVolumeUsage([]byte(testStatsSummary), newArchiverPod("archiver-test-abc"), "data")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage == nil || usage.UsedBytes != 85000 || usage.CapacityBytes != 100000 {
+		t.Errorf("usage = %+v, want 85000/100000", usage)
+	}
+}
+
+func TestParseVolumeUsage_PodNotReported(t *testing.T) {
+	usage, err := parseVolumeUsage([]byte(testStatsSummary), newArchiverPod("archiver-test-xyz"), "data")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage != nil {
+		t.Errorf("expected nil usage for unreported pod, got %+v", usage)
+	}
+}
+
+func TestParseVolumeUsage_InvalidJSON(t *testing.T) {
+	if _, err := parseVolumeUsage([]byte("not json"), newArchiverPod("archiver-test-abc"), "data"); err == nil {
+		t.Error("expected error for invalid summary")
+	}
+}
+
+// --- TestStorageFillingCondition ---
+
+func TestStorageFillingCondition_AboveDefaultThreshold(t *testing.T) {
+	r := &ArchiverReconciler{}
+
+	condition := r.storageFillingCondition(newTestArchiver(), &VolumeUsage{UsedBytes: 85, CapacityBytes: 100})
+
+	if condition.Status != metav1.ConditionTrue || condition.Reason != "UsageAboveThreshold" {
+		t.Errorf("expected StorageFilling=True at 85%%, got %+v", condition)
+	}
+}
+
+func TestStorageFillingCondition_CustomThreshold(t *testing.T) {
+	r := &ArchiverReconciler{}
+	archiver := newTestArchiver()
+	archiver.Spec.Storage.Local.FillingThresholdPercent = int32Ptr(90)
+
+	condition := r.storageFillingCondition(archiver, &VolumeUsage{UsedBytes: 85, CapacityBytes: 100})
+
+	if condition.Status != metav1.ConditionFalse {
+		t.Errorf("expected StorageFilling=False below 90%%, got %+v", condition)
+	}
+}
+
+// --- TestUpdateProgressStatus ---
+
+func TestUpdateProgressStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	r := &ArchiverReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(newArchiverPod("archiver-test-abc")).Build(),
+		Scheme: scheme,
+		VolumeStats: func(ctx context.Context, pod *corev1.Pod, volumeName string) (*VolumeUsage, error) {
+			return parseVolumeUsage([]byte(testStatsSummary), pod, volumeName)
+		},
+		scrape: func(ctx context.Context, url string) (metricFamilies, error) {
+			return parseTestMetrics(t, testArchiverMetrics), nil
+		},
+	}
+	archiver := newTestArchiver()
+
+	if err := r.updateProgressStatus(context.Background(), archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if archiver.Status.MessagesArchived != 1000 {
+		t.Errorf("MessagesArchived = %d, want 1000", archiver.Status.MessagesArchived)
+	}
+	if archiver.Status.BytesWritten != 524288 {
+		t.Errorf("BytesWritten = %d, want 524288", archiver.Status.BytesWritten)
+	}
+	if archiver.Status.FilesWritten != 4 {
+		t.Errorf("FilesWritten = %d, want 4", archiver.Status.FilesWritten)
+	}
+	if archiver.Status.LastFlushAt == nil {
+		t.Error("expected LastFlushAt to be set after files were rotated")
+	}
+	if !meta.IsStatusConditionTrue(archiver.Status.Conditions, "StorageFilling") {
+		t.Errorf("expected StorageFilling=True, got %+v", archiver.Status.Conditions)
+	}
+}
+
+func TestUpdateProgressStatus_NoFlushWithoutRotation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	r := &ArchiverReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(newArchiverPod("archiver-test-abc")).Build(),
+		Scheme: scheme,
+		scrape: func(ctx context.Context, url string) (metricFamilies, error) {
+			return parseTestMetrics(t, testArchiverMetrics), nil
+		},
+	}
+	archiver := newTestArchiver()
+	archiver.Status.FilesWritten = 4
+
+	if err := r.updateProgressStatus(context.Background(), archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if archiver.Status.LastFlushAt != nil {
+		t.Error("expected LastFlushAt unset when files_rotated_total did not increase")
+	}
+	if meta.FindStatusCondition(archiver.Status.Conditions, "StorageFilling") != nil {
+		t.Error("expected no StorageFilling condition without volume stats")
+	}
+}
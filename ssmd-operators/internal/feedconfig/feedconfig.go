@@ -0,0 +1,224 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package feedconfig loads feed definitions from feed-<name> ConfigMaps.
+//
+// Each ConfigMap holds the feed's exchanges/feeds/<name>.yaml under the
+// feed.yaml key: its protocol versions (endpoint, auth method, rate limit),
+// calendar, and per-component image defaults. Every controller and webhook
+// that needs feed settings reads them through Load.
+package feedconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigMapKey is the ConfigMap data key holding the feed definition
+const ConfigMapKey = "feed.yaml"
+
+// dateLayout is the format of effective_from and effective_to
+const dateLayout = "2006-01-02"
+
+// ConfigMapName returns the name of the ConfigMap for a feed
+func ConfigMapName(feed string) string {
+	return fmt.Sprintf("feed-%s", feed)
+}
+
+// Protocol is the wire protocol of a feed version
+type Protocol struct {
+	Transport string `json:"transport"`
+	Message   string `json:"message"`
+}
+
+// Version is a feed protocol version and the endpoint serving it
+type Version struct {
+	Version       string   `json:"version"`
+	EffectiveFrom string   `json:"effective_from"`
+	EffectiveTo   string   `json:"effective_to,omitempty"`
+	Protocol      Protocol `json:"protocol"`
+	Endpoint      string   `json:"endpoint"`
+	AuthMethod    string   `json:"auth_method,omitempty"`
+	// RateLimitPerSecond is the exchange's request limit, 0 if unknown
+	RateLimitPerSecond int `json:"rate_limit_per_second,omitempty"`
+}
+
+// Calendar is the trading calendar of a feed
+type Calendar struct {
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// TransportDefaults is the default NATS transport for a feed's connector
+type TransportDefaults struct {
+	Type          string `json:"type,omitempty"`
+	Stream        string `json:"stream,omitempty"`
+	SubjectPrefix string `json:"subjectPrefix,omitempty"`
+}
+
+// ComponentDefaults is the default image for one component of a feed
+type ComponentDefaults struct {
+	Image     string             `json:"image,omitempty"`
+	Version   string             `json:"version,omitempty"`
+	Transport *TransportDefaults `json:"transport,omitempty"`
+}
+
+// ImageRef returns image:version, or "" unless both are set
+func (c *ComponentDefaults) ImageRef() string {
+	if c == nil || c.Image == "" || c.Version == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s", c.Image, c.Version)
+}
+
+// Defaults holds the per-component defaults of a feed
+type Defaults struct {
+	Connector *ComponentDefaults `json:"connector,omitempty"`
+	Archiver  *ComponentDefaults `json:"archiver,omitempty"`
+	Signal    *ComponentDefaults `json:"signal,omitempty"`
+}
+
+// Config is a parsed feed definition
+type Config struct {
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Versions    []Version `json:"versions,omitempty"`
+	Calendar    *Calendar `json:"calendar,omitempty"`
+	Defaults    *Defaults `json:"defaults,omitempty"`
+}
+
+// ActiveVersion returns the version in effect at t: the latest one whose
+// effective_from is not after t and whose effective_to has not passed.
+// When no version matches by date it returns the first one, or nil if the
+// feed has none.
+func (c *Config) ActiveVersion(t time.Time) *Version {
+	if c == nil || len(c.Versions) == 0 {
+		return nil
+	}
+	day := t.UTC().Format(dateLayout)
+	var active *Version
+	for i := range c.Versions {
+		v := &c.Versions[i]
+		if v.EffectiveFrom > day || (v.EffectiveTo != "" && v.EffectiveTo < day) {
+			continue
+		}
+		if active == nil || v.EffectiveFrom > active.EffectiveFrom {
+			active = v
+		}
+	}
+	if active == nil {
+		active = &c.Versions[0]
+	}
+	return active
+}
+
+// ConnectorDefaults returns the connector defaults, or nil if unset
+func (c *Config) ConnectorDefaults() *ComponentDefaults {
+	if c == nil || c.Defaults == nil {
+		return nil
+	}
+	return c.Defaults.Connector
+}
+
+// ArchiverDefaults returns the archiver defaults, or nil if unset
+func (c *Config) ArchiverDefaults() *ComponentDefaults {
+	if c == nil || c.Defaults == nil {
+		return nil
+	}
+	return c.Defaults.Archiver
+}
+
+// Timezone returns the calendar timezone, or "" if unset
+func (c *Config) Timezone() string {
+	if c == nil || c.Calendar == nil {
+		return ""
+	}
+	return c.Calendar.Timezone
+}
+
+// Validate checks the fields the operator relies on
+func (c *Config) Validate() error {
+	var errs []error
+	for i, v := range c.Versions {
+		if v.EffectiveFrom != "" {
+			if _, err := time.Parse(dateLayout, v.EffectiveFrom); err != nil {
+				errs = append(errs, fmt.Errorf("versions[%d].effective_from: must be YYYY-MM-DD", i))
+			}
+		}
+		if v.EffectiveTo != "" {
+			if _, err := time.Parse(dateLayout, v.EffectiveTo); err != nil {
+				errs = append(errs, fmt.Errorf("versions[%d].effective_to: must be YYYY-MM-DD", i))
+			}
+		}
+		if v.RateLimitPerSecond < 0 {
+			errs = append(errs, fmt.Errorf("versions[%d].rate_limit_per_second: must not be negative", i))
+		}
+	}
+	if tz := c.Timezone(); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			errs = append(errs, fmt.Errorf("calendar.timezone: unknown timezone %q", tz))
+		}
+	}
+	if d := c.ConnectorDefaults(); d != nil && (d.Image == "") != (d.Version == "") {
+		errs = append(errs, fmt.Errorf("defaults.connector: image and version must be set together"))
+	}
+	return errors.Join(errs...)
+}
+
+// Parse parses and validates a feed definition
+func Parse(data string) (*Config, error) {
+	var config Config
+	if err := yaml.Unmarshal([]byte(data), &config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Load reads the feed ConfigMap for feed. It returns nil, nil when the
+// ConfigMap or its feed.yaml key does not exist.
+func Load(ctx context.Context, reader client.Reader, namespace, feed string) (*Config, error) {
+	name := ConfigMapName(feed)
+	configMap := &corev1.ConfigMap{}
+	err := reader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, configMap)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := configMap.Data[ConfigMapKey]
+	if !ok {
+		return nil, nil
+	}
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed ConfigMap %s: %w", name, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feedconfig
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// kalshiYAML mirrors exchanges/feeds/kalshi.yaml
+const kalshiYAML = `name: kalshi
+display_name: Kalshi Exchange
+type: websocket
+status: active
+versions:
+  - version: v1
+    effective_from: "2025-12-22"
+    protocol:
+      transport: wss
+      message: json
+    endpoint: wss://api.elections.kalshi.com/trade-api/ws/v2
+    auth_method: api_key
+    rate_limit_per_second: 10
+calendar:
+  timezone: America/New_York
+message_types:
+  ticker:
+    identifier_field: market_ticker
+defaults:
+  connector:
+    image: ghcr.io/aaronwald/ssmd-connector
+    version: "0.4.7"
+    transport:
+      type: nats
+      stream: PROD_KALSHI
+      subjectPrefix: prod.kalshi
+  archiver:
+    image: ghcr.io/aaronwald/ssmd-archiver
+    version: "0.4.8"
+`
+
+func TestParse(t *testing.T) {
+	config, err := Parse(kalshiYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.DisplayName != "Kalshi Exchange" {
+		t.Errorf("display name = %q", config.DisplayName)
+	}
+	v := config.ActiveVersion(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC))
+	if v == nil || v.AuthMethod != "api_key" || v.RateLimitPerSecond != 10 {
+		t.Errorf("active version = %+v", v)
+	}
+	if got := config.ConnectorDefaults().ImageRef(); got != "ghcr.io/aaronwald/ssmd-connector:0.4.7" {
+		t.Errorf("connector image = %q", got)
+	}
+	if got := config.ConnectorDefaults().Transport.Stream; got != "PROD_KALSHI" {
+		t.Errorf("connector stream = %q", got)
+	}
+	if got := config.ArchiverDefaults().ImageRef(); got != "ghcr.io/aaronwald/ssmd-archiver:0.4.8" {
+		t.Errorf("archiver image = %q", got)
+	}
+	if got := config.Timezone(); got != "America/New_York" {
+		t.Errorf("timezone = %q", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"bad yaml", "name: [kalshi", "error converting YAML"},
+		{"bad date", "versions:\n  - effective_from: 22-12-2025\n", "versions[0].effective_from"},
+		{"negative rate limit", "versions:\n  - rate_limit_per_second: -1\n", "versions[0].rate_limit_per_second"},
+		{"unknown timezone", "calendar:\n  timezone: Mars/Olympus\n", "calendar.timezone"},
+		{"image without version", "defaults:\n  connector:\n    image: ghcr.io/aaronwald/ssmd-connector\n", "defaults.connector"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.yaml)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestActiveVersion(t *testing.T) {
+	config := &Config{Versions: []Version{
+		{Version: "v1", EffectiveFrom: "2025-01-01", EffectiveTo: "2025-12-31"},
+		{Version: "v2", EffectiveFrom: "2026-01-01"},
+		{Version: "v3", EffectiveFrom: "2026-06-01"},
+	}}
+
+	tests := []struct {
+		at   string
+		want string
+	}{
+		{"2025-06-01", "v1"},
+		{"2026-03-01", "v2"},
+		{"2026-07-01", "v3"},
+		// Before any version takes effect, fall back to the first
+		{"2024-01-01", "v1"},
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(dateLayout, tt.at)
+		if got := config.ActiveVersion(at); got == nil || got.Version != tt.want {
+			t.Errorf("ActiveVersion(%s) = %+v, want %s", tt.at, got, tt.want)
+		}
+	}
+
+	var missing *Config
+	if missing.ActiveVersion(time.Now()) != nil {
+		t.Error("expected nil version for a nil config")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "feed-kalshi", Namespace: "ssmd"},
+			Data:       map[string]string{ConfigMapKey: kalshiYAML},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "feed-broken", Namespace: "ssmd"},
+			Data:       map[string]string{ConfigMapKey: "calendar:\n  timezone: Mars/Olympus\n"},
+		},
+	).Build()
+	ctx := context.Background()
+
+	config, err := Load(ctx, c, "ssmd", "kalshi")
+	if err != nil || config == nil || config.Name != "kalshi" {
+		t.Errorf("expected kalshi feed, got %+v, %v", config, err)
+	}
+
+	config, err = Load(ctx, c, "ssmd", "kraken")
+	if err != nil || config != nil {
+		t.Errorf("expected nil for a missing ConfigMap, got %+v, %v", config, err)
+	}
+
+	_, err = Load(ctx, c, "ssmd", "broken")
+	if err == nil || !strings.Contains(err.Error(), "invalid feed ConfigMap feed-broken") {
+		t.Errorf("expected invalid ConfigMap error, got %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jetstream provisions NATS JetStream streams and durable consumers.
+//
+// The operator only needs request/reply against the JetStream API, so this
+// speaks the NATS text protocol directly over a single connection instead of
+// pulling in a full client.
+package jetstream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRequestTimeout bounds a JetStream API request when the context has no deadline
+	defaultRequestTimeout = 5 * time.Second
+
+	// maxPayload caps reply sizes read from the server
+	maxPayload = 8 << 20
+)
+
+// ErrTimeout is returned when the server does not reply in time (e.g. JetStream is disabled).
+var ErrTimeout = errors.New("jetstream: request timed out")
+
+// serverInfo is the subset of the server's INFO message the client uses
+type serverInfo struct {
+	TLSRequired  bool `json:"tls_required"`
+	AuthRequired bool `json:"auth_required"`
+}
+
+// connectOptions is the CONNECT message sent after INFO
+type connectOptions struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	Version   string `json:"version"`
+	Protocol  int    `json:"protocol"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// Conn is a NATS connection that serves one request at a time.
+type Conn struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	r      *bufio.Reader
+	inbox  string
+	nextID int
+}
+
+// Dial connects to a nats:// URL. Credentials may be given as user:pass@ or token@.
+func Dial(ctx context.Context, rawURL string) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "nats" {
+		return nil, fmt.Errorf("unsupported NATS URL scheme %q", u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	var dialer net.Dialer
+	netConn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	c := &Conn{
+		conn:  netConn,
+		r:     bufio.NewReader(netConn),
+		inbox: "_INBOX.ssmd-operator." + strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+	if err := c.handshake(ctx, u.User); err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// handshake reads INFO, sends CONNECT and waits for the PONG confirming it was accepted
+func (c *Conn) handshake(ctx context.Context, user *url.Userinfo) error {
+	c.setDeadline(ctx)
+
+	line, err := c.readLine()
+	if err != nil {
+		return fmt.Errorf("failed to read server INFO: %w", err)
+	}
+	op, args := splitOp(line)
+	if op != "INFO" {
+		return fmt.Errorf("expected INFO from server, got %q", line)
+	}
+	var info serverInfo
+	if err := json.Unmarshal([]byte(args), &info); err != nil {
+		return fmt.Errorf("invalid server INFO: %w", err)
+	}
+	if info.TLSRequired {
+		return errors.New("NATS server requires TLS, which is not supported")
+	}
+
+	opts := connectOptions{Name: "ssmd-operator", Lang: "go", Version: "0.1.0", Protocol: 1}
+	if user != nil {
+		if pass, ok := user.Password(); ok {
+			opts.User, opts.Pass = user.Username(), pass
+		} else {
+			opts.AuthToken = user.Username()
+		}
+	}
+	connect, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		return err
+	}
+
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return err
+		}
+		op, args := splitOp(line)
+		switch op {
+		case "PONG":
+			return nil
+		case "-ERR":
+			return fmt.Errorf("NATS connect failed: %s", strings.Trim(args, "'"))
+		case "PING":
+			if _, err := io.WriteString(c.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Request publishes data to subject and returns the first reply.
+func (c *Conn) Request(ctx context.Context, subject string, data []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultRequestTimeout)
+		defer cancel()
+	}
+	c.setDeadline(ctx)
+
+	c.nextID++
+	sid := strconv.Itoa(c.nextID)
+	reply := c.inbox + "." + sid
+	if _, err := fmt.Fprintf(c.conn, "SUB %s %s\r\nUNSUB %s 1\r\nPUB %s %s %d\r\n%s\r\n",
+		reply, sid, sid, subject, reply, len(data), data); err != nil {
+		return nil, err
+	}
+
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return nil, ErrTimeout
+			}
+			return nil, err
+		}
+		op, args := splitOp(line)
+		switch op {
+		case "MSG":
+			// MSG <subject> <sid> [reply-to] <#bytes>
+			fields := strings.Fields(args)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("malformed MSG %q", line)
+			}
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil || size < 0 || size > maxPayload {
+				return nil, fmt.Errorf("malformed MSG size in %q", line)
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(c.r, payload); err != nil {
+				return nil, err
+			}
+			if fields[1] == sid {
+				return payload[:size], nil
+			}
+		case "PING":
+			if _, err := io.WriteString(c.conn, "PONG\r\n"); err != nil {
+				return nil, err
+			}
+		case "-ERR":
+			return nil, fmt.Errorf("NATS error: %s", strings.Trim(args, "'"))
+		}
+	}
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// setDeadline applies the context deadline to the connection
+func (c *Conn) setDeadline(ctx context.Context) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultRequestTimeout)
+	}
+	_ = c.conn.SetDeadline(deadline)
+}
+
+// readLine reads one protocol line without its trailing CRLF
+func (c *Conn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// splitOp splits a protocol line into its operation and arguments
+func splitOp(line string) (string, string) {
+	op, args, _ := strings.Cut(line, " ")
+	return strings.ToUpper(op), strings.TrimSpace(args)
+}
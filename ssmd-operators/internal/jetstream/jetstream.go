@@ -0,0 +1,299 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jetstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	// apiPrefix is the subject prefix of the JetStream API
+	apiPrefix = "$JS.API"
+
+	// JetStream API error codes the client handles
+	errCodeStreamNotFound   = 10059
+	errCodeConsumerNotFound = 10014
+
+	// tenantMetadataKey is the stream metadata key naming the tenant that created it
+	tenantMetadataKey = "ssmd.io/tenant"
+)
+
+// StreamConfig is the part of a stream's configuration the operator manages.
+// Other settings of an existing stream are left as they are.
+type StreamConfig struct {
+	Name string
+
+	// Subjects replace the stream's subjects when set
+	Subjects []string
+
+	// DefaultSubjects are used when creating the stream without Subjects;
+	// an existing stream keeps its subjects
+	DefaultSubjects []string
+
+	// Retention is limits, interest or workqueue (defaults to limits)
+	Retention string
+
+	// MaxAge is how long messages are kept (0 = forever)
+	MaxAge time.Duration
+
+	// MaxBytes caps the stream size (0 = unlimited)
+	MaxBytes int64
+
+	// Storage is file or memory (defaults to file)
+	Storage string
+
+	// Replicas is the number of stream replicas (defaults to 1)
+	Replicas int
+
+	// Tenant is recorded in the metadata of a stream when it is created. A
+	// stream recorded for another tenant is never updated; streams without a
+	// tenant are shared.
+	Tenant string
+}
+
+// ConsumerConfig describes a durable consumer.
+type ConsumerConfig struct {
+	Durable       string
+	FilterSubject string
+}
+
+// ConsumerInfo reports how far behind a consumer is.
+type ConsumerInfo struct {
+	// NumPending is the number of stream messages not yet delivered to the consumer
+	NumPending uint64 `json:"num_pending"`
+
+	// NumAckPending is the number of delivered messages awaiting acknowledgement
+	NumAckPending int64 `json:"num_ack_pending"`
+}
+
+// APIError is an error returned by the JetStream API.
+type APIError struct {
+	Code        int    `json:"code"`
+	ErrCode     int    `json:"err_code"`
+	Description string `json:"description"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("jetstream: %s (code %d, err_code %d)", e.Description, e.Code, e.ErrCode)
+}
+
+// requester sends a request and returns the reply
+type requester interface {
+	Request(ctx context.Context, subject string, data []byte) ([]byte, error)
+}
+
+// Client provisions streams and consumers through the JetStream API.
+type Client struct {
+	conn requester
+}
+
+// NewClient returns a Client that sends JetStream API requests over conn.
+func NewClient(conn *Conn) *Client {
+	return &Client{conn: conn}
+}
+
+// apiResponse is the envelope shared by all JetStream API replies
+type apiResponse struct {
+	Error *APIError `json:"error,omitempty"`
+}
+
+// streamInfoResponse is the reply to STREAM.INFO, CREATE and UPDATE
+type streamInfoResponse struct {
+	apiResponse
+	Config map[string]json.RawMessage `json:"config"`
+}
+
+// consumerInfoResponse is the reply to CONSUMER.INFO and CREATE
+type consumerInfoResponse struct {
+	apiResponse
+	ConsumerInfo
+	Config struct {
+		FilterSubject string `json:"filter_subject"`
+	} `json:"config"`
+}
+
+// request sends a JetStream API request and decodes the reply into resp
+func (c *Client) request(ctx context.Context, subject string, body any, resp any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	reply, err := c.conn.Request(ctx, apiPrefix+"."+subject, data)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(reply, resp); err != nil {
+		return fmt.Errorf("invalid JetStream API reply to %s: %w", subject, err)
+	}
+	return nil
+}
+
+// managedStreamFields returns the stream config keys the operator sets, with server defaults applied
+func managedStreamFields(cfg StreamConfig) map[string]any {
+	retention := cfg.Retention
+	if retention == "" {
+		retention = "limits"
+	}
+	storage := cfg.Storage
+	if storage == "" {
+		storage = "file"
+	}
+	maxBytes := cfg.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = -1
+	}
+	replicas := cfg.Replicas
+	if replicas == 0 {
+		replicas = 1
+	}
+	fields := map[string]any{
+		"retention":    retention,
+		"max_age":      int64(cfg.MaxAge),
+		"max_bytes":    maxBytes,
+		"storage":      storage,
+		"num_replicas": replicas,
+	}
+	// Without subjects the server captures the subject named after the stream
+	if len(cfg.Subjects) > 0 {
+		fields["subjects"] = cfg.Subjects
+	}
+	return fields
+}
+
+// EnsureStream creates the stream, or updates it when a managed setting differs.
+// It reports whether the stream was created or updated.
+func (c *Client) EnsureStream(ctx context.Context, cfg StreamConfig) (bool, error) {
+	desired := managedStreamFields(cfg)
+
+	var info streamInfoResponse
+	if err := c.request(ctx, "STREAM.INFO."+cfg.Name, nil, &info); err != nil {
+		return false, err
+	}
+	if info.Error != nil && info.Error.ErrCode != errCodeStreamNotFound {
+		return false, info.Error
+	}
+
+	// Create the stream if it does not exist
+	if info.Error != nil {
+		desired["name"] = cfg.Name
+		if len(cfg.Subjects) == 0 && len(cfg.DefaultSubjects) > 0 {
+			desired["subjects"] = cfg.DefaultSubjects
+		}
+		if cfg.Tenant != "" {
+			desired["metadata"] = map[string]string{tenantMetadataKey: cfg.Tenant}
+		}
+		var created streamInfoResponse
+		if err := c.request(ctx, "STREAM.CREATE."+cfg.Name, desired, &created); err != nil {
+			return false, err
+		}
+		if created.Error != nil {
+			return false, created.Error
+		}
+		return true, nil
+	}
+
+	if owner := streamTenant(info.Config); owner != "" && owner != cfg.Tenant {
+		return false, fmt.Errorf("stream %s belongs to tenant %s", cfg.Name, owner)
+	}
+
+	// Update only the managed settings, keeping the rest of the existing config
+	update := map[string]any{}
+	for key, value := range info.Config {
+		update[key] = value
+	}
+	changed := false
+	for key, want := range desired {
+		wantJSON, err := json.Marshal(want)
+		if err != nil {
+			return false, err
+		}
+		if !jsonEqual(info.Config[key], wantJSON) {
+			update[key] = want
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+
+	var updated streamInfoResponse
+	if err := c.request(ctx, "STREAM.UPDATE."+cfg.Name, update, &updated); err != nil {
+		return false, err
+	}
+	if updated.Error != nil {
+		return false, updated.Error
+	}
+	return true, nil
+}
+
+// streamTenant returns the tenant recorded in a stream's metadata, or ""
+func streamTenant(config map[string]json.RawMessage) string {
+	var metadata map[string]string
+	if raw, ok := config["metadata"]; !ok || json.Unmarshal(raw, &metadata) != nil {
+		return ""
+	}
+	return metadata[tenantMetadataKey]
+}
+
+// EnsureConsumer creates the durable consumer on stream, or updates its filter
+// subject when it changed, and returns its progress.
+func (c *Client) EnsureConsumer(ctx context.Context, stream string, cfg ConsumerConfig) (*ConsumerInfo, error) {
+	var info consumerInfoResponse
+	if err := c.request(ctx, fmt.Sprintf("CONSUMER.INFO.%s.%s", stream, cfg.Durable), nil, &info); err != nil {
+		return nil, err
+	}
+	if info.Error != nil && info.Error.ErrCode != errCodeConsumerNotFound {
+		return nil, info.Error
+	}
+	if info.Error == nil && info.Config.FilterSubject == cfg.FilterSubject {
+		return &info.ConsumerInfo, nil
+	}
+
+	request := map[string]any{
+		"stream_name": stream,
+		"config": map[string]any{
+			"durable_name":   cfg.Durable,
+			"filter_subject": cfg.FilterSubject,
+			"ack_policy":     "explicit",
+			"deliver_policy": "all",
+		},
+	}
+	var created consumerInfoResponse
+	if err := c.request(ctx, fmt.Sprintf("CONSUMER.DURABLE.CREATE.%s.%s", stream, cfg.Durable), request, &created); err != nil {
+		return nil, err
+	}
+	if created.Error != nil {
+		return nil, created.Error
+	}
+	return &created.ConsumerInfo, nil
+}
+
+// jsonEqual compares two JSON documents by value
+func jsonEqual(a, b []byte) bool {
+	var av, bv any
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
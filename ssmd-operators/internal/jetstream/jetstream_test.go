@@ -0,0 +1,403 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jetstream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeJetStream is a NATS server that answers JetStream API requests from memory.
+type fakeJetStream struct {
+	mu        sync.Mutex
+	streams   map[string]map[string]any
+	consumers map[string]map[string]any
+	requests  []string
+	connect   string
+	silent    bool
+}
+
+func newFakeJetStream(t *testing.T) (*fakeJetStream, string) {
+	t.Helper()
+	f := &fakeJetStream{streams: map[string]map[string]any{}, consumers: map[string]map[string]any{}}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f, "nats://" + ln.Addr().String()
+}
+
+func (f *fakeJetStream) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	_, _ = io.WriteString(conn, `INFO {"server_id":"fake","jetstream":true}`+"\r\n")
+
+	sids := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "CONNECT":
+			f.mu.Lock()
+			f.connect = strings.TrimSpace(strings.TrimPrefix(line, "CONNECT"))
+			f.mu.Unlock()
+		case "PING":
+			_, _ = io.WriteString(conn, "PONG\r\n")
+		case "SUB":
+			sids[fields[1]] = fields[2]
+		case "PUB":
+			size, _ := strconv.Atoi(fields[3])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			reply := f.handle(fields[1], payload[:size])
+			if reply == nil {
+				continue
+			}
+			// A server PING in between must not confuse the client
+			_, _ = fmt.Fprintf(conn, "PING\r\nMSG %s %s %d\r\n%s\r\n", fields[2], sids[fields[2]], len(reply), reply)
+		}
+	}
+}
+
+func (f *fakeJetStream) handle(subject string, data []byte) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, subject)
+	if f.silent {
+		return nil
+	}
+
+	api := strings.TrimPrefix(subject, "$JS.API.")
+	parts := strings.Split(api, ".")
+	reply := func(v any) []byte {
+		b, _ := json.Marshal(v)
+		return b
+	}
+	notFound := func(code int, what string) []byte {
+		return reply(map[string]any{"error": map[string]any{"code": 404, "err_code": code, "description": what + " not found"}})
+	}
+
+	switch {
+	case strings.HasPrefix(api, "STREAM.INFO."):
+		config, ok := f.streams[parts[2]]
+		if !ok {
+			return notFound(errCodeStreamNotFound, "stream")
+		}
+		return reply(map[string]any{"config": config})
+	case strings.HasPrefix(api, "STREAM.CREATE."), strings.HasPrefix(api, "STREAM.UPDATE."):
+		var config map[string]any
+		_ = json.Unmarshal(data, &config)
+		if parts[1] == "UPDATE" && f.streams[parts[2]]["storage"] != config["storage"] {
+			return reply(map[string]any{"error": map[string]any{"code": 500, "err_code": 10052, "description": "stream configuration update can not change storage type"}})
+		}
+		f.streams[parts[2]] = config
+		return reply(map[string]any{"config": config})
+	case strings.HasPrefix(api, "CONSUMER.INFO."):
+		consumer, ok := f.consumers[parts[2]+"/"+parts[3]]
+		if !ok {
+			return notFound(errCodeConsumerNotFound, "consumer")
+		}
+		return reply(consumer)
+	case strings.HasPrefix(api, "CONSUMER.DURABLE.CREATE."):
+		var req struct {
+			Config map[string]any `json:"config"`
+		}
+		_ = json.Unmarshal(data, &req)
+		consumer := map[string]any{"config": req.Config, "num_pending": 0, "num_ack_pending": 0}
+		f.consumers[parts[3]+"/"+parts[4]] = consumer
+		return reply(consumer)
+	}
+	return reply(map[string]any{"error": map[string]any{"code": 400, "description": "unknown API " + api}})
+}
+
+func dialFake(t *testing.T, url string) *Client {
+	t.Helper()
+	conn, err := Dial(context.Background(), url)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewClient(conn)
+}
+
+// --- TestDial ---
+
+func TestDial_SendsCredentials(t *testing.T) {
+	f, url := newFakeJetStream(t)
+	conn, err := Dial(context.Background(), strings.Replace(url, "nats://", "nats://ssmd:secret@", 1))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var opts connectOptions
+	if err := json.Unmarshal([]byte(f.connect), &opts); err != nil {
+		t.Fatalf("invalid CONNECT %q: %v", f.connect, err)
+	}
+	if opts.User != "ssmd" || opts.Pass != "secret" {
+		t.Errorf("expected user/pass in CONNECT, got %+v", opts)
+	}
+}
+
+func TestDial_RejectsUnsupportedScheme(t *testing.T) {
+	if _, err := Dial(context.Background(), "tls://nats:4222"); err == nil {
+		t.Error("expected error for tls:// URL")
+	}
+}
+
+func TestRequest_Timeout(t *testing.T) {
+	f, url := newFakeJetStream(t)
+	f.silent = true
+	conn, err := Dial(context.Background(), url)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := conn.Request(ctx, "$JS.API.INFO", nil); !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+}
+
+// --- TestEnsureStream ---
+
+func TestEnsureStream_Creates(t *testing.T) {
+	f, url := newFakeJetStream(t)
+	c := dialFake(t, url)
+
+	changed, err := c.EnsureStream(context.Background(), StreamConfig{
+		Name:     "PROD_KALSHI",
+		Subjects: []string{"prod.kalshi.>"},
+		MaxAge:   48 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("EnsureStream: %v", err)
+	}
+	if !changed {
+		t.Error("expected stream to be created")
+	}
+
+	config := f.streams["PROD_KALSHI"]
+	if config["retention"] != "limits" || config["storage"] != "file" || config["num_replicas"] != float64(1) {
+		t.Errorf("expected server defaults, got %v", config)
+	}
+	if config["max_age"] != float64(48*time.Hour) {
+		t.Errorf("expected max_age in nanoseconds, got %v", config["max_age"])
+	}
+}
+
+func TestEnsureStream_NoChange(t *testing.T) {
+	f, url := newFakeJetStream(t)
+	c := dialFake(t, url)
+	cfg := StreamConfig{Name: "PROD_KALSHI", Subjects: []string{"prod.kalshi.>"}}
+
+	if _, err := c.EnsureStream(context.Background(), cfg); err != nil {
+		t.Fatalf("EnsureStream: %v", err)
+	}
+	changed, err := c.EnsureStream(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("EnsureStream: %v", err)
+	}
+	if changed {
+		t.Error("expected no update for an unchanged stream")
+	}
+	if got := f.requests[len(f.requests)-1]; got != "$JS.API.STREAM.INFO.PROD_KALSHI" {
+		t.Errorf("expected only STREAM.INFO on the second call, got %s", got)
+	}
+}
+
+func TestEnsureStream_UpdateKeepsUnmanagedSettings(t *testing.T) {
+	f, url := newFakeJetStream(t)
+	c := dialFake(t, url)
+	f.streams["PROD_KALSHI"] = map[string]any{
+		"name": "PROD_KALSHI", "subjects": []any{"prod.kalshi.>"}, "retention": "limits", "max_age": 0,
+		"max_bytes": -1, "storage": "file", "num_replicas": 1, "duplicate_window": 120000000000,
+	}
+
+	changed, err := c.EnsureStream(context.Background(), StreamConfig{
+		Name:     "PROD_KALSHI",
+		Subjects: []string{"prod.kalshi.>"},
+		MaxAge:   24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("EnsureStream: %v", err)
+	}
+	if !changed {
+		t.Error("expected stream to be updated")
+	}
+	config := f.streams["PROD_KALSHI"]
+	if config["max_age"] != float64(24*time.Hour) {
+		t.Errorf("expected updated max_age, got %v", config["max_age"])
+	}
+	if config["duplicate_window"] != float64(120000000000) {
+		t.Errorf("expected duplicate_window to be kept, got %v", config["duplicate_window"])
+	}
+}
+
+func TestEnsureStream_DefaultSubjectsOnlyOnCreate(t *testing.T) {
+	f, url := newFakeJetStream(t)
+	c := dialFake(t, url)
+	cfg := StreamConfig{Name: "PROD_KALSHI", DefaultSubjects: []string{"prod.kalshi.json.>"}}
+
+	if _, err := c.EnsureStream(context.Background(), cfg); err != nil {
+		t.Fatalf("EnsureStream: %v", err)
+	}
+	if subjects := f.streams["PROD_KALSHI"]["subjects"]; !reflect.DeepEqual(subjects, []any{"prod.kalshi.json.>"}) {
+		t.Errorf("expected default subjects on create, got %v", subjects)
+	}
+
+	f.streams["PROD_KALSHI"]["subjects"] = []any{"prod.kalshi.>"}
+	changed, err := c.EnsureStream(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("EnsureStream: %v", err)
+	}
+	if changed {
+		t.Error("expected existing subjects to be kept")
+	}
+}
+
+func TestEnsureStream_APIError(t *testing.T) {
+	f, url := newFakeJetStream(t)
+	c := dialFake(t, url)
+	f.streams["PROD_KALSHI"] = map[string]any{"name": "PROD_KALSHI", "storage": "memory"}
+
+	_, err := c.EnsureStream(context.Background(), StreamConfig{Name: "PROD_KALSHI", Storage: "file"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrCode != 10052 {
+		t.Errorf("expected storage change to be rejected, got %v", err)
+	}
+}
+
+func TestEnsureStream_RecordsTenant(t *testing.T) {
+	f, url := newFakeJetStream(t)
+	c := dialFake(t, url)
+	cfg := StreamConfig{Name: "PROD_KALSHI", Subjects: []string{"prod.kalshi.>"}, Tenant: "desk-a"}
+
+	if _, err := c.EnsureStream(context.Background(), cfg); err != nil {
+		t.Fatalf("EnsureStream: %v", err)
+	}
+	if metadata := f.streams["PROD_KALSHI"]["metadata"]; !reflect.DeepEqual(metadata, map[string]any{"ssmd.io/tenant": "desk-a"}) {
+		t.Errorf("expected the tenant in the stream metadata, got %v", metadata)
+	}
+
+	// The owning tenant can update it
+	cfg.MaxAge = time.Hour
+	if changed, err := c.EnsureStream(context.Background(), cfg); err != nil || !changed {
+		t.Errorf("expected the owner to update the stream, got changed=%t err=%v", changed, err)
+	}
+}
+
+func TestEnsureStream_RefusesOtherTenant(t *testing.T) {
+	f, url := newFakeJetStream(t)
+	c := dialFake(t, url)
+	f.streams["PROD_KALSHI"] = map[string]any{
+		"name": "PROD_KALSHI", "subjects": []any{"prod.kalshi.>"}, "retention": "limits", "max_age": 0,
+		"max_bytes": -1, "storage": "file", "num_replicas": 1, "metadata": map[string]any{"ssmd.io/tenant": "desk-a"},
+	}
+
+	for _, tenant := range []string{"desk-b", ""} {
+		_, err := c.EnsureStream(context.Background(), StreamConfig{
+			Name: "PROD_KALSHI", Subjects: []string{"prod.kalshi.>"}, MaxAge: time.Hour, Tenant: tenant,
+		})
+		if err == nil || !strings.Contains(err.Error(), "belongs to tenant desk-a") {
+			t.Errorf("tenant %q: expected the stream to be refused, got %v", tenant, err)
+		}
+	}
+	if f.streams["PROD_KALSHI"]["max_age"] != 0 {
+		t.Errorf("expected the stream unchanged, got max_age %v", f.streams["PROD_KALSHI"]["max_age"])
+	}
+}
+
+// --- TestEnsureConsumer ---
+
+func TestEnsureConsumer_CreatesAndReportsPending(t *testing.T) {
+	f, url := newFakeJetStream(t)
+	c := dialFake(t, url)
+
+	info, err := c.EnsureConsumer(context.Background(), "PROD_KALSHI", ConsumerConfig{Durable: "archiver-politics", FilterSubject: "prod.kalshi.politics.>"})
+	if err != nil {
+		t.Fatalf("EnsureConsumer: %v", err)
+	}
+	if info.NumPending != 0 {
+		t.Errorf("expected no pending messages, got %d", info.NumPending)
+	}
+	config := f.consumers["PROD_KALSHI/archiver-politics"]["config"].(map[string]any)
+	if config["filter_subject"] != "prod.kalshi.politics.>" || config["ack_policy"] != "explicit" {
+		t.Errorf("unexpected consumer config %v", config)
+	}
+
+	// Existing consumers report their lag without being recreated
+	f.consumers["PROD_KALSHI/archiver-politics"]["num_pending"] = 1500
+	requests := len(f.requests)
+	info, err = c.EnsureConsumer(context.Background(), "PROD_KALSHI", ConsumerConfig{Durable: "archiver-politics", FilterSubject: "prod.kalshi.politics.>"})
+	if err != nil {
+		t.Fatalf("EnsureConsumer: %v", err)
+	}
+	if info.NumPending != 1500 {
+		t.Errorf("expected 1500 pending, got %d", info.NumPending)
+	}
+	if len(f.requests) != requests+1 {
+		t.Errorf("expected a single CONSUMER.INFO request, got %v", f.requests[requests:])
+	}
+}
+
+func TestEnsureConsumer_UpdatesFilter(t *testing.T) {
+	f, url := newFakeJetStream(t)
+	c := dialFake(t, url)
+	f.consumers["PROD_KALSHI/archiver"] = map[string]any{"config": map[string]any{"filter_subject": "prod.kalshi.>"}}
+
+	if _, err := c.EnsureConsumer(context.Background(), "PROD_KALSHI", ConsumerConfig{Durable: "archiver", FilterSubject: "prod.kalshi.sports.>"}); err != nil {
+		t.Fatalf("EnsureConsumer: %v", err)
+	}
+	config := f.consumers["PROD_KALSHI/archiver"]["config"].(map[string]any)
+	if config["filter_subject"] != "prod.kalshi.sports.>" {
+		t.Errorf("expected filter to be updated, got %v", config["filter_subject"])
+	}
+}
@@ -0,0 +1,352 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package options holds the operator manager settings: leader election,
+// metrics and health probe addresses, watched namespaces, reconcile
+// concurrency per controller, and the priority class and resources given to
+// each component's pods when their CR leaves them unset.
+//
+// Several operators can share a cluster, one per desk: each desk's operator
+// watches its own namespaces and reads its TenantConfig there, and a shared
+// operator excludes those namespaces.
+//
+// Settings load from a YAML file (the ssmd-operator-config ConfigMap mounted
+// into the manager pod) and flags given on the command line override the
+// file. Without a file the flag defaults apply.
+package options
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/yaml"
+
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/tenant"
+)
+
+// Controllers are the controller names accepted in MaxConcurrentReconciles
+var Controllers = []string{"archiver", "archiverschedule", "connector", "harman", "notifier", "signal", "snap"}
+
+// Components are the names accepted in Components: each controller's pods
+// plus the batch Jobs they run (archiver sync, compaction and secmaster archive)
+var Components = []string{"archiver", "compaction", "connector", "harman", "notifier", "secmaster", "signal", "snap", "sync"}
+
+// ComponentDefaults are the pod settings of a component whose CR does not set them
+type ComponentDefaults struct {
+	PriorityClassName string                       `json:"priorityClassName,omitempty"`
+	Resources         *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// Options configures the controller manager
+type Options struct {
+	LeaderElection   bool   `json:"leaderElection"`
+	LeaderElectionID string `json:"leaderElectionID,omitempty"`
+
+	// MetricsBindAddress is the metrics endpoint address, "0" disables it
+	MetricsBindAddress     string `json:"metricsBindAddress,omitempty"`
+	HealthProbeBindAddress string `json:"healthProbeBindAddress,omitempty"`
+
+	// Namespaces restricts the watched namespaces; empty watches all
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// ExcludeNamespaces are left to other operators, e.g. desks running their
+	// own; only valid when watching all namespaces
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+
+	// TenantConfig is the TenantConfig read from each namespace for tenant
+	// labels and defaults; empty disables tenancy
+	TenantConfig string `json:"tenantConfig,omitempty"`
+
+	// MaxConcurrentReconciles is the number of parallel reconciles per
+	// controller name; unlisted controllers reconcile one at a time
+	MaxConcurrentReconciles map[string]int `json:"maxConcurrentReconciles,omitempty"`
+
+	// Components are the default priority class and resources per component
+	Components map[string]ComponentDefaults `json:"components,omitempty"`
+}
+
+// Defaults returns the settings used when neither file nor flags set them.
+// Archiver reconciles block while polling sync Jobs, so they run in parallel
+// to keep one slow sync from stalling every other archiver.
+func Defaults() Options {
+	return Options{
+		LeaderElectionID:        "fd71ed73.ssmd.io",
+		MetricsBindAddress:      "0",
+		HealthProbeBindAddress:  ":8081",
+		MaxConcurrentReconciles: map[string]int{"archiver": 4},
+		TenantConfig:            tenant.DefaultConfigName,
+	}
+}
+
+// Flags holds the command-line values before they are merged with the file
+type Flags struct {
+	Options
+	ConfigFile string
+
+	// priorityClasses are the --<component>-priority-class values
+	priorityClasses map[string]string
+
+	fs *flag.FlagSet
+}
+
+// BindFlags registers the manager flags on fs with defaults from Defaults
+func BindFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{Options: Defaults(), priorityClasses: map[string]string{}, fs: fs}
+	fs.StringVar(&f.ConfigFile, "config", "",
+		"Operator config file (YAML). Flags set on the command line override its values.")
+	fs.StringVar(&f.MetricsBindAddress, "metrics-bind-address", f.MetricsBindAddress, "The address the metrics endpoint binds to. "+
+		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
+	fs.StringVar(&f.HealthProbeBindAddress, "health-probe-bind-address", f.HealthProbeBindAddress,
+		"The address the probe endpoint binds to.")
+	fs.BoolVar(&f.LeaderElection, "leader-elect", f.LeaderElection,
+		"Enable leader election for controller manager. "+
+			"Enabling this will ensure there is only one active controller manager.")
+	fs.StringVar(&f.LeaderElectionID, "leader-election-id", f.LeaderElectionID, "Name of the leader election Lease.")
+	fs.Func("namespaces", "Comma-separated namespaces to watch (default all).", func(value string) error {
+		f.Namespaces = splitList(value)
+		return nil
+	})
+	fs.Func("exclude-namespaces", "Comma-separated namespaces not to watch when watching all.", func(value string) error {
+		f.ExcludeNamespaces = splitList(value)
+		return nil
+	})
+	fs.StringVar(&f.TenantConfig, "tenant-config", f.TenantConfig,
+		"Name of the TenantConfig read from each namespace. Empty disables tenant labels and defaults.")
+	fs.Func("max-concurrent-reconciles",
+		"Parallel reconciles per controller, e.g. archiver=4,connector=2 (default archiver=4, others 1).",
+		func(value string) error {
+			concurrency, err := parseConcurrency(value)
+			if err != nil {
+				return err
+			}
+			f.MaxConcurrentReconciles = concurrency
+			return nil
+		})
+	for _, component := range Components {
+		fs.Func(component+"-priority-class", priorityClassUsage[component], func(value string) error {
+			f.priorityClasses[component] = value
+			return nil
+		})
+	}
+	return f
+}
+
+// priorityClassUsage describes the --<component>-priority-class flags
+var priorityClassUsage = map[string]string{
+	"archiver":   "Default PriorityClass for archiver pods.",
+	"compaction": "PriorityClass for ArchiverSchedule compaction Jobs. Defaults to the archiver template's priority class.",
+	"connector":  "Default PriorityClass for connector pods. Connectors should be the last workload evicted.",
+	"harman":     "Default PriorityClass for harman pods.",
+	"notifier":   "Default PriorityClass for notifier pods.",
+	"secmaster":  "PriorityClass for secmaster archive Jobs. Defaults to the archiver template's priority class.",
+	"signal":     "Default PriorityClass for signal pods.",
+	"snap":       "Default PriorityClass for snap pods.",
+	"sync":       "PriorityClass for archiver sync Jobs. Defaults to the archiver's priority class.",
+}
+
+// Resolve loads the config file, if any, and applies the flags that were set
+// explicitly on the command line over it
+func (f *Flags) Resolve() (Options, error) {
+	resolved := Defaults()
+	if f.ConfigFile != "" {
+		loaded, err := Load(f.ConfigFile)
+		if err != nil {
+			return Options{}, err
+		}
+		resolved = loaded
+	}
+
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "metrics-bind-address":
+			resolved.MetricsBindAddress = f.MetricsBindAddress
+		case "health-probe-bind-address":
+			resolved.HealthProbeBindAddress = f.HealthProbeBindAddress
+		case "leader-elect":
+			resolved.LeaderElection = f.LeaderElection
+		case "leader-election-id":
+			resolved.LeaderElectionID = f.LeaderElectionID
+		case "namespaces":
+			resolved.Namespaces = f.Namespaces
+		case "exclude-namespaces":
+			resolved.ExcludeNamespaces = f.ExcludeNamespaces
+		case "tenant-config":
+			resolved.TenantConfig = f.TenantConfig
+		case "max-concurrent-reconciles":
+			resolved.MaxConcurrentReconciles = f.MaxConcurrentReconciles
+		}
+		if component, ok := strings.CutSuffix(fl.Name, "-priority-class"); ok {
+			defaults := resolved.Components[component]
+			defaults.PriorityClassName = f.priorityClasses[component]
+			if resolved.Components == nil {
+				resolved.Components = map[string]ComponentDefaults{}
+			}
+			resolved.Components[component] = defaults
+		}
+	})
+	return resolved, resolved.Validate()
+}
+
+// Load reads a config file over Defaults. Unknown keys are rejected so a typo
+// does not silently fall back to a default.
+func Load(path string) (Options, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Options{}, fmt.Errorf("reading operator config: %w", err)
+	}
+	opts := Defaults()
+	if err := yaml.UnmarshalStrict(data, &opts); err != nil {
+		return Options{}, fmt.Errorf("parsing operator config %s: %w", path, err)
+	}
+	return opts, opts.Validate()
+}
+
+// Validate checks controller and component names, concurrency values and
+// namespace scoping
+func (o Options) Validate() error {
+	for name, n := range o.MaxConcurrentReconciles {
+		if !isController(name) {
+			return fmt.Errorf("maxConcurrentReconciles: unknown controller %q (expected one of %s)",
+				name, strings.Join(Controllers, ", "))
+		}
+		if n < 1 {
+			return fmt.Errorf("maxConcurrentReconciles: %s must be at least 1, got %d", name, n)
+		}
+	}
+	for name := range o.Components {
+		if !slices.Contains(Components, name) {
+			return fmt.Errorf("components: unknown component %q (expected one of %s)",
+				name, strings.Join(Components, ", "))
+		}
+	}
+	if o.LeaderElection && o.LeaderElectionID == "" {
+		return fmt.Errorf("leaderElectionID is required when leader election is enabled")
+	}
+	if len(o.Namespaces) > 0 && len(o.ExcludeNamespaces) > 0 {
+		return fmt.Errorf("excludeNamespaces only applies when watching all namespaces, not with namespaces")
+	}
+	return nil
+}
+
+// Concurrency returns the parallel reconciles for a controller (1 if unset)
+func (o Options) Concurrency(controller string) int {
+	if n := o.MaxConcurrentReconciles[controller]; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// PriorityClassName returns a component's default priority class ("" if unset)
+func (o Options) PriorityClassName(component string) string {
+	return o.Components[component].PriorityClassName
+}
+
+// Resources returns a component's default resources (nil if unset)
+func (o Options) Resources(component string) *corev1.ResourceRequirements {
+	return o.Components[component].Resources
+}
+
+// CacheOptions scopes the manager cache to Namespaces, or to every namespace
+// but ExcludeNamespaces
+func (o Options) CacheOptions() cache.Options {
+	if len(o.Namespaces) == 0 {
+		if len(o.ExcludeNamespaces) == 0 {
+			return cache.Options{}
+		}
+		selectors := make([]fields.Selector, 0, len(o.ExcludeNamespaces))
+		for _, ns := range o.ExcludeNamespaces {
+			selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.namespace", ns))
+		}
+		return cache.Options{DefaultFieldSelector: fields.AndSelectors(selectors...)}
+	}
+	namespaces := make(map[string]cache.Config, len(o.Namespaces))
+	for _, ns := range o.Namespaces {
+		namespaces[ns] = cache.Config{}
+	}
+	return cache.Options{DefaultNamespaces: namespaces}
+}
+
+// String summarizes the settings for the startup log
+func (o Options) String() string {
+	names := make([]string, 0, len(o.MaxConcurrentReconciles))
+	for name := range o.MaxConcurrentReconciles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	concurrency := make([]string, 0, len(names))
+	for _, name := range names {
+		concurrency = append(concurrency, name+"="+strconv.Itoa(o.MaxConcurrentReconciles[name]))
+	}
+	namespaces := "all"
+	if len(o.Namespaces) > 0 {
+		namespaces = strings.Join(o.Namespaces, ",")
+	} else if len(o.ExcludeNamespaces) > 0 {
+		namespaces = "all except " + strings.Join(o.ExcludeNamespaces, ",")
+	}
+	var priorityClasses []string
+	for _, component := range Components {
+		if class := o.PriorityClassName(component); class != "" {
+			priorityClasses = append(priorityClasses, component+"="+class)
+		}
+	}
+	return fmt.Sprintf("leaderElection=%t metrics=%s probes=%s namespaces=%s tenantConfig=%s maxConcurrentReconciles=%s priorityClasses=%s",
+		o.LeaderElection, o.MetricsBindAddress, o.HealthProbeBindAddress, namespaces, o.TenantConfig,
+		strings.Join(concurrency, ","), strings.Join(priorityClasses, ","))
+}
+
+func isController(name string) bool {
+	for _, c := range Controllers {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseConcurrency parses "archiver=4,connector=2"
+func parseConcurrency(value string) (map[string]int, error) {
+	concurrency := map[string]int{}
+	for _, item := range splitList(value) {
+		name, count, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected controller=count, got %q", item)
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, fmt.Errorf("invalid count for %s: %q", name, count)
+		}
+		concurrency[strings.TrimSpace(name)] = n
+	}
+	return concurrency, nil
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func resolve(t *testing.T, args ...string) (Options, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f := BindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	return f.Resolve()
+}
+
+func TestResolve_Defaults(t *testing.T) {
+	opts, err := resolve(t)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if opts.LeaderElection || opts.MetricsBindAddress != "0" || opts.HealthProbeBindAddress != ":8081" {
+		t.Errorf("unexpected defaults: %+v", opts)
+	}
+	if opts.Concurrency("archiver") != 4 || opts.Concurrency("connector") != 1 {
+		t.Errorf("expected archiver=4 connector=1, got %v", opts.MaxConcurrentReconciles)
+	}
+	if opts.CacheOptions().DefaultNamespaces != nil {
+		t.Error("expected all namespaces to be watched by default")
+	}
+}
+
+func TestResolve_ConfigFile(t *testing.T) {
+	path := writeConfig(t, `
+leaderElection: true
+metricsBindAddress: ":8443"
+namespaces: [ssmd, ssmd-dev]
+maxConcurrentReconciles:
+  connector: 2
+`)
+	opts, err := resolve(t, "--config", path)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if !opts.LeaderElection || opts.MetricsBindAddress != ":8443" || opts.HealthProbeBindAddress != ":8081" {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+	// File entries merge with the default archiver concurrency
+	if opts.Concurrency("archiver") != 4 || opts.Concurrency("connector") != 2 {
+		t.Errorf("expected archiver=4 connector=2, got %v", opts.MaxConcurrentReconciles)
+	}
+	namespaces := opts.CacheOptions().DefaultNamespaces
+	if len(namespaces) != 2 {
+		t.Errorf("expected cache scoped to 2 namespaces, got %v", namespaces)
+	}
+}
+
+func TestResolve_FlagsOverrideFile(t *testing.T) {
+	path := writeConfig(t, `
+leaderElection: true
+healthProbeBindAddress: ":9000"
+maxConcurrentReconciles:
+  archiver: 8
+`)
+	opts, err := resolve(t, "--config", path, "--leader-elect=false", "--max-concurrent-reconciles", "archiver=2,snap=3",
+		"--namespaces", "ssmd")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if opts.LeaderElection {
+		t.Error("expected --leader-elect=false to override the file")
+	}
+	if opts.HealthProbeBindAddress != ":9000" {
+		t.Errorf("expected unset flag to keep the file value, got %s", opts.HealthProbeBindAddress)
+	}
+	if !reflect.DeepEqual(opts.MaxConcurrentReconciles, map[string]int{"archiver": 2, "snap": 3}) {
+		t.Errorf("unexpected concurrency: %v", opts.MaxConcurrentReconciles)
+	}
+	if !reflect.DeepEqual(opts.Namespaces, []string{"ssmd"}) {
+		t.Errorf("unexpected namespaces: %v", opts.Namespaces)
+	}
+}
+
+func TestResolve_TenantScoping(t *testing.T) {
+	opts, err := resolve(t)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if opts.TenantConfig != "ssmd-tenant" {
+		t.Errorf("expected the default TenantConfig name, got %q", opts.TenantConfig)
+	}
+
+	path := writeConfig(t, "excludeNamespaces: [desk-a, desk-b]\n")
+	opts, err = resolve(t, "--config", path, "--tenant-config", "")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if opts.TenantConfig != "" {
+		t.Errorf("expected --tenant-config to disable tenancy, got %q", opts.TenantConfig)
+	}
+	cacheOpts := opts.CacheOptions()
+	if cacheOpts.DefaultNamespaces != nil {
+		t.Errorf("expected all namespaces watched, got %v", cacheOpts.DefaultNamespaces)
+	}
+	if got := cacheOpts.DefaultFieldSelector.String(); got != "metadata.namespace!=desk-a,metadata.namespace!=desk-b" {
+		t.Errorf("unexpected field selector: %s", got)
+	}
+	if !strings.Contains(opts.String(), "namespaces=all except desk-a,desk-b") {
+		t.Errorf("unexpected summary: %s", opts.String())
+	}
+}
+
+func TestResolve_ComponentDefaults(t *testing.T) {
+	path := writeConfig(t, `
+components:
+  connector:
+    priorityClassName: ssmd-standard
+    resources:
+      requests:
+        cpu: 250m
+        memory: 256Mi
+  sync:
+    priorityClassName: ssmd-batch
+`)
+	opts, err := resolve(t, "--config", path, "--connector-priority-class", "ssmd-critical")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if got := opts.PriorityClassName("connector"); got != "ssmd-critical" {
+		t.Errorf("expected the flag to override the file, got %q", got)
+	}
+	if got := opts.PriorityClassName("sync"); got != "ssmd-batch" {
+		t.Errorf("expected sync priority class from the file, got %q", got)
+	}
+	resources := opts.Resources("connector")
+	if resources == nil || resources.Requests.Memory().String() != "256Mi" {
+		t.Errorf("expected connector resources from the file, got %+v", resources)
+	}
+	if opts.Resources("archiver") != nil || opts.PriorityClassName("archiver") != "" {
+		t.Error("expected no archiver defaults")
+	}
+	if !strings.Contains(opts.String(), "priorityClasses=connector=ssmd-critical,sync=ssmd-batch") {
+		t.Errorf("unexpected summary: %s", opts.String())
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"unknown key", "leaderElect: true\n", "unknown field"},
+		{"unknown controller", "maxConcurrentReconciles:\n  archivers: 2\n", "unknown controller"},
+		{"zero concurrency", "maxConcurrentReconciles:\n  connector: 0\n", "at least 1"},
+		{"no lease name", "leaderElection: true\nleaderElectionID: \"\"\n", "leaderElectionID"},
+		{"unknown component", "components:\n  connectors:\n    priorityClassName: ssmd-critical\n", "unknown component"},
+		{"include and exclude", "namespaces: [desk-a]\nexcludeNamespaces: [desk-b]\n", "excludeNamespaces"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
+
+func TestParseConcurrency_Invalid(t *testing.T) {
+	for _, value := range []string{"archiver", "archiver=x"} {
+		if _, err := parseConcurrency(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	opts := Defaults()
+	opts.Namespaces = []string{"ssmd"}
+	opts.MaxConcurrentReconciles["connector"] = 2
+
+	got := opts.String()
+	if !strings.Contains(got, "namespaces=ssmd") || !strings.Contains(got, "maxConcurrentReconciles=archiver=4,connector=2") {
+		t.Errorf("unexpected summary: %s", got)
+	}
+}
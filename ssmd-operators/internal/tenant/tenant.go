@@ -0,0 +1,181 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tenant lets several desks share a cluster, each with its own
+// operator scoped to its namespaces.
+//
+// A desk's TenantConfig names the tenant, the labels set on everything the
+// operator creates, and the desk's default images and resources. Controllers
+// write through NewClient so every child carries the tenant labels, webhooks
+// default images and resources through Get, and stream provisioning records
+// the tenant on the streams it creates.
+package tenant
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// Label is the label naming the tenant on every object the operator creates
+const Label = "ssmd.io/tenant"
+
+// DefaultConfigName is the TenantConfig read from each namespace
+const DefaultConfigName = "ssmd-tenant"
+
+// Get reads the TenantConfig name in namespace. It returns nil, nil when name
+// is empty, the TenantConfig does not exist or its CRD is not installed.
+func Get(ctx context.Context, reader client.Reader, namespace, name string) (*ssmdv1alpha1.TenantConfig, error) {
+	if name == "" || namespace == "" {
+		return nil, nil
+	}
+	config := &ssmdv1alpha1.TenantConfig{}
+	err := reader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, config)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Name returns the tenant of namespace, or "" if it has none
+func Name(ctx context.Context, reader client.Reader, namespace, configName string) (string, error) {
+	config, err := Get(ctx, reader, namespace, configName)
+	if err != nil || config == nil {
+		return "", err
+	}
+	return config.Spec.Tenant, nil
+}
+
+// Labels returns the labels a tenant sets on its objects. The tenant label
+// wins over a spec.labels entry of the same key.
+func Labels(config *ssmdv1alpha1.TenantConfig) map[string]string {
+	labels := make(map[string]string, len(config.Spec.Labels)+1)
+	for k, v := range config.Spec.Labels {
+		labels[k] = v
+	}
+	labels[Label] = config.Spec.Tenant
+	return labels
+}
+
+// SetLabels adds labels to obj and to the pod template of workloads.
+// A Job's pod template is immutable, so it is only labelled on create.
+func SetLabels(obj client.Object, labels map[string]string, create bool) {
+	obj.SetLabels(merge(obj.GetLabels(), labels))
+
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		setMetaLabels(&o.Spec.Template.ObjectMeta, labels)
+	case *appsv1.StatefulSet:
+		setMetaLabels(&o.Spec.Template.ObjectMeta, labels)
+	case *batchv1.CronJob:
+		setMetaLabels(&o.Spec.JobTemplate.ObjectMeta, labels)
+		setMetaLabels(&o.Spec.JobTemplate.Spec.Template.ObjectMeta, labels)
+	case *batchv1.Job:
+		if create {
+			setMetaLabels(&o.Spec.Template.ObjectMeta, labels)
+		}
+	}
+}
+
+func setMetaLabels(objectMeta *metav1.ObjectMeta, labels map[string]string) {
+	objectMeta.Labels = merge(objectMeta.Labels, labels)
+}
+
+// merge returns a new map of labels and extra. Controllers often share one
+// map between a selector and pod labels, so labels is never modified.
+func merge(labels, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(labels)+len(extra))
+	for k, v := range labels {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+// +kubebuilder:rbac:groups=ssmd.ssmd.io,resources=tenantconfigs,verbs=get;list;watch
+
+// Client labels every object written through it with the tenant of the
+// object's namespace. The ssmd resources themselves belong to their users
+// and are written unchanged.
+type Client struct {
+	client.Client
+	configName string
+}
+
+// NewClient wraps c so creates, updates and patches carry the labels of the
+// TenantConfig configName in the object's namespace. Without a configName it
+// returns c.
+func NewClient(c client.Client, configName string) client.Client {
+	if configName == "" {
+		return c
+	}
+	return &Client{Client: c, configName: configName}
+}
+
+// Create labels obj, then creates it
+func (c *Client) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.label(ctx, obj, true); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// Update labels obj, then updates it
+func (c *Client) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.label(ctx, obj, false); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// Patch labels obj, so the labels are part of the patch, then patches it
+func (c *Client) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.label(ctx, obj, false); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// label sets the tenant labels on obj, if its namespace has a tenant
+func (c *Client) label(ctx context.Context, obj client.Object, create bool) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	if gvk.Group == ssmdv1alpha1.GroupVersion.Group {
+		return nil
+	}
+	config, err := Get(ctx, c.Client, obj.GetNamespace(), c.configName)
+	if err != nil || config == nil {
+		return err
+	}
+	SetLabels(obj, Labels(config), create)
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+func newTenantClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	tenantConfig := &ssmdv1alpha1.TenantConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: DefaultConfigName, Namespace: "desk-a"},
+		Spec: ssmdv1alpha1.TenantConfigSpec{
+			Tenant: "desk-a",
+			Labels: map[string]string{"team": "rates", Label: "ignored"},
+		},
+	}
+	objs = append(objs, tenantConfig)
+	return NewClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(), DefaultConfigName)
+}
+
+func newDeployment(namespace string) *appsv1.Deployment {
+	selector := map[string]string{"app.kubernetes.io/instance": "kalshi"}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "kalshi", Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: selector},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "connector", Image: "connector"}}},
+			},
+		},
+	}
+}
+
+func TestLabels_TenantWins(t *testing.T) {
+	labels := Labels(&ssmdv1alpha1.TenantConfig{Spec: ssmdv1alpha1.TenantConfigSpec{
+		Tenant: "desk-a",
+		Labels: map[string]string{"team": "rates", Label: "desk-b"},
+	}})
+	if labels[Label] != "desk-a" || labels["team"] != "rates" {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+}
+
+func TestClient_CreateLabelsDeploymentAndPodTemplate(t *testing.T) {
+	c := newTenantClient(t)
+	ctx := context.Background()
+	if err := c.Create(ctx, newDeployment("desk-a")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := c.Get(ctx, types.NamespacedName{Name: "kalshi", Namespace: "desk-a"}, deployment); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	for _, labels := range []map[string]string{deployment.Labels, deployment.Spec.Template.Labels} {
+		if labels[Label] != "desk-a" || labels["team"] != "rates" {
+			t.Errorf("expected tenant labels, got %v", labels)
+		}
+	}
+	if len(deployment.Spec.Selector.MatchLabels) != 1 {
+		t.Errorf("selector must not change, got %v", deployment.Spec.Selector.MatchLabels)
+	}
+}
+
+func TestClient_UpdateLeavesJobPodTemplate(t *testing.T) {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "sync", Namespace: "desk-a"}}
+	c := newTenantClient(t, job)
+	ctx := context.Background()
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(job), job); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if err := c.Update(ctx, job); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if job.Labels[Label] != "desk-a" {
+		t.Errorf("expected tenant label on the Job, got %v", job.Labels)
+	}
+	if len(job.Spec.Template.Labels) != 0 {
+		t.Errorf("expected the immutable pod template unchanged, got %v", job.Spec.Template.Labels)
+	}
+}
+
+func TestClient_PatchIncludesLabels(t *testing.T) {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "env", Namespace: "desk-a"}}
+	c := newTenantClient(t, configMap)
+	ctx := context.Background()
+
+	patch := client.MergeFrom(configMap.DeepCopy())
+	configMap.Data = map[string]string{"env.yaml": "name: prod"}
+	if err := c.Patch(ctx, configMap, patch); err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+
+	got := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(configMap), got); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Labels[Label] != "desk-a" {
+		t.Errorf("expected tenant label in the patch, got %v", got.Labels)
+	}
+}
+
+func TestClient_SkipsSsmdResourcesAndOtherNamespaces(t *testing.T) {
+	c := newTenantClient(t)
+	ctx := context.Background()
+
+	connector := &ssmdv1alpha1.Connector{ObjectMeta: metav1.ObjectMeta{Name: "kalshi", Namespace: "desk-a"}}
+	if err := c.Create(ctx, connector); err != nil {
+		t.Fatalf("create connector: %v", err)
+	}
+	if _, ok := connector.Labels[Label]; ok {
+		t.Error("expected the Connector to be written unchanged")
+	}
+
+	deployment := newDeployment("shared")
+	if err := c.Create(ctx, deployment); err != nil {
+		t.Fatalf("create deployment: %v", err)
+	}
+	if _, ok := deployment.Labels[Label]; ok {
+		t.Error("expected no tenant label without a TenantConfig in the namespace")
+	}
+}
+
+func TestNewClient_NoConfigName(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+	if NewClient(c, "") != c {
+		t.Error("expected the client unwrapped without a TenantConfig name")
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/feedconfig"
+)
+
+// log is for logging in this package.
+var archiverlog = logf.Log.WithName("archiver-resource")
+
+// SetupArchiverWebhookWithManager registers the webhook for Archiver in the manager.
+func SetupArchiverWebhookWithManager(mgr ctrl.Manager, imageVersionsConfigMap, tenantConfig string) error {
+	return ctrl.NewWebhookManagedBy(mgr, &ssmdv1alpha1.Archiver{}).
+		WithValidator(&ArchiverCustomValidator{}).
+		WithDefaulter(&ArchiverCustomDefaulter{
+			images: newImageDefaulter(mgr, imageVersionsConfigMap, tenantConfig),
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-ssmd-ssmd-io-v1alpha1-archiver,mutating=true,failurePolicy=fail,sideEffects=None,groups=ssmd.ssmd.io,resources=archivers,verbs=create;update,versions=v1alpha1,name=marchiver-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ArchiverCustomDefaulter sets the archiver and sync images from the TenantConfig or
+// image versions ConfigMap when neither the CR nor the feed defaults set one, and
+// the resources from the TenantConfig
+type ArchiverCustomDefaulter struct {
+	images imageDefaulter
+}
+
+// Default implements admission.Defaulter so a webhook will be registered for the type Archiver.
+func (d *ArchiverCustomDefaulter) Default(ctx context.Context, archiver *ssmdv1alpha1.Archiver) error {
+	archiverlog.Info("Defaulting for Archiver", "name", archiver.GetName())
+
+	if archiver.Spec.Resources == nil {
+		resources, err := d.images.defaultResources(ctx, archiver.Namespace, "archiver")
+		if err != nil {
+			// The controller leaves resources unset
+			archiverlog.Error(err, "Failed to read TenantConfig", "name", archiver.GetName())
+		}
+		archiver.Spec.Resources = resources
+	}
+
+	if archiver.Spec.Image == "" && !d.hasFeedImage(ctx, archiver) {
+		image, err := d.images.defaultImage(ctx, archiver.Namespace, "archiver")
+		if err != nil {
+			// The controller falls back to its built-in image
+			archiverlog.Error(err, "Failed to read image versions", "name", archiver.GetName())
+			return nil
+		}
+		archiver.Spec.Image = image
+	}
+
+	if archiver.Spec.Sync != nil && archiver.Spec.Sync.Image == "" {
+		image, err := d.images.defaultImage(ctx, archiver.Namespace, "sync")
+		if err != nil {
+			archiverlog.Error(err, "Failed to read image versions", "name", archiver.GetName())
+			return nil
+		}
+		archiver.Spec.Sync.Image = image
+	}
+	return nil
+}
+
+// hasFeedImage reports whether the feed ConfigMap sets defaults.archiver, which
+// takes precedence, as in the controller
+func (d *ArchiverCustomDefaulter) hasFeedImage(ctx context.Context, archiver *ssmdv1alpha1.Archiver) bool {
+	if archiver.Spec.Feed == "" {
+		return false
+	}
+	feed, err := feedconfig.Load(ctx, d.images.Reader, archiver.Namespace, archiver.Spec.Feed)
+	if err != nil {
+		archiverlog.Error(err, "Failed to read feed ConfigMap", "name", archiver.GetName())
+		return false
+	}
+	return feed.ArchiverDefaults().ImageRef() != ""
+}
+
+// +kubebuilder:webhook:path=/validate-ssmd-ssmd-io-v1alpha1-archiver,mutating=false,failurePolicy=fail,sideEffects=None,groups=ssmd.ssmd.io,resources=archivers,verbs=create;update,versions=v1alpha1,name=varchiver-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ArchiverCustomValidator validates Archiver sources and storage
+type ArchiverCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type Archiver.
+func (v *ArchiverCustomValidator) ValidateCreate(ctx context.Context, archiver *ssmdv1alpha1.Archiver) (admission.Warnings, error) {
+	archiverlog.Info("Validation for Archiver upon creation", "name", archiver.GetName())
+	return validateArchiver(archiver)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type Archiver.
+func (v *ArchiverCustomValidator) ValidateUpdate(ctx context.Context, oldArchiver, archiver *ssmdv1alpha1.Archiver) (admission.Warnings, error) {
+	archiverlog.Info("Validation for Archiver upon update", "name", archiver.GetName())
+
+	// Never block finalizer removal or metadata-only updates of existing CRs
+	if !archiver.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(oldArchiver.Spec, archiver.Spec) {
+		return nil, nil
+	}
+	return validateArchiver(archiver)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type Archiver.
+func (v *ArchiverCustomValidator) ValidateDelete(ctx context.Context, archiver *ssmdv1alpha1.Archiver) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateArchiver checks that exactly one source style is used and that remote storage is usable
+func validateArchiver(archiver *ssmdv1alpha1.Archiver) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	var allErrs field.ErrorList
+	spec := field.NewPath("spec")
+
+	// Check sources
+	switch {
+	case archiver.Spec.Source != nil && len(archiver.Spec.Sources) > 0:
+		allErrs = append(allErrs, field.Forbidden(spec.Child("sources"), "cannot be set together with spec.source"))
+	case archiver.Spec.Source == nil && len(archiver.Spec.Sources) == 0:
+		allErrs = append(allErrs, field.Required(spec.Child("sources"), "one of spec.source or spec.sources is required"))
+	case archiver.Spec.Source != nil && archiver.Spec.Source.Stream == "":
+		allErrs = append(allErrs, field.Required(spec.Child("source", "stream"), ""))
+	}
+
+	names := map[string]bool{}
+	for i, source := range archiver.Spec.Sources {
+		if names[source.Name] {
+			allErrs = append(allErrs, field.Duplicate(spec.Child("sources").Index(i).Child("name"), source.Name))
+		}
+		names[source.Name] = true
+	}
+
+	// Check stream provisioning
+	allErrs = append(allErrs, validateStreamProvision(spec.Child("stream"), archiver.Spec.Stream)...)
+	if archiver.Spec.Stream != nil && !archiver.Spec.ProvisionStream {
+		warnings = append(warnings, "spec.stream has no effect without spec.provisionStream")
+	}
+
+	// Check remote storage
+	if archiver.Spec.Storage != nil && archiver.Spec.Storage.Remote != nil {
+		remote := archiver.Spec.Storage.Remote
+		remotePath := spec.Child("storage", "remote")
+		if remote.Bucket == "" {
+			allErrs = append(allErrs, field.Required(remotePath.Child("bucket"), ""))
+		}
+		if remote.Endpoint != "" {
+			if remote.Type != "s3" {
+				allErrs = append(allErrs, field.Forbidden(remotePath.Child("endpoint"), "only supported for type s3"))
+			} else if u, err := url.Parse(remote.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+				allErrs = append(allErrs, field.Invalid(remotePath.Child("endpoint"), remote.Endpoint, "must be an absolute URL"))
+			}
+		}
+	}
+
+	// Check periodic sync prerequisites
+	if archiver.Spec.Sync != nil && archiver.Spec.Sync.Schedule != "" {
+		storage := archiver.Spec.Storage
+		if storage == nil || storage.Remote == nil || storage.Local == nil || storage.Local.PVCName == "" {
+			warnings = append(warnings, "spec.sync.schedule has no effect without storage.remote and storage.local.pvcName")
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(ssmdv1alpha1.GroupVersion.WithKind("Archiver").GroupKind(), archiver.Name, allErrs)
+}
+
+// validateStreamProvision checks the JetStream stream settings shared by connectors and archivers
+func validateStreamProvision(path *field.Path, stream *ssmdv1alpha1.StreamProvisionConfig) field.ErrorList {
+	var allErrs field.ErrorList
+	if stream == nil {
+		return allErrs
+	}
+	if stream.MaxAge != "" {
+		if d, err := time.ParseDuration(stream.MaxAge); err != nil || d < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("maxAge"), stream.MaxAge, "must be a non-negative duration (e.g. 48h)"))
+		}
+	}
+	if stream.MaxBytes != nil && stream.MaxBytes.Sign() < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxBytes"), stream.MaxBytes.String(), "must not be negative"))
+	}
+	return allErrs
+}
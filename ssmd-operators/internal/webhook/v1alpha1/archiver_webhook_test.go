@@ -0,0 +1,217 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// newTestReader returns a fake client holding the given objects
+func newTestReader(objs ...client.Object) client.Reader {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = ssmdv1alpha1.AddToScheme(scheme)
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func newImageVersions(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: DefaultImageVersionsConfigMap, Namespace: "ssmd"},
+		Data:       data,
+	}
+}
+
+func newWebhookTestArchiver() *ssmdv1alpha1.Archiver {
+	return &ssmdv1alpha1.Archiver{
+		ObjectMeta: metav1.ObjectMeta{Name: "kalshi-2026-01-04", Namespace: "ssmd"},
+		Spec: ssmdv1alpha1.ArchiverSpec{
+			Source: &ssmdv1alpha1.ArchiverSourceConfig{Stream: "PROD_KALSHI", Filter: "prod.kalshi.json.>"},
+		},
+	}
+}
+
+// --- TestArchiverCustomDefaulter ---
+
+func TestArchiverCustomDefaulter_ImagesFromVersions(t *testing.T) {
+	reader := newTestReader(newImageVersions(map[string]string{
+		"archiver": "ghcr.io/aaronwald/ssmd-archiver:0.4.8",
+		"sync":     "ghcr.io/aaronwald/ssmd-sync:0.1.2",
+	}))
+	d := &ArchiverCustomDefaulter{images: imageDefaulter{Reader: reader, ConfigMapName: DefaultImageVersionsConfigMap}}
+	archiver := newWebhookTestArchiver()
+	archiver.Spec.Sync = &ssmdv1alpha1.SyncConfig{Enabled: true}
+
+	if err := d.Default(context.Background(), archiver); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if archiver.Spec.Image != "ghcr.io/aaronwald/ssmd-archiver:0.4.8" {
+		t.Errorf("unexpected image: %s", archiver.Spec.Image)
+	}
+	if archiver.Spec.Sync.Image != "ghcr.io/aaronwald/ssmd-sync:0.1.2" {
+		t.Errorf("unexpected sync image: %s", archiver.Spec.Sync.Image)
+	}
+}
+
+func TestArchiverCustomDefaulter_KeepsExplicitImage(t *testing.T) {
+	reader := newTestReader(newImageVersions(map[string]string{"archiver": "ghcr.io/aaronwald/ssmd-archiver:0.4.8"}))
+	d := &ArchiverCustomDefaulter{images: imageDefaulter{Reader: reader, ConfigMapName: DefaultImageVersionsConfigMap}}
+	archiver := newWebhookTestArchiver()
+	archiver.Spec.Image = "ghcr.io/aaronwald/ssmd-archiver:dev"
+
+	_ = d.Default(context.Background(), archiver)
+
+	if archiver.Spec.Image != "ghcr.io/aaronwald/ssmd-archiver:dev" {
+		t.Errorf("explicit image was overwritten: %s", archiver.Spec.Image)
+	}
+}
+
+func TestArchiverCustomDefaulter_LeavesFeedDefaults(t *testing.T) {
+	reader := newTestReader(
+		newImageVersions(map[string]string{"archiver": "ghcr.io/aaronwald/ssmd-archiver:0.4.8"}),
+		newFeedConfigMap("defaults:\n  archiver:\n    image: ghcr.io/aaronwald/ssmd-archiver\n    version: \"0.5.0\"\n"),
+	)
+	d := &ArchiverCustomDefaulter{images: imageDefaulter{Reader: reader, ConfigMapName: DefaultImageVersionsConfigMap}}
+	archiver := newWebhookTestArchiver()
+	archiver.Spec.Feed = "kalshi"
+
+	_ = d.Default(context.Background(), archiver)
+
+	if archiver.Spec.Image != "" {
+		t.Errorf("expected image left to the feed defaults, got %s", archiver.Spec.Image)
+	}
+}
+
+func TestArchiverCustomDefaulter_NoVersionsConfigMap(t *testing.T) {
+	d := &ArchiverCustomDefaulter{images: imageDefaulter{Reader: newTestReader(), ConfigMapName: DefaultImageVersionsConfigMap}}
+	archiver := newWebhookTestArchiver()
+
+	if err := d.Default(context.Background(), archiver); err != nil || archiver.Spec.Image != "" {
+		t.Errorf("expected image left empty, got %q (err %v)", archiver.Spec.Image, err)
+	}
+}
+
+// --- TestArchiverCustomValidator ---
+
+func TestArchiverCustomValidator_Valid(t *testing.T) {
+	v := &ArchiverCustomValidator{}
+
+	if _, err := v.ValidateCreate(context.Background(), newWebhookTestArchiver()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestArchiverCustomValidator_SourceAndSources(t *testing.T) {
+	v := &ArchiverCustomValidator{}
+	archiver := newWebhookTestArchiver()
+	archiver.Spec.Sources = []ssmdv1alpha1.SourceConfig{{Name: "kalshi", Stream: "PROD_KALSHI", Consumer: "c", Filter: "f"}}
+
+	_, err := v.ValidateCreate(context.Background(), archiver)
+	if err == nil || !strings.Contains(err.Error(), "spec.sources") {
+		t.Errorf("expected spec.sources error, got %v", err)
+	}
+}
+
+func TestArchiverCustomValidator_NoSource(t *testing.T) {
+	v := &ArchiverCustomValidator{}
+	archiver := newWebhookTestArchiver()
+	archiver.Spec.Source = nil
+
+	if _, err := v.ValidateCreate(context.Background(), archiver); err == nil {
+		t.Error("expected error without a source")
+	}
+}
+
+func TestArchiverCustomValidator_DuplicateSourceNames(t *testing.T) {
+	v := &ArchiverCustomValidator{}
+	archiver := newWebhookTestArchiver()
+	archiver.Spec.Source = nil
+	archiver.Spec.Sources = []ssmdv1alpha1.SourceConfig{
+		{Name: "kalshi", Stream: "PROD_KALSHI", Consumer: "a", Filter: "f"},
+		{Name: "kalshi", Stream: "PROD_KALSHI_2", Consumer: "b", Filter: "f"},
+	}
+
+	_, err := v.ValidateCreate(context.Background(), archiver)
+	if err == nil || !strings.Contains(err.Error(), "spec.sources[1].name") {
+		t.Errorf("expected duplicate name error, got %v", err)
+	}
+}
+
+func TestArchiverCustomValidator_RemoteStorage(t *testing.T) {
+	v := &ArchiverCustomValidator{}
+	archiver := newWebhookTestArchiver()
+	archiver.Spec.Storage = &ssmdv1alpha1.StorageConfig{
+		Remote: &ssmdv1alpha1.RemoteStorageConfig{Type: "gcs", Endpoint: "http://minio:9000"},
+	}
+
+	_, err := v.ValidateCreate(context.Background(), archiver)
+	if err == nil || !strings.Contains(err.Error(), "spec.storage.remote.bucket") || !strings.Contains(err.Error(), "spec.storage.remote.endpoint") {
+		t.Errorf("expected bucket and endpoint errors, got %v", err)
+	}
+}
+
+func TestArchiverCustomValidator_ScheduleWithoutStorageWarns(t *testing.T) {
+	v := &ArchiverCustomValidator{}
+	archiver := newWebhookTestArchiver()
+	archiver.Spec.Sync = &ssmdv1alpha1.SyncConfig{Enabled: true, Schedule: "0 * * * *"}
+
+	warnings, err := v.ValidateCreate(context.Background(), archiver)
+	if err != nil || len(warnings) != 1 {
+		t.Errorf("expected one warning, got %v (err %v)", warnings, err)
+	}
+}
+
+func TestArchiverCustomValidator_StreamProvision(t *testing.T) {
+	v := &ArchiverCustomValidator{}
+	archiver := newWebhookTestArchiver()
+	archiver.Spec.Stream = &ssmdv1alpha1.StreamProvisionConfig{MaxAge: "2 days"}
+
+	warnings, err := v.ValidateCreate(context.Background(), archiver)
+	if err == nil || !strings.Contains(err.Error(), "spec.stream.maxAge") {
+		t.Errorf("expected maxAge error, got %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected a warning for stream without provisionStream, got %v", warnings)
+	}
+
+	archiver.Spec.ProvisionStream = true
+	archiver.Spec.Stream.MaxAge = "48h"
+	if warnings, err := v.ValidateCreate(context.Background(), archiver); err != nil || len(warnings) != 0 {
+		t.Errorf("expected valid stream settings, got %v (warnings %v)", err, warnings)
+	}
+}
+
+func TestArchiverCustomValidator_UpdateSkipsUnchangedSpec(t *testing.T) {
+	v := &ArchiverCustomValidator{}
+	archiver := newWebhookTestArchiver()
+	archiver.Spec.Source = nil
+	updated := archiver.DeepCopy()
+	updated.Finalizers = []string{"ssmd.ssmd.io/archiver-finalizer"}
+
+	if _, err := v.ValidateUpdate(context.Background(), archiver, updated); err != nil {
+		t.Errorf("metadata-only update of an existing CR should be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/feedconfig"
+)
+
+// log is for logging in this package.
+var connectorlog = logf.Log.WithName("connector-resource")
+
+// SetupConnectorWebhookWithManager registers the webhook for Connector in the manager.
+func SetupConnectorWebhookWithManager(mgr ctrl.Manager, imageVersionsConfigMap, tenantConfig string) error {
+	return ctrl.NewWebhookManagedBy(mgr, &ssmdv1alpha1.Connector{}).
+		WithValidator(&ConnectorCustomValidator{Reader: mgr.GetAPIReader()}).
+		WithDefaulter(&ConnectorCustomDefaulter{
+			Reader: mgr.GetAPIReader(),
+			images: newImageDefaulter(mgr, imageVersionsConfigMap, tenantConfig),
+		}).
+		Complete()
+}
+
+// getFeedConfigMap returns the feed ConfigMap for a Connector, or nil if it does not exist
+func getFeedConfigMap(ctx context.Context, reader client.Reader, connector *ssmdv1alpha1.Connector) (*corev1.ConfigMap, error) {
+	configMap := &corev1.ConfigMap{}
+	err := reader.Get(ctx, types.NamespacedName{Name: feedconfig.ConfigMapName(connector.Spec.Feed), Namespace: connector.Namespace}, configMap)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return configMap, nil
+}
+
+// +kubebuilder:webhook:path=/mutate-ssmd-ssmd-io-v1alpha1-connector,mutating=true,failurePolicy=fail,sideEffects=None,groups=ssmd.ssmd.io,resources=connectors,verbs=create;update,versions=v1alpha1,name=mconnector-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ConnectorCustomDefaulter sets the connector image from the TenantConfig or image
+// versions ConfigMap when neither the CR nor the feed defaults set one, and the
+// resources from the TenantConfig
+type ConnectorCustomDefaulter struct {
+	Reader client.Reader
+	images imageDefaulter
+}
+
+// Default implements admission.Defaulter so a webhook will be registered for the type Connector.
+func (d *ConnectorCustomDefaulter) Default(ctx context.Context, connector *ssmdv1alpha1.Connector) error {
+	connectorlog.Info("Defaulting for Connector", "name", connector.GetName())
+
+	if connector.Spec.Resources == nil {
+		resources, err := d.images.defaultResources(ctx, connector.Namespace, "connector")
+		if err != nil {
+			// The controller leaves resources unset
+			connectorlog.Error(err, "Failed to read TenantConfig", "name", connector.GetName())
+		}
+		connector.Spec.Resources = resources
+	}
+
+	if connector.Spec.Image != "" {
+		return nil
+	}
+
+	// Feed defaults take precedence, as in the controller
+	configMap, err := getFeedConfigMap(ctx, d.Reader, connector)
+	if err != nil {
+		connectorlog.Error(err, "Failed to read feed ConfigMap", "name", connector.GetName())
+		return nil
+	}
+	if configMap != nil {
+		if feed, err := feedconfig.Parse(configMap.Data[feedconfig.ConfigMapKey]); err == nil &&
+			feed.ConnectorDefaults().ImageRef() != "" {
+			return nil
+		}
+	}
+
+	image, err := d.images.defaultImage(ctx, connector.Namespace, "connector")
+	if err != nil {
+		// The controller falls back to its built-in image
+		connectorlog.Error(err, "Failed to read image versions", "name", connector.GetName())
+		return nil
+	}
+	connector.Spec.Image = image
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-ssmd-ssmd-io-v1alpha1-connector,mutating=false,failurePolicy=fail,sideEffects=None,groups=ssmd.ssmd.io,resources=connectors,verbs=create;update,versions=v1alpha1,name=vconnector-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ConnectorCustomValidator validates the Connector date and that its feed exists
+type ConnectorCustomValidator struct {
+	Reader client.Reader
+}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type Connector.
+func (v *ConnectorCustomValidator) ValidateCreate(ctx context.Context, connector *ssmdv1alpha1.Connector) (admission.Warnings, error) {
+	connectorlog.Info("Validation for Connector upon creation", "name", connector.GetName())
+	return v.validateConnector(ctx, connector)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type Connector.
+func (v *ConnectorCustomValidator) ValidateUpdate(ctx context.Context, oldConnector, connector *ssmdv1alpha1.Connector) (admission.Warnings, error) {
+	connectorlog.Info("Validation for Connector upon update", "name", connector.GetName())
+
+	// Never block finalizer removal or metadata-only updates of existing CRs
+	if !connector.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(oldConnector.Spec, connector.Spec) {
+		return nil, nil
+	}
+	return v.validateConnector(ctx, connector)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type Connector.
+func (v *ConnectorCustomValidator) ValidateDelete(ctx context.Context, connector *ssmdv1alpha1.Connector) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateConnector checks the date format and that the feed ConfigMap exists.
+// Without a feed ConfigMap the controller falls back to the spec.exchange
+// defaults, so a missing feed is only an error when no exchange is set.
+func (v *ConnectorCustomValidator) validateConnector(ctx context.Context, connector *ssmdv1alpha1.Connector) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	var allErrs field.ErrorList
+	spec := field.NewPath("spec")
+
+	// Check date
+	if connector.Spec.Date != "" {
+		if _, err := time.Parse("2006-01-02", connector.Spec.Date); err != nil {
+			allErrs = append(allErrs, field.Invalid(spec.Child("date"), connector.Spec.Date, "must be a date in YYYY-MM-DD format"))
+		}
+	}
+
+	// Check stream provisioning
+	allErrs = append(allErrs, validateStreamProvision(spec.Child("stream"), connector.Spec.Stream)...)
+	if connector.Spec.Stream != nil && !connector.Spec.ProvisionStream {
+		warnings = append(warnings, "spec.stream has no effect without spec.provisionStream")
+	}
+
+	// Check feed
+	configMap, err := getFeedConfigMap(ctx, v.Reader, connector)
+	if err != nil {
+		return nil, err
+	}
+	feedName := feedconfig.ConfigMapName(connector.Spec.Feed)
+	switch {
+	case configMap == nil && connector.Spec.Exchange == "":
+		allErrs = append(allErrs, field.Invalid(spec.Child("feed"), connector.Spec.Feed,
+			fmt.Sprintf("feed ConfigMap %s not found (set spec.exchange to use exchange defaults)", feedName)))
+	case configMap == nil:
+		warnings = append(warnings, fmt.Sprintf("feed ConfigMap %s not found, using %s exchange defaults", feedName, connector.Spec.Exchange))
+	default:
+		if feedYAML, ok := configMap.Data[feedconfig.ConfigMapKey]; ok {
+			if _, err := feedconfig.Parse(feedYAML); err != nil {
+				allErrs = append(allErrs, field.Invalid(spec.Child("feed"), connector.Spec.Feed,
+					fmt.Sprintf("feed ConfigMap %s has invalid feed.yaml: %v", feedName, err)))
+			}
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(ssmdv1alpha1.GroupVersion.WithKind("Connector").GroupKind(), connector.Name, allErrs)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/tenant"
+)
+
+func newFeedConfigMap(feedYAML string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "feed-kalshi", Namespace: "ssmd"},
+		Data:       map[string]string{"feed.yaml": feedYAML},
+	}
+}
+
+func newWebhookTestConnector() *ssmdv1alpha1.Connector {
+	return &ssmdv1alpha1.Connector{
+		ObjectMeta: metav1.ObjectMeta{Name: "kalshi-main", Namespace: "ssmd"},
+		Spec:       ssmdv1alpha1.ConnectorSpec{Feed: "kalshi"},
+	}
+}
+
+// --- TestConnectorCustomDefaulter ---
+
+func TestConnectorCustomDefaulter_ImageFromVersions(t *testing.T) {
+	reader := newTestReader(newFeedConfigMap("name: kalshi\n"),
+		newImageVersions(map[string]string{"connector": "ghcr.io/aaronwald/ssmd-connector:0.9.0"}))
+	d := &ConnectorCustomDefaulter{Reader: reader, images: imageDefaulter{Reader: reader, ConfigMapName: DefaultImageVersionsConfigMap}}
+	connector := newWebhookTestConnector()
+
+	if err := d.Default(context.Background(), connector); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if connector.Spec.Image != "ghcr.io/aaronwald/ssmd-connector:0.9.0" {
+		t.Errorf("unexpected image: %s", connector.Spec.Image)
+	}
+}
+
+func TestConnectorCustomDefaulter_FeedDefaultsTakePrecedence(t *testing.T) {
+	reader := newTestReader(
+		newFeedConfigMap("name: kalshi\ndefaults:\n  connector:\n    image: ghcr.io/aaronwald/ssmd-connector\n    version: 0.8.0\n"),
+		newImageVersions(map[string]string{"connector": "ghcr.io/aaronwald/ssmd-connector:0.9.0"}))
+	d := &ConnectorCustomDefaulter{Reader: reader, images: imageDefaulter{Reader: reader, ConfigMapName: DefaultImageVersionsConfigMap}}
+	connector := newWebhookTestConnector()
+
+	_ = d.Default(context.Background(), connector)
+
+	if connector.Spec.Image != "" {
+		t.Errorf("expected image left to the feed defaults, got %s", connector.Spec.Image)
+	}
+}
+
+func newTenantConfig() *ssmdv1alpha1.TenantConfig {
+	return &ssmdv1alpha1.TenantConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: tenant.DefaultConfigName, Namespace: "ssmd"},
+		Spec: ssmdv1alpha1.TenantConfigSpec{
+			Tenant: "desk-a",
+			Images: map[string]string{"connector": "ghcr.io/desk-a/ssmd-connector:1.0.0"},
+			Resources: map[string]corev1.ResourceRequirements{
+				"connector": {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")}},
+			},
+		},
+	}
+}
+
+func TestConnectorCustomDefaulter_TenantConfig(t *testing.T) {
+	reader := newTestReader(newFeedConfigMap("name: kalshi\n"), newTenantConfig(),
+		newImageVersions(map[string]string{"connector": "ghcr.io/aaronwald/ssmd-connector:0.9.0"}))
+	d := &ConnectorCustomDefaulter{Reader: reader, images: imageDefaulter{
+		Reader: reader, ConfigMapName: DefaultImageVersionsConfigMap, TenantConfigName: tenant.DefaultConfigName,
+	}}
+	connector := newWebhookTestConnector()
+
+	if err := d.Default(context.Background(), connector); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if connector.Spec.Image != "ghcr.io/desk-a/ssmd-connector:1.0.0" {
+		t.Errorf("expected the tenant image over the image versions, got %s", connector.Spec.Image)
+	}
+	if connector.Spec.Resources == nil || connector.Spec.Resources.Requests.Cpu().String() != "250m" {
+		t.Errorf("expected the tenant resources, got %v", connector.Spec.Resources)
+	}
+}
+
+func TestConnectorCustomDefaulter_TenantConfigKeepsSpec(t *testing.T) {
+	reader := newTestReader(newFeedConfigMap("name: kalshi\n"), newTenantConfig())
+	d := &ConnectorCustomDefaulter{Reader: reader, images: imageDefaulter{Reader: reader, TenantConfigName: tenant.DefaultConfigName}}
+	connector := newWebhookTestConnector()
+	connector.Spec.Image = "ghcr.io/aaronwald/ssmd-connector:0.8.0"
+	connector.Spec.Resources = &corev1.ResourceRequirements{}
+
+	_ = d.Default(context.Background(), connector)
+
+	if connector.Spec.Image != "ghcr.io/aaronwald/ssmd-connector:0.8.0" {
+		t.Errorf("expected the spec image kept, got %s", connector.Spec.Image)
+	}
+	if len(connector.Spec.Resources.Requests) != 0 {
+		t.Errorf("expected the spec resources kept, got %v", connector.Spec.Resources)
+	}
+}
+
+// --- TestConnectorCustomValidator ---
+
+func TestConnectorCustomValidator_FeedExists(t *testing.T) {
+	v := &ConnectorCustomValidator{Reader: newTestReader(newFeedConfigMap("name: kalshi\n"))}
+
+	warnings, err := v.ValidateCreate(context.Background(), newWebhookTestConnector())
+	if err != nil || len(warnings) != 0 {
+		t.Errorf("expected no errors or warnings, got %v (err %v)", warnings, err)
+	}
+}
+
+func TestConnectorCustomValidator_MissingFeed(t *testing.T) {
+	v := &ConnectorCustomValidator{Reader: newTestReader()}
+
+	_, err := v.ValidateCreate(context.Background(), newWebhookTestConnector())
+	if err == nil || !strings.Contains(err.Error(), "feed-kalshi not found") {
+		t.Errorf("expected missing feed error, got %v", err)
+	}
+}
+
+func TestConnectorCustomValidator_MissingFeedWithExchange(t *testing.T) {
+	v := &ConnectorCustomValidator{Reader: newTestReader()}
+	connector := newWebhookTestConnector()
+	connector.Spec.Exchange = ssmdv1alpha1.ExchangeTypeKalshi
+
+	warnings, err := v.ValidateCreate(context.Background(), connector)
+	if err != nil || len(warnings) != 1 {
+		t.Errorf("expected a warning only, got %v (err %v)", warnings, err)
+	}
+}
+
+func TestConnectorCustomValidator_InvalidFeedYAML(t *testing.T) {
+	v := &ConnectorCustomValidator{Reader: newTestReader(newFeedConfigMap("name: [kalshi\n"))}
+
+	if _, err := v.ValidateCreate(context.Background(), newWebhookTestConnector()); err == nil {
+		t.Error("expected error for invalid feed.yaml")
+	}
+}
+
+func TestConnectorCustomValidator_InvalidDate(t *testing.T) {
+	v := &ConnectorCustomValidator{Reader: newTestReader(newFeedConfigMap("name: kalshi\n"))}
+	connector := newWebhookTestConnector()
+	connector.Spec.Date = "01/04/2026"
+
+	_, err := v.ValidateCreate(context.Background(), connector)
+	if err == nil || !strings.Contains(err.Error(), "spec.date") {
+		t.Errorf("expected spec.date error, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var harmanlog = logf.Log.WithName("harman-resource")
+
+// exchangeSecretKeys are the keys the harman controller reads from the exchange secret
+var exchangeSecretKeys = map[ssmdv1alpha1.ExchangeType][]string{
+	ssmdv1alpha1.ExchangeTypeKalshi:     {"api-key", "private-key"},
+	ssmdv1alpha1.ExchangeTypeKraken:     {"api-key", "api-secret"},
+	ssmdv1alpha1.ExchangeTypePolymarket: {"api-key", "secret"},
+}
+
+// SetupHarmanWebhookWithManager registers the webhook for Harman in the manager.
+func SetupHarmanWebhookWithManager(mgr ctrl.Manager, imageVersionsConfigMap, tenantConfig string) error {
+	return ctrl.NewWebhookManagedBy(mgr, &ssmdv1alpha1.Harman{}).
+		WithValidator(&HarmanCustomValidator{Reader: mgr.GetAPIReader()}).
+		WithDefaulter(&HarmanCustomDefaulter{
+			images: newImageDefaulter(mgr, imageVersionsConfigMap, tenantConfig),
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-ssmd-ssmd-io-v1alpha1-harman,mutating=true,failurePolicy=fail,sideEffects=None,groups=ssmd.ssmd.io,resources=harmans,verbs=create;update,versions=v1alpha1,name=mharman-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// HarmanCustomDefaulter sets the harman image from the TenantConfig or image
+// versions ConfigMap, and the resources from the TenantConfig
+type HarmanCustomDefaulter struct {
+	images imageDefaulter
+}
+
+// Default implements admission.Defaulter so a webhook will be registered for the type Harman.
+func (d *HarmanCustomDefaulter) Default(ctx context.Context, harman *ssmdv1alpha1.Harman) error {
+	harmanlog.Info("Defaulting for Harman", "name", harman.GetName())
+
+	if harman.Spec.Resources == nil {
+		resources, err := d.images.defaultResources(ctx, harman.Namespace, "harman")
+		if err != nil {
+			// The controller leaves resources unset
+			harmanlog.Error(err, "Failed to read TenantConfig", "name", harman.GetName())
+		}
+		harman.Spec.Resources = resources
+	}
+
+	if harman.Spec.Image != "" {
+		return nil
+	}
+	image, err := d.images.defaultImage(ctx, harman.Namespace, "harman")
+	if err != nil {
+		// Schema validation rejects the CR if the image stays empty
+		harmanlog.Error(err, "Failed to read image versions", "name", harman.GetName())
+		return nil
+	}
+	harman.Spec.Image = image
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-ssmd-ssmd-io-v1alpha1-harman,mutating=false,failurePolicy=fail,sideEffects=None,groups=ssmd.ssmd.io,resources=harmans,verbs=create;update,versions=v1alpha1,name=vharman-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// HarmanCustomValidator validates that the Harman exchange and its credentials agree
+type HarmanCustomValidator struct {
+	Reader client.Reader
+}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type Harman.
+func (v *HarmanCustomValidator) ValidateCreate(ctx context.Context, harman *ssmdv1alpha1.Harman) (admission.Warnings, error) {
+	harmanlog.Info("Validation for Harman upon creation", "name", harman.GetName())
+	return v.validateHarman(ctx, harman)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type Harman.
+func (v *HarmanCustomValidator) ValidateUpdate(ctx context.Context, oldHarman, harman *ssmdv1alpha1.Harman) (admission.Warnings, error) {
+	harmanlog.Info("Validation for Harman upon update", "name", harman.GetName())
+
+	// Never block finalizer removal or metadata-only updates of existing CRs
+	if !harman.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(oldHarman.Spec, harman.Spec) {
+		return nil, nil
+	}
+	return v.validateHarman(ctx, harman)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type Harman.
+func (v *HarmanCustomValidator) ValidateDelete(ctx context.Context, harman *ssmdv1alpha1.Harman) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateHarman checks the exchange environment and that the exchange secret
+// holds the keys the exchange needs. A secret that does not exist yet (e.g.
+// still being synced) is only a warning.
+func (v *HarmanCustomValidator) validateHarman(ctx context.Context, harman *ssmdv1alpha1.Harman) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	var allErrs field.ErrorList
+	exchange := harman.Spec.Exchange
+	exchangePath := field.NewPath("spec", "exchange")
+
+	// Check environment
+	if exchange.Environment == ssmdv1alpha1.ExchangeEnvironmentTest && exchange.Type != ssmdv1alpha1.ExchangeTypeTest {
+		allErrs = append(allErrs, field.Invalid(exchangePath.Child("environment"), exchange.Environment,
+			"the test environment is only supported for the test exchange"))
+	}
+	if exchange.Environment == ssmdv1alpha1.ExchangeEnvironmentProd && strings.Contains(exchange.BaseURL, "demo") {
+		warnings = append(warnings, fmt.Sprintf("spec.exchange.baseURL %s looks like a demo endpoint for a prod environment", exchange.BaseURL))
+	}
+
+	// Check credentials
+	requiredKeys := exchangeSecretKeys[exchange.Type]
+	secretPath := exchangePath.Child("secretRef")
+	switch {
+	case len(requiredKeys) > 0 && exchange.SecretRef == nil:
+		allErrs = append(allErrs, field.Required(secretPath, fmt.Sprintf("required for the %s exchange", exchange.Type)))
+	case len(requiredKeys) > 0:
+		secret := &corev1.Secret{}
+		err := v.Reader.Get(ctx, types.NamespacedName{Name: exchange.SecretRef.Name, Namespace: harman.Namespace}, secret)
+		if apierrors.IsNotFound(err) {
+			warnings = append(warnings, fmt.Sprintf("secret %s not found; harman pods will not start until it exists", exchange.SecretRef.Name))
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var missing []string
+		for _, key := range requiredKeys {
+			if _, ok := secret.Data[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			allErrs = append(allErrs, field.Invalid(secretPath.Child("name"), exchange.SecretRef.Name,
+				fmt.Sprintf("secret is missing keys required for the %s exchange: %s", exchange.Type, strings.Join(missing, ", "))))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(ssmdv1alpha1.GroupVersion.WithKind("Harman").GroupKind(), harman.Name, allErrs)
+}
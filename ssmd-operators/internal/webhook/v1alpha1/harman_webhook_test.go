@@ -0,0 +1,141 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+func newWebhookTestHarman() *ssmdv1alpha1.Harman {
+	return &ssmdv1alpha1.Harman{
+		ObjectMeta: metav1.ObjectMeta{Name: "harman-demo", Namespace: "ssmd"},
+		Spec: ssmdv1alpha1.HarmanSpec{
+			Image: "ghcr.io/aaronwald/harman:0.1.0",
+			Exchange: ssmdv1alpha1.ExchangeConfig{
+				Type:        ssmdv1alpha1.ExchangeTypeKalshi,
+				Environment: ssmdv1alpha1.ExchangeEnvironmentDemo,
+				BaseURL:     "https://demo-api.kalshi.co",
+				SecretRef:   &corev1.LocalObjectReference{Name: "kalshi-credentials"},
+			},
+		},
+	}
+}
+
+func newExchangeSecret(keys ...string) *corev1.Secret {
+	data := map[string][]byte{}
+	for _, key := range keys {
+		data[key] = []byte("x")
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "kalshi-credentials", Namespace: "ssmd"},
+		Data:       data,
+	}
+}
+
+// --- TestHarmanCustomDefaulter ---
+
+func TestHarmanCustomDefaulter_ImageFromVersions(t *testing.T) {
+	reader := newTestReader(newImageVersions(map[string]string{"harman": "ghcr.io/aaronwald/harman:0.2.0"}))
+	d := &HarmanCustomDefaulter{images: imageDefaulter{Reader: reader, ConfigMapName: DefaultImageVersionsConfigMap}}
+	harman := newWebhookTestHarman()
+	harman.Spec.Image = ""
+
+	if err := d.Default(context.Background(), harman); err != nil || harman.Spec.Image != "ghcr.io/aaronwald/harman:0.2.0" {
+		t.Errorf("unexpected image %q (err %v)", harman.Spec.Image, err)
+	}
+}
+
+// --- TestHarmanCustomValidator ---
+
+func TestHarmanCustomValidator_Valid(t *testing.T) {
+	v := &HarmanCustomValidator{Reader: newTestReader(newExchangeSecret("api-key", "private-key"))}
+
+	warnings, err := v.ValidateCreate(context.Background(), newWebhookTestHarman())
+	if err != nil || len(warnings) != 0 {
+		t.Errorf("expected no errors or warnings, got %v (err %v)", warnings, err)
+	}
+}
+
+func TestHarmanCustomValidator_MissingSecretRef(t *testing.T) {
+	v := &HarmanCustomValidator{Reader: newTestReader()}
+	harman := newWebhookTestHarman()
+	harman.Spec.Exchange.SecretRef = nil
+
+	_, err := v.ValidateCreate(context.Background(), harman)
+	if err == nil || !strings.Contains(err.Error(), "spec.exchange.secretRef") {
+		t.Errorf("expected secretRef error, got %v", err)
+	}
+}
+
+func TestHarmanCustomValidator_SecretMissingKeys(t *testing.T) {
+	v := &HarmanCustomValidator{Reader: newTestReader(newExchangeSecret("api-key"))}
+
+	_, err := v.ValidateCreate(context.Background(), newWebhookTestHarman())
+	if err == nil || !strings.Contains(err.Error(), "private-key") {
+		t.Errorf("expected missing key error, got %v", err)
+	}
+}
+
+func TestHarmanCustomValidator_SecretNotFoundWarns(t *testing.T) {
+	v := &HarmanCustomValidator{Reader: newTestReader()}
+
+	warnings, err := v.ValidateCreate(context.Background(), newWebhookTestHarman())
+	if err != nil || len(warnings) != 1 {
+		t.Errorf("expected a warning only, got %v (err %v)", warnings, err)
+	}
+}
+
+func TestHarmanCustomValidator_TestExchange(t *testing.T) {
+	v := &HarmanCustomValidator{Reader: newTestReader()}
+	harman := newWebhookTestHarman()
+	harman.Spec.Exchange.Type = ssmdv1alpha1.ExchangeTypeTest
+	harman.Spec.Exchange.Environment = ssmdv1alpha1.ExchangeEnvironmentTest
+	harman.Spec.Exchange.SecretRef = nil
+
+	if _, err := v.ValidateCreate(context.Background(), harman); err != nil {
+		t.Errorf("test exchange needs no credentials, got %v", err)
+	}
+}
+
+func TestHarmanCustomValidator_TestEnvironmentRequiresTestExchange(t *testing.T) {
+	v := &HarmanCustomValidator{Reader: newTestReader(newExchangeSecret("api-key", "private-key"))}
+	harman := newWebhookTestHarman()
+	harman.Spec.Exchange.Environment = ssmdv1alpha1.ExchangeEnvironmentTest
+
+	_, err := v.ValidateCreate(context.Background(), harman)
+	if err == nil || !strings.Contains(err.Error(), "spec.exchange.environment") {
+		t.Errorf("expected environment error, got %v", err)
+	}
+}
+
+func TestHarmanCustomValidator_DemoURLInProdWarns(t *testing.T) {
+	v := &HarmanCustomValidator{Reader: newTestReader(newExchangeSecret("api-key", "private-key"))}
+	harman := newWebhookTestHarman()
+	harman.Spec.Exchange.Environment = ssmdv1alpha1.ExchangeEnvironmentProd
+
+	warnings, err := v.ValidateCreate(context.Background(), harman)
+	if err != nil || len(warnings) != 1 {
+		t.Errorf("expected a demo URL warning, got %v (err %v)", warnings, err)
+	}
+}
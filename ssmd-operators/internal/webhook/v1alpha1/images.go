@@ -0,0 +1,92 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aaronwald/ssmd/ssmd-operators/internal/tenant"
+)
+
+// DefaultImageVersionsConfigMap is the ConfigMap that maps components to default images
+const DefaultImageVersionsConfigMap = "ssmd-versions"
+
+// imageDefaulter looks up default images and resources for the CR's
+// namespace. The namespace's TenantConfig, if any, takes precedence over the
+// image versions ConfigMap, whose keys are component names ("archiver",
+// "connector", ...) and values are full image references.
+type imageDefaulter struct {
+	Reader           client.Reader
+	ConfigMapName    string
+	TenantConfigName string
+}
+
+// newImageDefaulter returns an imageDefaulter reading through the manager's API reader
+func newImageDefaulter(mgr ctrl.Manager, imageVersionsConfigMap, tenantConfig string) imageDefaulter {
+	return imageDefaulter{Reader: mgr.GetAPIReader(), ConfigMapName: imageVersionsConfigMap, TenantConfigName: tenantConfig}
+}
+
+// defaultImage returns the default image for a component, or "" if neither
+// the TenantConfig nor the ConfigMap sets one
+func (d imageDefaulter) defaultImage(ctx context.Context, namespace, component string) (string, error) {
+	if d.Reader == nil {
+		return "", nil
+	}
+
+	config, err := tenant.Get(ctx, d.Reader, namespace, d.TenantConfigName)
+	if err != nil {
+		return "", err
+	}
+	if config != nil && config.Spec.Images[component] != "" {
+		return config.Spec.Images[component], nil
+	}
+
+	if d.ConfigMapName == "" {
+		return "", nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := d.Reader.Get(ctx, types.NamespacedName{Name: d.ConfigMapName, Namespace: namespace}, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return configMap.Data[component], nil
+}
+
+// defaultResources returns the TenantConfig's resources for a component, or
+// nil if it sets none
+func (d imageDefaulter) defaultResources(ctx context.Context, namespace, component string) (*corev1.ResourceRequirements, error) {
+	if d.Reader == nil {
+		return nil, nil
+	}
+	config, err := tenant.Get(ctx, d.Reader, namespace, d.TenantConfigName)
+	if err != nil || config == nil {
+		return nil, err
+	}
+	resources, ok := config.Spec.Resources[component]
+	if !ok {
+		return nil, nil
+	}
+	return resources.DeepCopy(), nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var notifierlog = logf.Log.WithName("notifier-resource")
+
+// ntfyPriorities are the priorities accepted by ntfy
+var ntfyPriorities = map[string]bool{"min": true, "low": true, "default": true, "high": true, "urgent": true}
+
+// SetupNotifierWebhookWithManager registers the webhook for Notifier in the manager.
+func SetupNotifierWebhookWithManager(mgr ctrl.Manager, imageVersionsConfigMap, tenantConfig string) error {
+	return ctrl.NewWebhookManagedBy(mgr, &ssmdv1alpha1.Notifier{}).
+		WithValidator(&NotifierCustomValidator{}).
+		WithDefaulter(&NotifierCustomDefaulter{
+			images: newImageDefaulter(mgr, imageVersionsConfigMap, tenantConfig),
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-ssmd-ssmd-io-v1alpha1-notifier,mutating=true,failurePolicy=fail,sideEffects=None,groups=ssmd.ssmd.io,resources=notifiers,verbs=create;update,versions=v1alpha1,name=mnotifier-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// NotifierCustomDefaulter sets the notifier image from the TenantConfig or image
+// versions ConfigMap, and the resources from the TenantConfig
+type NotifierCustomDefaulter struct {
+	images imageDefaulter
+}
+
+// Default implements admission.Defaulter so a webhook will be registered for the type Notifier.
+func (d *NotifierCustomDefaulter) Default(ctx context.Context, notifier *ssmdv1alpha1.Notifier) error {
+	notifierlog.Info("Defaulting for Notifier", "name", notifier.GetName())
+
+	if notifier.Spec.Resources == nil {
+		resources, err := d.images.defaultResources(ctx, notifier.Namespace, "notifier")
+		if err != nil {
+			// The controller leaves resources unset
+			notifierlog.Error(err, "Failed to read TenantConfig", "name", notifier.GetName())
+		}
+		notifier.Spec.Resources = resources
+	}
+
+	if notifier.Spec.Image != "" {
+		return nil
+	}
+	image, err := d.images.defaultImage(ctx, notifier.Namespace, "notifier")
+	if err != nil {
+		// Schema validation rejects the CR if the image stays empty
+		notifierlog.Error(err, "Failed to read image versions", "name", notifier.GetName())
+		return nil
+	}
+	notifier.Spec.Image = image
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-ssmd-ssmd-io-v1alpha1-notifier,mutating=false,failurePolicy=fail,sideEffects=None,groups=ssmd.ssmd.io,resources=notifiers,verbs=create;update,versions=v1alpha1,name=vnotifier-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// NotifierCustomValidator validates Notifier destinations and routing rules
+type NotifierCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the type Notifier.
+func (v *NotifierCustomValidator) ValidateCreate(ctx context.Context, notifier *ssmdv1alpha1.Notifier) (admission.Warnings, error) {
+	notifierlog.Info("Validation for Notifier upon creation", "name", notifier.GetName())
+	return nil, validateNotifier(notifier)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the type Notifier.
+func (v *NotifierCustomValidator) ValidateUpdate(ctx context.Context, oldNotifier, notifier *ssmdv1alpha1.Notifier) (admission.Warnings, error) {
+	notifierlog.Info("Validation for Notifier upon update", "name", notifier.GetName())
+
+	// Never block finalizer removal or metadata-only updates of existing CRs
+	if !notifier.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(oldNotifier.Spec, notifier.Spec) {
+		return nil, nil
+	}
+	return nil, validateNotifier(notifier)
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the type Notifier.
+func (v *NotifierCustomValidator) ValidateDelete(ctx context.Context, notifier *ssmdv1alpha1.Notifier) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateNotifier checks that each destination has the settings its type needs
+func validateNotifier(notifier *ssmdv1alpha1.Notifier) error {
+	var allErrs field.ErrorList
+	names := map[string]bool{}
+
+	for i, dest := range notifier.Spec.Destinations {
+		path := field.NewPath("spec", "destinations").Index(i)
+		if names[dest.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("name"), dest.Name))
+		}
+		names[dest.Name] = true
+
+		config := dest.Config
+		if config == nil {
+			config = &ssmdv1alpha1.DestinationConfig{}
+		}
+		configPath := path.Child("config")
+
+		// Check type-specific settings
+		switch dest.Type {
+		case "ntfy":
+			if config.Topic == "" {
+				allErrs = append(allErrs, field.Required(configPath.Child("topic"), "required for ntfy destinations"))
+			}
+			if config.Priority != "" && !ntfyPriorities[config.Priority] {
+				allErrs = append(allErrs, field.NotSupported(configPath.Child("priority"), config.Priority,
+					[]string{"min", "low", "default", "high", "urgent"}))
+			}
+		case "slack", "discord":
+			if config.WebhookURL == "" && dest.SecretRef == nil && (dest.Slack == nil || dest.Slack.WebhookURL == "") {
+				allErrs = append(allErrs, field.Required(configPath.Child("webhookUrl"), "webhookUrl or secretRef is required for "+dest.Type+" destinations"))
+			}
+		case "email":
+			if config.To == "" && dest.Email == nil {
+				allErrs = append(allErrs, field.Required(configPath.Child("to"), "required for email destinations without email settings"))
+			}
+		case "pagerduty", "webhook":
+			if (dest.Type == "pagerduty" && dest.PagerDuty == nil) || (dest.Type == "webhook" && dest.Webhook == nil) {
+				allErrs = append(allErrs, field.Required(path.Child(dest.Type), "required for "+dest.Type+" destinations"))
+			}
+		}
+
+		// Typed settings that read keys from the destination secret need secretRef
+		if dest.SecretRef == nil && (dest.PagerDuty != nil ||
+			(dest.Slack != nil && dest.Slack.WebhookURLField != "") ||
+			(dest.Webhook != nil && len(dest.Webhook.SecretHeaders) > 0) ||
+			(dest.Email != nil && (dest.Email.UsernameField != "" || dest.Email.PasswordField != ""))) {
+			allErrs = append(allErrs, field.Required(path.Child("secretRef"), "required when settings reference secret keys"))
+		}
+		if dest.Webhook != nil {
+			if u, err := url.Parse(dest.Webhook.URL); err != nil || u.Host == "" {
+				allErrs = append(allErrs, field.Invalid(path.Child("webhook", "url"), dest.Webhook.URL, "must be an absolute URL"))
+			}
+		}
+
+		for _, setting := range []struct{ name, value string }{{"server", config.Server}, {"webhookUrl", config.WebhookURL}} {
+			if setting.value == "" {
+				continue
+			}
+			if u, err := url.Parse(setting.value); err != nil || u.Scheme == "" || u.Host == "" {
+				allErrs = append(allErrs, field.Invalid(configPath.Child(setting.name), setting.value, "must be an absolute URL"))
+			}
+		}
+
+		// Check routing rule
+		if dest.Match != nil {
+			matchPath := path.Child("match", "value")
+			switch dest.Match.Operator {
+			case "regex":
+				if _, err := regexp.Compile(dest.Match.Value); err != nil {
+					allErrs = append(allErrs, field.Invalid(matchPath, dest.Match.Value, err.Error()))
+				}
+			case "gt", "gte", "lt", "lte":
+				if _, err := strconv.ParseFloat(dest.Match.Value, 64); err != nil {
+					allErrs = append(allErrs, field.Invalid(matchPath, dest.Match.Value, "must be a number for operator "+dest.Match.Operator))
+				}
+			}
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(ssmdv1alpha1.GroupVersion.WithKind("Notifier").GroupKind(), notifier.Name, allErrs)
+}
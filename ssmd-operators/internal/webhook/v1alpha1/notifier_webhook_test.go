@@ -0,0 +1,170 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ssmdv1alpha1 "github.com/aaronwald/ssmd/ssmd-operators/api/v1alpha1"
+)
+
+func newWebhookTestNotifier(destinations ...ssmdv1alpha1.NotifierDestination) *ssmdv1alpha1.Notifier {
+	return &ssmdv1alpha1.Notifier{
+		ObjectMeta: metav1.ObjectMeta{Name: "alerts", Namespace: "ssmd"},
+		Spec: ssmdv1alpha1.NotifierSpec{
+			Source:       ssmdv1alpha1.NotifierSourceConfig{Subjects: []string{"signals.>"}},
+			Destinations: destinations,
+			Image:        "ghcr.io/aaronwald/ssmd-notifier:0.1.0",
+		},
+	}
+}
+
+// --- TestNotifierCustomDefaulter ---
+
+func TestNotifierCustomDefaulter_ImageFromVersions(t *testing.T) {
+	reader := newTestReader(newImageVersions(map[string]string{"notifier": "ghcr.io/aaronwald/ssmd-notifier:0.2.0"}))
+	d := &NotifierCustomDefaulter{images: imageDefaulter{Reader: reader, ConfigMapName: DefaultImageVersionsConfigMap}}
+	notifier := newWebhookTestNotifier()
+	notifier.Spec.Image = ""
+
+	if err := d.Default(context.Background(), notifier); err != nil || notifier.Spec.Image != "ghcr.io/aaronwald/ssmd-notifier:0.2.0" {
+		t.Errorf("unexpected image %q (err %v)", notifier.Spec.Image, err)
+	}
+}
+
+// --- TestNotifierCustomValidator ---
+
+func TestNotifierCustomValidator_Valid(t *testing.T) {
+	v := &NotifierCustomValidator{}
+	notifier := newWebhookTestNotifier(
+		ssmdv1alpha1.NotifierDestination{
+			Name:   "phone",
+			Type:   "ntfy",
+			Config: &ssmdv1alpha1.DestinationConfig{Server: "https://ntfy.sh", Topic: "ssmd", Priority: "high"},
+			Match:  &ssmdv1alpha1.MatchRule{Field: "payload.dollarVolume", Operator: "gte", Value: "10000"},
+		},
+		ssmdv1alpha1.NotifierDestination{
+			Name:      "ops",
+			Type:      "slack",
+			SecretRef: &corev1.LocalObjectReference{Name: "slack-webhook"},
+		},
+	)
+
+	if _, err := v.ValidateCreate(context.Background(), notifier); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNotifierCustomValidator_DestinationSchema(t *testing.T) {
+	v := &NotifierCustomValidator{}
+	notifier := newWebhookTestNotifier(
+		ssmdv1alpha1.NotifierDestination{Name: "phone", Type: "ntfy", Config: &ssmdv1alpha1.DestinationConfig{Priority: "loud"}},
+		ssmdv1alpha1.NotifierDestination{Name: "phone", Type: "slack"},
+		ssmdv1alpha1.NotifierDestination{Name: "mail", Type: "email"},
+	)
+
+	_, err := v.ValidateCreate(context.Background(), notifier)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{
+		"spec.destinations[0].config.topic",
+		"spec.destinations[0].config.priority",
+		"spec.destinations[1].name",
+		"spec.destinations[1].config.webhookUrl",
+		"spec.destinations[2].config.to",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error for %s, got %v", want, err)
+		}
+	}
+}
+
+func TestNotifierCustomValidator_TypedDestinations(t *testing.T) {
+	v := &NotifierCustomValidator{}
+	notifier := newWebhookTestNotifier(
+		ssmdv1alpha1.NotifierDestination{
+			Name:      "oncall",
+			Type:      "pagerduty",
+			PagerDuty: &ssmdv1alpha1.PagerDutyDestination{RoutingKeyField: "routing-key"},
+			SecretRef: &corev1.LocalObjectReference{Name: "pagerduty"},
+		},
+		ssmdv1alpha1.NotifierDestination{
+			Name:    "hook",
+			Type:    "webhook",
+			Webhook: &ssmdv1alpha1.WebhookDestination{URL: "https://hooks.example.com/ssmd", Headers: map[string]string{"X-Source": "ssmd"}},
+		},
+		ssmdv1alpha1.NotifierDestination{
+			Name:  "desk",
+			Type:  "email",
+			Email: &ssmdv1alpha1.EmailDestination{To: []string{"desk@example.com"}, From: "ssmd@example.com", Host: "smtp.example.com"},
+		},
+	)
+	if _, err := v.ValidateCreate(context.Background(), notifier); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	notifier = newWebhookTestNotifier(
+		ssmdv1alpha1.NotifierDestination{Name: "oncall", Type: "pagerduty"},
+		ssmdv1alpha1.NotifierDestination{
+			Name:    "hook",
+			Type:    "webhook",
+			Webhook: &ssmdv1alpha1.WebhookDestination{URL: "/relative", SecretHeaders: map[string]string{"Authorization": "token"}},
+		},
+	)
+	_, err := v.ValidateCreate(context.Background(), notifier)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{
+		"spec.destinations[0].pagerduty",
+		"spec.destinations[1].secretRef",
+		"spec.destinations[1].webhook.url",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error for %s, got %v", want, err)
+		}
+	}
+}
+
+func TestNotifierCustomValidator_MatchRule(t *testing.T) {
+	v := &NotifierCustomValidator{}
+	notifier := newWebhookTestNotifier(
+		ssmdv1alpha1.NotifierDestination{
+			Name:   "phone",
+			Type:   "ntfy",
+			Config: &ssmdv1alpha1.DestinationConfig{Topic: "ssmd"},
+			Match:  &ssmdv1alpha1.MatchRule{Field: "payload.ticker", Operator: "regex", Value: "KX(["},
+		},
+		ssmdv1alpha1.NotifierDestination{
+			Name:   "desk",
+			Type:   "ntfy",
+			Config: &ssmdv1alpha1.DestinationConfig{Topic: "desk"},
+			Match:  &ssmdv1alpha1.MatchRule{Field: "payload.dollarVolume", Operator: "gt", Value: "lots"},
+		},
+	)
+
+	_, err := v.ValidateCreate(context.Background(), notifier)
+	if err == nil || !strings.Contains(err.Error(), "spec.destinations[0].match.value") || !strings.Contains(err.Error(), "spec.destinations[1].match.value") {
+		t.Errorf("expected match value errors, got %v", err)
+	}
+}
@@ -37,7 +37,7 @@ pub use message::Message;
 pub use metrics::{encode_metrics, ConnectorMetrics, ShardMetrics};
 pub use nats_writer::NatsWriter;
 pub use publisher::{Publisher, TradeData, TradeSide};
-pub use resolver::EnvResolver;
+pub use resolver::{resolve_source, EnvResolver, FileResolver};
 pub use ring_buffer::{RingBuffer, RING_SIZE, RING_SLOTS, SLOT_SIZE};
 pub use runner::Runner;
 pub use secmaster::{SecmasterClient, SecmasterError};
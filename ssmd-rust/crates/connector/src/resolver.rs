@@ -1,5 +1,7 @@
 use std::collections::HashMap;
 use std::env;
+use std::fs;
+use std::path::Path;
 
 use crate::error::ResolverError;
 use crate::traits::KeyResolver;
@@ -57,6 +59,76 @@ impl KeyResolver for EnvResolver {
     }
 }
 
+/// Resolves keys from files, e.g. a Secret mounted as a volume. Unlike env
+/// vars, mounted files follow Secret rotation without recreating the pod.
+pub struct FileResolver;
+
+impl FileResolver {
+    pub fn new() -> Self {
+        Self
+    }
+}
+
+impl Default for FileResolver {
+    fn default() -> Self {
+        Self::new()
+    }
+}
+
+impl KeyResolver for FileResolver {
+    /// Parses "file:/path/KEY1,/path/KEY2" and returns each file's contents
+    /// keyed by its file name, with trailing newlines trimmed
+    fn resolve(&self, source: &str) -> Result<HashMap<String, String>, ResolverError> {
+        let prefix = "file:";
+        if !source.starts_with(prefix) {
+            return Err(ResolverError::UnsupportedSource(format!(
+                "expected 'file:' prefix, got: {}",
+                source
+            )));
+        }
+
+        let paths_part = &source[prefix.len()..];
+        if paths_part.is_empty() {
+            return Err(ResolverError::UnsupportedSource(
+                "empty file source".to_string(),
+            ));
+        }
+
+        let mut result = HashMap::new();
+        for path in paths_part.split(',') {
+            let path = path.trim();
+            if path.is_empty() {
+                continue;
+            }
+            let name = Path::new(path)
+                .file_name()
+                .and_then(|n| n.to_str())
+                .ok_or_else(|| {
+                    ResolverError::UnsupportedSource(format!("invalid key path: {}", path))
+                })?;
+            match fs::read_to_string(path) {
+                Ok(value) => {
+                    result.insert(name.to_string(), value.trim_end_matches('\n').to_string());
+                }
+                Err(_) => {
+                    return Err(ResolverError::MissingKey(path.to_string()));
+                }
+            }
+        }
+
+        Ok(result)
+    }
+}
+
+/// Resolves a key source with the resolver for its scheme ("env:" or "file:")
+pub fn resolve_source(source: &str) -> Result<HashMap<String, String>, ResolverError> {
+    if source.starts_with("file:") {
+        FileResolver::new().resolve(source)
+    } else {
+        EnvResolver::new().resolve(source)
+    }
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -89,4 +161,38 @@ mod tests {
         let result = resolver.resolve("vault:secret/path");
         assert!(result.is_err());
     }
+
+    #[test]
+    fn test_resolve_files() {
+        let dir = std::env::temp_dir().join(format!("ssmd-resolver-{}", std::process::id()));
+        fs::create_dir_all(&dir).unwrap();
+        fs::write(dir.join("TEST_FILE_KEY"), "key-id\n").unwrap();
+        fs::write(
+            dir.join("TEST_FILE_PEM"),
+            "-----BEGIN KEY-----\nabc\n-----END KEY-----\n",
+        )
+        .unwrap();
+
+        let source = format!(
+            "file:{},{}",
+            dir.join("TEST_FILE_KEY").display(),
+            dir.join("TEST_FILE_PEM").display()
+        );
+        let result = resolve_source(&source).unwrap();
+
+        assert_eq!(result.get("TEST_FILE_KEY"), Some(&"key-id".to_string()));
+        assert_eq!(
+            result.get("TEST_FILE_PEM"),
+            Some(&"-----BEGIN KEY-----\nabc\n-----END KEY-----".to_string())
+        );
+
+        fs::remove_dir_all(&dir).unwrap();
+    }
+
+    #[test]
+    fn test_missing_file() {
+        let resolver = FileResolver::new();
+        let result = resolver.resolve("file:/nonexistent/ssmd/KEY");
+        assert!(matches!(result, Err(ResolverError::MissingKey(_))));
+    }
 }
@@ -1,4 +1,6 @@
+use chrono::{DateTime, NaiveDate, Utc};
 use serde::Deserialize;
+use std::collections::BTreeMap;
 use std::path::PathBuf;
 use std::time::Duration;
 
@@ -7,6 +9,13 @@ pub struct Config {
     pub nats: NatsConfig,
     pub storage: StorageConfig,
     pub rotation: RotationConfig,
+    /// Field paths identifying one message, by message type (the schema
+    /// registry's dedup_key); recorded in each day's manifest
+    #[serde(default)]
+    pub dedup_keys: BTreeMap<String, Vec<String>>,
+    /// One-shot replay of a sequence range instead of following a durable consumer
+    #[serde(default)]
+    pub replay: Option<ReplayConfig>,
 }
 
 #[derive(Debug, Deserialize)]
@@ -43,6 +52,66 @@ pub struct RotationConfig {
     pub interval: String,
 }
 
+/// Rebuild one day's files from a JetStream sequence range. Messages are read
+/// through an ephemeral consumer, written under their JetStream publish time,
+/// and the archiver exits once the range is archived.
+#[derive(Debug, Deserialize, Clone)]
+pub struct ReplayConfig {
+    /// Day to rebuild (UTC); messages published on other days are not archived
+    pub date: NaiveDate,
+    /// First stream sequence to read
+    pub from_sequence: u64,
+    /// Last stream sequence to read (inclusive); defaults to the end of the day
+    #[serde(default)]
+    pub to_sequence: Option<u64>,
+}
+
+/// Where a replayed message falls relative to the replay range
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ReplayPosition {
+    /// Published before the replay date: skipped
+    Before,
+    /// Archived
+    Within,
+    /// After the replay date or to_sequence: the replay is done
+    Past,
+}
+
+impl ReplayConfig {
+    pub fn validate(&self) -> Result<(), crate::ArchiverError> {
+        if self.from_sequence == 0 {
+            return Err(crate::ArchiverError::Config(
+                "replay.from_sequence must be at least 1".to_string(),
+            ));
+        }
+        if let Some(to) = self.to_sequence {
+            if to < self.from_sequence {
+                return Err(crate::ArchiverError::Config(format!(
+                    "replay.to_sequence {} is before from_sequence {}",
+                    to, self.from_sequence
+                )));
+            }
+        }
+        Ok(())
+    }
+
+    /// Stream sequences are in publish order, so the first message past the
+    /// day or the last sequence ends the replay.
+    pub fn position(&self, seq: u64, published: DateTime<Utc>) -> ReplayPosition {
+        if self.to_sequence.is_some_and(|to| seq > to) {
+            return ReplayPosition::Past;
+        }
+        let day = published.date_naive();
+        if day < self.date {
+            ReplayPosition::Before
+        } else if day > self.date {
+            ReplayPosition::Past
+        } else {
+            ReplayPosition::Within
+        }
+    }
+}
+
 impl Config {
     pub fn load(path: &std::path::Path) -> Result<Self, crate::ArchiverError> {
         let content = std::fs::read_to_string(path)?;
@@ -125,6 +194,35 @@ rotation:
         assert_eq!(config.nats.streams[0].stream, "MARKETDATA");
         assert_eq!(config.storage.feed, "kalshi");
         assert_eq!(config.rotation.interval, "15m");
+        assert!(config.dedup_keys.is_empty());
+    }
+
+    #[test]
+    fn test_load_config_dedup_keys() {
+        let yaml = r#"
+nats:
+  url: nats://localhost:4222
+  streams: []
+
+storage:
+  path: /data/ssmd
+
+rotation:
+  interval: 15m
+
+dedup_keys:
+  trade:
+    - "data[].symbol"
+    - "data[].trade_id"
+"#;
+        let mut file = NamedTempFile::new().unwrap();
+        file.write_all(yaml.as_bytes()).unwrap();
+
+        let config = Config::load(file.path()).unwrap();
+        assert_eq!(
+            config.dedup_keys["trade"],
+            vec!["data[].symbol".to_string(), "data[].trade_id".to_string()]
+        );
     }
 
     #[test]
@@ -318,4 +416,84 @@ rotation:
         assert_eq!(config.nats.streams[0].feed, "kalshi");
         assert_eq!(config.nats.streams[1].feed, "kraken-futures");
     }
+
+    #[test]
+    fn test_load_replay_config() {
+        let yaml = r#"
+nats:
+  url: nats://localhost:4222
+  streams:
+    - name: crypto
+      stream: PROD_KALSHI_CRYPTO
+      consumer: rearchive-kalshi
+      filter: "prod.kalshi.crypto.json.>"
+
+storage:
+  path: /data/ssmd
+  feed: kalshi
+
+rotation:
+  interval: 15m
+
+replay:
+  date: "2026-01-15"
+  from_sequence: 1200
+"#;
+        let mut file = NamedTempFile::new().unwrap();
+        file.write_all(yaml.as_bytes()).unwrap();
+
+        let config = Config::load(file.path()).unwrap();
+        let replay = config.replay.unwrap();
+        assert_eq!(replay.date, NaiveDate::from_ymd_opt(2026, 1, 15).unwrap());
+        assert_eq!(replay.from_sequence, 1200);
+        assert_eq!(replay.to_sequence, None);
+        assert!(replay.validate().is_ok());
+    }
+
+    #[test]
+    fn test_replay_validate_rejects_bad_ranges() {
+        let date = NaiveDate::from_ymd_opt(2026, 1, 15).unwrap();
+        let zero = ReplayConfig {
+            date,
+            from_sequence: 0,
+            to_sequence: None,
+        };
+        assert!(zero.validate().is_err());
+        let backwards = ReplayConfig {
+            date,
+            from_sequence: 10,
+            to_sequence: Some(5),
+        };
+        assert!(backwards.validate().is_err());
+    }
+
+    #[test]
+    fn test_replay_position() {
+        let replay = ReplayConfig {
+            date: NaiveDate::from_ymd_opt(2026, 1, 15).unwrap(),
+            from_sequence: 100,
+            to_sequence: Some(500),
+        };
+        let at = |s: &str| s.parse::<DateTime<Utc>>().unwrap();
+        assert_eq!(
+            replay.position(100, at("2026-01-14T23:59:59Z")),
+            ReplayPosition::Before
+        );
+        assert_eq!(
+            replay.position(101, at("2026-01-15T00:00:00Z")),
+            ReplayPosition::Within
+        );
+        assert_eq!(
+            replay.position(500, at("2026-01-15T23:59:59Z")),
+            ReplayPosition::Within
+        );
+        assert_eq!(
+            replay.position(501, at("2026-01-15T23:59:59Z")),
+            ReplayPosition::Past
+        );
+        assert_eq!(
+            replay.position(400, at("2026-01-16T00:00:00Z")),
+            ReplayPosition::Past
+        );
+    }
 }
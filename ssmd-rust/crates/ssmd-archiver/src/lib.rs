@@ -13,5 +13,5 @@ pub mod subscriber;
 pub mod validation;
 pub mod writer;
 
-pub use config::Config;
+pub use config::{Config, ReplayConfig, ReplayPosition};
 pub use error::ArchiverError;
@@ -1,6 +1,6 @@
 //! ssmd-archiver binary entry point
 
-use std::collections::{HashMap, HashSet};
+use std::collections::{BTreeMap, HashSet};
 use std::net::SocketAddr;
 use std::path::{Path, PathBuf};
 use std::sync::atomic::{AtomicBool, AtomicU64, Ordering};
@@ -17,14 +17,14 @@ use tracing::{error, info, warn};
 use tracing_subscriber::{layer::SubscriberExt, util::SubscriberInitExt};
 
 use ssmd_archiver::config::StreamConfig;
-use ssmd_archiver::manifest::{FileEntry, Gap};
+use ssmd_archiver::manifest::{FileEntry, FileStats, Gap};
 use ssmd_archiver::manifest_io::update_manifest;
 use ssmd_archiver::metrics::{ArchiverMetrics, StreamMetrics};
 use ssmd_archiver::server::{run_server, ServerState};
 use ssmd_archiver::subscriber::Subscriber;
 use ssmd_archiver::validation::{extract_manifest_fields, MessageValidator};
 use ssmd_archiver::writer::{ArchiveOutput, ArchiveWriter};
-use ssmd_archiver::Config;
+use ssmd_archiver::{Config, ReplayConfig, ReplayPosition};
 
 #[derive(Parser, Debug)]
 #[command(name = "ssmd-archiver")]
@@ -61,6 +61,21 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
 
     let rotation_duration = config.rotation.parse_interval()?;
 
+    // A replay rebuilds one stream's files and exits
+    let replay = config.replay.clone();
+    if let Some(ref r) = replay {
+        r.validate()?;
+        if config.nats.streams.len() != 1 {
+            return Err("replay needs exactly one stream".into());
+        }
+        info!(
+            date = %r.date,
+            from_sequence = r.from_sequence,
+            to_sequence = ?r.to_sequence,
+            "Replay mode"
+        );
+    }
+
     info!(
         nats_url = %config.nats.url,
         streams = config.nats.streams.len(),
@@ -74,6 +89,7 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
     let nats_url = Arc::new(config.nats.url.clone());
     let base_path = Arc::new(config.storage.path.clone());
     let rotation_interval = Arc::new(config.rotation.interval.clone());
+    let dedup_keys = Arc::new(config.dedup_keys.clone());
     let connected = Arc::new(AtomicBool::new(false));
     let last_message_epoch_secs = Arc::new(AtomicU64::new(0));
 
@@ -99,14 +115,22 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
     // Spawn a task per stream
     let mut tasks: JoinSet<Result<(), Box<dyn std::error::Error + Send + Sync>>> = JoinSet::new();
 
-    // Health server runs in the JoinSet — if it exits, the archiver shuts down
-    tasks.spawn(async move {
+    let health_server = async move {
         info!(%health_addr, "Starting health/metrics server");
         if let Err(e) = run_server(health_addr, server_state).await {
             error!(error = %e, "Health server failed");
         }
-        Err("Health server exited unexpectedly".into())
-    });
+        Err::<(), Box<dyn std::error::Error + Send + Sync>>(
+            "Health server exited unexpectedly".into(),
+        )
+    };
+    if replay.is_some() {
+        // A replay exits once its range is archived; the server must not hold it open
+        tokio::spawn(health_server);
+    } else {
+        // Health server runs in the JoinSet — if it exits, the archiver shuts down
+        tasks.spawn(health_server);
+    }
 
     for stream_config in config.nats.streams {
         let shutdown = shutdown.clone();
@@ -114,8 +138,10 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
         let base_path = Arc::clone(&base_path);
         let feed = stream_config.feed.clone();
         let rotation_interval = Arc::clone(&rotation_interval);
+        let dedup_keys = Arc::clone(&dedup_keys);
         let connected = connected.clone();
         let last_message_epoch_secs = last_message_epoch_secs.clone();
+        let replay = replay.clone();
         let archiver_metrics = ArchiverMetrics::new(&feed);
         let metrics = archiver_metrics.for_stream(&stream_config.name);
         metrics.init(&["ticker", "trade"]);
@@ -134,11 +160,13 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
                 &base_path,
                 &feed,
                 &rotation_interval,
+                &dedup_keys,
                 rotation_duration,
                 shutdown,
                 metrics,
                 connected,
                 last_message_epoch_secs,
+                replay,
             )
             .await
         });
@@ -152,6 +180,8 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
 
     info!("Archiver running, waiting for SIGTERM/SIGINT to stop");
 
+    let mut failed = false;
+
     // Wait for signal or task failure
     tokio::select! {
         _ = sigterm.recv() => {
@@ -169,9 +199,11 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
                 }
                 Some(Ok(Err(e))) => {
                     error!(error = %e, "Task failed, shutting down all tasks");
+                    failed = true;
                 }
                 Some(Err(e)) => {
                     error!(error = %e, "Task panicked, shutting down all tasks");
+                    failed = true;
                 }
                 None => {
                     info!("All tasks completed");
@@ -185,8 +217,14 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
     while let Some(result) = tasks.join_next().await {
         match result {
             Ok(Ok(())) => info!("Task shutdown complete"),
-            Ok(Err(e)) => error!(error = %e, "Task failed during shutdown"),
-            Err(e) => error!(error = %e, "Task panicked during shutdown"),
+            Ok(Err(e)) => {
+                error!(error = %e, "Task failed during shutdown");
+                failed = true;
+            }
+            Err(e) => {
+                error!(error = %e, "Task panicked during shutdown");
+                failed = true;
+            }
         }
     }
 
@@ -198,6 +236,10 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
     }
 
     info!("Archiver stopped");
+    // A failed replay must fail its Job rather than sync a partial archive
+    if replay.is_some() && failed {
+        return Err("replay failed".into());
+    }
     Ok(())
 }
 
@@ -209,11 +251,13 @@ async fn archive_stream(
     base_path: &Path,
     feed: &str,
     rotation_interval: &str,
+    dedup_keys: &BTreeMap<String, Vec<String>>,
     rotation_duration: Duration,
     shutdown: CancellationToken,
     metrics: StreamMetrics,
     connected: Arc<AtomicBool>,
     last_message_epoch_secs: Arc<AtomicU64>,
+    replay: Option<ReplayConfig>,
 ) -> Result<(), Box<dyn std::error::Error + Send + Sync>> {
     let stream_name = stream_config.name.clone();
     let rotation_minutes = (rotation_duration.as_secs() / 60) as u32;
@@ -224,8 +268,11 @@ async fn archive_stream(
         "Connecting to NATS"
     );
 
-    // Connect to NATS
-    let mut subscriber = Subscriber::connect(nats_url, &stream_config).await?;
+    // Connect to NATS; a replay reads its range through an ephemeral consumer
+    let mut subscriber = match replay {
+        Some(ref r) => Subscriber::connect_range(nats_url, &stream_config, r.from_sequence).await?,
+        None => Subscriber::connect(nats_url, &stream_config).await?,
+    };
     connected.store(true, Ordering::SeqCst);
 
     // Create JSONL.gz writer
@@ -244,8 +291,11 @@ async fn archive_stream(
     let mut message_types: HashSet<String> = HashSet::new();
     let mut gaps: Vec<Gap> = Vec::new();
     let mut completed_files: Vec<FileEntry> = Vec::new();
-    let mut current_date = Utc::now().format("%Y-%m-%d").to_string();
-    let mut current_file_type_counts: HashMap<String, u64> = HashMap::new();
+    let mut current_date = match replay {
+        Some(ref r) => r.date.format("%Y-%m-%d").to_string(),
+        None => Utc::now().format("%Y-%m-%d").to_string(),
+    };
+    let mut current_file_stats = FileStats::default();
 
     // Sequence tracking (local — not worth Prometheus overhead)
     let mut first_seq: Option<u64> = None;
@@ -268,14 +318,14 @@ async fn archive_stream(
                 let now = Utc::now();
                 let date = now.format("%Y-%m-%d").to_string();
 
-                // Check for day rollover
-                if date != current_date {
+                // Check for day rollover (a replay only ever writes its own day)
+                if replay.is_none() && date != current_date {
                     info!(stream_name = %stream_name, old = %current_date, new = %date, "Day rollover, writing final manifest");
                     for mut entry in writer.close()? {
-                        entry.records_by_type = Some(std::mem::take(&mut current_file_type_counts));
+                        current_file_stats.finish(&mut entry);
                         completed_files.push(entry);
                     }
-                    update_manifest(base_path, feed, &stream_name, &current_date, rotation_interval, &tickers, &message_types, &gaps, &completed_files)?;
+                    update_manifest(base_path, feed, &stream_name, &current_date, rotation_interval, dedup_keys, &tickers, &message_types, &gaps, &completed_files)?;
                     tickers.clear();
                     message_types.clear();
                     gaps.clear();
@@ -287,8 +337,32 @@ async fn archive_stream(
                 match subscriber.fetch(100).await {
                     Ok(messages) => {
                         let mut pending_acks = Vec::with_capacity(messages.len());
+                        // An empty fetch means a replay has caught up with the stream
+                        let mut replay_done = messages.is_empty();
 
                         for msg in messages {
+                            // Replayed messages are filed under their original publish time
+                            let now = match replay {
+                                Some(_) => msg.published.unwrap_or(now),
+                                None => now,
+                            };
+                            if let Some(ref r) = replay {
+                                match r.position(msg.seq, now) {
+                                    ReplayPosition::Before => {
+                                        pending_acks.push(msg);
+                                        continue;
+                                    }
+                                    ReplayPosition::Past => {
+                                        replay_done = true;
+                                        break;
+                                    }
+                                    ReplayPosition::Within => {}
+                                }
+                                if msg.pending == Some(0) {
+                                    replay_done = true;
+                                }
+                            }
+
                             // Check for gap
                             if let Some((after_seq, missing)) = msg.gap {
                                 warn!(stream_name = %stream_name, after_seq = after_seq, missing = missing, "Recording gap");
@@ -311,7 +385,7 @@ async fn archive_stream(
                             metrics.set_last_message_timestamp(epoch_secs as f64);
 
                             // Lightweight manifest field extraction (no full JSON tree)
-                            let msg_type_for_count = match extract_manifest_fields(feed, msg.payload()) {
+                            let (msg_type_for_count, ticker_for_count) = match extract_manifest_fields(feed, msg.payload()) {
                                 Some(fields) => {
                                     let mt = fields.msg_type;
                                     if let Some(ref t) = mt {
@@ -320,15 +394,15 @@ async fn archive_stream(
                                     } else {
                                         metrics.inc_message("unknown");
                                     }
-                                    if let Some(t) = fields.ticker {
-                                        tickers.insert(t);
+                                    if let Some(ref t) = fields.ticker {
+                                        tickers.insert(t.clone());
                                     }
-                                    mt
+                                    (mt, fields.ticker)
                                 }
                                 None => {
                                     metrics.inc_parse_failure();
                                     metrics.inc_message("unknown");
-                                    None
+                                    (None, None)
                                 }
                             };
 
@@ -358,7 +432,7 @@ async fn archive_stream(
                                     if !rotated_entries.is_empty() {
                                         for mut rotated_entry in rotated_entries {
                                             metrics.inc_files_rotated();
-                                            rotated_entry.records_by_type = Some(std::mem::take(&mut current_file_type_counts));
+                                            current_file_stats.finish(&mut rotated_entry);
                                             info!(
                                                 stream_name = %stream_name,
                                                 file = %rotated_entry.name,
@@ -369,14 +443,12 @@ async fn archive_stream(
                                             );
                                             completed_files.push(rotated_entry);
                                         }
-                                        if let Err(e) = update_manifest(base_path, feed, &stream_name, &current_date, rotation_interval, &tickers, &message_types, &gaps, &completed_files) {
+                                        if let Err(e) = update_manifest(base_path, feed, &stream_name, &current_date, rotation_interval, dedup_keys, &tickers, &message_types, &gaps, &completed_files) {
                                             error!(stream_name = %stream_name, error = %e, "Failed to update manifest after rotation");
                                         }
                                     }
-                                    // Count current message type for the (possibly new) file
-                                    if let Some(ref t) = msg_type_for_count {
-                                        *current_file_type_counts.entry(t.clone()).or_insert(0) += 1;
-                                    }
+                                    // Count current message type and ticker for the (possibly new) file
+                                    current_file_stats.record(msg_type_for_count.as_deref(), ticker_for_count.as_deref());
                                 }
                                 Err(e) => {
                                     // Don't ack - message will be redelivered by NATS
@@ -417,6 +489,11 @@ async fn archive_stream(
                                 }
                             }
                         }
+
+                        if replay.is_some() && replay_done {
+                            info!(stream_name = %stream_name, nats_end_seq = last_seq, "Replay range archived");
+                            break;
+                        }
                     }
                     Err(e) => {
                         error!(stream_name = %stream_name, error = %e, "Failed to fetch messages");
@@ -447,7 +524,7 @@ async fn archive_stream(
     // Final cleanup
     info!(stream_name = %stream_name, "Writing final manifest");
     for mut entry in writer.close()? {
-        entry.records_by_type = Some(std::mem::take(&mut current_file_type_counts));
+        current_file_stats.finish(&mut entry);
         completed_files.push(entry);
     }
     update_manifest(
@@ -456,6 +533,7 @@ async fn archive_stream(
         &stream_name,
         &current_date,
         rotation_interval,
+        dedup_keys,
         &tickers,
         &message_types,
         &gaps,
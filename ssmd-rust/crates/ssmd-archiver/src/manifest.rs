@@ -1,4 +1,4 @@
-use std::collections::HashMap;
+use std::collections::{BTreeMap, HashMap, HashSet};
 
 use chrono::{DateTime, Utc};
 use serde::{Deserialize, Serialize};
@@ -13,7 +13,13 @@ pub struct Manifest {
     pub gaps: Vec<Gap>,
     pub tickers: Vec<String>,
     pub message_types: Vec<String>,
+    /// Field paths identifying one message, by message type, from the archiver config
+    #[serde(skip_serializing_if = "BTreeMap::is_empty", default)]
+    pub dedup_keys: BTreeMap<String, Vec<String>>,
     pub has_gaps: bool,
+    /// Set by `ssmd data compact` once the day's files are compacted to parquet
+    #[serde(skip_serializing_if = "Option::is_none", default)]
+    pub compaction: Option<Compaction>,
 }
 
 #[derive(Debug, Serialize, Deserialize, Clone)]
@@ -31,6 +37,20 @@ pub struct FileEntry {
     pub nats_end_seq: u64,
     #[serde(skip_serializing_if = "Option::is_none", default)]
     pub records_by_type: Option<HashMap<String, u64>>,
+    /// Hex SHA-256 of the compressed file, checked by `ssmd data verify`
+    #[serde(skip_serializing_if = "Option::is_none", default)]
+    pub sha256: Option<String>,
+    /// Duplicate lines removed by `ssmd data dedup --rewrite`
+    #[serde(skip_serializing_if = "Option::is_none", default)]
+    pub duplicates_removed: Option<u64>,
+    /// Earliest and latest `_received_at` in the file, for pruning range queries
+    #[serde(skip_serializing_if = "Option::is_none", default)]
+    pub min_ts: Option<DateTime<Utc>>,
+    #[serde(skip_serializing_if = "Option::is_none", default)]
+    pub max_ts: Option<DateTime<Utc>>,
+    /// Distinct tickers in the file
+    #[serde(skip_serializing_if = "Option::is_none", default)]
+    pub ticker_count: Option<u64>,
 }
 
 #[derive(Debug, Serialize, Deserialize, Clone)]
@@ -40,6 +60,32 @@ pub struct Gap {
     pub detected_at: DateTime<Utc>,
 }
 
+/// Ticker-partitioned parquet written from the day's JSONL files
+#[derive(Debug, Serialize, Deserialize, Clone)]
+pub struct Compaction {
+    pub compacted_at: DateTime<Utc>,
+    /// Directory of the parquet files, relative to the date directory
+    pub path: String,
+    pub partition_by: String,
+    pub sort_by: Vec<String>,
+    /// Archive files compacted, by name
+    pub sources: Vec<String>,
+    pub records: u64,
+    /// Duplicate lines left out (`ssmd data compact --dedup`)
+    #[serde(skip_serializing_if = "Option::is_none", default)]
+    pub duplicates_removed: Option<u64>,
+    pub files: Vec<CompactedFile>,
+    /// Whether the sources were deleted after compaction
+    pub originals_deleted: bool,
+}
+
+#[derive(Debug, Serialize, Deserialize, Clone)]
+pub struct CompactedFile {
+    pub name: String,
+    pub records: u64,
+    pub bytes: u64,
+}
+
 impl Manifest {
     pub fn new(feed: &str, date: &str, rotation_interval: &str, format: &str) -> Self {
         Self {
@@ -51,7 +97,112 @@ impl Manifest {
             gaps: Vec::new(),
             tickers: Vec::new(),
             message_types: Vec::new(),
+            dedup_keys: BTreeMap::new(),
             has_gaps: false,
+            compaction: None,
         }
     }
 }
+
+/// Counters for the file being written, moved into its FileEntry when it is
+/// rotated or closed.
+#[derive(Debug, Default)]
+pub struct FileStats {
+    type_counts: HashMap<String, u64>,
+    tickers: HashSet<String>,
+}
+
+impl FileStats {
+    pub fn record(&mut self, msg_type: Option<&str>, ticker: Option<&str>) {
+        if let Some(t) = msg_type {
+            *self.type_counts.entry(t.to_string()).or_insert(0) += 1;
+        }
+        if let Some(t) = ticker {
+            if !self.tickers.contains(t) {
+                self.tickers.insert(t.to_string());
+            }
+        }
+    }
+
+    /// Fill the entry's per-type counts and ticker cardinality, and reset for the next file
+    pub fn finish(&mut self, entry: &mut FileEntry) {
+        entry.records_by_type = Some(std::mem::take(&mut self.type_counts));
+        entry.ticker_count = Some(self.tickers.len() as u64);
+        self.tickers.clear();
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_manifest_reads_compaction() {
+        let json = r#"{"feed":"kalshi","date":"2026-02-15","format":"jsonl","rotation_interval":"15m",
+            "files":[],"gaps":[],"tickers":[],"message_types":[],"has_gaps":false,
+            "compaction":{"compacted_at":"2026-02-16T07:30:00Z","path":"compacted","partition_by":"ticker_key",
+            "sort_by":["ticker","received_at","nats_seq"],"sources":["0000.jsonl.gz"],"records":3,
+            "files":[{"name":"ticker_key=A/data_0.parquet","records":3,"bytes":900}],"originals_deleted":true}}"#;
+        let parsed: Manifest = serde_json::from_str(json).unwrap();
+        let compaction = parsed.compaction.unwrap();
+        assert_eq!(compaction.sources, vec!["0000.jsonl.gz"]);
+        assert!(compaction.originals_deleted);
+
+        let fresh =
+            serde_json::to_string(&Manifest::new("kalshi", "2026-02-15", "15m", "jsonl")).unwrap();
+        assert!(!fresh.contains("compaction"));
+    }
+
+    fn entry() -> FileEntry {
+        FileEntry {
+            name: "0000.jsonl.gz".to_string(),
+            start: Utc::now(),
+            end: Utc::now(),
+            records: 3,
+            bytes: 100,
+            raw_bytes: None,
+            compression_ratio: None,
+            nats_start_seq: 1,
+            nats_end_seq: 3,
+            records_by_type: None,
+            sha256: None,
+            duplicates_removed: None,
+            min_ts: None,
+            max_ts: None,
+            ticker_count: None,
+        }
+    }
+
+    #[test]
+    fn test_file_stats_counts_types_and_distinct_tickers() {
+        let mut stats = FileStats::default();
+        stats.record(Some("trade"), Some("A"));
+        stats.record(Some("trade"), Some("A"));
+        stats.record(Some("ticker"), Some("B"));
+        stats.record(None, None);
+
+        let mut first = entry();
+        stats.finish(&mut first);
+        let counts = first.records_by_type.unwrap();
+        assert_eq!(counts.get("trade"), Some(&2));
+        assert_eq!(counts.get("ticker"), Some(&1));
+        assert_eq!(first.ticker_count, Some(2));
+
+        // Reset for the next file
+        let mut second = entry();
+        stats.finish(&mut second);
+        assert!(second.records_by_type.unwrap().is_empty());
+        assert_eq!(second.ticker_count, Some(0));
+    }
+
+    #[test]
+    fn test_file_entry_stats_are_optional_in_json() {
+        let json = r#"{"name":"0000.jsonl.gz","start":"2026-02-15T00:00:00Z","end":"2026-02-15T00:15:00Z",
+            "records":1,"bytes":10,"nats_start_seq":1,"nats_end_seq":1}"#;
+        let parsed: FileEntry = serde_json::from_str(json).unwrap();
+        assert!(parsed.min_ts.is_none() && parsed.ticker_count.is_none());
+
+        let out = serde_json::to_string(&parsed).unwrap();
+        assert!(!out.contains("min_ts") && !out.contains("ticker_count"));
+    }
+}
@@ -1,4 +1,4 @@
-use std::collections::HashSet;
+use std::collections::{BTreeMap, HashSet};
 use std::path::Path;
 
 use crate::manifest::{FileEntry, Gap, Manifest};
@@ -12,6 +12,7 @@ pub fn update_manifest(
     stream_name: &str,
     date: &str,
     rotation_interval: &str,
+    dedup_keys: &BTreeMap<String, Vec<String>>,
     tickers: &HashSet<String>,
     message_types: &HashSet<String>,
     gaps: &[Gap],
@@ -23,6 +24,7 @@ pub fn update_manifest(
     manifest.tickers.sort_unstable();
     manifest.message_types = message_types.iter().cloned().collect();
     manifest.message_types.sort_unstable();
+    manifest.dedup_keys = dedup_keys.clone();
     manifest.gaps = gaps.to_vec();
     manifest.has_gaps = !gaps.is_empty();
 
@@ -50,6 +52,7 @@ pub fn write_manifest<W: ArchiveOutput>(
     stream_name: &str,
     date: &str,
     rotation_interval: &str,
+    dedup_keys: &BTreeMap<String, Vec<String>>,
     writer: &mut W,
     tickers: &HashSet<String>,
     message_types: &HashSet<String>,
@@ -64,6 +67,7 @@ pub fn write_manifest<W: ArchiveOutput>(
         stream_name,
         date,
         rotation_interval,
+        dedup_keys,
         tickers,
         message_types,
         gaps,
@@ -73,7 +77,7 @@ pub fn write_manifest<W: ArchiveOutput>(
 
 #[cfg(test)]
 mod tests {
-    use std::collections::HashSet;
+    use std::collections::{BTreeMap, HashSet};
 
     use chrono::Utc;
     use tempfile::TempDir;
@@ -117,6 +121,11 @@ mod tests {
         let mut message_types = HashSet::new();
         message_types.insert("trade".to_string());
         message_types.insert("ticker".to_string());
+        let mut dedup_keys = BTreeMap::new();
+        dedup_keys.insert(
+            "trade".to_string(),
+            vec!["msg.market_ticker".to_string(), "msg.trade_id".to_string()],
+        );
 
         let files = vec![FileEntry {
             name: "1200.jsonl.gz".to_string(),
@@ -129,6 +138,11 @@ mod tests {
             nats_start_seq: 1,
             nats_end_seq: 10,
             records_by_type: None,
+            sha256: None,
+            duplicates_removed: None,
+            min_ts: None,
+            max_ts: None,
+            ticker_count: None,
         }];
 
         update_manifest(
@@ -137,6 +151,7 @@ mod tests {
             "politics",
             date,
             "15m",
+            &dedup_keys,
             &tickers,
             &message_types,
             &[],
@@ -159,6 +174,8 @@ mod tests {
             manifest.message_types,
             vec!["ticker".to_string(), "trade".to_string()]
         );
+        assert_eq!(manifest.dedup_keys, dedup_keys);
+        assert!(content.contains("\"dedup_keys\""));
         assert!(!manifest_path.with_extension("json.tmp").exists());
     }
 
@@ -178,6 +195,11 @@ mod tests {
                 nats_start_seq: 11,
                 nats_end_seq: 15,
                 records_by_type: None,
+                sha256: None,
+                duplicates_removed: None,
+                min_ts: None,
+                max_ts: None,
+                ticker_count: None,
             }],
         };
 
@@ -192,6 +214,11 @@ mod tests {
             nats_start_seq: 1,
             nats_end_seq: 10,
             records_by_type: None,
+            sha256: None,
+            duplicates_removed: None,
+            min_ts: None,
+            max_ts: None,
+            ticker_count: None,
         }];
 
         let mut tickers = HashSet::new();
@@ -210,6 +237,7 @@ mod tests {
             "politics",
             "2026-02-14",
             "15m",
+            &BTreeMap::new(),
             &mut writer,
             &tickers,
             &message_types,
@@ -230,5 +258,6 @@ mod tests {
             serde_json::from_str(&std::fs::read_to_string(manifest_path).unwrap()).unwrap();
         assert_eq!(manifest.files.len(), 2);
         assert!(manifest.has_gaps);
+        assert!(manifest.dedup_keys.is_empty());
     }
 }
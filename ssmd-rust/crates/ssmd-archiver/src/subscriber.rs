@@ -1,4 +1,10 @@
-use async_nats::jetstream::{self, consumer::PullConsumer, message::Message};
+use async_nats::jetstream::{
+    self,
+    consumer::{DeliverPolicy, PullConsumer},
+    message::Message,
+    stream::Stream,
+};
+use chrono::{DateTime, Utc};
 use futures_util::StreamExt;
 use std::time::Duration;
 use tracing::{error, info, trace, warn};
@@ -15,6 +21,10 @@ pub struct Subscriber {
 pub struct ReceivedMessage {
     pub seq: u64,
     pub gap: Option<(u64, u64)>,
+    /// JetStream publish time
+    pub published: Option<DateTime<Utc>>,
+    /// Messages left for the consumer after this one
+    pub pending: Option<u64>,
     message: Message,
 }
 
@@ -36,43 +46,7 @@ impl ReceivedMessage {
 impl Subscriber {
     /// Connect to NATS and create a subscriber for a specific stream
     pub async fn connect(nats_url: &str, stream_config: &StreamConfig) -> Result<Self, ArchiverError> {
-        let client = async_nats::connect(nats_url)
-            .await
-            .map_err(|e| ArchiverError::Nats(e.to_string()))?;
-
-        let jetstream = jetstream::new(client);
-
-        // Get stream and validate filter subject before creating consumer
-        let mut stream = jetstream
-            .get_stream(&stream_config.stream)
-            .await
-            .map_err(|e| ArchiverError::Nats(format!("Stream not found: {}", e)))?;
-
-        // Validate filter subject matches stream subjects — crash on mismatch
-        // to prevent silent data loss (lifecycle stream incident: 1,856 messages
-        // dropped over a month because filter didn't match stream subjects).
-        let stream_info = stream
-            .info()
-            .await
-            .map_err(|e| ArchiverError::Nats(format!("Failed to get stream info: {}", e)))?;
-
-        let stream_subjects = &stream_info.config.subjects;
-        let filter = &stream_config.filter;
-
-        if !filter_matches_stream_subjects(filter, stream_subjects) {
-            return Err(ArchiverError::Nats(format!(
-                "Filter subject '{}' does not match any stream '{}' subjects: {:?}. \
-                 This will result in zero messages delivered.",
-                filter, stream_config.stream, stream_subjects
-            )));
-        }
-
-        info!(
-            stream = %stream_config.stream,
-            filter = %filter,
-            stream_subjects = ?stream_subjects,
-            "Stream subject validation passed"
-        );
+        let stream = open_stream(nats_url, stream_config).await?;
 
         let consumer = stream
             .get_or_create_consumer(
@@ -99,6 +73,37 @@ impl Subscriber {
         })
     }
 
+    /// Connect with an ephemeral consumer reading from `start_sequence`, for
+    /// replays. The live archiver's durable consumer is left untouched.
+    pub async fn connect_range(
+        nats_url: &str,
+        stream_config: &StreamConfig,
+        start_sequence: u64,
+    ) -> Result<Self, ArchiverError> {
+        let stream = open_stream(nats_url, stream_config).await?;
+
+        let consumer = stream
+            .create_consumer(jetstream::consumer::pull::Config {
+                filter_subject: stream_config.filter.clone(),
+                deliver_policy: DeliverPolicy::ByStartSequence { start_sequence },
+                ..Default::default()
+            })
+            .await
+            .map_err(|e| ArchiverError::Nats(e.to_string()))?;
+
+        info!(
+            stream = %stream_config.stream,
+            filter = %stream_config.filter,
+            start_sequence = start_sequence,
+            "Connected to NATS JetStream for replay"
+        );
+
+        Ok(Self {
+            consumer,
+            expected_seq: None,
+        })
+    }
+
     /// Fetch next batch of messages
     pub async fn fetch(&mut self, batch_size: usize) -> Result<Vec<ReceivedMessage>, ArchiverError> {
         let messages = self
@@ -117,7 +122,17 @@ impl Subscriber {
         while let Some(msg_result) = messages.next().await {
             match msg_result {
                 Ok(msg) => {
-                    let seq = msg.info().map(|i| i.stream_sequence).unwrap_or(0);
+                    let (seq, published, pending) = match msg.info() {
+                        Ok(info) => (
+                            info.stream_sequence,
+                            DateTime::from_timestamp(
+                                info.published.unix_timestamp(),
+                                info.published.nanosecond(),
+                            ),
+                            Some(info.pending),
+                        ),
+                        Err(_) => (0, None, None),
+                    };
                     let (gap, next_expected) = compute_gap_and_next(self.expected_seq, seq);
 
                     if let Some((after_seq, missing_count)) = gap {
@@ -135,6 +150,8 @@ impl Subscriber {
                     result.push(ReceivedMessage {
                         seq,
                         gap,
+                        published,
+                        pending,
                         message: msg,
                     });
                     // Note: ack deferred until after successful write
@@ -151,6 +168,49 @@ impl Subscriber {
 
 }
 
+/// Connect to NATS and get the stream, checking the filter matches its subjects
+async fn open_stream(nats_url: &str, stream_config: &StreamConfig) -> Result<Stream, ArchiverError> {
+    let client = async_nats::connect(nats_url)
+        .await
+        .map_err(|e| ArchiverError::Nats(e.to_string()))?;
+
+    let jetstream = jetstream::new(client);
+
+    // Get stream and validate filter subject before creating consumer
+    let mut stream = jetstream
+        .get_stream(&stream_config.stream)
+        .await
+        .map_err(|e| ArchiverError::Nats(format!("Stream not found: {}", e)))?;
+
+    // Validate filter subject matches stream subjects — crash on mismatch
+    // to prevent silent data loss (lifecycle stream incident: 1,856 messages
+    // dropped over a month because filter didn't match stream subjects).
+    let stream_info = stream
+        .info()
+        .await
+        .map_err(|e| ArchiverError::Nats(format!("Failed to get stream info: {}", e)))?;
+
+    let stream_subjects = &stream_info.config.subjects;
+    let filter = &stream_config.filter;
+
+    if !filter_matches_stream_subjects(filter, stream_subjects) {
+        return Err(ArchiverError::Nats(format!(
+            "Filter subject '{}' does not match any stream '{}' subjects: {:?}. \
+             This will result in zero messages delivered.",
+            filter, stream_config.stream, stream_subjects
+        )));
+    }
+
+    info!(
+        stream = %stream_config.stream,
+        filter = %filter,
+        stream_subjects = ?stream_subjects,
+        "Stream subject validation passed"
+    );
+
+    Ok(stream)
+}
+
 /// Check if a NATS filter subject is compatible with any of the stream's subjects.
 /// A filter is compatible if it shares a prefix with a stream subject (before wildcards).
 /// e.g., filter "prod.kalshi.json.ticker.>" matches stream "prod.kalshi.>"
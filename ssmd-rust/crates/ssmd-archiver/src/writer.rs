@@ -1,10 +1,11 @@
 use std::fs::{self, File};
 use std::io::Write;
-use std::path::PathBuf;
+use std::path::{Path, PathBuf};
 
 use chrono::{DateTime, Utc};
 use flate2::write::GzEncoder;
 use flate2::Compression;
+use sha2::{Digest, Sha256};
 
 use crate::error::ArchiverError;
 use crate::manifest::FileEntry;
@@ -44,6 +45,8 @@ struct CurrentFile {
     bytes_written: u64,
     first_seq: Option<u64>,
     last_seq: Option<u64>,
+    min_ts: Option<DateTime<Utc>>,
+    max_ts: Option<DateTime<Utc>>,
 }
 
 impl ArchiveWriter {
@@ -114,6 +117,8 @@ impl ArchiveWriter {
             bytes_written: 0,
             first_seq: None,
             last_seq: None,
+            min_ts: None,
+            max_ts: None,
         });
 
         Ok(())
@@ -125,6 +130,7 @@ impl ArchiveWriter {
         // Atomic rename from .tmp to final name
         let final_path = file.path.with_file_name(&file.final_name);
         fs::rename(&file.path, &final_path)?;
+        let sha256 = file_sha256(&final_path)?;
 
         let end_time = Utc::now();
 
@@ -139,10 +145,22 @@ impl ArchiveWriter {
             nats_start_seq: file.first_seq.unwrap_or(0),
             nats_end_seq: file.last_seq.unwrap_or(0),
             records_by_type: None,
+            sha256: Some(sha256),
+            duplicates_removed: None,
+            min_ts: file.min_ts,
+            max_ts: file.max_ts,
+            ticker_count: None,
         })
     }
 }
 
+/// Hex SHA-256 of a finished archive file, as uploaded
+fn file_sha256(path: &Path) -> Result<String, ArchiverError> {
+    let mut hasher = Sha256::new();
+    std::io::copy(&mut File::open(path)?, &mut hasher)?;
+    Ok(format!("{:x}", hasher.finalize()))
+}
+
 impl ArchiveOutput for ArchiveWriter {
     fn write(
         &mut self,
@@ -173,6 +191,12 @@ impl ArchiveOutput for ArchiveWriter {
             file.first_seq = Some(seq);
         }
         file.last_seq = Some(seq);
+        if file.min_ts.is_none_or(|t| now < t) {
+            file.min_ts = Some(now);
+        }
+        if file.max_ts.is_none_or(|t| now > t) {
+            file.max_ts = Some(now);
+        }
 
         // Inject _received_at and _nats_seq into JSON payload via byte-level
         // manipulation (no serde round-trip — this is the hot path).
@@ -279,6 +303,9 @@ mod tests {
         assert_eq!(lines.len(), 2);
         assert!(lines[0].as_ref().unwrap().contains("INXD"));
         assert!(lines[1].as_ref().unwrap().contains("KXBTC"));
+
+        let digest = Sha256::digest(fs::read(&final_path).unwrap());
+        assert_eq!(entry.sha256, Some(format!("{:x}", digest)));
     }
 
     #[test]
@@ -422,6 +449,29 @@ mod tests {
         );
     }
 
+    #[test]
+    fn test_entry_records_min_and_max_received_time() {
+        let tmp = TempDir::new().unwrap();
+        let mut writer = ArchiveWriter::new(
+            tmp.path().to_path_buf(),
+            "kalshi".to_string(),
+            "politics".to_string(),
+            15,
+        );
+
+        let first = Utc::now();
+        let last = first + chrono::Duration::seconds(30);
+        writer.write(br#"{"type":"trade"}"#, 1, first).unwrap();
+        writer.write(br#"{"type":"trade"}"#, 2, last).unwrap();
+        writer
+            .write(br#"{"type":"trade"}"#, 3, first + chrono::Duration::seconds(10))
+            .unwrap();
+
+        let entries = writer.close().unwrap();
+        assert_eq!(entries[0].min_ts, Some(first));
+        assert_eq!(entries[0].max_ts, Some(last));
+    }
+
     #[test]
     fn test_bytes_written_accounts_for_injected_fields() {
         let tmp = TempDir::new().unwrap();
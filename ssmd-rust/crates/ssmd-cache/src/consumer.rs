@@ -54,7 +54,7 @@ impl EventSeriesLookup {
         };
         match client
             .query_opt(
-                "SELECT series_ticker FROM events WHERE event_ticker = $1",
+                "SELECT series_ticker FROM events WHERE exchange = 'kalshi' AND event_ticker = $1",
                 &[&event_ticker],
             )
             .await
@@ -237,7 +237,7 @@ impl LifecycleConsumer {
         // UPDATE market status for terminal events
         if is_terminal_event(&m.event_type) {
             let result = tx.execute(
-                "UPDATE markets SET status = $1, updated_at = NOW() WHERE ticker = $2",
+                "UPDATE markets SET status = $1, updated_at = NOW() WHERE exchange = 'kalshi' AND ticker = $2",
                 &[&m.event_type, &m.market_ticker],
             ).await.map_err(|e| Error::Database(format!("Market status UPDATE failed: {e}")))?;
 
@@ -252,7 +252,7 @@ impl LifecycleConsumer {
         } else if m.event_type == "close_date_updated" {
             if let Some(close_dt) = epoch_to_datetime(m.close_ts) {
                 tx.execute(
-                    "UPDATE markets SET close_time = $1, updated_at = NOW() WHERE ticker = $2",
+                    "UPDATE markets SET close_time = $1, updated_at = NOW() WHERE exchange = 'kalshi' AND ticker = $2",
                     &[&close_dt, &m.market_ticker],
                 ).await.map_err(|e| Error::Database(format!("Close time UPDATE failed: {e}")))?;
             }
@@ -296,7 +296,7 @@ impl LifecycleConsumer {
                     // metadata (title/category). Never clobber existing event metadata.
                     tx.execute(
                         "INSERT INTO events (event_ticker, title) VALUES ($1, $2)
-                         ON CONFLICT (event_ticker) DO NOTHING",
+                         ON CONFLICT (exchange, event_ticker) DO NOTHING",
                         &[&event_ticker, &event_ticker],
                     ).await.map_err(|e| Error::Database(format!("Event upsert failed: {e}")))?;
 
@@ -310,7 +310,7 @@ impl LifecycleConsumer {
                     let upserted = tx.execute(
                         "INSERT INTO markets (ticker, event_ticker, title, status, close_time)
                          VALUES ($1, $2, $3, $5, $4)
-                         ON CONFLICT (ticker) DO UPDATE SET
+                         ON CONFLICT (exchange, ticker) DO UPDATE SET
                              status = $5,
                              close_time = COALESCE(EXCLUDED.close_time, markets.close_time),
                              updated_at = NOW()
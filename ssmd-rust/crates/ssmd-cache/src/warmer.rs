@@ -52,11 +52,12 @@ impl CacheWarmer {
                            COUNT(DISTINCT e.event_ticker) AS event_count,
                            COUNT(DISTINCT e.series_ticker) AS series_count
                     FROM events e
-                    WHERE e.status = 'active'
+                    WHERE e.exchange = 'kalshi'
+                      AND e.status = 'active'
                       AND e.category IS NOT NULL
                       AND EXISTS (
                         SELECT 1 FROM markets m
-                        WHERE m.event_ticker = e.event_ticker
+                        WHERE m.exchange = e.exchange AND m.event_ticker = e.event_ticker
                           AND m.status IN ('active','initialized')
                           AND m.close_time > NOW()
                       )
@@ -93,14 +94,14 @@ impl CacheWarmer {
                            COUNT(DISTINCT e.event_ticker) AS active_events,
                            COUNT(DISTINCT m.ticker) AS active_markets
                     FROM series s
-                    JOIN events e ON e.series_ticker = s.ticker AND e.status = 'active'
+                    JOIN events e ON e.series_ticker = s.ticker AND e.exchange = 'kalshi' AND e.status = 'active'
                       AND EXISTS (
                         SELECT 1 FROM markets m2
-                        WHERE m2.event_ticker = e.event_ticker
+                        WHERE m2.exchange = e.exchange AND m2.event_ticker = e.event_ticker
                           AND m2.status IN ('active','initialized')
                           AND m2.close_time > NOW()
                       )
-                    LEFT JOIN markets m ON m.event_ticker = e.event_ticker
+                    LEFT JOIN markets m ON m.exchange = e.exchange AND m.event_ticker = e.event_ticker
                       AND m.status IN ('active','initialized') AND m.close_time > NOW()
                     WHERE e.category IS NOT NULL
                     GROUP BY e.category, s.ticker, s.title
@@ -143,9 +144,9 @@ impl CacheWarmer {
                            COUNT(m.ticker) AS market_count,
                            MIN(m.expected_expiration_time)::text AS expected_expiration_time
                     FROM events e
-                    JOIN markets m ON m.event_ticker = e.event_ticker
+                    JOIN markets m ON m.exchange = e.exchange AND m.event_ticker = e.event_ticker
                       AND m.status IN ('active','initialized') AND m.close_time > NOW()
-                    WHERE e.status = 'active'
+                    WHERE e.exchange = 'kalshi' AND e.status = 'active'
                     GROUP BY e.series_ticker, e.event_ticker, e.title, e.status, e.strike_date
                     "#,
                     &[] as &[&str],
@@ -188,7 +189,8 @@ impl CacheWarmer {
                     SELECT m.event_ticker, m.ticker, m.title, m.status, m.close_time::text,
                            m.expected_expiration_time::text
                     FROM markets m
-                    WHERE m.status IN ('active','initialized')
+                    WHERE m.exchange = 'kalshi'
+                      AND m.status IN ('active','initialized')
                       AND m.close_time > NOW()
                     "#,
                     &[] as &[&str],
@@ -229,7 +231,7 @@ impl CacheWarmer {
                     SELECT mle.market_ticker, mle.event_type, mle.received_at::text,
                            mle.metadata::text
                     FROM market_lifecycle_events mle
-                    JOIN markets m ON m.ticker = mle.market_ticker
+                    JOIN markets m ON m.exchange = 'kalshi' AND m.ticker = mle.market_ticker
                     WHERE m.status IN ('active','initialized')
                       AND m.close_time > NOW()
                     ORDER BY mle.market_ticker, mle.received_at
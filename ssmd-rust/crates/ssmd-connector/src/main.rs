@@ -16,7 +16,7 @@ use ssmd_connector_lib::{
     binance::{BinanceConnector, BinanceNatsWriter},
     kalshi::{KalshiConfig, KalshiConnector, KalshiCredentials},
     massive::{MassiveConnector, MassiveNatsWriter},
-    EnvResolver, KeyResolver, NatsWriter, Runner, ServerState, WebSocketConnector,
+    resolve_source, NatsWriter, Runner, ServerState, WebSocketConnector,
 };
 use ssmd_metadata::{Environment, Feed, FeedType, KeyType, TransportType};
 use ssmd_middleware::MiddlewareFactory;
@@ -112,8 +112,7 @@ async fn run_kalshi_connector(
 
         if let Some(key_spec) = api_key_spec {
             if let Some(ref source) = key_spec.source {
-                let resolver = EnvResolver::new();
-                let resolved = resolver.resolve(source).map_err(|e| {
+                let resolved = resolve_source(source).map_err(|e| {
                     error!(error = %e, source = %source, "Failed to resolve credentials from env config");
                     e
                 })?;
@@ -866,8 +865,7 @@ async fn run_generic_connector(
 
         if let Some(key_spec) = api_key_spec {
             if let Some(ref source) = key_spec.source {
-                let resolver = EnvResolver::new();
-                match resolver.resolve(source) {
+                match resolve_source(source) {
                     Ok(resolved_keys) => Some(resolved_keys),
                     Err(e) => {
                         error!(error = %e, "Failed to resolve credentials");
@@ -119,8 +119,9 @@ pub async fn run(pool: &Pool, gcs: &Arc<GcsWriter>) -> Result<u64> {
                m.open_interest,
                m.close_time::text
         FROM markets m
-        JOIN events e ON e.event_ticker = m.event_ticker
-        WHERE e.category = 'Crypto'
+        JOIN events e ON e.exchange = m.exchange AND e.event_ticker = m.event_ticker
+        WHERE m.exchange = 'kalshi'
+          AND e.category = 'Crypto'
           AND e.series_ticker LIKE '%15M'
           AND m.status = 'settled'
           AND m.result IS NOT NULL